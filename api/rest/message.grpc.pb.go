@@ -124,6 +124,349 @@ func (x *SendWSMessageResponse) GetMessage() string {
 	return ""
 }
 
+// 获取投递链路状态请求
+type GetDeliveryStatusRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *GetDeliveryStatusRequest) Reset() {
+	*x = GetDeliveryStatusRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_message_grpc_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetDeliveryStatusRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetDeliveryStatusRequest) ProtoMessage() {}
+
+func (x *GetDeliveryStatusRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_message_grpc_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetDeliveryStatusRequest.ProtoReflect.Descriptor instead.
+func (*GetDeliveryStatusRequest) Descriptor() ([]byte, []int) {
+	return file_message_grpc_proto_rawDescGZIP(), []int{2}
+}
+
+// Connect实例投递状态
+type GatewayDeliveryStatus struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	ServerId    string `protobuf:"bytes,1,opt,name=server_id,json=serverId,proto3" json:"server_id,omitempty"`         // Connect实例ID
+	Subscribers int64  `protobuf:"varint,2,opt,name=subscribers,proto3" json:"subscribers,omitempty"`                  // 推送频道当前订阅数，为0表示实例未订阅
+	Pushed      int64  `protobuf:"varint,3,opt,name=pushed,proto3" json:"pushed,omitempty"`                            // 本实例启动以来推送到该频道的消息数
+	Unreceived  int64  `protobuf:"varint,4,opt,name=unreceived,proto3" json:"unreceived,omitempty"`                    // 发布时无订阅者、未被接收的消息数
+	Failed      int64  `protobuf:"varint,5,opt,name=failed,proto3" json:"failed,omitempty"`                            // 发布失败数
+	LastPushAt  string `protobuf:"bytes,6,opt,name=last_push_at,json=lastPushAt,proto3" json:"last_push_at,omitempty"` // 最近一次推送时间
+	LastError   string `protobuf:"bytes,7,opt,name=last_error,json=lastError,proto3" json:"last_error,omitempty"`      // 最近一次失败原因
+}
+
+func (x *GatewayDeliveryStatus) Reset() {
+	*x = GatewayDeliveryStatus{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_message_grpc_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GatewayDeliveryStatus) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GatewayDeliveryStatus) ProtoMessage() {}
+
+func (x *GatewayDeliveryStatus) ProtoReflect() protoreflect.Message {
+	mi := &file_message_grpc_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GatewayDeliveryStatus.ProtoReflect.Descriptor instead.
+func (*GatewayDeliveryStatus) Descriptor() ([]byte, []int) {
+	return file_message_grpc_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *GatewayDeliveryStatus) GetServerId() string {
+	if x != nil {
+		return x.ServerId
+	}
+	return ""
+}
+
+func (x *GatewayDeliveryStatus) GetSubscribers() int64 {
+	if x != nil {
+		return x.Subscribers
+	}
+	return 0
+}
+
+func (x *GatewayDeliveryStatus) GetPushed() int64 {
+	if x != nil {
+		return x.Pushed
+	}
+	return 0
+}
+
+func (x *GatewayDeliveryStatus) GetUnreceived() int64 {
+	if x != nil {
+		return x.Unreceived
+	}
+	return 0
+}
+
+func (x *GatewayDeliveryStatus) GetFailed() int64 {
+	if x != nil {
+		return x.Failed
+	}
+	return 0
+}
+
+func (x *GatewayDeliveryStatus) GetLastPushAt() string {
+	if x != nil {
+		return x.LastPushAt
+	}
+	return ""
+}
+
+func (x *GatewayDeliveryStatus) GetLastError() string {
+	if x != nil {
+		return x.LastError
+	}
+	return ""
+}
+
+// 获取投递链路状态响应
+type GetDeliveryStatusResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Success            bool                     `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Message            string                   `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	Status             string                   `protobuf:"bytes,3,opt,name=status,proto3" json:"status,omitempty"`                                                    // healthy, degraded, unhealthy
+	Problems           []string                 `protobuf:"bytes,4,rep,name=problems,proto3" json:"problems,omitempty"`                                                // 状态非healthy的原因
+	Gateways           []*GatewayDeliveryStatus `protobuf:"bytes,5,rep,name=gateways,proto3" json:"gateways,omitempty"`                                                // 订阅中或近期有推送的Connect实例
+	SubscribedGateways int64                    `protobuf:"varint,6,opt,name=subscribed_gateways,json=subscribedGateways,proto3" json:"subscribed_gateways,omitempty"` // 订阅中的Connect实例数
+	PendingAck         int64                    `protobuf:"varint,7,opt,name=pending_ack,json=pendingAck,proto3" json:"pending_ack,omitempty"`                         // 超过确认宽限期仍未确认的单聊消息数
+	LastConsumedAt     string                   `protobuf:"bytes,8,opt,name=last_consumed_at,json=lastConsumedAt,proto3" json:"last_consumed_at,omitempty"`            // 推送消费者最近一次消费时间
+	CheckedAt          string                   `protobuf:"bytes,9,opt,name=checked_at,json=checkedAt,proto3" json:"checked_at,omitempty"`
+	Fanout             *FanoutDeliveryStat      `protobuf:"bytes,10,opt,name=fanout,proto3" json:"fanout,omitempty"` // 群消息扇出投递结果统计
+}
+
+func (x *GetDeliveryStatusResponse) Reset() {
+	*x = GetDeliveryStatusResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_message_grpc_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetDeliveryStatusResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetDeliveryStatusResponse) ProtoMessage() {}
+
+func (x *GetDeliveryStatusResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_message_grpc_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetDeliveryStatusResponse.ProtoReflect.Descriptor instead.
+func (*GetDeliveryStatusResponse) Descriptor() ([]byte, []int) {
+	return file_message_grpc_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *GetDeliveryStatusResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *GetDeliveryStatusResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *GetDeliveryStatusResponse) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *GetDeliveryStatusResponse) GetProblems() []string {
+	if x != nil {
+		return x.Problems
+	}
+	return nil
+}
+
+func (x *GetDeliveryStatusResponse) GetGateways() []*GatewayDeliveryStatus {
+	if x != nil {
+		return x.Gateways
+	}
+	return nil
+}
+
+func (x *GetDeliveryStatusResponse) GetSubscribedGateways() int64 {
+	if x != nil {
+		return x.SubscribedGateways
+	}
+	return 0
+}
+
+func (x *GetDeliveryStatusResponse) GetPendingAck() int64 {
+	if x != nil {
+		return x.PendingAck
+	}
+	return 0
+}
+
+func (x *GetDeliveryStatusResponse) GetLastConsumedAt() string {
+	if x != nil {
+		return x.LastConsumedAt
+	}
+	return ""
+}
+
+func (x *GetDeliveryStatusResponse) GetCheckedAt() string {
+	if x != nil {
+		return x.CheckedAt
+	}
+	return ""
+}
+
+func (x *GetDeliveryStatusResponse) GetFanout() *FanoutDeliveryStat {
+	if x != nil {
+		return x.Fanout
+	}
+	return nil
+}
+
+// 群消息扇出投递结果统计，兼容逐条和批量发布的投递结果事件
+type FanoutDeliveryStat struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Delivered     int64  `protobuf:"varint,1,opt,name=delivered,proto3" json:"delivered,omitempty"`
+	QueuedOffline int64  `protobuf:"varint,2,opt,name=queued_offline,json=queuedOffline,proto3" json:"queued_offline,omitempty"`
+	Failed        int64  `protobuf:"varint,3,opt,name=failed,proto3" json:"failed,omitempty"`
+	Batches       int64  `protobuf:"varint,4,opt,name=batches,proto3" json:"batches,omitempty"`                             // 收到的批量事件数
+	LastEventAt   string `protobuf:"bytes,5,opt,name=last_event_at,json=lastEventAt,proto3" json:"last_event_at,omitempty"` // 最近一条事件的产生时间
+	MaxLag        int64  `protobuf:"varint,6,opt,name=max_lag,json=maxLag,proto3" json:"max_lag,omitempty"`                 // 事件产生到被消费的最大延迟（秒）
+}
+
+func (x *FanoutDeliveryStat) Reset() {
+	*x = FanoutDeliveryStat{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_message_grpc_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *FanoutDeliveryStat) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FanoutDeliveryStat) ProtoMessage() {}
+
+func (x *FanoutDeliveryStat) ProtoReflect() protoreflect.Message {
+	mi := &file_message_grpc_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FanoutDeliveryStat.ProtoReflect.Descriptor instead.
+func (*FanoutDeliveryStat) Descriptor() ([]byte, []int) {
+	return file_message_grpc_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *FanoutDeliveryStat) GetDelivered() int64 {
+	if x != nil {
+		return x.Delivered
+	}
+	return 0
+}
+
+func (x *FanoutDeliveryStat) GetQueuedOffline() int64 {
+	if x != nil {
+		return x.QueuedOffline
+	}
+	return 0
+}
+
+func (x *FanoutDeliveryStat) GetFailed() int64 {
+	if x != nil {
+		return x.Failed
+	}
+	return 0
+}
+
+func (x *FanoutDeliveryStat) GetBatches() int64 {
+	if x != nil {
+		return x.Batches
+	}
+	return 0
+}
+
+func (x *FanoutDeliveryStat) GetLastEventAt() string {
+	if x != nil {
+		return x.LastEventAt
+	}
+	return ""
+}
+
+func (x *FanoutDeliveryStat) GetMaxLag() int64 {
+	if x != nil {
+		return x.MaxLag
+	}
+	return 0
+}
+
 var File_message_grpc_proto protoreflect.FileDescriptor
 
 var file_message_grpc_proto_rawDesc = []byte{
@@ -138,23 +481,83 @@ var file_message_grpc_proto_rawDesc = []byte{
 	0x07, 0x73, 0x75, 0x63, 0x63, 0x65, 0x73, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x08, 0x52, 0x07,
 	0x73, 0x75, 0x63, 0x63, 0x65, 0x73, 0x73, 0x12, 0x18, 0x0a, 0x07, 0x6d, 0x65, 0x73, 0x73, 0x61,
 	0x67, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67,
-	0x65, 0x32, 0xf8, 0x01, 0x0a, 0x0e, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x53, 0x65, 0x72,
-	0x76, 0x69, 0x63, 0x65, 0x12, 0x48, 0x0a, 0x0d, 0x53, 0x65, 0x6e, 0x64, 0x57, 0x53, 0x4d, 0x65,
-	0x73, 0x73, 0x61, 0x67, 0x65, 0x12, 0x1a, 0x2e, 0x72, 0x65, 0x73, 0x74, 0x2e, 0x53, 0x65, 0x6e,
-	0x64, 0x57, 0x53, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
-	0x74, 0x1a, 0x1b, 0x2e, 0x72, 0x65, 0x73, 0x74, 0x2e, 0x53, 0x65, 0x6e, 0x64, 0x57, 0x53, 0x4d,
-	0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x47,
-	0x0a, 0x12, 0x47, 0x65, 0x74, 0x48, 0x69, 0x73, 0x74, 0x6f, 0x72, 0x79, 0x4d, 0x65, 0x73, 0x73,
-	0x61, 0x67, 0x65, 0x73, 0x12, 0x17, 0x2e, 0x72, 0x65, 0x73, 0x74, 0x2e, 0x47, 0x65, 0x74, 0x48,
-	0x69, 0x73, 0x74, 0x6f, 0x72, 0x79, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x18, 0x2e,
-	0x72, 0x65, 0x73, 0x74, 0x2e, 0x47, 0x65, 0x74, 0x48, 0x69, 0x73, 0x74, 0x6f, 0x72, 0x79, 0x52,
-	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x53, 0x0a, 0x12, 0x4d, 0x61, 0x72, 0x6b, 0x4d,
-	0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x73, 0x41, 0x73, 0x52, 0x65, 0x61, 0x64, 0x12, 0x1d, 0x2e,
-	0x72, 0x65, 0x73, 0x74, 0x2e, 0x4d, 0x61, 0x72, 0x6b, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65,
-	0x73, 0x52, 0x65, 0x61, 0x64, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1e, 0x2e, 0x72,
-	0x65, 0x73, 0x74, 0x2e, 0x4d, 0x61, 0x72, 0x6b, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x73,
-	0x52, 0x65, 0x61, 0x64, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x42, 0x08, 0x5a, 0x06,
-	0x2e, 0x3b, 0x72, 0x65, 0x73, 0x74, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+	0x65, 0x22, 0x1a, 0x0a, 0x18, 0x47, 0x65, 0x74, 0x44, 0x65, 0x6c, 0x69, 0x76, 0x65, 0x72, 0x79,
+	0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x22, 0xe7, 0x01,
+	0x0a, 0x15, 0x47, 0x61, 0x74, 0x65, 0x77, 0x61, 0x79, 0x44, 0x65, 0x6c, 0x69, 0x76, 0x65, 0x72,
+	0x79, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x1b, 0x0a, 0x09, 0x73, 0x65, 0x72, 0x76, 0x65,
+	0x72, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x73, 0x65, 0x72, 0x76,
+	0x65, 0x72, 0x49, 0x64, 0x12, 0x20, 0x0a, 0x0b, 0x73, 0x75, 0x62, 0x73, 0x63, 0x72, 0x69, 0x62,
+	0x65, 0x72, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0b, 0x73, 0x75, 0x62, 0x73, 0x63,
+	0x72, 0x69, 0x62, 0x65, 0x72, 0x73, 0x12, 0x16, 0x0a, 0x06, 0x70, 0x75, 0x73, 0x68, 0x65, 0x64,
+	0x18, 0x03, 0x20, 0x01, 0x28, 0x03, 0x52, 0x06, 0x70, 0x75, 0x73, 0x68, 0x65, 0x64, 0x12, 0x1e,
+	0x0a, 0x0a, 0x75, 0x6e, 0x72, 0x65, 0x63, 0x65, 0x69, 0x76, 0x65, 0x64, 0x18, 0x04, 0x20, 0x01,
+	0x28, 0x03, 0x52, 0x0a, 0x75, 0x6e, 0x72, 0x65, 0x63, 0x65, 0x69, 0x76, 0x65, 0x64, 0x12, 0x16,
+	0x0a, 0x06, 0x66, 0x61, 0x69, 0x6c, 0x65, 0x64, 0x18, 0x05, 0x20, 0x01, 0x28, 0x03, 0x52, 0x06,
+	0x66, 0x61, 0x69, 0x6c, 0x65, 0x64, 0x12, 0x20, 0x0a, 0x0c, 0x6c, 0x61, 0x73, 0x74, 0x5f, 0x70,
+	0x75, 0x73, 0x68, 0x5f, 0x61, 0x74, 0x18, 0x06, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x6c, 0x61,
+	0x73, 0x74, 0x50, 0x75, 0x73, 0x68, 0x41, 0x74, 0x12, 0x1d, 0x0a, 0x0a, 0x6c, 0x61, 0x73, 0x74,
+	0x5f, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x18, 0x07, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x6c, 0x61,
+	0x73, 0x74, 0x45, 0x72, 0x72, 0x6f, 0x72, 0x22, 0x89, 0x03, 0x0a, 0x19, 0x47, 0x65, 0x74, 0x44,
+	0x65, 0x6c, 0x69, 0x76, 0x65, 0x72, 0x79, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x65, 0x73,
+	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x73, 0x75, 0x63, 0x63, 0x65, 0x73, 0x73,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x08, 0x52, 0x07, 0x73, 0x75, 0x63, 0x63, 0x65, 0x73, 0x73, 0x12,
+	0x18, 0x0a, 0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x74, 0x61,
+	0x74, 0x75, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75,
+	0x73, 0x12, 0x1a, 0x0a, 0x08, 0x70, 0x72, 0x6f, 0x62, 0x6c, 0x65, 0x6d, 0x73, 0x18, 0x04, 0x20,
+	0x03, 0x28, 0x09, 0x52, 0x08, 0x70, 0x72, 0x6f, 0x62, 0x6c, 0x65, 0x6d, 0x73, 0x12, 0x37, 0x0a,
+	0x08, 0x67, 0x61, 0x74, 0x65, 0x77, 0x61, 0x79, 0x73, 0x18, 0x05, 0x20, 0x03, 0x28, 0x0b, 0x32,
+	0x1b, 0x2e, 0x72, 0x65, 0x73, 0x74, 0x2e, 0x47, 0x61, 0x74, 0x65, 0x77, 0x61, 0x79, 0x44, 0x65,
+	0x6c, 0x69, 0x76, 0x65, 0x72, 0x79, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x08, 0x67, 0x61,
+	0x74, 0x65, 0x77, 0x61, 0x79, 0x73, 0x12, 0x2f, 0x0a, 0x13, 0x73, 0x75, 0x62, 0x73, 0x63, 0x72,
+	0x69, 0x62, 0x65, 0x64, 0x5f, 0x67, 0x61, 0x74, 0x65, 0x77, 0x61, 0x79, 0x73, 0x18, 0x06, 0x20,
+	0x01, 0x28, 0x03, 0x52, 0x12, 0x73, 0x75, 0x62, 0x73, 0x63, 0x72, 0x69, 0x62, 0x65, 0x64, 0x47,
+	0x61, 0x74, 0x65, 0x77, 0x61, 0x79, 0x73, 0x12, 0x1f, 0x0a, 0x0b, 0x70, 0x65, 0x6e, 0x64, 0x69,
+	0x6e, 0x67, 0x5f, 0x61, 0x63, 0x6b, 0x18, 0x07, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0a, 0x70, 0x65,
+	0x6e, 0x64, 0x69, 0x6e, 0x67, 0x41, 0x63, 0x6b, 0x12, 0x28, 0x0a, 0x10, 0x6c, 0x61, 0x73, 0x74,
+	0x5f, 0x63, 0x6f, 0x6e, 0x73, 0x75, 0x6d, 0x65, 0x64, 0x5f, 0x61, 0x74, 0x18, 0x08, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x0e, 0x6c, 0x61, 0x73, 0x74, 0x43, 0x6f, 0x6e, 0x73, 0x75, 0x6d, 0x65, 0x64,
+	0x41, 0x74, 0x12, 0x1d, 0x0a, 0x0a, 0x63, 0x68, 0x65, 0x63, 0x6b, 0x65, 0x64, 0x5f, 0x61, 0x74,
+	0x18, 0x09, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x63, 0x68, 0x65, 0x63, 0x6b, 0x65, 0x64, 0x41,
+	0x74, 0x12, 0x30, 0x0a, 0x06, 0x66, 0x61, 0x6e, 0x6f, 0x75, 0x74, 0x18, 0x0a, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x18, 0x2e, 0x72, 0x65, 0x73, 0x74, 0x2e, 0x46, 0x61, 0x6e, 0x6f, 0x75, 0x74, 0x44,
+	0x65, 0x6c, 0x69, 0x76, 0x65, 0x72, 0x79, 0x53, 0x74, 0x61, 0x74, 0x52, 0x06, 0x66, 0x61, 0x6e,
+	0x6f, 0x75, 0x74, 0x22, 0xc8, 0x01, 0x0a, 0x12, 0x46, 0x61, 0x6e, 0x6f, 0x75, 0x74, 0x44, 0x65,
+	0x6c, 0x69, 0x76, 0x65, 0x72, 0x79, 0x53, 0x74, 0x61, 0x74, 0x12, 0x1c, 0x0a, 0x09, 0x64, 0x65,
+	0x6c, 0x69, 0x76, 0x65, 0x72, 0x65, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x09, 0x64,
+	0x65, 0x6c, 0x69, 0x76, 0x65, 0x72, 0x65, 0x64, 0x12, 0x25, 0x0a, 0x0e, 0x71, 0x75, 0x65, 0x75,
+	0x65, 0x64, 0x5f, 0x6f, 0x66, 0x66, 0x6c, 0x69, 0x6e, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03,
+	0x52, 0x0d, 0x71, 0x75, 0x65, 0x75, 0x65, 0x64, 0x4f, 0x66, 0x66, 0x6c, 0x69, 0x6e, 0x65, 0x12,
+	0x16, 0x0a, 0x06, 0x66, 0x61, 0x69, 0x6c, 0x65, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x03, 0x52,
+	0x06, 0x66, 0x61, 0x69, 0x6c, 0x65, 0x64, 0x12, 0x18, 0x0a, 0x07, 0x62, 0x61, 0x74, 0x63, 0x68,
+	0x65, 0x73, 0x18, 0x04, 0x20, 0x01, 0x28, 0x03, 0x52, 0x07, 0x62, 0x61, 0x74, 0x63, 0x68, 0x65,
+	0x73, 0x12, 0x22, 0x0a, 0x0d, 0x6c, 0x61, 0x73, 0x74, 0x5f, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x5f,
+	0x61, 0x74, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x6c, 0x61, 0x73, 0x74, 0x45, 0x76,
+	0x65, 0x6e, 0x74, 0x41, 0x74, 0x12, 0x17, 0x0a, 0x07, 0x6d, 0x61, 0x78, 0x5f, 0x6c, 0x61, 0x67,
+	0x18, 0x06, 0x20, 0x01, 0x28, 0x03, 0x52, 0x06, 0x6d, 0x61, 0x78, 0x4c, 0x61, 0x67, 0x32, 0xce,
+	0x02, 0x0a, 0x0e, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63,
+	0x65, 0x12, 0x48, 0x0a, 0x0d, 0x53, 0x65, 0x6e, 0x64, 0x57, 0x53, 0x4d, 0x65, 0x73, 0x73, 0x61,
+	0x67, 0x65, 0x12, 0x1a, 0x2e, 0x72, 0x65, 0x73, 0x74, 0x2e, 0x53, 0x65, 0x6e, 0x64, 0x57, 0x53,
+	0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1b,
+	0x2e, 0x72, 0x65, 0x73, 0x74, 0x2e, 0x53, 0x65, 0x6e, 0x64, 0x57, 0x53, 0x4d, 0x65, 0x73, 0x73,
+	0x61, 0x67, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x47, 0x0a, 0x12, 0x47,
+	0x65, 0x74, 0x48, 0x69, 0x73, 0x74, 0x6f, 0x72, 0x79, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65,
+	0x73, 0x12, 0x17, 0x2e, 0x72, 0x65, 0x73, 0x74, 0x2e, 0x47, 0x65, 0x74, 0x48, 0x69, 0x73, 0x74,
+	0x6f, 0x72, 0x79, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x18, 0x2e, 0x72, 0x65, 0x73,
+	0x74, 0x2e, 0x47, 0x65, 0x74, 0x48, 0x69, 0x73, 0x74, 0x6f, 0x72, 0x79, 0x52, 0x65, 0x73, 0x70,
+	0x6f, 0x6e, 0x73, 0x65, 0x12, 0x53, 0x0a, 0x12, 0x4d, 0x61, 0x72, 0x6b, 0x4d, 0x65, 0x73, 0x73,
+	0x61, 0x67, 0x65, 0x73, 0x41, 0x73, 0x52, 0x65, 0x61, 0x64, 0x12, 0x1d, 0x2e, 0x72, 0x65, 0x73,
+	0x74, 0x2e, 0x4d, 0x61, 0x72, 0x6b, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x73, 0x52, 0x65,
+	0x61, 0x64, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1e, 0x2e, 0x72, 0x65, 0x73, 0x74,
+	0x2e, 0x4d, 0x61, 0x72, 0x6b, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x73, 0x52, 0x65, 0x61,
+	0x64, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x54, 0x0a, 0x11, 0x47, 0x65, 0x74,
+	0x44, 0x65, 0x6c, 0x69, 0x76, 0x65, 0x72, 0x79, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x1e,
+	0x2e, 0x72, 0x65, 0x73, 0x74, 0x2e, 0x47, 0x65, 0x74, 0x44, 0x65, 0x6c, 0x69, 0x76, 0x65, 0x72,
+	0x79, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1f,
+	0x2e, 0x72, 0x65, 0x73, 0x74, 0x2e, 0x47, 0x65, 0x74, 0x44, 0x65, 0x6c, 0x69, 0x76, 0x65, 0x72,
+	0x79, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x42,
+	0x08, 0x5a, 0x06, 0x2e, 0x3b, 0x72, 0x65, 0x73, 0x74, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x33,
 }
 
 var (
@@ -169,29 +572,37 @@ func file_message_grpc_proto_rawDescGZIP() []byte {
 	return file_message_grpc_proto_rawDescData
 }
 
-var file_message_grpc_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
+var file_message_grpc_proto_msgTypes = make([]protoimpl.MessageInfo, 6)
 var file_message_grpc_proto_goTypes = []interface{}{
-	(*SendWSMessageRequest)(nil),     // 0: rest.SendWSMessageRequest
-	(*SendWSMessageResponse)(nil),    // 1: rest.SendWSMessageResponse
-	(*WSMessage)(nil),                // 2: rest.WSMessage
-	(*GetHistoryRequest)(nil),        // 3: rest.GetHistoryRequest
-	(*MarkMessagesReadRequest)(nil),  // 4: rest.MarkMessagesReadRequest
-	(*GetHistoryResponse)(nil),       // 5: rest.GetHistoryResponse
-	(*MarkMessagesReadResponse)(nil), // 6: rest.MarkMessagesReadResponse
+	(*SendWSMessageRequest)(nil),      // 0: rest.SendWSMessageRequest
+	(*SendWSMessageResponse)(nil),     // 1: rest.SendWSMessageResponse
+	(*GetDeliveryStatusRequest)(nil),  // 2: rest.GetDeliveryStatusRequest
+	(*GatewayDeliveryStatus)(nil),     // 3: rest.GatewayDeliveryStatus
+	(*GetDeliveryStatusResponse)(nil), // 4: rest.GetDeliveryStatusResponse
+	(*FanoutDeliveryStat)(nil),        // 5: rest.FanoutDeliveryStat
+	(*WSMessage)(nil),                 // 6: rest.WSMessage
+	(*GetHistoryRequest)(nil),         // 7: rest.GetHistoryRequest
+	(*MarkMessagesReadRequest)(nil),   // 8: rest.MarkMessagesReadRequest
+	(*GetHistoryResponse)(nil),        // 9: rest.GetHistoryResponse
+	(*MarkMessagesReadResponse)(nil),  // 10: rest.MarkMessagesReadResponse
 }
 var file_message_grpc_proto_depIdxs = []int32{
-	2, // 0: rest.SendWSMessageRequest.msg:type_name -> rest.WSMessage
-	0, // 1: rest.MessageService.SendWSMessage:input_type -> rest.SendWSMessageRequest
-	3, // 2: rest.MessageService.GetHistoryMessages:input_type -> rest.GetHistoryRequest
-	4, // 3: rest.MessageService.MarkMessagesAsRead:input_type -> rest.MarkMessagesReadRequest
-	1, // 4: rest.MessageService.SendWSMessage:output_type -> rest.SendWSMessageResponse
-	5, // 5: rest.MessageService.GetHistoryMessages:output_type -> rest.GetHistoryResponse
-	6, // 6: rest.MessageService.MarkMessagesAsRead:output_type -> rest.MarkMessagesReadResponse
-	4, // [4:7] is the sub-list for method output_type
-	1, // [1:4] is the sub-list for method input_type
-	1, // [1:1] is the sub-list for extension type_name
-	1, // [1:1] is the sub-list for extension extendee
-	0, // [0:1] is the sub-list for field type_name
+	6,  // 0: rest.SendWSMessageRequest.msg:type_name -> rest.WSMessage
+	3,  // 1: rest.GetDeliveryStatusResponse.gateways:type_name -> rest.GatewayDeliveryStatus
+	5,  // 2: rest.GetDeliveryStatusResponse.fanout:type_name -> rest.FanoutDeliveryStat
+	0,  // 3: rest.MessageService.SendWSMessage:input_type -> rest.SendWSMessageRequest
+	7,  // 4: rest.MessageService.GetHistoryMessages:input_type -> rest.GetHistoryRequest
+	8,  // 5: rest.MessageService.MarkMessagesAsRead:input_type -> rest.MarkMessagesReadRequest
+	2,  // 6: rest.MessageService.GetDeliveryStatus:input_type -> rest.GetDeliveryStatusRequest
+	1,  // 7: rest.MessageService.SendWSMessage:output_type -> rest.SendWSMessageResponse
+	9,  // 8: rest.MessageService.GetHistoryMessages:output_type -> rest.GetHistoryResponse
+	10, // 9: rest.MessageService.MarkMessagesAsRead:output_type -> rest.MarkMessagesReadResponse
+	4,  // 10: rest.MessageService.GetDeliveryStatus:output_type -> rest.GetDeliveryStatusResponse
+	7,  // [7:11] is the sub-list for method output_type
+	3,  // [3:7] is the sub-list for method input_type
+	3,  // [3:3] is the sub-list for extension type_name
+	3,  // [3:3] is the sub-list for extension extendee
+	0,  // [0:3] is the sub-list for field type_name
 }
 
 func init() { file_message_grpc_proto_init() }
@@ -225,6 +636,54 @@ func file_message_grpc_proto_init() {
 				return nil
 			}
 		}
+		file_message_grpc_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetDeliveryStatusRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_message_grpc_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GatewayDeliveryStatus); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_message_grpc_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetDeliveryStatusResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_message_grpc_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*FanoutDeliveryStat); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
 	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
@@ -232,7 +691,7 @@ func file_message_grpc_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_message_grpc_proto_rawDesc,
 			NumEnums:      0,
-			NumMessages:   2,
+			NumMessages:   6,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
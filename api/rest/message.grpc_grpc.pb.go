@@ -22,6 +22,7 @@ const (
 	MessageService_SendWSMessage_FullMethodName      = "/rest.MessageService/SendWSMessage"
 	MessageService_GetHistoryMessages_FullMethodName = "/rest.MessageService/GetHistoryMessages"
 	MessageService_MarkMessagesAsRead_FullMethodName = "/rest.MessageService/MarkMessagesAsRead"
+	MessageService_GetDeliveryStatus_FullMethodName  = "/rest.MessageService/GetDeliveryStatus"
 )
 
 // MessageServiceClient is the client API for MessageService service.
@@ -33,6 +34,8 @@ type MessageServiceClient interface {
 	GetHistoryMessages(ctx context.Context, in *GetHistoryRequest, opts ...grpc.CallOption) (*GetHistoryResponse, error)
 	// 标记消息已读
 	MarkMessagesAsRead(ctx context.Context, in *MarkMessagesReadRequest, opts ...grpc.CallOption) (*MarkMessagesReadResponse, error)
+	// 获取投递链路状态（Connect实例订阅、推送活动、待确认积压）
+	GetDeliveryStatus(ctx context.Context, in *GetDeliveryStatusRequest, opts ...grpc.CallOption) (*GetDeliveryStatusResponse, error)
 }
 
 type messageServiceClient struct {
@@ -70,6 +73,15 @@ func (c *messageServiceClient) MarkMessagesAsRead(ctx context.Context, in *MarkM
 	return out, nil
 }
 
+func (c *messageServiceClient) GetDeliveryStatus(ctx context.Context, in *GetDeliveryStatusRequest, opts ...grpc.CallOption) (*GetDeliveryStatusResponse, error) {
+	out := new(GetDeliveryStatusResponse)
+	err := c.cc.Invoke(ctx, MessageService_GetDeliveryStatus_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // MessageServiceServer is the server API for MessageService service.
 // All implementations must embed UnimplementedMessageServiceServer
 // for forward compatibility
@@ -79,6 +91,8 @@ type MessageServiceServer interface {
 	GetHistoryMessages(context.Context, *GetHistoryRequest) (*GetHistoryResponse, error)
 	// 标记消息已读
 	MarkMessagesAsRead(context.Context, *MarkMessagesReadRequest) (*MarkMessagesReadResponse, error)
+	// 获取投递链路状态（Connect实例订阅、推送活动、待确认积压）
+	GetDeliveryStatus(context.Context, *GetDeliveryStatusRequest) (*GetDeliveryStatusResponse, error)
 	mustEmbedUnimplementedMessageServiceServer()
 }
 
@@ -95,6 +109,9 @@ func (UnimplementedMessageServiceServer) GetHistoryMessages(context.Context, *Ge
 func (UnimplementedMessageServiceServer) MarkMessagesAsRead(context.Context, *MarkMessagesReadRequest) (*MarkMessagesReadResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method MarkMessagesAsRead not implemented")
 }
+func (UnimplementedMessageServiceServer) GetDeliveryStatus(context.Context, *GetDeliveryStatusRequest) (*GetDeliveryStatusResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetDeliveryStatus not implemented")
+}
 func (UnimplementedMessageServiceServer) mustEmbedUnimplementedMessageServiceServer() {}
 
 // UnsafeMessageServiceServer may be embedded to opt out of forward compatibility for this service.
@@ -162,6 +179,24 @@ func _MessageService_MarkMessagesAsRead_Handler(srv interface{}, ctx context.Con
 	return interceptor(ctx, in, info, handler)
 }
 
+func _MessageService_GetDeliveryStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetDeliveryStatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MessageServiceServer).GetDeliveryStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MessageService_GetDeliveryStatus_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MessageServiceServer).GetDeliveryStatus(ctx, req.(*GetDeliveryStatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // MessageService_ServiceDesc is the grpc.ServiceDesc for MessageService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -181,6 +216,10 @@ var MessageService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "MarkMessagesAsRead",
 			Handler:    _MessageService_MarkMessagesAsRead_Handler,
 		},
+		{
+			MethodName: "GetDeliveryStatus",
+			Handler:    _MessageService_GetDeliveryStatus_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "message.grpc.proto",
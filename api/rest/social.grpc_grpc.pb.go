@@ -19,11 +19,13 @@ import (
 const _ = grpc.SupportPackageIsVersion7
 
 const (
-	SocialService_NotifyFriendEvent_FullMethodName   = "/rest.SocialService/NotifyFriendEvent"
-	SocialService_GetGroupMemberIDs_FullMethodName   = "/rest.SocialService/GetGroupMemberIDs"
-	SocialService_ValidateGroupMember_FullMethodName = "/rest.SocialService/ValidateGroupMember"
-	SocialService_ValidateFriendship_FullMethodName  = "/rest.SocialService/ValidateFriendship"
-	SocialService_GetUserSocialInfo_FullMethodName   = "/rest.SocialService/GetUserSocialInfo"
+	SocialService_NotifyFriendEvent_FullMethodName     = "/rest.SocialService/NotifyFriendEvent"
+	SocialService_GetGroupMemberIDs_FullMethodName     = "/rest.SocialService/GetGroupMemberIDs"
+	SocialService_ValidateGroupMember_FullMethodName   = "/rest.SocialService/ValidateGroupMember"
+	SocialService_GetMembershipPeriods_FullMethodName  = "/rest.SocialService/GetMembershipPeriods"
+	SocialService_ValidateFriendship_FullMethodName    = "/rest.SocialService/ValidateFriendship"
+	SocialService_GetUserSocialInfo_FullMethodName     = "/rest.SocialService/GetUserSocialInfo"
+	SocialService_GetActiveAnnouncement_FullMethodName = "/rest.SocialService/GetActiveAnnouncement"
 )
 
 // SocialServiceClient is the client API for SocialService service.
@@ -36,10 +38,14 @@ type SocialServiceClient interface {
 	GetGroupMemberIDs(ctx context.Context, in *GetGroupMemberIDsRequest, opts ...grpc.CallOption) (*GetGroupMemberIDsResponse, error)
 	// 验证群成员身份（用于群消息发送权限验证）
 	ValidateGroupMember(ctx context.Context, in *ValidateGroupMemberRequest, opts ...grpc.CallOption) (*ValidateGroupMemberResponse, error)
+	// 获取用户的群成员身份时段（用于按在群期间限制消息搜索）
+	GetMembershipPeriods(ctx context.Context, in *GetMembershipPeriodsRequest, opts ...grpc.CallOption) (*GetMembershipPeriodsResponse, error)
 	// 验证好友关系（用于私聊消息发送权限验证）
 	ValidateFriendship(ctx context.Context, in *ValidateFriendshipRequest, opts ...grpc.CallOption) (*ValidateFriendshipResponse, error)
 	// 获取用户社交信息汇总
 	GetUserSocialInfo(ctx context.Context, in *GetUserSocialInfoRequest, opts ...grpc.CallOption) (*GetUserSocialInfoResponse, error)
+	// 获取群当前生效的公告（用于群置顶消息和公告的统一展示）
+	GetActiveAnnouncement(ctx context.Context, in *GetActiveAnnouncementRequest, opts ...grpc.CallOption) (*GetActiveAnnouncementResponse, error)
 }
 
 type socialServiceClient struct {
@@ -77,6 +83,15 @@ func (c *socialServiceClient) ValidateGroupMember(ctx context.Context, in *Valid
 	return out, nil
 }
 
+func (c *socialServiceClient) GetMembershipPeriods(ctx context.Context, in *GetMembershipPeriodsRequest, opts ...grpc.CallOption) (*GetMembershipPeriodsResponse, error) {
+	out := new(GetMembershipPeriodsResponse)
+	err := c.cc.Invoke(ctx, SocialService_GetMembershipPeriods_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *socialServiceClient) ValidateFriendship(ctx context.Context, in *ValidateFriendshipRequest, opts ...grpc.CallOption) (*ValidateFriendshipResponse, error) {
 	out := new(ValidateFriendshipResponse)
 	err := c.cc.Invoke(ctx, SocialService_ValidateFriendship_FullMethodName, in, out, opts...)
@@ -95,6 +110,15 @@ func (c *socialServiceClient) GetUserSocialInfo(ctx context.Context, in *GetUser
 	return out, nil
 }
 
+func (c *socialServiceClient) GetActiveAnnouncement(ctx context.Context, in *GetActiveAnnouncementRequest, opts ...grpc.CallOption) (*GetActiveAnnouncementResponse, error) {
+	out := new(GetActiveAnnouncementResponse)
+	err := c.cc.Invoke(ctx, SocialService_GetActiveAnnouncement_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // SocialServiceServer is the server API for SocialService service.
 // All implementations must embed UnimplementedSocialServiceServer
 // for forward compatibility
@@ -105,10 +129,14 @@ type SocialServiceServer interface {
 	GetGroupMemberIDs(context.Context, *GetGroupMemberIDsRequest) (*GetGroupMemberIDsResponse, error)
 	// 验证群成员身份（用于群消息发送权限验证）
 	ValidateGroupMember(context.Context, *ValidateGroupMemberRequest) (*ValidateGroupMemberResponse, error)
+	// 获取用户的群成员身份时段（用于按在群期间限制消息搜索）
+	GetMembershipPeriods(context.Context, *GetMembershipPeriodsRequest) (*GetMembershipPeriodsResponse, error)
 	// 验证好友关系（用于私聊消息发送权限验证）
 	ValidateFriendship(context.Context, *ValidateFriendshipRequest) (*ValidateFriendshipResponse, error)
 	// 获取用户社交信息汇总
 	GetUserSocialInfo(context.Context, *GetUserSocialInfoRequest) (*GetUserSocialInfoResponse, error)
+	// 获取群当前生效的公告（用于群置顶消息和公告的统一展示）
+	GetActiveAnnouncement(context.Context, *GetActiveAnnouncementRequest) (*GetActiveAnnouncementResponse, error)
 	mustEmbedUnimplementedSocialServiceServer()
 }
 
@@ -125,12 +153,18 @@ func (UnimplementedSocialServiceServer) GetGroupMemberIDs(context.Context, *GetG
 func (UnimplementedSocialServiceServer) ValidateGroupMember(context.Context, *ValidateGroupMemberRequest) (*ValidateGroupMemberResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method ValidateGroupMember not implemented")
 }
+func (UnimplementedSocialServiceServer) GetMembershipPeriods(context.Context, *GetMembershipPeriodsRequest) (*GetMembershipPeriodsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetMembershipPeriods not implemented")
+}
 func (UnimplementedSocialServiceServer) ValidateFriendship(context.Context, *ValidateFriendshipRequest) (*ValidateFriendshipResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method ValidateFriendship not implemented")
 }
 func (UnimplementedSocialServiceServer) GetUserSocialInfo(context.Context, *GetUserSocialInfoRequest) (*GetUserSocialInfoResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetUserSocialInfo not implemented")
 }
+func (UnimplementedSocialServiceServer) GetActiveAnnouncement(context.Context, *GetActiveAnnouncementRequest) (*GetActiveAnnouncementResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetActiveAnnouncement not implemented")
+}
 func (UnimplementedSocialServiceServer) mustEmbedUnimplementedSocialServiceServer() {}
 
 // UnsafeSocialServiceServer may be embedded to opt out of forward compatibility for this service.
@@ -198,6 +232,24 @@ func _SocialService_ValidateGroupMember_Handler(srv interface{}, ctx context.Con
 	return interceptor(ctx, in, info, handler)
 }
 
+func _SocialService_GetMembershipPeriods_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetMembershipPeriodsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SocialServiceServer).GetMembershipPeriods(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SocialService_GetMembershipPeriods_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SocialServiceServer).GetMembershipPeriods(ctx, req.(*GetMembershipPeriodsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _SocialService_ValidateFriendship_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(ValidateFriendshipRequest)
 	if err := dec(in); err != nil {
@@ -234,6 +286,24 @@ func _SocialService_GetUserSocialInfo_Handler(srv interface{}, ctx context.Conte
 	return interceptor(ctx, in, info, handler)
 }
 
+func _SocialService_GetActiveAnnouncement_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetActiveAnnouncementRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SocialServiceServer).GetActiveAnnouncement(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SocialService_GetActiveAnnouncement_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SocialServiceServer).GetActiveAnnouncement(ctx, req.(*GetActiveAnnouncementRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // SocialService_ServiceDesc is the grpc.ServiceDesc for SocialService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -253,6 +323,10 @@ var SocialService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "ValidateGroupMember",
 			Handler:    _SocialService_ValidateGroupMember_Handler,
 		},
+		{
+			MethodName: "GetMembershipPeriods",
+			Handler:    _SocialService_GetMembershipPeriods_Handler,
+		},
 		{
 			MethodName: "ValidateFriendship",
 			Handler:    _SocialService_ValidateFriendship_Handler,
@@ -261,6 +335,10 @@ var SocialService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetUserSocialInfo",
 			Handler:    _SocialService_GetUserSocialInfo_Handler,
 		},
+		{
+			MethodName: "GetActiveAnnouncement",
+			Handler:    _SocialService_GetActiveAnnouncement_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "social.grpc.proto",
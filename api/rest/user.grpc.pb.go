@@ -24,7 +24,7 @@ var File_user_grpc_proto protoreflect.FileDescriptor
 var file_user_grpc_proto_rawDesc = []byte{
 	0x0a, 0x0f, 0x75, 0x73, 0x65, 0x72, 0x2e, 0x67, 0x72, 0x70, 0x63, 0x2e, 0x70, 0x72, 0x6f, 0x74,
 	0x6f, 0x12, 0x04, 0x72, 0x65, 0x73, 0x74, 0x1a, 0x0a, 0x75, 0x73, 0x65, 0x72, 0x2e, 0x70, 0x72,
-	0x6f, 0x74, 0x6f, 0x32, 0xb2, 0x01, 0x0a, 0x0b, 0x55, 0x73, 0x65, 0x72, 0x53, 0x65, 0x72, 0x76,
+	0x6f, 0x74, 0x6f, 0x32, 0xc6, 0x02, 0x0a, 0x0b, 0x55, 0x73, 0x65, 0x72, 0x53, 0x65, 0x72, 0x76,
 	0x69, 0x63, 0x65, 0x12, 0x30, 0x0a, 0x05, 0x4c, 0x6f, 0x67, 0x69, 0x6e, 0x12, 0x12, 0x2e, 0x72,
 	0x65, 0x73, 0x74, 0x2e, 0x4c, 0x6f, 0x67, 0x69, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
 	0x1a, 0x13, 0x2e, 0x72, 0x65, 0x73, 0x74, 0x2e, 0x4c, 0x6f, 0x67, 0x69, 0x6e, 0x52, 0x65, 0x73,
@@ -35,27 +35,44 @@ var file_user_grpc_proto_rawDesc = []byte{
 	0x12, 0x36, 0x0a, 0x07, 0x47, 0x65, 0x74, 0x55, 0x73, 0x65, 0x72, 0x12, 0x14, 0x2e, 0x72, 0x65,
 	0x73, 0x74, 0x2e, 0x47, 0x65, 0x74, 0x55, 0x73, 0x65, 0x72, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
 	0x74, 0x1a, 0x15, 0x2e, 0x72, 0x65, 0x73, 0x74, 0x2e, 0x47, 0x65, 0x74, 0x55, 0x73, 0x65, 0x72,
-	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x42, 0x08, 0x5a, 0x06, 0x2e, 0x3b, 0x72, 0x65,
-	0x73, 0x74, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x48, 0x0a, 0x0d, 0x42, 0x61, 0x74, 0x63,
+	0x68, 0x47, 0x65, 0x74, 0x55, 0x73, 0x65, 0x72, 0x73, 0x12, 0x1a, 0x2e, 0x72, 0x65, 0x73, 0x74,
+	0x2e, 0x42, 0x61, 0x74, 0x63, 0x68, 0x47, 0x65, 0x74, 0x55, 0x73, 0x65, 0x72, 0x73, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1b, 0x2e, 0x72, 0x65, 0x73, 0x74, 0x2e, 0x42, 0x61, 0x74,
+	0x63, 0x68, 0x47, 0x65, 0x74, 0x55, 0x73, 0x65, 0x72, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
+	0x73, 0x65, 0x12, 0x48, 0x0a, 0x0d, 0x47, 0x65, 0x74, 0x43, 0x6f, 0x6e, 0x74, 0x65, 0x6e, 0x74,
+	0x42, 0x61, 0x6e, 0x12, 0x1a, 0x2e, 0x72, 0x65, 0x73, 0x74, 0x2e, 0x47, 0x65, 0x74, 0x43, 0x6f,
+	0x6e, 0x74, 0x65, 0x6e, 0x74, 0x42, 0x61, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a,
+	0x1b, 0x2e, 0x72, 0x65, 0x73, 0x74, 0x2e, 0x47, 0x65, 0x74, 0x43, 0x6f, 0x6e, 0x74, 0x65, 0x6e,
+	0x74, 0x42, 0x61, 0x6e, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x42, 0x08, 0x5a, 0x06,
+	0x2e, 0x3b, 0x72, 0x65, 0x73, 0x74, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
 }
 
 var file_user_grpc_proto_goTypes = []interface{}{
-	(*LoginRequest)(nil),     // 0: rest.LoginRequest
-	(*RegisterRequest)(nil),  // 1: rest.RegisterRequest
-	(*GetUserRequest)(nil),   // 2: rest.GetUserRequest
-	(*LoginResponse)(nil),    // 3: rest.LoginResponse
-	(*RegisterResponse)(nil), // 4: rest.RegisterResponse
-	(*GetUserResponse)(nil),  // 5: rest.GetUserResponse
+	(*LoginRequest)(nil),          // 0: rest.LoginRequest
+	(*RegisterRequest)(nil),       // 1: rest.RegisterRequest
+	(*GetUserRequest)(nil),        // 2: rest.GetUserRequest
+	(*BatchGetUsersRequest)(nil),  // 3: rest.BatchGetUsersRequest
+	(*GetContentBanRequest)(nil),  // 4: rest.GetContentBanRequest
+	(*LoginResponse)(nil),         // 5: rest.LoginResponse
+	(*RegisterResponse)(nil),      // 6: rest.RegisterResponse
+	(*GetUserResponse)(nil),       // 7: rest.GetUserResponse
+	(*BatchGetUsersResponse)(nil), // 8: rest.BatchGetUsersResponse
+	(*GetContentBanResponse)(nil), // 9: rest.GetContentBanResponse
 }
 var file_user_grpc_proto_depIdxs = []int32{
 	0, // 0: rest.UserService.Login:input_type -> rest.LoginRequest
 	1, // 1: rest.UserService.Register:input_type -> rest.RegisterRequest
 	2, // 2: rest.UserService.GetUser:input_type -> rest.GetUserRequest
-	3, // 3: rest.UserService.Login:output_type -> rest.LoginResponse
-	4, // 4: rest.UserService.Register:output_type -> rest.RegisterResponse
-	5, // 5: rest.UserService.GetUser:output_type -> rest.GetUserResponse
-	3, // [3:6] is the sub-list for method output_type
-	0, // [0:3] is the sub-list for method input_type
+	3, // 3: rest.UserService.BatchGetUsers:input_type -> rest.BatchGetUsersRequest
+	4, // 4: rest.UserService.GetContentBan:input_type -> rest.GetContentBanRequest
+	5, // 5: rest.UserService.Login:output_type -> rest.LoginResponse
+	6, // 6: rest.UserService.Register:output_type -> rest.RegisterResponse
+	7, // 7: rest.UserService.GetUser:output_type -> rest.GetUserResponse
+	8, // 8: rest.UserService.BatchGetUsers:output_type -> rest.BatchGetUsersResponse
+	9, // 9: rest.UserService.GetContentBan:output_type -> rest.GetContentBanResponse
+	5, // [5:10] is the sub-list for method output_type
+	0, // [0:5] is the sub-list for method input_type
 	0, // [0:0] is the sub-list for extension type_name
 	0, // [0:0] is the sub-list for extension extendee
 	0, // [0:0] is the sub-list for field type_name
@@ -4,7 +4,6 @@ import (
 	"context"
 	"log"
 	"os"
-	"time"
 
 	"github.com/gin-gonic/gin"
 	"google.golang.org/grpc"
@@ -39,7 +38,7 @@ func main() {
 
 	// 确保在程序退出时关闭OpenTelemetry
 	defer func() {
-		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		ctx, cancel := context.WithTimeout(context.Background(), otelConfig.ShutdownTimeout)
 		defer cancel()
 		if err := telemetry.ShutdownGlobal(ctx); err != nil {
 			log.Printf("Failed to shutdown OpenTelemetry: %v", err)
@@ -23,6 +23,8 @@ import (
 	"goim-social/pkg/discovery"
 	"goim-social/pkg/kafka"
 	"goim-social/pkg/logger"
+	"goim-social/pkg/middleware"
+	"goim-social/pkg/presence"
 	"goim-social/pkg/redis"
 	"goim-social/pkg/telemetry"
 )
@@ -61,7 +63,7 @@ func NewService(db *database.MongoDB, redis *redis.RedisClient, kafka *kafka.Pro
 
 	if instance, err := k8sDiscovery.GetServiceInstance("im-gateway-service"); err == nil {
 		imGatewayAddr := fmt.Sprintf("%s:%d", instance.Host, instance.GRPCPort)
-		conn, err := grpc.NewClient(imGatewayAddr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+		conn, err := grpc.NewClient(imGatewayAddr, grpc.WithTransportCredentials(insecure.NewCredentials()), middleware.DeadlineDialOption(cfg.Deadline))
 		if err != nil {
 			logger.Error(context.Background(), "Failed to connect to IM Gateway")
 		} else {
@@ -204,8 +206,7 @@ func (s *Service) OnlineStatus(ctx context.Context, userIDs []int64) (map[int64]
 
 	for _, uid := range userIDs {
 		// 查询Redis中的用户连接信息
-		pattern := fmt.Sprintf("conn:%d:*", uid)
-		keys, err := s.redis.Keys(ctx, pattern)
+		keys, err := presence.ConnectionKeys(ctx, s.redis, uid)
 		if err != nil {
 			log.Printf("查询用户 %d 连接信息失败: %v", uid, err)
 			status[uid] = false
@@ -231,10 +232,9 @@ func (s *Service) OnlineStatus(ctx context.Context, userIDs []int64) (map[int64]
 
 // GetUserConnections 获取用户的所有连接信息
 func (s *Service) GetUserConnections(ctx context.Context, userID int64) ([]*model.Connection, error) {
-	pattern := fmt.Sprintf("conn:%d:*", userID)
-	keys, err := s.redis.Keys(ctx, pattern)
+	keys, err := presence.ConnectionKeys(ctx, s.redis, userID)
 	if err != nil {
-		return nil, fmt.Errorf("查询用户连接失败: %v", err)
+		return nil, err
 	}
 
 	var connections []*model.Connection
@@ -2,19 +2,29 @@ package main
 
 import (
 	"context"
+	"fmt"
 	"log"
 	"os"
-	"time"
 
 	"github.com/gin-gonic/gin"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
 
 	"goim-social/api/rest"
+	"goim-social/apps/content-service/internal/consumer"
 	"goim-social/apps/content-service/internal/dao"
 	"goim-social/apps/content-service/internal/handler"
 	"goim-social/apps/content-service/internal/model"
+	"goim-social/apps/content-service/internal/moderation"
 	"goim-social/apps/content-service/internal/service"
+	"goim-social/apps/content-service/internal/storage"
+	"goim-social/pkg/audit"
+	"goim-social/pkg/editwindow"
+	"goim-social/pkg/kafka"
 	"goim-social/pkg/middleware"
+	"goim-social/pkg/outbox"
+	"goim-social/pkg/pagination"
+	"goim-social/pkg/profanity"
 	"goim-social/pkg/server"
 	"goim-social/pkg/telemetry"
 )
@@ -38,7 +48,7 @@ func main() {
 
 	// 确保在程序退出时关闭OpenTelemetry
 	defer func() {
-		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		ctx, cancel := context.WithTimeout(context.Background(), otelConfig.ShutdownTimeout)
 		defer cancel()
 		if err := telemetry.ShutdownGlobal(ctx); err != nil {
 			log.Printf("Failed to shutdown OpenTelemetry: %v", err)
@@ -63,15 +73,21 @@ func main() {
 	// 自动迁移数据库表结构（包含评论和互动表）
 	if err := postgreSQL.AutoMigrate(
 		&model.Content{},
+		&model.ContentProfilePin{}, // 个人主页置顶表
 		&model.ContentMediaFile{},
 		&model.ContentTag{},
 		&model.ContentTopic{},
+		&model.ContentCategory{},
 		&model.ContentTagRelation{},
 		&model.ContentTopicRelation{},
 		&model.ContentStatusLog{},
-		&model.Comment{},          // 评论表
-		&model.Interaction{},      // 互动表
-		&model.InteractionStats{}, // 互动统计表
+		&model.Comment{},              // 评论表
+		&model.Interaction{},          // 互动表
+		&model.InteractionStats{},     // 互动统计表
+		&model.Report{},               // 举报表
+		&model.CommentModerationLog{}, // 评论审核日志表
+		&model.ContentMention{},       // @提及表
+		&outbox.Event{},               // 发件箱表
 	); err != nil {
 		panic("Failed to migrate database: " + err.Error())
 	}
@@ -79,8 +95,90 @@ func main() {
 	// 初始化DAO层
 	contentDAO := dao.NewContentDAO(postgreSQL)
 
+	// 初始化发布审核器（未启用时不审核）
+	cfg := app.GetConfig()
+	moderator, err := moderation.NewFromConfig(cfg.Content.Moderation)
+	if err != nil {
+		log.Fatalf("Failed to initialize content moderator: %v", err)
+	}
+
+	// 初始化违禁词过滤器（未启用时不过滤），词表文件变更后自动重新加载
+	filter, err := profanity.New(cfg.Profanity)
+	if err != nil {
+		log.Fatalf("Failed to initialize profanity filter: %v", err)
+	}
+	defer filter.Close()
+
+	// 初始化媒体上传预签名器（未启用时不支持媒体上传）
+	presigner, err := storage.NewFromConfig(cfg.Content.Media)
+	if err != nil {
+		log.Fatalf("Failed to initialize media storage: %v", err)
+	}
+
+	// 初始化审计记录器，审核和管理操作异步写入共享审计记录，退出前写完队列
+	auditor := audit.NewRecorder(audit.NewStore(app.GetRedisClient(), cfg.Audit.RetentionDays), serviceName)
+	app.RegisterShutdownHook("audit", auditor.Close)
+
+	// 初始化逻辑服务客户端（分享内容到站内会话时发送链接消息）
+	logicAddr := fmt.Sprintf("%s:%d", cfg.Services.LogicService.Host, cfg.Services.LogicService.Port)
+	logicConn, err := grpc.NewClient(logicAddr, grpc.WithTransportCredentials(insecure.NewCredentials()), middleware.DeadlineDialOption(cfg.Deadline))
+	if err != nil {
+		log.Fatalf("Failed to connect to logic service: %v", err)
+	}
+	defer logicConn.Close()
+
+	// 初始化用户服务客户端（解析内容和评论中的@提及）
+	userAddr := fmt.Sprintf("%s:%d", cfg.Services.UserService.Host, cfg.Services.UserService.Port)
+	userConn, err := grpc.NewClient(userAddr, grpc.WithTransportCredentials(insecure.NewCredentials()), middleware.DeadlineDialOption(cfg.Deadline))
+	if err != nil {
+		log.Fatalf("Failed to connect to user service: %v", err)
+	}
+	defer userConn.Close()
+
+	// 初始化社交服务客户端（校验好友关系以判断好友可见内容）
+	socialAddr := fmt.Sprintf("%s:%d", cfg.Services.SocialService.Host, cfg.Services.SocialService.Port)
+	socialConn, err := grpc.NewClient(socialAddr, grpc.WithTransportCredentials(insecure.NewCredentials()), middleware.DeadlineDialOption(cfg.Deadline))
+	if err != nil {
+		log.Fatalf("Failed to connect to social service: %v", err)
+	}
+	defer socialConn.Close()
+
+	// 启动发件箱转发器，评论、互动和内容删除事件与业务数据在同一事务中写入发件箱，由转发器发布到Kafka
+	relay := outbox.NewRelay(postgreSQL.GetDB(), serviceName, func() (outbox.Publisher, error) {
+		return kafka.InitReliableProducer(cfg.Kafka.Brokers)
+	}, cfg.Outbox)
+	relay.Start(context.Background())
+	app.RegisterShutdownHook("outbox-relay", relay.Close)
+
 	// 初始化Service层
-	svc := service.NewService(contentDAO, app.GetRedisClient(), app.GetKafkaProducer(), app.GetLogger())
+	svc := service.NewService(contentDAO, app.GetRedisClient(), app.GetKafkaProducer(), relay, app.GetLogger(), cfg.Content, moderator, filter, presigner, auditor, rest.NewLogicServiceClient(logicConn), rest.NewUserServiceClient(userConn), rest.NewSocialServiceClient(socialConn), pagination.New(cfg.Pagination), editwindow.New(cfg.EditWindow))
+
+	// 初始化默认分类，并将历史未分类内容归入默认分类
+	if err := svc.InitCategories(context.Background()); err != nil {
+		log.Fatalf("Failed to initialize content categories: %v", err)
+	}
+
+	// 此前被违禁词拦截的评论进入复核队列
+	if err := svc.InitHeldComments(context.Background()); err != nil {
+		log.Fatalf("Failed to initialize held comments: %v", err)
+	}
+
+	// 启动互动统计缓存对账任务、浏览增量写库任务和标签话题热度衰减任务
+	go svc.StartStatsReconciler(context.Background())
+	go svc.StartViewCountFlusher(context.Background())
+	go svc.StartTrendingDecayer(context.Background())
+
+	// 启动内容删除消费者，清理已删除内容的评论、互动统计和标签话题热度
+	deletionConsumer := consumer.NewDeletionConsumer(svc)
+	go func() {
+		log.Println("启动内容删除消费者...")
+		if err := deletionConsumer.Start(context.Background(), cfg.Kafka.Brokers); err != nil {
+			log.Printf("Failed to start content deletion consumer: %v", err)
+		}
+	}()
+	app.RegisterShutdownHook("content-deletion-consumer", func(ctx context.Context) error {
+		return deletionConsumer.Stop()
+	})
 
 	// 初始化Handler
 	httpHandler := handler.NewHTTPHandler(svc, app.GetLogger())
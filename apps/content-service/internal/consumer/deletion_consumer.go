@@ -0,0 +1,81 @@
+package consumer
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+
+	"github.com/IBM/sarama"
+
+	"goim-social/apps/content-service/internal/model"
+	"goim-social/pkg/kafka"
+)
+
+// DeletedContentCleaner 清理已删除内容的关联数据
+type DeletedContentCleaner interface {
+	CleanupDeletedContent(ctx context.Context, event *model.ContentDeletedEvent) error
+}
+
+// DeletionConsumer 内容删除消费者
+// 职责：消费内容删除事件，隐藏评论、删除互动记录和统计、扣除标签话题热度。
+// 清理可重复执行，事件重放是安全的
+type DeletionConsumer struct {
+	consumer *kafka.Consumer
+	cleaner  DeletedContentCleaner
+}
+
+// NewDeletionConsumer 创建内容删除消费者
+func NewDeletionConsumer(cleaner DeletedContentCleaner) *DeletionConsumer {
+	return &DeletionConsumer{cleaner: cleaner}
+}
+
+// Start 启动内容删除消费者
+func (d *DeletionConsumer) Start(ctx context.Context, brokers []string) error {
+	cfg := kafka.KafkaConfig{
+		Brokers: brokers,
+		GroupID: "content-deletion-cleanup-group",
+		Topics:  []string{model.TopicContentDeleted},
+	}
+
+	consumer, err := kafka.InitConsumer(cfg, d)
+	if err != nil {
+		return err
+	}
+
+	d.consumer = consumer
+	log.Printf("内容删除消费者启动成功，监听topic: %s", model.TopicContentDeleted)
+
+	return d.consumer.StartConsuming(ctx)
+}
+
+// Stop 停止消费
+func (d *DeletionConsumer) Stop() error {
+	if d.consumer != nil {
+		return d.consumer.Close()
+	}
+	return nil
+}
+
+// HandleMessage 实现 kafka.ConsumerHandler 接口
+func (d *DeletionConsumer) HandleMessage(msg *sarama.ConsumerMessage) error {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("内容删除消费者处理消息时发生panic: %v", r)
+		}
+	}()
+
+	var event model.ContentDeletedEvent
+	if err := json.Unmarshal(msg.Value, &event); err != nil {
+		log.Printf("解析内容删除事件失败: %v", err)
+		return nil // 返回nil避免重试
+	}
+	if event.ContentID == 0 {
+		return nil
+	}
+
+	if err := d.cleaner.CleanupDeletedContent(context.Background(), &event); err != nil {
+		log.Printf("清理已删除内容失败: ContentID=%d, Error=%v", event.ContentID, err)
+		return err
+	}
+	return nil
+}
@@ -5,6 +5,9 @@ import (
 
 	"goim-social/api/rest"
 	"goim-social/apps/content-service/internal/model"
+	"goim-social/pkg/editwindow"
+	"goim-social/pkg/httpx"
+	"goim-social/pkg/utils"
 )
 
 // Converter 转换器，提供Model到Protobuf的转换
@@ -53,6 +56,12 @@ func (c *Converter) ContentModelToProto(content *model.Content) *rest.Content {
 		publishedAt = content.PublishedAt.Format(time.RFC3339)
 	}
 
+	// 转换置顶时间
+	var pinnedAt string
+	if content.PinnedAt != nil {
+		pinnedAt = content.PinnedAt.Format(time.RFC3339)
+	}
+
 	return &rest.Content{
 		Id:           content.ID,
 		AuthorId:     content.AuthorID,
@@ -68,7 +77,46 @@ func (c *Converter) ContentModelToProto(content *model.Content) *rest.Content {
 		CreatedAt:    content.CreatedAt.Format(time.RFC3339),
 		UpdatedAt:    content.UpdatedAt.Format(time.RFC3339),
 		PublishedAt:  publishedAt,
+		CategoryId:   content.CategoryID,
+		Mentions:     c.MentionModelsToProto(content.Mentions),
+		Visibility:   content.Visibility,
+		Pinned:       content.PinnedAt != nil,
+		PinnedAt:     pinnedAt,
+		Summary:      content.Summary,
+		Excerpt:      contentExcerpt(content),
+	}
+}
+
+// ContentPreviewToProto 将内容Model转换为列表中的预览，只保留摘要，不返回正文
+func (c *Converter) ContentPreviewToProto(content *model.Content) *rest.Content {
+	preview := c.ContentModelToProto(content)
+	if preview != nil {
+		preview.Content = ""
+	}
+	return preview
+}
+
+// contentExcerpt 内容的列表摘要，生成摘要之前保存的内容没有摘要时按默认长度生成
+func contentExcerpt(content *model.Content) string {
+	if content.Excerpt != "" {
+		return content.Excerpt
+	}
+	if content.Summary != "" {
+		return utils.Excerpt(content.Summary, model.DefaultSummaryMaxLength)
+	}
+	return utils.Excerpt(content.Content, model.DefaultExcerptLength)
+}
+
+// MentionModelsToProto 将提及Model列表转换为Protobuf列表
+func (c *Converter) MentionModelsToProto(mentions []*model.ContentMention) []*rest.Mention {
+	result := make([]*rest.Mention, 0, len(mentions))
+	for _, mention := range mentions {
+		result = append(result, &rest.Mention{
+			UserId:   mention.UserID,
+			Username: mention.Username,
+		})
 	}
+	return result
 }
 
 // ContentModelsToProto 将内容Model列表转换为Protobuf列表
@@ -301,6 +349,41 @@ func (c *Converter) BuildPublishContentResponse(success bool, message string, co
 	}
 }
 
+// BuildPinContentToProfileResponse 构建置顶内容到个人主页响应
+func (c *Converter) BuildPinContentToProfileResponse(success bool, message string, content *model.Content) *rest.PinContentToProfileResponse {
+	return &rest.PinContentToProfileResponse{
+		Success: success,
+		Message: message,
+		Content: c.ContentModelToProto(content),
+	}
+}
+
+// BuildUnpinContentFromProfileResponse 构建取消个人主页置顶响应
+func (c *Converter) BuildUnpinContentFromProfileResponse(success bool, message string) *rest.UnpinContentFromProfileResponse {
+	return &rest.UnpinContentFromProfileResponse{
+		Success: success,
+		Message: message,
+	}
+}
+
+// BuildGetEditWindowsResponse 构建获取编辑时限响应
+func (c *Converter) BuildGetEditWindowsResponse(success bool, message string, windows []editwindow.Window, serverTime time.Time) *rest.GetEditWindowsResponse {
+	windowProtos := make([]*rest.EditWindow, len(windows))
+	for i, window := range windows {
+		windowProtos[i] = &rest.EditWindow{
+			Kind:        window.Kind,
+			ContentType: window.ContentType,
+			Seconds:     int64(window.Duration / time.Second),
+		}
+	}
+	return &rest.GetEditWindowsResponse{
+		Success:    success,
+		Message:    message,
+		Windows:    windowProtos,
+		ServerTime: serverTime.UnixMilli(),
+	}
+}
+
 // BuildChangeContentStatusResponse 构建变更内容状态响应
 func (c *Converter) BuildChangeContentStatusResponse(success bool, message string, content *model.Content) *rest.ChangeContentStatusResponse {
 	return &rest.ChangeContentStatusResponse{
@@ -311,17 +394,41 @@ func (c *Converter) BuildChangeContentStatusResponse(success bool, message strin
 }
 
 // BuildGetUserContentResponse 构建获取用户内容响应
-func (c *Converter) BuildGetUserContentResponse(success bool, message string, contents []*model.Content, total int64, page, pageSize int32) *rest.GetUserContentResponse {
+func (c *Converter) BuildGetUserContentResponse(success bool, message string, contents []*model.Content, total int64, page, pageSize int32, nextCursor string) *rest.GetUserContentResponse {
 	return &rest.GetUserContentResponse{
-		Success:  success,
-		Message:  message,
-		Contents: c.ContentModelsToProto(contents),
-		Total:    total,
-		Page:     page,
-		PageSize: pageSize,
+		Success:    success,
+		Message:    message,
+		Contents:   c.ContentModelsToProto(contents),
+		Total:      total,
+		Page:       page,
+		PageSize:   pageSize,
+		NextCursor: nextCursor,
+		Pagination: httpx.CursorPage(page, pageSize, total, nextCursor).Proto(),
 	}
 }
 
+// UserContentQueryFromProto 将获取用户内容请求转换为查询条件
+func (c *Converter) UserContentQueryFromProto(req *rest.GetUserContentRequest) *model.UserContentQuery {
+	query := &model.UserContentQuery{
+		AuthorID: req.AuthorId,
+		ViewerID: req.ViewerId,
+		Status:   c.ContentStatusFromProto(req.Status),
+		TagID:    req.TagId,
+		TopicID:  req.TopicId,
+		SortBy:   req.SortBy,
+	}
+	if req.Type != rest.ContentType_CONTENT_TYPE_UNSPECIFIED {
+		query.Type = c.ContentTypeFromProto(req.Type)
+	}
+	if req.StartTime > 0 {
+		query.StartTime = time.Unix(req.StartTime, 0)
+	}
+	if req.EndTime > 0 {
+		query.EndTime = time.Unix(req.EndTime, 0)
+	}
+	return query
+}
+
 // BuildGetContentStatsResponse 构建获取内容统计响应
 func (c *Converter) BuildGetContentStatsResponse(success bool, message string, stats *model.ContentStats) *rest.GetContentStatsResponse {
 	if !success || stats == nil {
@@ -359,12 +466,13 @@ func (c *Converter) BuildCreateTagResponse(success bool, message string, tag *mo
 }
 
 // BuildGetTagsResponse 构建获取标签列表响应
-func (c *Converter) BuildGetTagsResponse(success bool, message string, tags []*model.ContentTag, total int64) *rest.GetTagsResponse {
+func (c *Converter) BuildGetTagsResponse(success bool, message string, tags []*model.ContentTag, total int64, page, pageSize int32) *rest.GetTagsResponse {
 	return &rest.GetTagsResponse{
-		Success: success,
-		Message: message,
-		Tags:    c.TagModelsToProto(tags),
-		Total:   total,
+		Success:    success,
+		Message:    message,
+		Tags:       c.TagModelsToProto(tags),
+		Total:      total,
+		Pagination: httpx.OffsetPage(page, pageSize, total).Proto(),
 	}
 }
 
@@ -378,12 +486,45 @@ func (c *Converter) BuildCreateTopicResponse(success bool, message string, topic
 }
 
 // BuildGetTopicsResponse 构建获取话题列表响应
-func (c *Converter) BuildGetTopicsResponse(success bool, message string, topics []*model.ContentTopic, total int64) *rest.GetTopicsResponse {
+func (c *Converter) BuildGetTopicsResponse(success bool, message string, topics []*model.ContentTopic, total int64, page, pageSize int32) *rest.GetTopicsResponse {
 	return &rest.GetTopicsResponse{
+		Success:    success,
+		Message:    message,
+		Topics:     c.TopicModelsToProto(topics),
+		Total:      total,
+		Pagination: httpx.OffsetPage(page, pageSize, total).Proto(),
+	}
+}
+
+// BuildGetTrendingTagsResponse 构建获取热门标签响应
+func (c *Converter) BuildGetTrendingTagsResponse(success bool, message string, tags []*model.TrendingTag) *rest.GetTrendingTagsResponse {
+	protoTags := make([]*rest.TrendingTag, 0, len(tags))
+	for _, tag := range tags {
+		protoTags = append(protoTags, &rest.TrendingTag{
+			Tag:   c.TagModelToProto(tag.Tag),
+			Score: tag.Score,
+		})
+	}
+	return &rest.GetTrendingTagsResponse{
 		Success: success,
 		Message: message,
-		Topics:  c.TopicModelsToProto(topics),
-		Total:   total,
+		Tags:    protoTags,
+	}
+}
+
+// BuildGetTrendingTopicsResponse 构建获取热门话题响应
+func (c *Converter) BuildGetTrendingTopicsResponse(success bool, message string, topics []*model.TrendingTopic) *rest.GetTrendingTopicsResponse {
+	protoTopics := make([]*rest.TrendingTopic, 0, len(topics))
+	for _, topic := range topics {
+		protoTopics = append(protoTopics, &rest.TrendingTopic{
+			Topic: c.TopicModelToProto(topic.Topic),
+			Score: topic.Score,
+		})
+	}
+	return &rest.GetTrendingTopicsResponse{
+		Success: success,
+		Message: message,
+		Topics:  protoTopics,
 	}
 }
 
@@ -414,6 +555,16 @@ func (c *Converter) BuildErrorPublishContentResponse(message string) *rest.Publi
 	return c.BuildPublishContentResponse(false, message, nil)
 }
 
+// BuildErrorPinContentToProfileResponse 构建置顶内容到个人主页错误响应
+func (c *Converter) BuildErrorPinContentToProfileResponse(message string) *rest.PinContentToProfileResponse {
+	return c.BuildPinContentToProfileResponse(false, message, nil)
+}
+
+// BuildErrorUnpinContentFromProfileResponse 构建取消个人主页置顶错误响应
+func (c *Converter) BuildErrorUnpinContentFromProfileResponse(message string) *rest.UnpinContentFromProfileResponse {
+	return c.BuildUnpinContentFromProfileResponse(false, message)
+}
+
 // BuildErrorChangeContentStatusResponse 构建变更内容状态错误响应
 func (c *Converter) BuildErrorChangeContentStatusResponse(message string) *rest.ChangeContentStatusResponse {
 	return c.BuildChangeContentStatusResponse(false, message, nil)
@@ -421,7 +572,7 @@ func (c *Converter) BuildErrorChangeContentStatusResponse(message string) *rest.
 
 // BuildErrorGetUserContentResponse 构建获取用户内容错误响应
 func (c *Converter) BuildErrorGetUserContentResponse(message string) *rest.GetUserContentResponse {
-	return c.BuildGetUserContentResponse(false, message, nil, 0, 0, 0)
+	return c.BuildGetUserContentResponse(false, message, nil, 0, 0, 0, "")
 }
 
 // BuildErrorGetContentStatsResponse 构建获取内容统计错误响应
@@ -436,7 +587,7 @@ func (c *Converter) BuildErrorCreateTagResponse(message string) *rest.CreateTagR
 
 // BuildErrorGetTagsResponse 构建获取标签列表错误响应
 func (c *Converter) BuildErrorGetTagsResponse(message string) *rest.GetTagsResponse {
-	return c.BuildGetTagsResponse(false, message, nil, 0)
+	return c.BuildGetTagsResponse(false, message, nil, 0, 0, 0)
 }
 
 // BuildErrorCreateTopicResponse 构建创建话题错误响应
@@ -446,7 +597,17 @@ func (c *Converter) BuildErrorCreateTopicResponse(message string) *rest.CreateTo
 
 // BuildErrorGetTopicsResponse 构建获取话题列表错误响应
 func (c *Converter) BuildErrorGetTopicsResponse(message string) *rest.GetTopicsResponse {
-	return c.BuildGetTopicsResponse(false, message, nil, 0)
+	return c.BuildGetTopicsResponse(false, message, nil, 0, 0, 0)
+}
+
+// BuildErrorGetTrendingTagsResponse 构建获取热门标签错误响应
+func (c *Converter) BuildErrorGetTrendingTagsResponse(message string) *rest.GetTrendingTagsResponse {
+	return c.BuildGetTrendingTagsResponse(false, message, nil)
+}
+
+// BuildErrorGetTrendingTopicsResponse 构建获取热门话题错误响应
+func (c *Converter) BuildErrorGetTrendingTopicsResponse(message string) *rest.GetTrendingTopicsResponse {
+	return c.BuildGetTrendingTopicsResponse(false, message, nil)
 }
 
 // ==================== 评论相关转换方法 ====================
@@ -476,6 +637,7 @@ func (c *Converter) CommentModelToProto(comment *model.Comment) *rest.Comment {
 		IsHot:           comment.IsHot,
 		CreatedAt:       comment.CreatedAt.Format(time.RFC3339),
 		UpdatedAt:       comment.UpdatedAt.Format(time.RFC3339),
+		Mentions:        c.MentionModelsToProto(comment.Mentions),
 	}
 }
 
@@ -504,6 +666,10 @@ func (c *Converter) stringToCommentStatus(status string) rest.CommentStatus {
 		return rest.CommentStatus_COMMENT_STATUS_REJECTED
 	case "deleted":
 		return rest.CommentStatus_COMMENT_STATUS_DELETED
+	case "hidden":
+		return rest.CommentStatus_COMMENT_STATUS_HIDDEN
+	case "held":
+		return rest.CommentStatus_COMMENT_STATUS_HELD
 	default:
 		return rest.CommentStatus_COMMENT_STATUS_UNSPECIFIED
 	}
@@ -541,7 +707,7 @@ func (c *Converter) BuildDeleteCommentResponse(success bool, message string) *re
 }
 
 // BuildGetCommentsResponse 构建获取评论列表响应
-func (c *Converter) BuildGetCommentsResponse(success bool, message string, comments []*model.Comment, total int64) *rest.GetCommentsResponse {
+func (c *Converter) BuildGetCommentsResponse(success bool, message string, comments []*model.Comment, total int64, page, pageSize int32) *rest.GetCommentsResponse {
 	var commentProtos []*rest.Comment
 	if comments != nil {
 		commentProtos = make([]*rest.Comment, len(comments))
@@ -551,15 +717,18 @@ func (c *Converter) BuildGetCommentsResponse(success bool, message string, comme
 	}
 
 	return &rest.GetCommentsResponse{
-		Success:  success,
-		Message:  message,
-		Comments: commentProtos,
-		Total:    total,
+		Success:    success,
+		Message:    message,
+		Comments:   commentProtos,
+		Total:      total,
+		Page:       page,
+		PageSize:   pageSize,
+		Pagination: httpx.OffsetPage(page, pageSize, total).Proto(),
 	}
 }
 
 // BuildGetCommentRepliesResponse 构建获取评论回复响应
-func (c *Converter) BuildGetCommentRepliesResponse(success bool, message string, replies []*model.Comment, total int64) *rest.GetCommentRepliesResponse {
+func (c *Converter) BuildGetCommentRepliesResponse(success bool, message string, replies []*model.Comment, total int64, page, pageSize int32) *rest.GetCommentRepliesResponse {
 	var replyProtos []*rest.Comment
 	if replies != nil {
 		replyProtos = make([]*rest.Comment, len(replies))
@@ -569,10 +738,13 @@ func (c *Converter) BuildGetCommentRepliesResponse(success bool, message string,
 	}
 
 	return &rest.GetCommentRepliesResponse{
-		Success: success,
-		Message: message,
-		Replies: replyProtos,
-		Total:   total,
+		Success:    success,
+		Message:    message,
+		Replies:    replyProtos,
+		Total:      total,
+		Page:       page,
+		PageSize:   pageSize,
+		Pagination: httpx.OffsetPage(page, pageSize, total).Proto(),
 	}
 }
 
@@ -586,11 +758,11 @@ func (c *Converter) BuildErrorDeleteCommentResponse(message string) *rest.Delete
 }
 
 func (c *Converter) BuildErrorGetCommentsResponse(message string) *rest.GetCommentsResponse {
-	return c.BuildGetCommentsResponse(false, message, nil, 0)
+	return c.BuildGetCommentsResponse(false, message, nil, 0, 0, 0)
 }
 
 func (c *Converter) BuildErrorGetCommentRepliesResponse(message string) *rest.GetCommentRepliesResponse {
-	return c.BuildGetCommentRepliesResponse(false, message, nil, 0)
+	return c.BuildGetCommentRepliesResponse(false, message, nil, 0, 0, 0)
 }
 
 // ==================== 互动相关转换方法 ====================
@@ -607,6 +779,7 @@ func (c *Converter) InteractionModelToProto(interaction *model.Interaction) *res
 		TargetId:        interaction.TargetID,
 		TargetType:      c.stringToTargetType(interaction.TargetType),
 		InteractionType: c.stringToInteractionType(interaction.InteractionType),
+		ReactionKey:     interaction.ReactionKey,
 		Metadata:        interaction.Metadata,
 		CreatedAt:       interaction.CreatedAt.Format(time.RFC3339),
 		UpdatedAt:       interaction.UpdatedAt.Format(time.RFC3339),
@@ -620,12 +793,13 @@ func (c *Converter) InteractionStatsModelToProto(stats *model.InteractionStats)
 	}
 
 	return &rest.InteractionStats{
-		TargetId:      stats.TargetID,
-		TargetType:    c.stringToTargetType(stats.TargetType),
-		LikeCount:     stats.LikeCount,
-		FavoriteCount: stats.FavoriteCount,
-		ShareCount:    stats.ShareCount,
-		RepostCount:   stats.RepostCount,
+		TargetId:       stats.TargetID,
+		TargetType:     c.stringToTargetType(stats.TargetType),
+		LikeCount:      stats.LikeCount,
+		FavoriteCount:  stats.FavoriteCount,
+		ShareCount:     stats.ShareCount,
+		RepostCount:    stats.RepostCount,
+		ReactionCounts: stats.ReactionCounts,
 	}
 }
 
@@ -640,6 +814,8 @@ func (c *Converter) stringToInteractionType(interactionType string) rest.Interac
 		return rest.InteractionType_INTERACTION_TYPE_SHARE
 	case "repost":
 		return rest.InteractionType_INTERACTION_TYPE_REPOST
+	case "reaction":
+		return rest.InteractionType_INTERACTION_TYPE_REACTION
 	default:
 		return rest.InteractionType_INTERACTION_TYPE_UNSPECIFIED
 	}
@@ -656,6 +832,8 @@ func (c *Converter) InteractionTypeToString(interactionType rest.InteractionType
 		return "share"
 	case rest.InteractionType_INTERACTION_TYPE_REPOST:
 		return "repost"
+	case rest.InteractionType_INTERACTION_TYPE_REACTION:
+		return "reaction"
 	default:
 		return ""
 	}
@@ -714,6 +892,44 @@ func (c *Converter) BuildErrorGetInteractionStatsResponse(message string) *rest.
 	return c.BuildGetInteractionStatsResponse(false, message, nil)
 }
 
+// ShareTargetProtoToModel 将分享目的地Protobuf转换为Model，未指定时返回nil
+func (c *Converter) ShareTargetProtoToModel(target *rest.ShareTarget) *model.ShareTarget {
+	if target == nil {
+		return nil
+	}
+	return &model.ShareTarget{
+		Type:    target.Type,
+		ID:      target.Id,
+		Channel: target.Channel,
+	}
+}
+
+// BuildGetShareTargetsResponse 构建获取分享目的地统计响应
+func (c *Converter) BuildGetShareTargetsResponse(success bool, message string, stats []*model.ShareTargetStat, total int64) *rest.GetShareTargetsResponse {
+	targets := make([]*rest.ShareTargetStat, 0, len(stats))
+	for _, stat := range stats {
+		targets = append(targets, &rest.ShareTargetStat{
+			Target: &rest.ShareTarget{
+				Type:    stat.Target.Type,
+				Id:      stat.Target.ID,
+				Channel: stat.Target.Channel,
+			},
+			Count: stat.Count,
+		})
+	}
+
+	return &rest.GetShareTargetsResponse{
+		Success: success,
+		Message: message,
+		Targets: targets,
+		Total:   total,
+	}
+}
+
+func (c *Converter) BuildErrorGetShareTargetsResponse(message string) *rest.GetShareTargetsResponse {
+	return c.BuildGetShareTargetsResponse(false, message, nil, 0)
+}
+
 // ==================== 聚合查询相关转换方法 ====================
 
 // ContentDetailResultToProto 将内容详情结果转换为Protobuf
@@ -741,6 +957,8 @@ func (c *Converter) ContentDetailResultToProto(detail *model.ContentDetailResult
 		TopComments:      topComments,
 		InteractionStats: c.InteractionStatsModelToProto(detail.InteractionStats),
 		UserInteractions: userInteractions,
+		Partial:          len(detail.DegradedParts) > 0,
+		DegradedParts:    detail.DegradedParts,
 	}
 }
 
@@ -757,7 +975,7 @@ func (c *Converter) ContentFeedItemToProto(item *model.ContentFeedItem) *rest.Co
 	}
 
 	return &rest.ContentFeedItem{
-		Content:             c.ContentModelToProto(item.Content),
+		Content:             c.ContentPreviewToProto(item.Content),
 		InteractionStats:    c.InteractionStatsModelToProto(item.InteractionStats),
 		UserInteractions:    userInteractions,
 		CommentPreviewCount: item.CommentPreview,
@@ -774,7 +992,7 @@ func (c *Converter) BuildGetContentDetailResponse(success bool, message string,
 }
 
 // BuildGetContentFeedResponse 构建获取内容流响应
-func (c *Converter) BuildGetContentFeedResponse(success bool, message string, feedItems []*model.ContentFeedItem, total int64) *rest.GetContentFeedResponse {
+func (c *Converter) BuildGetContentFeedResponse(success bool, message string, feedItems []*model.ContentFeedItem, total int64, page, pageSize int32, nextCursor string) *rest.GetContentFeedResponse {
 	var feedItemProtos []*rest.ContentFeedItem
 	if feedItems != nil {
 		feedItemProtos = make([]*rest.ContentFeedItem, len(feedItems))
@@ -784,10 +1002,14 @@ func (c *Converter) BuildGetContentFeedResponse(success bool, message string, fe
 	}
 
 	return &rest.GetContentFeedResponse{
-		Success: success,
-		Message: message,
-		Items:   feedItemProtos,
-		Total:   total,
+		Success:    success,
+		Message:    message,
+		Items:      feedItemProtos,
+		Total:      total,
+		Page:       page,
+		PageSize:   pageSize,
+		NextCursor: nextCursor,
+		Pagination: httpx.CursorPage(page, pageSize, total, nextCursor).Proto(),
 	}
 }
 
@@ -814,9 +1036,255 @@ func (c *Converter) BuildErrorGetContentDetailResponse(message string) *rest.Get
 }
 
 func (c *Converter) BuildErrorGetContentFeedResponse(message string) *rest.GetContentFeedResponse {
-	return c.BuildGetContentFeedResponse(false, message, nil, 0)
+	return c.BuildGetContentFeedResponse(false, message, nil, 0, 0, 0, "")
 }
 
 func (c *Converter) BuildErrorGetTrendingContentResponse(message string) *rest.GetTrendingContentResponse {
 	return c.BuildGetTrendingContentResponse(false, message, nil)
 }
+
+// ==================== 举报相关转换方法 ====================
+
+// ReportSummaryModelToProto 将举报汇总Model转换为Protobuf
+func (c *Converter) ReportSummaryModelToProto(summary *model.ReportSummary) *rest.ReportSummary {
+	if summary == nil {
+		return nil
+	}
+
+	return &rest.ReportSummary{
+		TargetId:        summary.TargetID,
+		TargetType:      c.stringToTargetType(summary.TargetType),
+		ReportCount:     summary.ReportCount,
+		ReasonCounts:    summary.ReasonCounts,
+		Status:          summary.Status,
+		FirstReportedAt: summary.FirstReportedAt.Format(time.RFC3339),
+		LastReportedAt:  summary.LastReportedAt.Format(time.RFC3339),
+	}
+}
+
+// BuildReportContentResponse 构建举报内容响应
+func (c *Converter) BuildReportContentResponse(success bool, message string) *rest.ReportContentResponse {
+	return &rest.ReportContentResponse{
+		Success: success,
+		Message: message,
+	}
+}
+
+// BuildListReportsResponse 构建举报列表响应
+func (c *Converter) BuildListReportsResponse(success bool, message string, summaries []*model.ReportSummary, total int64, page, pageSize int32) *rest.ListReportsResponse {
+	var reportProtos []*rest.ReportSummary
+	if summaries != nil {
+		reportProtos = make([]*rest.ReportSummary, len(summaries))
+		for i, summary := range summaries {
+			reportProtos[i] = c.ReportSummaryModelToProto(summary)
+		}
+	}
+
+	return &rest.ListReportsResponse{
+		Success:    success,
+		Message:    message,
+		Reports:    reportProtos,
+		Total:      total,
+		Page:       page,
+		PageSize:   pageSize,
+		Pagination: httpx.OffsetPage(page, pageSize, total).Proto(),
+	}
+}
+
+// BuildResolveReportResponse 构建处理举报响应
+func (c *Converter) BuildResolveReportResponse(success bool, message string) *rest.ResolveReportResponse {
+	return &rest.ResolveReportResponse{
+		Success: success,
+		Message: message,
+	}
+}
+
+// BuildReportCommentResponse 构建举报评论响应
+func (c *Converter) BuildReportCommentResponse(success bool, message string) *rest.ReportCommentResponse {
+	return &rest.ReportCommentResponse{
+		Success: success,
+		Message: message,
+	}
+}
+
+func (c *Converter) BuildErrorReportContentResponse(message string) *rest.ReportContentResponse {
+	return c.BuildReportContentResponse(false, message)
+}
+
+func (c *Converter) BuildErrorReportCommentResponse(message string) *rest.ReportCommentResponse {
+	return c.BuildReportCommentResponse(false, message)
+}
+
+func (c *Converter) BuildErrorListReportsResponse(message string) *rest.ListReportsResponse {
+	return c.BuildListReportsResponse(false, message, nil, 0, 0, 0)
+}
+
+func (c *Converter) BuildErrorResolveReportResponse(message string) *rest.ResolveReportResponse {
+	return c.BuildResolveReportResponse(false, message)
+}
+
+// ==================== 评论复核相关转换方法 ====================
+
+// BuildListHeldCommentsResponse 构建待复核评论列表响应
+func (c *Converter) BuildListHeldCommentsResponse(success bool, message string, comments []*model.Comment, total int64, page, pageSize int32) *rest.ListHeldCommentsResponse {
+	var commentProtos []*rest.Comment
+	if comments != nil {
+		commentProtos = make([]*rest.Comment, len(comments))
+		for i, comment := range comments {
+			commentProtos[i] = c.CommentModelToProto(comment)
+		}
+	}
+
+	return &rest.ListHeldCommentsResponse{
+		Success:    success,
+		Message:    message,
+		Comments:   commentProtos,
+		Total:      total,
+		Page:       page,
+		PageSize:   pageSize,
+		Pagination: httpx.OffsetPage(page, pageSize, total).Proto(),
+	}
+}
+
+// BuildReviewCommentResponse 构建复核评论响应
+func (c *Converter) BuildReviewCommentResponse(success bool, message string) *rest.ReviewCommentResponse {
+	return &rest.ReviewCommentResponse{
+		Success: success,
+		Message: message,
+	}
+}
+
+func (c *Converter) BuildErrorListHeldCommentsResponse(message string) *rest.ListHeldCommentsResponse {
+	return c.BuildListHeldCommentsResponse(false, message, nil, 0, 0, 0)
+}
+
+func (c *Converter) BuildErrorReviewCommentResponse(message string) *rest.ReviewCommentResponse {
+	return c.BuildReviewCommentResponse(false, message)
+}
+
+// ==================== 分类相关转换方法 ====================
+
+// CategoryModelToProto 将分类Model转换为Protobuf
+func (c *Converter) CategoryModelToProto(category *model.ContentCategory) *rest.ContentCategory {
+	if category == nil {
+		return nil
+	}
+	return &rest.ContentCategory{
+		Id:          category.ID,
+		Name:        category.Name,
+		Description: category.Description,
+		SortOrder:   category.SortOrder,
+	}
+}
+
+// CategoryModelsToProto 将分类Model列表转换为Protobuf列表
+func (c *Converter) CategoryModelsToProto(categories []*model.ContentCategory) []*rest.ContentCategory {
+	result := make([]*rest.ContentCategory, 0, len(categories))
+	for _, category := range categories {
+		if protoCategory := c.CategoryModelToProto(category); protoCategory != nil {
+			result = append(result, protoCategory)
+		}
+	}
+	return result
+}
+
+// BuildCreateCategoryResponse 构建创建分类响应
+func (c *Converter) BuildCreateCategoryResponse(success bool, message string, category *model.ContentCategory) *rest.CreateCategoryResponse {
+	return &rest.CreateCategoryResponse{
+		Success:  success,
+		Message:  message,
+		Category: c.CategoryModelToProto(category),
+	}
+}
+
+// BuildUpdateCategoryResponse 构建更新分类响应
+func (c *Converter) BuildUpdateCategoryResponse(success bool, message string, category *model.ContentCategory) *rest.UpdateCategoryResponse {
+	return &rest.UpdateCategoryResponse{
+		Success:  success,
+		Message:  message,
+		Category: c.CategoryModelToProto(category),
+	}
+}
+
+// BuildDeleteCategoryResponse 构建删除分类响应
+func (c *Converter) BuildDeleteCategoryResponse(success bool, message string) *rest.DeleteCategoryResponse {
+	return &rest.DeleteCategoryResponse{
+		Success: success,
+		Message: message,
+	}
+}
+
+// BuildGetCategoriesResponse 构建获取分类列表响应
+func (c *Converter) BuildGetCategoriesResponse(success bool, message string, categories []*model.ContentCategory) *rest.GetCategoriesResponse {
+	return &rest.GetCategoriesResponse{
+		Success:    success,
+		Message:    message,
+		Categories: c.CategoryModelsToProto(categories),
+	}
+}
+
+// BuildGetCategoryFeedResponse 构建获取分类内容流响应
+func (c *Converter) BuildGetCategoryFeedResponse(success bool, message string, feedItems []*model.ContentFeedItem, total int64, page, pageSize int32) *rest.GetCategoryFeedResponse {
+	var feedItemProtos []*rest.ContentFeedItem
+	if feedItems != nil {
+		feedItemProtos = make([]*rest.ContentFeedItem, len(feedItems))
+		for i, item := range feedItems {
+			feedItemProtos[i] = c.ContentFeedItemToProto(item)
+		}
+	}
+
+	return &rest.GetCategoryFeedResponse{
+		Success:    success,
+		Message:    message,
+		Items:      feedItemProtos,
+		Total:      total,
+		Page:       page,
+		PageSize:   pageSize,
+		Pagination: httpx.OffsetPage(page, pageSize, total).Proto(),
+	}
+}
+
+// 错误响应构建方法
+func (c *Converter) BuildErrorCreateCategoryResponse(message string) *rest.CreateCategoryResponse {
+	return c.BuildCreateCategoryResponse(false, message, nil)
+}
+
+func (c *Converter) BuildErrorUpdateCategoryResponse(message string) *rest.UpdateCategoryResponse {
+	return c.BuildUpdateCategoryResponse(false, message, nil)
+}
+
+func (c *Converter) BuildErrorDeleteCategoryResponse(message string) *rest.DeleteCategoryResponse {
+	return c.BuildDeleteCategoryResponse(false, message)
+}
+
+func (c *Converter) BuildErrorGetCategoriesResponse(message string) *rest.GetCategoriesResponse {
+	return c.BuildGetCategoriesResponse(false, message, nil)
+}
+
+func (c *Converter) BuildErrorGetCategoryFeedResponse(message string) *rest.GetCategoryFeedResponse {
+	return c.BuildGetCategoryFeedResponse(false, message, nil, 0, 0, 0)
+}
+
+// ==================== 媒体上传相关转换方法 ====================
+
+// BuildPresignMediaUploadResponse 构建获取媒体上传地址响应
+func (c *Converter) BuildPresignMediaUploadResponse(success bool, message string, upload *model.MediaUpload) *rest.PresignMediaUploadResponse {
+	resp := &rest.PresignMediaUploadResponse{
+		Success: success,
+		Message: message,
+	}
+	if upload != nil {
+		resp.UploadUrl = upload.UploadURL
+		resp.Method = upload.Method
+		resp.Headers = upload.Headers
+		resp.ObjectKey = upload.ObjectKey
+		resp.ObjectUrl = upload.ObjectURL
+		resp.MediaType = upload.MediaType
+		resp.ExpiresAt = upload.ExpiresAt.Format(time.RFC3339)
+	}
+	return resp
+}
+
+func (c *Converter) BuildErrorPresignMediaUploadResponse(message string) *rest.PresignMediaUploadResponse {
+	return c.BuildPresignMediaUploadResponse(false, message, nil)
+}
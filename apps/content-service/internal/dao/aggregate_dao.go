@@ -3,73 +3,63 @@ package dao
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"gorm.io/gorm"
 	"goim-social/apps/content-service/internal/model"
+	"goim-social/pkg/outbox"
 )
 
 // ==================== 聚合查询方法实现 ====================
 
-// GetContentWithDetails 获取内容详情（包含评论和互动）
-func (d *contentDAO) GetContentWithDetails(ctx context.Context, contentID, userID int64, commentLimit int32) (*model.Content, []*model.Comment, *model.InteractionStats, map[string]bool, error) {
-	var content model.Content
+// GetTopComments 获取内容的热门顶级评论，按点赞数和时间倒序，excludeUserIDs中用户的评论不返回
+func (d *contentDAO) GetTopComments(ctx context.Context, contentID int64, limit int32, excludeUserIDs []int64) ([]*model.Comment, error) {
 	var comments []*model.Comment
-	var stats model.InteractionStats
-	userInteractions := make(map[string]bool)
-
-	// 获取内容
-	if err := d.db.GetDB().WithContext(ctx).First(&content, contentID).Error; err != nil {
-		return nil, nil, nil, nil, err
+	query := d.db.GetDB().WithContext(ctx).
+		Where("target_id = ? AND target_type = ? AND parent_id = 0", contentID, model.TargetTypeContent).
+		Where("status NOT IN ?", model.HiddenCommentStatuses())
+	if len(excludeUserIDs) > 0 {
+		query = query.Where("user_id NOT IN ?", excludeUserIDs)
 	}
-
-	// 获取热门评论
-	if commentLimit > 0 {
-		err := d.db.GetDB().WithContext(ctx).
-			Where("target_id = ? AND target_type = ? AND parent_id = 0", contentID, model.TargetTypeContent).
-			Order("like_count DESC, created_at DESC").
-			Limit(int(commentLimit)).
-			Find(&comments).Error
-		if err != nil {
-			return nil, nil, nil, nil, err
-		}
+	err := query.Order("like_count DESC, created_at DESC").
+		Limit(int(limit)).
+		Find(&comments).Error
+	if err != nil {
+		return nil, err
 	}
+	return comments, nil
+}
 
-	// 获取互动统计
+// GetUserTargetInteractions 获取用户对单个目标的互动状态，键为互动类型
+func (d *contentDAO) GetUserTargetInteractions(ctx context.Context, userID, targetID int64, targetType string) (map[string]bool, error) {
+	var interactions []model.Interaction
 	err := d.db.GetDB().WithContext(ctx).
-		Where("target_id = ? AND target_type = ?", contentID, model.TargetTypeContent).
-		First(&stats).Error
-	if err != nil && err != gorm.ErrRecordNotFound {
-		return nil, nil, nil, nil, err
+		Where("user_id = ? AND target_id = ? AND target_type = ?", userID, targetID, targetType).
+		Find(&interactions).Error
+	if err != nil {
+		return nil, err
 	}
 
-	// 获取用户互动状态
-	if userID > 0 {
-		var interactions []model.Interaction
-		err := d.db.GetDB().WithContext(ctx).
-			Where("user_id = ? AND target_id = ? AND target_type = ?", userID, contentID, model.TargetTypeContent).
-			Find(&interactions).Error
-		if err != nil {
-			return nil, nil, nil, nil, err
-		}
-
-		for _, interaction := range interactions {
-			userInteractions[interaction.InteractionType] = true
-		}
+	userInteractions := make(map[string]bool, len(interactions))
+	for _, interaction := range interactions {
+		userInteractions[interaction.InteractionType] = true
 	}
-
-	return &content, comments, &stats, userInteractions, nil
+	return userInteractions, nil
 }
 
-// GetContentFeed 获取内容流
-func (d *contentDAO) GetContentFeed(ctx context.Context, userID int64, contentType, sortBy string, page, pageSize int32) ([]*model.Content, []*model.InteractionStats, map[int64]map[string]bool, error) {
+// GetContentFeed 获取内容流，只包含公开内容，按偏移量分页以便游标精确记录已消费的位置，excludeAuthorIDs中作者的内容不返回
+func (d *contentDAO) GetContentFeed(ctx context.Context, userID int64, contentType, sortBy string, offset, limit int32, excludeAuthorIDs []int64) ([]*model.Content, map[int64]map[string]bool, error) {
 	var contents []*model.Content
 	
 	query := d.db.GetDB().WithContext(ctx).Model(&model.Content{}).
-		Where("status = ?", model.ContentStatusPublished)
+		Where("status = ? AND visibility = ?", model.ContentStatusPublished, model.VisibilityPublic)
 
 	if contentType != "" {
 		query = query.Where("type = ?", contentType)
 	}
+	if len(excludeAuthorIDs) > 0 {
+		query = query.Where("author_id NOT IN ?", excludeAuthorIDs)
+	}
 
 	// 排序
 	switch sortBy {
@@ -82,14 +72,13 @@ func (d *contentDAO) GetContentFeed(ctx context.Context, userID int64, contentTy
 	}
 
 	// 分页
-	offset := (page - 1) * pageSize
-	err := query.Offset(int(offset)).Limit(int(pageSize)).Find(&contents).Error
+	err := query.Offset(int(offset)).Limit(int(limit)).Find(&contents).Error
 	if err != nil {
-		return nil, nil, nil, err
+		return nil, nil, err
 	}
 
 	if len(contents) == 0 {
-		return contents, nil, nil, nil
+		return contents, nil, nil
 	}
 
 	// 获取内容ID列表
@@ -98,12 +87,6 @@ func (d *contentDAO) GetContentFeed(ctx context.Context, userID int64, contentTy
 		contentIDs[i] = content.ID
 	}
 
-	// 批量获取互动统计
-	stats, err := d.BatchGetInteractionStats(ctx, contentIDs, model.TargetTypeContent)
-	if err != nil {
-		return nil, nil, nil, err
-	}
-
 	// 获取用户互动状态
 	userInteractionsMap := make(map[int64]map[string]bool)
 	if userID > 0 {
@@ -112,7 +95,7 @@ func (d *contentDAO) GetContentFeed(ctx context.Context, userID int64, contentTy
 			Where("user_id = ? AND target_id IN ? AND target_type = ?", userID, contentIDs, model.TargetTypeContent).
 			Find(&interactions).Error
 		if err != nil {
-			return nil, nil, nil, err
+			return nil, nil, err
 		}
 
 		// 初始化用户互动map
@@ -126,15 +109,15 @@ func (d *contentDAO) GetContentFeed(ctx context.Context, userID int64, contentTy
 		}
 	}
 
-	return contents, stats, userInteractionsMap, nil
+	return contents, userInteractionsMap, nil
 }
 
-// GetTrendingContent 获取热门内容
-func (d *contentDAO) GetTrendingContent(ctx context.Context, timeRange, contentType string, limit int32) ([]*model.Content, []*model.InteractionStats, error) {
+// GetTrendingContent 获取热门内容，只包含公开内容
+func (d *contentDAO) GetTrendingContent(ctx context.Context, timeRange, contentType string, limit int32) ([]*model.Content, error) {
 	var contents []*model.Content
 	
 	query := d.db.GetDB().WithContext(ctx).Model(&model.Content{}).
-		Where("status = ?", model.ContentStatusPublished)
+		Where("status = ? AND visibility = ?", model.ContentStatusPublished, model.VisibilityPublic)
 
 	if contentType != "" {
 		query = query.Where("type = ?", contentType)
@@ -157,33 +140,84 @@ func (d *contentDAO) GetTrendingContent(ctx context.Context, timeRange, contentT
 		Limit(int(limit)).
 		Find(&contents).Error
 	if err != nil {
-		return nil, nil, err
+		return nil, err
 	}
 
-	if len(contents) == 0 {
-		return contents, nil, nil
+	return contents, nil
+}
+
+// GetUserFeedAffinity 根据用户最近互动过的内容获取其偏好的作者和话题
+func (d *contentDAO) GetUserFeedAffinity(ctx context.Context, userID int64, limit int) (map[int64]bool, map[int64]bool, error) {
+	authors := make(map[int64]bool)
+	topics := make(map[int64]bool)
+	db := d.db.GetDB().WithContext(ctx)
+
+	var contentIDs []int64
+	if err := db.Model(&model.Interaction{}).
+		Where("user_id = ? AND target_type = ?", userID, model.TargetTypeContent).
+		Order("created_at DESC").
+		Limit(limit).
+		Pluck("target_id", &contentIDs).Error; err != nil {
+		return nil, nil, err
+	}
+	if len(contentIDs) == 0 {
+		return authors, topics, nil
 	}
 
-	// 获取内容ID列表
-	contentIDs := make([]int64, len(contents))
-	for i, content := range contents {
-		contentIDs[i] = content.ID
+	var authorIDs []int64
+	if err := db.Model(&model.Content{}).
+		Where("id IN ?", contentIDs).
+		Distinct().
+		Pluck("author_id", &authorIDs).Error; err != nil {
+		return nil, nil, err
+	}
+	for _, authorID := range authorIDs {
+		authors[authorID] = true
 	}
 
-	// 批量获取互动统计
-	stats, err := d.BatchGetInteractionStats(ctx, contentIDs, model.TargetTypeContent)
-	if err != nil {
+	var topicIDs []int64
+	if err := db.Model(&model.ContentTopicRelation{}).
+		Where("content_id IN ?", contentIDs).
+		Distinct().
+		Pluck("topic_id", &topicIDs).Error; err != nil {
 		return nil, nil, err
 	}
+	for _, topicID := range topicIDs {
+		topics[topicID] = true
+	}
+
+	return authors, topics, nil
+}
+
+// GetContentTopicIDs 批量获取内容关联的话题ID
+func (d *contentDAO) GetContentTopicIDs(ctx context.Context, contentIDs []int64) (map[int64][]int64, error) {
+	result := make(map[int64][]int64)
+	if len(contentIDs) == 0 {
+		return result, nil
+	}
 
-	return contents, stats, nil
+	var relations []model.ContentTopicRelation
+	if err := d.db.GetDB().WithContext(ctx).
+		Where("content_id IN ?", contentIDs).
+		Find(&relations).Error; err != nil {
+		return nil, err
+	}
+	for _, relation := range relations {
+		result[relation.ContentID] = append(result[relation.ContentID], relation.TopicID)
+	}
+	return result, nil
 }
 
 // ==================== 事务操作方法实现 ====================
 
-// DeleteContentWithRelated 删除内容及其相关数据
-func (d *contentDAO) DeleteContentWithRelated(ctx context.Context, contentID int64) error {
+// DeleteContentWithRelated 删除内容及其相关数据，events与删除在同一事务中写入发件箱
+func (d *contentDAO) DeleteContentWithRelated(ctx context.Context, contentID int64, events ...outbox.Builder) error {
 	return d.db.GetDB().WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// 0. 删除评论上的互动和统计，评论删除后无法再定位
+		if err := deleteCommentInteractions(tx, contentID); err != nil {
+			return err
+		}
+
 		// 1. 删除内容相关的评论
 		if err := tx.Where("target_id = ? AND target_type = ?", contentID, model.TargetTypeContent).
 			Delete(&model.Comment{}).Error; err != nil {
@@ -226,15 +260,86 @@ func (d *contentDAO) DeleteContentWithRelated(ctx context.Context, contentID int
 			return fmt.Errorf("failed to delete status logs: %v", err)
 		}
 
+		// 删除个人主页置顶
+		if err := tx.Where("content_id = ?", contentID).
+			Delete(&model.ContentProfilePin{}).Error; err != nil {
+			return fmt.Errorf("failed to delete profile pin: %v", err)
+		}
+
 		// 8. 最后删除内容本身
 		if err := tx.Delete(&model.Content{}, contentID).Error; err != nil {
 			return fmt.Errorf("failed to delete content: %v", err)
 		}
 
+		// 9. 写入发件箱
+		return outbox.Write(tx, events...)
+	})
+}
+
+// CleanupDeletedContent 清理已删除内容的评论、互动和统计
+// 评论标记为已删除而不物理删除，互动记录和统计行直接删除，软删除保留的内容行计数清零。
+// 各步骤都按当前状态过滤，重复执行不会产生额外变更
+func (d *contentDAO) CleanupDeletedContent(ctx context.Context, contentID int64) error {
+	return d.db.GetDB().WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// 1. 删除评论上的互动和统计
+		if err := deleteCommentInteractions(tx, contentID); err != nil {
+			return err
+		}
+
+		// 2. 隐藏内容下的评论
+		if err := tx.Model(&model.Comment{}).
+			Where("target_id = ? AND target_type = ? AND status <> ?", contentID, model.TargetTypeContent, model.CommentStatusDeleted).
+			Updates(map[string]interface{}{
+				"status":     model.CommentStatusDeleted,
+				"updated_at": time.Now(),
+			}).Error; err != nil {
+			return fmt.Errorf("failed to hide comments: %v", err)
+		}
+
+		// 3. 删除内容上的互动
+		if err := tx.Where("target_id = ? AND target_type = ?", contentID, model.TargetTypeContent).
+			Delete(&model.Interaction{}).Error; err != nil {
+			return fmt.Errorf("failed to delete interactions: %v", err)
+		}
+
+		// 4. 删除内容的互动统计
+		if err := tx.Where("target_id = ? AND target_type = ?", contentID, model.TargetTypeContent).
+			Delete(&model.InteractionStats{}).Error; err != nil {
+			return fmt.Errorf("failed to delete interaction stats: %v", err)
+		}
+
+		// 5. 软删除的内容行保留，互动计数清零
+		if err := tx.Model(&model.Content{}).
+			Where("id = ? AND status = ?", contentID, model.ContentStatusDeleted).
+			Updates(map[string]interface{}{
+				"like_count":     0,
+				"comment_count":  0,
+				"share_count":    0,
+				"favorite_count": 0,
+			}).Error; err != nil {
+			return fmt.Errorf("failed to reset content counters: %v", err)
+		}
+
 		return nil
 	})
 }
 
+// deleteCommentInteractions 删除内容下所有评论上的互动记录和统计
+func deleteCommentInteractions(tx *gorm.DB, contentID int64) error {
+	commentIDs := tx.Model(&model.Comment{}).Select("id").
+		Where("target_id = ? AND target_type = ?", contentID, model.TargetTypeContent)
+
+	if err := tx.Where("target_type = ? AND target_id IN (?)", model.TargetTypeComment, commentIDs).
+		Delete(&model.Interaction{}).Error; err != nil {
+		return fmt.Errorf("failed to delete comment interactions: %v", err)
+	}
+	if err := tx.Where("target_type = ? AND target_id IN (?)", model.TargetTypeComment, commentIDs).
+		Delete(&model.InteractionStats{}).Error; err != nil {
+		return fmt.Errorf("failed to delete comment interaction stats: %v", err)
+	}
+	return nil
+}
+
 // BatchUpdateStats 批量更新统计数据
 func (d *contentDAO) BatchUpdateStats(ctx context.Context, updates []model.StatsUpdate) error {
 	return d.db.GetDB().WithContext(ctx).Transaction(func(tx *gorm.DB) error {
@@ -0,0 +1,149 @@
+package dao
+
+import (
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+
+	"goim-social/apps/content-service/internal/model"
+)
+
+// ==================== 分类相关方法实现 ====================
+
+// CreateCategory 创建分类
+func (d *contentDAO) CreateCategory(ctx context.Context, category *model.ContentCategory) error {
+	return d.db.WithContext(ctx).Create(category).Error
+}
+
+// GetCategory 获取分类
+func (d *contentDAO) GetCategory(ctx context.Context, categoryID int64) (*model.ContentCategory, error) {
+	var category model.ContentCategory
+	err := d.db.WithContext(ctx).Where("id = ?", categoryID).First(&category).Error
+	if err != nil {
+		return nil, err
+	}
+	return &category, nil
+}
+
+// GetCategoryByName 根据名称获取分类
+func (d *contentDAO) GetCategoryByName(ctx context.Context, name string) (*model.ContentCategory, error) {
+	var category model.ContentCategory
+	err := d.db.WithContext(ctx).Where("name = ?", name).First(&category).Error
+	if err != nil {
+		return nil, err
+	}
+	return &category, nil
+}
+
+// GetCategories 获取全部分类，按排序值和创建时间升序
+func (d *contentDAO) GetCategories(ctx context.Context) ([]*model.ContentCategory, error) {
+	var categories []*model.ContentCategory
+	err := d.db.WithContext(ctx).
+		Order("sort_order ASC, id ASC").
+		Find(&categories).Error
+	return categories, err
+}
+
+// CountCategories 统计分类数量
+func (d *contentDAO) CountCategories(ctx context.Context) (int64, error) {
+	var count int64
+	err := d.db.WithContext(ctx).Model(&model.ContentCategory{}).Count(&count).Error
+	return count, err
+}
+
+// UpdateCategory 更新分类
+func (d *contentDAO) UpdateCategory(ctx context.Context, category *model.ContentCategory) error {
+	return d.db.WithContext(ctx).Save(category).Error
+}
+
+// DeleteCategory 删除分类，分类下的内容归入目标分类
+func (d *contentDAO) DeleteCategory(ctx context.Context, categoryID, fallbackCategoryID int64) error {
+	return d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&model.Content{}).
+			Where("category_id = ?", categoryID).
+			Update("category_id", fallbackCategoryID).Error; err != nil {
+			return err
+		}
+		return tx.Where("id = ?", categoryID).Delete(&model.ContentCategory{}).Error
+	})
+}
+
+// EnsureDefaultCategory 确保默认分类存在并返回
+func (d *contentDAO) EnsureDefaultCategory(ctx context.Context) (*model.ContentCategory, error) {
+	category, err := d.GetCategoryByName(ctx, model.DefaultCategoryName)
+	if err == nil {
+		return category, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	category = &model.ContentCategory{
+		Name:        model.DefaultCategoryName,
+		Description: model.DefaultCategoryDescription,
+	}
+	if err := d.CreateCategory(ctx, category); err != nil {
+		// 多实例同时启动时可能已被其他实例创建
+		if existing, getErr := d.GetCategoryByName(ctx, model.DefaultCategoryName); getErr == nil {
+			return existing, nil
+		}
+		return nil, err
+	}
+	return category, nil
+}
+
+// AssignUncategorizedContents 将未分配分类的内容归入默认分类，返回迁移的内容数
+func (d *contentDAO) AssignUncategorizedContents(ctx context.Context, defaultCategoryID int64) (int64, error) {
+	result := d.db.WithContext(ctx).Model(&model.Content{}).
+		Where("category_id = 0").
+		Update("category_id", defaultCategoryID)
+	return result.RowsAffected, result.Error
+}
+
+// GetCategoryFeed 获取分类下已发布的公开内容，按发布时间倒序，同时返回总数和用户互动状态
+// excludeAuthorIDs中作者的内容不返回也不计入总数
+func (d *contentDAO) GetCategoryFeed(ctx context.Context, userID, categoryID int64, page, pageSize int32, excludeAuthorIDs []int64) ([]*model.Content, int64, map[int64]map[string]bool, error) {
+	query := d.db.WithContext(ctx).Model(&model.Content{}).
+		Where("status = ? AND visibility = ? AND category_id = ?", model.ContentStatusPublished, model.VisibilityPublic, categoryID)
+	if len(excludeAuthorIDs) > 0 {
+		query = query.Where("author_id NOT IN ?", excludeAuthorIDs)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, nil, err
+	}
+
+	var contents []*model.Content
+	offset := (page - 1) * pageSize
+	if err := query.Order("published_at DESC, id DESC").
+		Offset(int(offset)).
+		Limit(int(pageSize)).
+		Find(&contents).Error; err != nil {
+		return nil, 0, nil, err
+	}
+
+	userInteractionsMap := make(map[int64]map[string]bool)
+	if userID <= 0 || len(contents) == 0 {
+		return contents, total, userInteractionsMap, nil
+	}
+
+	contentIDs := make([]int64, len(contents))
+	for i, content := range contents {
+		contentIDs[i] = content.ID
+		userInteractionsMap[content.ID] = make(map[string]bool)
+	}
+
+	var interactions []model.Interaction
+	if err := d.db.WithContext(ctx).
+		Where("user_id = ? AND target_id IN ? AND target_type = ?", userID, contentIDs, model.TargetTypeContent).
+		Find(&interactions).Error; err != nil {
+		return nil, 0, nil, err
+	}
+	for _, interaction := range interactions {
+		userInteractionsMap[interaction.TargetID][interaction.InteractionType] = true
+	}
+
+	return contents, total, userInteractionsMap, nil
+}
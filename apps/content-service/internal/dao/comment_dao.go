@@ -4,14 +4,22 @@ import (
 	"context"
 	"fmt"
 
+	"gorm.io/gorm"
+
 	"goim-social/apps/content-service/internal/model"
+	"goim-social/pkg/outbox"
 )
 
 // ==================== 评论相关方法实现 ====================
 
-// CreateComment 创建评论
-func (d *contentDAO) CreateComment(ctx context.Context, comment *model.Comment) error {
-	return d.db.GetDB().WithContext(ctx).Create(comment).Error
+// CreateComment 创建评论，events与评论在同一事务中写入发件箱
+func (d *contentDAO) CreateComment(ctx context.Context, comment *model.Comment, events ...outbox.Builder) error {
+	return d.db.GetDB().WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(comment).Error; err != nil {
+			return err
+		}
+		return outbox.Write(tx, events...)
+	})
 }
 
 // GetComment 获取评论
@@ -31,18 +39,28 @@ func (d *contentDAO) UpdateComment(ctx context.Context, commentID int64, content
 		Update("content", content).Error
 }
 
-// DeleteComment 删除评论
-func (d *contentDAO) DeleteComment(ctx context.Context, commentID int64) error {
-	return d.db.GetDB().WithContext(ctx).Delete(&model.Comment{}, commentID).Error
+// DeleteComment 删除评论，events与删除在同一事务中写入发件箱
+func (d *contentDAO) DeleteComment(ctx context.Context, commentID int64, events ...outbox.Builder) error {
+	return d.db.GetDB().WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&model.Comment{}, commentID).Error; err != nil {
+			return err
+		}
+		return outbox.Write(tx, events...)
+	})
 }
 
 // GetComments 获取评论列表
-func (d *contentDAO) GetComments(ctx context.Context, targetID int64, targetType string, parentID int64, sortBy, sortOrder string, page, pageSize int32) ([]*model.Comment, int64, error) {
+// excludeUserIDs中用户的评论不返回也不计入总数
+func (d *contentDAO) GetComments(ctx context.Context, targetID int64, targetType string, parentID int64, sortBy, sortOrder string, page, pageSize int32, excludeUserIDs []int64) ([]*model.Comment, int64, error) {
 	var comments []*model.Comment
 	var total int64
 
 	query := d.db.GetDB().WithContext(ctx).Model(&model.Comment{}).
-		Where("target_id = ? AND target_type = ? AND parent_id = ?", targetID, targetType, parentID)
+		Where("target_id = ? AND target_type = ? AND parent_id = ?", targetID, targetType, parentID).
+		Where("status NOT IN ?", model.HiddenCommentStatuses())
+	if len(excludeUserIDs) > 0 {
+		query = query.Where("user_id NOT IN ?", excludeUserIDs)
+	}
 
 	// 计算总数
 	if err := query.Count(&total).Error; err != nil {
@@ -73,12 +91,17 @@ func (d *contentDAO) GetComments(ctx context.Context, targetID int64, targetType
 }
 
 // GetCommentReplies 获取评论回复
-func (d *contentDAO) GetCommentReplies(ctx context.Context, commentID int64, sortBy, sortOrder string, page, pageSize int32) ([]*model.Comment, int64, error) {
+// excludeUserIDs中用户的回复不返回也不计入总数
+func (d *contentDAO) GetCommentReplies(ctx context.Context, commentID int64, sortBy, sortOrder string, page, pageSize int32, excludeUserIDs []int64) ([]*model.Comment, int64, error) {
 	var replies []*model.Comment
 	var total int64
 
 	query := d.db.GetDB().WithContext(ctx).Model(&model.Comment{}).
-		Where("parent_id = ?", commentID)
+		Where("parent_id = ?", commentID).
+		Where("status NOT IN ?", model.HiddenCommentStatuses())
+	if len(excludeUserIDs) > 0 {
+		query = query.Where("user_id NOT IN ?", excludeUserIDs)
+	}
 
 	// 计算总数
 	if err := query.Count(&total).Error; err != nil {
@@ -129,6 +152,90 @@ func (d *contentDAO) GetCommentsByUser(ctx context.Context, userID int64, page,
 	return comments, total, err
 }
 
+// UpdateCommentStatus 更新评论状态
+func (d *contentDAO) UpdateCommentStatus(ctx context.Context, commentID int64, status string) error {
+	return d.db.GetDB().WithContext(ctx).Model(&model.Comment{}).
+		Where("id = ?", commentID).
+		Update("status", status).Error
+}
+
+// GetCommentsByStatus 按状态分页获取评论，按创建时间从早到晚排列，先提交的先审核
+func (d *contentDAO) GetCommentsByStatus(ctx context.Context, status string, page, pageSize int32) ([]*model.Comment, int64, error) {
+	var comments []*model.Comment
+	var total int64
+
+	query := d.db.GetDB().WithContext(ctx).Model(&model.Comment{}).
+		Where("status = ?", status)
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * pageSize
+	err := query.Order("created_at ASC, id ASC").
+		Offset(int(offset)).
+		Limit(int(pageSize)).
+		Find(&comments).Error
+
+	return comments, total, err
+}
+
+// UpdateHeldCommentStatus 变更待复核评论的状态，events与状态变更在同一事务中写入发件箱
+// 评论已不处于待复核状态时不变更、不写事件并返回false，并发复核同一评论时只有一次生效
+func (d *contentDAO) UpdateHeldCommentStatus(ctx context.Context, commentID int64, status string, events ...outbox.Builder) (bool, error) {
+	var updated bool
+	err := d.db.GetDB().WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&model.Comment{}).
+			Where("id = ? AND status = ?", commentID, model.CommentStatusHeld).
+			Update("status", status)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+		updated = true
+		return outbox.Write(tx, events...)
+	})
+	return updated, err
+}
+
+// MarkFilteredCommentsHeld 将此前被违禁词拦截、仍处于隐藏状态的评论迁移为待复核状态，返回迁移的评论数
+// 违禁词拦截原先与举报隐藏共用hidden状态，无法进入复核队列
+func (d *contentDAO) MarkFilteredCommentsHeld(ctx context.Context) (int64, error) {
+	db := d.db.GetDB().WithContext(ctx)
+	filtered := db.Model(&model.CommentModerationLog{}).
+		Select("comment_id").
+		Where("action = ?", model.CommentModerationActionAutoFilter)
+	result := db.Model(&model.Comment{}).
+		Where("status = ? AND id IN (?)", model.CommentStatusHidden, filtered).
+		Update("status", model.CommentStatusHeld)
+	return result.RowsAffected, result.Error
+}
+
+// GetCommentModerationActions 获取评论经历过的审核动作
+func (d *contentDAO) GetCommentModerationActions(ctx context.Context, commentID int64) (map[string]bool, error) {
+	var actions []string
+	err := d.db.GetDB().WithContext(ctx).Model(&model.CommentModerationLog{}).
+		Where("comment_id = ?", commentID).
+		Distinct().
+		Pluck("action", &actions).Error
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]bool, len(actions))
+	for _, action := range actions {
+		result[action] = true
+	}
+	return result, nil
+}
+
+// CreateCommentModerationLog 创建评论审核日志
+func (d *contentDAO) CreateCommentModerationLog(ctx context.Context, log *model.CommentModerationLog) error {
+	return d.db.GetDB().WithContext(ctx).Create(log).Error
+}
+
 // UpdateCommentReplyCount 更新评论回复数
 func (d *contentDAO) UpdateCommentReplyCount(ctx context.Context, commentID int64, delta int32) error {
 	return d.db.GetDB().WithContext(ctx).Model(&model.Comment{}).
@@ -6,6 +6,7 @@ import (
 
 	"goim-social/apps/content-service/internal/model"
 	"goim-social/pkg/database"
+	"goim-social/pkg/outbox"
 
 	"gorm.io/gorm"
 )
@@ -50,13 +51,24 @@ func (d *contentDAO) GetContentWithRelations(ctx context.Context, contentID int6
 	return &content, nil
 }
 
-// UpdateContent 更新内容
-func (d *contentDAO) UpdateContent(ctx context.Context, content *model.Content) error {
-	return d.db.WithContext(ctx).Save(content).Error
+// UpdateContent 更新内容，内容不再是已发布状态时取消个人主页置顶
+func (d *contentDAO) UpdateContent(ctx context.Context, content *model.Content, events ...outbox.Builder) error {
+	return d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(content).Error; err != nil {
+			return err
+		}
+		if content.Status != model.ContentStatusPublished {
+			if err := tx.Where("content_id = ?", content.ID).Delete(&model.ContentProfilePin{}).Error; err != nil {
+				return err
+			}
+			content.PinnedAt = nil
+		}
+		return outbox.Write(tx, events...)
+	})
 }
 
-// DeleteContent 删除内容
-func (d *contentDAO) DeleteContent(ctx context.Context, contentID int64) error {
+// DeleteContent 删除内容，events与删除在同一事务中写入发件箱
+func (d *contentDAO) DeleteContent(ctx context.Context, contentID int64, events ...outbox.Builder) error {
 	return d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
 		// 删除媒体文件
 		if err := tx.Where("content_id = ?", contentID).Delete(&model.ContentMediaFile{}).Error; err != nil {
@@ -78,17 +90,43 @@ func (d *contentDAO) DeleteContent(ctx context.Context, contentID int64) error {
 			return err
 		}
 
+		// 删除个人主页置顶
+		if err := tx.Where("content_id = ?", contentID).Delete(&model.ContentProfilePin{}).Error; err != nil {
+			return err
+		}
+
 		// 删除内容
-		return tx.Where("id = ?", contentID).Delete(&model.Content{}).Error
+		if err := tx.Where("id = ?", contentID).Delete(&model.Content{}).Error; err != nil {
+			return err
+		}
+		return outbox.Write(tx, events...)
 	})
 }
 
-// GetUserContents 获取用户内容列表
-func (d *contentDAO) GetUserContents(ctx context.Context, authorID int64, status string, page, pageSize int32) ([]*model.Content, int64, error) {
-	query := d.db.WithContext(ctx).Model(&model.Content{}).Where("author_id = ?", authorID)
+// GetUserContents 获取用户内容列表，总数按全部过滤条件统计
+func (d *contentDAO) GetUserContents(ctx context.Context, q *model.UserContentQuery, offset, limit int32) ([]*model.Content, int64, error) {
+	query := d.db.WithContext(ctx).Model(&model.Content{}).Where("author_id = ?", q.AuthorID)
 
-	if status != "" {
-		query = query.Where("status = ?", status)
+	if q.Status != "" {
+		query = query.Where("status = ?", q.Status)
+	}
+	if q.Type != "" {
+		query = query.Where("type = ?", q.Type)
+	}
+	if len(q.Visibilities) > 0 {
+		query = query.Where("visibility IN ?", q.Visibilities)
+	}
+	if !q.StartTime.IsZero() {
+		query = query.Where("created_at >= ?", q.StartTime)
+	}
+	if !q.EndTime.IsZero() {
+		query = query.Where("created_at <= ?", q.EndTime)
+	}
+	if q.TagID > 0 {
+		query = query.Where("EXISTS (SELECT 1 FROM content_tag_relations r WHERE r.content_id = contents.id AND r.tag_id = ?)", q.TagID)
+	}
+	if q.TopicID > 0 {
+		query = query.Where("EXISTS (SELECT 1 FROM content_topic_relations r WHERE r.content_id = contents.id AND r.topic_id = ?)", q.TopicID)
 	}
 
 	// 获取总数
@@ -97,11 +135,21 @@ func (d *contentDAO) GetUserContents(ctx context.Context, authorID int64, status
 		return nil, 0, err
 	}
 
-	// 分页和排序
-	offset := (page - 1) * pageSize
-	query = query.Order("created_at DESC").
+	// 分页和排序，置顶内容按置顶时间排在最前，按ID兜底保证同分内容的顺序稳定
+	var orderBy string
+	switch q.SortBy {
+	case model.UserContentSortMostViewed:
+		orderBy = "view_count DESC, id DESC"
+	case model.UserContentSortMostLiked:
+		orderBy = "like_count DESC, id DESC"
+	default:
+		orderBy = "created_at DESC, id DESC"
+	}
+	query = query.Select("contents.*, (" + profilePinnedAtSQL + ") AS pinned_at").
+		Order(profilePinnedAtSQL + " DESC NULLS LAST").
+		Order(orderBy).
 		Offset(int(offset)).
-		Limit(int(pageSize)).
+		Limit(int(limit)).
 		Preload("MediaFiles").
 		Preload("Tags").
 		Preload("Topics")
@@ -111,6 +159,63 @@ func (d *contentDAO) GetUserContents(ctx context.Context, authorID int64, status
 	return contents, total, err
 }
 
+// profilePinnedAtSQL 内容置顶到个人主页的时间，未置顶为NULL
+const profilePinnedAtSQL = "SELECT p.pinned_at FROM content_profile_pins p WHERE p.content_id = contents.id"
+
+// PinContentToProfile 置顶内容到作者个人主页，已置顶时返回true；置顶数已达上限时不置顶并返回false
+func (d *contentDAO) PinContentToProfile(ctx context.Context, authorID, contentID int64, maxPins int) (bool, error) {
+	pinned := false
+	err := d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var existing int64
+		if err := tx.Model(&model.ContentProfilePin{}).Where("content_id = ?", contentID).Count(&existing).Error; err != nil {
+			return err
+		}
+		if existing > 0 {
+			pinned = true
+			return nil
+		}
+
+		var count int64
+		if err := tx.Model(&model.ContentProfilePin{}).Where("author_id = ?", authorID).Count(&count).Error; err != nil {
+			return err
+		}
+		if count >= int64(maxPins) {
+			return nil
+		}
+
+		pin := &model.ContentProfilePin{
+			ContentID: contentID,
+			AuthorID:  authorID,
+			PinnedAt:  time.Now(),
+		}
+		if err := tx.Create(pin).Error; err != nil {
+			return err
+		}
+		pinned = true
+		return nil
+	})
+	return pinned, err
+}
+
+// UnpinContentFromProfile 取消内容在作者个人主页的置顶
+func (d *contentDAO) UnpinContentFromProfile(ctx context.Context, authorID, contentID int64) error {
+	return d.db.WithContext(ctx).
+		Where("content_id = ? AND author_id = ?", contentID, authorID).
+		Delete(&model.ContentProfilePin{}).Error
+}
+
+// GetProfilePinnedAt 获取内容置顶到个人主页的时间，未置顶时返回nil
+func (d *contentDAO) GetProfilePinnedAt(ctx context.Context, contentID int64) (*time.Time, error) {
+	var pins []model.ContentProfilePin
+	if err := d.db.WithContext(ctx).Where("content_id = ?", contentID).Limit(1).Find(&pins).Error; err != nil {
+		return nil, err
+	}
+	if len(pins) == 0 {
+		return nil, nil
+	}
+	return &pins[0].PinnedAt, nil
+}
+
 // GetContentsByStatus 根据状态获取内容列表
 func (d *contentDAO) GetContentsByStatus(ctx context.Context, status string, page, pageSize int32) ([]*model.Content, int64, error) {
 	query := d.db.WithContext(ctx).Model(&model.Content{}).Where("status = ?", status)
@@ -189,10 +294,10 @@ func (d *contentDAO) GetContentStats(ctx context.Context, authorID int64) (*mode
 }
 
 // IncrementViewCount 增加浏览次数
-func (d *contentDAO) IncrementViewCount(ctx context.Context, contentID int64) error {
+func (d *contentDAO) IncrementViewCount(ctx context.Context, contentID, delta int64) error {
 	return d.db.WithContext(ctx).Model(&model.Content{}).
 		Where("id = ?", contentID).
-		UpdateColumn("view_count", gorm.Expr("view_count + 1")).Error
+		UpdateColumn("view_count", gorm.Expr("view_count + ?", delta)).Error
 }
 
 // CreateMediaFile 创建媒体文件
@@ -266,6 +371,16 @@ func (d *contentDAO) GetTags(ctx context.Context, keyword string, page, pageSize
 	return tags, total, err
 }
 
+// GetTagsByIDs 批量获取标签
+func (d *contentDAO) GetTagsByIDs(ctx context.Context, tagIDs []int64) ([]*model.ContentTag, error) {
+	var tags []*model.ContentTag
+	if len(tagIDs) == 0 {
+		return tags, nil
+	}
+	err := d.db.WithContext(ctx).Where("id IN ?", tagIDs).Find(&tags).Error
+	return tags, err
+}
+
 // UpdateTagUsageCount 更新标签使用次数
 func (d *contentDAO) UpdateTagUsageCount(ctx context.Context, tagID int64, delta int64) error {
 	if delta > 0 {
@@ -335,6 +450,16 @@ func (d *contentDAO) GetTopics(ctx context.Context, keyword string, hotOnly bool
 	return topics, total, err
 }
 
+// GetTopicsByIDs 批量获取话题
+func (d *contentDAO) GetTopicsByIDs(ctx context.Context, topicIDs []int64) ([]*model.ContentTopic, error) {
+	var topics []*model.ContentTopic
+	if len(topicIDs) == 0 {
+		return topics, nil
+	}
+	err := d.db.WithContext(ctx).Where("id IN ?", topicIDs).Find(&topics).Error
+	return topics, err
+}
+
 // UpdateTopicContentCount 更新话题内容数量
 func (d *contentDAO) UpdateTopicContentCount(ctx context.Context, topicID int64, delta int64) error {
 	if delta > 0 {
@@ -5,30 +5,40 @@ import (
 	"fmt"
 
 	"goim-social/apps/content-service/internal/model"
+	"goim-social/pkg/outbox"
 	"gorm.io/gorm"
 )
 
 // ==================== 互动相关方法实现 ====================
 
-// CreateInteraction 创建互动
-func (d *contentDAO) CreateInteraction(ctx context.Context, interaction *model.Interaction) error {
-	return d.db.GetDB().WithContext(ctx).Create(interaction).Error
+// CreateInteraction 创建互动，events与互动在同一事务中写入发件箱
+func (d *contentDAO) CreateInteraction(ctx context.Context, interaction *model.Interaction, events ...outbox.Builder) error {
+	return d.db.GetDB().WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(interaction).Error; err != nil {
+			return err
+		}
+		return outbox.Write(tx, events...)
+	})
 }
 
-// DeleteInteraction 删除互动
-func (d *contentDAO) DeleteInteraction(ctx context.Context, userID, targetID int64, targetType, interactionType string) error {
-	return d.db.GetDB().WithContext(ctx).
-		Where("user_id = ? AND target_id = ? AND target_type = ? AND interaction_type = ?",
-			userID, targetID, targetType, interactionType).
-		Delete(&model.Interaction{}).Error
+// DeleteInteraction 删除互动，reactionKey仅对表情回应有效，其他互动类型传空；events与删除在同一事务中写入发件箱
+func (d *contentDAO) DeleteInteraction(ctx context.Context, userID, targetID int64, targetType, interactionType, reactionKey string, events ...outbox.Builder) error {
+	return d.db.GetDB().WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ? AND target_id = ? AND target_type = ? AND interaction_type = ? AND reaction_key = ?",
+			userID, targetID, targetType, interactionType, reactionKey).
+			Delete(&model.Interaction{}).Error; err != nil {
+			return err
+		}
+		return outbox.Write(tx, events...)
+	})
 }
 
-// GetInteraction 获取互动
-func (d *contentDAO) GetInteraction(ctx context.Context, userID, targetID int64, targetType, interactionType string) (*model.Interaction, error) {
+// GetInteraction 获取互动，reactionKey仅对表情回应有效，其他互动类型传空
+func (d *contentDAO) GetInteraction(ctx context.Context, userID, targetID int64, targetType, interactionType, reactionKey string) (*model.Interaction, error) {
 	var interaction model.Interaction
 	err := d.db.GetDB().WithContext(ctx).
-		Where("user_id = ? AND target_id = ? AND target_type = ? AND interaction_type = ?",
-			userID, targetID, targetType, interactionType).
+		Where("user_id = ? AND target_id = ? AND target_type = ? AND interaction_type = ? AND reaction_key = ?",
+			userID, targetID, targetType, interactionType, reactionKey).
 		First(&interaction).Error
 
 	if err != nil {
@@ -145,6 +155,45 @@ func (d *contentDAO) BatchGetInteractionStats(ctx context.Context, targetIDs []i
 	return stats, nil
 }
 
+// GetReactionCounts 按表情标识聚合目标的表情回应计数
+func (d *contentDAO) GetReactionCounts(ctx context.Context, targetID int64, targetType string) (map[string]int64, error) {
+	var rows []struct {
+		ReactionKey string
+		Count       int64
+	}
+
+	err := d.db.GetDB().WithContext(ctx).Model(&model.Interaction{}).
+		Select("reaction_key, COUNT(*) AS count").
+		Where("target_id = ? AND target_type = ? AND interaction_type = ?",
+			targetID, targetType, model.InteractionTypeReaction).
+		Group("reaction_key").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]int64, len(rows))
+	for _, row := range rows {
+		counts[row.ReactionKey] = row.Count
+	}
+	return counts, nil
+}
+
+// ListShareTargetMetadata 获取最近limit条记录了分享目的地的分享互动元数据，由调用方解析聚合
+func (d *contentDAO) ListShareTargetMetadata(ctx context.Context, targetID int64, targetType string, limit int) ([]string, error) {
+	var metadata []string
+	err := d.db.GetDB().WithContext(ctx).Model(&model.Interaction{}).
+		Where("target_id = ? AND target_type = ? AND interaction_type = ? AND metadata LIKE ?",
+			targetID, targetType, model.InteractionTypeShare, `%"share_target"%`).
+		Order("id DESC").
+		Limit(limit).
+		Pluck("metadata", &metadata).Error
+	if err != nil {
+		return nil, err
+	}
+	return metadata, nil
+}
+
 // UpdateInteractionStats 更新互动统计
 func (d *contentDAO) UpdateInteractionStats(ctx context.Context, targetID int64, targetType, interactionType string, delta int64) error {
 	// 根据互动类型更新对应字段
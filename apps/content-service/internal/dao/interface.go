@@ -2,8 +2,10 @@ package dao
 
 import (
 	"context"
+	"time"
 
 	"goim-social/apps/content-service/internal/model"
+	"goim-social/pkg/outbox"
 )
 
 // ContentDAO 内容数据访问接口（合并了评论和互动功能）
@@ -12,16 +14,21 @@ type ContentDAO interface {
 	CreateContent(ctx context.Context, content *model.Content) error
 	GetContent(ctx context.Context, contentID int64) (*model.Content, error)
 	GetContentWithRelations(ctx context.Context, contentID int64) (*model.Content, error)
-	UpdateContent(ctx context.Context, content *model.Content) error
-	DeleteContent(ctx context.Context, contentID int64) error
+	UpdateContent(ctx context.Context, content *model.Content, events ...outbox.Builder) error
+	DeleteContent(ctx context.Context, contentID int64, events ...outbox.Builder) error
 
 	// 内容查询
-	GetUserContents(ctx context.Context, authorID int64, status string, page, pageSize int32) ([]*model.Content, int64, error)
+	GetUserContents(ctx context.Context, query *model.UserContentQuery, offset, limit int32) ([]*model.Content, int64, error)
 	GetContentsByStatus(ctx context.Context, status string, page, pageSize int32) ([]*model.Content, int64, error)
 
+	// 个人主页置顶
+	PinContentToProfile(ctx context.Context, authorID, contentID int64, maxPins int) (bool, error)
+	UnpinContentFromProfile(ctx context.Context, authorID, contentID int64) error
+	GetProfilePinnedAt(ctx context.Context, contentID int64) (*time.Time, error)
+
 	// 内容统计
 	GetContentStats(ctx context.Context, authorID int64) (*model.ContentStats, error)
-	IncrementViewCount(ctx context.Context, contentID int64) error
+	IncrementViewCount(ctx context.Context, contentID, delta int64) error
 
 	// 媒体文件管理
 	CreateMediaFile(ctx context.Context, mediaFile *model.ContentMediaFile) error
@@ -33,6 +40,7 @@ type ContentDAO interface {
 	GetTag(ctx context.Context, tagID int64) (*model.ContentTag, error)
 	GetTagByName(ctx context.Context, name string) (*model.ContentTag, error)
 	GetTags(ctx context.Context, keyword string, page, pageSize int32) ([]*model.ContentTag, int64, error)
+	GetTagsByIDs(ctx context.Context, tagIDs []int64) ([]*model.ContentTag, error)
 	UpdateTagUsageCount(ctx context.Context, tagID int64, delta int64) error
 
 	// 话题管理
@@ -40,8 +48,20 @@ type ContentDAO interface {
 	GetTopic(ctx context.Context, topicID int64) (*model.ContentTopic, error)
 	GetTopicByName(ctx context.Context, name string) (*model.ContentTopic, error)
 	GetTopics(ctx context.Context, keyword string, hotOnly bool, page, pageSize int32) ([]*model.ContentTopic, int64, error)
+	GetTopicsByIDs(ctx context.Context, topicIDs []int64) ([]*model.ContentTopic, error)
 	UpdateTopicContentCount(ctx context.Context, topicID int64, delta int64) error
 
+	// 分类管理
+	CreateCategory(ctx context.Context, category *model.ContentCategory) error
+	GetCategory(ctx context.Context, categoryID int64) (*model.ContentCategory, error)
+	GetCategoryByName(ctx context.Context, name string) (*model.ContentCategory, error)
+	GetCategories(ctx context.Context) ([]*model.ContentCategory, error)
+	CountCategories(ctx context.Context) (int64, error)
+	UpdateCategory(ctx context.Context, category *model.ContentCategory) error
+	DeleteCategory(ctx context.Context, categoryID, fallbackCategoryID int64) error
+	EnsureDefaultCategory(ctx context.Context) (*model.ContentCategory, error)
+	AssignUncategorizedContents(ctx context.Context, defaultCategoryID int64) (int64, error)
+
 	// 关联关系管理
 	AddContentTags(ctx context.Context, contentID int64, tagIDs []int64) error
 	RemoveContentTags(ctx context.Context, contentID int64) error
@@ -55,16 +75,24 @@ type ContentDAO interface {
 	// ==================== 评论相关方法 ====================
 
 	// 评论基础操作
-	CreateComment(ctx context.Context, comment *model.Comment) error
+	CreateComment(ctx context.Context, comment *model.Comment, events ...outbox.Builder) error
 	GetComment(ctx context.Context, commentID int64) (*model.Comment, error)
 	UpdateComment(ctx context.Context, commentID int64, content string) error
-	DeleteComment(ctx context.Context, commentID int64) error
+	DeleteComment(ctx context.Context, commentID int64, events ...outbox.Builder) error
 
 	// 评论查询
-	GetComments(ctx context.Context, targetID int64, targetType string, parentID int64, sortBy, sortOrder string, page, pageSize int32) ([]*model.Comment, int64, error)
-	GetCommentReplies(ctx context.Context, commentID int64, sortBy, sortOrder string, page, pageSize int32) ([]*model.Comment, int64, error)
+	GetComments(ctx context.Context, targetID int64, targetType string, parentID int64, sortBy, sortOrder string, page, pageSize int32, excludeUserIDs []int64) ([]*model.Comment, int64, error)
+	GetCommentReplies(ctx context.Context, commentID int64, sortBy, sortOrder string, page, pageSize int32, excludeUserIDs []int64) ([]*model.Comment, int64, error)
 	GetCommentsByUser(ctx context.Context, userID int64, page, pageSize int32) ([]*model.Comment, int64, error)
 
+	// 评论审核
+	UpdateCommentStatus(ctx context.Context, commentID int64, status string) error
+	CreateCommentModerationLog(ctx context.Context, log *model.CommentModerationLog) error
+	GetCommentsByStatus(ctx context.Context, status string, page, pageSize int32) ([]*model.Comment, int64, error)
+	UpdateHeldCommentStatus(ctx context.Context, commentID int64, status string, events ...outbox.Builder) (bool, error)
+	MarkFilteredCommentsHeld(ctx context.Context) (int64, error)
+	GetCommentModerationActions(ctx context.Context, commentID int64) (map[string]bool, error)
+
 	// 评论统计
 	UpdateCommentReplyCount(ctx context.Context, commentID int64, delta int32) error
 	UpdateTargetCommentCount(ctx context.Context, targetID int64, targetType string, delta int64) error
@@ -72,9 +100,9 @@ type ContentDAO interface {
 	// ==================== 互动相关方法 ====================
 
 	// 互动基础操作
-	CreateInteraction(ctx context.Context, interaction *model.Interaction) error
-	DeleteInteraction(ctx context.Context, userID, targetID int64, targetType, interactionType string) error
-	GetInteraction(ctx context.Context, userID, targetID int64, targetType, interactionType string) (*model.Interaction, error)
+	CreateInteraction(ctx context.Context, interaction *model.Interaction, events ...outbox.Builder) error
+	DeleteInteraction(ctx context.Context, userID, targetID int64, targetType, interactionType, reactionKey string, events ...outbox.Builder) error
+	GetInteraction(ctx context.Context, userID, targetID int64, targetType, interactionType, reactionKey string) (*model.Interaction, error)
 
 	// 批量互动查询
 	BatchCheckInteractions(ctx context.Context, userID int64, targetIDs []int64, targetType, interactionType string) (map[int64]bool, error)
@@ -84,6 +112,8 @@ type ContentDAO interface {
 	GetInteractionStats(ctx context.Context, targetID int64, targetType string) (*model.InteractionStats, error)
 	BatchGetInteractionStats(ctx context.Context, targetIDs []int64, targetType string) ([]*model.InteractionStats, error)
 	UpdateInteractionStats(ctx context.Context, targetID int64, targetType, interactionType string, delta int64) error
+	GetReactionCounts(ctx context.Context, targetID int64, targetType string) (map[string]int64, error)
+	ListShareTargetMetadata(ctx context.Context, targetID int64, targetType string, limit int) ([]string, error)
 
 	// 互动计数
 	IncrementInteractionCount(ctx context.Context, targetID int64, targetType, interactionType string) error
@@ -91,19 +121,49 @@ type ContentDAO interface {
 
 	// ==================== 聚合查询方法 ====================
 
-	// 内容详情聚合查询（包含评论和互动）
-	GetContentWithDetails(ctx context.Context, contentID, userID int64, commentLimit int32) (*model.Content, []*model.Comment, *model.InteractionStats, map[string]bool, error)
+	// 内容详情的热门评论和当前用户互动状态，与内容本身分开查询以便并行加载
+	GetTopComments(ctx context.Context, contentID int64, limit int32, excludeUserIDs []int64) ([]*model.Comment, error)
+	GetUserTargetInteractions(ctx context.Context, userID, targetID int64, targetType string) (map[string]bool, error)
 
 	// 内容流聚合查询
-	GetContentFeed(ctx context.Context, userID int64, contentType, sortBy string, page, pageSize int32) ([]*model.Content, []*model.InteractionStats, map[int64]map[string]bool, error)
+	GetContentFeed(ctx context.Context, userID int64, contentType, sortBy string, offset, limit int32, excludeAuthorIDs []int64) ([]*model.Content, map[int64]map[string]bool, error)
+
+	// 分类内容流查询
+	GetCategoryFeed(ctx context.Context, userID, categoryID int64, page, pageSize int32, excludeAuthorIDs []int64) ([]*model.Content, int64, map[int64]map[string]bool, error)
 
 	// 热门内容查询
-	GetTrendingContent(ctx context.Context, timeRange, contentType string, limit int32) ([]*model.Content, []*model.InteractionStats, error)
+	GetTrendingContent(ctx context.Context, timeRange, contentType string, limit int32) ([]*model.Content, error)
+
+	// 内容流个性化
+	GetUserFeedAffinity(ctx context.Context, userID int64, limit int) (map[int64]bool, map[int64]bool, error)
+	GetContentTopicIDs(ctx context.Context, contentIDs []int64) (map[int64][]int64, error)
+
+	// ==================== 举报相关方法 ====================
+
+	// 举报基础操作
+	CreateReport(ctx context.Context, report *model.Report) error
+	HasReported(ctx context.Context, targetID int64, targetType string, reporterID int64) (bool, error)
+	CountPendingReports(ctx context.Context, targetID int64, targetType string) (int64, error)
+
+	// 举报汇总与处理
+	GetReportSummaries(ctx context.Context, targetType, status string, page, pageSize int32) ([]*model.ReportSummary, int64, error)
+	ResolveReports(ctx context.Context, targetID int64, targetType string, handlerID int64, status, resolution string) (int64, error)
+
+	// ==================== @提及相关方法 ====================
+
+	// 提及记录与通知状态
+	ReplaceMentions(ctx context.Context, targetID int64, targetType string, mentions []*model.ContentMention) error
+	GetMentions(ctx context.Context, targetIDs []int64, targetType string) ([]*model.ContentMention, error)
+	GetUnnotifiedMentions(ctx context.Context, targetID int64, targetType string) ([]*model.ContentMention, error)
+	MarkMentionNotified(ctx context.Context, mentionID int64, notifiedAt time.Time) (bool, error)
 
 	// ==================== 事务操作方法 ====================
 
 	// 删除内容及其相关数据（评论、互动）
-	DeleteContentWithRelated(ctx context.Context, contentID int64) error
+	DeleteContentWithRelated(ctx context.Context, contentID int64, events ...outbox.Builder) error
+
+	// 清理已删除内容的评论、互动和统计，可重复执行
+	CleanupDeletedContent(ctx context.Context, contentID int64) error
 
 	// 批量更新统计数据
 	BatchUpdateStats(ctx context.Context, updates []model.StatsUpdate) error
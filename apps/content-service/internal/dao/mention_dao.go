@@ -0,0 +1,74 @@
+package dao
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"goim-social/apps/content-service/internal/model"
+)
+
+// ==================== @提及相关方法实现 ====================
+
+// ReplaceMentions 用新的提及列表替换对象的提及记录，已存在的记录保留原通知时间，不再出现的记录删除
+func (d *contentDAO) ReplaceMentions(ctx context.Context, targetID int64, targetType string, mentions []*model.ContentMention) error {
+	return d.db.GetDB().WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		userIDs := make([]int64, 0, len(mentions))
+		for _, mention := range mentions {
+			userIDs = append(userIDs, mention.UserID)
+		}
+
+		query := tx.Where("target_id = ? AND target_type = ?", targetID, targetType)
+		if len(userIDs) > 0 {
+			query = query.Where("user_id NOT IN ?", userIDs)
+		}
+		if err := query.Delete(&model.ContentMention{}).Error; err != nil {
+			return fmt.Errorf("failed to delete stale mentions: %v", err)
+		}
+
+		if len(mentions) == 0 {
+			return nil
+		}
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&mentions).Error; err != nil {
+			return fmt.Errorf("failed to create mentions: %v", err)
+		}
+		return nil
+	})
+}
+
+// GetMentions 批量获取对象的提及记录，按创建顺序排列
+func (d *contentDAO) GetMentions(ctx context.Context, targetIDs []int64, targetType string) ([]*model.ContentMention, error) {
+	var mentions []*model.ContentMention
+	if len(targetIDs) == 0 {
+		return mentions, nil
+	}
+	err := d.db.GetDB().WithContext(ctx).
+		Where("target_id IN ? AND target_type = ?", targetIDs, targetType).
+		Order("id ASC").
+		Find(&mentions).Error
+	return mentions, err
+}
+
+// GetUnnotifiedMentions 获取对象中尚未通知的提及记录
+func (d *contentDAO) GetUnnotifiedMentions(ctx context.Context, targetID int64, targetType string) ([]*model.ContentMention, error) {
+	var mentions []*model.ContentMention
+	err := d.db.GetDB().WithContext(ctx).
+		Where("target_id = ? AND target_type = ? AND notified_at IS NULL", targetID, targetType).
+		Order("id ASC").
+		Find(&mentions).Error
+	return mentions, err
+}
+
+// MarkMentionNotified 将提及标记为已通知，返回是否由本次调用标记，已被标记时返回false
+func (d *contentDAO) MarkMentionNotified(ctx context.Context, mentionID int64, notifiedAt time.Time) (bool, error) {
+	result := d.db.GetDB().WithContext(ctx).Model(&model.ContentMention{}).
+		Where("id = ? AND notified_at IS NULL", mentionID).
+		Update("notified_at", notifiedAt)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
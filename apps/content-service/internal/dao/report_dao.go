@@ -0,0 +1,120 @@
+package dao
+
+import (
+	"context"
+	"time"
+
+	"goim-social/apps/content-service/internal/model"
+)
+
+// ==================== 举报相关方法实现 ====================
+
+// CreateReport 创建举报记录
+func (d *contentDAO) CreateReport(ctx context.Context, report *model.Report) error {
+	return d.db.GetDB().WithContext(ctx).Create(report).Error
+}
+
+// HasReported 检查用户是否已举报过该对象
+func (d *contentDAO) HasReported(ctx context.Context, targetID int64, targetType string, reporterID int64) (bool, error) {
+	var count int64
+	err := d.db.GetDB().WithContext(ctx).Model(&model.Report{}).
+		Where("target_id = ? AND target_type = ? AND reporter_id = ?", targetID, targetType, reporterID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// CountPendingReports 统计对象的待处理举报数
+func (d *contentDAO) CountPendingReports(ctx context.Context, targetID int64, targetType string) (int64, error) {
+	var count int64
+	err := d.db.GetDB().WithContext(ctx).Model(&model.Report{}).
+		Where("target_id = ? AND target_type = ? AND status = ?", targetID, targetType, model.ReportStatusPending).
+		Count(&count).Error
+	return count, err
+}
+
+// GetReportSummaries 按被举报对象聚合举报记录，举报数多的排在前面
+func (d *contentDAO) GetReportSummaries(ctx context.Context, targetType, status string, page, pageSize int32) ([]*model.ReportSummary, int64, error) {
+	db := d.db.GetDB().WithContext(ctx)
+
+	var total int64
+	if err := db.Model(&model.Report{}).
+		Where("target_type = ? AND status = ?", targetType, status).
+		Distinct("target_id").
+		Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var rows []struct {
+		TargetID        int64
+		ReportCount     int64
+		FirstReportedAt time.Time
+		LastReportedAt  time.Time
+	}
+	offset := (page - 1) * pageSize
+	if err := db.Model(&model.Report{}).
+		Select("target_id, COUNT(*) AS report_count, MIN(created_at) AS first_reported_at, MAX(created_at) AS last_reported_at").
+		Where("target_type = ? AND status = ?", targetType, status).
+		Group("target_id").
+		Order("report_count DESC, last_reported_at DESC").
+		Offset(int(offset)).
+		Limit(int(pageSize)).
+		Scan(&rows).Error; err != nil {
+		return nil, 0, err
+	}
+	if len(rows) == 0 {
+		return []*model.ReportSummary{}, total, nil
+	}
+
+	summaries := make([]*model.ReportSummary, len(rows))
+	summaryMap := make(map[int64]*model.ReportSummary, len(rows))
+	targetIDs := make([]int64, len(rows))
+	for i, row := range rows {
+		summaries[i] = &model.ReportSummary{
+			TargetID:        row.TargetID,
+			TargetType:      targetType,
+			ReportCount:     row.ReportCount,
+			ReasonCounts:    make(map[string]int64),
+			Status:          status,
+			FirstReportedAt: row.FirstReportedAt,
+			LastReportedAt:  row.LastReportedAt,
+		}
+		summaryMap[row.TargetID] = summaries[i]
+		targetIDs[i] = row.TargetID
+	}
+
+	// 统计各举报原因的次数
+	var reasonRows []struct {
+		TargetID int64
+		Reason   string
+		Count    int64
+	}
+	if err := db.Model(&model.Report{}).
+		Select("target_id, reason, COUNT(*) AS count").
+		Where("target_type = ? AND status = ? AND target_id IN ?", targetType, status, targetIDs).
+		Group("target_id, reason").
+		Scan(&reasonRows).Error; err != nil {
+		return nil, 0, err
+	}
+	for _, row := range reasonRows {
+		if summary, ok := summaryMap[row.TargetID]; ok {
+			summary.ReasonCounts[row.Reason] = row.Count
+		}
+	}
+
+	return summaries, total, nil
+}
+
+// ResolveReports 处理对象的所有待处理举报，返回处理的记录数
+func (d *contentDAO) ResolveReports(ctx context.Context, targetID int64, targetType string, handlerID int64, status, resolution string) (int64, error) {
+	now := time.Now()
+	result := d.db.GetDB().WithContext(ctx).Model(&model.Report{}).
+		Where("target_id = ? AND target_type = ? AND status = ?", targetID, targetType, model.ReportStatusPending).
+		Updates(map[string]interface{}{
+			"status":      status,
+			"handler_id":  handlerID,
+			"resolution":  resolution,
+			"resolved_at": now,
+			"updated_at":  now,
+		})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,156 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"goim-social/api/rest"
+	tracecontext "goim-social/pkg/context"
+	"goim-social/pkg/httpx"
+	"goim-social/pkg/logger"
+)
+
+// CreateCategory 创建分类（管理员）
+func (h *HTTPHandler) CreateCategory(c *gin.Context) {
+	var (
+		ctx  = c.Request.Context()
+		req  rest.CreateCategoryRequest
+		resp *rest.CreateCategoryResponse
+		err  error
+	)
+
+	if err = c.Bind(&req); err != nil {
+		h.logger.Error(ctx, "Invalid create category request", logger.F("error", err.Error()))
+		resp = h.converter.BuildErrorCreateCategoryResponse("Invalid request format")
+		httpx.WriteObject(c, resp, err)
+		return
+	}
+
+	// 将业务信息添加到context
+	ctx = tracecontext.WithUserID(ctx, req.OperatorId)
+
+	category, err := h.svc.CreateCategory(ctx, req.OperatorId, req.Name, req.Description, req.SortOrder)
+	if err != nil {
+		h.logger.Error(ctx, "Create category failed", logger.F("error", err.Error()), logger.F("name", req.Name))
+		resp = h.converter.BuildErrorCreateCategoryResponse(err.Error())
+	} else {
+		h.logger.Info(ctx, "Create category successful", logger.F("categoryID", category.ID), logger.F("name", category.Name))
+		resp = h.converter.BuildCreateCategoryResponse(true, "创建分类成功", category)
+	}
+
+	httpx.WriteObject(c, resp, err)
+}
+
+// UpdateCategory 更新分类（管理员）
+func (h *HTTPHandler) UpdateCategory(c *gin.Context) {
+	var (
+		ctx  = c.Request.Context()
+		req  rest.UpdateCategoryRequest
+		resp *rest.UpdateCategoryResponse
+		err  error
+	)
+
+	if err = c.Bind(&req); err != nil {
+		h.logger.Error(ctx, "Invalid update category request", logger.F("error", err.Error()))
+		resp = h.converter.BuildErrorUpdateCategoryResponse("Invalid request format")
+		httpx.WriteObject(c, resp, err)
+		return
+	}
+
+	// 将业务信息添加到context
+	ctx = tracecontext.WithUserID(ctx, req.OperatorId)
+
+	category, err := h.svc.UpdateCategory(ctx, req.OperatorId, req.CategoryId, req.Name, req.Description, req.SortOrder)
+	if err != nil {
+		h.logger.Error(ctx, "Update category failed", logger.F("error", err.Error()), logger.F("categoryID", req.CategoryId))
+		resp = h.converter.BuildErrorUpdateCategoryResponse(err.Error())
+	} else {
+		h.logger.Info(ctx, "Update category successful", logger.F("categoryID", category.ID))
+		resp = h.converter.BuildUpdateCategoryResponse(true, "更新分类成功", category)
+	}
+
+	httpx.WriteObject(c, resp, err)
+}
+
+// DeleteCategory 删除分类（管理员）
+func (h *HTTPHandler) DeleteCategory(c *gin.Context) {
+	var (
+		ctx  = c.Request.Context()
+		req  rest.DeleteCategoryRequest
+		resp *rest.DeleteCategoryResponse
+		err  error
+	)
+
+	if err = c.Bind(&req); err != nil {
+		h.logger.Error(ctx, "Invalid delete category request", logger.F("error", err.Error()))
+		resp = h.converter.BuildErrorDeleteCategoryResponse("Invalid request format")
+		httpx.WriteObject(c, resp, err)
+		return
+	}
+
+	// 将业务信息添加到context
+	ctx = tracecontext.WithUserID(ctx, req.OperatorId)
+
+	err = h.svc.DeleteCategory(ctx, req.OperatorId, req.CategoryId)
+	if err != nil {
+		h.logger.Error(ctx, "Delete category failed", logger.F("error", err.Error()), logger.F("categoryID", req.CategoryId))
+		resp = h.converter.BuildErrorDeleteCategoryResponse(err.Error())
+	} else {
+		h.logger.Info(ctx, "Delete category successful", logger.F("categoryID", req.CategoryId))
+		resp = h.converter.BuildDeleteCategoryResponse(true, "删除分类成功")
+	}
+
+	httpx.WriteObject(c, resp, err)
+}
+
+// GetCategories 获取分类列表
+func (h *HTTPHandler) GetCategories(c *gin.Context) {
+	var (
+		ctx  = c.Request.Context()
+		resp *rest.GetCategoriesResponse
+		err  error
+	)
+
+	categories, err := h.svc.GetCategories(ctx)
+	if err != nil {
+		h.logger.Error(ctx, "Get categories failed", logger.F("error", err.Error()))
+		resp = h.converter.BuildErrorGetCategoriesResponse(err.Error())
+	} else {
+		resp = h.converter.BuildGetCategoriesResponse(true, "获取分类列表成功", categories)
+	}
+
+	httpx.WriteObject(c, resp, err)
+}
+
+// GetCategoryFeed 获取分类内容流
+func (h *HTTPHandler) GetCategoryFeed(c *gin.Context) {
+	var (
+		ctx  = c.Request.Context()
+		req  rest.GetCategoryFeedRequest
+		resp *rest.GetCategoryFeedResponse
+		err  error
+	)
+
+	if err = c.Bind(&req); err != nil {
+		h.logger.Error(ctx, "Invalid get category feed request", logger.F("error", err.Error()))
+		resp = h.converter.BuildErrorGetCategoryFeedResponse("Invalid request format")
+		httpx.WriteObject(c, resp, err)
+		return
+	}
+
+	// 将业务信息添加到context
+	if req.UserId > 0 {
+		ctx = tracecontext.WithUserID(ctx, req.UserId)
+	}
+
+	pageSize := h.svc.PageSize(req.PageSize)
+	feedItems, total, err := h.svc.GetCategoryFeed(ctx, req.UserId, req.CategoryId, req.Page, pageSize)
+	if err != nil {
+		h.logger.Error(ctx, "Get category feed failed", logger.F("error", err.Error()), logger.F("categoryID", req.CategoryId))
+		resp = h.converter.BuildErrorGetCategoryFeedResponse(err.Error())
+	} else {
+		h.logger.Info(ctx, "Get category feed successful", logger.F("categoryID", req.CategoryId), logger.F("itemCount", len(feedItems)))
+		resp = h.converter.BuildGetCategoryFeedResponse(true, "获取分类内容流成功", feedItems, total, req.Page, pageSize)
+	}
+
+	httpx.WriteObject(c, resp, err)
+}
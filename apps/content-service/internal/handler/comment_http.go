@@ -107,13 +107,14 @@ func (h *HTTPHandler) GetComments(c *gin.Context) {
 	// 将业务信息添加到context
 	ctx = tracecontext.WithContentID(ctx, req.TargetId)
 
-	comments, total, err := h.svc.GetComments(ctx, req.TargetId, req.TargetType.String(), req.ParentId, req.SortBy, req.SortOrder, req.Page, req.PageSize)
+	pageSize := h.svc.PageSize(req.PageSize)
+	comments, total, err := h.svc.GetComments(ctx, req.UserId, req.TargetId, req.TargetType.String(), req.ParentId, req.SortBy, req.SortOrder, req.Page, pageSize)
 	if err != nil {
 		h.logger.Error(ctx, "Get comments failed", logger.F("error", err.Error()), logger.F("targetID", req.TargetId))
 		resp = h.converter.BuildErrorGetCommentsResponse(err.Error())
 	} else {
 		h.logger.Info(ctx, "Get comments successful", logger.F("targetID", req.TargetId), logger.F("count", len(comments)))
-		resp = h.converter.BuildGetCommentsResponse(true, "获取评论列表成功", comments, total)
+		resp = h.converter.BuildGetCommentsResponse(true, "获取评论列表成功", comments, total, req.Page, pageSize)
 	}
 
 	httpx.WriteObject(c, resp, err)
@@ -135,13 +136,77 @@ func (h *HTTPHandler) GetCommentReplies(c *gin.Context) {
 		return
 	}
 
-	replies, total, err := h.svc.GetCommentReplies(ctx, req.CommentId, req.SortBy, req.SortOrder, req.Page, req.PageSize)
+	pageSize := h.svc.PageSize(req.PageSize)
+	replies, total, err := h.svc.GetCommentReplies(ctx, req.UserId, req.CommentId, req.SortBy, req.SortOrder, req.Page, pageSize)
 	if err != nil {
 		h.logger.Error(ctx, "Get comment replies failed", logger.F("error", err.Error()), logger.F("commentID", req.CommentId))
 		resp = h.converter.BuildErrorGetCommentRepliesResponse(err.Error())
 	} else {
 		h.logger.Info(ctx, "Get comment replies successful", logger.F("commentID", req.CommentId), logger.F("count", len(replies)))
-		resp = h.converter.BuildGetCommentRepliesResponse(true, "获取评论回复成功", replies, total)
+		resp = h.converter.BuildGetCommentRepliesResponse(true, "获取评论回复成功", replies, total, req.Page, pageSize)
+	}
+
+	httpx.WriteObject(c, resp, err)
+}
+
+// ListHeldComments 获取待复核评论（管理员）
+func (h *HTTPHandler) ListHeldComments(c *gin.Context) {
+	var (
+		ctx  = c.Request.Context()
+		req  rest.ListHeldCommentsRequest
+		resp *rest.ListHeldCommentsResponse
+		err  error
+	)
+
+	if err = c.Bind(&req); err != nil {
+		h.logger.Error(ctx, "Invalid list held comments request", logger.F("error", err.Error()))
+		resp = h.converter.BuildErrorListHeldCommentsResponse("Invalid request format")
+		httpx.WriteObject(c, resp, err)
+		return
+	}
+
+	// 将业务信息添加到context
+	ctx = tracecontext.WithUserID(ctx, req.OperatorId)
+
+	pageSize := h.svc.PageSize(req.PageSize)
+	comments, total, err := h.svc.ListHeldComments(ctx, req.OperatorId, req.Page, pageSize)
+	if err != nil {
+		h.logger.Error(ctx, "List held comments failed", logger.F("error", err.Error()))
+		resp = h.converter.BuildErrorListHeldCommentsResponse(err.Error())
+	} else {
+		h.logger.Info(ctx, "List held comments successful", logger.F("count", len(comments)))
+		resp = h.converter.BuildListHeldCommentsResponse(true, "获取待复核评论成功", comments, total, req.Page, pageSize)
+	}
+
+	httpx.WriteObject(c, resp, err)
+}
+
+// ReviewComment 复核被拦截的评论（管理员）
+func (h *HTTPHandler) ReviewComment(c *gin.Context) {
+	var (
+		ctx  = c.Request.Context()
+		req  rest.ReviewCommentRequest
+		resp *rest.ReviewCommentResponse
+		err  error
+	)
+
+	if err = c.Bind(&req); err != nil {
+		h.logger.Error(ctx, "Invalid review comment request", logger.F("error", err.Error()))
+		resp = h.converter.BuildErrorReviewCommentResponse("Invalid request format")
+		httpx.WriteObject(c, resp, err)
+		return
+	}
+
+	// 将业务信息添加到context
+	ctx = tracecontext.WithUserID(ctx, req.OperatorId)
+
+	err = h.svc.ReviewComment(ctx, req.CommentId, req.OperatorId, req.Action, req.Note)
+	if err != nil {
+		h.logger.Error(ctx, "Review comment failed", logger.F("error", err.Error()), logger.F("commentID", req.CommentId))
+		resp = h.converter.BuildErrorReviewCommentResponse(err.Error())
+	} else {
+		h.logger.Info(ctx, "Review comment successful", logger.F("commentID", req.CommentId), logger.F("action", req.Action))
+		resp = h.converter.BuildReviewCommentResponse(true, "评论复核成功")
 	}
 
 	httpx.WriteObject(c, resp, err)
@@ -24,11 +24,14 @@ func (h *GRPCHandler) createContentImpl(ctx context.Context, req *rest.CreateCon
 		req.AuthorId,
 		req.Title,
 		req.Content,
+		req.Summary,
 		contentType,
 		mediaFiles,
 		req.TagIds,
 		req.TopicIds,
+		req.CategoryId,
 		req.TemplateData,
+		req.Visibility,
 		req.SaveAsDraft,
 	)
 	if err != nil {
@@ -61,11 +64,14 @@ func (h *GRPCHandler) updateContentImpl(ctx context.Context, req *rest.UpdateCon
 		req.AuthorId,
 		req.Title,
 		req.Content,
+		req.Summary,
 		contentType,
 		mediaFiles,
 		req.TagIds,
 		req.TopicIds,
+		req.CategoryId,
 		req.TemplateData,
+		req.Visibility,
 	)
 	if err != nil {
 		h.logger.Error(ctx, "Failed to update content via gRPC",
@@ -143,9 +149,10 @@ func (h *GRPCHandler) changeContentStatusImpl(ctx context.Context, req *rest.Cha
 
 // getUserContentImpl 获取用户内容列表实现
 func (h *GRPCHandler) getUserContentImpl(ctx context.Context, req *rest.GetUserContentRequest) (*rest.GetUserContentResponse, error) {
-	status := h.converter.ContentStatusFromProto(req.Status)
+	query := h.converter.UserContentQueryFromProto(req)
 
-	contents, total, err := h.svc.GetUserContent(ctx, req.AuthorId, status, req.Page, req.PageSize)
+	pageSize := h.svc.PageSize(req.PageSize)
+	contents, total, nextCursor, err := h.svc.GetUserContent(ctx, query, req.Page, pageSize, req.Cursor)
 	if err != nil {
 		h.logger.Error(ctx, "Failed to get user content via gRPC",
 			logger.F("error", err.Error()),
@@ -153,7 +160,7 @@ func (h *GRPCHandler) getUserContentImpl(ctx context.Context, req *rest.GetUserC
 		return h.converter.BuildErrorGetUserContentResponse(err.Error()), nil
 	}
 
-	return h.converter.BuildGetUserContentResponse(true, "获取成功", contents, total, req.Page, req.PageSize), nil
+	return h.converter.BuildGetUserContentResponse(true, "获取成功", contents, total, req.Page, pageSize, nextCursor), nil
 }
 
 // getContentStatsImpl 获取内容统计实现
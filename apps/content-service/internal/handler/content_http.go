@@ -1,6 +1,8 @@
 package handler
 
 import (
+	"time"
+
 	"github.com/gin-gonic/gin"
 
 	"goim-social/api/rest"
@@ -39,11 +41,14 @@ func (h *HTTPHandler) CreateContent(c *gin.Context) {
 		req.AuthorId,
 		req.Title,
 		req.Content,
+		req.Summary,
 		contentType,
 		mediaFiles,
 		req.TagIds,
 		req.TopicIds,
+		req.CategoryId,
 		req.TemplateData,
+		req.Visibility,
 		req.SaveAsDraft,
 	)
 	if err != nil {
@@ -89,11 +94,14 @@ func (h *HTTPHandler) UpdateContent(c *gin.Context) {
 		req.AuthorId,
 		req.Title,
 		req.Content,
+		req.Summary,
 		contentType,
 		mediaFiles,
 		req.TagIds,
 		req.TopicIds,
+		req.CategoryId,
 		req.TemplateData,
+		req.Visibility,
 	)
 	if err != nil {
 		h.logger.Error(ctx, "Update content failed", logger.F("error", err.Error()), logger.F("contentID", req.ContentId))
@@ -204,6 +212,76 @@ func (h *HTTPHandler) PublishContent(c *gin.Context) {
 	httpx.WriteObject(c, resp, err)
 }
 
+// PinContentToProfile 置顶内容到个人主页
+func (h *HTTPHandler) PinContentToProfile(c *gin.Context) {
+	var (
+		ctx  = c.Request.Context()
+		req  rest.PinContentToProfileRequest
+		resp *rest.PinContentToProfileResponse
+		err  error
+	)
+
+	if err = c.Bind(&req); err != nil {
+		h.logger.Error(ctx, "Invalid pin content request", logger.F("error", err.Error()))
+		resp = h.converter.BuildErrorPinContentToProfileResponse("Invalid request format")
+		httpx.WriteObject(c, resp, err)
+		return
+	}
+
+	// 将业务信息添加到context
+	ctx = tracecontext.WithUserID(ctx, req.AuthorId)
+	ctx = tracecontext.WithContentID(ctx, req.ContentId)
+
+	content, err := h.svc.PinContentToProfile(ctx, req.ContentId, req.AuthorId)
+	if err != nil {
+		h.logger.Error(ctx, "Pin content failed", logger.F("error", err.Error()), logger.F("contentID", req.ContentId))
+		resp = h.converter.BuildErrorPinContentToProfileResponse(err.Error())
+	} else {
+		h.logger.Info(ctx, "Pin content successful", logger.F("contentID", req.ContentId))
+		resp = h.converter.BuildPinContentToProfileResponse(true, "置顶成功", content)
+	}
+
+	httpx.WriteObject(c, resp, err)
+}
+
+// UnpinContentFromProfile 取消个人主页置顶
+func (h *HTTPHandler) UnpinContentFromProfile(c *gin.Context) {
+	var (
+		ctx  = c.Request.Context()
+		req  rest.UnpinContentFromProfileRequest
+		resp *rest.UnpinContentFromProfileResponse
+		err  error
+	)
+
+	if err = c.Bind(&req); err != nil {
+		h.logger.Error(ctx, "Invalid unpin content request", logger.F("error", err.Error()))
+		resp = h.converter.BuildErrorUnpinContentFromProfileResponse("Invalid request format")
+		httpx.WriteObject(c, resp, err)
+		return
+	}
+
+	// 将业务信息添加到context
+	ctx = tracecontext.WithUserID(ctx, req.AuthorId)
+	ctx = tracecontext.WithContentID(ctx, req.ContentId)
+
+	err = h.svc.UnpinContentFromProfile(ctx, req.ContentId, req.AuthorId)
+	if err != nil {
+		h.logger.Error(ctx, "Unpin content failed", logger.F("error", err.Error()), logger.F("contentID", req.ContentId))
+		resp = h.converter.BuildErrorUnpinContentFromProfileResponse(err.Error())
+	} else {
+		h.logger.Info(ctx, "Unpin content successful", logger.F("contentID", req.ContentId))
+		resp = h.converter.BuildUnpinContentFromProfileResponse(true, "取消置顶成功")
+	}
+
+	httpx.WriteObject(c, resp, err)
+}
+
+// GetEditWindows 获取内容、评论和消息的编辑时限，附带服务端当前时间供客户端展示倒计时
+func (h *HTTPHandler) GetEditWindows(c *gin.Context) {
+	resp := h.converter.BuildGetEditWindowsResponse(true, "获取编辑时限成功", h.svc.EditWindows(), time.Now())
+	httpx.WriteObject(c, resp, nil)
+}
+
 // ChangeContentStatus 变更内容状态
 func (h *HTTPHandler) ChangeContentStatus(c *gin.Context) {
 	var (
@@ -258,16 +336,17 @@ func (h *HTTPHandler) GetUserContent(c *gin.Context) {
 	// 将业务信息添加到context
 	ctx = tracecontext.WithUserID(ctx, req.AuthorId)
 
-	// 转换状态枚举
-	statusStr := h.converter.ContentStatusFromProto(req.Status)
+	// 转换过滤条件
+	query := h.converter.UserContentQueryFromProto(&req)
 
-	contents, total, err := h.svc.GetUserContent(ctx, req.AuthorId, statusStr, req.Page, req.PageSize)
+	pageSize := h.svc.PageSize(req.PageSize)
+	contents, total, nextCursor, err := h.svc.GetUserContent(ctx, query, req.Page, pageSize, req.Cursor)
 	if err != nil {
 		h.logger.Error(ctx, "Get user content failed", logger.F("error", err.Error()), logger.F("authorID", req.AuthorId))
 		resp = h.converter.BuildErrorGetUserContentResponse(err.Error())
 	} else {
 		h.logger.Info(ctx, "Get user content successful", logger.F("authorID", req.AuthorId), logger.F("count", len(contents)))
-		resp = h.converter.BuildGetUserContentResponse(true, "获取用户内容成功", contents, total, req.Page, req.PageSize)
+		resp = h.converter.BuildGetUserContentResponse(true, "获取用户内容成功", contents, total, req.Page, pageSize, nextCursor)
 	}
 
 	httpx.WriteObject(c, resp, err)
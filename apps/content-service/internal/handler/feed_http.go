@@ -62,13 +62,14 @@ func (h *HTTPHandler) GetContentFeed(c *gin.Context) {
 	// 将业务信息添加到context
 	ctx = tracecontext.WithUserID(ctx, req.UserId)
 
-	feed, total, err := h.svc.GetContentFeed(ctx, req.UserId, req.ContentType, req.SortBy, req.Page, req.PageSize)
+	pageSize := h.svc.PageSize(req.PageSize)
+	feed, total, nextCursor, err := h.svc.GetContentFeed(ctx, req.UserId, req.ContentType, req.SortBy, req.Page, pageSize, req.Cursor, req.ViewedMode)
 	if err != nil {
 		h.logger.Error(ctx, "Get content feed failed", logger.F("error", err.Error()), logger.F("userID", req.UserId))
 		resp = h.converter.BuildErrorGetContentFeedResponse(err.Error())
 	} else {
 		h.logger.Info(ctx, "Get content feed successful", logger.F("userID", req.UserId), logger.F("count", len(feed)))
-		resp = h.converter.BuildGetContentFeedResponse(true, "获取内容流成功", feed, total)
+		resp = h.converter.BuildGetContentFeedResponse(true, "获取内容流成功", feed, total, req.Page, pageSize, nextCursor)
 	}
 
 	httpx.WriteObject(c, resp, err)
@@ -29,24 +29,36 @@ func (h *HTTPHandler) RegisterRoutes(r *gin.Engine) {
 	api := r.Group("/api/v1/content")
 	{
 		// 内容管理
-		api.POST("/create", h.CreateContent)              // 创建内容
-		api.POST("/update", h.UpdateContent)              // 更新内容
-		api.POST("/get", h.GetContent)                    // 获取内容详情
-		api.POST("/delete", h.DeleteContent)              // 删除内容
-		api.POST("/publish", h.PublishContent)            // 发布内容
-		api.POST("/change_status", h.ChangeContentStatus) // 变更内容状态
+		api.POST("/create", h.CreateContent)                  // 创建内容
+		api.POST("/update", h.UpdateContent)                  // 更新内容
+		api.POST("/get", h.GetContent)                        // 获取内容详情
+		api.POST("/delete", h.DeleteContent)                  // 删除内容
+		api.POST("/publish", h.PublishContent)                // 发布内容
+		api.POST("/change_status", h.ChangeContentStatus)     // 变更内容状态
+		api.POST("/pin_profile", h.PinContentToProfile)       // 置顶内容到个人主页
+		api.POST("/unpin_profile", h.UnpinContentFromProfile) // 取消个人主页置顶
+		api.POST("/edit_windows", h.GetEditWindows)           // 获取编辑时限
 
 		// 内容查询
 		api.POST("/user_content", h.GetUserContent) // 获取用户内容列表
 		api.POST("/stats", h.GetContentStats)       // 获取内容统计
 
 		// 标签管理
-		api.POST("/tag/create", h.CreateTag) // 创建标签
-		api.POST("/tag/list", h.GetTags)     // 获取标签列表
+		api.POST("/tag/create", h.CreateTag)         // 创建标签
+		api.POST("/tag/list", h.GetTags)             // 获取标签列表
+		api.POST("/tag/trending", h.GetTrendingTags) // 获取近期热门标签
 
 		// 话题管理
-		api.POST("/topic/create", h.CreateTopic) // 创建话题
-		api.POST("/topic/list", h.GetTopics)     // 获取话题列表
+		api.POST("/topic/create", h.CreateTopic)         // 创建话题
+		api.POST("/topic/list", h.GetTopics)             // 获取话题列表
+		api.POST("/topic/trending", h.GetTrendingTopics) // 获取近期热门话题
+
+		// 分类管理
+		api.POST("/category/create", h.CreateCategory) // 创建分类（管理员）
+		api.POST("/category/update", h.UpdateCategory) // 更新分类（管理员）
+		api.POST("/category/delete", h.DeleteCategory) // 删除分类（管理员）
+		api.POST("/category/list", h.GetCategories)    // 获取分类列表
+		api.POST("/category/feed", h.GetCategoryFeed)  // 获取分类内容流
 
 		// 评论管理
 		api.POST("/comment/create", h.CreateComment)      // 创建评论
@@ -55,10 +67,24 @@ func (h *HTTPHandler) RegisterRoutes(r *gin.Engine) {
 		api.POST("/comment/replies", h.GetCommentReplies) // 获取评论回复
 
 		// 互动管理
-		api.POST("/interaction/do", h.DoInteraction)          // 执行互动（点赞/收藏/分享等）
-		api.POST("/interaction/undo", h.UndoInteraction)      // 取消互动
-		api.POST("/interaction/check", h.CheckInteraction)    // 检查互动状态
-		api.POST("/interaction/stats", h.GetInteractionStats) // 获取互动统计
+		api.POST("/interaction/do", h.DoInteraction)              // 执行互动（点赞/收藏/分享等）
+		api.POST("/interaction/undo", h.UndoInteraction)          // 取消互动
+		api.POST("/interaction/check", h.CheckInteraction)        // 检查互动状态
+		api.POST("/interaction/stats", h.GetInteractionStats)     // 获取互动统计
+		api.POST("/interaction/share_targets", h.GetShareTargets) // 获取分享目的地统计
+
+		// 举报管理
+		api.POST("/report/content", h.ReportContent) // 举报内容
+		api.POST("/report/comment", h.ReportComment) // 举报评论
+		api.POST("/report/list", h.ListReports)      // 获取举报列表（管理员）
+		api.POST("/report/resolve", h.ResolveReport) // 处理举报（管理员）
+
+		// 评论复核
+		api.POST("/comment/held", h.ListHeldComments) // 获取待复核评论（管理员）
+		api.POST("/comment/review", h.ReviewComment)  // 复核被拦截的评论（管理员）
+
+		// 媒体上传
+		api.POST("/media/presign", h.PresignMediaUpload) // 获取媒体直传地址
 
 		// 聚合查询
 		api.POST("/detail", h.GetContentDetail)     // 获取内容详情（包含评论和互动）
@@ -9,7 +9,7 @@ import (
 	"goim-social/pkg/logger"
 )
 
-// DoInteraction 执行互动（点赞/收藏/分享等）
+// DoInteraction 执行互动（点赞/收藏/分享/表情回应等）
 func (h *HTTPHandler) DoInteraction(c *gin.Context) {
 	var (
 		ctx  = c.Request.Context()
@@ -29,7 +29,7 @@ func (h *HTTPHandler) DoInteraction(c *gin.Context) {
 	ctx = tracecontext.WithUserID(ctx, req.UserId)
 	ctx = tracecontext.WithContentID(ctx, req.TargetId)
 
-	interaction, err := h.svc.DoInteraction(ctx, req.UserId, req.TargetId, req.TargetType.String(), req.InteractionType.String(), req.Metadata)
+	interaction, err := h.svc.DoInteraction(ctx, req.UserId, req.TargetId, h.converter.TargetTypeToString(req.TargetType), h.converter.InteractionTypeToString(req.InteractionType), req.ReactionKey, req.Metadata, h.converter.ShareTargetProtoToModel(req.ShareTarget), req.SendMessage)
 	if err != nil {
 		h.logger.Error(ctx, "Do interaction failed", logger.F("error", err.Error()), logger.F("targetID", req.TargetId), logger.F("type", req.InteractionType.String()))
 		resp = h.converter.BuildErrorDoInteractionResponse(err.Error())
@@ -61,7 +61,7 @@ func (h *HTTPHandler) UndoInteraction(c *gin.Context) {
 	ctx = tracecontext.WithUserID(ctx, req.UserId)
 	ctx = tracecontext.WithContentID(ctx, req.TargetId)
 
-	err = h.svc.UndoInteraction(ctx, req.UserId, req.TargetId, req.TargetType.String(), req.InteractionType.String())
+	err = h.svc.UndoInteraction(ctx, req.UserId, req.TargetId, h.converter.TargetTypeToString(req.TargetType), h.converter.InteractionTypeToString(req.InteractionType), req.ReactionKey)
 	if err != nil {
 		h.logger.Error(ctx, "Undo interaction failed", logger.F("error", err.Error()), logger.F("targetID", req.TargetId), logger.F("type", req.InteractionType.String()))
 		resp = h.converter.BuildErrorUndoInteractionResponse(err.Error())
@@ -93,7 +93,7 @@ func (h *HTTPHandler) CheckInteraction(c *gin.Context) {
 	ctx = tracecontext.WithUserID(ctx, req.UserId)
 	ctx = tracecontext.WithContentID(ctx, req.TargetId)
 
-	hasInteraction, interaction, err := h.svc.CheckInteraction(ctx, req.UserId, req.TargetId, req.TargetType.String(), req.InteractionType.String())
+	hasInteraction, interaction, err := h.svc.CheckInteraction(ctx, req.UserId, req.TargetId, h.converter.TargetTypeToString(req.TargetType), h.converter.InteractionTypeToString(req.InteractionType), req.ReactionKey)
 	if err != nil {
 		h.logger.Error(ctx, "Check interaction failed", logger.F("error", err.Error()), logger.F("targetID", req.TargetId), logger.F("type", req.InteractionType.String()))
 		resp = h.converter.BuildErrorCheckInteractionResponse(err.Error())
@@ -124,7 +124,7 @@ func (h *HTTPHandler) GetInteractionStats(c *gin.Context) {
 	// 将业务信息添加到context
 	ctx = tracecontext.WithContentID(ctx, req.TargetId)
 
-	stats, err := h.svc.GetInteractionStats(ctx, req.TargetId, req.TargetType.String())
+	stats, err := h.svc.GetInteractionStats(ctx, req.TargetId, h.converter.TargetTypeToString(req.TargetType))
 	if err != nil {
 		h.logger.Error(ctx, "Get interaction stats failed", logger.F("error", err.Error()), logger.F("targetID", req.TargetId))
 		resp = h.converter.BuildErrorGetInteractionStatsResponse(err.Error())
@@ -135,3 +135,35 @@ func (h *HTTPHandler) GetInteractionStats(c *gin.Context) {
 
 	httpx.WriteObject(c, resp, err)
 }
+
+// GetShareTargets 获取内容的分享目的地统计
+func (h *HTTPHandler) GetShareTargets(c *gin.Context) {
+	var (
+		ctx  = c.Request.Context()
+		req  rest.GetShareTargetsRequest
+		resp *rest.GetShareTargetsResponse
+		err  error
+	)
+
+	if err = c.Bind(&req); err != nil {
+		h.logger.Error(ctx, "Invalid get share targets request", logger.F("error", err.Error()))
+		resp = h.converter.BuildErrorGetShareTargetsResponse("Invalid request format")
+		httpx.WriteObject(c, resp, err)
+		return
+	}
+
+	// 将业务信息添加到context
+	ctx = tracecontext.WithUserID(ctx, req.UserId)
+	ctx = tracecontext.WithContentID(ctx, req.ContentId)
+
+	stats, total, err := h.svc.GetShareTargets(ctx, req.ContentId, req.UserId)
+	if err != nil {
+		h.logger.Error(ctx, "Get share targets failed", logger.F("error", err.Error()), logger.F("contentID", req.ContentId))
+		resp = h.converter.BuildErrorGetShareTargetsResponse(err.Error())
+	} else {
+		h.logger.Info(ctx, "Get share targets successful", logger.F("contentID", req.ContentId), logger.F("total", total))
+		resp = h.converter.BuildGetShareTargetsResponse(true, "获取分享目的地统计成功", stats, total)
+	}
+
+	httpx.WriteObject(c, resp, err)
+}
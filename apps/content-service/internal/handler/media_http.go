@@ -0,0 +1,41 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"goim-social/api/rest"
+	tracecontext "goim-social/pkg/context"
+	"goim-social/pkg/httpx"
+	"goim-social/pkg/logger"
+)
+
+// PresignMediaUpload 获取媒体直传地址
+func (h *HTTPHandler) PresignMediaUpload(c *gin.Context) {
+	var (
+		ctx  = c.Request.Context()
+		req  rest.PresignMediaUploadRequest
+		resp *rest.PresignMediaUploadResponse
+		err  error
+	)
+
+	if err = c.Bind(&req); err != nil {
+		h.logger.Error(ctx, "Invalid presign media upload request", logger.F("error", err.Error()))
+		resp = h.converter.BuildErrorPresignMediaUploadResponse("Invalid request format")
+		httpx.WriteObject(c, resp, err)
+		return
+	}
+
+	// 将业务信息添加到context
+	ctx = tracecontext.WithUserID(ctx, req.UserId)
+
+	upload, err := h.svc.PresignMediaUpload(ctx, req.UserId, req.ContentType, req.Size, req.Filename)
+	if err != nil {
+		h.logger.Error(ctx, "Presign media upload failed", logger.F("error", err.Error()), logger.F("userID", req.UserId), logger.F("contentType", req.ContentType))
+		resp = h.converter.BuildErrorPresignMediaUploadResponse(err.Error())
+	} else {
+		h.logger.Info(ctx, "Presign media upload successful", logger.F("userID", req.UserId), logger.F("objectKey", upload.ObjectKey))
+		resp = h.converter.BuildPresignMediaUploadResponse(true, "获取上传地址成功", upload)
+	}
+
+	httpx.WriteObject(c, resp, err)
+}
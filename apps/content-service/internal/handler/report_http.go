@@ -0,0 +1,138 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"goim-social/api/rest"
+	tracecontext "goim-social/pkg/context"
+	"goim-social/pkg/httpx"
+	"goim-social/pkg/logger"
+)
+
+// ReportContent 举报内容
+func (h *HTTPHandler) ReportContent(c *gin.Context) {
+	var (
+		ctx  = c.Request.Context()
+		req  rest.ReportContentRequest
+		resp *rest.ReportContentResponse
+		err  error
+	)
+
+	if err = c.Bind(&req); err != nil {
+		h.logger.Error(ctx, "Invalid report content request", logger.F("error", err.Error()))
+		resp = h.converter.BuildErrorReportContentResponse("Invalid request format")
+		httpx.WriteObject(c, resp, err)
+		return
+	}
+
+	// 将业务信息添加到context
+	ctx = tracecontext.WithUserID(ctx, req.ReporterId)
+	ctx = tracecontext.WithContentID(ctx, req.ContentId)
+
+	err = h.svc.ReportContent(ctx, req.ContentId, req.ReporterId, req.Reason, req.Note)
+	if err != nil {
+		h.logger.Error(ctx, "Report content failed", logger.F("error", err.Error()), logger.F("contentID", req.ContentId))
+		resp = h.converter.BuildErrorReportContentResponse(err.Error())
+	} else {
+		h.logger.Info(ctx, "Report content successful", logger.F("contentID", req.ContentId), logger.F("reason", req.Reason))
+		resp = h.converter.BuildReportContentResponse(true, "举报成功，我们会尽快处理")
+	}
+
+	httpx.WriteObject(c, resp, err)
+}
+
+// ReportComment 举报评论
+func (h *HTTPHandler) ReportComment(c *gin.Context) {
+	var (
+		ctx  = c.Request.Context()
+		req  rest.ReportCommentRequest
+		resp *rest.ReportCommentResponse
+		err  error
+	)
+
+	if err = c.Bind(&req); err != nil {
+		h.logger.Error(ctx, "Invalid report comment request", logger.F("error", err.Error()))
+		resp = h.converter.BuildErrorReportCommentResponse("Invalid request format")
+		httpx.WriteObject(c, resp, err)
+		return
+	}
+
+	// 将业务信息添加到context
+	ctx = tracecontext.WithUserID(ctx, req.ReporterId)
+
+	err = h.svc.ReportComment(ctx, req.CommentId, req.ReporterId, req.Reason, req.Note)
+	if err != nil {
+		h.logger.Error(ctx, "Report comment failed", logger.F("error", err.Error()), logger.F("commentID", req.CommentId))
+		resp = h.converter.BuildErrorReportCommentResponse(err.Error())
+	} else {
+		h.logger.Info(ctx, "Report comment successful", logger.F("commentID", req.CommentId), logger.F("reason", req.Reason))
+		resp = h.converter.BuildReportCommentResponse(true, "举报成功，我们会尽快处理")
+	}
+
+	httpx.WriteObject(c, resp, err)
+}
+
+// ListReports 获取举报列表（管理员）
+func (h *HTTPHandler) ListReports(c *gin.Context) {
+	var (
+		ctx  = c.Request.Context()
+		req  rest.ListReportsRequest
+		resp *rest.ListReportsResponse
+		err  error
+	)
+
+	if err = c.Bind(&req); err != nil {
+		h.logger.Error(ctx, "Invalid list reports request", logger.F("error", err.Error()))
+		resp = h.converter.BuildErrorListReportsResponse("Invalid request format")
+		httpx.WriteObject(c, resp, err)
+		return
+	}
+
+	// 将业务信息添加到context
+	ctx = tracecontext.WithUserID(ctx, req.OperatorId)
+
+	targetType := h.converter.TargetTypeToString(req.TargetType)
+	pageSize := h.svc.PageSize(req.PageSize)
+	reports, total, err := h.svc.ListReports(ctx, req.OperatorId, targetType, req.Status, req.Page, pageSize)
+	if err != nil {
+		h.logger.Error(ctx, "List reports failed", logger.F("error", err.Error()), logger.F("targetType", targetType))
+		resp = h.converter.BuildErrorListReportsResponse(err.Error())
+	} else {
+		h.logger.Info(ctx, "List reports successful", logger.F("targetType", targetType), logger.F("count", len(reports)))
+		resp = h.converter.BuildListReportsResponse(true, "获取举报列表成功", reports, total, req.Page, pageSize)
+	}
+
+	httpx.WriteObject(c, resp, err)
+}
+
+// ResolveReport 处理举报（管理员）
+func (h *HTTPHandler) ResolveReport(c *gin.Context) {
+	var (
+		ctx  = c.Request.Context()
+		req  rest.ResolveReportRequest
+		resp *rest.ResolveReportResponse
+		err  error
+	)
+
+	if err = c.Bind(&req); err != nil {
+		h.logger.Error(ctx, "Invalid resolve report request", logger.F("error", err.Error()))
+		resp = h.converter.BuildErrorResolveReportResponse("Invalid request format")
+		httpx.WriteObject(c, resp, err)
+		return
+	}
+
+	// 将业务信息添加到context
+	ctx = tracecontext.WithUserID(ctx, req.OperatorId)
+
+	targetType := h.converter.TargetTypeToString(req.TargetType)
+	err = h.svc.ResolveReport(ctx, req.TargetId, targetType, req.OperatorId, req.Action, req.Note)
+	if err != nil {
+		h.logger.Error(ctx, "Resolve report failed", logger.F("error", err.Error()), logger.F("targetID", req.TargetId))
+		resp = h.converter.BuildErrorResolveReportResponse(err.Error())
+	} else {
+		h.logger.Info(ctx, "Resolve report successful", logger.F("targetID", req.TargetId), logger.F("action", req.Action))
+		resp = h.converter.BuildResolveReportResponse(true, "举报处理成功")
+	}
+
+	httpx.WriteObject(c, resp, err)
+}
@@ -22,7 +22,8 @@ func (h *GRPCHandler) createTagImpl(ctx context.Context, req *rest.CreateTagRequ
 
 // getTagsImpl 获取标签列表实现
 func (h *GRPCHandler) getTagsImpl(ctx context.Context, req *rest.GetTagsRequest) (*rest.GetTagsResponse, error) {
-	tags, total, err := h.svc.GetTags(ctx, req.Keyword, req.Page, req.PageSize)
+	pageSize := h.svc.PageSize(req.PageSize)
+	tags, total, err := h.svc.GetTags(ctx, req.Keyword, req.Page, pageSize)
 	if err != nil {
 		h.logger.Error(ctx, "Failed to get tags via gRPC",
 			logger.F("error", err.Error()),
@@ -30,7 +31,7 @@ func (h *GRPCHandler) getTagsImpl(ctx context.Context, req *rest.GetTagsRequest)
 		return h.converter.BuildErrorGetTagsResponse(err.Error()), nil
 	}
 
-	return h.converter.BuildGetTagsResponse(true, "获取成功", tags, total), nil
+	return h.converter.BuildGetTagsResponse(true, "获取成功", tags, total, req.Page, pageSize), nil
 }
 
 // createTopicImpl 创建话题实现
@@ -48,7 +49,8 @@ func (h *GRPCHandler) createTopicImpl(ctx context.Context, req *rest.CreateTopic
 
 // getTopicsImpl 获取话题列表实现
 func (h *GRPCHandler) getTopicsImpl(ctx context.Context, req *rest.GetTopicsRequest) (*rest.GetTopicsResponse, error) {
-	topics, total, err := h.svc.GetTopics(ctx, req.Keyword, req.HotOnly, req.Page, req.PageSize)
+	pageSize := h.svc.PageSize(req.PageSize)
+	topics, total, err := h.svc.GetTopics(ctx, req.Keyword, req.HotOnly, req.Page, pageSize)
 	if err != nil {
 		h.logger.Error(ctx, "Failed to get topics via gRPC",
 			logger.F("error", err.Error()),
@@ -56,5 +58,5 @@ func (h *GRPCHandler) getTopicsImpl(ctx context.Context, req *rest.GetTopicsRequ
 		return h.converter.BuildErrorGetTopicsResponse(err.Error()), nil
 	}
 
-	return h.converter.BuildGetTopicsResponse(true, "获取成功", topics, total), nil
+	return h.converter.BuildGetTopicsResponse(true, "获取成功", topics, total, req.Page, pageSize), nil
 }
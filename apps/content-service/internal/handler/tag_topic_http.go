@@ -52,13 +52,14 @@ func (h *HTTPHandler) GetTags(c *gin.Context) {
 		return
 	}
 
-	tags, total, err := h.svc.GetTags(ctx, req.Keyword, req.Page, req.PageSize)
+	pageSize := h.svc.PageSize(req.PageSize)
+	tags, total, err := h.svc.GetTags(ctx, req.Keyword, req.Page, pageSize)
 	if err != nil {
 		h.logger.Error(ctx, "Get tags failed", logger.F("error", err.Error()))
 		resp = h.converter.BuildErrorGetTagsResponse(err.Error())
 	} else {
 		h.logger.Info(ctx, "Get tags successful", logger.F("count", len(tags)))
-		resp = h.converter.BuildGetTagsResponse(true, "获取标签列表成功", tags, total)
+		resp = h.converter.BuildGetTagsResponse(true, "获取标签列表成功", tags, total, req.Page, pageSize)
 	}
 
 	httpx.WriteObject(c, resp, err)
@@ -108,13 +109,70 @@ func (h *HTTPHandler) GetTopics(c *gin.Context) {
 		return
 	}
 
-	topics, total, err := h.svc.GetTopics(ctx, req.Keyword, req.HotOnly, req.Page, req.PageSize)
+	pageSize := h.svc.PageSize(req.PageSize)
+	topics, total, err := h.svc.GetTopics(ctx, req.Keyword, req.HotOnly, req.Page, pageSize)
 	if err != nil {
 		h.logger.Error(ctx, "Get topics failed", logger.F("error", err.Error()))
 		resp = h.converter.BuildErrorGetTopicsResponse(err.Error())
 	} else {
 		h.logger.Info(ctx, "Get topics successful", logger.F("count", len(topics)))
-		resp = h.converter.BuildGetTopicsResponse(true, "获取话题列表成功", topics, total)
+		resp = h.converter.BuildGetTopicsResponse(true, "获取话题列表成功", topics, total, req.Page, pageSize)
+	}
+
+	httpx.WriteObject(c, resp, err)
+}
+
+// GetTrendingTags 获取近期热门标签
+func (h *HTTPHandler) GetTrendingTags(c *gin.Context) {
+	var (
+		ctx  = c.Request.Context()
+		req  rest.GetTrendingTagsRequest
+		resp *rest.GetTrendingTagsResponse
+		err  error
+	)
+
+	if err = c.Bind(&req); err != nil {
+		h.logger.Error(ctx, "Invalid get trending tags request", logger.F("error", err.Error()))
+		resp = h.converter.BuildErrorGetTrendingTagsResponse("Invalid request format")
+		httpx.WriteObject(c, resp, err)
+		return
+	}
+
+	tags, err := h.svc.GetTrendingTags(ctx, req.Limit)
+	if err != nil {
+		h.logger.Error(ctx, "Get trending tags failed", logger.F("error", err.Error()))
+		resp = h.converter.BuildErrorGetTrendingTagsResponse(err.Error())
+	} else {
+		h.logger.Info(ctx, "Get trending tags successful", logger.F("count", len(tags)))
+		resp = h.converter.BuildGetTrendingTagsResponse(true, "获取热门标签成功", tags)
+	}
+
+	httpx.WriteObject(c, resp, err)
+}
+
+// GetTrendingTopics 获取近期热门话题
+func (h *HTTPHandler) GetTrendingTopics(c *gin.Context) {
+	var (
+		ctx  = c.Request.Context()
+		req  rest.GetTrendingTopicsRequest
+		resp *rest.GetTrendingTopicsResponse
+		err  error
+	)
+
+	if err = c.Bind(&req); err != nil {
+		h.logger.Error(ctx, "Invalid get trending topics request", logger.F("error", err.Error()))
+		resp = h.converter.BuildErrorGetTrendingTopicsResponse("Invalid request format")
+		httpx.WriteObject(c, resp, err)
+		return
+	}
+
+	topics, err := h.svc.GetTrendingTopics(ctx, req.Limit)
+	if err != nil {
+		h.logger.Error(ctx, "Get trending topics failed", logger.F("error", err.Error()))
+		resp = h.converter.BuildErrorGetTrendingTopicsResponse(err.Error())
+	} else {
+		h.logger.Info(ctx, "Get trending topics successful", logger.F("count", len(topics)))
+		resp = h.converter.BuildGetTrendingTopicsResponse(true, "获取热门话题成功", topics)
 	}
 
 	httpx.WriteObject(c, resp, err)
@@ -1,11 +1,5 @@
 package model
 
-// 默认配置
-const (
-	DefaultPageSize = 20
-	MaxPageSize     = 100
-)
-
 // 内容类型
 const (
 	ContentTypeText     = "text"     // 纯文本
@@ -25,6 +19,27 @@ const (
 	ContentStatusDeleted   = "deleted"   // 已删除
 )
 
+// 内容可见范围
+const (
+	VisibilityPublic  = "public"  // 所有人可见
+	VisibilityFriends = "friends" // 仅作者好友可见，作者本人始终可见
+)
+
+// 用户内容列表排序方式
+const (
+	UserContentSortNewest     = "newest"      // 按创建时间倒序
+	UserContentSortMostViewed = "most_viewed" // 按浏览数倒序
+	UserContentSortMostLiked  = "most_liked"  // 按点赞数倒序
+)
+
+// 个人主页置顶
+const (
+	MaxProfilePinnedContents = 3                  // 每个作者最多置顶的内容数
+	CacheKeyProfilePinLock   = "content:pin:lock" // 置顶操作锁 content:pin:lock:{authorID}，保证并发置顶不超过上限
+	ProfilePinLockTTLSeconds = 5                  // 置顶锁过期时间
+	ProfilePinLockWaitMillis = 1000               // 置顶锁被占用时的最长等待时间
+)
+
 // 排序字段
 const (
 	SortByCreatedAt   = "created_at"
@@ -53,6 +68,8 @@ const (
 	CommentStatusApproved = "approved" // 已通过
 	CommentStatusRejected = "rejected" // 已拒绝
 	CommentStatusDeleted  = "deleted"  // 已删除
+	CommentStatusHidden   = "hidden"   // 举报过多隐藏待审核
+	CommentStatusHeld     = "held"     // 命中违禁词拦截，等待人工复核
 )
 
 // 互动类型
@@ -61,6 +78,62 @@ const (
 	InteractionTypeFavorite = "favorite" // 收藏
 	InteractionTypeShare    = "share"    // 分享
 	InteractionTypeRepost   = "repost"   // 转发
+	InteractionTypeReaction = "reaction" // 表情回应，具体表情由ReactionKey区分
+)
+
+// 表情回应限制
+const (
+	MaxReactionKeyLength = 32 // 表情标识最大长度
+)
+
+// 分享目的地类型，记录在分享互动的元数据中
+const (
+	ShareTargetConversation = "conversation" // 站内单聊，ID为对方用户ID
+	ShareTargetGroup        = "group"        // 站内群聊，ID为群ID
+	ShareTargetExternal     = "external"     // 站外渠道，Channel为渠道标识
+
+	MaxShareChannelLength   = 32    // 站外渠道标识最大长度
+	MessageTypeContentShare = 6     // 内容分享消息，content为分享卡片JSON，与logic-service的消息类型一致
+	MaxShareTargetScan      = 10000 // 统计分享目的地时最多读取的最近分享数
+)
+
+// @提及相关常量
+const (
+	MaxMentionUsernameLength = 50                  // 用户名最大长度，与user-service一致，更长的@文本不解析
+	MessageTypeMention       = 104                 // 提及通知消息，content为MentionNotice JSON
+	EventTypeMention         = "mention"           // 提及通知事件，由message-service推送给被提及用户
+	TopicDownlinkMessage     = "downlink_messages" // 下行推送Topic
+)
+
+// 推送优先级通道，与im-gateway的通道名一致：@提及先于群广播推送，点赞通知排在其他推送之后
+const (
+	PushPriorityHigh = "high"
+	PushPriorityBulk = "bulk"
+)
+
+// 点赞通知，属于低优先级通知，由message-service按作者的摘要设置立即推送或合并为摘要
+const (
+	MessageTypeLikeNotice = 108            // 点赞通知消息，content为LikeNotice JSON
+	EventTypeContentLike  = "content_like" // 点赞通知事件
+
+	CacheKeyLikeNoticeSent = "content:like_notice:sent" // 已发送点赞通知的标记 content:like_notice:sent:{targetType}:{targetID}:{userID}
+	LikeNoticeDedupTTL     = 7 * 24 * 3600              // 同一用户反复点赞、取消点赞同一对象时只通知一次的时长（秒）
+)
+
+// 发布限制范围，与user-service一致，all范围的限制由user-service合并判断
+const (
+	BanScopeContent = "content" // 发布内容
+	BanScopeComment = "comment" // 发表评论
+)
+
+// 新账号发布冷却
+const (
+	CacheKeyNewAccountRate       = "content:new_account:rate" // 新账号发布计数 content:new_account:rate:{scope}:{userID}
+	TopicModerationEvents        = "moderation-events"        // 审核事件Topic，供审核后台和滥用监控订阅
+	EventTypeNewAccountLimited   = "new_account_limited"      // 新账号发布被限制
+	EventTypeNewAccountActivity  = "new_account_activity"     // 新账号在冷却期内发布，未超出限制
+	NewAccountReasonVerification = "verification_required"    // 该类发布需要认证或注册满阈值
+	NewAccountReasonRateLimited  = "rate_limited"             // 超出新账号的发布频率
 )
 
 // 评论内容限制
@@ -69,6 +142,45 @@ const (
 	MinCommentLength = 1    // 评论最小长度
 )
 
+// 举报原因
+const (
+	ReportReasonSpam           = "spam"           // 垃圾广告
+	ReportReasonAbuse          = "abuse"          // 辱骂骚扰
+	ReportReasonPornography    = "pornography"    // 色情低俗
+	ReportReasonIllegal        = "illegal"        // 违法违规
+	ReportReasonMisinformation = "misinformation" // 虚假信息
+	ReportReasonOther          = "other"          // 其他
+)
+
+// 举报状态
+const (
+	ReportStatusPending   = "pending"   // 待处理
+	ReportStatusResolved  = "resolved"  // 举报成立
+	ReportStatusDismissed = "dismissed" // 已驳回
+)
+
+// 举报处理动作
+const (
+	ReportActionRemove  = "remove"  // 举报成立，下架目标对象
+	ReportActionDismiss = "dismiss" // 驳回举报，恢复目标对象
+)
+
+// 评论审核动作
+const (
+	CommentModerationActionAutoHide   = "auto_hide"   // 举报达到阈值自动隐藏
+	CommentModerationActionAutoFilter = "auto_filter" // 命中违禁词自动隐藏
+	CommentModerationActionApprove    = "approve"     // 人工复核通过被拦截的评论
+	CommentModerationActionReject     = "reject"      // 人工复核拒绝被拦截的评论
+)
+
+// 举报限制
+const (
+	ReportEscalateThreshold = 5    // 待处理举报数达到阈值后自动隐藏待审核
+	MaxReportNoteLength     = 500  // 举报说明最大长度
+	ReportRateLimitMax      = 20   // 每个举报人在限流窗口内的最大举报次数
+	ReportRateLimitWindow   = 3600 // 举报限流窗口（秒）
+)
+
 // Redis缓存键前缀
 const (
 	CacheKeyContentDetail    = "content:detail"    // 内容详情缓存
@@ -78,6 +190,16 @@ const (
 	CacheKeyUserInteraction  = "interaction:user"  // 用户互动缓存
 	CacheKeyHotContent       = "content:hot"       // 热门内容缓存
 	CacheKeyUserContent      = "user:content"      // 用户内容列表缓存
+	CacheKeyReportRateLimit  = "report:rate_limit" // 举报人限流计数
+
+	CacheKeyInteractionStatsTracked = "interaction:stats:tracked"    // 已缓存互动统计的目标集合，供对账任务遍历
+	CacheKeyFeedRankingWeights      = "content:feed:ranking_weights" // 内容流排序权重运行时覆盖
+	CacheKeyPendingViews            = "content:views:pending"        // 待写入数据库的浏览增量（Hash，字段为内容ID）
+	CacheKeyViewDedup               = "content:views:dedup"          // 浏览去重标记 content:views:dedup:{contentID}:{userID}
+	CacheKeyTrendingTags            = "content:trending:tags"        // 标签近期热度（ZSet，成员为标签ID）
+	CacheKeyTrendingTopics          = "content:trending:topics"      // 话题近期热度（ZSet，成员为话题ID）
+	CacheKeyTrendingDecayedAt       = "content:trending:decayed_at"  // 热度最近一次衰减的时间戳（秒）
+	CacheKeyTopComments             = "content:comments:top"         // 内容详情热门评论（Hash，字段为评论数量）
 )
 
 // 缓存过期时间（秒）
@@ -87,11 +209,104 @@ const (
 	CacheExpireUserAction    = 3600 // 用户行为缓存1小时
 	CacheExpireHotList       = 600  // 热门列表缓存10分钟
 	CacheExpireCommentList   = 180  // 评论列表缓存3分钟
+
+	CacheExpireInteractionStats = 3600 // 互动统计缓存1小时（写穿更新，由对账任务纠偏）
 )
 
-// 批量操作限制
+// 互动统计对账
 const (
-	MaxBatchSize = 100 // 批量操作最大数量
+	StatsReconcileInterval = 300 // 对账间隔（秒）
+)
+
+// 内容详情中可降级的部分，加载失败或超时时返回内容本身并标记缺失的部分
+const (
+	DetailPartTopComments      = "top_comments"
+	DetailPartInteractionStats = "interaction_stats"
+	DetailPartUserInteractions = "user_interactions"
+
+	DefaultDetailCommentLimit = 10  // 默认返回的热门评论数
+	DefaultDetailTimeout      = 800 // 未配置时各部分的整体加载时限（毫秒）
+)
+
+// 内容摘要，按字符计
+const (
+	DefaultExcerptLength    = 120 // 未配置时自动摘要的最大字符数
+	DefaultSummaryMaxLength = 300 // 未配置时作者摘要的最大字符数
+)
+
+// 浏览计数
+const (
+	ViewDedupCooldown = 1800 // 同一用户重复浏览同一内容的去重冷却期（秒）
+	ViewFlushInterval = 30   // 浏览增量写入数据库的间隔（秒）
+)
+
+// 标签和话题热度
+const (
+	TrendingDecayInterval = 600  // 热度衰减间隔（秒）
+	TrendingMinScore      = 0.01 // 衰减后低于该分数的成员被移除
+	DefaultTrendingLimit  = 10
+	MaxTrendingLimit      = 50
+)
+
+// 内容删除清理
+const (
+	TopicContentDeleted      = "content-deleted-events"   // 内容删除事件Topic，按内容ID分区
+	CacheKeyTrendingRevoked  = "content:trending:revoked" // 已扣除热度的已删除内容标记 content:trending:revoked:{contentID}
+	TrendingRevokedMarkerTTL = 7 * 24 * 3600              // 热度扣除标记保留时间（秒），覆盖删除事件可能被重放的时间范围
+)
+
+// 内容审核
+const (
+	SystemOperatorID  = 0  // 系统自动操作的操作者ID
+	ModerationTimeout = 10 // 单次审核流程超时（秒）
+)
+
+// 内容流排序方式
+const (
+	FeedSortTime     = "time"     // 按发布时间，纯时间序便于复现
+	FeedSortHot      = "hot"      // 按点赞和浏览
+	FeedSortTrending = "trending" // 按互动总数
+	FeedSortRanked   = "ranked"   // 综合时效、互动和个性化的加权排序
+)
+
+// 内容流加权排序
+const (
+	FeedRankingCandidateMultiplier = 3   // 候选窗口为页大小的倍数
+	FeedAffinityInteractionLimit   = 200 // 计算用户偏好时参考的最近互动数
+
+	// 互动分数中各项计数的权重
+	EngagementWeightLike     = 1.0
+	EngagementWeightFavorite = 2.0
+	EngagementWeightComment  = 2.0
+	EngagementWeightShare    = 3.0
+	EngagementWeightRepost   = 3.0
+	EngagementWeightView     = 0.1
+)
+
+// 内容流去重
+const (
+	MaxFeedSeenIDs     = 500 // 游标中最多携带的已下发内容ID数
+	MaxFeedFetchRounds = 3   // 单页因去重不足时最多补拉次数
+)
+
+// 内容流已浏览内容
+const (
+	FeedViewedModeOff      = "off"      // 不处理
+	FeedViewedModeExclude  = "exclude"  // 排除已浏览内容
+	FeedViewedModeDownrank = "downrank" // 已浏览内容降权
+
+	CacheKeyFeedViewed       = "content:feed:viewed" // 用户已浏览内容（ZSet，成员为内容ID，分数为浏览时间）content:feed:viewed:{userID}
+	DefaultFeedViewedMax     = 1000                  // 未配置时每个用户最多记录的已浏览内容数
+	DefaultFeedViewedTTLDays = 30                    // 未配置时浏览记录的有效天数
+	DefaultFeedViewedPenalty = 0.3                   // 未配置时降权系数
+)
+
+// 内容分类
+const (
+	DefaultCategoryName        = "uncategorized" // 默认分类，未指定分类的内容归入此分类
+	DefaultCategoryDescription = "未分类"
+	MaxCategoryNameLength      = 50  // 分类名称最大长度
+	MaxCategoryCount           = 100 // 分类总数上限，分类为受控的小集合
 )
 
 // 媒体文件类型
@@ -101,3 +316,11 @@ const (
 	MediaTypeAudio = "audio"
 	MediaTypeFile  = "file"
 )
+
+// 媒体上传
+const (
+	MediaKeyPrefix         = "media" // 对象键前缀，完整格式为 media/{user_id}/{yyyymmdd}/{uuid}{ext}
+	DefaultPresignExpire   = 300     // 默认上传地址有效期（秒）
+	MaxPresignExpire       = 3600    // 上传地址有效期上限（秒）
+	MaxMediaFilenameExtLen = 10      // 保留的文件扩展名最大长度（不含点）
+)
@@ -1,6 +1,9 @@
 package model
 
 import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
 	"time"
 )
 
@@ -10,9 +13,13 @@ type Content struct {
 	AuthorID      int64      `json:"author_id" gorm:"not null;index"`
 	Title         string     `json:"title" gorm:"type:varchar(200);not null"`
 	Content       string     `json:"content" gorm:"type:text"`
+	Summary       string     `json:"summary" gorm:"type:text"` // 作者填写的摘要，为空时由正文自动生成
+	Excerpt       string     `json:"excerpt" gorm:"type:text"` // 列表展示的纯文本摘要，创建和更新时生成
 	Type          string     `json:"type" gorm:"type:varchar(20);not null;index"`
 	Status        string     `json:"status" gorm:"type:varchar(20);not null;index;default:'draft'"`
-	TemplateData  string     `json:"template_data" gorm:"type:text"` // JSON格式的模板数据
+	TemplateData  string     `json:"template_data" gorm:"type:text"`                                     // JSON格式的模板数据
+	CategoryID    int64      `json:"category_id" gorm:"not null;default:0;index"`                        // 主分类，每条内容只属于一个分类
+	Visibility    string     `json:"visibility" gorm:"type:varchar(20);not null;default:'public';index"` // 可见范围：public所有人可见，friends仅作者好友可见
 	ViewCount     int64      `json:"view_count" gorm:"default:0"`
 	LikeCount     int64      `json:"like_count" gorm:"default:0"`     // 点赞数
 	CommentCount  int64      `json:"comment_count" gorm:"default:0"`  // 评论数
@@ -21,11 +28,13 @@ type Content struct {
 	CreatedAt     time.Time  `json:"created_at" gorm:"autoCreateTime"`
 	UpdatedAt     time.Time  `json:"updated_at" gorm:"autoUpdateTime"`
 	PublishedAt   *time.Time `json:"published_at" gorm:"index"`
+	PinnedAt      *time.Time `json:"pinned_at,omitempty" gorm:"->;-:migration"` // 置顶到作者主页的时间，未置顶为nil，列表查询时填充
 
 	// 关联数据
 	MediaFiles []ContentMediaFile `json:"media_files" gorm:"foreignKey:ContentID"`
 	Tags       []ContentTag       `json:"tags" gorm:"many2many:content_tag_relations;"`
 	Topics     []ContentTopic     `json:"topics" gorm:"many2many:content_topic_relations;"`
+	Mentions   []*ContentMention  `json:"mentions,omitempty" gorm:"-"` // 已解析的@提及，按需加载
 }
 
 // TableName .
@@ -33,6 +42,18 @@ func (Content) TableName() string {
 	return "contents"
 }
 
+// ContentProfilePin 置顶到作者个人主页的内容，内容取消发布或删除时一并删除
+type ContentProfilePin struct {
+	ContentID int64     `json:"content_id" gorm:"primaryKey"`
+	AuthorID  int64     `json:"author_id" gorm:"not null;index"`
+	PinnedAt  time.Time `json:"pinned_at" gorm:"not null"`
+}
+
+// TableName .
+func (ContentProfilePin) TableName() string {
+	return "content_profile_pins"
+}
+
 // ContentMediaFile 内容媒体文件
 type ContentMediaFile struct {
 	ID        int64     `json:"id" gorm:"primaryKey;autoIncrement"`
@@ -84,6 +105,21 @@ func (ContentTopic) TableName() string {
 	return "content_topics"
 }
 
+// ContentCategory 内容分类，由管理员维护的受控分类，与自由标签不同，每条内容只有一个主分类
+type ContentCategory struct {
+	ID          int64     `json:"id" gorm:"primaryKey;autoIncrement"`
+	Name        string    `json:"name" gorm:"type:varchar(50);not null;uniqueIndex"`
+	Description string    `json:"description" gorm:"type:varchar(200)"`
+	SortOrder   int32     `json:"sort_order" gorm:"default:0"`
+	CreatedAt   time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt   time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// TableName .
+func (ContentCategory) TableName() string {
+	return "content_categories"
+}
+
 // ContentTagRelation 内容标签关联表
 type ContentTagRelation struct {
 	ContentID int64     `json:"content_id" gorm:"primaryKey"`
@@ -148,6 +184,11 @@ func ValidateContentType(contentType string) bool {
 	return false
 }
 
+// ValidateVisibility 验证内容可见范围
+func ValidateVisibility(visibility string) bool {
+	return visibility == VisibilityPublic || visibility == VisibilityFriends
+}
+
 // ValidateContentStatus 验证内容状态
 func ValidateContentStatus(status string) bool {
 	validStatuses := []string{
@@ -162,6 +203,25 @@ func ValidateContentStatus(status string) bool {
 	return false
 }
 
+// ValidateReportReason 验证举报原因
+func ValidateReportReason(reason string) bool {
+	validReasons := []string{
+		ReportReasonSpam, ReportReasonAbuse, ReportReasonPornography,
+		ReportReasonIllegal, ReportReasonMisinformation, ReportReasonOther,
+	}
+	for _, r := range validReasons {
+		if r == reason {
+			return true
+		}
+	}
+	return false
+}
+
+// HiddenCommentStatuses 不对外展示的评论状态
+func HiddenCommentStatuses() []string {
+	return []string{CommentStatusHidden, CommentStatusHeld, CommentStatusRejected}
+}
+
 // CanTransitionStatus 检查状态转换是否合法
 func CanTransitionStatus(from, to string) bool {
 	// 定义合法的状态转换
@@ -220,6 +280,8 @@ type Comment struct {
 	UserAgent       string    `json:"user_agent" gorm:"type:text"`                                     // 用户代理
 	CreatedAt       time.Time `json:"created_at" gorm:"autoCreateTime"`
 	UpdatedAt       time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+
+	Mentions []*ContentMention `json:"mentions,omitempty" gorm:"-"` // 已解析的@提及，按需加载
 }
 
 // TableName .
@@ -227,14 +289,33 @@ func (Comment) TableName() string {
 	return "comments"
 }
 
-// Interaction 互动模型 - 支持多态关联
+// CommentModerationLog 评论审核日志
+type CommentModerationLog struct {
+	ID          int64     `json:"id" gorm:"primaryKey;autoIncrement"`
+	CommentID   int64     `json:"comment_id" gorm:"not null;index"`
+	FromStatus  string    `json:"from_status" gorm:"type:varchar(20)"`
+	ToStatus    string    `json:"to_status" gorm:"type:varchar(20);not null"`
+	OperatorID  int64     `json:"operator_id" gorm:"not null"` // 0表示系统自动处理
+	Action      string    `json:"action" gorm:"type:varchar(20);not null"`
+	Reason      string    `json:"reason" gorm:"type:text"`
+	ReportCount int64     `json:"report_count" gorm:"default:0"` // 处理时的待处理举报数
+	CreatedAt   time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// TableName .
+func (CommentModerationLog) TableName() string {
+	return "comment_moderation_logs"
+}
+
+// Interaction 互动模型 - 支持多态关联，同一用户对同一目标的同一表情回应由部分唯一索引保证只有一条
 type Interaction struct {
 	ID              int64     `json:"id" gorm:"primaryKey;autoIncrement"`
-	UserID          int64     `json:"user_id" gorm:"not null;index:idx_user_target"`                      // 用户ID
-	TargetID        int64     `json:"target_id" gorm:"not null;index:idx_user_target,idx_target_type"`    // 目标对象ID
-	TargetType      string    `json:"target_type" gorm:"type:varchar(20);not null;index:idx_target_type"` // 目标对象类型
-	InteractionType string    `json:"interaction_type" gorm:"type:varchar(20);not null;index"`            // 互动类型
-	Metadata        string    `json:"metadata" gorm:"type:text"`                                          // JSON格式的元数据
+	UserID          int64     `json:"user_id" gorm:"not null;index:idx_user_target;uniqueIndex:idx_user_reaction,where:interaction_type = 'reaction'"`                      // 用户ID
+	TargetID        int64     `json:"target_id" gorm:"not null;index:idx_user_target,idx_target_type;uniqueIndex:idx_user_reaction,where:interaction_type = 'reaction'"`    // 目标对象ID
+	TargetType      string    `json:"target_type" gorm:"type:varchar(20);not null;index:idx_target_type;uniqueIndex:idx_user_reaction,where:interaction_type = 'reaction'"` // 目标对象类型
+	InteractionType string    `json:"interaction_type" gorm:"type:varchar(20);not null;index"`                                                                              // 互动类型
+	ReactionKey     string    `json:"reaction_key" gorm:"type:varchar(32);not null;default:'';uniqueIndex:idx_user_reaction,where:interaction_type = 'reaction'"`           // 表情回应的表情标识，其他互动类型为空
+	Metadata        string    `json:"metadata" gorm:"type:text"`                                                                                                            // JSON格式的元数据
 	CreatedAt       time.Time `json:"created_at" gorm:"autoCreateTime;index"`
 	UpdatedAt       time.Time `json:"updated_at" gorm:"autoUpdateTime"`
 }
@@ -254,6 +335,8 @@ type InteractionStats struct {
 	ShareCount    int64     `json:"share_count" gorm:"default:0"`
 	RepostCount   int64     `json:"repost_count" gorm:"default:0"`
 	UpdatedAt     time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+
+	ReactionCounts map[string]int64 `json:"reaction_counts,omitempty" gorm:"-"` // 各表情回应计数，由互动记录聚合
 }
 
 // TableName .
@@ -261,6 +344,94 @@ func (InteractionStats) TableName() string {
 	return "interaction_stats"
 }
 
+// ShareTarget 分享目的地，写入分享互动元数据的share_target字段
+type ShareTarget struct {
+	Type    string `json:"type"`              // conversation, group, external
+	ID      int64  `json:"id,omitempty"`      // 单聊为对方用户ID，群聊为群ID
+	Channel string `json:"channel,omitempty"` // 站外渠道，如wechat、weibo
+}
+
+// ShareTargetStat 分享目的地统计
+type ShareTargetStat struct {
+	Target ShareTarget `json:"target"`
+	Count  int64       `json:"count"`
+}
+
+// ShareCard 分享到站内会话时自动发送的内容链接消息
+type ShareCard struct {
+	ContentID int64  `json:"content_id"`
+	Title     string `json:"title"`
+	AuthorID  int64  `json:"author_id"`
+	Type      string `json:"type"`
+}
+
+// ContentMention 内容或评论中已解析的@提及，同一对象对同一用户只记录一次
+type ContentMention struct {
+	ID          int64      `json:"id" gorm:"primaryKey;autoIncrement"`
+	TargetID    int64      `json:"target_id" gorm:"not null;uniqueIndex:idx_mention_target_user"`                    // 内容或评论ID
+	TargetType  string     `json:"target_type" gorm:"type:varchar(20);not null;uniqueIndex:idx_mention_target_user"` // content, comment
+	UserID      int64      `json:"user_id" gorm:"not null;uniqueIndex:idx_mention_target_user;index"`                // 被提及用户ID
+	Username    string     `json:"username" gorm:"type:varchar(50);not null"`                                        // 被提及用户名，客户端据此定位@文本
+	MentionerID int64      `json:"mentioner_id" gorm:"not null"`                                                     // 提及者（作者或评论者）ID
+	NotifiedAt  *time.Time `json:"notified_at"`                                                                      // 通知时间，为空表示尚未通知
+	CreatedAt   time.Time  `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// TableName .
+func (ContentMention) TableName() string {
+	return "content_mentions"
+}
+
+// MentionNotice 提及通知内容，content_id为提及所在的内容，评论中的提及为评论所属内容
+type MentionNotice struct {
+	MentionID   int64  `json:"mention_id"`
+	TargetType  string `json:"target_type"`
+	TargetID    int64  `json:"target_id"`
+	ContentID   int64  `json:"content_id,omitempty"`
+	MentionerID int64  `json:"mentioner_id"`
+}
+
+// LikeNotice 点赞通知内容，content_id为被点赞的内容，评论点赞时为评论所属内容
+type LikeNotice struct {
+	InteractionID int64  `json:"interaction_id"`
+	TargetType    string `json:"target_type"`
+	TargetID      int64  `json:"target_id"`
+	ContentID     int64  `json:"content_id,omitempty"`
+	LikerID       int64  `json:"liker_id"`
+}
+
+// Report 举报记录 - 支持多态关联，同一用户对同一对象只能举报一次
+type Report struct {
+	ID         int64      `json:"id" gorm:"primaryKey;autoIncrement"`
+	TargetID   int64      `json:"target_id" gorm:"not null;uniqueIndex:idx_report_reporter;index:idx_report_target"`                    // 被举报对象ID
+	TargetType string     `json:"target_type" gorm:"type:varchar(20);not null;uniqueIndex:idx_report_reporter;index:idx_report_target"` // 被举报对象类型
+	ReporterID int64      `json:"reporter_id" gorm:"not null;uniqueIndex:idx_report_reporter"`                                          // 举报人ID
+	Reason     string     `json:"reason" gorm:"type:varchar(20);not null;index"`                                                        // 举报原因
+	Note       string     `json:"note" gorm:"type:text"`                                                                                // 补充说明
+	Status     string     `json:"status" gorm:"type:varchar(20);not null;index;default:'pending'"`                                      // 处理状态
+	HandlerID  int64      `json:"handler_id" gorm:"default:0"`                                                                          // 处理人ID
+	Resolution string     `json:"resolution" gorm:"type:text"`                                                                          // 处理说明
+	CreatedAt  time.Time  `json:"created_at" gorm:"autoCreateTime;index"`
+	UpdatedAt  time.Time  `json:"updated_at" gorm:"autoUpdateTime"`
+	ResolvedAt *time.Time `json:"resolved_at"`
+}
+
+// TableName .
+func (Report) TableName() string {
+	return "reports"
+}
+
+// ReportSummary 举报汇总（按被举报对象聚合）
+type ReportSummary struct {
+	TargetID        int64            `json:"target_id"`
+	TargetType      string           `json:"target_type"`
+	ReportCount     int64            `json:"report_count"`
+	ReasonCounts    map[string]int64 `json:"reason_counts"`
+	Status          string           `json:"status"`
+	FirstReportedAt time.Time        `json:"first_reported_at"`
+	LastReportedAt  time.Time        `json:"last_reported_at"`
+}
+
 // StatsUpdate 统计更新结构
 type StatsUpdate struct {
 	TargetID        int64  `json:"target_id"`
@@ -293,11 +464,25 @@ type InteractionQuery struct {
 }
 
 // ContentDetailResult 内容详情聚合结果
+// 评论、互动统计和用户互动状态加载失败或超时时对应字段为空，并在DegradedParts中列出
 type ContentDetailResult struct {
 	Content          *Content
 	TopComments      []*Comment
 	InteractionStats *InteractionStats
 	UserInteractions map[string]bool
+	DegradedParts    []string
+}
+
+// TrendingTag 热门标签及其近期热度分数
+type TrendingTag struct {
+	Tag   *ContentTag
+	Score float64
+}
+
+// TrendingTopic 热门话题及其近期热度分数
+type TrendingTopic struct {
+	Topic *ContentTopic
+	Score float64
 }
 
 // ContentFeedItem 内容流项目
@@ -307,3 +492,121 @@ type ContentFeedItem struct {
 	UserInteractions map[string]bool
 	CommentPreview   int32
 }
+
+// FeedCursor 内容流游标，记录已消费的偏移量和本次会话已下发的内容ID
+// 新内容插入导致偏移后移时，上一页末尾的内容会再次出现在下一页，依靠SeenIDs过滤
+type FeedCursor struct {
+	Offset  int32   `json:"o"`
+	SeenIDs []int64 `json:"s,omitempty"`
+}
+
+// EncodeFeedCursor 将游标编码为不透明字符串，只保留最近的MaxFeedSeenIDs个ID
+func EncodeFeedCursor(cursor *FeedCursor) string {
+	if len(cursor.SeenIDs) > MaxFeedSeenIDs {
+		cursor.SeenIDs = cursor.SeenIDs[len(cursor.SeenIDs)-MaxFeedSeenIDs:]
+	}
+	data, err := json.Marshal(cursor)
+	if err != nil {
+		return ""
+	}
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeFeedCursor 解析游标字符串
+func DecodeFeedCursor(s string) (*FeedCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("无效的游标: %v", err)
+	}
+	var cursor FeedCursor
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return nil, fmt.Errorf("无效的游标: %v", err)
+	}
+	if cursor.Offset < 0 {
+		return nil, fmt.Errorf("无效的游标偏移量: %d", cursor.Offset)
+	}
+	return &cursor, nil
+}
+
+// UserContentQuery 用户内容列表查询条件，零值字段不参与过滤
+type UserContentQuery struct {
+	AuthorID     int64
+	ViewerID     int64
+	Status       string
+	Type         string
+	StartTime    time.Time
+	EndTime      time.Time
+	TagID        int64
+	TopicID      int64
+	SortBy       string
+	Visibilities []string // 查看者可见的范围，由服务层按好友关系确定
+}
+
+// MediaUpload 媒体上传凭证，客户端按Method和Headers将文件上传到UploadURL，完成后使用ObjectURL
+type MediaUpload struct {
+	UploadURL string            `json:"upload_url"`
+	Method    string            `json:"method"`
+	Headers   map[string]string `json:"headers"`
+	ObjectKey string            `json:"object_key"`
+	ObjectURL string            `json:"object_url"`
+	MediaType string            `json:"media_type"`
+	ExpiresAt time.Time         `json:"expires_at"`
+}
+
+// ContentEvent 内容变更事件，发布到content-events
+type ContentEvent struct {
+	EventType  string `json:"event_type"`
+	ContentID  int64  `json:"content_id"`
+	AuthorID   int64  `json:"author_id"`
+	Title      string `json:"title"`
+	Type       string `json:"type"`
+	Status     string `json:"status"`
+	CategoryID int64  `json:"category_id"`
+	Timestamp  int64  `json:"timestamp"`
+}
+
+// CommentEvent 评论变更事件，发布到comment-events
+type CommentEvent struct {
+	EventType  string `json:"event_type"`
+	CommentID  int64  `json:"comment_id"`
+	TargetID   int64  `json:"target_id"`
+	TargetType string `json:"target_type"`
+	UserID     int64  `json:"user_id"`
+	Content    string `json:"content"`
+	ParentID   int64  `json:"parent_id"`
+	Timestamp  int64  `json:"timestamp"`
+}
+
+// InteractionEvent 互动变更事件，发布到interaction-events，Metadata为互动元数据的JSON字符串
+type InteractionEvent struct {
+	EventType       string `json:"event_type"`
+	InteractionID   int64  `json:"interaction_id"`
+	UserID          int64  `json:"user_id"`
+	TargetID        int64  `json:"target_id"`
+	TargetType      string `json:"target_type"`
+	InteractionType string `json:"interaction_type"`
+	ReactionKey     string `json:"reaction_key"`
+	Metadata        string `json:"metadata"`
+	Timestamp       int64  `json:"timestamp"`
+}
+
+// ViewEvent 浏览增量事件，发布到content-view-events
+type ViewEvent struct {
+	EventType  string `json:"event_type"`
+	TargetID   int64  `json:"target_id"`
+	TargetType string `json:"target_type"`
+	Count      int64  `json:"count"`
+	Timestamp  int64  `json:"timestamp"`
+}
+
+// ContentDeletedEvent 内容删除事件，删除时刻的标签和话题随事件携带，硬删除后关联已不可查
+type ContentDeletedEvent struct {
+	ContentID    int64   `json:"content_id"`
+	AuthorID     int64   `json:"author_id"`
+	OperatorID   int64   `json:"operator_id"`
+	TagIDs       []int64 `json:"tag_ids"`
+	TopicIDs     []int64 `json:"topic_ids"`
+	WasPublished bool    `json:"was_published"` // 删除前是否发布过，发布过的内容计入过标签话题热度
+	Hard         bool    `json:"hard"`          // 是否已从数据库删除，软删除时内容保留为deleted状态
+	DeletedAt    int64   `json:"deleted_at"`
+}
@@ -0,0 +1,60 @@
+package moderation
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// HTTPModerator 调用外部审核服务，请求体为Request的JSON，响应体为Result的JSON
+type HTTPModerator struct {
+	endpoint string
+	client   *http.Client
+}
+
+// NewHTTPModerator 创建外部审核服务客户端
+func NewHTTPModerator(endpoint string, timeout time.Duration) (*HTTPModerator, error) {
+	if endpoint == "" {
+		return nil, fmt.Errorf("外部审核服务地址不能为空")
+	}
+	return &HTTPModerator{
+		endpoint: endpoint,
+		client:   &http.Client{Timeout: timeout},
+	}, nil
+}
+
+// Moderate 请求外部审核服务
+func (m *HTTPModerator) Moderate(ctx context.Context, req *Request) (*Result, error) {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("序列化审核请求失败: %v", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, m.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("创建审核请求失败: %v", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	resp, err := m.client.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("请求外部审核服务失败: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("外部审核服务返回错误状态: %d", resp.StatusCode)
+	}
+
+	var result Result
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("解析审核结果失败: %v", err)
+	}
+	if !ValidateDecision(result.Decision) {
+		return nil, fmt.Errorf("无效的审核结论: %s", result.Decision)
+	}
+	return &result, nil
+}
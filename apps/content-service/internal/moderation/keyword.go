@@ -0,0 +1,83 @@
+package moderation
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// KeywordModerator 基于关键词和正则的默认审核实现，拒绝规则优先于标记规则
+type KeywordModerator struct {
+	rejectKeywords []string
+	flagKeywords   []string
+	rejectPattern  *regexp.Regexp
+	flagPattern    *regexp.Regexp
+}
+
+// NewKeywordModerator 创建关键词审核器，关键词以逗号分隔，匹配时不区分大小写
+func NewKeywordModerator(rejectKeywords, flagKeywords, rejectPattern, flagPattern string) (*KeywordModerator, error) {
+	m := &KeywordModerator{
+		rejectKeywords: splitKeywords(rejectKeywords),
+		flagKeywords:   splitKeywords(flagKeywords),
+	}
+
+	var err error
+	if m.rejectPattern, err = compilePattern(rejectPattern); err != nil {
+		return nil, fmt.Errorf("拒绝规则正则无效: %v", err)
+	}
+	if m.flagPattern, err = compilePattern(flagPattern); err != nil {
+		return nil, fmt.Errorf("标记规则正则无效: %v", err)
+	}
+	return m, nil
+}
+
+// Moderate 检查标题、正文和模板数据
+func (m *KeywordModerator) Moderate(ctx context.Context, req *Request) (*Result, error) {
+	text := strings.Join([]string{req.Title, req.Content, req.TemplateData}, "\n")
+	lower := strings.ToLower(text)
+
+	if keyword := matchKeyword(lower, m.rejectKeywords); keyword != "" {
+		return &Result{Decision: DecisionReject, Reason: fmt.Sprintf("包含违禁词: %s", keyword)}, nil
+	}
+	if m.rejectPattern != nil && m.rejectPattern.MatchString(text) {
+		return &Result{Decision: DecisionReject, Reason: "命中拒绝规则"}, nil
+	}
+	if keyword := matchKeyword(lower, m.flagKeywords); keyword != "" {
+		return &Result{Decision: DecisionFlag, Reason: fmt.Sprintf("包含敏感词: %s", keyword)}, nil
+	}
+	if m.flagPattern != nil && m.flagPattern.MatchString(text) {
+		return &Result{Decision: DecisionFlag, Reason: "命中标记规则"}, nil
+	}
+	return &Result{Decision: DecisionApprove}, nil
+}
+
+// splitKeywords 解析逗号分隔的关键词列表
+func splitKeywords(spec string) []string {
+	var keywords []string
+	for _, keyword := range strings.Split(spec, ",") {
+		keyword = strings.ToLower(strings.TrimSpace(keyword))
+		if keyword != "" {
+			keywords = append(keywords, keyword)
+		}
+	}
+	return keywords
+}
+
+// compilePattern 编译正则，空字符串表示不启用
+func compilePattern(pattern string) (*regexp.Regexp, error) {
+	if pattern == "" {
+		return nil, nil
+	}
+	return regexp.Compile(pattern)
+}
+
+// matchKeyword 返回第一个命中的关键词
+func matchKeyword(text string, keywords []string) string {
+	for _, keyword := range keywords {
+		if strings.Contains(text, keyword) {
+			return keyword
+		}
+	}
+	return ""
+}
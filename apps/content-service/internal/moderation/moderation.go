@@ -0,0 +1,71 @@
+package moderation
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"goim-social/pkg/config"
+)
+
+// 审核结论
+const (
+	DecisionApprove = "approve" // 通过，自动发布
+	DecisionReject  = "reject"  // 拒绝，记录原因
+	DecisionFlag    = "flag"    // 标记，保持待审核等待人工复核
+)
+
+// Request 待审核内容
+type Request struct {
+	ContentID    int64  `json:"content_id"`
+	AuthorID     int64  `json:"author_id"`
+	Type         string `json:"type"`
+	Title        string `json:"title"`
+	Content      string `json:"content"`
+	TemplateData string `json:"template_data,omitempty"`
+}
+
+// Result 审核结果
+type Result struct {
+	Decision string `json:"decision"`
+	Reason   string `json:"reason"`
+}
+
+// Moderator 内容审核接口，可替换为外部审核服务
+type Moderator interface {
+	Moderate(ctx context.Context, req *Request) (*Result, error)
+}
+
+// NewFromConfig 根据配置创建审核器，未启用时返回nil表示不审核
+func NewFromConfig(cfg config.ModerationConfig) (Moderator, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+
+	switch cfg.Provider {
+	case "", "keyword":
+		m, err := NewKeywordModerator(cfg.RejectKeywords, cfg.FlagKeywords, cfg.RejectPattern, cfg.FlagPattern)
+		if err != nil {
+			return nil, err
+		}
+		return m, nil
+	case "http":
+		m, err := NewHTTPModerator(cfg.Endpoint, time.Duration(cfg.Timeout)*time.Millisecond)
+		if err != nil {
+			return nil, err
+		}
+		return m, nil
+	default:
+		return nil, fmt.Errorf("未知的审核实现: %s", cfg.Provider)
+	}
+}
+
+// ValidateDecision 验证审核结论
+func ValidateDecision(decision string) bool {
+	switch decision {
+	case DecisionApprove, DecisionReject, DecisionFlag:
+		return true
+	default:
+		return false
+	}
+}
@@ -9,6 +9,7 @@ import (
 
 	"goim-social/apps/content-service/internal/model"
 	tracecontext "goim-social/pkg/context"
+	"goim-social/pkg/httpx"
 	"goim-social/pkg/logger"
 	"goim-social/pkg/telemetry"
 )
@@ -40,50 +41,59 @@ func (s *Service) GetContentDetail(ctx context.Context, contentID, userID int64,
 	}
 
 	if commentLimit <= 0 {
-		commentLimit = 10 // 默认返回10条评论
+		commentLimit = model.DefaultDetailCommentLimit
 	}
+	commentLimit = s.paging.PageSize32(commentLimit)
 
-	// 聚合查询内容详情
-	content, comments, stats, userInteractions, err := s.dao.GetContentWithDetails(ctx, contentID, userID, commentLimit)
+	// 评论、互动统计和用户互动状态只依赖内容ID，与内容本身并行加载，拉黑双方的评论不返回
+	hidden := s.hiddenAuthors(ctx, userID)
+	parts := s.loadContentDetailParts(ctx, contentID, userID, commentLimit, hidden)
+	defer parts.cancel()
+
+	content, err := s.dao.GetContent(ctx, contentID)
 	if err != nil {
 		span.RecordError(err)
-		span.SetStatus(codes.Error, "failed to get content details")
+		span.SetStatus(codes.Error, "failed to get content")
 		return nil, fmt.Errorf("获取内容详情失败: %v", err)
 	}
+	if hidden.Has(content.AuthorID) {
+		span.SetStatus(codes.Error, "author blocked")
+		return nil, fmt.Errorf("获取内容详情失败: 内容不存在")
+	}
 
-	// 增加浏览次数（异步执行，不影响主流程）
-	go func() {
-		if err := s.dao.IncrementViewCount(context.Background(), contentID); err != nil {
-			s.logger.Error(context.Background(), "Failed to increment view count",
-				logger.F("contentID", contentID),
-				logger.F("error", err.Error()))
-		}
-	}()
-
-	result := &model.ContentDetailResult{
-		Content:          content,
-		TopComments:      comments,
-		InteractionStats: stats,
-		UserInteractions: userInteractions,
+	// 记录浏览（去重后累加到待写入增量，不在读取路径上写库），返回含待写入增量的近似浏览数
+	s.recordView(ctx, contentID, content.AuthorID, userID)
+	s.applyPendingViews(ctx, content)
+	s.attachContentMentions(ctx, content)
+
+	result := parts.wait()
+	result.Content = content
+	if len(result.DegradedParts) > 0 {
+		span.SetAttributes(attribute.StringSlice("content.degraded_parts", result.DegradedParts))
+		s.logger.Warn(ctx, "Content detail returned partially",
+			logger.F("contentID", contentID),
+			logger.F("degradedParts", result.DegradedParts))
 	}
 
 	span.SetAttributes(
 		attribute.String("content.title", content.Title),
-		attribute.Int("content.comment_count", len(comments)),
-		attribute.Int64("content.like_count", stats.LikeCount),
+		attribute.Int("content.comment_count", len(result.TopComments)),
 	)
 
 	s.logger.Info(ctx, "Content detail retrieved successfully",
 		logger.F("contentID", contentID),
 		logger.F("userID", userID),
-		logger.F("commentCount", len(comments)))
+		logger.F("commentCount", len(result.TopComments)))
 
 	span.SetStatus(codes.Ok, "content detail retrieved successfully")
 	return result, nil
 }
 
 // GetContentFeed 获取内容流
-func (s *Service) GetContentFeed(ctx context.Context, userID int64, contentType, sortBy string, page, pageSize int32) ([]*model.ContentFeedItem, int64, error) {
+// 携带cursor时忽略page，按游标偏移量继续拉取，并过滤本次会话中已下发过的内容。
+// 转发不会产生新的内容记录，按内容ID去重即可避免同一内容在流中重复出现。
+// viewedMode指定用户已浏览内容的处理方式，为空时使用配置
+func (s *Service) GetContentFeed(ctx context.Context, userID int64, contentType, sortBy string, page, pageSize int32, cursor, viewedMode string) ([]*model.ContentFeedItem, int64, string, error) {
 	// 开始OpenTelemetry span
 	ctx, span := telemetry.StartSpan(ctx, "content.service.GetContentFeed")
 	defer span.End()
@@ -106,32 +116,124 @@ func (s *Service) GetContentFeed(ctx context.Context, userID int64, contentType,
 	if page <= 0 {
 		page = 1
 	}
-	if pageSize <= 0 || pageSize > model.MaxBatchSize {
-		pageSize = model.DefaultPageSize
-	}
+	pageSize = s.paging.PageSize32(pageSize)
 	if sortBy == "" {
-		sortBy = "time"
+		sortBy = model.FeedSortTime
+	}
+
+	// 解析游标，携带游标时以游标记录的偏移量为准
+	offset := (page - 1) * pageSize
+	seen := make(map[int64]bool)
+	var seenIDs []int64
+	if cursor != "" {
+		feedCursor, err := model.DecodeFeedCursor(cursor)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, "invalid feed cursor")
+			return nil, 0, "", httpx.InvalidArgument(err)
+		}
+		offset = feedCursor.Offset
+		seenIDs = feedCursor.SeenIDs
+		for _, id := range seenIDs {
+			seen[id] = true
+		}
 	}
 
-	// 获取内容流数据
-	contents, stats, userInteractionsMap, err := s.dao.GetContentFeed(ctx, userID, contentType, sortBy, page, pageSize)
+	// 已浏览内容按处理方式排除或降权，与本次会话去重相互独立
+	viewedMode, err := s.feedViewedMode(viewedMode)
+	if err != nil {
+		span.SetStatus(codes.Error, "invalid viewed mode")
+		return nil, 0, "", httpx.InvalidArgument(err)
+	}
+	viewed := s.loadViewedContents(ctx, userID, viewedMode)
+	span.SetAttributes(
+		attribute.String("feed.viewed_mode", viewedMode),
+		attribute.Int("feed.viewed_count", len(viewed)),
+	)
+
+	// 获取内容流数据，拉黑双方的内容在查询时排除
+	excludeAuthorIDs := s.hiddenAuthors(ctx, userID).IDs()
+	var contents []*model.Content
+	userInteractionsMap := make(map[int64]map[string]bool)
+	exhausted := false
+	duplicateCount := 0
+	viewedSkipped := 0
+	if sortBy == model.FeedSortRanked {
+		// 加权排序，候选窗口中已下发的内容在排序前过滤；排除已浏览内容时一并过滤，偏移量可越过这些内容
+		skip, demote := seen, map[int64]bool(nil)
+		switch viewedMode {
+		case model.FeedViewedModeExclude:
+			skip = make(map[int64]bool, len(seen)+len(viewed))
+			for id := range seen {
+				skip[id] = true
+			}
+			for id := range viewed {
+				skip[id] = true
+			}
+		case model.FeedViewedModeDownrank:
+			demote = viewed
+		}
+		ranked, rankedInteractions, nextOffset, end, err := s.fetchRankedFeed(ctx, userID, contentType, offset, pageSize, skip, demote, excludeAuthorIDs)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, "failed to get ranked content feed")
+			return nil, 0, "", httpx.Unavailable(fmt.Errorf("获取内容流失败: %v", err))
+		}
+		offset, exhausted = nextOffset, end
+		for _, content := range ranked {
+			seen[content.ID] = true
+			seenIDs = append(seenIDs, content.ID)
+			contents = append(contents, content)
+		}
+		userInteractionsMap = rankedInteractions
+	} else {
+		// 去重后不足一页时继续向后补拉
+		for round := 0; round < model.MaxFeedFetchRounds && len(contents) < int(pageSize) && !exhausted; round++ {
+			limit := pageSize - int32(len(contents))
+			batch, batchInteractions, err := s.dao.GetContentFeed(ctx, userID, contentType, sortBy, offset, limit, excludeAuthorIDs)
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, "failed to get content feed")
+				return nil, 0, "", httpx.Unavailable(fmt.Errorf("获取内容流失败: %v", err))
+			}
+
+			offset += int32(len(batch))
+			exhausted = len(batch) < int(limit)
+			for _, content := range batch {
+				if seen[content.ID] {
+					duplicateCount++
+					continue
+				}
+				if viewedMode == model.FeedViewedModeExclude && viewed[content.ID] {
+					viewedSkipped++
+					continue
+				}
+				seen[content.ID] = true
+				seenIDs = append(seenIDs, content.ID)
+				contents = append(contents, content)
+			}
+			for contentID, interactions := range batchInteractions {
+				userInteractionsMap[contentID] = interactions
+			}
+		}
+		if viewedMode == model.FeedViewedModeDownrank {
+			contents = demoteViewed(contents, viewed)
+		}
+	}
+
+	// 批量获取互动统计（优先读缓存）
+	statsMap, err := s.batchGetInteractionStats(ctx, contentIDsOf(contents), model.TargetTypeContent)
 	if err != nil {
 		span.RecordError(err)
-		span.SetStatus(codes.Error, "failed to get content feed")
-		return nil, 0, fmt.Errorf("获取内容流失败: %v", err)
+		span.SetStatus(codes.Error, "failed to get interaction stats")
+		return nil, 0, "", httpx.Unavailable(fmt.Errorf("获取互动统计失败: %v", err))
 	}
 
 	// 构建内容流项目
 	feedItems := make([]*model.ContentFeedItem, len(contents))
 	for i, content := range contents {
 		// 查找对应的统计数据
-		var contentStats *model.InteractionStats
-		for _, stat := range stats {
-			if stat.TargetID == content.ID && stat.TargetType == model.TargetTypeContent {
-				contentStats = stat
-				break
-			}
-		}
+		contentStats := statsMap[content.ID]
 		if contentStats == nil {
 			contentStats = &model.InteractionStats{
 				TargetID:   content.ID,
@@ -157,13 +259,21 @@ func (s *Service) GetContentFeed(ctx context.Context, userID int64, contentType,
 	}
 
 	// 计算总数（简化实现，实际应该从数据库查询）
-	total := int64(len(contents))
-	if len(contents) == int(pageSize) {
-		total = int64(page * pageSize) // 估算值
+	total := int64(offset) // 估算值
+
+	// 已到末尾时不再返回游标
+	nextCursor := ""
+	if !exhausted {
+		nextCursor = model.EncodeFeedCursor(&model.FeedCursor{
+			Offset:  offset,
+			SeenIDs: seenIDs,
+		})
 	}
 
 	span.SetAttributes(
 		attribute.Int("feed.item_count", len(feedItems)),
+		attribute.Int("feed.duplicate_count", duplicateCount),
+		attribute.Int("feed.viewed_skipped", viewedSkipped),
 		attribute.Int64("feed.total", total),
 	)
 
@@ -171,10 +281,11 @@ func (s *Service) GetContentFeed(ctx context.Context, userID int64, contentType,
 		logger.F("userID", userID),
 		logger.F("contentType", contentType),
 		logger.F("sortBy", sortBy),
-		logger.F("itemCount", len(feedItems)))
+		logger.F("itemCount", len(feedItems)),
+		logger.F("duplicateCount", duplicateCount))
 
 	span.SetStatus(codes.Ok, "content feed retrieved successfully")
-	return feedItems, total, nil
+	return feedItems, total, nextCursor, nil
 }
 
 // GetTrendingContent 获取热门内容
@@ -191,32 +302,32 @@ func (s *Service) GetTrendingContent(ctx context.Context, timeRange, contentType
 	)
 
 	// 参数验证和默认值设置
-	if limit <= 0 || limit > model.MaxBatchSize {
-		limit = 20 // 默认返回20条热门内容
-	}
+	limit = s.paging.PageSize32(limit)
 	if timeRange == "" {
 		timeRange = "day" // 默认一天内的热门内容
 	}
 
 	// 获取热门内容数据
-	contents, stats, err := s.dao.GetTrendingContent(ctx, timeRange, contentType, limit)
+	contents, err := s.dao.GetTrendingContent(ctx, timeRange, contentType, limit)
 	if err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, "failed to get trending content")
 		return nil, fmt.Errorf("获取热门内容失败: %v", err)
 	}
 
+	// 批量获取互动统计（优先读缓存）
+	statsMap, err := s.batchGetInteractionStats(ctx, contentIDsOf(contents), model.TargetTypeContent)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to get interaction stats")
+		return nil, fmt.Errorf("获取互动统计失败: %v", err)
+	}
+
 	// 构建内容流项目
 	feedItems := make([]*model.ContentFeedItem, len(contents))
 	for i, content := range contents {
 		// 查找对应的统计数据
-		var contentStats *model.InteractionStats
-		for _, stat := range stats {
-			if stat.TargetID == content.ID && stat.TargetType == model.TargetTypeContent {
-				contentStats = stat
-				break
-			}
-		}
+		contentStats := statsMap[content.ID]
 		if contentStats == nil {
 			contentStats = &model.InteractionStats{
 				TargetID:   content.ID,
@@ -264,8 +375,8 @@ func (s *Service) DeleteContentWithRelated(ctx context.Context, contentID, userI
 		return fmt.Errorf("内容ID无效")
 	}
 
-	// 获取内容信息进行权限检查
-	content, err := s.dao.GetContent(ctx, contentID)
+	// 获取内容信息进行权限检查，删除事件需要携带删除前的标签和话题
+	content, err := s.dao.GetContentWithRelations(ctx, contentID)
 	if err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, "content not found")
@@ -278,8 +389,9 @@ func (s *Service) DeleteContentWithRelated(ctx context.Context, contentID, userI
 		return fmt.Errorf("无权限删除此内容")
 	}
 
-	// 执行级联删除
-	if err := s.dao.DeleteContentWithRelated(ctx, contentID); err != nil {
+	// 执行级联删除，内容事件和删除事件与删除在同一事务中写入发件箱，热度和搜索索引由删除事件清理
+	deleted := newContentDeletedEvent(content, userID, true)
+	if err := s.dao.DeleteContentWithRelated(ctx, contentID, contentEvent("delete", content), contentDeletedOutbox(deleted)); err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, "failed to delete content with related data")
 		return fmt.Errorf("删除内容失败: %v", err)
@@ -288,8 +400,7 @@ func (s *Service) DeleteContentWithRelated(ctx context.Context, contentID, userI
 	// 清除相关缓存
 	go s.clearContentCache(context.Background(), contentID)
 
-	// 发送事件到消息队列
-	go s.publishContentEvent(context.Background(), "delete", content)
+	s.onContentDeleted(ctx, deleted)
 
 	s.logger.Info(ctx, "Content with related data deleted successfully",
 		logger.F("contentID", contentID),
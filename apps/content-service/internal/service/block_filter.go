@@ -0,0 +1,21 @@
+package service
+
+import (
+	"context"
+
+	"goim-social/pkg/blocklist"
+	"goim-social/pkg/logger"
+)
+
+// hiddenAuthors 返回与查看者有拉黑关系的用户，双方互相看不到对方的内容和评论
+// 查询失败时不屏蔽，避免拉黑缓存异常影响正常浏览
+func (s *Service) hiddenAuthors(ctx context.Context, viewerID int64) blocklist.Set {
+	hidden, err := s.blocks.Hidden(ctx, viewerID)
+	if err != nil {
+		s.logger.Warn(ctx, "查询拉黑关系失败，不屏蔽",
+			logger.F("viewerID", viewerID),
+			logger.F("error", err.Error()))
+		return nil
+	}
+	return hidden
+}
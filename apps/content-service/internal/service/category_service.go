@@ -0,0 +1,309 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+
+	"goim-social/apps/content-service/internal/model"
+	tracecontext "goim-social/pkg/context"
+	"goim-social/pkg/httpx"
+	"goim-social/pkg/logger"
+	"goim-social/pkg/telemetry"
+)
+
+// ==================== 分类相关业务逻辑 ====================
+
+// InitCategories 确保默认分类存在，并将历史上未分配分类的内容归入默认分类
+func (s *Service) InitCategories(ctx context.Context) error {
+	category, err := s.dao.EnsureDefaultCategory(ctx)
+	if err != nil {
+		return fmt.Errorf("初始化默认分类失败: %v", err)
+	}
+
+	migrated, err := s.dao.AssignUncategorizedContents(ctx, category.ID)
+	if err != nil {
+		return fmt.Errorf("迁移未分类内容失败: %v", err)
+	}
+	if migrated > 0 {
+		s.logger.Info(ctx, "未分类内容已归入默认分类",
+			logger.F("categoryID", category.ID),
+			logger.F("count", migrated))
+	}
+	return nil
+}
+
+// CreateCategory 创建分类（管理员）
+func (s *Service) CreateCategory(ctx context.Context, operatorID int64, name, description string, sortOrder int32) (*model.ContentCategory, error) {
+	// 开始OpenTelemetry span
+	ctx, span := telemetry.StartSpan(ctx, "content.service.CreateCategory")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.Int64("category.operator_id", operatorID),
+		attribute.String("category.name", name),
+	)
+	ctx = tracecontext.WithUserID(ctx, operatorID)
+
+	if !s.admins[operatorID] {
+		span.SetStatus(codes.Error, "permission denied")
+		return nil, httpx.PermissionDenied(fmt.Errorf("无权限创建分类"))
+	}
+
+	name = strings.TrimSpace(name)
+	if err := validateCategoryName(name); err != nil {
+		span.SetStatus(codes.Error, "invalid category name")
+		return nil, err
+	}
+
+	if existing, err := s.dao.GetCategoryByName(ctx, name); err == nil && existing != nil {
+		span.SetStatus(codes.Error, "category already exists")
+		return nil, fmt.Errorf("分类已存在: %s", name)
+	}
+
+	count, err := s.dao.CountCategories(ctx)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to count categories")
+		return nil, fmt.Errorf("统计分类数量失败: %v", err)
+	}
+	if count >= model.MaxCategoryCount {
+		span.SetStatus(codes.Error, "too many categories")
+		return nil, fmt.Errorf("分类数量已达上限: %d", model.MaxCategoryCount)
+	}
+
+	category := &model.ContentCategory{
+		Name:        name,
+		Description: description,
+		SortOrder:   sortOrder,
+	}
+	if err := s.dao.CreateCategory(ctx, category); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to create category")
+		return nil, fmt.Errorf("创建分类失败: %v", err)
+	}
+
+	s.logger.Info(ctx, "分类创建成功",
+		logger.F("categoryID", category.ID),
+		logger.F("name", name),
+		logger.F("operatorID", operatorID))
+
+	span.SetStatus(codes.Ok, "category created")
+	return category, nil
+}
+
+// UpdateCategory 更新分类（管理员），默认分类不允许改名
+func (s *Service) UpdateCategory(ctx context.Context, operatorID, categoryID int64, name, description string, sortOrder int32) (*model.ContentCategory, error) {
+	// 开始OpenTelemetry span
+	ctx, span := telemetry.StartSpan(ctx, "content.service.UpdateCategory")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.Int64("category.operator_id", operatorID),
+		attribute.Int64("category.id", categoryID),
+	)
+	ctx = tracecontext.WithUserID(ctx, operatorID)
+
+	if !s.admins[operatorID] {
+		span.SetStatus(codes.Error, "permission denied")
+		return nil, httpx.PermissionDenied(fmt.Errorf("无权限修改分类"))
+	}
+
+	category, err := s.dao.GetCategory(ctx, categoryID)
+	if err != nil {
+		span.SetStatus(codes.Error, "category not found")
+		return nil, fmt.Errorf("分类不存在: %v", err)
+	}
+
+	name = strings.TrimSpace(name)
+	if name != "" && name != category.Name {
+		if category.Name == model.DefaultCategoryName {
+			span.SetStatus(codes.Error, "default category cannot be renamed")
+			return nil, fmt.Errorf("默认分类不允许改名")
+		}
+		if err := validateCategoryName(name); err != nil {
+			span.SetStatus(codes.Error, "invalid category name")
+			return nil, err
+		}
+		if existing, err := s.dao.GetCategoryByName(ctx, name); err == nil && existing != nil {
+			span.SetStatus(codes.Error, "category already exists")
+			return nil, fmt.Errorf("分类已存在: %s", name)
+		}
+		category.Name = name
+	}
+	category.Description = description
+	category.SortOrder = sortOrder
+
+	if err := s.dao.UpdateCategory(ctx, category); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to update category")
+		return nil, fmt.Errorf("更新分类失败: %v", err)
+	}
+
+	s.logger.Info(ctx, "分类更新成功",
+		logger.F("categoryID", categoryID),
+		logger.F("operatorID", operatorID))
+
+	span.SetStatus(codes.Ok, "category updated")
+	return category, nil
+}
+
+// DeleteCategory 删除分类（管理员），分类下的内容归入默认分类
+func (s *Service) DeleteCategory(ctx context.Context, operatorID, categoryID int64) error {
+	// 开始OpenTelemetry span
+	ctx, span := telemetry.StartSpan(ctx, "content.service.DeleteCategory")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.Int64("category.operator_id", operatorID),
+		attribute.Int64("category.id", categoryID),
+	)
+	ctx = tracecontext.WithUserID(ctx, operatorID)
+
+	if !s.admins[operatorID] {
+		span.SetStatus(codes.Error, "permission denied")
+		return httpx.PermissionDenied(fmt.Errorf("无权限删除分类"))
+	}
+
+	category, err := s.dao.GetCategory(ctx, categoryID)
+	if err != nil {
+		span.SetStatus(codes.Error, "category not found")
+		return fmt.Errorf("分类不存在: %v", err)
+	}
+	if category.Name == model.DefaultCategoryName {
+		span.SetStatus(codes.Error, "default category cannot be deleted")
+		return fmt.Errorf("默认分类不允许删除")
+	}
+
+	defaultCategory, err := s.dao.EnsureDefaultCategory(ctx)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to get default category")
+		return fmt.Errorf("获取默认分类失败: %v", err)
+	}
+
+	if err := s.dao.DeleteCategory(ctx, categoryID, defaultCategory.ID); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to delete category")
+		return fmt.Errorf("删除分类失败: %v", err)
+	}
+
+	s.logger.Info(ctx, "分类删除成功，内容已归入默认分类",
+		logger.F("categoryID", categoryID),
+		logger.F("operatorID", operatorID))
+
+	span.SetStatus(codes.Ok, "category deleted")
+	return nil
+}
+
+// GetCategories 获取全部分类
+func (s *Service) GetCategories(ctx context.Context) ([]*model.ContentCategory, error) {
+	categories, err := s.dao.GetCategories(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("获取分类列表失败: %v", err)
+	}
+	return categories, nil
+}
+
+// GetCategoryFeed 获取分类内容流，按发布时间倒序
+func (s *Service) GetCategoryFeed(ctx context.Context, userID, categoryID int64, page, pageSize int32) ([]*model.ContentFeedItem, int64, error) {
+	// 开始OpenTelemetry span
+	ctx, span := telemetry.StartSpan(ctx, "content.service.GetCategoryFeed")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.Int64("feed.user_id", userID),
+		attribute.Int64("feed.category_id", categoryID),
+		attribute.Int("feed.page", int(page)),
+		attribute.Int("feed.page_size", int(pageSize)),
+	)
+	if userID > 0 {
+		ctx = tracecontext.WithUserID(ctx, userID)
+	}
+
+	if page <= 0 {
+		page = 1
+	}
+	pageSize = s.paging.PageSize32(pageSize)
+
+	if _, err := s.dao.GetCategory(ctx, categoryID); err != nil {
+		span.SetStatus(codes.Error, "category not found")
+		return nil, 0, fmt.Errorf("分类不存在: %v", err)
+	}
+
+	contents, total, userInteractionsMap, err := s.dao.GetCategoryFeed(ctx, userID, categoryID, page, pageSize, s.hiddenAuthors(ctx, userID).IDs())
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to get category feed")
+		return nil, 0, fmt.Errorf("获取分类内容流失败: %v", err)
+	}
+
+	// 批量获取互动统计（优先读缓存）
+	statsMap, err := s.batchGetInteractionStats(ctx, contentIDsOf(contents), model.TargetTypeContent)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to get interaction stats")
+		return nil, 0, fmt.Errorf("获取互动统计失败: %v", err)
+	}
+
+	feedItems := make([]*model.ContentFeedItem, len(contents))
+	for i, content := range contents {
+		contentStats := statsMap[content.ID]
+		if contentStats == nil {
+			contentStats = &model.InteractionStats{
+				TargetID:   content.ID,
+				TargetType: model.TargetTypeContent,
+			}
+		}
+
+		userInteractions := userInteractionsMap[content.ID]
+		if userInteractions == nil {
+			userInteractions = make(map[string]bool)
+		}
+
+		feedItems[i] = &model.ContentFeedItem{
+			Content:          content,
+			InteractionStats: contentStats,
+			UserInteractions: userInteractions,
+			CommentPreview:   3, // 预览3条评论
+		}
+	}
+
+	span.SetAttributes(
+		attribute.Int("feed.item_count", len(feedItems)),
+		attribute.Int64("feed.total", total),
+	)
+	span.SetStatus(codes.Ok, "category feed retrieved")
+	return feedItems, total, nil
+}
+
+// resolveCategoryID 校验内容的分类，未指定时归入默认分类
+func (s *Service) resolveCategoryID(ctx context.Context, categoryID int64) (int64, error) {
+	if categoryID <= 0 {
+		category, err := s.dao.EnsureDefaultCategory(ctx)
+		if err != nil {
+			return 0, fmt.Errorf("获取默认分类失败: %v", err)
+		}
+		return category.ID, nil
+	}
+
+	if _, err := s.dao.GetCategory(ctx, categoryID); err != nil {
+		return 0, fmt.Errorf("分类不存在: %d", categoryID)
+	}
+	return categoryID, nil
+}
+
+// validateCategoryName 验证分类名称
+func validateCategoryName(name string) error {
+	if name == "" {
+		return fmt.Errorf("分类名称不能为空")
+	}
+	if utf8.RuneCountInString(name) > model.MaxCategoryNameLength {
+		return fmt.Errorf("分类名称不能超过%d个字符", model.MaxCategoryNameLength)
+	}
+	return nil
+}
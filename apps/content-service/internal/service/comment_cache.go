@@ -0,0 +1,116 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	goredis "github.com/go-redis/redis/v8"
+
+	"goim-social/apps/content-service/internal/model"
+	"goim-social/pkg/blocklist"
+	"goim-social/pkg/logger"
+)
+
+// ==================== 内容详情热门评论缓存 ====================
+// 每个内容一个哈希，字段为请求的评论数量，值为未按拉黑关系过滤的评论列表JSON。
+// 评论新增、删除或审核状态变化时整键删除，点赞数变化引起的排序偏差由短过期时间兜底
+
+// topCommentsCacheKey 内容详情热门评论缓存键
+func topCommentsCacheKey(contentID int64) string {
+	return fmt.Sprintf("%s:%d", model.CacheKeyTopComments, contentID)
+}
+
+// getTopComments 获取内容详情的热门评论，优先读缓存
+// 缓存列表中与查看者有拉黑关系的评论在返回前过滤，过滤后不足一页且缓存列表为满页时回源按拉黑关系查询
+func (s *Service) getTopComments(ctx context.Context, contentID int64, limit int32, hidden blocklist.Set) ([]*model.Comment, error) {
+	comments, ok := s.getCachedTopComments(ctx, contentID, limit)
+	if !ok {
+		var err error
+		comments, err = s.dao.GetTopComments(ctx, contentID, limit, nil)
+		if err != nil {
+			return nil, err
+		}
+		s.attachCommentMentions(ctx, comments)
+		s.setCachedTopComments(ctx, contentID, limit, comments)
+	}
+	if len(hidden) == 0 {
+		return comments, nil
+	}
+
+	visible := blocklist.Filter(comments, hidden, func(comment *model.Comment) int64 { return comment.UserID })
+	if len(visible) == len(comments) || len(comments) < int(limit) {
+		return visible, nil
+	}
+
+	comments, err := s.dao.GetTopComments(ctx, contentID, limit, hidden.IDs())
+	if err != nil {
+		return nil, err
+	}
+	s.attachCommentMentions(ctx, comments)
+	return comments, nil
+}
+
+// getCachedTopComments 读取热门评论缓存，未命中或读取失败时返回false
+func (s *Service) getCachedTopComments(ctx context.Context, contentID int64, limit int32) ([]*model.Comment, bool) {
+	if s.redis == nil {
+		return nil, false
+	}
+
+	data, err := s.redis.GetClient().HGet(ctx, topCommentsCacheKey(contentID), strconv.Itoa(int(limit))).Result()
+	if err != nil {
+		if err != goredis.Nil {
+			s.logger.Warn(ctx, "读取热门评论缓存失败，回退到数据库",
+				logger.F("contentID", contentID),
+				logger.F("error", err.Error()))
+		}
+		return nil, false
+	}
+
+	var comments []*model.Comment
+	if err := json.Unmarshal([]byte(data), &comments); err != nil {
+		s.logger.Warn(ctx, "解析热门评论缓存失败，回退到数据库",
+			logger.F("contentID", contentID),
+			logger.F("error", err.Error()))
+		return nil, false
+	}
+	return comments, true
+}
+
+// setCachedTopComments 回填热门评论缓存，写入失败只记录日志
+func (s *Service) setCachedTopComments(ctx context.Context, contentID int64, limit int32, comments []*model.Comment) {
+	if s.redis == nil {
+		return
+	}
+
+	data, err := json.Marshal(comments)
+	if err != nil {
+		return
+	}
+
+	key := topCommentsCacheKey(contentID)
+	pipe := s.redis.GetClient().TxPipeline()
+	pipe.HSet(ctx, key, strconv.Itoa(int(limit)), data)
+	pipe.Expire(ctx, key, time.Duration(model.CacheExpireCommentList)*time.Second)
+	if _, err := pipe.Exec(ctx); err != nil {
+		s.logger.Warn(ctx, "写入热门评论缓存失败",
+			logger.F("contentID", contentID),
+			logger.F("error", err.Error()))
+	}
+}
+
+// clearTopCommentsCache 清除内容的热门评论缓存
+func (s *Service) clearTopCommentsCache(ctx context.Context, contentID int64) {
+	if s.redis == nil {
+		return
+	}
+
+	key := topCommentsCacheKey(contentID)
+	if err := s.redis.Del(ctx, key); err != nil {
+		s.logger.Error(ctx, "Failed to clear top comments cache",
+			logger.F("cacheKey", key),
+			logger.F("error", err.Error()))
+	}
+}
@@ -0,0 +1,146 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+
+	"goim-social/apps/content-service/internal/model"
+	tracecontext "goim-social/pkg/context"
+	"goim-social/pkg/httpx"
+	"goim-social/pkg/logger"
+	"goim-social/pkg/outbox"
+	"goim-social/pkg/telemetry"
+)
+
+// ==================== 评论复核相关业务逻辑 ====================
+// 命中hold级别违禁词的评论创建即进入复核队列，创建时跳过评论计数、@提及通知和评论创建事件，
+// 管理员复核通过后补做这些步骤；拒绝后评论保持隐藏。举报驳回只恢复因举报隐藏的评论，不会放出复核队列中的评论
+
+// InitHeldComments 将此前被违禁词拦截、仍处于隐藏状态的评论迁移到复核队列
+func (s *Service) InitHeldComments(ctx context.Context) error {
+	migrated, err := s.dao.MarkFilteredCommentsHeld(ctx)
+	if err != nil {
+		return fmt.Errorf("迁移被拦截评论失败: %v", err)
+	}
+	if migrated > 0 {
+		s.logger.Info(ctx, "被拦截评论已进入复核队列", logger.F("count", migrated))
+	}
+	return nil
+}
+
+// ListHeldComments 获取等待复核的评论（管理员），先提交的排在前面
+func (s *Service) ListHeldComments(ctx context.Context, operatorID int64, page, pageSize int32) ([]*model.Comment, int64, error) {
+	if !s.admins[operatorID] {
+		return nil, 0, httpx.PermissionDenied(fmt.Errorf("无权限查看待复核评论"))
+	}
+	if page <= 0 {
+		page = 1
+	}
+	pageSize = s.paging.PageSize32(pageSize)
+
+	return s.dao.GetCommentsByStatus(ctx, model.CommentStatusHeld, page, pageSize)
+}
+
+// ReviewComment 复核被违禁词拦截的评论（管理员）
+// 通过后评论对外可见，并补做评论计数、@提及通知和评论创建事件；拒绝后评论保持隐藏
+func (s *Service) ReviewComment(ctx context.Context, commentID, operatorID int64, action, note string) error {
+	// 开始OpenTelemetry span
+	ctx, span := telemetry.StartSpan(ctx, "content.service.ReviewComment")
+	defer span.End()
+
+	// 设置span属性
+	span.SetAttributes(
+		attribute.Int64("comment.id", commentID),
+		attribute.Int64("comment.operator_id", operatorID),
+		attribute.String("comment.review_action", action),
+	)
+
+	// 将业务信息添加到context
+	ctx = tracecontext.WithUserID(ctx, operatorID)
+
+	if !s.admins[operatorID] {
+		span.SetStatus(codes.Error, "permission denied")
+		return httpx.PermissionDenied(fmt.Errorf("无权限复核评论"))
+	}
+
+	var newStatus string
+	switch action {
+	case model.CommentModerationActionApprove:
+		newStatus = model.CommentStatusApproved
+	case model.CommentModerationActionReject:
+		newStatus = model.CommentStatusRejected
+	default:
+		span.SetStatus(codes.Error, "invalid action")
+		return httpx.InvalidArgument(fmt.Errorf("无效的复核动作: %s", action))
+	}
+
+	comment, err := s.dao.GetComment(ctx, commentID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "comment not found")
+		return fmt.Errorf("评论不存在")
+	}
+	if comment.Status != model.CommentStatusHeld {
+		span.SetStatus(codes.Error, "comment not held")
+		return httpx.Conflict(fmt.Errorf("评论不在待复核状态"))
+	}
+
+	// 状态变更与评论创建事件在同一事务中写入，并发复核同一评论时只有一次生效
+	oldStatus := comment.Status
+	comment.Status = newStatus
+	var event outbox.Builder
+	if newStatus == model.CommentStatusApproved {
+		event = commentEvent("create", comment)
+	}
+	updated, err := s.dao.UpdateHeldCommentStatus(ctx, commentID, newStatus, event)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to update comment status")
+		return fmt.Errorf("复核评论失败: %v", err)
+	}
+	if !updated {
+		span.SetStatus(codes.Error, "comment not held")
+		return httpx.Conflict(fmt.Errorf("评论不在待复核状态"))
+	}
+
+	// 记录审核日志，通过时解析@提及并通知被提及用户
+	s.recordCommentModeration(ctx, comment, oldStatus, operatorID, action, note, 0)
+
+	if newStatus == model.CommentStatusApproved {
+		// 评论创建时未计入评论数，复核通过后补计
+		go s.updateCommentCounts(context.Background(), comment)
+
+		// 评论事件已写入发件箱，唤醒转发器发布
+		s.relay.Notify()
+	}
+
+	s.logger.Info(ctx, "Held comment reviewed",
+		logger.F("commentID", commentID),
+		logger.F("operatorID", operatorID),
+		logger.F("action", action))
+
+	span.SetStatus(codes.Ok, "comment reviewed successfully")
+	return nil
+}
+
+// commentCounted 评论是否已计入目标对象的评论数
+// 违禁词拦截的评论复核通过后才计入，复核前被拒绝或删除的不计入
+func (s *Service) commentCounted(ctx context.Context, comment *model.Comment) bool {
+	switch comment.Status {
+	case model.CommentStatusHeld:
+		return false
+	case model.CommentStatusRejected:
+		actions, err := s.dao.GetCommentModerationActions(ctx, comment.ID)
+		if err != nil {
+			s.logger.Error(ctx, "Failed to get comment moderation actions",
+				logger.F("commentID", comment.ID),
+				logger.F("error", err.Error()))
+			return true
+		}
+		return !actions[model.CommentModerationActionAutoFilter] || actions[model.CommentModerationActionApprove]
+	}
+	return true
+}
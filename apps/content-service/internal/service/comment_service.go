@@ -11,7 +11,10 @@ import (
 
 	"goim-social/apps/content-service/internal/model"
 	tracecontext "goim-social/pkg/context"
+	"goim-social/pkg/httpx"
 	"goim-social/pkg/logger"
+	"goim-social/pkg/outbox"
+	"goim-social/pkg/profanity"
 	"goim-social/pkg/telemetry"
 )
 
@@ -54,12 +57,30 @@ func (s *Service) CreateComment(ctx context.Context, params CreateCommentParams)
 		span.SetStatus(codes.Error, "invalid parameters")
 		return nil, err
 	}
+	if err := s.checkPostingBan(ctx, params.UserID, model.BanScopeComment); err != nil {
+		span.SetStatus(codes.Error, "user is banned from commenting")
+		return nil, err
+	}
+	filterResult, err := s.screenText(ctx, fmt.Sprintf("comment:user:%d", params.UserID), params.Content)
+	if err != nil {
+		span.SetStatus(codes.Error, "comment contains prohibited words")
+		return nil, err
+	}
 
 	// 检查目标对象是否存在
 	if err := s.validateCommentTarget(ctx, params.TargetID, params.TargetType); err != nil {
 		span.SetStatus(codes.Error, "target validation failed")
 		return nil, err
 	}
+	if err := s.checkTargetVisible(ctx, params.UserID, params.TargetID, params.TargetType); err != nil {
+		span.SetStatus(codes.Error, "target not visible")
+		return nil, err
+	}
+	// 最后检查新账号发布频率，被其他校验拒绝的评论不消耗额度
+	if err := s.checkNewAccountCooldown(ctx, params.UserID, model.BanScopeComment); err != nil {
+		span.SetStatus(codes.Error, "new account is cooling down")
+		return nil, err
+	}
 
 	// 构建评论对象
 	comment := &model.Comment{
@@ -78,6 +99,11 @@ func (s *Service) CreateComment(ctx context.Context, params CreateCommentParams)
 		CreatedAt:       time.Now(),
 		UpdatedAt:       time.Now(),
 	}
+	// 命中hold级别的评论创建即进入复核队列，复核通过前不对外展示
+	held := filterResult.Severity == profanity.SeverityHold
+	if held {
+		comment.Status = model.CommentStatusHeld
+	}
 
 	// 设置根评论ID
 	if params.ParentID == 0 {
@@ -98,18 +124,36 @@ func (s *Service) CreateComment(ctx context.Context, params CreateCommentParams)
 		}
 	}
 
-	// 创建评论
-	if err := s.dao.CreateComment(ctx, comment); err != nil {
+	// 创建评论，待审核的评论审核通过前不发布评论事件
+	var event outbox.Builder
+	if !held {
+		event = commentEvent("create", comment)
+	}
+	if err := s.dao.CreateComment(ctx, comment, event); err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, "failed to create comment")
 		return nil, fmt.Errorf("创建评论失败: %v", err)
 	}
+	if held {
+		s.holdComment(ctx, comment, filterResult)
+		span.SetAttributes(attribute.Bool("comment.held", true))
+		span.SetStatus(codes.Ok, "comment held for review")
+		return comment, nil
+	}
+
+	// 解析@提及，评论创建后即可见，立即通知被提及用户
+	s.syncCommentMentions(ctx, comment)
+
+	// 新的顶级评论会进入内容详情的热门评论
+	if comment.TargetType == model.TargetTypeContent && comment.ParentID == 0 {
+		s.clearTopCommentsCache(ctx, comment.TargetID)
+	}
 
 	// 更新相关计数
 	go s.updateCommentCounts(context.Background(), comment)
 
-	// 发送事件到消息队列
-	go s.publishCommentEvent(context.Background(), "create", comment)
+	// 评论事件已写入发件箱，唤醒转发器发布
+	s.relay.Notify()
 
 	s.logger.Info(ctx, "Comment created successfully",
 		logger.F("commentID", comment.ID),
@@ -151,17 +195,23 @@ func (s *Service) DeleteComment(ctx context.Context, commentID, userID int64) er
 	}
 
 	// 删除评论
-	if err := s.dao.DeleteComment(ctx, commentID); err != nil {
+	if err := s.dao.DeleteComment(ctx, commentID, commentEvent("delete", comment)); err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, "failed to delete comment")
 		return fmt.Errorf("删除评论失败: %v", err)
 	}
 
-	// 更新相关计数
-	go s.updateCommentCountsOnDelete(context.Background(), comment)
+	if comment.TargetType == model.TargetTypeContent && comment.ParentID == 0 {
+		s.clearTopCommentsCache(ctx, comment.TargetID)
+	}
+
+	// 更新相关计数，未计入评论数的评论不扣减
+	if s.commentCounted(ctx, comment) {
+		go s.updateCommentCountsOnDelete(context.Background(), comment)
+	}
 
-	// 发送事件到消息队列
-	go s.publishCommentEvent(context.Background(), "delete", comment)
+	// 评论事件已写入发件箱，唤醒转发器发布
+	s.relay.Notify()
 
 	s.logger.Info(ctx, "Comment deleted successfully",
 		logger.F("commentID", commentID),
@@ -171,14 +221,15 @@ func (s *Service) DeleteComment(ctx context.Context, commentID, userID int64) er
 	return nil
 }
 
-// GetComments 获取评论列表
-func (s *Service) GetComments(ctx context.Context, targetID int64, targetType string, parentID int64, sortBy, sortOrder string, page, pageSize int32) ([]*model.Comment, int64, error) {
+// GetComments 获取评论列表，viewerID大于0时不返回与其有拉黑关系的用户的评论
+func (s *Service) GetComments(ctx context.Context, viewerID, targetID int64, targetType string, parentID int64, sortBy, sortOrder string, page, pageSize int32) ([]*model.Comment, int64, error) {
 	// 开始OpenTelemetry span
 	ctx, span := telemetry.StartSpan(ctx, "content.service.GetComments")
 	defer span.End()
 
 	// 设置span属性
 	span.SetAttributes(
+		attribute.Int64("comment.viewer_id", viewerID),
 		attribute.Int64("comment.target_id", targetID),
 		attribute.String("comment.target_type", targetType),
 		attribute.Int64("comment.parent_id", parentID),
@@ -190,23 +241,28 @@ func (s *Service) GetComments(ctx context.Context, targetID int64, targetType st
 	// 参数验证
 	if targetID <= 0 {
 		span.SetStatus(codes.Error, "invalid target ID")
-		return nil, 0, fmt.Errorf("目标ID无效")
+		return nil, 0, httpx.InvalidArgument(fmt.Errorf("目标ID无效"))
+	}
+
+	// 好友可见内容的评论只对能查看内容的用户可见
+	if err := s.checkTargetVisible(ctx, viewerID, targetID, targetType); err != nil {
+		span.SetStatus(codes.Error, "target not visible")
+		return nil, 0, err
 	}
 
 	if page <= 0 {
 		page = 1
 	}
-	if pageSize <= 0 || pageSize > model.MaxBatchSize {
-		pageSize = model.DefaultPageSize
-	}
+	pageSize = s.paging.PageSize32(pageSize)
 
 	// 获取评论列表
-	comments, total, err := s.dao.GetComments(ctx, targetID, targetType, parentID, sortBy, sortOrder, page, pageSize)
+	comments, total, err := s.dao.GetComments(ctx, targetID, targetType, parentID, sortBy, sortOrder, page, pageSize, s.hiddenAuthors(ctx, viewerID).IDs())
 	if err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, "failed to get comments")
-		return nil, 0, fmt.Errorf("获取评论列表失败: %v", err)
+		return nil, 0, httpx.Unavailable(fmt.Errorf("获取评论列表失败: %v", err))
 	}
+	s.attachCommentMentions(ctx, comments)
 
 	span.SetAttributes(
 		attribute.Int64("comment.total", total),
@@ -223,14 +279,15 @@ func (s *Service) GetComments(ctx context.Context, targetID int64, targetType st
 	return comments, total, nil
 }
 
-// GetCommentReplies 获取评论回复
-func (s *Service) GetCommentReplies(ctx context.Context, commentID int64, sortBy, sortOrder string, page, pageSize int32) ([]*model.Comment, int64, error) {
+// GetCommentReplies 获取评论回复，viewerID大于0时不返回与其有拉黑关系的用户的回复
+func (s *Service) GetCommentReplies(ctx context.Context, viewerID, commentID int64, sortBy, sortOrder string, page, pageSize int32) ([]*model.Comment, int64, error) {
 	// 开始OpenTelemetry span
 	ctx, span := telemetry.StartSpan(ctx, "content.service.GetCommentReplies")
 	defer span.End()
 
 	// 设置span属性
 	span.SetAttributes(
+		attribute.Int64("comment.viewer_id", viewerID),
 		attribute.Int64("comment.id", commentID),
 		attribute.String("comment.sort_by", sortBy),
 		attribute.Int("comment.page", int(page)),
@@ -240,23 +297,27 @@ func (s *Service) GetCommentReplies(ctx context.Context, commentID int64, sortBy
 	// 参数验证
 	if commentID <= 0 {
 		span.SetStatus(codes.Error, "invalid comment ID")
-		return nil, 0, fmt.Errorf("评论ID无效")
+		return nil, 0, httpx.InvalidArgument(fmt.Errorf("评论ID无效"))
+	}
+
+	if err := s.checkTargetVisible(ctx, viewerID, commentID, model.TargetTypeComment); err != nil {
+		span.SetStatus(codes.Error, "target not visible")
+		return nil, 0, err
 	}
 
 	if page <= 0 {
 		page = 1
 	}
-	if pageSize <= 0 || pageSize > model.MaxBatchSize {
-		pageSize = model.DefaultPageSize
-	}
+	pageSize = s.paging.PageSize32(pageSize)
 
 	// 获取回复列表
-	replies, total, err := s.dao.GetCommentReplies(ctx, commentID, sortBy, sortOrder, page, pageSize)
+	replies, total, err := s.dao.GetCommentReplies(ctx, commentID, sortBy, sortOrder, page, pageSize, s.hiddenAuthors(ctx, viewerID).IDs())
 	if err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, "failed to get comment replies")
-		return nil, 0, fmt.Errorf("获取评论回复失败: %v", err)
+		return nil, 0, httpx.Unavailable(fmt.Errorf("获取评论回复失败: %v", err))
 	}
+	s.attachCommentMentions(ctx, replies)
 
 	span.SetAttributes(
 		attribute.Int64("comment.total", total),
@@ -0,0 +1,179 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	goredis "github.com/go-redis/redis/v8"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"gorm.io/gorm"
+
+	"goim-social/apps/content-service/internal/model"
+	tracecontext "goim-social/pkg/context"
+	"goim-social/pkg/logger"
+	"goim-social/pkg/outbox"
+	"goim-social/pkg/telemetry"
+)
+
+// ==================== 内容删除清理 ====================
+// 作者删除（硬删除）和管理员删除（软删除为deleted状态）都会发布内容删除事件，按内容ID分区。
+// 删除事件与删除在同一事务中写入发件箱，由转发器发布，删除成功则事件一定会发布。
+// 内容服务消费事件隐藏评论、删除互动记录和统计行、扣除标签话题热度并清理缓存，
+// 搜索服务消费同一事件删除内容文档。各步骤都可重复执行，事件重放不会重复扣除或产生残留数据；
+// Kafka不可用时在本地直接执行清理，Kafka恢复后事件仍会发布，重复清理无副作用
+
+// newContentDeletedEvent 构建内容删除事件，content需携带删除前的标签和话题
+func newContentDeletedEvent(content *model.Content, operatorID int64, hard bool) *model.ContentDeletedEvent {
+	event := &model.ContentDeletedEvent{
+		ContentID:    content.ID,
+		AuthorID:     content.AuthorID,
+		OperatorID:   operatorID,
+		WasPublished: content.PublishedAt != nil,
+		Hard:         hard,
+		DeletedAt:    time.Now().Unix(),
+	}
+	for _, tag := range content.Tags {
+		event.TagIDs = append(event.TagIDs, tag.ID)
+	}
+	for _, topic := range content.Topics {
+		event.TopicIDs = append(event.TopicIDs, topic.ID)
+	}
+	return event
+}
+
+// contentDeletedOutbox 内容删除事件写入发件箱，按内容ID分区；event为nil时不写入
+func contentDeletedOutbox(event *model.ContentDeletedEvent) outbox.Builder {
+	if event == nil {
+		return nil
+	}
+	return func() (*outbox.Event, error) {
+		return outbox.NewEvent(model.TopicContentDeleted, strconv.FormatInt(event.ContentID, 10), event)
+	}
+}
+
+// onContentDeleted 删除事务提交后唤醒转发器发布删除事件，Kafka不可用时在本地直接执行清理
+func (s *Service) onContentDeleted(ctx context.Context, event *model.ContentDeletedEvent) {
+	s.relay.Notify()
+	if s.kafka != nil {
+		return
+	}
+
+	s.logger.Warn(ctx, "Kafka not available, cleaning up deleted content locally",
+		logger.F("contentID", event.ContentID))
+	go func() {
+		if err := s.CleanupDeletedContent(context.Background(), event); err != nil {
+			s.logger.Error(context.Background(), "Failed to clean up deleted content",
+				logger.F("contentID", event.ContentID),
+				logger.F("error", err.Error()))
+		}
+	}()
+}
+
+// CleanupDeletedContent 清理已删除内容的评论、互动统计、标签话题热度和缓存，可重复执行
+func (s *Service) CleanupDeletedContent(ctx context.Context, event *model.ContentDeletedEvent) error {
+	// 开始OpenTelemetry span
+	ctx, span := telemetry.StartSpan(ctx, "content.service.CleanupDeletedContent")
+	defer span.End()
+
+	// 设置span属性
+	span.SetAttributes(
+		attribute.Int64("content.id", event.ContentID),
+		attribute.Bool("content.hard_deleted", event.Hard),
+	)
+
+	// 将业务信息添加到context
+	ctx = tracecontext.WithContentID(ctx, event.ContentID)
+
+	if event.ContentID <= 0 {
+		span.SetStatus(codes.Error, "invalid content ID")
+		return fmt.Errorf("内容ID无效")
+	}
+
+	// 软删除的内容必须仍处于deleted状态，防止误收到的事件清理正常内容
+	// 内容已不存在时按已删除处理
+	if !event.Hard {
+		content, err := s.dao.GetContent(ctx, event.ContentID)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, "failed to get content")
+			return fmt.Errorf("获取内容失败: %v", err)
+		}
+		if err == nil && content.Status != model.ContentStatusDeleted {
+			span.SetStatus(codes.Error, "content not deleted")
+			return fmt.Errorf("内容未删除: %d", event.ContentID)
+		}
+	}
+
+	if err := s.dao.CleanupDeletedContent(ctx, event.ContentID); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to clean up deleted content")
+		return fmt.Errorf("清理已删除内容失败: %v", err)
+	}
+
+	if event.WasPublished {
+		if err := s.revokeTrendingUsage(ctx, event); err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, "failed to revoke trending usage")
+			return err
+		}
+	}
+	s.clearContentCache(ctx, event.ContentID)
+
+	s.logger.Info(ctx, "Deleted content cleaned up",
+		logger.F("contentID", event.ContentID),
+		logger.F("hard", event.Hard))
+
+	span.SetStatus(codes.Ok, "deleted content cleaned up")
+	return nil
+}
+
+// revokeTrendingScript 扣除标签和话题热度并写入扣除标记，标记已存在时不扣除，返回是否扣除
+// KEYS: 扣除标记、标签热度、话题热度；ARGV: 标记保留秒数、最小分数、标签数、标签ID...、话题ID...
+// 扣除完成后才写入标记，扣除与标记在同一脚本中完成，失败时不留下标记，重试仍会扣除
+var revokeTrendingScript = goredis.NewScript(`
+if redis.call('EXISTS', KEYS[1]) == 1 then
+	return 0
+end
+local tags = tonumber(ARGV[3])
+for i = 4, #ARGV do
+	if i - 3 <= tags then
+		redis.call('ZINCRBY', KEYS[2], -1, ARGV[i])
+	else
+		redis.call('ZINCRBY', KEYS[3], -1, ARGV[i])
+	end
+end
+redis.call('ZREMRANGEBYSCORE', KEYS[2], '-inf', ARGV[2])
+redis.call('ZREMRANGEBYSCORE', KEYS[3], '-inf', ARGV[2])
+redis.call('SET', KEYS[1], 1, 'EX', ARGV[1])
+return 1
+`)
+
+// revokeTrendingUsage 扣除已删除内容发布时累加的标签和话题热度
+// 扣除标记已存在说明已扣除过，重放的事件不再扣除
+func (s *Service) revokeTrendingUsage(ctx context.Context, event *model.ContentDeletedEvent) error {
+	if s.redis == nil || (len(event.TagIDs) == 0 && len(event.TopicIDs) == 0) {
+		return nil
+	}
+
+	// 热度已按时间衰减，扣除后低于最小分数的成员直接移除
+	minScore := "(" + strconv.FormatFloat(model.TrendingMinScore, 'f', -1, 64)
+	args := make([]interface{}, 0, 3+len(event.TagIDs)+len(event.TopicIDs))
+	args = append(args, model.TrendingRevokedMarkerTTL, minScore, len(event.TagIDs))
+	for _, tagID := range event.TagIDs {
+		args = append(args, tagID)
+	}
+	for _, topicID := range event.TopicIDs {
+		args = append(args, topicID)
+	}
+
+	markerKey := fmt.Sprintf("%s:%d", model.CacheKeyTrendingRevoked, event.ContentID)
+	keys := []string{markerKey, model.CacheKeyTrendingTags, model.CacheKeyTrendingTopics}
+	if err := revokeTrendingScript.Run(ctx, s.redis.GetClient(), keys, args...).Err(); err != nil {
+		return fmt.Errorf("扣除标签话题热度失败: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,121 @@
+package service
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"goim-social/apps/content-service/internal/model"
+	"goim-social/pkg/blocklist"
+	"goim-social/pkg/logger"
+)
+
+// ==================== 内容详情并行加载 ====================
+// 内容本身是详情的必需部分，失败时整个请求失败；热门评论、互动统计和用户互动状态
+// 在共享的整体时限内并行加载，任一部分失败或超时只标记为降级，不影响其余部分返回
+
+// detailPartResult 单个部分的加载结果，apply在等待方的goroutine中执行，避免并发写同一结果
+type detailPartResult struct {
+	part  string
+	apply func(result *model.ContentDetailResult)
+	err   error
+}
+
+// contentDetailParts 正在并行加载的内容详情各部分
+type contentDetailParts struct {
+	svc       *Service
+	ctx       context.Context
+	cancel    context.CancelFunc
+	contentID int64
+	results   chan detailPartResult
+	pending   map[string]bool
+}
+
+// loadContentDetailParts 启动内容详情各部分的并行加载，调用方通过wait收集结果
+// 各部分共享同一个带整体时限的context，未登录用户不加载互动状态
+func (s *Service) loadContentDetailParts(ctx context.Context, contentID, userID int64, commentLimit int32, hidden blocklist.Set) *contentDetailParts {
+	timeout := s.config.DetailTimeout
+	if timeout <= 0 {
+		timeout = model.DefaultDetailTimeout
+	}
+	partsCtx, cancel := context.WithTimeout(ctx, time.Duration(timeout)*time.Millisecond)
+
+	parts := &contentDetailParts{
+		svc:       s,
+		ctx:       partsCtx,
+		cancel:    cancel,
+		contentID: contentID,
+		results:   make(chan detailPartResult, 3),
+		pending:   make(map[string]bool, 3),
+	}
+
+	parts.start(model.DetailPartTopComments, func(ctx context.Context) (func(*model.ContentDetailResult), error) {
+		comments, err := s.getTopComments(ctx, contentID, commentLimit, hidden)
+		if err != nil {
+			return nil, err
+		}
+		return func(result *model.ContentDetailResult) { result.TopComments = comments }, nil
+	})
+
+	parts.start(model.DetailPartInteractionStats, func(ctx context.Context) (func(*model.ContentDetailResult), error) {
+		stats, err := s.getInteractionStats(ctx, contentID, model.TargetTypeContent)
+		if err != nil {
+			return nil, err
+		}
+		return func(result *model.ContentDetailResult) { result.InteractionStats = stats }, nil
+	})
+
+	if userID > 0 {
+		parts.start(model.DetailPartUserInteractions, func(ctx context.Context) (func(*model.ContentDetailResult), error) {
+			interactions, err := s.dao.GetUserTargetInteractions(ctx, userID, contentID, model.TargetTypeContent)
+			if err != nil {
+				return nil, err
+			}
+			return func(result *model.ContentDetailResult) { result.UserInteractions = interactions }, nil
+		})
+	}
+
+	return parts
+}
+
+// start 在独立goroutine中加载一个部分，结果通道有足够缓冲，超时后返回的结果直接丢弃
+func (p *contentDetailParts) start(part string, load func(ctx context.Context) (func(*model.ContentDetailResult), error)) {
+	p.pending[part] = true
+	go func() {
+		apply, err := load(p.ctx)
+		p.results <- detailPartResult{part: part, apply: apply, err: err}
+	}()
+}
+
+// wait 等待各部分加载完成或整体时限到达，失败和超时的部分记入DegradedParts
+func (p *contentDetailParts) wait() *model.ContentDetailResult {
+	defer p.cancel()
+
+	result := &model.ContentDetailResult{UserInteractions: make(map[string]bool)}
+	for len(p.pending) > 0 {
+		select {
+		case r := <-p.results:
+			delete(p.pending, r.part)
+			if r.err != nil {
+				p.svc.logger.Warn(p.ctx, "Failed to load content detail part",
+					logger.F("contentID", p.contentID),
+					logger.F("part", r.part),
+					logger.F("error", r.err.Error()))
+				result.DegradedParts = append(result.DegradedParts, r.part)
+				continue
+			}
+			r.apply(result)
+		case <-p.ctx.Done():
+			for part := range p.pending {
+				p.svc.logger.Warn(p.ctx, "Content detail part timed out",
+					logger.F("contentID", p.contentID),
+					logger.F("part", part))
+				result.DegradedParts = append(result.DegradedParts, part)
+			}
+			p.pending = nil
+		}
+	}
+
+	sort.Strings(result.DegradedParts)
+	return result
+}
@@ -0,0 +1,48 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"goim-social/apps/content-service/internal/model"
+	"goim-social/pkg/utils"
+)
+
+// ==================== 内容摘要 ====================
+// 内容创建和更新时生成纯文本摘要并保存，内容流等列表响应只返回摘要，不返回正文。
+// 作者填写了摘要时直接使用，否则取正文去除HTML和Markdown标记后的前若干个字符，按字符截断，不会截断中文
+
+// excerptLength 自动摘要的最大字符数
+func (s *Service) excerptLength() int {
+	if s.config.Excerpt.Length > 0 {
+		return s.config.Excerpt.Length
+	}
+	return model.DefaultExcerptLength
+}
+
+// summaryMaxLength 作者摘要的最大字符数
+func (s *Service) summaryMaxLength() int {
+	if s.config.Excerpt.SummaryMaxLength > 0 {
+		return s.config.Excerpt.SummaryMaxLength
+	}
+	return model.DefaultSummaryMaxLength
+}
+
+// normalizeSummary 校验作者填写的摘要，去除首尾空白，超过长度时返回错误
+func (s *Service) normalizeSummary(summary string) (string, error) {
+	summary = strings.TrimSpace(summary)
+	if limit := s.summaryMaxLength(); utf8.RuneCountInString(summary) > limit {
+		return "", fmt.Errorf("摘要不能超过%d个字符", limit)
+	}
+	return summary, nil
+}
+
+// applyExcerpt 根据作者摘要和正文生成内容的列表摘要
+func (s *Service) applyExcerpt(content *model.Content) {
+	if content.Summary != "" {
+		content.Excerpt = utils.Excerpt(content.Summary, s.summaryMaxLength())
+		return
+	}
+	content.Excerpt = utils.Excerpt(content.Content, s.excerptLength())
+}
@@ -0,0 +1,47 @@
+package service
+
+import (
+	"encoding/json"
+	"testing"
+
+	"goim-social/apps/content-service/internal/model"
+)
+
+// TestEventPayloadsEscaped 事件中的用户输入含引号、反斜杠和换行时仍生成有效的JSON
+func TestEventPayloadsEscaped(t *testing.T) {
+	tricky := "a\"b\\c\nd"
+
+	event, err := interactionEvent("reaction", &model.Interaction{
+		ID:          1,
+		ReactionKey: tricky,
+		Metadata:    `{"k":"v"}`,
+	})()
+	if err != nil {
+		t.Fatalf("interactionEvent error: %v", err)
+	}
+	var interaction model.InteractionEvent
+	if err := json.Unmarshal(event.Payload, &interaction); err != nil {
+		t.Fatalf("interaction payload is not JSON: %v", err)
+	}
+	if interaction.ReactionKey != tricky || interaction.Metadata != `{"k":"v"}` {
+		t.Errorf("interaction payload = %+v", interaction)
+	}
+
+	event, err = commentEvent("create", &model.Comment{ID: 2, Content: tricky})()
+	if err != nil {
+		t.Fatalf("commentEvent error: %v", err)
+	}
+	var comment model.CommentEvent
+	if err := json.Unmarshal(event.Payload, &comment); err != nil || comment.Content != tricky {
+		t.Errorf("comment payload = %s, err %v", event.Payload, err)
+	}
+
+	event, err = contentEvent("update", &model.Content{ID: 3, Title: tricky})()
+	if err != nil {
+		t.Fatalf("contentEvent error: %v", err)
+	}
+	var content model.ContentEvent
+	if err := json.Unmarshal(event.Payload, &content); err != nil || content.Title != tricky {
+		t.Errorf("content payload = %s, err %v", event.Payload, err)
+	}
+}
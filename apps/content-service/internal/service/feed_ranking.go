@@ -0,0 +1,211 @@
+package service
+
+import (
+	"context"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+
+	"goim-social/apps/content-service/internal/model"
+	"goim-social/pkg/config"
+	"goim-social/pkg/logger"
+)
+
+// ==================== 内容流加权排序 ====================
+// 分数 = 时效权重*时效分 + 互动权重*互动分 + 个性化权重*个性化分，各分项均归一化到[0,1]
+// 时效分按半衰期指数衰减；互动分取互动统计缓存中加权计数的对数并压缩；
+// 个性化分来自用户近期互动过的作者和话题（关注关系在social-service，此处不跨服务查询）
+
+// feedScore 单条内容的排序分数构成
+type feedScore struct {
+	content         *model.Content
+	recency         float64
+	engagement      float64
+	personalization float64
+	total           float64
+}
+
+// fetchRankedFeed 拉取按时间倒序的候选窗口并加权排序，返回选中的内容、用户互动状态、新的偏移量以及是否已到末尾
+// 偏移量只前进到候选窗口中连续已下发的前缀，未选中的候选留给后续页面继续参与排序；demote中的内容降权参与排序
+func (s *Service) fetchRankedFeed(ctx context.Context, userID int64, contentType string, offset, pageSize int32, seen, demote map[int64]bool, excludeAuthorIDs []int64) ([]*model.Content, map[int64]map[string]bool, int32, bool, error) {
+	limit := pageSize * model.FeedRankingCandidateMultiplier
+	candidates, userInteractionsMap, err := s.dao.GetContentFeed(ctx, userID, contentType, model.FeedSortTime, offset, limit, excludeAuthorIDs)
+	if err != nil {
+		return nil, nil, offset, false, err
+	}
+
+	fresh := make([]*model.Content, 0, len(candidates))
+	for _, content := range candidates {
+		if !seen[content.ID] {
+			fresh = append(fresh, content)
+		}
+	}
+
+	ranked := s.rankContents(ctx, userID, fresh, demote)
+	if len(ranked) > int(pageSize) {
+		ranked = ranked[:pageSize]
+	}
+
+	selected := make(map[int64]bool, len(ranked))
+	for _, content := range ranked {
+		selected[content.ID] = true
+	}
+	advance := int32(0)
+	for _, content := range candidates {
+		if !seen[content.ID] && !selected[content.ID] {
+			break
+		}
+		advance++
+	}
+
+	exhausted := len(candidates) < int(limit) && advance == int32(len(candidates))
+	return ranked, userInteractionsMap, offset + advance, exhausted, nil
+}
+
+// rankContents 按加权分数从高到低排序内容，分数相同时保持原有的时间顺序，demote中的内容总分乘以降权系数
+func (s *Service) rankContents(ctx context.Context, userID int64, contents []*model.Content, demote map[int64]bool) []*model.Content {
+	if len(contents) == 0 {
+		return contents
+	}
+
+	weights := s.loadRankingWeights(ctx)
+	contentIDs := contentIDsOf(contents)
+
+	statsMap, err := s.batchGetInteractionStats(ctx, contentIDs, model.TargetTypeContent)
+	if err != nil {
+		s.logger.Warn(ctx, "获取互动统计失败，互动分按0计算", logger.F("error", err.Error()))
+		statsMap = make(map[int64]*model.InteractionStats)
+	}
+
+	// 个性化权重为0或匿名用户时不查询用户偏好
+	var affinityAuthors, affinityTopics map[int64]bool
+	var contentTopics map[int64][]int64
+	if weights.PersonalizationWeight > 0 && userID > 0 {
+		affinityAuthors, affinityTopics, err = s.dao.GetUserFeedAffinity(ctx, userID, model.FeedAffinityInteractionLimit)
+		if err == nil {
+			contentTopics, err = s.dao.GetContentTopicIDs(ctx, contentIDs)
+		}
+		if err != nil {
+			s.logger.Warn(ctx, "获取用户偏好失败，个性化分按0计算", logger.F("error", err.Error()))
+			affinityAuthors, affinityTopics, contentTopics = nil, nil, nil
+		}
+	}
+
+	now := time.Now()
+	scores := make([]*feedScore, len(contents))
+	for i, content := range contents {
+		score := &feedScore{
+			content:         content,
+			recency:         recencyScore(content, now, weights.RecencyHalfLifeHours),
+			engagement:      engagementScore(content, statsMap[content.ID]),
+			personalization: personalizationScore(content, contentTopics[content.ID], affinityAuthors, affinityTopics),
+		}
+		score.total = weights.RecencyWeight*score.recency +
+			weights.EngagementWeight*score.engagement +
+			weights.PersonalizationWeight*score.personalization
+		if demote[content.ID] {
+			score.total *= s.feedViewedPenalty()
+		}
+		scores[i] = score
+	}
+
+	sort.SliceStable(scores, func(i, j int) bool {
+		return scores[i].total > scores[j].total
+	})
+
+	ranked := make([]*model.Content, len(scores))
+	for i, score := range scores {
+		ranked[i] = score.content
+		if weights.Debug {
+			s.logger.Info(ctx, "内容流排序分数",
+				logger.F("rank", i+1),
+				logger.F("contentID", score.content.ID),
+				logger.F("recency", score.recency),
+				logger.F("engagement", score.engagement),
+				logger.F("personalization", score.personalization),
+				logger.F("total", score.total))
+		}
+	}
+	return ranked
+}
+
+// loadRankingWeights 读取排序权重，Redis中存在覆盖值时优先使用，便于运行时调参
+func (s *Service) loadRankingWeights(ctx context.Context) config.FeedRankingConfig {
+	weights := s.config.FeedRanking
+	if s.redis == nil {
+		return weights
+	}
+
+	fields, err := s.redis.HGetAll(ctx, model.CacheKeyFeedRankingWeights)
+	if err != nil || len(fields) == 0 {
+		return weights
+	}
+
+	parseFloat := func(field string, target *float64) {
+		if value, err := strconv.ParseFloat(fields[field], 64); err == nil {
+			*target = value
+		}
+	}
+	parseFloat("recency_weight", &weights.RecencyWeight)
+	parseFloat("engagement_weight", &weights.EngagementWeight)
+	parseFloat("personalization_weight", &weights.PersonalizationWeight)
+	if value, err := strconv.Atoi(fields["recency_half_life_hours"]); err == nil {
+		weights.RecencyHalfLifeHours = value
+	}
+	if value, err := strconv.ParseBool(fields["debug"]); err == nil {
+		weights.Debug = value
+	}
+	return weights
+}
+
+// recencyScore 时效分，发布时刻为1，每经过一个半衰期减半
+func recencyScore(content *model.Content, now time.Time, halfLifeHours int) float64 {
+	if halfLifeHours <= 0 {
+		return 0
+	}
+	publishedAt := content.CreatedAt
+	if content.PublishedAt != nil {
+		publishedAt = *content.PublishedAt
+	}
+	ageHours := now.Sub(publishedAt).Hours()
+	if ageHours < 0 {
+		ageHours = 0
+	}
+	return math.Exp(-math.Ln2 * ageHours / float64(halfLifeHours))
+}
+
+// engagementScore 互动分，对加权互动数取对数后压缩到[0,1)
+func engagementScore(content *model.Content, stats *model.InteractionStats) float64 {
+	raw := model.EngagementWeightComment*float64(content.CommentCount) +
+		model.EngagementWeightView*float64(content.ViewCount)
+	if stats != nil {
+		raw += model.EngagementWeightLike*float64(stats.LikeCount) +
+			model.EngagementWeightFavorite*float64(stats.FavoriteCount) +
+			model.EngagementWeightShare*float64(stats.ShareCount) +
+			model.EngagementWeightRepost*float64(stats.RepostCount)
+	}
+	if raw <= 0 {
+		return 0
+	}
+	l := math.Log1p(raw)
+	return l / (1 + l)
+}
+
+// personalizationScore 个性化分，作者命中和话题命中各占一半
+func personalizationScore(content *model.Content, topicIDs []int64, affinityAuthors, affinityTopics map[int64]bool) float64 {
+	score := 0.0
+	if affinityAuthors[content.AuthorID] {
+		score += 0.5
+	}
+	if len(topicIDs) > 0 {
+		matched := 0
+		for _, topicID := range topicIDs {
+			if affinityTopics[topicID] {
+				matched++
+			}
+		}
+		score += 0.5 * float64(matched) / float64(len(topicIDs))
+	}
+	return score
+}
@@ -0,0 +1,122 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	goredis "github.com/go-redis/redis/v8"
+
+	"goim-social/apps/content-service/internal/model"
+	"goim-social/pkg/logger"
+)
+
+// ==================== 内容流已浏览内容 ====================
+// 用户打开内容详情时记录到有上限的有序集合，超出上限淘汰最早的记录，超过有效期的记录不再生效。
+// 刷新内容流时按配置排除已浏览内容，或降低其排序：ranked排序按系数降低分数，其他排序移到本页末尾。
+// 已浏览记录与游标中的本次会话去重相互独立，不写入游标
+
+// feedViewedMode 解析已浏览内容的处理方式，请求未指定时使用配置
+func (s *Service) feedViewedMode(requested string) (string, error) {
+	mode := requested
+	if mode == "" {
+		mode = s.config.FeedViewed.Mode
+	}
+	switch mode {
+	case "", model.FeedViewedModeOff:
+		return model.FeedViewedModeOff, nil
+	case model.FeedViewedModeExclude, model.FeedViewedModeDownrank:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("无效的已浏览内容处理方式: %s", requested)
+	}
+}
+
+// feedViewedTTL 浏览记录有效期
+func (s *Service) feedViewedTTL() time.Duration {
+	days := s.config.FeedViewed.TTLDays
+	if days <= 0 {
+		days = model.DefaultFeedViewedTTLDays
+	}
+	return time.Duration(days) * 24 * time.Hour
+}
+
+// feedViewedPenalty 降权系数
+func (s *Service) feedViewedPenalty() float64 {
+	if penalty := s.config.FeedViewed.Penalty; penalty > 0 && penalty < 1 {
+		return penalty
+	}
+	return model.DefaultFeedViewedPenalty
+}
+
+// feedViewedKey 用户已浏览内容集合键
+func feedViewedKey(userID int64) string {
+	return fmt.Sprintf("%s:%d", model.CacheKeyFeedViewed, userID)
+}
+
+// markContentViewed 记录用户浏览过的内容，只保留最近的若干条，整个集合在有效期内无新浏览时过期
+func (s *Service) markContentViewed(ctx context.Context, userID, contentID int64) {
+	if s.redis == nil || userID <= 0 {
+		return
+	}
+
+	maxItems := s.config.FeedViewed.MaxItems
+	if maxItems <= 0 {
+		maxItems = model.DefaultFeedViewedMax
+	}
+
+	key := feedViewedKey(userID)
+	pipe := s.redis.GetClient().TxPipeline()
+	pipe.ZAdd(ctx, key, &goredis.Z{Score: float64(time.Now().Unix()), Member: contentID})
+	pipe.ZRemRangeByRank(ctx, key, 0, int64(-maxItems-1))
+	pipe.Expire(ctx, key, s.feedViewedTTL())
+	if _, err := pipe.Exec(ctx); err != nil {
+		s.logger.Warn(ctx, "记录已浏览内容失败",
+			logger.F("userID", userID),
+			logger.F("contentID", contentID),
+			logger.F("error", err.Error()))
+	}
+}
+
+// loadViewedContents 读取用户有效期内浏览过的内容，读取失败时按没有浏览记录处理，不影响内容流
+func (s *Service) loadViewedContents(ctx context.Context, userID int64, mode string) map[int64]bool {
+	if s.redis == nil || userID <= 0 || mode == model.FeedViewedModeOff {
+		return nil
+	}
+
+	cutoff := time.Now().Add(-s.feedViewedTTL()).Unix()
+	members, err := s.redis.ZRangeByScore(ctx, feedViewedKey(userID), &goredis.ZRangeBy{
+		Min: strconv.FormatInt(cutoff, 10),
+		Max: "+inf",
+	})
+	if err != nil {
+		s.logger.Warn(ctx, "读取已浏览内容失败", logger.F("userID", userID), logger.F("error", err.Error()))
+		return nil
+	}
+
+	viewed := make(map[int64]bool, len(members))
+	for _, member := range members {
+		if id, err := strconv.ParseInt(member, 10, 64); err == nil {
+			viewed[id] = true
+		}
+	}
+	return viewed
+}
+
+// demoteViewed 将已浏览内容稳定地移到末尾，未浏览内容保持原有顺序
+func demoteViewed(contents []*model.Content, viewed map[int64]bool) []*model.Content {
+	if len(viewed) == 0 {
+		return contents
+	}
+	fresh := make([]*model.Content, 0, len(contents))
+	var stale []*model.Content
+	for _, content := range contents {
+		if viewed[content.ID] {
+			stale = append(stale, content)
+		} else {
+			fresh = append(fresh, content)
+		}
+	}
+	return append(fresh, stale...)
+}
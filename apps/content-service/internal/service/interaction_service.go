@@ -2,8 +2,12 @@ package service
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"strings"
 	"time"
+	"unicode"
+	"unicode/utf8"
 
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
@@ -17,8 +21,9 @@ import (
 
 // ==================== 互动相关业务逻辑 ====================
 
-// DoInteraction 执行互动操作
-func (s *Service) DoInteraction(ctx context.Context, userID, targetID int64, targetType, interactionType, metadata string) (*model.Interaction, error) {
+// DoInteraction 执行互动操作，表情回应按表情标识区分，重复回应同一表情时直接返回已有记录
+// 分享可指定目的地，每次分享到不同目的地都单独记录；sendMessage为true时在站内目的地会话发送内容链接消息
+func (s *Service) DoInteraction(ctx context.Context, userID, targetID int64, targetType, interactionType, reactionKey, metadata string, shareTarget *model.ShareTarget, sendMessage bool) (*model.Interaction, error) {
 	// 开始OpenTelemetry span
 	ctx, span := telemetry.StartSpan(ctx, "content.service.DoInteraction")
 	defer span.End()
@@ -29,30 +34,67 @@ func (s *Service) DoInteraction(ctx context.Context, userID, targetID int64, tar
 		attribute.Int64("interaction.target_id", targetID),
 		attribute.String("interaction.target_type", targetType),
 		attribute.String("interaction.type", interactionType),
+		attribute.String("interaction.reaction_key", reactionKey),
 	)
 
 	// 将业务信息添加到context
 	ctx = tracecontext.WithUserID(ctx, userID)
 
 	// 参数验证
-	if err := s.validateInteractionParams(userID, targetID, targetType, interactionType); err != nil {
+	reactionKey, err := s.validateInteractionParams(userID, targetID, targetType, interactionType, reactionKey)
+	if err != nil {
 		span.SetStatus(codes.Error, "invalid parameters")
 		return nil, err
 	}
 
-	// 检查是否已经存在相同的互动
-	existingInteraction, err := s.dao.GetInteraction(ctx, userID, targetID, targetType, interactionType)
-	if err != nil && err != gorm.ErrRecordNotFound {
-		span.RecordError(err)
-		span.SetStatus(codes.Error, "failed to check existing interaction")
-		return nil, fmt.Errorf("检查互动状态失败: %v", err)
+	// 不能查看的内容及其评论也不能互动
+	if err := s.checkTargetVisible(ctx, userID, targetID, targetType); err != nil {
+		span.SetStatus(codes.Error, "target not visible")
+		return nil, err
+	}
+
+	if shareTarget != nil {
+		if err := validateShareTarget(interactionType, shareTarget); err != nil {
+			span.SetStatus(codes.Error, "invalid share target")
+			return nil, err
+		}
+		span.SetAttributes(attribute.String("interaction.share_target", shareTarget.Type))
+	}
+
+	// 检查是否已经存在相同的互动，指定了目的地的分享每次单独记录
+	var existingInteraction *model.Interaction
+	if shareTarget == nil {
+		existingInteraction, err = s.dao.GetInteraction(ctx, userID, targetID, targetType, interactionType, reactionKey)
+		if err != nil && err != gorm.ErrRecordNotFound {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, "failed to check existing interaction")
+			return nil, fmt.Errorf("检查互动状态失败: %v", err)
+		}
 	}
 
 	if existingInteraction != nil {
+		// 表情回应幂等，重复提交同一表情视为成功
+		if interactionType == model.InteractionTypeReaction {
+			span.SetStatus(codes.Ok, "reaction already exists")
+			return existingInteraction, nil
+		}
 		span.SetStatus(codes.Error, "interaction already exists")
 		return nil, fmt.Errorf("已经执行过此互动")
 	}
 
+	if interactionType == model.InteractionTypeReaction {
+		if metadata, err = reactionMetadata(metadata, reactionKey); err != nil {
+			span.SetStatus(codes.Error, "invalid metadata")
+			return nil, err
+		}
+	}
+	if shareTarget != nil {
+		if metadata, err = shareMetadata(metadata, shareTarget); err != nil {
+			span.SetStatus(codes.Error, "invalid metadata")
+			return nil, err
+		}
+	}
+
 	// 检查目标对象是否存在
 	if err := s.validateInteractionTarget(ctx, targetID, targetType); err != nil {
 		span.SetStatus(codes.Error, "target validation failed")
@@ -65,12 +107,20 @@ func (s *Service) DoInteraction(ctx context.Context, userID, targetID int64, tar
 		TargetID:        targetID,
 		TargetType:      targetType,
 		InteractionType: interactionType,
+		ReactionKey:     reactionKey,
 		Metadata:        metadata,
 		CreatedAt:       time.Now(),
 		UpdatedAt:       time.Now(),
 	}
 
-	if err := s.dao.CreateInteraction(ctx, interaction); err != nil {
+	if err := s.dao.CreateInteraction(ctx, interaction, interactionEvent("create", interaction)); err != nil {
+		// 并发回应同一表情时唯一索引冲突，返回先写入的记录
+		if interactionType == model.InteractionTypeReaction {
+			if existing, getErr := s.dao.GetInteraction(ctx, userID, targetID, targetType, interactionType, reactionKey); getErr == nil {
+				span.SetStatus(codes.Ok, "reaction already exists")
+				return existing, nil
+			}
+		}
 		span.RecordError(err)
 		span.SetStatus(codes.Error, "failed to create interaction")
 		return nil, fmt.Errorf("创建互动失败: %v", err)
@@ -79,14 +129,26 @@ func (s *Service) DoInteraction(ctx context.Context, userID, targetID int64, tar
 	// 设置交互ID到span
 	span.SetAttributes(attribute.Int64("interaction.id", interaction.ID))
 
-	// 更新统计数据
-	go s.updateInteractionStats(context.Background(), targetID, targetType, interactionType, 1)
+	// 更新统计数据，表情回应计数由互动记录实时聚合
+	if interactionType != model.InteractionTypeReaction {
+		go s.updateInteractionStats(context.Background(), targetID, targetType, interactionType, 1)
+	}
 
 	// 清除相关缓存
 	go s.clearInteractionCache(context.Background(), userID, targetID, targetType, interactionType)
 
-	// 发送事件到消息队列
-	go s.publishInteractionEvent(context.Background(), "create", interaction)
+	// 互动事件已写入发件箱，唤醒转发器发布
+	s.relay.Notify()
+
+	// 点赞通知被点赞的作者
+	if interactionType == model.InteractionTypeLike {
+		go s.notifyLike(context.Background(), interaction)
+	}
+
+	// 分享到站内会话时发送内容链接消息，发送失败不影响分享记录
+	if sendMessage && shareTarget != nil && targetType == model.TargetTypeContent {
+		s.sendShareMessage(ctx, userID, targetID, shareTarget)
+	}
 
 	s.logger.Info(ctx, "Interaction created successfully",
 		logger.F("interactionID", interaction.ID),
@@ -99,7 +161,7 @@ func (s *Service) DoInteraction(ctx context.Context, userID, targetID int64, tar
 }
 
 // UndoInteraction 取消互动操作
-func (s *Service) UndoInteraction(ctx context.Context, userID, targetID int64, targetType, interactionType string) error {
+func (s *Service) UndoInteraction(ctx context.Context, userID, targetID int64, targetType, interactionType, reactionKey string) error {
 	// 开始OpenTelemetry span
 	ctx, span := telemetry.StartSpan(ctx, "content.service.UndoInteraction")
 	defer span.End()
@@ -110,19 +172,27 @@ func (s *Service) UndoInteraction(ctx context.Context, userID, targetID int64, t
 		attribute.Int64("interaction.target_id", targetID),
 		attribute.String("interaction.target_type", targetType),
 		attribute.String("interaction.type", interactionType),
+		attribute.String("interaction.reaction_key", reactionKey),
 	)
 
 	// 将业务信息添加到context
 	ctx = tracecontext.WithUserID(ctx, userID)
 
 	// 参数验证
-	if err := s.validateInteractionParams(userID, targetID, targetType, interactionType); err != nil {
+	reactionKey, err := s.validateInteractionParams(userID, targetID, targetType, interactionType, reactionKey)
+	if err != nil {
 		span.SetStatus(codes.Error, "invalid parameters")
 		return err
 	}
 
+	// 已删除目标的互动统计已清理，取消互动不能再扣减计数
+	if err := s.checkTargetNotDeleted(ctx, targetID, targetType); err != nil {
+		span.SetStatus(codes.Error, "target deleted")
+		return err
+	}
+
 	// 检查互动是否存在
-	existingInteraction, err := s.dao.GetInteraction(ctx, userID, targetID, targetType, interactionType)
+	existingInteraction, err := s.dao.GetInteraction(ctx, userID, targetID, targetType, interactionType, reactionKey)
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
 			span.SetStatus(codes.Error, "interaction not found")
@@ -134,20 +204,23 @@ func (s *Service) UndoInteraction(ctx context.Context, userID, targetID int64, t
 	}
 
 	// 删除互动记录
-	if err := s.dao.DeleteInteraction(ctx, userID, targetID, targetType, interactionType); err != nil {
+	if err := s.dao.DeleteInteraction(ctx, userID, targetID, targetType, interactionType, reactionKey,
+		interactionEvent("delete", existingInteraction)); err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, "failed to delete interaction")
 		return fmt.Errorf("删除互动失败: %v", err)
 	}
 
-	// 更新统计数据
-	go s.updateInteractionStats(context.Background(), targetID, targetType, interactionType, -1)
+	// 更新统计数据，表情回应计数由互动记录实时聚合
+	if interactionType != model.InteractionTypeReaction {
+		go s.updateInteractionStats(context.Background(), targetID, targetType, interactionType, -1)
+	}
 
 	// 清除相关缓存
 	go s.clearInteractionCache(context.Background(), userID, targetID, targetType, interactionType)
 
-	// 发送事件到消息队列
-	go s.publishInteractionEvent(context.Background(), "delete", existingInteraction)
+	// 互动事件已写入发件箱，唤醒转发器发布
+	s.relay.Notify()
 
 	s.logger.Info(ctx, "Interaction deleted successfully",
 		logger.F("userID", userID),
@@ -159,7 +232,7 @@ func (s *Service) UndoInteraction(ctx context.Context, userID, targetID int64, t
 }
 
 // CheckInteraction 检查互动状态
-func (s *Service) CheckInteraction(ctx context.Context, userID, targetID int64, targetType, interactionType string) (bool, *model.Interaction, error) {
+func (s *Service) CheckInteraction(ctx context.Context, userID, targetID int64, targetType, interactionType, reactionKey string) (bool, *model.Interaction, error) {
 	// 开始OpenTelemetry span
 	ctx, span := telemetry.StartSpan(ctx, "content.service.CheckInteraction")
 	defer span.End()
@@ -170,19 +243,21 @@ func (s *Service) CheckInteraction(ctx context.Context, userID, targetID int64,
 		attribute.Int64("interaction.target_id", targetID),
 		attribute.String("interaction.target_type", targetType),
 		attribute.String("interaction.type", interactionType),
+		attribute.String("interaction.reaction_key", reactionKey),
 	)
 
 	// 将业务信息添加到context
 	ctx = tracecontext.WithUserID(ctx, userID)
 
 	// 参数验证
-	if err := s.validateInteractionParams(userID, targetID, targetType, interactionType); err != nil {
+	reactionKey, err := s.validateInteractionParams(userID, targetID, targetType, interactionType, reactionKey)
+	if err != nil {
 		span.SetStatus(codes.Error, "invalid parameters")
 		return false, nil, err
 	}
 
 	// 检查互动是否存在
-	interaction, err := s.dao.GetInteraction(ctx, userID, targetID, targetType, interactionType)
+	interaction, err := s.dao.GetInteraction(ctx, userID, targetID, targetType, interactionType, reactionKey)
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
 			span.SetStatus(codes.Ok, "interaction not found")
@@ -220,19 +295,29 @@ func (s *Service) GetInteractionStats(ctx context.Context, targetID int64, targe
 		return nil, fmt.Errorf("目标类型不能为空")
 	}
 
-	// 获取统计数据
-	stats, err := s.dao.GetInteractionStats(ctx, targetID, targetType)
+	// 获取统计数据（优先读缓存）
+	stats, err := s.getInteractionStats(ctx, targetID, targetType)
 	if err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, "failed to get interaction stats")
 		return nil, fmt.Errorf("获取互动统计失败: %v", err)
 	}
 
+	// 表情回应计数按表情标识实时聚合
+	reactionCounts, err := s.dao.GetReactionCounts(ctx, targetID, targetType)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to get reaction counts")
+		return nil, fmt.Errorf("获取表情回应统计失败: %v", err)
+	}
+	stats.ReactionCounts = reactionCounts
+
 	span.SetAttributes(
 		attribute.Int64("interaction.like_count", stats.LikeCount),
 		attribute.Int64("interaction.favorite_count", stats.FavoriteCount),
 		attribute.Int64("interaction.share_count", stats.ShareCount),
 		attribute.Int64("interaction.repost_count", stats.RepostCount),
+		attribute.Int("interaction.reaction_kinds", len(reactionCounts)),
 	)
 
 	s.logger.Info(ctx, "Interaction stats retrieved successfully",
@@ -245,19 +330,19 @@ func (s *Service) GetInteractionStats(ctx context.Context, targetID int64, targe
 	return stats, nil
 }
 
-// validateInteractionParams 验证互动参数
-func (s *Service) validateInteractionParams(userID, targetID int64, targetType, interactionType string) error {
+// validateInteractionParams 验证互动参数，返回规范化后的表情标识（非表情回应时为空）
+func (s *Service) validateInteractionParams(userID, targetID int64, targetType, interactionType, reactionKey string) (string, error) {
 	if userID <= 0 {
-		return fmt.Errorf("用户ID无效")
+		return "", fmt.Errorf("用户ID无效")
 	}
 	if targetID <= 0 {
-		return fmt.Errorf("目标ID无效")
+		return "", fmt.Errorf("目标ID无效")
 	}
 	if targetType == "" {
-		return fmt.Errorf("目标类型不能为空")
+		return "", fmt.Errorf("目标类型不能为空")
 	}
 	if interactionType == "" {
-		return fmt.Errorf("互动类型不能为空")
+		return "", fmt.Errorf("互动类型不能为空")
 	}
 
 	// 验证目标类型
@@ -270,13 +355,14 @@ func (s *Service) validateInteractionParams(userID, targetID int64, targetType,
 		}
 	}
 	if !isValidTargetType {
-		return fmt.Errorf("不支持的目标类型: %s", targetType)
+		return "", fmt.Errorf("不支持的目标类型: %s", targetType)
 	}
 
 	// 验证互动类型
 	validInteractionTypes := []string{
 		model.InteractionTypeLike, model.InteractionTypeFavorite,
 		model.InteractionTypeShare, model.InteractionTypeRepost,
+		model.InteractionTypeReaction,
 	}
 	isValidInteractionType := false
 	for _, validType := range validInteractionTypes {
@@ -286,10 +372,56 @@ func (s *Service) validateInteractionParams(userID, targetID int64, targetType,
 		}
 	}
 	if !isValidInteractionType {
-		return fmt.Errorf("不支持的互动类型: %s", interactionType)
+		return "", fmt.Errorf("不支持的互动类型: %s", interactionType)
 	}
 
-	return nil
+	if interactionType != model.InteractionTypeReaction {
+		return "", nil
+	}
+	return s.validateReactionKey(reactionKey)
+}
+
+// validateReactionKey 验证表情标识，配置了允许列表时只接受列表内的表情
+func (s *Service) validateReactionKey(reactionKey string) (string, error) {
+	reactionKey = strings.TrimSpace(reactionKey)
+	if reactionKey == "" {
+		return "", fmt.Errorf("表情标识不能为空")
+	}
+	if utf8.RuneCountInString(reactionKey) > model.MaxReactionKeyLength {
+		return "", fmt.Errorf("表情标识不能超过%d个字符", model.MaxReactionKeyLength)
+	}
+	for _, r := range reactionKey {
+		if unicode.IsSpace(r) || unicode.IsControl(r) {
+			return "", fmt.Errorf("表情标识包含非法字符")
+		}
+	}
+
+	if s.config.AllowedReactions != "" {
+		for _, allowed := range strings.Split(s.config.AllowedReactions, ",") {
+			if strings.TrimSpace(allowed) == reactionKey {
+				return reactionKey, nil
+			}
+		}
+		return "", fmt.Errorf("不支持的表情: %s", reactionKey)
+	}
+	return reactionKey, nil
+}
+
+// reactionMetadata 将表情标识写入互动元数据，保留调用方传入的其他字段
+func reactionMetadata(metadata, reactionKey string) (string, error) {
+	fields := make(map[string]interface{})
+	if strings.TrimSpace(metadata) != "" {
+		if err := json.Unmarshal([]byte(metadata), &fields); err != nil {
+			return "", fmt.Errorf("互动元数据必须是JSON对象: %v", err)
+		}
+	}
+	fields["reaction_key"] = reactionKey
+
+	data, err := json.Marshal(fields)
+	if err != nil {
+		return "", fmt.Errorf("序列化互动元数据失败: %v", err)
+	}
+	return string(data), nil
 }
 
 // validateInteractionTarget 验证互动目标
@@ -305,11 +437,8 @@ func (s *Service) validateInteractionTarget(ctx context.Context, targetID int64,
 			return fmt.Errorf("内容未发布，无法互动")
 		}
 	case model.TargetTypeComment:
-		// 检查评论是否存在
-		_, err := s.dao.GetComment(ctx, targetID)
-		if err != nil {
-			return fmt.Errorf("评论不存在")
-		}
+		// 检查评论是否存在，已删除的评论和已删除内容下的评论不能互动
+		return s.checkTargetNotDeleted(ctx, targetID, targetType)
 	case model.TargetTypeUser:
 		// 用户相关的互动（如关注）暂时跳过验证
 		// TODO: 可以添加用户存在性验证
@@ -318,3 +447,29 @@ func (s *Service) validateInteractionTarget(ctx context.Context, targetID int64,
 	}
 	return nil
 }
+
+// checkTargetNotDeleted 检查互动目标未被删除，已删除内容的互动由删除清理统一移除，不能再单独增减
+func (s *Service) checkTargetNotDeleted(ctx context.Context, targetID int64, targetType string) error {
+	switch targetType {
+	case model.TargetTypeContent:
+		content, err := s.dao.GetContent(ctx, targetID)
+		if err != nil {
+			return fmt.Errorf("内容不存在")
+		}
+		if content.Status == model.ContentStatusDeleted {
+			return fmt.Errorf("内容已删除，无法互动")
+		}
+	case model.TargetTypeComment:
+		comment, err := s.dao.GetComment(ctx, targetID)
+		if err != nil {
+			return fmt.Errorf("评论不存在")
+		}
+		if comment.Status == model.CommentStatusDeleted {
+			return fmt.Errorf("评论已删除，无法互动")
+		}
+		if comment.TargetType == model.TargetTypeContent {
+			return s.checkTargetNotDeleted(ctx, comment.TargetID, comment.TargetType)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"goim-social/api/rest"
+	"goim-social/apps/content-service/internal/model"
+	"goim-social/pkg/logger"
+)
+
+// likeNoticeKey 点赞通知去重标记的键
+func likeNoticeKey(interaction *model.Interaction) string {
+	return fmt.Sprintf("%s:%s:%d:%d", model.CacheKeyLikeNoticeSent, interaction.TargetType, interaction.TargetID, interaction.UserID)
+}
+
+// notifyLike 通知被点赞内容或评论的作者，不通知自己点赞自己和作者已拉黑的用户；失败只记录日志
+// 点赞通知为低优先级，message-service按作者的摘要设置立即推送或合并为摘要。
+// 取消后再次点赞会新建互动记录，同一用户对同一对象在去重期内只通知一次
+func (s *Service) notifyLike(ctx context.Context, interaction *model.Interaction) {
+	if s.kafka == nil {
+		return
+	}
+
+	var authorID, contentID int64
+	switch interaction.TargetType {
+	case model.TargetTypeContent:
+		content, err := s.dao.GetContent(ctx, interaction.TargetID)
+		if err != nil {
+			return
+		}
+		authorID, contentID = content.AuthorID, content.ID
+	case model.TargetTypeComment:
+		comment, err := s.dao.GetComment(ctx, interaction.TargetID)
+		if err != nil {
+			return
+		}
+		authorID = comment.UserID
+		if comment.TargetType == model.TargetTypeContent {
+			contentID = comment.TargetID
+		}
+	default:
+		return
+	}
+
+	if authorID <= 0 || authorID == interaction.UserID || s.hiddenAuthors(ctx, authorID).Has(interaction.UserID) {
+		return
+	}
+
+	// 去重检查失败时照常通知，重复通知好过漏发
+	if s.redis != nil {
+		first, err := s.redis.SetNX(ctx, likeNoticeKey(interaction), 1, time.Duration(model.LikeNoticeDedupTTL)*time.Second)
+		if err != nil {
+			s.logger.Warn(ctx, "点赞通知去重检查失败",
+				logger.F("interactionID", interaction.ID),
+				logger.F("error", err.Error()))
+		} else if !first {
+			return
+		}
+	}
+
+	notice, err := json.Marshal(&model.LikeNotice{
+		InteractionID: interaction.ID,
+		TargetType:    interaction.TargetType,
+		TargetID:      interaction.TargetID,
+		ContentID:     contentID,
+		LikerID:       interaction.UserID,
+	})
+	if err != nil {
+		s.logger.Warn(ctx, "Failed to marshal like notice",
+			logger.F("interactionID", interaction.ID),
+			logger.F("error", err.Error()))
+		return
+	}
+
+	now := time.Now()
+	event := &rest.MessageEvent{
+		Type: model.EventTypeContentLike,
+		Message: &rest.WSMessage{
+			MessageId:   interaction.ID,
+			From:        interaction.UserID,
+			To:          authorID,
+			Content:     string(notice),
+			MessageType: model.MessageTypeLikeNotice,
+			Timestamp:   now.Unix(),
+			Priority:    model.PushPriorityBulk,
+		},
+		Timestamp: now.Unix(),
+	}
+	if err := s.kafka.PublishMessage(model.TopicDownlinkMessage, event); err != nil {
+		s.logger.Warn(ctx, "Failed to publish like notice",
+			logger.F("interactionID", interaction.ID),
+			logger.F("userID", authorID),
+			logger.F("error", err.Error()))
+	}
+}
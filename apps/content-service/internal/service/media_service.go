@@ -0,0 +1,145 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"mime"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+
+	"goim-social/apps/content-service/internal/model"
+	"goim-social/apps/content-service/internal/storage"
+	tracecontext "goim-social/pkg/context"
+	"goim-social/pkg/logger"
+	"goim-social/pkg/telemetry"
+)
+
+// PresignMediaUpload 签发媒体直传地址
+// 对象键以上传者ID为前缀，文件类型和大小参与签名，上传时必须与声明一致
+func (s *Service) PresignMediaUpload(ctx context.Context, userID int64, contentType string, size int64, filename string) (*model.MediaUpload, error) {
+	// 开始OpenTelemetry span
+	ctx, span := telemetry.StartSpan(ctx, "content.service.PresignMediaUpload")
+	defer span.End()
+
+	// 设置span属性
+	span.SetAttributes(
+		attribute.Int64("media.user_id", userID),
+		attribute.String("media.content_type", contentType),
+		attribute.Int64("media.size", size),
+	)
+
+	// 将业务信息添加到context
+	ctx = tracecontext.WithUserID(ctx, userID)
+
+	if s.storage == nil {
+		span.SetStatus(codes.Error, "media upload disabled")
+		return nil, fmt.Errorf("媒体上传未启用")
+	}
+	if userID <= 0 {
+		span.SetStatus(codes.Error, "invalid user ID")
+		return nil, fmt.Errorf("用户ID无效")
+	}
+	contentType, err := s.validateMediaContentType(contentType)
+	if err != nil {
+		span.SetStatus(codes.Error, "invalid content type")
+		return nil, err
+	}
+	if size <= 0 {
+		span.SetStatus(codes.Error, "invalid size")
+		return nil, fmt.Errorf("文件大小无效")
+	}
+	if s.config.Media.MaxSize > 0 && size > s.config.Media.MaxSize {
+		span.SetStatus(codes.Error, "file too large")
+		return nil, fmt.Errorf("文件大小超过限制: 最大%d字节", s.config.Media.MaxSize)
+	}
+
+	now := time.Now()
+	key := fmt.Sprintf("%s/%d/%s/%s%s", model.MediaKeyPrefix, userID, now.Format("20060102"), uuid.New().String(), mediaFileExt(filename))
+
+	upload, err := s.storage.PresignPut(ctx, &storage.PresignRequest{
+		Key:           key,
+		ContentType:   contentType,
+		ContentLength: size,
+		Expire:        s.mediaPresignExpire(),
+	})
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to presign upload")
+		s.logger.Error(ctx, "Failed to presign media upload",
+			logger.F("userID", userID),
+			logger.F("key", key),
+			logger.F("error", err.Error()))
+		return nil, fmt.Errorf("生成上传地址失败: %v", err)
+	}
+
+	span.SetAttributes(attribute.String("media.object_key", key))
+	span.SetStatus(codes.Ok, "media upload presigned")
+	return &model.MediaUpload{
+		UploadURL: upload.UploadURL,
+		Method:    upload.Method,
+		Headers:   upload.Headers,
+		ObjectKey: key,
+		ObjectURL: upload.ObjectURL,
+		MediaType: mediaTypeOf(contentType),
+		ExpiresAt: upload.ExpiresAt,
+	}, nil
+}
+
+// validateMediaContentType 规范化并校验上传文件类型，返回去掉参数后的小写MIME类型
+func (s *Service) validateMediaContentType(contentType string) (string, error) {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return "", fmt.Errorf("文件类型无效: %s", contentType)
+	}
+	for _, allowed := range strings.Split(s.config.Media.AllowedContentTypes, ",") {
+		if strings.EqualFold(strings.TrimSpace(allowed), mediaType) {
+			return mediaType, nil
+		}
+	}
+	return "", fmt.Errorf("不支持的文件类型: %s", mediaType)
+}
+
+// mediaPresignExpire 上传地址有效期，限制在较短范围内
+func (s *Service) mediaPresignExpire() time.Duration {
+	expire := s.config.Media.PresignExpire
+	if expire <= 0 {
+		expire = model.DefaultPresignExpire
+	}
+	if expire > model.MaxPresignExpire {
+		expire = model.MaxPresignExpire
+	}
+	return time.Duration(expire) * time.Second
+}
+
+// mediaFileExt 从原始文件名中提取扩展名，只保留字母和数字，不合法时返回空
+func mediaFileExt(filename string) string {
+	ext := strings.ToLower(strings.TrimPrefix(path.Ext(filename), "."))
+	if ext == "" || len(ext) > model.MaxMediaFilenameExtLen {
+		return ""
+	}
+	for _, c := range ext {
+		if (c < 'a' || c > 'z') && (c < '0' || c > '9') {
+			return ""
+		}
+	}
+	return "." + ext
+}
+
+// mediaTypeOf 根据MIME类型推断媒体文件类型
+func mediaTypeOf(contentType string) string {
+	switch {
+	case strings.HasPrefix(contentType, "image/"):
+		return model.MediaTypeImage
+	case strings.HasPrefix(contentType, "video/"):
+		return model.MediaTypeVideo
+	case strings.HasPrefix(contentType, "audio/"):
+		return model.MediaTypeAudio
+	default:
+		return model.MediaTypeFile
+	}
+}
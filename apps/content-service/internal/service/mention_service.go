@@ -0,0 +1,246 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"time"
+
+	"goim-social/api/rest"
+	"goim-social/apps/content-service/internal/model"
+	"goim-social/pkg/logger"
+)
+
+// ==================== @提及相关业务逻辑 ====================
+
+// mentionPattern 匹配@用户名，@前须为文本开头或非用户名字符，避免把邮箱地址当作提及
+var mentionPattern = regexp.MustCompile(`(?:^|[^\p{L}\p{N}_@])@([\p{L}\p{N}_]+)`)
+
+// parseMentions 按出现顺序提取去重后的用户名，最多limit个，超出部分和过长的@文本保留为普通文本
+func parseMentions(text string, limit int) []string {
+	if limit <= 0 {
+		return nil
+	}
+
+	var usernames []string
+	seen := make(map[string]bool)
+	for _, match := range mentionPattern.FindAllStringSubmatch(text, -1) {
+		username := match[1]
+		if len([]rune(username)) > model.MaxMentionUsernameLength || seen[username] {
+			continue
+		}
+		seen[username] = true
+		usernames = append(usernames, username)
+		if len(usernames) >= limit {
+			break
+		}
+	}
+	return usernames
+}
+
+// resolveMentions 通过user-service批量查询将@用户名解析为用户，用户名须精确匹配
+// 不存在的用户名不返回，调用方将其保留为普通文本；查询失败时返回错误，调用方保留原有提及记录
+func (s *Service) resolveMentions(ctx context.Context, targetID int64, targetType string, mentionerID int64, text string) ([]*model.ContentMention, error) {
+	usernames := parseMentions(text, s.config.MaxMentions)
+	if len(usernames) == 0 {
+		return nil, nil
+	}
+	if s.user == nil {
+		return nil, fmt.Errorf("用户服务不可用")
+	}
+
+	resp, err := s.user.BatchGetUsers(ctx, &rest.BatchGetUsersRequest{Usernames: usernames})
+	if err != nil {
+		return nil, fmt.Errorf("批量查询用户失败: %v", err)
+	}
+	if !resp.Success {
+		return nil, fmt.Errorf("批量查询用户失败: %s", resp.Message)
+	}
+
+	users := make(map[string]int64, len(resp.Users))
+	for _, user := range resp.Users {
+		users[user.Username] = user.Id
+	}
+
+	mentions := make([]*model.ContentMention, 0, len(usernames))
+	for _, username := range usernames {
+		userID, ok := users[username]
+		if !ok {
+			continue
+		}
+		mentions = append(mentions, &model.ContentMention{
+			TargetID:    targetID,
+			TargetType:  targetType,
+			UserID:      userID,
+			Username:    username,
+			MentionerID: mentionerID,
+		})
+	}
+	return mentions, nil
+}
+
+// syncMentions 解析文本中的@提及并替换对象的提及记录，返回当前的提及列表
+// 解析或保存失败只记录日志，不影响内容和评论的保存
+func (s *Service) syncMentions(ctx context.Context, targetID int64, targetType string, mentionerID int64, text string) []*model.ContentMention {
+	mentions, err := s.resolveMentions(ctx, targetID, targetType, mentionerID, text)
+	if err != nil {
+		s.logger.Warn(ctx, "Failed to resolve mentions, keep existing mentions",
+			logger.F("targetID", targetID),
+			logger.F("targetType", targetType),
+			logger.F("error", err.Error()))
+		return s.loadMentions(ctx, []int64{targetID}, targetType)[targetID]
+	}
+
+	if err := s.dao.ReplaceMentions(ctx, targetID, targetType, mentions); err != nil {
+		s.logger.Error(ctx, "Failed to save mentions",
+			logger.F("targetID", targetID),
+			logger.F("targetType", targetType),
+			logger.F("error", err.Error()))
+	}
+	return s.loadMentions(ctx, []int64{targetID}, targetType)[targetID]
+}
+
+// syncCommentMentions 解析可见评论的@提及并异步通知尚未通知的被提及用户
+func (s *Service) syncCommentMentions(ctx context.Context, comment *model.Comment) {
+	comment.Mentions = s.syncMentions(ctx, comment.ID, model.TargetTypeComment, comment.UserID, comment.Content)
+	if len(comment.Mentions) == 0 {
+		return
+	}
+	var contentID int64
+	if comment.TargetType == model.TargetTypeContent {
+		contentID = comment.TargetID
+	}
+	go s.notifyMentions(context.Background(), comment.ID, model.TargetTypeComment, contentID)
+}
+
+// loadMentions 批量加载对象的提及记录，按对象ID分组，失败时返回空结果
+func (s *Service) loadMentions(ctx context.Context, targetIDs []int64, targetType string) map[int64][]*model.ContentMention {
+	result := make(map[int64][]*model.ContentMention)
+	mentions, err := s.dao.GetMentions(ctx, targetIDs, targetType)
+	if err != nil {
+		s.logger.Warn(ctx, "Failed to load mentions",
+			logger.F("targetType", targetType),
+			logger.F("error", err.Error()))
+		return result
+	}
+	for _, mention := range mentions {
+		result[mention.TargetID] = append(result[mention.TargetID], mention)
+	}
+	return result
+}
+
+// attachContentMentions 为内容填充已解析的提及列表
+func (s *Service) attachContentMentions(ctx context.Context, contents ...*model.Content) {
+	ids := make([]int64, 0, len(contents))
+	for _, content := range contents {
+		if content != nil {
+			ids = append(ids, content.ID)
+		}
+	}
+	if len(ids) == 0 {
+		return
+	}
+
+	mentions := s.loadMentions(ctx, ids, model.TargetTypeContent)
+	for _, content := range contents {
+		if content != nil {
+			content.Mentions = mentions[content.ID]
+		}
+	}
+}
+
+// attachCommentMentions 为评论填充已解析的提及列表
+func (s *Service) attachCommentMentions(ctx context.Context, comments []*model.Comment) {
+	if len(comments) == 0 {
+		return
+	}
+
+	ids := make([]int64, 0, len(comments))
+	for _, comment := range comments {
+		ids = append(ids, comment.ID)
+	}
+	mentions := s.loadMentions(ctx, ids, model.TargetTypeComment)
+	for _, comment := range comments {
+		comment.Mentions = mentions[comment.ID]
+	}
+}
+
+// notifyMentions 通知对象中尚未通知的被提及用户，每条提及只通知一次
+// 不通知提及者自己和与提及者有拉黑关系的用户，contentID为提及所在的内容，用于客户端跳转
+func (s *Service) notifyMentions(ctx context.Context, targetID int64, targetType string, contentID int64) {
+	if s.kafka == nil {
+		return
+	}
+
+	mentions, err := s.dao.GetUnnotifiedMentions(ctx, targetID, targetType)
+	if err != nil {
+		s.logger.Warn(ctx, "Failed to get unnotified mentions",
+			logger.F("targetID", targetID),
+			logger.F("targetType", targetType),
+			logger.F("error", err.Error()))
+		return
+	}
+
+	for _, mention := range mentions {
+		if mention.UserID == mention.MentionerID || s.hiddenAuthors(ctx, mention.UserID).Has(mention.MentionerID) {
+			continue
+		}
+
+		now := time.Now()
+		marked, err := s.dao.MarkMentionNotified(ctx, mention.ID, now)
+		if err != nil {
+			s.logger.Warn(ctx, "Failed to mark mention notified",
+				logger.F("mentionID", mention.ID),
+				logger.F("error", err.Error()))
+			continue
+		}
+		if !marked {
+			continue
+		}
+
+		s.publishMentionNotice(ctx, mention, contentID, now)
+	}
+}
+
+// publishMentionNotice 发布提及通知，由message-service推送给被提及用户
+func (s *Service) publishMentionNotice(ctx context.Context, mention *model.ContentMention, contentID int64, now time.Time) {
+	notice, err := json.Marshal(&model.MentionNotice{
+		MentionID:   mention.ID,
+		TargetType:  mention.TargetType,
+		TargetID:    mention.TargetID,
+		ContentID:   contentID,
+		MentionerID: mention.MentionerID,
+	})
+	if err != nil {
+		s.logger.Warn(ctx, "Failed to marshal mention notice",
+			logger.F("mentionID", mention.ID),
+			logger.F("error", err.Error()))
+		return
+	}
+
+	event := &rest.MessageEvent{
+		Type: model.EventTypeMention,
+		Message: &rest.WSMessage{
+			MessageId:   mention.ID,
+			From:        mention.MentionerID,
+			To:          mention.UserID,
+			Content:     string(notice),
+			MessageType: model.MessageTypeMention,
+			Timestamp:   now.Unix(),
+			Priority:    model.PushPriorityHigh,
+		},
+		Timestamp: now.Unix(),
+	}
+	if err := s.kafka.PublishMessage(model.TopicDownlinkMessage, event); err != nil {
+		s.logger.Warn(ctx, "Failed to publish mention notice",
+			logger.F("mentionID", mention.ID),
+			logger.F("userID", mention.UserID),
+			logger.F("error", err.Error()))
+	}
+}
+
+// contentMentionText 返回内容中参与@提及解析的文本
+func contentMentionText(content *model.Content) string {
+	return content.Title + "\n" + content.Content
+}
@@ -0,0 +1,37 @@
+package service
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"goim-social/apps/content-service/internal/model"
+)
+
+// TestParseMentions 按出现顺序提取去重后的用户名，忽略邮箱地址和过长的@文本
+func TestParseMentions(t *testing.T) {
+	longName := strings.Repeat("a", model.MaxMentionUsernameLength+1)
+	cases := []struct {
+		name  string
+		text  string
+		limit int
+		want  []string
+	}{
+		{name: "start of text", text: "@alice 你好", limit: 10, want: []string{"alice"}},
+		{name: "order and dedup", text: "@bob 和 @alice，再提一次 @bob", limit: 10, want: []string{"bob", "alice"}},
+		{name: "chinese username", text: "你好 @小明！", limit: 10, want: []string{"小明"}},
+		{name: "email is not mention", text: "联系 alice@example.com", limit: 10, want: nil},
+		{name: "double at", text: "@@alice", limit: 10, want: nil},
+		{name: "too long", text: "@" + longName + " @carol", limit: 10, want: []string{"carol"}},
+		{name: "limit", text: "@a @b @c", limit: 2, want: []string{"a", "b"}},
+		{name: "zero limit", text: "@alice", limit: 0, want: nil},
+		{name: "no mention", text: "普通文本", limit: 10, want: nil},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := parseMentions(tc.text, tc.limit); !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("parseMentions(%q, %d) = %v, want %v", tc.text, tc.limit, got, tc.want)
+			}
+		})
+	}
+}
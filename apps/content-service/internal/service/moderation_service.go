@@ -0,0 +1,124 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+
+	"goim-social/apps/content-service/internal/model"
+	"goim-social/apps/content-service/internal/moderation"
+	tracecontext "goim-social/pkg/context"
+	"goim-social/pkg/logger"
+	"goim-social/pkg/telemetry"
+)
+
+// ==================== 发布审核相关业务逻辑 ====================
+
+// submitForModeration 将内容置为待审核并执行审核
+// 异步模式下立即返回，内容保持待审核直到审核结论生效，避免外部审核拖慢发布接口
+func (s *Service) submitForModeration(ctx context.Context, content *model.Content, operatorID int64) error {
+	if content.Status != model.ContentStatusPending {
+		if err := s.moderateContentStatus(ctx, content, model.ContentStatusPending, operatorID, "提交审核"); err != nil {
+			return err
+		}
+	}
+
+	if s.config.Moderation.Async {
+		go s.runModeration(context.Background(), content.ID)
+		return nil
+	}
+	s.runModeration(ctx, content.ID)
+	return nil
+}
+
+// runModeration 执行审核并根据结论变更内容状态，审核失败时保持待审核等待人工处理
+func (s *Service) runModeration(ctx context.Context, contentID int64) {
+	// 开始OpenTelemetry span
+	ctx, span := telemetry.StartSpan(ctx, "content.service.runModeration")
+	defer span.End()
+
+	span.SetAttributes(attribute.Int64("content.id", contentID))
+	ctx = tracecontext.WithContentID(ctx, contentID)
+
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(model.ModerationTimeout)*time.Second)
+	defer cancel()
+
+	content, err := s.dao.GetContent(ctx, contentID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "content not found")
+		s.logger.Error(ctx, "获取待审核内容失败", logger.F("contentID", contentID), logger.F("error", err.Error()))
+		return
+	}
+
+	result, err := s.moderator.Moderate(ctx, &moderation.Request{
+		ContentID:    content.ID,
+		AuthorID:     content.AuthorID,
+		Type:         content.Type,
+		Title:        content.Title,
+		Content:      content.Content,
+		TemplateData: content.TemplateData,
+	})
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "moderation failed")
+		s.logger.Error(ctx, "内容审核失败，保持待审核", logger.F("contentID", contentID), logger.F("error", err.Error()))
+		return
+	}
+	span.SetAttributes(attribute.String("moderation.decision", result.Decision))
+
+	// 审核期间内容可能已被作者或管理员变更，只处理仍处于待审核的内容
+	content, err = s.dao.GetContent(ctx, contentID)
+	if err != nil || content.Status != model.ContentStatusPending {
+		span.SetStatus(codes.Ok, "content no longer pending")
+		return
+	}
+
+	if err := s.applyModerationResult(ctx, content, result); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to apply moderation result")
+		s.logger.Error(ctx, "应用审核结论失败", logger.F("contentID", contentID), logger.F("error", err.Error()))
+		return
+	}
+
+	s.logger.Info(ctx, "内容审核完成",
+		logger.F("contentID", contentID),
+		logger.F("decision", result.Decision),
+		logger.F("reason", result.Reason))
+
+	span.SetStatus(codes.Ok, "content moderated")
+}
+
+// applyModerationResult 根据审核结论变更内容状态
+func (s *Service) applyModerationResult(ctx context.Context, content *model.Content, result *moderation.Result) error {
+	switch result.Decision {
+	case moderation.DecisionApprove:
+		now := time.Now()
+		content.PublishedAt = &now
+		if err := s.moderateContentStatus(ctx, content, model.ContentStatusPublished, model.SystemOperatorID, "自动审核通过"); err != nil {
+			return err
+		}
+		if fullContent, err := s.dao.GetContentWithRelations(ctx, content.ID); err == nil {
+			s.recordTrendingUsage(ctx, fullContent)
+		}
+		s.notifyMentions(ctx, content.ID, model.TargetTypeContent, content.ID)
+		return nil
+	case moderation.DecisionReject:
+		return s.moderateContentStatus(ctx, content, model.ContentStatusRejected, model.SystemOperatorID, "自动审核拒绝: "+result.Reason)
+	case moderation.DecisionFlag:
+		// 标记的内容保持待审核，只记录原因供人工复核
+		return s.dao.CreateStatusLog(ctx, &model.ContentStatusLog{
+			ContentID:  content.ID,
+			FromStatus: content.Status,
+			ToStatus:   content.Status,
+			OperatorID: model.SystemOperatorID,
+			Reason:     "自动审核标记，等待人工复核: " + result.Reason,
+			CreatedAt:  time.Now(),
+		})
+	default:
+		return fmt.Errorf("无效的审核结论: %s", result.Decision)
+	}
+}
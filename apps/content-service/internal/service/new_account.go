@@ -0,0 +1,168 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	goredis "github.com/go-redis/redis/v8"
+
+	"goim-social/api/rest"
+	"goim-social/apps/content-service/internal/model"
+	"goim-social/pkg/httpx"
+	"goim-social/pkg/logger"
+)
+
+// ==================== 新账号发布冷却 ====================
+// 注册时间不足阈值的账号创建内容和评论时按更严格的频率限制，上限为0时需要认证或注册满阈值后才能发布；
+// 认证账号可配置为不受限制。冷却期内的发布和被限制的发布都发布到审核事件Topic，供监控新账号的滥用模式
+
+// incrNewAccountRateScript 计数加1，首次计数或计数键缺少过期时间时设置窗口，返回计数和剩余毫秒数
+// 自增和设置过期在同一脚本中完成，避免设置过期前失败留下永不过期的计数
+var incrNewAccountRateScript = goredis.NewScript(`
+local count = redis.call('INCR', KEYS[1])
+if count == 1 or redis.call('PTTL', KEYS[1]) < 0 then
+	redis.call('PEXPIRE', KEYS[1], ARGV[1])
+end
+return {count, redis.call('PTTL', KEYS[1])}
+`)
+
+// newAccountState 账号所处的冷却期，liftsAt为注册满阈值的时间
+type newAccountState struct {
+	age     time.Duration
+	liftsAt time.Time
+}
+
+// checkNewAccountCooldown 检查新账号在冷却期内的发布频率，超出时返回包含解除时间的错误
+// 每次调用都计入频率，须在其他校验通过后、写入前调用，避免被拒绝的发布消耗额度；
+// 账号信息查询或计数失败时放行并记录日志，避免用户服务或Redis故障导致无法发布
+func (s *Service) checkNewAccountCooldown(ctx context.Context, userID int64, scope string) error {
+	state := s.accountCooldown(ctx, userID)
+	if state == nil {
+		return nil
+	}
+
+	cfg := s.config.NewAccount
+	limit := cfg.ContentLimit
+	action := "发布内容"
+	if scope == model.BanScopeComment {
+		limit = cfg.CommentLimit
+		action = "发表评论"
+	}
+	liftsAt := state.liftsAt.Format("2006-01-02 15:04:05")
+
+	if limit <= 0 {
+		s.publishNewAccountEvent(ctx, model.EventTypeNewAccountLimited, userID, scope, model.NewAccountReasonVerification, state, 0, limit)
+		return httpx.PermissionDenied(fmt.Errorf("新注册账号暂不能%s，请完成账号认证，或于%s注册满%d小时后再试", action, liftsAt, cfg.MinAgeHours))
+	}
+	if s.redis == nil {
+		return nil
+	}
+
+	window := time.Duration(cfg.Window) * time.Second
+	if window <= 0 {
+		window = time.Hour
+	}
+	key := fmt.Sprintf("%s:%s:%d", model.CacheKeyNewAccountRate, scope, userID)
+	res, err := incrNewAccountRateScript.Run(ctx, s.redis.GetClient(), []string{key}, window.Milliseconds()).Int64Slice()
+	if err == nil && len(res) != 2 {
+		err = fmt.Errorf("unexpected script result: %v", res)
+	}
+	if err != nil {
+		s.logger.Warn(ctx, "Failed to check new account rate limit",
+			logger.F("userID", userID),
+			logger.F("scope", scope),
+			logger.F("error", err.Error()))
+		return nil
+	}
+	count, ttl := res[0], time.Duration(res[1])*time.Millisecond
+
+	if count > int64(limit) {
+		s.publishNewAccountEvent(ctx, model.EventTypeNewAccountLimited, userID, scope, model.NewAccountReasonRateLimited, state, count, limit)
+		retryAt := time.Now().Add(window)
+		if ttl > 0 {
+			retryAt = time.Now().Add(ttl)
+		}
+		return httpx.TooManyRequests(fmt.Errorf("新注册账号每%s最多%s%d次，请于%s后再试；完成账号认证或于%s注册满%d小时后解除限制",
+			formatWindow(window), action, limit, retryAt.Format("2006-01-02 15:04:05"), liftsAt, cfg.MinAgeHours))
+	}
+
+	s.publishNewAccountEvent(ctx, model.EventTypeNewAccountActivity, userID, scope, "", state, count, limit)
+	return nil
+}
+
+// accountCooldown 返回账号的冷却期状态，未启用、账号已过冷却期或认证账号免于限制时返回nil
+func (s *Service) accountCooldown(ctx context.Context, userID int64) *newAccountState {
+	cfg := s.config.NewAccount
+	if !cfg.Enabled || cfg.MinAgeHours <= 0 || s.user == nil {
+		return nil
+	}
+
+	resp, err := s.user.GetUser(ctx, &rest.GetUserRequest{UserId: userID})
+	if err == nil && (!resp.Success || resp.User == nil) {
+		err = fmt.Errorf("%s", resp.Message)
+	}
+	if err != nil {
+		s.logger.Warn(ctx, "Failed to get account age, allow posting",
+			logger.F("userID", userID),
+			logger.F("error", err.Error()))
+		return nil
+	}
+	if resp.User.Verified && cfg.ExemptVerified {
+		return nil
+	}
+
+	createdAt := time.Unix(resp.User.CreatedAt, 0)
+	liftsAt := createdAt.Add(time.Duration(cfg.MinAgeHours) * time.Hour)
+	if !time.Now().Before(liftsAt) {
+		return nil
+	}
+	return &newAccountState{age: time.Since(createdAt), liftsAt: liftsAt}
+}
+
+// publishNewAccountEvent 发布新账号发布事件，供监控新账号的滥用模式，按用户ID分区
+func (s *Service) publishNewAccountEvent(ctx context.Context, eventType string, userID int64, scope, reason string, state *newAccountState, count int64, limit int) {
+	if s.kafka == nil {
+		return
+	}
+
+	event := map[string]interface{}{
+		"event_type":          eventType,
+		"user_id":             userID,
+		"scope":               scope,
+		"account_age_seconds": int64(state.age / time.Second),
+		"lifts_at":            state.liftsAt.Unix(),
+		"count":               count,
+		"limit":               limit,
+		"timestamp":           time.Now().Unix(),
+	}
+	if reason != "" {
+		event["reason"] = reason
+	}
+
+	eventData, err := json.Marshal(event)
+	if err != nil {
+		return
+	}
+
+	if err := s.kafka.SendMessage(model.TopicModerationEvents, []byte(strconv.FormatInt(userID, 10)), eventData); err != nil {
+		s.logger.Error(ctx, "Failed to publish new account event",
+			logger.F("eventType", eventType),
+			logger.F("userID", userID),
+			logger.F("error", err.Error()))
+	}
+}
+
+// formatWindow 频率限制窗口的描述，整小时或整分钟时按小时或分钟描述
+func formatWindow(window time.Duration) string {
+	switch {
+	case window%time.Hour == 0:
+		return fmt.Sprintf("%d小时", window/time.Hour)
+	case window%time.Minute == 0:
+		return fmt.Sprintf("%d分钟", window/time.Minute)
+	default:
+		return fmt.Sprintf("%d秒", window/time.Second)
+	}
+}
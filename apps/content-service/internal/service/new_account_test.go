@@ -0,0 +1,85 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+
+	"goim-social/api/rest"
+	"goim-social/apps/content-service/internal/model"
+	"goim-social/pkg/config"
+	"goim-social/pkg/httpx"
+	"goim-social/pkg/logger"
+)
+
+// accountUsers 返回固定注册时间和认证状态的用户服务客户端，err非空时查询失败
+type accountUsers struct {
+	rest.UserServiceClient
+	createdAt time.Time
+	verified  bool
+	err       error
+}
+
+func (u *accountUsers) GetUser(ctx context.Context, req *rest.GetUserRequest, opts ...grpc.CallOption) (*rest.GetUserResponse, error) {
+	if u.err != nil {
+		return nil, u.err
+	}
+	return &rest.GetUserResponse{Success: true, User: &rest.UserInfo{Id: req.UserId, CreatedAt: u.createdAt.Unix(), Verified: u.verified}}, nil
+}
+
+// TestCheckNewAccountCooldown 冷却期外、认证豁免或查询失败时放行，冷却期内上限为0时拒绝并提示解除时间
+func TestCheckNewAccountCooldown(t *testing.T) {
+	log, err := logger.NewLogger("error")
+	if err != nil {
+		t.Fatalf("创建日志失败: %v", err)
+	}
+	cfg := config.NewAccountConfig{Enabled: true, MinAgeHours: 24, ContentLimit: 0, CommentLimit: 3, ExemptVerified: true}
+	newUser := time.Now().Add(-time.Hour)
+	cases := []struct {
+		name     string
+		cfg      config.NewAccountConfig
+		users    *accountUsers
+		scope    string
+		wantCode int
+	}{
+		{name: "disabled", cfg: config.NewAccountConfig{MinAgeHours: 24}, users: &accountUsers{createdAt: newUser}, scope: model.BanScopeContent},
+		{name: "old account", cfg: cfg, users: &accountUsers{createdAt: time.Now().Add(-48 * time.Hour)}, scope: model.BanScopeContent},
+		{name: "verified exempt", cfg: cfg, users: &accountUsers{createdAt: newUser, verified: true}, scope: model.BanScopeContent},
+		{name: "user lookup failed", cfg: cfg, users: &accountUsers{err: errors.New("unavailable")}, scope: model.BanScopeContent},
+		{name: "zero limit", cfg: cfg, users: &accountUsers{createdAt: newUser}, scope: model.BanScopeContent, wantCode: http.StatusForbidden},
+		{name: "no redis", cfg: cfg, users: &accountUsers{createdAt: newUser}, scope: model.BanScopeComment},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := &Service{user: tc.users, logger: log}
+			s.config.NewAccount = tc.cfg
+			err := s.checkNewAccountCooldown(context.Background(), 1, tc.scope)
+			if tc.wantCode == 0 && err != nil {
+				t.Errorf("checkNewAccountCooldown() = %v, want allowed", err)
+			}
+			if tc.wantCode != 0 && httpx.StatusOf(err) != tc.wantCode {
+				t.Errorf("checkNewAccountCooldown() = %v, want status %d", err, tc.wantCode)
+			}
+		})
+	}
+}
+
+// TestFormatWindow 整小时和整分钟的窗口按小时和分钟描述，其余按秒
+func TestFormatWindow(t *testing.T) {
+	cases := map[time.Duration]string{
+		time.Hour:        "1小时",
+		2 * time.Hour:    "2小时",
+		30 * time.Minute: "30分钟",
+		90 * time.Minute: "90分钟",
+		45 * time.Second: "45秒",
+	}
+	for window, want := range cases {
+		if got := formatWindow(window); got != want {
+			t.Errorf("formatWindow(%v) = %s, want %s", window, got, want)
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"goim-social/api/rest"
+	"goim-social/apps/content-service/internal/model"
+	"goim-social/pkg/logger"
+)
+
+// checkPostingBan 检查用户是否被管理员限制发布，限制期间返回包含解除时间和原因的错误
+// 发布限制由user-service保存并按到期时间自动失效；查询失败时放行并记录日志，避免用户服务故障导致无法发布
+func (s *Service) checkPostingBan(ctx context.Context, userID int64, scope string) error {
+	if s.user == nil {
+		return nil
+	}
+
+	resp, err := s.user.GetContentBan(ctx, &rest.GetContentBanRequest{UserId: userID, Scope: scope})
+	if err == nil && !resp.Success {
+		err = fmt.Errorf("%s", resp.Message)
+	}
+	if err != nil {
+		s.logger.Warn(ctx, "Failed to check posting ban, allow posting",
+			logger.F("userID", userID),
+			logger.F("scope", scope),
+			logger.F("error", err.Error()))
+		return nil
+	}
+	if !resp.Banned || resp.Ban == nil {
+		return nil
+	}
+
+	action := "发布内容"
+	if scope == model.BanScopeComment {
+		action = "发表评论"
+	}
+	until := "永久限制"
+	if resp.Ban.ExpiresAt > 0 {
+		until = time.Unix(resp.Ban.ExpiresAt, 0).Format("2006-01-02 15:04:05") + "解除"
+	}
+	if resp.Ban.Reason != "" {
+		return fmt.Errorf("您已被限制%s（%s），原因：%s", action, until, resp.Ban.Reason)
+	}
+	return fmt.Errorf("您已被限制%s（%s）", action, until)
+}
@@ -0,0 +1,163 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+
+	"goim-social/apps/content-service/internal/model"
+	tracecontext "goim-social/pkg/context"
+	"goim-social/pkg/httpx"
+	"goim-social/pkg/logger"
+	"goim-social/pkg/redis"
+	"goim-social/pkg/telemetry"
+)
+
+// ==================== 个人主页置顶 ====================
+// 作者可以把自己已发布的内容置顶到个人主页，每个作者最多置顶MaxProfilePinnedContents条，
+// 用户内容列表中置顶内容按置顶时间倒序排在最前。内容取消发布、被下架或删除时自动取消置顶。
+// 同一作者的置顶操作持有作者维度的锁，避免并发置顶超过上限
+
+// PinContentToProfile 置顶内容到作者个人主页，仅作者本人可以置顶已发布的内容，重复置顶视为成功
+func (s *Service) PinContentToProfile(ctx context.Context, contentID, authorID int64) (*model.Content, error) {
+	// 开始OpenTelemetry span
+	ctx, span := telemetry.StartSpan(ctx, "content.service.PinContentToProfile")
+	defer span.End()
+
+	// 设置span属性
+	span.SetAttributes(
+		attribute.Int64("content.id", contentID),
+		attribute.Int64("content.author_id", authorID),
+	)
+
+	// 将业务信息添加到context
+	ctx = tracecontext.WithUserID(ctx, authorID)
+	ctx = tracecontext.WithContentID(ctx, contentID)
+
+	content, err := s.getOwnContent(ctx, contentID, authorID)
+	if err != nil {
+		span.SetStatus(codes.Error, "content not pinnable")
+		return nil, err
+	}
+	if content.Status != model.ContentStatusPublished {
+		span.SetStatus(codes.Error, "content not published")
+		return nil, httpx.InvalidArgument(fmt.Errorf("只能置顶已发布的内容"))
+	}
+
+	pin := func(ctx context.Context) error {
+		pinned, err := s.dao.PinContentToProfile(ctx, authorID, contentID, model.MaxProfilePinnedContents)
+		if err != nil {
+			return fmt.Errorf("置顶内容失败: %v", err)
+		}
+		if !pinned {
+			return httpx.Conflict(fmt.Errorf("最多置顶%d条内容，请先取消其他置顶", model.MaxProfilePinnedContents))
+		}
+		return nil
+	}
+
+	if s.redis != nil {
+		lockKey := fmt.Sprintf("%s:%d", model.CacheKeyProfilePinLock, authorID)
+		lockOpts := redis.LockOptions{
+			TTL:         model.ProfilePinLockTTLSeconds * time.Second,
+			WaitTimeout: model.ProfilePinLockWaitMillis * time.Millisecond,
+		}
+		err = s.redis.WithLock(ctx, lockKey, lockOpts, pin)
+		if errors.Is(err, redis.ErrLockNotObtained) {
+			err = httpx.Unavailable(fmt.Errorf("置顶操作繁忙，请稍后重试"))
+		}
+	} else {
+		err = pin(ctx)
+	}
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to pin content")
+		return nil, err
+	}
+
+	s.clearContentCache(ctx, contentID)
+
+	fullContent, err := s.dao.GetContentWithRelations(ctx, contentID)
+	if err != nil {
+		s.logger.Error(ctx, "Failed to get full content after pin",
+			logger.F("contentID", contentID),
+			logger.F("error", err.Error()))
+		fullContent = content
+	}
+	s.attachProfilePin(ctx, fullContent)
+
+	s.logger.Info(ctx, "Content pinned to profile",
+		logger.F("contentID", contentID),
+		logger.F("authorID", authorID))
+
+	span.SetStatus(codes.Ok, "content pinned successfully")
+	return fullContent, nil
+}
+
+// UnpinContentFromProfile 取消内容在作者个人主页的置顶，未置顶时视为成功
+func (s *Service) UnpinContentFromProfile(ctx context.Context, contentID, authorID int64) error {
+	// 开始OpenTelemetry span
+	ctx, span := telemetry.StartSpan(ctx, "content.service.UnpinContentFromProfile")
+	defer span.End()
+
+	// 设置span属性
+	span.SetAttributes(
+		attribute.Int64("content.id", contentID),
+		attribute.Int64("content.author_id", authorID),
+	)
+
+	// 将业务信息添加到context
+	ctx = tracecontext.WithUserID(ctx, authorID)
+	ctx = tracecontext.WithContentID(ctx, contentID)
+
+	if _, err := s.getOwnContent(ctx, contentID, authorID); err != nil {
+		span.SetStatus(codes.Error, "content not unpinnable")
+		return err
+	}
+
+	if err := s.dao.UnpinContentFromProfile(ctx, authorID, contentID); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to unpin content")
+		return fmt.Errorf("取消置顶失败: %v", err)
+	}
+
+	s.clearContentCache(ctx, contentID)
+
+	s.logger.Info(ctx, "Content unpinned from profile",
+		logger.F("contentID", contentID),
+		logger.F("authorID", authorID))
+
+	span.SetStatus(codes.Ok, "content unpinned successfully")
+	return nil
+}
+
+// getOwnContent 获取作者本人的内容，不是作者时返回无权限
+func (s *Service) getOwnContent(ctx context.Context, contentID, authorID int64) (*model.Content, error) {
+	if contentID <= 0 || authorID <= 0 {
+		return nil, httpx.InvalidArgument(fmt.Errorf("内容ID或作者ID无效"))
+	}
+
+	content, err := s.dao.GetContent(ctx, contentID)
+	if err != nil {
+		return nil, fmt.Errorf("内容不存在: %v", err)
+	}
+	if content.AuthorID != authorID {
+		return nil, httpx.PermissionDenied(fmt.Errorf("只有作者可以置顶或取消置顶内容"))
+	}
+	return content, nil
+}
+
+// attachProfilePin 填充内容的个人主页置顶时间，查询失败时按未置顶返回
+func (s *Service) attachProfilePin(ctx context.Context, content *model.Content) {
+	pinnedAt, err := s.dao.GetProfilePinnedAt(ctx, content.ID)
+	if err != nil {
+		s.logger.Warn(ctx, "Failed to get profile pin",
+			logger.F("contentID", content.ID),
+			logger.F("error", err.Error()))
+		return
+	}
+	content.PinnedAt = pinnedAt
+}
@@ -0,0 +1,512 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+
+	"goim-social/apps/content-service/internal/model"
+	"goim-social/pkg/audit"
+	tracecontext "goim-social/pkg/context"
+	"goim-social/pkg/httpx"
+	"goim-social/pkg/logger"
+	"goim-social/pkg/telemetry"
+)
+
+// ==================== 举报相关业务逻辑 ====================
+
+// ReportContent 举报内容，同一对象的待处理举报达到阈值后自动隐藏待审核
+func (s *Service) ReportContent(ctx context.Context, contentID, reporterID int64, reason, note string) error {
+	// 开始OpenTelemetry span
+	ctx, span := telemetry.StartSpan(ctx, "content.service.ReportContent")
+	defer span.End()
+
+	// 设置span属性
+	span.SetAttributes(
+		attribute.Int64("report.content_id", contentID),
+		attribute.Int64("report.reporter_id", reporterID),
+		attribute.String("report.reason", reason),
+	)
+
+	// 将业务信息添加到context
+	ctx = tracecontext.WithUserID(ctx, reporterID)
+	ctx = tracecontext.WithContentID(ctx, contentID)
+
+	if err := s.validateReportParams(reporterID, reason, note); err != nil {
+		span.SetStatus(codes.Error, "invalid parameters")
+		return err
+	}
+
+	content, err := s.dao.GetContent(ctx, contentID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "content not found")
+		return fmt.Errorf("内容不存在")
+	}
+	if content.Status == model.ContentStatusDeleted {
+		span.SetStatus(codes.Error, "content deleted")
+		return fmt.Errorf("内容已删除")
+	}
+	if content.AuthorID == reporterID {
+		span.SetStatus(codes.Error, "cannot report own content")
+		return fmt.Errorf("不能举报自己的内容")
+	}
+
+	if err := s.createReport(ctx, contentID, model.TargetTypeContent, reporterID, reason, note); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to create report")
+		return err
+	}
+
+	// 达到阈值且内容仍公开时，自动隐藏待审核
+	pendingCount, err := s.dao.CountPendingReports(ctx, contentID, model.TargetTypeContent)
+	if err != nil {
+		s.logger.Error(ctx, "Failed to count pending reports",
+			logger.F("contentID", contentID),
+			logger.F("error", err.Error()))
+	} else if pendingCount >= model.ReportEscalateThreshold && content.Status == model.ContentStatusPublished {
+		reasonText := fmt.Sprintf("举报次数达到%d次，自动隐藏待审核", pendingCount)
+		if err := s.moderateContentStatus(ctx, content, model.ContentStatusPending, 0, reasonText); err != nil {
+			s.logger.Error(ctx, "Failed to escalate reported content",
+				logger.F("contentID", contentID),
+				logger.F("error", err.Error()))
+		} else {
+			s.publishReportEvent(ctx, "report_escalated", contentID, model.TargetTypeContent, map[string]interface{}{
+				"pending_count": pendingCount,
+			})
+		}
+	}
+
+	span.SetAttributes(attribute.Int64("report.pending_count", pendingCount))
+	span.SetStatus(codes.Ok, "content reported successfully")
+	return nil
+}
+
+// ReportComment 举报评论，举报人信息不会透露给评论作者
+func (s *Service) ReportComment(ctx context.Context, commentID, reporterID int64, reason, note string) error {
+	// 开始OpenTelemetry span
+	ctx, span := telemetry.StartSpan(ctx, "content.service.ReportComment")
+	defer span.End()
+
+	// 设置span属性
+	span.SetAttributes(
+		attribute.Int64("report.comment_id", commentID),
+		attribute.Int64("report.reporter_id", reporterID),
+		attribute.String("report.reason", reason),
+	)
+
+	// 将业务信息添加到context
+	ctx = tracecontext.WithUserID(ctx, reporterID)
+
+	if err := s.validateReportParams(reporterID, reason, note); err != nil {
+		span.SetStatus(codes.Error, "invalid parameters")
+		return err
+	}
+
+	comment, err := s.dao.GetComment(ctx, commentID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "comment not found")
+		return fmt.Errorf("评论不存在")
+	}
+	if comment.UserID == reporterID {
+		span.SetStatus(codes.Error, "cannot report own comment")
+		return fmt.Errorf("不能举报自己的评论")
+	}
+
+	if err := s.createReport(ctx, commentID, model.TargetTypeComment, reporterID, reason, note); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to create report")
+		return err
+	}
+
+	// 达到阈值且评论仍可见时，自动隐藏待审核
+	pendingCount, err := s.dao.CountPendingReports(ctx, commentID, model.TargetTypeComment)
+	if err != nil {
+		s.logger.Error(ctx, "Failed to count pending reports",
+			logger.F("commentID", commentID),
+			logger.F("error", err.Error()))
+	} else if pendingCount >= model.ReportEscalateThreshold && !isHiddenCommentStatus(comment.Status) {
+		reasonText := fmt.Sprintf("举报次数达到%d次，自动隐藏待审核", pendingCount)
+		if err := s.moderateCommentStatus(ctx, comment, model.CommentStatusHidden, 0,
+			model.CommentModerationActionAutoHide, reasonText, pendingCount); err != nil {
+			s.logger.Error(ctx, "Failed to escalate reported comment",
+				logger.F("commentID", commentID),
+				logger.F("error", err.Error()))
+		} else {
+			s.publishReportEvent(ctx, "report_escalated", commentID, model.TargetTypeComment, map[string]interface{}{
+				"pending_count": pendingCount,
+			})
+		}
+	}
+
+	span.SetAttributes(attribute.Int64("report.pending_count", pendingCount))
+	span.SetStatus(codes.Ok, "comment reported successfully")
+	return nil
+}
+
+// ListReports 获取按对象聚合的举报列表（管理员）
+func (s *Service) ListReports(ctx context.Context, operatorID int64, targetType, status string, page, pageSize int32) ([]*model.ReportSummary, int64, error) {
+	if !s.admins[operatorID] {
+		return nil, 0, httpx.PermissionDenied(fmt.Errorf("无权限查看举报"))
+	}
+	if targetType == "" {
+		targetType = model.TargetTypeContent
+	}
+	if status == "" {
+		status = model.ReportStatusPending
+	}
+	if page <= 0 {
+		page = 1
+	}
+	pageSize = s.paging.PageSize32(pageSize)
+
+	return s.dao.GetReportSummaries(ctx, targetType, status, page, pageSize)
+}
+
+// ResolveReport 处理对象的所有待处理举报（管理员）
+func (s *Service) ResolveReport(ctx context.Context, targetID int64, targetType string, operatorID int64, action, note string) error {
+	// 开始OpenTelemetry span
+	ctx, span := telemetry.StartSpan(ctx, "content.service.ResolveReport")
+	defer span.End()
+
+	// 设置span属性
+	span.SetAttributes(
+		attribute.Int64("report.target_id", targetID),
+		attribute.String("report.target_type", targetType),
+		attribute.Int64("report.operator_id", operatorID),
+		attribute.String("report.action", action),
+	)
+
+	// 将业务信息添加到context
+	ctx = tracecontext.WithUserID(ctx, operatorID)
+
+	// 处理举报会下架内容或评论，只允许配置的管理员操作
+	if !s.admins[operatorID] {
+		span.SetStatus(codes.Error, "permission denied")
+		return httpx.PermissionDenied(fmt.Errorf("无权限处理举报"))
+	}
+
+	if targetType == "" {
+		targetType = model.TargetTypeContent
+	}
+
+	var reportStatus string
+	switch action {
+	case model.ReportActionRemove:
+		reportStatus = model.ReportStatusResolved
+	case model.ReportActionDismiss:
+		reportStatus = model.ReportStatusDismissed
+	default:
+		span.SetStatus(codes.Error, "invalid action")
+		return fmt.Errorf("无效的处理动作: %s", action)
+	}
+
+	var err error
+	switch targetType {
+	case model.TargetTypeContent:
+		err = s.applyContentReportAction(ctx, targetID, operatorID, action, note)
+	case model.TargetTypeComment:
+		err = s.applyCommentReportAction(ctx, targetID, operatorID, action, note)
+	default:
+		err = fmt.Errorf("不支持的举报对象类型: %s", targetType)
+	}
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to apply report action")
+		return err
+	}
+
+	affected, err := s.dao.ResolveReports(ctx, targetID, targetType, operatorID, reportStatus, note)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to resolve reports")
+		return fmt.Errorf("更新举报状态失败: %v", err)
+	}
+
+	s.auditor.Record(audit.Entry{
+		ActorID:    operatorID,
+		Action:     audit.ActionReportResolve,
+		TargetType: targetType,
+		TargetID:   targetID,
+		Reason:     note,
+		Detail:     map[string]string{"action": action, "resolved": strconv.FormatInt(affected, 10)},
+	})
+
+	s.publishReportEvent(ctx, "report_resolved", targetID, targetType, map[string]interface{}{
+		"operator_id": operatorID,
+		"action":      action,
+		"resolved":    affected,
+	})
+
+	s.logger.Info(ctx, "Reports resolved",
+		logger.F("targetID", targetID),
+		logger.F("targetType", targetType),
+		logger.F("operatorID", operatorID),
+		logger.F("action", action),
+		logger.F("resolved", affected))
+
+	span.SetAttributes(attribute.Int64("report.resolved_count", affected))
+	span.SetStatus(codes.Ok, "reports resolved successfully")
+	return nil
+}
+
+// applyContentReportAction 对被举报内容执行处理动作
+func (s *Service) applyContentReportAction(ctx context.Context, contentID, operatorID int64, action, note string) error {
+	content, err := s.dao.GetContent(ctx, contentID)
+	if err != nil {
+		return fmt.Errorf("内容不存在")
+	}
+
+	switch action {
+	case model.ReportActionRemove:
+		if content.Status == model.ContentStatusRejected || content.Status == model.ContentStatusDeleted {
+			return nil
+		}
+		return s.moderateContentStatus(ctx, content, model.ContentStatusRejected, operatorID, "举报成立: "+note)
+	case model.ReportActionDismiss:
+		// 仅恢复曾经发布过、因举报被隐藏的内容
+		if content.Status != model.ContentStatusPending || content.PublishedAt == nil {
+			return nil
+		}
+		return s.moderateContentStatus(ctx, content, model.ContentStatusPublished, operatorID, "举报驳回: "+note)
+	}
+	return nil
+}
+
+// applyCommentReportAction 对被举报评论执行处理动作，并记录审核日志
+// 只由ResolveReport在校验管理员身份后调用，普通用户只能举报评论，不能隐藏或恢复评论
+func (s *Service) applyCommentReportAction(ctx context.Context, commentID, operatorID int64, action, note string) error {
+	comment, err := s.dao.GetComment(ctx, commentID)
+	if err != nil {
+		return fmt.Errorf("评论不存在")
+	}
+
+	pendingCount, err := s.dao.CountPendingReports(ctx, commentID, model.TargetTypeComment)
+	if err != nil {
+		return fmt.Errorf("统计待处理举报失败: %v", err)
+	}
+
+	newStatus := comment.Status
+	switch action {
+	case model.ReportActionRemove:
+		newStatus = model.CommentStatusRejected
+	case model.ReportActionDismiss:
+		// 仅恢复因举报被隐藏的评论，违禁词拦截的评论须通过评论复核恢复
+		if comment.Status == model.CommentStatusHidden {
+			newStatus = model.CommentStatusApproved
+		}
+	}
+
+	return s.moderateCommentStatus(ctx, comment, newStatus, operatorID, action, note, pendingCount)
+}
+
+// moderateCommentStatus 变更评论状态并记录审核日志，状态不变时只记录日志
+func (s *Service) moderateCommentStatus(ctx context.Context, comment *model.Comment, newStatus string, operatorID int64, action, reason string, reportCount int64) error {
+	oldStatus := comment.Status
+	if newStatus != oldStatus {
+		if err := s.dao.UpdateCommentStatus(ctx, comment.ID, newStatus); err != nil {
+			return fmt.Errorf("更新评论状态失败: %v", err)
+		}
+		comment.Status = newStatus
+	}
+
+	s.recordCommentModeration(ctx, comment, oldStatus, operatorID, action, reason, reportCount)
+	return nil
+}
+
+// recordCommentModeration 评论状态变更后记录审核日志和审计，清除评论缓存，评论恢复可见时解析@提及
+func (s *Service) recordCommentModeration(ctx context.Context, comment *model.Comment, oldStatus string, operatorID int64, action, reason string, reportCount int64) {
+	newStatus := comment.Status
+	moderationLog := &model.CommentModerationLog{
+		CommentID:   comment.ID,
+		FromStatus:  oldStatus,
+		ToStatus:    newStatus,
+		OperatorID:  operatorID,
+		Action:      action,
+		Reason:      reason,
+		ReportCount: reportCount,
+		CreatedAt:   time.Now(),
+	}
+	if err := s.dao.CreateCommentModerationLog(ctx, moderationLog); err != nil {
+		s.logger.Error(ctx, "Failed to create comment moderation log",
+			logger.F("commentID", comment.ID),
+			logger.F("error", err.Error()))
+	}
+	s.auditor.Record(audit.Entry{
+		ActorID:    operatorID,
+		Action:     audit.ActionCommentModerate,
+		TargetType: model.TargetTypeComment,
+		TargetID:   comment.ID,
+		Reason:     reason,
+		Detail:     map[string]string{"action": action, "from": oldStatus, "to": newStatus},
+	})
+
+	// 评论可见性变化后清除评论列表缓存，热门评论缓存显式清除，不依赖clearContentCache的实现
+	if comment.TargetType == model.TargetTypeContent {
+		s.clearContentCache(ctx, comment.TargetID)
+		s.clearTopCommentsCache(ctx, comment.TargetID)
+	}
+
+	// 隐藏的评论恢复可见后解析@提及，违禁词拦截的评论创建时未解析，被提及用户在评论可见后才收到通知
+	if isHiddenCommentStatus(oldStatus) && !isHiddenCommentStatus(newStatus) {
+		s.syncCommentMentions(ctx, comment)
+	}
+}
+
+// isHiddenCommentStatus 判断评论是否已对外隐藏
+func isHiddenCommentStatus(status string) bool {
+	for _, hidden := range model.HiddenCommentStatuses() {
+		if hidden == status {
+			return true
+		}
+	}
+	return false
+}
+
+// moderateContentStatus 审核流程变更内容状态，不受作者状态流转规则限制
+func (s *Service) moderateContentStatus(ctx context.Context, content *model.Content, newStatus string, operatorID int64, reason string) error {
+	oldStatus := content.Status
+	content.Status = newStatus
+	content.UpdatedAt = time.Now()
+
+	if err := s.dao.UpdateContent(ctx, content); err != nil {
+		return fmt.Errorf("更新内容状态失败: %v", err)
+	}
+
+	statusLog := &model.ContentStatusLog{
+		ContentID:  content.ID,
+		FromStatus: oldStatus,
+		ToStatus:   newStatus,
+		OperatorID: operatorID,
+		Reason:     reason,
+		CreatedAt:  time.Now(),
+	}
+	if err := s.dao.CreateStatusLog(ctx, statusLog); err != nil {
+		s.logger.Error(ctx, "Failed to create status log",
+			logger.F("contentID", content.ID),
+			logger.F("error", err.Error()))
+	}
+
+	// 作者自己提交审核不属于审核操作，不记审计
+	if operatorID != content.AuthorID {
+		s.auditor.Record(audit.Entry{
+			ActorID:    operatorID,
+			Action:     audit.ActionContentStatusChange,
+			TargetType: model.TargetTypeContent,
+			TargetID:   content.ID,
+			Reason:     reason,
+			Detail:     map[string]string{"from": oldStatus, "to": newStatus},
+		})
+	}
+
+	s.clearContentCache(ctx, content.ID)
+	return nil
+}
+
+// createReport 创建举报记录，同一用户对同一对象只能举报一次
+func (s *Service) createReport(ctx context.Context, targetID int64, targetType string, reporterID int64, reason, note string) error {
+	if err := s.checkReportRateLimit(ctx, reporterID); err != nil {
+		return err
+	}
+
+	reported, err := s.dao.HasReported(ctx, targetID, targetType, reporterID)
+	if err != nil {
+		return fmt.Errorf("检查举报记录失败: %v", err)
+	}
+	if reported {
+		return fmt.Errorf("您已举报过该对象，请等待处理")
+	}
+
+	report := &model.Report{
+		TargetID:   targetID,
+		TargetType: targetType,
+		ReporterID: reporterID,
+		Reason:     reason,
+		Note:       strings.TrimSpace(note),
+		Status:     model.ReportStatusPending,
+		CreatedAt:  time.Now(),
+		UpdatedAt:  time.Now(),
+	}
+	if err := s.dao.CreateReport(ctx, report); err != nil {
+		return fmt.Errorf("创建举报失败: %v", err)
+	}
+
+	s.publishReportEvent(ctx, "report_created", targetID, targetType, map[string]interface{}{
+		"report_id": report.ID,
+		"reason":    reason,
+	})
+	return nil
+}
+
+// checkReportRateLimit 检查举报人在限流窗口内的举报次数，Redis异常时放行
+func (s *Service) checkReportRateLimit(ctx context.Context, reporterID int64) error {
+	if s.redis == nil {
+		return nil
+	}
+
+	key := fmt.Sprintf("%s:%d", model.CacheKeyReportRateLimit, reporterID)
+	count, err := s.redis.GetClient().Incr(ctx, key).Result()
+	if err != nil {
+		s.logger.Warn(ctx, "Failed to check report rate limit",
+			logger.F("reporterID", reporterID),
+			logger.F("error", err.Error()))
+		return nil
+	}
+	if count == 1 {
+		s.redis.Expire(ctx, key, time.Duration(model.ReportRateLimitWindow)*time.Second)
+	}
+	if count > model.ReportRateLimitMax {
+		return fmt.Errorf("举报过于频繁，请稍后再试")
+	}
+	return nil
+}
+
+// validateReportParams 验证举报参数
+func (s *Service) validateReportParams(reporterID int64, reason, note string) error {
+	if reporterID <= 0 {
+		return fmt.Errorf("举报人ID无效")
+	}
+	if !model.ValidateReportReason(reason) {
+		return fmt.Errorf("无效的举报原因")
+	}
+	if len([]rune(note)) > model.MaxReportNoteLength {
+		return fmt.Errorf("举报说明不能超过%d个字符", model.MaxReportNoteLength)
+	}
+	return nil
+}
+
+// publishReportEvent 发布举报事件，供审核后台订阅
+func (s *Service) publishReportEvent(ctx context.Context, eventType string, targetID int64, targetType string, extra map[string]interface{}) {
+	if s.kafka == nil {
+		return
+	}
+
+	event := map[string]interface{}{
+		"event_type":  eventType,
+		"target_id":   targetID,
+		"target_type": targetType,
+		"timestamp":   time.Now().Unix(),
+	}
+	for k, v := range extra {
+		event[k] = v
+	}
+
+	eventData, err := json.Marshal(event)
+	if err != nil {
+		return
+	}
+
+	if err := s.kafka.SendMessage("moderation-events", nil, eventData); err != nil {
+		s.logger.Error(ctx, "Failed to publish report event",
+			logger.F("eventType", eventType),
+			logger.F("targetID", targetID),
+			logger.F("error", err.Error()))
+	}
+}
@@ -2,43 +2,93 @@ package service
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"time"
 
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
 
+	"goim-social/api/rest"
 	"goim-social/apps/content-service/internal/dao"
 	"goim-social/apps/content-service/internal/model"
+	"goim-social/apps/content-service/internal/moderation"
+	"goim-social/apps/content-service/internal/storage"
+	"goim-social/pkg/audit"
+	"goim-social/pkg/blocklist"
+	"goim-social/pkg/config"
 	tracecontext "goim-social/pkg/context"
+	"goim-social/pkg/editwindow"
 	"goim-social/pkg/kafka"
 	"goim-social/pkg/logger"
+	"goim-social/pkg/outbox"
+	"goim-social/pkg/pagination"
+	"goim-social/pkg/profanity"
 	"goim-social/pkg/redis"
 	"goim-social/pkg/telemetry"
 )
 
 // Service 内容服务
 type Service struct {
-	dao    dao.ContentDAO
-	redis  *redis.RedisClient
-	kafka  *kafka.Producer
-	logger logger.Logger
+	dao       dao.ContentDAO
+	redis     *redis.RedisClient
+	kafka     *kafka.Producer
+	relay     *outbox.Relay // 发件箱转发器，业务事务提交后唤醒，为nil时按扫描间隔转发
+	logger    logger.Logger
+	config    config.ContentConfig
+	moderator moderation.Moderator     // 发布审核器，为nil时不审核
+	profanity *profanity.Filter        // 违禁词过滤器，为nil时不过滤
+	storage   storage.Presigner        // 媒体上传预签名器，为nil时不支持媒体上传
+	blocks    *blocklist.Store         // 拉黑关系缓存，为nil时不屏蔽
+	auditor   *audit.Recorder          // 审核和管理操作的审计记录器
+	logic     rest.LogicServiceClient  // 逻辑服务客户端，分享到站内会话时发送链接消息，为nil时不发送
+	user      rest.UserServiceClient   // 用户服务客户端，解析@提及的用户名、查询发布限制和账号注册时间，为nil时不解析也不限制
+	social    rest.SocialServiceClient // 社交服务客户端，判断好友关系，为nil时好友可见的内容只对作者可见
+	paging    pagination.Limits        // 列表接口的每页数量限制
+	edits     editwindow.Windows       // 内容和评论的编辑时限
+	admins    map[int64]bool           // 允许处理举报和管理分类的管理员
 }
 
 // NewService 创建内容服务实例
-func NewService(contentDAO dao.ContentDAO, redis *redis.RedisClient, kafka *kafka.Producer, log logger.Logger) *Service {
-	return &Service{
-		dao:    contentDAO,
-		redis:  redis,
-		kafka:  kafka,
-		logger: log,
-	}
+func NewService(contentDAO dao.ContentDAO, redis *redis.RedisClient, kafka *kafka.Producer, relay *outbox.Relay, log logger.Logger, cfg config.ContentConfig, moderator moderation.Moderator, filter *profanity.Filter, presigner storage.Presigner, auditor *audit.Recorder, logic rest.LogicServiceClient, user rest.UserServiceClient, social rest.SocialServiceClient, paging pagination.Limits, edits editwindow.Windows) *Service {
+	svc := &Service{
+		dao:       contentDAO,
+		redis:     redis,
+		kafka:     kafka,
+		relay:     relay,
+		logger:    log,
+		config:    cfg,
+		moderator: moderator,
+		profanity: filter,
+		storage:   presigner,
+		auditor:   auditor,
+		logic:     logic,
+		user:      user,
+		social:    social,
+		paging:    paging,
+		edits:     edits,
+		admins:    config.ParseUserIDs(cfg.AdminIDs),
+	}
+	if redis != nil {
+		svc.blocks = blocklist.NewStore(redis)
+	}
+	return svc
+}
+
+// PageSize 规范每页数量，与列表查询使用相同的默认值和上限，用于响应中的分页元数据
+func (s *Service) PageSize(size int32) int32 {
+	return s.paging.PageSize32(size)
+}
+
+// EditWindows 内容、评论和消息的编辑时限，与各服务校验编辑时使用同一份配置
+func (s *Service) EditWindows() []editwindow.Window {
+	return s.edits.All()
 }
 
 // CreateContent 创建内容
 func (s *Service) CreateContent(ctx context.Context, authorID int64,
-	title, content, contentType string, mediaFiles []model.ContentMediaFile,
-	tagIDs, topicIDs []int64, templateData string, saveAsDraft bool) (*model.Content, error) {
+	title, content, summary, contentType string, mediaFiles []model.ContentMediaFile,
+	tagIDs, topicIDs []int64, categoryID int64, templateData, visibility string, saveAsDraft bool) (*model.Content, error) {
 
 	// 开始OpenTelemetry span
 	ctx, span := telemetry.StartSpan(ctx, "content.service.CreateContent")
@@ -53,6 +103,8 @@ func (s *Service) CreateContent(ctx context.Context, authorID int64,
 		attribute.Int("content.media_files_count", len(mediaFiles)),
 		attribute.Int("content.tag_ids_count", len(tagIDs)),
 		attribute.Int("content.topic_ids_count", len(topicIDs)),
+		attribute.Int64("content.category_id", categoryID),
+		attribute.String("content.visibility", visibility),
 	)
 
 	// 将业务信息添加到context
@@ -70,6 +122,36 @@ func (s *Service) CreateContent(ctx context.Context, authorID int64,
 		span.SetStatus(codes.Error, "invalid content type")
 		return nil, fmt.Errorf("内容类型无效")
 	}
+	summary, err := s.normalizeSummary(summary)
+	if err != nil {
+		span.SetStatus(codes.Error, "invalid summary")
+		return nil, err
+	}
+	if visibility == "" {
+		visibility = model.VisibilityPublic
+	}
+	if !model.ValidateVisibility(visibility) {
+		span.SetStatus(codes.Error, "invalid visibility")
+		return nil, fmt.Errorf("可见范围无效")
+	}
+	categoryID, err = s.resolveCategoryID(ctx, categoryID)
+	if err != nil {
+		span.SetStatus(codes.Error, "invalid category")
+		return nil, err
+	}
+	if err := s.checkPostingBan(ctx, authorID, model.BanScopeContent); err != nil {
+		span.SetStatus(codes.Error, "author is banned from posting")
+		return nil, err
+	}
+	if _, err := s.screenText(ctx, fmt.Sprintf("content:author:%d", authorID), title, content, summary, templateData); err != nil {
+		span.SetStatus(codes.Error, "content contains prohibited words")
+		return nil, err
+	}
+	// 最后检查新账号发布频率，被其他校验拒绝的发布不消耗额度
+	if err := s.checkNewAccountCooldown(ctx, authorID, model.BanScopeContent); err != nil {
+		span.SetStatus(codes.Error, "new account is cooling down")
+		return nil, err
+	}
 
 	// 确定初始状态
 	status := model.ContentStatusPending
@@ -82,12 +164,16 @@ func (s *Service) CreateContent(ctx context.Context, authorID int64,
 		AuthorID:     authorID,
 		Title:        title,
 		Content:      content,
+		Summary:      summary,
 		Type:         contentType,
 		Status:       status,
 		TemplateData: templateData,
+		CategoryID:   categoryID,
+		Visibility:   visibility,
 		CreatedAt:    time.Now(),
 		UpdatedAt:    time.Now(),
 	}
+	s.applyExcerpt(newContent)
 
 	// 如果直接发布，设置发布时间
 	if status == model.ContentStatusPublished {
@@ -151,6 +237,18 @@ func (s *Service) CreateContent(ctx context.Context, authorID int64,
 			logger.F("error", err.Error()))
 	}
 
+	// 解析@提及，内容发布时再通知被提及用户
+	mentions := s.syncMentions(ctx, newContent.ID, model.TargetTypeContent, authorID, contentMentionText(newContent))
+
+	// 直接提交审核的内容交给审核器处理
+	if status == model.ContentStatusPending && s.moderator != nil {
+		if err := s.submitForModeration(ctx, newContent, authorID); err != nil {
+			s.logger.Error(ctx, "Failed to submit content for moderation",
+				logger.F("contentID", newContent.ID),
+				logger.F("error", err.Error()))
+		}
+	}
+
 	// 获取完整的内容信息
 	fullContent, err := s.dao.GetContentWithRelations(ctx, newContent.ID)
 	if err != nil {
@@ -158,8 +256,10 @@ func (s *Service) CreateContent(ctx context.Context, authorID int64,
 			logger.F("contentID", newContent.ID),
 			logger.F("error", err.Error()))
 		span.SetStatus(codes.Ok, "content created but failed to get full content")
+		newContent.Mentions = mentions
 		return newContent, nil
 	}
+	fullContent.Mentions = mentions
 
 	s.logger.Info(ctx, "Content created successfully",
 		logger.F("contentID", newContent.ID),
@@ -172,8 +272,8 @@ func (s *Service) CreateContent(ctx context.Context, authorID int64,
 }
 
 // UpdateContent 更新内容
-func (s *Service) UpdateContent(ctx context.Context, contentID, authorID int64, title, content, contentType string,
-	mediaFiles []model.ContentMediaFile, tagIDs, topicIDs []int64, templateData string) (*model.Content, error) {
+func (s *Service) UpdateContent(ctx context.Context, contentID, authorID int64, title, content, summary, contentType string,
+	mediaFiles []model.ContentMediaFile, tagIDs, topicIDs []int64, categoryID int64, templateData, visibility string) (*model.Content, error) {
 
 	// 获取现有内容
 	existingContent, err := s.dao.GetContent(ctx, contentID)
@@ -198,13 +298,38 @@ func (s *Service) UpdateContent(ctx context.Context, contentID, authorID int64,
 	if !model.ValidateContentType(contentType) {
 		return nil, fmt.Errorf("内容类型无效")
 	}
+	if visibility != "" && !model.ValidateVisibility(visibility) {
+		return nil, fmt.Errorf("可见范围无效")
+	}
+	summary, err = s.normalizeSummary(summary)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.screenText(ctx, fmt.Sprintf("content:%d", contentID), title, content, summary, templateData); err != nil {
+		return nil, err
+	}
+
+	// 未指定分类时保留原分类
+	if categoryID <= 0 {
+		categoryID = existingContent.CategoryID
+	}
+	categoryID, err = s.resolveCategoryID(ctx, categoryID)
+	if err != nil {
+		return nil, err
+	}
 
 	// 更新内容字段
 	existingContent.Title = title
 	existingContent.Content = content
+	existingContent.Summary = summary
 	existingContent.Type = contentType
 	existingContent.TemplateData = templateData
+	existingContent.CategoryID = categoryID
+	if visibility != "" {
+		existingContent.Visibility = visibility
+	}
 	existingContent.UpdatedAt = time.Now()
+	s.applyExcerpt(existingContent)
 
 	// 如果是被拒绝的内容，修改后重新设为草稿状态
 	if existingContent.Status == model.ContentStatusRejected {
@@ -261,14 +386,19 @@ func (s *Service) UpdateContent(ctx context.Context, contentID, authorID int64,
 		}
 	}
 
+	// 重新解析@提及，已通知过的用户不会重复通知
+	mentions := s.syncMentions(ctx, contentID, model.TargetTypeContent, authorID, contentMentionText(existingContent))
+
 	// 获取完整的内容信息
 	fullContent, err := s.dao.GetContentWithRelations(ctx, contentID)
 	if err != nil {
 		s.logger.Error(ctx, "Failed to get full content after update",
 			logger.F("contentID", contentID),
 			logger.F("error", err.Error()))
+		existingContent.Mentions = mentions
 		return existingContent, nil
 	}
+	fullContent.Mentions = mentions
 
 	return fullContent, nil
 }
@@ -308,6 +438,12 @@ func (s *Service) GetContent(ctx context.Context, contentID, userID int64) (*mod
 		attribute.Int64("content.author_id", content.AuthorID),
 	)
 
+	// 拉黑双方互相看不到对方的内容，按不存在处理
+	if s.hiddenAuthors(ctx, userID).Has(content.AuthorID) {
+		span.SetStatus(codes.Error, "author blocked")
+		return nil, fmt.Errorf("内容不存在")
+	}
+
 	// 权限检查：只有作者可以查看草稿和被拒绝的内容
 	if (content.Status == model.ContentStatusDraft || content.Status == model.ContentStatusRejected) &&
 		content.AuthorID != userID {
@@ -321,14 +457,17 @@ func (s *Service) GetContent(ctx context.Context, contentID, userID int64) (*mod
 		return nil, fmt.Errorf("内容不可访问")
 	}
 
-	// 增加浏览次数（异步执行，不影响主流程）
-	go func() {
-		if err := s.dao.IncrementViewCount(context.Background(), contentID); err != nil {
-			s.logger.Error(context.Background(), "Failed to increment view count",
-				logger.F("contentID", contentID),
-				logger.F("error", err.Error()))
-		}
-	}()
+	// 好友可见的内容只有作者和作者的好友可以查看
+	if !s.canViewContent(ctx, userID, content) {
+		span.SetStatus(codes.Error, "content visible to friends only")
+		return nil, fmt.Errorf("内容不可访问")
+	}
+
+	// 记录浏览（去重后累加到待写入增量，不在读取路径上写库），返回含待写入增量的近似浏览数
+	s.recordView(ctx, contentID, content.AuthorID, userID)
+	s.applyPendingViews(ctx, content)
+	s.attachContentMentions(ctx, content)
+	s.attachProfilePin(ctx, content)
 
 	s.logger.Info(ctx, "Content retrieved successfully",
 		logger.F("contentID", contentID),
@@ -341,8 +480,8 @@ func (s *Service) GetContent(ctx context.Context, contentID, userID int64) (*mod
 
 // DeleteContent 删除内容
 func (s *Service) DeleteContent(ctx context.Context, contentID, authorID int64) error {
-	// 获取内容
-	content, err := s.dao.GetContent(ctx, contentID)
+	// 获取内容，删除事件需要携带删除前的标签和话题
+	content, err := s.dao.GetContentWithRelations(ctx, contentID)
 	if err != nil {
 		return fmt.Errorf("内容不存在: %v", err)
 	}
@@ -367,8 +506,14 @@ func (s *Service) DeleteContent(ctx context.Context, contentID, authorID int64)
 			logger.F("error", err.Error()))
 	}
 
-	// 删除内容（级联删除关联数据）
-	return s.dao.DeleteContent(ctx, contentID)
+	// 删除内容（级联删除关联数据），评论、互动统计、热度和搜索索引由删除事件清理
+	deleted := newContentDeletedEvent(content, authorID, true)
+	if err := s.dao.DeleteContent(ctx, contentID, contentDeletedOutbox(deleted)); err != nil {
+		return err
+	}
+
+	s.onContentDeleted(ctx, deleted)
+	return nil
 }
 
 // PublishContent 发布内容
@@ -412,6 +557,51 @@ func (s *Service) PublishContent(ctx context.Context, contentID, authorID int64)
 		return nil, fmt.Errorf("当前状态不允许发布")
 	}
 
+	// 违禁词表可能在内容保存后更新，发布前再检查一次；命中hold级别时保持待审核，不交给审核器
+	filterResult, err := s.screenText(ctx, fmt.Sprintf("content:%d", contentID), content.Title, content.Content, content.Summary, content.TemplateData)
+	if err != nil {
+		span.SetStatus(codes.Error, "content contains prohibited words")
+		return nil, err
+	}
+	if filterResult.Severity == profanity.SeverityHold {
+		if err := s.holdContent(ctx, content, authorID, filterResult); err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, "failed to hold content")
+			return nil, fmt.Errorf("提交审核失败: %v", err)
+		}
+		if fullContent, err := s.dao.GetContentWithRelations(ctx, contentID); err == nil {
+			content = fullContent
+		}
+		span.SetStatus(codes.Ok, "content held for review")
+		return content, nil
+	}
+
+	// 启用审核时先进入待审核状态，由审核结论决定是否发布
+	if s.moderator != nil {
+		if err := s.submitForModeration(ctx, content, authorID); err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, "failed to submit for moderation")
+			return nil, fmt.Errorf("提交审核失败: %v", err)
+		}
+
+		fullContent, err := s.dao.GetContentWithRelations(ctx, contentID)
+		if err != nil {
+			s.logger.Error(ctx, "Failed to get full content after moderation",
+				logger.F("contentID", contentID),
+				logger.F("error", err.Error()))
+			span.SetStatus(codes.Ok, "content submitted but failed to get full content")
+			return content, nil
+		}
+
+		s.logger.Info(ctx, "Content submitted for moderation",
+			logger.F("contentID", contentID),
+			logger.F("authorID", authorID),
+			logger.F("status", fullContent.Status))
+
+		span.SetStatus(codes.Ok, "content submitted for moderation")
+		return fullContent, nil
+	}
+
 	// 更新状态和发布时间
 	oldStatus := content.Status
 	content.Status = model.ContentStatusPublished
@@ -449,6 +639,9 @@ func (s *Service) PublishContent(ctx context.Context, contentID, authorID int64)
 		span.SetStatus(codes.Ok, "content published but failed to get full content")
 		return content, nil
 	}
+	s.recordTrendingUsage(ctx, fullContent)
+	s.notifyMentions(ctx, contentID, model.TargetTypeContent, contentID)
+	s.attachContentMentions(ctx, fullContent)
 
 	s.logger.Info(ctx, "Content published successfully",
 		logger.F("contentID", contentID),
@@ -482,13 +675,25 @@ func (s *Service) ChangeContentStatus(ctx context.Context, contentID, operatorID
 	content.Status = newStatus
 	content.UpdatedAt = time.Now()
 
-	// 如果是发布状态，设置发布时间
-	if newStatus == model.ContentStatusPublished && content.PublishedAt == nil {
+	// 如果是首次发布，设置发布时间
+	firstPublish := newStatus == model.ContentStatusPublished && content.PublishedAt == nil
+	if firstPublish {
 		now := time.Now()
 		content.PublishedAt = &now
 	}
 
-	if err := s.dao.UpdateContent(ctx, content); err != nil {
+	// 删除事件与状态变更在同一事务中写入发件箱，需携带删除前的标签和话题
+	var deleted *model.ContentDeletedEvent
+	if newStatus == model.ContentStatusDeleted {
+		// 关联查询失败时事件不带标签和话题，热度交由衰减淘汰
+		withRelations := content
+		if full, err := s.dao.GetContentWithRelations(ctx, contentID); err == nil {
+			withRelations = full
+		}
+		deleted = newContentDeletedEvent(withRelations, operatorID, false)
+	}
+
+	if err := s.dao.UpdateContent(ctx, content, contentDeletedOutbox(deleted)); err != nil {
 		return nil, fmt.Errorf("更新内容状态失败: %v", err)
 	}
 
@@ -506,6 +711,18 @@ func (s *Service) ChangeContentStatus(ctx context.Context, contentID, operatorID
 			logger.F("contentID", contentID),
 			logger.F("error", err.Error()))
 	}
+	s.auditor.Record(audit.Entry{
+		ActorID:    operatorID,
+		Action:     audit.ActionContentStatusChange,
+		TargetType: model.TargetTypeContent,
+		TargetID:   contentID,
+		Reason:     reason,
+		Detail:     map[string]string{"from": oldStatus, "to": newStatus},
+	})
+
+	if deleted != nil {
+		s.onContentDeleted(ctx, deleted)
+	}
 
 	// 获取完整内容信息
 	fullContent, err := s.dao.GetContentWithRelations(ctx, contentID)
@@ -515,26 +732,13 @@ func (s *Service) ChangeContentStatus(ctx context.Context, contentID, operatorID
 			logger.F("error", err.Error()))
 		return content, nil
 	}
-
-	return fullContent, nil
-}
-
-// GetUserContent 获取用户内容列表
-func (s *Service) GetUserContent(ctx context.Context, authorID int64, status string, page, pageSize int32) ([]*model.Content, int64, error) {
-	if authorID <= 0 {
-		return nil, 0, fmt.Errorf("用户ID无效")
-	}
-	if page <= 0 {
-		page = 1
-	}
-	if pageSize <= 0 {
-		pageSize = model.DefaultPageSize
-	}
-	if pageSize > model.MaxPageSize {
-		pageSize = model.MaxPageSize
+	if firstPublish {
+		s.recordTrendingUsage(ctx, fullContent)
+		s.notifyMentions(ctx, contentID, model.TargetTypeContent, contentID)
 	}
+	s.attachContentMentions(ctx, fullContent)
 
-	return s.dao.GetUserContents(ctx, authorID, status, page, pageSize)
+	return fullContent, nil
 }
 
 // GetContentStats 获取内容统计
@@ -573,12 +777,7 @@ func (s *Service) GetTags(ctx context.Context, keyword string, page, pageSize in
 	if page <= 0 {
 		page = 1
 	}
-	if pageSize <= 0 {
-		pageSize = model.DefaultPageSize
-	}
-	if pageSize > model.MaxPageSize {
-		pageSize = model.MaxPageSize
-	}
+	pageSize = s.paging.PageSize32(pageSize)
 
 	return s.dao.GetTags(ctx, keyword, page, pageSize)
 }
@@ -617,12 +816,7 @@ func (s *Service) GetTopics(ctx context.Context, keyword string, hotOnly bool, p
 	if page <= 0 {
 		page = 1
 	}
-	if pageSize <= 0 {
-		pageSize = model.DefaultPageSize
-	}
-	if pageSize > model.MaxPageSize {
-		pageSize = model.MaxPageSize
-	}
+	pageSize = s.paging.PageSize32(pageSize)
 
 	return s.dao.GetTopics(ctx, keyword, hotOnly, page, pageSize)
 }
@@ -671,7 +865,7 @@ func (s *Service) updateCommentCountsOnDelete(ctx context.Context, comment *mode
 
 // updateInteractionStats 更新互动统计
 func (s *Service) updateInteractionStats(ctx context.Context, targetID int64, targetType, interactionType string, delta int64) {
-	// 更新互动统计表
+	// 更新互动统计表，成功后同步自增统计缓存
 	if err := s.dao.UpdateInteractionStats(ctx, targetID, targetType, interactionType, delta); err != nil {
 		s.logger.Error(ctx, "Failed to update interaction stats",
 			logger.F("targetID", targetID),
@@ -679,6 +873,8 @@ func (s *Service) updateInteractionStats(ctx context.Context, targetID int64, ta
 			logger.F("interactionType", interactionType),
 			logger.F("delta", delta),
 			logger.F("error", err.Error()))
+	} else {
+		s.incrInteractionStatsCache(ctx, targetID, targetType, interactionType, delta)
 	}
 
 	// 更新目标对象的计数
@@ -701,86 +897,80 @@ func (s *Service) updateInteractionStats(ctx context.Context, targetID int64, ta
 	}
 }
 
-// publishCommentEvent 发布评论事件
-func (s *Service) publishCommentEvent(ctx context.Context, eventType string, comment *model.Comment) {
-	if s.kafka == nil {
-		return
+// commentEvent 评论事件，与评论变更在同一事务中写入发件箱，评论ID在事务中生成后才构建
+func commentEvent(eventType string, comment *model.Comment) outbox.Builder {
+	return func() (*outbox.Event, error) {
+		return outbox.NewEvent("comment-events", "", &model.CommentEvent{
+			EventType:  eventType,
+			CommentID:  comment.ID,
+			TargetID:   comment.TargetID,
+			TargetType: comment.TargetType,
+			UserID:     comment.UserID,
+			Content:    comment.Content,
+			ParentID:   comment.ParentID,
+			Timestamp:  time.Now().Unix(),
+		})
 	}
+}
 
-	// 构建事件消息（简化版，实际应该使用protobuf）
-	eventData := fmt.Sprintf(`{
-		"event_type": "%s",
-		"comment_id": %d,
-		"target_id": %d,
-		"target_type": "%s",
-		"user_id": %d,
-		"content": "%s",
-		"parent_id": %d,
-		"timestamp": %d
-	}`, eventType, comment.ID, comment.TargetID, comment.TargetType,
-		comment.UserID, comment.Content, comment.ParentID, time.Now().Unix())
-
-	if err := s.kafka.SendMessage("comment-events", nil, []byte(eventData)); err != nil {
-		s.logger.Error(ctx, "Failed to publish comment event",
-			logger.F("eventType", eventType),
-			logger.F("commentID", comment.ID),
-			logger.F("error", err.Error()))
+// interactionEvent 互动事件，与互动变更在同一事务中写入发件箱，互动ID在事务中生成后才构建
+func interactionEvent(eventType string, interaction *model.Interaction) outbox.Builder {
+	return func() (*outbox.Event, error) {
+		return outbox.NewEvent("interaction-events", "", &model.InteractionEvent{
+			EventType:       eventType,
+			InteractionID:   interaction.ID,
+			UserID:          interaction.UserID,
+			TargetID:        interaction.TargetID,
+			TargetType:      interaction.TargetType,
+			InteractionType: interaction.InteractionType,
+			ReactionKey:     interaction.ReactionKey,
+			Metadata:        interaction.Metadata,
+			Timestamp:       time.Now().Unix(),
+		})
 	}
 }
 
-// publishInteractionEvent 发布互动事件
-func (s *Service) publishInteractionEvent(ctx context.Context, eventType string, interaction *model.Interaction) {
+// publishViewEvent 发布浏览增量事件，浏览数批量落库后发布，供搜索服务更新热度
+func (s *Service) publishViewEvent(ctx context.Context, contentID, delta int64) {
 	if s.kafka == nil {
 		return
 	}
 
-	// 构建事件消息（简化版，实际应该使用protobuf）
-	eventData := fmt.Sprintf(`{
-		"event_type": "%s",
-		"interaction_id": %d,
-		"user_id": %d,
-		"target_id": %d,
-		"target_type": "%s",
-		"interaction_type": "%s",
-		"metadata": "%s",
-		"timestamp": %d
-	}`, eventType, interaction.ID, interaction.UserID, interaction.TargetID,
-		interaction.TargetType, interaction.InteractionType, interaction.Metadata, time.Now().Unix())
-
-	if err := s.kafka.SendMessage("interaction-events", nil, []byte(eventData)); err != nil {
-		s.logger.Error(ctx, "Failed to publish interaction event",
-			logger.F("eventType", eventType),
-			logger.F("interactionID", interaction.ID),
-			logger.F("error", err.Error()))
-	}
-}
-
-// publishContentEvent 发布内容事件
-func (s *Service) publishContentEvent(ctx context.Context, eventType string, content *model.Content) {
-	if s.kafka == nil {
+	eventData, err := json.Marshal(&model.ViewEvent{
+		EventType:  "view",
+		TargetID:   contentID,
+		TargetType: model.TargetTypeContent,
+		Count:      delta,
+		Timestamp:  time.Now().Unix(),
+	})
+	if err != nil {
 		return
 	}
 
-	// 构建事件消息（简化版，实际应该使用protobuf）
-	eventData := fmt.Sprintf(`{
-		"event_type": "%s",
-		"content_id": %d,
-		"author_id": %d,
-		"title": "%s",
-		"type": "%s",
-		"status": "%s",
-		"timestamp": %d
-	}`, eventType, content.ID, content.AuthorID, content.Title,
-		content.Type, content.Status, time.Now().Unix())
-
-	if err := s.kafka.SendMessage("content-events", nil, []byte(eventData)); err != nil {
-		s.logger.Error(ctx, "Failed to publish content event",
-			logger.F("eventType", eventType),
-			logger.F("contentID", content.ID),
+	if err := s.kafka.SendMessage("content-view-events", nil, eventData); err != nil {
+		s.logger.Error(ctx, "Failed to publish view event",
+			logger.F("contentID", contentID),
+			logger.F("delta", delta),
 			logger.F("error", err.Error()))
 	}
 }
 
+// contentEvent 内容事件，与内容变更在同一事务中写入发件箱
+func contentEvent(eventType string, content *model.Content) outbox.Builder {
+	return func() (*outbox.Event, error) {
+		return outbox.NewEvent("content-events", "", &model.ContentEvent{
+			EventType:  eventType,
+			ContentID:  content.ID,
+			AuthorID:   content.AuthorID,
+			Title:      content.Title,
+			Type:       content.Type,
+			Status:     content.Status,
+			CategoryID: content.CategoryID,
+			Timestamp:  time.Now().Unix(),
+		})
+	}
+}
+
 // clearInteractionCache 清除互动相关缓存
 func (s *Service) clearInteractionCache(ctx context.Context, userID, targetID int64, targetType, interactionType string) {
 	if s.redis == nil {
@@ -794,14 +984,6 @@ func (s *Service) clearInteractionCache(ctx context.Context, userID, targetID in
 			logger.F("cacheKey", userCacheKey),
 			logger.F("error", err.Error()))
 	}
-
-	// 清除目标对象统计缓存
-	statsCacheKey := fmt.Sprintf("%s:%d:%s", model.CacheKeyInteractionStats, targetID, targetType)
-	if err := s.redis.Del(ctx, statsCacheKey); err != nil {
-		s.logger.Error(ctx, "Failed to clear interaction stats cache",
-			logger.F("cacheKey", statsCacheKey),
-			logger.F("error", err.Error()))
-	}
 }
 
 // clearContentCache 清除内容相关缓存
@@ -826,6 +1008,17 @@ func (s *Service) clearContentCache(ctx context.Context, contentID int64) {
 			logger.F("error", err.Error()))
 	}
 
+	// 清除内容互动统计缓存
+	interactionStatsKey := interactionStatsCacheKey(contentID, model.TargetTypeContent)
+	if err := s.redis.Del(ctx, interactionStatsKey); err != nil {
+		s.logger.Error(ctx, "Failed to clear interaction stats cache",
+			logger.F("cacheKey", interactionStatsKey),
+			logger.F("error", err.Error()))
+	}
+
+	// 清除内容详情热门评论缓存
+	s.clearTopCommentsCache(ctx, contentID)
+
 	// 清除热门内容缓存
 	hotCacheKey := model.CacheKeyHotContent
 	if err := s.redis.Del(ctx, hotCacheKey); err != nil {
@@ -0,0 +1,207 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+
+	"goim-social/api/rest"
+	"goim-social/apps/content-service/internal/model"
+	tracecontext "goim-social/pkg/context"
+	"goim-social/pkg/logger"
+	"goim-social/pkg/telemetry"
+)
+
+// ==================== 分享目的地相关业务逻辑 ====================
+
+// GetShareTargets 获取内容的分享目的地统计，按分享次数倒序，仅内容作者可查询
+// 未指定目的地的普通分享不计入，返回的总数为指定了目的地的分享次数
+// 只统计最近model.MaxShareTargetScan次指定了目的地的分享，避免分享量大的内容一次读出全部记录
+func (s *Service) GetShareTargets(ctx context.Context, contentID, userID int64) ([]*model.ShareTargetStat, int64, error) {
+	// 开始OpenTelemetry span
+	ctx, span := telemetry.StartSpan(ctx, "content.service.GetShareTargets")
+	defer span.End()
+
+	// 设置span属性
+	span.SetAttributes(
+		attribute.Int64("content.id", contentID),
+		attribute.Int64("user.id", userID),
+	)
+
+	// 将业务信息添加到context
+	ctx = tracecontext.WithUserID(ctx, userID)
+	ctx = tracecontext.WithContentID(ctx, contentID)
+
+	if contentID <= 0 {
+		span.SetStatus(codes.Error, "invalid content ID")
+		return nil, 0, fmt.Errorf("内容ID无效")
+	}
+
+	content, err := s.dao.GetContent(ctx, contentID)
+	if err != nil {
+		span.SetStatus(codes.Error, "content not found")
+		return nil, 0, fmt.Errorf("内容不存在")
+	}
+	if content.AuthorID != userID {
+		span.SetStatus(codes.Error, "permission denied")
+		return nil, 0, fmt.Errorf("只有内容作者可以查看分享目的地")
+	}
+
+	metadataList, err := s.dao.ListShareTargetMetadata(ctx, contentID, model.TargetTypeContent, model.MaxShareTargetScan)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to list share targets")
+		return nil, 0, fmt.Errorf("获取分享目的地失败: %v", err)
+	}
+
+	counts := make(map[model.ShareTarget]int64)
+	var total int64
+	for _, metadata := range metadataList {
+		var fields struct {
+			ShareTarget *model.ShareTarget `json:"share_target"`
+		}
+		if err := json.Unmarshal([]byte(metadata), &fields); err != nil || fields.ShareTarget == nil {
+			continue
+		}
+		counts[*fields.ShareTarget]++
+		total++
+	}
+
+	stats := make([]*model.ShareTargetStat, 0, len(counts))
+	for target, count := range counts {
+		stats = append(stats, &model.ShareTargetStat{Target: target, Count: count})
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].Count != stats[j].Count {
+			return stats[i].Count > stats[j].Count
+		}
+		if stats[i].Target.Type != stats[j].Target.Type {
+			return stats[i].Target.Type < stats[j].Target.Type
+		}
+		if stats[i].Target.ID != stats[j].Target.ID {
+			return stats[i].Target.ID < stats[j].Target.ID
+		}
+		return stats[i].Target.Channel < stats[j].Target.Channel
+	})
+
+	span.SetAttributes(
+		attribute.Int("share.target_count", len(stats)),
+		attribute.Int64("share.total", total),
+	)
+	span.SetStatus(codes.Ok, "share targets retrieved successfully")
+	return stats, total, nil
+}
+
+// validateShareTarget 验证分享目的地，并规范化站外渠道标识
+func validateShareTarget(interactionType string, target *model.ShareTarget) error {
+	if interactionType != model.InteractionTypeShare {
+		return fmt.Errorf("只有分享可以指定分享目的地")
+	}
+
+	switch target.Type {
+	case model.ShareTargetConversation, model.ShareTargetGroup:
+		if target.ID <= 0 {
+			return fmt.Errorf("分享目的地ID无效")
+		}
+		target.Channel = ""
+	case model.ShareTargetExternal:
+		target.ID = 0
+		target.Channel = strings.ToLower(strings.TrimSpace(target.Channel))
+		if target.Channel == "" {
+			return fmt.Errorf("站外分享渠道不能为空")
+		}
+		if utf8.RuneCountInString(target.Channel) > model.MaxShareChannelLength {
+			return fmt.Errorf("站外分享渠道不能超过%d个字符", model.MaxShareChannelLength)
+		}
+	default:
+		return fmt.Errorf("不支持的分享目的地类型: %s", target.Type)
+	}
+	return nil
+}
+
+// shareMetadata 将分享目的地写入互动元数据，保留调用方传入的其他字段
+func shareMetadata(metadata string, target *model.ShareTarget) (string, error) {
+	fields := make(map[string]interface{})
+	if strings.TrimSpace(metadata) != "" {
+		if err := json.Unmarshal([]byte(metadata), &fields); err != nil {
+			return "", fmt.Errorf("互动元数据必须是JSON对象: %v", err)
+		}
+	}
+	fields["share_target"] = target
+
+	data, err := json.Marshal(fields)
+	if err != nil {
+		return "", fmt.Errorf("序列化互动元数据失败: %v", err)
+	}
+	return string(data), nil
+}
+
+// sendShareMessage 以分享者身份在站内目的地会话发送内容链接消息，好友和群成员校验由logic-service完成
+// 站外分享和未配置逻辑服务时不发送，失败只记录日志
+func (s *Service) sendShareMessage(ctx context.Context, userID, contentID int64, target *model.ShareTarget) {
+	if s.logic == nil || target.Type == model.ShareTargetExternal {
+		return
+	}
+
+	content, err := s.dao.GetContent(ctx, contentID)
+	if err != nil {
+		s.logger.Warn(ctx, "Failed to get content for share message",
+			logger.F("contentID", contentID),
+			logger.F("error", err.Error()))
+		return
+	}
+
+	card, err := json.Marshal(&model.ShareCard{
+		ContentID: content.ID,
+		Title:     content.Title,
+		AuthorID:  content.AuthorID,
+		Type:      content.Type,
+	})
+	if err != nil {
+		s.logger.Warn(ctx, "Failed to marshal share card",
+			logger.F("contentID", contentID),
+			logger.F("error", err.Error()))
+		return
+	}
+
+	msg := &rest.WSMessage{
+		From:        userID,
+		Content:     string(card),
+		Timestamp:   time.Now().Unix(),
+		MessageType: model.MessageTypeContentShare,
+	}
+	if target.Type == model.ShareTargetGroup {
+		msg.GroupId = target.ID
+	} else {
+		msg.To = target.ID
+	}
+
+	resp, err := s.logic.SendMessage(ctx, &rest.SendLogicMessageRequest{Msg: msg})
+	if err != nil || !resp.Success {
+		reason := ""
+		if err != nil {
+			reason = err.Error()
+		} else if resp != nil {
+			reason = resp.Message
+		}
+		s.logger.Warn(ctx, "Failed to send share message",
+			logger.F("contentID", contentID),
+			logger.F("targetType", target.Type),
+			logger.F("targetID", target.ID),
+			logger.F("error", reason))
+		return
+	}
+
+	s.logger.Info(ctx, "Share message sent",
+		logger.F("contentID", contentID),
+		logger.F("targetType", target.Type),
+		logger.F("targetID", target.ID),
+		logger.F("messageID", resp.MessageId))
+}
@@ -0,0 +1,117 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"gorm.io/gorm"
+
+	"goim-social/apps/content-service/internal/dao"
+	"goim-social/apps/content-service/internal/model"
+)
+
+// shareDAO 返回固定分享元数据的内容存储，并记录读取分享元数据时的条数上限
+type shareDAO struct {
+	dao.ContentDAO
+	content  *model.Content
+	metadata []string
+	limit    int
+}
+
+func (d *shareDAO) GetContent(ctx context.Context, contentID int64) (*model.Content, error) {
+	if d.content == nil || d.content.ID != contentID {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return d.content, nil
+}
+
+func (d *shareDAO) ListShareTargetMetadata(ctx context.Context, targetID int64, targetType string, limit int) ([]string, error) {
+	d.limit = limit
+	return d.metadata, nil
+}
+
+// TestGetShareTargets 按目的地聚合并按次数倒序，无法解析或未指定目的地的元数据不计入，读取条数受上限约束
+func TestGetShareTargets(t *testing.T) {
+	group := `{"share_target":{"type":"group","id":9}}`
+	wechat := `{"share_target":{"type":"external","channel":"wechat"},"note":"x"}`
+	store := &shareDAO{
+		content:  &model.Content{ID: 10, AuthorID: 1},
+		metadata: []string{wechat, group, group, "not json", `{"share_target":null}`, wechat, group},
+	}
+	s := &Service{dao: store}
+
+	stats, total, err := s.GetShareTargets(context.Background(), 10, 1)
+	if err != nil {
+		t.Fatalf("GetShareTargets returned error: %v", err)
+	}
+	want := []*model.ShareTargetStat{
+		{Target: model.ShareTarget{Type: model.ShareTargetGroup, ID: 9}, Count: 3},
+		{Target: model.ShareTarget{Type: model.ShareTargetExternal, Channel: "wechat"}, Count: 2},
+	}
+	if !reflect.DeepEqual(stats, want) || total != 5 {
+		t.Errorf("GetShareTargets() = %+v (total %d), want %+v (total 5)", stats, total, want)
+	}
+	if store.limit != model.MaxShareTargetScan {
+		t.Errorf("ListShareTargetMetadata limit = %d, want %d", store.limit, model.MaxShareTargetScan)
+	}
+
+	if _, _, err := s.GetShareTargets(context.Background(), 10, 2); err == nil {
+		t.Error("GetShareTargets by non-author succeeded, want error")
+	}
+	if _, _, err := s.GetShareTargets(context.Background(), 11, 1); err == nil {
+		t.Error("GetShareTargets of missing content succeeded, want error")
+	}
+}
+
+// TestValidateShareTarget 站内目的地需要ID，站外渠道规范化为小写并限制长度，只有分享可以指定目的地
+func TestValidateShareTarget(t *testing.T) {
+	target := &model.ShareTarget{Type: model.ShareTargetExternal, ID: 5, Channel: "  WeChat "}
+	if err := validateShareTarget(model.InteractionTypeShare, target); err != nil {
+		t.Fatalf("validateShareTarget(external) returned error: %v", err)
+	}
+	if want := (model.ShareTarget{Type: model.ShareTargetExternal, Channel: "wechat"}); *target != want {
+		t.Errorf("normalized target = %+v, want %+v", *target, want)
+	}
+
+	invalid := map[string]struct {
+		interactionType string
+		target          model.ShareTarget
+	}{
+		"not share":        {model.InteractionTypeLike, model.ShareTarget{Type: model.ShareTargetGroup, ID: 1}},
+		"group without id": {model.InteractionTypeShare, model.ShareTarget{Type: model.ShareTargetGroup}},
+		"empty channel":    {model.InteractionTypeShare, model.ShareTarget{Type: model.ShareTargetExternal, Channel: " "}},
+		"long channel":     {model.InteractionTypeShare, model.ShareTarget{Type: model.ShareTargetExternal, Channel: "abcdefghijklmnopqrstuvwxyz0123456"}},
+		"unknown type":     {model.InteractionTypeShare, model.ShareTarget{Type: "email", ID: 1}},
+	}
+	for name, tc := range invalid {
+		target := tc.target
+		if err := validateShareTarget(tc.interactionType, &target); err == nil {
+			t.Errorf("validateShareTarget(%s) succeeded, want error", name)
+		}
+	}
+}
+
+// TestShareMetadata 写入分享目的地并保留原有字段，非JSON对象的元数据被拒绝
+func TestShareMetadata(t *testing.T) {
+	target := &model.ShareTarget{Type: model.ShareTargetConversation, ID: 2}
+	got, err := shareMetadata(`{"note":"hi"}`, target)
+	if err != nil {
+		t.Fatalf("shareMetadata returned error: %v", err)
+	}
+	var fields struct {
+		Note        string             `json:"note"`
+		ShareTarget *model.ShareTarget `json:"share_target"`
+	}
+	if err := json.Unmarshal([]byte(got), &fields); err != nil {
+		t.Fatalf("shareMetadata() = %q, not valid JSON: %v", got, err)
+	}
+	if fields.Note != "hi" || fields.ShareTarget == nil || *fields.ShareTarget != *target {
+		t.Errorf("shareMetadata() = %q, want note kept and share_target set", got)
+	}
+
+	if _, err := shareMetadata("[1]", target); err == nil {
+		t.Error("shareMetadata(array) succeeded, want error")
+	}
+}
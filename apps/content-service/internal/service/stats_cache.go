@@ -0,0 +1,286 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	goredis "github.com/go-redis/redis/v8"
+
+	"goim-social/apps/content-service/internal/model"
+	"goim-social/pkg/logger"
+)
+
+// ==================== 互动统计缓存 ====================
+// 数据库为统计数据的唯一可信来源，Redis哈希作为写穿缓存：
+// 互动发生时数据库与缓存各自原子自增，读取时缓存未命中再从数据库加载回填，
+// 两者之间可能出现的偏差由定期对账任务以数据库为准纠正
+
+// incrStatsScript 仅在缓存存在时自增，避免缓存未命中时写入不完整的统计
+var incrStatsScript = goredis.NewScript(`
+if redis.call('EXISTS', KEYS[1]) == 1 then
+	redis.call('HINCRBY', KEYS[1], ARGV[1], ARGV[2])
+	return 1
+end
+return 0
+`)
+
+// interactionStatsCacheKey 互动统计缓存键
+func interactionStatsCacheKey(targetID int64, targetType string) string {
+	return fmt.Sprintf("%s:%d:%s", model.CacheKeyInteractionStats, targetID, targetType)
+}
+
+// interactionStatsField 互动类型对应的统计字段
+func interactionStatsField(interactionType string) string {
+	switch interactionType {
+	case model.InteractionTypeLike:
+		return "like_count"
+	case model.InteractionTypeFavorite:
+		return "favorite_count"
+	case model.InteractionTypeShare:
+		return "share_count"
+	case model.InteractionTypeRepost:
+		return "repost_count"
+	default:
+		return ""
+	}
+}
+
+// interactionStatsFields 将统计数据转换为缓存哈希字段
+func interactionStatsFields(stats *model.InteractionStats) map[string]interface{} {
+	return map[string]interface{}{
+		"like_count":     stats.LikeCount,
+		"favorite_count": stats.FavoriteCount,
+		"share_count":    stats.ShareCount,
+		"repost_count":   stats.RepostCount,
+	}
+}
+
+// parseInteractionStats 从缓存哈希字段解析统计数据
+func parseInteractionStats(targetID int64, targetType string, fields map[string]string) *model.InteractionStats {
+	parse := func(field string) int64 {
+		value, _ := strconv.ParseInt(fields[field], 10, 64)
+		return value
+	}
+	return &model.InteractionStats{
+		TargetID:      targetID,
+		TargetType:    targetType,
+		LikeCount:     parse("like_count"),
+		FavoriteCount: parse("favorite_count"),
+		ShareCount:    parse("share_count"),
+		RepostCount:   parse("repost_count"),
+	}
+}
+
+// sameInteractionCounts 比较两份统计的计数是否一致
+func sameInteractionCounts(a, b *model.InteractionStats) bool {
+	return a.LikeCount == b.LikeCount &&
+		a.FavoriteCount == b.FavoriteCount &&
+		a.ShareCount == b.ShareCount &&
+		a.RepostCount == b.RepostCount
+}
+
+// contentIDsOf 提取内容ID列表
+func contentIDsOf(contents []*model.Content) []int64 {
+	contentIDs := make([]int64, len(contents))
+	for i, content := range contents {
+		contentIDs[i] = content.ID
+	}
+	return contentIDs
+}
+
+// getInteractionStats 获取单个目标的互动统计，缓存未命中时从数据库加载并回填
+func (s *Service) getInteractionStats(ctx context.Context, targetID int64, targetType string) (*model.InteractionStats, error) {
+	statsMap, err := s.batchGetInteractionStats(ctx, []int64{targetID}, targetType)
+	if err != nil {
+		return nil, err
+	}
+	return statsMap[targetID], nil
+}
+
+// batchGetInteractionStats 批量获取互动统计，优先读缓存，未命中的部分从数据库加载并回填
+func (s *Service) batchGetInteractionStats(ctx context.Context, targetIDs []int64, targetType string) (map[int64]*model.InteractionStats, error) {
+	result := make(map[int64]*model.InteractionStats, len(targetIDs))
+	if len(targetIDs) == 0 {
+		return result, nil
+	}
+
+	missIDs := targetIDs
+	if s.redis != nil {
+		pipe := s.redis.GetClient().Pipeline()
+		cmds := make([]*goredis.StringStringMapCmd, len(targetIDs))
+		for i, targetID := range targetIDs {
+			cmds[i] = pipe.HGetAll(ctx, interactionStatsCacheKey(targetID, targetType))
+		}
+
+		if _, err := pipe.Exec(ctx); err != nil {
+			s.logger.Warn(ctx, "读取互动统计缓存失败，回退到数据库", logger.F("error", err.Error()))
+		} else {
+			missIDs = nil
+			for i, cmd := range cmds {
+				fields := cmd.Val()
+				if len(fields) == 0 {
+					missIDs = append(missIDs, targetIDs[i])
+					continue
+				}
+				result[targetIDs[i]] = parseInteractionStats(targetIDs[i], targetType, fields)
+			}
+		}
+	}
+
+	if len(missIDs) == 0 {
+		return result, nil
+	}
+
+	stats, err := s.dao.BatchGetInteractionStats(ctx, missIDs, targetType)
+	if err != nil {
+		return nil, err
+	}
+	for _, stat := range stats {
+		result[stat.TargetID] = stat
+		s.cacheInteractionStats(ctx, stat)
+	}
+	return result, nil
+}
+
+// cacheInteractionStats 回填互动统计缓存，并登记到对账集合
+func (s *Service) cacheInteractionStats(ctx context.Context, stats *model.InteractionStats) {
+	if s.redis == nil {
+		return
+	}
+
+	key := interactionStatsCacheKey(stats.TargetID, stats.TargetType)
+	pipe := s.redis.GetClient().TxPipeline()
+	pipe.HSet(ctx, key, interactionStatsFields(stats))
+	pipe.Expire(ctx, key, time.Duration(model.CacheExpireInteractionStats)*time.Second)
+	pipe.SAdd(ctx, model.CacheKeyInteractionStatsTracked, fmt.Sprintf("%d:%s", stats.TargetID, stats.TargetType))
+	if _, err := pipe.Exec(ctx); err != nil {
+		s.logger.Warn(ctx, "回填互动统计缓存失败",
+			logger.F("targetID", stats.TargetID),
+			logger.F("targetType", stats.TargetType),
+			logger.F("error", err.Error()))
+	}
+}
+
+// incrInteractionStatsCache 在数据库更新成功后同步自增缓存，失败时删除缓存等待下次回填
+func (s *Service) incrInteractionStatsCache(ctx context.Context, targetID int64, targetType, interactionType string, delta int64) {
+	if s.redis == nil {
+		return
+	}
+
+	field := interactionStatsField(interactionType)
+	if field == "" {
+		return
+	}
+
+	key := interactionStatsCacheKey(targetID, targetType)
+	if err := incrStatsScript.Run(ctx, s.redis.GetClient(), []string{key}, field, delta).Err(); err != nil {
+		s.logger.Warn(ctx, "更新互动统计缓存失败",
+			logger.F("cacheKey", key),
+			logger.F("error", err.Error()))
+		s.redis.Del(ctx, key)
+	}
+}
+
+// StartStatsReconciler 启动互动统计对账任务，定期以数据库为准纠正缓存偏差
+func (s *Service) StartStatsReconciler(ctx context.Context) {
+	if s.redis == nil {
+		return
+	}
+
+	ticker := time.NewTicker(time.Duration(model.StatsReconcileInterval) * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.reconcileInteractionStats(ctx)
+		}
+	}
+}
+
+// reconcileInteractionStats 对账所有已缓存的互动统计，缓存已过期的目标移出对账集合
+func (s *Service) reconcileInteractionStats(ctx context.Context) {
+	members, err := s.redis.SMembers(ctx, model.CacheKeyInteractionStatsTracked)
+	if err != nil {
+		s.logger.Error(ctx, "获取互动统计对账集合失败", logger.F("error", err.Error()))
+		return
+	}
+
+	corrected := 0
+	for _, member := range members {
+		parts := strings.SplitN(member, ":", 2)
+		if len(parts) != 2 {
+			s.redis.SRem(ctx, model.CacheKeyInteractionStatsTracked, member)
+			continue
+		}
+		targetID, err := strconv.ParseInt(parts[0], 10, 64)
+		if err != nil {
+			s.redis.SRem(ctx, model.CacheKeyInteractionStatsTracked, member)
+			continue
+		}
+
+		cached, fixed, err := s.reconcileTargetStats(ctx, targetID, parts[1])
+		if err != nil {
+			s.logger.Warn(ctx, "互动统计对账失败",
+				logger.F("targetID", targetID),
+				logger.F("targetType", parts[1]),
+				logger.F("error", err.Error()))
+			continue
+		}
+		if !cached {
+			s.redis.SRem(ctx, model.CacheKeyInteractionStatsTracked, member)
+		}
+		if fixed {
+			corrected++
+		}
+	}
+
+	s.logger.Info(ctx, "互动统计对账完成",
+		logger.F("tracked", len(members)),
+		logger.F("corrected", corrected))
+}
+
+// reconcileTargetStats 对账单个目标的统计缓存，返回缓存是否存在以及是否做了纠正
+// 使用WATCH保证对账期间若有新的自增写入则放弃本次覆盖，留待下一轮
+func (s *Service) reconcileTargetStats(ctx context.Context, targetID int64, targetType string) (bool, bool, error) {
+	key := interactionStatsCacheKey(targetID, targetType)
+	cached := true
+	fixed := false
+
+	err := s.redis.GetClient().Watch(ctx, func(tx *goredis.Tx) error {
+		fields, err := tx.HGetAll(ctx, key).Result()
+		if err != nil {
+			return err
+		}
+		if len(fields) == 0 {
+			cached = false
+			return nil
+		}
+
+		stats, err := s.dao.GetInteractionStats(ctx, targetID, targetType)
+		if err != nil {
+			return err
+		}
+		if sameInteractionCounts(parseInteractionStats(targetID, targetType, fields), stats) {
+			return nil
+		}
+
+		_, err = tx.TxPipelined(ctx, func(pipe goredis.Pipeliner) error {
+			pipe.HSet(ctx, key, interactionStatsFields(stats))
+			return nil
+		})
+		if err == nil {
+			fixed = true
+		}
+		return err
+	}, key)
+	if err == goredis.TxFailedErr {
+		return true, false, nil
+	}
+	return cached, fixed, err
+}
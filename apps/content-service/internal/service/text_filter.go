@@ -0,0 +1,61 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"goim-social/apps/content-service/internal/model"
+	"goim-social/pkg/httpx"
+	"goim-social/pkg/logger"
+	"goim-social/pkg/profanity"
+)
+
+// ==================== 违禁词过滤相关业务逻辑 ====================
+// 内容和评论写入前经过共享的违禁词过滤器：reject直接拒绝，hold时内容保持待审核、评论隐藏等待人工复核，warn只记录
+
+// ErrProhibitedText 文本命中拒绝级别的违禁词
+var ErrProhibitedText = errors.New("包含违禁内容，请修改后重试")
+
+// screenText 检查文本是否包含违禁词，命中拒绝级别时返回错误
+func (s *Service) screenText(ctx context.Context, source string, texts ...string) (profanity.Result, error) {
+	result := s.profanity.Check(source, texts...)
+	if result.Severity == profanity.SeverityNone {
+		return result, nil
+	}
+
+	s.logger.Warn(ctx, "文本命中违禁词",
+		logger.F("source", source),
+		logger.F("severity", result.Severity.String()),
+		logger.F("words", result.Words()))
+	if result.Severity == profanity.SeverityReject {
+		return result, httpx.InvalidArgument(ErrProhibitedText)
+	}
+	return result, nil
+}
+
+// holdContent 命中hold级别的内容保持待审核，等待人工复核后发布
+func (s *Service) holdContent(ctx context.Context, content *model.Content, operatorID int64, result profanity.Result) error {
+	reason := "违禁词过滤拦截，等待人工复核: " + result.Words()
+	if content.Status != model.ContentStatusPending {
+		return s.moderateContentStatus(ctx, content, model.ContentStatusPending, operatorID, reason)
+	}
+	return s.dao.CreateStatusLog(ctx, &model.ContentStatusLog{
+		ContentID:  content.ID,
+		FromStatus: content.Status,
+		ToStatus:   content.Status,
+		OperatorID: model.SystemOperatorID,
+		Reason:     reason,
+		CreatedAt:  time.Now(),
+	})
+}
+
+// holdComment 记录因违禁词进入复核队列的评论，评论创建时已是待复核状态
+func (s *Service) holdComment(ctx context.Context, comment *model.Comment, result profanity.Result) {
+	if err := s.moderateCommentStatus(ctx, comment, model.CommentStatusHeld, model.SystemOperatorID,
+		model.CommentModerationActionAutoFilter, "违禁词过滤拦截，等待人工复核: "+result.Words(), 0); err != nil {
+		s.logger.Error(ctx, "Failed to record filtered comment",
+			logger.F("commentID", comment.ID),
+			logger.F("error", err.Error()))
+	}
+}
@@ -0,0 +1,212 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	goredis "github.com/go-redis/redis/v8"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+
+	"goim-social/apps/content-service/internal/model"
+	"goim-social/pkg/logger"
+	"goim-social/pkg/telemetry"
+)
+
+// ==================== 标签和话题热度 ====================
+// 新发布的内容每使用一个标签或话题，该标签/话题在Redis有序集合中的热度加1；
+// 热度按半衰期定期整体衰减，长期无人使用的成员逐渐跌出榜单并被移除。
+// 热度只反映近期使用速度，与数据库中的累计使用次数（UsageCount/ContentCount）相互独立
+
+// decayTrendingScript 按距上次衰减经过的时间整体衰减热度，多个实例同时执行时只有经过的时间被计入一次
+// KEYS: 标签热度, 话题热度, 上次衰减时间  ARGV: 当前时间（秒）, 半衰期（秒）, 最小保留分数
+var decayTrendingScript = goredis.NewScript(`
+local now = tonumber(ARGV[1])
+local last = tonumber(redis.call('GET', KEYS[3]))
+if not last then
+	redis.call('SET', KEYS[3], now)
+	return 0
+end
+local elapsed = now - last
+if elapsed <= 0 then
+	return 0
+end
+local factor = math.pow(0.5, elapsed / tonumber(ARGV[2]))
+for i = 1, 2 do
+	if redis.call('EXISTS', KEYS[i]) == 1 then
+		redis.call('ZUNIONSTORE', KEYS[i], 1, KEYS[i], 'WEIGHTS', factor)
+		redis.call('ZREMRANGEBYSCORE', KEYS[i], '-inf', '(' .. ARGV[3])
+	end
+end
+redis.call('SET', KEYS[3], now)
+return 1
+`)
+
+// recordTrendingUsage 内容首次发布时累加其标签和话题的热度
+func (s *Service) recordTrendingUsage(ctx context.Context, content *model.Content) {
+	if s.redis == nil || content == nil || (len(content.Tags) == 0 && len(content.Topics) == 0) {
+		return
+	}
+
+	pipe := s.redis.GetClient().Pipeline()
+	for _, tag := range content.Tags {
+		pipe.ZIncrBy(ctx, model.CacheKeyTrendingTags, 1, strconv.FormatInt(tag.ID, 10))
+	}
+	for _, topic := range content.Topics {
+		pipe.ZIncrBy(ctx, model.CacheKeyTrendingTopics, 1, strconv.FormatInt(topic.ID, 10))
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		s.logger.Warn(ctx, "记录标签话题热度失败",
+			logger.F("contentID", content.ID),
+			logger.F("error", err.Error()))
+	}
+}
+
+// StartTrendingDecayer 启动标签和话题热度衰减任务
+func (s *Service) StartTrendingDecayer(ctx context.Context) {
+	if s.redis == nil {
+		return
+	}
+
+	ticker := time.NewTicker(time.Duration(model.TrendingDecayInterval) * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.decayTrending(ctx)
+		}
+	}
+}
+
+// decayTrending 执行一轮热度衰减
+func (s *Service) decayTrending(ctx context.Context) {
+	halfLife := s.config.Trending.HalfLifeHours * 3600
+	if halfLife <= 0 {
+		return
+	}
+
+	keys := []string{model.CacheKeyTrendingTags, model.CacheKeyTrendingTopics, model.CacheKeyTrendingDecayedAt}
+	if err := decayTrendingScript.Run(ctx, s.redis.GetClient(), keys, time.Now().Unix(), halfLife, model.TrendingMinScore).Err(); err != nil {
+		s.logger.Error(ctx, "标签话题热度衰减失败", logger.F("error", err.Error()))
+	}
+}
+
+// trendingMembers 读取热度最高的成员ID及分数
+func (s *Service) trendingMembers(ctx context.Context, key string, limit int32) ([]int64, map[int64]float64, error) {
+	members, err := s.redis.GetClient().ZRevRangeWithScores(ctx, key, 0, int64(limit)-1).Result()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ids := make([]int64, 0, len(members))
+	scores := make(map[int64]float64, len(members))
+	for _, member := range members {
+		id, err := strconv.ParseInt(fmt.Sprint(member.Member), 10, 64)
+		if err != nil {
+			continue
+		}
+		ids = append(ids, id)
+		scores[id] = member.Score
+	}
+	return ids, scores, nil
+}
+
+// normalizeTrendingLimit 规范化热度榜单返回数量
+func normalizeTrendingLimit(limit int32) int32 {
+	if limit <= 0 {
+		return model.DefaultTrendingLimit
+	}
+	if limit > model.MaxTrendingLimit {
+		return model.MaxTrendingLimit
+	}
+	return limit
+}
+
+// GetTrendingTags 获取近期热门标签，按热度降序
+func (s *Service) GetTrendingTags(ctx context.Context, limit int32) ([]*model.TrendingTag, error) {
+	ctx, span := telemetry.StartSpan(ctx, "content.service.GetTrendingTags")
+	defer span.End()
+
+	limit = normalizeTrendingLimit(limit)
+	span.SetAttributes(attribute.Int("trending.limit", int(limit)))
+
+	if s.redis == nil {
+		return []*model.TrendingTag{}, nil
+	}
+
+	ids, scores, err := s.trendingMembers(ctx, model.CacheKeyTrendingTags, limit)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to read trending tags")
+		return nil, fmt.Errorf("获取热门标签失败: %v", err)
+	}
+
+	tags, err := s.dao.GetTagsByIDs(ctx, ids)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to load tags")
+		return nil, fmt.Errorf("获取热门标签失败: %v", err)
+	}
+	tagMap := make(map[int64]*model.ContentTag, len(tags))
+	for _, tag := range tags {
+		tagMap[tag.ID] = tag
+	}
+
+	// 按热度顺序输出，已删除的标签跳过
+	result := make([]*model.TrendingTag, 0, len(ids))
+	for _, id := range ids {
+		if tag, ok := tagMap[id]; ok {
+			result = append(result, &model.TrendingTag{Tag: tag, Score: scores[id]})
+		}
+	}
+
+	span.SetStatus(codes.Ok, "trending tags retrieved")
+	return result, nil
+}
+
+// GetTrendingTopics 获取近期热门话题，按热度降序
+func (s *Service) GetTrendingTopics(ctx context.Context, limit int32) ([]*model.TrendingTopic, error) {
+	ctx, span := telemetry.StartSpan(ctx, "content.service.GetTrendingTopics")
+	defer span.End()
+
+	limit = normalizeTrendingLimit(limit)
+	span.SetAttributes(attribute.Int("trending.limit", int(limit)))
+
+	if s.redis == nil {
+		return []*model.TrendingTopic{}, nil
+	}
+
+	ids, scores, err := s.trendingMembers(ctx, model.CacheKeyTrendingTopics, limit)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to read trending topics")
+		return nil, fmt.Errorf("获取热门话题失败: %v", err)
+	}
+
+	topics, err := s.dao.GetTopicsByIDs(ctx, ids)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to load topics")
+		return nil, fmt.Errorf("获取热门话题失败: %v", err)
+	}
+	topicMap := make(map[int64]*model.ContentTopic, len(topics))
+	for _, topic := range topics {
+		topicMap[topic.ID] = topic
+	}
+
+	// 按热度顺序输出，已删除的话题跳过
+	result := make([]*model.TrendingTopic, 0, len(ids))
+	for _, id := range ids {
+		if topic, ok := topicMap[id]; ok {
+			result = append(result, &model.TrendingTopic{Topic: topic, Score: scores[id]})
+		}
+	}
+
+	span.SetStatus(codes.Ok, "trending topics retrieved")
+	return result, nil
+}
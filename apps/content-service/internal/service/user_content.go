@@ -0,0 +1,221 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"gorm.io/gorm"
+
+	"goim-social/api/rest"
+	"goim-social/apps/content-service/internal/model"
+	tracecontext "goim-social/pkg/context"
+	"goim-social/pkg/httpx"
+	"goim-social/pkg/logger"
+	"goim-social/pkg/telemetry"
+)
+
+// ==================== 用户内容列表与可见范围 ====================
+// 作者本人可以查看自己全部状态和可见范围的内容；其他查看者只能看到已发布的内容，
+// 其中公开内容所有人可见，好友可见的内容只对作者的好友可见，拉黑双方互相看不到。
+// 可见范围在查询条件中过滤，总数只统计查看者可见的内容；分页与内容流一样使用偏移量游标并跨页去重
+
+// GetUserContent 获取用户内容列表，支持按类型、创建时间、标签和话题过滤，按最新、最多浏览或最多点赞排序
+func (s *Service) GetUserContent(ctx context.Context, query *model.UserContentQuery, page, pageSize int32, cursor string) ([]*model.Content, int64, string, error) {
+	// 开始OpenTelemetry span
+	ctx, span := telemetry.StartSpan(ctx, "content.service.GetUserContent")
+	defer span.End()
+
+	// 设置span属性
+	span.SetAttributes(
+		attribute.Int64("content.author_id", query.AuthorID),
+		attribute.Int64("content.viewer_id", query.ViewerID),
+		attribute.String("content.status", query.Status),
+		attribute.String("content.type", query.Type),
+		attribute.String("content.sort_by", query.SortBy),
+		attribute.Int("content.page", int(page)),
+		attribute.Int("content.page_size", int(pageSize)),
+	)
+
+	// 将业务信息添加到context
+	if query.ViewerID > 0 {
+		ctx = tracecontext.WithUserID(ctx, query.ViewerID)
+	}
+
+	if err := validateUserContentQuery(query); err != nil {
+		span.SetStatus(codes.Error, "invalid query")
+		return nil, 0, "", httpx.InvalidArgument(err)
+	}
+	if query.SortBy == "" {
+		query.SortBy = model.UserContentSortNewest
+	}
+	if page <= 0 {
+		page = 1
+	}
+	pageSize = s.paging.PageSize32(pageSize)
+
+	// 非作者本人只能查看可见的已发布内容
+	if query.ViewerID != query.AuthorID {
+		if query.Status != "" && query.Status != model.ContentStatusPublished {
+			span.SetStatus(codes.Error, "permission denied")
+			return nil, 0, "", httpx.PermissionDenied(fmt.Errorf("只能查看已发布的内容"))
+		}
+		query.Status = model.ContentStatusPublished
+
+		// 拉黑双方互相看不到对方的内容，按没有内容处理
+		if s.hiddenAuthors(ctx, query.ViewerID).Has(query.AuthorID) {
+			span.SetStatus(codes.Ok, "author blocked")
+			return []*model.Content{}, 0, "", nil
+		}
+		query.Visibilities = []string{model.VisibilityPublic}
+		if s.isFriend(ctx, query.ViewerID, query.AuthorID) {
+			query.Visibilities = append(query.Visibilities, model.VisibilityFriends)
+		}
+	}
+
+	// 解析游标，携带游标时以游标记录的偏移量为准
+	offset := (page - 1) * pageSize
+	seen := make(map[int64]bool)
+	var seenIDs []int64
+	if cursor != "" {
+		listCursor, err := model.DecodeFeedCursor(cursor)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, "invalid cursor")
+			return nil, 0, "", httpx.InvalidArgument(err)
+		}
+		offset = listCursor.Offset
+		seenIDs = listCursor.SeenIDs
+		for _, id := range seenIDs {
+			seen[id] = true
+		}
+	}
+
+	// 按浏览数或点赞数排序时翻页期间计数变化会使内容前后移动，去重后不足一页时继续向后补拉
+	contents := make([]*model.Content, 0, pageSize)
+	var total int64
+	exhausted := false
+	for round := 0; round < model.MaxFeedFetchRounds && len(contents) < int(pageSize) && !exhausted; round++ {
+		limit := pageSize - int32(len(contents))
+		batch, count, err := s.dao.GetUserContents(ctx, query, offset, limit)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, "failed to get user content")
+			return nil, 0, "", httpx.Unavailable(fmt.Errorf("获取用户内容失败: %v", err))
+		}
+
+		total = count
+		offset += int32(len(batch))
+		exhausted = len(batch) < int(limit)
+		for _, content := range batch {
+			if seen[content.ID] {
+				continue
+			}
+			seen[content.ID] = true
+			seenIDs = append(seenIDs, content.ID)
+			contents = append(contents, content)
+		}
+	}
+
+	// 已到末尾时不再返回游标
+	nextCursor := ""
+	if !exhausted {
+		nextCursor = model.EncodeFeedCursor(&model.FeedCursor{
+			Offset:  offset,
+			SeenIDs: seenIDs,
+		})
+	}
+
+	span.SetAttributes(
+		attribute.Int("result.count", len(contents)),
+		attribute.Int64("result.total", total),
+	)
+	span.SetStatus(codes.Ok, "user content retrieved successfully")
+	return contents, total, nextCursor, nil
+}
+
+// validateUserContentQuery 校验用户内容列表的过滤条件
+func validateUserContentQuery(query *model.UserContentQuery) error {
+	if query.AuthorID <= 0 {
+		return fmt.Errorf("用户ID无效")
+	}
+	if query.Status != "" && !model.ValidateContentStatus(query.Status) {
+		return fmt.Errorf("内容状态无效: %s", query.Status)
+	}
+	if query.Type != "" && !model.ValidateContentType(query.Type) {
+		return fmt.Errorf("内容类型无效: %s", query.Type)
+	}
+	switch query.SortBy {
+	case "", model.UserContentSortNewest, model.UserContentSortMostViewed, model.UserContentSortMostLiked:
+	default:
+		return fmt.Errorf("排序方式无效: %s", query.SortBy)
+	}
+	if !query.StartTime.IsZero() && !query.EndTime.IsZero() && query.StartTime.After(query.EndTime) {
+		return fmt.Errorf("开始时间不能晚于结束时间")
+	}
+	return nil
+}
+
+// canViewContent 判断查看者能否查看内容的可见范围，作者本人始终可见
+func (s *Service) canViewContent(ctx context.Context, viewerID int64, content *model.Content) bool {
+	if content.Visibility != model.VisibilityFriends || content.AuthorID == viewerID {
+		return true
+	}
+	return s.isFriend(ctx, viewerID, content.AuthorID)
+}
+
+// checkTargetVisible 检查查看者能否看到评论或互动的目标，评论按其所属的内容判断可见范围
+// 目标不存在时不在这里报错，由各操作原有的目标校验处理
+func (s *Service) checkTargetVisible(ctx context.Context, viewerID, targetID int64, targetType string) error {
+	switch targetType {
+	case model.TargetTypeContent:
+		content, err := s.dao.GetContent(ctx, targetID)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		if err != nil {
+			return httpx.Unavailable(fmt.Errorf("查询内容失败: %v", err))
+		}
+		if !s.canViewContent(ctx, viewerID, content) {
+			return httpx.PermissionDenied(fmt.Errorf("内容不可访问"))
+		}
+	case model.TargetTypeComment:
+		comment, err := s.dao.GetComment(ctx, targetID)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		if err != nil {
+			return httpx.Unavailable(fmt.Errorf("查询评论失败: %v", err))
+		}
+		return s.checkTargetVisible(ctx, viewerID, comment.TargetID, comment.TargetType)
+	}
+	return nil
+}
+
+// isFriend 查询查看者是否为作者的好友，未登录、社交服务不可用或查询失败时按非好友处理
+func (s *Service) isFriend(ctx context.Context, viewerID, authorID int64) bool {
+	if viewerID <= 0 || s.social == nil {
+		return false
+	}
+
+	resp, err := s.social.ValidateFriendship(ctx, &rest.ValidateFriendshipRequest{
+		UserId:   viewerID,
+		FriendId: authorID,
+	})
+	if err != nil || !resp.Success {
+		message := ""
+		if err != nil {
+			message = err.Error()
+		} else {
+			message = resp.Message
+		}
+		s.logger.Warn(ctx, "Failed to validate friendship for content visibility",
+			logger.F("viewerID", viewerID),
+			logger.F("authorID", authorID),
+			logger.F("error", message))
+		return false
+	}
+	return resp.IsFriend
+}
@@ -0,0 +1,121 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"google.golang.org/grpc"
+	"gorm.io/gorm"
+
+	"goim-social/api/rest"
+	"goim-social/apps/content-service/internal/dao"
+	"goim-social/apps/content-service/internal/model"
+	"goim-social/pkg/httpx"
+	"goim-social/pkg/logger"
+)
+
+// visibilityDAO 只实现查询内容和评论的内容存储
+type visibilityDAO struct {
+	dao.ContentDAO
+	contents map[int64]*model.Content
+	comments map[int64]*model.Comment
+}
+
+func (d *visibilityDAO) GetContent(ctx context.Context, contentID int64) (*model.Content, error) {
+	if content, ok := d.contents[contentID]; ok {
+		return content, nil
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (d *visibilityDAO) GetComment(ctx context.Context, commentID int64) (*model.Comment, error) {
+	if comment, ok := d.comments[commentID]; ok {
+		return comment, nil
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+// friendSocial 按固定的好友关系回答是否为好友
+type friendSocial struct {
+	rest.SocialServiceClient
+	friends map[[2]int64]bool
+}
+
+func (f *friendSocial) ValidateFriendship(ctx context.Context, req *rest.ValidateFriendshipRequest, opts ...grpc.CallOption) (*rest.ValidateFriendshipResponse, error) {
+	return &rest.ValidateFriendshipResponse{Success: true, IsFriend: f.friends[[2]int64{req.UserId, req.FriendId}]}, nil
+}
+
+// newVisibilityService 作者1发布了公开内容10和好友可见内容20，评论100在内容20下，用户2是作者的好友
+func newVisibilityService(t *testing.T) *Service {
+	t.Helper()
+	log, err := logger.NewLogger("error")
+	if err != nil {
+		t.Fatalf("创建日志失败: %v", err)
+	}
+	return &Service{
+		dao: &visibilityDAO{
+			contents: map[int64]*model.Content{
+				10: {ID: 10, AuthorID: 1, Status: model.ContentStatusPublished, Visibility: model.VisibilityPublic},
+				20: {ID: 20, AuthorID: 1, Status: model.ContentStatusPublished, Visibility: model.VisibilityFriends},
+			},
+			comments: map[int64]*model.Comment{
+				100: {ID: 100, TargetID: 20, TargetType: model.TargetTypeContent, UserID: 2},
+			},
+		},
+		social: &friendSocial{friends: map[[2]int64]bool{{2, 1}: true}},
+		logger: log,
+	}
+}
+
+// TestCheckTargetVisible 好友可见的内容及其评论只对作者和作者的好友可见，不存在的目标不在这里拒绝
+func TestCheckTargetVisible(t *testing.T) {
+	s := newVisibilityService(t)
+	cases := []struct {
+		name       string
+		viewerID   int64
+		targetID   int64
+		targetType string
+		visible    bool
+	}{
+		{name: "public content", viewerID: 3, targetID: 10, targetType: model.TargetTypeContent, visible: true},
+		{name: "author", viewerID: 1, targetID: 20, targetType: model.TargetTypeContent, visible: true},
+		{name: "friend", viewerID: 2, targetID: 20, targetType: model.TargetTypeContent, visible: true},
+		{name: "stranger", viewerID: 3, targetID: 20, targetType: model.TargetTypeContent},
+		{name: "anonymous", viewerID: 0, targetID: 20, targetType: model.TargetTypeContent},
+		{name: "comment under friends content", viewerID: 3, targetID: 100, targetType: model.TargetTypeComment},
+		{name: "comment visible to friend", viewerID: 2, targetID: 100, targetType: model.TargetTypeComment, visible: true},
+		{name: "missing content", viewerID: 3, targetID: 30, targetType: model.TargetTypeContent, visible: true},
+		{name: "user target", viewerID: 3, targetID: 1, targetType: model.TargetTypeUser, visible: true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := s.checkTargetVisible(context.Background(), tc.viewerID, tc.targetID, tc.targetType)
+			if tc.visible && err != nil {
+				t.Errorf("checkTargetVisible() = %v, want visible", err)
+			}
+			if !tc.visible && httpx.StatusOf(err) != http.StatusForbidden {
+				t.Errorf("checkTargetVisible() = %v, want permission denied", err)
+			}
+		})
+	}
+}
+
+// TestFriendsOnlyContentGated 非好友不能读取好友可见内容的评论和回复，也不能对其互动
+func TestFriendsOnlyContentGated(t *testing.T) {
+	s := newVisibilityService(t)
+	ctx := context.Background()
+
+	if _, _, err := s.GetComments(ctx, 3, 20, model.TargetTypeContent, 0, "", "", 1, 20); httpx.StatusOf(err) != http.StatusForbidden {
+		t.Errorf("GetComments by stranger = %v, want permission denied", err)
+	}
+	if _, _, err := s.GetCommentReplies(ctx, 3, 100, "", "", 1, 20); httpx.StatusOf(err) != http.StatusForbidden {
+		t.Errorf("GetCommentReplies by stranger = %v, want permission denied", err)
+	}
+	if _, err := s.DoInteraction(ctx, 3, 20, model.TargetTypeContent, model.InteractionTypeLike, "", "", nil, false); httpx.StatusOf(err) != http.StatusForbidden {
+		t.Errorf("DoInteraction(like) by stranger = %v, want permission denied", err)
+	}
+	if _, err := s.DoInteraction(ctx, 3, 100, model.TargetTypeComment, model.InteractionTypeLike, "", "", nil, false); httpx.StatusOf(err) != http.StatusForbidden {
+		t.Errorf("DoInteraction(like comment) by stranger = %v, want permission denied", err)
+	}
+}
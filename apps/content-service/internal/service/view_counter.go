@@ -0,0 +1,139 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	goredis "github.com/go-redis/redis/v8"
+
+	"goim-social/apps/content-service/internal/model"
+	"goim-social/pkg/logger"
+)
+
+// ==================== 浏览计数 ====================
+// 浏览不在读取路径上同步写库：去重后的浏览先累加到Redis哈希中的待写入增量，
+// 由定时任务批量写入数据库后扣减；读取时返回数据库计数与待写入增量之和作为近似值
+
+// settleViewsScript 扣减已写入数据库的增量，扣减到0时删除字段，期间新增的浏览保留在哈希中
+var settleViewsScript = goredis.NewScript(`
+local remain = redis.call('HINCRBY', KEYS[1], ARGV[1], -tonumber(ARGV[2]))
+if remain <= 0 then
+	redis.call('HDEL', KEYS[1], ARGV[1])
+end
+return remain
+`)
+
+// viewDedupKey 浏览去重标记键
+func viewDedupKey(contentID, userID int64) string {
+	return fmt.Sprintf("%s:%d:%d", model.CacheKeyViewDedup, contentID, userID)
+}
+
+// recordView 记录一次浏览并记入用户的已浏览内容，作者浏览自己的内容不计数，同一用户在冷却期内重复浏览只计一次
+// Redis不可用时回退为异步直接写库
+func (s *Service) recordView(ctx context.Context, contentID, authorID, userID int64) {
+	if userID > 0 && userID == authorID {
+		return
+	}
+	s.markContentViewed(ctx, userID, contentID)
+
+	if s.redis == nil {
+		go func() {
+			if err := s.dao.IncrementViewCount(context.Background(), contentID, 1); err != nil {
+				s.logger.Error(context.Background(), "Failed to increment view count",
+					logger.F("contentID", contentID),
+					logger.F("error", err.Error()))
+			}
+		}()
+		return
+	}
+
+	// 匿名浏览无法识别用户，不做去重
+	if userID > 0 {
+		first, err := s.redis.SetNX(ctx, viewDedupKey(contentID, userID), 1, time.Duration(model.ViewDedupCooldown)*time.Second)
+		if err != nil {
+			s.logger.Warn(ctx, "浏览去重检查失败", logger.F("contentID", contentID), logger.F("error", err.Error()))
+			return
+		}
+		if !first {
+			return
+		}
+	}
+
+	if err := s.redis.GetClient().HIncrBy(ctx, model.CacheKeyPendingViews, strconv.FormatInt(contentID, 10), 1).Err(); err != nil {
+		s.logger.Warn(ctx, "记录浏览增量失败", logger.F("contentID", contentID), logger.F("error", err.Error()))
+	}
+}
+
+// applyPendingViews 将尚未写入数据库的浏览增量叠加到内容的浏览数上
+func (s *Service) applyPendingViews(ctx context.Context, content *model.Content) {
+	if s.redis == nil || content == nil {
+		return
+	}
+
+	pending, err := s.redis.GetClient().HGet(ctx, model.CacheKeyPendingViews, strconv.FormatInt(content.ID, 10)).Int64()
+	if err != nil {
+		return
+	}
+	content.ViewCount += pending
+}
+
+// StartViewCountFlusher 启动浏览增量写库任务
+func (s *Service) StartViewCountFlusher(ctx context.Context) {
+	if s.redis == nil {
+		return
+	}
+
+	ticker := time.NewTicker(time.Duration(model.ViewFlushInterval) * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.flushPendingViews(ctx)
+		}
+	}
+}
+
+// flushPendingViews 将待写入的浏览增量写入数据库并扣减，写库失败的增量保留到下一轮
+func (s *Service) flushPendingViews(ctx context.Context) {
+	pending, err := s.redis.HGetAll(ctx, model.CacheKeyPendingViews)
+	if err != nil {
+		s.logger.Error(ctx, "获取待写入浏览增量失败", logger.F("error", err.Error()))
+		return
+	}
+
+	flushed := 0
+	for field, value := range pending {
+		contentID, err1 := strconv.ParseInt(field, 10, 64)
+		delta, err2 := strconv.ParseInt(value, 10, 64)
+		if err1 != nil || err2 != nil || delta <= 0 {
+			s.redis.GetClient().HDel(ctx, model.CacheKeyPendingViews, field)
+			continue
+		}
+
+		if err := s.dao.IncrementViewCount(ctx, contentID, delta); err != nil {
+			s.logger.Warn(ctx, "浏览增量写入数据库失败",
+				logger.F("contentID", contentID),
+				logger.F("delta", delta),
+				logger.F("error", err.Error()))
+			continue
+		}
+		if err := settleViewsScript.Run(ctx, s.redis.GetClient(), []string{model.CacheKeyPendingViews}, field, delta).Err(); err != nil {
+			s.logger.Error(ctx, "扣减浏览增量失败，该增量可能被重复计入",
+				logger.F("contentID", contentID),
+				logger.F("delta", delta),
+				logger.F("error", err.Error()))
+			continue
+		}
+		s.publishViewEvent(ctx, contentID, delta)
+		flushed++
+	}
+
+	if flushed > 0 {
+		s.logger.Info(ctx, "浏览增量写入完成", logger.F("contents", flushed))
+	}
+}
@@ -0,0 +1,196 @@
+package storage
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	s3Algorithm     = "AWS4-HMAC-SHA256"
+	s3Service       = "s3"
+	s3DateFormat    = "20060102T150405Z"
+	s3MaxExpire     = 7 * 24 * time.Hour // SigV4预签名允许的最长有效期
+	unsignedPayload = "UNSIGNED-PAYLOAD"
+)
+
+// S3Presigner 兼容S3协议的对象存储预签名实现（AWS Signature V4查询参数签名）
+type S3Presigner struct {
+	endpoint  *url.URL
+	region    string
+	bucket    string
+	accessKey string
+	secretKey string
+	pathStyle bool
+	publicURL string
+	now       func() time.Time
+}
+
+// NewS3Presigner 创建S3预签名器，publicURL为空时使用存储地址作为对象访问地址
+func NewS3Presigner(endpoint, region, bucket, accessKey, secretKey string, pathStyle bool, publicURL string) (*S3Presigner, error) {
+	if endpoint == "" || bucket == "" {
+		return nil, fmt.Errorf("对象存储地址和存储桶不能为空")
+	}
+	if accessKey == "" || secretKey == "" {
+		return nil, fmt.Errorf("对象存储访问密钥不能为空")
+	}
+	if region == "" {
+		return nil, fmt.Errorf("对象存储区域不能为空")
+	}
+	u, err := url.Parse(endpoint)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("无效的对象存储地址: %s", endpoint)
+	}
+
+	return &S3Presigner{
+		endpoint:  u,
+		region:    region,
+		bucket:    bucket,
+		accessKey: accessKey,
+		secretKey: secretKey,
+		pathStyle: pathStyle,
+		publicURL: strings.TrimRight(publicURL, "/"),
+		now:       time.Now,
+	}, nil
+}
+
+// PresignPut 生成PUT上传地址，Content-Type和Content-Length参与签名，上传时必须与声明一致
+func (p *S3Presigner) PresignPut(ctx context.Context, req *PresignRequest) (*PresignedUpload, error) {
+	if req.Key == "" {
+		return nil, fmt.Errorf("对象键不能为空")
+	}
+	if req.Expire <= 0 || req.Expire > s3MaxExpire {
+		return nil, fmt.Errorf("无效的上传地址有效期: %s", req.Expire)
+	}
+
+	headers := map[string]string{
+		"Content-Type":   req.ContentType,
+		"Content-Length": strconv.FormatInt(req.ContentLength, 10),
+	}
+	now := p.now().UTC()
+	uploadURL := p.presign(http.MethodPut, req.Key, headers, req.Expire, now)
+
+	return &PresignedUpload{
+		UploadURL: uploadURL,
+		Method:    http.MethodPut,
+		Headers:   headers,
+		ObjectURL: p.objectURL(req.Key),
+		ExpiresAt: now.Add(req.Expire),
+	}, nil
+}
+
+// presign 按SigV4生成预签名地址，headers为需要参与签名的请求头（host自动加入）
+func (p *S3Presigner) presign(method, key string, headers map[string]string, expire time.Duration, now time.Time) string {
+	host, path := p.hostAndPath(key)
+	amzDate := now.Format(s3DateFormat)
+	date := amzDate[:8]
+	scope := strings.Join([]string{date, p.region, s3Service, "aws4_request"}, "/")
+
+	// 规范化请求头，按小写名称排序
+	canonical := map[string]string{"host": host}
+	for name, value := range headers {
+		canonical[strings.ToLower(name)] = strings.TrimSpace(value)
+	}
+	names := make([]string, 0, len(canonical))
+	for name := range canonical {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + canonical[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	query := map[string]string{
+		"X-Amz-Algorithm":     s3Algorithm,
+		"X-Amz-Credential":    p.accessKey + "/" + scope,
+		"X-Amz-Date":          amzDate,
+		"X-Amz-Expires":       strconv.FormatInt(int64(expire/time.Second), 10),
+		"X-Amz-SignedHeaders": signedHeaders,
+	}
+	canonicalQuery := canonicalQueryString(query)
+
+	canonicalRequest := strings.Join([]string{
+		method,
+		path,
+		canonicalQuery,
+		canonicalHeaders.String(),
+		signedHeaders,
+		unsignedPayload,
+	}, "\n")
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := strings.Join([]string{s3Algorithm, amzDate, scope, hex.EncodeToString(requestHash[:])}, "\n")
+
+	signingKey := hmacSHA256([]byte("AWS4"+p.secretKey), date)
+	signingKey = hmacSHA256(signingKey, p.region)
+	signingKey = hmacSHA256(signingKey, s3Service)
+	signingKey = hmacSHA256(signingKey, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(signingKey, stringToSign))
+
+	return fmt.Sprintf("%s://%s%s?%s&X-Amz-Signature=%s", p.endpoint.Scheme, host, path, canonicalQuery, signature)
+}
+
+// hostAndPath 计算对象的请求主机和规范化路径
+func (p *S3Presigner) hostAndPath(key string) (string, string) {
+	base := strings.TrimRight(p.endpoint.Path, "/")
+	if p.pathStyle {
+		return p.endpoint.Host, base + "/" + uriEncode(p.bucket, false) + "/" + uriEncode(key, false)
+	}
+	return p.bucket + "." + p.endpoint.Host, base + "/" + uriEncode(key, false)
+}
+
+// objectURL 对象访问地址
+func (p *S3Presigner) objectURL(key string) string {
+	if p.publicURL != "" {
+		return p.publicURL + "/" + uriEncode(key, false)
+	}
+	host, path := p.hostAndPath(key)
+	return p.endpoint.Scheme + "://" + host + path
+}
+
+// canonicalQueryString 按参数名排序并编码查询参数
+func canonicalQueryString(query map[string]string) string {
+	keys := make([]string, 0, len(query))
+	for k := range query {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	parts := make([]string, 0, len(keys))
+	for _, k := range keys {
+		parts = append(parts, uriEncode(k, true)+"="+uriEncode(query[k], true))
+	}
+	return strings.Join(parts, "&")
+}
+
+// uriEncode 按SigV4规则编码，除非保留字符外全部百分号编码，encodeSlash为false时保留路径分隔符
+func uriEncode(s string, encodeSlash bool) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case (c >= 'A' && c <= 'Z') || (c >= 'a' && c <= 'z') || (c >= '0' && c <= '9'),
+			c == '-', c == '_', c == '.', c == '~':
+			b.WriteByte(c)
+		case c == '/' && !encodeSlash:
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
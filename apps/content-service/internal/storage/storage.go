@@ -0,0 +1,49 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"goim-social/pkg/config"
+)
+
+// PresignRequest 预签名上传请求
+type PresignRequest struct {
+	Key           string        // 对象键
+	ContentType   string        // 文件MIME类型，上传时需一致
+	ContentLength int64         // 文件大小，上传时需一致
+	Expire        time.Duration // 上传地址有效期
+}
+
+// PresignedUpload 预签名上传结果
+type PresignedUpload struct {
+	UploadURL string            // 预签名上传地址
+	Method    string            // 上传使用的HTTP方法
+	Headers   map[string]string // 上传时必须携带的请求头
+	ObjectURL string            // 上传完成后的访问地址
+	ExpiresAt time.Time         // 上传地址过期时间
+}
+
+// Presigner 对象存储预签名接口，可替换为不同的存储实现
+type Presigner interface {
+	PresignPut(ctx context.Context, req *PresignRequest) (*PresignedUpload, error)
+}
+
+// NewFromConfig 根据配置创建预签名器，未启用时返回nil表示不支持媒体上传
+func NewFromConfig(cfg config.MediaConfig) (Presigner, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+
+	switch cfg.Provider {
+	case "", "s3":
+		p, err := NewS3Presigner(cfg.Endpoint, cfg.Region, cfg.Bucket, cfg.AccessKey, cfg.SecretKey, cfg.PathStyle, cfg.PublicURL)
+		if err != nil {
+			return nil, err
+		}
+		return p, nil
+	default:
+		return nil, fmt.Errorf("未知的存储实现: %s", cfg.Provider)
+	}
+}
@@ -4,7 +4,6 @@ import (
 	"context"
 	"log"
 	"os"
-	"time"
 
 	"github.com/gin-gonic/gin"
 	"google.golang.org/grpc"
@@ -39,7 +38,7 @@ func main() {
 
 	// 确保在程序退出时关闭OpenTelemetry
 	defer func() {
-		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		ctx, cancel := context.WithTimeout(context.Background(), otelConfig.ShutdownTimeout)
 		defer cancel()
 		if err := telemetry.ShutdownGlobal(ctx); err != nil {
 			log.Printf("Failed to shutdown OpenTelemetry: %v", err)
@@ -56,14 +55,17 @@ func main() {
 	app.EnableGRPC()
 
 	// 初始化Service层
-	svc := service.NewService(app.GetMongoDB(), app.GetRedisClient(), app.GetKafkaProducer(), app.GetConfig())
+	svc := service.NewService(app.GetMongoDB(), app.GetRedisClient(), app.GetKafkaProducer(), app.GetConfig(), app.GetAuthProvider())
+
+	// 退出时排空WebSocket连接并清理本实例的连接状态
+	app.RegisterShutdownHook("websocket-connections", svc.Shutdown)
 
 	// 创建OpenTelemetry中间件
 	otelMW := middleware.NewOTelMiddleware(serviceName, app.GetLogger())
 
 	// 创建各handler
 	httpHandler := handler.NewHTTPHandler(svc, app.GetLogger())
-	wsHandler := handler.NewWSHandler(svc, app.GetLogger())
+	wsHandler := handler.NewWSHandler(svc, app.GetLogger(), app.GetConfig().Connect.WebSocket)
 	grpcHandler := handler.NewGRPCHandler(svc, app.GetLogger())
 
 	// 注册HTTP路由
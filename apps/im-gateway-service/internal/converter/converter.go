@@ -30,6 +30,8 @@ func (c *Converter) ConnectionModelToMap(conn *model.Connection) map[string]inte
 		"client_type":    conn.ClientType,
 		"online":         conn.Online,
 		"remote_ip":      conn.RemoteIP,
+		"platform":       conn.Platform,
+		"app_version":    conn.AppVersion,
 	}
 }
 
@@ -115,6 +117,14 @@ func (c *Converter) BuildHTTPErrorOnlineStatusResponse(message string) map[strin
 	}
 }
 
+// BuildHTTPOperationResponse 构建HTTP操作结果响应
+func (c *Converter) BuildHTTPOperationResponse(success bool, message string) map[string]interface{} {
+	return map[string]interface{}{
+		"success": success,
+		"message": message,
+	}
+}
+
 // BuildHTTPInvalidRequestResponse 构建HTTP无效请求响应
 func (c *Converter) BuildHTTPInvalidRequestResponse(message string) map[string]interface{} {
 	return map[string]interface{}{
@@ -137,19 +147,76 @@ func (c *Converter) BuildHTTPConnectionStatsResponse(totalConnections, activeCon
 	}
 }
 
-// BuildHTTPConnectionListResponse 构建HTTP连接列表响应
-func (c *Converter) BuildHTTPConnectionListResponse(connections []*model.Connection) map[string]interface{} {
+// BuildHTTPConnectionListResponse 构建HTTP连接列表响应，next_cursor为0表示已列完
+func (c *Converter) BuildHTTPConnectionListResponse(connections []*model.Connection, nextCursor uint64) map[string]interface{} {
 	return map[string]interface{}{
 		"success": true,
 		"message": "获取成功",
 		"data": map[string]interface{}{
 			"connections": c.ConnectionModelsToMaps(connections),
 			"count":       len(connections),
+			"next_cursor": nextCursor,
 			"timestamp":   time.Now().Format(time.RFC3339),
 		},
 	}
 }
 
+// BuildHTTPCapacityResponse 构建HTTP连接容量响应
+func (c *Converter) BuildHTTPCapacityResponse(instanceID string, stats *model.CapacityStats) map[string]interface{} {
+	return map[string]interface{}{
+		"success": true,
+		"message": "获取成功",
+		"data": map[string]interface{}{
+			"instance_id": instanceID,
+			"current":     stats.Current,
+			"pending":     stats.Pending,
+			"max":         stats.Max,
+			"reserved":    stats.Reserved,
+			"rejected":    stats.Rejected,
+			"timestamp":   time.Now().Format(time.RFC3339),
+		},
+	}
+}
+
+// PresenceSubscriptionToMap 将在线状态回调订阅转换为Map格式，签名密钥为空时不返回
+func (c *Converter) PresenceSubscriptionToMap(sub *model.PresenceSubscription) map[string]interface{} {
+	result := map[string]interface{}{
+		"id":           sub.ID,
+		"callback_url": sub.CallbackURL,
+		"user_ids":     sub.UserIDs,
+		"created_at":   sub.CreatedAt,
+	}
+	if sub.Secret != "" {
+		result["secret"] = sub.Secret
+	}
+	return result
+}
+
+// BuildHTTPPresenceSubscriptionResponse 构建HTTP在线状态回调订阅响应，包含只返回一次的签名密钥
+func (c *Converter) BuildHTTPPresenceSubscriptionResponse(sub *model.PresenceSubscription) map[string]interface{} {
+	return map[string]interface{}{
+		"success": true,
+		"message": "订阅成功，请妥善保存签名密钥，之后不再返回",
+		"data":    c.PresenceSubscriptionToMap(sub),
+	}
+}
+
+// BuildHTTPPresenceSubscriptionListResponse 构建HTTP在线状态回调订阅列表响应
+func (c *Converter) BuildHTTPPresenceSubscriptionListResponse(subs []*model.PresenceSubscription) map[string]interface{} {
+	list := make([]map[string]interface{}, 0, len(subs))
+	for _, sub := range subs {
+		list = append(list, c.PresenceSubscriptionToMap(sub))
+	}
+	return map[string]interface{}{
+		"success": true,
+		"message": "获取成功",
+		"data": map[string]interface{}{
+			"subscriptions": list,
+			"count":         len(list),
+		},
+	}
+}
+
 // BuildHTTPHealthResponse 构建HTTP健康检查响应
 func (c *Converter) BuildHTTPHealthResponse(serviceName string, timestamp int64) map[string]interface{} {
 	return map[string]interface{}{
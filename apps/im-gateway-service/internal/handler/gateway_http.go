@@ -42,3 +42,235 @@ func (h *HTTPHandler) OnlineStatus(c *gin.Context) {
 
 	httpx.WriteObject(c, resp, err)
 }
+
+// UpdateFriendOnlineSettings 设置好友上线提醒
+func (h *HTTPHandler) UpdateFriendOnlineSettings(c *gin.Context) {
+	var (
+		ctx  = c.Request.Context()
+		resp interface{}
+		err  error
+	)
+
+	var req struct {
+		UserID int64 `json:"user_id" binding:"required"`
+		Notify bool  `json:"notify"` // 是否接收好友上线提醒
+		Hidden bool  `json:"hidden"` // 上线时是否对好友隐身
+	}
+
+	if err = c.Bind(&req); err != nil {
+		h.log.Error(ctx, "Invalid friend online settings request", logger.F("error", err.Error()))
+		resp = h.converter.BuildHTTPInvalidRequestResponse(err.Error())
+		httpx.WriteObject(c, resp, err)
+		return
+	}
+
+	ctx = tracecontext.WithUserID(ctx, req.UserID)
+
+	if err = h.svc.UpdateFriendOnlineSettings(ctx, req.UserID, req.Notify, req.Hidden); err != nil {
+		h.log.Error(ctx, "Update friend online settings failed", logger.F("error", err.Error()))
+		resp = h.converter.BuildHTTPOperationResponse(false, err.Error())
+	} else {
+		resp = h.converter.BuildHTTPOperationResponse(true, "设置成功")
+	}
+
+	httpx.WriteObject(c, resp, err)
+}
+
+// MuteFriendOnline 屏蔽或取消屏蔽指定好友的上线提醒
+func (h *HTTPHandler) MuteFriendOnline(c *gin.Context) {
+	var (
+		ctx  = c.Request.Context()
+		resp interface{}
+		err  error
+	)
+
+	var req struct {
+		UserID   int64 `json:"user_id" binding:"required"`
+		FriendID int64 `json:"friend_id" binding:"required"`
+		Muted    bool  `json:"muted"`
+	}
+
+	if err = c.Bind(&req); err != nil {
+		h.log.Error(ctx, "Invalid friend online mute request", logger.F("error", err.Error()))
+		resp = h.converter.BuildHTTPInvalidRequestResponse(err.Error())
+		httpx.WriteObject(c, resp, err)
+		return
+	}
+
+	ctx = tracecontext.WithUserID(ctx, req.UserID)
+
+	if err = h.svc.MuteFriendOnline(ctx, req.UserID, req.FriendID, req.Muted); err != nil {
+		h.log.Error(ctx, "Mute friend online failed", logger.F("error", err.Error()))
+		resp = h.converter.BuildHTTPOperationResponse(false, err.Error())
+	} else {
+		resp = h.converter.BuildHTTPOperationResponse(true, "设置成功")
+	}
+
+	httpx.WriteObject(c, resp, err)
+}
+
+// ListConnections 列出连接及客户端平台、版本，供运维排查
+func (h *HTTPHandler) ListConnections(c *gin.Context) {
+	var (
+		ctx  = c.Request.Context()
+		resp interface{}
+		err  error
+	)
+
+	var req struct {
+		OperatorID int64   `json:"operator_id" binding:"required"` // 操作者，须为配置的管理员
+		UserIDs    []int64 `json:"user_ids"`                       // 为空时分页列出全部连接
+		Platform   string  `json:"platform"`                       // 按平台过滤，为空时不过滤
+		Cursor     uint64  `json:"cursor"`                         // 上一页返回的next_cursor，首页为0
+		Limit      int     `json:"limit"`                          // 每页条数，默认100，最多1000
+	}
+
+	if err = c.Bind(&req); err != nil {
+		h.log.Error(ctx, "Invalid list connections request", logger.F("error", err.Error()))
+		resp = h.converter.BuildHTTPInvalidRequestResponse(err.Error())
+		httpx.WriteObject(c, resp, err)
+		return
+	}
+
+	ctx = tracecontext.WithUserID(ctx, req.OperatorID)
+
+	connections, nextCursor, err := h.svc.ListConnections(ctx, req.OperatorID, req.UserIDs, req.Platform, req.Cursor, req.Limit)
+	if err != nil {
+		h.log.Error(ctx, "List connections failed", logger.F("error", err.Error()))
+		resp = h.converter.BuildHTTPOperationResponse(false, err.Error())
+	} else {
+		resp = h.converter.BuildHTTPConnectionListResponse(connections, nextCursor)
+	}
+
+	httpx.WriteObject(c, resp, err)
+}
+
+// GetCapacity 查询本实例的当前连接数和最大连接数，供运维和负载均衡按容量调度，仅管理员
+func (h *HTTPHandler) GetCapacity(c *gin.Context) {
+	var (
+		ctx  = c.Request.Context()
+		resp interface{}
+		err  error
+	)
+
+	var req struct {
+		OperatorID int64 `json:"operator_id" binding:"required"` // 操作者，须为配置的管理员
+	}
+
+	if err = c.Bind(&req); err != nil {
+		h.log.Error(ctx, "Invalid get capacity request", logger.F("error", err.Error()))
+		resp = h.converter.BuildHTTPInvalidRequestResponse(err.Error())
+		httpx.WriteObject(c, resp, err)
+		return
+	}
+
+	ctx = tracecontext.WithUserID(ctx, req.OperatorID)
+
+	stats, err := h.svc.CapacityStats(req.OperatorID)
+	if err != nil {
+		h.log.Error(ctx, "Get capacity failed", logger.F("error", err.Error()))
+		resp = h.converter.BuildHTTPOperationResponse(false, err.Error())
+	} else {
+		resp = h.converter.BuildHTTPCapacityResponse(h.svc.GetInstanceID(), stats)
+	}
+
+	httpx.WriteObject(c, resp, err)
+}
+
+// SubscribePresence 订阅好友的在线状态回调
+func (h *HTTPHandler) SubscribePresence(c *gin.Context) {
+	var (
+		ctx  = c.Request.Context()
+		resp interface{}
+		err  error
+	)
+
+	var req struct {
+		UserID      int64   `json:"user_id" binding:"required"`      // 订阅者，通常为机器人账号
+		CallbackURL string  `json:"callback_url" binding:"required"` // 回调地址
+		UserIDs     []int64 `json:"user_ids" binding:"required"`     // 关注的好友
+	}
+
+	if err = c.Bind(&req); err != nil {
+		h.log.Error(ctx, "Invalid subscribe presence request", logger.F("error", err.Error()))
+		resp = h.converter.BuildHTTPInvalidRequestResponse(err.Error())
+		httpx.WriteObject(c, resp, err)
+		return
+	}
+
+	ctx = tracecontext.WithUserID(ctx, req.UserID)
+
+	sub, err := h.svc.SubscribePresence(ctx, req.UserID, req.CallbackURL, req.UserIDs)
+	if err != nil {
+		h.log.Error(ctx, "Subscribe presence failed", logger.F("error", err.Error()))
+		resp = h.converter.BuildHTTPOperationResponse(false, err.Error())
+	} else {
+		resp = h.converter.BuildHTTPPresenceSubscriptionResponse(sub)
+	}
+
+	httpx.WriteObject(c, resp, err)
+}
+
+// UnsubscribePresence 取消在线状态回调订阅
+func (h *HTTPHandler) UnsubscribePresence(c *gin.Context) {
+	var (
+		ctx  = c.Request.Context()
+		resp interface{}
+		err  error
+	)
+
+	var req struct {
+		UserID         int64  `json:"user_id" binding:"required"`
+		SubscriptionID string `json:"subscription_id" binding:"required"`
+	}
+
+	if err = c.Bind(&req); err != nil {
+		h.log.Error(ctx, "Invalid unsubscribe presence request", logger.F("error", err.Error()))
+		resp = h.converter.BuildHTTPInvalidRequestResponse(err.Error())
+		httpx.WriteObject(c, resp, err)
+		return
+	}
+
+	ctx = tracecontext.WithUserID(ctx, req.UserID)
+
+	if err = h.svc.UnsubscribePresence(ctx, req.UserID, req.SubscriptionID); err != nil {
+		h.log.Error(ctx, "Unsubscribe presence failed", logger.F("error", err.Error()))
+		resp = h.converter.BuildHTTPOperationResponse(false, err.Error())
+	} else {
+		resp = h.converter.BuildHTTPOperationResponse(true, "取消订阅成功")
+	}
+
+	httpx.WriteObject(c, resp, err)
+}
+
+// ListPresenceSubscriptions 列出在线状态回调订阅
+func (h *HTTPHandler) ListPresenceSubscriptions(c *gin.Context) {
+	var (
+		ctx  = c.Request.Context()
+		resp interface{}
+		err  error
+	)
+
+	var req struct {
+		UserID int64 `json:"user_id" binding:"required"`
+	}
+
+	if err = c.Bind(&req); err != nil {
+		h.log.Error(ctx, "Invalid list presence subscriptions request", logger.F("error", err.Error()))
+		resp = h.converter.BuildHTTPInvalidRequestResponse(err.Error())
+		httpx.WriteObject(c, resp, err)
+		return
+	}
+
+	ctx = tracecontext.WithUserID(ctx, req.UserID)
+
+	subs, err := h.svc.ListPresenceSubscriptions(ctx, req.UserID)
+	if err != nil {
+		h.log.Error(ctx, "List presence subscriptions failed", logger.F("error", err.Error()))
+		resp = h.converter.BuildHTTPOperationResponse(false, err.Error())
+	} else {
+		resp = h.converter.BuildHTTPPresenceSubscriptionListResponse(subs)
+	}
+
+	httpx.WriteObject(c, resp, err)
+}
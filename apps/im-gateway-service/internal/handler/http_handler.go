@@ -28,6 +28,13 @@ func NewHTTPHandler(svc *service.Service, log logger.Logger) *HTTPHandler {
 func (h *HTTPHandler) RegisterRoutes(r *gin.Engine) {
 	api := r.Group("/api/v1/connect")
 	{
-		api.POST("/online_status", h.OnlineStatus) // 查询在线状态
+		api.POST("/online_status", h.OnlineStatus)                        // 查询在线状态
+		api.POST("/friend_online/settings", h.UpdateFriendOnlineSettings) // 设置好友上线提醒
+		api.POST("/friend_online/mute", h.MuteFriendOnline)               // 屏蔽指定好友的上线提醒
+		api.POST("/connections", h.ListConnections)                       // 列出连接及客户端信息，仅管理员
+		api.POST("/capacity", h.GetCapacity)                              // 查询本实例的连接容量，仅管理员
+		api.POST("/presence_webhook/subscribe", h.SubscribePresence)      // 订阅好友的在线状态回调
+		api.POST("/presence_webhook/unsubscribe", h.UnsubscribePresence)  // 取消在线状态回调订阅
+		api.POST("/presence_webhook/list", h.ListPresenceSubscriptions)   // 列出在线状态回调订阅
 	}
 }
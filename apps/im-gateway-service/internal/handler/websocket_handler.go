@@ -9,12 +9,14 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/gorilla/websocket"
-	"google.golang.org/protobuf/proto"
 
 	"goim-social/api/rest"
+	"goim-social/apps/im-gateway-service/internal/model"
 	"goim-social/apps/im-gateway-service/internal/service"
+	"goim-social/pkg/config"
 	tracecontext "goim-social/pkg/context"
 	"goim-social/pkg/logger"
+	"goim-social/pkg/wsorigin"
 )
 
 // WSHandler WebSocket协议处理器
@@ -24,12 +26,25 @@ type WSHandler struct {
 	upgrader websocket.Upgrader
 }
 
-// NewWSHandler 创建WebSocket处理器
-func NewWSHandler(svc *service.Service, log logger.Logger) *WSHandler {
+// NewWSHandler 创建WebSocket处理器，握手时按配置校验Origin，拒绝不允许的浏览器来源
+func NewWSHandler(svc *service.Service, log logger.Logger, wsCfg config.WebSocketConfig) *WSHandler {
+	checker := wsorigin.NewChecker(wsCfg)
 	return &WSHandler{
-		svc:      svc,
-		log:      log,
-		upgrader: websocket.Upgrader{},
+		svc: svc,
+		log: log,
+		upgrader: websocket.Upgrader{
+			// 原生客户端使用protobuf，浏览器客户端可协商json子协议
+			Subprotocols: []string{model.SubprotocolProtobuf, model.SubprotocolJSON},
+			CheckOrigin: func(r *http.Request) bool {
+				if checker.Check(r) {
+					return true
+				}
+				log.Warn(r.Context(), "WebSocket origin rejected",
+					logger.F("origin", r.Header.Get("Origin")),
+					logger.F("host", r.Host))
+				return false
+			},
+		},
 	}
 }
 
@@ -45,8 +60,11 @@ func (ws *WSHandler) RegisterRoutes(r *gin.Engine) {
 func (ws *WSHandler) HandleConnection(c *gin.Context) {
 	ctx := c.Request.Context()
 
-	// 从 header 获取 token
+	// 从 header 获取 token，浏览器WebSocket无法设置header时从query参数获取
 	token := c.GetHeader("Authorization")
+	if token == "" {
+		token = c.Query("token")
+	}
 	if token == "" {
 		ws.log.Error(ctx, "Missing authorization token")
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "缺少认证 token"})
@@ -55,6 +73,9 @@ func (ws *WSHandler) HandleConnection(c *gin.Context) {
 
 	// 从headers中获取userID
 	userIDStr := c.GetHeader("User-ID")
+	if userIDStr == "" {
+		userIDStr = c.Query("user_id")
+	}
 	if userIDStr == "" {
 		ws.log.Error(ctx, "Missing User-ID header")
 		c.JSON(http.StatusBadRequest, gin.H{"error": "缺少User-ID header"})
@@ -74,13 +95,35 @@ func (ws *WSHandler) HandleConnection(c *gin.Context) {
 
 	// 验证token
 	ws.log.Info(c.Request.Context(), "Validating token", logger.F("token", token), logger.F("userID", userID))
-	if !ws.svc.ValidateToken(token) {
+	if !ws.svc.ValidateToken(c.Request.Context(), token) {
 		ws.log.Error(c.Request.Context(), "Invalid token", logger.F("token", token), logger.F("userID", userID))
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "无效认证 token"})
 		return
 	}
 	ws.log.Info(c.Request.Context(), "Token validation successful", logger.F("userID", userID))
 
+	// 客户端平台和版本，浏览器无法设置请求头时从query参数获取
+	platform := c.GetHeader(model.HeaderClientPlatform)
+	if platform == "" {
+		platform = c.Query("platform")
+	}
+	appVersion := c.GetHeader(model.HeaderAppVersion)
+	if appVersion == "" {
+		appVersion = c.Query("app_version")
+	}
+	client := service.ParseClientInfo(platform, appVersion)
+
+	// 检查本实例的连接容量，满载时返回503和Retry-After，客户端重试时由负载均衡路由到其他实例
+	release, err := ws.svc.AdmitConnection(c.Request.Context(), userID)
+	if err != nil {
+		retryAfter := int(ws.svc.CapacityRetryAfter().Seconds())
+		ws.log.Warn(c.Request.Context(), "Connection rejected, instance at capacity", logger.F("userID", userID))
+		c.Header("Retry-After", strconv.Itoa(retryAfter))
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error(), "retry_after": retryAfter})
+		return
+	}
+	defer release()
+
 	// 升级到WebSocket连接
 	conn, err := ws.upgrader.Upgrade(c.Writer, c.Request, nil)
 	if err != nil {
@@ -88,14 +131,24 @@ func (ws *WSHandler) HandleConnection(c *gin.Context) {
 		return
 	}
 	defer conn.Close()
+	defer service.ReleaseWSConn(conn)
+
+	ws.log.Info(c.Request.Context(), "WebSocket connection established",
+		logger.F("userID", userID),
+		logger.F("subprotocol", conn.Subprotocol()),
+		logger.F("platform", client.Platform),
+		logger.F("appVersion", client.AppVersion))
 
 	// 将userID存储到gin.Context中，供后续使用
 	c.Set("user_id", userID)
 
+	// 建立连接前判断是否为该用户首个在线设备，用于好友上线提醒
+	firstDevice := !ws.svc.HasActiveConnection(c.Request.Context(), userID)
+
 	// 1. 建立连接记录到Redis
 	timestamp := time.Now().Unix()
 	connID := fmt.Sprintf("conn-%d-%d", userID, timestamp)
-	_, err = ws.svc.Connect(c.Request.Context(), userID, token, ws.svc.GetInstanceID(), "web")
+	_, err = ws.svc.Connect(c.Request.Context(), userID, token, ws.svc.GetInstanceID(), "web", client)
 	if err != nil {
 		ws.log.Error(c.Request.Context(), "Failed to register connection", logger.F("error", err.Error()))
 		return
@@ -113,8 +166,8 @@ func (ws *WSHandler) HandleConnection(c *gin.Context) {
 			}
 		}()
 
-		// 发送pong响应
-		err = conn.WriteMessage(websocket.PongMessage, []byte(appData))
+		// 发送pong响应，与其他写入共用连接写锁
+		err := service.WriteWSPong(conn, appData)
 		if err != nil {
 			ws.log.Error(c.Request.Context(), "发送pong响应失败",
 				logger.F("userID", userID),
@@ -124,12 +177,22 @@ func (ws *WSHandler) HandleConnection(c *gin.Context) {
 	})
 
 	// 3. 注册本地WebSocket连接
-	ws.svc.AddWebSocketConnection(userID, conn)
+	ws.svc.AddWebSocketConnection(userID, conn, client)
+	release()
+
+	// 首个设备上线时异步提醒在线好友并回调在线状态订阅，重连和多设备登录不重复提醒
+	if firstDevice {
+		go ws.svc.NotifyFriendsOnline(context.Background(), userID)
+		go ws.svc.PublishPresenceChange(context.Background(), userID, model.PresenceStatusOnline)
+	}
 
 	// 4. 确保断开时清理资源
+	// 连接断开后请求context可能已取消，清理使用新的context，避免残留连接记录和在线状态
 	defer func(uid int64, cid string) {
 		ws.svc.RemoveWebSocketConnection(uid)
-		ws.svc.Disconnect(c.Request.Context(), uid, cid)
+		ctx, cancel := frameContext(c)
+		defer cancel()
+		ws.svc.Disconnect(ctx, uid, cid)
 	}(userID, connID)
 
 	// 认证通过，进入主循环
@@ -146,39 +209,66 @@ func (ws *WSHandler) handleWebSocketMessages(c *gin.Context, conn *websocket.Con
 			break
 		}
 
-		var wsMsg rest.WSMessage
-		if err := proto.Unmarshal(msg, &wsMsg); err != nil {
-			ws.log.Error(c.Request.Context(), "Invalid WebSocket proto message", logger.F("error", err.Error()))
+		envelope, err := service.DecodeWSFrame(conn.Subprotocol(), msg)
+		if err != nil {
+			ws.log.Warn(c.Request.Context(), "Invalid WebSocket frame",
+				logger.F("subprotocol", conn.Subprotocol()),
+				logger.F("error", err.Error()))
 			continue
 		}
 
-		ws.routeWebSocketMessage(c, conn, &wsMsg)
+		ctx, cancel := frameContext(c)
+		ws.routeWebSocketFrame(ctx, conn, userID, envelope)
+		cancel()
 	}
 }
 
-// routeWebSocketMessage 路由WebSocket消息到对应的处理器
-func (ws *WSHandler) routeWebSocketMessage(c *gin.Context, conn *websocket.Conn, wsMsg *rest.WSMessage) {
-	switch wsMsg.MessageType {
-	case 1: // 文本消息
-		if err := ws.svc.ForwardMessageToLogicService(c.Request.Context(), wsMsg); err != nil {
-			ws.log.Error(c.Request.Context(), "ForwardMessageToLogicService failed", logger.F("error", err.Error()))
+// frameContext 为单帧处理创建context，保留连接上的追踪信息，不继承连接的取消和截止时间
+func frameContext(c *gin.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.WithoutCancel(c.Request.Context()), model.WSFrameTimeout*time.Second)
+}
+
+// routeWebSocketFrame 按信封类型路由帧，聊天帧经防重放校验后转发给Logic服务并回复发送确认，控制帧按操作分发
+func (ws *WSHandler) routeWebSocketFrame(ctx context.Context, conn *websocket.Conn, userID int64, envelope *rest.WSEnvelope) {
+	switch payload := envelope.Payload.(type) {
+	case *rest.WSEnvelope_Chat:
+		if err := ws.svc.SendChatMessage(ctx, conn, userID, payload.Chat); err != nil {
+			ws.log.Error(ctx, "ForwardMessageToLogicService failed", logger.F("error", err.Error()))
 		}
-	case 2: // 心跳
-		if err := ws.svc.HandleHeartbeat(c.Request.Context(), wsMsg, conn); err != nil {
-			ws.log.Error(c.Request.Context(), "HandleHeartbeat failed", logger.F("error", err.Error()))
+	case *rest.WSEnvelope_Control:
+		ws.routeControlFrame(ctx, payload.Control)
+	}
+}
+
+// routeControlFrame 路由控制帧到对应的处理器
+func (ws *WSHandler) routeControlFrame(ctx context.Context, frame *rest.ControlFrame) {
+	switch frame.Op {
+	case rest.ControlOp_CONTROL_OP_HEARTBEAT:
+		if err := ws.svc.HandleHeartbeat(ctx, frame); err != nil {
+			ws.log.Error(ctx, "HandleHeartbeat failed", logger.F("error", err.Error()))
 		}
-	case 3: // 连接管理
-		// 连接管理功能已简化，暂时跳过
-		ws.log.Info(c.Request.Context(), "Connection management message received", logger.F("userID", wsMsg.From))
-	case 4: // 消息ACK确认
-		if err := ws.svc.HandleMessageACK(c.Request.Context(), wsMsg); err != nil {
-			ws.log.Error(c.Request.Context(), "HandleMessageACK failed", logger.F("error", err.Error()))
+	case rest.ControlOp_CONTROL_OP_ACK:
+		// 确认本身是幂等的，只拦截时间戳超出窗口的重放帧
+		if err := ws.svc.CheckFrameTimestamp(frame.Timestamp); err != nil {
+			ws.log.Warn(ctx, "Replayed ACK frame rejected",
+				logger.F("userID", frame.UserId),
+				logger.F("messageID", frame.MessageId),
+				logger.F("error", err.Error()))
+			return
+		}
+		if err := ws.svc.HandleMessageACK(ctx, frame); err != nil {
+			ws.log.Error(ctx, "HandleMessageACK failed", logger.F("error", err.Error()))
 		}
-	case 10: // 在线状态事件推送
+	case rest.ControlOp_CONTROL_OP_CONNECTION:
+		// 连接管理功能已简化，暂时跳过
+		ws.log.Info(ctx, "Connection management message received", logger.F("userID", frame.UserId))
+	case rest.ControlOp_CONTROL_OP_TYPING:
+		// TODO:输入状态转发暂未实现
+		ws.log.Debug(ctx, "Typing event received", logger.F("userID", frame.UserId))
+	case rest.ControlOp_CONTROL_OP_PRESENCE:
 		// TODO:在线状态事件推送功能暂未实现(类似上线通知粉丝/订阅者)
-		ws.log.Info(c.Request.Context(), "Online status event received", logger.F("userID", wsMsg.From))
+		ws.log.Info(ctx, "Online status event received", logger.F("userID", frame.UserId))
 	default:
-		// 未知类型
-		ws.log.Warn(c.Request.Context(), "Unknown message type", logger.F("type", wsMsg.MessageType))
+		ws.log.Warn(ctx, "Unknown control op", logger.F("op", frame.Op.String()))
 	}
 }
@@ -1,5 +1,176 @@
 package model
 
+// WebSocket子协议，握手时通过Sec-WebSocket-Protocol协商
+const (
+	SubprotocolProtobuf = "protobuf" // 二进制protobuf帧（默认，原生客户端）
+	SubprotocolJSON     = "json"     // 文本JSON帧（浏览器客户端）
+)
+
+// WebSocket帧信封
+const (
+	WSEnvelopeVersion = 1 // 当前信封协议版本，高于此版本的帧拒绝处理
+)
+
+// 发送确认中由网关判定的失败原因，Logic服务的拒绝原因原样转告
+const (
+	SendAckReasonUnavailable = "unavailable" // Logic服务调用失败或处理出错，客户端可重试
+	SendAckReasonStaleFrame  = "stale_frame" // 帧时间戳超出防重放窗口，客户端需校准时间后重新发送
+)
+
+// 旧版裸WSMessage帧通过message_type区分控制语义，仅用于兼容解析
+const (
+	LegacyMessageTypeText       = 1  // 文本消息
+	LegacyMessageTypeHeartbeat  = 2  // 心跳，content为连接ID
+	LegacyMessageTypeConnection = 3  // 连接管理
+	LegacyMessageTypeAck        = 4  // 消息ACK确认
+	LegacyMessageTypePresence   = 10 // 在线状态事件
+)
+
+// 发送方消息回显
+const (
+	EchoModeOtherDevices = "other_devices" // 只回显到发送方的其他设备（默认）
+	EchoModeAllDevices   = "all_devices"   // 同时回显到发起发送的连接
+
+	GatewayMessageTypeEcho = "echo_message" // 经connect_forward转发的回显消息
+)
+
+// 上行帧防重放
+const (
+	ReplayKeyPrefix      = "ws_replay" // 已处理的client_msg_id ws_replay:{userID}:{clientMsgID}，值为首次处理的发送确认
+	ReplayPendingValue   = "pending"   // 首次处理尚未完成
+	DefaultReplayWindow  = 300         // 默认时间窗口（秒）
+	MaxClientMsgIDLength = 128         // client_msg_id最大长度，超出时拒绝
+)
+
+// 单实例连接容量
+// 连接数达到上限后新的握手返回503，连接数进入为重连预留的容量后只接受重连的用户
+const (
+	DefaultCapacityRetryAfter = 5 // 默认建议客户端重试的等待时间（秒）
+)
+
+// CapacityStats 本实例的连接容量
+type CapacityStats struct {
+	Current  int   `json:"current"`  // 当前连接数
+	Pending  int   `json:"pending"`  // 已通过容量检查、正在握手的连接数
+	Max      int   `json:"max"`      // 最大连接数，0表示不限制
+	Reserved int   `json:"reserved"` // 为重连用户预留的连接数
+	Rejected int64 `json:"rejected"` // 启动以来拒绝的握手数
+}
+
+// WSFrameTimeout 单个WebSocket帧的处理超时（秒），长连接不受HTTP请求截止时间约束，每帧单独计时
+const WSFrameTimeout = 5
+
+// 跨节点转发去重
+const (
+	ForwardSeqWindow    = 1024 // 每个来源保留的序号窗口，落后窗口之外的消息视为过期丢弃
+	ForwardSourceExpire = 600  // 来源空闲过期时间（秒），发送方重启后会使用新的来源标识
+)
+
+// 同一发送者消息的顺序保证
+const (
+	SenderOrderWait       = 500 // 等待缺失的发送者序号的最长时间（毫秒），超时后跳过继续推送
+	SenderOrderIdleExpire = 600 // 发送流空闲过期时间（秒）
+)
+
+// 推送优先级通道，每个连接的发送队列按通道排队，high先于normal、normal先于bulk发送
+const (
+	PushLaneHigh   = "high"   // 单聊消息
+	PushLaneNormal = "normal" // 群聊消息
+	PushLaneBulk   = "bulk"   // 系统广播、已读回执、在线状态和好友上线提醒等批量推送
+
+	DefaultPushLaneQueueSize = 256 // 每个通道默认最多排队的消息数
+
+	MessageTypeSystem      = 100 // 系统消息（管理员广播）
+	MessageTypeReadReceipt = 106 // 已读回执
+)
+
+// 好友上线提醒
+// 接收方需主动开启提醒，可对单个好友屏蔽；隐身用户上线不通知任何好友
+const (
+	MessageTypeFriendOnline          = 11                       // 好友上线提醒，from为上线的好友
+	DefaultFriendOnlineDebounce      = 60                       // 默认防抖时间（秒）
+	FriendOnlineOptInKey             = "friend_online:optin"    // 开启好友上线提醒的用户集合
+	FriendOnlineHiddenKey            = "friend_online:hidden"   // 上线时不通知好友的用户集合
+	FriendOnlineMutedKeyPrefix       = "friend_online:muted"    // 用户屏蔽上线提醒的好友集合前缀
+	FriendOnlineNotifiedKeyPrefix    = "friend_online:notified" // 防抖期内已发送过上线提醒的标记前缀
+	FriendOnlineLastOfflineKeyPrefix = "friend_online:offline"  // 最近一次全部设备下线的标记前缀
+)
+
+// 在线状态回调
+// 机器人等外部集成订阅好友的上下线事件，用户首个设备上线和全部设备下线时签名后回调；隐身用户不回调
+const (
+	PresenceStatusOnline  = "online"
+	PresenceStatusOffline = "offline"
+	PresenceEventChanged  = "presence.changed" // 回调事件类型
+
+	PresenceHookKeyPrefix         = "presence_webhook:sub"      // 订阅详情 presence_webhook:sub:{subscriptionID}
+	PresenceHookOwnerKeyPrefix    = "presence_webhook:owner"    // 用户创建的订阅ID集合 presence_webhook:owner:{userID}
+	PresenceHookWatchersKeyPrefix = "presence_webhook:watchers" // 关注某用户的订阅ID集合 presence_webhook:watchers:{userID}
+	PresenceHiddenUsersKey        = "presence_hidden_users"     // 在线状态隐身用户集合，由social-service维护
+
+	DefaultPresenceHookTimeout          = 5    // 默认单次回调超时（秒）
+	DefaultPresenceHookRetryBackoff     = 1000 // 默认首次重试等待时间（毫秒）
+	DefaultPresenceHookMaxSubscriptions = 5    // 默认每个用户最多的订阅数
+	DefaultPresenceHookMaxUsers         = 500  // 默认每个订阅最多关注的用户数
+	MaxPresenceHookRetryBackoff         = 60   // 重试等待时间上限（秒）
+)
+
+// PresenceSubscription 在线状态回调订阅，签名密钥只在创建时返回
+type PresenceSubscription struct {
+	ID          string  `json:"id"`
+	OwnerID     int64   `json:"owner_id"`
+	CallbackURL string  `json:"callback_url"`
+	Secret      string  `json:"secret,omitempty"`
+	UserIDs     []int64 `json:"user_ids"`
+	CreatedAt   int64   `json:"created_at"`
+}
+
+// PresenceEvent 在线状态回调事件，重试时事件ID不变
+type PresenceEvent struct {
+	EventID        string `json:"event_id"`
+	Type           string `json:"type"`
+	SubscriptionID string `json:"subscription_id"`
+	UserID         int64  `json:"user_id"`
+	Status         string `json:"status"`
+	OccurredAt     int64  `json:"occurred_at"`
+}
+
+// 客户端平台与版本，握手时通过请求头上报，浏览器无法设置请求头时通过query参数上报
+const (
+	PlatformIOS     = "ios"
+	PlatformAndroid = "android"
+	PlatformWeb     = "web"
+	PlatformDesktop = "desktop"
+	PlatformUnknown = "unknown" // 未上报或无法识别
+
+	DefaultAppVersion = "0.0.0" // 无法解析的版本按最旧版本处理，不开启需要新版本的功能；未上报的版本保留为空，视为支持所有功能
+
+	HeaderClientPlatform = "X-Client-Platform"
+	HeaderAppVersion     = "X-App-Version"
+)
+
+// 连接列表分页
+const (
+	DefaultConnectionListLimit = 100  // 连接列表默认每页条数
+	MaxConnectionListLimit     = 1000 // 连接列表每页上限，按用户查询时也是用户数上限
+)
+
+// 需要按客户端版本开启的功能
+const (
+	FeatureFriendOnline = "friend_online" // 好友上线提醒
+)
+
+// FeatureMinAppVersion 功能要求的最低客户端版本，旧版本客户端无法识别对应的消息类型
+var FeatureMinAppVersion = map[string]string{
+	FeatureFriendOnline: "1.1.0",
+}
+
+// ClientInfo 客户端信息
+type ClientInfo struct {
+	Platform   string `json:"platform"`
+	AppVersion string `json:"app_version"`
+}
+
 // WSMessage represents a WebSocket message structure.
 type WSMessage struct {
 	MessageType int         `json:"message_type"`
@@ -16,6 +187,8 @@ type Connection struct {
 	Timestamp     int64  `json:"timestamp"`
 	LastHeartbeat int64  `json:"last_heartbeat"`
 	ClientType    string `json:"client_type"`
+	Platform      string `json:"platform"`
+	AppVersion    string `json:"app_version"`
 }
 
 type ConnectRequest struct {
@@ -0,0 +1,145 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	goredis "github.com/go-redis/redis/v8"
+	"google.golang.org/protobuf/proto"
+
+	"goim-social/api/rest"
+	"goim-social/apps/im-gateway-service/internal/model"
+)
+
+// ==================== 上行帧防重放 ====================
+// 截获的上行帧可能被原样重发，网关校验帧时间戳，超出窗口的帧直接拒绝；
+// 窗口内按用户记录已处理的client_msg_id，重复的聊天帧不再转发给Logic服务，而是回复首次处理的发送确认，
+// 客户端的正常重试因此得到相同的消息ID，不会产生重复消息。记录保存在Redis中，重连到其他实例后重试同样生效
+
+// 防重放拒绝原因
+var (
+	ErrStaleFrame         = errors.New("帧时间戳超出防重放窗口")
+	ErrMissingClientMsgID = errors.New("聊天帧缺少client_msg_id")
+	ErrMissingTimestamp   = errors.New("帧缺少时间戳")
+	ErrInvalidClientMsgID = errors.New("client_msg_id过长")
+)
+
+// replayWindow 防重放时间窗口
+func (s *Service) replayWindow() time.Duration {
+	window := s.config.Connect.AntiReplay.Window
+	if window <= 0 {
+		window = model.DefaultReplayWindow
+	}
+	return time.Duration(window) * time.Second
+}
+
+// CheckFrameTimestamp 校验上行帧时间戳（秒），与服务端时间相差超过窗口时返回ErrStaleFrame
+// 未携带时间戳的旧版客户端帧只有在要求client_msg_id（同时要求时间戳）时才拒绝
+func (s *Service) CheckFrameTimestamp(timestamp int64) error {
+	cfg := s.config.Connect.AntiReplay
+	if !cfg.Enabled {
+		return nil
+	}
+	if timestamp <= 0 {
+		if cfg.RequireClientMsgID {
+			replayRejected.WithLabelValues(replayReasonMissing).Inc()
+			return ErrMissingTimestamp
+		}
+		return nil
+	}
+
+	skew := time.Since(time.Unix(timestamp, 0))
+	if skew < 0 {
+		skew = -skew
+	}
+	if skew > s.replayWindow() {
+		replayRejected.WithLabelValues(replayReasonStale).Inc()
+		return ErrStaleFrame
+	}
+	return nil
+}
+
+// checkChatFrame 校验聊天帧的时间戳和client_msg_id
+func (s *Service) checkChatFrame(msg *rest.WSMessage) error {
+	cfg := s.config.Connect.AntiReplay
+	if !cfg.Enabled {
+		return nil
+	}
+	if msg.ClientMsgId == "" && cfg.RequireClientMsgID {
+		replayRejected.WithLabelValues(replayReasonMissing).Inc()
+		return ErrMissingClientMsgID
+	}
+	if len(msg.ClientMsgId) > model.MaxClientMsgIDLength {
+		return ErrInvalidClientMsgID
+	}
+	return s.CheckFrameTimestamp(msg.Timestamp)
+}
+
+// beginClientMsg 登记首次处理的client_msg_id，返回是否为重复帧以及首次处理的发送确认
+// 首次处理尚未完成时重复帧没有可回复的确认，返回的确认为nil；Redis不可用时不拦截
+func (s *Service) beginClientMsg(ctx context.Context, userID int64, clientMsgID string) (bool, *rest.SendAck) {
+	if !s.config.Connect.AntiReplay.Enabled || clientMsgID == "" || s.redis == nil {
+		return false, nil
+	}
+
+	// 时间戳在窗口内的帧最晚在首次处理后两个窗口内仍可通过校验，记录至少保留这么久
+	key := replayKey(userID, clientMsgID)
+	ok, err := s.redis.SetNX(ctx, key, model.ReplayPendingValue, 2*s.replayWindow())
+	if err != nil {
+		log.Printf("登记client_msg_id失败: UserID=%d, ClientMsgID=%s, Error=%v", userID, clientMsgID, err)
+		return false, nil
+	}
+	if ok {
+		return false, nil
+	}
+
+	replayRejected.WithLabelValues(replayReasonDuplicate).Inc()
+	value, err := s.redis.Get(ctx, key)
+	if err != nil {
+		if err != goredis.Nil {
+			log.Printf("读取client_msg_id处理结果失败: UserID=%d, ClientMsgID=%s, Error=%v", userID, clientMsgID, err)
+		}
+		return true, nil
+	}
+	if value == model.ReplayPendingValue {
+		return true, nil
+	}
+
+	var ack rest.SendAck
+	if err := proto.Unmarshal([]byte(value), &ack); err != nil {
+		return true, nil
+	}
+	return true, &ack
+}
+
+// finishClientMsg 保存首次处理的发送确认，供窗口内的重试直接回复
+// 发送失败的记录被删除，客户端可以用同一个client_msg_id重试
+func (s *Service) finishClientMsg(ctx context.Context, userID int64, ack *rest.SendAck) {
+	if !s.config.Connect.AntiReplay.Enabled || ack.ClientMsgId == "" || s.redis == nil {
+		return
+	}
+
+	key := replayKey(userID, ack.ClientMsgId)
+	if ack.Status == rest.SendAckStatus_SEND_ACK_STATUS_FAILED {
+		if err := s.redis.Del(ctx, key); err != nil {
+			log.Printf("清除client_msg_id失败: UserID=%d, ClientMsgID=%s, Error=%v", userID, ack.ClientMsgId, err)
+		}
+		return
+	}
+
+	data, err := proto.Marshal(ack)
+	if err != nil {
+		return
+	}
+	if err := s.redis.Set(ctx, key, data, 2*s.replayWindow()); err != nil {
+		log.Printf("保存client_msg_id处理结果失败: UserID=%d, ClientMsgID=%s, Error=%v", userID, ack.ClientMsgId, err)
+	}
+}
+
+// replayKey 已处理的client_msg_id键
+func replayKey(userID int64, clientMsgID string) string {
+	return fmt.Sprintf("%s:%d:%s", model.ReplayKeyPrefix, userID, clientMsgID)
+}
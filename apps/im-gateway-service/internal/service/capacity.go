@@ -0,0 +1,109 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"goim-social/apps/im-gateway-service/internal/model"
+	"goim-social/pkg/httpx"
+)
+
+// ==================== 单实例连接容量 ====================
+// 连接数达到上限后拒绝新的握手，客户端按Retry-After重试，由负载均衡路由到其他实例；
+// 连接数进入为重连预留的容量后只接受重连的用户：本实例已有连接的用户重连时替换原连接，不增加连接数；
+// 刚断线、在线状态缓存中仍在线的用户优先于新用户，避免实例接近满载时已在线的用户掉线后无法恢复
+
+// ErrCapacityFull 本实例连接数已满
+var ErrCapacityFull = errors.New("连接数已达上限，请稍后重试")
+
+// AdmitConnection 握手前检查本实例的连接容量，通过时返回的release须在连接注册后或握手失败时调用
+// 通过检查到注册完成之间的连接计入容量；检查和计入以CAS一起完成，计数在检查后被并发握手改变时重新检查，
+// 避免并发握手同时通过检查而超出上限。注册时先加入本地连接再release，期间只会多算不会少算
+func (s *Service) AdmitConnection(ctx context.Context, userID int64) (func(), error) {
+	maxConns := s.config.Connect.Connection.MaxConnections
+	if maxConns <= 0 {
+		return func() {}, nil
+	}
+
+	// 本实例已有连接的用户重连时替换原连接，不占用新的容量
+	if _, exists := s.connMgr.GetConnection(userID); exists {
+		s.admitting.Add(1)
+	} else {
+		// 是否重连需要查询Redis，只在进入预留容量时查询一次
+		checked, reconnecting := false, false
+		for {
+			pending := s.admitting.Load()
+			used := s.connMgr.LocalConnectionCount() + int(pending)
+			if used >= maxConns {
+				return nil, s.rejectConnection(capacityReasonFull)
+			}
+			if used >= maxConns-s.reservedConnections() {
+				if !checked {
+					checked, reconnecting = true, s.isReconnecting(ctx, userID)
+				}
+				if !reconnecting {
+					return nil, s.rejectConnection(capacityReasonReserved)
+				}
+			}
+			if s.admitting.CompareAndSwap(pending, pending+1) {
+				break
+			}
+		}
+	}
+
+	var once sync.Once
+	return func() {
+		once.Do(func() { s.admitting.Add(-1) })
+	}, nil
+}
+
+// CapacityRetryAfter 拒绝握手时建议客户端重试的等待时间
+func (s *Service) CapacityRetryAfter() time.Duration {
+	retryAfter := s.config.Connect.Connection.RetryAfter
+	if retryAfter <= 0 {
+		retryAfter = model.DefaultCapacityRetryAfter
+	}
+	return time.Duration(retryAfter) * time.Second
+}
+
+// CapacityStats 返回本实例的连接容量，仅配置的管理员可查询
+func (s *Service) CapacityStats(operatorID int64) (*model.CapacityStats, error) {
+	if !s.admins[operatorID] {
+		return nil, httpx.PermissionDenied(fmt.Errorf("无权限查询连接容量"))
+	}
+	return &model.CapacityStats{
+		Current:  s.connMgr.LocalConnectionCount(),
+		Pending:  int(s.admitting.Load()),
+		Max:      s.config.Connect.Connection.MaxConnections,
+		Reserved: s.reservedConnections(),
+		Rejected: s.rejected.Load(),
+	}, nil
+}
+
+// reservedConnections 为重连用户预留的连接数
+func (s *Service) reservedConnections() int {
+	cfg := s.config.Connect.Connection
+	if cfg.MaxConnections <= 0 || cfg.ReconnectReserve <= 0 {
+		return 0
+	}
+	return cfg.MaxConnections * min(cfg.ReconnectReserve, 100) / 100
+}
+
+// isReconnecting 用户是否刚断线重连：在线状态缓存中仍在线，或仍有未清理的连接记录
+func (s *Service) isReconnecting(ctx context.Context, userID int64) bool {
+	if s.presence.Enabled() {
+		online, err := s.presence.Online(ctx, []int64{userID})
+		return err == nil && online[userID]
+	}
+	return s.HasActiveConnection(ctx, userID)
+}
+
+// rejectConnection 记录被拒绝的握手
+func (s *Service) rejectConnection(reason string) error {
+	s.rejected.Add(1)
+	connectionsRejected.WithLabelValues(reason).Inc()
+	return ErrCapacityFull
+}
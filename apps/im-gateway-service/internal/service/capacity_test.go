@@ -0,0 +1,77 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"goim-social/pkg/config"
+	"goim-social/pkg/httpx"
+)
+
+// newCapacityService 创建最多maxConns个连接、不预留重连容量的服务
+func newCapacityService(maxConns int) *Service {
+	cfg := &config.Config{}
+	cfg.Connect.Connection.MaxConnections = maxConns
+	return &Service{
+		config:  cfg,
+		connMgr: NewConnectionManager(nil, cfg),
+		admins:  map[int64]bool{1: true},
+	}
+}
+
+// TestAdmitConnectionConcurrent 并发握手通过检查的数量不超过最大连接数，release后容量可再次使用
+func TestAdmitConnectionConcurrent(t *testing.T) {
+	const maxConns = 10
+	s := newCapacityService(maxConns)
+
+	var admitted atomic.Int32
+	releases := make(chan func(), 100)
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func(userID int64) {
+			defer wg.Done()
+			release, err := s.AdmitConnection(context.Background(), userID)
+			if err == nil {
+				admitted.Add(1)
+				releases <- release
+			} else if err != ErrCapacityFull {
+				t.Errorf("AdmitConnection(%d) = %v, want ErrCapacityFull", userID, err)
+			}
+		}(int64(i + 1))
+	}
+	wg.Wait()
+	close(releases)
+
+	if got := admitted.Load(); got != maxConns {
+		t.Fatalf("admitted %d handshakes, want %d", got, maxConns)
+	}
+	if _, err := s.AdmitConnection(context.Background(), 1000); err != ErrCapacityFull {
+		t.Errorf("AdmitConnection at capacity = %v, want ErrCapacityFull", err)
+	}
+
+	release := <-releases
+	release()
+	release()
+	if _, err := s.AdmitConnection(context.Background(), 1000); err != nil {
+		t.Errorf("AdmitConnection after release = %v, want admitted", err)
+	}
+	if _, err := s.AdmitConnection(context.Background(), 1001); err != ErrCapacityFull {
+		t.Errorf("repeated release freed more than one slot: %v", err)
+	}
+}
+
+// TestCapacityStatsAdminOnly 只有配置的管理员能查询连接容量
+func TestCapacityStatsAdminOnly(t *testing.T) {
+	s := newCapacityService(10)
+	if _, err := s.CapacityStats(2); httpx.StatusOf(err) != http.StatusForbidden {
+		t.Errorf("CapacityStats by non-admin = %v, want permission denied", err)
+	}
+	stats, err := s.CapacityStats(1)
+	if err != nil || stats.Max != 10 {
+		t.Errorf("CapacityStats by admin = %+v, %v, want max 10", stats, err)
+	}
+}
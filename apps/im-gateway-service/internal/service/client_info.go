@@ -0,0 +1,92 @@
+package service
+
+import (
+	"strconv"
+	"strings"
+
+	"goim-social/apps/im-gateway-service/internal/model"
+)
+
+// platformAliases 客户端常见的平台写法，统一为标准平台名
+var platformAliases = map[string]string{
+	"ios":      model.PlatformIOS,
+	"iphone":   model.PlatformIOS,
+	"ipad":     model.PlatformIOS,
+	"android":  model.PlatformAndroid,
+	"web":      model.PlatformWeb,
+	"h5":       model.PlatformWeb,
+	"browser":  model.PlatformWeb,
+	"desktop":  model.PlatformDesktop,
+	"windows":  model.PlatformDesktop,
+	"mac":      model.PlatformDesktop,
+	"macos":    model.PlatformDesktop,
+	"linux":    model.PlatformDesktop,
+	"electron": model.PlatformDesktop,
+}
+
+// ParseClientInfo 解析并规范化握手时上报的平台和版本，平台缺失或无法识别、版本无法解析时使用默认值
+// 未上报版本的客户端（包括早于版本上报的客户端）版本保留为空
+func ParseClientInfo(platform, appVersion string) model.ClientInfo {
+	client := model.ClientInfo{Platform: normalizePlatform(platform)}
+	if strings.TrimSpace(appVersion) != "" {
+		client.AppVersion = normalizeAppVersion(appVersion)
+	}
+	return client
+}
+
+// normalizePlatform 规范化平台名
+func normalizePlatform(platform string) string {
+	if p, ok := platformAliases[strings.ToLower(strings.TrimSpace(platform))]; ok {
+		return p
+	}
+	return model.PlatformUnknown
+}
+
+// normalizeAppVersion 规范化版本号为major.minor.patch，忽略v前缀和预发布、构建后缀
+func normalizeAppVersion(version string) string {
+	version = strings.TrimPrefix(strings.TrimPrefix(strings.TrimSpace(version), "v"), "V")
+	if i := strings.IndexAny(version, "-+"); i >= 0 {
+		version = version[:i]
+	}
+	if version == "" {
+		return model.DefaultAppVersion
+	}
+
+	parts := strings.Split(version, ".")
+	if len(parts) > 3 {
+		return model.DefaultAppVersion
+	}
+	normalized := []string{"0", "0", "0"}
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return model.DefaultAppVersion
+		}
+		normalized[i] = strconv.Itoa(n)
+	}
+	return strings.Join(normalized, ".")
+}
+
+// compareAppVersion 比较两个规范化后的版本号，a<b返回负数，相等返回0，a>b返回正数
+func compareAppVersion(a, b string) int {
+	pa := strings.Split(normalizeAppVersion(a), ".")
+	pb := strings.Split(normalizeAppVersion(b), ".")
+	for i := 0; i < 3; i++ {
+		na, _ := strconv.Atoi(pa[i])
+		nb, _ := strconv.Atoi(pb[i])
+		if na != nb {
+			return na - nb
+		}
+	}
+	return 0
+}
+
+// SupportsFeature 客户端版本是否支持指定功能，未登记最低版本的功能所有客户端均支持
+// 未上报版本的客户端视为支持，避免不上报版本的现有客户端被静默关闭功能
+func SupportsFeature(appVersion, feature string) bool {
+	minVersion, ok := model.FeatureMinAppVersion[feature]
+	if !ok || strings.TrimSpace(appVersion) == "" {
+		return true
+	}
+	return compareAppVersion(appVersion, minVersion) >= 0
+}
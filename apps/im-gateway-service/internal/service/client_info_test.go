@@ -0,0 +1,51 @@
+package service
+
+import (
+	"testing"
+
+	"goim-social/apps/im-gateway-service/internal/model"
+)
+
+// TestParseClientInfo 平台按别名规范化，版本规范化为major.minor.patch，未上报的版本保留为空
+func TestParseClientInfo(t *testing.T) {
+	cases := []struct {
+		platform     string
+		appVersion   string
+		wantPlatform string
+		wantVersion  string
+	}{
+		{platform: "iPhone", appVersion: "v1.2", wantPlatform: model.PlatformIOS, wantVersion: "1.2.0"},
+		{platform: "electron", appVersion: "2.0.1-beta+42", wantPlatform: model.PlatformDesktop, wantVersion: "2.0.1"},
+		{platform: "", appVersion: "", wantPlatform: model.PlatformUnknown, wantVersion: ""},
+		{platform: "web", appVersion: "  ", wantPlatform: model.PlatformWeb, wantVersion: ""},
+		{platform: "tv", appVersion: "latest", wantPlatform: model.PlatformUnknown, wantVersion: model.DefaultAppVersion},
+		{platform: "android", appVersion: "1.2.3.4", wantPlatform: model.PlatformAndroid, wantVersion: model.DefaultAppVersion},
+	}
+	for _, tc := range cases {
+		got := ParseClientInfo(tc.platform, tc.appVersion)
+		if got.Platform != tc.wantPlatform || got.AppVersion != tc.wantVersion {
+			t.Errorf("ParseClientInfo(%q, %q) = %+v, want %s/%q", tc.platform, tc.appVersion, got, tc.wantPlatform, tc.wantVersion)
+		}
+	}
+}
+
+// TestSupportsFeature 低于最低版本的客户端不支持，未上报版本和未登记的功能视为支持
+func TestSupportsFeature(t *testing.T) {
+	cases := []struct {
+		appVersion string
+		feature    string
+		want       bool
+	}{
+		{appVersion: "1.1.0", feature: model.FeatureFriendOnline, want: true},
+		{appVersion: "1.10.0", feature: model.FeatureFriendOnline, want: true},
+		{appVersion: "1.0.9", feature: model.FeatureFriendOnline, want: false},
+		{appVersion: model.DefaultAppVersion, feature: model.FeatureFriendOnline, want: false},
+		{appVersion: "", feature: model.FeatureFriendOnline, want: true},
+		{appVersion: "0.1.0", feature: "unregistered", want: true},
+	}
+	for _, tc := range cases {
+		if got := SupportsFeature(tc.appVersion, tc.feature); got != tc.want {
+			t.Errorf("SupportsFeature(%q, %q) = %v, want %v", tc.appVersion, tc.feature, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,165 @@
+package service
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/gorilla/websocket"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	"goim-social/api/rest"
+	"goim-social/apps/im-gateway-service/internal/model"
+)
+
+var (
+	// JSON编解码使用proto字段名，与protobuf编码保持相同的消息语义
+	wsJSONMarshaler   = protojson.MarshalOptions{UseProtoNames: true}
+	wsJSONUnmarshaler = protojson.UnmarshalOptions{DiscardUnknown: true}
+
+	// wsWriteLocks 每个连接的写锁，推送和发送确认来自不同goroutine，而连接不支持并发写
+	wsWriteLocks sync.Map // *websocket.Conn -> *sync.Mutex
+)
+
+// DecodeWSMessage 按连接协商的子协议解析客户端上行帧
+func DecodeWSMessage(subprotocol string, data []byte, msg *rest.WSMessage) error {
+	if subprotocol == model.SubprotocolJSON {
+		if err := wsJSONUnmarshaler.Unmarshal(data, msg); err != nil {
+			return fmt.Errorf("解析JSON消息失败: %v", err)
+		}
+		return nil
+	}
+
+	if err := proto.Unmarshal(data, msg); err != nil {
+		return fmt.Errorf("解析protobuf消息失败: %v", err)
+	}
+	return nil
+}
+
+// DecodeWSFrame 解析客户端上行帧，优先按信封解析，不含信封负载时按旧版裸WSMessage兼容解析
+func DecodeWSFrame(subprotocol string, data []byte) (*rest.WSEnvelope, error) {
+	var envelope rest.WSEnvelope
+	var err error
+	if subprotocol == model.SubprotocolJSON {
+		err = wsJSONUnmarshaler.Unmarshal(data, &envelope)
+	} else {
+		err = proto.Unmarshal(data, &envelope)
+	}
+
+	// 信封字段号与WSMessage不重叠，旧版帧解析为信封时负载为空
+	if err != nil || envelope.Payload == nil {
+		var msg rest.WSMessage
+		if err := DecodeWSMessage(subprotocol, data, &msg); err != nil {
+			return nil, err
+		}
+		return legacyEnvelope(&msg)
+	}
+
+	if envelope.Version == 0 || envelope.Version > model.WSEnvelopeVersion {
+		return nil, fmt.Errorf("不支持的信封协议版本: %d", envelope.Version)
+	}
+	return &envelope, nil
+}
+
+// legacyEnvelope 将旧版裸WSMessage按message_type转换为信封
+func legacyEnvelope(msg *rest.WSMessage) (*rest.WSEnvelope, error) {
+	envelope := &rest.WSEnvelope{Version: model.WSEnvelopeVersion}
+
+	control := &rest.ControlFrame{
+		UserId:    msg.From,
+		MessageId: msg.MessageId,
+		AckId:     msg.AckId,
+		Timestamp: msg.Timestamp,
+	}
+	switch msg.MessageType {
+	case model.LegacyMessageTypeText:
+		envelope.Payload = &rest.WSEnvelope_Chat{Chat: msg}
+		return envelope, nil
+	case model.LegacyMessageTypeHeartbeat:
+		control.Op = rest.ControlOp_CONTROL_OP_HEARTBEAT
+		control.ConnId = msg.Content
+	case model.LegacyMessageTypeConnection:
+		control.Op = rest.ControlOp_CONTROL_OP_CONNECTION
+	case model.LegacyMessageTypeAck:
+		control.Op = rest.ControlOp_CONTROL_OP_ACK
+	case model.LegacyMessageTypePresence:
+		control.Op = rest.ControlOp_CONTROL_OP_PRESENCE
+	default:
+		return nil, fmt.Errorf("未知消息类型: %d", msg.MessageType)
+	}
+
+	envelope.Payload = &rest.WSEnvelope_Control{Control: control}
+	return envelope, nil
+}
+
+// EncodeWSMessage 按子协议编码下行消息，返回WebSocket帧类型和数据
+func EncodeWSMessage(subprotocol string, msg *rest.WSMessage) (int, []byte, error) {
+	if subprotocol == model.SubprotocolJSON {
+		data, err := wsJSONMarshaler.Marshal(msg)
+		if err != nil {
+			return 0, nil, fmt.Errorf("序列化JSON消息失败: %v", err)
+		}
+		return websocket.TextMessage, data, nil
+	}
+
+	data, err := proto.Marshal(msg)
+	if err != nil {
+		return 0, nil, fmt.Errorf("序列化protobuf消息失败: %v", err)
+	}
+	return websocket.BinaryMessage, data, nil
+}
+
+// EncodeWSEnvelope 按子协议编码下行信封帧，返回WebSocket帧类型和数据
+func EncodeWSEnvelope(subprotocol string, envelope *rest.WSEnvelope) (int, []byte, error) {
+	if subprotocol == model.SubprotocolJSON {
+		data, err := wsJSONMarshaler.Marshal(envelope)
+		if err != nil {
+			return 0, nil, fmt.Errorf("序列化JSON信封失败: %v", err)
+		}
+		return websocket.TextMessage, data, nil
+	}
+
+	data, err := proto.Marshal(envelope)
+	if err != nil {
+		return 0, nil, fmt.Errorf("序列化protobuf信封失败: %v", err)
+	}
+	return websocket.BinaryMessage, data, nil
+}
+
+// writeWSMessage 按连接协商的子协议向客户端写入消息
+func writeWSMessage(conn *websocket.Conn, msg *rest.WSMessage) error {
+	messageType, data, err := EncodeWSMessage(conn.Subprotocol(), msg)
+	if err != nil {
+		return err
+	}
+	return writeWSFrame(conn, messageType, data)
+}
+
+// writeWSEnvelope 按连接协商的子协议向客户端写入信封帧
+func writeWSEnvelope(conn *websocket.Conn, envelope *rest.WSEnvelope) error {
+	messageType, data, err := EncodeWSEnvelope(conn.Subprotocol(), envelope)
+	if err != nil {
+		return err
+	}
+	return writeWSFrame(conn, messageType, data)
+}
+
+// writeWSFrame 持有连接写锁写入一帧
+func writeWSFrame(conn *websocket.Conn, messageType int, data []byte) error {
+	lock, _ := wsWriteLocks.LoadOrStore(conn, &sync.Mutex{})
+	mu := lock.(*sync.Mutex)
+	mu.Lock()
+	defer mu.Unlock()
+	return conn.WriteMessage(messageType, data)
+}
+
+// WriteWSPong 持有连接写锁回复pong，避免与消息和确认帧并发写入同一连接
+func WriteWSPong(conn *websocket.Conn, appData string) error {
+	return writeWSFrame(conn, websocket.PongMessage, []byte(appData))
+}
+
+// ReleaseWSConn 连接关闭后释放其发送队列和写锁
+func ReleaseWSConn(conn *websocket.Conn) {
+	releaseSendQueue(conn)
+	wsWriteLocks.Delete(conn)
+}
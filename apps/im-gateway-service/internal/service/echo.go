@@ -0,0 +1,88 @@
+package service
+
+import (
+	"context"
+	"encoding/base64"
+	"log"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"google.golang.org/protobuf/proto"
+
+	"goim-social/api/rest"
+	"goim-social/apps/im-gateway-service/internal/model"
+	"goim-social/pkg/presence"
+)
+
+// ==================== 发送方消息回显 ====================
+// 消息被Logic服务受理后，网关把带服务端消息ID的副本回显给发送方的设备，用于多设备同步。
+// 默认只回显到其他设备：发起发送的连接已渲染本地乐观消息并通过发送确认拿到消息ID，再回显会重复显示；
+// all_devices模式同时回显到发起连接，供不做乐观渲染的客户端使用。
+// 每个网关实例上同一用户只保留一个连接，其他设备即发送方在其他网关实例上的连接
+
+// echoToSender 将受理的消息回显给发送方的设备，失败只记录日志
+func (s *Service) echoToSender(ctx context.Context, conn *websocket.Conn, msg *rest.WSMessage, resp *rest.SendLogicMessageResponse) {
+	echo := &rest.WSMessage{
+		MessageId:   resp.MessageId,
+		From:        msg.From,
+		To:          msg.To,
+		GroupId:     msg.GroupId,
+		Content:     msg.Content,
+		MessageType: msg.MessageType,
+		Timestamp:   resp.ServerTime,
+		SenderSeq:   resp.SenderSeq,
+		ClientMsgId: msg.ClientMsgId,
+	}
+
+	if s.echoMode() == model.EchoModeAllDevices {
+		if err := writeWSMessage(conn, echo); err != nil {
+			log.Printf("回显消息到发起连接失败: From=%d, MessageID=%d, Error=%v", msg.From, resp.MessageId, err)
+		}
+	}
+
+	keys, err := presence.ConnectionKeys(ctx, s.redis, msg.From)
+	if err != nil || len(keys) == 0 {
+		return
+	}
+
+	servers := make(map[string]bool)
+	for _, key := range keys {
+		connInfo, err := s.redis.HGetAll(ctx, key)
+		if err != nil {
+			continue
+		}
+		// 本实例上的连接就是发起发送的连接
+		if serverID := connInfo["serverID"]; serverID != "" && serverID != s.instanceID {
+			servers[serverID] = true
+		}
+	}
+
+	now := time.Now().Unix()
+	for serverID := range servers {
+		gatewayMsg := &rest.GatewayMessage{
+			Type:       model.GatewayMessageTypeEcho,
+			Message:    echo,
+			TargetUser: msg.From,
+			Timestamp:  now,
+			Source:     s.instanceID,
+			Seq:        s.forwardSeq.Add(1),
+		}
+
+		payloadBytes, err := proto.Marshal(gatewayMsg)
+		if err != nil {
+			continue
+		}
+		channel := "connect_forward:" + serverID
+		if err := s.redis.Publish(ctx, channel, base64.StdEncoding.EncodeToString(payloadBytes)); err != nil {
+			log.Printf("回显消息到其他设备失败: From=%d, MessageID=%d, Server=%s, Error=%v", msg.From, resp.MessageId, serverID, err)
+		}
+	}
+}
+
+// echoMode 发送方消息回显模式，未配置或无法识别时只回显到其他设备
+func (s *Service) echoMode() string {
+	if s.config.Connect.Echo.Mode == model.EchoModeAllDevices {
+		return model.EchoModeAllDevices
+	}
+	return model.EchoModeOtherDevices
+}
@@ -0,0 +1,81 @@
+package service
+
+import (
+	"sync"
+	"time"
+
+	"goim-social/api/rest"
+	"goim-social/apps/im-gateway-service/internal/model"
+)
+
+// forwardSource 单个发送方的序号状态
+type forwardSource struct {
+	lastSeq  int64              // 已收到的最大序号
+	seen     map[int64]struct{} // 窗口内已收到的序号
+	lastSeen time.Time          // 最近收到消息的时间
+}
+
+// forwardDeduper 跨节点转发消息去重器
+// Redis发布/订阅在发送方并发发布时不保证顺序，也可能因重试重复投递，
+// 按来源记录窗口内已收到的序号：重复序号丢弃，落后窗口之外的序号视为过期丢弃，窗口内的乱序消息正常投递
+type forwardDeduper struct {
+	sources map[string]*forwardSource
+	mutex   sync.Mutex
+}
+
+// newForwardDeduper 创建转发去重器
+func newForwardDeduper() *forwardDeduper {
+	return &forwardDeduper{
+		sources: make(map[string]*forwardSource),
+	}
+}
+
+// accept 判断转发消息是否应当投递，未携带来源或序号的旧版本消息直接投递
+func (d *forwardDeduper) accept(msg *rest.GatewayMessage) (bool, string) {
+	if msg.Source == "" || msg.Seq <= 0 {
+		return true, ""
+	}
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	now := time.Now()
+	src, exists := d.sources[msg.Source]
+	if !exists {
+		d.evictIdleSources(now)
+		src = &forwardSource{seen: make(map[int64]struct{})}
+		d.sources[msg.Source] = src
+	}
+	src.lastSeen = now
+
+	if exists && msg.Seq <= src.lastSeq-model.ForwardSeqWindow {
+		return false, "过期"
+	}
+	if _, dup := src.seen[msg.Seq]; dup {
+		return false, "重复"
+	}
+
+	src.seen[msg.Seq] = struct{}{}
+	if msg.Seq > src.lastSeq {
+		src.lastSeq = msg.Seq
+	}
+	// 窗口外的序号不会再被查询，累积到两倍窗口时统一清理
+	if len(src.seen) > 2*model.ForwardSeqWindow {
+		for seq := range src.seen {
+			if seq <= src.lastSeq-model.ForwardSeqWindow {
+				delete(src.seen, seq)
+			}
+		}
+	}
+	return true, ""
+}
+
+// evictIdleSources 清理长时间没有消息的来源，调用方需持有锁
+func (d *forwardDeduper) evictIdleSources(now time.Time) {
+	expire := time.Duration(model.ForwardSourceExpire) * time.Second
+	for source, src := range d.sources {
+		if now.Sub(src.lastSeen) > expire {
+			delete(d.sources, source)
+		}
+	}
+}
@@ -0,0 +1,212 @@
+package service
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"log"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/protobuf/proto"
+
+	"goim-social/api/rest"
+	"goim-social/apps/im-gateway-service/internal/model"
+	"goim-social/pkg/middleware"
+	"goim-social/pkg/presence"
+)
+
+// ==================== 好友上线提醒 ====================
+// 用户首个设备上线时，向开启了提醒的在线好友推送一条上线提醒，复用connect_forward转发通道，
+// 与在线状态查询相互独立。下线后在防抖时间内重连、或多设备先后上线都不会重复提醒
+
+// initSocialClient 初始化Social服务客户端，用于查询好友列表
+func (s *Service) initSocialClient() error {
+	socialAddr := fmt.Sprintf("%s:%d", s.config.Services.SocialService.Host, s.config.Services.SocialService.Port)
+
+	conn, err := grpc.NewClient(socialAddr, grpc.WithTransportCredentials(insecure.NewCredentials()), middleware.DeadlineDialOption(s.config.Deadline))
+	if err != nil {
+		return fmt.Errorf("连接Social服务失败: %v", err)
+	}
+
+	s.socialClient = rest.NewSocialServiceClient(conn)
+
+	log.Printf("Social服务客户端初始化成功，地址: %s", socialAddr)
+	return nil
+}
+
+// friendOnlineDebounce 好友上线提醒防抖时间
+func (s *Service) friendOnlineDebounce() time.Duration {
+	debounce := s.config.Connect.FriendOnline.Debounce
+	if debounce <= 0 {
+		debounce = model.DefaultFriendOnlineDebounce
+	}
+	return time.Duration(debounce) * time.Second
+}
+
+// HasActiveConnection 用户当前是否有任意设备在线，建立新连接前调用以判断是否为首个设备
+func (s *Service) HasActiveConnection(ctx context.Context, userID int64) bool {
+	keys, err := presence.ConnectionKeys(ctx, s.redis, userID)
+	return err == nil && len(keys) > 0
+}
+
+// NotifyFriendsOnline 用户首个设备上线时向在线好友推送上线提醒
+// 隐身用户不通知；下线后防抖时间内重连不通知；接收方未开启提醒或屏蔽了该好友时不通知
+func (s *Service) NotifyFriendsOnline(ctx context.Context, userID int64) {
+	if !s.config.Connect.FriendOnline.Enabled || s.socialClient == nil {
+		return
+	}
+
+	hidden, err := s.redis.SIsMember(ctx, model.FriendOnlineHiddenKey, userID)
+	if err != nil || hidden {
+		return
+	}
+
+	// 短暂断线重连视为持续在线
+	offlineKey := fmt.Sprintf("%s:%d", model.FriendOnlineLastOfflineKeyPrefix, userID)
+	if n, err := s.redis.Exists(ctx, offlineKey); err != nil || n > 0 {
+		return
+	}
+
+	// 多个设备在不同实例上同时上线时只提醒一次
+	notifiedKey := fmt.Sprintf("%s:%d", model.FriendOnlineNotifiedKeyPrefix, userID)
+	first, err := s.redis.SetNX(ctx, notifiedKey, time.Now().Unix(), s.friendOnlineDebounce())
+	if err != nil || !first {
+		return
+	}
+
+	resp, err := s.socialClient.GetUserSocialInfo(ctx, &rest.GetUserSocialInfoRequest{UserId: userID})
+	if err != nil || !resp.Success || resp.SocialInfo == nil {
+		log.Printf("获取用户 %d 好友列表失败，跳过上线提醒: %v", userID, err)
+		return
+	}
+
+	notified := 0
+	for _, friendID := range resp.SocialInfo.FriendIds {
+		if !s.acceptsFriendOnline(ctx, friendID, userID) {
+			continue
+		}
+		if s.forwardFriendOnline(ctx, userID, friendID) {
+			notified++
+		}
+	}
+
+	if notified > 0 {
+		log.Printf("用户 %d 上线提醒已发送给 %d 个在线好友", userID, notified)
+	}
+}
+
+// acceptsFriendOnline 接收方是否接收该好友的上线提醒，设置读取失败时按不接收处理
+func (s *Service) acceptsFriendOnline(ctx context.Context, recipientID, friendID int64) bool {
+	optIn, err := s.redis.SIsMember(ctx, model.FriendOnlineOptInKey, recipientID)
+	if err != nil || !optIn {
+		return false
+	}
+
+	mutedKey := fmt.Sprintf("%s:%d", model.FriendOnlineMutedKeyPrefix, recipientID)
+	muted, err := s.redis.SIsMember(ctx, mutedKey, friendID)
+	return err == nil && !muted
+}
+
+// forwardFriendOnline 向接收方所在的每个网关实例转发上线提醒，接收方不在线或客户端版本不支持时不推送
+func (s *Service) forwardFriendOnline(ctx context.Context, userID, recipientID int64) bool {
+	keys, err := presence.ConnectionKeys(ctx, s.redis, recipientID)
+	if err != nil || len(keys) == 0 {
+		return false
+	}
+
+	servers := make(map[string]bool)
+	for _, key := range keys {
+		connInfo, err := s.redis.HGetAll(ctx, key)
+		if err != nil {
+			continue
+		}
+		// 旧版本客户端无法识别上线提醒消息类型，不推送
+		if !SupportsFeature(connInfo["appVersion"], model.FeatureFriendOnline) {
+			continue
+		}
+		if serverID := connInfo["serverID"]; serverID != "" {
+			servers[serverID] = true
+		}
+	}
+
+	now := time.Now().Unix()
+	sent := false
+	for serverID := range servers {
+		gatewayMsg := &rest.GatewayMessage{
+			Type: "push_message",
+			Message: &rest.WSMessage{
+				From:        userID,
+				To:          recipientID,
+				MessageType: model.MessageTypeFriendOnline,
+				Timestamp:   now,
+			},
+			TargetUser: recipientID,
+			Timestamp:  now,
+			Source:     s.instanceID,
+			Seq:        s.forwardSeq.Add(1),
+		}
+
+		payloadBytes, err := proto.Marshal(gatewayMsg)
+		if err != nil {
+			continue
+		}
+		channel := "connect_forward:" + serverID
+		if err := s.redis.Publish(ctx, channel, base64.StdEncoding.EncodeToString(payloadBytes)); err != nil {
+			log.Printf("转发上线提醒失败: UserID=%d, Recipient=%d, Server=%s, err=%v", userID, recipientID, serverID, err)
+			continue
+		}
+		sent = true
+	}
+	return sent
+}
+
+// markOffline 用户全部设备下线时记录下线标记，防抖时间内重连不再提醒好友
+func (s *Service) markOffline(ctx context.Context, userID int64) {
+	if !s.config.Connect.FriendOnline.Enabled || s.HasActiveConnection(ctx, userID) {
+		return
+	}
+
+	offlineKey := fmt.Sprintf("%s:%d", model.FriendOnlineLastOfflineKeyPrefix, userID)
+	if err := s.redis.Set(ctx, offlineKey, time.Now().Unix(), s.friendOnlineDebounce()); err != nil {
+		log.Printf("记录用户 %d 下线标记失败: %v", userID, err)
+	}
+}
+
+// UpdateFriendOnlineSettings 更新好友上线提醒设置
+// notify为是否接收好友的上线提醒，hidden为上线时是否对好友隐身
+func (s *Service) UpdateFriendOnlineSettings(ctx context.Context, userID int64, notify, hidden bool) error {
+	if userID <= 0 {
+		return fmt.Errorf("无效的用户ID")
+	}
+
+	if err := s.toggleSetMember(ctx, model.FriendOnlineOptInKey, userID, notify); err != nil {
+		return fmt.Errorf("更新上线提醒设置失败: %v", err)
+	}
+	if err := s.toggleSetMember(ctx, model.FriendOnlineHiddenKey, userID, hidden); err != nil {
+		return fmt.Errorf("更新隐身设置失败: %v", err)
+	}
+	return nil
+}
+
+// MuteFriendOnline 屏蔽或取消屏蔽指定好友的上线提醒
+func (s *Service) MuteFriendOnline(ctx context.Context, userID, friendID int64, muted bool) error {
+	if userID <= 0 || friendID <= 0 || userID == friendID {
+		return fmt.Errorf("无效的用户ID或好友ID")
+	}
+
+	mutedKey := fmt.Sprintf("%s:%d", model.FriendOnlineMutedKeyPrefix, userID)
+	if err := s.toggleSetMember(ctx, mutedKey, friendID, muted); err != nil {
+		return fmt.Errorf("更新好友上线提醒屏蔽设置失败: %v", err)
+	}
+	return nil
+}
+
+// toggleSetMember 按开关将成员加入或移出集合
+func (s *Service) toggleSetMember(ctx context.Context, key string, member int64, on bool) error {
+	if on {
+		return s.redis.SAdd(ctx, key, member)
+	}
+	return s.redis.SRem(ctx, key, member)
+}
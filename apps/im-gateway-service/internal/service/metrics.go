@@ -0,0 +1,145 @@
+package service
+
+import (
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"goim-social/api/rest"
+)
+
+// 推送来源
+const (
+	pushSourceLocal   = "local"   // Logic服务直接路由到本实例的消息
+	pushSourceForward = "forward" // 经connect_forward跨节点转发到本实例的消息
+)
+
+// 推送结果
+const (
+	pushResultSuccess = "success"
+	pushResultFailed  = "failed"
+	pushResultFull    = "queue_full" // 通道已满，未入队
+)
+
+// 推送丢弃原因
+const (
+	dropReasonDecode     = "decode_error"  // 消息解码失败
+	dropReasonInvalid    = "invalid"       // 消息类型未知或缺少内容
+	dropReasonDuplicate  = "duplicate"     // 重复或过期的转发消息
+	dropReasonOffline    = "no_connection" // 用户在本实例没有活跃连接
+	dropReasonWriteError = "write_error"   // 写入WebSocket连接失败
+	dropReasonQueueFull  = "queue_full"    // 连接的发送队列对应通道已满
+)
+
+// 上行帧防重放拦截原因
+const (
+	replayReasonStale     = "stale"                 // 时间戳超出窗口
+	replayReasonDuplicate = "duplicate"             // 窗口内重复的client_msg_id
+	replayReasonMissing   = "missing_client_msg_id" // 要求client_msg_id时未携带client_msg_id或时间戳
+)
+
+// 连接握手被拒绝的原因
+const (
+	capacityReasonFull     = "full"     // 达到最大连接数
+	capacityReasonReserved = "reserved" // 剩余容量为重连用户预留
+)
+
+// 推送链路指标，通过HTTP服务的/metrics暴露，实例由抓取目标区分
+var (
+	pushLatency = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "im_gateway_push_latency_seconds",
+		Help:    "消息从到达本实例到写入WebSocket连接的耗时，包括按发送者序号重排的等待",
+		Buckets: []float64{.001, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+	}, []string{"source"})
+
+	pushTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "im_gateway_push_total",
+		Help: "推送到本地连接的消息数，按来源和结果区分",
+	}, []string{"source", "result"})
+
+	pushDropped = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "im_gateway_push_dropped_total",
+		Help: "未能推送的消息数，按原因区分",
+	}, []string{"reason"})
+
+	pushQueueDepth = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "im_gateway_push_queue_depth",
+		Help: "本实例所有连接的发送队列中排队的消息数，按优先级通道区分",
+	}, []string{"lane"})
+
+	pushQueueWait = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "im_gateway_push_queue_wait_seconds",
+		Help:    "消息在连接发送队列中的排队耗时，按优先级通道区分",
+		Buckets: []float64{.0005, .001, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+	}, []string{"lane"})
+
+	pushLaneTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "im_gateway_push_lane_total",
+		Help: "经发送队列推送的消息数，按优先级通道和结果区分",
+	}, []string{"lane", "result"})
+
+	connectionsRejected = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "im_gateway_connections_rejected_total",
+		Help: "因本实例连接容量不足被拒绝的WebSocket握手数，按原因区分",
+	}, []string{"reason"})
+
+	replayRejected = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "im_gateway_replay_rejected_total",
+		Help: "被防重放校验拦截的上行帧数，按原因区分，重复帧中包括客户端的正常重试",
+	}, []string{"reason"})
+)
+
+// registerConnectionGauge 注册本地连接数和最大连接数指标，每个进程只创建一个Service
+func registerConnectionGauge(cm *ConnectionManager, maxConnections int) {
+	promauto.NewGaugeFunc(prometheus.GaugeOpts{
+		Name: "im_gateway_local_connections",
+		Help: "本实例当前的WebSocket连接数",
+	}, func() float64 {
+		return float64(cm.LocalConnectionCount())
+	})
+	promauto.NewGaugeFunc(prometheus.GaugeOpts{
+		Name: "im_gateway_max_connections",
+		Help: "本实例允许的最大WebSocket连接数，0表示不限制",
+	}, func() float64 {
+		return float64(maxConnections)
+	})
+}
+
+// pushStart 消息到达本实例的时间和来源
+type pushStart struct {
+	at     time.Time
+	source string
+}
+
+// pushTracker 记录进入推送链路的消息，投递时计算推送延迟
+// 消息经过重排缓冲区后异步投递，按消息指针关联到达记录
+type pushTracker struct {
+	starts sync.Map // *rest.WSMessage -> pushStart
+}
+
+// begin 记录消息到达
+func (t *pushTracker) begin(msg *rest.WSMessage, source string) {
+	t.starts.Store(msg, pushStart{at: time.Now(), source: source})
+}
+
+// finish 记录投递结果，失败时按原因计入丢弃数
+func (t *pushTracker) finish(msg *rest.WSMessage, dropReason string) {
+	source := pushSourceLocal
+	value, ok := t.starts.LoadAndDelete(msg)
+	if ok {
+		start := value.(pushStart)
+		source = start.source
+		if dropReason == "" {
+			pushLatency.WithLabelValues(source).Observe(time.Since(start.at).Seconds())
+		}
+	}
+
+	if dropReason == "" {
+		pushTotal.WithLabelValues(source, pushResultSuccess).Inc()
+		return
+	}
+	pushTotal.WithLabelValues(source, pushResultFailed).Inc()
+	pushDropped.WithLabelValues(dropReason).Inc()
+}
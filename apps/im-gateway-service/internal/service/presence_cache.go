@@ -0,0 +1,60 @@
+package service
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// refreshPresence 定期刷新本实例连接用户的在线状态缓存
+// 在线标记带短TTL，不依赖客户端心跳，浏览器等不主动发送ping的客户端也能保持在线；实例退出后不再刷新，在线标记自然失效
+func (s *Service) refreshPresence() {
+	if !s.presence.Enabled() {
+		return
+	}
+
+	ticker := time.NewTicker(s.presence.RefreshInterval())
+	defer ticker.Stop()
+
+	for range ticker.C {
+		userIDs := s.connMgr.LocalUserIDs()
+		if len(userIDs) == 0 {
+			continue
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), s.presence.RefreshInterval())
+		if err := s.presence.MarkOnline(ctx, userIDs...); err != nil {
+			log.Printf("刷新在线状态缓存失败: Users=%d, Error=%v", len(userIDs), err)
+		}
+		s.clearDisconnectedPresence(ctx, userIDs)
+		cancel()
+	}
+}
+
+// clearDisconnectedPresence 刷新期间断开的用户可能在断开时清除在线标记之后又被本轮刷新写回，
+// 刷新后重新检查本轮的用户，已不在本实例且没有其他活跃连接的重新标记下线，避免在TTL内显示为在线。
+// 断开时先移除本地连接再清除在线标记，因此这里未发现断开的用户，其清除一定发生在本轮写入之后
+func (s *Service) clearDisconnectedPresence(ctx context.Context, refreshed []int64) {
+	local := make(map[int64]bool)
+	for _, userID := range s.connMgr.LocalUserIDs() {
+		local[userID] = true
+	}
+	for _, userID := range refreshed {
+		if !local[userID] && !s.HasActiveConnection(ctx, userID) {
+			s.markPresenceOffline(ctx, userID)
+		}
+	}
+}
+
+// markPresenceOnline 连接建立或心跳时标记用户在线，失败只记录日志，下一轮定期刷新时补写
+func (s *Service) markPresenceOnline(ctx context.Context, userID int64) {
+	if err := s.presence.MarkOnline(ctx, userID); err != nil {
+		log.Printf("标记用户 %d 在线失败: %v", userID, err)
+	}
+}
+
+// markPresenceOffline 用户全部设备下线时清除在线标记并记录最近在线时间
+func (s *Service) markPresenceOffline(ctx context.Context, userID int64) {
+	if err := s.presence.MarkOffline(ctx, userID); err != nil {
+		log.Printf("标记用户 %d 下线失败: %v", userID, err)
+	}
+}
@@ -0,0 +1,368 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	goredis "github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+
+	"goim-social/api/rest"
+	"goim-social/apps/im-gateway-service/internal/model"
+	"goim-social/pkg/httpx"
+	"goim-social/pkg/webhook"
+)
+
+// ==================== 在线状态回调 ====================
+// 机器人等外部集成以自己的账号订阅好友的上下线事件，不必轮询在线状态查询接口。
+// 用户首个设备上线、全部设备下线时，网关向订阅了该用户的回调地址推送签名事件，失败时按指数退避重试。
+// 只能订阅好友，回调前会再次确认好友关系；对好友隐身或在线状态隐身的用户不回调。
+// 只有配置的集成账号可以订阅；回调地址不能指向内网，订阅时解析校验，回调连接时再次校验防止DNS重绑定
+
+// presenceHookClient 回调HTTP客户端，不跟随重定向、不走代理，只连接公网地址，避免回调被引导到内部服务
+var presenceHookClient = &http.Client{
+	Transport: &http.Transport{
+		DialContext:         webhook.DialContext,
+		TLSHandshakeTimeout: 10 * time.Second,
+		MaxIdleConnsPerHost: 2,
+		IdleConnTimeout:     90 * time.Second,
+	},
+	CheckRedirect: func(req *http.Request, via []*http.Request) error {
+		return http.ErrUseLastResponse
+	},
+}
+
+// SubscribePresence 创建在线状态回调订阅，返回的订阅中包含签名密钥，之后不再返回
+func (s *Service) SubscribePresence(ctx context.Context, ownerID int64, callbackURL string, userIDs []int64) (*model.PresenceSubscription, error) {
+	cfg := s.config.Connect.PresenceHook
+	if !cfg.Enabled || s.socialClient == nil {
+		return nil, httpx.Unavailable(fmt.Errorf("在线状态回调未启用"))
+	}
+	if ownerID <= 0 {
+		return nil, httpx.InvalidArgument(fmt.Errorf("无效的用户ID"))
+	}
+	if !s.presenceHookIntegration(ownerID) {
+		return nil, httpx.PermissionDenied(fmt.Errorf("该账号未开通在线状态回调"))
+	}
+	if err := s.validateCallbackURL(ctx, callbackURL); err != nil {
+		return nil, httpx.InvalidArgument(err)
+	}
+
+	targets := make([]int64, 0, len(userIDs))
+	seen := make(map[int64]bool, len(userIDs))
+	for _, userID := range userIDs {
+		if userID <= 0 || userID == ownerID || seen[userID] {
+			continue
+		}
+		seen[userID] = true
+		targets = append(targets, userID)
+	}
+	if len(targets) == 0 {
+		return nil, httpx.InvalidArgument(fmt.Errorf("订阅的用户不能为空"))
+	}
+	if maxUsers := s.presenceHookMaxUsers(); len(targets) > maxUsers {
+		return nil, httpx.InvalidArgument(fmt.Errorf("每个订阅最多关注%d个用户", maxUsers))
+	}
+
+	friends, err := s.friendIDSet(ctx, ownerID)
+	if err != nil {
+		return nil, httpx.Unavailable(fmt.Errorf("获取好友列表失败: %v", err))
+	}
+	for _, userID := range targets {
+		if !friends[userID] {
+			return nil, httpx.PermissionDenied(fmt.Errorf("只能订阅好友的在线状态: %d", userID))
+		}
+	}
+
+	ownerKey := fmt.Sprintf("%s:%d", model.PresenceHookOwnerKeyPrefix, ownerID)
+	existing, err := s.redis.SMembers(ctx, ownerKey)
+	if err != nil {
+		return nil, httpx.Unavailable(fmt.Errorf("查询订阅失败: %v", err))
+	}
+	if maxSubs := s.presenceHookMaxSubscriptions(); len(existing) >= maxSubs {
+		return nil, httpx.Conflict(fmt.Errorf("每个用户最多创建%d个订阅", maxSubs))
+	}
+
+	secret, err := webhook.NewSecret()
+	if err != nil {
+		return nil, fmt.Errorf("生成签名密钥失败: %v", err)
+	}
+	sub := &model.PresenceSubscription{
+		ID:          uuid.New().String(),
+		OwnerID:     ownerID,
+		CallbackURL: callbackURL,
+		Secret:      secret,
+		UserIDs:     targets,
+		CreatedAt:   time.Now().Unix(),
+	}
+	data, err := json.Marshal(sub)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.redis.Set(ctx, presenceHookKey(sub.ID), data, 0); err != nil {
+		return nil, httpx.Unavailable(fmt.Errorf("保存订阅失败: %v", err))
+	}
+	if err := s.redis.SAdd(ctx, ownerKey, sub.ID); err != nil {
+		s.redis.Del(ctx, presenceHookKey(sub.ID))
+		return nil, httpx.Unavailable(fmt.Errorf("保存订阅失败: %v", err))
+	}
+	for _, userID := range targets {
+		watchersKey := fmt.Sprintf("%s:%d", model.PresenceHookWatchersKeyPrefix, userID)
+		if err := s.redis.SAdd(ctx, watchersKey, sub.ID); err != nil {
+			log.Printf("登记在线状态订阅失败: Subscription=%s, UserID=%d, err=%v", sub.ID, userID, err)
+		}
+	}
+
+	log.Printf("用户 %d 创建在线状态回调订阅 %s，关注 %d 个用户", ownerID, sub.ID, len(targets))
+	return sub, nil
+}
+
+// UnsubscribePresence 删除在线状态回调订阅，只能删除自己创建的订阅
+func (s *Service) UnsubscribePresence(ctx context.Context, ownerID int64, subscriptionID string) error {
+	if ownerID <= 0 || subscriptionID == "" {
+		return httpx.InvalidArgument(fmt.Errorf("无效的用户ID或订阅ID"))
+	}
+
+	sub, err := s.getPresenceSubscription(ctx, subscriptionID)
+	if err != nil {
+		return httpx.Unavailable(fmt.Errorf("查询订阅失败: %v", err))
+	}
+	if sub == nil || sub.OwnerID != ownerID {
+		return httpx.InvalidArgument(fmt.Errorf("订阅不存在"))
+	}
+
+	for _, userID := range sub.UserIDs {
+		s.redis.SRem(ctx, fmt.Sprintf("%s:%d", model.PresenceHookWatchersKeyPrefix, userID), sub.ID)
+	}
+	s.redis.SRem(ctx, fmt.Sprintf("%s:%d", model.PresenceHookOwnerKeyPrefix, ownerID), sub.ID)
+	if err := s.redis.Del(ctx, presenceHookKey(sub.ID)); err != nil {
+		return httpx.Unavailable(fmt.Errorf("删除订阅失败: %v", err))
+	}
+	return nil
+}
+
+// ListPresenceSubscriptions 列出用户创建的在线状态回调订阅，不返回签名密钥
+func (s *Service) ListPresenceSubscriptions(ctx context.Context, ownerID int64) ([]*model.PresenceSubscription, error) {
+	if ownerID <= 0 {
+		return nil, httpx.InvalidArgument(fmt.Errorf("无效的用户ID"))
+	}
+
+	ownerKey := fmt.Sprintf("%s:%d", model.PresenceHookOwnerKeyPrefix, ownerID)
+	ids, err := s.redis.SMembers(ctx, ownerKey)
+	if err != nil {
+		return nil, httpx.Unavailable(fmt.Errorf("查询订阅失败: %v", err))
+	}
+
+	subs := make([]*model.PresenceSubscription, 0, len(ids))
+	for _, id := range ids {
+		sub, err := s.getPresenceSubscription(ctx, id)
+		if err != nil {
+			return nil, httpx.Unavailable(fmt.Errorf("查询订阅失败: %v", err))
+		}
+		if sub == nil {
+			s.redis.SRem(ctx, ownerKey, id)
+			continue
+		}
+		sub.Secret = ""
+		subs = append(subs, sub)
+	}
+	return subs, nil
+}
+
+// PublishPresenceChange 向订阅了该用户的集成回调上下线事件
+// 隐身用户不回调；订阅者已不是该用户的好友时跳过，订阅保留，恢复好友关系后继续回调
+func (s *Service) PublishPresenceChange(ctx context.Context, userID int64, status string) {
+	if !s.config.Connect.PresenceHook.Enabled || s.socialClient == nil {
+		return
+	}
+
+	for _, key := range []string{model.FriendOnlineHiddenKey, model.PresenceHiddenUsersKey} {
+		hidden, err := s.redis.SIsMember(ctx, key, userID)
+		if err != nil || hidden {
+			return
+		}
+	}
+
+	watchersKey := fmt.Sprintf("%s:%d", model.PresenceHookWatchersKeyPrefix, userID)
+	ids, err := s.redis.SMembers(ctx, watchersKey)
+	if err != nil || len(ids) == 0 {
+		return
+	}
+
+	friends, err := s.friendIDSet(ctx, userID)
+	if err != nil {
+		log.Printf("获取用户 %d 好友列表失败，跳过在线状态回调: %v", userID, err)
+		return
+	}
+
+	now := time.Now().Unix()
+	for _, id := range ids {
+		sub, err := s.getPresenceSubscription(ctx, id)
+		if err != nil {
+			continue
+		}
+		if sub == nil {
+			s.redis.SRem(ctx, watchersKey, id)
+			continue
+		}
+		if !friends[sub.OwnerID] {
+			continue
+		}
+
+		event := &model.PresenceEvent{
+			EventID:        uuid.New().String(),
+			Type:           model.PresenceEventChanged,
+			SubscriptionID: sub.ID,
+			UserID:         userID,
+			Status:         status,
+			OccurredAt:     now,
+		}
+		go s.deliverPresenceEvent(sub, event)
+	}
+}
+
+// deliverPresenceEvent 签名后回调事件，网络错误、429和5xx按指数退避重试，其他4xx视为接收方拒绝不再重试
+func (s *Service) deliverPresenceEvent(sub *model.PresenceSubscription, event *model.PresenceEvent) {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return
+	}
+
+	cfg := s.config.Connect.PresenceHook
+	timeout := time.Duration(cfg.Timeout) * time.Second
+	if cfg.Timeout <= 0 {
+		timeout = model.DefaultPresenceHookTimeout * time.Second
+	}
+	backoff := time.Duration(cfg.RetryBackoff) * time.Millisecond
+	if cfg.RetryBackoff <= 0 {
+		backoff = model.DefaultPresenceHookRetryBackoff * time.Millisecond
+	}
+
+	for attempt := 0; ; attempt++ {
+		status, err := s.postPresenceEvent(sub, event, body, timeout)
+		if err == nil && status >= 200 && status < 300 {
+			return
+		}
+		retryable := err != nil || status == http.StatusTooManyRequests || status >= 500
+		if !retryable || attempt >= cfg.MaxRetries {
+			log.Printf("在线状态回调失败: Subscription=%s, Event=%s, Attempts=%d, Status=%d, err=%v",
+				sub.ID, event.EventID, attempt+1, status, err)
+			return
+		}
+
+		time.Sleep(backoff)
+		backoff = min(backoff*2, model.MaxPresenceHookRetryBackoff*time.Second)
+	}
+}
+
+// postPresenceEvent 发送一次回调请求，每次请求使用当前时间重新签名
+func (s *Service) postPresenceEvent(sub *model.PresenceSubscription, event *model.PresenceEvent, body []byte, timeout time.Duration) (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sub.CallbackURL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	timestamp := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(webhook.HeaderEventID, event.EventID)
+	req.Header.Set(webhook.HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(webhook.HeaderSignature, webhook.Sign(sub.Secret, timestamp, body))
+
+	resp, err := presenceHookClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	return resp.StatusCode, nil
+}
+
+// validateCallbackURL 校验回调地址，默认只允许HTTPS，主机解析到回环、内网或链路本地地址时拒绝
+func (s *Service) validateCallbackURL(ctx context.Context, callbackURL string) error {
+	u, err := url.Parse(callbackURL)
+	if err != nil || u.Hostname() == "" {
+		return fmt.Errorf("无效的回调地址")
+	}
+	if u.Scheme != "https" && !(u.Scheme == "http" && s.config.Connect.PresenceHook.AllowHTTP) {
+		return fmt.Errorf("回调地址必须使用HTTPS")
+	}
+	if _, err := webhook.ResolvePublic(ctx, u.Hostname()); err != nil {
+		return err
+	}
+	return nil
+}
+
+// presenceHookIntegration 用户是否为允许订阅在线状态回调的集成账号
+func (s *Service) presenceHookIntegration(userID int64) bool {
+	for _, item := range strings.Split(s.config.Connect.PresenceHook.IntegrationIDs, ",") {
+		if id, err := strconv.ParseInt(strings.TrimSpace(item), 10, 64); err == nil && id == userID {
+			return true
+		}
+	}
+	return false
+}
+
+// friendIDSet 获取用户的好友ID集合
+func (s *Service) friendIDSet(ctx context.Context, userID int64) (map[int64]bool, error) {
+	resp, err := s.socialClient.GetUserSocialInfo(ctx, &rest.GetUserSocialInfoRequest{UserId: userID})
+	if err != nil {
+		return nil, err
+	}
+	if !resp.Success || resp.SocialInfo == nil {
+		return nil, fmt.Errorf("%s", resp.Message)
+	}
+
+	friends := make(map[int64]bool, len(resp.SocialInfo.FriendIds))
+	for _, friendID := range resp.SocialInfo.FriendIds {
+		friends[friendID] = true
+	}
+	return friends, nil
+}
+
+// getPresenceSubscription 读取订阅详情，订阅不存在时返回nil
+func (s *Service) getPresenceSubscription(ctx context.Context, subscriptionID string) (*model.PresenceSubscription, error) {
+	data, err := s.redis.Get(ctx, presenceHookKey(subscriptionID))
+	if err != nil {
+		if err == goredis.Nil {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var sub model.PresenceSubscription
+	if err := json.Unmarshal([]byte(data), &sub); err != nil {
+		return nil, err
+	}
+	return &sub, nil
+}
+
+// presenceHookMaxUsers 每个订阅最多关注的用户数
+func (s *Service) presenceHookMaxUsers() int {
+	if n := s.config.Connect.PresenceHook.MaxUsers; n > 0 {
+		return n
+	}
+	return model.DefaultPresenceHookMaxUsers
+}
+
+// presenceHookMaxSubscriptions 每个用户最多的订阅数
+func (s *Service) presenceHookMaxSubscriptions() int {
+	if n := s.config.Connect.PresenceHook.MaxSubscriptions; n > 0 {
+		return n
+	}
+	return model.DefaultPresenceHookMaxSubscriptions
+}
+
+// presenceHookKey 订阅详情键
+func presenceHookKey(subscriptionID string) string {
+	return fmt.Sprintf("%s:%s", model.PresenceHookKeyPrefix, subscriptionID)
+}
@@ -0,0 +1,211 @@
+package service
+
+import (
+	"log"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"goim-social/api/rest"
+	"goim-social/apps/im-gateway-service/internal/model"
+	"goim-social/pkg/config"
+)
+
+// ============ 推送优先级通道 ============
+// 每个连接一个发送队列，分为high、normal、bulk三个通道，由该连接的发送协程按优先级出队写入：
+// 有高优先级消息排队时总是先发送，群广播风暴只会堆积在normal、bulk通道，不会延迟单聊消息。
+// 通道内先进先出，同一发送者同一通道的消息保持顺序；不同通道的消息可能交错，客户端按sender_seq展示。
+// 每个通道单独限制排队数，通道满时丢弃新消息而不阻塞推送链路，未送达的消息由客户端重连后拉取
+
+// pushLaneOrder 出队顺序
+var pushLaneOrder = []string{model.PushLaneHigh, model.PushLaneNormal, model.PushLaneBulk}
+
+// defaultPushLanes 各消息类型的默认通道，未列出的类型单聊为high、群聊为normal
+var defaultPushLanes = map[int32]string{
+	model.MessageTypeSystem:         model.PushLaneBulk,
+	model.MessageTypeReadReceipt:    model.PushLaneBulk,
+	model.MessageTypeFriendOnline:   model.PushLaneBulk,
+	model.LegacyMessageTypePresence: model.PushLaneBulk,
+}
+
+// wsSendQueues 每个连接的发送队列，首次推送时创建，连接关闭时释放
+var wsSendQueues sync.Map // *websocket.Conn -> *sendQueue
+
+// isPushLane 是否为有效的通道名
+func isPushLane(lane string) bool {
+	return lane == model.PushLaneHigh || lane == model.PushLaneNormal || lane == model.PushLaneBulk
+}
+
+// parsePushLaneOverrides 解析按消息类型覆盖的通道配置，忽略无效的项
+func parsePushLaneOverrides(cfg config.PushLanesConfig) map[int32]string {
+	overrides := make(map[int32]string, len(cfg.Overrides))
+	for key, lane := range cfg.Overrides {
+		messageType, err := strconv.ParseInt(key, 10, 32)
+		if err != nil || !isPushLane(lane) {
+			log.Printf("忽略无效的推送通道配置: %s=%s", key, lane)
+			continue
+		}
+		overrides[int32(messageType)] = lane
+	}
+	return overrides
+}
+
+// pushLane 确定消息的推送通道：消息携带的通道优先，其次是按消息类型的配置，最后是默认通道
+func (s *Service) pushLane(msg *rest.WSMessage) string {
+	if isPushLane(msg.Priority) {
+		return msg.Priority
+	}
+	if lane, ok := s.laneOverrides[msg.MessageType]; ok {
+		return lane
+	}
+	if lane, ok := defaultPushLanes[msg.MessageType]; ok {
+		return lane
+	}
+	if msg.GroupId > 0 {
+		return model.PushLaneNormal
+	}
+	return model.PushLaneHigh
+}
+
+// queuedPush 排队中的推送
+type queuedPush struct {
+	msg        *rest.WSMessage
+	lane       string
+	enqueuedAt time.Time
+	done       func(dropReason string) // 写入成功时dropReason为空
+}
+
+// sendQueue 单个连接的发送队列
+type sendQueue struct {
+	conn     *websocket.Conn
+	capacity int
+
+	mu     sync.Mutex
+	lanes  map[string][]*queuedPush
+	closed bool
+
+	notify chan struct{} // 有新消息入队，容量为1
+	stop   chan struct{} // 关闭时关闭
+}
+
+// enqueuePush 将消息放入连接的发送队列，首次推送时启动该连接的发送协程；未入队时返回丢弃原因
+func enqueuePush(conn *websocket.Conn, capacity int, push *queuedPush) string {
+	value, ok := wsSendQueues.Load(conn)
+	if !ok {
+		var loaded bool
+		value, loaded = wsSendQueues.LoadOrStore(conn, newSendQueue(conn, capacity))
+		if !loaded {
+			go value.(*sendQueue).run()
+		}
+	}
+	return value.(*sendQueue).push(push)
+}
+
+// releaseSendQueue 关闭连接的发送队列，排队中的消息按连接已断开丢弃
+func releaseSendQueue(conn *websocket.Conn) {
+	if value, ok := wsSendQueues.LoadAndDelete(conn); ok {
+		value.(*sendQueue).close()
+	}
+}
+
+func newSendQueue(conn *websocket.Conn, capacity int) *sendQueue {
+	if capacity <= 0 {
+		capacity = model.DefaultPushLaneQueueSize
+	}
+	return &sendQueue{
+		conn:     conn,
+		capacity: capacity,
+		lanes:    make(map[string][]*queuedPush, len(pushLaneOrder)),
+		notify:   make(chan struct{}, 1),
+		stop:     make(chan struct{}),
+	}
+}
+
+// push 消息入队，通道已满或队列已关闭时返回丢弃原因
+func (q *sendQueue) push(push *queuedPush) string {
+	q.mu.Lock()
+	if q.closed {
+		q.mu.Unlock()
+		return dropReasonOffline
+	}
+	if len(q.lanes[push.lane]) >= q.capacity {
+		q.mu.Unlock()
+		return dropReasonQueueFull
+	}
+	push.enqueuedAt = time.Now()
+	q.lanes[push.lane] = append(q.lanes[push.lane], push)
+	q.mu.Unlock()
+
+	pushQueueDepth.WithLabelValues(push.lane).Inc()
+	select {
+	case q.notify <- struct{}{}:
+	default:
+	}
+	return ""
+}
+
+// pop 按优先级取出下一条消息，队列为空时返回nil
+func (q *sendQueue) pop() *queuedPush {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for _, lane := range pushLaneOrder {
+		if pending := q.lanes[lane]; len(pending) > 0 {
+			push := pending[0]
+			pending[0] = nil
+			q.lanes[lane] = pending[1:]
+			return push
+		}
+	}
+	return nil
+}
+
+// run 发送协程，每次写入前重新按优先级选择，写入失败时关闭队列
+func (q *sendQueue) run() {
+	for {
+		select {
+		case <-q.stop:
+			return
+		case <-q.notify:
+		}
+
+		for push := q.pop(); push != nil; push = q.pop() {
+			pushQueueDepth.WithLabelValues(push.lane).Dec()
+			pushQueueWait.WithLabelValues(push.lane).Observe(time.Since(push.enqueuedAt).Seconds())
+
+			if err := writeWSMessage(q.conn, push.msg); err != nil {
+				log.Printf("向用户 %d 发送消息失败: %v", push.msg.To, err)
+				pushLaneTotal.WithLabelValues(push.lane, pushResultFailed).Inc()
+				push.done(dropReasonWriteError)
+				wsSendQueues.CompareAndDelete(q.conn, q)
+				q.close()
+				return
+			}
+			pushLaneTotal.WithLabelValues(push.lane, pushResultSuccess).Inc()
+			push.done("")
+		}
+	}
+}
+
+// close 停止发送协程并丢弃排队中的消息，可重复调用
+func (q *sendQueue) close() {
+	q.mu.Lock()
+	if q.closed {
+		q.mu.Unlock()
+		return
+	}
+	q.closed = true
+	pending := q.lanes
+	q.lanes = nil
+	q.mu.Unlock()
+
+	close(q.stop)
+	for lane, pushes := range pending {
+		for _, push := range pushes {
+			pushQueueDepth.WithLabelValues(lane).Dec()
+			pushLaneTotal.WithLabelValues(lane, pushResultFailed).Inc()
+			push.done(dropReasonOffline)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package service
+
+import (
+	"reflect"
+	"testing"
+
+	"goim-social/api/rest"
+	"goim-social/apps/im-gateway-service/internal/model"
+	"goim-social/pkg/config"
+)
+
+// TestPushLane 消息携带的通道优先，其次是按消息类型的配置，未配置时单聊为high、群聊为normal
+func TestPushLane(t *testing.T) {
+	s := &Service{laneOverrides: map[int32]string{model.MessageTypeSystem: model.PushLaneHigh, 3: model.PushLaneBulk}}
+	cases := []struct {
+		name string
+		msg  *rest.WSMessage
+		want string
+	}{
+		{name: "private", msg: &rest.WSMessage{MessageType: 1, To: 2}, want: model.PushLaneHigh},
+		{name: "group", msg: &rest.WSMessage{MessageType: 1, To: 2, GroupId: 9}, want: model.PushLaneNormal},
+		{name: "mention in group", msg: &rest.WSMessage{MessageType: 104, GroupId: 9, Priority: model.PushLaneHigh}, want: model.PushLaneHigh},
+		{name: "message priority beats override", msg: &rest.WSMessage{MessageType: 3, Priority: model.PushLaneNormal}, want: model.PushLaneNormal},
+		{name: "invalid priority ignored", msg: &rest.WSMessage{MessageType: 1, GroupId: 9, Priority: "urgent"}, want: model.PushLaneNormal},
+		{name: "override", msg: &rest.WSMessage{MessageType: 3}, want: model.PushLaneBulk},
+		{name: "override beats default", msg: &rest.WSMessage{MessageType: model.MessageTypeSystem}, want: model.PushLaneHigh},
+		{name: "default by type", msg: &rest.WSMessage{MessageType: model.MessageTypeReadReceipt, To: 2}, want: model.PushLaneBulk},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := s.pushLane(tc.msg); got != tc.want {
+				t.Errorf("pushLane() = %s, want %s", got, tc.want)
+			}
+		})
+	}
+}
+
+// TestParsePushLaneOverrides 忽略无法解析的消息类型和无效的通道名
+func TestParsePushLaneOverrides(t *testing.T) {
+	got := parsePushLaneOverrides(config.PushLanesConfig{Overrides: map[string]string{
+		"100": model.PushLaneHigh,
+		"abc": model.PushLaneBulk,
+		"5":   "urgent",
+	}})
+	want := map[int32]string{100: model.PushLaneHigh}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parsePushLaneOverrides() = %v, want %v", got, want)
+	}
+}
+
+// TestSendQueuePop 高优先级通道先出队，同一通道内先进先出
+func TestSendQueuePop(t *testing.T) {
+	q := newSendQueue(nil, 10)
+	pushes := []struct {
+		id   int64
+		lane string
+	}{
+		{1, model.PushLaneBulk},
+		{2, model.PushLaneNormal},
+		{3, model.PushLaneHigh},
+		{4, model.PushLaneNormal},
+		{5, model.PushLaneHigh},
+	}
+	for _, p := range pushes {
+		if reason := q.push(&queuedPush{msg: &rest.WSMessage{MessageId: p.id}, lane: p.lane}); reason != "" {
+			t.Fatalf("push(%d) dropped: %s", p.id, reason)
+		}
+	}
+
+	var got []int64
+	for push := q.pop(); push != nil; push = q.pop() {
+		got = append(got, push.msg.MessageId)
+	}
+	if want := []int64{3, 5, 2, 4, 1}; !reflect.DeepEqual(got, want) {
+		t.Errorf("pop order = %v, want %v", got, want)
+	}
+}
+
+// TestSendQueueFull 通道满时丢弃新消息，不影响其他通道；关闭后不再入队
+func TestSendQueueFull(t *testing.T) {
+	q := newSendQueue(nil, 1)
+	if reason := q.push(&queuedPush{msg: &rest.WSMessage{MessageId: 1}, lane: model.PushLaneBulk}); reason != "" {
+		t.Fatalf("first push dropped: %s", reason)
+	}
+	if reason := q.push(&queuedPush{msg: &rest.WSMessage{MessageId: 2}, lane: model.PushLaneBulk}); reason != dropReasonQueueFull {
+		t.Errorf("push to full lane = %q, want %q", reason, dropReasonQueueFull)
+	}
+	if reason := q.push(&queuedPush{msg: &rest.WSMessage{MessageId: 3}, lane: model.PushLaneHigh}); reason != "" {
+		t.Errorf("push to other lane dropped: %s", reason)
+	}
+
+	var dropped []string
+	for _, lane := range pushLaneOrder {
+		for _, push := range q.lanes[lane] {
+			push.done = func(reason string) { dropped = append(dropped, reason) }
+		}
+	}
+	q.close()
+	if len(dropped) != 2 {
+		t.Errorf("close dropped %d pending pushes, want 2", len(dropped))
+	}
+	if reason := q.push(&queuedPush{msg: &rest.WSMessage{MessageId: 4}, lane: model.PushLaneHigh}); reason != dropReasonOffline {
+		t.Errorf("push after close = %q, want %q", reason, dropReasonOffline)
+	}
+}
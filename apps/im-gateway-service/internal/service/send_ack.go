@@ -0,0 +1,93 @@
+package service
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"goim-social/api/rest"
+	"goim-social/apps/im-gateway-service/internal/model"
+)
+
+// SendChatMessage 转发上行聊天消息到Logic服务，消息携带client_msg_id时立即向发送方回复发送确认，
+// 受理后按回显模式把消息回显给发送方的设备。未携带client_msg_id的旧版客户端不回复确认，
+// 只能依赖all_devices模式下的消息回显判断发送结果。
+// 转发前经过防重放校验：时间戳超出窗口的帧被拒绝，窗口内重复的client_msg_id直接回复首次处理的发送确认
+func (s *Service) SendChatMessage(ctx context.Context, conn *websocket.Conn, userID int64, msg *rest.WSMessage) error {
+	if err := s.checkChatFrame(msg); err != nil {
+		if msg.ClientMsgId != "" && len(msg.ClientMsgId) <= model.MaxClientMsgIDLength {
+			s.replySendAck(conn, msg, &rest.SendAck{
+				ClientMsgId: msg.ClientMsgId,
+				Status:      rest.SendAckStatus_SEND_ACK_STATUS_REJECTED,
+				Reason:      model.SendAckReasonStaleFrame,
+				Message:     err.Error(),
+				ServerTime:  time.Now().Unix(),
+			})
+		}
+		return err
+	}
+
+	if duplicate, prevAck := s.beginClientMsg(ctx, userID, msg.ClientMsgId); duplicate {
+		log.Printf("重复的client_msg_id，不再转发: UserID=%d, ClientMsgID=%s", userID, msg.ClientMsgId)
+		if prevAck != nil {
+			s.replySendAck(conn, msg, prevAck)
+		}
+		return nil
+	}
+
+	resp, err := s.ForwardMessageToLogicService(ctx, msg)
+
+	ack := buildSendAck(msg.ClientMsgId, resp)
+	s.finishClientMsg(ctx, userID, ack)
+	if msg.ClientMsgId != "" {
+		s.replySendAck(conn, msg, ack)
+	}
+
+	// 回显在发送确认之后，发起连接先拿到消息ID再收到回显
+	if ack.Status == rest.SendAckStatus_SEND_ACK_STATUS_ACCEPTED {
+		s.echoToSender(ctx, conn, msg, resp)
+	}
+	return err
+}
+
+// replySendAck 向发起发送的连接回复发送确认，写入失败只记录日志
+func (s *Service) replySendAck(conn *websocket.Conn, msg *rest.WSMessage, ack *rest.SendAck) {
+	envelope := &rest.WSEnvelope{
+		Version: model.WSEnvelopeVersion,
+		Payload: &rest.WSEnvelope_SendAck{SendAck: ack},
+	}
+	if err := writeWSEnvelope(conn, envelope); err != nil {
+		log.Printf("回复发送确认失败: From=%d, ClientMsgID=%s, Error=%v", msg.From, msg.ClientMsgId, err)
+	}
+}
+
+// buildSendAck 根据Logic服务的处理结果构建发送确认
+// 带拒绝原因的结果为拒绝；附件扫描中的结果为待扫描，扫描未通过时Logic服务另行推送发送失败通知；
+// 已分配消息ID的结果说明消息已持久化或进入重试队列，视为受理，
+// 即使实时推送失败接收方也能通过未读消息拉取；其余情况（调用失败、持久化失败）为失败，客户端可重试
+func buildSendAck(clientMsgID string, resp *rest.SendLogicMessageResponse) *rest.SendAck {
+	ack := &rest.SendAck{ClientMsgId: clientMsgID}
+	switch {
+	case resp != nil && resp.RejectReason != "":
+		ack.Status = rest.SendAckStatus_SEND_ACK_STATUS_REJECTED
+		ack.Reason = resp.RejectReason
+		ack.Message = resp.Message
+	case resp != nil && resp.ScanPending && resp.MessageId > 0:
+		ack.Status = rest.SendAckStatus_SEND_ACK_STATUS_PENDING
+		ack.MessageId = resp.MessageId
+		ack.ServerTime = resp.ServerTime
+		ack.Message = resp.Message
+	case resp != nil && resp.MessageId > 0:
+		ack.Status = rest.SendAckStatus_SEND_ACK_STATUS_ACCEPTED
+		ack.MessageId = resp.MessageId
+		ack.SenderSeq = resp.SenderSeq
+		ack.ServerTime = resp.ServerTime
+	default:
+		ack.Status = rest.SendAckStatus_SEND_ACK_STATUS_FAILED
+		ack.Reason = model.SendAckReasonUnavailable
+		ack.Message = "消息发送失败，请稍后重试"
+	}
+	return ack
+}
@@ -5,11 +5,9 @@ import (
 	"encoding/base64"
 	"fmt"
 	"log"
-	"os"
-	"os/signal"
 	"strconv"
 	"sync"
-	"syscall"
+	"sync/atomic"
 	"time"
 
 	"github.com/gorilla/websocket"
@@ -24,7 +22,11 @@ import (
 	"goim-social/pkg/config"
 	tracecontext "goim-social/pkg/context"
 	"goim-social/pkg/database"
+	"goim-social/pkg/httpx"
 	"goim-social/pkg/kafka"
+	"goim-social/pkg/middleware"
+	"goim-social/pkg/ordering"
+	"goim-social/pkg/presence"
 	"goim-social/pkg/redis"
 	"goim-social/pkg/sessionlocator"
 	"goim-social/pkg/telemetry"
@@ -48,7 +50,7 @@ func NewConnectionManager(redis *redis.RedisClient, cfg *config.Config) *Connect
 }
 
 // AddConnection 原子式添加连接，同时更新本地连接和Redis状态
-func (cm *ConnectionManager) AddConnection(ctx context.Context, userID int64, conn *websocket.Conn, connID string, serverID string) error {
+func (cm *ConnectionManager) AddConnection(ctx context.Context, userID int64, conn *websocket.Conn, connID string, serverID string, client model.ClientInfo) error {
 	// 开始OpenTelemetry span
 	ctx, span := telemetry.StartSpan(ctx, "im-gateway.connection.AddConnection")
 	defer span.End()
@@ -58,6 +60,8 @@ func (cm *ConnectionManager) AddConnection(ctx context.Context, userID int64, co
 		attribute.Int64("user.id", userID),
 		attribute.String("connection.id", connID),
 		attribute.String("server.id", serverID),
+		attribute.String("client.platform", client.Platform),
+		attribute.String("client.app_version", client.AppVersion),
 	)
 
 	// 将业务信息添加到context
@@ -85,12 +89,14 @@ func (cm *ConnectionManager) AddConnection(ctx context.Context, userID int64, co
 	}
 
 	// 添加连接信息到Redis Hash
-	key := fmt.Sprintf("conn:%d:%s", userID, connID)
+	key := presence.ConnectionKey(userID, connID)
 	connInfo := map[string]interface{}{
 		"userID":        userID,
 		"connID":        connID,
 		"serverID":      serverID,
 		"clientType":    cm.getDefaultClientType(),
+		"platform":      client.Platform,
+		"appVersion":    client.AppVersion,
 		"timestamp":     time.Now().Unix(),
 		"lastHeartbeat": time.Now().Unix(),
 	}
@@ -108,6 +114,9 @@ func (cm *ConnectionManager) AddConnection(ctx context.Context, userID int64, co
 	if err := cm.redis.Expire(ctx, key, expireTime); err != nil {
 		log.Printf("设置连接过期时间失败: %v", err)
 	}
+	if err := presence.TrackConnection(ctx, cm.redis, userID, connID, expireTime); err != nil {
+		log.Printf("记录用户 %d 连接失败: %v", userID, err)
+	}
 
 	totalConnections := len(cm.localConnections)
 	log.Printf("用户 %d 连接已添加，当前总连接数: %d", userID, totalConnections)
@@ -156,12 +165,15 @@ func (cm *ConnectionManager) RemoveConnection(ctx context.Context, userID int64,
 
 	// 删除Redis中的连接信息
 	if connID != "" {
-		key := fmt.Sprintf("conn:%d:%s", userID, connID)
+		key := presence.ConnectionKey(userID, connID)
 		if err := cm.redis.Del(ctx, key); err != nil {
 			log.Printf("删除Redis连接信息失败: %v", err)
 		} else {
 			log.Printf("用户 %d 的Redis连接信息已删除", userID)
 		}
+		if err := presence.UntrackConnection(ctx, cm.redis, userID, connID); err != nil {
+			log.Printf("移除用户 %d 连接失败: %v", userID, err)
+		}
 	}
 
 	totalConnections := len(cm.localConnections)
@@ -222,6 +234,40 @@ func (cm *ConnectionManager) getConnectionList() []int64 {
 	return users
 }
 
+// NotifyGoingAway 向所有本地连接发送关闭帧，通知客户端服务即将下线，返回通知的连接数
+func (cm *ConnectionManager) NotifyGoingAway() int {
+	cm.mutex.RLock()
+	defer cm.mutex.RUnlock()
+
+	msg := websocket.FormatCloseMessage(websocket.CloseGoingAway, "server shutting down")
+	deadline := time.Now().Add(time.Second)
+	for userID, conn := range cm.localConnections {
+		if err := conn.WriteControl(websocket.CloseMessage, msg, deadline); err != nil {
+			log.Printf("向用户 %d 发送关闭帧失败: %v", userID, err)
+		}
+	}
+	return len(cm.localConnections)
+}
+
+// LocalUserIDs 获取本实例有连接的用户ID
+func (cm *ConnectionManager) LocalUserIDs() []int64 {
+	cm.mutex.RLock()
+	defer cm.mutex.RUnlock()
+
+	userIDs := make([]int64, 0, len(cm.localConnections))
+	for userID := range cm.localConnections {
+		userIDs = append(userIDs, userID)
+	}
+	return userIDs
+}
+
+// LocalConnectionCount 获取本地连接数
+func (cm *ConnectionManager) LocalConnectionCount() int {
+	cm.mutex.RLock()
+	defer cm.mutex.RUnlock()
+	return len(cm.localConnections)
+}
+
 // CleanupAll 清理所有本地连接（服务关闭时调用）
 func (cm *ConnectionManager) CleanupAll() {
 	cm.mutex.Lock()
@@ -244,35 +290,63 @@ func (cm *ConnectionManager) CleanupAll() {
 }
 
 type Service struct {
-	db           *database.MongoDB
-	redis        *redis.RedisClient
-	kafka        *kafka.Producer
-	config       *config.Config                   // 配置
-	instanceID   string                           // Connect服务实例ID
-	logicClient  rest.LogicServiceClient          // Logic服务客户端
-	connMgr      *ConnectionManager               // 统一连接管理器
-	heartbeatMgr *sessionlocator.HeartbeatManager // 心跳管理器
-}
-
-func NewService(db *database.MongoDB, redis *redis.RedisClient, kafka *kafka.Producer, cfg *config.Config) *Service {
+	db            *database.MongoDB
+	redis         *redis.RedisClient
+	kafka         *kafka.Producer
+	config        *config.Config                   // 配置
+	authProvider  auth.Provider                    // 认证提供者
+	instanceID    string                           // Connect服务实例ID
+	logicClient   rest.LogicServiceClient          // Logic服务客户端
+	socialClient  rest.SocialServiceClient         // Social服务客户端，用于好友上线提醒和在线状态回调
+	connMgr       *ConnectionManager               // 统一连接管理器
+	heartbeatMgr  *sessionlocator.HeartbeatManager // 心跳管理器
+	forwardDedup  *forwardDeduper                  // 跨节点转发去重器
+	forwardSeq    atomic.Int64                     // 本实例发出的转发消息序号
+	senderOrder   *ordering.Buffer                 // 按发送者序号重排推送消息
+	pushes        pushTracker                      // 推送延迟和结果统计
+	laneOverrides map[int32]string                 // 按消息类型覆盖的推送通道
+	presence      *presence.Cache                  // 在线状态缓存，供其他服务直接读取
+	admitting     atomic.Int64                     // 已通过容量检查、尚未注册的连接数
+	rejected      atomic.Int64                     // 因容量不足拒绝的握手数
+	admins        map[int64]bool                   // 允许查询连接列表和连接容量的管理员
+}
+
+func NewService(db *database.MongoDB, redis *redis.RedisClient, kafka *kafka.Producer, cfg *config.Config, authProvider auth.Provider) *Service {
 	instanceID := fmt.Sprintf("im-gateway-%d", time.Now().UnixNano()) // 生成唯一实例ID
 
 	service := &Service{
-		db:         db,
-		redis:      redis,
-		kafka:      kafka,
-		config:     cfg,
-		instanceID: instanceID,
-		connMgr:    NewConnectionManager(redis, cfg), // 初始化连接管理器
+		db:           db,
+		redis:        redis,
+		kafka:        kafka,
+		config:       cfg,
+		authProvider: authProvider,
+		instanceID:   instanceID,
+		connMgr:      NewConnectionManager(redis, cfg), // 初始化连接管理器
 		heartbeatMgr: sessionlocator.NewHeartbeatManager(redis, instanceID, // 初始化心跳管理器
 			cfg.Connect.Instance.Host, cfg.Connect.Instance.Port),
+		forwardDedup:  newForwardDeduper(),
+		laneOverrides: parsePushLaneOverrides(cfg.Connect.PushLanes),
+		presence:      presence.NewCache(redis, cfg.Presence),
+		admins:        config.ParseUserIDs(cfg.Connect.AdminIDs),
 	}
+	service.senderOrder = ordering.NewBuffer(
+		time.Duration(model.SenderOrderWait)*time.Millisecond,
+		time.Duration(model.SenderOrderIdleExpire)*time.Second,
+		service.deliverToLocalConnection)
+	registerConnectionGauge(service.connMgr, cfg.Connect.Connection.MaxConnections)
 
 	// 初始化Logic服务客户端
 	if err := service.initLogicClient(); err != nil {
 		log.Printf("Logic服务客户端初始化失败: %v", err)
 	}
 
+	// 启用好友上线提醒或在线状态回调时初始化Social服务客户端
+	if cfg.Connect.FriendOnline.Enabled || cfg.Connect.PresenceHook.Enabled {
+		if err := service.initSocialClient(); err != nil {
+			log.Printf("Social服务客户端初始化失败，好友上线提醒和在线状态回调不可用: %v", err)
+		}
+	}
+
 	// 注册服务实例
 	if err := service.registerInstance(); err != nil {
 		log.Printf("服务实例注册失败: %v", err)
@@ -287,6 +361,9 @@ func NewService(db *database.MongoDB, redis *redis.RedisClient, kafka *kafka.Pro
 	// 启动Redis订阅 gateway:instanceID:user_message 频道（来自Logic服务的消息）
 	go service.subscribeGatewayUserMessage()
 
+	// 定期刷新本实例连接用户的在线状态缓存
+	go service.refreshPresence()
+
 	return service
 }
 
@@ -296,7 +373,7 @@ func (s *Service) initLogicClient() error {
 	logicAddr := fmt.Sprintf("%s:%d", s.config.Connect.LogicService.Host, s.config.Connect.LogicService.Port)
 
 	// 建立gRPC连接
-	conn, err := grpc.Dial(logicAddr, grpc.WithInsecure())
+	conn, err := grpc.Dial(logicAddr, grpc.WithInsecure(), middleware.DeadlineDialOption(s.config.Deadline))
 	if err != nil {
 		return fmt.Errorf("连接Logic服务失败: %v", err)
 	}
@@ -321,7 +398,12 @@ func (s *Service) cleanupOnStartup() {
 	}
 
 	cleanedCount := 0
-	for _, key := range keys {
+	for i, key := range keys {
+		if ctx.Err() != nil {
+			log.Printf("退出时限已到，剩余 %d 个连接key未检查", len(keys)-i)
+			break
+		}
+
 		// 获取连接信息
 		connInfo, err := s.redis.HGetAll(ctx, key)
 		if err != nil {
@@ -345,22 +427,55 @@ func (s *Service) cleanupOnStartup() {
 	log.Printf("启动时清理完成: 清理了 %d 个旧连接", cleanedCount)
 }
 
-// setupGracefulShutdown 设置优雅退出
-func (s *Service) setupGracefulShutdown() {
-	sigChan := make(chan os.Signal, 1)
-	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
-
-	<-sigChan
+// Shutdown 优雅退出：通知客户端断开并在排空时间内等待其断开，随后清理本实例在Redis中的连接状态
+func (s *Service) Shutdown(ctx context.Context) error {
 	log.Printf("收到退出信号，开始优雅关闭...")
 
-	s.cleanup()
-	os.Exit(0)
+	s.drainConnections(ctx)
+	s.connMgr.CleanupAll()
+	s.cleanup(ctx)
+	return nil
 }
 
-// cleanup 清理资源
-func (s *Service) cleanup() {
-	ctx := context.Background()
+// drainConnections 向本地连接发送关闭帧让客户端重连到其他实例，并等待客户端断开
+// 排空时间不超过退出剩余时间的一半，为之后的Redis清理保留余量
+func (s *Service) drainConnections(ctx context.Context) {
+	drain := time.Duration(s.config.Connect.Connection.DrainTimeout) * time.Second
+	if deadline, ok := ctx.Deadline(); ok {
+		if remaining := time.Until(deadline) / 2; remaining < drain {
+			drain = remaining
+		}
+	}
+
+	notified := s.connMgr.NotifyGoingAway()
+	if notified == 0 || drain <= 0 {
+		return
+	}
+	log.Printf("已通知 %d 个连接断开，最多等待 %v", notified, drain)
+
+	timer := time.NewTimer(drain)
+	defer timer.Stop()
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if s.connMgr.LocalConnectionCount() == 0 {
+				log.Printf("所有连接已断开")
+				return
+			}
+		case <-timer.C:
+			log.Printf("连接排空超时，仍有 %d 个连接将被强制关闭", s.connMgr.LocalConnectionCount())
+			return
+		case <-ctx.Done():
+			return
+		}
+	}
+}
 
+// cleanup 清理资源
+func (s *Service) cleanup(ctx context.Context) {
 	log.Printf("开始清理实例资源: %s", s.instanceID)
 
 	// 停止心跳管理器（会自动注销新的ZSET和Hash）
@@ -424,15 +539,11 @@ func (s *Service) registerInstance() error {
 	}
 
 	log.Printf("Connect服务实例已注册: %s", s.instanceID)
-
-	// 启动优雅退出监听
-	go s.setupGracefulShutdown()
-
 	return nil
 }
 
-// Connect 处理连接，写入 redis hash，并维护在线用户 set
-func (s *Service) Connect(ctx context.Context, userID int64, token string, serverID, clientType string) (*model.Connection, error) {
+// Connect 处理连接，写入 redis hash（含客户端平台和版本），并维护在线用户 set
+func (s *Service) Connect(ctx context.Context, userID int64, token string, serverID, clientType string, client model.ClientInfo) (*model.Connection, error) {
 	if token == "" {
 		return nil, fmt.Errorf("token required")
 	}
@@ -445,9 +556,11 @@ func (s *Service) Connect(ctx context.Context, userID int64, token string, serve
 		Timestamp:     timestamp,
 		LastHeartbeat: timestamp,
 		ClientType:    clientType,
+		Platform:      client.Platform,
+		AppVersion:    client.AppVersion,
 		Online:        true,
 	}
-	key := fmt.Sprintf("conn:%d:%s", userID, connID)
+	key := presence.ConnectionKey(userID, connID)
 	fields := map[string]interface{}{
 		"userID":        userID,
 		"connID":        connID,
@@ -455,42 +568,67 @@ func (s *Service) Connect(ctx context.Context, userID int64, token string, serve
 		"timestamp":     timestamp,
 		"lastHeartbeat": timestamp,
 		"clientType":    clientType,
+		"platform":      client.Platform,
+		"appVersion":    client.AppVersion,
 	}
 	if err := s.redis.HMSet(ctx, key, fields); err != nil {
 		return nil, err
 	}
 	expireTime := time.Duration(s.config.Connect.Connection.ExpireTime) * time.Hour
 	_ = s.redis.Expire(ctx, key, expireTime)
+	if err := presence.TrackConnection(ctx, s.redis, userID, connID, expireTime); err != nil {
+		log.Printf("记录用户 %d 连接失败: %v", userID, err)
+	}
 	_ = s.redis.SAdd(ctx, "online_users", userID)
+	s.markPresenceOnline(ctx, userID)
 	return conn, nil
 }
 
 // Disconnect 处理断开，删除 redis hash，并维护在线用户 set
 func (s *Service) Disconnect(ctx context.Context, userID int64, connID string) error {
-	key := fmt.Sprintf("conn:%d:%s", userID, connID)
+	key := presence.ConnectionKey(userID, connID)
 	err := s.redis.Del(ctx, key)
+	if untrackErr := presence.UntrackConnection(ctx, s.redis, userID, connID); untrackErr != nil {
+		log.Printf("移除用户 %d 连接失败: %v", userID, untrackErr)
+	}
 	_ = s.redis.SRem(ctx, "online_users", userID)
+	s.markOffline(ctx, userID)
+	active := s.HasActiveConnection(ctx, userID)
+	if !active {
+		s.markPresenceOffline(ctx, userID)
+	}
+	// 全部设备下线时回调在线状态订阅
+	if s.config.Connect.PresenceHook.Enabled && !active {
+		go s.PublishPresenceChange(context.Background(), userID, model.PresenceStatusOffline)
+	}
 	return err
 }
 
 // Heartbeat 心跳，更新 lastHeartbeat 字段
 func (s *Service) Heartbeat(ctx context.Context, userID int64, connID string) error {
-	key := fmt.Sprintf("conn:%d:%s", userID, connID)
+	key := presence.ConnectionKey(userID, connID)
 	timestamp := time.Now().Unix()
 	if err := s.redis.HSet(ctx, key, "lastHeartbeat", timestamp); err != nil {
 		return err
 	}
-	// 刷新过期时间
+	s.markPresenceOnline(ctx, userID)
+	// 刷新过期时间，连接集合一并刷新，也补上集合上线前建立的连接
 	expireTime := time.Duration(s.config.Connect.Connection.ExpireTime) * time.Hour
+	if err := presence.TrackConnection(ctx, s.redis, userID, connID, expireTime); err != nil {
+		return err
+	}
 	return s.redis.Expire(ctx, key, expireTime)
 }
 
-// OnlineStatus 查询用户是否有活跃连接
+// OnlineStatus 查询用户是否有活跃连接，启用在线状态缓存时批量读取缓存
 func (s *Service) OnlineStatus(ctx context.Context, userIDs []int64) (map[int64]bool, error) {
+	if s.presence.Enabled() {
+		return s.presence.Online(ctx, userIDs)
+	}
+
 	status := make(map[int64]bool)
 	for _, uid := range userIDs {
-		pattern := fmt.Sprintf("conn:%d:*", uid)
-		keys, err := s.redis.Keys(ctx, pattern)
+		keys, err := presence.ConnectionKeys(ctx, s.redis, uid)
 		if err != nil {
 			status[uid] = false
 			continue
@@ -500,8 +638,83 @@ func (s *Service) OnlineStatus(ctx context.Context, userIDs []int64) (map[int64]
 	return status, nil
 }
 
-// ForwardMessageToLogicService 通过 gRPC 转发消息到 Logic 微服务
-func (s *Service) ForwardMessageToLogicService(ctx context.Context, wsMsg *rest.WSMessage) error {
+// ListConnections 列出连接及其客户端信息，供运维排查，仅配置的管理员可查询，platform非空时按平台过滤
+// userIDs为空时以SCAN分页列出全部连接，cursor为上一页返回的游标，返回的游标为0表示已列完；
+// 按用户查询时一次返回这些用户的全部连接，用户数不超过每页上限
+func (s *Service) ListConnections(ctx context.Context, operatorID int64, userIDs []int64, platform string, cursor uint64, limit int) ([]*model.Connection, uint64, error) {
+	if !s.admins[operatorID] {
+		return nil, 0, httpx.PermissionDenied(fmt.Errorf("无权限查询连接列表"))
+	}
+	if limit <= 0 {
+		limit = model.DefaultConnectionListLimit
+	}
+	limit = min(limit, model.MaxConnectionListLimit)
+	if len(userIDs) > limit {
+		return nil, 0, httpx.InvalidArgument(fmt.Errorf("每次最多查询%d个用户的连接", limit))
+	}
+
+	var keys []string
+	if len(userIDs) > 0 {
+		for _, uid := range userIDs {
+			userKeys, err := presence.ConnectionKeys(ctx, s.redis, uid)
+			if err != nil {
+				return nil, 0, err
+			}
+			keys = append(keys, userKeys...)
+		}
+		cursor = 0
+	} else {
+		// SCAN每批返回的数量不固定，凑满一页或遍历结束为止
+		for {
+			batch, next, err := s.redis.Scan(ctx, cursor, "conn:*", int64(limit))
+			if err != nil {
+				return nil, 0, fmt.Errorf("查询连接失败: %v", err)
+			}
+			keys = append(keys, batch...)
+			cursor = next
+			if cursor == 0 || len(keys) >= limit {
+				break
+			}
+		}
+	}
+
+	connections := make([]*model.Connection, 0, len(keys))
+	for _, key := range keys {
+		connInfo, err := s.redis.HGetAll(ctx, key)
+		if err != nil || len(connInfo) == 0 {
+			continue
+		}
+		conn := connectionFromHash(connInfo)
+		if platform != "" && conn.Platform != platform {
+			continue
+		}
+		connections = append(connections, conn)
+	}
+	return connections, cursor, nil
+}
+
+// connectionFromHash 将Redis连接哈希转换为连接模型，早于客户端信息上报的连接按默认值展示
+func connectionFromHash(connInfo map[string]string) *model.Connection {
+	userID, _ := strconv.ParseInt(connInfo["userID"], 10, 64)
+	timestamp, _ := strconv.ParseInt(connInfo["timestamp"], 10, 64)
+	lastHeartbeat, _ := strconv.ParseInt(connInfo["lastHeartbeat"], 10, 64)
+	client := ParseClientInfo(connInfo["platform"], connInfo["appVersion"])
+
+	return &model.Connection{
+		UserID:        userID,
+		ConnID:        connInfo["connID"],
+		Online:        true,
+		ServerID:      connInfo["serverID"],
+		Timestamp:     timestamp,
+		LastHeartbeat: lastHeartbeat,
+		ClientType:    connInfo["clientType"],
+		Platform:      client.Platform,
+		AppVersion:    client.AppVersion,
+	}
+}
+
+// ForwardMessageToLogicService 通过 gRPC 转发消息到 Logic 微服务，返回Logic服务的处理结果用于回复发送确认
+func (s *Service) ForwardMessageToLogicService(ctx context.Context, wsMsg *rest.WSMessage) (*rest.SendLogicMessageResponse, error) {
 	// 开始OpenTelemetry span
 	ctx, span := telemetry.StartSpan(ctx, "im-gateway.message.ForwardToLogic")
 	defer span.End()
@@ -522,38 +735,37 @@ func (s *Service) ForwardMessageToLogicService(ctx context.Context, wsMsg *rest.
 	log.Printf("Connect服务转发消息: From=%d, To=%d, Content=%s", wsMsg.From, wsMsg.To, wsMsg.Content)
 
 	// 使用Chat服务的单向调用
-	err := s.sendMessageViaUnaryCall(ctx, wsMsg)
+	resp, err := s.sendMessageViaUnaryCall(ctx, wsMsg)
 	if err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, "failed to forward message to logic service")
-		return err
+		return resp, err
 	}
 
 	span.SetStatus(codes.Ok, "message forwarded successfully")
-	return nil
+	return resp, nil
 }
 
-// HandleHeartbeat 处理心跳包
-func (s *Service) HandleHeartbeat(ctx context.Context, wsMsg *rest.WSMessage, conn interface{}) error {
-	// 这里假设 Content 字段存储 ConnID
-	connID := wsMsg.Content
-	if connID == "" {
+// HandleHeartbeat 处理心跳控制帧
+func (s *Service) HandleHeartbeat(ctx context.Context, frame *rest.ControlFrame) error {
+	if frame.ConnId == "" {
 		return fmt.Errorf("心跳包缺少 ConnID")
 	}
-	return s.Heartbeat(ctx, wsMsg.From, connID)
+	return s.Heartbeat(ctx, frame.UserId, frame.ConnId)
 }
 
-// ValidateToken 校验 JWT token
-func (s *Service) ValidateToken(token string) bool {
-	return auth.ValidateToken(token)
+// ValidateToken 通过认证提供者校验token
+func (s *Service) ValidateToken(ctx context.Context, token string) bool {
+	_, err := s.authProvider.Authenticate(ctx, token)
+	return err == nil
 }
 
 // HandleMessageACK 处理客户端的消息ACK确认
-func (s *Service) HandleMessageACK(ctx context.Context, wsMsg *rest.WSMessage) error {
-	// 从WebSocket消息中提取用户ID和消息ID
-	userID := wsMsg.From // 客户端发送ACK时，From字段是自己的用户ID
-	messageID := wsMsg.MessageId
-	ackID := wsMsg.AckId
+func (s *Service) HandleMessageACK(ctx context.Context, frame *rest.ControlFrame) error {
+	// 从控制帧中提取用户ID和消息ID
+	userID := frame.UserId
+	messageID := frame.MessageId
+	ackID := frame.AckId
 
 	log.Printf("收到客户端ACK: UserID=%d, MessageID=%d, AckID=%s", userID, messageID, ackID)
 
@@ -592,9 +804,9 @@ func (s *Service) HandleMessageACK(ctx context.Context, wsMsg *rest.WSMessage) e
 }
 
 // sendMessageViaUnaryCall 通过Logic服务单向调用发送消息
-func (s *Service) sendMessageViaUnaryCall(ctx context.Context, wsMsg *rest.WSMessage) error {
+func (s *Service) sendMessageViaUnaryCall(ctx context.Context, wsMsg *rest.WSMessage) (*rest.SendLogicMessageResponse, error) {
 	if s.logicClient == nil {
-		return fmt.Errorf("Logic服务客户端未初始化")
+		return nil, fmt.Errorf("Logic服务客户端未初始化")
 	}
 
 	log.Printf("通过Logic服务单向调用发送消息: From=%d, To=%d, GroupID=%d, Content=%s",
@@ -608,28 +820,32 @@ func (s *Service) sendMessageViaUnaryCall(ctx context.Context, wsMsg *rest.WSMes
 	resp, err := s.logicClient.SendMessage(ctx, req)
 	if err != nil {
 		log.Printf("Logic服务单向调用发送消息失败: %v", err)
-		return err
+		return nil, err
 	}
 
 	if !resp.Success {
 		log.Printf("Logic服务处理消息失败: %s", resp.Message)
-		return fmt.Errorf("Logic服务处理消息失败: %s", resp.Message)
+		return resp, fmt.Errorf("Logic服务处理消息失败: %s", resp.Message)
 	}
 
-	log.Printf("Logic服务单向调用发送消息成功: MessageID=%d, SuccessCount=%d",
-		resp.MessageId, resp.SuccessCount)
-	return nil
+	if resp.Queued {
+		log.Printf("消息已进入重试队列，等待持久化: MessageID=%d", resp.MessageId)
+	}
+
+	log.Printf("Logic服务单向调用发送消息成功: MessageID=%d, SuccessCount=%d, Delivered=%d, Offline=%d, Failed=%d",
+		resp.MessageId, resp.SuccessCount, resp.DeliveredCount, resp.OfflineCount, resp.FailureCount)
+	return resp, nil
 }
 
 // AddWebSocketConnection 添加WebSocket连接（兼容旧接口）
-func (s *Service) AddWebSocketConnection(userID int64, conn *websocket.Conn) {
+func (s *Service) AddWebSocketConnection(userID int64, conn *websocket.Conn, client model.ClientInfo) {
 	// 生成连接ID
 	timestamp := time.Now().Unix()
 	connID := fmt.Sprintf("conn-%d-%d", userID, timestamp)
 
 	// 使用新的连接管理器
 	ctx := context.Background()
-	if err := s.connMgr.AddConnection(ctx, userID, conn, connID, s.instanceID); err != nil {
+	if err := s.connMgr.AddConnection(ctx, userID, conn, connID, s.instanceID, client); err != nil {
 		log.Printf("添加WebSocket连接失败: %v", err)
 	}
 }
@@ -656,6 +872,7 @@ func (s *Service) subscribeConnectForward() {
 		payloadBytes, err := base64.StdEncoding.DecodeString(msg.Payload)
 		if err != nil {
 			log.Printf("推送消息base64解码失败: %v", err)
+			pushDropped.WithLabelValues(dropReasonDecode).Inc()
 			continue
 		}
 
@@ -663,38 +880,40 @@ func (s *Service) subscribeConnectForward() {
 		var gatewayMsg rest.GatewayMessage
 		if err := proto.Unmarshal(payloadBytes, &gatewayMsg); err != nil {
 			log.Printf("推送protobuf消息解析失败: %v", err)
+			pushDropped.WithLabelValues(dropReasonDecode).Inc()
 			continue
 		}
 
 		// 检查消息类型
-		if gatewayMsg.Type != "push_message" {
+		if gatewayMsg.Type != "push_message" && gatewayMsg.Type != model.GatewayMessageTypeEcho {
 			log.Printf("未知的推送消息类型: %v", gatewayMsg.Type)
+			pushDropped.WithLabelValues(dropReasonInvalid).Inc()
 			continue
 		}
 
 		// 检查消息内容
 		if gatewayMsg.Message == nil {
 			log.Printf("推送消息缺少message字段")
+			pushDropped.WithLabelValues(dropReasonInvalid).Inc()
 			continue
 		}
 
-		userID := gatewayMsg.TargetUser
+		// 丢弃重复和过期的转发消息
+		if ok, reason := s.forwardDedup.accept(&gatewayMsg); !ok {
+			log.Printf("丢弃%s的推送消息: Source=%s, Seq=%d, MessageID=%d",
+				reason, gatewayMsg.Source, gatewayMsg.Seq, gatewayMsg.Message.MessageId)
+			pushDropped.WithLabelValues(dropReasonDuplicate).Inc()
+			continue
+		}
 
-		// 推送到本地WebSocket连接
-		if conn, exists := s.connMgr.GetConnection(userID); exists {
-			msgBytes, err := proto.Marshal(gatewayMsg.Message)
-			if err != nil {
-				log.Printf("WebSocket推送protobuf序列化失败: %v", err)
-				continue
-			}
-			if err := conn.WriteMessage(websocket.BinaryMessage, msgBytes); err != nil {
-				log.Printf("WebSocket推送失败: %v", err)
-			} else {
-				log.Printf("WebSocket推送成功: UserID=%d, MessageID=%d", userID, gatewayMsg.Message.MessageId)
-			}
-		} else {
-			log.Printf("用户 %d 不在本地连接，无法推送", userID)
+		// 按发送者序号推送到本地WebSocket连接
+		// 回显消息直接推送：发送方各会话的序号相互独立，且本设备发出的消息不会回显，不能按序号等待
+		s.pushes.begin(gatewayMsg.Message, pushSourceForward)
+		if gatewayMsg.Type == model.GatewayMessageTypeEcho {
+			s.deliverToLocalConnection(gatewayMsg.TargetUser, gatewayMsg.Message)
+			continue
 		}
+		s.senderOrder.Push(gatewayMsg.TargetUser, gatewayMsg.Message)
 	}
 }
 
@@ -714,6 +933,7 @@ func (s *Service) subscribeGatewayUserMessage() {
 		payloadBytes, err := base64.StdEncoding.DecodeString(msg.Payload)
 		if err != nil {
 			log.Printf("Logic服务消息base64解码失败: %v", err)
+			pushDropped.WithLabelValues(dropReasonDecode).Inc()
 			continue
 		}
 
@@ -721,51 +941,64 @@ func (s *Service) subscribeGatewayUserMessage() {
 		var gatewayMsg rest.GatewayMessage
 		if err := proto.Unmarshal(payloadBytes, &gatewayMsg); err != nil {
 			log.Printf("Logic服务protobuf消息解析失败: %v", err)
+			pushDropped.WithLabelValues(dropReasonDecode).Inc()
 			continue
 		}
 
 		// 检查消息类型
 		if gatewayMsg.Type != "user_message" {
 			log.Printf("未知的Logic服务消息类型: %v", gatewayMsg.Type)
+			pushDropped.WithLabelValues(dropReasonInvalid).Inc()
 			continue
 		}
 
 		// 检查消息内容
 		if gatewayMsg.Message == nil {
 			log.Printf("Logic服务消息缺少message字段")
+			pushDropped.WithLabelValues(dropReasonInvalid).Inc()
 			continue
 		}
 
-		// 转发消息到目标用户
-		if err := s.forwardMessageToUser(ctx, gatewayMsg.Message); err != nil {
-			log.Printf("转发消息到用户失败: %v", err)
+		// 丢弃重复和过期的转发消息
+		if ok, reason := s.forwardDedup.accept(&gatewayMsg); !ok {
+			log.Printf("丢弃%s的Logic服务消息: Source=%s, Seq=%d, MessageID=%d",
+				reason, gatewayMsg.Source, gatewayMsg.Seq, gatewayMsg.Message.MessageId)
+			pushDropped.WithLabelValues(dropReasonDuplicate).Inc()
+			continue
 		}
+
+		// 转发消息到目标用户，同一发送者的消息按发送者序号顺序推送
+		s.pushes.begin(gatewayMsg.Message, pushSourceLocal)
+		s.senderOrder.Push(gatewayMsg.Message.To, gatewayMsg.Message)
 	}
 }
 
-// forwardMessageToUser 转发消息到指定用户的连接
-func (s *Service) forwardMessageToUser(ctx context.Context, wsMsg *rest.WSMessage) error {
-	userID := wsMsg.To
-
-	// 获取用户在本实例的WebSocket连接
+// deliverToLocalConnection 将消息写入用户在本实例的WebSocket连接，用户不在本实例时跳过
+func (s *Service) deliverToLocalConnection(userID int64, wsMsg *rest.WSMessage) {
 	conn, exists := s.connMgr.GetConnection(userID)
 	if !exists {
 		log.Printf("用户 %d 在本实例没有活跃连接", userID)
-		return nil
+		s.pushes.finish(wsMsg, dropReasonOffline)
+		return
 	}
 
-	// 序列化消息
-	messageBytes, err := proto.Marshal(wsMsg)
-	if err != nil {
-		return fmt.Errorf("序列化消息失败: %v", err)
+	// 按优先级通道放入连接的发送队列，由发送协程按连接协商的编码写入
+	lane := s.pushLane(wsMsg)
+	push := &queuedPush{
+		msg:  wsMsg,
+		lane: lane,
+		done: func(dropReason string) {
+			s.pushes.finish(wsMsg, dropReason)
+			if dropReason == "" {
+				log.Printf("消息已成功发送到用户 %d: MessageID=%d, SenderSeq=%d, Lane=%s", userID, wsMsg.MessageId, wsMsg.SenderSeq, lane)
+			}
+		},
 	}
-
-	// 发送消息到WebSocket连接
-	if err := conn.WriteMessage(websocket.TextMessage, messageBytes); err != nil {
-		log.Printf("向用户 %d 发送消息失败: %v", userID, err)
-		return err
+	if dropReason := enqueuePush(conn, s.config.Connect.PushLanes.QueueSize, push); dropReason != "" {
+		log.Printf("用户 %d 的%s通道无法入队，丢弃消息: MessageID=%d, reason=%s", userID, lane, wsMsg.MessageId, dropReason)
+		if dropReason == dropReasonQueueFull {
+			pushLaneTotal.WithLabelValues(lane, pushResultFull).Inc()
+		}
+		s.pushes.finish(wsMsg, dropReason)
 	}
-
-	log.Printf("消息已成功发送到用户 %d", userID)
-	return nil
 }
@@ -5,7 +5,6 @@ import (
 	"fmt"
 	"log"
 	"os"
-	"time"
 
 	"github.com/gin-gonic/gin"
 	"google.golang.org/grpc"
@@ -13,6 +12,7 @@ import (
 	"goim-social/api/rest"
 	"goim-social/apps/logic-service/internal/handler"
 	"goim-social/apps/logic-service/internal/service"
+	"goim-social/pkg/audit"
 	"goim-social/pkg/middleware"
 	"goim-social/pkg/server"
 	"goim-social/pkg/snowflake"
@@ -41,7 +41,7 @@ func main() {
 
 	// 确保在程序退出时关闭OpenTelemetry
 	defer func() {
-		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		ctx, cancel := context.WithTimeout(context.Background(), otelConfig.ShutdownTimeout)
 		defer cancel()
 		if err := telemetry.ShutdownGlobal(ctx); err != nil {
 			log.Printf("Failed to shutdown OpenTelemetry: %v", err)
@@ -70,6 +70,10 @@ func main() {
 	messageAddr := fmt.Sprintf("%s:%d", config.Services.MessageService.Host, config.Services.MessageService.Port)
 	userAddr := fmt.Sprintf("%s:%d", config.Services.UserService.Host, config.Services.UserService.Port)
 
+	// 初始化审计记录器，管理操作异步写入共享审计记录，退出前写完队列
+	auditor := audit.NewRecorder(audit.NewStore(app.GetRedisClient(), config.Audit.RetentionDays), serviceName)
+	app.RegisterShutdownHook("audit", auditor.Close)
+
 	// 初始化Service层
 	svc, err := service.NewService(
 		app.GetRedisClient(),
@@ -79,11 +83,34 @@ func main() {
 		socialAddr,
 		messageAddr,
 		userAddr,
+		config.Logic.PersistRetry,
+		config.Logic.SendQuota,
+		config.Logic.DeliveryBatch,
+		config.Logic.Fanout,
+		config.Logic.AttachmentScan,
+		config.Logic.AdminIDs,
+		config.Audit,
+		config.Deadline,
+		config.Presence,
+		auditor,
 	)
 	if err != nil {
 		panic("Failed to create logic service: " + err.Error())
 	}
 
+	// 退出时发布尚未发送的批量投递事件，须在Kafka Producer关闭之前
+	app.RegisterShutdownHook("delivery-events", svc.FlushDeliveryEvents)
+
+	// 接管其他实例退出前未完成的附件扫描
+	if config.Logic.AttachmentScan.Enabled {
+		scanCtx, stopScanRecovery := context.WithCancel(context.Background())
+		go svc.RunPendingScanRecovery(scanCtx)
+		app.RegisterShutdownHook("pending-scan-recovery", func(ctx context.Context) error {
+			stopScanRecovery()
+			return nil
+		})
+	}
+
 	// 创建OpenTelemetry中间件
 	otelMW := middleware.NewOTelMiddleware(serviceName, app.GetLogger())
 
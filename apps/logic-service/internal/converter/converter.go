@@ -5,6 +5,7 @@ import (
 
 	"goim-social/api/rest"
 	"goim-social/apps/logic-service/internal/model"
+	"goim-social/pkg/audit"
 )
 
 // Converter 转换器，提供Model到Protobuf的转换
@@ -113,7 +114,7 @@ func (c *Converter) determineChatType(msg *rest.WSMessage) int32 {
 // 响应构建方法
 
 // BuildSendLogicMessageResponse 构建发送逻辑消息响应
-func (c *Converter) BuildSendLogicMessageResponse(success bool, message string, messageID int64, successCount, failureCount int32, failedUsers []int64) *rest.SendLogicMessageResponse {
+func (c *Converter) BuildSendLogicMessageResponse(success bool, message string, messageID int64, successCount, failureCount int32, failedUsers []int64, queued bool) *rest.SendLogicMessageResponse {
 	return &rest.SendLogicMessageResponse{
 		Success:      success,
 		Message:      message,
@@ -121,6 +122,7 @@ func (c *Converter) BuildSendLogicMessageResponse(success bool, message string,
 		SuccessCount: successCount,
 		FailureCount: failureCount,
 		FailedUsers:  failedUsers,
+		Queued:       queued,
 	}
 }
 
@@ -136,7 +138,7 @@ func (c *Converter) BuildMessageAckResponse(success bool, message string) *rest.
 
 // BuildErrorSendLogicMessageResponse 构建发送逻辑消息错误响应
 func (c *Converter) BuildErrorSendLogicMessageResponse(message string) *rest.SendLogicMessageResponse {
-	return c.BuildSendLogicMessageResponse(false, message, 0, 0, 0, nil)
+	return c.BuildSendLogicMessageResponse(false, message, 0, 0, 0, nil, false)
 }
 
 // BuildErrorMessageAckResponse 构建消息ACK错误响应
@@ -148,14 +150,23 @@ func (c *Converter) BuildErrorMessageAckResponse(message string) *rest.MessageAc
 
 // BuildSuccessSendLogicMessageResponse 构建发送逻辑消息成功响应
 func (c *Converter) BuildSuccessSendLogicMessageResponse(result *model.MessageResult) *rest.SendLogicMessageResponse {
-	return c.BuildSendLogicMessageResponse(
+	resp := c.BuildSendLogicMessageResponse(
 		result.Success,
 		result.Message,
 		result.MessageID,
 		int32(result.SuccessCount),
 		int32(result.FailureCount),
 		result.FailedUsers,
+		result.Queued,
 	)
+	resp.DeliveredCount = int32(result.DeliveredCount)
+	resp.OfflineCount = int32(result.OfflineCount)
+	resp.OfflineUsers = result.OfflineUsers
+	resp.RejectReason = result.RejectReason
+	resp.SenderSeq = result.SenderSeq
+	resp.ServerTime = result.ServerTime
+	resp.ScanPending = result.ScanPending
+	return resp
 }
 
 // BuildSuccessMessageAckResponse 构建消息ACK成功响应
@@ -174,6 +185,7 @@ func (c *Converter) BuildSendLogicMessageResponseFromForwardResult(result *model
 		int32(result.SuccessCount),
 		int32(result.FailureCount),
 		result.FailedUsers,
+		false,
 	)
 }
 
@@ -182,9 +194,9 @@ func (c *Converter) BuildSendLogicMessageResponseFromForwardResult(result *model
 // BuildSendLogicMessageResponseFromRouteResult 从RouteResult构建发送逻辑消息响应
 func (c *Converter) BuildSendLogicMessageResponseFromRouteResult(result *model.RouteResult) *rest.SendLogicMessageResponse {
 	if result.Success {
-		return c.BuildSendLogicMessageResponse(true, result.Message, result.MessageID, 1, 0, nil)
+		return c.BuildSendLogicMessageResponse(true, result.Message, result.MessageID, 1, 0, nil, false)
 	}
-	return c.BuildSendLogicMessageResponse(false, result.Message, result.MessageID, 0, 1, result.TargetUsers)
+	return c.BuildSendLogicMessageResponse(false, result.Message, result.MessageID, 0, 1, result.TargetUsers, false)
 }
 
 // HTTP响应构建方法（用于测试接口）
@@ -198,6 +210,12 @@ func (c *Converter) BuildHTTPRouteMessageResponse(result *model.MessageResult) m
 		"success_count": result.SuccessCount,
 		"failure_count": result.FailureCount,
 		"failed_users":  result.FailedUsers,
+		"queued":        result.Queued,
+
+		"delivered_count": result.DeliveredCount,
+		"offline_count":   result.OfflineCount,
+		"offline_users":   result.OfflineUsers,
+		"scan_pending":    result.ScanPending,
 	}
 }
 
@@ -209,11 +227,56 @@ func (c *Converter) BuildHTTPErrorResponse(message string) map[string]interface{
 	}
 }
 
-// BuildHTTPHealthResponse 构建HTTP健康检查响应
-func (c *Converter) BuildHTTPHealthResponse(service string, timestamp int64) map[string]interface{} {
+// BuildHTTPReadReceiptSettingsResponse 构建已读回执设置响应
+func (c *Converter) BuildHTTPReadReceiptSettingsResponse(userID int64, enabled bool) map[string]interface{} {
+	return map[string]interface{}{
+		"success": true,
+		"message": "已读回执设置已更新",
+		"data": map[string]interface{}{
+			"user_id": userID,
+			"enabled": enabled,
+		},
+	}
+}
+
+// BuildHTTPAuditLogResponse 构建审计记录查询响应
+func (c *Converter) BuildHTTPAuditLogResponse(entries []*audit.Entry) map[string]interface{} {
+	return map[string]interface{}{
+		"success": true,
+		"message": "查询审计记录成功",
+		"entries": entries,
+		"count":   len(entries),
+	}
+}
+
+// BuildHTTPHealthResponse 构建HTTP健康检查响应，投递链路异常或无法检查时整体状态随之降级
+func (c *Converter) BuildHTTPHealthResponse(service string, timestamp int64, delivery *rest.GetDeliveryStatusResponse, deliveryErr error, fanoutPool *model.FanoutPoolStat) map[string]interface{} {
+	status := model.HealthStatusOK
+	dependency := map[string]interface{}{}
+	switch {
+	case deliveryErr != nil:
+		status = model.HealthStatusUnhealthy
+		dependency["status"] = model.HealthStatusUnhealthy
+		dependency["error"] = deliveryErr.Error()
+	case delivery.Status == "unhealthy":
+		status = model.HealthStatusUnhealthy
+	case delivery.Status == "degraded":
+		status = model.HealthStatusDegraded
+	}
+	if delivery != nil {
+		dependency["status"] = delivery.Status
+		dependency["problems"] = delivery.Problems
+		dependency["subscribed_gateways"] = delivery.SubscribedGateways
+		dependency["pending_ack"] = delivery.PendingAck
+	}
+
 	return map[string]interface{}{
-		"status":    "ok",
+		"status":    status,
 		"service":   service,
 		"timestamp": timestamp,
+		"dependencies": map[string]interface{}{
+			"message_delivery": dependency,
+		},
+		"fanout_pool": fanoutPool,
 	}
 }
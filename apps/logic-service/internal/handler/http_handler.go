@@ -28,7 +28,10 @@ func NewHTTPHandler(svc *service.Service, log logger.Logger) *HTTPHandler {
 func (h *HTTPHandler) RegisterRoutes(r *gin.Engine) {
 	api := r.Group("/api/v1/logic")
 	{
-		api.POST("/health", h.HealthCheck) // 健康检查
-		api.POST("/route", h.RouteMessage) // 消息路由测试
+		api.POST("/health", h.HealthCheck)                               // 健康检查
+		api.POST("/route", h.RouteMessage)                               // 消息路由测试
+		api.POST("/broadcast", h.Broadcast)                              // 管理员广播系统消息
+		api.POST("/audit/query", h.QueryAuditLog)                        // 管理员查询审计记录
+		api.POST("/read-receipts/settings", h.UpdateReadReceiptSettings) // 更新用户已读回执设置
 	}
 }
@@ -4,6 +4,7 @@ import (
 	"github.com/gin-gonic/gin"
 
 	"goim-social/api/rest"
+	"goim-social/pkg/audit"
 	tracecontext "goim-social/pkg/context"
 	"goim-social/pkg/httpx"
 	"goim-social/pkg/logger"
@@ -25,6 +26,7 @@ func (h *HTTPHandler) RouteMessage(c *gin.Context) {
 		Content     string `json:"content" binding:"required"`
 		MessageType int32  `json:"message_type"`
 		ChatType    int32  `json:"chat_type" binding:"required"`
+		TTL         int64  `json:"ttl"` // 消息有效期（秒），0表示使用会话默认设置
 	}
 
 	if err = c.Bind(&req); err != nil {
@@ -47,6 +49,7 @@ func (h *HTTPHandler) RouteMessage(c *gin.Context) {
 		GroupId:     req.GroupID,
 		Content:     req.Content,
 		MessageType: req.MessageType,
+		Ttl:         req.TTL,
 	}
 
 	// 处理消息
@@ -62,8 +65,134 @@ func (h *HTTPHandler) RouteMessage(c *gin.Context) {
 	httpx.WriteObject(c, resp, err)
 }
 
-// HealthCheck 健康检查
+// Broadcast 管理员广播系统消息（内部接口）
+func (h *HTTPHandler) Broadcast(c *gin.Context) {
+	var (
+		ctx  = c.Request.Context()
+		resp interface{}
+		err  error
+	)
+
+	var req struct {
+		OperatorID int64  `json:"operator_id" binding:"required"`
+		Scope      string `json:"scope" binding:"required"` // group / all
+		GroupID    int64  `json:"group_id"`
+		Content    string `json:"content" binding:"required"`
+	}
+
+	if err = c.Bind(&req); err != nil {
+		h.logger.Error(ctx, "Invalid broadcast request", logger.F("error", err.Error()))
+		resp = h.converter.BuildHTTPErrorResponse("请求参数错误: " + err.Error())
+		httpx.WriteObject(c, resp, err)
+		return
+	}
+
+	result, err := h.svc.Broadcast(ctx, req.OperatorID, req.Scope, req.GroupID, req.Content)
+	if err != nil {
+		h.logger.Error(ctx, "Broadcast failed",
+			logger.F("operatorID", req.OperatorID),
+			logger.F("scope", req.Scope),
+			logger.F("error", err.Error()))
+		resp = h.converter.BuildHTTPErrorResponse("广播失败: " + err.Error())
+		httpx.WriteObject(c, resp, err)
+		return
+	}
+
+	resp = h.converter.BuildHTTPRouteMessageResponse(result)
+	httpx.WriteObject(c, resp, err)
+}
+
+// QueryAuditLog 管理员查询审计记录（内部接口），时间为秒级时间戳
+func (h *HTTPHandler) QueryAuditLog(c *gin.Context) {
+	var (
+		ctx  = c.Request.Context()
+		resp interface{}
+		err  error
+	)
+
+	var req struct {
+		OperatorID int64  `json:"operator_id" binding:"required"`
+		ActorID    int64  `json:"actor_id"`
+		TargetType string `json:"target_type"`
+		TargetID   int64  `json:"target_id"`
+		Action     string `json:"action"`
+		StartTime  int64  `json:"start_time"`
+		EndTime    int64  `json:"end_time"`
+		Limit      int    `json:"limit"`
+	}
+
+	if err = c.Bind(&req); err != nil {
+		h.logger.Error(ctx, "Invalid query audit log request", logger.F("error", err.Error()))
+		resp = h.converter.BuildHTTPErrorResponse("请求参数错误: " + err.Error())
+		httpx.WriteObject(c, resp, err)
+		return
+	}
+
+	entries, err := h.svc.QueryAuditLog(ctx, req.OperatorID, audit.Filter{
+		ActorID:    req.ActorID,
+		TargetType: req.TargetType,
+		TargetID:   req.TargetID,
+		Action:     req.Action,
+		StartTime:  req.StartTime * 1000,
+		EndTime:    req.EndTime * 1000,
+		Limit:      req.Limit,
+	})
+	if err != nil {
+		h.logger.Error(ctx, "Query audit log failed",
+			logger.F("operatorID", req.OperatorID),
+			logger.F("error", err.Error()))
+		resp = h.converter.BuildHTTPErrorResponse("查询审计记录失败: " + err.Error())
+		httpx.WriteObject(c, resp, err)
+		return
+	}
+
+	resp = h.converter.BuildHTTPAuditLogResponse(entries)
+	httpx.WriteObject(c, resp, err)
+}
+
+// UpdateReadReceiptSettings 更新用户已读回执设置，关闭后既不发送也不接收已读回执
+func (h *HTTPHandler) UpdateReadReceiptSettings(c *gin.Context) {
+	var (
+		ctx  = c.Request.Context()
+		resp interface{}
+		err  error
+	)
+
+	var req struct {
+		UserID  int64 `json:"user_id" binding:"required"`
+		Enabled *bool `json:"enabled" binding:"required"`
+	}
+
+	if err = c.Bind(&req); err != nil {
+		h.logger.Error(ctx, "Invalid read receipt settings request", logger.F("error", err.Error()))
+		resp = h.converter.BuildHTTPErrorResponse("请求参数错误: " + err.Error())
+		httpx.WriteObject(c, resp, err)
+		return
+	}
+
+	// 将业务信息添加到context
+	ctx = tracecontext.WithUserID(ctx, req.UserID)
+
+	if err = h.svc.SetReadReceiptsEnabled(ctx, req.UserID, *req.Enabled); err != nil {
+		h.logger.Error(ctx, "Update read receipt settings failed",
+			logger.F("userID", req.UserID),
+			logger.F("error", err.Error()))
+		resp = h.converter.BuildHTTPErrorResponse(err.Error())
+		httpx.WriteObject(c, resp, err)
+		return
+	}
+
+	resp = h.converter.BuildHTTPReadReceiptSettingsResponse(req.UserID, *req.Enabled)
+	httpx.WriteObject(c, resp, err)
+}
+
+// HealthCheck 健康检查，包含Message服务投递链路状态和扇出推送池状态
 func (h *HTTPHandler) HealthCheck(c *gin.Context) {
-	resp := h.converter.BuildHTTPHealthResponse("logic-service", utils.GetCurrentTimestamp())
+	ctx := c.Request.Context()
+	delivery, err := h.svc.CheckMessageDelivery(ctx)
+	if err != nil {
+		h.logger.Warn(ctx, "Message delivery check failed", logger.F("error", err.Error()))
+	}
+	resp := h.converter.BuildHTTPHealthResponse("logic-service", utils.GetCurrentTimestamp(), delivery, err, h.svc.FanoutPoolStats())
 	httpx.WriteObject(c, resp, nil)
 }
@@ -13,6 +13,121 @@ const (
 	MessageTypeAudio = 3 // 语音消息
 	MessageTypeVideo = 4 // 视频消息
 	MessageTypeFile  = 5 // 文件消息
+	MessageTypeShare = 6 // 内容分享消息，content为content-service生成的分享卡片JSON
+	MessageTypePoll  = 7 // 群投票消息，content为投票JSON，仅限群聊
+
+	MessageTypeSystem      = 100 // 系统消息（管理员广播），客户端需区别渲染
+	MessageTypeReadReceipt = 106 // 已读回执，推送给原消息发送方，message_id为被读的消息，from为读者
+	MessageTypeSendFailed  = 110 // 发送失败通知，扫描中的媒体消息未能投递时推送给发送方，content为SendFailedNotice JSON
+
+	MaxTextMessageLength  = 5000 // 文本消息最大字符数
+	MaxMediaMessageLength = 2048 // 媒体消息content（媒体地址或描述信息）最大字节数
+	MaxShareMessageLength = 2048 // 分享消息content（分享卡片JSON）最大字节数
+
+	MinPollOptions        = 2   // 投票最少选项数
+	MaxPollOptions        = 10  // 投票最多选项数
+	MaxPollQuestionLength = 200 // 投票问题最大字符数
+	MaxPollOptionLength   = 100 // 投票选项最大字符数
+)
+
+// 发送拒绝原因，随发送结果返回给网关，网关在发送确认中转告发送方
+const (
+	RejectReasonInvalidMessage = "invalid_message" // 消息类型、内容或目标不合法
+	RejectReasonRateLimited    = "rate_limited"    // 超出发送配额或被反垃圾临时限制
+	RejectReasonNotGroupMember = "not_group_member"
+	RejectReasonNotFriend      = "not_friend"
+	RejectReasonBlocked        = "blocked" // 被对方拉黑
+
+	RejectReasonAttachmentRejected    = "attachment_rejected"    // 附件未通过扫描（大小、类型或病毒）
+	RejectReasonAttachmentQuarantined = "attachment_quarantined" // 附件被隔离待人工复核
+)
+
+// SystemSenderID 系统消息发送者ID
+const SystemSenderID = 0
+
+// 消息持久化相关常量
+const (
+	TopicMessagePersistence      = "message_persistence_log"   // 持久化Topic
+	TopicMessagePersistenceRetry = "message_persistence_retry" // 持久化重试Topic，按会话分区保证顺序，与持久化Topic位于同一集群
+
+	RedisKeyPersistRetryPending = "persist_retry:pending" // 会话存在排队消息标记 persist_retry:pending:{conversationKey}
+	PersistRetryStickyTTL       = 60                      // 排队标记有效期（秒），期间同会话消息都走重试队列
+)
+
+// 发送者消息顺序相关常量
+const (
+	RedisKeySenderSeq = "sender_seq"  // 发送者在会话内的递增序号 sender_seq:{conversationID}:{senderID}
+	SenderSeqTTL      = 7 * 24 * 3600 // 序号有效期（秒），每次发送时续期，须远大于网关发送流的空闲过期时间
+)
+
+// 已读回执相关常量
+const (
+	RedisKeyReadReceiptsDisabled = "read_receipt:disabled" // 关闭已读回执的用户集合，不在集合中即为开启
+)
+
+// 广播相关常量
+const (
+	BroadcastScopeGroup = "group" // 广播到指定群组
+	BroadcastScopeAll   = "all"   // 广播到所有在线用户
+
+	BroadcastChunkSize     = 500 // 全员广播每批投递用户数
+	BroadcastChunkInterval = 100 // 全员广播批次间隔（毫秒）
+	BroadcastRateLimit     = 30  // 同一操作者两次广播最小间隔（秒）
+
+	RedisKeyOnlineUsers        = "online_users"
+	RedisKeyBroadcastRateLimit = "broadcast:rate_limit" // 广播限流 broadcast:rate_limit:{operatorID}
+)
+
+// 扇出投递相关常量
+const (
+	DeliveryStatusDelivered     = "delivered"      // 已推送到成员所在网关
+	DeliveryStatusQueuedOffline = "queued_offline" // 成员离线，消息已持久化等待上线拉取
+	DeliveryStatusFailed        = "failed"         // 重试后仍投递失败
+
+	FanoutMaxRetries   = 2  // 单个成员投递失败后的重试次数
+	FanoutRetryBackoff = 50 // 重试退避基数（毫秒），按重试次数线性增长
+
+	TopicDeliveryEvents = "message_delivery_events" // 成员级投递结果事件
+
+	DefaultDeliveryBatchWindow  = 200  // 未配置合并窗口时的默认值（毫秒）
+	MaxDeliveryBatchWindow      = 1000 // 合并窗口上限（毫秒），避免投递跟踪滞后
+	DefaultDeliveryBatchMaxSize = 500  // 未配置批量大小时的默认值
+
+	DefaultFanoutWorkers    = 64 // 未配置全局扇出并发数时的默认值
+	DefaultFanoutPerMessage = 16 // 未配置单条消息扇出并发数时的默认值
+)
+
+// 发送配额与反垃圾相关常量
+const (
+	RedisKeySendQuotaHourly = "send_quota:hourly" // 每小时发送计数 send_quota:hourly:{userID}:{yyyyMMddHH}
+	RedisKeySendQuotaDaily  = "send_quota:daily"  // 每日发送计数 send_quota:daily:{userID}:{yyyyMMdd}
+	RedisKeySpamRate        = "spam:rate"         // 窗口内发送条数 spam:rate:{userID}:{bucket}
+	RedisKeySpamDuplicate   = "spam:dup"          // 窗口内各内容发送次数（Hash） spam:dup:{userID}:{bucket}
+	RedisKeySpamRecipients  = "spam:rcpt"         // 窗口内接收方集合 spam:rcpt:{userID}:{bucket}
+	RedisKeySendBlocked     = "spam:blocked"      // 临时禁止发送标记 spam:blocked:{userID}
+
+	DefaultSpamWindow = 60 // 未配置统计窗口时的默认值（秒）
+
+	TopicSpamFlagEvents = "message_spam_flags" // 垃圾发送者标记事件
+)
+
+// 附件扫描相关常量
+const (
+	ScanVerdictAllow      = "allow"      // 放行
+	ScanVerdictReject     = "reject"     // 拒绝
+	ScanVerdictQuarantine = "quarantine" // 隔离，消息不投递，附件待人工复核
+
+	DefaultAttachmentScanSyncWait = 500   // 未配置时同步等待扫描结果的时长（毫秒）
+	DefaultAttachmentScanTimeout  = 30000 // 未配置时单次扫描最长时间（毫秒）
+	PendingScanDeliverTimeout     = 30    // 扫描通过后投递待扫描消息的超时（秒）
+	PendingScanRecoverInterval    = 30    // 检查未完成的待扫描消息的间隔（秒）
+	PendingScanRecoverGrace       = 30    // 扫描和投递超时之外再等待的时长（秒），之后视为处理该消息的实例已退出
+	PendingScanBatchSize          = 100   // 每批接管的待扫描消息数
+
+	RedisKeyPendingScans    = "scan:pending"      // 待扫描消息的接管时间（ZSet），成员为消息ID，分数为Unix毫秒
+	RedisKeyPendingScanData = "scan:pending:data" // 待扫描消息内容（Hash），字段为消息ID，值为PendingScan JSON
+
+	TopicAttachmentQuarantine = "message_attachment_quarantine" // 附件隔离事件
 )
 
 // MessageStatus 消息状态常量
@@ -23,3 +138,12 @@ const (
 	MessageStatusDelivered = 2  // 已送达
 	MessageStatusRead      = 3  // 已读
 )
+
+// 健康检查相关常量
+const (
+	HealthStatusOK        = "ok"
+	HealthStatusDegraded  = "degraded"
+	HealthStatusUnhealthy = "unhealthy"
+
+	DependencyCheckTimeout = 2 // 依赖检查超时（秒）
+)
@@ -77,4 +77,108 @@ type MessageResult struct {
 	SuccessCount int     `json:"success_count"`
 	FailureCount int     `json:"failure_count"`
 	FailedUsers  []int64 `json:"failed_users"`
+	Queued       bool    `json:"queued"` // 持久化进入重试队列，尚未落地
+
+	DeliveredCount int     `json:"delivered_count"` // 已推送到在线成员的数量
+	OfflineCount   int     `json:"offline_count"`   // 离线成员数量，消息已持久化等待上线拉取
+	OfflineUsers   []int64 `json:"offline_users"`
+
+	RejectReason string `json:"reject_reason,omitempty"` // 消息被拒绝时的原因，为空表示已受理
+	SenderSeq    int64  `json:"sender_seq"`              // 分配的发送者序号
+	ServerTime   int64  `json:"server_time"`             // 服务端受理时间（秒级时间戳）
+	ScanPending  bool   `json:"scan_pending"`            // 附件扫描中，扫描通过后再投递
+}
+
+// DeliveryEvent 成员级投递结果事件
+type DeliveryEvent struct {
+	MessageID int64  `json:"message_id"`
+	GroupID   int64  `json:"group_id"`
+	UserID    int64  `json:"user_id"`
+	SenderID  int64  `json:"sender_id,omitempty"` // 消息发送者，用于向发送者汇总投递和已读情况
+	Status    string `json:"status"`              // delivered / queued_offline / failed
+	Attempts  int    `json:"attempts"`
+	Error     string `json:"error,omitempty"`
+	Timestamp int64  `json:"timestamp"`
+}
+
+// FanoutPoolStat 扇出推送池状态
+type FanoutPoolStat struct {
+	Size       int   `json:"size"`        // 全局并发上限
+	PerMessage int   `json:"per_message"` // 单条消息并发上限
+	Active     int64 `json:"active"`      // 正在进行的推送数
+	Waiting    int64 `json:"waiting"`     // 等待空闲槽位的推送数，即队列深度
+}
+
+// DeliveryEventBatch 批量投递结果事件，与单条事件发布到同一Topic，消费方以events字段区分
+type DeliveryEventBatch struct {
+	Events    []*DeliveryEvent `json:"events"`
+	Count     int              `json:"count"`
+	Timestamp int64            `json:"timestamp"` // 批次发布时间
+}
+
+// BroadcastAudit 广播审计记录
+type BroadcastAudit struct {
+	MessageID    int64  `json:"message_id"`
+	OperatorID   int64  `json:"operator_id"`
+	Scope        string `json:"scope"`    // group / all
+	GroupID      int64  `json:"group_id"` // 群组广播时的群组ID
+	Content      string `json:"content"`
+	SuccessCount int    `json:"success_count"`
+	FailureCount int    `json:"failure_count"`
+	Total        int    `json:"total"`           // 目标用户数，全员广播为受理时的在线用户数
+	Completed    bool   `json:"completed"`       // 是否投递完成，为false时成功和失败数为中断前的进度
+	Error        string `json:"error,omitempty"` // 广播失败或中断的原因
+	CreatedAt    int64  `json:"created_at"`
+}
+
+// SpamFlagEvent 用户被判定为垃圾发送者的标记事件，供人工复核
+type SpamFlagEvent struct {
+	UserID         int64   `json:"user_id"`
+	MessageID      int64   `json:"message_id"` // 触发标记的消息ID
+	Score          float64 `json:"score"`
+	RateCount      int64   `json:"rate_count"`      // 统计窗口内发送条数
+	DuplicateCount int64   `json:"duplicate_count"` // 统计窗口内同一内容重复次数
+	RecipientCount int64   `json:"recipient_count"` // 统计窗口内不同接收方数
+	BlockDuration  int     `json:"block_duration"`  // 临时禁止发送时长（秒）
+	Timestamp      int64   `json:"timestamp"`
+}
+
+// Attachment 媒体消息引用的附件，由消息content解析，未声明的字段为零值
+type Attachment struct {
+	URL      string `json:"url"`
+	Name     string `json:"name,omitempty"`
+	Size     int64  `json:"size,omitempty"`      // 字节数
+	MimeType string `json:"mime_type,omitempty"` // 未声明时按文件扩展名推断
+}
+
+// ScanResult 附件扫描结果
+type ScanResult struct {
+	Verdict string `json:"verdict"` // allow / reject / quarantine
+	Reason  string `json:"reason,omitempty"`
+}
+
+// SendFailedNotice 发送失败通知内容，推送给待扫描消息的发送方
+type SendFailedNotice struct {
+	MessageID   int64  `json:"message_id"`
+	ClientMsgID string `json:"client_msg_id,omitempty"`
+	Reason      string `json:"reason"`  // 与发送确认的拒绝原因一致
+	Message     string `json:"message"` // 可展示的说明
+}
+
+// PendingScan 持久化的待扫描消息，实例重启后由任一实例重新扫描并投递或通知发送方
+type PendingScan struct {
+	Message    []byte      `json:"message"` // WSMessage的protobuf编码
+	Attachment *Attachment `json:"attachment"`
+	QueuedAt   int64       `json:"queued_at"` // 转为待扫描的时间（Unix秒）
+}
+
+// AttachmentQuarantineEvent 附件隔离事件，供人工复核
+type AttachmentQuarantineEvent struct {
+	MessageID  int64       `json:"message_id"`
+	SenderID   int64       `json:"sender_id"`
+	To         int64       `json:"to,omitempty"`
+	GroupID    int64       `json:"group_id,omitempty"`
+	Attachment *Attachment `json:"attachment"`
+	Reason     string      `json:"reason"`
+	Timestamp  int64       `json:"timestamp"`
 }
@@ -0,0 +1,573 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	goredis "github.com/go-redis/redis/v8"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"google.golang.org/protobuf/proto"
+
+	"goim-social/api/rest"
+	"goim-social/apps/logic-service/internal/model"
+	"goim-social/pkg/config"
+	"goim-social/pkg/logger"
+	"goim-social/pkg/telemetry"
+)
+
+// AttachmentScanner 媒体消息附件扫描钩子，在消息持久化和投递之前调用
+// 返回错误表示扫描本身失败（如扫描服务不可用），按配置放行或拒绝；ctx到期时应尽快返回
+type AttachmentScanner interface {
+	Scan(ctx context.Context, attachment *model.Attachment) (*model.ScanResult, error)
+}
+
+// SetAttachmentScanner 替换附件扫描钩子，须在开始处理消息之前调用，传入nil恢复为不扫描
+func (s *Service) SetAttachmentScanner(scanner AttachmentScanner) {
+	if scanner == nil {
+		scanner = noopAttachmentScanner{}
+	}
+	s.attachmentScanner = scanner
+}
+
+// newAttachmentScanner 根据配置创建附件扫描钩子，未启用时不扫描
+// 先检查大小和类型，配置了外部扫描服务时再调用外部服务，任一环节未放行即返回
+func newAttachmentScanner(cfg config.AttachmentScanConfig) AttachmentScanner {
+	if !cfg.Enabled {
+		return noopAttachmentScanner{}
+	}
+	scanners := chainAttachmentScanner{attachmentPolicyScanner{maxSize: cfg.MaxSize, allowedTypes: cfg.AllowedTypes, client: &http.Client{}}}
+	if cfg.ScanURL != "" {
+		scanners = append(scanners, &httpAttachmentScanner{url: cfg.ScanURL, client: &http.Client{}})
+	}
+	return scanners
+}
+
+// scanAttachment 扫描媒体消息的附件，返回非nil时消息不再继续处理：
+// 未通过扫描时返回拒绝结果；同步等待时间内未完成时返回待扫描结果，扫描在后台继续，完成后投递或通知发送方。
+// 待扫描消息同时持久化到Redis，本实例在完成前退出时由其他实例在接管时间后重新扫描
+func (s *Service) scanAttachment(ctx context.Context, msg *rest.WSMessage) *model.MessageResult {
+	if _, disabled := s.attachmentScanner.(noopAttachmentScanner); disabled {
+		return nil
+	}
+
+	ctx, span := telemetry.StartSpan(ctx, "logic.service.scanAttachment")
+	defer span.End()
+	span.SetAttributes(attribute.Int64("message.id", msg.MessageId))
+
+	attachment := parseAttachment(msg.Content)
+	done := make(chan *model.ScanResult, 1)
+	scanCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), s.attachmentScanTimeout())
+	go func() {
+		defer cancel()
+		done <- s.runAttachmentScan(scanCtx, attachment)
+	}()
+
+	select {
+	case result := <-done:
+		span.SetAttributes(attribute.String("scan.verdict", result.Verdict))
+		if result.Verdict == model.ScanVerdictAllow {
+			return nil
+		}
+		span.SetStatus(codes.Error, "attachment not allowed")
+		message, reason := s.handleScanVerdict(ctx, msg, attachment, result)
+		return rejectedResult(msg, message, reason)
+	case <-time.After(s.attachmentScanSyncWait()):
+	}
+
+	span.SetAttributes(attribute.Bool("scan.pending", true))
+	s.logger.Info(ctx, "附件扫描未在同步等待时间内完成，消息转为待扫描",
+		logger.F("messageID", msg.MessageId),
+		logger.F("from", msg.From))
+	s.savePendingScan(ctx, msg, attachment)
+	go s.finishPendingScan(context.WithoutCancel(ctx), msg, attachment, done)
+
+	return &model.MessageResult{
+		Success:     true,
+		Message:     "附件扫描中，扫描通过后投递",
+		MessageID:   msg.MessageId,
+		ServerTime:  time.Now().Unix(),
+		ScanPending: true,
+	}
+}
+
+// finishPendingScan 等待后台扫描完成后处理扫描结果
+func (s *Service) finishPendingScan(ctx context.Context, msg *rest.WSMessage, attachment *model.Attachment, done <-chan *model.ScanResult) {
+	s.completePendingScan(ctx, msg, attachment, <-done)
+}
+
+// completePendingScan 扫描通过时投递消息，未通过或投递被拒绝时向发送方推送发送失败通知，完成后删除持久化记录
+func (s *Service) completePendingScan(ctx context.Context, msg *rest.WSMessage, attachment *model.Attachment, result *model.ScanResult) {
+	defer s.clearPendingScan(ctx, msg.MessageId)
+
+	if result.Verdict != model.ScanVerdictAllow {
+		message, reason := s.handleScanVerdict(ctx, msg, attachment, result)
+		s.notifySendFailed(ctx, msg, reason, message)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, model.PendingScanDeliverTimeout*time.Second)
+	defer cancel()
+
+	var deliverResult *model.MessageResult
+	var err error
+	if msg.GroupId > 0 {
+		deliverResult, err = s.processGroupMessage(ctx, msg)
+	} else {
+		deliverResult, err = s.processPrivateMessage(ctx, msg)
+	}
+
+	switch {
+	case err != nil:
+		s.logger.Error(ctx, "投递扫描通过的消息失败",
+			logger.F("messageID", msg.MessageId),
+			logger.F("error", err.Error()))
+		s.notifySendFailed(ctx, msg, "", "消息发送失败，请重新发送")
+	case deliverResult.RejectReason != "":
+		s.notifySendFailed(ctx, msg, deliverResult.RejectReason, deliverResult.Message)
+	}
+}
+
+// RunPendingScanRecovery 定期接管超过接管时间仍未完成的待扫描消息，直到ctx取消
+func (s *Service) RunPendingScanRecovery(ctx context.Context) {
+	ticker := time.NewTicker(model.PendingScanRecoverInterval * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.recoverPendingScans(ctx)
+		}
+	}
+}
+
+// recoverPendingScans 分批认领到达接管时间的待扫描消息，多实例下移除成功的实例负责该消息
+// 认领后先延后接管时间再处理，处理期间本实例退出时该消息仍会被再次接管
+func (s *Service) recoverPendingScans(ctx context.Context) {
+	if s.redis == nil {
+		return
+	}
+
+	client := s.redis.GetClient()
+	until := strconv.FormatInt(time.Now().UnixMilli(), 10)
+	for ctx.Err() == nil {
+		members, err := s.redis.ZRangeByScore(ctx, model.RedisKeyPendingScans, &goredis.ZRangeBy{
+			Min:   "-inf",
+			Max:   until,
+			Count: model.PendingScanBatchSize,
+		})
+		if err != nil {
+			s.logger.Warn(ctx, "查询待接管的扫描消息失败", logger.F("error", err.Error()))
+			return
+		}
+
+		for _, member := range members {
+			claimed, err := client.ZRem(ctx, model.RedisKeyPendingScans, member).Result()
+			if err != nil || claimed == 0 {
+				continue
+			}
+			s.recoverPendingScan(ctx, member)
+		}
+		if len(members) < model.PendingScanBatchSize {
+			return
+		}
+	}
+}
+
+// recoverPendingScan 重新扫描一条已认领的待扫描消息并投递或通知发送方
+func (s *Service) recoverPendingScan(ctx context.Context, member string) {
+	data, err := s.redis.GetClient().HGet(ctx, model.RedisKeyPendingScanData, member).Result()
+	if err != nil {
+		if err != goredis.Nil {
+			s.logger.Warn(ctx, "读取待扫描消息失败", logger.F("messageID", member), logger.F("error", err.Error()))
+		}
+		return
+	}
+	msg, attachment, err := decodePendingScan([]byte(data))
+	if err != nil {
+		s.logger.Error(ctx, "解析待扫描消息失败", logger.F("messageID", member), logger.F("error", err.Error()))
+		s.redis.GetClient().HDel(ctx, model.RedisKeyPendingScanData, member)
+		return
+	}
+
+	if err := s.redis.ZAdd(ctx, model.RedisKeyPendingScans, &goredis.Z{
+		Score:  float64(s.pendingScanDeadline(time.Now()).UnixMilli()),
+		Member: member,
+	}); err != nil {
+		s.logger.Warn(ctx, "延后待扫描消息接管时间失败", logger.F("messageID", member), logger.F("error", err.Error()))
+	}
+
+	s.logger.Info(ctx, "接管未完成的待扫描消息",
+		logger.F("messageID", msg.MessageId),
+		logger.F("from", msg.From))
+	scanCtx, cancel := context.WithTimeout(ctx, s.attachmentScanTimeout())
+	result := s.runAttachmentScan(scanCtx, attachment)
+	cancel()
+	s.completePendingScan(context.WithoutCancel(ctx), msg, attachment, result)
+}
+
+// savePendingScan 持久化待扫描消息，失败时只记录日志，消息仍由本实例在后台完成扫描
+func (s *Service) savePendingScan(ctx context.Context, msg *rest.WSMessage, attachment *model.Attachment) {
+	if s.redis == nil {
+		return
+	}
+
+	data, err := encodePendingScan(msg, attachment, time.Now())
+	if err == nil {
+		field := strconv.FormatInt(msg.MessageId, 10)
+		err = s.redis.HSet(ctx, model.RedisKeyPendingScanData, field, string(data))
+		if err == nil {
+			err = s.redis.ZAdd(ctx, model.RedisKeyPendingScans, &goredis.Z{
+				Score:  float64(s.pendingScanDeadline(time.Now()).UnixMilli()),
+				Member: field,
+			})
+		}
+	}
+	if err != nil {
+		s.logger.Warn(ctx, "持久化待扫描消息失败，实例退出时该消息不会被接管",
+			logger.F("messageID", msg.MessageId),
+			logger.F("error", err.Error()))
+	}
+}
+
+// clearPendingScan 删除待扫描消息的持久化记录
+func (s *Service) clearPendingScan(ctx context.Context, messageID int64) {
+	if s.redis == nil {
+		return
+	}
+
+	field := strconv.FormatInt(messageID, 10)
+	if err := s.redis.ZRem(ctx, model.RedisKeyPendingScans, field); err != nil {
+		s.logger.Warn(ctx, "删除待扫描消息记录失败", logger.F("messageID", messageID), logger.F("error", err.Error()))
+		return
+	}
+	s.redis.GetClient().HDel(ctx, model.RedisKeyPendingScanData, field)
+}
+
+// pendingScanDeadline 待扫描消息的接管时间：扫描和投递都超时后再等待一段时间，处理该消息的实例仍未完成即视为已退出
+func (s *Service) pendingScanDeadline(now time.Time) time.Time {
+	return now.Add(s.attachmentScanTimeout() + (model.PendingScanDeliverTimeout+model.PendingScanRecoverGrace)*time.Second)
+}
+
+// runAttachmentScan 调用扫描钩子，扫描失败或超时时按配置放行或拒绝
+func (s *Service) runAttachmentScan(ctx context.Context, attachment *model.Attachment) *model.ScanResult {
+	result, err := s.attachmentScanner.Scan(ctx, attachment)
+	if err == nil && result != nil {
+		return result
+	}
+	if err == nil {
+		err = fmt.Errorf("扫描钩子未返回结果")
+	}
+
+	s.logger.Warn(ctx, "附件扫描失败",
+		logger.F("url", attachment.URL),
+		logger.F("failOpen", s.attachmentScan.FailOpen),
+		logger.F("error", err.Error()))
+	if s.attachmentScan.FailOpen {
+		return &model.ScanResult{Verdict: model.ScanVerdictAllow}
+	}
+	return &model.ScanResult{Verdict: model.ScanVerdictReject, Reason: "附件扫描失败，请稍后重新发送"}
+}
+
+// handleScanVerdict 处理未放行的扫描结果，隔离的附件发布隔离事件，返回给发送方的说明和拒绝原因
+func (s *Service) handleScanVerdict(ctx context.Context, msg *rest.WSMessage, attachment *model.Attachment, result *model.ScanResult) (string, string) {
+	s.logger.Info(ctx, "媒体消息附件未通过扫描",
+		logger.F("messageID", msg.MessageId),
+		logger.F("from", msg.From),
+		logger.F("verdict", result.Verdict),
+		logger.F("reason", result.Reason))
+
+	if result.Verdict == model.ScanVerdictQuarantine {
+		s.publishQuarantineEvent(ctx, &model.AttachmentQuarantineEvent{
+			MessageID:  msg.MessageId,
+			SenderID:   msg.From,
+			To:         msg.To,
+			GroupID:    msg.GroupId,
+			Attachment: attachment,
+			Reason:     result.Reason,
+			Timestamp:  time.Now().Unix(),
+		})
+		return "附件待审核，暂不能发送", model.RejectReasonAttachmentQuarantined
+	}
+
+	message := "附件未通过安全检查"
+	if result.Reason != "" {
+		message = result.Reason
+	}
+	return message, model.RejectReasonAttachmentRejected
+}
+
+// notifySendFailed 向待扫描消息的发送方推送发送失败通知，推送失败只记录日志
+func (s *Service) notifySendFailed(ctx context.Context, msg *rest.WSMessage, reason, message string) {
+	content, err := json.Marshal(&model.SendFailedNotice{
+		MessageID:   msg.MessageId,
+		ClientMsgID: msg.ClientMsgId,
+		Reason:      reason,
+		Message:     message,
+	})
+	if err != nil {
+		return
+	}
+
+	notice := &rest.WSMessage{
+		MessageId:   msg.MessageId,
+		From:        model.SystemSenderID,
+		To:          msg.From,
+		GroupId:     msg.GroupId,
+		Content:     string(content),
+		MessageType: model.MessageTypeSendFailed,
+		Timestamp:   time.Now().Unix(),
+	}
+	if err := s.publishMessageToQueue(ctx, msg.From, notice); err != nil {
+		s.logger.Warn(ctx, "推送发送失败通知失败",
+			logger.F("messageID", msg.MessageId),
+			logger.F("userID", msg.From),
+			logger.F("error", err.Error()))
+	}
+}
+
+// publishQuarantineEvent 发布附件隔离事件，发送失败只记录日志
+func (s *Service) publishQuarantineEvent(ctx context.Context, event *model.AttachmentQuarantineEvent) {
+	if s.kafka == nil {
+		return
+	}
+
+	data, err := json.Marshal(event)
+	if err != nil {
+		return
+	}
+
+	key := []byte(fmt.Sprintf("%d", event.SenderID))
+	if err := s.kafka.SendMessage(model.TopicAttachmentQuarantine, key, data); err != nil {
+		s.logger.Warn(ctx, "发布附件隔离事件失败",
+			logger.F("messageID", event.MessageID),
+			logger.F("error", err.Error()))
+	}
+}
+
+// attachmentScanSyncWait 同步等待扫描结果的时长
+func (s *Service) attachmentScanSyncWait() time.Duration {
+	if s.attachmentScan.SyncWait > 0 {
+		return time.Duration(s.attachmentScan.SyncWait) * time.Millisecond
+	}
+	return model.DefaultAttachmentScanSyncWait * time.Millisecond
+}
+
+// attachmentScanTimeout 单次扫描的最长时间
+func (s *Service) attachmentScanTimeout() time.Duration {
+	if s.attachmentScan.Timeout > 0 {
+		return time.Duration(s.attachmentScan.Timeout) * time.Millisecond
+	}
+	return model.DefaultAttachmentScanTimeout * time.Millisecond
+}
+
+// encodePendingScan 编码待扫描消息的持久化记录
+func encodePendingScan(msg *rest.WSMessage, attachment *model.Attachment, queuedAt time.Time) ([]byte, error) {
+	message, err := proto.Marshal(msg)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(&model.PendingScan{Message: message, Attachment: attachment, QueuedAt: queuedAt.Unix()})
+}
+
+// decodePendingScan 解码待扫描消息的持久化记录
+func decodePendingScan(data []byte) (*rest.WSMessage, *model.Attachment, error) {
+	var pending model.PendingScan
+	if err := json.Unmarshal(data, &pending); err != nil {
+		return nil, nil, err
+	}
+	msg := &rest.WSMessage{}
+	if err := proto.Unmarshal(pending.Message, msg); err != nil {
+		return nil, nil, err
+	}
+	if pending.Attachment == nil {
+		pending.Attachment = parseAttachment(msg.Content)
+	}
+	return msg, pending.Attachment, nil
+}
+
+// parseAttachment 解析媒体消息content，支持附件描述JSON（url、name、size、mime_type）和纯地址两种格式
+// 未声明MIME类型时按文件扩展名推断
+func parseAttachment(content string) *model.Attachment {
+	attachment := &model.Attachment{}
+	if err := json.Unmarshal([]byte(content), attachment); err != nil || attachment.URL == "" {
+		attachment = &model.Attachment{URL: strings.TrimSpace(content)}
+	}
+
+	if attachment.MimeType == "" {
+		name := attachment.Name
+		if name == "" {
+			name = urlPath(attachment.URL)
+		}
+		attachment.MimeType = mimeTypeByName(name)
+	}
+	if mediaType, _, err := mime.ParseMediaType(attachment.MimeType); err == nil {
+		attachment.MimeType = mediaType
+	}
+	return attachment
+}
+
+// urlPath 返回地址的路径部分，解析失败时返回原地址
+func urlPath(rawURL string) string {
+	if u, err := url.Parse(rawURL); err == nil {
+		return u.Path
+	}
+	return rawURL
+}
+
+// mimeTypeByName 按文件扩展名推断MIME类型，无法推断时返回空
+func mimeTypeByName(name string) string {
+	ext := path.Ext(name)
+	if ext == "" {
+		return ""
+	}
+	if mediaType, _, err := mime.ParseMediaType(mime.TypeByExtension(strings.ToLower(ext))); err == nil {
+		return mediaType
+	}
+	return ""
+}
+
+// noopAttachmentScanner 不扫描，放行所有附件
+type noopAttachmentScanner struct{}
+
+func (noopAttachmentScanner) Scan(context.Context, *model.Attachment) (*model.ScanResult, error) {
+	return &model.ScanResult{Verdict: model.ScanVerdictAllow}, nil
+}
+
+// chainAttachmentScanner 依次调用多个扫描钩子，第一个未放行的结果即为最终结果
+type chainAttachmentScanner []AttachmentScanner
+
+func (c chainAttachmentScanner) Scan(ctx context.Context, attachment *model.Attachment) (*model.ScanResult, error) {
+	for _, scanner := range c {
+		result, err := scanner.Scan(ctx, attachment)
+		if err != nil {
+			return nil, err
+		}
+		if result.Verdict != model.ScanVerdictAllow {
+			return result, nil
+		}
+	}
+	return &model.ScanResult{Verdict: model.ScanVerdictAllow}, nil
+}
+
+// attachmentPolicyScanner 检查附件的大小和MIME类型，不信任客户端声明的值：
+// 以HEAD附件地址得到的Content-Length和Content-Type为准，响应未给出类型时按地址的扩展名推断；
+// 配置了大小上限而响应未给出大小时返回错误，按扫描失败处理
+type attachmentPolicyScanner struct {
+	maxSize      int64
+	allowedTypes []string
+	client       *http.Client
+}
+
+func (p attachmentPolicyScanner) Scan(ctx context.Context, attachment *model.Attachment) (*model.ScanResult, error) {
+	if p.maxSize <= 0 && len(p.allowedTypes) == 0 {
+		return &model.ScanResult{Verdict: model.ScanVerdictAllow}, nil
+	}
+
+	u, err := url.Parse(attachment.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return &model.ScanResult{Verdict: model.ScanVerdictReject, Reason: "附件地址无效"}, nil
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, attachment.URL, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("获取附件信息失败: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone {
+		return &model.ScanResult{Verdict: model.ScanVerdictReject, Reason: "附件不存在"}, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("获取附件信息返回状态码%d", resp.StatusCode)
+	}
+
+	if p.maxSize > 0 && resp.ContentLength < 0 {
+		return nil, fmt.Errorf("无法确定附件大小")
+	}
+	if p.maxSize > 0 && resp.ContentLength > p.maxSize {
+		return &model.ScanResult{
+			Verdict: model.ScanVerdictReject,
+			Reason:  fmt.Sprintf("附件大小不能超过%dMB", p.maxSize/(1024*1024)),
+		}, nil
+	}
+	mimeType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if mimeType == "" || mimeType == "application/octet-stream" {
+		mimeType = mimeTypeByName(urlPath(attachment.URL))
+	}
+	if len(p.allowedTypes) > 0 && !mimeTypeAllowed(mimeType, p.allowedTypes) {
+		return &model.ScanResult{Verdict: model.ScanVerdictReject, Reason: "不支持的附件类型"}, nil
+	}
+	return &model.ScanResult{Verdict: model.ScanVerdictAllow}, nil
+}
+
+// mimeTypeAllowed 判断MIME类型是否在允许列表中，支持image/*形式的通配，类型未知时不允许
+func mimeTypeAllowed(mimeType string, allowed []string) bool {
+	if mimeType == "" {
+		return false
+	}
+	for _, pattern := range allowed {
+		pattern = strings.ToLower(strings.TrimSpace(pattern))
+		if prefix, ok := strings.CutSuffix(pattern, "/*"); ok {
+			if strings.HasPrefix(mimeType, prefix+"/") {
+				return true
+			}
+		} else if mimeType == pattern {
+			return true
+		}
+	}
+	return false
+}
+
+// httpAttachmentScanner 调用外部杀毒扫描服务，请求体为附件描述JSON，响应为扫描结果JSON
+type httpAttachmentScanner struct {
+	url    string
+	client *http.Client
+}
+
+func (h *httpAttachmentScanner) Scan(ctx context.Context, attachment *model.Attachment) (*model.ScanResult, error) {
+	body, err := json.Marshal(attachment)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, h.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("调用扫描服务失败: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("扫描服务返回状态码%d", resp.StatusCode)
+	}
+
+	var result model.ScanResult
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("解析扫描结果失败: %v", err)
+	}
+	switch result.Verdict {
+	case model.ScanVerdictAllow, model.ScanVerdictReject, model.ScanVerdictQuarantine:
+		return &result, nil
+	default:
+		return nil, fmt.Errorf("未知的扫描结果: %s", result.Verdict)
+	}
+}
@@ -0,0 +1,132 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+
+	"goim-social/api/rest"
+	"goim-social/apps/logic-service/internal/model"
+)
+
+// attachmentServer 按路径返回固定大小和类型的附件地址，size为负数时不返回Content-Length
+func attachmentServer(t *testing.T, size int64, contentType string) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			http.NotFound(w, r)
+			return
+		}
+		if contentType != "" {
+			w.Header().Set("Content-Type", contentType)
+		}
+		if size >= 0 {
+			w.Header().Set("Content-Length", strconv.FormatInt(size, 10))
+		} else {
+			w.Header().Set("Transfer-Encoding", "chunked")
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// TestAttachmentPolicyScanner 大小和类型以附件地址的响应为准，客户端声明的值不影响结果
+func TestAttachmentPolicyScanner(t *testing.T) {
+	const maxSize = 1024
+	cases := []struct {
+		name        string
+		size        int64
+		contentType string
+		path        string
+		declared    model.Attachment
+		want        string
+		wantErr     bool
+	}{
+		{name: "allowed", size: 100, contentType: "image/png", path: "/a.png", want: model.ScanVerdictAllow},
+		{name: "declared small but actually large", size: maxSize + 1, contentType: "image/png", path: "/a.png",
+			declared: model.Attachment{Size: 1, MimeType: "image/png"}, want: model.ScanVerdictReject},
+		{name: "size omitted", size: maxSize + 1, contentType: "image/png", path: "/a.png", want: model.ScanVerdictReject},
+		{name: "declared image but actually executable", size: 100, contentType: "application/x-msdownload", path: "/a.png",
+			declared: model.Attachment{MimeType: "image/png"}, want: model.ScanVerdictReject},
+		{name: "type inferred from url", size: 100, contentType: "application/octet-stream", path: "/a.jpg", want: model.ScanVerdictAllow},
+		{name: "missing", size: 100, contentType: "image/png", path: "/missing", want: model.ScanVerdictReject},
+		{name: "unknown size", size: -1, contentType: "image/png", path: "/a.png", wantErr: true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			server := attachmentServer(t, tc.size, tc.contentType)
+			scanner := attachmentPolicyScanner{maxSize: maxSize, allowedTypes: []string{"image/*"}, client: server.Client()}
+			attachment := tc.declared
+			attachment.URL = server.URL + tc.path
+
+			result, err := scanner.Scan(context.Background(), &attachment)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatalf("Scan() = %+v, want error", result)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Scan() returned error: %v", err)
+			}
+			if result.Verdict != tc.want {
+				t.Errorf("Scan() verdict = %s (%s), want %s", result.Verdict, result.Reason, tc.want)
+			}
+		})
+	}
+}
+
+// TestAttachmentPolicyScannerInvalidURL 非http地址直接拒绝，不发起请求
+func TestAttachmentPolicyScannerInvalidURL(t *testing.T) {
+	scanner := attachmentPolicyScanner{maxSize: 1024, client: http.DefaultClient}
+	for _, rawURL := range []string{"", "file:///etc/passwd", "not a url"} {
+		result, err := scanner.Scan(context.Background(), &model.Attachment{URL: rawURL})
+		if err != nil || result.Verdict != model.ScanVerdictReject {
+			t.Errorf("Scan(%q) = %+v, %v, want reject", rawURL, result, err)
+		}
+	}
+}
+
+// TestPendingScanRoundTrip 持久化的待扫描消息解码后与原消息一致
+func TestPendingScanRoundTrip(t *testing.T) {
+	msg := &rest.WSMessage{MessageId: 42, From: 1, To: 2, Content: `{"url":"https://cdn.example.com/a.png"}`, MessageType: 2, ClientMsgId: "c-1"}
+	attachment := parseAttachment(msg.Content)
+
+	data, err := encodePendingScan(msg, attachment, time.Unix(100, 0))
+	if err != nil {
+		t.Fatalf("encodePendingScan returned error: %v", err)
+	}
+	gotMsg, gotAttachment, err := decodePendingScan(data)
+	if err != nil {
+		t.Fatalf("decodePendingScan returned error: %v", err)
+	}
+	if !proto.Equal(gotMsg, msg) {
+		t.Errorf("decoded message = %v, want %v", gotMsg, msg)
+	}
+	if *gotAttachment != *attachment {
+		t.Errorf("decoded attachment = %+v, want %+v", gotAttachment, attachment)
+	}
+}
+
+// TestMimeTypeAllowed 支持通配，类型未知时不允许
+func TestMimeTypeAllowed(t *testing.T) {
+	allowed := []string{"image/*", "video/mp4"}
+	cases := map[string]bool{
+		"image/png":       true,
+		"video/mp4":       true,
+		"video/webm":      false,
+		"imagex/png":      false,
+		"":                false,
+		"application/pdf": false,
+	}
+	for mimeType, want := range cases {
+		if got := mimeTypeAllowed(mimeType, allowed); got != want {
+			t.Errorf("mimeTypeAllowed(%q) = %v, want %v", mimeType, got, want)
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+
+	"goim-social/pkg/audit"
+	tracecontext "goim-social/pkg/context"
+	"goim-social/pkg/telemetry"
+)
+
+// QueryAuditLog 按操作者、操作对象和时间查询各服务写入的审计记录，仅配置的管理员可查询
+func (s *Service) QueryAuditLog(ctx context.Context, operatorID int64, filter audit.Filter) ([]*audit.Entry, error) {
+	// 开始OpenTelemetry span
+	ctx, span := telemetry.StartSpan(ctx, "logic.service.QueryAuditLog")
+	defer span.End()
+
+	// 设置span属性
+	span.SetAttributes(
+		attribute.Int64("audit.operator_id", operatorID),
+		attribute.Int64("audit.actor_id", filter.ActorID),
+		attribute.String("audit.target_type", filter.TargetType),
+		attribute.Int64("audit.target_id", filter.TargetID),
+		attribute.String("audit.action", filter.Action),
+	)
+
+	// 将业务信息添加到context
+	ctx = tracecontext.WithUserID(ctx, operatorID)
+
+	if !s.auditAdmins[operatorID] {
+		span.SetStatus(codes.Error, "permission denied")
+		return nil, fmt.Errorf("无权限查询审计记录")
+	}
+	if filter.StartTime > 0 && filter.EndTime > 0 && filter.StartTime > filter.EndTime {
+		span.SetStatus(codes.Error, "invalid time range")
+		return nil, fmt.Errorf("开始时间不能晚于结束时间")
+	}
+
+	entries, err := s.auditStore.Query(ctx, filter)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to query audit log")
+		return nil, err
+	}
+
+	span.SetAttributes(attribute.Int("audit.result_count", len(entries)))
+	span.SetStatus(codes.Ok, "audit log queried")
+	return entries, nil
+}
@@ -0,0 +1,298 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+
+	"goim-social/api/rest"
+	"goim-social/apps/logic-service/internal/model"
+	"goim-social/pkg/audit"
+	tracecontext "goim-social/pkg/context"
+	"goim-social/pkg/httpx"
+	"goim-social/pkg/logger"
+	"goim-social/pkg/snowflake"
+	"goim-social/pkg/telemetry"
+)
+
+// Broadcast 管理员广播系统消息到指定群组或所有在线用户
+// 系统消息与普通消息一样先持久化再投递，离线用户重连后可通过未读消息拉取；
+// 全员广播受理后在后台分批投递，不受请求截止时间约束，返回结果只表示已受理
+func (s *Service) Broadcast(ctx context.Context, operatorID int64, scope string, groupID int64, content string) (*model.MessageResult, error) {
+	// 开始OpenTelemetry span
+	ctx, span := telemetry.StartSpan(ctx, "logic.service.Broadcast")
+	defer span.End()
+
+	// 设置span属性
+	span.SetAttributes(
+		attribute.Int64("broadcast.operator_id", operatorID),
+		attribute.String("broadcast.scope", scope),
+		attribute.Int64("broadcast.group_id", groupID),
+	)
+
+	// 将业务信息添加到context
+	ctx = tracecontext.WithUserID(ctx, operatorID)
+	if groupID > 0 {
+		ctx = tracecontext.WithGroupID(ctx, groupID)
+	}
+
+	// 系统广播推送给所有用户，只允许配置的管理员操作
+	if !s.admins[operatorID] {
+		span.SetStatus(codes.Error, "permission denied")
+		return nil, httpx.PermissionDenied(fmt.Errorf("无权限广播系统消息"))
+	}
+
+	if content == "" {
+		span.SetStatus(codes.Error, "empty broadcast content")
+		return nil, fmt.Errorf("广播内容不能为空")
+	}
+	if scope == model.BroadcastScopeGroup && groupID <= 0 {
+		span.SetStatus(codes.Error, "invalid group id")
+		return nil, fmt.Errorf("群组广播必须指定群组ID")
+	}
+	if scope != model.BroadcastScopeGroup && scope != model.BroadcastScopeAll {
+		span.SetStatus(codes.Error, "invalid broadcast scope")
+		return nil, fmt.Errorf("无效的广播范围: %s", scope)
+	}
+
+	// 1. 限流 - 同一操作者在限流窗口内只能广播一次
+	rateKey := fmt.Sprintf("%s:%d", model.RedisKeyBroadcastRateLimit, operatorID)
+	allowed, err := s.redis.SetNX(ctx, rateKey, 1, time.Duration(model.BroadcastRateLimit)*time.Second)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to check rate limit")
+		return nil, fmt.Errorf("检查广播限流失败: %v", err)
+	}
+	if !allowed {
+		span.SetStatus(codes.Error, "broadcast rate limited")
+		return nil, fmt.Errorf("广播过于频繁，请%d秒后重试", model.BroadcastRateLimit)
+	}
+
+	msg := &rest.WSMessage{
+		MessageId:   snowflake.GenerateID(),
+		From:        model.SystemSenderID,
+		GroupId:     groupID,
+		Content:     content,
+		MessageType: model.MessageTypeSystem,
+		Timestamp:   time.Now().Unix(),
+	}
+	span.SetAttributes(attribute.Int64("message.id", msg.MessageId))
+
+	// 2. 持久化并投递，成功、失败和中断都记录审计
+	record := &model.BroadcastAudit{
+		MessageID:  msg.MessageId,
+		OperatorID: operatorID,
+		Scope:      scope,
+		GroupID:    groupID,
+		Content:    content,
+		CreatedAt:  msg.Timestamp,
+	}
+	if scope == model.BroadcastScopeAll {
+		msg.GroupId = 0
+		record.GroupID = 0
+		result, err := s.startOnlineBroadcast(ctx, msg, record)
+		if err != nil {
+			record.Error = err.Error()
+			s.recordBroadcastAudit(ctx, record)
+			span.RecordError(err)
+			span.SetStatus(codes.Error, "failed to start broadcast")
+			return nil, err
+		}
+		span.SetAttributes(attribute.Int("broadcast.total", record.Total))
+		span.SetStatus(codes.Ok, "broadcast accepted")
+		return result, nil
+	}
+
+	result, err := s.broadcastToGroup(ctx, msg)
+	if err != nil {
+		record.Error = err.Error()
+		s.recordBroadcastAudit(ctx, record)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to broadcast")
+		return nil, err
+	}
+
+	// 3. 审计记录
+	record.SuccessCount = result.SuccessCount
+	record.FailureCount = result.FailureCount
+	record.Total = result.SuccessCount + result.FailureCount
+	record.Completed = true
+	s.recordBroadcastAudit(ctx, record)
+
+	span.SetAttributes(
+		attribute.Int("result.success_count", result.SuccessCount),
+		attribute.Int("result.failure_count", result.FailureCount),
+	)
+	span.SetStatus(codes.Ok, "broadcast completed")
+	return result, nil
+}
+
+// broadcastToGroup 广播系统消息到群组，按群消息持久化后扇出给所有成员
+func (s *Service) broadcastToGroup(ctx context.Context, msg *rest.WSMessage) (*model.MessageResult, error) {
+	membersResp, err := s.socialClient.GetGroupMemberIDs(ctx, &rest.GetGroupMemberIDsRequest{
+		GroupId: msg.GroupId,
+	})
+	if err != nil {
+		s.logger.Error(ctx, "获取群成员失败", logger.F("error", err.Error()))
+		return nil, fmt.Errorf("获取群成员失败: %v", err)
+	}
+
+	if _, err := s.ensureMessagePersistence(ctx, msg); err != nil {
+		return nil, fmt.Errorf("消息持久化失败: %v", err)
+	}
+
+	result := s.fanOutToMembers(ctx, msg, membersResp.MemberIds)
+	result.Success = true
+	result.Message = fmt.Sprintf("群组广播完成，送达: %d, 离线: %d, 失败: %d",
+		result.DeliveredCount, result.OfflineCount, result.FailureCount)
+	return result, nil
+}
+
+// startOnlineBroadcast 获取在线用户快照后在后台分批投递，立即返回受理结果
+// 后台投递使用脱离请求取消的context，投递完成或中断后按实际进度记录审计
+func (s *Service) startOnlineBroadcast(ctx context.Context, msg *rest.WSMessage, record *model.BroadcastAudit) (*model.MessageResult, error) {
+	members, err := s.redis.SMembers(ctx, model.RedisKeyOnlineUsers)
+	if err != nil {
+		s.logger.Error(ctx, "获取在线用户失败", logger.F("error", err.Error()))
+		return nil, fmt.Errorf("获取在线用户失败: %v", err)
+	}
+	record.Total = len(members)
+
+	go func(ctx context.Context) {
+		defer func() {
+			if r := recover(); r != nil {
+				record.Error = fmt.Sprintf("广播中断: %v", r)
+				s.recordBroadcastAudit(ctx, record)
+			}
+		}()
+
+		result := s.broadcastToOnlineUsers(ctx, msg, members)
+		record.SuccessCount = result.SuccessCount
+		record.FailureCount = result.FailureCount
+		record.Completed = result.Success
+		if !result.Success {
+			record.Error = result.Message
+		}
+		s.recordBroadcastAudit(ctx, record)
+	}(context.WithoutCancel(ctx))
+
+	return &model.MessageResult{
+		Success:   true,
+		Message:   fmt.Sprintf("全员广播已受理，在线用户: %d，后台分批投递", len(members)),
+		MessageID: msg.MessageId,
+	}, nil
+}
+
+// broadcastToOnlineUsers 广播系统消息到在线用户快照
+// 分批投递避免瞬时压垮网关，每个用户持久化一份个人副本以便断线后补收；ctx取消时停止，返回已投递的进度
+func (s *Service) broadcastToOnlineUsers(ctx context.Context, msg *rest.WSMessage, members []string) *model.MessageResult {
+	successCount := 0
+	failureCount := 0
+	var failedUsers []int64
+	for start := 0; start < len(members); start += model.BroadcastChunkSize {
+		end := start + model.BroadcastChunkSize
+		if end > len(members) {
+			end = len(members)
+		}
+
+		for _, member := range members[start:end] {
+			userID, err := strconv.ParseInt(member, 10, 64)
+			if err != nil {
+				continue
+			}
+
+			// 每个接收者一份独立消息ID，与写扩散后的单聊消息一致
+			userMsg := &rest.WSMessage{
+				MessageId:   snowflake.GenerateID(),
+				From:        msg.From,
+				To:          userID,
+				Content:     msg.Content,
+				MessageType: msg.MessageType,
+				Timestamp:   msg.Timestamp,
+			}
+			if _, err = s.ensureMessagePersistence(ctx, userMsg); err == nil {
+				err = s.publishMessageToQueue(ctx, userID, userMsg)
+			}
+			if err != nil {
+				s.logger.Error(ctx, "广播消息投递失败",
+					logger.F("targetUser", userID),
+					logger.F("error", err.Error()))
+				failureCount++
+				failedUsers = append(failedUsers, userID)
+			} else {
+				successCount++
+			}
+		}
+
+		s.logger.Info(ctx, "全员广播批次完成",
+			logger.F("messageID", msg.MessageId),
+			logger.F("progress", end),
+			logger.F("total", len(members)))
+
+		if end < len(members) {
+			select {
+			case <-ctx.Done():
+				return &model.MessageResult{
+					Message:      fmt.Sprintf("广播已取消，进度: %d/%d: %v", end, len(members), ctx.Err()),
+					MessageID:    msg.MessageId,
+					SuccessCount: successCount,
+					FailureCount: failureCount,
+					FailedUsers:  failedUsers,
+				}
+			case <-time.After(time.Duration(model.BroadcastChunkInterval) * time.Millisecond):
+			}
+		}
+	}
+
+	return &model.MessageResult{
+		Success:      true,
+		Message:      fmt.Sprintf("全员广播完成，成功: %d, 失败: %d", successCount, failureCount),
+		MessageID:    msg.MessageId,
+		SuccessCount: successCount,
+		FailureCount: failureCount,
+		FailedUsers:  failedUsers,
+	}
+}
+
+// recordBroadcastAudit 记录广播审计日志，异步写入共享审计记录，不影响广播结果
+// 失败和中断的广播同样记录，completed为false时成功和失败数为中断前的进度
+func (s *Service) recordBroadcastAudit(ctx context.Context, record *model.BroadcastAudit) {
+	s.logger.Info(ctx, "管理员广播审计",
+		logger.F("messageID", record.MessageID),
+		logger.F("operatorID", record.OperatorID),
+		logger.F("scope", record.Scope),
+		logger.F("groupID", record.GroupID),
+		logger.F("successCount", record.SuccessCount),
+		logger.F("failureCount", record.FailureCount),
+		logger.F("total", record.Total),
+		logger.F("completed", record.Completed))
+
+	detail := map[string]string{
+		"message_id":    strconv.FormatInt(record.MessageID, 10),
+		"content":       record.Content,
+		"success_count": strconv.Itoa(record.SuccessCount),
+		"failure_count": strconv.Itoa(record.FailureCount),
+		"total":         strconv.Itoa(record.Total),
+		"completed":     strconv.FormatBool(record.Completed),
+	}
+	if record.Error != "" {
+		detail["error"] = record.Error
+	}
+
+	targetType := audit.TargetAll
+	if record.Scope == model.BroadcastScopeGroup {
+		targetType = audit.TargetGroup
+	}
+	s.auditor.Record(audit.Entry{
+		ActorID:    record.OperatorID,
+		Action:     audit.ActionBroadcast,
+		TargetType: targetType,
+		TargetID:   record.GroupID,
+		Detail:     detail,
+		CreatedAt:  record.CreatedAt * 1000,
+	})
+}
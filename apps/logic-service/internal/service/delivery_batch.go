@@ -0,0 +1,158 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"goim-social/apps/logic-service/internal/model"
+	"goim-social/pkg/config"
+	"goim-social/pkg/logger"
+)
+
+// deliveryBatcher 投递结果事件合并器，达到批量大小或合并窗口到期时发布一批
+type deliveryBatcher struct {
+	window  time.Duration
+	maxSize int
+	publish func(events []*model.DeliveryEvent)
+
+	mu      sync.Mutex
+	pending []*model.DeliveryEvent
+	timer   *time.Timer
+	closed  bool
+}
+
+// newDeliveryBatcher 根据配置创建合并器，未启用时返回nil表示逐条发布
+func newDeliveryBatcher(cfg config.DeliveryBatchConfig, publish func(events []*model.DeliveryEvent)) *deliveryBatcher {
+	if !cfg.Enabled {
+		return nil
+	}
+
+	window := cfg.Window
+	if window <= 0 {
+		window = model.DefaultDeliveryBatchWindow
+	}
+	if window > model.MaxDeliveryBatchWindow {
+		window = model.MaxDeliveryBatchWindow
+	}
+	maxSize := cfg.MaxSize
+	if maxSize <= 0 {
+		maxSize = model.DefaultDeliveryBatchMaxSize
+	}
+
+	return &deliveryBatcher{
+		window:  time.Duration(window) * time.Millisecond,
+		maxSize: maxSize,
+		publish: publish,
+	}
+}
+
+// add 加入一条事件，返回false表示合并器已关闭，调用方应直接逐条发布
+// 窗口从批次第一条事件加入时开始计时，保证事件最多延迟一个窗口
+func (b *deliveryBatcher) add(event *model.DeliveryEvent) bool {
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return false
+	}
+
+	b.pending = append(b.pending, event)
+	if len(b.pending) < b.maxSize {
+		if b.timer == nil {
+			b.timer = time.AfterFunc(b.window, b.flush)
+		}
+		b.mu.Unlock()
+		return true
+	}
+
+	events := b.take()
+	b.mu.Unlock()
+	b.publish(events)
+	return true
+}
+
+// flush 发布当前批次
+func (b *deliveryBatcher) flush() {
+	b.mu.Lock()
+	events := b.take()
+	b.mu.Unlock()
+
+	if len(events) > 0 {
+		b.publish(events)
+	}
+}
+
+// close 关闭合并器并发布剩余事件，之后加入的事件由调用方逐条发布
+func (b *deliveryBatcher) close() {
+	b.mu.Lock()
+	b.closed = true
+	events := b.take()
+	b.mu.Unlock()
+
+	if len(events) > 0 {
+		b.publish(events)
+	}
+}
+
+// take 取出当前批次并停止窗口计时，调用方需持有锁
+func (b *deliveryBatcher) take() []*model.DeliveryEvent {
+	if b.timer != nil {
+		b.timer.Stop()
+		b.timer = nil
+	}
+	events := b.pending
+	b.pending = nil
+	return events
+}
+
+// publishDeliveryEvent 发布成员级投递结果事件，启用批量发送时先合并，发送失败只记录日志
+func (s *Service) publishDeliveryEvent(ctx context.Context, event *model.DeliveryEvent) {
+	if s.kafka == nil {
+		return
+	}
+	if s.deliveryBatch != nil && s.deliveryBatch.add(event) {
+		return
+	}
+
+	data, err := json.Marshal(event)
+	if err != nil {
+		return
+	}
+
+	key := []byte(fmt.Sprintf("%d", event.MessageID))
+	if err := s.kafka.SendMessage(model.TopicDeliveryEvents, key, data); err != nil {
+		s.logger.Warn(ctx, "发布投递事件失败",
+			logger.F("messageID", event.MessageID),
+			logger.F("userID", event.UserID),
+			logger.F("error", err.Error()))
+	}
+}
+
+// publishDeliveryBatch 发布批量投递结果事件，以实例ID为键保证同一实例的批次有序
+func (s *Service) publishDeliveryBatch(events []*model.DeliveryEvent) {
+	ctx := context.Background()
+	data, err := json.Marshal(&model.DeliveryEventBatch{
+		Events:    events,
+		Count:     len(events),
+		Timestamp: time.Now().Unix(),
+	})
+	if err != nil {
+		return
+	}
+
+	if err := s.kafka.SendMessage(model.TopicDeliveryEvents, []byte(s.instanceID), data); err != nil {
+		s.logger.Warn(ctx, "发布批量投递事件失败",
+			logger.F("count", len(events)),
+			logger.F("error", err.Error()))
+	}
+}
+
+// FlushDeliveryEvents 停止合并并发布尚未发送的投递结果事件，退出前调用
+func (s *Service) FlushDeliveryEvents(ctx context.Context) error {
+	if s.deliveryBatch != nil {
+		s.deliveryBatch.close()
+	}
+	return nil
+}
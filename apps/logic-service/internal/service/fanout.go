@@ -0,0 +1,140 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"goim-social/api/rest"
+	"goim-social/apps/logic-service/internal/model"
+	"goim-social/pkg/logger"
+)
+
+// fanOutToMembers 向群成员投递消息并记录每个成员的投递结果，单个成员失败不影响其他成员
+// 离线成员不推送，消息已持久化，上线后通过未读消息拉取；在线成员推送失败时按退避重试
+// 在线成员通过扇出推送池并发推送，单条消息的并发数和全局并发数都有上限
+func (s *Service) fanOutToMembers(ctx context.Context, msg *rest.WSMessage, memberIDs []int64) *model.MessageResult {
+	result := &model.MessageResult{MessageID: msg.MessageId}
+	online := s.onlineMembers(ctx, memberIDs)
+
+	var (
+		mu     sync.Mutex
+		wg     sync.WaitGroup
+		inProc = make(chan struct{}, s.fanoutPool.perMessage)
+	)
+
+	for _, memberID := range memberIDs {
+		if memberID == msg.From {
+			continue // 跳过发送者
+		}
+
+		event := &model.DeliveryEvent{
+			MessageID: msg.MessageId,
+			GroupID:   msg.GroupId,
+			UserID:    memberID,
+			SenderID:  msg.From,
+			Timestamp: time.Now().Unix(),
+		}
+
+		if online != nil && !online[memberID] {
+			event.Status = model.DeliveryStatusQueuedOffline
+			mu.Lock()
+			result.OfflineCount++
+			result.OfflineUsers = append(result.OfflineUsers, memberID)
+			mu.Unlock()
+			s.publishDeliveryEvent(ctx, event)
+			continue
+		}
+
+		// 先占用本消息的并发名额再获取全局槽位，两者用满时在此等待，形成背压
+		inProc <- struct{}{}
+		if err := s.fanoutPool.acquire(ctx); err != nil {
+			<-inProc
+			s.recordFanoutDelivery(ctx, &mu, result, event, 0, err)
+			continue
+		}
+
+		wg.Add(1)
+		go func(memberID int64, event *model.DeliveryEvent) {
+			defer func() {
+				s.fanoutPool.release()
+				<-inProc
+				wg.Done()
+			}()
+
+			attempts, err := s.deliverWithRetry(ctx, memberID, msg)
+			s.recordFanoutDelivery(ctx, &mu, result, event, attempts, err)
+		}(memberID, event)
+	}
+	wg.Wait()
+
+	result.SuccessCount = result.DeliveredCount + result.OfflineCount
+	result.Message = fmt.Sprintf("群消息发送完成，送达: %d, 离线: %d, 失败: %d",
+		result.DeliveredCount, result.OfflineCount, result.FailureCount)
+	return result
+}
+
+// recordFanoutDelivery 记录单个在线成员的推送结果并发布投递事件
+func (s *Service) recordFanoutDelivery(ctx context.Context, mu *sync.Mutex, result *model.MessageResult, event *model.DeliveryEvent, attempts int, err error) {
+	event.Attempts = attempts
+	if err != nil {
+		s.logger.Error(ctx, "消息投递失败",
+			logger.F("targetUser", event.UserID),
+			logger.F("attempts", attempts),
+			logger.F("error", err.Error()))
+		event.Status = model.DeliveryStatusFailed
+		event.Error = err.Error()
+	} else {
+		event.Status = model.DeliveryStatusDelivered
+	}
+
+	mu.Lock()
+	if err != nil {
+		result.FailureCount++
+		result.FailedUsers = append(result.FailedUsers, event.UserID)
+	} else {
+		result.DeliveredCount++
+	}
+	mu.Unlock()
+
+	s.publishDeliveryEvent(ctx, event)
+}
+
+// FanoutPoolStats 返回扇出推送池状态
+func (s *Service) FanoutPoolStats() *model.FanoutPoolStat {
+	return s.fanoutPool.stats()
+}
+
+// onlineMembers 批量查询成员在线状态，查询失败时返回nil，此时按在线处理逐个推送
+func (s *Service) onlineMembers(ctx context.Context, memberIDs []int64) map[int64]bool {
+	if len(memberIDs) == 0 {
+		return nil
+	}
+
+	online, err := s.presence.Online(ctx, memberIDs)
+	if err != nil {
+		s.logger.Warn(ctx, "查询成员在线状态失败，按在线处理", logger.F("error", err.Error()))
+		return nil
+	}
+	return online
+}
+
+// deliverWithRetry 投递消息到单个成员，失败时按退避重试，返回尝试次数
+func (s *Service) deliverWithRetry(ctx context.Context, userID int64, msg *rest.WSMessage) (int, error) {
+	var err error
+	for attempt := 0; attempt <= model.FanoutMaxRetries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return attempt, ctx.Err()
+			case <-time.After(time.Duration(attempt*model.FanoutRetryBackoff) * time.Millisecond):
+			}
+		}
+
+		if err = s.publishMessageToQueue(ctx, userID, msg); err == nil {
+			return attempt + 1, nil
+		}
+	}
+	return model.FanoutMaxRetries + 1, err
+}
@@ -0,0 +1,69 @@
+package service
+
+import (
+	"context"
+	"sync/atomic"
+
+	"goim-social/apps/logic-service/internal/model"
+	"goim-social/pkg/config"
+)
+
+// fanoutPool 扇出推送并发池，所有消息共享固定数量的推送槽位
+// 槽位用满时获取方阻塞等待，扇出循环随之暂停，不会为大群创建大量goroutine或无限排队
+type fanoutPool struct {
+	slots      chan struct{}
+	perMessage int
+
+	active  atomic.Int64
+	waiting atomic.Int64
+}
+
+// newFanoutPool 根据配置创建扇出推送池，单条消息并发数不超过全局并发数
+func newFanoutPool(cfg config.FanoutConfig) *fanoutPool {
+	workers := cfg.Workers
+	if workers <= 0 {
+		workers = model.DefaultFanoutWorkers
+	}
+	perMessage := cfg.PerMessage
+	if perMessage <= 0 {
+		perMessage = model.DefaultFanoutPerMessage
+	}
+	if perMessage > workers {
+		perMessage = workers
+	}
+
+	return &fanoutPool{
+		slots:      make(chan struct{}, workers),
+		perMessage: perMessage,
+	}
+}
+
+// acquire 获取一个推送槽位，槽位用满时等待，ctx结束时返回错误
+func (p *fanoutPool) acquire(ctx context.Context) error {
+	p.waiting.Add(1)
+	defer p.waiting.Add(-1)
+
+	select {
+	case p.slots <- struct{}{}:
+		p.active.Add(1)
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// release 归还推送槽位
+func (p *fanoutPool) release() {
+	p.active.Add(-1)
+	<-p.slots
+}
+
+// stats 返回推送池状态快照
+func (p *fanoutPool) stats() *model.FanoutPoolStat {
+	return &model.FanoutPoolStat{
+		Size:       cap(p.slots),
+		PerMessage: p.perMessage,
+		Active:     p.active.Load(),
+		Waiting:    p.waiting.Load(),
+	}
+}
@@ -0,0 +1,157 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"goim-social/api/rest"
+	"goim-social/apps/logic-service/internal/model"
+)
+
+// MessageTypeHandler 消息类型处理器，负责该类型消息进入投递流程前的校验
+type MessageTypeHandler interface {
+	Name() string
+	Validate(msg *rest.WSMessage) error
+}
+
+// messageTypeRegistry 消息类型注册表，新增消息类型时注册处理器，未注册的类型一律拒绝
+type messageTypeRegistry struct {
+	handlers map[int32]MessageTypeHandler
+}
+
+// newMessageTypeRegistry 创建注册表并注册内置的聊天消息类型
+// 系统消息只能由广播接口构造，不经过该注册表
+func newMessageTypeRegistry() *messageTypeRegistry {
+	r := &messageTypeRegistry{handlers: make(map[int32]MessageTypeHandler)}
+	r.register(model.MessageTypeText, textMessageHandler{})
+	r.register(model.MessageTypeImage, mediaMessageHandler{name: "image"})
+	r.register(model.MessageTypeAudio, mediaMessageHandler{name: "audio"})
+	r.register(model.MessageTypeVideo, mediaMessageHandler{name: "video"})
+	r.register(model.MessageTypeFile, mediaMessageHandler{name: "file"})
+	r.register(model.MessageTypeShare, shareMessageHandler{})
+	r.register(model.MessageTypePoll, pollMessageHandler{})
+	return r
+}
+
+// register 注册消息类型处理器，重复注册视为编码错误
+func (r *messageTypeRegistry) register(msgType int32, handler MessageTypeHandler) {
+	if _, exists := r.handlers[msgType]; exists {
+		panic(fmt.Sprintf("消息类型重复注册: %d", msgType))
+	}
+	r.handlers[msgType] = handler
+}
+
+// resolve 查找消息类型处理器，未指定类型的旧客户端消息按文本处理
+func (r *messageTypeRegistry) resolve(msg *rest.WSMessage) (MessageTypeHandler, error) {
+	if msg.MessageType == 0 {
+		msg.MessageType = model.MessageTypeText
+	}
+
+	handler, ok := r.handlers[msg.MessageType]
+	if !ok {
+		return nil, fmt.Errorf("不支持的消息类型: %d，支持的类型: %v", msg.MessageType, r.types())
+	}
+	return handler, nil
+}
+
+// types 已注册的消息类型，按类型值排序
+func (r *messageTypeRegistry) types() []int32 {
+	types := make([]int32, 0, len(r.handlers))
+	for msgType := range r.handlers {
+		types = append(types, msgType)
+	}
+	sort.Slice(types, func(i, j int) bool { return types[i] < types[j] })
+	return types
+}
+
+// textMessageHandler 文本消息
+type textMessageHandler struct{}
+
+func (textMessageHandler) Name() string { return "text" }
+
+func (textMessageHandler) Validate(msg *rest.WSMessage) error {
+	if msg.Content == "" {
+		return fmt.Errorf("文本消息内容不能为空")
+	}
+	if utf8.RuneCountInString(msg.Content) > model.MaxTextMessageLength {
+		return fmt.Errorf("文本消息长度不能超过%d个字符", model.MaxTextMessageLength)
+	}
+	return nil
+}
+
+// mediaMessageHandler 媒体消息（图片、语音、视频、文件），content为媒体地址或描述信息
+type mediaMessageHandler struct {
+	name string
+}
+
+func (h mediaMessageHandler) Name() string { return h.name }
+
+func (h mediaMessageHandler) Validate(msg *rest.WSMessage) error {
+	if msg.Content == "" {
+		return fmt.Errorf("%s消息缺少媒体地址", h.name)
+	}
+	if len(msg.Content) > model.MaxMediaMessageLength {
+		return fmt.Errorf("%s消息内容长度不能超过%d字节", h.name, model.MaxMediaMessageLength)
+	}
+	return nil
+}
+
+// shareMessageHandler 内容分享消息，content为分享卡片JSON，至少包含被分享的内容ID
+type shareMessageHandler struct{}
+
+func (shareMessageHandler) Name() string { return "share" }
+
+func (shareMessageHandler) Validate(msg *rest.WSMessage) error {
+	if len(msg.Content) > model.MaxShareMessageLength {
+		return fmt.Errorf("分享消息内容长度不能超过%d字节", model.MaxShareMessageLength)
+	}
+	var card struct {
+		ContentID int64 `json:"content_id"`
+	}
+	if err := json.Unmarshal([]byte(msg.Content), &card); err != nil || card.ContentID <= 0 {
+		return fmt.Errorf("分享消息缺少有效的内容ID")
+	}
+	return nil
+}
+
+// pollMessageHandler 群投票消息，content为投票JSON，包含问题、选项和是否多选
+// 投票状态和票数由message-service保存，消息本身发出后不再改写
+type pollMessageHandler struct{}
+
+func (pollMessageHandler) Name() string { return "poll" }
+
+func (pollMessageHandler) Validate(msg *rest.WSMessage) error {
+	if msg.GroupId <= 0 {
+		return fmt.Errorf("投票消息仅支持群聊")
+	}
+	var poll struct {
+		Question    string   `json:"question"`
+		Options     []string `json:"options"`
+		MultiSelect bool     `json:"multi_select"`
+	}
+	if err := json.Unmarshal([]byte(msg.Content), &poll); err != nil {
+		return fmt.Errorf("投票消息内容格式错误")
+	}
+	question := strings.TrimSpace(poll.Question)
+	if question == "" || utf8.RuneCountInString(question) > model.MaxPollQuestionLength {
+		return fmt.Errorf("投票问题不能为空且不能超过%d个字符", model.MaxPollQuestionLength)
+	}
+	if len(poll.Options) < model.MinPollOptions || len(poll.Options) > model.MaxPollOptions {
+		return fmt.Errorf("投票选项数量须在%d-%d之间", model.MinPollOptions, model.MaxPollOptions)
+	}
+	seen := make(map[string]struct{}, len(poll.Options))
+	for _, option := range poll.Options {
+		option = strings.TrimSpace(option)
+		if option == "" || utf8.RuneCountInString(option) > model.MaxPollOptionLength {
+			return fmt.Errorf("投票选项不能为空且不能超过%d个字符", model.MaxPollOptionLength)
+		}
+		if _, ok := seen[option]; ok {
+			return fmt.Errorf("投票选项不能重复: %s", option)
+		}
+		seen[option] = struct{}{}
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package service
+
+import (
+	"strings"
+	"testing"
+
+	"goim-social/api/rest"
+	"goim-social/apps/logic-service/internal/model"
+)
+
+// TestPollMessageValidate 投票消息仅支持群聊，问题和选项须非空、不超长且选项不重复
+func TestPollMessageValidate(t *testing.T) {
+	longQuestion := strings.Repeat("问", model.MaxPollQuestionLength+1)
+	longOption := strings.Repeat("选", model.MaxPollOptionLength+1)
+	tooMany := `"1","2","3","4","5","6","7","8","9","10","11"`
+	cases := []struct {
+		name    string
+		groupID int64
+		content string
+		wantErr bool
+	}{
+		{name: "valid", groupID: 1, content: `{"question":"午饭吃什么","options":["面","饭"]}`},
+		{name: "valid multi select", groupID: 1, content: `{"question":"周末活动","options":["爬山","看电影","打球"],"multi_select":true}`},
+		{name: "private chat", groupID: 0, content: `{"question":"午饭吃什么","options":["面","饭"]}`, wantErr: true},
+		{name: "not json", groupID: 1, content: "午饭吃什么", wantErr: true},
+		{name: "blank question", groupID: 1, content: `{"question":"  ","options":["面","饭"]}`, wantErr: true},
+		{name: "long question", groupID: 1, content: `{"question":"` + longQuestion + `","options":["面","饭"]}`, wantErr: true},
+		{name: "too few options", groupID: 1, content: `{"question":"午饭吃什么","options":["面"]}`, wantErr: true},
+		{name: "too many options", groupID: 1, content: `{"question":"午饭吃什么","options":[` + tooMany + `]}`, wantErr: true},
+		{name: "blank option", groupID: 1, content: `{"question":"午饭吃什么","options":["面"," "]}`, wantErr: true},
+		{name: "long option", groupID: 1, content: `{"question":"午饭吃什么","options":["面","` + longOption + `"]}`, wantErr: true},
+		{name: "duplicate option", groupID: 1, content: `{"question":"午饭吃什么","options":["面"," 面 "]}`, wantErr: true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			msg := &rest.WSMessage{GroupId: tc.groupID, MessageType: model.MessageTypePoll, Content: tc.content}
+			err := pollMessageHandler{}.Validate(msg)
+			if tc.wantErr && err == nil {
+				t.Errorf("Validate(%s) = nil, want error", tc.content)
+			}
+			if !tc.wantErr && err != nil {
+				t.Errorf("Validate(%s) error: %v", tc.content, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,104 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"goim-social/api/rest"
+	"goim-social/apps/logic-service/internal/model"
+	"goim-social/pkg/logger"
+)
+
+// ==================== 已读回执 ====================
+// 接收方ACK单聊消息后，Logic服务向原消息发送方推送已读回执。已读回执设置按用户保存，默认开启，
+// 且是对称的：关闭后该用户ACK消息不再向对方发送回执，同时也不再收到别人的已读回执；
+// 只有读者和发送方都开启时才推送。关闭回执不影响消息本身的已读标记和未读计数。
+// 群消息的已读状态通过已读水位查询，不在ACK链路中推送回执
+
+// SetReadReceiptsEnabled 更新用户的已读回执设置
+func (s *Service) SetReadReceiptsEnabled(ctx context.Context, userID int64, enabled bool) error {
+	if userID <= 0 {
+		return fmt.Errorf("无效的用户ID: %d", userID)
+	}
+
+	var err error
+	if enabled {
+		err = s.redis.SRem(ctx, model.RedisKeyReadReceiptsDisabled, userID)
+	} else {
+		err = s.redis.SAdd(ctx, model.RedisKeyReadReceiptsDisabled, userID)
+	}
+	if err != nil {
+		return fmt.Errorf("更新已读回执设置失败: %v", err)
+	}
+
+	s.logger.Info(ctx, "已读回执设置已更新",
+		logger.F("userID", userID),
+		logger.F("enabled", enabled))
+	return nil
+}
+
+// readReceiptsEnabled 查询用户是否开启已读回执，未设置过的用户为开启
+func (s *Service) readReceiptsEnabled(ctx context.Context, userID int64) (bool, error) {
+	disabled, err := s.redis.SIsMember(ctx, model.RedisKeyReadReceiptsDisabled, userID)
+	if err != nil {
+		return false, err
+	}
+	return !disabled, nil
+}
+
+// sendReadReceipts 向已读单聊消息的发送方推送已读回执
+// 读者或发送方关闭了回执时不推送；设置查询失败时按关闭处理，宁可少发也不泄露已读状态。推送失败只记录日志
+func (s *Service) sendReadReceipts(ctx context.Context, readerID int64, receipts []*rest.ReadReceipt) {
+	if len(receipts) == 0 {
+		return
+	}
+
+	enabled, err := s.readReceiptsEnabled(ctx, readerID)
+	if err != nil {
+		s.logger.Warn(ctx, "查询读者已读回执设置失败，跳过已读回执",
+			logger.F("readerID", readerID),
+			logger.F("error", err.Error()))
+		return
+	}
+	if !enabled {
+		return
+	}
+
+	now := time.Now().Unix()
+	senderEnabled := make(map[int64]bool)
+	for _, receipt := range receipts {
+		senderID := receipt.SenderId
+		if receipt.GroupId > 0 || senderID == model.SystemSenderID || senderID == readerID {
+			continue
+		}
+
+		enabled, checked := senderEnabled[senderID]
+		if !checked {
+			if enabled, err = s.readReceiptsEnabled(ctx, senderID); err != nil {
+				s.logger.Warn(ctx, "查询发送方已读回执设置失败，跳过已读回执",
+					logger.F("senderID", senderID),
+					logger.F("error", err.Error()))
+			}
+			senderEnabled[senderID] = enabled
+		}
+		if !enabled {
+			continue
+		}
+
+		notice := &rest.WSMessage{
+			MessageId:   receipt.MessageId,
+			From:        readerID,
+			To:          senderID,
+			MessageType: model.MessageTypeReadReceipt,
+			Timestamp:   now,
+		}
+		if err := s.publishMessageToQueue(ctx, senderID, notice); err != nil {
+			s.logger.Warn(ctx, "推送已读回执失败",
+				logger.F("messageID", receipt.MessageId),
+				logger.F("readerID", readerID),
+				logger.F("senderID", senderID),
+				logger.F("error", err.Error()))
+		}
+	}
+}
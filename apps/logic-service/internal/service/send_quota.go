@@ -0,0 +1,166 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	goredis "github.com/go-redis/redis/v8"
+
+	"goim-social/api/rest"
+	"goim-social/apps/logic-service/internal/model"
+	"goim-social/pkg/config"
+	"goim-social/pkg/logger"
+)
+
+// checkSendQuota 检查用户发送配额和垃圾评分，返回拒绝原因，为空表示允许发送
+// Redis不可用时放行，避免反垃圾故障影响正常聊天
+func (s *Service) checkSendQuota(ctx context.Context, msg *rest.WSMessage) string {
+	cfg := s.sendQuota
+	if !cfg.Enabled || msg.From == model.SystemSenderID || s.quotaExempt[msg.From] {
+		return ""
+	}
+
+	now := time.Now()
+	client := s.redis.GetClient()
+	blockKey := fmt.Sprintf("%s:%d", model.RedisKeySendBlocked, msg.From)
+	hourKey := fmt.Sprintf("%s:%d:%s", model.RedisKeySendQuotaHourly, msg.From, now.Format("2006010215"))
+	dayKey := fmt.Sprintf("%s:%d:%s", model.RedisKeySendQuotaDaily, msg.From, now.Format("20060102"))
+
+	// 1. 临时禁止和配额检查
+	pipe := client.Pipeline()
+	blockTTL := pipe.TTL(ctx, blockKey)
+	hourCount := pipe.Get(ctx, hourKey)
+	dayCount := pipe.Get(ctx, dayKey)
+	if _, err := pipe.Exec(ctx); err != nil && err != goredis.Nil {
+		s.logger.Warn(ctx, "检查发送配额失败，放行", logger.F("userID", msg.From), logger.F("error", err.Error()))
+		return ""
+	}
+
+	if ttl := blockTTL.Val(); ttl > 0 {
+		return fmt.Sprintf("发送行为异常，已被临时限制发送，请%d秒后重试", int(math.Ceil(ttl.Seconds())))
+	}
+	if hour, _ := hourCount.Int64(); cfg.HourlyLimit > 0 && hour >= int64(cfg.HourlyLimit) {
+		return fmt.Sprintf("已达到每小时发送上限（%d条），请稍后再试", cfg.HourlyLimit)
+	}
+	if day, _ := dayCount.Int64(); cfg.DailyLimit > 0 && day >= int64(cfg.DailyLimit) {
+		return fmt.Sprintf("已达到每日发送上限（%d条），请明天再试", cfg.DailyLimit)
+	}
+
+	// 2. 计入配额并累计统计窗口内的发送行为
+	windowSeconds := cfg.SpamWindow
+	if windowSeconds <= 0 {
+		windowSeconds = model.DefaultSpamWindow
+	}
+	window := time.Duration(windowSeconds) * time.Second
+	bucket := now.Unix() / int64(windowSeconds)
+	rateKey := fmt.Sprintf("%s:%d:%d", model.RedisKeySpamRate, msg.From, bucket)
+	dupKey := fmt.Sprintf("%s:%d:%d", model.RedisKeySpamDuplicate, msg.From, bucket)
+	rcptKey := fmt.Sprintf("%s:%d:%d", model.RedisKeySpamRecipients, msg.From, bucket)
+
+	recipient := fmt.Sprintf("u:%d", msg.To)
+	if msg.GroupId > 0 {
+		recipient = fmt.Sprintf("g:%d", msg.GroupId)
+	}
+
+	pipe = client.TxPipeline()
+	pipe.Incr(ctx, hourKey)
+	pipe.Expire(ctx, hourKey, time.Hour)
+	pipe.Incr(ctx, dayKey)
+	pipe.Expire(ctx, dayKey, 24*time.Hour)
+	rateCount := pipe.Incr(ctx, rateKey)
+	pipe.Expire(ctx, rateKey, window)
+	dupCount := pipe.HIncrBy(ctx, dupKey, contentFingerprint(msg.Content), 1)
+	pipe.Expire(ctx, dupKey, window)
+	pipe.SAdd(ctx, rcptKey, recipient)
+	rcptCount := pipe.SCard(ctx, rcptKey)
+	pipe.Expire(ctx, rcptKey, window)
+	if _, err := pipe.Exec(ctx); err != nil {
+		s.logger.Warn(ctx, "记录发送行为失败，放行", logger.F("userID", msg.From), logger.F("error", err.Error()))
+		return ""
+	}
+
+	// 3. 垃圾评分，被拒绝的消息不占用配额
+	score := spamScore(cfg, rateCount.Val(), dupCount.Val(), rcptCount.Val())
+	reason := ""
+	switch {
+	case cfg.BlockScore > 0 && score >= cfg.BlockScore:
+		if err := client.Set(ctx, blockKey, strconv.FormatFloat(score, 'f', 2, 64), time.Duration(cfg.BlockDuration)*time.Second).Err(); err != nil {
+			s.logger.Warn(ctx, "设置临时禁止发送失败", logger.F("userID", msg.From), logger.F("error", err.Error()))
+		}
+		s.publishSpamFlagEvent(ctx, &model.SpamFlagEvent{
+			UserID:         msg.From,
+			MessageID:      msg.MessageId,
+			Score:          score,
+			RateCount:      rateCount.Val(),
+			DuplicateCount: dupCount.Val(),
+			RecipientCount: rcptCount.Val(),
+			BlockDuration:  cfg.BlockDuration,
+			Timestamp:      now.Unix(),
+		})
+		reason = fmt.Sprintf("发送行为异常，已被临时限制发送%d秒", cfg.BlockDuration)
+	case cfg.ThrottleScore > 0 && score >= cfg.ThrottleScore:
+		reason = "发送过于频繁，请稍后再试"
+	default:
+		return ""
+	}
+
+	pipe = client.Pipeline()
+	pipe.Decr(ctx, hourKey)
+	pipe.Decr(ctx, dayKey)
+	if _, err := pipe.Exec(ctx); err != nil {
+		s.logger.Warn(ctx, "回退发送配额失败", logger.F("userID", msg.From), logger.F("error", err.Error()))
+	}
+
+	s.logger.Warn(ctx, "用户发送被限制",
+		logger.F("userID", msg.From),
+		logger.F("score", score),
+		logger.F("rateCount", rateCount.Val()),
+		logger.F("duplicateCount", dupCount.Val()),
+		logger.F("recipientCount", rcptCount.Val()))
+	return reason
+}
+
+// spamScore 计算垃圾评分，发送频率、重复内容、接收方数量各自按阈值归一化后相加
+func spamScore(cfg config.SendQuotaConfig, rateCount, duplicateCount, recipientCount int64) float64 {
+	ratio := func(count int64, threshold int) float64 {
+		if threshold <= 0 {
+			return 0
+		}
+		return float64(count) / float64(threshold)
+	}
+	return ratio(rateCount, cfg.RateThreshold) +
+		ratio(duplicateCount, cfg.DuplicateThreshold) +
+		ratio(recipientCount, cfg.RecipientThreshold)
+}
+
+// contentFingerprint 消息内容指纹，用于统计重复内容
+func contentFingerprint(content string) string {
+	h := fnv.New64a()
+	h.Write([]byte(strings.TrimSpace(content)))
+	return strconv.FormatUint(h.Sum64(), 16)
+}
+
+// publishSpamFlagEvent 发布垃圾发送者标记事件，发送失败只记录日志
+func (s *Service) publishSpamFlagEvent(ctx context.Context, event *model.SpamFlagEvent) {
+	if s.kafka == nil {
+		return
+	}
+
+	data, err := json.Marshal(event)
+	if err != nil {
+		return
+	}
+
+	key := []byte(fmt.Sprintf("%d", event.UserID))
+	if err := s.kafka.SendMessage(model.TopicSpamFlagEvents, key, data); err != nil {
+		s.logger.Warn(ctx, "发布垃圾发送标记事件失败",
+			logger.F("userID", event.UserID),
+			logger.F("error", err.Error()))
+	}
+}
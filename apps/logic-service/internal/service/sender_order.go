@@ -0,0 +1,30 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"goim-social/api/rest"
+	"goim-social/apps/logic-service/internal/model"
+	"goim-social/pkg/conversation"
+	"goim-social/pkg/logger"
+)
+
+// assignSenderSeq 为消息分配发送者在会话内的递增序号，网关据此将同一发送者的消息按发送顺序推送给接收方
+// 序号在校验通过后、持久化和投递前分配，分配顺序即发送顺序；Redis不可用时不分配，消息按到达顺序投递
+func (s *Service) assignSenderSeq(ctx context.Context, msg *rest.WSMessage) {
+	key := fmt.Sprintf("%s:%s:%d", model.RedisKeySenderSeq, conversation.ID(msg.From, msg.To, msg.GroupId), msg.From)
+
+	pipe := s.redis.GetClient().TxPipeline()
+	seq := pipe.Incr(ctx, key)
+	pipe.Expire(ctx, key, time.Duration(model.SenderSeqTTL)*time.Second)
+	if _, err := pipe.Exec(ctx); err != nil {
+		s.logger.Warn(ctx, "分配发送者序号失败，按到达顺序投递",
+			logger.F("messageID", msg.MessageId),
+			logger.F("from", msg.From),
+			logger.F("error", err.Error()))
+		return
+	}
+	msg.SenderSeq = seq.Val()
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/base64"
 	"fmt"
+	"sync/atomic"
 	"time"
 
 	"github.com/golang/protobuf/proto"
@@ -14,9 +15,15 @@ import (
 
 	"goim-social/api/rest"
 	"goim-social/apps/logic-service/internal/model"
+	"goim-social/pkg/audit"
+	"goim-social/pkg/blocklist"
+	"goim-social/pkg/config"
 	tracecontext "goim-social/pkg/context"
+	"goim-social/pkg/conversation"
 	"goim-social/pkg/kafka"
 	"goim-social/pkg/logger"
+	"goim-social/pkg/middleware"
+	"goim-social/pkg/presence"
 	"goim-social/pkg/redis"
 	"goim-social/pkg/sessionlocator"
 	"goim-social/pkg/snowflake"
@@ -35,31 +42,47 @@ type Service struct {
 	socialClient   rest.SocialServiceClient
 	messageClient  rest.MessageServiceClient
 	userClient     rest.UserServiceClient
+	persistRetry   config.RetryConfig     // 消息持久化重试配置
+	forwardSeq     atomic.Int64           // 跨节点转发序号，网关据此丢弃重复和乱序的消息
+	sendQuota      config.SendQuotaConfig // 发送配额与反垃圾配置
+	quotaExempt    map[int64]bool         // 不受发送配额限制的用户
+	deliveryBatch  *deliveryBatcher       // 投递结果事件合并器，未启用批量发送时为nil
+	messageTypes   *messageTypeRegistry   // 消息类型注册表
+	fanoutPool     *fanoutPool            // 群消息扇出推送池
+	blocks         *blocklist.Store       // 拉黑关系缓存，拉黑双方不能互发私聊消息
+	presence       *presence.Cache        // 在线状态缓存，群消息扇出时批量判断成员是否在线
+	auditor        *audit.Recorder        // 管理操作的审计记录器
+	auditStore     *audit.Store           // 共享审计记录，供管理员查询
+	auditAdmins    map[int64]bool         // 允许查询审计记录的管理员
+	admins         map[int64]bool         // 允许广播系统消息的管理员
+
+	attachmentScan    config.AttachmentScanConfig // 附件扫描配置
+	attachmentScanner AttachmentScanner           // 媒体消息附件扫描钩子，未启用扫描时为不扫描的默认实现
 }
 
 // NewService 创建Logic服务实例
-func NewService(redis *redis.RedisClient, kafkaProducer *kafka.Producer, log logger.Logger, kafkaBrokers []string, socialAddr, messageAddr, userAddr string) (*Service, error) {
+func NewService(redis *redis.RedisClient, kafkaProducer *kafka.Producer, log logger.Logger, kafkaBrokers []string, socialAddr, messageAddr, userAddr string, persistRetry config.RetryConfig, sendQuota config.SendQuotaConfig, deliveryBatch config.DeliveryBatchConfig, fanout config.FanoutConfig, attachmentScan config.AttachmentScanConfig, adminIDs string, auditCfg config.AuditConfig, deadline config.DeadlineConfig, presenceCfg config.PresenceConfig, auditor *audit.Recorder) (*Service, error) {
 	// 初始化高可靠性同步Producer（用于持久化保障）
 	reliableKafka, err := kafka.InitReliableProducer(kafkaBrokers)
 	if err != nil {
 		return nil, fmt.Errorf("初始化可靠Kafka Producer失败: %v", err)
 	}
 	// 连接Social服务（合并了原来的Group和Friend服务）
-	socialConn, err := grpc.NewClient(socialAddr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	socialConn, err := grpc.NewClient(socialAddr, grpc.WithTransportCredentials(insecure.NewCredentials()), middleware.DeadlineDialOption(deadline))
 	if err != nil {
 		return nil, fmt.Errorf("连接Social服务失败: %v", err)
 	}
 	socialClient := rest.NewSocialServiceClient(socialConn)
 
 	// 连接Message服务
-	messageConn, err := grpc.NewClient(messageAddr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	messageConn, err := grpc.NewClient(messageAddr, grpc.WithTransportCredentials(insecure.NewCredentials()), middleware.DeadlineDialOption(deadline))
 	if err != nil {
 		return nil, fmt.Errorf("连接Message服务失败: %v", err)
 	}
 	messageClient := rest.NewMessageServiceClient(messageConn)
 
 	// 连接User服务
-	userConn, err := grpc.NewClient(userAddr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	userConn, err := grpc.NewClient(userAddr, grpc.WithTransportCredentials(insecure.NewCredentials()), middleware.DeadlineDialOption(deadline))
 	if err != nil {
 		return nil, fmt.Errorf("连接User服务失败: %v", err)
 	}
@@ -85,7 +108,22 @@ func NewService(redis *redis.RedisClient, kafkaProducer *kafka.Producer, log log
 		socialClient:   socialClient,
 		messageClient:  messageClient,
 		userClient:     userClient,
-	}
+		persistRetry:   persistRetry,
+		sendQuota:      sendQuota,
+		quotaExempt:    config.ParseUserIDs(sendQuota.ExemptUsers),
+		messageTypes:   newMessageTypeRegistry(),
+		fanoutPool:     newFanoutPool(fanout),
+		blocks:         blocklist.NewStore(redis),
+		presence:       presence.NewCache(redis, presenceCfg),
+		auditor:        auditor,
+		auditStore:     audit.NewStore(redis, auditCfg.RetentionDays),
+		auditAdmins:    config.ParseUserIDs(auditCfg.AdminIDs),
+		admins:         config.ParseUserIDs(adminIDs),
+
+		attachmentScan:    attachmentScan,
+		attachmentScanner: newAttachmentScanner(attachmentScan),
+	}
+	service.deliveryBatch = newDeliveryBatcher(deliveryBatch, service.publishDeliveryBatch)
 
 	// 启动网关清理器（包含领导者选举）
 	gatewayCleaner.Start(context.Background())
@@ -120,6 +158,36 @@ func (s *Service) ProcessMessage(ctx context.Context, msg *rest.WSMessage) (*mod
 		span.SetAttributes(attribute.Int64("message.generated_id", msg.MessageId))
 	}
 
+	// 按消息类型校验，未注册的类型直接拒绝
+	typeHandler, err := s.messageTypes.resolve(msg)
+	if err == nil {
+		err = typeHandler.Validate(msg)
+	}
+	if err != nil {
+		s.logger.Warn(ctx, "消息类型校验失败",
+			logger.F("messageID", msg.MessageId),
+			logger.F("from", msg.From),
+			logger.F("messageType", msg.MessageType),
+			logger.F("error", err.Error()))
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "invalid message type")
+		return rejectedResult(msg, err.Error(), model.RejectReasonInvalidMessage), nil
+	}
+	span.SetAttributes(attribute.String("message.kind", typeHandler.Name()))
+
+	// 发送配额与反垃圾检查，超限或评分过高时拒绝发送
+	if reason := s.checkSendQuota(ctx, msg); reason != "" {
+		span.SetStatus(codes.Error, "send quota exceeded")
+		return rejectedResult(msg, reason, model.RejectReasonRateLimited), nil
+	}
+
+	// 媒体消息在持久化和投递之前扫描附件，未通过时拒绝，扫描较慢时转为待扫描，扫描通过后再投递
+	if _, isMedia := typeHandler.(mediaMessageHandler); isMedia {
+		if result := s.scanAttachment(ctx, msg); result != nil {
+			return result, nil
+		}
+	}
+
 	s.logger.Info(ctx, "Logic服务开始处理消息",
 		logger.F("messageID", msg.MessageId),
 		logger.F("from", msg.From),
@@ -128,7 +196,6 @@ func (s *Service) ProcessMessage(ctx context.Context, msg *rest.WSMessage) (*mod
 
 	// 1. 消息路由决策
 	var result *model.MessageResult
-	var err error
 
 	if msg.GroupId > 0 {
 		// 群聊消息
@@ -140,7 +207,7 @@ func (s *Service) ProcessMessage(ctx context.Context, msg *rest.WSMessage) (*mod
 		result, err = s.processPrivateMessage(ctx, msg)
 	} else {
 		span.SetStatus(codes.Error, "invalid message target")
-		return nil, fmt.Errorf("无效的消息目标")
+		return rejectedResult(msg, "无效的消息目标", model.RejectReasonInvalidMessage), nil
 	}
 
 	if err != nil {
@@ -148,6 +215,12 @@ func (s *Service) ProcessMessage(ctx context.Context, msg *rest.WSMessage) (*mod
 		span.SetStatus(codes.Error, "failed to process message")
 		return nil, err
 	}
+	// 已受理的消息（已持久化或进入重试队列）返回分配的消息ID和发送者序号，供网关回执发送方
+	if result.RejectReason == "" {
+		result.MessageID = msg.MessageId
+		result.SenderSeq = msg.SenderSeq
+		result.ServerTime = time.Now().Unix()
+	}
 
 	span.SetAttributes(
 		attribute.Int("result.success_count", result.SuccessCount),
@@ -157,6 +230,17 @@ func (s *Service) ProcessMessage(ctx context.Context, msg *rest.WSMessage) (*mod
 	return result, nil
 }
 
+// rejectedResult 构建被拒绝消息的处理结果，reason为返回给发送方的拒绝原因
+func rejectedResult(msg *rest.WSMessage, message, reason string) *model.MessageResult {
+	return &model.MessageResult{
+		Success:      false,
+		Message:      message,
+		MessageID:    msg.MessageId,
+		FailureCount: 1,
+		RejectReason: reason,
+	}
+}
+
 // processGroupMessage 处理群聊消息
 func (s *Service) processGroupMessage(ctx context.Context, msg *rest.WSMessage) (*model.MessageResult, error) {
 	// 开始OpenTelemetry span
@@ -176,14 +260,17 @@ func (s *Service) processGroupMessage(ctx context.Context, msg *rest.WSMessage)
 		GroupId: msg.GroupId,
 		UserId:  msg.From,
 	})
-	if err != nil || !memberResp.Success || !memberResp.IsMember {
+	// 社交服务不可用时返回错误由发送方重试，只有确认不是群成员才拒绝消息
+	if err == nil && !memberResp.Success {
+		err = fmt.Errorf("%s", memberResp.Message)
+	}
+	if err != nil {
+		s.logger.Error(ctx, "验证群成员身份失败", logger.F("groupID", msg.GroupId), logger.F("error", err.Error()))
+		return nil, fmt.Errorf("验证群成员身份失败: %v", err)
+	}
+	if !memberResp.IsMember {
 		s.logger.Error(ctx, "用户不在群组中", logger.F("userID", msg.From), logger.F("groupID", msg.GroupId))
-		return &model.MessageResult{
-			Success:      false,
-			Message:      "您不在该群组中",
-			SuccessCount: 0,
-			FailureCount: 1,
-		}, nil
+		return rejectedResult(msg, "您不在该群组中", model.RejectReasonNotGroupMember), nil
 	}
 
 	// 2. 获取群成员列表
@@ -195,45 +282,30 @@ func (s *Service) processGroupMessage(ctx context.Context, msg *rest.WSMessage)
 		return nil, err
 	}
 
-	// 3. 消息持久化保障 - 同步写入Kafka确保安全落地
-	if err := s.ensureMessagePersistence(ctx, msg); err != nil {
+	// 3. 分配发送者序号，保证成员看到的该发送者消息与发送顺序一致
+	s.assignSenderSeq(ctx, msg)
+
+	// 4. 消息持久化保障 - 同步写入Kafka确保安全落地
+	queued, err := s.ensureMessagePersistence(ctx, msg)
+	if err != nil {
 		s.logger.Error(ctx, "消息持久化保障失败",
 			logger.F("messageID", msg.MessageId),
 			logger.F("error", err.Error()))
 		return nil, fmt.Errorf("消息持久化失败: %v", err)
 	}
 
-	// 4. 消息扇出 - 发送给所有群成员
-	successCount := 0
-	failureCount := 0
-	var failedUsers []int64
-
-	for _, memberID := range membersResp.MemberIds {
-		if memberID == msg.From {
-			continue // 跳过发送者
-		}
+	// 5. 消息扇出 - 发送给所有群成员，逐个记录投递结果
+	result := s.fanOutToMembers(ctx, msg, membersResp.MemberIds)
+	result.Success = result.SuccessCount > 0 || queued
+	result.Queued = queued
 
-		// 通过消息队列异步投递
-		err := s.publishMessageToQueue(ctx, memberID, msg)
-		if err != nil {
-			s.logger.Error(ctx, "消息投递失败",
-				logger.F("targetUser", memberID),
-				logger.F("error", err.Error()))
-			failureCount++
-			failedUsers = append(failedUsers, memberID)
-		} else {
-			successCount++
-		}
-	}
+	span.SetAttributes(
+		attribute.Int("fanout.delivered", result.DeliveredCount),
+		attribute.Int("fanout.offline", result.OfflineCount),
+		attribute.Int("fanout.failed", result.FailureCount),
+	)
 
-	return &model.MessageResult{
-		Success:      successCount > 0,
-		Message:      fmt.Sprintf("群消息发送完成，成功: %d, 失败: %d", successCount, failureCount),
-		MessageID:    msg.MessageId,
-		SuccessCount: successCount,
-		FailureCount: failureCount,
-		FailedUsers:  failedUsers,
-	}, nil
+	return result, nil
 }
 
 // processPrivateMessage 处理私聊消息
@@ -255,29 +327,56 @@ func (s *Service) processPrivateMessage(ctx context.Context, msg *rest.WSMessage
 		UserId:   msg.From,
 		FriendId: msg.To,
 	})
-	if err != nil || !friendResp.Success || !friendResp.IsFriend {
+	// 社交服务不可用时返回错误由发送方重试，只有确认不是好友才拒绝消息
+	if err == nil && !friendResp.Success {
+		err = fmt.Errorf("%s", friendResp.Message)
+	}
+	if err != nil {
+		s.logger.Error(ctx, "验证好友关系失败", logger.F("from", msg.From), logger.F("to", msg.To), logger.F("error", err.Error()))
+		return nil, fmt.Errorf("验证好友关系失败: %v", err)
+	}
+	if !friendResp.IsFriend {
 		s.logger.Error(ctx, "用户不是好友关系", logger.F("from", msg.From), logger.F("to", msg.To))
-		return &model.MessageResult{
-			Success:      false,
-			Message:      "您与对方不是好友关系",
-			SuccessCount: 0,
-			FailureCount: 1,
-		}, nil
+		return rejectedResult(msg, "您与对方不是好友关系", model.RejectReasonNotFriend), nil
 	}
 	s.logger.Info(ctx, "好友关系验证通过", logger.F("from", msg.From), logger.F("to", msg.To))
 
-	// 2. 消息持久化保障 - 同步写入Kafka确保安全落地
-	if err := s.ensureMessagePersistence(ctx, msg); err != nil {
+	// 拉黑双方不能互发私聊消息，查询失败时不拦截
+	blocked, err := s.blocks.IsHidden(ctx, msg.From, msg.To)
+	if err != nil {
+		s.logger.Warn(ctx, "查询拉黑关系失败，不拦截", logger.F("error", err.Error()))
+	} else if blocked {
+		s.logger.Info(ctx, "拉黑关系拦截私聊消息", logger.F("from", msg.From), logger.F("to", msg.To))
+		return rejectedResult(msg, "消息已被对方拒收", model.RejectReasonBlocked), nil
+	}
+
+	// 2. 分配发送者序号，保证接收方看到的该发送者消息与发送顺序一致
+	s.assignSenderSeq(ctx, msg)
+
+	// 3. 消息持久化保障 - 同步写入Kafka确保安全落地
+	queued, err := s.ensureMessagePersistence(ctx, msg)
+	if err != nil {
 		s.logger.Error(ctx, "消息持久化保障失败",
 			logger.F("messageID", msg.MessageId),
 			logger.F("error", err.Error()))
 		return nil, fmt.Errorf("消息持久化失败: %v", err)
 	}
 
-	// 3. 消息投递
+	// 4. 消息投递
 	err = s.publishMessageToQueue(ctx, msg.To, msg)
 	if err != nil {
 		s.logger.Error(ctx, "私聊消息投递失败", logger.F("error", err.Error()))
+		if queued {
+			// 消息已排队，持久化后接收方可通过未读消息拉取
+			return &model.MessageResult{
+				Success:      true,
+				Message:      "消息已排队，稍后送达",
+				MessageID:    msg.MessageId,
+				FailureCount: 1,
+				FailedUsers:  []int64{msg.To},
+				Queued:       true,
+			}, nil
+		}
 		return &model.MessageResult{
 			Success:      false,
 			Message:      "消息发送失败",
@@ -287,13 +386,18 @@ func (s *Service) processPrivateMessage(ctx context.Context, msg *rest.WSMessage
 		}, nil
 	}
 
-	return &model.MessageResult{
+	result := &model.MessageResult{
 		Success:      true,
 		Message:      "私聊消息发送成功",
 		MessageID:    msg.MessageId,
 		SuccessCount: 1,
 		FailureCount: 0,
-	}, nil
+		Queued:       queued,
+	}
+	if queued {
+		result.Message = "私聊消息已送达，持久化排队中"
+	}
+	return result, nil
 }
 
 // publishMessageToQueue 将消息发布到消息队列
@@ -308,6 +412,9 @@ func (s *Service) publishMessageToQueue(ctx context.Context, targetUserID int64,
 		MessageType: msg.MessageType,
 		Timestamp:   msg.Timestamp,
 		AckId:       msg.AckId,
+		Ttl:         msg.Ttl,
+		SenderSeq:   msg.SenderSeq,
+		Priority:    msg.Priority,
 	}
 
 	// 使用会话定位器找到用户对应的网关实例
@@ -341,6 +448,8 @@ func (s *Service) forwardMessageToGateway(ctx context.Context, gateway *sessionl
 		Message:    msg,
 		TargetUser: msg.To,
 		Timestamp:  time.Now().Unix(),
+		Source:     s.instanceID,
+		Seq:        s.forwardSeq.Add(1),
 	}
 
 	// 序列化为protobuf二进制数据
@@ -387,8 +496,8 @@ func (s *Service) publishToKafkaFallback(ctx context.Context, msg *rest.WSMessag
 		Timestamp: time.Now().Unix(),
 	}
 
-	// 发布到下行消息队列
-	return s.kafka.PublishMessage("downlink_messages", messageEvent)
+	// 发布到下行消息队列，按接收方分区，保证同一接收方的消息按发布顺序推送
+	return s.kafka.PublishMessageWithKey("downlink_messages", []byte(fmt.Sprintf("%d", msg.To)), messageEvent)
 }
 
 // GetSessionLocatorStatus 获取会话定位器状态信息
@@ -540,9 +649,8 @@ func (s *Service) HandleMessageAck(ctx context.Context, userID, messageID int64,
 		return fmt.Errorf("标记消息已读失败: %s", resp.Message)
 	}
 
-	// 3. 可能的扩展：发送已读回执通知给发送方
-	// TODO: 实现已读回执通知功能
-	// s.sendReadReceiptNotification(ctx, messageID, userID)
+	// 3. 按双方的已读回执设置通知发送方
+	s.sendReadReceipts(ctx, userID, resp.Receipts)
 
 	s.logger.Info(ctx, "消息ACK处理成功",
 		logger.F("userID", userID),
@@ -575,7 +683,10 @@ func (s *Service) validateAckPermission(ctx context.Context, userID, messageID i
 }
 
 // ensureMessagePersistence 确保消息持久化, 同步写入专门的持久化Topic
-func (s *Service) ensureMessagePersistence(ctx context.Context, msg *rest.WSMessage) error {
+// 写入失败时按配置退避重试，重试耗尽后降级到重试Topic，返回值queued表示消息已排队尚未落地。
+// 重试Topic与持久化Topic位于同一Kafka集群，只能应对单个Topic或分区的故障；整个集群不可用时两者同时失败，
+// 本地不落盘暂存，消息不会被投递，返回错误由客户端重发
+func (s *Service) ensureMessagePersistence(ctx context.Context, msg *rest.WSMessage) (bool, error) {
 	s.logger.Info(ctx, "开始消息持久化保障",
 		logger.F("messageID", msg.MessageId),
 		logger.F("from", msg.From),
@@ -589,19 +700,112 @@ func (s *Service) ensureMessagePersistence(ctx context.Context, msg *rest.WSMess
 		Timestamp: time.Now().Unix(),
 	}
 
+	// 同一会话已有排队消息时直接排队，保证会话内消息落地顺序
+	convKey := conversation.ID(msg.From, msg.To, msg.GroupId)
+	if s.hasQueuedPersistence(ctx, convKey) {
+		if err := s.enqueuePersistenceRetry(ctx, convKey, persistenceCommand); err != nil {
+			return false, fmt.Errorf("消息持久化保障失败: %v", err)
+		}
+		return true, nil
+	}
+
 	// 使用高可靠性同步Producer写入专门的持久化Topic
 	// 这个Topic有独立的配置：更高副本数、更长保留期、独立监控
-	if err := s.reliableKafka.PublishMessageSync("message_persistence_log", persistenceCommand); err != nil {
+	maxAttempts := s.persistRetry.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = 1
+	}
+	backoff := time.Duration(s.persistRetry.InitialBackoff) * time.Millisecond
+	maxBackoff := time.Duration(s.persistRetry.MaxBackoff) * time.Millisecond
+
+	var lastErr error
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		lastErr = s.reliableKafka.PublishMessageSync(model.TopicMessagePersistence, persistenceCommand)
+		if lastErr == nil {
+			s.logger.Info(ctx, "消息归档命令已安全写入持久化Topic（已确认）",
+				logger.F("messageID", msg.MessageId),
+				logger.F("topic", model.TopicMessagePersistence),
+				logger.F("attempt", attempt))
+			return false, nil
+		}
+
+		s.logger.Warn(ctx, "写入持久化Topic失败",
+			logger.F("messageID", msg.MessageId),
+			logger.F("topic", model.TopicMessagePersistence),
+			logger.F("attempt", attempt),
+			logger.F("error", lastErr.Error()))
+
+		if attempt == maxAttempts {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			return false, fmt.Errorf("消息持久化保障失败: %v", ctx.Err())
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+		if maxBackoff > 0 && backoff > maxBackoff {
+			backoff = maxBackoff
+		}
+	}
+
+	// 重试耗尽，降级到重试Topic，由Message服务异步归档
+	if err := s.enqueuePersistenceRetry(ctx, convKey, persistenceCommand); err != nil {
 		s.logger.Error(ctx, "消息持久化保障失败",
 			logger.F("messageID", msg.MessageId),
-			logger.F("topic", "message_persistence_log"),
+			logger.F("topic", model.TopicMessagePersistenceRetry),
+			logger.F("lastError", lastErr.Error()),
 			logger.F("error", err.Error()))
-		return fmt.Errorf("消息持久化保障失败: %v", err)
+		return false, fmt.Errorf("消息持久化保障失败: %v", lastErr)
 	}
+	return true, nil
+}
 
-	s.logger.Info(ctx, "消息归档命令已安全写入持久化Topic（已确认）",
-		logger.F("messageID", msg.MessageId),
-		logger.F("topic", "message_persistence_log"))
+// enqueuePersistenceRetry 将归档命令写入重试Topic，以会话为Key保证同会话消息落在同一分区
+// 使用高可靠性同步Producer，Broker确认写入后才返回成功，调用方据此向客户端返回排队状态
+func (s *Service) enqueuePersistenceRetry(ctx context.Context, convKey string, command *rest.MessageEvent) error {
+	data, err := proto.Marshal(command)
+	if err != nil {
+		return fmt.Errorf("序列化归档命令失败: %v", err)
+	}
+
+	if err := s.reliableKafka.SendMessageSync(model.TopicMessagePersistenceRetry, []byte(convKey), data); err != nil {
+		return fmt.Errorf("写入重试Topic失败: %v", err)
+	}
+
+	// 标记会话存在排队消息，后续消息跟随排队
+	pendingKey := fmt.Sprintf("%s:%s", model.RedisKeyPersistRetryPending, convKey)
+	if err := s.redis.Set(ctx, pendingKey, 1, time.Duration(model.PersistRetryStickyTTL)*time.Second); err != nil {
+		s.logger.Warn(ctx, "设置会话排队标记失败",
+			logger.F("conversation", convKey),
+			logger.F("error", err.Error()))
+	}
 
+	s.logger.Warn(ctx, "消息已进入持久化重试队列",
+		logger.F("messageID", command.Message.MessageId),
+		logger.F("conversation", convKey),
+		logger.F("topic", model.TopicMessagePersistenceRetry))
 	return nil
 }
+
+// hasQueuedPersistence 检查会话是否存在排队中的消息
+func (s *Service) hasQueuedPersistence(ctx context.Context, convKey string) bool {
+	pendingKey := fmt.Sprintf("%s:%s", model.RedisKeyPersistRetryPending, convKey)
+	exists, err := s.redis.Exists(ctx, pendingKey)
+	return err == nil && exists > 0
+}
+
+// CheckMessageDelivery 查询Message服务的投递链路状态，用于健康检查
+func (s *Service) CheckMessageDelivery(ctx context.Context) (*rest.GetDeliveryStatusResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, model.DependencyCheckTimeout*time.Second)
+	defer cancel()
+
+	resp, err := s.messageClient.GetDeliveryStatus(ctx, &rest.GetDeliveryStatusRequest{})
+	if err != nil {
+		return nil, fmt.Errorf("查询投递链路状态失败: %v", err)
+	}
+	if !resp.Success {
+		return nil, fmt.Errorf("查询投递链路状态失败: %s", resp.Message)
+	}
+	return resp, nil
+}
@@ -2,17 +2,20 @@ package main
 
 import (
 	"context"
+	"fmt"
 	"log"
 	"os"
-	"time"
 
 	"github.com/gin-gonic/gin"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
 
 	"goim-social/api/rest"
 	"goim-social/apps/message-service/internal/consumer"
+	"goim-social/apps/message-service/internal/dao"
 	"goim-social/apps/message-service/internal/handler"
 	"goim-social/apps/message-service/internal/service"
+	"goim-social/pkg/encryption"
 	"goim-social/pkg/middleware"
 	"goim-social/pkg/server"
 	"goim-social/pkg/telemetry"
@@ -40,7 +43,7 @@ func main() {
 
 	// 确保在程序退出时关闭OpenTelemetry
 	defer func() {
-		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		ctx, cancel := context.WithTimeout(context.Background(), otelConfig.ShutdownTimeout)
 		defer cancel()
 		if err := telemetry.ShutdownGlobal(ctx); err != nil {
 			log.Printf("Failed to shutdown OpenTelemetry: %v", err)
@@ -56,15 +59,109 @@ func main() {
 	app.EnableHTTP()
 	app.EnableGRPC()
 
+	cfg := app.GetConfig()
+
+	// 初始化消息内容加密器（未启用时不加密）
+	encryptor, err := encryption.NewFromConfig(cfg.Message.Encryption)
+	if err != nil {
+		log.Fatalf("Failed to initialize message encryptor: %v", err)
+	}
+
+	// 初始化社交服务客户端（群组权限校验）
+	socialAddr := fmt.Sprintf("%s:%d", cfg.Services.SocialService.Host, cfg.Services.SocialService.Port)
+	socialConn, err := grpc.NewClient(socialAddr, grpc.WithTransportCredentials(insecure.NewCredentials()), middleware.DeadlineDialOption(cfg.Deadline))
+	if err != nil {
+		log.Fatalf("Failed to connect to social service: %v", err)
+	}
+	defer socialConn.Close()
+
+	// 按消息类型的存储策略，存储、持久化和推送消费者共用
+	storagePolicy := consumer.NewStoragePolicy(cfg.Message.Storage)
+
+	// 推送消费者同时为投递链路状态检查提供推送统计
+	pushConsumer := consumer.NewPushConsumer(app.GetRedisClient(), cfg.Message.AckResend, cfg.Message.Digest, storagePolicy)
+	// 投递结果事件消费者为投递链路状态检查提供扇出投递统计
+	// 启用投递汇总时同时记录群消息的成员投递结果
+	deliveryEventConsumer := consumer.NewDeliveryEventConsumer(app.GetRedisClient(), cfg.Message.DeliveryReport)
+
+	// 消息存储，服务层和消费者只依赖存储接口
+	store := dao.NewMongoDAO(app.GetMongoDB().GetDatabase())
+
 	// 初始化Service层
-	svc := service.NewService(app.GetMongoDB(), app.GetRedisClient(), app.GetKafkaProducer(), app.GetLogger())
+	svc := service.NewService(store, app.GetRedisClient(), app.GetKafkaProducer(), encryptor, rest.NewSocialServiceClient(socialConn), pushConsumer, pushConsumer, deliveryEventConsumer, cfg.Message.Export, cfg.Message.Archive, cfg.Search.Highlight, cfg.Message.Digest, cfg.Message.Retention, cfg.Message.Poll, cfg.Message.Pin, cfg.Message.DeliveryReport, app.GetLogger())
 
 	// 启动Kafka消费者
 	ctx := context.Background()
-	cfg := app.GetConfig()
+
+	// 创建索引，失败时不影响启动
+	if err := svc.EnsureIndexes(ctx); err != nil {
+		log.Printf("创建索引失败: %v", err)
+	}
+
+	// 启动过期消息清理任务
+	expiryCtx, stopExpiry := context.WithCancel(ctx)
+	go svc.RunExpiryWorker(expiryCtx)
+	app.RegisterShutdownHook("message-expiry", func(ctx context.Context) error {
+		stopExpiry()
+		return nil
+	})
+
+	// 启动群聊记录导出任务，退出时未完成的导出记为失败
+	exportCtx, stopExport := context.WithCancel(ctx)
+	go svc.RunExportWorker(exportCtx)
+	app.RegisterShutdownHook("group-export", func(ctx context.Context) error {
+		stopExport()
+		return nil
+	})
+
+	// 启动历史消息保留期清理任务
+	retentionCtx, stopRetention := context.WithCancel(ctx)
+	go svc.RunRetentionPurger(retentionCtx)
+	app.RegisterShutdownHook("message-retention", func(ctx context.Context) error {
+		stopRetention()
+		return nil
+	})
+
+	// 启动投票结果更新推送任务
+	pollCtx, stopPoll := context.WithCancel(ctx)
+	go svc.RunPollUpdateFlusher(pollCtx)
+	app.RegisterShutdownHook("poll-update", func(ctx context.Context) error {
+		stopPoll()
+		return nil
+	})
+
+	// 启动群消息投递和已读汇总推送任务
+	if cfg.Message.DeliveryReport.Enabled {
+		reportCtx, stopReport := context.WithCancel(ctx)
+		go svc.RunDeliveryReportFlusher(reportCtx)
+		app.RegisterShutdownHook("delivery-report", func(ctx context.Context) error {
+			stopReport()
+			return nil
+		})
+	}
+
+	// 启动推送确认超时重发任务
+	if cfg.Message.AckResend.Enabled {
+		ackCtx, stopAckResend := context.WithCancel(ctx)
+		go pushConsumer.RunAckResender(ackCtx)
+		app.RegisterShutdownHook("push-ack-resend", func(ctx context.Context) error {
+			stopAckResend()
+			return nil
+		})
+	}
+
+	// 启动通知摘要推送任务
+	if cfg.Message.Digest.Enabled {
+		digestCtx, stopDigest := context.WithCancel(ctx)
+		go pushConsumer.RunDigestFlusher(digestCtx)
+		app.RegisterShutdownHook("notification-digest", func(ctx context.Context) error {
+			stopDigest()
+			return nil
+		})
+	}
 
 	// 启动存储消费者（处理uplink_messages中的原始消息）
-	storageConsumer := consumer.NewStorageConsumer(app.GetMongoDB())
+	storageConsumer := consumer.NewStorageConsumer(store, encryptor, storagePolicy, app.GetKafkaProducer())
 	go func() {
 		log.Println("启动存储消费者...")
 		if err := storageConsumer.Start(ctx, cfg.Kafka.Brokers); err != nil {
@@ -73,7 +170,7 @@ func main() {
 	}()
 
 	// 启动持久化消费者（处理message_persistence_log中的归档命令）
-	persistenceConsumer := consumer.NewPersistenceConsumer(app.GetMongoDB())
+	persistenceConsumer := consumer.NewPersistenceConsumer(store, encryptor, storagePolicy, app.GetKafkaProducer())
 	go func() {
 		log.Println("启动持久化消费者...")
 		if err := persistenceConsumer.Start(ctx, cfg.Kafka.Brokers); err != nil {
@@ -82,7 +179,6 @@ func main() {
 	}()
 
 	// 启动推送消费者
-	pushConsumer := consumer.NewPushConsumer(app.GetRedisClient())
 	go func() {
 		log.Println("启动推送消费者...")
 		if err := pushConsumer.Start(ctx, cfg.Kafka.Brokers); err != nil {
@@ -90,6 +186,24 @@ func main() {
 		}
 	}()
 
+	// 启动投递结果事件消费者
+	go func() {
+		log.Println("启动投递结果事件消费者...")
+		if err := deliveryEventConsumer.Start(ctx, cfg.Kafka.Brokers); err != nil {
+			log.Fatalf("Failed to start delivery event consumer: %v", err)
+		}
+	}()
+
+	// 退出时关闭消费者，等待进行中的消息处理完成并提交位移
+	app.RegisterShutdownHook("kafka-consumers", func(ctx context.Context) error {
+		for _, c := range []interface{ Stop() error }{storageConsumer, persistenceConsumer, pushConsumer, deliveryEventConsumer} {
+			if err := c.Stop(); err != nil {
+				log.Printf("停止Kafka消费者失败: %v", err)
+			}
+		}
+		return nil
+	})
+
 	// 创建OpenTelemetry中间件
 	otelMW := middleware.NewOTelMiddleware(serviceName, app.GetLogger())
 
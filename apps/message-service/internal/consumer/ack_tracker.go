@@ -0,0 +1,234 @@
+package consumer
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	goredis "github.com/go-redis/redis/v8"
+	"google.golang.org/protobuf/proto"
+
+	"goim-social/api/rest"
+	"goim-social/apps/message-service/internal/model"
+)
+
+// ==================== 推送确认超时重发 ====================
+// 推送成功后在Redis中登记待确认记录，客户端确认（标记已读）时移除；
+// 重发任务扫描超过截止时间仍未确认的记录，用户仍在线且未超过最多推送次数时重发，否则留给客户端上线拉取。
+// 待确认记录存放在Redis中，确认请求落在任意Message实例上都能移除，多实例下由认领脚本保证同一记录只被一个实例重发。
+
+// trackAckScript 登记待确认推送，已确认的消息不再登记，避免重发期间到达的确认被覆盖
+var trackAckScript = goredis.NewScript(`
+if redis.call('EXISTS', KEYS[4]) == 1 then
+	return 0
+end
+redis.call('ZADD', KEYS[1], ARGV[2], ARGV[1])
+redis.call('HSET', KEYS[2], ARGV[1], ARGV[3])
+redis.call('HSET', KEYS[3], ARGV[1], ARGV[4])
+return 1
+`)
+
+// claimAckScript 认领一条超时的待确认推送，返回消息内容和已推送次数；已被其他实例认领或已确认时返回nil
+var claimAckScript = goredis.NewScript(`
+if redis.call('ZREM', KEYS[1], ARGV[1]) == 0 then
+	return false
+end
+local payload = redis.call('HGET', KEYS[2], ARGV[1])
+local attempts = redis.call('HGET', KEYS[3], ARGV[1])
+redis.call('HDEL', KEYS[2], ARGV[1])
+redis.call('HDEL', KEYS[3], ARGV[1])
+if not payload or redis.call('EXISTS', KEYS[4]) == 1 then
+	return false
+end
+return {payload, attempts or '1'}
+`)
+
+// ackScript 确认推送，设置已确认标记并移除待确认记录；只有首次确认且存在待确认记录时返回1
+var ackScript = goredis.NewScript(`
+local first = redis.call('SET', KEYS[4], '1', 'NX', 'EX', ARGV[2])
+local pending = redis.call('ZREM', KEYS[1], ARGV[1])
+local payload = redis.call('HDEL', KEYS[2], ARGV[1])
+redis.call('HDEL', KEYS[3], ARGV[1])
+if first and (pending == 1 or payload == 1) then
+	return 1
+end
+return 0
+`)
+
+// pendingAckMember 待确认记录成员，群消息按成员扇出后消息ID相同，需带上用户ID
+func pendingAckMember(userID, messageID int64) string {
+	return fmt.Sprintf("%d:%d", userID, messageID)
+}
+
+// ackKeys 待确认相关的键，顺序与脚本中的KEYS一致
+func ackKeys(member string) []string {
+	return []string{
+		model.CacheKeyPendingAck,
+		model.CacheKeyPendingAckPayload,
+		model.CacheKeyPendingAckAttempts,
+		fmt.Sprintf("%s:%s", model.CacheKeyAckedPrefix, member),
+	}
+}
+
+// ackedMarkerTTL 已确认标记的保留时间，覆盖全部重发周期，之后到达的确认已无待确认记录可移除
+func (p *PushConsumer) ackedMarkerTTL() int {
+	return p.ackCfg.Timeout * (p.ackCfg.MaxAttempts + 1) * 2
+}
+
+// pushAndTrack 推送消息，需要确认时登记待确认记录
+func (p *PushConsumer) pushAndTrack(userID int64, msg *rest.WSMessage, needAck bool) {
+	pushed, err := p.pushToGatewayService(userID, msg)
+	if err != nil {
+		log.Printf("推送消息到Gateway服务失败: %v", err)
+		return
+	}
+	if pushed && needAck {
+		p.trackPendingAck(context.Background(), userID, msg, 1)
+	}
+}
+
+// trackPendingAck 登记待确认推送，attempts为已推送次数
+func (p *PushConsumer) trackPendingAck(ctx context.Context, userID int64, msg *rest.WSMessage, attempts int) {
+	if !p.ackCfg.Enabled || p.redis == nil {
+		return
+	}
+
+	data, err := proto.Marshal(msg)
+	if err != nil {
+		log.Printf("序列化待确认消息失败: %v, MessageID=%d", err, msg.MessageId)
+		return
+	}
+
+	member := pendingAckMember(userID, msg.MessageId)
+	deadline := time.Now().Add(time.Duration(p.ackCfg.Timeout) * time.Second).UnixMilli()
+	if err := trackAckScript.Run(ctx, p.redis.GetClient(), ackKeys(member),
+		member, deadline, base64.StdEncoding.EncodeToString(data), attempts).Err(); err != nil {
+		log.Printf("登记待确认推送失败: %v, UserID=%d, MessageID=%d", err, userID, msg.MessageId)
+	}
+}
+
+// Acknowledge 确认用户已收到消息，移除待确认记录；同一推送只在首次确认时计数，重发后到达的迟到确认不重复计数
+func (p *PushConsumer) Acknowledge(ctx context.Context, userID int64, messageIDs []int64) {
+	if !p.ackCfg.Enabled || p.redis == nil {
+		return
+	}
+
+	for _, messageID := range messageIDs {
+		member := pendingAckMember(userID, messageID)
+		first, err := ackScript.Run(ctx, p.redis.GetClient(), ackKeys(member), member, p.ackedMarkerTTL()).Int()
+		if err != nil {
+			log.Printf("确认推送失败: %v, UserID=%d, MessageID=%d", err, userID, messageID)
+			continue
+		}
+		if first == 1 {
+			p.recordAckOutcome(func(stat *model.AckResendStat) { stat.Acked++ })
+		}
+	}
+}
+
+// RunAckResender 定期重发超时未确认的推送，直到ctx取消
+func (p *PushConsumer) RunAckResender(ctx context.Context) {
+	if !p.ackCfg.Enabled || p.redis == nil {
+		return
+	}
+
+	ticker := time.NewTicker(model.AckResendScanInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			p.resendExpired(ctx)
+		}
+	}
+}
+
+// resendExpired 处理一批超时未确认的推送
+func (p *PushConsumer) resendExpired(ctx context.Context) {
+	members, err := p.redis.ZRangeByScore(ctx, model.CacheKeyPendingAck, &goredis.ZRangeBy{
+		Min:   "-inf",
+		Max:   strconv.FormatInt(time.Now().UnixMilli(), 10),
+		Count: model.AckResendBatchSize,
+	})
+	if err != nil {
+		log.Printf("获取超时未确认推送失败: %v", err)
+		return
+	}
+
+	for _, member := range members {
+		p.resendOne(ctx, member)
+	}
+}
+
+// resendOne 认领并处理一条超时未确认的推送
+func (p *PushConsumer) resendOne(ctx context.Context, member string) {
+	result, err := claimAckScript.Run(ctx, p.redis.GetClient(), ackKeys(member), member).StringSlice()
+	if err != nil {
+		if err != goredis.Nil {
+			log.Printf("认领超时未确认推送失败: %v, Member=%s", err, member)
+		}
+		return
+	}
+	if len(result) != 2 {
+		return
+	}
+
+	userID, err := strconv.ParseInt(strings.SplitN(member, ":", 2)[0], 10, 64)
+	if err != nil {
+		return
+	}
+	attempts, _ := strconv.Atoi(result[1])
+
+	if attempts >= p.ackCfg.MaxAttempts {
+		log.Printf("推送重发次数用尽，等待客户端上线拉取: Member=%s, Attempts=%d", member, attempts)
+		p.recordAckOutcome(func(stat *model.AckResendStat) { stat.GaveUp++ })
+		return
+	}
+
+	data, err := base64.StdEncoding.DecodeString(result[0])
+	if err != nil {
+		return
+	}
+	var msg rest.WSMessage
+	if err := proto.Unmarshal(data, &msg); err != nil {
+		log.Printf("解析待确认消息失败: %v, Member=%s", err, member)
+		return
+	}
+
+	// 用户已断开时不再重发，消息保持未读，上线后拉取
+	pushed, err := p.pushToGatewayService(userID, &msg)
+	if err != nil {
+		log.Printf("重发推送失败: %v, Member=%s", err, member)
+		return
+	}
+	if !pushed {
+		p.recordAckOutcome(func(stat *model.AckResendStat) { stat.SkippedOffline++ })
+		p.requeueDigest(ctx, userID, &msg)
+		return
+	}
+
+	p.recordAckOutcome(func(stat *model.AckResendStat) { stat.Resent++ })
+	p.trackPendingAck(ctx, userID, &msg, attempts+1)
+}
+
+// recordAckOutcome 累计推送确认与重发统计
+func (p *PushConsumer) recordAckOutcome(update func(stat *model.AckResendStat)) {
+	p.statsMu.Lock()
+	defer p.statsMu.Unlock()
+	update(&p.ackStat)
+}
+
+// AckResendStats 返回推送确认与重发统计快照
+func (p *PushConsumer) AckResendStats() *model.AckResendStat {
+	p.statsMu.Lock()
+	defer p.statsMu.Unlock()
+
+	snapshot := p.ackStat
+	return &snapshot
+}
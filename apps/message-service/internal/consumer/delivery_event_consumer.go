@@ -0,0 +1,195 @@
+package consumer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/IBM/sarama"
+	goredis "github.com/go-redis/redis/v8"
+
+	"goim-social/apps/message-service/internal/model"
+	"goim-social/pkg/config"
+	"goim-social/pkg/kafka"
+	"goim-social/pkg/redis"
+)
+
+// DeliveryEventConsumer 投递结果事件消费者
+// 职责：消费Logic服务发布的成员级投递结果，兼容单条事件和批量事件两种格式，累计扇出投递统计，
+// 启用投递汇总时在Redis中记录每条群消息的成员投递结果，由服务层按间隔汇总推送给发送者
+type DeliveryEventConsumer struct {
+	consumer  *kafka.Consumer
+	redis     *redis.RedisClient
+	reportCfg config.DeliveryReportConfig
+
+	mu   sync.Mutex
+	stat model.FanoutDeliveryStat
+}
+
+// NewDeliveryEventConsumer 创建投递结果事件消费者
+func NewDeliveryEventConsumer(redis *redis.RedisClient, reportCfg config.DeliveryReportConfig) *DeliveryEventConsumer {
+	return &DeliveryEventConsumer{
+		redis:     redis,
+		reportCfg: reportCfg,
+	}
+}
+
+// Start 启动投递结果事件消费者
+func (d *DeliveryEventConsumer) Start(ctx context.Context, brokers []string) error {
+	cfg := kafka.KafkaConfig{
+		Brokers: brokers,
+		GroupID: "delivery-event-consumer-group",
+		Topics:  []string{model.TopicDeliveryEvents},
+	}
+
+	consumer, err := kafka.InitConsumer(cfg, d)
+	if err != nil {
+		return err
+	}
+
+	d.consumer = consumer
+	log.Printf("投递结果事件消费者启动成功，监听topic: %s", model.TopicDeliveryEvents)
+
+	return d.consumer.StartConsuming(ctx)
+}
+
+// HandleMessage 实现 kafka.ConsumerHandler 接口
+func (d *DeliveryEventConsumer) HandleMessage(msg *sarama.ConsumerMessage) error {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("投递结果事件消费者处理消息时发生panic: %v", r)
+		}
+	}()
+
+	events, batched, err := decodeDeliveryEvents(msg.Value)
+	if err != nil {
+		log.Printf("解析投递结果事件失败: %v, 原始消息: %s", err, string(msg.Value))
+		return nil // 返回nil避免重试
+	}
+
+	d.record(events, batched)
+	if err := d.track(context.Background(), events); err != nil {
+		log.Printf("记录群消息投递结果失败: %v", err)
+	}
+	return nil
+}
+
+// decodeDeliveryEvents 解析投递结果事件，含events字段时为批量格式，否则为单条格式
+func decodeDeliveryEvents(data []byte) ([]*model.FanoutDeliveryEvent, bool, error) {
+	var batch model.FanoutDeliveryBatch
+	if err := json.Unmarshal(data, &batch); err != nil {
+		return nil, false, err
+	}
+	if batch.Events != nil {
+		return batch.Events, true, nil
+	}
+
+	var event model.FanoutDeliveryEvent
+	if err := json.Unmarshal(data, &event); err != nil {
+		return nil, false, err
+	}
+	return []*model.FanoutDeliveryEvent{&event}, false, nil
+}
+
+// record 累计投递结果
+func (d *DeliveryEventConsumer) record(events []*model.FanoutDeliveryEvent, batched bool) {
+	now := time.Now()
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if batched {
+		d.stat.Batches++
+	}
+	for _, event := range events {
+		if event == nil {
+			continue
+		}
+		switch event.Status {
+		case model.FanoutStatusDelivered:
+			d.stat.Delivered++
+		case model.FanoutStatusQueuedOffline:
+			d.stat.QueuedOffline++
+		case model.FanoutStatusFailed:
+			d.stat.Failed++
+		}
+
+		eventAt := time.Unix(event.Timestamp, 0)
+		if eventAt.After(d.stat.LastEventAt) {
+			d.stat.LastEventAt = eventAt
+		}
+		if lag := int64(now.Sub(eventAt) / time.Second); lag > d.stat.MaxLag {
+			d.stat.MaxLag = lag
+		}
+	}
+}
+
+// track 记录群消息的成员投递结果和发送者，并标记所在群待汇总
+// 每个群只跟踪最近的若干条消息，投递结果在保留时长后过期
+func (d *DeliveryEventConsumer) track(ctx context.Context, events []*model.FanoutDeliveryEvent) error {
+	if d.redis == nil || !d.reportCfg.Enabled {
+		return nil
+	}
+
+	retention := time.Duration(d.reportCfg.Retention) * time.Hour
+	if retention <= 0 {
+		retention = model.DefaultDeliveryRetention
+	}
+
+	pipe := d.redis.GetClient().Pipeline()
+	messages := make(map[int64]bool)
+	groups := make(map[int64]bool)
+	for _, event := range events {
+		if event == nil || event.MessageID <= 0 || event.GroupID <= 0 || event.UserID <= 0 {
+			continue
+		}
+
+		key := fmt.Sprintf("%s:%d", model.CacheKeyDeliveryPrefix, event.MessageID)
+		pipe.HSet(ctx, key, strconv.FormatInt(event.UserID, 10), event.Status)
+		if !messages[event.MessageID] {
+			messages[event.MessageID] = true
+			if event.SenderID > 0 {
+				pipe.HSet(ctx, key, model.DeliveryFieldSender, event.SenderID)
+			}
+			pipe.Expire(ctx, key, retention)
+
+			groupKey := fmt.Sprintf("%s:%d", model.CacheKeyDeliveryGroupPrefix, event.GroupID)
+			pipe.ZAdd(ctx, groupKey, &goredis.Z{Score: float64(event.MessageID), Member: event.MessageID})
+			pipe.ZRemRangeByRank(ctx, groupKey, 0, -model.MaxTrackedGroupMessages-1)
+			pipe.Expire(ctx, groupKey, retention)
+		}
+		groups[event.GroupID] = true
+	}
+	if len(messages) == 0 {
+		return nil
+	}
+
+	now := float64(time.Now().UnixMilli())
+	for groupID := range groups {
+		pipe.ZAddNX(ctx, model.CacheKeyDeliveryDirty, &goredis.Z{Score: now, Member: groupID})
+	}
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// FanoutStats 返回扇出投递结果统计快照
+func (d *DeliveryEventConsumer) FanoutStats() *model.FanoutDeliveryStat {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	snapshot := d.stat
+	return &snapshot
+}
+
+// Stop 停止消费者
+func (d *DeliveryEventConsumer) Stop() error {
+	if d.consumer != nil {
+		log.Printf("投递结果事件消费者停止")
+		return d.consumer.Close()
+	}
+	return nil
+}
@@ -0,0 +1,35 @@
+package consumer
+
+import (
+	"encoding/json"
+	"log"
+	"strconv"
+
+	"goim-social/apps/message-service/internal/model"
+	"goim-social/pkg/kafka"
+)
+
+// publishMessageIndex 消息保存成功后发布索引事件，content为加密前的明文正文
+// 不参与搜索的消息类型不发布；发布失败只记录日志，不影响消息存储
+func publishMessageIndex(producer *kafka.Producer, message *model.Message, content string) {
+	if producer == nil || message.Unindexed || content == "" {
+		return
+	}
+
+	data, err := json.Marshal(&model.MessageIndexEvent{
+		ID:          message.MessageID,
+		FromUserID:  message.From,
+		ToUserID:    message.To,
+		GroupID:     message.GroupID,
+		Content:     content,
+		MessageType: strconv.Itoa(message.MessageType),
+		CreatedAt:   message.CreatedAt,
+	})
+	if err != nil {
+		log.Printf("序列化消息索引事件失败: MessageID=%d, Error=%v", message.MessageID, err)
+		return
+	}
+	if err := producer.SendMessage(model.TopicMessageIndex, []byte(message.ConversationID), data); err != nil {
+		log.Printf("发布消息索引事件失败: MessageID=%d, Error=%v", message.MessageID, err)
+	}
+}
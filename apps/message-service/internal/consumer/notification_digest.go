@@ -0,0 +1,366 @@
+package consumer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	goredis "github.com/go-redis/redis/v8"
+
+	"goim-social/api/rest"
+	"goim-social/apps/message-service/internal/model"
+)
+
+// ==================== 通知摘要 ====================
+// 点赞等低优先级通知按用户的摘要模式处理：立即推送，或暂存到Redis中按小时、按天汇总为一条摘要推送。
+// 用户第一条待汇总的通知到达时登记推送时间，扫描任务到期后推送摘要；提及和单聊消息不合并，到达时先推送已积累的摘要。
+// 摘要数据存放在Redis中，多实例下由认领脚本保证同一摘要只被一个实例推送。摘要推送后跟踪客户端确认，超时重发；
+// 推送时或重发时用户离线则放回Redis，与期间新到的通知合并后定期重试，直到第一条通知超过保留时间
+
+// digestSinceField 计数哈希中记录第一条通知时间的字段，事件类型不以下划线开头，不会冲突
+const digestSinceField = "_since"
+
+// addDigestScript 暂存一条通知：保留最近的若干条，按事件类型计数，首次暂存时登记推送时间
+var addDigestScript = goredis.NewScript(`
+redis.call('RPUSH', KEYS[1], ARGV[1])
+redis.call('LTRIM', KEYS[1], -tonumber(ARGV[2]), -1)
+redis.call('HINCRBY', KEYS[2], ARGV[3], 1)
+redis.call('HSETNX', KEYS[2], '_since', ARGV[4])
+redis.call('EXPIRE', KEYS[1], ARGV[7])
+redis.call('EXPIRE', KEYS[2], ARGV[7])
+redis.call('ZADD', KEYS[3], 'NX', ARGV[6], ARGV[5])
+return 1
+`)
+
+// claimDigestScript 认领用户待推送的摘要，返回计数和最近的通知；没有待推送摘要或已被其他实例认领时返回nil
+var claimDigestScript = goredis.NewScript(`
+if redis.call('ZREM', KEYS[3], ARGV[1]) == 0 then
+	return false
+end
+local counts = redis.call('HGETALL', KEYS[2])
+local items = redis.call('LRANGE', KEYS[1], 0, -1)
+redis.call('DEL', KEYS[1], KEYS[2])
+return {counts, items}
+`)
+
+// restoreDigestScript 放回未送达的摘要：计数累加，较早的通知排在期间新到的通知之前，保留较早的起始时间和推送时间，
+// 保留时间只延长不缩短。ARGV[6]为计数的事件类型数，其后依次为事件类型和计数，剩余参数为通知（从旧到新）
+var restoreDigestScript = goredis.NewScript(`
+local n = tonumber(ARGV[6])
+for i = 0, n - 1 do
+	redis.call('HINCRBY', KEYS[2], ARGV[7 + i * 2], ARGV[8 + i * 2])
+end
+local since = redis.call('HGET', KEYS[2], '_since')
+if not since or tonumber(since) > tonumber(ARGV[5]) then
+	redis.call('HSET', KEYS[2], '_since', ARGV[5])
+end
+for i = #ARGV, 7 + n * 2, -1 do
+	redis.call('LPUSH', KEYS[1], ARGV[i])
+end
+redis.call('LTRIM', KEYS[1], -tonumber(ARGV[3]), -1)
+for i = 1, 2 do
+	if redis.call('TTL', KEYS[i]) < tonumber(ARGV[4]) then
+		redis.call('EXPIRE', KEYS[i], ARGV[4])
+	end
+end
+local due = redis.call('ZSCORE', KEYS[3], ARGV[1])
+if not due or tonumber(due) > tonumber(ARGV[2]) then
+	redis.call('ZADD', KEYS[3], ARGV[2], ARGV[1])
+end
+return 1
+`)
+
+// digestLabels 摘要文本中各类通知的描述
+var digestLabels = map[string]string{
+	model.EventTypeContentLike: "个赞",
+}
+
+// digestKeys 摘要相关的键，顺序与脚本中的KEYS一致
+func digestKeys(userID int64) []string {
+	return []string{
+		fmt.Sprintf("%s:%d", model.CacheKeyDigestItemsPrefix, userID),
+		fmt.Sprintf("%s:%d", model.CacheKeyDigestCountsPrefix, userID),
+		model.CacheKeyDigestDue,
+	}
+}
+
+// digestEnabled 是否启用通知摘要
+func (p *PushConsumer) digestEnabled() bool {
+	return p.digestCfg.Enabled && p.redis != nil
+}
+
+// digestMode 获取用户的摘要模式，未设置时使用默认模式，读取失败时立即推送
+func (p *PushConsumer) digestMode(ctx context.Context, userID int64) string {
+	mode, err := p.redis.GetClient().HGet(ctx, model.CacheKeyDigestMode, strconv.FormatInt(userID, 10)).Result()
+	if err == goredis.Nil {
+		mode = p.digestCfg.DefaultMode
+	} else if err != nil {
+		log.Printf("获取摘要模式失败: %v, UserID=%d", err, userID)
+		return model.DigestModeImmediate
+	}
+	if !model.IsDigestMode(mode) {
+		return model.DigestModeImmediate
+	}
+	return mode
+}
+
+// handleLowPriority 处理低优先级通知，用户选择摘要时暂存，否则立即推送
+func (p *PushConsumer) handleLowPriority(eventType string, msg *rest.WSMessage) error {
+	if msg.To <= 0 {
+		return fmt.Errorf("通知缺少目标用户ID")
+	}
+	if !p.digestEnabled() {
+		return p.handleNewMessage(msg, false)
+	}
+
+	ctx := context.Background()
+	interval := model.DigestInterval(p.digestMode(ctx, msg.To))
+	if interval == 0 {
+		return p.handleNewMessage(msg, false)
+	}
+
+	item, err := json.Marshal(&model.DigestItem{
+		EventType: eventType,
+		MessageID: msg.MessageId,
+		From:      msg.From,
+		Content:   msg.Content,
+		Timestamp: msg.Timestamp,
+	})
+	if err != nil {
+		return fmt.Errorf("序列化摘要通知失败: %v", err)
+	}
+
+	now := time.Now()
+	if err := addDigestScript.Run(ctx, p.redis.GetClient(), digestKeys(msg.To),
+		item, p.digestMaxItems(), eventType, now.Unix(), msg.To,
+		now.Add(interval).UnixMilli(), int(model.DigestItemTTL.Seconds())).Err(); err != nil {
+		// 暂存失败时退回立即推送，避免丢失通知
+		log.Printf("暂存摘要通知失败，立即推送: %v, UserID=%d", err, msg.To)
+		return p.handleNewMessage(msg, false)
+	}
+	return nil
+}
+
+// digestMaxItems 每个摘要保留的最近通知条数
+func (p *PushConsumer) digestMaxItems() int {
+	if p.digestCfg.MaxItems > 0 {
+		return p.digestCfg.MaxItems
+	}
+	return model.DefaultDigestMaxItems
+}
+
+// flushDigest 推送用户已积累的摘要，高优先级通知到达前调用，没有待推送摘要时不做任何事
+func (p *PushConsumer) flushDigest(ctx context.Context, userID int64) {
+	if !p.digestEnabled() || userID <= 0 {
+		return
+	}
+
+	result, err := claimDigestScript.Run(ctx, p.redis.GetClient(), digestKeys(userID), userID).Slice()
+	if err != nil {
+		if err != goredis.Nil {
+			log.Printf("认领通知摘要失败: %v, UserID=%d", err, userID)
+		}
+		return
+	}
+	if len(result) != 2 {
+		return
+	}
+
+	digest := buildDigest(redisStrings(result[0]), redisStrings(result[1]), time.Now())
+	if len(digest.Counts) == 0 {
+		return
+	}
+
+	content, err := json.Marshal(digest)
+	if err != nil {
+		log.Printf("序列化通知摘要失败: %v, UserID=%d", err, userID)
+		return
+	}
+
+	now := time.Now()
+	msg := &rest.WSMessage{
+		MessageId:   now.UnixMilli(),
+		To:          userID,
+		Content:     string(content),
+		MessageType: model.MessageTypeNotificationDigest,
+		Timestamp:   now.Unix(),
+		Priority:    model.PushPriorityBulk,
+	}
+
+	// 摘要不落库，推送失败或用户离线时放回Redis，之后重新推送
+	pushed, err := p.pushToGatewayService(userID, msg)
+	if err != nil {
+		log.Printf("推送通知摘要失败: %v, UserID=%d", err, userID)
+	}
+	if !pushed {
+		p.restoreDigest(ctx, userID, digest)
+		return
+	}
+	p.trackPendingAck(ctx, userID, msg, 1)
+}
+
+// requeueDigest 重发时用户已离线，消息为通知摘要时放回Redis
+func (p *PushConsumer) requeueDigest(ctx context.Context, userID int64, msg *rest.WSMessage) {
+	if msg.MessageType != model.MessageTypeNotificationDigest || !p.digestEnabled() {
+		return
+	}
+
+	var digest model.NotificationDigest
+	if err := json.Unmarshal([]byte(msg.Content), &digest); err != nil {
+		log.Printf("解析通知摘要失败: %v, UserID=%d", err, userID)
+		return
+	}
+	p.restoreDigest(ctx, userID, &digest)
+}
+
+// restoreDigest 将未送达的摘要放回Redis，DigestOfflineRetry后重新推送；第一条通知已超过保留时间时丢弃
+func (p *PushConsumer) restoreDigest(ctx context.Context, userID int64, digest *model.NotificationDigest) {
+	now := time.Now()
+	since := digest.Since
+	if since <= 0 {
+		since = now.Unix()
+	}
+	ttl := time.Unix(since, 0).Add(model.DigestItemTTL).Sub(now)
+	if ttl < time.Second {
+		log.Printf("通知摘要超过保留时间，不再重试: UserID=%d, Since=%d", userID, since)
+		return
+	}
+
+	args := []interface{}{userID, now.Add(model.DigestOfflineRetry).UnixMilli(), p.digestMaxItems(),
+		int(ttl.Seconds()), since, len(digest.Counts)}
+	for eventType, count := range digest.Counts {
+		args = append(args, eventType, count)
+	}
+	for _, item := range digest.Items {
+		raw, err := json.Marshal(&item)
+		if err != nil {
+			continue
+		}
+		args = append(args, raw)
+	}
+
+	if err := restoreDigestScript.Run(ctx, p.redis.GetClient(), digestKeys(userID), args...).Err(); err != nil {
+		log.Printf("放回通知摘要失败: %v, UserID=%d", err, userID)
+	}
+}
+
+// RunDigestFlusher 定期推送到期的通知摘要，直到ctx取消
+func (p *PushConsumer) RunDigestFlusher(ctx context.Context) {
+	if !p.digestEnabled() {
+		return
+	}
+
+	ticker := time.NewTicker(model.DigestFlushScanInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			p.flushDueDigests(ctx)
+		}
+	}
+}
+
+// flushDueDigests 推送一批到期的通知摘要
+func (p *PushConsumer) flushDueDigests(ctx context.Context) {
+	members, err := p.redis.ZRangeByScore(ctx, model.CacheKeyDigestDue, &goredis.ZRangeBy{
+		Min:   "-inf",
+		Max:   strconv.FormatInt(time.Now().UnixMilli(), 10),
+		Count: model.DigestFlushBatchSize,
+	})
+	if err != nil {
+		log.Printf("获取到期通知摘要失败: %v", err)
+		return
+	}
+
+	for _, member := range members {
+		userID, err := strconv.ParseInt(member, 10, 64)
+		if err != nil {
+			continue
+		}
+		p.flushDigest(ctx, userID)
+	}
+}
+
+// buildDigest 根据计数哈希和最近的通知生成摘要
+func buildDigest(counts, items []string, now time.Time) *model.NotificationDigest {
+	digest := &model.NotificationDigest{
+		Counts: make(map[string]int64),
+		Items:  make([]model.DigestItem, 0, len(items)),
+		Until:  now.Unix(),
+	}
+
+	for i := 0; i+1 < len(counts); i += 2 {
+		value, err := strconv.ParseInt(counts[i+1], 10, 64)
+		if err != nil {
+			continue
+		}
+		if counts[i] == digestSinceField {
+			digest.Since = value
+		} else if value > 0 {
+			digest.Counts[counts[i]] = value
+		}
+	}
+
+	for _, raw := range items {
+		var item model.DigestItem
+		if err := json.Unmarshal([]byte(raw), &item); err != nil {
+			continue
+		}
+		digest.Items = append(digest.Items, item)
+	}
+
+	digest.Summary = digestSummary(digest.Counts, time.Unix(digest.Since, 0), now)
+	return digest
+}
+
+// digestSummary 生成摘要文本，如“过去1小时收到12个赞”
+func digestSummary(counts map[string]int64, since, now time.Time) string {
+	eventTypes := make([]string, 0, len(counts))
+	for eventType := range counts {
+		eventTypes = append(eventTypes, eventType)
+	}
+	sort.Strings(eventTypes)
+
+	parts := make([]string, 0, len(eventTypes))
+	for _, eventType := range eventTypes {
+		label, ok := digestLabels[eventType]
+		if !ok {
+			label = "条通知"
+		}
+		parts = append(parts, fmt.Sprintf("%d%s", counts[eventType], label))
+	}
+
+	elapsed := now.Sub(since)
+	minutes := int(elapsed.Round(time.Minute) / time.Minute)
+	hours := int(elapsed.Round(time.Hour) / time.Hour)
+	var period string
+	switch {
+	case minutes < 60:
+		period = fmt.Sprintf("%d分钟", max(1, minutes))
+	case hours < 24:
+		period = fmt.Sprintf("%d小时", hours)
+	default:
+		period = fmt.Sprintf("%d天", (hours+12)/24)
+	}
+	return fmt.Sprintf("过去%s收到%s", period, strings.Join(parts, "、"))
+}
+
+// redisStrings 将脚本返回的数组转换为字符串切片
+func redisStrings(value interface{}) []string {
+	values, _ := value.([]interface{})
+	result := make([]string, 0, len(values))
+	for _, v := range values {
+		if s, ok := v.(string); ok {
+			result = append(result, s)
+		}
+	}
+	return result
+}
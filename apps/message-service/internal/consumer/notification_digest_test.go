@@ -0,0 +1,67 @@
+package consumer
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"goim-social/apps/message-service/internal/model"
+)
+
+// TestBuildDigest 按事件类型汇总计数，跳过无效的计数和通知，起始时间取自计数哈希
+func TestBuildDigest(t *testing.T) {
+	now := time.Unix(1700007200, 0)
+	item := model.DigestItem{EventType: model.EventTypeContentLike, MessageID: 7, From: 3, Content: "{}", Timestamp: 1700000100}
+	raw, err := json.Marshal(&item)
+	if err != nil {
+		t.Fatalf("序列化通知失败: %v", err)
+	}
+
+	counts := []string{
+		model.EventTypeContentLike, "12",
+		digestSinceField, "1700000000",
+		"broken", "abc",
+		"zero", "0",
+	}
+	digest := buildDigest(counts, []string{string(raw), "not json"}, now)
+
+	if want := map[string]int64{model.EventTypeContentLike: 12}; !reflect.DeepEqual(digest.Counts, want) {
+		t.Errorf("Counts = %v, want %v", digest.Counts, want)
+	}
+	if want := []model.DigestItem{item}; !reflect.DeepEqual(digest.Items, want) {
+		t.Errorf("Items = %+v, want %+v", digest.Items, want)
+	}
+	if digest.Since != 1700000000 || digest.Until != now.Unix() {
+		t.Errorf("Since, Until = %d, %d, want 1700000000, %d", digest.Since, digest.Until, now.Unix())
+	}
+	if want := "过去2小时收到12个赞"; digest.Summary != want {
+		t.Errorf("Summary = %q, want %q", digest.Summary, want)
+	}
+}
+
+// TestDigestSummary 时长按分钟、小时、天取整，不足1分钟按1分钟，未知事件类型按条计数
+func TestDigestSummary(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	likes := map[string]int64{model.EventTypeContentLike: 3}
+	cases := []struct {
+		name    string
+		counts  map[string]int64
+		elapsed time.Duration
+		want    string
+	}{
+		{name: "under a minute", counts: likes, elapsed: 10 * time.Second, want: "过去1分钟收到3个赞"},
+		{name: "minutes", counts: likes, elapsed: 42 * time.Minute, want: "过去42分钟收到3个赞"},
+		{name: "hours", counts: likes, elapsed: 90*time.Minute + time.Second, want: "过去2小时收到3个赞"},
+		{name: "days", counts: likes, elapsed: 36 * time.Hour, want: "过去2天收到3个赞"},
+		{name: "multiple types", counts: map[string]int64{model.EventTypeContentLike: 2, "follow": 1}, elapsed: time.Hour,
+			want: "过去1小时收到2个赞、1条通知"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := digestSummary(tc.counts, now.Add(-tc.elapsed), now); got != tc.want {
+				t.Errorf("digestSummary() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
@@ -2,32 +2,40 @@ package consumer
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"time"
 
 	"github.com/IBM/sarama"
-	"go.mongodb.org/mongo-driver/mongo"
 	"google.golang.org/protobuf/proto"
 
 	"goim-social/api/rest"
+	"goim-social/apps/message-service/internal/dao"
 	"goim-social/apps/message-service/internal/model"
-	"goim-social/pkg/database"
+	"goim-social/pkg/conversation"
+	"goim-social/pkg/encryption"
 	"goim-social/pkg/kafka"
 )
 
 // PersistenceConsumer 专门的持久化消费者
-// 职责：消费message_persistence_log Topic，执行消息归档
-// 幂等性保护：依赖MongoDB的MessageID唯一索引
+// 职责：消费message_persistence_log及其重试Topic，执行消息归档
+// 幂等性保护：依赖消息存储按MessageID去重
 type PersistenceConsumer struct {
-	db       *database.MongoDB
-	consumer *kafka.Consumer
+	store     dao.MessageStore
+	encryptor encryption.Encryptor
+	policy    *StoragePolicy
+	indexer   *kafka.Producer // 发布消息索引事件，为nil时不写入搜索索引
+	consumer  *kafka.Consumer
 }
 
 // NewPersistenceConsumer 创建持久化消费者
-func NewPersistenceConsumer(db *database.MongoDB) *PersistenceConsumer {
+func NewPersistenceConsumer(store dao.MessageStore, encryptor encryption.Encryptor, policy *StoragePolicy, indexer *kafka.Producer) *PersistenceConsumer {
 	return &PersistenceConsumer{
-		db: db,
+		store:     store,
+		encryptor: encryptor,
+		policy:    policy,
+		indexer:   indexer,
 	}
 }
 
@@ -37,8 +45,8 @@ func (p *PersistenceConsumer) Start(ctx context.Context, brokers []string) error
 
 	cfg := kafka.KafkaConfig{
 		Brokers: brokers,
-		GroupID: "persistence-consumer-group",        // 独立的Consumer Group
-		Topics:  []string{"message_persistence_log"}, // 专门的持久化Topic
+		GroupID: "persistence-consumer-group",                                     // 独立的Consumer Group
+		Topics:  []string{"message_persistence_log", "message_persistence_retry"}, // 持久化Topic及Logic服务的重试Topic
 	}
 
 	consumer, err := kafka.InitConsumer(cfg, p)
@@ -48,7 +56,7 @@ func (p *PersistenceConsumer) Start(ctx context.Context, brokers []string) error
 	}
 
 	p.consumer = consumer
-	log.Printf("持久化消费者启动成功，监听topic: message_persistence_log, GroupID: persistence-consumer-group")
+	log.Printf("持久化消费者启动成功，监听topic: message_persistence_log, message_persistence_retry, GroupID: persistence-consumer-group")
 
 	return p.consumer.StartConsuming(ctx)
 }
@@ -64,7 +72,7 @@ func (p *PersistenceConsumer) HandleMessage(msg *sarama.ConsumerMessage) error {
 		}
 	}()
 
-	// 幂等性由MongoDB的MessageID唯一索引保证
+	// 幂等性由消息存储按MessageID去重保证
 	var event rest.MessageEvent
 	if err := proto.Unmarshal(msg.Value, &event); err != nil {
 		log.Printf("解析归档命令失败: %v", err)
@@ -90,11 +98,18 @@ func (p *PersistenceConsumer) HandleMessage(msg *sarama.ConsumerMessage) error {
 }
 
 // handleArchiveMessage 处理消息归档（经过Logic Service处理的标准格式）
-// 使用乐观插入策略：直接插入，依赖MongoDB唯一索引处理重复
+// 使用乐观插入策略：直接插入，由消息存储识别重复
 func (p *PersistenceConsumer) handleArchiveMessage(msg *rest.WSMessage) error {
 	log.Printf("执行消息归档: From=%d, To=%d, Content=%s, MessageID=%d",
 		msg.From, msg.To, msg.Content, msg.MessageId)
 
+	// 只转发的消息类型不归档
+	policy := p.policy.Resolve(msg.MessageType)
+	if policy == model.StoragePolicyRelay {
+		log.Printf("消息类型只转发，跳过归档: MessageType=%d, MessageID=%d", msg.MessageType, msg.MessageId)
+		return nil
+	}
+
 	// 检查MessageID是否存在
 	if msg.MessageId == 0 {
 		log.Printf("归档消息MessageID为0，跳过归档: From=%d, To=%d", msg.From, msg.To)
@@ -104,26 +119,39 @@ func (p *PersistenceConsumer) handleArchiveMessage(msg *rest.WSMessage) error {
 	// 转换为Message模型并设置状态
 	message := &model.Message{
 		// 不设置ID，让MongoDB自动生成_id
-		MessageID:   msg.MessageId, // 直接使用Kafka消息中的MessageID
-		From:        msg.From,
-		To:          msg.To,
-		GroupID:     msg.GroupId,
-		Content:     msg.Content,
-		MessageType: int(msg.MessageType),
-		Timestamp:   msg.Timestamp,
-		Status:      model.MessageStatusSent,
-		CreatedAt:   time.Unix(msg.Timestamp, 0),
-		UpdatedAt:   time.Now(),
+		MessageID:      msg.MessageId, // 直接使用Kafka消息中的MessageID
+		From:           msg.From,
+		To:             msg.To,
+		GroupID:        msg.GroupId,
+		ConversationID: conversation.ID(msg.From, msg.To, msg.GroupId),
+		Content:        msg.Content,
+		MessageType:    int(msg.MessageType),
+		Timestamp:      msg.Timestamp,
+		SenderSeq:      msg.SenderSeq,
+		Status:         model.MessageStatusSent,
+		Unindexed:      policy == model.StoragePolicyUnindexed,
+		CreatedAt:      time.Unix(msg.Timestamp, 0),
+		UpdatedAt:      time.Now(),
+	}
+	if err := dao.ApplyMessageTTL(context.Background(), p.store, message, msg.Ttl); err != nil {
+		log.Printf("读取会话消息有效期失败，按永久保存: ConversationID=%s, Error=%v", message.ConversationID, err)
 	}
 
-	// 乐观插入策略：直接尝试插入，让MongoDB唯一索引处理重复
-	collection := p.db.GetCollection("messages")
-	_, err := collection.InsertOne(context.Background(), message)
+	// 加密消息内容，元数据保持明文
+	content, keyID, err := p.encryptor.Encrypt(msg.Content)
+	if err != nil {
+		return fmt.Errorf("加密消息内容失败: %v", err)
+	}
+	message.Content = content
+	message.KeyID = keyID
+
+	// 乐观插入策略：直接尝试插入，由存储按MessageID识别重复
+	err = p.store.SaveMessage(context.Background(), message)
 
 	// 检查错误类型
 	if err != nil {
-		if mongo.IsDuplicateKeyError(err) {
-			log.Printf("消息已归档(唯一索引幂等性保护): MessageID=%d", msg.MessageId)
+		if errors.Is(err, dao.ErrDuplicateMessage) {
+			log.Printf("消息已归档(幂等性保护): MessageID=%d", msg.MessageId)
 			return nil // 幂等处理，返回成功
 		}
 		// 其他类型的数据库错误
@@ -131,6 +159,9 @@ func (p *PersistenceConsumer) handleArchiveMessage(msg *rest.WSMessage) error {
 		return err
 	}
 
+	// 库中正文可能已加密，使用明文发布索引事件；重复消息由首次保存时发布
+	publishMessageIndex(p.indexer, message, msg.Content)
+
 	log.Printf("消息归档成功: From=%d, To=%d, Status=已发送, MessageID=%d",
 		msg.From, msg.To, msg.MessageId)
 
@@ -140,8 +171,8 @@ func (p *PersistenceConsumer) handleArchiveMessage(msg *rest.WSMessage) error {
 // Stop 停止消费者
 func (p *PersistenceConsumer) Stop() error {
 	if p.consumer != nil {
-		// TODO: 实现优雅停止
 		log.Printf("持久化消费者停止")
+		return p.consumer.Close()
 	}
 	return nil
 }
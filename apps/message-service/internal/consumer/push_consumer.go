@@ -5,26 +5,47 @@ import (
 	"encoding/base64"
 	"fmt"
 	"log"
+	"sort"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/IBM/sarama"
 	"google.golang.org/protobuf/proto"
 
 	"goim-social/api/rest"
+	"goim-social/apps/message-service/internal/model"
+	"goim-social/pkg/config"
 	"goim-social/pkg/kafka"
+	"goim-social/pkg/presence"
 	"goim-social/pkg/redis"
 )
 
 // PushConsumer 推送消费者
 type PushConsumer struct {
-	consumer *kafka.Consumer
-	redis    *redis.RedisClient
+	consumer  *kafka.Consumer
+	redis     *redis.RedisClient
+	source    string       // 推送来源标识，写入转发消息供网关去重
+	seq       atomic.Int64 // 推送序号，网关据此丢弃重复和乱序的消息
+	ackCfg    config.AckResendConfig
+	digestCfg config.NotificationDigestConfig // 低优先级通知摘要配置
+	policy    *StoragePolicy                  // 只转发的消息类型不存储，客户端确认无法标记已读，不跟踪确认
+
+	statsMu        sync.Mutex
+	gatewayStats   map[string]*model.GatewayPushStat // 按Connect实例累计的推送统计
+	ackStat        model.AckResendStat               // 推送确认与重发统计
+	lastConsumedAt atomic.Int64                      // 最近一次消费时间（UnixNano）
 }
 
 // NewPushConsumer 创建推送消费者
-func NewPushConsumer(redis *redis.RedisClient) *PushConsumer {
+func NewPushConsumer(redis *redis.RedisClient, ackCfg config.AckResendConfig, digestCfg config.NotificationDigestConfig, policy *StoragePolicy) *PushConsumer {
 	return &PushConsumer{
-		redis: redis,
+		redis:        redis,
+		source:       fmt.Sprintf("push-consumer-%d", time.Now().UnixNano()),
+		ackCfg:       ackCfg,
+		digestCfg:    digestCfg,
+		policy:       policy,
+		gatewayStats: make(map[string]*model.GatewayPushStat),
 	}
 }
 
@@ -51,6 +72,7 @@ func (p *PushConsumer) Start(ctx context.Context, brokers []string) error {
 func (p *PushConsumer) HandleMessage(msg *sarama.ConsumerMessage) error {
 	log.Printf("推送消费者收到消息: topic=%s, partition=%d, offset=%d",
 		msg.Topic, msg.Partition, msg.Offset)
+	p.lastConsumedAt.Store(time.Now().UnixNano())
 
 	defer func() {
 		if r := recover(); r != nil {
@@ -68,21 +90,108 @@ func (p *PushConsumer) HandleMessage(msg *sarama.ConsumerMessage) error {
 	// 根据事件类型处理
 	switch event.Type {
 	case "new_message":
-		if err := p.handleNewMessage(event.Message); err != nil {
+		// 单聊消息为高优先级，先推送接收方已积累的通知摘要
+		if event.Message.GetGroupId() == 0 {
+			p.flushDigest(context.Background(), event.Message.GetTo())
+		}
+		if err := p.handleNewMessage(event.Message, p.policy.Persisted(event.Message.GetMessageType())); err != nil {
 			log.Printf("处理新消息推送失败: %v", err)
 			return nil // 返回nil避免重试
 		}
 
 		log.Printf("消息推送完成: MessageID=%d", event.Message.MessageId)
 		return nil
+	case model.EventTypeExpired:
+		// 过期通知已按参与者拆分，与新消息走同一推送路径，不需要客户端确认
+		if err := p.handleNewMessage(event.Message, false); err != nil {
+			log.Printf("处理消息过期通知推送失败: %v", err)
+			return nil // 返回nil避免重试
+		}
+
+		log.Printf("消息过期通知推送完成: MessageID=%d, UserID=%d", event.Message.MessageId, event.Message.To)
+		return nil
+	case model.EventTypeGroupJoinRequest:
+		// 加群申请通知已按接收人拆分，不需要客户端确认，离线用户上线后主动查询申请列表
+		if err := p.handleNewMessage(event.Message, false); err != nil {
+			log.Printf("处理加群申请通知推送失败: %v", err)
+			return nil // 返回nil避免重试
+		}
+
+		log.Printf("加群申请通知推送完成: RequestID=%d, UserID=%d", event.Message.MessageId, event.Message.To)
+		return nil
+	case model.EventTypeMention:
+		// @提及通知已按被提及用户拆分，不需要客户端确认，MessageID为提及记录ID
+		// 提及为高优先级，先推送已积累的通知摘要
+		p.flushDigest(context.Background(), event.Message.To)
+		if err := p.handleNewMessage(event.Message, false); err != nil {
+			log.Printf("处理提及通知推送失败: %v", err)
+			return nil // 返回nil避免重试
+		}
+
+		log.Printf("提及通知推送完成: MentionID=%d, UserID=%d", event.Message.MessageId, event.Message.To)
+		return nil
+	case model.EventTypeContentBan:
+		// 发布限制通知只发给被限制的用户，不需要客户端确认，MessageID为限制记录ID
+		if err := p.handleNewMessage(event.Message, false); err != nil {
+			log.Printf("处理发布限制通知推送失败: %v", err)
+			return nil // 返回nil避免重试
+		}
+
+		log.Printf("发布限制通知推送完成: BanID=%d, UserID=%d", event.Message.MessageId, event.Message.To)
+		return nil
+	case model.EventTypeGroupInfoUpdated:
+		// 群资料变更通知已按在线成员拆分，不需要客户端确认，MessageID为群资料版本号
+		if err := p.handleNewMessage(event.Message, false); err != nil {
+			log.Printf("处理群资料变更通知推送失败: %v", err)
+			return nil // 返回nil避免重试
+		}
+
+		log.Printf("群资料变更通知推送完成: GroupID=%d, Version=%d, UserID=%d", event.Message.GroupId, event.Message.MessageId, event.Message.To)
+		return nil
+	case model.EventTypeDeliveryReport:
+		// 投递和已读汇总只发给群消息发送者，按间隔推送最新汇总，不需要客户端确认，MessageID为被汇总的消息ID
+		if err := p.handleNewMessage(event.Message, false); err != nil {
+			log.Printf("处理投递和已读汇总推送失败: %v", err)
+			return nil // 返回nil避免重试
+		}
+
+		log.Printf("投递和已读汇总推送完成: MessageID=%d, SenderID=%d", event.Message.MessageId, event.Message.To)
+		return nil
+	case model.EventTypePollUpdated:
+		// 投票结果更新已按群成员拆分，只携带最新票数，不需要客户端确认，MessageID为投票ID
+		if err := p.handleNewMessage(event.Message, false); err != nil {
+			log.Printf("处理投票结果更新推送失败: %v", err)
+			return nil // 返回nil避免重试
+		}
+
+		log.Printf("投票结果更新推送完成: PollID=%d, UserID=%d", event.Message.MessageId, event.Message.To)
+		return nil
+	case model.EventTypeGroupPinsUpdated:
+		// 群置顶变更已按群成员拆分，content携带变更后的全部置顶，不需要客户端确认
+		if err := p.handleNewMessage(event.Message, false); err != nil {
+			log.Printf("处理群置顶变更推送失败: %v", err)
+			return nil // 返回nil避免重试
+		}
+
+		log.Printf("群置顶变更推送完成: GroupID=%d, UserID=%d", event.Message.GroupId, event.Message.To)
+		return nil
+	case model.EventTypeContentLike:
+		// 点赞通知为低优先级，按用户的摘要模式立即推送或合并为摘要，MessageID为互动记录ID
+		if err := p.handleLowPriority(event.Type, event.Message); err != nil {
+			log.Printf("处理点赞通知失败: %v", err)
+			return nil // 返回nil避免重试
+		}
+
+		log.Printf("点赞通知处理完成: InteractionID=%d, UserID=%d", event.Message.MessageId, event.Message.To)
+		return nil
 	default:
 		log.Printf("未知的消息事件类型: %s", event.Type)
 		return nil
 	}
 }
 
-// handleNewMessage 处理新消息推送
-func (p *PushConsumer) handleNewMessage(msg *rest.WSMessage) error {
+// handleNewMessage 处理新消息推送，needAck为true时跟踪客户端确认，超时未确认则重发
+func (p *PushConsumer) handleNewMessage(msg *rest.WSMessage, needAck bool) error {
 	// 检查MessageID是否存在
 	if msg.MessageId == 0 {
 		log.Printf("MessageID为0，跳过推送: From=%d, To=%d, Content=%s", msg.From, msg.To, msg.Content)
@@ -92,9 +201,7 @@ func (p *PushConsumer) handleNewMessage(msg *rest.WSMessage) error {
 	if msg.To > 0 {
 		// 单聊消息：推送给目标用户
 		log.Printf("推送单聊消息: From=%d, To=%d, Content=%s, MessageID=%d", msg.From, msg.To, msg.Content, msg.MessageId)
-		if err := p.pushToGatewayService(msg.To, msg); err != nil {
-			log.Printf("推送消息到Gateway服务失败: %v", err)
-		}
+		p.pushAndTrack(msg.To, msg, needAck)
 	} else if msg.GroupId > 0 {
 		// 群聊消息：推送给所有群成员（已在Logic服务中处理扇出）
 		log.Printf("推送群聊消息: From=%d, GroupID=%d, Content=%s, MessageID=%d", msg.From, msg.GroupId, msg.Content, msg.MessageId)
@@ -102,9 +209,7 @@ func (p *PushConsumer) handleNewMessage(msg *rest.WSMessage) error {
 		// 这里接收到的应该是针对特定用户的消息，直接推送即可
 		// 如果To字段有值，说明是扇出后的单个用户消息
 		if msg.To > 0 {
-			if err := p.pushToGatewayService(msg.To, msg); err != nil {
-				log.Printf("推送群聊消息到Gateway服务失败: %v", err)
-			}
+			p.pushAndTrack(msg.To, msg, needAck)
 		} else {
 			log.Printf("群聊消息缺少目标用户ID，跳过推送: GroupID=%d, MessageID=%d", msg.GroupId, msg.MessageId)
 		}
@@ -115,31 +220,30 @@ func (p *PushConsumer) handleNewMessage(msg *rest.WSMessage) error {
 	return nil
 }
 
-// pushToGatewayService 通过Redis发布消息到Gateway服务
-func (p *PushConsumer) pushToGatewayService(targetUserID int64, message *rest.WSMessage) error {
+// pushToGatewayService 通过Redis发布消息到Gateway服务，返回是否已发布到用户所在的Connect实例
+func (p *PushConsumer) pushToGatewayService(targetUserID int64, message *rest.WSMessage) (bool, error) {
 	ctx := context.Background()
 
 	// 查找用户所在的Connect实例
-	pattern := fmt.Sprintf("conn:%d:*", targetUserID)
-	keys, err := p.redis.Keys(ctx, pattern)
+	keys, err := presence.ConnectionKeys(ctx, p.redis, targetUserID)
 	if err != nil {
-		return fmt.Errorf("查找用户连接失败: %v", err)
+		return false, err
 	}
 
 	if len(keys) == 0 {
 		log.Printf("用户 %d 不在线，跳过推送", targetUserID)
-		return nil
+		return false, nil
 	}
 
 	// 获取用户连接信息
 	connInfo, err := p.redis.HGetAll(ctx, keys[0])
 	if err != nil {
-		return fmt.Errorf("获取连接信息失败: %v", err)
+		return false, fmt.Errorf("获取连接信息失败: %v", err)
 	}
 
 	serverID, exists := connInfo["serverID"]
 	if !exists {
-		return fmt.Errorf("连接信息中缺少serverID")
+		return false, fmt.Errorf("连接信息中缺少serverID")
 	}
 
 	// 构造protobuf推送消息
@@ -148,33 +252,88 @@ func (p *PushConsumer) pushToGatewayService(targetUserID int64, message *rest.WS
 		Message:    message,
 		TargetUser: targetUserID,
 		Timestamp:  time.Now().Unix(),
+		Source:     p.source,
+		Seq:        p.seq.Add(1),
 	}
 
 	// 序列化为protobuf二进制数据
 	msgBytes, err := proto.Marshal(pushMsg)
 	if err != nil {
-		return fmt.Errorf("序列化protobuf推送消息失败: %v", err)
+		return false, fmt.Errorf("序列化protobuf推送消息失败: %v", err)
 	}
 
 	// 使用base64编码便于Redis传输
 	msgBase64 := base64.StdEncoding.EncodeToString(msgBytes)
 
 	// 发布到Gateway服务的频道
-	channel := fmt.Sprintf("connect_forward:%s", serverID)
-	if err := p.redis.Publish(ctx, channel, msgBase64); err != nil {
-		return fmt.Errorf("发布推送消息失败: %v", err)
+	channel := fmt.Sprintf("%s:%s", model.GatewayForwardChannelPrefix, serverID)
+	receivers, err := p.redis.PublishCount(ctx, channel, msgBase64)
+	p.recordPush(serverID, receivers, err)
+	if err != nil {
+		return false, fmt.Errorf("发布推送消息失败: %v", err)
+	}
+	if receivers == 0 {
+		log.Printf("Connect实例未订阅推送频道，消息未被接收: ServerID=%s, UserID=%d, MessageID=%d",
+			serverID, targetUserID, message.MessageId)
 	}
 
 	log.Printf("已发布推送消息到Connect服务: ServerID=%s, UserID=%d, MessageID=%d, Key=%s",
 		serverID, targetUserID, message.MessageId, channel)
-	return nil
+	return true, nil
+}
+
+// recordPush 记录一次推送结果
+func (p *PushConsumer) recordPush(serverID string, receivers int64, err error) {
+	p.statsMu.Lock()
+	defer p.statsMu.Unlock()
+
+	stat, ok := p.gatewayStats[serverID]
+	if !ok {
+		stat = &model.GatewayPushStat{ServerID: serverID}
+		p.gatewayStats[serverID] = stat
+	}
+	stat.LastPushAt = time.Now()
+	switch {
+	case err != nil:
+		stat.Failed++
+		stat.LastError = err.Error()
+	case receivers == 0:
+		stat.Pushed++
+		stat.Unreceived++
+		stat.LastError = "频道无订阅者"
+	default:
+		stat.Pushed++
+	}
+}
+
+// GatewayStats 返回各Connect实例的推送统计快照，按实例ID排序
+func (p *PushConsumer) GatewayStats() []*model.GatewayPushStat {
+	p.statsMu.Lock()
+	defer p.statsMu.Unlock()
+
+	stats := make([]*model.GatewayPushStat, 0, len(p.gatewayStats))
+	for _, stat := range p.gatewayStats {
+		snapshot := *stat
+		stats = append(stats, &snapshot)
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].ServerID < stats[j].ServerID })
+	return stats
+}
+
+// LastConsumedAt 最近一次消费时间，尚未消费时返回零值
+func (p *PushConsumer) LastConsumedAt() time.Time {
+	nano := p.lastConsumedAt.Load()
+	if nano == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, nano)
 }
 
 // Stop 停止消费者
 func (p *PushConsumer) Stop() error {
 	if p.consumer != nil {
-		// TODO: 实现优雅停止
 		log.Printf("推送消费者停止")
+		return p.consumer.Close()
 	}
 	return nil
 }
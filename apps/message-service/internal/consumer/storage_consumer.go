@@ -2,6 +2,7 @@ package consumer
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"time"
@@ -9,24 +10,31 @@ import (
 	"github.com/IBM/sarama"
 	"google.golang.org/protobuf/proto"
 
-	"go.mongodb.org/mongo-driver/mongo"
 	"goim-social/api/rest"
+	"goim-social/apps/message-service/internal/dao"
 	"goim-social/apps/message-service/internal/model"
-	"goim-social/pkg/database"
+	"goim-social/pkg/conversation"
+	"goim-social/pkg/encryption"
 	"goim-social/pkg/kafka"
 )
 
 // StorageConsumer 存储消费者
-// 幂等性保护：依赖MongoDB的MessageID唯一索引
+// 幂等性保护：依赖消息存储按MessageID去重
 type StorageConsumer struct {
-	db       *database.MongoDB
-	consumer *kafka.Consumer
+	store     dao.MessageStore
+	encryptor encryption.Encryptor
+	policy    *StoragePolicy
+	indexer   *kafka.Producer // 发布消息索引事件，为nil时不写入搜索索引
+	consumer  *kafka.Consumer
 }
 
 // NewStorageConsumer 创建存储消费者
-func NewStorageConsumer(db *database.MongoDB) *StorageConsumer {
+func NewStorageConsumer(store dao.MessageStore, encryptor encryption.Encryptor, policy *StoragePolicy, indexer *kafka.Producer) *StorageConsumer {
 	return &StorageConsumer{
-		db: db,
+		store:     store,
+		encryptor: encryptor,
+		policy:    policy,
+		indexer:   indexer,
 	}
 }
 
@@ -87,6 +95,13 @@ func (s *StorageConsumer) HandleMessage(msg *sarama.ConsumerMessage) error {
 func (s *StorageConsumer) handleNewMessage(msg *rest.WSMessage) error {
 	log.Printf("存储消息: From=%d, To=%d, Content=%s, MessageID=%d", msg.From, msg.To, msg.Content, msg.MessageId)
 
+	// 只转发的消息类型不存储
+	policy := s.policy.Resolve(msg.MessageType)
+	if policy == model.StoragePolicyRelay {
+		log.Printf("消息类型只转发，跳过存储: MessageType=%d, MessageID=%d", msg.MessageType, msg.MessageId)
+		return nil
+	}
+
 	// 检查MessageID是否存在
 	if msg.MessageId == 0 {
 		log.Printf("MessageID为0，跳过存储: From=%d, To=%d", msg.From, msg.To)
@@ -96,27 +111,40 @@ func (s *StorageConsumer) handleNewMessage(msg *rest.WSMessage) error {
 	// 转换为Message模型并设置状态
 	message := &model.Message{
 		// 不设置ID，让MongoDB自动生成_id
-		MessageID:   msg.MessageId, // 直接使用Kafka消息中的MessageID
-		From:        msg.From,
-		To:          msg.To,
-		GroupID:     msg.GroupId,
-		Content:     msg.Content,
-		MessageType: int(msg.MessageType),
-		Timestamp:   msg.Timestamp,
-		Status:      model.MessageStatusSent,
-		CreatedAt:   time.Unix(msg.Timestamp, 0),
-		UpdatedAt:   time.Now(),
+		MessageID:      msg.MessageId, // 直接使用Kafka消息中的MessageID
+		From:           msg.From,
+		To:             msg.To,
+		GroupID:        msg.GroupId,
+		ConversationID: conversation.ID(msg.From, msg.To, msg.GroupId),
+		Content:        msg.Content,
+		MessageType:    int(msg.MessageType),
+		Timestamp:      msg.Timestamp,
+		SenderSeq:      msg.SenderSeq,
+		Status:         model.MessageStatusSent,
+		Unindexed:      policy == model.StoragePolicyUnindexed,
+		CreatedAt:      time.Unix(msg.Timestamp, 0),
+		UpdatedAt:      time.Now(),
+	}
+	if err := dao.ApplyMessageTTL(context.Background(), s.store, message, msg.Ttl); err != nil {
+		log.Printf("读取会话消息有效期失败，按永久保存: ConversationID=%s, Error=%v", message.ConversationID, err)
 	}
 
-	// 乐观插入策略：直接尝试插入，让MongoDB唯一索引处理重复
-	collection := s.db.GetCollection("messages")
-	_, err := collection.InsertOne(context.Background(), message)
+	// 加密消息内容，元数据保持明文
+	content, keyID, err := s.encryptor.Encrypt(msg.Content)
+	if err != nil {
+		return fmt.Errorf("加密消息内容失败: %v", err)
+	}
+	message.Content = content
+	message.KeyID = keyID
+
+	// 乐观插入策略：直接尝试插入，由存储按MessageID识别重复
+	err = s.store.SaveMessage(context.Background(), message)
 
 	// 检查错误类型
 	if err != nil {
 		// 如果是重复键错误，说明是幂等触发，这不是一个真正的错误
-		if mongo.IsDuplicateKeyError(err) {
-			log.Printf("消息已存在(唯一索引幂等性保护): MessageID=%d", msg.MessageId)
+		if errors.Is(err, dao.ErrDuplicateMessage) {
+			log.Printf("消息已存在(幂等性保护): MessageID=%d", msg.MessageId)
 			return nil // 幂等处理，返回成功
 		}
 		// 其他类型的数据库错误
@@ -124,6 +152,9 @@ func (s *StorageConsumer) handleNewMessage(msg *rest.WSMessage) error {
 		return err
 	}
 
+	// 库中正文可能已加密，使用明文发布索引事件；重复消息由首次保存时发布
+	publishMessageIndex(s.indexer, message, msg.Content)
+
 	log.Printf("消息存储成功: From=%d, To=%d, Status=未读, MessageID=%d",
 		msg.From, msg.To, msg.MessageId)
 
@@ -133,8 +164,8 @@ func (s *StorageConsumer) handleNewMessage(msg *rest.WSMessage) error {
 // Stop 停止消费者
 func (s *StorageConsumer) Stop() error {
 	if s.consumer != nil {
-		// TODO: 实现优雅停止
 		log.Printf("存储消费者停止")
+		return s.consumer.Close()
 	}
 	return nil
 }
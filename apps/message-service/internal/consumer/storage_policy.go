@@ -0,0 +1,48 @@
+package consumer
+
+import (
+	"goim-social/apps/message-service/internal/model"
+	"goim-social/pkg/config"
+)
+
+// StoragePolicy 按消息类型决定消息是否写入存储以及是否参与会话搜索
+// 只转发的类型（在线状态、已读回执等控制消息）照常推送，但存储和持久化消费者不写入，推送时也不跟踪确认；
+// 未配置的类型存储并参与搜索
+type StoragePolicy struct {
+	relay     map[int32]bool
+	unindexed map[int32]bool
+}
+
+// NewStoragePolicy 根据配置创建存储策略
+func NewStoragePolicy(cfg config.MessageStorageConfig) *StoragePolicy {
+	p := &StoragePolicy{
+		relay:     make(map[int32]bool, len(cfg.RelayTypes)),
+		unindexed: make(map[int32]bool, len(cfg.UnindexedTypes)),
+	}
+	for _, t := range cfg.RelayTypes {
+		p.relay[int32(t)] = true
+	}
+	for _, t := range cfg.UnindexedTypes {
+		p.unindexed[int32(t)] = true
+	}
+	return p
+}
+
+// Resolve 返回消息类型的存储策略，同时配置为只转发和不参与搜索时按只转发处理
+func (p *StoragePolicy) Resolve(messageType int32) string {
+	switch {
+	case p == nil:
+		return model.StoragePolicyPersist
+	case p.relay[messageType]:
+		return model.StoragePolicyRelay
+	case p.unindexed[messageType]:
+		return model.StoragePolicyUnindexed
+	default:
+		return model.StoragePolicyPersist
+	}
+}
+
+// Persisted 判断消息类型是否写入存储
+func (p *StoragePolicy) Persisted(messageType int32) bool {
+	return p.Resolve(messageType) != model.StoragePolicyRelay
+}
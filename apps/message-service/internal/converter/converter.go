@@ -6,6 +6,7 @@ import (
 
 	"goim-social/api/rest"
 	"goim-social/apps/message-service/internal/model"
+	"goim-social/pkg/httpx"
 )
 
 // Converter 转换器，提供Model到Protobuf的转换
@@ -30,6 +31,8 @@ func (c *Converter) MessageModelToProto(msg *model.Message) *rest.WSMessage {
 		Timestamp:   msg.Timestamp,
 		MessageType: int32(msg.MessageType),
 		AckId:       msg.AckID,
+		Ttl:         msg.TTL,
+		SenderSeq:   msg.SenderSeq,
 	}
 }
 
@@ -69,20 +72,22 @@ func (c *Converter) BuildSendWSMessageResponse(success bool, message string) *re
 // BuildGetHistoryResponse 构建获取历史消息响应
 func (c *Converter) BuildGetHistoryResponse(messages []*model.Message, total int64, page, size int32) *rest.GetHistoryResponse {
 	return &rest.GetHistoryResponse{
-		Messages: c.MessageModelsToProto(messages),
-		Total:    int32(total),
-		Page:     page,
-		Size:     size,
+		Messages:   c.MessageModelsToProto(messages),
+		Total:      int32(total),
+		Page:       page,
+		Size:       size,
+		Pagination: httpx.OffsetPage(page, size, total).Proto(),
 	}
 }
 
 // BuildGetUnreadMessagesResponse 构建获取未读消息响应
-func (c *Converter) BuildGetUnreadMessagesResponse(success bool, message string, messages []*model.Message) *rest.GetUnreadMessagesResponse {
+func (c *Converter) BuildGetUnreadMessagesResponse(success bool, message string, page *model.UnreadPage) *rest.GetUnreadMessagesResponse {
 	return &rest.GetUnreadMessagesResponse{
-		Success:  success,
-		Message:  message,
-		Messages: c.MessageModelsToProto(messages),
-		Total:    int32(len(messages)),
+		Success:    success,
+		Message:    message,
+		Messages:   c.MessageModelsToProto(page.Messages),
+		Total:      int32(page.Total),
+		NextCursor: page.NextCursor,
 	}
 }
 
@@ -95,6 +100,19 @@ func (c *Converter) BuildMarkMessagesReadResponse(success bool, message string,
 	}
 }
 
+// ReadReceiptsToProto 将标记已读成功的消息转换为已读回执来源
+func (c *Converter) ReadReceiptsToProto(messages []*model.Message) []*rest.ReadReceipt {
+	receipts := make([]*rest.ReadReceipt, 0, len(messages))
+	for _, message := range messages {
+		receipts = append(receipts, &rest.ReadReceipt{
+			MessageId: message.MessageID,
+			SenderId:  message.From,
+			GroupId:   message.GroupID,
+		})
+	}
+	return receipts
+}
+
 // 便捷方法：构建错误响应
 
 // BuildErrorGetHistoryResponse 构建获取历史消息错误响应
@@ -145,13 +163,8 @@ func (c *Converter) BuildSuccessSendWSMessageResponse(message string) *rest.Send
 }
 
 // BuildSuccessGetUnreadMessagesResponse 构建获取未读消息成功响应
-func (c *Converter) BuildSuccessGetUnreadMessagesResponse(messages []*model.Message) *rest.GetUnreadMessagesResponse {
-	return &rest.GetUnreadMessagesResponse{
-		Success:  true,
-		Message:  "获取未读消息成功",
-		Messages: c.MessageModelsToProto(messages),
-		Total:    int32(len(messages)),
-	}
+func (c *Converter) BuildSuccessGetUnreadMessagesResponse(page *model.UnreadPage) *rest.GetUnreadMessagesResponse {
+	return c.BuildGetUnreadMessagesResponse(true, "获取未读消息成功", page)
 }
 
 // BuildSuccessMarkMessagesReadResponse 构建标记消息已读成功响应
@@ -408,12 +421,13 @@ func (c *Converter) BuildGetUserHistoryResponse(records []*model.HistoryRecord,
 	}
 
 	return &rest.GetUserHistoryResponse{
-		Success:  true,
-		Message:  "获取用户历史记录成功",
-		Records:  protoRecords,
-		Total:    total,
-		Page:     page,
-		PageSize: pageSize,
+		Success:    true,
+		Message:    "获取用户历史记录成功",
+		Records:    protoRecords,
+		Total:      total,
+		Page:       page,
+		PageSize:   pageSize,
+		Pagination: httpx.OffsetPage(page, pageSize, total).Proto(),
 	}
 }
 
@@ -468,3 +482,544 @@ func (c *Converter) BuildErrorGetUserActionStatsResponse(message string) *rest.G
 		Message: message,
 	}
 }
+
+// BuildGetGroupStatsResponse 构建获取群组统计响应
+func (c *Converter) BuildGetGroupStatsResponse(stats *model.GroupStats, posters []*model.GroupPosterStat, page, pageSize int32) *rest.GetGroupStatsResponse {
+	protoPosters := make([]*rest.GroupPosterStat, 0, len(posters))
+	for _, poster := range posters {
+		protoPosters = append(protoPosters, &rest.GroupPosterStat{
+			UserId:       poster.UserID,
+			MessageCount: poster.MessageCount,
+		})
+	}
+
+	return &rest.GetGroupStatsResponse{
+		Success:           true,
+		Message:           "获取群组统计成功",
+		MessageCount:      stats.MessageCount,
+		ActiveMemberCount: stats.ActiveMemberCount,
+		TopPosters:        protoPosters,
+		Page:              page,
+		PageSize:          pageSize,
+		StartTime:         stats.StartTime.Format(time.RFC3339),
+		EndTime:           stats.EndTime.Format(time.RFC3339),
+	}
+}
+
+// BuildErrorGetGroupStatsResponse 构建错误获取群组统计响应
+func (c *Converter) BuildErrorGetGroupStatsResponse(message string) *rest.GetGroupStatsResponse {
+	return &rest.GetGroupStatsResponse{
+		Success: false,
+		Message: message,
+	}
+}
+
+// BuildSetGroupReadWatermarkResponse 构建设置群已读水位响应
+func (c *Converter) BuildSetGroupReadWatermarkResponse(watermark *model.GroupReadWatermark) *rest.SetGroupReadWatermarkResponse {
+	return &rest.SetGroupReadWatermarkResponse{
+		Success:   true,
+		Message:   "设置群已读水位成功",
+		MessageId: watermark.MessageID,
+	}
+}
+
+// BuildErrorSetGroupReadWatermarkResponse 构建错误设置群已读水位响应
+func (c *Converter) BuildErrorSetGroupReadWatermarkResponse(message string) *rest.SetGroupReadWatermarkResponse {
+	return &rest.SetGroupReadWatermarkResponse{
+		Success: false,
+		Message: message,
+	}
+}
+
+// GroupReadWatermarkToProto 将群成员已读水位Model转换为Protobuf
+func (c *Converter) GroupReadWatermarkToProto(watermark *model.GroupReadWatermark) *rest.GroupReadWatermark {
+	return &rest.GroupReadWatermark{
+		UserId:    watermark.UserID,
+		MessageId: watermark.MessageID,
+		UpdatedAt: watermark.UpdatedAt.Format(time.RFC3339),
+		GroupId:   watermark.GroupID,
+	}
+}
+
+// BuildGetGroupReadStatusResponse 构建获取群已读状态响应
+func (c *Converter) BuildGetGroupReadStatusResponse(status *model.GroupReadStatus) *rest.GetGroupReadStatusResponse {
+	protoWatermarks := make([]*rest.GroupReadWatermark, 0, len(status.Watermarks))
+	for _, watermark := range status.Watermarks {
+		protoWatermarks = append(protoWatermarks, c.GroupReadWatermarkToProto(watermark))
+	}
+
+	return &rest.GetGroupReadStatusResponse{
+		Success:     true,
+		Message:     "获取群已读状态成功",
+		MessageId:   status.MessageID,
+		ReadCount:   status.ReadCount,
+		MemberCount: status.MemberCount,
+		Watermarks:  protoWatermarks,
+	}
+}
+
+// BuildErrorGetGroupReadStatusResponse 构建错误获取群已读状态响应
+func (c *Converter) BuildErrorGetGroupReadStatusResponse(message string) *rest.GetGroupReadStatusResponse {
+	return &rest.GetGroupReadStatusResponse{
+		Success: false,
+		Message: message,
+	}
+}
+
+// DeliveryReportToProto 将投递和已读汇总转换为protobuf格式
+func (c *Converter) DeliveryReportToProto(report *model.DeliveryReport) *rest.MessageDeliveryReport {
+	if report == nil {
+		return nil
+	}
+	return &rest.MessageDeliveryReport{
+		MessageId:      report.MessageID,
+		GroupId:        report.GroupID,
+		RecipientCount: report.RecipientCount,
+		Delivered:      report.Delivered,
+		Read:           report.Read,
+		Offline:        report.Offline,
+		Failed:         report.Failed,
+		UpdatedAt:      report.UpdatedAt,
+	}
+}
+
+// BuildGetMessageReadStatusResponse 构建获取群消息投递和已读明细响应
+func (c *Converter) BuildGetMessageReadStatusResponse(status *model.MessageReadStatus) *rest.GetMessageReadStatusResponse {
+	members := make([]*rest.MemberReadStatus, 0, len(status.Members))
+	for _, member := range status.Members {
+		members = append(members, &rest.MemberReadStatus{
+			UserId: member.UserID,
+			Status: member.Status,
+		})
+	}
+
+	return &rest.GetMessageReadStatusResponse{
+		Success:  true,
+		Message:  "获取消息已读明细成功",
+		Report:   c.DeliveryReportToProto(status.Report),
+		Members:  members,
+		Total:    status.Total,
+		Page:     int32(status.Page),
+		PageSize: int32(status.PageSize),
+	}
+}
+
+// BuildErrorGetMessageReadStatusResponse 构建错误获取群消息投递和已读明细响应
+func (c *Converter) BuildErrorGetMessageReadStatusResponse(message string) *rest.GetMessageReadStatusResponse {
+	return &rest.GetMessageReadStatusResponse{
+		Success: false,
+		Message: message,
+	}
+}
+
+// BuildSetConversationTTLResponse 构建设置会话消息有效期响应
+func (c *Converter) BuildSetConversationTTLResponse(conversationID string, ttl int64) *rest.SetConversationTTLResponse {
+	return &rest.SetConversationTTLResponse{
+		Success:        true,
+		Message:        "设置会话消息有效期成功",
+		ConversationId: conversationID,
+		Ttl:            ttl,
+	}
+}
+
+// BuildErrorSetConversationTTLResponse 构建错误设置会话消息有效期响应
+func (c *Converter) BuildErrorSetConversationTTLResponse(message string) *rest.SetConversationTTLResponse {
+	return &rest.SetConversationTTLResponse{
+		Success: false,
+		Message: message,
+	}
+}
+
+// BuildConversationRetentionResponse 构建会话消息保留期响应
+func (c *Converter) BuildConversationRetentionResponse(message string, retention *model.ConversationRetention) *rest.ConversationRetentionResponse {
+	return &rest.ConversationRetentionResponse{
+		Success:        true,
+		Message:        message,
+		ConversationId: retention.ConversationID,
+		RetentionDays:  int32(retention.RetentionDays),
+		EffectiveDays:  int32(retention.EffectiveDays),
+		PeerDays:       int32(retention.PeerDays),
+		DefaultDays:    int32(retention.DefaultDays),
+		MinDays:        int32(retention.MinDays),
+		MaxDays:        int32(retention.MaxDays),
+	}
+}
+
+// BuildErrorConversationRetentionResponse 构建错误会话消息保留期响应
+func (c *Converter) BuildErrorConversationRetentionResponse(message string) *rest.ConversationRetentionResponse {
+	return &rest.ConversationRetentionResponse{
+		Success: false,
+		Message: message,
+	}
+}
+
+// BuildPollResponse 构建投票结果响应
+func (c *Converter) BuildPollResponse(message string, result *model.PollResult) *rest.PollResponse {
+	poll := result.Poll
+	myChoices := make([]int32, 0, len(result.MyChoices))
+	for _, choice := range result.MyChoices {
+		myChoices = append(myChoices, int32(choice))
+	}
+	return &rest.PollResponse{
+		Success:     true,
+		Message:     message,
+		PollId:      poll.PollID,
+		GroupId:     poll.GroupID,
+		CreatorId:   poll.CreatorID,
+		Question:    poll.Question,
+		Options:     poll.Options,
+		MultiSelect: poll.MultiSelect,
+		Closed:      poll.Closed,
+		ClosedBy:    poll.ClosedBy,
+		ClosedAt:    poll.ClosedAt,
+		Counts:      result.Counts,
+		VoterCount:  result.VoterCount,
+		MyChoices:   myChoices,
+	}
+}
+
+// BuildErrorPollResponse 构建错误投票结果响应
+func (c *Converter) BuildErrorPollResponse(message string) *rest.PollResponse {
+	return &rest.PollResponse{
+		Success: false,
+		Message: message,
+	}
+}
+
+// BuildGroupHighlightsResponse 构建群亮点响应
+func (c *Converter) BuildGroupHighlightsResponse(message string, groupID int64, highlights []*model.GroupHighlight) *rest.GroupHighlightsResponse {
+	items := make([]*rest.GroupHighlight, 0, len(highlights))
+	for _, highlight := range highlights {
+		items = append(items, &rest.GroupHighlight{
+			Type:           highlight.Type,
+			AnnouncementId: highlight.AnnouncementID,
+			Content:        highlight.Content,
+			AuthorId:       highlight.AuthorID,
+			Message:        c.MessageModelToProto(highlight.Message),
+			PinnedBy:       highlight.PinnedBy,
+			PinnedAt:       highlight.PinnedAt,
+			CreatedAt:      highlight.CreatedAt,
+		})
+	}
+	return &rest.GroupHighlightsResponse{
+		Success:    true,
+		Message:    message,
+		GroupId:    groupID,
+		Highlights: items,
+	}
+}
+
+// BuildErrorGroupHighlightsResponse 构建错误群亮点响应
+func (c *Converter) BuildErrorGroupHighlightsResponse(message string) *rest.GroupHighlightsResponse {
+	return &rest.GroupHighlightsResponse{
+		Success: false,
+		Message: message,
+	}
+}
+
+// BuildGetDeliveryStatusResponse 构建获取投递链路状态响应
+func (c *Converter) BuildGetDeliveryStatusResponse(status *model.DeliveryStatus) *rest.GetDeliveryStatusResponse {
+	gateways := make([]*rest.GatewayDeliveryStatus, 0, len(status.Gateways))
+	for _, gateway := range status.Gateways {
+		item := &rest.GatewayDeliveryStatus{
+			ServerId:    gateway.ServerID,
+			Subscribers: gateway.Subscribers,
+			Pushed:      gateway.Pushed,
+			Unreceived:  gateway.Unreceived,
+			Failed:      gateway.Failed,
+			LastError:   gateway.LastError,
+		}
+		if !gateway.LastPushAt.IsZero() {
+			item.LastPushAt = gateway.LastPushAt.Format(time.RFC3339)
+		}
+		gateways = append(gateways, item)
+	}
+
+	resp := &rest.GetDeliveryStatusResponse{
+		Success:            true,
+		Message:            "获取投递链路状态成功",
+		Status:             status.Status,
+		Problems:           status.Problems,
+		Gateways:           gateways,
+		SubscribedGateways: status.SubscribedGateways,
+		PendingAck:         status.PendingAck,
+		CheckedAt:          status.CheckedAt.Format(time.RFC3339),
+	}
+	if !status.LastConsumedAt.IsZero() {
+		resp.LastConsumedAt = status.LastConsumedAt.Format(time.RFC3339)
+	}
+	if status.Fanout != nil {
+		resp.Fanout = &rest.FanoutDeliveryStat{
+			Delivered:     status.Fanout.Delivered,
+			QueuedOffline: status.Fanout.QueuedOffline,
+			Failed:        status.Fanout.Failed,
+			Batches:       status.Fanout.Batches,
+			MaxLag:        status.Fanout.MaxLag,
+		}
+		if !status.Fanout.LastEventAt.IsZero() {
+			resp.Fanout.LastEventAt = status.Fanout.LastEventAt.Format(time.RFC3339)
+		}
+	}
+	return resp
+}
+
+// BuildErrorGetDeliveryStatusResponse 构建错误获取投递链路状态响应
+func (c *Converter) BuildErrorGetDeliveryStatusResponse(message string) *rest.GetDeliveryStatusResponse {
+	return &rest.GetDeliveryStatusResponse{
+		Success: false,
+		Message: message,
+		Status:  model.DeliveryUnhealthy,
+	}
+}
+
+// GroupExportModelToProto 将导出记录转换为Protobuf
+func (c *Converter) GroupExportModelToProto(export *model.GroupExport) *rest.GroupExportInfo {
+	info := &rest.GroupExportInfo{
+		ExportId:     export.ExportID,
+		GroupId:      export.GroupID,
+		UserId:       export.UserID,
+		Format:       export.Format,
+		Status:       export.Status,
+		StartTime:    export.StartTime.Unix(),
+		EndTime:      export.EndTime.Unix(),
+		MessageCount: export.MessageCount,
+		Error:        export.Error,
+		CreatedAt:    export.CreatedAt.Unix(),
+	}
+	if export.CompletedAt != nil {
+		info.CompletedAt = export.CompletedAt.Unix()
+	}
+	return info
+}
+
+// BuildExportGroupHistoryResponse 构建导出群聊记录响应
+func (c *Converter) BuildExportGroupHistoryResponse(export *model.GroupExport) *rest.ExportGroupHistoryResponse {
+	message := "导出成功"
+	if export.Status == model.ExportStatusPending {
+		message = "导出任务已创建，请稍后查询进度"
+	}
+	return &rest.ExportGroupHistoryResponse{
+		Success: true,
+		Message: message,
+		Export:  c.GroupExportModelToProto(export),
+	}
+}
+
+// BuildErrorExportGroupHistoryResponse 构建错误导出群聊记录响应
+func (c *Converter) BuildErrorExportGroupHistoryResponse(message string) *rest.ExportGroupHistoryResponse {
+	return &rest.ExportGroupHistoryResponse{
+		Success: false,
+		Message: message,
+	}
+}
+
+// BuildGetGroupExportResponse 构建查询群聊记录导出响应
+func (c *Converter) BuildGetGroupExportResponse(export *model.GroupExport) *rest.GetGroupExportResponse {
+	return &rest.GetGroupExportResponse{
+		Success: true,
+		Message: "获取导出记录成功",
+		Export:  c.GroupExportModelToProto(export),
+	}
+}
+
+// BuildErrorGetGroupExportResponse 构建错误查询群聊记录导出响应
+func (c *Converter) BuildErrorGetGroupExportResponse(message string) *rest.GetGroupExportResponse {
+	return &rest.GetGroupExportResponse{
+		Success: false,
+		Message: message,
+	}
+}
+
+// ConversationSummaryToProto 将会话摘要转换为Protobuf
+func (c *Converter) ConversationSummaryToProto(summary *model.ConversationSummary) *rest.ConversationInfo {
+	info := &rest.ConversationInfo{
+		ConversationId: summary.ConversationID,
+		PeerId:         summary.PeerID,
+		GroupId:        summary.GroupID,
+		LastMessage:    c.MessageModelToProto(summary.LastMessage),
+	}
+	if summary.ArchivedAt != nil {
+		info.Archived = true
+		info.ArchivedAt = summary.ArchivedAt.Unix()
+	}
+	return info
+}
+
+// ConversationSummariesToProto 将会话摘要列表转换为Protobuf列表
+func (c *Converter) ConversationSummariesToProto(summaries []*model.ConversationSummary) []*rest.ConversationInfo {
+	result := make([]*rest.ConversationInfo, 0, len(summaries))
+	for _, summary := range summaries {
+		result = append(result, c.ConversationSummaryToProto(summary))
+	}
+	return result
+}
+
+// BuildGetConversationsResponse 构建获取会话列表响应
+func (c *Converter) BuildGetConversationsResponse(summaries []*model.ConversationSummary, page, size int32) *rest.GetConversationsResponse {
+	return &rest.GetConversationsResponse{
+		Success:       true,
+		Message:       "获取会话列表成功",
+		Conversations: c.ConversationSummariesToProto(summaries),
+		Page:          page,
+		Size:          size,
+	}
+}
+
+// BuildErrorGetConversationsResponse 构建错误获取会话列表响应
+func (c *Converter) BuildErrorGetConversationsResponse(message string) *rest.GetConversationsResponse {
+	return &rest.GetConversationsResponse{
+		Success: false,
+		Message: message,
+	}
+}
+
+// BuildArchiveConversationResponse 构建归档或取消归档会话响应
+func (c *Converter) BuildArchiveConversationResponse(conversationID string, archived bool) *rest.ArchiveConversationResponse {
+	message := "归档会话成功"
+	if !archived {
+		message = "取消归档会话成功"
+	}
+	return &rest.ArchiveConversationResponse{
+		Success:        true,
+		Message:        message,
+		ConversationId: conversationID,
+		Archived:       archived,
+	}
+}
+
+// BuildErrorArchiveConversationResponse 构建错误归档或取消归档会话响应
+func (c *Converter) BuildErrorArchiveConversationResponse(message string) *rest.ArchiveConversationResponse {
+	return &rest.ArchiveConversationResponse{
+		Success: false,
+		Message: message,
+	}
+}
+
+// BuildListArchivedConversationsResponse 构建获取已归档会话响应，已归档会话不分页，一次返回全部
+func (c *Converter) BuildListArchivedConversationsResponse(summaries []*model.ConversationSummary) *rest.ListArchivedConversationsResponse {
+	total := int32(len(summaries))
+	return &rest.ListArchivedConversationsResponse{
+		Success:       true,
+		Message:       "获取已归档会话成功",
+		Conversations: c.ConversationSummariesToProto(summaries),
+		Pagination:    httpx.OffsetPage(1, total, int64(total)).Proto(),
+	}
+}
+
+// BuildErrorListArchivedConversationsResponse 构建错误获取已归档会话响应
+func (c *Converter) BuildErrorListArchivedConversationsResponse(message string) *rest.ListArchivedConversationsResponse {
+	return &rest.ListArchivedConversationsResponse{
+		Success: false,
+		Message: message,
+	}
+}
+
+// ==================== 会话内搜索相关转换方法 ====================
+
+// ConversationSearchRequestToModel 将会话内搜索请求转换为搜索条件，时间为0表示不限
+func (c *Converter) ConversationSearchRequestToModel(req *rest.SearchConversationMessagesRequest) *model.ConversationSearchQuery {
+	query := &model.ConversationSearchQuery{
+		ConversationID: req.ConversationId,
+		Query:          req.Query,
+		SenderID:       req.SenderId,
+		Cursor:         req.Cursor,
+		Limit:          req.Limit,
+	}
+	if req.StartTime > 0 {
+		query.StartTime = time.Unix(req.StartTime, 0)
+	}
+	if req.EndTime > 0 {
+		query.EndTime = time.Unix(req.EndTime, 0)
+	}
+	return query
+}
+
+// BuildSearchConversationMessagesResponse 构建会话内搜索消息响应
+func (c *Converter) BuildSearchConversationMessagesResponse(page *model.ConversationSearchPage) *rest.SearchConversationMessagesResponse {
+	hits := make([]*rest.ConversationSearchHit, 0, len(page.Hits))
+	for _, hit := range page.Hits {
+		hits = append(hits, &rest.ConversationSearchHit{
+			Message: c.MessageModelToProto(hit.Message),
+			Snippet: hit.Snippet,
+		})
+	}
+	return &rest.SearchConversationMessagesResponse{
+		Success:    true,
+		Message:    "搜索会话消息成功",
+		Hits:       hits,
+		NextCursor: page.NextCursor,
+		Pagination: httpx.CursorPage(1, page.PageSize, 0, page.NextCursor).Proto(),
+	}
+}
+
+// BuildErrorSearchConversationMessagesResponse 构建错误会话内搜索消息响应
+func (c *Converter) BuildErrorSearchConversationMessagesResponse(message string) *rest.SearchConversationMessagesResponse {
+	return &rest.SearchConversationMessagesResponse{
+		Success: false,
+		Message: message,
+		Hits:    []*rest.ConversationSearchHit{},
+	}
+}
+
+// BuildSyncSinceResponse 构建增量同步响应
+func (c *Converter) BuildSyncSinceResponse(page *model.SyncPage) *rest.SyncSinceResponse {
+	messages := make([]*rest.SyncMessage, 0, len(page.Messages))
+	for _, msg := range page.Messages {
+		messages = append(messages, &rest.SyncMessage{
+			Message:   c.MessageModelToProto(msg),
+			Status:    msg.Status,
+			UpdatedAt: msg.UpdatedAt.UnixMilli(),
+		})
+	}
+
+	deletions := make([]*rest.SyncDeletion, 0, len(page.Deletions))
+	for _, tombstone := range page.Deletions {
+		deletions = append(deletions, &rest.SyncDeletion{
+			MessageId:      tombstone.MessageID,
+			ConversationId: tombstone.ConversationID,
+			Reason:         tombstone.Reason,
+			DeletedAt:      tombstone.DeletedAt.UnixMilli(),
+		})
+	}
+
+	watermarks := make([]*rest.GroupReadWatermark, 0, len(page.Watermarks))
+	for _, watermark := range page.Watermarks {
+		watermarks = append(watermarks, c.GroupReadWatermarkToProto(watermark))
+	}
+
+	return &rest.SyncSinceResponse{
+		Success:        true,
+		Message:        "同步成功",
+		Messages:       messages,
+		Deletions:      deletions,
+		ReadWatermarks: watermarks,
+		NextCursor:     page.NextCursor,
+		HasMore:        page.HasMore,
+		ResetRequired:  page.ResetRequired,
+	}
+}
+
+// BuildErrorSyncSinceResponse 构建错误增量同步响应
+func (c *Converter) BuildErrorSyncSinceResponse(message string) *rest.SyncSinceResponse {
+	return &rest.SyncSinceResponse{
+		Success: false,
+		Message: message,
+	}
+}
+
+// BuildDigestModeResponse 构建通知摘要设置响应
+func (c *Converter) BuildDigestModeResponse(mode string) *rest.DigestModeResponse {
+	return &rest.DigestModeResponse{
+		Success: true,
+		Message: "操作成功",
+		Mode:    mode,
+	}
+}
+
+// BuildErrorDigestModeResponse 构建错误通知摘要设置响应
+func (c *Converter) BuildErrorDigestModeResponse(message string) *rest.DigestModeResponse {
+	return &rest.DigestModeResponse{
+		Success: false,
+		Message: message,
+	}
+}
@@ -2,19 +2,67 @@ package dao
 
 import (
 	"context"
+	"errors"
 	"time"
 
 	"goim-social/apps/message-service/internal/model"
 )
 
-// MessageDAO 消息数据访问接口
+// 存储实现需将各自的错误转换为以下错误，服务层和消费者据此判断，不依赖具体存储的错误类型
+var (
+	ErrNotFound         = errors.New("record not found")  // 记录不存在
+	ErrDuplicateMessage = errors.New("duplicate message") // 消息ID已存在，重复投递时按成功处理
+)
+
+// MessageStore 消息存储接口，覆盖消息本身及会话设置的读写
+// 服务层和消费者只依赖该接口，更换存储（如Cassandra/ScyllaDB）时实现该接口即可，无需改动业务逻辑
+type MessageStore interface {
+	// 消息读写
+	SaveMessage(ctx context.Context, message *model.Message) error // 消息ID已存在时返回ErrDuplicateMessage
+	GetMessage(ctx context.Context, messageID int64) (*model.Message, error)                    // 不存在时返回ErrNotFound
+	GetMessagesByIDs(ctx context.Context, messageIDs []int64) ([]*model.Message, error)         // 不存在的消息不返回，顺序不保证
+	GetRecipientMessage(ctx context.Context, userID, messageID int64) (*model.Message, error) // 用户可标记已读的消息，不存在或无权限时返回ErrNotFound
+	UpdateMessageStatus(ctx context.Context, messageID int64, status string) error            // 消息不存在时返回ErrNotFound
+	DeleteMessage(ctx context.Context, messageID, senderID int64) (*model.Message, error)     // 只删除发送者自己的消息并记录墓碑，返回被删除的消息，不存在时返回ErrNotFound
+	CountPendingAckMessages(ctx context.Context, startTime, endTime time.Time) (int64, error)
+	
+	// 消息查询
+	ListMessageHistory(ctx context.Context, userID, groupID int64, offset, limit int64) ([]*model.Message, int64, error)
+	CountUnreadMessages(ctx context.Context, userID int64) (int64, error)
+	ListUnreadMessages(ctx context.Context, userID, beforeMessageID, limit int64) ([]*model.Message, error)
+	FindConversationMessagesBefore(ctx context.Context, query *model.ConversationSearchQuery, beforeMessageID, limit int64) ([]*model.Message, error)
+	GetLatestConversationMessages(ctx context.Context, userID int64, groupIDs []int64, excludeIDs []string, offset, limit int64) ([]*model.Message, error)
+	GetLatestMessagesByConversation(ctx context.Context, conversationIDs []string) ([]*model.Message, error)
+	
+	// 群消息查询
+	GetGroupPosterStats(ctx context.Context, groupID int64, startTime, endTime time.Time) ([]*model.GroupPosterStat, error)
+	CountGroupMessages(ctx context.Context, groupID int64, startTime, endTime time.Time) (int64, error)
+	FindGroupMessagesAfter(ctx context.Context, groupID int64, startTime, endTime time.Time, afterMessageID, limit int64) ([]*model.Message, error)
+	
+	// 消息过期
+	SetConversationTTL(ctx context.Context, conversationID string, ttl, userID int64) error
+	GetConversationTTL(ctx context.Context, conversationID string) (int64, error) // 未设置时返回0
+	FindExpiredMessages(ctx context.Context, now time.Time, limit int64) ([]*model.Message, error)
+	DeleteExpiredMessages(ctx context.Context, messageIDs []int64, now time.Time) (int64, error) // 删除时记录墓碑
+	
+	// 消息保留期，群聊按会话设置，单聊按参与者分别设置
+	SetConversationRetention(ctx context.Context, conversationID string, days int, userID int64) error
+	SetParticipantRetention(ctx context.Context, conversationID string, days int, userID int64) error // days为0时清除该参与者的设置
+	GetConversationRetention(ctx context.Context, conversationID string) (*model.ConversationSetting, error) // 未设置时返回nil
+	ListConversationRetentions(ctx context.Context, afterConversationID string, limit int64) ([]*model.ConversationSetting, error) // 设置了保留期的会话，按会话ID分页
+	FindConversationRetentions(ctx context.Context, conversationIDs []string) ([]*model.ConversationSetting, error) // 指定会话中设置了保留期的会话
+	FindMessagesCreatedBefore(ctx context.Context, conversationID string, before time.Time, limit int64) ([]*model.Message, error)
+	ScanMessagesCreatedBefore(ctx context.Context, before time.Time, after *model.Message, limit int64) ([]*model.Message, error) // 按(创建时间, 消息ID)升序分页
+	DeleteMessagesCreatedBefore(ctx context.Context, messageIDs []int64, before, now time.Time) (int64, error) // 删除时记录墓碑
+	
+	// 增量同步，按(变更时间, _id)升序返回after之后、until及之前的变更，群消息只返回windows内创建的
+	FindMessagesChangedSince(ctx context.Context, userID int64, windows []model.VisibleWindow, after model.SyncPosition, until time.Time, limit int64) ([]*model.Message, error)
+	FindTombstonesSince(ctx context.Context, userID int64, windows []model.VisibleWindow, after model.SyncPosition, until time.Time, limit int64) ([]*model.MessageTombstone, error)
+}
+
+// MessageDAO 消息服务数据访问接口，在消息存储之外包含历史记录、统计、已读水位、导出和归档等辅助数据
 type MessageDAO interface {
-	// 消息相关
-	SaveMessage(ctx context.Context, message *model.Message) error
-	GetMessage(ctx context.Context, messageID int64) (*model.Message, error)
-	GetMessageHistory(ctx context.Context, userID, targetID int64, isGroup bool, limit int32, offset int32) ([]*model.HistoryMessage, error)
-	UpdateMessageStatus(ctx context.Context, messageID int64, status string) error
-	DeleteMessage(ctx context.Context, messageID int64) error
+	MessageStore
 	
 	// 历史记录相关
 	RecordUserAction(ctx context.Context, record *model.HistoryRecord) error
@@ -27,4 +75,39 @@ type MessageDAO interface {
 	UpdateUserActionStats(ctx context.Context, userID int64, actionType string) error
 	GetObjectHotStats(ctx context.Context, objectType string, objectID int64) (*model.ObjectHotStats, error)
 	UpdateObjectHotStats(ctx context.Context, objectType string, objectID int64, actionType string, delta int64) error
+	
+	// 索引
+	EnsureIndexes(ctx context.Context) error
+	
+	// 群消息已读水位相关
+	SetGroupReadWatermark(ctx context.Context, groupID, userID, messageID int64) (*model.GroupReadWatermark, error)
+	GetGroupReadWatermarks(ctx context.Context, groupID int64) ([]*model.GroupReadWatermark, error)
+	FindGroupReadWatermarksSince(ctx context.Context, groupIDs []int64, after model.SyncPosition, until time.Time, limit int64) ([]*model.GroupReadWatermark, error)
+	
+	// 群聊记录导出相关
+	CreateGroupExport(ctx context.Context, export *model.GroupExport) error
+	FinishGroupExport(ctx context.Context, export *model.GroupExport) error
+	GetGroupExport(ctx context.Context, exportID string) (*model.GroupExport, error) // 不存在时返回ErrNotFound
+	FindGroupExportsCreatedBefore(ctx context.Context, status string, before time.Time, limit int64) ([]*model.GroupExport, error)
+	
+	// 会话归档相关
+	ArchiveConversation(ctx context.Context, userID int64, conversationID string) (*model.ConversationArchive, error)
+	UnarchiveConversation(ctx context.Context, userID int64, conversationID string) (bool, error)
+	UnarchiveForRecipients(ctx context.Context, conversationID string, senderID int64) (int64, error)
+	GetConversationArchives(ctx context.Context, userID int64, limit int64) ([]*model.ConversationArchive, error)
+	
+	// 群投票相关
+	GetPollVote(ctx context.Context, pollID, userID int64) (*model.PollVote, error) // 未投票时返回nil
+	ReplacePollVote(ctx context.Context, vote *model.PollVote, previous []int) (bool, error) // 之前的选择已变化时返回false
+	GetPollVotesExcluding(ctx context.Context, pollID int64, userIDs []int64) ([]*model.PollVote, error)
+	AddPollCounts(ctx context.Context, pollID, groupID int64, delta map[int]int64, voterDelta int64, requireOpen bool) (bool, error) // requireOpen时投票已结束返回false
+	GetPollState(ctx context.Context, pollID int64) (*model.PollState, error) // 投票进行中且无人投票时返回nil
+	ClosePoll(ctx context.Context, state *model.PollState) (bool, error) // 已结束时返回false
+	
+	// 群置顶消息相关
+	PinGroupMessage(ctx context.Context, pin *model.GroupPinnedMessage) (bool, error) // 已置顶时返回false
+	UnpinGroupMessage(ctx context.Context, groupID, messageID int64) (bool, error)   // 未置顶时返回false
+	GetGroupPinnedMessages(ctx context.Context, groupID int64) ([]*model.GroupPinnedMessage, error)
+	SetGroupPinPositions(ctx context.Context, groupID int64, positions map[int64]int64) error // positions的键为消息ID
+	SetMessagePinned(ctx context.Context, messageID int64, pinned bool) error
 }
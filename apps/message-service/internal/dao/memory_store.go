@@ -0,0 +1,715 @@
+package dao
+
+import (
+	"bytes"
+	"context"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"goim-social/apps/message-service/internal/model"
+	"goim-social/pkg/conversation"
+)
+
+// MemoryStore 内存消息存储，查询语义与MongoDB实现一致，供服务层和消费者的测试使用，数据不持久化
+// 时间按毫秒比较，与MongoDB的日期精度一致
+type MemoryStore struct {
+	mu         sync.Mutex
+	messages   map[int64]*model.Message
+	settings   map[string]*model.ConversationSetting
+	tombstones []*model.MessageTombstone
+}
+
+var _ MessageStore = (*MemoryStore)(nil)
+
+// NewMemoryStore 创建空的内存消息存储
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		messages: make(map[int64]*model.Message),
+		settings: make(map[string]*model.ConversationSetting),
+	}
+}
+
+// ==================== 消息读写 ====================
+
+// SaveMessage 保存消息，消息ID已存在时返回ErrDuplicateMessage
+func (m *MemoryStore) SaveMessage(ctx context.Context, message *model.Message) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.messages[message.MessageID]; ok {
+		return ErrDuplicateMessage
+	}
+	stored := *message
+	if stored.ID.IsZero() {
+		stored.ID = primitive.NewObjectID()
+	}
+	m.messages[stored.MessageID] = &stored
+	return nil
+}
+
+// GetMessage 获取消息
+func (m *MemoryStore) GetMessage(ctx context.Context, messageID int64) (*model.Message, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	message, ok := m.messages[messageID]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return copyMessage(message), nil
+}
+
+// GetMessagesByIDs 批量获取消息，不存在的消息不返回
+func (m *MemoryStore) GetMessagesByIDs(ctx context.Context, messageIDs []int64) ([]*model.Message, error) {
+	if len(messageIDs) == 0 {
+		return nil, nil
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var messages []*model.Message
+	for _, messageID := range messageIDs {
+		if message, ok := m.messages[messageID]; ok {
+			messages = append(messages, copyMessage(message))
+		}
+	}
+	return messages, nil
+}
+
+// GetRecipientMessage 获取用户可标记已读的消息：单聊中的接收者，或群聊消息
+func (m *MemoryStore) GetRecipientMessage(ctx context.Context, userID, messageID int64) (*model.Message, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	message, ok := m.messages[messageID]
+	if !ok || (message.To != userID && message.GroupID <= 0) {
+		return nil, ErrNotFound
+	}
+	return copyMessage(message), nil
+}
+
+// UpdateMessageStatus 更新消息状态
+func (m *MemoryStore) UpdateMessageStatus(ctx context.Context, messageID int64, status string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	message, ok := m.messages[messageID]
+	if !ok {
+		return ErrNotFound
+	}
+	message.Status = status
+	message.UpdatedAt = time.Now()
+	return nil
+}
+
+// DeleteMessage 删除发送者自己的消息并记录墓碑
+func (m *MemoryStore) DeleteMessage(ctx context.Context, messageID, senderID int64) (*model.Message, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	message, ok := m.messages[messageID]
+	if !ok || message.From != senderID {
+		return nil, ErrNotFound
+	}
+	delete(m.messages, messageID)
+	m.saveTombstones([]*model.Message{message}, model.DeletionReasonDeleted, time.Now())
+	return copyMessage(message), nil
+}
+
+// CountPendingAckMessages 统计时间范围内仍为已发送状态的单聊消息数
+func (m *MemoryStore) CountPendingAckMessages(ctx context.Context, startTime, endTime time.Time) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	messages := m.filter(func(msg *model.Message) bool {
+		return msg.GroupID == 0 && msg.Status == model.MessageStatusSent &&
+			!msg.CreatedAt.Before(startTime) && msg.CreatedAt.Before(endTime)
+	})
+	return int64(len(messages)), nil
+}
+
+// ==================== 消息查询 ====================
+
+// ListMessageHistory 分页获取消息历史，按时间倒序，同时返回总数
+func (m *MemoryStore) ListMessageHistory(ctx context.Context, userID, groupID int64, offset, limit int64) ([]*model.Message, int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	messages := m.filter(func(msg *model.Message) bool {
+		if expired(msg, now) {
+			return false
+		}
+		if groupID > 0 {
+			return msg.GroupID == groupID
+		}
+		return msg.GroupID == 0 && (msg.From == userID || msg.To == userID)
+	})
+	sort.SliceStable(messages, func(i, j int) bool { return messages[i].Timestamp > messages[j].Timestamp })
+	return paginate(messages, offset, limit), int64(len(messages)), nil
+}
+
+// CountUnreadMessages 统计用户的未读消息数
+func (m *MemoryStore) CountUnreadMessages(ctx context.Context, userID int64) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	return int64(len(m.filter(func(msg *model.Message) bool { return unread(msg, userID, now) }))), nil
+}
+
+// ListUnreadMessages 按消息ID倒序获取未读消息，beforeMessageID为0时从最新的消息开始
+func (m *MemoryStore) ListUnreadMessages(ctx context.Context, userID, beforeMessageID, limit int64) ([]*model.Message, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	messages := m.filter(func(msg *model.Message) bool {
+		return unread(msg, userID, now) && (beforeMessageID <= 0 || msg.MessageID < beforeMessageID)
+	})
+	sortByMessageIDDesc(messages)
+	return paginate(messages, 0, limit), nil
+}
+
+// FindConversationMessagesBefore 按消息ID倒序分批读取会话内可搜索的消息
+func (m *MemoryStore) FindConversationMessagesBefore(ctx context.Context, query *model.ConversationSearchQuery, beforeMessageID, limit int64) ([]*model.Message, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	messages := m.filter(func(msg *model.Message) bool {
+		if msg.ConversationID != query.ConversationID || msg.Status == model.MessageStatusRevoked || expired(msg, now) || msg.Unindexed {
+			return false
+		}
+		if beforeMessageID > 0 && msg.MessageID >= beforeMessageID {
+			return false
+		}
+		if query.SenderID > 0 && msg.From != query.SenderID {
+			return false
+		}
+		if !inWindow(msg.CreatedAt, model.VisibleWindow{Start: query.StartTime, End: query.EndTime}) {
+			return false
+		}
+		if query.Windows == nil {
+			return true
+		}
+		for _, window := range query.Windows {
+			if inWindow(msg.CreatedAt, window) {
+				return true
+			}
+		}
+		return false
+	})
+	sortByMessageIDDesc(messages)
+	return paginate(messages, 0, limit), nil
+}
+
+// GetLatestConversationMessages 获取用户参与的单聊和所在群聊的最后一条消息，跳过excludeIDs中的会话
+func (m *MemoryStore) GetLatestConversationMessages(ctx context.Context, userID int64, groupIDs []int64, excludeIDs []string, offset, limit int64) ([]*model.Message, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	seen := make(map[string]bool)
+	var conversationIDs []string
+	for _, msg := range m.messages {
+		if (msg.From != userID && msg.To != userID) || seen[msg.ConversationID] {
+			continue
+		}
+		if conv, err := conversation.Parse(msg.ConversationID); err != nil || conv.Type != conversation.TypePrivate {
+			continue
+		}
+		seen[msg.ConversationID] = true
+		conversationIDs = append(conversationIDs, msg.ConversationID)
+	}
+	for _, groupID := range groupIDs {
+		conversationIDs = append(conversationIDs, conversation.Group(groupID))
+	}
+
+	exclude := make(map[string]bool, len(excludeIDs))
+	for _, conversationID := range excludeIDs {
+		exclude[conversationID] = true
+	}
+	return m.latestConversationMessages(conversationIDs, exclude, offset, limit), nil
+}
+
+// GetLatestMessagesByConversation 获取指定会话的最后一条消息
+func (m *MemoryStore) GetLatestMessagesByConversation(ctx context.Context, conversationIDs []string) ([]*model.Message, error) {
+	if len(conversationIDs) == 0 {
+		return nil, nil
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.latestConversationMessages(conversationIDs, nil, 0, int64(len(conversationIDs))), nil
+}
+
+// latestConversationMessages 会话按最后一条消息从新到旧排列后分页，最后一条消息已到期的会话不返回
+func (m *MemoryStore) latestConversationMessages(conversationIDs []string, exclude map[string]bool, offset, limit int64) []*model.Message {
+	wanted := make(map[string]bool, len(conversationIDs))
+	for _, conversationID := range conversationIDs {
+		wanted[conversationID] = true
+	}
+	heads := make(map[string]*model.Message)
+	for _, msg := range m.messages {
+		if !wanted[msg.ConversationID] {
+			continue
+		}
+		if head, ok := heads[msg.ConversationID]; !ok || msg.MessageID > head.MessageID {
+			heads[msg.ConversationID] = msg
+		}
+	}
+
+	var latest []*model.Message
+	for conversationID, head := range heads {
+		if !exclude[conversationID] {
+			latest = append(latest, head)
+		}
+	}
+	sortByMessageIDDesc(latest)
+
+	now := time.Now()
+	var messages []*model.Message
+	for _, msg := range paginate(latest, offset, limit) {
+		if !expired(msg, now) {
+			messages = append(messages, copyMessage(msg))
+		}
+	}
+	return messages
+}
+
+// ==================== 群消息查询 ====================
+
+// GetGroupPosterStats 按发送者聚合群组窗口内的消息数，按发言数降序排列，不统计已撤回的消息
+func (m *MemoryStore) GetGroupPosterStats(ctx context.Context, groupID int64, startTime, endTime time.Time) ([]*model.GroupPosterStat, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	counts := make(map[int64]int64)
+	for _, msg := range m.messages {
+		if msg.GroupID == groupID && msg.Status != model.MessageStatusRevoked &&
+			inWindow(msg.CreatedAt, model.VisibleWindow{Start: startTime, End: endTime}) {
+			counts[msg.From]++
+		}
+	}
+	stats := make([]*model.GroupPosterStat, 0, len(counts))
+	for userID, count := range counts {
+		stats = append(stats, &model.GroupPosterStat{UserID: userID, MessageCount: count})
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].MessageCount != stats[j].MessageCount {
+			return stats[i].MessageCount > stats[j].MessageCount
+		}
+		return stats[i].UserID < stats[j].UserID
+	})
+	return stats, nil
+}
+
+// CountGroupMessages 统计群组时间范围内可导出的消息数
+func (m *MemoryStore) CountGroupMessages(ctx context.Context, groupID int64, startTime, endTime time.Time) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return int64(len(m.filter(groupExportMatcher(groupID, startTime, endTime)))), nil
+}
+
+// FindGroupMessagesAfter 按消息ID升序分批读取群组时间范围内的消息
+func (m *MemoryStore) FindGroupMessagesAfter(ctx context.Context, groupID int64, startTime, endTime time.Time, afterMessageID, limit int64) ([]*model.Message, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	exportable := groupExportMatcher(groupID, startTime, endTime)
+	messages := m.filter(func(msg *model.Message) bool {
+		return msg.MessageID > afterMessageID && exportable(msg)
+	})
+	sort.Slice(messages, func(i, j int) bool { return messages[i].MessageID < messages[j].MessageID })
+	return paginate(messages, 0, limit), nil
+}
+
+// groupExportMatcher 群聊记录导出的消息范围，不含已撤回和已到期的消息
+func groupExportMatcher(groupID int64, startTime, endTime time.Time) func(*model.Message) bool {
+	now := time.Now()
+	return func(msg *model.Message) bool {
+		return msg.GroupID == groupID && msg.Status != model.MessageStatusRevoked && !expired(msg, now) &&
+			inWindow(msg.CreatedAt, model.VisibleWindow{Start: startTime, End: endTime})
+	}
+}
+
+// ==================== 消息过期 ====================
+
+// SetConversationTTL 设置会话默认消息有效期
+func (m *MemoryStore) SetConversationTTL(ctx context.Context, conversationID string, ttl, userID int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	setting := m.setting(conversationID, userID)
+	setting.TTL = ttl
+	return nil
+}
+
+// GetConversationTTL 获取会话默认消息有效期，未设置时返回0
+func (m *MemoryStore) GetConversationTTL(ctx context.Context, conversationID string) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if setting, ok := m.settings[conversationID]; ok {
+		return setting.TTL, nil
+	}
+	return 0, nil
+}
+
+// FindExpiredMessages 查询已到期的消息，按过期时间从早到晚排列
+func (m *MemoryStore) FindExpiredMessages(ctx context.Context, now time.Time, limit int64) ([]*model.Message, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	messages := m.filter(func(msg *model.Message) bool { return expired(msg, now) })
+	sort.Slice(messages, func(i, j int) bool { return messages[i].ExpireAt.Before(*messages[j].ExpireAt) })
+	return paginate(messages, 0, limit), nil
+}
+
+// DeleteExpiredMessages 删除已到期的消息并记录墓碑，返回实际删除数
+func (m *MemoryStore) DeleteExpiredMessages(ctx context.Context, messageIDs []int64, now time.Time) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.deleteMessages(messageIDs, func(msg *model.Message) bool { return expired(msg, now) }, model.DeletionReasonExpired, now), nil
+}
+
+// ==================== 消息保留期 ====================
+
+// SetConversationRetention 设置会话消息保留天数，0表示使用默认保留期
+func (m *MemoryStore) SetConversationRetention(ctx context.Context, conversationID string, days int, userID int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.setting(conversationID, userID).RetentionDays = days
+	return nil
+}
+
+// SetParticipantRetention 设置单聊参与者自己的保留天数，0表示清除该参与者的设置
+// 与MongoDB实现一致，设置全部清除后保留空的参与者设置，会话仍视为已设置保留期
+func (m *MemoryStore) SetParticipantRetention(ctx context.Context, conversationID string, days int, userID int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	setting := m.setting(conversationID, userID)
+	if setting.ParticipantDays == nil {
+		setting.ParticipantDays = make(map[string]int)
+	}
+	key := strconv.FormatInt(userID, 10)
+	if days > 0 {
+		setting.ParticipantDays[key] = days
+	} else {
+		delete(setting.ParticipantDays, key)
+	}
+	return nil
+}
+
+// GetConversationRetention 获取会话的保留期设置，未设置时返回nil
+func (m *MemoryStore) GetConversationRetention(ctx context.Context, conversationID string) (*model.ConversationSetting, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	setting, ok := m.settings[conversationID]
+	if !ok {
+		return nil, nil
+	}
+	return copySetting(setting), nil
+}
+
+// ListConversationRetentions 分页获取设置了保留期的会话，按会话ID升序
+func (m *MemoryStore) ListConversationRetentions(ctx context.Context, afterConversationID string, limit int64) ([]*model.ConversationSetting, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var settings []*model.ConversationSetting
+	for conversationID, setting := range m.settings {
+		if conversationID > afterConversationID && hasRetention(setting) {
+			settings = append(settings, copySetting(setting))
+		}
+	}
+	sort.Slice(settings, func(i, j int) bool { return settings[i].ConversationID < settings[j].ConversationID })
+	return paginate(settings, 0, limit), nil
+}
+
+// FindConversationRetentions 获取指定会话中设置了保留期的会话
+func (m *MemoryStore) FindConversationRetentions(ctx context.Context, conversationIDs []string) ([]*model.ConversationSetting, error) {
+	if len(conversationIDs) == 0 {
+		return nil, nil
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var settings []*model.ConversationSetting
+	for _, conversationID := range conversationIDs {
+		if setting, ok := m.settings[conversationID]; ok && hasRetention(setting) {
+			settings = append(settings, copySetting(setting))
+		}
+	}
+	return settings, nil
+}
+
+// FindMessagesCreatedBefore 查询会话中早于指定时间创建的消息，按创建时间从早到晚排列，不含群置顶消息
+func (m *MemoryStore) FindMessagesCreatedBefore(ctx context.Context, conversationID string, before time.Time, limit int64) ([]*model.Message, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	messages := m.filter(func(msg *model.Message) bool {
+		return msg.ConversationID == conversationID && createdBefore(msg, before)
+	})
+	sortByCreatedAt(messages)
+	return paginate(messages, 0, limit), nil
+}
+
+// ScanMessagesCreatedBefore 按(创建时间, 消息ID)升序分页查询所有会话中早于指定时间创建的消息，不含群置顶消息
+func (m *MemoryStore) ScanMessagesCreatedBefore(ctx context.Context, before time.Time, after *model.Message, limit int64) ([]*model.Message, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	messages := m.filter(func(msg *model.Message) bool {
+		if !createdBefore(msg, before) {
+			return false
+		}
+		if after == nil {
+			return true
+		}
+		createdAt, afterCreatedAt := msg.CreatedAt.UnixMilli(), after.CreatedAt.UnixMilli()
+		return createdAt > afterCreatedAt || createdAt == afterCreatedAt && msg.MessageID > after.MessageID
+	})
+	sortByCreatedAt(messages)
+	return paginate(messages, 0, limit), nil
+}
+
+// DeleteMessagesCreatedBefore 删除超过保留期的消息并记录墓碑，返回实际删除数，查询后被置顶的消息不删除
+func (m *MemoryStore) DeleteMessagesCreatedBefore(ctx context.Context, messageIDs []int64, before, now time.Time) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.deleteMessages(messageIDs, func(msg *model.Message) bool { return createdBefore(msg, before) }, model.DeletionReasonRetention, now), nil
+}
+
+// ==================== 增量同步 ====================
+
+// FindMessagesChangedSince 查询用户会话中新增或状态变化的消息
+func (m *MemoryStore) FindMessagesChangedSince(ctx context.Context, userID int64, windows []model.VisibleWindow, after model.SyncPosition, until time.Time, limit int64) ([]*model.Message, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	afterID, err := syncAfterID(after)
+	if err != nil {
+		return nil, err
+	}
+	messages := m.filter(func(msg *model.Message) bool {
+		return participates(userID, windows, msg.From, msg.To, msg.GroupID, msg.CreatedAt) &&
+			changedSince(msg.UpdatedAt, msg.ID, after, afterID, until)
+	})
+	sort.Slice(messages, func(i, j int) bool {
+		return changedBefore(messages[i].UpdatedAt, messages[i].ID, messages[j].UpdatedAt, messages[j].ID)
+	})
+	return paginate(messages, 0, limit), nil
+}
+
+// FindTombstonesSince 查询用户会话中已删除消息的墓碑
+func (m *MemoryStore) FindTombstonesSince(ctx context.Context, userID int64, windows []model.VisibleWindow, after model.SyncPosition, until time.Time, limit int64) ([]*model.MessageTombstone, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	afterID, err := syncAfterID(after)
+	if err != nil {
+		return nil, err
+	}
+	var tombstones []*model.MessageTombstone
+	for _, tombstone := range m.tombstones {
+		if participates(userID, windows, tombstone.From, tombstone.To, tombstone.GroupID, tombstone.CreatedAt) &&
+			changedSince(tombstone.DeletedAt, tombstone.ID, after, afterID, until) {
+			copied := *tombstone
+			tombstones = append(tombstones, &copied)
+		}
+	}
+	sort.Slice(tombstones, func(i, j int) bool {
+		return changedBefore(tombstones[i].DeletedAt, tombstones[i].ID, tombstones[j].DeletedAt, tombstones[j].ID)
+	})
+	return paginate(tombstones, 0, limit), nil
+}
+
+// ==================== 内部方法 ====================
+
+// filter 返回满足条件的消息副本，调用方需持有锁
+func (m *MemoryStore) filter(match func(*model.Message) bool) []*model.Message {
+	var messages []*model.Message
+	for _, msg := range m.messages {
+		if match(msg) {
+			messages = append(messages, copyMessage(msg))
+		}
+	}
+	return messages
+}
+
+// deleteMessages 删除指定消息中满足条件的消息并记录墓碑，返回删除数，调用方需持有锁
+func (m *MemoryStore) deleteMessages(messageIDs []int64, match func(*model.Message) bool, reason string, now time.Time) int64 {
+	var deleted []*model.Message
+	for _, messageID := range messageIDs {
+		if msg, ok := m.messages[messageID]; ok && match(msg) {
+			delete(m.messages, messageID)
+			deleted = append(deleted, msg)
+		}
+	}
+	m.saveTombstones(deleted, reason, now)
+	return int64(len(deleted))
+}
+
+// saveTombstones 记录已删除消息的墓碑，同一消息只记录一次，调用方需持有锁
+func (m *MemoryStore) saveTombstones(messages []*model.Message, reason string, deletedAt time.Time) {
+	for _, msg := range messages {
+		recorded := false
+		for _, tombstone := range m.tombstones {
+			if tombstone.MessageID == msg.MessageID {
+				recorded = true
+				break
+			}
+		}
+		if recorded {
+			continue
+		}
+		m.tombstones = append(m.tombstones, &model.MessageTombstone{
+			ID:             primitive.NewObjectID(),
+			MessageID:      msg.MessageID,
+			ConversationID: msg.ConversationID,
+			From:           msg.From,
+			To:             msg.To,
+			GroupID:        msg.GroupID,
+			Reason:         reason,
+			CreatedAt:      msg.CreatedAt,
+			DeletedAt:      deletedAt,
+		})
+	}
+}
+
+// setting 获取会话设置，不存在时创建，并记录修改者，调用方需持有锁
+func (m *MemoryStore) setting(conversationID string, userID int64) *model.ConversationSetting {
+	setting, ok := m.settings[conversationID]
+	if !ok {
+		setting = &model.ConversationSetting{ConversationID: conversationID}
+		m.settings[conversationID] = setting
+	}
+	setting.UpdatedBy = userID
+	setting.UpdatedAt = time.Now()
+	return setting
+}
+
+// paginate 跳过offset条后取limit条，limit不大于0时不限制，与MongoDB的skip和limit一致
+func paginate[T any](items []T, offset, limit int64) []T {
+	if offset >= int64(len(items)) {
+		return nil
+	}
+	items = items[offset:]
+	if limit > 0 && int64(len(items)) > limit {
+		items = items[:limit]
+	}
+	return items
+}
+
+func copyMessage(msg *model.Message) *model.Message {
+	copied := *msg
+	return &copied
+}
+
+func copySetting(setting *model.ConversationSetting) *model.ConversationSetting {
+	copied := *setting
+	if setting.ParticipantDays != nil {
+		copied.ParticipantDays = make(map[string]int, len(setting.ParticipantDays))
+		for userID, days := range setting.ParticipantDays {
+			copied.ParticipantDays[userID] = days
+		}
+	}
+	return &copied
+}
+
+// expired 消息已到期但尚未清理
+func expired(msg *model.Message, now time.Time) bool {
+	return msg.ExpireAt != nil && !msg.ExpireAt.After(now)
+}
+
+// unread 用户的未读消息：发给用户的单聊消息和群聊消息，已到期的不计入
+func unread(msg *model.Message, userID int64, now time.Time) bool {
+	return (msg.To == userID || msg.GroupID > 0) && msg.Status != model.MessageStatusRead && !expired(msg, now)
+}
+
+// createdBefore 消息早于before创建且不是群置顶消息
+func createdBefore(msg *model.Message, before time.Time) bool {
+	return msg.CreatedAt.UnixMilli() < before.UnixMilli() && !msg.Pinned
+}
+
+// hasRetention 会话设置了保留期，单聊参与者的设置全部清除后同样视为已设置
+func hasRetention(setting *model.ConversationSetting) bool {
+	return setting.RetentionDays > 0 || setting.ParticipantDays != nil
+}
+
+// inWindow 时间在窗口内，窗口的零值边界表示不限
+func inWindow(t time.Time, window model.VisibleWindow) bool {
+	ms := t.UnixMilli()
+	if !window.Start.IsZero() && ms < window.Start.UnixMilli() {
+		return false
+	}
+	if !window.End.IsZero() && ms > window.End.UnixMilli() {
+		return false
+	}
+	return true
+}
+
+// participates 用户参与的单聊，或用户在群期间的群聊
+func participates(userID int64, windows []model.VisibleWindow, from, to, groupID int64, createdAt time.Time) bool {
+	if groupID == 0 {
+		return from == userID || to == userID
+	}
+	for _, window := range windows {
+		if window.GroupID == groupID && inWindow(createdAt, window) {
+			return true
+		}
+	}
+	return false
+}
+
+// syncAfterID 解析同步位置中同一毫秒内最后一条记录的_id
+func syncAfterID(after model.SyncPosition) (primitive.ObjectID, error) {
+	if after.ID == "" {
+		return primitive.NilObjectID, nil
+	}
+	return primitive.ObjectIDFromHex(after.ID)
+}
+
+// changedSince 变更时间在after之后、until及之前，同一毫秒内按_id继续
+func changedSince(changedAt time.Time, id primitive.ObjectID, after model.SyncPosition, afterID primitive.ObjectID, until time.Time) bool {
+	ms := changedAt.UnixMilli()
+	if ms > after.Time && ms <= until.UnixMilli() {
+		return true
+	}
+	return after.ID != "" && ms == after.Time && bytes.Compare(id[:], afterID[:]) > 0
+}
+
+// changedBefore 按(变更时间, _id)升序比较
+func changedBefore(a time.Time, aID primitive.ObjectID, b time.Time, bID primitive.ObjectID) bool {
+	if a.UnixMilli() != b.UnixMilli() {
+		return a.UnixMilli() < b.UnixMilli()
+	}
+	return bytes.Compare(aID[:], bID[:]) < 0
+}
+
+func sortByMessageIDDesc(messages []*model.Message) {
+	sort.Slice(messages, func(i, j int) bool { return messages[i].MessageID > messages[j].MessageID })
+}
+
+// sortByCreatedAt 按(创建时间, 消息ID)升序排列
+func sortByCreatedAt(messages []*model.Message) {
+	sort.Slice(messages, func(i, j int) bool {
+		a, b := messages[i].CreatedAt.UnixMilli(), messages[j].CreatedAt.UnixMilli()
+		if a != b {
+			return a < b
+		}
+		return messages[i].MessageID < messages[j].MessageID
+	})
+}
@@ -0,0 +1,19 @@
+package dao
+
+import (
+	"context"
+
+	"goim-social/apps/message-service/internal/model"
+)
+
+// ApplyMessageTTL 设置消息有效期和过期时间，消息未指定有效期时使用会话默认设置，有效期不超过上限
+// 读取会话设置失败时按永久保存处理并返回错误，由调用方记录日志后继续保存
+func ApplyMessageTTL(ctx context.Context, store MessageStore, message *model.Message, ttl int64) error {
+	var err error
+	if ttl <= 0 {
+		ttl, err = store.GetConversationTTL(ctx, message.ConversationID)
+	}
+	message.TTL = min(max(ttl, 0), model.MaxMessageTTL)
+	message.ExpireAt = model.MessageExpireAt(message.CreatedAt, message.TTL)
+	return err
+}
@@ -2,7 +2,10 @@ package dao
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log"
+	"sort"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
@@ -11,6 +14,7 @@ import (
 	"go.mongodb.org/mongo-driver/mongo/options"
 
 	"goim-social/apps/message-service/internal/model"
+	"goim-social/pkg/conversation"
 )
 
 type mongoDAO struct {
@@ -26,10 +30,13 @@ func NewMongoDAO(db *mongo.Database) MessageDAO {
 
 // ==================== 消息相关方法 ====================
 
-// SaveMessage 保存消息
+// SaveMessage 保存消息，依赖MessageID唯一索引保证幂等，重复写入返回ErrDuplicateMessage
 func (d *mongoDAO) SaveMessage(ctx context.Context, message *model.Message) error {
 	collection := d.db.Collection("messages")
 	_, err := collection.InsertOne(ctx, message)
+	if mongo.IsDuplicateKeyError(err) {
+		return ErrDuplicateMessage
+	}
 	return err
 }
 
@@ -38,81 +45,186 @@ func (d *mongoDAO) GetMessage(ctx context.Context, messageID int64) (*model.Mess
 	collection := d.db.Collection("messages")
 	var message model.Message
 	err := collection.FindOne(ctx, bson.M{"message_id": messageID}).Decode(&message)
+	if err != nil {
+		return nil, notFound(err)
+	}
+	return &message, nil
+}
+
+// GetMessagesByIDs 批量获取消息，不存在的消息不返回
+func (d *mongoDAO) GetMessagesByIDs(ctx context.Context, messageIDs []int64) ([]*model.Message, error) {
+	if len(messageIDs) == 0 {
+		return nil, nil
+	}
+	collection := d.db.Collection("messages")
+	cursor, err := collection.Find(ctx, bson.M{"message_id": bson.M{"$in": messageIDs}})
 	if err != nil {
 		return nil, err
 	}
+	defer cursor.Close(ctx)
+	
+	var messages []*model.Message
+	if err := cursor.All(ctx, &messages); err != nil {
+		return nil, err
+	}
+	return messages, nil
+}
+
+// GetRecipientMessage 获取用户可标记已读的消息：单聊中的接收者，或群聊消息（群成员身份由调用方验证）
+func (d *mongoDAO) GetRecipientMessage(ctx context.Context, userID, messageID int64) (*model.Message, error) {
+	collection := d.db.Collection("messages")
+	filter := bson.M{
+		"message_id": messageID,
+		"$or": []bson.M{
+			{"to": userID},                 // 私聊中的接收者
+			{"group_id": bson.M{"$gt": 0}}, // 群聊消息（需要进一步验证群成员身份）
+		},
+	}
+	var message model.Message
+	if err := collection.FindOne(ctx, filter).Decode(&message); err != nil {
+		return nil, notFound(err)
+	}
 	return &message, nil
 }
 
-// GetMessageHistory 获取消息历史
-func (d *mongoDAO) GetMessageHistory(ctx context.Context, userID, targetID int64, isGroup bool, limit int32, offset int32) ([]*model.HistoryMessage, error) {
+// UpdateMessageStatus 更新消息状态
+func (d *mongoDAO) UpdateMessageStatus(ctx context.Context, messageID int64, status string) error {
+	collection := d.db.Collection("messages")
+	filter := bson.M{"message_id": messageID}
+	update := bson.M{
+		"$set": bson.M{
+			"status":     status,
+			"updated_at": time.Now(),
+		},
+	}
+	result, err := collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// CountPendingAckMessages 统计时间范围内仍为已发送状态、未被接收方确认的单聊消息数
+func (d *mongoDAO) CountPendingAckMessages(ctx context.Context, startTime, endTime time.Time) (int64, error) {
+	collection := d.db.Collection("messages")
+	filter := bson.M{
+		"group_id":   0,
+		"status":     model.MessageStatusSent,
+		"created_at": bson.M{"$gte": startTime, "$lt": endTime},
+	}
+	return collection.CountDocuments(ctx, filter)
+}
+
+// DeleteMessage 删除发送者自己的消息，并记录墓碑供离线客户端同步删除
+func (d *mongoDAO) DeleteMessage(ctx context.Context, messageID, senderID int64) (*model.Message, error) {
+	collection := d.db.Collection("messages")
+	var message model.Message
+	err := collection.FindOneAndDelete(ctx, bson.M{"message_id": messageID, "from": senderID}).Decode(&message)
+	if err != nil {
+		return nil, notFound(err)
+	}
+	return &message, d.saveTombstones(ctx, []*model.Message{&message}, model.DeletionReasonDeleted, time.Now())
+}
+
+// ListMessageHistory 分页获取消息历史，按时间倒序，同时返回总数
+// groupID大于0时查询该群组的所有消息，否则查询用户参与的所有私聊消息
+func (d *mongoDAO) ListMessageHistory(ctx context.Context, userID, groupID int64, offset, limit int64) ([]*model.Message, int64, error) {
 	collection := d.db.Collection("messages")
 	
 	var filter bson.M
-	if isGroup {
-		filter = bson.M{"group_id": targetID}
+	if groupID > 0 {
+		filter = bson.M{"group_id": groupID}
 	} else {
 		filter = bson.M{
-			"$or": []bson.M{
-				{"from": userID, "to": targetID},
-				{"from": targetID, "to": userID},
+			"$and": []bson.M{
+				{"group_id": bson.M{"$eq": 0}}, // 不是群聊
+				{
+					"$or": []bson.M{
+						{"from": userID},
+						{"to": userID},
+					},
+				},
 			},
 		}
 	}
+	filter["expire_at"] = bson.M{"$not": bson.M{"$lte": time.Now()}} // 已到期但尚未清理的消息不返回
+	
+	total, err := collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, fmt.Errorf("统计消息数量失败: %v", err)
+	}
 	
 	opts := options.Find().
-		SetSort(bson.D{{Key: "created_at", Value: -1}}).
-		SetLimit(int64(limit)).
-		SetSkip(int64(offset))
+		SetSort(bson.D{{Key: "timestamp", Value: -1}}). // 按时间倒序
+		SetSkip(offset).
+		SetLimit(limit)
+	cursor, err := collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, 0, fmt.Errorf("查询消息失败: %v", err)
+	}
+	defer cursor.Close(ctx)
+	
+	return decodeMessages(ctx, cursor), total, nil
+}
+
+// unreadFilter 用户的未读消息：发给用户的单聊消息和群聊消息，已到期但尚未清理的消息不计入
+func unreadFilter(userID int64) bson.M {
+	return bson.M{
+		"$or": []bson.M{
+			{"to": userID, "status": bson.M{"$ne": model.MessageStatusRead}},
+			{"group_id": bson.M{"$gt": 0}, "status": bson.M{"$ne": model.MessageStatusRead}},
+		},
+		"expire_at": bson.M{"$not": bson.M{"$lte": time.Now()}},
+	}
+}
+
+// CountUnreadMessages 统计用户的未读消息数
+func (d *mongoDAO) CountUnreadMessages(ctx context.Context, userID int64) (int64, error) {
+	return d.db.Collection("messages").CountDocuments(ctx, unreadFilter(userID))
+}
+
+// ListUnreadMessages 按消息ID倒序获取未读消息，beforeMessageID为0时从最新的消息开始
+func (d *mongoDAO) ListUnreadMessages(ctx context.Context, userID, beforeMessageID, limit int64) ([]*model.Message, error) {
+	collection := d.db.Collection("messages")
 	
+	filter := unreadFilter(userID)
+	if beforeMessageID > 0 {
+		filter = bson.M{"$and": []bson.M{filter, {"message_id": bson.M{"$lt": beforeMessageID}}}}
+	}
+	opts := options.Find().
+		SetSort(bson.D{{Key: "message_id", Value: -1}}).
+		SetLimit(limit)
 	cursor, err := collection.Find(ctx, filter, opts)
 	if err != nil {
 		return nil, err
 	}
 	defer cursor.Close(ctx)
 	
-	var messages []*model.HistoryMessage
+	return decodeMessages(ctx, cursor), nil
+}
+
+// decodeMessages 逐条解码消息，跳过无法解码的文档
+func decodeMessages(ctx context.Context, cursor *mongo.Cursor) []*model.Message {
+	var messages []*model.Message
 	for cursor.Next(ctx) {
 		var msg model.Message
 		if err := cursor.Decode(&msg); err != nil {
+			log.Printf("解码消息失败: %v", err)
 			continue
 		}
-		
-		historyMsg := &model.HistoryMessage{
-			ID:        msg.MessageID,
-			From:      msg.From,
-			To:        msg.To,
-			GroupID:   msg.GroupID,
-			Content:   msg.Content,
-			MsgType:   int32(msg.MessageType),
-			AckID:     msg.AckID,
-			CreatedAt: msg.CreatedAt,
-			Status:    0, // 默认状态
-		}
-		messages = append(messages, historyMsg)
+		messages = append(messages, &msg)
 	}
-	
-	return messages, nil
+	return messages
 }
 
-// UpdateMessageStatus 更新消息状态
-func (d *mongoDAO) UpdateMessageStatus(ctx context.Context, messageID int64, status string) error {
-	collection := d.db.Collection("messages")
-	filter := bson.M{"message_id": messageID}
-	update := bson.M{
-		"$set": bson.M{
-			"status":     status,
-			"updated_at": time.Now(),
-		},
+// notFound 将MongoDB的未找到错误转换为ErrNotFound
+func notFound(err error) error {
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return ErrNotFound
 	}
-	_, err := collection.UpdateOne(ctx, filter, update)
-	return err
-}
-
-// DeleteMessage 删除消息
-func (d *mongoDAO) DeleteMessage(ctx context.Context, messageID int64) error {
-	collection := d.db.Collection("messages")
-	_, err := collection.DeleteOne(ctx, bson.M{"message_id": messageID})
 	return err
 }
 
@@ -384,3 +496,1202 @@ func (d *mongoDAO) UpdateObjectHotStats(ctx context.Context, objectType string,
 	_, err := collection.UpdateOne(ctx, filter, update, opts)
 	return err
 }
+
+// ==================== 群组统计相关方法 ====================
+
+// GetGroupPosterStats 按发送者聚合群组窗口内的消息数，按发言数降序排列，不统计已撤回的消息
+func (d *mongoDAO) GetGroupPosterStats(ctx context.Context, groupID int64, startTime, endTime time.Time) ([]*model.GroupPosterStat, error) {
+	collection := d.db.Collection("messages")
+	
+	pipeline := []bson.M{
+		{"$match": bson.M{
+			"group_id":   groupID,
+			"status":     bson.M{"$ne": model.MessageStatusRevoked},
+			"created_at": bson.M{"$gte": startTime, "$lte": endTime},
+		}},
+		{"$group": bson.M{
+			"_id":   "$from",
+			"count": bson.M{"$sum": 1},
+		}},
+		{"$sort": bson.D{{Key: "count", Value: -1}, {Key: "_id", Value: 1}}},
+	}
+	
+	cursor, err := collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+	
+	var stats []*model.GroupPosterStat
+	if err := cursor.All(ctx, &stats); err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+// EnsureIndexes 创建查询依赖的索引
+// 会话ID索引用于按会话分页查询历史消息；状态索引用于统计待确认积压；群成员已读水位唯一索引保证并发upsert时每个成员只有一条水位记录
+// 过期时间索引用于过期清理任务扫描到期消息，不使用MongoDB TTL索引以便删除后通知客户端
+// 群组消息ID索引用于群聊记录导出按消息ID分批读取
+// 会话归档唯一索引保证每个用户每个会话只有一条归档记录，会话ID索引用于新消息到达时取消归档
+// 会话消息ID索引用于会话内搜索按消息ID倒序分批读取，以及会话列表逐会话定位最后一条消息
+// 发送者/接收者与会话ID的组合索引用于会话列表去重扫描用户参与的单聊会话
+// 变更时间索引用于增量同步按(变更时间, _id)分页读取，墓碑的删除时间索引同时是TTL索引，超过同步保留时长后清理
+func (d *mongoDAO) EnsureIndexes(ctx context.Context) error {
+	_, err := d.db.Collection("messages").Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "conversation_id", Value: 1}, {Key: "created_at", Value: -1}},
+	})
+	if err != nil {
+		return fmt.Errorf("创建会话索引失败: %v", err)
+	}
+	
+	_, err = d.db.Collection("messages").Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "status", Value: 1}, {Key: "created_at", Value: 1}},
+	})
+	if err != nil {
+		return fmt.Errorf("创建消息状态索引失败: %v", err)
+	}
+	
+	_, err = d.db.Collection(model.CollectionGroupReadWatermarks).Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "group_id", Value: 1}, {Key: "user_id", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	if err != nil {
+		return fmt.Errorf("创建群已读水位索引失败: %v", err)
+	}
+	
+	_, err = d.db.Collection("messages").Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "expire_at", Value: 1}},
+		Options: options.Index().SetSparse(true),
+	})
+	if err != nil {
+		return fmt.Errorf("创建消息过期索引失败: %v", err)
+	}
+	
+	_, err = d.db.Collection(model.CollectionConversationSettings).Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "conversation_id", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	if err != nil {
+		return fmt.Errorf("创建会话设置索引失败: %v", err)
+	}
+	
+	_, err = d.db.Collection("messages").Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "group_id", Value: 1}, {Key: "message_id", Value: 1}},
+	})
+	if err != nil {
+		return fmt.Errorf("创建群组消息索引失败: %v", err)
+	}
+	
+	_, err = d.db.Collection(model.CollectionGroupExports).Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "export_id", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	if err != nil {
+		return fmt.Errorf("创建导出记录索引失败: %v", err)
+	}
+	
+	// 状态和创建时间索引用于清理超过保留时间的导出文件
+	_, err = d.db.Collection(model.CollectionGroupExports).Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "status", Value: 1}, {Key: "created_at", Value: 1}},
+	})
+	if err != nil {
+		return fmt.Errorf("创建导出记录状态索引失败: %v", err)
+	}
+	
+	_, err = d.db.Collection(model.CollectionConversationArchives).Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "user_id", Value: 1}, {Key: "conversation_id", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	if err != nil {
+		return fmt.Errorf("创建会话归档索引失败: %v", err)
+	}
+	
+	_, err = d.db.Collection(model.CollectionConversationArchives).Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "conversation_id", Value: 1}},
+	})
+	if err != nil {
+		return fmt.Errorf("创建会话归档会话索引失败: %v", err)
+	}
+	
+	_, err = d.db.Collection("messages").Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "conversation_id", Value: 1}, {Key: "message_id", Value: -1}},
+	})
+	if err != nil {
+		return fmt.Errorf("创建会话消息索引失败: %v", err)
+	}
+	
+	_, err = d.db.Collection("messages").Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "from", Value: 1}, {Key: "conversation_id", Value: 1}}},
+		{Keys: bson.D{{Key: "to", Value: 1}, {Key: "conversation_id", Value: 1}}},
+	})
+	if err != nil {
+		return fmt.Errorf("创建用户会话索引失败: %v", err)
+	}
+	
+	_, err = d.db.Collection("messages").Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "updated_at", Value: 1}, {Key: "_id", Value: 1}},
+	})
+	if err != nil {
+		return fmt.Errorf("创建消息变更时间索引失败: %v", err)
+	}
+	
+	_, err = d.db.Collection("messages").Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "created_at", Value: 1}, {Key: "message_id", Value: 1}},
+	})
+	if err != nil {
+		return fmt.Errorf("创建消息创建时间索引失败: %v", err)
+	}
+	
+	_, err = d.db.Collection(model.CollectionGroupReadWatermarks).Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "updated_at", Value: 1}, {Key: "_id", Value: 1}},
+	})
+	if err != nil {
+		return fmt.Errorf("创建群已读水位变更时间索引失败: %v", err)
+	}
+	
+	_, err = d.db.Collection(model.CollectionMessageTombstones).Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "message_id", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	if err != nil {
+		return fmt.Errorf("创建消息墓碑索引失败: %v", err)
+	}
+	
+	_, err = d.db.Collection(model.CollectionMessageTombstones).Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "deleted_at", Value: 1}},
+		Options: options.Index().SetExpireAfterSeconds(int32(model.SyncRetention / time.Second)),
+	})
+	if err != nil {
+		return fmt.Errorf("创建消息墓碑过期索引失败: %v", err)
+	}
+	
+	_, err = d.db.Collection(model.CollectionPollVotes).Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "poll_id", Value: 1}, {Key: "user_id", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	if err != nil {
+		return fmt.Errorf("创建投票记录索引失败: %v", err)
+	}
+	
+	_, err = d.db.Collection(model.CollectionPollStates).Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "poll_id", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	if err != nil {
+		return fmt.Errorf("创建投票状态索引失败: %v", err)
+	}
+	
+	_, err = d.db.Collection(model.CollectionGroupPinnedMessages).Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "group_id", Value: 1}, {Key: "message_id", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	if err != nil {
+		return fmt.Errorf("创建群置顶消息索引失败: %v", err)
+	}
+	return nil
+}
+
+// SetGroupReadWatermark 推进群成员已读水位，使用$max保证水位只前进不后退，返回更新后的水位
+func (d *mongoDAO) SetGroupReadWatermark(ctx context.Context, groupID, userID, messageID int64) (*model.GroupReadWatermark, error) {
+	collection := d.db.Collection(model.CollectionGroupReadWatermarks)
+	
+	filter := bson.M{"group_id": groupID, "user_id": userID}
+	update := bson.M{
+		"$max": bson.M{"message_id": messageID},
+		"$set": bson.M{"updated_at": time.Now()},
+	}
+	opts := options.FindOneAndUpdate().
+		SetUpsert(true).
+		SetReturnDocument(options.After)
+	
+	var watermark model.GroupReadWatermark
+	if err := collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&watermark); err != nil {
+		return nil, err
+	}
+	return &watermark, nil
+}
+
+// GetGroupReadWatermarks 获取群内所有成员的已读水位，按水位从高到低排列
+func (d *mongoDAO) GetGroupReadWatermarks(ctx context.Context, groupID int64) ([]*model.GroupReadWatermark, error) {
+	collection := d.db.Collection(model.CollectionGroupReadWatermarks)
+	
+	opts := options.Find().SetSort(bson.D{{Key: "message_id", Value: -1}, {Key: "user_id", Value: 1}})
+	cursor, err := collection.Find(ctx, bson.M{"group_id": groupID}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+	
+	var watermarks []*model.GroupReadWatermark
+	if err := cursor.All(ctx, &watermarks); err != nil {
+		return nil, err
+	}
+	return watermarks, nil
+}
+
+// ==================== 消息过期相关方法 ====================
+
+// SetConversationTTL 设置会话默认消息有效期
+func (d *mongoDAO) SetConversationTTL(ctx context.Context, conversationID string, ttl, userID int64) error {
+	collection := d.db.Collection(model.CollectionConversationSettings)
+	
+	update := bson.M{
+		"$set": bson.M{
+			"ttl":        ttl,
+			"updated_by": userID,
+			"updated_at": time.Now(),
+		},
+	}
+	_, err := collection.UpdateOne(ctx, bson.M{"conversation_id": conversationID}, update, options.Update().SetUpsert(true))
+	return err
+}
+
+// GetConversationTTL 获取会话默认消息有效期，未设置时返回0
+func (d *mongoDAO) GetConversationTTL(ctx context.Context, conversationID string) (int64, error) {
+	collection := d.db.Collection(model.CollectionConversationSettings)
+	
+	var setting model.ConversationSetting
+	err := collection.FindOne(ctx, bson.M{"conversation_id": conversationID}).Decode(&setting)
+	if err == mongo.ErrNoDocuments {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return setting.TTL, nil
+}
+
+// FindExpiredMessages 查询已到期的消息，按过期时间从早到晚排列
+func (d *mongoDAO) FindExpiredMessages(ctx context.Context, now time.Time, limit int64) ([]*model.Message, error) {
+	collection := d.db.Collection("messages")
+	
+	opts := options.Find().
+		SetSort(bson.D{{Key: "expire_at", Value: 1}}).
+		SetLimit(limit).
+		SetProjection(bson.M{"message_id": 1, "conversation_id": 1, "from": 1, "to": 1, "group_id": 1, "ttl": 1, "expire_at": 1})
+	cursor, err := collection.Find(ctx, bson.M{"expire_at": bson.M{"$lte": now}}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+	
+	var messages []*model.Message
+	if err := cursor.All(ctx, &messages); err != nil {
+		return nil, err
+	}
+	return messages, nil
+}
+
+// DeleteExpiredMessages 删除已到期的消息并记录墓碑，返回实际删除数，多实例并发清理时只有一个实例删除成功
+func (d *mongoDAO) DeleteExpiredMessages(ctx context.Context, messageIDs []int64, now time.Time) (int64, error) {
+	collection := d.db.Collection("messages")
+	filter := bson.M{
+		"message_id": bson.M{"$in": messageIDs},
+		"expire_at":  bson.M{"$lte": now},
+	}
+	
+	// 先读取会话信息再删除，墓碑按会话参与者同步
+	opts := options.Find().SetProjection(bson.M{"message_id": 1, "conversation_id": 1, "from": 1, "to": 1, "group_id": 1})
+	cursor, err := collection.Find(ctx, filter, opts)
+	if err != nil {
+		return 0, err
+	}
+	var messages []*model.Message
+	if err := cursor.All(ctx, &messages); err != nil {
+		return 0, err
+	}
+	
+	result, err := collection.DeleteMany(ctx, filter)
+	if err != nil {
+		return 0, err
+	}
+	if err := d.saveTombstones(ctx, messages, model.DeletionReasonExpired, now); err != nil {
+		return result.DeletedCount, err
+	}
+	return result.DeletedCount, nil
+}
+
+// SetConversationRetention 设置会话消息保留天数，0表示使用默认保留期
+func (d *mongoDAO) SetConversationRetention(ctx context.Context, conversationID string, days int, userID int64) error {
+	collection := d.db.Collection(model.CollectionConversationSettings)
+	
+	update := bson.M{
+		"$set": bson.M{
+			"retention_days": days,
+			"updated_by":     userID,
+			"updated_at":     time.Now(),
+		},
+	}
+	_, err := collection.UpdateOne(ctx, bson.M{"conversation_id": conversationID}, update, options.Update().SetUpsert(true))
+	return err
+}
+
+// SetParticipantRetention 设置单聊参与者自己的保留天数，0表示清除该参与者的设置
+func (d *mongoDAO) SetParticipantRetention(ctx context.Context, conversationID string, days int, userID int64) error {
+	collection := d.db.Collection(model.CollectionConversationSettings)
+	
+	field := fmt.Sprintf("participant_retention_days.%d", userID)
+	update := bson.M{
+		"$set": bson.M{
+			"updated_by": userID,
+			"updated_at": time.Now(),
+		},
+	}
+	if days > 0 {
+		update["$set"].(bson.M)[field] = days
+	} else {
+		update["$unset"] = bson.M{field: ""}
+	}
+	_, err := collection.UpdateOne(ctx, bson.M{"conversation_id": conversationID}, update, options.Update().SetUpsert(true))
+	return err
+}
+
+// GetConversationRetention 获取会话的保留期设置，未设置时返回nil
+func (d *mongoDAO) GetConversationRetention(ctx context.Context, conversationID string) (*model.ConversationSetting, error) {
+	collection := d.db.Collection(model.CollectionConversationSettings)
+	
+	var setting model.ConversationSetting
+	err := collection.FindOne(ctx, bson.M{"conversation_id": conversationID}).Decode(&setting)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &setting, nil
+}
+
+// retentionSettingFilter 设置了保留期的会话，单聊参与者的设置全部清除后为空文档，同样视为已设置
+func retentionSettingFilter() bson.M {
+	return bson.M{"$or": []bson.M{
+		{"retention_days": bson.M{"$gt": 0}},
+		{"participant_retention_days": bson.M{"$exists": true}},
+	}}
+}
+
+// ListConversationRetentions 分页获取设置了保留期的会话，按会话ID升序，afterConversationID为上一页最后一个会话
+func (d *mongoDAO) ListConversationRetentions(ctx context.Context, afterConversationID string, limit int64) ([]*model.ConversationSetting, error) {
+	collection := d.db.Collection(model.CollectionConversationSettings)
+	
+	filter := retentionSettingFilter()
+	if afterConversationID != "" {
+		filter = bson.M{"$and": []bson.M{filter, {"conversation_id": bson.M{"$gt": afterConversationID}}}}
+	}
+	opts := options.Find().
+		SetSort(bson.D{{Key: "conversation_id", Value: 1}}).
+		SetLimit(limit).
+		SetProjection(bson.M{"conversation_id": 1, "retention_days": 1, "participant_retention_days": 1})
+	cursor, err := collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+	
+	var settings []*model.ConversationSetting
+	if err := cursor.All(ctx, &settings); err != nil {
+		return nil, err
+	}
+	return settings, nil
+}
+
+// FindConversationRetentions 获取指定会话中设置了保留期的会话
+func (d *mongoDAO) FindConversationRetentions(ctx context.Context, conversationIDs []string) ([]*model.ConversationSetting, error) {
+	if len(conversationIDs) == 0 {
+		return nil, nil
+	}
+	collection := d.db.Collection(model.CollectionConversationSettings)
+	
+	filter := bson.M{"$and": []bson.M{retentionSettingFilter(), {"conversation_id": bson.M{"$in": conversationIDs}}}}
+	opts := options.Find().SetProjection(bson.M{"conversation_id": 1, "retention_days": 1, "participant_retention_days": 1})
+	cursor, err := collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+	
+	var settings []*model.ConversationSetting
+	if err := cursor.All(ctx, &settings); err != nil {
+		return nil, err
+	}
+	return settings, nil
+}
+
+// retentionProjection 清理超期消息时读取的字段
+var retentionProjection = bson.M{"message_id": 1, "conversation_id": 1, "from": 1, "to": 1, "group_id": 1, "created_at": 1}
+
+// FindMessagesCreatedBefore 查询会话中早于指定时间创建的消息，按创建时间从早到晚排列
+// 带置顶标记的群置顶消息不受保留期限制，取消置顶后才会被清理
+func (d *mongoDAO) FindMessagesCreatedBefore(ctx context.Context, conversationID string, before time.Time, limit int64) ([]*model.Message, error) {
+	collection := d.db.Collection("messages")
+	
+	filter := bson.M{
+		"conversation_id": conversationID,
+		"created_at":      bson.M{"$lt": before},
+		"pinned":          bson.M{"$ne": true},
+	}
+	opts := options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: 1}}).
+		SetLimit(limit).
+		SetProjection(retentionProjection)
+	cursor, err := collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+	
+	var messages []*model.Message
+	if err := cursor.All(ctx, &messages); err != nil {
+		return nil, err
+	}
+	return messages, nil
+}
+
+// ScanMessagesCreatedBefore 按(创建时间, 消息ID)升序分页查询所有会话中早于指定时间创建的消息，
+// after为上一页的最后一条消息，为nil时从头开始；不含群置顶消息
+func (d *mongoDAO) ScanMessagesCreatedBefore(ctx context.Context, before time.Time, after *model.Message, limit int64) ([]*model.Message, error) {
+	collection := d.db.Collection("messages")
+	
+	filter := bson.M{
+		"created_at": bson.M{"$lt": before},
+		"pinned":     bson.M{"$ne": true},
+	}
+	if after != nil {
+		filter["$or"] = []bson.M{
+			{"created_at": bson.M{"$gt": after.CreatedAt}},
+			{"created_at": after.CreatedAt, "message_id": bson.M{"$gt": after.MessageID}},
+		}
+	}
+	opts := options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: 1}, {Key: "message_id", Value: 1}}).
+		SetLimit(limit).
+		SetProjection(retentionProjection)
+	cursor, err := collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+	
+	var messages []*model.Message
+	if err := cursor.All(ctx, &messages); err != nil {
+		return nil, err
+	}
+	return messages, nil
+}
+
+// DeleteMessagesCreatedBefore 删除超过保留期的消息并记录墓碑，返回实际删除数
+func (d *mongoDAO) DeleteMessagesCreatedBefore(ctx context.Context, messageIDs []int64, before, now time.Time) (int64, error) {
+	collection := d.db.Collection("messages")
+	// 查询后被置顶的消息不删除
+	filter := bson.M{
+		"message_id": bson.M{"$in": messageIDs},
+		"created_at": bson.M{"$lt": before},
+		"pinned":     bson.M{"$ne": true},
+	}
+	
+	// 先读取会话信息再删除，墓碑按会话参与者同步
+	opts := options.Find().SetProjection(bson.M{"message_id": 1, "conversation_id": 1, "from": 1, "to": 1, "group_id": 1})
+	cursor, err := collection.Find(ctx, filter, opts)
+	if err != nil {
+		return 0, err
+	}
+	var messages []*model.Message
+	if err := cursor.All(ctx, &messages); err != nil {
+		return 0, err
+	}
+	
+	result, err := collection.DeleteMany(ctx, filter)
+	if err != nil {
+		return 0, err
+	}
+	if err := d.saveTombstones(ctx, messages, model.DeletionReasonRetention, now); err != nil {
+		return result.DeletedCount, err
+	}
+	return result.DeletedCount, nil
+}
+
+// saveTombstones 记录已删除消息的墓碑，同一消息只记录一次，并发删除时重复写入不会产生多条
+func (d *mongoDAO) saveTombstones(ctx context.Context, messages []*model.Message, reason string, deletedAt time.Time) error {
+	if len(messages) == 0 {
+		return nil
+	}
+	
+	models := make([]mongo.WriteModel, 0, len(messages))
+	for _, message := range messages {
+		models = append(models, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"message_id": message.MessageID}).
+			SetUpdate(bson.M{"$setOnInsert": model.MessageTombstone{
+				MessageID:      message.MessageID,
+				ConversationID: message.ConversationID,
+				From:           message.From,
+				To:             message.To,
+				GroupID:        message.GroupID,
+				Reason:         reason,
+				CreatedAt:      message.CreatedAt,
+				DeletedAt:      deletedAt,
+			}}).
+			SetUpsert(true))
+	}
+	_, err := d.db.Collection(model.CollectionMessageTombstones).BulkWrite(ctx, models, options.BulkWrite().SetOrdered(false))
+	if err != nil {
+		return fmt.Errorf("记录消息墓碑失败: %v", err)
+	}
+	return nil
+}
+
+// ==================== 增量同步相关方法 ====================
+
+// participantFilter 用户参与的单聊和在群期间的群聊消息的范围
+func participantFilter(userID int64, windows []model.VisibleWindow) bson.M {
+	participants := []bson.M{
+		{"group_id": 0, "from": userID},
+		{"group_id": 0, "to": userID},
+	}
+	for _, window := range windows {
+		participant := bson.M{"group_id": window.GroupID}
+		if createdAt := windowRange(window); createdAt != nil {
+			participant["created_at"] = createdAt
+		}
+		participants = append(participants, participant)
+	}
+	return bson.M{"$or": participants}
+}
+
+// windowRange 可见窗口对应的创建时间范围，窗口不限时返回nil
+func windowRange(window model.VisibleWindow) bson.M {
+	cond := bson.M{}
+	if !window.Start.IsZero() {
+		cond["$gte"] = window.Start
+	}
+	if !window.End.IsZero() {
+		cond["$lte"] = window.End
+	}
+	if len(cond) == 0 {
+		return nil
+	}
+	return cond
+}
+
+// changedSinceFilter 变更时间字段在after之后、until及之前的范围，同一毫秒内按_id继续
+func changedSinceFilter(field string, after model.SyncPosition, until time.Time) (bson.M, error) {
+	since := after.After()
+	window := bson.M{field: bson.M{"$gt": since, "$lte": until}}
+	if after.ID == "" {
+		return window, nil
+	}
+	afterID, err := primitive.ObjectIDFromHex(after.ID)
+	if err != nil {
+		return nil, err
+	}
+	return bson.M{"$or": []bson.M{
+		window,
+		{field: since, "_id": bson.M{"$gt": afterID}},
+	}}, nil
+}
+
+// findChangedSince 按(变更时间, _id)升序查询范围内的变更
+func (d *mongoDAO) findChangedSince(ctx context.Context, collection, field string, scope bson.M, after model.SyncPosition, until time.Time, limit int64, results interface{}) error {
+	changed, err := changedSinceFilter(field, after, until)
+	if err != nil {
+		return err
+	}
+	opts := options.Find().
+		SetSort(bson.D{{Key: field, Value: 1}, {Key: "_id", Value: 1}}).
+		SetLimit(limit)
+	cursor, err := d.db.Collection(collection).Find(ctx, bson.M{"$and": []bson.M{scope, changed}}, opts)
+	if err != nil {
+		return err
+	}
+	return cursor.All(ctx, results)
+}
+
+// FindMessagesChangedSince 查询用户会话中新增或状态变化的消息
+func (d *mongoDAO) FindMessagesChangedSince(ctx context.Context, userID int64, windows []model.VisibleWindow, after model.SyncPosition, until time.Time, limit int64) ([]*model.Message, error) {
+	var messages []*model.Message
+	if err := d.findChangedSince(ctx, "messages", "updated_at", participantFilter(userID, windows), after, until, limit, &messages); err != nil {
+		return nil, err
+	}
+	return messages, nil
+}
+
+// FindTombstonesSince 查询用户会话中已删除消息的墓碑
+func (d *mongoDAO) FindTombstonesSince(ctx context.Context, userID int64, windows []model.VisibleWindow, after model.SyncPosition, until time.Time, limit int64) ([]*model.MessageTombstone, error) {
+	var tombstones []*model.MessageTombstone
+	if err := d.findChangedSince(ctx, model.CollectionMessageTombstones, "deleted_at", participantFilter(userID, windows), after, until, limit, &tombstones); err != nil {
+		return nil, err
+	}
+	return tombstones, nil
+}
+
+// FindGroupReadWatermarksSince 查询群组成员已读水位的变化
+func (d *mongoDAO) FindGroupReadWatermarksSince(ctx context.Context, groupIDs []int64, after model.SyncPosition, until time.Time, limit int64) ([]*model.GroupReadWatermark, error) {
+	if len(groupIDs) == 0 {
+		return nil, nil
+	}
+	var watermarks []*model.GroupReadWatermark
+	scope := bson.M{"group_id": bson.M{"$in": groupIDs}}
+	if err := d.findChangedSince(ctx, model.CollectionGroupReadWatermarks, "updated_at", scope, after, until, limit, &watermarks); err != nil {
+		return nil, err
+	}
+	return watermarks, nil
+}
+
+// groupExportFilter 群聊记录导出的消息范围，不含已撤回和已到期的消息
+func groupExportFilter(groupID int64, startTime, endTime time.Time) bson.M {
+	return bson.M{
+		"group_id":   groupID,
+		"created_at": bson.M{"$gte": startTime, "$lte": endTime},
+		"status":     bson.M{"$ne": model.MessageStatusRevoked},
+		"expire_at":  bson.M{"$not": bson.M{"$lte": time.Now()}},
+	}
+}
+
+// CountGroupMessages 统计群组时间范围内可导出的消息数
+func (d *mongoDAO) CountGroupMessages(ctx context.Context, groupID int64, startTime, endTime time.Time) (int64, error) {
+	return d.db.Collection("messages").CountDocuments(ctx, groupExportFilter(groupID, startTime, endTime))
+}
+
+// FindGroupMessagesAfter 按消息ID升序分批读取群组时间范围内的消息，afterMessageID为上一批最后一条消息的ID
+func (d *mongoDAO) FindGroupMessagesAfter(ctx context.Context, groupID int64, startTime, endTime time.Time, afterMessageID, limit int64) ([]*model.Message, error) {
+	collection := d.db.Collection("messages")
+	
+	filter := groupExportFilter(groupID, startTime, endTime)
+	filter["message_id"] = bson.M{"$gt": afterMessageID}
+	opts := options.Find().
+		SetSort(bson.D{{Key: "message_id", Value: 1}}).
+		SetLimit(limit)
+	cursor, err := collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+	
+	var messages []*model.Message
+	if err := cursor.All(ctx, &messages); err != nil {
+		return nil, err
+	}
+	return messages, nil
+}
+
+// CreateGroupExport 创建导出记录
+func (d *mongoDAO) CreateGroupExport(ctx context.Context, export *model.GroupExport) error {
+	_, err := d.db.Collection(model.CollectionGroupExports).InsertOne(ctx, export)
+	return err
+}
+
+// FinishGroupExport 更新导出结果
+func (d *mongoDAO) FinishGroupExport(ctx context.Context, export *model.GroupExport) error {
+	_, err := d.db.Collection(model.CollectionGroupExports).UpdateOne(ctx,
+		bson.M{"export_id": export.ExportID},
+		bson.M{"$set": bson.M{
+			"status":        export.Status,
+			"message_count": export.MessageCount,
+			"file_path":     export.FilePath,
+			"error":         export.Error,
+			"completed_at":  export.CompletedAt,
+		}},
+	)
+	return err
+}
+
+// GetGroupExport 获取导出记录
+func (d *mongoDAO) GetGroupExport(ctx context.Context, exportID string) (*model.GroupExport, error) {
+	var export model.GroupExport
+	if err := d.db.Collection(model.CollectionGroupExports).FindOne(ctx, bson.M{"export_id": exportID}).Decode(&export); err != nil {
+		return nil, notFound(err)
+	}
+	return &export, nil
+}
+
+// FindGroupExportsCreatedBefore 查询指定状态、创建时间早于before的导出记录，按创建时间从早到晚排列
+func (d *mongoDAO) FindGroupExportsCreatedBefore(ctx context.Context, status string, before time.Time, limit int64) ([]*model.GroupExport, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}}).SetLimit(limit)
+	cursor, err := d.db.Collection(model.CollectionGroupExports).Find(ctx,
+		bson.M{"status": status, "created_at": bson.M{"$lt": before}}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+	
+	var exports []*model.GroupExport
+	if err := cursor.All(ctx, &exports); err != nil {
+		return nil, err
+	}
+	return exports, nil
+}
+
+// ==================== 会话列表与归档相关方法 ====================
+
+// conversationHead 会话最后一条消息的ID
+type conversationHead struct {
+	ConversationID string `bson:"_id"`
+	MessageID      int64  `bson:"message_id"`
+}
+
+// userPrivateConversationIDs 用户参与的单聊会话ID，按(from, conversation_id)和(to, conversation_id)索引去重扫描
+func (d *mongoDAO) userPrivateConversationIDs(ctx context.Context, userID int64) ([]string, error) {
+	collection := d.db.Collection("messages")
+	
+	seen := make(map[string]bool)
+	var conversationIDs []string
+	for _, field := range []string{"from", "to"} {
+		values, err := collection.Distinct(ctx, "conversation_id", bson.M{field: userID})
+		if err != nil {
+			return nil, err
+		}
+		for _, value := range values {
+			conversationID, ok := value.(string)
+			if !ok || seen[conversationID] {
+				continue
+			}
+			// 群消息同样带有from，只保留单聊会话
+			if conv, err := conversation.Parse(conversationID); err != nil || conv.Type != conversation.TypePrivate {
+				continue
+			}
+			seen[conversationID] = true
+			conversationIDs = append(conversationIDs, conversationID)
+		}
+	}
+	return conversationIDs, nil
+}
+
+// latestConversationHeads 取每个会话最后一条消息的ID，会话按最后一条消息从新到旧排列
+// 按会话排序后分组取第一条，由(conversation_id, message_id)索引逐会话定位，不扫描会话内的历史消息
+func (d *mongoDAO) latestConversationHeads(ctx context.Context, conversationIDs []string) ([]conversationHead, error) {
+	collection := d.db.Collection("messages")
+	
+	var heads []conversationHead
+	for start := 0; start < len(conversationIDs); start += model.ConversationHeadBatchSize {
+		batch := conversationIDs[start:min(start+model.ConversationHeadBatchSize, len(conversationIDs))]
+		pipeline := mongo.Pipeline{
+			{{Key: "$match", Value: bson.M{"conversation_id": bson.M{"$in": batch}}}},
+			{{Key: "$sort", Value: bson.D{{Key: "conversation_id", Value: 1}, {Key: "message_id", Value: -1}}}},
+			{{Key: "$group", Value: bson.M{"_id": "$conversation_id", "message_id": bson.M{"$first": "$message_id"}}}},
+		}
+		cursor, err := collection.Aggregate(ctx, pipeline)
+		if err != nil {
+			return nil, err
+		}
+		var batchHeads []conversationHead
+		err = cursor.All(ctx, &batchHeads)
+		cursor.Close(ctx)
+		if err != nil {
+			return nil, err
+		}
+		heads = append(heads, batchHeads...)
+	}
+	
+	sort.Slice(heads, func(i, j int) bool { return heads[i].MessageID > heads[j].MessageID })
+	return heads, nil
+}
+
+// latestConversationMessages 获取会话的最后一条消息，跳过exclude中的会话，会话按最后一条消息从新到旧排列
+// 先按索引取各会话最后一条消息的ID并分页，只读取当前页的消息；最后一条消息已到期尚未清理的会话不返回
+func (d *mongoDAO) latestConversationMessages(ctx context.Context, conversationIDs []string, exclude map[string]bool, offset, limit int64) ([]*model.Message, error) {
+	heads, err := d.latestConversationHeads(ctx, conversationIDs)
+	if err != nil {
+		return nil, err
+	}
+	
+	page := make([]bson.M, 0, len(heads))
+	var skipped int64
+	for _, head := range heads {
+		if exclude[head.ConversationID] {
+			continue
+		}
+		if skipped < offset {
+			skipped++
+			continue
+		}
+		if limit > 0 && int64(len(page)) >= limit {
+			break
+		}
+		page = append(page, bson.M{"conversation_id": head.ConversationID, "message_id": head.MessageID})
+	}
+	if len(page) == 0 {
+		return nil, nil
+	}
+	
+	collection := d.db.Collection("messages")
+	filter := bson.M{
+		"$or":       page,
+		"expire_at": bson.M{"$not": bson.M{"$lte": time.Now()}},
+	}
+	opts := options.Find().
+		SetSort(bson.D{{Key: "message_id", Value: -1}}).
+		SetLimit(int64(len(page)))
+	cursor, err := collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+	
+	var messages []*model.Message
+	if err := cursor.All(ctx, &messages); err != nil {
+		return nil, err
+	}
+	return messages, nil
+}
+
+// GetLatestConversationMessages 获取用户参与的单聊和所在群聊的最后一条消息，跳过excludeIDs中的会话
+func (d *mongoDAO) GetLatestConversationMessages(ctx context.Context, userID int64, groupIDs []int64, excludeIDs []string, offset, limit int64) ([]*model.Message, error) {
+	conversationIDs, err := d.userPrivateConversationIDs(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, groupID := range groupIDs {
+		conversationIDs = append(conversationIDs, conversation.Group(groupID))
+	}
+	
+	exclude := make(map[string]bool, len(excludeIDs))
+	for _, conversationID := range excludeIDs {
+		exclude[conversationID] = true
+	}
+	return d.latestConversationMessages(ctx, conversationIDs, exclude, offset, limit)
+}
+
+// GetLatestMessagesByConversation 获取指定会话的最后一条消息
+func (d *mongoDAO) GetLatestMessagesByConversation(ctx context.Context, conversationIDs []string) ([]*model.Message, error) {
+	if len(conversationIDs) == 0 {
+		return nil, nil
+	}
+	return d.latestConversationMessages(ctx, conversationIDs, nil, 0, int64(len(conversationIDs)))
+}
+
+// ArchiveConversation 归档会话，重复归档保留首次归档时间
+func (d *mongoDAO) ArchiveConversation(ctx context.Context, userID int64, conversationID string) (*model.ConversationArchive, error) {
+	collection := d.db.Collection(model.CollectionConversationArchives)
+	
+	filter := bson.M{"user_id": userID, "conversation_id": conversationID}
+	update := bson.M{"$setOnInsert": bson.M{"archived_at": time.Now()}}
+	opts := options.FindOneAndUpdate().
+		SetUpsert(true).
+		SetReturnDocument(options.After)
+	
+	var archive model.ConversationArchive
+	if err := collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&archive); err != nil {
+		return nil, err
+	}
+	return &archive, nil
+}
+
+// UnarchiveConversation 取消归档会话，返回会话此前是否已归档
+func (d *mongoDAO) UnarchiveConversation(ctx context.Context, userID int64, conversationID string) (bool, error) {
+	result, err := d.db.Collection(model.CollectionConversationArchives).DeleteOne(ctx, bson.M{
+		"user_id":         userID,
+		"conversation_id": conversationID,
+	})
+	if err != nil {
+		return false, err
+	}
+	return result.DeletedCount > 0, nil
+}
+
+// UnarchiveForRecipients 会话收到新消息时为除发送者外的参与者取消归档，返回取消归档的用户数
+func (d *mongoDAO) UnarchiveForRecipients(ctx context.Context, conversationID string, senderID int64) (int64, error) {
+	result, err := d.db.Collection(model.CollectionConversationArchives).DeleteMany(ctx, bson.M{
+		"conversation_id": conversationID,
+		"user_id":         bson.M{"$ne": senderID},
+	})
+	if err != nil {
+		return 0, err
+	}
+	return result.DeletedCount, nil
+}
+
+// GetConversationArchives 获取用户的会话归档记录，按归档时间从新到旧排列，limit为0时不限制
+func (d *mongoDAO) GetConversationArchives(ctx context.Context, userID int64, limit int64) ([]*model.ConversationArchive, error) {
+	collection := d.db.Collection(model.CollectionConversationArchives)
+	
+	opts := options.Find().
+		SetSort(bson.D{{Key: "archived_at", Value: -1}}).
+		SetLimit(limit)
+	cursor, err := collection.Find(ctx, bson.M{"user_id": userID}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+	
+	var archives []*model.ConversationArchive
+	if err := cursor.All(ctx, &archives); err != nil {
+		return nil, err
+	}
+	return archives, nil
+}
+
+// FindConversationMessagesBefore 按消息ID倒序分批读取会话内的消息，beforeMessageID为0时从最新的消息开始
+// 已撤回、已过期和存储策略为不参与搜索的消息不返回；内容可能加密，关键词由调用方解密后匹配
+func (d *mongoDAO) FindConversationMessagesBefore(ctx context.Context, query *model.ConversationSearchQuery, beforeMessageID, limit int64) ([]*model.Message, error) {
+	collection := d.db.Collection("messages")
+	
+	filter := bson.M{
+		"conversation_id": query.ConversationID,
+		"status":          bson.M{"$ne": model.MessageStatusRevoked},
+		"expire_at":       bson.M{"$not": bson.M{"$lte": time.Now()}},
+		"unindexed":       bson.M{"$ne": true},
+	}
+	if beforeMessageID > 0 {
+		filter["message_id"] = bson.M{"$lt": beforeMessageID}
+	}
+	if query.SenderID > 0 {
+		filter["from"] = query.SenderID
+	}
+	createdAt := bson.M{}
+	if !query.StartTime.IsZero() {
+		createdAt["$gte"] = query.StartTime
+	}
+	if !query.EndTime.IsZero() {
+		createdAt["$lte"] = query.EndTime
+	}
+	if len(createdAt) > 0 {
+		filter["created_at"] = createdAt
+	}
+	// 群聊只匹配搜索者在群期间的消息
+	if query.Windows != nil {
+		windows := bson.A{}
+		for _, window := range query.Windows {
+			cond := windowRange(window)
+			if cond == nil {
+				windows = append(windows, bson.M{})
+				continue
+			}
+			windows = append(windows, bson.M{"created_at": cond})
+		}
+		filter["$or"] = windows
+	}
+	
+	opts := options.Find().
+		SetSort(bson.D{{Key: "message_id", Value: -1}}).
+		SetLimit(limit)
+	cursor, err := collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+	
+	var messages []*model.Message
+	if err := cursor.All(ctx, &messages); err != nil {
+		return nil, err
+	}
+	return messages, nil
+}
+
+// ==================== 群投票相关方法 ====================
+
+// GetPollVote 获取成员在投票中的投票记录，未投票时返回nil
+func (d *mongoDAO) GetPollVote(ctx context.Context, pollID, userID int64) (*model.PollVote, error) {
+	collection := d.db.Collection(model.CollectionPollVotes)
+	
+	var vote model.PollVote
+	if err := collection.FindOne(ctx, bson.M{"poll_id": pollID, "user_id": userID}).Decode(&vote); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &vote, nil
+}
+
+// ReplacePollVote 以之前的选择为条件保存成员的投票，(poll_id, user_id)唯一
+// previous为nil表示之前未投票，只在没有记录时插入；之前的选择已被并发投票改变时返回false
+func (d *mongoDAO) ReplacePollVote(ctx context.Context, vote *model.PollVote, previous []int) (bool, error) {
+	collection := d.db.Collection(model.CollectionPollVotes)
+	
+	if previous == nil {
+		_, err := collection.InsertOne(ctx, vote)
+		if err != nil {
+			if mongo.IsDuplicateKeyError(err) {
+				return false, nil
+			}
+			return false, err
+		}
+		return true, nil
+	}
+	
+	filter := bson.M{"poll_id": vote.PollID, "user_id": vote.UserID, "choices": previous}
+	update := bson.M{
+		"$set": bson.M{
+			"choices":  vote.Choices,
+			"voted_at": vote.VotedAt,
+		},
+	}
+	result, err := collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return false, err
+	}
+	return result.MatchedCount > 0, nil
+}
+
+// GetPollVotesExcluding 获取投票中不属于指定用户的投票记录，用于从计数中扣除已退群成员的投票
+func (d *mongoDAO) GetPollVotesExcluding(ctx context.Context, pollID int64, userIDs []int64) ([]*model.PollVote, error) {
+	collection := d.db.Collection(model.CollectionPollVotes)
+	
+	cursor, err := collection.Find(ctx, bson.M{"poll_id": pollID, "user_id": bson.M{"$nin": userIDs}})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+	
+	var votes []*model.PollVote
+	if err := cursor.All(ctx, &votes); err != nil {
+		return nil, err
+	}
+	return votes, nil
+}
+
+// AddPollCounts 以$inc增减各选项票数和投票人数，delta的键为选项下标
+// requireOpen为true时只更新进行中的投票，投票已结束时返回false，保证结束后的票数不再被新投票改变
+func (d *mongoDAO) AddPollCounts(ctx context.Context, pollID, groupID int64, delta map[int]int64, voterDelta int64, requireOpen bool) (bool, error) {
+	collection := d.db.Collection(model.CollectionPollStates)
+	
+	inc := bson.M{}
+	for choice, n := range delta {
+		if n != 0 {
+			inc[fmt.Sprintf("counts.%d", choice)] = n
+		}
+	}
+	if voterDelta != 0 {
+		inc["voter_count"] = voterDelta
+	}
+	if len(inc) == 0 {
+		return true, nil
+	}
+	
+	filter := bson.M{"poll_id": pollID}
+	if requireOpen {
+		filter["closed"] = bson.M{"$ne": true}
+	}
+	update := bson.M{
+		"$inc":         inc,
+		"$setOnInsert": bson.M{"group_id": groupID},
+	}
+	// 已结束的投票不匹配过滤条件，upsert插入时与唯一索引冲突
+	_, err := collection.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+	if err != nil {
+		if requireOpen && mongo.IsDuplicateKeyError(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// GetPollState 获取投票状态，没有记录时返回nil表示投票进行中
+func (d *mongoDAO) GetPollState(ctx context.Context, pollID int64) (*model.PollState, error) {
+	collection := d.db.Collection(model.CollectionPollStates)
+	
+	var state model.PollState
+	if err := collection.FindOne(ctx, bson.M{"poll_id": pollID}).Decode(&state); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &state, nil
+}
+
+// ClosePoll 结束投票，只有进行中的投票会被更新，投票已结束时返回false
+func (d *mongoDAO) ClosePoll(ctx context.Context, state *model.PollState) (bool, error) {
+	collection := d.db.Collection(model.CollectionPollStates)
+	
+	filter := bson.M{"poll_id": state.PollID, "closed": bson.M{"$ne": true}}
+	update := bson.M{
+		"$set": bson.M{
+			"group_id":  state.GroupID,
+			"closed":    true,
+			"closed_by": state.ClosedBy,
+			"closed_at": state.ClosedAt,
+		},
+	}
+	// 已结束的投票不匹配过滤条件，upsert插入时与唯一索引冲突
+	_, err := collection.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// PinGroupMessage 置顶群消息，(group_id, message_id)唯一，消息已置顶时不改变原置顶记录并返回false
+func (d *mongoDAO) PinGroupMessage(ctx context.Context, pin *model.GroupPinnedMessage) (bool, error) {
+	collection := d.db.Collection(model.CollectionGroupPinnedMessages)
+	
+	filter := bson.M{"group_id": pin.GroupID, "message_id": pin.MessageID}
+	setOnInsert := bson.M{
+		"position":  pin.Position,
+		"pinned_by": pin.PinnedBy,
+		"pinned_at": pin.PinnedAt,
+	}
+	if pin.AnnouncementID > 0 {
+		setOnInsert["announcement_id"] = pin.AnnouncementID
+	}
+	update := bson.M{"$setOnInsert": setOnInsert}
+	result, err := collection.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+	if err != nil {
+		// 并发置顶同一条消息时upsert与唯一索引冲突，视为已置顶
+		if mongo.IsDuplicateKeyError(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return result.UpsertedCount > 0, nil
+}
+
+// UnpinGroupMessage 取消置顶群消息，消息未置顶时返回false
+func (d *mongoDAO) UnpinGroupMessage(ctx context.Context, groupID, messageID int64) (bool, error) {
+	collection := d.db.Collection(model.CollectionGroupPinnedMessages)
+	
+	result, err := collection.DeleteOne(ctx, bson.M{"group_id": groupID, "message_id": messageID})
+	if err != nil {
+		return false, err
+	}
+	return result.DeletedCount > 0, nil
+}
+
+// SetGroupPinPositions 批量设置群置顶消息的排序位置，positions的键为消息ID
+func (d *mongoDAO) SetGroupPinPositions(ctx context.Context, groupID int64, positions map[int64]int64) error {
+	if len(positions) == 0 {
+		return nil
+	}
+	
+	models := make([]mongo.WriteModel, 0, len(positions))
+	for messageID, position := range positions {
+		models = append(models, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"group_id": groupID, "message_id": messageID}).
+			SetUpdate(bson.M{"$set": bson.M{"position": position}}))
+	}
+	_, err := d.db.Collection(model.CollectionGroupPinnedMessages).BulkWrite(ctx, models, options.BulkWrite().SetOrdered(false))
+	return err
+}
+
+// SetMessagePinned 设置消息文档上的置顶标记，保留期清理据此跳过置顶消息
+func (d *mongoDAO) SetMessagePinned(ctx context.Context, messageID int64, pinned bool) error {
+	collection := d.db.Collection("messages")
+	
+	update := bson.M{"$set": bson.M{"pinned": true}}
+	if !pinned {
+		update = bson.M{"$unset": bson.M{"pinned": ""}}
+	}
+	_, err := collection.UpdateOne(ctx, bson.M{"message_id": messageID}, update)
+	return err
+}
+
+// GetGroupPinnedMessages 获取群的置顶消息记录，按排序位置从前到后，位置相同时按置顶时间从新到旧
+func (d *mongoDAO) GetGroupPinnedMessages(ctx context.Context, groupID int64) ([]*model.GroupPinnedMessage, error) {
+	collection := d.db.Collection(model.CollectionGroupPinnedMessages)
+	
+	opts := options.Find().SetSort(bson.D{{Key: "position", Value: -1}, {Key: "pinned_at", Value: -1}, {Key: "message_id", Value: -1}})
+	cursor, err := collection.Find(ctx, bson.M{"group_id": groupID}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+	
+	var pins []*model.GroupPinnedMessage
+	if err := cursor.All(ctx, &pins); err != nil {
+		return nil, err
+	}
+	return pins, nil
+}
@@ -40,3 +40,13 @@ func (g *GRPCHandler) GetHistoryMessages(ctx context.Context, req *rest.GetHisto
 func (g *GRPCHandler) MarkMessagesAsRead(ctx context.Context, req *rest.MarkMessagesReadRequest) (*rest.MarkMessagesReadResponse, error) {
 	return g.markMessagesAsReadImpl(ctx, req)
 }
+
+// GetDeliveryStatus 获取投递链路状态
+func (g *GRPCHandler) GetDeliveryStatus(ctx context.Context, req *rest.GetDeliveryStatusRequest) (*rest.GetDeliveryStatusResponse, error) {
+	status, err := g.service.GetDeliveryStatus(ctx)
+	if err != nil {
+		g.logger.Error(ctx, "获取投递链路状态失败", logger.F("error", err.Error()))
+		return g.converter.BuildErrorGetDeliveryStatusResponse(err.Error()), nil
+	}
+	return g.converter.BuildGetDeliveryStatusResponse(status), nil
+}
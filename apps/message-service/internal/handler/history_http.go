@@ -7,6 +7,7 @@ import (
 	"github.com/gin-gonic/gin"
 
 	"goim-social/api/rest"
+	"goim-social/apps/message-service/internal/service"
 	tracecontext "goim-social/pkg/context"
 	"goim-social/pkg/httpx"
 	"goim-social/pkg/logger"
@@ -95,7 +96,8 @@ func (h *HTTPHandler) GetUserHistory(c *gin.Context) {
 	// 需要将字符串时间转换为time.Time，这里简化处理
 	startTime, _ := time.Parse(time.RFC3339, req.StartTime)
 	endTime, _ := time.Parse(time.RFC3339, req.EndTime)
-	actions, total, err := h.service.GetUserHistory(ctx, req.UserId, req.ActionType.String(), req.ObjectType.String(), startTime, endTime, req.Page, req.PageSize)
+	page, pageSize := service.UserHistoryPage(req.Page, req.PageSize)
+	actions, total, err := h.service.GetUserHistory(ctx, req.UserId, req.ActionType.String(), req.ObjectType.String(), startTime, endTime, page, pageSize)
 	if err != nil {
 		h.logger.Error(ctx, "Get user history failed",
 			logger.F("error", err.Error()),
@@ -105,7 +107,7 @@ func (h *HTTPHandler) GetUserHistory(c *gin.Context) {
 		h.logger.Info(ctx, "Get user history successful",
 			logger.F("userID", req.UserId),
 			logger.F("total", total))
-		resp = h.converter.BuildGetUserHistoryResponse(actions, total, req.Page, req.PageSize)
+		resp = h.converter.BuildGetUserHistoryResponse(actions, total, page, pageSize)
 	}
 
 	httpx.WriteObject(c, resp, err)
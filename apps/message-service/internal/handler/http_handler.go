@@ -29,10 +29,36 @@ func (h *HTTPHandler) RegisterRoutes(r *gin.Engine) {
 	// 消息相关路由
 	messages := r.Group("/api/v1/messages")
 	{
-		messages.POST("/history", h.GetHistory)         // 获取历史消息
-		messages.POST("/unread", h.GetUnreadMessages)   // 获取未读消息
-		messages.POST("/mark-read", h.MarkMessagesRead) // 标记消息已读
-		messages.POST("/send", h.SendMessage)           // 特殊场景下的短连接消息，如测试、某些网络环境下的备用通道
+		messages.POST("/history", h.GetHistory)                                  // 获取历史消息
+		messages.POST("/unread", h.GetUnreadMessages)                            // 获取未读消息
+		messages.POST("/sync", h.SyncSince)                                      // 增量同步所有会话的变更
+		messages.POST("/mark-read", h.MarkMessagesRead)                          // 标记消息已读
+		messages.POST("/send", h.SendMessage)                                    // 特殊场景下的短连接消息，如测试、某些网络环境下的备用通道
+		messages.POST("/group-stats", h.GetGroupStats)                           // 获取群组活跃度统计
+		messages.POST("/group-read-watermark", h.SetGroupReadWatermark)          // 设置群消息已读水位
+		messages.POST("/group-read-status", h.GetGroupReadStatus)                // 获取群消息已读状态
+		messages.POST("/message-read-status", h.GetMessageReadStatus)            // 获取群消息投递和已读汇总及明细
+		messages.POST("/conversation-ttl", h.SetConversationTTL)                 // 设置会话默认消息有效期
+		messages.POST("/conversation-retention", h.GetConversationRetention)     // 获取会话消息保留期
+		messages.POST("/conversation-retention/set", h.SetConversationRetention) // 设置会话消息保留期
+		messages.POST("/delivery-status", h.GetDeliveryStatus)                   // 获取投递链路状态
+		messages.POST("/group-export", h.ExportGroupHistory)                     // 导出群聊记录
+		messages.POST("/group-export/status", h.GetGroupExport)                  // 查询群聊记录导出进度
+		messages.POST("/group-export/download", h.DownloadGroupExport)           // 下载群聊记录导出文件
+		messages.POST("/conversations", h.GetConversations)                      // 获取会话列表，默认不含已归档会话
+		messages.POST("/conversations/archive", h.ArchiveConversation)           // 归档会话
+		messages.POST("/conversations/unarchive", h.UnarchiveConversation)       // 取消归档会话
+		messages.POST("/conversations/archived", h.ListArchivedConversations)    // 获取已归档会话
+		messages.POST("/conversations/search", h.SearchConversationMessages)     // 会话内搜索消息
+		messages.POST("/digest-mode", h.GetDigestMode)                           // 获取通知摘要设置
+		messages.POST("/digest-mode/set", h.SetDigestMode)                       // 设置通知摘要模式
+		messages.POST("/polls/get", h.GetPoll)                                   // 获取群投票结果
+		messages.POST("/polls/vote", h.VotePoll)                                 // 群投票
+		messages.POST("/polls/close", h.ClosePoll)                               // 结束群投票
+		messages.POST("/group-highlights", h.GetGroupHighlights)                 // 获取群亮点（生效公告和置顶消息）
+		messages.POST("/group-pins/pin", h.PinGroupMessage)                      // 置顶群消息
+		messages.POST("/group-pins/unpin", h.UnpinGroupMessage)                  // 取消置顶群消息
+		messages.POST("/group-pins/reorder", h.ReorderGroupPins)                 // 调整群置顶消息顺序
 	}
 
 	// 历史记录相关路由
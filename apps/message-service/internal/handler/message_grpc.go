@@ -82,7 +82,7 @@ func (g *GRPCHandler) markMessagesAsReadImpl(ctx context.Context, req *rest.Mark
 		logger.F("messageIDs", req.MessageIds))
 
 	// 调用service层标记消息已读
-	failedIDs, err := g.service.MarkMessagesAsRead(ctx, req.UserId, req.MessageIds)
+	readMessages, failedIDs, err := g.service.MarkMessagesAsRead(ctx, req.UserId, req.MessageIds)
 
 	response := &rest.MarkMessagesReadResponse{
 		Success: err == nil && len(failedIDs) == 0,
@@ -96,6 +96,7 @@ func (g *GRPCHandler) markMessagesAsReadImpl(ctx context.Context, req *rest.Mark
 			return "标记已读成功"
 		}(),
 		FailedIds: failedIDs,
+		Receipts:  g.converter.ReadReceiptsToProto(readMessages),
 	}
 
 	if err != nil {
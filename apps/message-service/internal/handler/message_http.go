@@ -1,9 +1,12 @@
 package handler
 
 import (
+	"fmt"
+
 	"github.com/gin-gonic/gin"
 
 	"goim-social/api/rest"
+	"goim-social/apps/message-service/internal/model"
 	tracecontext "goim-social/pkg/context"
 	"goim-social/pkg/httpx"
 	"goim-social/pkg/logger"
@@ -93,12 +96,42 @@ func (h *HTTPHandler) GetUnreadMessages(c *gin.Context) {
 	// 将业务信息添加到context
 	ctx = tracecontext.WithUserID(ctx, req.UserId)
 
-	msgs, err := h.service.GetUnreadMessages(ctx, req.UserId)
+	page, err := h.service.GetUnreadMessages(ctx, req.UserId, req.Cursor, req.Limit)
 	if err != nil {
 		h.logger.Error(ctx, "Get unread messages failed", logger.F("error", err.Error()))
 		resp = h.converter.BuildErrorGetUnreadMessagesResponse(err.Error())
 	} else {
-		resp = h.converter.BuildSuccessGetUnreadMessagesResponse(msgs)
+		resp = h.converter.BuildSuccessGetUnreadMessagesResponse(page)
+	}
+
+	httpx.WriteObject(c, resp, err)
+}
+
+// SyncSince 增量同步用户所有会话自游标以来的变更
+func (h *HTTPHandler) SyncSince(c *gin.Context) {
+	var (
+		ctx  = c.Request.Context()
+		req  rest.SyncSinceRequest
+		resp *rest.SyncSinceResponse
+		err  error
+	)
+
+	if err = c.Bind(&req); err != nil {
+		h.logger.Error(ctx, "Invalid sync since request", logger.F("error", err.Error()))
+		resp = h.converter.BuildErrorSyncSinceResponse("Invalid request format")
+		httpx.WriteObject(c, resp, err)
+		return
+	}
+
+	// 将业务信息添加到context
+	ctx = tracecontext.WithUserID(ctx, req.UserId)
+
+	page, err := h.service.SyncSince(ctx, req.UserId, req.Cursor, req.Limit)
+	if err != nil {
+		h.logger.Error(ctx, "Sync since failed", logger.F("error", err.Error()))
+		resp = h.converter.BuildErrorSyncSinceResponse(err.Error())
+	} else {
+		resp = h.converter.BuildSyncSinceResponse(page)
 	}
 
 	httpx.WriteObject(c, resp, err)
@@ -123,7 +156,7 @@ func (h *HTTPHandler) MarkMessagesRead(c *gin.Context) {
 	// 将业务信息添加到context
 	ctx = tracecontext.WithUserID(ctx, req.UserId)
 
-	failedIDs, err := h.service.MarkMessagesAsRead(ctx, req.UserId, req.MessageIds)
+	_, failedIDs, err := h.service.MarkMessagesAsRead(ctx, req.UserId, req.MessageIds)
 	if err != nil {
 		h.logger.Error(ctx, "Mark messages read failed", logger.F("error", err.Error()))
 		resp = h.converter.BuildErrorMarkMessagesReadResponse(err.Error())
@@ -133,3 +166,846 @@ func (h *HTTPHandler) MarkMessagesRead(c *gin.Context) {
 
 	httpx.WriteObject(c, resp, err)
 }
+
+// GetGroupStats 获取群组活跃度统计
+func (h *HTTPHandler) GetGroupStats(c *gin.Context) {
+	var (
+		ctx  = c.Request.Context()
+		req  rest.GetGroupStatsRequest
+		resp *rest.GetGroupStatsResponse
+		err  error
+	)
+
+	if err = c.Bind(&req); err != nil {
+		h.logger.Error(ctx, "Invalid get group stats request", logger.F("error", err.Error()))
+		resp = h.converter.BuildErrorGetGroupStatsResponse("Invalid request format")
+		httpx.WriteObject(c, resp, err)
+		return
+	}
+
+	// 将业务信息添加到context
+	ctx = tracecontext.WithUserID(ctx, req.UserId)
+	ctx = tracecontext.WithGroupID(ctx, req.GroupId)
+
+	stats, posters, err := h.service.GetGroupStats(ctx, req.UserId, req.GroupId, req.TimeRange, req.Page, req.PageSize)
+	if err != nil {
+		h.logger.Error(ctx, "Get group stats failed",
+			logger.F("error", err.Error()),
+			logger.F("userID", req.UserId),
+			logger.F("groupID", req.GroupId))
+		resp = h.converter.BuildErrorGetGroupStatsResponse(err.Error())
+	} else {
+		h.logger.Info(ctx, "Get group stats successful",
+			logger.F("groupID", req.GroupId),
+			logger.F("activeMemberCount", stats.ActiveMemberCount))
+		resp = h.converter.BuildGetGroupStatsResponse(stats, posters, req.Page, req.PageSize)
+	}
+
+	httpx.WriteObject(c, resp, err)
+}
+
+// SetGroupReadWatermark 设置群消息已读水位
+func (h *HTTPHandler) SetGroupReadWatermark(c *gin.Context) {
+	var (
+		ctx  = c.Request.Context()
+		req  rest.SetGroupReadWatermarkRequest
+		resp *rest.SetGroupReadWatermarkResponse
+		err  error
+	)
+
+	if err = c.Bind(&req); err != nil {
+		h.logger.Error(ctx, "Invalid set group read watermark request", logger.F("error", err.Error()))
+		resp = h.converter.BuildErrorSetGroupReadWatermarkResponse("Invalid request format")
+		httpx.WriteObject(c, resp, err)
+		return
+	}
+
+	// 将业务信息添加到context
+	ctx = tracecontext.WithUserID(ctx, req.UserId)
+	ctx = tracecontext.WithGroupID(ctx, req.GroupId)
+
+	watermark, err := h.service.SetGroupReadWatermark(ctx, req.UserId, req.GroupId, req.MessageId)
+	if err != nil {
+		h.logger.Error(ctx, "Set group read watermark failed",
+			logger.F("error", err.Error()),
+			logger.F("userID", req.UserId),
+			logger.F("groupID", req.GroupId),
+			logger.F("messageID", req.MessageId))
+		resp = h.converter.BuildErrorSetGroupReadWatermarkResponse(err.Error())
+	} else {
+		resp = h.converter.BuildSetGroupReadWatermarkResponse(watermark)
+	}
+
+	httpx.WriteObject(c, resp, err)
+}
+
+// GetGroupReadStatus 获取群消息已读状态
+func (h *HTTPHandler) GetGroupReadStatus(c *gin.Context) {
+	var (
+		ctx  = c.Request.Context()
+		req  rest.GetGroupReadStatusRequest
+		resp *rest.GetGroupReadStatusResponse
+		err  error
+	)
+
+	if err = c.Bind(&req); err != nil {
+		h.logger.Error(ctx, "Invalid get group read status request", logger.F("error", err.Error()))
+		resp = h.converter.BuildErrorGetGroupReadStatusResponse("Invalid request format")
+		httpx.WriteObject(c, resp, err)
+		return
+	}
+
+	// 将业务信息添加到context
+	ctx = tracecontext.WithUserID(ctx, req.UserId)
+	ctx = tracecontext.WithGroupID(ctx, req.GroupId)
+
+	status, err := h.service.GetGroupReadStatus(ctx, req.UserId, req.GroupId, req.MessageId)
+	if err != nil {
+		h.logger.Error(ctx, "Get group read status failed",
+			logger.F("error", err.Error()),
+			logger.F("userID", req.UserId),
+			logger.F("groupID", req.GroupId))
+		resp = h.converter.BuildErrorGetGroupReadStatusResponse(err.Error())
+	} else {
+		resp = h.converter.BuildGetGroupReadStatusResponse(status)
+	}
+
+	httpx.WriteObject(c, resp, err)
+}
+
+// GetMessageReadStatus 获取群消息投递和已读汇总及分页明细
+func (h *HTTPHandler) GetMessageReadStatus(c *gin.Context) {
+	var (
+		ctx  = c.Request.Context()
+		req  rest.GetMessageReadStatusRequest
+		resp *rest.GetMessageReadStatusResponse
+		err  error
+	)
+
+	if err = c.Bind(&req); err != nil {
+		h.logger.Error(ctx, "Invalid get message read status request", logger.F("error", err.Error()))
+		resp = h.converter.BuildErrorGetMessageReadStatusResponse("Invalid request format")
+		httpx.WriteObject(c, resp, err)
+		return
+	}
+
+	// 将业务信息添加到context
+	ctx = tracecontext.WithUserID(ctx, req.UserId)
+	ctx = tracecontext.WithGroupID(ctx, req.GroupId)
+	ctx = tracecontext.WithMessageID(ctx, req.MessageId)
+
+	status, err := h.service.GetMessageReadStatus(ctx, req.UserId, req.GroupId, req.MessageId, req.Status, int(req.Page), int(req.PageSize))
+	if err != nil {
+		h.logger.Error(ctx, "Get message read status failed",
+			logger.F("error", err.Error()),
+			logger.F("userID", req.UserId),
+			logger.F("groupID", req.GroupId),
+			logger.F("messageID", req.MessageId))
+		resp = h.converter.BuildErrorGetMessageReadStatusResponse(err.Error())
+	} else {
+		resp = h.converter.BuildGetMessageReadStatusResponse(status)
+	}
+
+	httpx.WriteObject(c, resp, err)
+}
+
+// SetConversationTTL 设置会话默认消息有效期
+func (h *HTTPHandler) SetConversationTTL(c *gin.Context) {
+	var (
+		ctx  = c.Request.Context()
+		req  rest.SetConversationTTLRequest
+		resp *rest.SetConversationTTLResponse
+		err  error
+	)
+
+	if err = c.Bind(&req); err != nil {
+		h.logger.Error(ctx, "Invalid set conversation ttl request", logger.F("error", err.Error()))
+		resp = h.converter.BuildErrorSetConversationTTLResponse("Invalid request format")
+		httpx.WriteObject(c, resp, err)
+		return
+	}
+
+	// 将业务信息添加到context
+	ctx = tracecontext.WithUserID(ctx, req.UserId)
+
+	convID, err := h.service.SetConversationTTL(ctx, req.UserId, req.To, req.GroupId, req.Ttl)
+	if err != nil {
+		h.logger.Error(ctx, "Set conversation ttl failed",
+			logger.F("error", err.Error()),
+			logger.F("userID", req.UserId),
+			logger.F("to", req.To),
+			logger.F("groupID", req.GroupId))
+		resp = h.converter.BuildErrorSetConversationTTLResponse(err.Error())
+	} else {
+		resp = h.converter.BuildSetConversationTTLResponse(convID, req.Ttl)
+	}
+
+	httpx.WriteObject(c, resp, err)
+}
+
+// GetConversationRetention 获取会话消息保留期
+func (h *HTTPHandler) GetConversationRetention(c *gin.Context) {
+	var (
+		ctx  = c.Request.Context()
+		req  rest.GetConversationRetentionRequest
+		resp *rest.ConversationRetentionResponse
+		err  error
+	)
+
+	if err = c.Bind(&req); err != nil {
+		h.logger.Error(ctx, "Invalid get conversation retention request", logger.F("error", err.Error()))
+		resp = h.converter.BuildErrorConversationRetentionResponse("Invalid request format")
+		httpx.WriteObject(c, resp, err)
+		return
+	}
+
+	// 将业务信息添加到context
+	ctx = tracecontext.WithUserID(ctx, req.UserId)
+
+	retention, err := h.service.GetConversationRetention(ctx, req.UserId, req.To, req.GroupId)
+	if err != nil {
+		h.logger.Error(ctx, "Get conversation retention failed",
+			logger.F("error", err.Error()),
+			logger.F("userID", req.UserId),
+			logger.F("to", req.To),
+			logger.F("groupID", req.GroupId))
+		resp = h.converter.BuildErrorConversationRetentionResponse(err.Error())
+	} else {
+		resp = h.converter.BuildConversationRetentionResponse("获取会话消息保留期成功", retention)
+	}
+
+	httpx.WriteObject(c, resp, err)
+}
+
+// SetConversationRetention 设置会话消息保留期
+func (h *HTTPHandler) SetConversationRetention(c *gin.Context) {
+	var (
+		ctx  = c.Request.Context()
+		req  rest.SetConversationRetentionRequest
+		resp *rest.ConversationRetentionResponse
+		err  error
+	)
+
+	if err = c.Bind(&req); err != nil {
+		h.logger.Error(ctx, "Invalid set conversation retention request", logger.F("error", err.Error()))
+		resp = h.converter.BuildErrorConversationRetentionResponse("Invalid request format")
+		httpx.WriteObject(c, resp, err)
+		return
+	}
+
+	// 将业务信息添加到context
+	ctx = tracecontext.WithUserID(ctx, req.UserId)
+
+	retention, err := h.service.SetConversationRetention(ctx, req.UserId, req.To, req.GroupId, int(req.RetentionDays))
+	if err != nil {
+		h.logger.Error(ctx, "Set conversation retention failed",
+			logger.F("error", err.Error()),
+			logger.F("userID", req.UserId),
+			logger.F("to", req.To),
+			logger.F("groupID", req.GroupId))
+		resp = h.converter.BuildErrorConversationRetentionResponse(err.Error())
+	} else {
+		resp = h.converter.BuildConversationRetentionResponse("设置会话消息保留期成功", retention)
+	}
+
+	httpx.WriteObject(c, resp, err)
+}
+
+// GetPoll 获取群投票结果
+func (h *HTTPHandler) GetPoll(c *gin.Context) {
+	var (
+		ctx  = c.Request.Context()
+		req  rest.GetPollRequest
+		resp *rest.PollResponse
+		err  error
+	)
+
+	if err = c.Bind(&req); err != nil {
+		h.logger.Error(ctx, "Invalid get poll request", logger.F("error", err.Error()))
+		resp = h.converter.BuildErrorPollResponse("Invalid request format")
+		httpx.WriteObject(c, resp, err)
+		return
+	}
+
+	// 将业务信息添加到context
+	ctx = tracecontext.WithUserID(ctx, req.UserId)
+	ctx = tracecontext.WithGroupID(ctx, req.GroupId)
+
+	result, err := h.service.GetPoll(ctx, req.UserId, req.GroupId, req.PollId)
+	if err != nil {
+		h.logger.Error(ctx, "Get poll failed",
+			logger.F("error", err.Error()),
+			logger.F("userID", req.UserId),
+			logger.F("groupID", req.GroupId),
+			logger.F("pollID", req.PollId))
+		resp = h.converter.BuildErrorPollResponse(err.Error())
+	} else {
+		resp = h.converter.BuildPollResponse("获取投票成功", result)
+	}
+
+	httpx.WriteObject(c, resp, err)
+}
+
+// VotePoll 群投票
+func (h *HTTPHandler) VotePoll(c *gin.Context) {
+	var (
+		ctx  = c.Request.Context()
+		req  rest.VotePollRequest
+		resp *rest.PollResponse
+		err  error
+	)
+
+	if err = c.Bind(&req); err != nil {
+		h.logger.Error(ctx, "Invalid vote poll request", logger.F("error", err.Error()))
+		resp = h.converter.BuildErrorPollResponse("Invalid request format")
+		httpx.WriteObject(c, resp, err)
+		return
+	}
+
+	// 将业务信息添加到context
+	ctx = tracecontext.WithUserID(ctx, req.UserId)
+	ctx = tracecontext.WithGroupID(ctx, req.GroupId)
+
+	choices := make([]int, 0, len(req.Choices))
+	for _, choice := range req.Choices {
+		choices = append(choices, int(choice))
+	}
+	result, err := h.service.VotePoll(ctx, req.UserId, req.GroupId, req.PollId, choices)
+	if err != nil {
+		h.logger.Error(ctx, "Vote poll failed",
+			logger.F("error", err.Error()),
+			logger.F("userID", req.UserId),
+			logger.F("groupID", req.GroupId),
+			logger.F("pollID", req.PollId))
+		resp = h.converter.BuildErrorPollResponse(err.Error())
+	} else {
+		resp = h.converter.BuildPollResponse("投票成功", result)
+	}
+
+	httpx.WriteObject(c, resp, err)
+}
+
+// ClosePoll 结束群投票
+func (h *HTTPHandler) ClosePoll(c *gin.Context) {
+	var (
+		ctx  = c.Request.Context()
+		req  rest.ClosePollRequest
+		resp *rest.PollResponse
+		err  error
+	)
+
+	if err = c.Bind(&req); err != nil {
+		h.logger.Error(ctx, "Invalid close poll request", logger.F("error", err.Error()))
+		resp = h.converter.BuildErrorPollResponse("Invalid request format")
+		httpx.WriteObject(c, resp, err)
+		return
+	}
+
+	// 将业务信息添加到context
+	ctx = tracecontext.WithUserID(ctx, req.UserId)
+	ctx = tracecontext.WithGroupID(ctx, req.GroupId)
+
+	result, err := h.service.ClosePoll(ctx, req.UserId, req.GroupId, req.PollId)
+	if err != nil {
+		h.logger.Error(ctx, "Close poll failed",
+			logger.F("error", err.Error()),
+			logger.F("userID", req.UserId),
+			logger.F("groupID", req.GroupId),
+			logger.F("pollID", req.PollId))
+		resp = h.converter.BuildErrorPollResponse(err.Error())
+	} else {
+		resp = h.converter.BuildPollResponse("结束投票成功", result)
+	}
+
+	httpx.WriteObject(c, resp, err)
+}
+
+// GetGroupHighlights 获取群亮点（生效公告和置顶消息）
+func (h *HTTPHandler) GetGroupHighlights(c *gin.Context) {
+	var (
+		ctx  = c.Request.Context()
+		req  rest.GetGroupHighlightsRequest
+		resp *rest.GroupHighlightsResponse
+		err  error
+	)
+
+	if err = c.Bind(&req); err != nil {
+		h.logger.Error(ctx, "Invalid get group highlights request", logger.F("error", err.Error()))
+		resp = h.converter.BuildErrorGroupHighlightsResponse("Invalid request format")
+		httpx.WriteObject(c, resp, err)
+		return
+	}
+
+	// 将业务信息添加到context
+	ctx = tracecontext.WithUserID(ctx, req.UserId)
+	ctx = tracecontext.WithGroupID(ctx, req.GroupId)
+
+	highlights, err := h.service.GetGroupHighlights(ctx, req.UserId, req.GroupId)
+	if err != nil {
+		h.logger.Error(ctx, "Get group highlights failed",
+			logger.F("error", err.Error()),
+			logger.F("userID", req.UserId),
+			logger.F("groupID", req.GroupId))
+		resp = h.converter.BuildErrorGroupHighlightsResponse(err.Error())
+	} else {
+		resp = h.converter.BuildGroupHighlightsResponse("获取群亮点成功", req.GroupId, highlights)
+	}
+
+	httpx.WriteObject(c, resp, err)
+}
+
+// PinGroupMessage 置顶群消息
+func (h *HTTPHandler) PinGroupMessage(c *gin.Context) {
+	var (
+		ctx  = c.Request.Context()
+		req  rest.PinGroupMessageRequest
+		resp *rest.GroupHighlightsResponse
+		err  error
+	)
+
+	if err = c.Bind(&req); err != nil {
+		h.logger.Error(ctx, "Invalid pin group message request", logger.F("error", err.Error()))
+		resp = h.converter.BuildErrorGroupHighlightsResponse("Invalid request format")
+		httpx.WriteObject(c, resp, err)
+		return
+	}
+
+	// 将业务信息添加到context
+	ctx = tracecontext.WithUserID(ctx, req.UserId)
+	ctx = tracecontext.WithGroupID(ctx, req.GroupId)
+
+	highlights, err := h.service.PinGroupMessage(ctx, req.UserId, req.GroupId, req.MessageId, req.AnnouncementId)
+	if err != nil {
+		h.logger.Error(ctx, "Pin group message failed",
+			logger.F("error", err.Error()),
+			logger.F("userID", req.UserId),
+			logger.F("groupID", req.GroupId),
+			logger.F("messageID", req.MessageId))
+		resp = h.converter.BuildErrorGroupHighlightsResponse(err.Error())
+	} else {
+		resp = h.converter.BuildGroupHighlightsResponse("置顶消息成功", req.GroupId, highlights)
+	}
+
+	httpx.WriteObject(c, resp, err)
+}
+
+// UnpinGroupMessage 取消置顶群消息
+func (h *HTTPHandler) UnpinGroupMessage(c *gin.Context) {
+	var (
+		ctx  = c.Request.Context()
+		req  rest.PinGroupMessageRequest
+		resp *rest.GroupHighlightsResponse
+		err  error
+	)
+
+	if err = c.Bind(&req); err != nil {
+		h.logger.Error(ctx, "Invalid unpin group message request", logger.F("error", err.Error()))
+		resp = h.converter.BuildErrorGroupHighlightsResponse("Invalid request format")
+		httpx.WriteObject(c, resp, err)
+		return
+	}
+
+	// 将业务信息添加到context
+	ctx = tracecontext.WithUserID(ctx, req.UserId)
+	ctx = tracecontext.WithGroupID(ctx, req.GroupId)
+
+	highlights, err := h.service.UnpinGroupMessage(ctx, req.UserId, req.GroupId, req.MessageId)
+	if err != nil {
+		h.logger.Error(ctx, "Unpin group message failed",
+			logger.F("error", err.Error()),
+			logger.F("userID", req.UserId),
+			logger.F("groupID", req.GroupId),
+			logger.F("messageID", req.MessageId))
+		resp = h.converter.BuildErrorGroupHighlightsResponse(err.Error())
+	} else {
+		resp = h.converter.BuildGroupHighlightsResponse("取消置顶成功", req.GroupId, highlights)
+	}
+
+	httpx.WriteObject(c, resp, err)
+}
+
+// ReorderGroupPins 调整群置顶消息顺序
+func (h *HTTPHandler) ReorderGroupPins(c *gin.Context) {
+	var (
+		ctx  = c.Request.Context()
+		req  rest.ReorderGroupPinsRequest
+		resp *rest.GroupHighlightsResponse
+		err  error
+	)
+
+	if err = c.Bind(&req); err != nil {
+		h.logger.Error(ctx, "Invalid reorder group pins request", logger.F("error", err.Error()))
+		resp = h.converter.BuildErrorGroupHighlightsResponse("Invalid request format")
+		httpx.WriteObject(c, resp, err)
+		return
+	}
+
+	// 将业务信息添加到context
+	ctx = tracecontext.WithUserID(ctx, req.UserId)
+	ctx = tracecontext.WithGroupID(ctx, req.GroupId)
+
+	highlights, err := h.service.ReorderGroupPins(ctx, req.UserId, req.GroupId, req.MessageIds)
+	if err != nil {
+		h.logger.Error(ctx, "Reorder group pins failed",
+			logger.F("error", err.Error()),
+			logger.F("userID", req.UserId),
+			logger.F("groupID", req.GroupId))
+		resp = h.converter.BuildErrorGroupHighlightsResponse(err.Error())
+	} else {
+		resp = h.converter.BuildGroupHighlightsResponse("调整置顶顺序成功", req.GroupId, highlights)
+	}
+
+	httpx.WriteObject(c, resp, err)
+}
+
+// GetDeliveryStatus 获取投递链路状态
+func (h *HTTPHandler) GetDeliveryStatus(c *gin.Context) {
+	var (
+		ctx  = c.Request.Context()
+		resp *rest.GetDeliveryStatusResponse
+	)
+
+	status, err := h.service.GetDeliveryStatus(ctx)
+	if err != nil {
+		h.logger.Error(ctx, "Get delivery status failed", logger.F("error", err.Error()))
+		resp = h.converter.BuildErrorGetDeliveryStatusResponse(err.Error())
+	} else {
+		resp = h.converter.BuildGetDeliveryStatusResponse(status)
+	}
+
+	httpx.WriteObject(c, resp, err)
+}
+
+// ExportGroupHistory 导出群聊记录
+func (h *HTTPHandler) ExportGroupHistory(c *gin.Context) {
+	var (
+		ctx  = c.Request.Context()
+		req  rest.ExportGroupHistoryRequest
+		resp *rest.ExportGroupHistoryResponse
+		err  error
+	)
+
+	if err = c.Bind(&req); err != nil {
+		h.logger.Error(ctx, "Invalid export group history request", logger.F("error", err.Error()))
+		resp = h.converter.BuildErrorExportGroupHistoryResponse("Invalid request format")
+		httpx.WriteObject(c, resp, err)
+		return
+	}
+
+	// 将业务信息添加到context
+	ctx = tracecontext.WithUserID(ctx, req.UserId)
+	ctx = tracecontext.WithGroupID(ctx, req.GroupId)
+
+	export, err := h.service.ExportGroupHistory(ctx, req.UserId, req.GroupId, req.Format, req.StartTime, req.EndTime)
+	if err != nil {
+		h.logger.Error(ctx, "Export group history failed",
+			logger.F("error", err.Error()),
+			logger.F("userID", req.UserId),
+			logger.F("groupID", req.GroupId))
+		resp = h.converter.BuildErrorExportGroupHistoryResponse(err.Error())
+	} else {
+		resp = h.converter.BuildExportGroupHistoryResponse(export)
+	}
+
+	httpx.WriteObject(c, resp, err)
+}
+
+// GetGroupExport 查询群聊记录导出进度
+func (h *HTTPHandler) GetGroupExport(c *gin.Context) {
+	var (
+		ctx  = c.Request.Context()
+		req  rest.GetGroupExportRequest
+		resp *rest.GetGroupExportResponse
+		err  error
+	)
+
+	if err = c.Bind(&req); err != nil {
+		h.logger.Error(ctx, "Invalid get group export request", logger.F("error", err.Error()))
+		resp = h.converter.BuildErrorGetGroupExportResponse("Invalid request format")
+		httpx.WriteObject(c, resp, err)
+		return
+	}
+
+	// 将业务信息添加到context
+	ctx = tracecontext.WithUserID(ctx, req.UserId)
+
+	export, err := h.service.GetGroupExport(ctx, req.UserId, req.ExportId)
+	if err != nil {
+		h.logger.Error(ctx, "Get group export failed",
+			logger.F("error", err.Error()),
+			logger.F("userID", req.UserId),
+			logger.F("exportID", req.ExportId))
+		resp = h.converter.BuildErrorGetGroupExportResponse(err.Error())
+	} else {
+		resp = h.converter.BuildGetGroupExportResponse(export)
+	}
+
+	httpx.WriteObject(c, resp, err)
+}
+
+// DownloadGroupExport 下载群聊记录导出文件，导出未完成时返回导出进度
+func (h *HTTPHandler) DownloadGroupExport(c *gin.Context) {
+	var (
+		ctx = c.Request.Context()
+		req rest.GetGroupExportRequest
+		err error
+	)
+
+	if err = c.Bind(&req); err != nil {
+		h.logger.Error(ctx, "Invalid download group export request", logger.F("error", err.Error()))
+		httpx.WriteObject(c, h.converter.BuildErrorGetGroupExportResponse("Invalid request format"), err)
+		return
+	}
+
+	// 将业务信息添加到context
+	ctx = tracecontext.WithUserID(ctx, req.UserId)
+
+	export, err := h.service.GetGroupExport(ctx, req.UserId, req.ExportId)
+	if err != nil {
+		h.logger.Error(ctx, "Download group export failed",
+			logger.F("error", err.Error()),
+			logger.F("userID", req.UserId),
+			logger.F("exportID", req.ExportId))
+		httpx.WriteObject(c, h.converter.BuildErrorGetGroupExportResponse(err.Error()), err)
+		return
+	}
+	if export.Status != model.ExportStatusReady {
+		resp := h.converter.BuildGetGroupExportResponse(export)
+		resp.Success = false
+		resp.Message = "导出文件尚未生成"
+		if export.Status == model.ExportStatusExpired {
+			resp.Message = "导出文件已过期，请重新导出"
+		}
+		httpx.WriteObject(c, resp, nil)
+		return
+	}
+
+	h.logger.Info(ctx, "Group export downloaded",
+		logger.F("userID", req.UserId),
+		logger.F("exportID", export.ExportID),
+		logger.F("groupID", export.GroupID))
+	c.FileAttachment(export.FilePath, fmt.Sprintf("group_%d_%s.%s", export.GroupID, export.ExportID, export.Format))
+}
+
+// GetConversations 获取会话列表
+func (h *HTTPHandler) GetConversations(c *gin.Context) {
+	var (
+		ctx  = c.Request.Context()
+		req  rest.GetConversationsRequest
+		resp *rest.GetConversationsResponse
+		err  error
+	)
+
+	if err = c.Bind(&req); err != nil {
+		h.logger.Error(ctx, "Invalid get conversations request", logger.F("error", err.Error()))
+		resp = h.converter.BuildErrorGetConversationsResponse("Invalid request format")
+		httpx.WriteObject(c, resp, err)
+		return
+	}
+
+	// 将业务信息添加到context
+	ctx = tracecontext.WithUserID(ctx, req.UserId)
+
+	summaries, err := h.service.GetConversations(ctx, req.UserId, req.IncludeArchived, req.Page, req.Size)
+	if err != nil {
+		h.logger.Error(ctx, "Get conversations failed",
+			logger.F("error", err.Error()),
+			logger.F("userID", req.UserId))
+		resp = h.converter.BuildErrorGetConversationsResponse(err.Error())
+	} else {
+		resp = h.converter.BuildGetConversationsResponse(summaries, req.Page, req.Size)
+	}
+
+	httpx.WriteObject(c, resp, err)
+}
+
+// ArchiveConversation 归档会话
+func (h *HTTPHandler) ArchiveConversation(c *gin.Context) {
+	var (
+		ctx  = c.Request.Context()
+		req  rest.ArchiveConversationRequest
+		resp *rest.ArchiveConversationResponse
+		err  error
+	)
+
+	if err = c.Bind(&req); err != nil {
+		h.logger.Error(ctx, "Invalid archive conversation request", logger.F("error", err.Error()))
+		resp = h.converter.BuildErrorArchiveConversationResponse("Invalid request format")
+		httpx.WriteObject(c, resp, err)
+		return
+	}
+
+	// 将业务信息添加到context
+	ctx = tracecontext.WithUserID(ctx, req.UserId)
+
+	_, err = h.service.ArchiveConversation(ctx, req.UserId, req.ConversationId)
+	if err != nil {
+		h.logger.Error(ctx, "Archive conversation failed",
+			logger.F("error", err.Error()),
+			logger.F("userID", req.UserId),
+			logger.F("conversationID", req.ConversationId))
+		resp = h.converter.BuildErrorArchiveConversationResponse(err.Error())
+	} else {
+		resp = h.converter.BuildArchiveConversationResponse(req.ConversationId, true)
+	}
+
+	httpx.WriteObject(c, resp, err)
+}
+
+// UnarchiveConversation 取消归档会话
+func (h *HTTPHandler) UnarchiveConversation(c *gin.Context) {
+	var (
+		ctx  = c.Request.Context()
+		req  rest.ArchiveConversationRequest
+		resp *rest.ArchiveConversationResponse
+		err  error
+	)
+
+	if err = c.Bind(&req); err != nil {
+		h.logger.Error(ctx, "Invalid unarchive conversation request", logger.F("error", err.Error()))
+		resp = h.converter.BuildErrorArchiveConversationResponse("Invalid request format")
+		httpx.WriteObject(c, resp, err)
+		return
+	}
+
+	// 将业务信息添加到context
+	ctx = tracecontext.WithUserID(ctx, req.UserId)
+
+	err = h.service.UnarchiveConversation(ctx, req.UserId, req.ConversationId)
+	if err != nil {
+		h.logger.Error(ctx, "Unarchive conversation failed",
+			logger.F("error", err.Error()),
+			logger.F("userID", req.UserId),
+			logger.F("conversationID", req.ConversationId))
+		resp = h.converter.BuildErrorArchiveConversationResponse(err.Error())
+	} else {
+		resp = h.converter.BuildArchiveConversationResponse(req.ConversationId, false)
+	}
+
+	httpx.WriteObject(c, resp, err)
+}
+
+// ListArchivedConversations 获取已归档会话
+func (h *HTTPHandler) ListArchivedConversations(c *gin.Context) {
+	var (
+		ctx  = c.Request.Context()
+		req  rest.ListArchivedConversationsRequest
+		resp *rest.ListArchivedConversationsResponse
+		err  error
+	)
+
+	if err = c.Bind(&req); err != nil {
+		h.logger.Error(ctx, "Invalid list archived conversations request", logger.F("error", err.Error()))
+		resp = h.converter.BuildErrorListArchivedConversationsResponse("Invalid request format")
+		httpx.WriteObject(c, resp, err)
+		return
+	}
+
+	// 将业务信息添加到context
+	ctx = tracecontext.WithUserID(ctx, req.UserId)
+
+	summaries, err := h.service.ListArchivedConversations(ctx, req.UserId)
+	if err != nil {
+		h.logger.Error(ctx, "List archived conversations failed",
+			logger.F("error", err.Error()),
+			logger.F("userID", req.UserId))
+		resp = h.converter.BuildErrorListArchivedConversationsResponse(err.Error())
+	} else {
+		resp = h.converter.BuildListArchivedConversationsResponse(summaries)
+	}
+
+	httpx.WriteObject(c, resp, err)
+}
+
+// SearchConversationMessages 会话内搜索消息
+func (h *HTTPHandler) SearchConversationMessages(c *gin.Context) {
+	var (
+		ctx  = c.Request.Context()
+		req  rest.SearchConversationMessagesRequest
+		resp *rest.SearchConversationMessagesResponse
+		err  error
+	)
+
+	if err = c.Bind(&req); err != nil {
+		h.logger.Error(ctx, "Invalid search conversation messages request", logger.F("error", err.Error()))
+		resp = h.converter.BuildErrorSearchConversationMessagesResponse("Invalid request format")
+		httpx.WriteObject(c, resp, err)
+		return
+	}
+
+	// 将业务信息添加到context
+	ctx = tracecontext.WithUserID(ctx, req.UserId)
+
+	page, err := h.service.SearchConversationMessages(ctx, req.UserId, h.converter.ConversationSearchRequestToModel(&req))
+	if err != nil {
+		h.logger.Error(ctx, "Search conversation messages failed",
+			logger.F("error", err.Error()),
+			logger.F("userID", req.UserId),
+			logger.F("conversationID", req.ConversationId))
+		resp = h.converter.BuildErrorSearchConversationMessagesResponse(err.Error())
+	} else {
+		resp = h.converter.BuildSearchConversationMessagesResponse(page)
+	}
+
+	httpx.WriteObject(c, resp, err)
+}
+
+// GetDigestMode 获取通知摘要设置
+func (h *HTTPHandler) GetDigestMode(c *gin.Context) {
+	var (
+		ctx  = c.Request.Context()
+		req  rest.GetDigestModeRequest
+		resp *rest.DigestModeResponse
+		err  error
+	)
+
+	if err = c.Bind(&req); err != nil {
+		h.logger.Error(ctx, "Invalid get digest mode request", logger.F("error", err.Error()))
+		resp = h.converter.BuildErrorDigestModeResponse("Invalid request format")
+		httpx.WriteObject(c, resp, err)
+		return
+	}
+
+	// 将业务信息添加到context
+	ctx = tracecontext.WithUserID(ctx, req.UserId)
+
+	mode, err := h.service.GetDigestMode(ctx, req.UserId)
+	if err != nil {
+		h.logger.Error(ctx, "Get digest mode failed", logger.F("error", err.Error()))
+		resp = h.converter.BuildErrorDigestModeResponse(err.Error())
+	} else {
+		resp = h.converter.BuildDigestModeResponse(mode)
+	}
+
+	httpx.WriteObject(c, resp, err)
+}
+
+// SetDigestMode 设置通知摘要模式
+func (h *HTTPHandler) SetDigestMode(c *gin.Context) {
+	var (
+		ctx  = c.Request.Context()
+		req  rest.SetDigestModeRequest
+		resp *rest.DigestModeResponse
+		err  error
+	)
+
+	if err = c.Bind(&req); err != nil {
+		h.logger.Error(ctx, "Invalid set digest mode request", logger.F("error", err.Error()))
+		resp = h.converter.BuildErrorDigestModeResponse("Invalid request format")
+		httpx.WriteObject(c, resp, err)
+		return
+	}
+
+	// 将业务信息添加到context
+	ctx = tracecontext.WithUserID(ctx, req.UserId)
+
+	err = h.service.SetDigestMode(ctx, req.UserId, req.Mode)
+	if err != nil {
+		h.logger.Error(ctx, "Set digest mode failed", logger.F("error", err.Error()))
+		resp = h.converter.BuildErrorDigestModeResponse(err.Error())
+	} else {
+		resp = h.converter.BuildDigestModeResponse(req.Mode)
+	}
+
+	httpx.WriteObject(c, resp, err)
+}
@@ -1,9 +1,14 @@
 package model
 
 import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"goim-social/pkg/membership"
 )
 
 // 消息状态常量
@@ -15,18 +20,25 @@ const (
 )
 
 type Message struct {
-	ID          primitive.ObjectID `bson:"_id,omitempty" json:"id"`
-	MessageID   int64              `bson:"message_id" json:"message_id"` // 唯一消息ID
-	From        int64              `bson:"from" json:"from"`
-	To          int64              `bson:"to" json:"to"`
-	GroupID     int64              `bson:"group_id" json:"group_id"`
-	Content     string             `bson:"content" json:"content"`
-	MessageType int                `bson:"message_type" json:"message_type"`         // 消息类型
-	Timestamp   int64              `bson:"timestamp" json:"timestamp"`               // 时间戳
-	AckID       string             `bson:"ack_id,omitempty" json:"ack_id,omitempty"` // 确认ID，可选存储
-	Status      string             `bson:"status" json:"status"`                     // 消息状态：sent/delivered/read/revoked
-	CreatedAt   time.Time          `bson:"created_at" json:"created_at"`
-	UpdatedAt   time.Time          `bson:"updated_at" json:"updated_at"`
+	ID             primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	MessageID      int64              `bson:"message_id" json:"message_id"` // 唯一消息ID
+	From           int64              `bson:"from" json:"from"`
+	To             int64              `bson:"to" json:"to"`
+	GroupID        int64              `bson:"group_id" json:"group_id"`
+	ConversationID string             `bson:"conversation_id" json:"conversation_id"` // 会话ID，见pkg/conversation
+	Content        string             `bson:"content" json:"content"`
+	MessageType    int                `bson:"message_type" json:"message_type"`                 // 消息类型
+	Timestamp      int64              `bson:"timestamp" json:"timestamp"`                       // 时间戳
+	AckID          string             `bson:"ack_id,omitempty" json:"ack_id,omitempty"`         // 确认ID，可选存储
+	Status         string             `bson:"status" json:"status"`                             // 消息状态：sent/delivered/read/revoked
+	KeyID          string             `bson:"key_id,omitempty" json:"-"`                        // 加密密钥ID，为空表示content为明文
+	TTL            int64              `bson:"ttl,omitempty" json:"ttl,omitempty"`               // 有效期（秒），0表示永久保存
+	SenderSeq      int64              `bson:"sender_seq,omitempty" json:"sender_seq,omitempty"` // 发送者在会话内的递增序号，客户端按此排列同一发送者的消息
+	ExpireAt       *time.Time         `bson:"expire_at,omitempty" json:"expire_at,omitempty"`   // 过期时间，到期后由过期清理任务删除
+	Unindexed      bool               `bson:"unindexed,omitempty" json:"-"`                     // 不参与会话搜索，由消息类型的存储策略决定
+	Pinned         bool               `bson:"pinned,omitempty" json:"pinned,omitempty"`         // 是否为群置顶消息，置顶消息不受保留期清理
+	CreatedAt      time.Time          `bson:"created_at" json:"created_at"`
+	UpdatedAt      time.Time          `bson:"updated_at" json:"updated_at"`
 }
 
 type WSMessage struct {
@@ -137,11 +149,742 @@ type ActionStatItem struct {
 const (
 	DefaultPageSize = 20
 	MaxPageSize     = 100
+
+	MaxUnreadPageSize = 100 // 未读消息每页上限，未指定时也按此返回
 )
 
+// UnreadPage 一页未读消息，按消息ID倒序
+type UnreadPage struct {
+	Messages   []*Message
+	Total      int64  // 全部未读消息数
+	NextCursor string // 下一页游标，没有更多时为空
+}
+
 // 时间分组常量
 const (
 	GroupByDay   = "day"
 	GroupByWeek  = "week"
 	GroupByMonth = "month"
 )
+
+// ==================== 群组统计相关模型 ====================
+
+// 群组统计窗口
+const (
+	GroupStatsRangeDay   = "day"
+	GroupStatsRangeWeek  = "week"
+	GroupStatsRangeMonth = "month"
+)
+
+// 群组统计缓存
+const (
+	CacheKeyGroupStats    = "message:group_stats" // 群组统计缓存前缀
+	CacheExpireGroupStats = 300                   // 群组统计缓存5分钟
+)
+
+// 群成员角色，与social-service保持一致
+const (
+	GroupRoleOwner = "owner" // 群主
+	GroupRoleAdmin = "admin" // 管理员
+)
+
+// GroupPosterStat 群组成员发言统计
+type GroupPosterStat struct {
+	UserID       int64 `bson:"_id" json:"user_id"`
+	MessageCount int64 `bson:"count" json:"message_count"`
+}
+
+// GroupStats 群组统计结果，Posters按发言数从高到低排列
+type GroupStats struct {
+	GroupID           int64              `json:"group_id"`
+	TimeRange         string             `json:"time_range"`
+	StartTime         time.Time          `json:"start_time"`
+	EndTime           time.Time          `json:"end_time"`
+	MessageCount      int64              `json:"message_count"`
+	ActiveMemberCount int64              `json:"active_member_count"`
+	Posters           []*GroupPosterStat `json:"posters"`
+}
+
+// ==================== 群消息已读水位相关模型 ====================
+
+// CollectionGroupReadWatermarks 群成员已读水位集合
+const CollectionGroupReadWatermarks = "group_read_watermarks"
+
+// GroupReadWatermark 群成员已读水位，记录成员在群内已读到的最大消息ID
+// 消息ID由雪花算法生成，按时间单调递增，水位只前进不后退
+type GroupReadWatermark struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty" json:"-"`
+	GroupID   int64              `bson:"group_id" json:"group_id"`
+	UserID    int64              `bson:"user_id" json:"user_id"`
+	MessageID int64              `bson:"message_id" json:"message_id"`
+	UpdatedAt time.Time          `bson:"updated_at" json:"updated_at"`
+}
+
+// GroupReadStatus 群消息已读状态
+type GroupReadStatus struct {
+	GroupID     int64                 `json:"group_id"`
+	MessageID   int64                 `json:"message_id"`
+	ReadCount   int64                 `json:"read_count"`
+	MemberCount int64                 `json:"member_count"`
+	Watermarks  []*GroupReadWatermark `json:"watermarks"`
+}
+
+// ==================== 投递链路状态相关模型 ====================
+
+// 投递链路状态
+const (
+	DeliveryHealthy   = "healthy"   // 正常
+	DeliveryDegraded  = "degraded"  // 可投递但存在积压
+	DeliveryUnhealthy = "unhealthy" // 投递链路中断
+)
+
+// 投递链路检查参数
+const (
+	GatewayForwardChannelPrefix = "connect_forward" // Connect实例推送频道前缀 connect_forward:{serverID}
+	DeliveryActivityWindow      = 5 * time.Minute   // 近期推送窗口，窗口内有推送的实例纳入检查
+	PendingAckGrace             = 30 * time.Second  // 确认宽限期，超过后仍未确认的消息计入积压
+	PendingAckWindow            = 24 * time.Hour    // 积压统计窗口
+	PendingAckDegradedThreshold = 1000              // 积压超过该值时视为degraded
+)
+
+// 推送确认超时重发
+const (
+	CacheKeyPendingAck         = "push:pending_ack"          // 待确认推送有序集合，成员为 {userID}:{messageID}，分值为确认截止时间（毫秒）
+	CacheKeyPendingAckPayload  = "push:pending_ack:payload"  // 待确认推送的消息内容，用于重发
+	CacheKeyPendingAckAttempts = "push:pending_ack:attempts" // 待确认推送的已推送次数
+	CacheKeyAckedPrefix        = "push:acked"                // 已确认标记 push:acked:{userID}:{messageID}，用于确认去重
+	AckResendScanInterval      = time.Second                 // 扫描超时未确认推送的间隔
+	AckResendBatchSize         = 100                         // 每次扫描处理的超时推送数
+)
+
+// AckResendStat 推送确认与重发统计，由推送消费者在本实例内累计
+type AckResendStat struct {
+	Acked          int64 `json:"acked"`           // 首次确认的推送数，迟到和重复的确认不计
+	Resent         int64 `json:"resent"`          // 超时重发次数
+	GaveUp         int64 `json:"gave_up"`         // 重发次数用尽，留给客户端上线拉取
+	SkippedOffline int64 `json:"skipped_offline"` // 用户已断开，不再重发
+}
+
+// GatewayPushStat Connect实例推送统计，由推送消费者在本实例内累计
+type GatewayPushStat struct {
+	ServerID   string    `json:"server_id"`
+	Pushed     int64     `json:"pushed"`
+	Unreceived int64     `json:"unreceived"` // 发布时频道无订阅者
+	Failed     int64     `json:"failed"`
+	LastPushAt time.Time `json:"last_push_at"`
+	LastError  string    `json:"last_error"`
+}
+
+// GatewayDeliveryStatus Connect实例投递状态
+type GatewayDeliveryStatus struct {
+	GatewayPushStat
+	Subscribers int64 `json:"subscribers"`
+}
+
+// DeliveryStatus 投递链路状态
+type DeliveryStatus struct {
+	Status             string                   `json:"status"`
+	Problems           []string                 `json:"problems"`
+	Gateways           []*GatewayDeliveryStatus `json:"gateways"`
+	SubscribedGateways int64                    `json:"subscribed_gateways"`
+	PendingAck         int64                    `json:"pending_ack"`
+	LastConsumedAt     time.Time                `json:"last_consumed_at"`
+	Fanout             *FanoutDeliveryStat      `json:"fanout"`     // 群消息扇出投递结果统计
+	AckResend          *AckResendStat           `json:"ack_resend"` // 推送确认与重发统计
+	CheckedAt          time.Time                `json:"checked_at"`
+}
+
+// 群消息扇出投递结果相关常量
+const (
+	TopicDeliveryEvents = "message_delivery_events" // Logic服务发布的成员级投递结果事件，单条或批量
+
+	FanoutStatusDelivered     = "delivered"
+	FanoutStatusQueuedOffline = "queued_offline"
+	FanoutStatusFailed        = "failed"
+)
+
+// FanoutDeliveryEvent 成员级投递结果事件
+type FanoutDeliveryEvent struct {
+	MessageID int64  `json:"message_id"`
+	GroupID   int64  `json:"group_id"`
+	UserID    int64  `json:"user_id"`
+	SenderID  int64  `json:"sender_id,omitempty"` // 消息发送者，旧版本Logic服务的事件中为空
+	Status    string `json:"status"`
+	Attempts  int    `json:"attempts"`
+	Error     string `json:"error,omitempty"`
+	Timestamp int64  `json:"timestamp"`
+}
+
+// FanoutDeliveryBatch 批量投递结果事件，events字段非空时为批量格式
+type FanoutDeliveryBatch struct {
+	Events    []*FanoutDeliveryEvent `json:"events"`
+	Count     int                    `json:"count"`
+	Timestamp int64                  `json:"timestamp"`
+}
+
+// FanoutDeliveryStat 扇出投递结果统计，由投递事件消费者在本实例内累计
+type FanoutDeliveryStat struct {
+	Delivered     int64     `json:"delivered"`
+	QueuedOffline int64     `json:"queued_offline"`
+	Failed        int64     `json:"failed"`
+	Batches       int64     `json:"batches"`       // 收到的批量事件数
+	LastEventAt   time.Time `json:"last_event_at"` // 最近一条事件的产生时间
+	MaxLag        int64     `json:"max_lag"`       // 事件产生到被消费的最大延迟（秒）
+}
+
+// 消息过期（阅后即焚）相关常量
+const (
+	CollectionConversationSettings = "conversation_settings" // 会话设置，保存会话默认消息有效期
+
+	MaxMessageTTL        = 7 * 24 * 3600    // 消息有效期上限（秒）
+	ExpiryScanInterval   = 10 * time.Second // 过期清理扫描间隔，决定消息到期后最长的删除延迟
+	ExpiryScanBatchSize  = 500              // 每批清理的过期消息数
+	MessageTypeExpired   = 101              // 消息过期通知，客户端按message_id删除本地消息
+	EventTypeExpired     = "message_expired"
+	TopicDownlinkMessage = "downlink_messages" // 下行推送Topic，由推送消费者推送到在线用户
+)
+
+// 消息存储策略，按消息类型配置
+const (
+	StoragePolicyPersist   = "persist"   // 存储并参与会话搜索
+	StoragePolicyUnindexed = "unindexed" // 存储但不参与会话搜索
+	StoragePolicyRelay     = "relay"     // 只转发不存储
+)
+
+// EventTypeGroupJoinRequest 加群申请通知事件，由social-service按接收人拆分后发布
+const EventTypeGroupJoinRequest = "group_join_request"
+
+// EventTypeMention @提及通知事件，由content-service按被提及用户拆分后发布
+const EventTypeMention = "mention"
+
+// EventTypeContentBan 发布限制通知事件，由user-service在管理员限制用户发布时发布
+const EventTypeContentBan = "content_ban"
+
+// EventTypeGroupInfoUpdated 群资料变更通知事件，由social-service按在线成员拆分后发布
+const EventTypeGroupInfoUpdated = "group_info_updated"
+
+// EventTypeContentLike 点赞通知事件，由content-service发布给被点赞内容或评论的作者，按用户的摘要设置合并推送
+const EventTypeContentLike = "content_like"
+
+// ConversationSetting 会话设置
+type ConversationSetting struct {
+	ConversationID  string         `bson:"conversation_id" json:"conversation_id"`
+	TTL             int64          `bson:"ttl" json:"ttl"`                                                                   // 会话默认消息有效期（秒），0表示关闭
+	RetentionDays   int            `bson:"retention_days,omitempty" json:"retention_days,omitempty"`                         // 会话消息保留天数，0表示使用默认保留期
+	ParticipantDays map[string]int `bson:"participant_retention_days,omitempty" json:"participant_retention_days,omitempty"` // 单聊双方各自设置的保留天数，键为用户ID
+	UpdatedBy       int64          `bson:"updated_by" json:"updated_by"`
+	UpdatedAt       time.Time      `bson:"updated_at" json:"updated_at"`
+}
+
+// MessageExpireAt 根据有效期计算过期时间，有效期不大于0时返回nil表示永久保存
+func MessageExpireAt(createdAt time.Time, ttl int64) *time.Time {
+	if ttl <= 0 {
+		return nil
+	}
+	expireAt := createdAt.Add(time.Duration(ttl) * time.Second)
+	return &expireAt
+}
+
+// ==================== 群聊记录导出相关模型 ====================
+
+// 群聊记录导出相关常量
+const (
+	CollectionGroupExports = "group_exports" // 导出记录，同时作为导出审计日志
+
+	ExportFormatJSON = "json"
+	ExportFormatCSV  = "csv"
+
+	ExportStatusPending = "pending" // 生成中
+	ExportStatusReady   = "ready"   // 可下载
+	ExportStatusFailed  = "failed"  // 生成失败
+	ExportStatusExpired = "expired" // 文件已过保留时间被删除
+
+	ExportBatchSize        = 500                   // 每批读取的消息数
+	ExportQueueSize        = 16                    // 等待后台生成的导出数上限，队列满时拒绝新的异步导出
+	ExportCleanupInterval  = 10 * time.Minute      // 过期导出文件的清理间隔
+	ExportCleanupBatchSize = 100                   // 每批清理的导出记录数
+	CacheKeyExportRate     = "message:export:rate" // 导出限流计数前缀
+)
+
+// GroupExport 群聊记录导出记录
+type GroupExport struct {
+	ExportID     string     `bson:"export_id" json:"export_id"`
+	GroupID      int64      `bson:"group_id" json:"group_id"`
+	UserID       int64      `bson:"user_id" json:"user_id"`
+	Format       string     `bson:"format" json:"format"`
+	Status       string     `bson:"status" json:"status"`
+	StartTime    time.Time  `bson:"start_time" json:"start_time"` // 实际导出范围，已按入群时间收窄
+	EndTime      time.Time  `bson:"end_time" json:"end_time"`
+	MessageCount int64      `bson:"message_count" json:"message_count"`
+	FilePath     string     `bson:"file_path,omitempty" json:"-"`
+	Error        string     `bson:"error,omitempty" json:"error,omitempty"`
+	CreatedAt    time.Time  `bson:"created_at" json:"created_at"`
+	CompletedAt  *time.Time `bson:"completed_at,omitempty" json:"completed_at,omitempty"`
+}
+
+// ExportedMessage 导出文件中的单条消息
+type ExportedMessage struct {
+	MessageID   int64     `json:"message_id"`
+	From        int64     `json:"from"`
+	MessageType int       `json:"message_type"`
+	Content     string    `json:"content"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// ==================== 会话归档相关模型 ====================
+
+// 会话归档相关常量
+const (
+	CollectionConversationArchives = "conversation_archives" // 会话归档状态，每个用户每个会话一条
+
+	MaxArchivedConversations  = 500 // 单个用户最多返回的已归档会话数
+	ConversationHeadBatchSize = 500 // 会话列表每批查询最后一条消息的会话数
+)
+
+// ConversationArchive 用户的会话归档记录，存在即表示已归档，取消归档时删除
+type ConversationArchive struct {
+	UserID         int64     `bson:"user_id" json:"user_id"`
+	ConversationID string    `bson:"conversation_id" json:"conversation_id"`
+	ArchivedAt     time.Time `bson:"archived_at" json:"archived_at"`
+}
+
+// ConversationSummary 会话摘要
+type ConversationSummary struct {
+	ConversationID string     `json:"conversation_id"`
+	PeerID         int64      `json:"peer_id"`  // 单聊对方用户ID
+	GroupID        int64      `json:"group_id"` // 群聊ID
+	LastMessage    *Message   `json:"last_message"`
+	ArchivedAt     *time.Time `json:"archived_at,omitempty"` // 归档时间，未归档为nil
+}
+
+// ==================== 会话内搜索相关模型 ====================
+
+// 会话内搜索相关常量
+const (
+	MaxSearchQueryLength        = 100  // 搜索关键词最大长度（字符）
+	ConversationSearchBatchSize = 200  // 每批读取的消息数
+	ConversationSearchMaxScan   = 2000 // 单次请求最多扫描的消息数，达到上限时返回游标由客户端继续
+	DefaultSearchFragmentSize   = 150  // 未配置高亮片段长度时的摘要长度（字符）
+	SearchSnippetEllipsis       = "..."
+)
+
+// ConversationSearchQuery 会话内搜索条件，时间为零值表示不限
+type ConversationSearchQuery struct {
+	ConversationID string
+	Query          string
+	SenderID       int64
+	StartTime      time.Time
+	EndTime        time.Time
+	Cursor         string
+	Limit          int32
+	Windows        []VisibleWindow // 群聊中搜索者可见的时间窗口，取并集；为nil时不限制
+}
+
+// VisibleWindow 群成员可见消息的时间窗口，对应一段成员身份时段，零值表示不限
+type VisibleWindow = membership.Window
+
+// ConversationSearchHit 会话内搜索命中的消息，Snippet已转义，匹配部分以高亮标签包裹
+type ConversationSearchHit struct {
+	Message *Message
+	Snippet string
+}
+
+// ConversationSearchPage 一页会话内搜索结果，按消息ID倒序
+type ConversationSearchPage struct {
+	Hits       []*ConversationSearchHit
+	PageSize   int32  // 生效的每页数量
+	NextCursor string // 下一页游标，没有更多时为空
+}
+
+// ==================== 增量同步相关模型 ====================
+
+const (
+	CollectionMessageTombstones = "message_tombstones" // 已删除消息的墓碑记录，供离线客户端同步删除
+
+	DeletionReasonDeleted   = "deleted"   // 发送者删除
+	DeletionReasonExpired   = "expired"   // 到期删除
+	DeletionReasonRetention = "retention" // 超过会话保留期删除
+
+	DefaultSyncPageSize = 100                 // 每页每类变更的默认条数
+	MaxSyncPageSize     = 500                 // 每页每类变更的条数上限
+	SyncSettleDelay     = 2 * time.Second     // 只同步该时长之前的变更，等待并发写入可见，避免游标越过尚未落库的变更
+	SyncRetention       = 30 * 24 * time.Hour // 墓碑保留时长，游标早于该时长时客户端需重新全量加载
+)
+
+// MessageTombstone 已删除消息的墓碑，消息本身删除后仍能告知离线的客户端删除本地副本
+type MessageTombstone struct {
+	ID             primitive.ObjectID `bson:"_id,omitempty" json:"-"`
+	MessageID      int64              `bson:"message_id" json:"message_id"`
+	ConversationID string             `bson:"conversation_id" json:"conversation_id"`
+	From           int64              `bson:"from" json:"from"`
+	To             int64              `bson:"to" json:"to"`
+	GroupID        int64              `bson:"group_id" json:"group_id"`
+	Reason         string             `bson:"reason" json:"reason"` // deleted/expired
+	CreatedAt      time.Time          `bson:"created_at" json:"-"`  // 被删除消息的创建时间，增量同步按成员身份时段过滤群消息
+	DeletedAt      time.Time          `bson:"deleted_at" json:"deleted_at"`
+}
+
+// SyncPosition 一类变更已同步到的位置，变更按(变更时间, _id)升序排列
+type SyncPosition struct {
+	Time int64  `json:"t"`           // 变更时间（Unix毫秒）
+	ID   string `json:"i,omitempty"` // 同一毫秒内最后一条已同步记录的_id
+}
+
+// After 该位置之后的变更时间起点
+func (p SyncPosition) After() time.Time {
+	return time.UnixMilli(p.Time)
+}
+
+// SyncCursor 增量同步游标，消息、删除和已读水位分别记录位置，各自独立分页
+type SyncCursor struct {
+	Messages   SyncPosition `json:"m"`
+	Deletions  SyncPosition `json:"d"`
+	Watermarks SyncPosition `json:"w"`
+}
+
+// EncodeSyncCursor 将游标编码为不透明字符串
+func EncodeSyncCursor(cursor *SyncCursor) string {
+	data, err := json.Marshal(cursor)
+	if err != nil {
+		return ""
+	}
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeSyncCursor 解析游标字符串
+func DecodeSyncCursor(s string) (*SyncCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("无效的同步游标: %v", err)
+	}
+	var cursor SyncCursor
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return nil, fmt.Errorf("无效的同步游标: %v", err)
+	}
+	for _, position := range []SyncPosition{cursor.Messages, cursor.Deletions, cursor.Watermarks} {
+		if position.Time <= 0 {
+			return nil, fmt.Errorf("无效的同步游标时间: %d", position.Time)
+		}
+		if position.ID != "" && !primitive.IsValidObjectID(position.ID) {
+			return nil, fmt.Errorf("无效的同步游标ID: %s", position.ID)
+		}
+	}
+	return &cursor, nil
+}
+
+// SyncPage 一页增量变更
+type SyncPage struct {
+	Messages      []*Message            // 新消息和状态变化（已读、撤回）的消息，按变更时间升序
+	Deletions     []*MessageTombstone   // 已删除的消息，按删除时间升序
+	Watermarks    []*GroupReadWatermark // 所在群组成员的已读水位变化，按变更时间升序
+	NextCursor    string                // 下一次同步的游标，总是非空
+	HasMore       bool                  // 是否还有未返回的变更，为true时应立即用NextCursor继续同步
+	ResetRequired bool                  // 游标过旧，部分删除记录已清理，客户端需重新全量加载后使用NextCursor
+}
+
+// ============ 通知摘要相关模型 ============
+
+// 通知摘要模式
+const (
+	DigestModeImmediate = "immediate" // 立即推送，不合并
+	DigestModeHourly    = "hourly"    // 每小时推送一次摘要
+	DigestModeDaily     = "daily"     // 每天推送一次摘要
+)
+
+// 通知摘要
+const (
+	MessageTypeNotificationDigest = 109    // 通知摘要消息，content为NotificationDigest JSON
+	PushPriorityBulk              = "bulk" // 摘要的推送通道，与im-gateway的通道名一致
+
+	CacheKeyDigestMode         = "notify:digest:mode"   // 用户摘要模式哈希，field为用户ID
+	CacheKeyDigestDue          = "notify:digest:due"    // 待推送摘要有序集合，成员为用户ID，分值为推送时间（毫秒）
+	CacheKeyDigestItemsPrefix  = "notify:digest:items"  // 摘要中最近的通知 notify:digest:items:{userID}
+	CacheKeyDigestCountsPrefix = "notify:digest:counts" // 摘要中各类通知的计数 notify:digest:counts:{userID}
+
+	DigestItemTTL           = 48 * time.Hour  // 摘要数据的保留时间，覆盖最长的摘要周期
+	DigestFlushScanInterval = time.Minute     // 扫描到期摘要的间隔
+	DigestFlushBatchSize    = 100             // 每次扫描推送的摘要数
+	DefaultDigestMaxItems   = 20              // 每个摘要默认保留的最近通知条数
+	DigestOfflineRetry      = 5 * time.Minute // 用户离线时摘要放回后重新推送的间隔
+)
+
+// IsDigestMode 是否为有效的摘要模式
+func IsDigestMode(mode string) bool {
+	return mode == DigestModeImmediate || mode == DigestModeHourly || mode == DigestModeDaily
+}
+
+// DigestInterval 摘要模式的推送周期，立即推送时为0
+func DigestInterval(mode string) time.Duration {
+	switch mode {
+	case DigestModeHourly:
+		return time.Hour
+	case DigestModeDaily:
+		return 24 * time.Hour
+	default:
+		return 0
+	}
+}
+
+// DigestItem 摘要中的一条通知，保留原通知的内容供客户端展示
+type DigestItem struct {
+	EventType string `json:"event_type"`
+	MessageID int64  `json:"message_id"`
+	From      int64  `json:"from"`
+	Content   string `json:"content"` // 原通知的content
+	Timestamp int64  `json:"timestamp"`
+}
+
+// NotificationDigest 通知摘要，Counts按事件类型计数，Items为最近的若干条通知
+type NotificationDigest struct {
+	Summary string           `json:"summary"` // 摘要文本，如“过去1小时收到12个赞”
+	Counts  map[string]int64 `json:"counts"`
+	Items   []DigestItem     `json:"items"`
+	Since   int64            `json:"since"` // 第一条通知的时间（Unix秒）
+	Until   int64            `json:"until"` // 摘要生成时间（Unix秒）
+}
+
+// ==================== 历史消息保留期相关模型 ====================
+
+// 历史消息保留期相关常量
+const (
+	DefaultRetentionPurgeInterval = time.Hour               // 未配置时的清理间隔
+	DefaultRetentionBatchSize     = 500                     // 未配置时每批删除的消息数
+	RetentionSettingsPageSize     = 200                     // 清理时每次读取的会话保留期设置数
+	TopicMessagePurged            = "message-purged-events" // 消息清理事件Topic，按会话ID分区，供搜索索引等下游移除已删除的消息
+)
+
+// ConversationRetention 会话消息保留期设置
+type ConversationRetention struct {
+	ConversationID string `json:"conversation_id"`
+	RetentionDays  int    `json:"retention_days"` // 会话自己设置的保留天数，单聊为调用方的设置，0表示使用默认保留期
+	PeerDays       int    `json:"peer_days"`      // 单聊对方设置的保留天数，0表示使用默认保留期
+	EffectiveDays  int    `json:"effective_days"` // 实际生效的保留天数，0表示永久保留
+	DefaultDays    int    `json:"default_days"`   // 平台默认保留天数
+	MinDays        int    `json:"min_days"`       // 可设置的最短保留天数
+	MaxDays        int    `json:"max_days"`       // 可设置的最长保留天数，0表示不限制
+}
+
+// MessagePurgedEvent 消息清理事件，同一会话的一批消息合并为一个事件，下游据此删除索引和缓存，重复处理是安全的
+type MessagePurgedEvent struct {
+	ConversationID string  `json:"conversation_id"`
+	GroupID        int64   `json:"group_id,omitempty"`
+	MessageIDs     []int64 `json:"message_ids"`
+	Reason         string  `json:"reason"`
+	PurgedAt       int64   `json:"purged_at"` // Unix秒
+}
+
+// ==================== 消息搜索索引相关模型 ====================
+
+// TopicMessageIndex 消息索引事件Topic，按会话ID分区，搜索服务据此写入消息索引
+// 启用静态加密后库中的正文为密文，索引文档由消息服务在保存时用明文正文生成
+const TopicMessageIndex = "message-index-events"
+
+// MessageIndexEvent 消息索引事件，即搜索服务的消息索引文档，重复处理按消息ID覆盖写入
+type MessageIndexEvent struct {
+	ID          int64     `json:"id"`
+	FromUserID  int64     `json:"from_user_id"`
+	ToUserID    int64     `json:"to_user_id,omitempty"`
+	GroupID     int64     `json:"group_id,omitempty"`
+	Content     string    `json:"content"`
+	MessageType string    `json:"message_type"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// ==================== 群投票相关模型 ====================
+
+// 群投票相关常量
+const (
+	MessageTypePoll       = 7   // 群投票消息，content为PollContent JSON，投票ID即消息ID
+	MessageTypePollUpdate = 111 // 投票结果更新，推送给群成员，content为PollUpdate JSON，客户端据此刷新票数而不改写原消息
+	EventTypePollUpdated  = "poll_updated"
+
+	CollectionPollVotes  = "poll_votes"  // 投票记录，每个成员在每个投票中一条，重复投票覆盖之前的选择
+	CollectionPollStates = "poll_states" // 投票状态，保存各选项票数计数和结束状态
+
+	CacheKeyPollDirty       = "poll:dirty" // 票数有变化、待推送结果更新的投票有序集合，成员为"群组ID:投票ID"，分值为首次变化时间（毫秒）
+	DefaultPollUpdatePeriod = time.Second  // 未配置时的投票结果更新推送间隔
+	PollUpdateBatchSize     = 100          // 每次推送处理的投票数
+	PollVoteMaxAttempts     = 3            // 同一成员并发投票冲突时的最大尝试次数
+)
+
+// PollContent 投票消息的内容，发出后不再改写
+type PollContent struct {
+	Question    string   `json:"question"`
+	Options     []string `json:"options"`
+	MultiSelect bool     `json:"multi_select"`
+}
+
+// Poll 群投票，由投票消息和投票状态组成
+type Poll struct {
+	PollID      int64    `json:"poll_id"`
+	GroupID     int64    `json:"group_id"`
+	CreatorID   int64    `json:"creator_id"`
+	Question    string   `json:"question"`
+	Options     []string `json:"options"`
+	MultiSelect bool     `json:"multi_select"` // 实际是否允许多选，平台关闭多选时为false
+	Closed      bool     `json:"closed"`
+	ClosedBy    int64    `json:"closed_by,omitempty"`
+	ClosedAt    int64    `json:"closed_at,omitempty"` // Unix秒
+	CreatedAt   int64    `json:"created_at"`          // Unix秒
+}
+
+// PollVote 成员的投票记录，Choices为选项下标
+type PollVote struct {
+	ID      primitive.ObjectID `bson:"_id,omitempty" json:"-"`
+	PollID  int64              `bson:"poll_id" json:"poll_id"`
+	GroupID int64              `bson:"group_id" json:"group_id"`
+	UserID  int64              `bson:"user_id" json:"user_id"`
+	Choices []int              `bson:"choices" json:"choices"`
+	VotedAt time.Time          `bson:"voted_at" json:"voted_at"`
+}
+
+// PollState 投票状态，没有记录表示投票进行中且无人投票
+// Counts按选项下标（字符串）计数，投票时以$inc增减，统计结果不需要扫描全部投票记录
+type PollState struct {
+	ID         primitive.ObjectID `bson:"_id,omitempty" json:"-"`
+	PollID     int64              `bson:"poll_id" json:"poll_id"`
+	GroupID    int64              `bson:"group_id" json:"group_id"`
+	Counts     map[string]int64   `bson:"counts" json:"counts"`
+	VoterCount int64              `bson:"voter_count" json:"voter_count"`
+	Closed     bool               `bson:"closed" json:"closed"`
+	ClosedBy   int64              `bson:"closed_by" json:"closed_by"`
+	ClosedAt   time.Time          `bson:"closed_at" json:"closed_at"`
+}
+
+// PollResult 投票结果，Counts按选项下标计数
+type PollResult struct {
+	Poll       *Poll   `json:"poll"`
+	Counts     []int64 `json:"counts"`
+	VoterCount int64   `json:"voter_count"`
+	MyChoices  []int   `json:"my_choices"` // 当前用户的选择，未投票时为空
+}
+
+// PollUpdate 投票结果更新推送的内容
+type PollUpdate struct {
+	PollID     int64   `json:"poll_id"`
+	Counts     []int64 `json:"counts"`
+	VoterCount int64   `json:"voter_count"`
+	Closed     bool    `json:"closed"`
+}
+
+// ==================== 群消息投递和已读汇总相关模型 ====================
+
+// 群消息投递和已读汇总相关常量
+const (
+	MessageTypeDeliveryReport = 112 // 投递和已读汇总，推送给群消息发送者，content为DeliveryReport JSON
+	EventTypeDeliveryReport   = "delivery_report"
+
+	CacheKeyDeliveryPrefix      = "msg_delivery"       // 成员投递结果哈希 msg_delivery:{messageID}，field为成员ID，值为投递结果
+	CacheKeyDeliveryGroupPrefix = "msg_delivery:group" // 群内跟踪中的消息有序集合 msg_delivery:group:{groupID}，分值为消息ID
+	CacheKeyDeliveryDirty       = "msg_delivery:dirty" // 投递或已读有变化、待汇总的群有序集合，分值为首次变化时间（毫秒）
+	DeliveryFieldSender         = "sender"             // 投递结果哈希中记录发送者的field
+	DeliveryFieldReported       = "reported"           // 投递结果哈希中记录上次推送的汇总计数的field，汇总无变化时不重复推送
+	DeliveryStatusRead          = "read"               // 明细中的已读状态，已读即视为已送达
+	DeliveryStatusPending       = "pending"            // 明细中尚无投递结果的状态，如投递结果已过保留期或仍在扇出
+	DefaultDeliveryReportPeriod = 10 * time.Second     // 未配置时的汇总推送间隔
+	DefaultDeliveryRetention    = 72 * time.Hour       // 未配置时投递结果的保留时长
+	MaxTrackedGroupMessages     = 200                  // 每个群最多同时跟踪的消息数，超出时停止跟踪最早的消息
+	DeliveryReportBatchSize     = 100                  // 每次汇总处理的群数
+	DefaultReadStatusPageSize   = 50                   // 已读明细默认每页条数
+	MaxReadStatusPageSize       = 500                  // 已读明细每页上限
+)
+
+// IsReadStatusFilter 是否为有效的已读明细筛选条件，空表示不筛选
+func IsReadStatusFilter(status string) bool {
+	switch status {
+	case "", DeliveryStatusRead, FanoutStatusDelivered, FanoutStatusQueuedOffline, FanoutStatusFailed, DeliveryStatusPending:
+		return true
+	default:
+		return false
+	}
+}
+
+// DeliveryReport 群消息的投递和已读汇总，不含发送者
+// 已读的成员同时计入Delivered；Offline为离线待拉取且尚未读取的成员数
+type DeliveryReport struct {
+	MessageID      int64 `json:"message_id"`
+	GroupID        int64 `json:"group_id"`
+	RecipientCount int64 `json:"recipient_count"` // 当前群成员数（不含发送者）
+	Delivered      int64 `json:"delivered"`
+	Read           int64 `json:"read"`
+	Offline        int64 `json:"offline"`
+	Failed         int64 `json:"failed"`
+	UpdatedAt      int64 `json:"updated_at"` // Unix秒
+}
+
+// MemberReadStatus 成员对一条群消息的投递和已读状态
+type MemberReadStatus struct {
+	UserID int64  `json:"user_id"`
+	Status string `json:"status"` // read / delivered / queued_offline / failed / pending
+}
+
+// MessageReadStatus 群消息的投递和已读汇总及分页明细
+type MessageReadStatus struct {
+	Report   *DeliveryReport     `json:"report"`
+	Members  []*MemberReadStatus `json:"members"`
+	Total    int64               `json:"total"` // 符合筛选条件的成员数
+	Page     int                 `json:"page"`
+	PageSize int                 `json:"page_size"`
+}
+
+// ==================== 群置顶消息和群亮点相关模型 ====================
+
+// 群置顶消息和群亮点相关常量
+const (
+	CollectionGroupPinnedMessages = "group_pinned_messages" // 群置顶消息，每个群每条消息一条
+
+	DefaultMaxGroupPinnedMessages = 10 // 未配置时每个群最多置顶的消息数
+
+	PinLimitReject      = "reject"       // 置顶数达到上限时拒绝新的置顶
+	PinLimitEvictOldest = "evict_oldest" // 置顶数达到上限时取消排在最后的置顶
+
+	CacheKeyGroupPinLock = "msg_group_pin_lock" // 群置顶操作锁 msg_group_pin_lock:{groupID}，同一个群的置顶和排序串行执行，避免并发置顶超过上限
+	GroupPinLockTTL      = 5 * time.Second
+	GroupPinLockWait     = 2 * time.Second
+
+	MessageTypeGroupPinsUpdate = 113 // 群置顶变更，推送给群成员，content为GroupPinsUpdate JSON，客户端据此刷新置顶横幅
+	EventTypeGroupPinsUpdated  = "group_pins_updated"
+
+	GroupPinActionPin     = "pin"
+	GroupPinActionUnpin   = "unpin"
+	GroupPinActionReorder = "reorder"
+
+	HighlightTypeAnnouncement  = "announcement"   // 群当前生效的公告
+	HighlightTypePinnedMessage = "pinned_message" // 群置顶消息
+)
+
+// GroupPinnedMessage 群置顶消息记录，同一个群的置顶按Position从大到小排列
+// 新置顶的消息排在最前，管理员可以手动调整顺序；AnnouncementID非0表示该消息就是这条群公告
+type GroupPinnedMessage struct {
+	ID             primitive.ObjectID `bson:"_id,omitempty" json:"-"`
+	GroupID        int64              `bson:"group_id" json:"group_id"`
+	MessageID      int64              `bson:"message_id" json:"message_id"`
+	Position       int64              `bson:"position" json:"position"`
+	AnnouncementID int64              `bson:"announcement_id,omitempty" json:"announcement_id,omitempty"`
+	PinnedBy       int64              `bson:"pinned_by" json:"pinned_by"`
+	PinnedAt       time.Time          `bson:"pinned_at" json:"pinned_at"`
+}
+
+// GroupPinsUpdate 群置顶变更推送的内容，MessageIDs为变更后按展示顺序排列的全部置顶消息
+type GroupPinsUpdate struct {
+	GroupID    int64   `json:"group_id"`
+	Action     string  `json:"action"`               // pin / unpin / reorder
+	MessageID  int64   `json:"message_id,omitempty"` // 置顶或取消置顶的消息，排序时为0
+	Evicted    []int64 `json:"evicted,omitempty"`    // 因达到上限被取消置顶的消息
+	MessageIDs []int64 `json:"message_ids"`
+	OperatorID int64   `json:"operator_id"`
+	UpdatedAt  int64   `json:"updated_at"` // Unix秒
+}
+
+// GroupHighlight 群亮点条目，群公告和置顶消息统一展示
+// 置顶时关联了生效公告的消息只保留公告条目，Message和置顶信息记录在公告条目上
+type GroupHighlight struct {
+	Type           string   `json:"type"`                      // announcement / pinned_message
+	AnnouncementID int64    `json:"announcement_id,omitempty"` // 公告ID
+	Content        string   `json:"content,omitempty"`         // 公告内容，置顶消息的内容见Message
+	AuthorID       int64    `json:"author_id"`                 // 公告发布者或消息发送者
+	Message        *Message `json:"message,omitempty"`         // 置顶的消息
+	PinnedBy       int64    `json:"pinned_by,omitempty"`
+	PinnedAt       int64    `json:"pinned_at,omitempty"` // 置顶时间（Unix秒），未置顶的公告为0
+	CreatedAt      int64    `json:"created_at"`          // 公告发布时间或消息发送时间（Unix秒）
+}
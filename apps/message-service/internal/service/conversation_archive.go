@@ -0,0 +1,331 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+
+	"goim-social/api/rest"
+	"goim-social/apps/message-service/internal/model"
+	tracecontext "goim-social/pkg/context"
+	"goim-social/pkg/conversation"
+	"goim-social/pkg/logger"
+	"goim-social/pkg/telemetry"
+)
+
+// GetConversations 获取用户的会话列表，按最后一条消息从新到旧排列
+// 默认不含已归档的会话，includeArchived为true时一并返回并标记归档时间
+func (s *Service) GetConversations(ctx context.Context, userID int64, includeArchived bool, page, size int32) ([]*model.ConversationSummary, error) {
+	// 开始OpenTelemetry span
+	ctx, span := telemetry.StartSpan(ctx, "message.service.GetConversations")
+	defer span.End()
+
+	// 设置span属性
+	span.SetAttributes(
+		attribute.Int64("user.id", userID),
+		attribute.Bool("conversation.include_archived", includeArchived),
+		attribute.Int("page", int(page)),
+		attribute.Int("size", int(size)),
+	)
+
+	// 将业务信息添加到context
+	ctx = tracecontext.WithUserID(ctx, userID)
+
+	if userID <= 0 {
+		span.SetStatus(codes.Error, "invalid user id")
+		return nil, fmt.Errorf("无效的用户ID")
+	}
+	if page <= 0 {
+		page = 1
+	}
+	if size <= 0 || size > model.MaxPageSize {
+		size = model.DefaultPageSize
+	}
+
+	groupIDs, err := s.userGroupIDs(ctx, userID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to get user groups")
+		return nil, err
+	}
+
+	// 读取全部归档记录，归档较多时也不会让已归档的会话出现在列表中
+	archives, err := s.dao.GetConversationArchives(ctx, userID, 0)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to get conversation archives")
+		return nil, fmt.Errorf("获取会话归档状态失败: %v", err)
+	}
+	archivedAt := make(map[string]time.Time, len(archives))
+	var excludeIDs []string
+	for _, archive := range archives {
+		archivedAt[archive.ConversationID] = archive.ArchivedAt
+		if !includeArchived {
+			excludeIDs = append(excludeIDs, archive.ConversationID)
+		}
+	}
+
+	offset := int64(page-1) * int64(size)
+	messages, err := s.dao.GetLatestConversationMessages(ctx, userID, groupIDs, excludeIDs, offset, int64(size))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to query conversations")
+		return nil, fmt.Errorf("查询会话列表失败: %v", err)
+	}
+
+	summaries := s.buildConversationSummaries(ctx, userID, messages, archivedAt)
+
+	span.SetAttributes(attribute.Int("result.count", len(summaries)))
+	span.SetStatus(codes.Ok, "conversations retrieved successfully")
+	return summaries, nil
+}
+
+// ArchiveConversation 归档会话，只隐藏会话，不删除消息；仅会话参与者可归档
+func (s *Service) ArchiveConversation(ctx context.Context, userID int64, conversationID string) (*model.ConversationArchive, error) {
+	// 开始OpenTelemetry span
+	ctx, span := telemetry.StartSpan(ctx, "message.service.ArchiveConversation")
+	defer span.End()
+
+	// 设置span属性
+	span.SetAttributes(
+		attribute.Int64("user.id", userID),
+		attribute.String("conversation.id", conversationID),
+	)
+
+	// 将业务信息添加到context
+	ctx = tracecontext.WithUserID(ctx, userID)
+
+	if err := s.checkConversationParticipant(ctx, userID, conversationID); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "permission denied")
+		return nil, err
+	}
+
+	archive, err := s.dao.ArchiveConversation(ctx, userID, conversationID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to archive conversation")
+		s.logger.Error(ctx, "Failed to archive conversation",
+			logger.F("conversationID", conversationID),
+			logger.F("error", err.Error()))
+		return nil, fmt.Errorf("归档会话失败: %v", err)
+	}
+
+	s.logger.Info(ctx, "Conversation archived",
+		logger.F("userID", userID),
+		logger.F("conversationID", conversationID))
+
+	span.SetStatus(codes.Ok, "conversation archived")
+	return archive, nil
+}
+
+// UnarchiveConversation 取消归档会话，会话未归档时直接返回成功
+func (s *Service) UnarchiveConversation(ctx context.Context, userID int64, conversationID string) error {
+	// 开始OpenTelemetry span
+	ctx, span := telemetry.StartSpan(ctx, "message.service.UnarchiveConversation")
+	defer span.End()
+
+	// 设置span属性
+	span.SetAttributes(
+		attribute.Int64("user.id", userID),
+		attribute.String("conversation.id", conversationID),
+	)
+
+	// 将业务信息添加到context
+	ctx = tracecontext.WithUserID(ctx, userID)
+
+	if userID <= 0 {
+		span.SetStatus(codes.Error, "invalid user id")
+		return fmt.Errorf("无效的用户ID")
+	}
+	if _, err := conversation.Parse(conversationID); err != nil {
+		span.SetStatus(codes.Error, "invalid conversation id")
+		return err
+	}
+
+	// 取消归档只删除用户自己的归档记录，已退群的用户也可以取消归档
+	unarchived, err := s.dao.UnarchiveConversation(ctx, userID, conversationID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to unarchive conversation")
+		s.logger.Error(ctx, "Failed to unarchive conversation",
+			logger.F("conversationID", conversationID),
+			logger.F("error", err.Error()))
+		return fmt.Errorf("取消归档会话失败: %v", err)
+	}
+
+	if unarchived {
+		s.logger.Info(ctx, "Conversation unarchived",
+			logger.F("userID", userID),
+			logger.F("conversationID", conversationID))
+	}
+
+	span.SetStatus(codes.Ok, "conversation unarchived")
+	return nil
+}
+
+// ListArchivedConversations 获取用户已归档的会话，按归档时间从新到旧排列，最多MaxArchivedConversations个
+func (s *Service) ListArchivedConversations(ctx context.Context, userID int64) ([]*model.ConversationSummary, error) {
+	// 开始OpenTelemetry span
+	ctx, span := telemetry.StartSpan(ctx, "message.service.ListArchivedConversations")
+	defer span.End()
+
+	// 设置span属性
+	span.SetAttributes(attribute.Int64("user.id", userID))
+
+	// 将业务信息添加到context
+	ctx = tracecontext.WithUserID(ctx, userID)
+
+	if userID <= 0 {
+		span.SetStatus(codes.Error, "invalid user id")
+		return nil, fmt.Errorf("无效的用户ID")
+	}
+
+	archives, err := s.dao.GetConversationArchives(ctx, userID, model.MaxArchivedConversations)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to get conversation archives")
+		return nil, fmt.Errorf("获取已归档会话失败: %v", err)
+	}
+	if len(archives) == 0 {
+		span.SetStatus(codes.Ok, "no archived conversations")
+		return []*model.ConversationSummary{}, nil
+	}
+
+	conversationIDs := make([]string, 0, len(archives))
+	for _, archive := range archives {
+		conversationIDs = append(conversationIDs, archive.ConversationID)
+	}
+	messages, err := s.dao.GetLatestMessagesByConversation(ctx, conversationIDs)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to query archived conversations")
+		return nil, fmt.Errorf("查询已归档会话失败: %v", err)
+	}
+	lastMessages := make(map[string]*model.Message, len(messages))
+	for _, msg := range messages {
+		lastMessages[msg.ConversationID] = msg
+	}
+
+	// 按归档时间排序，消息已全部过期的会话仍然返回，最后一条消息为空
+	summaries := make([]*model.ConversationSummary, 0, len(archives))
+	for _, archive := range archives {
+		summary := s.newConversationSummary(ctx, userID, archive.ConversationID, lastMessages[archive.ConversationID])
+		if summary == nil {
+			continue
+		}
+		archivedAt := archive.ArchivedAt
+		summary.ArchivedAt = &archivedAt
+		summaries = append(summaries, summary)
+	}
+
+	span.SetAttributes(attribute.Int("result.count", len(summaries)))
+	span.SetStatus(codes.Ok, "archived conversations retrieved successfully")
+	return summaries, nil
+}
+
+// unarchiveOnNewMessage 会话收到新消息时为接收方取消归档，发送者自己的归档状态不变
+// 失败只记录日志，不影响消息保存
+func (s *Service) unarchiveOnNewMessage(ctx context.Context, msg *model.Message) {
+	if msg.ConversationID == "" {
+		return
+	}
+
+	count, err := s.dao.UnarchiveForRecipients(ctx, msg.ConversationID, msg.From)
+	if err != nil {
+		s.logger.Warn(ctx, "Failed to unarchive conversation on new message",
+			logger.F("conversationID", msg.ConversationID),
+			logger.F("messageID", msg.MessageID),
+			logger.F("error", err.Error()))
+		return
+	}
+	if count > 0 {
+		s.logger.Debug(ctx, "Conversation unarchived by new message",
+			logger.F("conversationID", msg.ConversationID),
+			logger.F("users", count))
+	}
+}
+
+// buildConversationSummaries 根据每个会话的最后一条消息构建会话摘要
+func (s *Service) buildConversationSummaries(ctx context.Context, userID int64, messages []*model.Message, archivedAt map[string]time.Time) []*model.ConversationSummary {
+	summaries := make([]*model.ConversationSummary, 0, len(messages))
+	for _, msg := range messages {
+		summary := s.newConversationSummary(ctx, userID, msg.ConversationID, msg)
+		if summary == nil {
+			continue
+		}
+		if t, ok := archivedAt[msg.ConversationID]; ok {
+			summary.ArchivedAt = &t
+		}
+		summaries = append(summaries, summary)
+	}
+	return summaries
+}
+
+// newConversationSummary 构建单个会话摘要，会话ID无效时返回nil
+// 最后一条消息解密失败时清空内容，会话仍然返回
+func (s *Service) newConversationSummary(ctx context.Context, userID int64, conversationID string, lastMessage *model.Message) *model.ConversationSummary {
+	conv, err := conversation.Parse(conversationID)
+	if err != nil {
+		s.logger.Warn(ctx, "Skip conversation with invalid id",
+			logger.F("conversationID", conversationID))
+		return nil
+	}
+
+	summary := &model.ConversationSummary{
+		ConversationID: conversationID,
+		PeerID:         conv.Peer(userID),
+		GroupID:        conv.GroupID,
+		LastMessage:    lastMessage,
+	}
+	if lastMessage != nil {
+		if err := s.decryptMessage(lastMessage); err != nil {
+			s.logger.Warn(ctx, "Failed to decrypt last message",
+				logger.F("messageID", lastMessage.MessageID),
+				logger.F("error", err.Error()))
+			lastMessage.Content = ""
+		}
+	}
+	return summary
+}
+
+// checkConversationParticipant 验证用户是否为会话参与者，单聊须为双方之一，群聊须为群成员
+func (s *Service) checkConversationParticipant(ctx context.Context, userID int64, conversationID string) error {
+	if userID <= 0 {
+		return fmt.Errorf("无效的用户ID")
+	}
+
+	conv, err := conversation.Parse(conversationID)
+	if err != nil {
+		return err
+	}
+	if conv.IsGroup() {
+		return s.checkGroupMember(ctx, userID, conv.GroupID)
+	}
+	if conv.Peer(userID) == 0 {
+		return fmt.Errorf("用户不是会话参与者")
+	}
+	return nil
+}
+
+// userGroupIDs 获取用户所在的群组ID列表，用于查询群聊会话
+func (s *Service) userGroupIDs(ctx context.Context, userID int64) ([]int64, error) {
+	if s.social == nil {
+		return nil, fmt.Errorf("社交服务不可用")
+	}
+
+	resp, err := s.social.GetUserSocialInfo(ctx, &rest.GetUserSocialInfoRequest{UserId: userID})
+	if err != nil {
+		return nil, fmt.Errorf("获取用户群组失败: %v", err)
+	}
+	if !resp.Success {
+		return nil, fmt.Errorf("获取用户群组失败: %s", resp.Message)
+	}
+	if resp.SocialInfo == nil {
+		return nil, nil
+	}
+	return resp.SocialInfo.GroupIds, nil
+}
@@ -0,0 +1,235 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+
+	"goim-social/apps/message-service/internal/model"
+	tracecontext "goim-social/pkg/context"
+	"goim-social/pkg/conversation"
+	"goim-social/pkg/logger"
+	"goim-social/pkg/membership"
+	"goim-social/pkg/telemetry"
+)
+
+// SearchConversationMessages 在会话内按关键词搜索消息，按消息ID倒序分页，游标为上一页最后扫描到的消息ID
+// 仅会话参与者可搜索，已撤回、已删除和已过期的消息不参与搜索
+// 消息内容可能加密存储，因此逐批解密后匹配；单次请求最多扫描ConversationSearchMaxScan条消息，
+// 达到上限时即使命中数不足一页也返回游标，客户端按游标继续搜索更早的消息
+func (s *Service) SearchConversationMessages(ctx context.Context, userID int64, query *model.ConversationSearchQuery) (*model.ConversationSearchPage, error) {
+	// 开始OpenTelemetry span
+	ctx, span := telemetry.StartSpan(ctx, "message.service.SearchConversationMessages")
+	defer span.End()
+
+	// 设置span属性
+	span.SetAttributes(
+		attribute.Int64("user.id", userID),
+		attribute.String("conversation.id", query.ConversationID),
+		attribute.Int64("search.sender_id", query.SenderID),
+		attribute.String("search.cursor", query.Cursor),
+	)
+
+	// 将业务信息添加到context
+	ctx = tracecontext.WithUserID(ctx, userID)
+
+	keyword := strings.TrimSpace(query.Query)
+	if keyword == "" {
+		span.SetStatus(codes.Error, "empty query")
+		return nil, fmt.Errorf("搜索关键词不能为空")
+	}
+	if len([]rune(keyword)) > model.MaxSearchQueryLength {
+		span.SetStatus(codes.Error, "query too long")
+		return nil, fmt.Errorf("搜索关键词过长，最多%d个字符", model.MaxSearchQueryLength)
+	}
+	if !query.StartTime.IsZero() && !query.EndTime.IsZero() && query.StartTime.After(query.EndTime) {
+		span.SetStatus(codes.Error, "invalid time range")
+		return nil, fmt.Errorf("开始时间不能晚于结束时间")
+	}
+
+	var beforeID int64
+	if query.Cursor != "" {
+		id, err := strconv.ParseInt(query.Cursor, 10, 64)
+		if err != nil || id <= 0 {
+			span.SetStatus(codes.Error, "invalid search cursor")
+			return nil, fmt.Errorf("无效的游标: %s", query.Cursor)
+		}
+		beforeID = id
+	}
+
+	limit := query.Limit
+	if limit <= 0 {
+		limit = model.DefaultPageSize
+	}
+	if limit > model.MaxPageSize {
+		limit = model.MaxPageSize
+	}
+
+	if err := s.checkConversationParticipant(ctx, userID, query.ConversationID); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "permission denied")
+		return nil, err
+	}
+
+	// 群聊只能搜到在群期间的消息，时段查询失败时拒绝搜索
+	if conv, err := conversation.Parse(query.ConversationID); err == nil && conv.IsGroup() {
+		windows, err := membership.Fetch(ctx, s.social, userID, conv.GroupID)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, "failed to get membership periods")
+			return nil, err
+		}
+		if len(windows) == 0 {
+			span.SetStatus(codes.Ok, "no visible window")
+			return &model.ConversationSearchPage{Hits: []*model.ConversationSearchHit{}, PageSize: limit}, nil
+		}
+		query.Windows = windows
+	}
+
+	matcher := newSnippetMatcher(keyword, s.highlightCfg.PreTag, s.highlightCfg.PostTag, s.highlightCfg.FragmentSize)
+	page := &model.ConversationSearchPage{Hits: []*model.ConversationSearchHit{}, PageSize: limit}
+	scanned := 0
+	for {
+		messages, err := s.dao.FindConversationMessagesBefore(ctx, query, beforeID, model.ConversationSearchBatchSize)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, "failed to query conversation messages")
+			s.logger.Error(ctx, "Failed to search conversation messages",
+				logger.F("conversationID", query.ConversationID),
+				logger.F("error", err.Error()))
+			return nil, fmt.Errorf("搜索会话消息失败: %v", err)
+		}
+
+		for _, msg := range messages {
+			scanned++
+			beforeID = msg.MessageID
+			if err := s.decryptMessage(msg); err != nil {
+				s.logger.Warn(ctx, "Skip undecryptable message in conversation search",
+					logger.F("messageID", msg.MessageID),
+					logger.F("error", err.Error()))
+				continue
+			}
+			if snippet, ok := matcher.snippet(msg.Content); ok {
+				page.Hits = append(page.Hits, &model.ConversationSearchHit{Message: msg, Snippet: snippet})
+			}
+			// 命中一页或达到扫描上限时停止，游标取最后扫描到的消息，下一页从更早的消息继续
+			if len(page.Hits) >= int(limit) || scanned >= model.ConversationSearchMaxScan {
+				page.NextCursor = strconv.FormatInt(beforeID, 10)
+				break
+			}
+		}
+		if page.NextCursor != "" || len(messages) < model.ConversationSearchBatchSize {
+			break
+		}
+	}
+
+	span.SetAttributes(
+		attribute.Int("result.count", len(page.Hits)),
+		attribute.Int("search.scanned", scanned),
+		attribute.Bool("result.has_more", page.NextCursor != ""),
+	)
+	span.SetStatus(codes.Ok, "conversation messages searched successfully")
+	return page, nil
+}
+
+// snippetMatcher 按关键词不区分大小写匹配消息内容并生成高亮摘要
+type snippetMatcher struct {
+	keyword []rune
+	preTag  string
+	postTag string
+	length  int
+}
+
+// newSnippetMatcher 创建摘要匹配器，length不大于0时使用默认摘要长度
+func newSnippetMatcher(keyword, preTag, postTag string, length int) *snippetMatcher {
+	if length <= 0 {
+		length = model.DefaultSearchFragmentSize
+	}
+	return &snippetMatcher{
+		keyword: foldRunes([]rune(keyword)),
+		preTag:  preTag,
+		postTag: postTag,
+		length:  length,
+	}
+}
+
+// snippet 内容包含关键词时返回以第一处匹配为中心的摘要，原文已转义，摘要内的匹配以高亮标签包裹
+func (m *snippetMatcher) snippet(content string) (string, bool) {
+	runes := []rune(content)
+	folded := foldRunes(runes)
+	first := m.indexFrom(folded, 0)
+	if first < 0 {
+		return "", false
+	}
+
+	// 摘要窗口以第一处匹配为中心，不超过内容边界
+	start, end := 0, len(runes)
+	if len(runes) > m.length {
+		start = first - (m.length-len(m.keyword))/2
+		if start > first {
+			start = first
+		}
+		if start < 0 {
+			start = 0
+		}
+		end = start + m.length
+		if end > len(runes) {
+			end = len(runes)
+			start = end - m.length
+		}
+	}
+
+	var b strings.Builder
+	if start > 0 {
+		b.WriteString(model.SearchSnippetEllipsis)
+	}
+	pos := start
+	for pos < end {
+		i := m.indexFrom(folded[:end], pos)
+		if i < 0 {
+			break
+		}
+		b.WriteString(html.EscapeString(string(runes[pos:i])))
+		b.WriteString(m.preTag)
+		b.WriteString(html.EscapeString(string(runes[i : i+len(m.keyword)])))
+		b.WriteString(m.postTag)
+		pos = i + len(m.keyword)
+	}
+	b.WriteString(html.EscapeString(string(runes[pos:end])))
+	if end < len(runes) {
+		b.WriteString(model.SearchSnippetEllipsis)
+	}
+	return b.String(), true
+}
+
+// indexFrom 返回关键词在from之后第一次出现的位置，未找到返回-1
+func (m *snippetMatcher) indexFrom(folded []rune, from int) int {
+	for i := from; i+len(m.keyword) <= len(folded); i++ {
+		matched := true
+		for j, r := range m.keyword {
+			if folded[i+j] != r {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return i
+		}
+	}
+	return -1
+}
+
+// foldRunes 逐字符转为小写，保持与原文相同的字符位置
+func foldRunes(runes []rune) []rune {
+	folded := make([]rune, len(runes))
+	for i, r := range runes {
+		folded[i] = unicode.ToLower(r)
+	}
+	return folded
+}
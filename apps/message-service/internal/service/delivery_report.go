@@ -0,0 +1,383 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	goredis "github.com/go-redis/redis/v8"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+
+	"goim-social/api/rest"
+	"goim-social/apps/message-service/internal/model"
+	tracecontext "goim-social/pkg/context"
+	"goim-social/pkg/logger"
+	"goim-social/pkg/telemetry"
+)
+
+// ==================== 群消息投递和已读汇总 ====================
+// 投递事件消费者在Redis中记录群消息的成员投递结果，已读情况来自群已读水位，两者变化时所在群被标记为待汇总；
+// 汇总任务按间隔认领待汇总的群，计算群内跟踪中的消息的汇总，与上次推送相比有变化时推送给发送者（消息类型112），
+// 大群的发送者不再逐个接收成员回执，需要时通过 GetMessageReadStatus 分页查询明细；所有成员都已读的消息停止跟踪
+
+// GetMessageReadStatus 获取群消息的投递和已读汇总及分页明细，群成员均可查询
+// 已读按成员的已读水位判断，未读成员按投递结果区分已送达、离线待拉取和推送失败，投递结果已过保留期时为pending
+func (s *Service) GetMessageReadStatus(ctx context.Context, userID, groupID, messageID int64, status string, page, pageSize int) (*model.MessageReadStatus, error) {
+	// 开始OpenTelemetry span
+	ctx, span := telemetry.StartSpan(ctx, "message.service.GetMessageReadStatus")
+	defer span.End()
+
+	// 设置span属性
+	span.SetAttributes(
+		attribute.Int64("group.id", groupID),
+		attribute.Int64("group.user_id", userID),
+		attribute.Int64("message.id", messageID),
+		attribute.String("message.read_status_filter", status),
+	)
+
+	// 将业务信息添加到context
+	ctx = tracecontext.WithUserID(ctx, userID)
+	ctx = tracecontext.WithGroupID(ctx, groupID)
+	ctx = tracecontext.WithMessageID(ctx, messageID)
+
+	// 参数验证
+	if userID <= 0 || groupID <= 0 || messageID <= 0 {
+		span.SetStatus(codes.Error, "invalid parameters")
+		return nil, fmt.Errorf("无效的用户ID、群组ID或消息ID")
+	}
+	if !model.IsReadStatusFilter(status) {
+		span.SetStatus(codes.Error, "invalid status filter")
+		return nil, fmt.Errorf("无效的状态筛选条件: %s", status)
+	}
+	if page <= 0 {
+		page = 1
+	}
+	if pageSize <= 0 {
+		pageSize = model.DefaultReadStatusPageSize
+	}
+	pageSize = min(pageSize, model.MaxReadStatusPageSize)
+
+	// 权限验证
+	if err := s.checkGroupMember(ctx, userID, groupID); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "permission denied")
+		return nil, err
+	}
+
+	message, err := s.getGroupMessage(ctx, groupID, messageID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "invalid message")
+		return nil, err
+	}
+
+	memberResp, err := s.social.GetGroupMemberIDs(ctx, &rest.GetGroupMemberIDsRequest{GroupId: groupID})
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to get group members")
+		return nil, fmt.Errorf("获取群成员列表失败: %v", err)
+	}
+	if !memberResp.Success {
+		span.SetStatus(codes.Error, "failed to get group members")
+		return nil, fmt.Errorf("获取群成员列表失败: %s", memberResp.Message)
+	}
+
+	watermarks, err := s.readWatermarks(ctx, groupID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to get read watermarks")
+		s.logger.Error(ctx, "Failed to get group read watermarks",
+			logger.F("groupID", groupID),
+			logger.F("error", err.Error()))
+		return nil, fmt.Errorf("获取群已读水位失败: %v", err)
+	}
+
+	// 投递结果只用于区分未读成员的状态，读取失败时按pending返回
+	deliveries := map[string]string{}
+	if s.redis != nil {
+		deliveries, err = s.redis.HGetAll(ctx, deliveryKey(messageID))
+		if err != nil {
+			s.logger.Warn(ctx, "Failed to get message deliveries",
+				logger.F("messageID", messageID),
+				logger.F("error", err.Error()))
+			deliveries = map[string]string{}
+		}
+	}
+
+	members := make([]*model.MemberReadStatus, 0, len(memberResp.MemberIds))
+	for _, memberID := range memberResp.MemberIds {
+		if memberID == message.From {
+			continue
+		}
+		members = append(members, &model.MemberReadStatus{
+			UserID: memberID,
+			Status: memberDeliveryStatus(memberID, messageID, deliveries, watermarks),
+		})
+	}
+	sort.Slice(members, func(i, j int) bool { return members[i].UserID < members[j].UserID })
+
+	result := &model.MessageReadStatus{
+		Report:   buildDeliveryReport(messageID, groupID, members),
+		Page:     page,
+		PageSize: pageSize,
+	}
+	result.Members, result.Total = pageMemberReadStatus(members, status, page, pageSize)
+
+	span.SetAttributes(
+		attribute.Int64("message.delivered_count", result.Report.Delivered),
+		attribute.Int64("message.read_count", result.Report.Read),
+		attribute.Int64("message.read_status_total", result.Total),
+	)
+
+	s.logger.Info(ctx, "Message read status retrieved successfully",
+		logger.F("groupID", groupID),
+		logger.F("messageID", messageID),
+		logger.F("delivered", result.Report.Delivered),
+		logger.F("read", result.Report.Read))
+
+	span.SetStatus(codes.Ok, "message read status retrieved successfully")
+	return result, nil
+}
+
+// RunDeliveryReportFlusher 按汇总间隔向发送者推送待汇总群的投递和已读汇总，ctx取消时退出
+func (s *Service) RunDeliveryReportFlusher(ctx context.Context) {
+	interval := time.Duration(s.reportCfg.SummaryInterval) * time.Second
+	if interval <= 0 {
+		interval = model.DefaultDeliveryReportPeriod
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.flushDeliveryReports(ctx)
+		}
+	}
+}
+
+// flushDeliveryReports 分批认领本轮开始前标记的待汇总群，多实例下移除成功的实例负责该群
+// 本轮处理期间再次变化的群留到下一轮，保证同一条消息在一个间隔内最多推送一次
+func (s *Service) flushDeliveryReports(ctx context.Context) {
+	if s.redis == nil {
+		return
+	}
+
+	client := s.redis.GetClient()
+	until := strconv.FormatInt(time.Now().UnixMilli(), 10)
+	for ctx.Err() == nil {
+		groups, err := s.redis.ZRangeByScore(ctx, model.CacheKeyDeliveryDirty, &goredis.ZRangeBy{
+			Min:   "-inf",
+			Max:   until,
+			Count: model.DeliveryReportBatchSize,
+		})
+		if err != nil {
+			s.logger.Warn(ctx, "Failed to scan delivery report groups", logger.F("error", err.Error()))
+			return
+		}
+
+		for _, member := range groups {
+			claimed, err := client.ZRem(ctx, model.CacheKeyDeliveryDirty, member).Result()
+			if err != nil || claimed == 0 {
+				continue
+			}
+			groupID, err := strconv.ParseInt(member, 10, 64)
+			if err != nil {
+				continue
+			}
+			s.reportGroupDeliveries(ctx, groupID)
+		}
+		if len(groups) < model.DeliveryReportBatchSize {
+			return
+		}
+	}
+}
+
+// reportGroupDeliveries 汇总群内跟踪中的消息，汇总有变化时推送给发送者
+// 同一个群只查询一次成员和已读水位；投递结果已过期或所有成员都已读的消息停止跟踪
+func (s *Service) reportGroupDeliveries(ctx context.Context, groupID int64) {
+	client := s.redis.GetClient()
+	groupKey := fmt.Sprintf("%s:%d", model.CacheKeyDeliveryGroupPrefix, groupID)
+	ids, err := client.ZRange(ctx, groupKey, 0, -1).Result()
+	if err != nil || len(ids) == 0 {
+		return
+	}
+
+	memberIDs := s.groupMemberIDs(ctx, groupID)
+	watermarks, err := s.readWatermarks(ctx, groupID)
+	if len(memberIDs) == 0 || err != nil {
+		// 成员或水位获取失败时保留待汇总标记，下一轮重试
+		s.markDeliveryDirty(ctx, groupID)
+		return
+	}
+	pushable := s.reportCfg.MinGroupSize <= 0 || len(memberIDs) >= s.reportCfg.MinGroupSize
+
+	pipe := client.Pipeline()
+	cmds := make([]*goredis.StringStringMapCmd, len(ids))
+	for i, id := range ids {
+		cmds[i] = pipe.HGetAll(ctx, model.CacheKeyDeliveryPrefix+":"+id)
+	}
+	if _, err := pipe.Exec(ctx); err != nil && err != goredis.Nil {
+		s.logger.Warn(ctx, "Failed to get group message deliveries",
+			logger.F("groupID", groupID),
+			logger.F("error", err.Error()))
+		s.markDeliveryDirty(ctx, groupID)
+		return
+	}
+
+	for i, id := range ids {
+		messageID, err := strconv.ParseInt(id, 10, 64)
+		deliveries := cmds[i].Val()
+		if err != nil || len(deliveries) == 0 {
+			client.ZRem(ctx, groupKey, id)
+			continue
+		}
+		senderID, _ := strconv.ParseInt(deliveries[model.DeliveryFieldSender], 10, 64)
+
+		members := make([]*model.MemberReadStatus, 0, len(memberIDs))
+		for _, memberID := range memberIDs {
+			if memberID == senderID {
+				continue
+			}
+			members = append(members, &model.MemberReadStatus{
+				UserID: memberID,
+				Status: memberDeliveryStatus(memberID, messageID, deliveries, watermarks),
+			})
+		}
+		report := buildDeliveryReport(messageID, groupID, members)
+		if report.Read >= report.RecipientCount {
+			client.ZRem(ctx, groupKey, id)
+		}
+
+		counts := fmt.Sprintf("%d:%d:%d:%d:%d", report.RecipientCount, report.Delivered, report.Read, report.Offline, report.Failed)
+		if !pushable || senderID <= 0 || deliveries[model.DeliveryFieldReported] == counts {
+			continue
+		}
+		if err := s.pushDeliveryReport(senderID, report); err != nil {
+			s.logger.Warn(ctx, "Failed to push delivery report",
+				logger.F("messageID", messageID),
+				logger.F("senderID", senderID),
+				logger.F("error", err.Error()))
+			continue
+		}
+		client.HSet(ctx, model.CacheKeyDeliveryPrefix+":"+id, model.DeliveryFieldReported, counts)
+	}
+}
+
+// pushDeliveryReport 通过下行推送向发送者推送汇总
+func (s *Service) pushDeliveryReport(senderID int64, report *model.DeliveryReport) error {
+	if s.kafka == nil {
+		return fmt.Errorf("Kafka生产者未初始化")
+	}
+
+	content, err := json.Marshal(report)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().Unix()
+	event := &rest.MessageEvent{
+		Type: model.EventTypeDeliveryReport,
+		Message: &rest.WSMessage{
+			MessageId:   report.MessageID,
+			From:        senderID,
+			To:          senderID,
+			GroupId:     report.GroupID,
+			Content:     string(content),
+			MessageType: model.MessageTypeDeliveryReport,
+			Timestamp:   now,
+		},
+		Timestamp: now,
+	}
+	return s.kafka.PublishMessage(model.TopicDownlinkMessage, event)
+}
+
+// markDeliveryDirty 标记群的投递或已读有变化，已标记时保留首次标记的时间
+func (s *Service) markDeliveryDirty(ctx context.Context, groupID int64) {
+	if s.redis == nil || !s.reportCfg.Enabled {
+		return
+	}
+	if err := s.redis.GetClient().ZAddNX(ctx, model.CacheKeyDeliveryDirty, &goredis.Z{
+		Score:  float64(time.Now().UnixMilli()),
+		Member: groupID,
+	}).Err(); err != nil {
+		s.logger.Warn(ctx, "Failed to mark delivery report group",
+			logger.F("groupID", groupID),
+			logger.F("error", err.Error()))
+	}
+}
+
+// readWatermarks 获取群成员的已读水位，按成员ID索引
+func (s *Service) readWatermarks(ctx context.Context, groupID int64) (map[int64]int64, error) {
+	watermarks, err := s.dao.GetGroupReadWatermarks(ctx, groupID)
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[int64]int64, len(watermarks))
+	for _, watermark := range watermarks {
+		result[watermark.UserID] = watermark.MessageID
+	}
+	return result, nil
+}
+
+// deliveryKey 群消息成员投递结果的键
+func deliveryKey(messageID int64) string {
+	return fmt.Sprintf("%s:%d", model.CacheKeyDeliveryPrefix, messageID)
+}
+
+// memberDeliveryStatus 成员对消息的状态，水位不低于该消息即为已读，否则取投递结果
+func memberDeliveryStatus(memberID, messageID int64, deliveries map[string]string, watermarks map[int64]int64) string {
+	if watermarks[memberID] >= messageID {
+		return model.DeliveryStatusRead
+	}
+	if status, ok := deliveries[strconv.FormatInt(memberID, 10)]; ok {
+		return status
+	}
+	return model.DeliveryStatusPending
+}
+
+// pageMemberReadStatus 按状态筛选成员明细并分页，返回当前页和符合筛选条件的总数，status为空表示不筛选
+func pageMemberReadStatus(members []*model.MemberReadStatus, status string, page, pageSize int) ([]*model.MemberReadStatus, int64) {
+	paged := []*model.MemberReadStatus{}
+	var total int64
+	for _, member := range members {
+		if status != "" && member.Status != status {
+			continue
+		}
+		if total >= int64((page-1)*pageSize) && len(paged) < pageSize {
+			paged = append(paged, member)
+		}
+		total++
+	}
+	return paged, total
+}
+
+// buildDeliveryReport 按成员状态汇总，已读的成员同时计入已送达
+func buildDeliveryReport(messageID, groupID int64, members []*model.MemberReadStatus) *model.DeliveryReport {
+	report := &model.DeliveryReport{
+		MessageID:      messageID,
+		GroupID:        groupID,
+		RecipientCount: int64(len(members)),
+		UpdatedAt:      time.Now().Unix(),
+	}
+	for _, member := range members {
+		switch member.Status {
+		case model.DeliveryStatusRead:
+			report.Read++
+			report.Delivered++
+		case model.FanoutStatusDelivered:
+			report.Delivered++
+		case model.FanoutStatusQueuedOffline:
+			report.Offline++
+		case model.FanoutStatusFailed:
+			report.Failed++
+		}
+	}
+	return report
+}
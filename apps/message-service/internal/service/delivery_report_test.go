@@ -0,0 +1,99 @@
+package service
+
+import (
+	"testing"
+
+	"goim-social/apps/message-service/internal/model"
+)
+
+// TestMemberDeliveryStatus 已读水位优先于投递结果，两者都没有时为pending
+func TestMemberDeliveryStatus(t *testing.T) {
+	deliveries := map[string]string{
+		"1": model.FanoutStatusDelivered,
+		"2": model.FanoutStatusQueuedOffline,
+		"3": model.FanoutStatusFailed,
+	}
+	watermarks := map[int64]int64{1: 100, 2: 99}
+	cases := []struct {
+		memberID int64
+		want     string
+	}{
+		{memberID: 1, want: model.DeliveryStatusRead},
+		{memberID: 2, want: model.FanoutStatusQueuedOffline},
+		{memberID: 3, want: model.FanoutStatusFailed},
+		{memberID: 4, want: model.DeliveryStatusPending},
+	}
+	for _, tc := range cases {
+		if got := memberDeliveryStatus(tc.memberID, 100, deliveries, watermarks); got != tc.want {
+			t.Errorf("memberDeliveryStatus(%d) = %q, want %q", tc.memberID, got, tc.want)
+		}
+	}
+}
+
+// TestBuildDeliveryReport 已读成员同时计入已送达，pending不计入任何分类
+func TestBuildDeliveryReport(t *testing.T) {
+	members := []*model.MemberReadStatus{
+		{UserID: 1, Status: model.DeliveryStatusRead},
+		{UserID: 2, Status: model.DeliveryStatusRead},
+		{UserID: 3, Status: model.FanoutStatusDelivered},
+		{UserID: 4, Status: model.FanoutStatusQueuedOffline},
+		{UserID: 5, Status: model.FanoutStatusFailed},
+		{UserID: 6, Status: model.DeliveryStatusPending},
+	}
+	report := buildDeliveryReport(100, 10, members)
+	if report.MessageID != 100 || report.GroupID != 10 {
+		t.Errorf("report ids = %d/%d, want 100/10", report.MessageID, report.GroupID)
+	}
+	if report.RecipientCount != 6 || report.Delivered != 3 || report.Read != 2 || report.Offline != 1 || report.Failed != 1 {
+		t.Errorf("report = %+v, want recipients 6, delivered 3, read 2, offline 1, failed 1", report)
+	}
+}
+
+// TestPageMemberReadStatus 先筛选再分页，总数为符合筛选条件的成员数
+func TestPageMemberReadStatus(t *testing.T) {
+	var members []*model.MemberReadStatus
+	for i := int64(1); i <= 5; i++ {
+		status := model.DeliveryStatusRead
+		if i%2 == 0 {
+			status = model.FanoutStatusDelivered
+		}
+		members = append(members, &model.MemberReadStatus{UserID: i, Status: status})
+	}
+	cases := []struct {
+		name      string
+		status    string
+		page      int
+		pageSize  int
+		wantIDs   []int64
+		wantTotal int64
+	}{
+		{name: "first page", page: 1, pageSize: 2, wantIDs: []int64{1, 2}, wantTotal: 5},
+		{name: "last page", page: 3, pageSize: 2, wantIDs: []int64{5}, wantTotal: 5},
+		{name: "past end", page: 4, pageSize: 2, wantIDs: []int64{}, wantTotal: 5},
+		{name: "filtered", status: model.DeliveryStatusRead, page: 2, pageSize: 2, wantIDs: []int64{5}, wantTotal: 3},
+		{name: "no match", status: model.FanoutStatusFailed, page: 1, pageSize: 2, wantIDs: []int64{}, wantTotal: 0},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			paged, total := pageMemberReadStatus(members, tc.status, tc.page, tc.pageSize)
+			if total != tc.wantTotal {
+				t.Errorf("total = %d, want %d", total, tc.wantTotal)
+			}
+			if paged == nil {
+				t.Fatalf("paged = nil, want empty slice")
+			}
+			ids := make([]int64, 0, len(paged))
+			for _, member := range paged {
+				ids = append(ids, member.UserID)
+			}
+			if len(ids) != len(tc.wantIDs) {
+				t.Fatalf("ids = %v, want %v", ids, tc.wantIDs)
+			}
+			for i := range ids {
+				if ids[i] != tc.wantIDs[i] {
+					t.Fatalf("ids = %v, want %v", ids, tc.wantIDs)
+				}
+			}
+		})
+	}
+}
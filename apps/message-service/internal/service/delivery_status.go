@@ -0,0 +1,157 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+
+	"goim-social/apps/message-service/internal/model"
+	"goim-social/pkg/logger"
+	"goim-social/pkg/telemetry"
+)
+
+// PushStatsSource 推送统计来源，由推送消费者实现
+type PushStatsSource interface {
+	GatewayStats() []*model.GatewayPushStat
+	LastConsumedAt() time.Time
+	AckResendStats() *model.AckResendStat
+}
+
+// PushAckTracker 推送确认跟踪，由推送消费者实现，客户端确认后不再超时重发
+type PushAckTracker interface {
+	Acknowledge(ctx context.Context, userID int64, messageIDs []int64)
+}
+
+// FanoutStatsSource 扇出投递结果统计来源，由投递结果事件消费者实现
+type FanoutStatsSource interface {
+	FanoutStats() *model.FanoutDeliveryStat
+}
+
+// GetDeliveryStatus 检查消息投递链路（推送消费者 -> Redis频道 -> Connect实例）的状态
+// Redis不可用，或近期有推送的Connect实例已不再订阅推送频道时为unhealthy；待确认积压过多时为degraded
+func (s *Service) GetDeliveryStatus(ctx context.Context) (*model.DeliveryStatus, error) {
+	// 开始OpenTelemetry span
+	ctx, span := telemetry.StartSpan(ctx, "message.service.GetDeliveryStatus")
+	defer span.End()
+
+	now := time.Now()
+	status := &model.DeliveryStatus{
+		Status:    model.DeliveryHealthy,
+		Problems:  []string{},
+		CheckedAt: now,
+	}
+
+	if s.redis == nil {
+		status.Status = model.DeliveryUnhealthy
+		status.Problems = append(status.Problems, "Redis未初始化")
+		span.SetStatus(codes.Error, "redis not initialized")
+		return status, nil
+	}
+	if err := s.redis.Ping(ctx); err != nil {
+		status.Status = model.DeliveryUnhealthy
+		status.Problems = append(status.Problems, fmt.Sprintf("Redis不可用: %v", err))
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "redis unavailable")
+		return status, nil
+	}
+
+	// 当前订阅推送频道的Connect实例
+	gateways := make(map[string]*model.GatewayDeliveryStatus)
+	channels, err := s.redis.PubSubChannels(ctx, model.GatewayForwardChannelPrefix+":*")
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to list channels")
+		return nil, fmt.Errorf("获取推送频道失败: %v", err)
+	}
+	for _, channel := range channels {
+		serverID := strings.TrimPrefix(channel, model.GatewayForwardChannelPrefix+":")
+		gateways[serverID] = &model.GatewayDeliveryStatus{GatewayPushStat: model.GatewayPushStat{ServerID: serverID}}
+	}
+
+	// 合并本实例推送统计，近期有推送但已不在订阅列表中的实例同样纳入检查
+	if s.pushStats != nil {
+		status.LastConsumedAt = s.pushStats.LastConsumedAt()
+		status.AckResend = s.pushStats.AckResendStats()
+		for _, stat := range s.pushStats.GatewayStats() {
+			gateway, ok := gateways[stat.ServerID]
+			if !ok {
+				if now.Sub(stat.LastPushAt) > model.DeliveryActivityWindow {
+					continue
+				}
+				gateway = &model.GatewayDeliveryStatus{}
+				gateways[stat.ServerID] = gateway
+			}
+			gateway.GatewayPushStat = *stat
+		}
+	}
+
+	serverIDs := make([]string, 0, len(gateways))
+	channelNames := make([]string, 0, len(gateways))
+	for serverID := range gateways {
+		serverIDs = append(serverIDs, serverID)
+		channelNames = append(channelNames, model.GatewayForwardChannelPrefix+":"+serverID)
+	}
+	sort.Strings(serverIDs)
+
+	if len(channelNames) > 0 {
+		subscribers, err := s.redis.PubSubNumSub(ctx, channelNames...)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, "failed to count subscribers")
+			return nil, fmt.Errorf("获取推送频道订阅数失败: %v", err)
+		}
+		for serverID, gateway := range gateways {
+			gateway.Subscribers = subscribers[model.GatewayForwardChannelPrefix+":"+serverID]
+		}
+	}
+
+	for _, serverID := range serverIDs {
+		gateway := gateways[serverID]
+		status.Gateways = append(status.Gateways, gateway)
+		if gateway.Subscribers > 0 {
+			status.SubscribedGateways++
+			continue
+		}
+		status.Status = model.DeliveryUnhealthy
+		status.Problems = append(status.Problems, fmt.Sprintf("Connect实例%s近期有推送但未订阅推送频道", serverID))
+	}
+
+	if s.fanout != nil {
+		status.Fanout = s.fanout.FanoutStats()
+	}
+
+	// 待确认积压：超过宽限期仍未被接收方确认的单聊消息
+	pending, err := s.dao.CountPendingAckMessages(ctx, now.Add(-model.PendingAckWindow), now.Add(-model.PendingAckGrace))
+	if err != nil {
+		s.logger.Warn(ctx, "Failed to count pending ack messages", logger.F("error", err.Error()))
+		status.Problems = append(status.Problems, fmt.Sprintf("统计待确认消息失败: %v", err))
+		if status.Status == model.DeliveryHealthy {
+			status.Status = model.DeliveryDegraded
+		}
+	} else {
+		status.PendingAck = pending
+		if pending > model.PendingAckDegradedThreshold && status.Status == model.DeliveryHealthy {
+			status.Status = model.DeliveryDegraded
+			status.Problems = append(status.Problems, fmt.Sprintf("待确认消息积压: %d", pending))
+		}
+	}
+
+	span.SetAttributes(
+		attribute.String("delivery.status", status.Status),
+		attribute.Int64("delivery.subscribed_gateways", status.SubscribedGateways),
+		attribute.Int64("delivery.pending_ack", status.PendingAck),
+	)
+	if status.Status != model.DeliveryHealthy {
+		s.logger.Warn(ctx, "Delivery path not healthy",
+			logger.F("status", status.Status),
+			logger.F("problems", strings.Join(status.Problems, "; ")))
+	}
+
+	span.SetStatus(codes.Ok, "delivery status checked")
+	return status, nil
+}
@@ -0,0 +1,497 @@
+package service
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+
+	"goim-social/api/rest"
+	"goim-social/apps/message-service/internal/dao"
+	"goim-social/apps/message-service/internal/model"
+	tracecontext "goim-social/pkg/context"
+	"goim-social/pkg/httpx"
+	"goim-social/pkg/logger"
+	"goim-social/pkg/telemetry"
+)
+
+// ExportGroupHistory 导出成员可见范围内的群聊记录
+// 启用入群时间限制时起始时间不早于成员入群时间；消息数超过异步阈值时后台生成，调用方通过导出ID查询进度
+// 每次导出都会保存导出记录用于审计
+func (s *Service) ExportGroupHistory(ctx context.Context, userID, groupID int64, format string, startTime, endTime int64) (*model.GroupExport, error) {
+	// 开始OpenTelemetry span
+	ctx, span := telemetry.StartSpan(ctx, "message.service.ExportGroupHistory")
+	defer span.End()
+
+	// 设置span属性
+	span.SetAttributes(
+		attribute.Int64("user.id", userID),
+		attribute.Int64("group.id", groupID),
+		attribute.String("export.format", format),
+	)
+
+	// 将业务信息添加到context
+	ctx = tracecontext.WithUserID(ctx, userID)
+	ctx = tracecontext.WithGroupID(ctx, groupID)
+
+	// 参数验证
+	if userID <= 0 || groupID <= 0 {
+		span.SetStatus(codes.Error, "invalid parameters")
+		return nil, fmt.Errorf("无效的用户ID或群组ID")
+	}
+	if format == "" {
+		format = model.ExportFormatJSON
+	}
+	if format != model.ExportFormatJSON && format != model.ExportFormatCSV {
+		span.SetStatus(codes.Error, "invalid format")
+		return nil, fmt.Errorf("不支持的导出格式: %s，支持json和csv", format)
+	}
+	end := time.Now()
+	if endTime > 0 && endTime < end.Unix() {
+		end = time.Unix(endTime, 0)
+	}
+	start := time.Unix(max(startTime, 0), 0)
+	if start.After(end) {
+		span.SetStatus(codes.Error, "invalid time range")
+		return nil, fmt.Errorf("无效的时间范围")
+	}
+
+	joinedAt, err := s.getGroupJoinedAt(ctx, userID, groupID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "permission denied")
+		return nil, err
+	}
+	if s.exportCfg.RespectJoinTime && joinedAt.After(start) {
+		start = joinedAt
+	}
+	if start.After(end) {
+		span.SetStatus(codes.Error, "empty range")
+		return nil, fmt.Errorf("所选时间范围早于入群时间，没有可导出的消息")
+	}
+
+	if err := s.checkExportRate(ctx, userID); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "rate limited")
+		return nil, err
+	}
+
+	count, err := s.dao.CountGroupMessages(ctx, groupID, start, end)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to count messages")
+		return nil, fmt.Errorf("统计消息数量失败: %v", err)
+	}
+
+	export := &model.GroupExport{
+		ExportID:     primitive.NewObjectID().Hex(),
+		GroupID:      groupID,
+		UserID:       userID,
+		Format:       format,
+		Status:       model.ExportStatusPending,
+		StartTime:    start,
+		EndTime:      end,
+		MessageCount: count,
+		CreatedAt:    time.Now(),
+	}
+	if err := s.dao.CreateGroupExport(ctx, export); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to create export")
+		return nil, fmt.Errorf("创建导出记录失败: %v", err)
+	}
+
+	s.logger.Info(ctx, "Group history export requested",
+		logger.F("exportID", export.ExportID),
+		logger.F("userID", userID),
+		logger.F("groupID", groupID),
+		logger.F("format", format),
+		logger.F("startTime", start.Unix()),
+		logger.F("endTime", end.Unix()),
+		logger.F("messageCount", count))
+
+	span.SetAttributes(
+		attribute.String("export.id", export.ExportID),
+		attribute.Int64("export.message_count", count),
+	)
+
+	if count > int64(s.exportCfg.AsyncThreshold) {
+		// 后台任务使用副本，避免与返回给调用方的记录并发读写
+		pending := *export
+		select {
+		case s.exportQueue <- &pending:
+		default:
+			err := fmt.Errorf("导出任务繁忙，请稍后重试")
+			s.failGroupExport(ctx, export, err)
+			span.SetStatus(codes.Error, "export queue full")
+			return nil, httpx.Unavailable(err)
+		}
+		span.SetStatus(codes.Ok, "export scheduled")
+		return export, nil
+	}
+
+	s.generateGroupExport(ctx, export)
+	span.SetStatus(codes.Ok, "export generated")
+	return export, nil
+}
+
+// GetGroupExport 获取导出记录，只有发起导出的用户可以查看
+func (s *Service) GetGroupExport(ctx context.Context, userID int64, exportID string) (*model.GroupExport, error) {
+	// 开始OpenTelemetry span
+	ctx, span := telemetry.StartSpan(ctx, "message.service.GetGroupExport")
+	defer span.End()
+
+	// 设置span属性
+	span.SetAttributes(
+		attribute.Int64("user.id", userID),
+		attribute.String("export.id", exportID),
+	)
+
+	// 将业务信息添加到context
+	ctx = tracecontext.WithUserID(ctx, userID)
+
+	if userID <= 0 || exportID == "" {
+		span.SetStatus(codes.Error, "invalid parameters")
+		return nil, fmt.Errorf("无效的用户ID或导出ID")
+	}
+
+	export, err := s.dao.GetGroupExport(ctx, exportID)
+	if err != nil {
+		if errors.Is(err, dao.ErrNotFound) {
+			span.SetStatus(codes.Error, "export not found")
+			return nil, fmt.Errorf("导出记录不存在: %s", exportID)
+		}
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to get export")
+		return nil, fmt.Errorf("获取导出记录失败: %v", err)
+	}
+	if export.UserID != userID {
+		span.SetStatus(codes.Error, "permission denied")
+		return nil, fmt.Errorf("导出记录不存在: %s", exportID)
+	}
+
+	span.SetStatus(codes.Ok, "export retrieved")
+	return export, nil
+}
+
+// getGroupJoinedAt 验证群成员身份并返回入群时间，社交服务未返回入群时间时按零值处理
+func (s *Service) getGroupJoinedAt(ctx context.Context, userID, groupID int64) (time.Time, error) {
+	if s.social == nil {
+		return time.Time{}, fmt.Errorf("社交服务不可用")
+	}
+
+	resp, err := s.social.ValidateGroupMember(ctx, &rest.ValidateGroupMemberRequest{
+		GroupId: groupID,
+		UserId:  userID,
+	})
+	if err != nil {
+		return time.Time{}, fmt.Errorf("验证群成员身份失败: %v", err)
+	}
+	if !resp.Success {
+		return time.Time{}, fmt.Errorf("验证群成员身份失败: %s", resp.Message)
+	}
+	if !resp.IsMember {
+		return time.Time{}, fmt.Errorf("用户不是群成员")
+	}
+	if resp.JoinedAt <= 0 {
+		return time.Time{}, nil
+	}
+	return time.Unix(resp.JoinedAt, 0), nil
+}
+
+// checkExportRate 按用户限制导出频率，Redis不可用时不限流
+func (s *Service) checkExportRate(ctx context.Context, userID int64) error {
+	if s.redis == nil || s.exportCfg.RateLimit <= 0 {
+		return nil
+	}
+
+	key := fmt.Sprintf("%s:%d", model.CacheKeyExportRate, userID)
+	count, err := s.redis.GetClient().Incr(ctx, key).Result()
+	if err != nil {
+		s.logger.Warn(ctx, "Failed to check export rate", logger.F("userID", userID), logger.F("error", err.Error()))
+		return nil
+	}
+	if count == 1 {
+		s.redis.Expire(ctx, key, time.Duration(s.exportCfg.RateWindow)*time.Second)
+	}
+	if count > int64(s.exportCfg.RateLimit) {
+		return fmt.Errorf("导出过于频繁，每%d秒最多导出%d次", s.exportCfg.RateWindow, s.exportCfg.RateLimit)
+	}
+	return nil
+}
+
+// RunExportWorker 依次生成排队的异步导出，并定期删除超过保留时间的导出文件，ctx取消时退出
+// 退出时正在生成的导出因ctx取消而失败，仍在排队的导出同样记为失败，由用户重新发起
+func (s *Service) RunExportWorker(ctx context.Context) {
+	ticker := time.NewTicker(model.ExportCleanupInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			s.failQueuedExports(context.WithoutCancel(ctx))
+			return
+		case export := <-s.exportQueue:
+			s.generateGroupExport(ctx, export)
+		case <-ticker.C:
+			s.cleanupGroupExports(ctx)
+		}
+	}
+}
+
+// failQueuedExports 将队列中尚未生成的导出记为失败
+func (s *Service) failQueuedExports(ctx context.Context) {
+	for {
+		select {
+		case export := <-s.exportQueue:
+			s.failGroupExport(ctx, export, fmt.Errorf("服务退出，导出未生成，请重新导出"))
+		default:
+			return
+		}
+	}
+}
+
+// generateGroupExport 分批读取消息写入导出文件并更新导出记录，生成时间受导出超时限制
+// 先写入临时文件，全部写完后再重命名，下载时不会读到不完整的文件
+func (s *Service) generateGroupExport(ctx context.Context, export *model.GroupExport) {
+	genCtx := ctx
+	if s.exportCfg.Timeout > 0 {
+		var cancel context.CancelFunc
+		genCtx, cancel = context.WithTimeout(ctx, time.Duration(s.exportCfg.Timeout)*time.Second)
+		defer cancel()
+	}
+
+	path := filepath.Join(s.exportCfg.Dir, export.ExportID+"."+export.Format)
+	count, err := s.writeGroupExport(genCtx, export, path)
+	// 超时或服务退出时ctx已结束，结果仍需写入导出记录
+	ctx = context.WithoutCancel(ctx)
+	if err != nil {
+		s.failGroupExport(ctx, export, err)
+		return
+	}
+
+	now := time.Now()
+	export.CompletedAt = &now
+	export.Status = model.ExportStatusReady
+	export.MessageCount = count
+	export.FilePath = path
+	s.logger.Info(ctx, "Group history export generated",
+		logger.F("exportID", export.ExportID),
+		logger.F("groupID", export.GroupID),
+		logger.F("messageCount", count))
+	s.finishGroupExport(ctx, export)
+}
+
+// failGroupExport 将导出记为失败并保存失败原因
+func (s *Service) failGroupExport(ctx context.Context, export *model.GroupExport, cause error) {
+	now := time.Now()
+	export.CompletedAt = &now
+	export.Status = model.ExportStatusFailed
+	export.Error = cause.Error()
+	s.logger.Error(ctx, "Failed to generate group history export",
+		logger.F("exportID", export.ExportID),
+		logger.F("groupID", export.GroupID),
+		logger.F("error", cause.Error()))
+	s.finishGroupExport(ctx, export)
+}
+
+// finishGroupExport 保存导出结果，失败只记录日志
+func (s *Service) finishGroupExport(ctx context.Context, export *model.GroupExport) {
+	if err := s.dao.FinishGroupExport(ctx, export); err != nil {
+		s.logger.Error(ctx, "Failed to update group export",
+			logger.F("exportID", export.ExportID),
+			logger.F("error", err.Error()))
+	}
+}
+
+// cleanupGroupExports 删除超过保留时间的导出文件并将导出记为已过期，单批不足批量大小时说明已清理完毕
+func (s *Service) cleanupGroupExports(ctx context.Context) {
+	if s.exportCfg.FileTTL <= 0 {
+		return
+	}
+
+	before := time.Now().Add(-time.Duration(s.exportCfg.FileTTL) * time.Second)
+	for ctx.Err() == nil {
+		exports, err := s.dao.FindGroupExportsCreatedBefore(ctx, model.ExportStatusReady, before, model.ExportCleanupBatchSize)
+		if err != nil {
+			s.logger.Error(ctx, "Failed to find expired group exports", logger.F("error", err.Error()))
+			return
+		}
+
+		for _, export := range exports {
+			if err := os.Remove(export.FilePath); err != nil && !errors.Is(err, os.ErrNotExist) {
+				// 文件删除失败时保留记录等下一轮重试，并结束本轮，否则下一批会再次查到该记录
+				s.logger.Warn(ctx, "Failed to remove expired export file",
+					logger.F("exportID", export.ExportID),
+					logger.F("error", err.Error()))
+				return
+			}
+			export.Status = model.ExportStatusExpired
+			export.FilePath = ""
+			if err := s.dao.FinishGroupExport(ctx, export); err != nil {
+				s.logger.Error(ctx, "Failed to expire group export",
+					logger.F("exportID", export.ExportID),
+					logger.F("error", err.Error()))
+				return
+			}
+		}
+
+		if len(exports) > 0 {
+			s.logger.Info(ctx, "Expired group exports removed", logger.F("count", len(exports)))
+		}
+		if len(exports) < model.ExportCleanupBatchSize {
+			return
+		}
+	}
+}
+
+// writeGroupExport 将导出范围内的消息写入文件，返回写入的消息数
+func (s *Service) writeGroupExport(ctx context.Context, export *model.GroupExport, path string) (int64, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return 0, fmt.Errorf("创建导出目录失败: %v", err)
+	}
+
+	tmpPath := path + ".tmp"
+	file, err := os.Create(tmpPath)
+	if err != nil {
+		return 0, fmt.Errorf("创建导出文件失败: %v", err)
+	}
+	defer os.Remove(tmpPath)
+
+	writer := newExportWriter(export.Format, file)
+	var (
+		count  int64
+		lastID int64
+	)
+	for {
+		if err := ctx.Err(); err != nil {
+			file.Close()
+			return 0, fmt.Errorf("导出未完成: %v", err)
+		}
+		messages, err := s.dao.FindGroupMessagesAfter(ctx, export.GroupID, export.StartTime, export.EndTime, lastID, model.ExportBatchSize)
+		if err != nil {
+			file.Close()
+			return 0, fmt.Errorf("读取群消息失败: %v", err)
+		}
+
+		for _, msg := range messages {
+			lastID = msg.MessageID
+			if err := s.decryptMessage(msg); err != nil {
+				s.logger.Warn(ctx, "Skip undecryptable message in export",
+					logger.F("exportID", export.ExportID),
+					logger.F("messageID", msg.MessageID))
+				continue
+			}
+			if err := writer.write(&model.ExportedMessage{
+				MessageID:   msg.MessageID,
+				From:        msg.From,
+				MessageType: msg.MessageType,
+				Content:     msg.Content,
+				CreatedAt:   msg.CreatedAt,
+			}); err != nil {
+				file.Close()
+				return 0, fmt.Errorf("写入导出文件失败: %v", err)
+			}
+			count++
+		}
+
+		if len(messages) < model.ExportBatchSize {
+			break
+		}
+	}
+
+	if err := writer.close(); err != nil {
+		file.Close()
+		return 0, fmt.Errorf("写入导出文件失败: %v", err)
+	}
+	if err := file.Close(); err != nil {
+		return 0, fmt.Errorf("写入导出文件失败: %v", err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		return 0, fmt.Errorf("保存导出文件失败: %v", err)
+	}
+	return count, nil
+}
+
+// exportCSVHeader CSV导出文件表头
+var exportCSVHeader = []string{"message_id", "from", "message_type", "content", "created_at"}
+
+// exportWriter 导出文件写入器，JSON格式为消息数组，CSV格式首行为表头
+type exportWriter struct {
+	format  string
+	w       io.Writer
+	csv     *csv.Writer
+	written bool
+}
+
+// newExportWriter 创建导出文件写入器
+func newExportWriter(format string, w io.Writer) *exportWriter {
+	ew := &exportWriter{format: format, w: w}
+	if format == model.ExportFormatCSV {
+		ew.csv = csv.NewWriter(w)
+	}
+	return ew
+}
+
+// write 写入一条消息
+func (ew *exportWriter) write(msg *model.ExportedMessage) error {
+	if ew.csv != nil {
+		if !ew.written {
+			if err := ew.csv.Write(exportCSVHeader); err != nil {
+				return err
+			}
+			ew.written = true
+		}
+		return ew.csv.Write([]string{
+			strconv.FormatInt(msg.MessageID, 10),
+			strconv.FormatInt(msg.From, 10),
+			strconv.Itoa(msg.MessageType),
+			msg.Content,
+			msg.CreatedAt.Format(time.RFC3339),
+		})
+	}
+
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	sep := ","
+	if !ew.written {
+		sep = "["
+		ew.written = true
+	}
+	if _, err := io.WriteString(ew.w, sep); err != nil {
+		return err
+	}
+	_, err = ew.w.Write(data)
+	return err
+}
+
+// close 写入结尾，没有消息时JSON输出空数组，CSV只输出表头
+func (ew *exportWriter) close() error {
+	if ew.csv != nil {
+		if !ew.written {
+			if err := ew.csv.Write(exportCSVHeader); err != nil {
+				return err
+			}
+		}
+		ew.csv.Flush()
+		return ew.csv.Error()
+	}
+
+	if !ew.written {
+		_, err := io.WriteString(ew.w, "[]")
+		return err
+	}
+	_, err := io.WriteString(ew.w, "]")
+	return err
+}
@@ -0,0 +1,216 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+
+	"goim-social/apps/message-service/internal/dao"
+	"goim-social/apps/message-service/internal/model"
+	"goim-social/pkg/config"
+)
+
+// exportDAO 在内存中保存导出记录
+type exportDAO struct {
+	*memoryDAO
+	exports map[string]*model.GroupExport
+}
+
+func (d *exportDAO) CreateGroupExport(ctx context.Context, export *model.GroupExport) error {
+	saved := *export
+	d.exports[export.ExportID] = &saved
+	return nil
+}
+
+func (d *exportDAO) FinishGroupExport(ctx context.Context, export *model.GroupExport) error {
+	saved := *export
+	d.exports[export.ExportID] = &saved
+	return nil
+}
+
+func (d *exportDAO) GetGroupExport(ctx context.Context, exportID string) (*model.GroupExport, error) {
+	export, ok := d.exports[exportID]
+	if !ok {
+		return nil, dao.ErrNotFound
+	}
+	saved := *export
+	return &saved, nil
+}
+
+func (d *exportDAO) FindGroupExportsCreatedBefore(ctx context.Context, status string, before time.Time, limit int64) ([]*model.GroupExport, error) {
+	var exports []*model.GroupExport
+	for _, export := range d.exports {
+		if export.Status == status && export.CreatedAt.Before(before) {
+			saved := *export
+			exports = append(exports, &saved)
+		}
+	}
+	sort.Slice(exports, func(i, j int) bool { return exports[i].CreatedAt.Before(exports[j].CreatedAt) })
+	if int64(len(exports)) > limit {
+		exports = exports[:limit]
+	}
+	return exports, nil
+}
+
+// newExportService 创建导出文件写入临时目录、导出记录保存在内存中的服务
+func newExportService(t *testing.T) (*Service, *dao.MemoryStore, *exportDAO) {
+	t.Helper()
+	s, store := newMemoryService(t)
+	exports := &exportDAO{memoryDAO: s.dao.(*memoryDAO), exports: make(map[string]*model.GroupExport)}
+	s.dao = exports
+	s.exportCfg = config.MessageExportConfig{Dir: t.TempDir(), Timeout: 60, FileTTL: 3600}
+	s.exportQueue = make(chan *model.GroupExport, model.ExportQueueSize)
+	return s, store, exports
+}
+
+// TestExportWriter JSON格式输出消息数组，CSV格式首行为表头，没有消息时输出空数组或只有表头
+func TestExportWriter(t *testing.T) {
+	at := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	messages := []*model.ExportedMessage{
+		{MessageID: 1, From: 7, MessageType: 1, Content: "hi", CreatedAt: at},
+		{MessageID: 2, From: 8, MessageType: 1, Content: "a,\"b\"", CreatedAt: at},
+	}
+	cases := []struct {
+		name     string
+		format   string
+		messages []*model.ExportedMessage
+		want     string
+	}{
+		{name: "json empty", format: model.ExportFormatJSON, want: "[]"},
+		{name: "csv empty", format: model.ExportFormatCSV, want: "message_id,from,message_type,content,created_at\n"},
+		{
+			name: "json", format: model.ExportFormatJSON, messages: messages,
+			want: `[{"message_id":1,"from":7,"message_type":1,"content":"hi","created_at":"2024-01-02T03:04:05Z"},` +
+				`{"message_id":2,"from":8,"message_type":1,"content":"a,\"b\"","created_at":"2024-01-02T03:04:05Z"}]`,
+		},
+		{
+			name: "csv", format: model.ExportFormatCSV, messages: messages,
+			want: "message_id,from,message_type,content,created_at\n" +
+				"1,7,1,hi,2024-01-02T03:04:05Z\n" +
+				"2,8,1,\"a,\"\"b\"\"\",2024-01-02T03:04:05Z\n",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			w := newExportWriter(tc.format, &buf)
+			for _, msg := range tc.messages {
+				if err := w.write(msg); err != nil {
+					t.Fatalf("write returned error: %v", err)
+				}
+			}
+			if err := w.close(); err != nil {
+				t.Fatalf("close returned error: %v", err)
+			}
+			if got := buf.String(); got != tc.want {
+				t.Errorf("output = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+// TestGenerateGroupExport 导出范围内的群消息写入文件并记为可下载，不留下临时文件
+func TestGenerateGroupExport(t *testing.T) {
+	s, store, exports := newExportService(t)
+	now := time.Now()
+	saveTestMessage(t, store, &model.Message{MessageID: 1, From: 1, GroupID: 9, Content: "a", CreatedAt: now.Add(-3 * time.Minute)})
+	saveTestMessage(t, store, &model.Message{MessageID: 2, From: 2, GroupID: 9, Content: "b", CreatedAt: now.Add(-2 * time.Minute)})
+	saveTestMessage(t, store, &model.Message{MessageID: 3, From: 1, GroupID: 10, Content: "c", CreatedAt: now.Add(-time.Minute)})
+	saveTestMessage(t, store, &model.Message{MessageID: 4, From: 1, GroupID: 9, Content: "d", CreatedAt: now.Add(-time.Hour)})
+
+	export := &model.GroupExport{ExportID: "e1", GroupID: 9, Format: model.ExportFormatJSON, StartTime: now.Add(-10 * time.Minute), EndTime: now, CreatedAt: now}
+	s.generateGroupExport(context.Background(), export)
+
+	saved := exports.exports["e1"]
+	if saved == nil || saved.Status != model.ExportStatusReady || saved.MessageCount != 2 {
+		t.Fatalf("export = %+v, want ready with 2 messages", saved)
+	}
+	data, err := os.ReadFile(saved.FilePath)
+	if err != nil {
+		t.Fatalf("ReadFile returned error: %v", err)
+	}
+	var got []model.ExportedMessage
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("export file is not valid JSON: %v", err)
+	}
+	if len(got) != 2 || got[0].MessageID != 1 || got[1].MessageID != 2 {
+		t.Errorf("exported messages = %+v, want messages 1 and 2", got)
+	}
+	if _, err := os.Stat(saved.FilePath + ".tmp"); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("temporary file left behind: %v", err)
+	}
+}
+
+// TestGenerateGroupExportCanceled ctx取消时导出记为失败，不留下导出文件和临时文件
+func TestGenerateGroupExportCanceled(t *testing.T) {
+	s, store, exports := newExportService(t)
+	now := time.Now()
+	saveTestMessage(t, store, &model.Message{MessageID: 1, From: 1, GroupID: 9, Content: "a", CreatedAt: now.Add(-time.Minute)})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	s.generateGroupExport(ctx, &model.GroupExport{ExportID: "e1", GroupID: 9, Format: model.ExportFormatCSV, StartTime: now.Add(-time.Hour), EndTime: now, CreatedAt: now})
+
+	if saved := exports.exports["e1"]; saved == nil || saved.Status != model.ExportStatusFailed {
+		t.Fatalf("export = %+v, want failed", saved)
+	}
+	files, err := os.ReadDir(s.exportCfg.Dir)
+	if err != nil {
+		t.Fatalf("ReadDir returned error: %v", err)
+	}
+	if len(files) != 0 {
+		t.Errorf("export dir has %d files, want none", len(files))
+	}
+}
+
+// TestFailQueuedExports 服务退出时仍在排队的导出记为失败
+func TestFailQueuedExports(t *testing.T) {
+	s, _, exports := newExportService(t)
+	s.exportQueue <- &model.GroupExport{ExportID: "e1", Status: model.ExportStatusPending}
+
+	s.failQueuedExports(context.Background())
+
+	if saved := exports.exports["e1"]; saved == nil || saved.Status != model.ExportStatusFailed {
+		t.Errorf("export = %+v, want failed", saved)
+	}
+}
+
+// TestCleanupGroupExports 超过保留时间的导出删除文件并记为已过期，未到期的保留
+func TestCleanupGroupExports(t *testing.T) {
+	s, _, exports := newExportService(t)
+	now := time.Now()
+	oldPath := filepath.Join(s.exportCfg.Dir, "old.json")
+	newPath := filepath.Join(s.exportCfg.Dir, "new.json")
+	for _, path := range []string{oldPath, newPath} {
+		if err := os.WriteFile(path, []byte("[]"), 0o644); err != nil {
+			t.Fatalf("WriteFile returned error: %v", err)
+		}
+	}
+	exports.exports["old"] = &model.GroupExport{ExportID: "old", Status: model.ExportStatusReady, FilePath: oldPath, CreatedAt: now.Add(-2 * time.Hour)}
+	exports.exports["new"] = &model.GroupExport{ExportID: "new", Status: model.ExportStatusReady, FilePath: newPath, CreatedAt: now}
+	// 文件已不存在的过期导出同样记为已过期
+	exports.exports["gone"] = &model.GroupExport{ExportID: "gone", Status: model.ExportStatusReady, FilePath: filepath.Join(s.exportCfg.Dir, "gone.json"), CreatedAt: now.Add(-2 * time.Hour)}
+
+	s.cleanupGroupExports(context.Background())
+
+	for _, id := range []string{"old", "gone"} {
+		if saved := exports.exports[id]; saved.Status != model.ExportStatusExpired || saved.FilePath != "" {
+			t.Errorf("export %s = %+v, want expired without file", id, saved)
+		}
+	}
+	if _, err := os.Stat(oldPath); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("expired export file still exists: %v", err)
+	}
+	if saved := exports.exports["new"]; saved.Status != model.ExportStatusReady {
+		t.Errorf("recent export = %+v, want ready", saved)
+	}
+	if _, err := os.Stat(newPath); err != nil {
+		t.Errorf("recent export file removed: %v", err)
+	}
+}
@@ -0,0 +1,594 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+
+	"goim-social/api/rest"
+	"goim-social/apps/message-service/internal/model"
+	tracecontext "goim-social/pkg/context"
+	"goim-social/pkg/httpx"
+	"goim-social/pkg/logger"
+	"goim-social/pkg/redis"
+	"goim-social/pkg/telemetry"
+)
+
+// ==================== 群置顶消息和群亮点 ====================
+// 群公告由社交服务维护，置顶消息保存在消息服务，群亮点把两者合并为一个列表供客户端展示群头部：
+// 生效公告在前，置顶消息按排序位置，新置顶的排在最前。查看群亮点只需群成员身份，置顶、取消置顶和调整顺序与发布公告一样仅群主和管理员可操作，
+// 变更后推送给群成员。置顶时关联了生效公告的消息视为公告被置顶，只保留公告条目；已删除、已撤回或已过期的置顶消息在查看时清理
+
+// GetGroupHighlights 获取群亮点，仅群成员可查看
+func (s *Service) GetGroupHighlights(ctx context.Context, userID, groupID int64) ([]*model.GroupHighlight, error) {
+	// 开始OpenTelemetry span
+	ctx, span := telemetry.StartSpan(ctx, "message.service.GetGroupHighlights")
+	defer span.End()
+
+	// 设置span属性
+	span.SetAttributes(
+		attribute.Int64("user.id", userID),
+		attribute.Int64("group.id", groupID),
+	)
+
+	// 将业务信息添加到context
+	ctx = tracecontext.WithUserID(ctx, userID)
+	ctx = tracecontext.WithGroupID(ctx, groupID)
+
+	if userID <= 0 || groupID <= 0 {
+		span.SetStatus(codes.Error, "invalid parameters")
+		return nil, httpx.InvalidArgument(fmt.Errorf("无效的用户ID或群组ID"))
+	}
+
+	if err := s.checkGroupMember(ctx, userID, groupID); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "permission denied")
+		return nil, err
+	}
+
+	highlights, err := s.buildGroupHighlights(ctx, groupID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to build group highlights")
+		return nil, err
+	}
+
+	span.SetAttributes(attribute.Int("group.highlight_count", len(highlights)))
+	span.SetStatus(codes.Ok, "group highlights retrieved")
+	return highlights, nil
+}
+
+// PinGroupMessage 置顶群消息，仅群主和管理员可操作，消息已置顶时不改变原置顶记录，返回最新的群亮点
+// 新置顶的消息排在最前；达到每群置顶上限时按配置拒绝或取消排在最后的置顶。announcementID非0时须为群当前生效的公告，
+// 表示该消息就是这条公告，群亮点中合并为一个条目
+func (s *Service) PinGroupMessage(ctx context.Context, userID, groupID, messageID, announcementID int64) ([]*model.GroupHighlight, error) {
+	// 开始OpenTelemetry span
+	ctx, span := telemetry.StartSpan(ctx, "message.service.PinGroupMessage")
+	defer span.End()
+
+	// 设置span属性
+	span.SetAttributes(
+		attribute.Int64("user.id", userID),
+		attribute.Int64("group.id", groupID),
+		attribute.Int64("message.id", messageID),
+	)
+
+	// 将业务信息添加到context
+	ctx = tracecontext.WithUserID(ctx, userID)
+	ctx = tracecontext.WithGroupID(ctx, groupID)
+	ctx = tracecontext.WithMessageID(ctx, messageID)
+
+	if userID <= 0 || groupID <= 0 || messageID <= 0 || announcementID < 0 {
+		span.SetStatus(codes.Error, "invalid parameters")
+		return nil, httpx.InvalidArgument(fmt.Errorf("无效的用户ID、群组ID或消息ID"))
+	}
+
+	if err := s.checkGroupAdmin(ctx, userID, groupID); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "permission denied")
+		return nil, err
+	}
+
+	message, err := s.getGroupMessage(ctx, groupID, messageID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to get message")
+		return nil, httpx.InvalidArgument(err)
+	}
+	if !pinnable(message, time.Now()) {
+		span.SetStatus(codes.Error, "message not pinnable")
+		return nil, httpx.InvalidArgument(fmt.Errorf("消息已撤回或已过期，不能置顶"))
+	}
+	if announcementID > 0 {
+		announcement := s.activeAnnouncement(ctx, groupID)
+		if announcement == nil || announcement.AnnouncementID != announcementID {
+			span.SetStatus(codes.Error, "announcement not active")
+			return nil, httpx.InvalidArgument(fmt.Errorf("公告不存在或已不是群的生效公告"))
+		}
+	}
+
+	var update *model.GroupPinsUpdate
+	pin := func(ctx context.Context) error {
+		pins, err := s.dao.GetGroupPinnedMessages(ctx, groupID)
+		if err != nil {
+			return httpx.Unavailable(fmt.Errorf("获取置顶消息失败: %v", err))
+		}
+		for _, existing := range pins {
+			if existing.MessageID == messageID {
+				return nil
+			}
+		}
+
+		limit := s.maxGroupPins()
+		evicted := pinEvictions(pins, limit)
+		if len(evicted) > 0 && s.pinCfg.OnLimit != model.PinLimitEvictOldest {
+			return httpx.Conflict(fmt.Errorf("每个群最多置顶%d条消息，请先取消其他消息的置顶", limit))
+		}
+		for _, oldest := range evicted {
+			if err := s.unpinMessage(ctx, oldest.GroupID, oldest.MessageID); err != nil {
+				return httpx.Unavailable(fmt.Errorf("取消置顶失败: %v", err))
+			}
+		}
+
+		// 先在消息上设置置顶标记再写置顶记录，保证置顶消息不会被保留期清理
+		if err := s.dao.SetMessagePinned(ctx, messageID, true); err != nil {
+			return httpx.Unavailable(fmt.Errorf("置顶消息失败: %v", err))
+		}
+		created, err := s.dao.PinGroupMessage(ctx, &model.GroupPinnedMessage{
+			GroupID:        groupID,
+			MessageID:      messageID,
+			Position:       nextPinPosition(pins),
+			AnnouncementID: announcementID,
+			PinnedBy:       userID,
+			PinnedAt:       time.Now(),
+		})
+		if err != nil {
+			if clearErr := s.dao.SetMessagePinned(ctx, messageID, false); clearErr != nil {
+				s.logger.Warn(ctx, "Failed to clear pinned flag",
+					logger.F("messageID", messageID),
+					logger.F("error", clearErr.Error()))
+			}
+			return httpx.Unavailable(fmt.Errorf("置顶消息失败: %v", err))
+		}
+		if !created {
+			return nil
+		}
+
+		remaining := pins[:len(pins)-len(evicted)]
+		update = &model.GroupPinsUpdate{
+			GroupID:    groupID,
+			Action:     model.GroupPinActionPin,
+			MessageID:  messageID,
+			Evicted:    pinnedMessageIDs(evicted),
+			MessageIDs: append([]int64{messageID}, pinnedMessageIDs(remaining)...),
+			OperatorID: userID,
+		}
+		return nil
+	}
+	if err := s.withGroupPinLock(ctx, groupID, pin); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to pin message")
+		s.logger.Error(ctx, "Failed to pin group message",
+			logger.F("groupID", groupID),
+			logger.F("messageID", messageID),
+			logger.F("error", err.Error()))
+		return nil, err
+	}
+	if update != nil {
+		s.logger.Info(ctx, "Group message pinned",
+			logger.F("groupID", groupID),
+			logger.F("messageID", messageID),
+			logger.F("evicted", update.Evicted),
+			logger.F("operatorID", userID))
+		go s.notifyGroupPinsUpdated(context.WithoutCancel(ctx), update)
+	}
+
+	highlights, err := s.buildGroupHighlights(ctx, groupID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to build group highlights")
+		return nil, err
+	}
+
+	span.SetStatus(codes.Ok, "group message pinned")
+	return highlights, nil
+}
+
+// UnpinGroupMessage 取消置顶群消息，仅群主和管理员可操作，消息未置顶时直接返回最新的群亮点
+func (s *Service) UnpinGroupMessage(ctx context.Context, userID, groupID, messageID int64) ([]*model.GroupHighlight, error) {
+	// 开始OpenTelemetry span
+	ctx, span := telemetry.StartSpan(ctx, "message.service.UnpinGroupMessage")
+	defer span.End()
+
+	// 设置span属性
+	span.SetAttributes(
+		attribute.Int64("user.id", userID),
+		attribute.Int64("group.id", groupID),
+		attribute.Int64("message.id", messageID),
+	)
+
+	// 将业务信息添加到context
+	ctx = tracecontext.WithUserID(ctx, userID)
+	ctx = tracecontext.WithGroupID(ctx, groupID)
+	ctx = tracecontext.WithMessageID(ctx, messageID)
+
+	if userID <= 0 || groupID <= 0 || messageID <= 0 {
+		span.SetStatus(codes.Error, "invalid parameters")
+		return nil, httpx.InvalidArgument(fmt.Errorf("无效的用户ID、群组ID或消息ID"))
+	}
+
+	if err := s.checkGroupAdmin(ctx, userID, groupID); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "permission denied")
+		return nil, err
+	}
+
+	var update *model.GroupPinsUpdate
+	unpin := func(ctx context.Context) error {
+		pins, err := s.dao.GetGroupPinnedMessages(ctx, groupID)
+		if err != nil {
+			return httpx.Unavailable(fmt.Errorf("获取置顶消息失败: %v", err))
+		}
+		remaining := make([]*model.GroupPinnedMessage, 0, len(pins))
+		for _, pin := range pins {
+			if pin.MessageID != messageID {
+				remaining = append(remaining, pin)
+			}
+		}
+		if len(remaining) == len(pins) {
+			return nil
+		}
+
+		if err := s.unpinMessage(ctx, groupID, messageID); err != nil {
+			return httpx.Unavailable(fmt.Errorf("取消置顶失败: %v", err))
+		}
+		update = &model.GroupPinsUpdate{
+			GroupID:    groupID,
+			Action:     model.GroupPinActionUnpin,
+			MessageID:  messageID,
+			MessageIDs: pinnedMessageIDs(remaining),
+			OperatorID: userID,
+		}
+		return nil
+	}
+	if err := s.withGroupPinLock(ctx, groupID, unpin); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to unpin message")
+		s.logger.Error(ctx, "Failed to unpin group message",
+			logger.F("groupID", groupID),
+			logger.F("messageID", messageID),
+			logger.F("error", err.Error()))
+		return nil, err
+	}
+	if update != nil {
+		s.logger.Info(ctx, "Group message unpinned",
+			logger.F("groupID", groupID),
+			logger.F("messageID", messageID),
+			logger.F("operatorID", userID))
+		go s.notifyGroupPinsUpdated(context.WithoutCancel(ctx), update)
+	}
+
+	highlights, err := s.buildGroupHighlights(ctx, groupID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to build group highlights")
+		return nil, err
+	}
+
+	span.SetStatus(codes.Ok, "group message unpinned")
+	return highlights, nil
+}
+
+// ReorderGroupPins 调整群置顶消息的展示顺序，仅群主和管理员可操作
+// messageIDs须恰好包含当前全部置顶消息，避免基于过期列表的排序覆盖其他管理员刚做的置顶变更
+func (s *Service) ReorderGroupPins(ctx context.Context, userID, groupID int64, messageIDs []int64) ([]*model.GroupHighlight, error) {
+	// 开始OpenTelemetry span
+	ctx, span := telemetry.StartSpan(ctx, "message.service.ReorderGroupPins")
+	defer span.End()
+
+	// 设置span属性
+	span.SetAttributes(
+		attribute.Int64("user.id", userID),
+		attribute.Int64("group.id", groupID),
+		attribute.Int("pin.count", len(messageIDs)),
+	)
+
+	// 将业务信息添加到context
+	ctx = tracecontext.WithUserID(ctx, userID)
+	ctx = tracecontext.WithGroupID(ctx, groupID)
+
+	if userID <= 0 || groupID <= 0 || len(messageIDs) == 0 {
+		span.SetStatus(codes.Error, "invalid parameters")
+		return nil, httpx.InvalidArgument(fmt.Errorf("无效的用户ID、群组ID或置顶消息列表"))
+	}
+
+	if err := s.checkGroupAdmin(ctx, userID, groupID); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "permission denied")
+		return nil, err
+	}
+
+	var update *model.GroupPinsUpdate
+	reorder := func(ctx context.Context) error {
+		pins, err := s.dao.GetGroupPinnedMessages(ctx, groupID)
+		if err != nil {
+			return httpx.Unavailable(fmt.Errorf("获取置顶消息失败: %v", err))
+		}
+		positions, err := reorderPinPositions(pins, messageIDs)
+		if err != nil {
+			return httpx.Conflict(err)
+		}
+		if err := s.dao.SetGroupPinPositions(ctx, groupID, positions); err != nil {
+			return httpx.Unavailable(fmt.Errorf("调整置顶顺序失败: %v", err))
+		}
+		update = &model.GroupPinsUpdate{
+			GroupID:    groupID,
+			Action:     model.GroupPinActionReorder,
+			MessageIDs: messageIDs,
+			OperatorID: userID,
+		}
+		return nil
+	}
+	if err := s.withGroupPinLock(ctx, groupID, reorder); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to reorder pins")
+		return nil, err
+	}
+
+	s.logger.Info(ctx, "Group pins reordered",
+		logger.F("groupID", groupID),
+		logger.F("messageIDs", messageIDs),
+		logger.F("operatorID", userID))
+	go s.notifyGroupPinsUpdated(context.WithoutCancel(ctx), update)
+
+	highlights, err := s.buildGroupHighlights(ctx, groupID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to build group highlights")
+		return nil, err
+	}
+
+	span.SetStatus(codes.Ok, "group pins reordered")
+	return highlights, nil
+}
+
+// withGroupPinLock 在群置顶锁内执行fn，同一个群的置顶、取消置顶和排序串行执行，保证置顶数不超过上限
+func (s *Service) withGroupPinLock(ctx context.Context, groupID int64, fn func(ctx context.Context) error) error {
+	if s.redis == nil {
+		return fn(ctx)
+	}
+
+	lockKey := fmt.Sprintf("%s:%d", model.CacheKeyGroupPinLock, groupID)
+	lockOpts := redis.LockOptions{
+		TTL:         model.GroupPinLockTTL,
+		WaitTimeout: model.GroupPinLockWait,
+	}
+	err := s.redis.WithLock(ctx, lockKey, lockOpts, fn)
+	if errors.Is(err, redis.ErrLockNotObtained) {
+		return httpx.Unavailable(fmt.Errorf("置顶操作繁忙，请稍后重试"))
+	}
+	return err
+}
+
+// unpinMessage 删除置顶记录并清除消息上的置顶标记
+// 先删除记录，清除标记失败时消息只是暂时不被保留期清理，不影响展示
+func (s *Service) unpinMessage(ctx context.Context, groupID, messageID int64) error {
+	if _, err := s.dao.UnpinGroupMessage(ctx, groupID, messageID); err != nil {
+		return err
+	}
+	if err := s.dao.SetMessagePinned(ctx, messageID, false); err != nil {
+		s.logger.Warn(ctx, "Failed to clear pinned flag",
+			logger.F("groupID", groupID),
+			logger.F("messageID", messageID),
+			logger.F("error", err.Error()))
+	}
+	return nil
+}
+
+// notifyGroupPinsUpdated 向群成员推送置顶变更，推送失败只记录日志，客户端下次获取群亮点时会看到最新置顶
+func (s *Service) notifyGroupPinsUpdated(ctx context.Context, update *model.GroupPinsUpdate) {
+	if s.kafka == nil || update == nil {
+		return
+	}
+
+	now := time.Now().Unix()
+	update.UpdatedAt = now
+	content, err := json.Marshal(update)
+	if err != nil {
+		return
+	}
+	// 推送要求MessageID非0，排序变更没有单独的消息，使用排在最前的置顶消息
+	messageID := update.MessageID
+	if messageID == 0 && len(update.MessageIDs) > 0 {
+		messageID = update.MessageIDs[0]
+	}
+
+	for _, memberID := range s.groupMemberIDs(ctx, update.GroupID) {
+		event := &rest.MessageEvent{
+			Type: model.EventTypeGroupPinsUpdated,
+			Message: &rest.WSMessage{
+				MessageId:   messageID,
+				From:        update.OperatorID,
+				To:          memberID,
+				GroupId:     update.GroupID,
+				Content:     string(content),
+				MessageType: model.MessageTypeGroupPinsUpdate,
+				Timestamp:   now,
+			},
+			Timestamp: now,
+		}
+		if err := s.kafka.PublishMessage(model.TopicDownlinkMessage, event); err != nil {
+			s.logger.Warn(ctx, "Failed to publish group pins update",
+				logger.F("groupID", update.GroupID),
+				logger.F("userID", memberID),
+				logger.F("error", err.Error()))
+		}
+	}
+}
+
+// maxGroupPins 每个群最多置顶的消息数
+func (s *Service) maxGroupPins() int {
+	if s.pinCfg.MaxPerGroup > 0 {
+		return s.pinCfg.MaxPerGroup
+	}
+	return model.DefaultMaxGroupPinnedMessages
+}
+
+// buildGroupHighlights 合并群的生效公告和置顶消息，调用方负责校验权限
+// 获取公告失败时只返回置顶消息，避免社交服务故障导致群头部无法展示
+func (s *Service) buildGroupHighlights(ctx context.Context, groupID int64) ([]*model.GroupHighlight, error) {
+	pins, err := s.dao.GetGroupPinnedMessages(ctx, groupID)
+	if err != nil {
+		return nil, httpx.Unavailable(fmt.Errorf("获取置顶消息失败: %v", err))
+	}
+
+	messages, err := s.dao.GetMessagesByIDs(ctx, pinnedMessageIDs(pins))
+	if err != nil {
+		return nil, httpx.Unavailable(fmt.Errorf("获取置顶消息失败: %v", err))
+	}
+	byID := make(map[int64]*model.Message, len(messages))
+	for _, message := range messages {
+		byID[message.MessageID] = message
+	}
+
+	highlights := make([]*model.GroupHighlight, 0, len(pins)+1)
+	announcement := s.activeAnnouncement(ctx, groupID)
+	if announcement != nil {
+		highlights = append(highlights, announcement)
+	}
+
+	now := time.Now()
+	for _, pin := range pins {
+		message := byID[pin.MessageID]
+		if message == nil || message.GroupID != groupID || !pinnable(message, now) {
+			s.dropStalePin(ctx, pin)
+			continue
+		}
+		if err := s.decryptMessage(message); err != nil {
+			s.logger.Error(ctx, "Failed to decrypt pinned message",
+				logger.F("messageID", message.MessageID),
+				logger.F("error", err.Error()))
+			continue
+		}
+
+		// 置顶时关联了生效公告的消息合并到公告条目，不重复展示
+		if announcement != nil && announcement.Message == nil && pin.AnnouncementID == announcement.AnnouncementID {
+			announcement.Message = message
+			announcement.PinnedBy = pin.PinnedBy
+			announcement.PinnedAt = pin.PinnedAt.Unix()
+			continue
+		}
+
+		highlights = append(highlights, &model.GroupHighlight{
+			Type:      model.HighlightTypePinnedMessage,
+			AuthorID:  message.From,
+			Message:   message,
+			PinnedBy:  pin.PinnedBy,
+			PinnedAt:  pin.PinnedAt.Unix(),
+			CreatedAt: message.Timestamp,
+		})
+	}
+	return highlights, nil
+}
+
+// activeAnnouncement 从社交服务获取群的生效公告，没有生效公告或获取失败时返回nil
+func (s *Service) activeAnnouncement(ctx context.Context, groupID int64) *model.GroupHighlight {
+	if s.social == nil {
+		return nil
+	}
+
+	resp, err := s.social.GetActiveAnnouncement(ctx, &rest.GetActiveAnnouncementRequest{GroupId: groupID})
+	if err == nil && !resp.Success {
+		err = fmt.Errorf("%s", resp.Message)
+	}
+	if err != nil {
+		s.logger.Warn(ctx, "Failed to get active announcement, return pinned messages only",
+			logger.F("groupID", groupID),
+			logger.F("error", err.Error()))
+		return nil
+	}
+	if resp.Announcement == nil || strings.TrimSpace(resp.Announcement.Content) == "" {
+		return nil
+	}
+
+	return &model.GroupHighlight{
+		Type:           model.HighlightTypeAnnouncement,
+		AnnouncementID: resp.Announcement.Id,
+		Content:        resp.Announcement.Content,
+		AuthorID:       resp.Announcement.AuthorId,
+		CreatedAt:      resp.Announcement.CreatedAt,
+	}
+}
+
+// dropStalePin 清理已删除、已撤回或已过期消息的置顶记录，失败时只记录日志，下次查看时重试
+func (s *Service) dropStalePin(ctx context.Context, pin *model.GroupPinnedMessage) {
+	if err := s.unpinMessage(ctx, pin.GroupID, pin.MessageID); err != nil {
+		s.logger.Warn(ctx, "Failed to drop stale pinned message",
+			logger.F("groupID", pin.GroupID),
+			logger.F("messageID", pin.MessageID),
+			logger.F("error", err.Error()))
+	}
+}
+
+// pinnable 消息是否可以置顶：未撤回且未过期
+func pinnable(message *model.Message, now time.Time) bool {
+	if message.Status == model.MessageStatusRevoked {
+		return false
+	}
+	return message.ExpireAt == nil || message.ExpireAt.After(now)
+}
+
+// nextPinPosition 新置顶消息的排序位置，排在当前所有置顶之前
+func nextPinPosition(pins []*model.GroupPinnedMessage) int64 {
+	var top int64
+	for _, pin := range pins {
+		top = max(top, pin.Position)
+	}
+	return top + 1
+}
+
+// pinEvictions 再置顶一条消息时需要取消的置顶，pins按展示顺序排列，从最后一条开始取消
+func pinEvictions(pins []*model.GroupPinnedMessage, limit int) []*model.GroupPinnedMessage {
+	if len(pins) < limit {
+		return nil
+	}
+	return pins[max(limit-1, 0):]
+}
+
+// reorderPinPositions 校验新顺序恰好包含当前全部置顶消息，返回每条消息的排序位置，排在前面的位置更大
+func reorderPinPositions(pins []*model.GroupPinnedMessage, messageIDs []int64) (map[int64]int64, error) {
+	if len(messageIDs) != len(pins) {
+		return nil, fmt.Errorf("置顶消息已变化，请刷新后重试")
+	}
+	current := make(map[int64]bool, len(pins))
+	for _, pin := range pins {
+		current[pin.MessageID] = true
+	}
+
+	positions := make(map[int64]int64, len(messageIDs))
+	for i, messageID := range messageIDs {
+		if !current[messageID] {
+			return nil, fmt.Errorf("置顶消息已变化，请刷新后重试")
+		}
+		if _, ok := positions[messageID]; ok {
+			return nil, fmt.Errorf("置顶消息列表中有重复的消息")
+		}
+		positions[messageID] = int64(len(messageIDs) - i)
+	}
+	return positions, nil
+}
+
+// pinnedMessageIDs 按置顶记录的顺序返回消息ID
+func pinnedMessageIDs(pins []*model.GroupPinnedMessage) []int64 {
+	ids := make([]int64, 0, len(pins))
+	for _, pin := range pins {
+		ids = append(ids, pin.MessageID)
+	}
+	return ids
+}
@@ -0,0 +1,79 @@
+package service
+
+import (
+	"reflect"
+	"testing"
+
+	"goim-social/apps/message-service/internal/model"
+)
+
+func testPins(positions ...int64) []*model.GroupPinnedMessage {
+	pins := make([]*model.GroupPinnedMessage, 0, len(positions))
+	for i, position := range positions {
+		pins = append(pins, &model.GroupPinnedMessage{GroupID: 1, MessageID: int64(100 + i), Position: position})
+	}
+	return pins
+}
+
+// TestNextPinPosition 新置顶排在当前所有置顶之前
+func TestNextPinPosition(t *testing.T) {
+	if got := nextPinPosition(nil); got != 1 {
+		t.Errorf("nextPinPosition(nil) = %d, want 1", got)
+	}
+	if got := nextPinPosition(testPins(3, 7, 0)); got != 8 {
+		t.Errorf("nextPinPosition = %d, want 8", got)
+	}
+}
+
+// TestPinEvictions 未达上限时不取消置顶，达到上限时从排在最后的开始取消，腾出一个位置
+func TestPinEvictions(t *testing.T) {
+	pins := testPins(3, 2, 1)
+	cases := []struct {
+		name  string
+		limit int
+		want  []int64
+	}{
+		{name: "below limit", limit: 4, want: nil},
+		{name: "at limit", limit: 3, want: []int64{102}},
+		{name: "limit lowered", limit: 2, want: []int64{101, 102}},
+		{name: "limit one", limit: 1, want: []int64{100, 101, 102}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			evicted := pinEvictions(pins, tc.limit)
+			var got []int64
+			if len(evicted) > 0 {
+				got = pinnedMessageIDs(evicted)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("pinEvictions(limit=%d) = %v, want %v", tc.limit, got, tc.want)
+			}
+		})
+	}
+}
+
+// TestReorderPinPositions 新顺序须恰好是当前置顶的一个排列，排在前面的位置更大
+func TestReorderPinPositions(t *testing.T) {
+	pins := testPins(3, 2, 1)
+
+	positions, err := reorderPinPositions(pins, []int64{102, 100, 101})
+	if err != nil {
+		t.Fatalf("reorderPinPositions returned error: %v", err)
+	}
+	want := map[int64]int64{102: 3, 100: 2, 101: 1}
+	if !reflect.DeepEqual(positions, want) {
+		t.Errorf("reorderPinPositions = %v, want %v", positions, want)
+	}
+
+	invalid := map[string][]int64{
+		"missing":   {100, 101},
+		"extra":     {100, 101, 102, 103},
+		"unknown":   {100, 101, 999},
+		"duplicate": {100, 100, 101},
+	}
+	for name, messageIDs := range invalid {
+		if _, err := reorderPinPositions(pins, messageIDs); err == nil {
+			t.Errorf("%s: reorderPinPositions(%v) succeeded, want error", name, messageIDs)
+		}
+	}
+}
@@ -0,0 +1,196 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+
+	"goim-social/api/rest"
+	"goim-social/apps/message-service/internal/model"
+	tracecontext "goim-social/pkg/context"
+	"goim-social/pkg/logger"
+	"goim-social/pkg/telemetry"
+)
+
+// GetGroupStats 获取群组活跃度统计，仅群主和管理员可查看
+// 统计结果按群组和窗口缓存，发言排行在缓存结果上分页，返回统计结果和当前页的发言排行
+func (s *Service) GetGroupStats(ctx context.Context, userID, groupID int64, timeRange string, page, pageSize int32) (*model.GroupStats, []*model.GroupPosterStat, error) {
+	// 开始OpenTelemetry span
+	ctx, span := telemetry.StartSpan(ctx, "message.service.GetGroupStats")
+	defer span.End()
+
+	// 设置span属性
+	span.SetAttributes(
+		attribute.Int64("group.id", groupID),
+		attribute.Int64("group.user_id", userID),
+		attribute.String("group.stats_range", timeRange),
+	)
+
+	// 将业务信息添加到context
+	ctx = tracecontext.WithUserID(ctx, userID)
+	ctx = tracecontext.WithGroupID(ctx, groupID)
+
+	// 参数验证
+	if userID <= 0 || groupID <= 0 {
+		span.SetStatus(codes.Error, "invalid parameters")
+		return nil, nil, fmt.Errorf("无效的用户ID或群组ID")
+	}
+	if timeRange == "" {
+		timeRange = model.GroupStatsRangeWeek
+	}
+	window, err := groupStatsWindow(timeRange)
+	if err != nil {
+		span.SetStatus(codes.Error, "invalid time range")
+		return nil, nil, err
+	}
+	if page <= 0 {
+		page = 1
+	}
+	if pageSize <= 0 {
+		pageSize = model.DefaultPageSize
+	}
+	if pageSize > model.MaxPageSize {
+		pageSize = model.MaxPageSize
+	}
+
+	// 权限验证
+	if err := s.checkGroupAdmin(ctx, userID, groupID); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "permission denied")
+		return nil, nil, err
+	}
+
+	stats := s.getCachedGroupStats(ctx, groupID, timeRange)
+	if stats == nil {
+		endTime := time.Now()
+		startTime := endTime.Add(-window)
+		posters, err := s.dao.GetGroupPosterStats(ctx, groupID, startTime, endTime)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, "failed to aggregate group stats")
+			s.logger.Error(ctx, "Failed to aggregate group stats",
+				logger.F("groupID", groupID),
+				logger.F("error", err.Error()))
+			return nil, nil, fmt.Errorf("统计群组消息失败: %v", err)
+		}
+
+		stats = &model.GroupStats{
+			GroupID:           groupID,
+			TimeRange:         timeRange,
+			StartTime:         startTime,
+			EndTime:           endTime,
+			ActiveMemberCount: int64(len(posters)),
+			Posters:           posters,
+		}
+		for _, poster := range posters {
+			stats.MessageCount += poster.MessageCount
+		}
+		s.cacheGroupStats(ctx, stats)
+	}
+
+	// 发言排行分页
+	start := int((page - 1) * pageSize)
+	end := start + int(pageSize)
+	if start > len(stats.Posters) {
+		start = len(stats.Posters)
+	}
+	if end > len(stats.Posters) {
+		end = len(stats.Posters)
+	}
+
+	span.SetAttributes(
+		attribute.Int64("group.message_count", stats.MessageCount),
+		attribute.Int64("group.active_member_count", stats.ActiveMemberCount),
+	)
+
+	s.logger.Info(ctx, "Group stats retrieved successfully",
+		logger.F("groupID", groupID),
+		logger.F("timeRange", timeRange),
+		logger.F("messageCount", stats.MessageCount),
+		logger.F("activeMemberCount", stats.ActiveMemberCount))
+
+	span.SetStatus(codes.Ok, "group stats retrieved successfully")
+	return stats, stats.Posters[start:end], nil
+}
+
+// checkGroupAdmin 验证用户是否为群主或管理员
+func (s *Service) checkGroupAdmin(ctx context.Context, userID, groupID int64) error {
+	if s.social == nil {
+		return fmt.Errorf("社交服务不可用")
+	}
+
+	resp, err := s.social.ValidateGroupMember(ctx, &rest.ValidateGroupMemberRequest{
+		GroupId: groupID,
+		UserId:  userID,
+	})
+	if err != nil {
+		return fmt.Errorf("验证群成员身份失败: %v", err)
+	}
+	if !resp.Success {
+		return fmt.Errorf("验证群成员身份失败: %s", resp.Message)
+	}
+	if !resp.IsMember || (resp.Role != model.GroupRoleOwner && resp.Role != model.GroupRoleAdmin) {
+		return fmt.Errorf("权限不足，仅群主和管理员可执行该操作")
+	}
+	return nil
+}
+
+// getCachedGroupStats 读取缓存的群组统计，未命中或缓存不可用时返回nil
+func (s *Service) getCachedGroupStats(ctx context.Context, groupID int64, timeRange string) *model.GroupStats {
+	if s.redis == nil {
+		return nil
+	}
+
+	data, err := s.redis.Get(ctx, groupStatsCacheKey(groupID, timeRange))
+	if err != nil || data == "" {
+		return nil
+	}
+
+	var stats model.GroupStats
+	if err := json.Unmarshal([]byte(data), &stats); err != nil {
+		return nil
+	}
+	return &stats
+}
+
+// cacheGroupStats 缓存群组统计，写入失败只记录日志
+func (s *Service) cacheGroupStats(ctx context.Context, stats *model.GroupStats) {
+	if s.redis == nil {
+		return
+	}
+
+	data, err := json.Marshal(stats)
+	if err != nil {
+		return
+	}
+
+	expire := time.Duration(model.CacheExpireGroupStats) * time.Second
+	if err := s.redis.Set(ctx, groupStatsCacheKey(stats.GroupID, stats.TimeRange), data, expire); err != nil {
+		s.logger.Warn(ctx, "Failed to cache group stats",
+			logger.F("groupID", stats.GroupID),
+			logger.F("error", err.Error()))
+	}
+}
+
+// groupStatsCacheKey 群组统计缓存键
+func groupStatsCacheKey(groupID int64, timeRange string) string {
+	return fmt.Sprintf("%s:%d:%s", model.CacheKeyGroupStats, groupID, timeRange)
+}
+
+// groupStatsWindow 统计窗口对应的时长
+func groupStatsWindow(timeRange string) (time.Duration, error) {
+	switch timeRange {
+	case model.GroupStatsRangeDay:
+		return 24 * time.Hour, nil
+	case model.GroupStatsRangeWeek:
+		return 7 * 24 * time.Hour, nil
+	case model.GroupStatsRangeMonth:
+		return 30 * 24 * time.Hour, nil
+	default:
+		return 0, fmt.Errorf("无效的统计窗口: %s", timeRange)
+	}
+}
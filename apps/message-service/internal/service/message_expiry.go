@@ -0,0 +1,216 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+
+	"goim-social/api/rest"
+	"goim-social/apps/message-service/internal/model"
+	tracecontext "goim-social/pkg/context"
+	"goim-social/pkg/conversation"
+	"goim-social/pkg/logger"
+	"goim-social/pkg/telemetry"
+)
+
+// SetConversationTTL 设置会话默认消息有效期，未指定有效期的新消息按该设置过期，ttl为0时关闭
+// 单聊双方均可设置；群聊仅群主和管理员可设置
+func (s *Service) SetConversationTTL(ctx context.Context, userID, to, groupID, ttl int64) (string, error) {
+	// 开始OpenTelemetry span
+	ctx, span := telemetry.StartSpan(ctx, "message.service.SetConversationTTL")
+	defer span.End()
+
+	// 设置span属性
+	span.SetAttributes(
+		attribute.Int64("user.id", userID),
+		attribute.Int64("message.to", to),
+		attribute.Int64("group.id", groupID),
+		attribute.Int64("conversation.ttl", ttl),
+	)
+
+	// 将业务信息添加到context
+	ctx = tracecontext.WithUserID(ctx, userID)
+
+	// 参数验证
+	if userID <= 0 || (to <= 0 && groupID <= 0) || (to > 0 && groupID > 0) || to == userID {
+		span.SetStatus(codes.Error, "invalid parameters")
+		return "", fmt.Errorf("无效的用户ID或会话")
+	}
+	if ttl < 0 || ttl > model.MaxMessageTTL {
+		span.SetStatus(codes.Error, "invalid ttl")
+		return "", fmt.Errorf("无效的消息有效期，范围为0-%d秒", model.MaxMessageTTL)
+	}
+
+	if groupID > 0 {
+		ctx = tracecontext.WithGroupID(ctx, groupID)
+		if err := s.checkGroupAdmin(ctx, userID, groupID); err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, "permission denied")
+			return "", err
+		}
+	}
+
+	convID := conversation.ID(userID, to, groupID)
+	if err := s.dao.SetConversationTTL(ctx, convID, ttl, userID); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to set conversation ttl")
+		s.logger.Error(ctx, "Failed to set conversation ttl",
+			logger.F("conversationID", convID),
+			logger.F("error", err.Error()))
+		return "", fmt.Errorf("设置会话消息有效期失败: %v", err)
+	}
+
+	s.logger.Info(ctx, "Conversation ttl updated",
+		logger.F("conversationID", convID),
+		logger.F("ttl", ttl))
+
+	span.SetStatus(codes.Ok, "conversation ttl updated")
+	return convID, nil
+}
+
+// RunExpiryWorker 定期删除到期消息并通知会话参与者，ctx取消时退出
+func (s *Service) RunExpiryWorker(ctx context.Context) {
+	ticker := time.NewTicker(model.ExpiryScanInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.purgeExpiredMessages(ctx)
+		}
+	}
+}
+
+// purgeExpiredMessages 分批删除到期消息，单批不足批量大小时说明已清理完毕
+// 先推送过期通知再删除，通知发布失败的消息本轮不删除，下一轮重新通知，避免客户端漏收通知而保留已删除的消息
+func (s *Service) purgeExpiredMessages(ctx context.Context) {
+	// 同一轮清理内缓存群成员，避免同群多条消息重复查询
+	members := make(map[int64][]int64)
+
+	for ctx.Err() == nil {
+		now := time.Now()
+		messages, err := s.dao.FindExpiredMessages(ctx, now, model.ExpiryScanBatchSize)
+		if err != nil {
+			s.logger.Error(ctx, "Failed to find expired messages", logger.F("error", err.Error()))
+			return
+		}
+		if len(messages) == 0 {
+			return
+		}
+
+		// 多实例同时清理时通知可能重复，客户端按message_id删除是幂等的
+		notified := make([]*model.Message, 0, len(messages))
+		for _, msg := range messages {
+			if err := s.notifyMessageExpired(ctx, msg, members); err != nil {
+				s.logger.Warn(ctx, "Failed to notify expired message, retry next round",
+					logger.F("messageID", msg.MessageID),
+					logger.F("error", err.Error()))
+				continue
+			}
+			notified = append(notified, msg)
+		}
+		if len(notified) == 0 {
+			return
+		}
+
+		messageIDs := make([]int64, 0, len(notified))
+		for _, msg := range notified {
+			messageIDs = append(messageIDs, msg.MessageID)
+		}
+		deleted, err := s.dao.DeleteExpiredMessages(ctx, messageIDs, now)
+		if err != nil {
+			s.logger.Error(ctx, "Failed to delete expired messages", logger.F("error", err.Error()))
+			return
+		}
+		s.publishMessagesPurged(ctx, notified, model.DeletionReasonExpired)
+
+		s.logger.Info(ctx, "Expired messages purged",
+			logger.F("found", len(messages)),
+			logger.F("deleted", deleted))
+
+		// 有通知失败的消息时结束本轮，否则下一批会再次查到这些消息
+		if len(notified) < len(messages) || len(messages) < model.ExpiryScanBatchSize {
+			return
+		}
+	}
+}
+
+// notifyMessageExpired 向会话参与者推送过期通知，单聊通知双方，群聊通知当前群成员
+// 离线参与者不推送，上线拉取时消息已不存在；群成员获取失败或任一通知发布失败时返回错误
+func (s *Service) notifyMessageExpired(ctx context.Context, msg *model.Message, members map[int64][]int64) error {
+	if s.kafka == nil {
+		return nil
+	}
+
+	var recipients []int64
+	if msg.GroupID > 0 {
+		memberIDs, ok := members[msg.GroupID]
+		if !ok {
+			var err error
+			memberIDs, err = s.fetchGroupMemberIDs(ctx, msg.GroupID)
+			if err != nil {
+				return err
+			}
+			members[msg.GroupID] = memberIDs
+		}
+		recipients = memberIDs
+	} else {
+		recipients = []int64{msg.From, msg.To}
+	}
+
+	for _, userID := range recipients {
+		if userID <= 0 {
+			continue
+		}
+		event := &rest.MessageEvent{
+			Type: model.EventTypeExpired,
+			Message: &rest.WSMessage{
+				MessageId:   msg.MessageID,
+				From:        msg.From,
+				To:          userID,
+				GroupId:     msg.GroupID,
+				MessageType: model.MessageTypeExpired,
+				Timestamp:   time.Now().Unix(),
+				Ttl:         msg.TTL,
+			},
+			Timestamp: time.Now().Unix(),
+		}
+		if err := s.kafka.PublishMessage(model.TopicDownlinkMessage, event); err != nil {
+			return fmt.Errorf("发布消息过期通知失败: userID=%d, %w", userID, err)
+		}
+	}
+	return nil
+}
+
+// groupMemberIDs 获取群成员ID列表，失败时记录日志并返回nil，调用方此时不推送
+func (s *Service) groupMemberIDs(ctx context.Context, groupID int64) []int64 {
+	memberIDs, err := s.fetchGroupMemberIDs(ctx, groupID)
+	if err != nil {
+		s.logger.Warn(ctx, "Failed to get group members",
+			logger.F("groupID", groupID),
+			logger.F("error", err.Error()))
+		return nil
+	}
+	return memberIDs
+}
+
+// fetchGroupMemberIDs 获取群成员ID列表，未配置社交服务时返回nil
+func (s *Service) fetchGroupMemberIDs(ctx context.Context, groupID int64) ([]int64, error) {
+	if s.social == nil {
+		return nil, nil
+	}
+
+	resp, err := s.social.GetGroupMemberIDs(ctx, &rest.GetGroupMemberIDsRequest{GroupId: groupID})
+	if err != nil {
+		return nil, fmt.Errorf("获取群成员失败: groupID=%d, %w", groupID, err)
+	}
+	if !resp.Success {
+		return nil, fmt.Errorf("获取群成员失败: groupID=%d, %s", groupID, resp.Message)
+	}
+	return resp.MemberIds, nil
+}
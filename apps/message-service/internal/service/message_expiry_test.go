@@ -0,0 +1,81 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"goim-social/apps/message-service/internal/dao"
+	"goim-social/apps/message-service/internal/model"
+	"goim-social/pkg/conversation"
+)
+
+// TestApplyMessageTTL 消息指定的有效期优先，未指定时使用会话默认设置，超过上限时按上限计算
+func TestApplyMessageTTL(t *testing.T) {
+	store := dao.NewMemoryStore()
+	ctx := context.Background()
+	convID := conversation.Private(1, 2)
+	if err := store.SetConversationTTL(ctx, convID, 60, 1); err != nil {
+		t.Fatalf("SetConversationTTL returned error: %v", err)
+	}
+
+	createdAt := time.Now()
+	cases := []struct {
+		name    string
+		convID  string
+		ttl     int64
+		wantTTL int64
+	}{
+		{name: "message ttl", convID: convID, ttl: 30, wantTTL: 30},
+		{name: "conversation default", convID: convID, wantTTL: 60},
+		{name: "capped", convID: convID, ttl: model.MaxMessageTTL + 1, wantTTL: model.MaxMessageTTL},
+		{name: "no ttl", convID: conversation.Private(3, 4)},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			msg := &model.Message{ConversationID: tc.convID, CreatedAt: createdAt}
+			if err := dao.ApplyMessageTTL(ctx, store, msg, tc.ttl); err != nil {
+				t.Fatalf("ApplyMessageTTL returned error: %v", err)
+			}
+			if msg.TTL != tc.wantTTL {
+				t.Errorf("TTL = %d, want %d", msg.TTL, tc.wantTTL)
+			}
+			if tc.wantTTL == 0 {
+				if msg.ExpireAt != nil {
+					t.Errorf("ExpireAt = %v, want nil", msg.ExpireAt)
+				}
+				return
+			}
+			if want := createdAt.Add(time.Duration(tc.wantTTL) * time.Second); msg.ExpireAt == nil || !msg.ExpireAt.Equal(want) {
+				t.Errorf("ExpireAt = %v, want %v", msg.ExpireAt, want)
+			}
+		})
+	}
+}
+
+// TestPurgeExpiredMessages 到期消息被删除并留下墓碑，未到期的消息保留
+func TestPurgeExpiredMessages(t *testing.T) {
+	s, store := newMemoryService(t)
+	ctx := context.Background()
+	now := time.Now()
+	expired := now.Add(-time.Second)
+	later := now.Add(time.Hour)
+	saveTestMessage(t, store, &model.Message{MessageID: 1, From: 1, To: 2, CreatedAt: now, ExpireAt: &expired})
+	saveTestMessage(t, store, &model.Message{MessageID: 2, From: 1, To: 2, CreatedAt: now, ExpireAt: &later})
+
+	s.purgeExpiredMessages(ctx)
+
+	if _, err := store.GetMessage(ctx, 1); err != dao.ErrNotFound {
+		t.Errorf("GetMessage(expired) = %v, want ErrNotFound", err)
+	}
+	if _, err := store.GetMessage(ctx, 2); err != nil {
+		t.Errorf("GetMessage(not expired) returned error: %v", err)
+	}
+	tombstones, err := store.FindTombstonesSince(ctx, 2, nil, model.SyncPosition{Time: 1}, now.Add(time.Minute), 10)
+	if err != nil {
+		t.Fatalf("FindTombstonesSince returned error: %v", err)
+	}
+	if len(tombstones) != 1 || tombstones[0].MessageID != 1 || tombstones[0].Reason != model.DeletionReasonExpired {
+		t.Errorf("tombstones = %+v, want one expired tombstone for message 1", tombstones)
+	}
+}
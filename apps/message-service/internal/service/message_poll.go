@@ -0,0 +1,584 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	goredis "github.com/go-redis/redis/v8"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+
+	"goim-social/api/rest"
+	"goim-social/apps/message-service/internal/model"
+	tracecontext "goim-social/pkg/context"
+	"goim-social/pkg/httpx"
+	"goim-social/pkg/logger"
+	"goim-social/pkg/telemetry"
+)
+
+// ==================== 群投票 ====================
+// 投票消息（消息类型7）的问题和选项保存在消息内容中，发出后不再改写，投票ID即消息ID；
+// 成员的投票单独保存，各选项票数在投票状态中以$inc计数，投票只在投票进行中时计入；
+// 票数变化时投票被标记为待推送，推送任务按间隔向群成员推送投票结果更新（消息类型111），同一投票在一个间隔内最多推送一次。
+// 每个成员在每个投票中只保留一条记录，重复投票覆盖之前的选择；已退群成员的投票默认继续计入结果
+
+// GetPoll 获取投票及当前结果，仅群成员可查看
+func (s *Service) GetPoll(ctx context.Context, userID, groupID, pollID int64) (*model.PollResult, error) {
+	// 开始OpenTelemetry span
+	ctx, span := telemetry.StartSpan(ctx, "message.service.GetPoll")
+	defer span.End()
+
+	// 设置span属性
+	span.SetAttributes(
+		attribute.Int64("user.id", userID),
+		attribute.Int64("group.id", groupID),
+		attribute.Int64("poll.id", pollID),
+	)
+
+	// 将业务信息添加到context
+	ctx = tracecontext.WithUserID(ctx, userID)
+	ctx = tracecontext.WithGroupID(ctx, groupID)
+	ctx = tracecontext.WithMessageID(ctx, pollID)
+
+	if userID <= 0 || groupID <= 0 || pollID <= 0 {
+		span.SetStatus(codes.Error, "invalid parameters")
+		return nil, httpx.InvalidArgument(fmt.Errorf("无效的用户ID、群组ID或投票ID"))
+	}
+
+	if err := s.checkGroupMember(ctx, userID, groupID); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "permission denied")
+		return nil, err
+	}
+
+	poll, state, err := s.loadPoll(ctx, groupID, pollID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to load poll")
+		return nil, err
+	}
+
+	result, err := s.buildPollResult(ctx, poll, state, userID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to count poll votes")
+		return nil, err
+	}
+
+	span.SetAttributes(attribute.Int64("poll.voter_count", result.VoterCount))
+	span.SetStatus(codes.Ok, "poll retrieved")
+	return result, nil
+}
+
+// VotePoll 群成员投票，choices为选项下标，重复投票时覆盖之前的选择
+// 单选投票（或平台关闭多选时）只能选择一项，投票结束后不能再投票
+// 先在投票进行中的条件下增减票数计数，再以之前的选择为条件保存投票记录；
+// 投票记录已被同一成员的并发投票改变时撤销本次计数并重试，结束投票与计数的先后决定本次投票是否计入
+func (s *Service) VotePoll(ctx context.Context, userID, groupID, pollID int64, choices []int) (*model.PollResult, error) {
+	// 开始OpenTelemetry span
+	ctx, span := telemetry.StartSpan(ctx, "message.service.VotePoll")
+	defer span.End()
+
+	// 设置span属性
+	span.SetAttributes(
+		attribute.Int64("user.id", userID),
+		attribute.Int64("group.id", groupID),
+		attribute.Int64("poll.id", pollID),
+		attribute.Int("poll.choice_count", len(choices)),
+	)
+
+	// 将业务信息添加到context
+	ctx = tracecontext.WithUserID(ctx, userID)
+	ctx = tracecontext.WithGroupID(ctx, groupID)
+	ctx = tracecontext.WithMessageID(ctx, pollID)
+
+	if userID <= 0 || groupID <= 0 || pollID <= 0 {
+		span.SetStatus(codes.Error, "invalid parameters")
+		return nil, httpx.InvalidArgument(fmt.Errorf("无效的用户ID、群组ID或投票ID"))
+	}
+
+	if err := s.checkGroupMember(ctx, userID, groupID); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "permission denied")
+		return nil, err
+	}
+
+	poll, _, err := s.loadPoll(ctx, groupID, pollID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to load poll")
+		return nil, err
+	}
+	if poll.Closed {
+		span.SetStatus(codes.Error, "poll closed")
+		return nil, httpx.Conflict(fmt.Errorf("投票已结束"))
+	}
+
+	choices, err = normalizePollChoices(poll, choices)
+	if err != nil {
+		span.SetStatus(codes.Error, "invalid choices")
+		return nil, httpx.InvalidArgument(err)
+	}
+
+	vote := &model.PollVote{
+		PollID:  pollID,
+		GroupID: groupID,
+		UserID:  userID,
+		Choices: choices,
+		VotedAt: time.Now(),
+	}
+	saved := false
+	for attempt := 0; attempt < model.PollVoteMaxAttempts && !saved; attempt++ {
+		saved, err = s.savePollVote(ctx, vote)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, "failed to save vote")
+			return nil, err
+		}
+	}
+	if !saved {
+		span.SetStatus(codes.Error, "vote conflict")
+		return nil, httpx.Conflict(fmt.Errorf("投票冲突，请重试"))
+	}
+	s.markPollDirty(ctx, groupID, pollID)
+
+	state, err := s.dao.GetPollState(ctx, pollID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to get poll state")
+		return nil, httpx.Unavailable(fmt.Errorf("获取投票状态失败: %v", err))
+	}
+	result, err := s.buildPollResult(ctx, poll, state, userID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to count poll votes")
+		return nil, err
+	}
+
+	s.logger.Info(ctx, "Poll vote saved",
+		logger.F("pollID", pollID),
+		logger.F("userID", userID),
+		logger.F("choices", choices))
+
+	span.SetAttributes(attribute.Int64("poll.voter_count", result.VoterCount))
+	span.SetStatus(codes.Ok, "poll vote saved")
+	return result, nil
+}
+
+// ClosePoll 结束投票，仅投票发起人、群主和管理员可操作，最终结果由推送任务推送
+func (s *Service) ClosePoll(ctx context.Context, userID, groupID, pollID int64) (*model.PollResult, error) {
+	// 开始OpenTelemetry span
+	ctx, span := telemetry.StartSpan(ctx, "message.service.ClosePoll")
+	defer span.End()
+
+	// 设置span属性
+	span.SetAttributes(
+		attribute.Int64("user.id", userID),
+		attribute.Int64("group.id", groupID),
+		attribute.Int64("poll.id", pollID),
+	)
+
+	// 将业务信息添加到context
+	ctx = tracecontext.WithUserID(ctx, userID)
+	ctx = tracecontext.WithGroupID(ctx, groupID)
+	ctx = tracecontext.WithMessageID(ctx, pollID)
+
+	if userID <= 0 || groupID <= 0 || pollID <= 0 {
+		span.SetStatus(codes.Error, "invalid parameters")
+		return nil, httpx.InvalidArgument(fmt.Errorf("无效的用户ID、群组ID或投票ID"))
+	}
+
+	if err := s.checkGroupMember(ctx, userID, groupID); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "permission denied")
+		return nil, err
+	}
+
+	poll, _, err := s.loadPoll(ctx, groupID, pollID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to load poll")
+		return nil, err
+	}
+	if poll.CreatorID != userID {
+		if err := s.checkGroupAdmin(ctx, userID, groupID); err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, "permission denied")
+			return nil, err
+		}
+	}
+	if poll.Closed {
+		span.SetStatus(codes.Error, "poll closed")
+		return nil, httpx.Conflict(fmt.Errorf("投票已结束"))
+	}
+
+	now := time.Now()
+	closed, err := s.dao.ClosePoll(ctx, &model.PollState{
+		PollID:   pollID,
+		GroupID:  groupID,
+		ClosedBy: userID,
+		ClosedAt: now,
+	})
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to close poll")
+		s.logger.Error(ctx, "Failed to close poll",
+			logger.F("pollID", pollID),
+			logger.F("error", err.Error()))
+		return nil, httpx.Unavailable(fmt.Errorf("结束投票失败: %v", err))
+	}
+	if !closed {
+		span.SetStatus(codes.Error, "poll closed")
+		return nil, httpx.Conflict(fmt.Errorf("投票已结束"))
+	}
+	poll.Closed = true
+	poll.ClosedBy = userID
+	poll.ClosedAt = now.Unix()
+	s.markPollDirty(ctx, groupID, pollID)
+
+	state, err := s.dao.GetPollState(ctx, pollID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to get poll state")
+		return nil, httpx.Unavailable(fmt.Errorf("获取投票状态失败: %v", err))
+	}
+	result, err := s.buildPollResult(ctx, poll, state, userID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to count poll votes")
+		return nil, err
+	}
+
+	s.logger.Info(ctx, "Poll closed",
+		logger.F("pollID", pollID),
+		logger.F("closedBy", userID),
+		logger.F("voterCount", result.VoterCount))
+
+	span.SetStatus(codes.Ok, "poll closed")
+	return result, nil
+}
+
+// loadPoll 读取投票消息和投票状态，消息不存在、不属于该群或不是投票消息时返回错误
+// 投票进行中且无人投票时返回的投票状态为nil
+func (s *Service) loadPoll(ctx context.Context, groupID, pollID int64) (*model.Poll, *model.PollState, error) {
+	message, err := s.getGroupMessage(ctx, groupID, pollID)
+	if err != nil {
+		return nil, nil, httpx.InvalidArgument(err)
+	}
+	if message.MessageType != model.MessageTypePoll {
+		return nil, nil, httpx.InvalidArgument(fmt.Errorf("消息不是投票: %d", pollID))
+	}
+	if err := s.decryptMessage(message); err != nil {
+		return nil, nil, httpx.Unavailable(fmt.Errorf("解密投票内容失败: %v", err))
+	}
+
+	var content model.PollContent
+	if err := json.Unmarshal([]byte(message.Content), &content); err != nil || len(content.Options) == 0 {
+		return nil, nil, httpx.InvalidArgument(fmt.Errorf("投票内容格式错误: %d", pollID))
+	}
+
+	poll := &model.Poll{
+		PollID:      pollID,
+		GroupID:     groupID,
+		CreatorID:   message.From,
+		Question:    content.Question,
+		Options:     content.Options,
+		MultiSelect: content.MultiSelect && s.pollCfg.AllowMultiSelect,
+		CreatedAt:   message.Timestamp,
+	}
+
+	state, err := s.dao.GetPollState(ctx, pollID)
+	if err != nil {
+		return nil, nil, httpx.Unavailable(fmt.Errorf("获取投票状态失败: %v", err))
+	}
+	if state != nil && state.Closed {
+		poll.Closed = true
+		poll.ClosedBy = state.ClosedBy
+		poll.ClosedAt = state.ClosedAt.Unix()
+	}
+	return poll, state, nil
+}
+
+// savePollVote 计入一次投票，并发投票改变了成员之前的选择时撤销计数并返回false，由调用方重试
+func (s *Service) savePollVote(ctx context.Context, vote *model.PollVote) (bool, error) {
+	previous, err := s.dao.GetPollVote(ctx, vote.PollID, vote.UserID)
+	if err != nil {
+		return false, httpx.Unavailable(fmt.Errorf("获取投票记录失败: %v", err))
+	}
+	var previousChoices []int
+	voterDelta := int64(1)
+	if previous != nil {
+		previousChoices = previous.Choices
+		if previousChoices == nil {
+			previousChoices = []int{}
+		}
+		voterDelta = 0
+	}
+	delta := pollVoteDelta(previousChoices, vote.Choices)
+
+	open, err := s.dao.AddPollCounts(ctx, vote.PollID, vote.GroupID, delta, voterDelta, true)
+	if err != nil {
+		s.logger.Error(ctx, "Failed to count poll vote",
+			logger.F("pollID", vote.PollID),
+			logger.F("userID", vote.UserID),
+			logger.F("error", err.Error()))
+		return false, httpx.Unavailable(fmt.Errorf("投票失败: %v", err))
+	}
+	if !open {
+		return false, httpx.Conflict(fmt.Errorf("投票已结束"))
+	}
+
+	replaced, err := s.dao.ReplacePollVote(ctx, vote, previousChoices)
+	if err == nil && replaced {
+		return true, nil
+	}
+
+	// 投票记录未保存，撤销本次计数；投票可能已在此期间结束，撤销不受结束状态限制
+	for choice, n := range delta {
+		delta[choice] = -n
+	}
+	if _, revertErr := s.dao.AddPollCounts(ctx, vote.PollID, vote.GroupID, delta, -voterDelta, false); revertErr != nil {
+		s.logger.Error(ctx, "Failed to revert poll counts",
+			logger.F("pollID", vote.PollID),
+			logger.F("userID", vote.UserID),
+			logger.F("error", revertErr.Error()))
+	}
+	if err != nil {
+		s.logger.Error(ctx, "Failed to save poll vote",
+			logger.F("pollID", vote.PollID),
+			logger.F("userID", vote.UserID),
+			logger.F("error", err.Error()))
+		return false, httpx.Unavailable(fmt.Errorf("投票失败: %v", err))
+	}
+	return false, nil
+}
+
+// pollVoteDelta 计算从之前的选择改为新选择时各选项票数的变化，键为选项下标，未变化的选项不出现
+func pollVoteDelta(previous, choices []int) map[int]int64 {
+	delta := make(map[int]int64, len(previous)+len(choices))
+	for _, choice := range previous {
+		delta[choice]--
+	}
+	for _, choice := range choices {
+		delta[choice]++
+	}
+	for choice, n := range delta {
+		if n == 0 {
+			delete(delta, choice)
+		}
+	}
+	return delta
+}
+
+// normalizePollChoices 校验选项下标，去重并排序
+func normalizePollChoices(poll *model.Poll, choices []int) ([]int, error) {
+	if len(choices) == 0 {
+		return nil, fmt.Errorf("至少选择一个选项")
+	}
+
+	seen := make(map[int]struct{}, len(choices))
+	normalized := make([]int, 0, len(choices))
+	for _, choice := range choices {
+		if choice < 0 || choice >= len(poll.Options) {
+			return nil, fmt.Errorf("无效的选项: %d", choice)
+		}
+		if _, ok := seen[choice]; ok {
+			continue
+		}
+		seen[choice] = struct{}{}
+		normalized = append(normalized, choice)
+	}
+	if !poll.MultiSelect && len(normalized) > 1 {
+		return nil, fmt.Errorf("该投票只能选择一项")
+	}
+	sort.Ints(normalized)
+	return normalized, nil
+}
+
+// buildPollResult 按投票状态中的计数生成投票结果，配置为不计入已退群成员时扣除非当前成员的投票
+// 平台关闭多选前投出的多选票按原选择计数，userID大于0时附带该用户的选择
+func (s *Service) buildPollResult(ctx context.Context, poll *model.Poll, state *model.PollState, userID int64) (*model.PollResult, error) {
+	var excluded []*model.PollVote
+	if !s.pollCfg.CountLeftMembers && state != nil && state.VoterCount > 0 {
+		memberIDs := s.groupMemberIDs(ctx, poll.GroupID)
+		if memberIDs == nil {
+			return nil, httpx.Unavailable(fmt.Errorf("获取群成员列表失败"))
+		}
+		var err error
+		excluded, err = s.dao.GetPollVotesExcluding(ctx, poll.PollID, memberIDs)
+		if err != nil {
+			return nil, httpx.Unavailable(fmt.Errorf("获取投票记录失败: %v", err))
+		}
+	}
+
+	result := tallyPoll(poll, state, excluded)
+	if userID > 0 {
+		vote, err := s.dao.GetPollVote(ctx, poll.PollID, userID)
+		if err != nil {
+			return nil, httpx.Unavailable(fmt.Errorf("获取投票记录失败: %v", err))
+		}
+		if vote != nil {
+			result.MyChoices = vote.Choices
+		}
+	}
+	return result, nil
+}
+
+// tallyPoll 由投票状态中的计数扣除excluded中的投票得到各选项票数，计数异常为负时按0返回
+func tallyPoll(poll *model.Poll, state *model.PollState, excluded []*model.PollVote) *model.PollResult {
+	result := &model.PollResult{
+		Poll:   poll,
+		Counts: make([]int64, len(poll.Options)),
+	}
+	if state == nil {
+		return result
+	}
+	for i := range result.Counts {
+		result.Counts[i] = state.Counts[strconv.Itoa(i)]
+	}
+	result.VoterCount = state.VoterCount
+	for _, vote := range excluded {
+		for _, choice := range vote.Choices {
+			if choice >= 0 && choice < len(result.Counts) {
+				result.Counts[choice]--
+			}
+		}
+		result.VoterCount--
+	}
+	for i := range result.Counts {
+		result.Counts[i] = max(result.Counts[i], 0)
+	}
+	result.VoterCount = max(result.VoterCount, 0)
+	return result
+}
+
+// RunPollUpdateFlusher 按间隔向群成员推送待推送投票的结果更新，ctx取消时退出
+func (s *Service) RunPollUpdateFlusher(ctx context.Context) {
+	interval := time.Duration(s.pollCfg.UpdateInterval) * time.Second
+	if interval <= 0 {
+		interval = model.DefaultPollUpdatePeriod
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.flushPollUpdates(ctx)
+		}
+	}
+}
+
+// flushPollUpdates 分批认领本轮开始前标记的待推送投票，多实例下移除成功的实例负责该投票
+// 本轮处理期间再次变化的投票留到下一轮，同一投票的多次投票合并为一次推送
+func (s *Service) flushPollUpdates(ctx context.Context) {
+	if s.redis == nil {
+		return
+	}
+
+	client := s.redis.GetClient()
+	until := strconv.FormatInt(time.Now().UnixMilli(), 10)
+	for ctx.Err() == nil {
+		polls, err := s.redis.ZRangeByScore(ctx, model.CacheKeyPollDirty, &goredis.ZRangeBy{
+			Min:   "-inf",
+			Max:   until,
+			Count: model.PollUpdateBatchSize,
+		})
+		if err != nil {
+			s.logger.Warn(ctx, "Failed to scan poll updates", logger.F("error", err.Error()))
+			return
+		}
+
+		for _, member := range polls {
+			claimed, err := client.ZRem(ctx, model.CacheKeyPollDirty, member).Result()
+			if err != nil || claimed == 0 {
+				continue
+			}
+			var groupID, pollID int64
+			if _, err := fmt.Sscanf(member, "%d:%d", &groupID, &pollID); err != nil {
+				continue
+			}
+			s.notifyPollUpdated(ctx, groupID, pollID)
+		}
+		if len(polls) < model.PollUpdateBatchSize {
+			return
+		}
+	}
+}
+
+// markPollDirty 标记投票的票数或状态有变化，已标记时保留首次标记的时间；未配置Redis时直接在后台推送
+func (s *Service) markPollDirty(ctx context.Context, groupID, pollID int64) {
+	if s.redis == nil {
+		go s.notifyPollUpdated(context.Background(), groupID, pollID)
+		return
+	}
+	if err := s.redis.GetClient().ZAddNX(ctx, model.CacheKeyPollDirty, &goredis.Z{
+		Score:  float64(time.Now().UnixMilli()),
+		Member: fmt.Sprintf("%d:%d", groupID, pollID),
+	}).Err(); err != nil {
+		s.logger.Warn(ctx, "Failed to mark poll update",
+			logger.F("pollID", pollID),
+			logger.F("error", err.Error()))
+	}
+}
+
+// notifyPollUpdated 向群成员推送投票的最新结果，只携带票数，不改写原投票消息，推送失败只记录日志
+func (s *Service) notifyPollUpdated(ctx context.Context, groupID, pollID int64) {
+	if s.kafka == nil {
+		return
+	}
+
+	poll, state, err := s.loadPoll(ctx, groupID, pollID)
+	if err != nil {
+		s.logger.Warn(ctx, "Failed to load poll for update",
+			logger.F("pollID", pollID),
+			logger.F("error", err.Error()))
+		return
+	}
+	result, err := s.buildPollResult(ctx, poll, state, 0)
+	if err != nil {
+		s.logger.Warn(ctx, "Failed to count poll votes for update",
+			logger.F("pollID", pollID),
+			logger.F("error", err.Error()))
+		return
+	}
+
+	content, err := json.Marshal(&model.PollUpdate{
+		PollID:     result.Poll.PollID,
+		Counts:     result.Counts,
+		VoterCount: result.VoterCount,
+		Closed:     result.Poll.Closed,
+	})
+	if err != nil {
+		return
+	}
+
+	now := time.Now().Unix()
+	for _, memberID := range s.groupMemberIDs(ctx, result.Poll.GroupID) {
+		event := &rest.MessageEvent{
+			Type: model.EventTypePollUpdated,
+			Message: &rest.WSMessage{
+				MessageId:   result.Poll.PollID,
+				From:        result.Poll.CreatorID,
+				To:          memberID,
+				GroupId:     result.Poll.GroupID,
+				Content:     string(content),
+				MessageType: model.MessageTypePollUpdate,
+				Timestamp:   now,
+			},
+			Timestamp: now,
+		}
+		if err := s.kafka.PublishMessage(model.TopicDownlinkMessage, event); err != nil {
+			s.logger.Warn(ctx, "Failed to publish poll update",
+				logger.F("pollID", result.Poll.PollID),
+				logger.F("userID", memberID),
+				logger.F("error", err.Error()))
+		}
+	}
+}
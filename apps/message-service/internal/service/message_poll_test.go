@@ -0,0 +1,103 @@
+package service
+
+import (
+	"reflect"
+	"testing"
+
+	"goim-social/apps/message-service/internal/model"
+)
+
+// TestNormalizePollChoices 校验选项下标，去重排序，单选投票只能选一项
+func TestNormalizePollChoices(t *testing.T) {
+	single := &model.Poll{Options: []string{"a", "b", "c"}}
+	multi := &model.Poll{Options: []string{"a", "b", "c"}, MultiSelect: true}
+	cases := []struct {
+		name    string
+		poll    *model.Poll
+		choices []int
+		want    []int
+		wantErr bool
+	}{
+		{name: "single choice", poll: single, choices: []int{1}, want: []int{1}},
+		{name: "single duplicate", poll: single, choices: []int{2, 2}, want: []int{2}},
+		{name: "single multiple", poll: single, choices: []int{0, 1}, wantErr: true},
+		{name: "multi sorted and deduped", poll: multi, choices: []int{2, 0, 2}, want: []int{0, 2}},
+		{name: "empty", poll: multi, choices: nil, wantErr: true},
+		{name: "negative", poll: multi, choices: []int{-1}, wantErr: true},
+		{name: "out of range", poll: multi, choices: []int{3}, wantErr: true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := normalizePollChoices(tc.poll, tc.choices)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatalf("normalizePollChoices(%v) = %v, want error", tc.choices, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("normalizePollChoices(%v) error: %v", tc.choices, err)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("normalizePollChoices(%v) = %v, want %v", tc.choices, got, tc.want)
+			}
+		})
+	}
+}
+
+// TestPollVoteDelta 改票时只有变化的选项出现在计数变化中
+func TestPollVoteDelta(t *testing.T) {
+	cases := []struct {
+		name     string
+		previous []int
+		choices  []int
+		want     map[int]int64
+	}{
+		{name: "first vote", previous: nil, choices: []int{0, 2}, want: map[int]int64{0: 1, 2: 1}},
+		{name: "change", previous: []int{0}, choices: []int{1}, want: map[int]int64{0: -1, 1: 1}},
+		{name: "partial overlap", previous: []int{0, 1}, choices: []int{1, 2}, want: map[int]int64{0: -1, 2: 1}},
+		{name: "unchanged", previous: []int{1}, choices: []int{1}, want: map[int]int64{}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := pollVoteDelta(tc.previous, tc.choices); !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("pollVoteDelta(%v, %v) = %v, want %v", tc.previous, tc.choices, got, tc.want)
+			}
+		})
+	}
+}
+
+// TestTallyPoll 票数来自投票状态中的计数，扣除已退群成员的投票，计数缺失的选项为0
+func TestTallyPoll(t *testing.T) {
+	poll := &model.Poll{PollID: 1, Options: []string{"a", "b", "c"}}
+
+	result := tallyPoll(poll, nil, nil)
+	if !reflect.DeepEqual(result.Counts, []int64{0, 0, 0}) || result.VoterCount != 0 {
+		t.Errorf("tallyPoll(no state) = %v/%d, want [0 0 0]/0", result.Counts, result.VoterCount)
+	}
+
+	state := &model.PollState{
+		PollID:     1,
+		Counts:     map[string]int64{"0": 3, "2": 2},
+		VoterCount: 4,
+	}
+	result = tallyPoll(poll, state, nil)
+	if !reflect.DeepEqual(result.Counts, []int64{3, 0, 2}) || result.VoterCount != 4 {
+		t.Errorf("tallyPoll(state) = %v/%d, want [3 0 2]/4", result.Counts, result.VoterCount)
+	}
+
+	excluded := []*model.PollVote{
+		{UserID: 10, Choices: []int{0, 2}},
+		{UserID: 11, Choices: []int{0}},
+	}
+	result = tallyPoll(poll, state, excluded)
+	if !reflect.DeepEqual(result.Counts, []int64{1, 0, 1}) || result.VoterCount != 2 {
+		t.Errorf("tallyPoll(excluded) = %v/%d, want [1 0 1]/2", result.Counts, result.VoterCount)
+	}
+
+	// 计数与投票记录短暂不一致时不返回负数
+	result = tallyPoll(poll, &model.PollState{Counts: map[string]int64{"1": 0}}, []*model.PollVote{{UserID: 10, Choices: []int{1}}})
+	if !reflect.DeepEqual(result.Counts, []int64{0, 0, 0}) || result.VoterCount != 0 {
+		t.Errorf("tallyPoll(inconsistent) = %v/%d, want [0 0 0]/0", result.Counts, result.VoterCount)
+	}
+}
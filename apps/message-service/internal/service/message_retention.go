@@ -0,0 +1,404 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+
+	"goim-social/apps/message-service/internal/model"
+	tracecontext "goim-social/pkg/context"
+	"goim-social/pkg/conversation"
+	"goim-social/pkg/httpx"
+	"goim-social/pkg/logger"
+	"goim-social/pkg/telemetry"
+)
+
+// ==================== 历史消息保留期 ====================
+// 超过保留期的消息由后台任务删除并记录墓碑，离线客户端通过增量同步删除本地副本；
+// 每批删除的消息按会话发布清理事件，搜索索引等下游据此移除，到期删除和发送者删除同样发布。
+// 群聊由群主和管理员在平台上下限内设置会话的保留期；单聊双方各自设置，按双方中较长的保留期生效，
+// 一方缩短保留期不会删除另一方仍想保留的历史。未设置时使用平台默认保留期，默认为0时永久保留。
+// 推送确认重发队列中的记录只保留几分钟，远短于最短保留期，无需随消息清理
+
+// GetConversationRetention 获取会话消息保留期设置，单聊参与者和群成员可查看
+func (s *Service) GetConversationRetention(ctx context.Context, userID, to, groupID int64) (*model.ConversationRetention, error) {
+	// 开始OpenTelemetry span
+	ctx, span := telemetry.StartSpan(ctx, "message.service.GetConversationRetention")
+	defer span.End()
+
+	// 设置span属性
+	span.SetAttributes(
+		attribute.Int64("user.id", userID),
+		attribute.Int64("message.to", to),
+		attribute.Int64("group.id", groupID),
+	)
+
+	// 将业务信息添加到context
+	ctx = tracecontext.WithUserID(ctx, userID)
+
+	if userID <= 0 || (to <= 0 && groupID <= 0) || (to > 0 && groupID > 0) || to == userID {
+		span.SetStatus(codes.Error, "invalid parameters")
+		return nil, httpx.InvalidArgument(fmt.Errorf("无效的用户ID或会话"))
+	}
+	if groupID > 0 {
+		ctx = tracecontext.WithGroupID(ctx, groupID)
+		if err := s.checkGroupMember(ctx, userID, groupID); err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, "permission denied")
+			return nil, err
+		}
+	}
+
+	convID := conversation.ID(userID, to, groupID)
+	setting, err := s.dao.GetConversationRetention(ctx, convID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to get conversation retention")
+		return nil, httpx.Unavailable(fmt.Errorf("获取会话消息保留期失败: %v", err))
+	}
+
+	span.SetStatus(codes.Ok, "conversation retention retrieved")
+	return s.buildConversationRetention(convID, setting, userID), nil
+}
+
+// SetConversationRetention 设置会话消息保留天数，days为0时恢复为平台默认保留期
+// 群聊仅群主和管理员可设置；单聊只设置调用方自己的保留期，双方中较长的一方生效。
+// 缩短生效的保留期后，超出的历史消息在下一轮清理时删除
+func (s *Service) SetConversationRetention(ctx context.Context, userID, to, groupID int64, days int) (*model.ConversationRetention, error) {
+	// 开始OpenTelemetry span
+	ctx, span := telemetry.StartSpan(ctx, "message.service.SetConversationRetention")
+	defer span.End()
+
+	// 设置span属性
+	span.SetAttributes(
+		attribute.Int64("user.id", userID),
+		attribute.Int64("message.to", to),
+		attribute.Int64("group.id", groupID),
+		attribute.Int("retention.days", days),
+	)
+
+	// 将业务信息添加到context
+	ctx = tracecontext.WithUserID(ctx, userID)
+
+	if userID <= 0 || (to <= 0 && groupID <= 0) || (to > 0 && groupID > 0) || to == userID {
+		span.SetStatus(codes.Error, "invalid parameters")
+		return nil, httpx.InvalidArgument(fmt.Errorf("无效的用户ID或会话"))
+	}
+	if err := s.validateRetentionDays(days); err != nil {
+		span.SetStatus(codes.Error, "invalid retention days")
+		return nil, httpx.InvalidArgument(err)
+	}
+
+	if groupID > 0 {
+		ctx = tracecontext.WithGroupID(ctx, groupID)
+		if err := s.checkGroupAdmin(ctx, userID, groupID); err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, "permission denied")
+			return nil, err
+		}
+	}
+
+	convID := conversation.ID(userID, to, groupID)
+	var err error
+	if groupID > 0 {
+		err = s.dao.SetConversationRetention(ctx, convID, days, userID)
+	} else {
+		err = s.dao.SetParticipantRetention(ctx, convID, days, userID)
+	}
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to set conversation retention")
+		s.logger.Error(ctx, "Failed to set conversation retention",
+			logger.F("conversationID", convID),
+			logger.F("error", err.Error()))
+		return nil, httpx.Unavailable(fmt.Errorf("设置会话消息保留期失败: %v", err))
+	}
+
+	s.logger.Info(ctx, "Conversation retention updated",
+		logger.F("conversationID", convID),
+		logger.F("retentionDays", days))
+
+	setting, err := s.dao.GetConversationRetention(ctx, convID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to get conversation retention")
+		return nil, httpx.Unavailable(fmt.Errorf("获取会话消息保留期失败: %v", err))
+	}
+
+	span.SetStatus(codes.Ok, "conversation retention updated")
+	return s.buildConversationRetention(convID, setting, userID), nil
+}
+
+// validateRetentionDays 校验会话保留天数是否在平台上下限内，0表示使用默认保留期
+func (s *Service) validateRetentionDays(days int) error {
+	if days == 0 {
+		return nil
+	}
+	minDays, maxDays := s.retentionCfg.MinDays, s.retentionCfg.MaxDays
+	if days < 0 || days < minDays || (maxDays > 0 && days > maxDays) {
+		if maxDays > 0 {
+			return fmt.Errorf("无效的保留天数，范围为%d-%d天，0表示使用默认保留期", max(minDays, 1), maxDays)
+		}
+		return fmt.Errorf("无效的保留天数，不能少于%d天，0表示使用默认保留期", max(minDays, 1))
+	}
+	return nil
+}
+
+// effectiveRetentionDays 会话实际生效的保留天数，0表示永久保留
+// 平台上下限调整后，已有的会话设置按新的上下限生效
+func (s *Service) effectiveRetentionDays(days int) int {
+	if days <= 0 {
+		return max(s.retentionCfg.DefaultDays, 0)
+	}
+	if s.retentionCfg.MaxDays > 0 {
+		days = min(days, s.retentionCfg.MaxDays)
+	}
+	return max(days, s.retentionCfg.MinDays)
+}
+
+// conversationRetentionDays 会话实际生效的保留天数，0表示永久保留，setting为nil表示未设置
+// 群聊按会话设置；单聊按双方各自生效的保留天数中较长的一方，任一方永久保留时会话永久保留。
+// 单聊按会话记录的旧设置可能只由一方设置，不再生效
+func (s *Service) conversationRetentionDays(convID string, setting *model.ConversationSetting) int {
+	if conv, err := conversation.Parse(convID); err == nil && conv.Type == conversation.TypePrivate {
+		daysA := s.effectiveRetentionDays(participantRetentionDays(setting, conv.UserA))
+		daysB := s.effectiveRetentionDays(participantRetentionDays(setting, conv.UserB))
+		if daysA == 0 || daysB == 0 {
+			return 0
+		}
+		return max(daysA, daysB)
+	}
+	if setting == nil {
+		return s.effectiveRetentionDays(0)
+	}
+	return s.effectiveRetentionDays(setting.RetentionDays)
+}
+
+// participantRetentionDays 单聊参与者自己设置的保留天数，未设置时返回0
+func participantRetentionDays(setting *model.ConversationSetting, userID int64) int {
+	if setting == nil {
+		return 0
+	}
+	return setting.ParticipantDays[strconv.FormatInt(userID, 10)]
+}
+
+// buildConversationRetention 构建userID看到的会话保留期设置，单聊返回调用方和对方各自的设置
+func (s *Service) buildConversationRetention(convID string, setting *model.ConversationSetting, userID int64) *model.ConversationRetention {
+	retention := &model.ConversationRetention{
+		ConversationID: convID,
+		EffectiveDays:  s.conversationRetentionDays(convID, setting),
+		DefaultDays:    s.retentionCfg.DefaultDays,
+		MinDays:        s.retentionCfg.MinDays,
+		MaxDays:        s.retentionCfg.MaxDays,
+	}
+	if conv, err := conversation.Parse(convID); err == nil && conv.Type == conversation.TypePrivate {
+		peerID := conv.UserA
+		if peerID == userID {
+			peerID = conv.UserB
+		}
+		retention.RetentionDays = participantRetentionDays(setting, userID)
+		retention.PeerDays = participantRetentionDays(setting, peerID)
+	} else if setting != nil {
+		retention.RetentionDays = setting.RetentionDays
+	}
+	return retention
+}
+
+// RunRetentionPurger 定期删除超过保留期的消息，ctx取消时退出
+func (s *Service) RunRetentionPurger(ctx context.Context) {
+	interval := model.DefaultRetentionPurgeInterval
+	if s.retentionCfg.PurgeInterval > 0 {
+		interval = time.Duration(s.retentionCfg.PurgeInterval) * time.Second
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.purgeRetainedMessages(ctx)
+		}
+	}
+}
+
+// purgeRetainedMessages 按会话设置和默认保留期删除超期消息
+// 设置了保留期的会话逐个按各自的保留期清理，其余会话按默认保留期清理
+func (s *Service) purgeRetainedMessages(ctx context.Context) {
+	now := time.Now()
+
+	after := ""
+	for ctx.Err() == nil {
+		settings, err := s.dao.ListConversationRetentions(ctx, after, model.RetentionSettingsPageSize)
+		if err != nil {
+			s.logger.Error(ctx, "Failed to list conversation retentions", logger.F("error", err.Error()))
+			break
+		}
+		for _, setting := range settings {
+			if days := s.conversationRetentionDays(setting.ConversationID, setting); days > 0 {
+				s.purgeConversationBefore(ctx, setting.ConversationID, now.AddDate(0, 0, -days))
+			}
+		}
+		if len(settings) < model.RetentionSettingsPageSize {
+			break
+		}
+		after = settings[len(settings)-1].ConversationID
+	}
+
+	if s.retentionCfg.DefaultDays > 0 {
+		s.purgeDefaultBefore(ctx, now.AddDate(0, 0, -s.retentionCfg.DefaultDays))
+	}
+}
+
+// retentionBatchSize 每批删除的消息数
+func (s *Service) retentionBatchSize() int64 {
+	if s.retentionCfg.BatchSize > 0 {
+		return int64(s.retentionCfg.BatchSize)
+	}
+	return int64(model.DefaultRetentionBatchSize)
+}
+
+// purgeConversationBefore 分批删除会话中早于before创建的消息，单批不足批量大小时说明已清理完毕
+// 群置顶消息由DAO查询时排除，保留到取消置顶，群亮点中的置顶不会因保留期消失
+func (s *Service) purgeConversationBefore(ctx context.Context, convID string, before time.Time) {
+	batchSize := s.retentionBatchSize()
+	for ctx.Err() == nil {
+		messages, err := s.dao.FindMessagesCreatedBefore(ctx, convID, before, batchSize)
+		if err != nil {
+			s.logger.Error(ctx, "Failed to find messages beyond retention",
+				logger.F("conversationID", convID),
+				logger.F("error", err.Error()))
+			return
+		}
+		if len(messages) == 0 || !s.deleteRetainedMessages(ctx, messages, before) {
+			return
+		}
+		if int64(len(messages)) < batchSize {
+			return
+		}
+	}
+}
+
+// purgeDefaultBefore 按默认保留期分批删除早于before创建的消息，跳过设置了保留期的会话
+// 按(创建时间, 消息ID)游标遍历，跳过的消息不会被重复读取
+func (s *Service) purgeDefaultBefore(ctx context.Context, before time.Time) {
+	batchSize := s.retentionBatchSize()
+	var after *model.Message
+	for ctx.Err() == nil {
+		messages, err := s.dao.ScanMessagesCreatedBefore(ctx, before, after, batchSize)
+		if err != nil {
+			s.logger.Error(ctx, "Failed to find messages beyond retention", logger.F("error", err.Error()))
+			return
+		}
+		if len(messages) == 0 {
+			return
+		}
+		after = messages[len(messages)-1]
+
+		expired, err := s.withoutCustomRetention(ctx, messages)
+		if err != nil {
+			s.logger.Error(ctx, "Failed to find conversation retentions", logger.F("error", err.Error()))
+			return
+		}
+		if len(expired) > 0 && !s.deleteRetainedMessages(ctx, expired, before) {
+			return
+		}
+		if int64(len(messages)) < batchSize {
+			return
+		}
+	}
+}
+
+// withoutCustomRetention 过滤掉设置了保留期的会话中的消息，这些会话按各自的保留期清理
+func (s *Service) withoutCustomRetention(ctx context.Context, messages []*model.Message) ([]*model.Message, error) {
+	seen := make(map[string]bool)
+	convIDs := make([]string, 0)
+	for _, msg := range messages {
+		if !seen[msg.ConversationID] {
+			seen[msg.ConversationID] = true
+			convIDs = append(convIDs, msg.ConversationID)
+		}
+	}
+
+	settings, err := s.dao.FindConversationRetentions(ctx, convIDs)
+	if err != nil {
+		return nil, err
+	}
+	customized := make(map[string]bool, len(settings))
+	for _, setting := range settings {
+		customized[setting.ConversationID] = true
+	}
+
+	result := make([]*model.Message, 0, len(messages))
+	for _, msg := range messages {
+		if !customized[msg.ConversationID] {
+			result = append(result, msg)
+		}
+	}
+	return result, nil
+}
+
+// deleteRetainedMessages 删除一批超过保留期的消息并发布清理事件，删除失败时返回false
+func (s *Service) deleteRetainedMessages(ctx context.Context, messages []*model.Message, before time.Time) bool {
+	messageIDs := make([]int64, 0, len(messages))
+	for _, msg := range messages {
+		messageIDs = append(messageIDs, msg.MessageID)
+	}
+	deleted, err := s.dao.DeleteMessagesCreatedBefore(ctx, messageIDs, before, time.Now())
+	if err != nil {
+		s.logger.Error(ctx, "Failed to delete messages beyond retention", logger.F("error", err.Error()))
+		return false
+	}
+
+	// 多实例同时清理时事件可能重复，下游按消息ID删除是幂等的
+	s.publishMessagesPurged(ctx, messages, model.DeletionReasonRetention)
+
+	s.logger.Info(ctx, "Messages beyond retention purged",
+		logger.F("before", before.Format(time.RFC3339)),
+		logger.F("found", len(messages)),
+		logger.F("deleted", deleted))
+	return true
+}
+
+// publishMessagesPurged 按会话发布消息清理事件，超过保留期、到期和发送者删除的消息都会发布，发布失败只记录日志
+func (s *Service) publishMessagesPurged(ctx context.Context, messages []*model.Message, reason string) {
+	if s.kafka == nil {
+		return
+	}
+
+	events := make(map[string]*model.MessagePurgedEvent)
+	order := make([]string, 0)
+	now := time.Now().Unix()
+	for _, msg := range messages {
+		event, ok := events[msg.ConversationID]
+		if !ok {
+			event = &model.MessagePurgedEvent{
+				ConversationID: msg.ConversationID,
+				GroupID:        msg.GroupID,
+				Reason:         reason,
+				PurgedAt:       now,
+			}
+			events[msg.ConversationID] = event
+			order = append(order, msg.ConversationID)
+		}
+		event.MessageIDs = append(event.MessageIDs, msg.MessageID)
+	}
+
+	for _, convID := range order {
+		data, err := json.Marshal(events[convID])
+		if err != nil {
+			continue
+		}
+		if err := s.kafka.SendMessage(model.TopicMessagePurged, []byte(convID), data); err != nil {
+			s.logger.Warn(ctx, "Failed to publish messages purged event",
+				logger.F("conversationID", convID),
+				logger.F("error", err.Error()))
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"goim-social/apps/message-service/internal/dao"
+	"goim-social/apps/message-service/internal/model"
+	"goim-social/pkg/config"
+	"goim-social/pkg/conversation"
+)
+
+// TestValidateRetentionDays 0表示使用默认保留期，其余须在平台上下限内
+func TestValidateRetentionDays(t *testing.T) {
+	cases := []struct {
+		name    string
+		cfg     config.MessageRetentionConfig
+		days    int
+		wantErr bool
+	}{
+		{name: "default", cfg: config.MessageRetentionConfig{MinDays: 7, MaxDays: 365}, days: 0},
+		{name: "in range", cfg: config.MessageRetentionConfig{MinDays: 7, MaxDays: 365}, days: 30},
+		{name: "below min", cfg: config.MessageRetentionConfig{MinDays: 7, MaxDays: 365}, days: 6, wantErr: true},
+		{name: "above max", cfg: config.MessageRetentionConfig{MinDays: 7, MaxDays: 365}, days: 366, wantErr: true},
+		{name: "negative", cfg: config.MessageRetentionConfig{}, days: -1, wantErr: true},
+		{name: "no max", cfg: config.MessageRetentionConfig{MinDays: 1}, days: 10000},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := &Service{retentionCfg: tc.cfg}
+			if err := s.validateRetentionDays(tc.days); (err != nil) != tc.wantErr {
+				t.Errorf("validateRetentionDays(%d) = %v, wantErr %v", tc.days, err, tc.wantErr)
+			}
+		})
+	}
+}
+
+// TestEffectiveRetentionDays 未设置时使用默认保留期，已有设置按当前上下限生效
+func TestEffectiveRetentionDays(t *testing.T) {
+	s := &Service{retentionCfg: config.MessageRetentionConfig{DefaultDays: 90, MinDays: 7, MaxDays: 365}}
+	cases := map[int]int{0: 90, -1: 90, 30: 30, 3: 7, 1000: 365}
+	for days, want := range cases {
+		if got := s.effectiveRetentionDays(days); got != want {
+			t.Errorf("effectiveRetentionDays(%d) = %d, want %d", days, got, want)
+		}
+	}
+
+	forever := &Service{retentionCfg: config.MessageRetentionConfig{MinDays: 7}}
+	if got := forever.effectiveRetentionDays(0); got != 0 {
+		t.Errorf("effectiveRetentionDays(0) with no default = %d, want 0", got)
+	}
+}
+
+// TestConversationRetentionDays 单聊按双方中较长的保留期生效，一方缩短不影响另一方的历史
+func TestConversationRetentionDays(t *testing.T) {
+	s := &Service{retentionCfg: config.MessageRetentionConfig{DefaultDays: 90, MinDays: 7}}
+	private := conversation.Private(1, 2)
+	cases := []struct {
+		name    string
+		convID  string
+		setting *model.ConversationSetting
+		want    int
+	}{
+		{name: "private unset", convID: private, setting: nil, want: 90},
+		{name: "private one side shorter", convID: private,
+			setting: &model.ConversationSetting{ParticipantDays: map[string]int{"1": 7}}, want: 90},
+		{name: "private one side longer", convID: private,
+			setting: &model.ConversationSetting{ParticipantDays: map[string]int{"2": 365}}, want: 365},
+		{name: "private both shorter", convID: private,
+			setting: &model.ConversationSetting{ParticipantDays: map[string]int{"1": 7, "2": 30}}, want: 30},
+		{name: "private legacy conversation setting ignored", convID: private,
+			setting: &model.ConversationSetting{RetentionDays: 7}, want: 90},
+		{name: "group", convID: conversation.Group(3),
+			setting: &model.ConversationSetting{RetentionDays: 30}, want: 30},
+		{name: "group unset", convID: conversation.Group(3), setting: nil, want: 90},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := s.conversationRetentionDays(tc.convID, tc.setting); got != tc.want {
+				t.Errorf("conversationRetentionDays() = %d, want %d", got, tc.want)
+			}
+		})
+	}
+
+	forever := &Service{retentionCfg: config.MessageRetentionConfig{MinDays: 7}}
+	setting := &model.ConversationSetting{ParticipantDays: map[string]int{"1": 7}}
+	if got := forever.conversationRetentionDays(private, setting); got != 0 {
+		t.Errorf("conversationRetentionDays() with peer keeping forever = %d, want 0", got)
+	}
+}
+
+// TestPurgeRetainedMessages 各会话按自己的保留期清理，单聊按双方中较长的保留期，群置顶消息不清理
+func TestPurgeRetainedMessages(t *testing.T) {
+	s, store := newMemoryService(t)
+	s.retentionCfg = config.MessageRetentionConfig{DefaultDays: 30, MinDays: 1, MaxDays: 365, BatchSize: 2}
+	ctx := context.Background()
+	daysAgo := func(days int) time.Time { return time.Now().AddDate(0, 0, -days) }
+
+	longer := conversation.Private(3, 4)
+	group := conversation.Group(9)
+	if err := store.SetParticipantRetention(ctx, longer, 365, 3); err != nil {
+		t.Fatalf("SetParticipantRetention returned error: %v", err)
+	}
+	if err := store.SetConversationRetention(ctx, group, 7, 1); err != nil {
+		t.Fatalf("SetConversationRetention returned error: %v", err)
+	}
+
+	messages := []struct {
+		msg  *model.Message
+		kept bool
+	}{
+		{msg: &model.Message{MessageID: 1, From: 1, To: 2, CreatedAt: daysAgo(40)}, kept: false},
+		{msg: &model.Message{MessageID: 2, From: 1, To: 2, CreatedAt: daysAgo(10)}, kept: true},
+		{msg: &model.Message{MessageID: 3, From: 4, To: 3, CreatedAt: daysAgo(40)}, kept: true},
+		{msg: &model.Message{MessageID: 4, From: 4, To: 3, CreatedAt: daysAgo(400)}, kept: false},
+		{msg: &model.Message{MessageID: 5, From: 1, GroupID: 9, CreatedAt: daysAgo(10)}, kept: false},
+		{msg: &model.Message{MessageID: 6, From: 1, GroupID: 9, CreatedAt: daysAgo(3)}, kept: true},
+		{msg: &model.Message{MessageID: 7, From: 1, GroupID: 9, CreatedAt: daysAgo(10), Pinned: true}, kept: true},
+		{msg: &model.Message{MessageID: 8, From: 5, To: 6, CreatedAt: daysAgo(31)}, kept: false},
+		{msg: &model.Message{MessageID: 9, From: 5, To: 6, CreatedAt: daysAgo(32)}, kept: false},
+	}
+	for _, m := range messages {
+		saveTestMessage(t, store, m.msg)
+	}
+
+	s.purgeRetainedMessages(ctx)
+
+	for _, m := range messages {
+		_, err := store.GetMessage(ctx, m.msg.MessageID)
+		if kept := !errors.Is(err, dao.ErrNotFound); kept != m.kept {
+			t.Errorf("message %d kept = %v, want %v", m.msg.MessageID, kept, m.kept)
+		}
+	}
+}
@@ -0,0 +1,192 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+
+	"goim-social/apps/message-service/internal/model"
+	tracecontext "goim-social/pkg/context"
+	"goim-social/pkg/httpx"
+	"goim-social/pkg/membership"
+	"goim-social/pkg/telemetry"
+)
+
+// ============ 增量同步 ============
+// 离线返回的客户端用一个游标拉取所有会话自上次同步以来的变更，不必逐个会话轮询：
+// 新消息和状态变化（已读、撤回）的消息、已删除（发送者删除或到期）的消息、所在群组成员的已读水位。
+// 消息、删除和已读水位各自按(变更时间, _id)升序分页，每类每页最多limit条，任一类还有剩余时HasMore为true。
+// 只返回SyncSettleDelay之前的变更，避免并发写入尚未可见时游标越过它们。
+// 群消息和删除记录按成员身份时段过滤，只同步用户在群期间的消息；已读水位只同步仍在群内的群组。
+// 消息解密失败时游标停在该消息之前，之后的同步重新拉取，不会跳过
+
+// SyncSince 获取用户所有会话自游标以来的变更；游标为空时不返回变更，只返回当前位置的游标，
+// 客户端应先获取游标再全量加载会话，之后用游标增量同步
+func (s *Service) SyncSince(ctx context.Context, userID int64, cursor string, limit int32) (*model.SyncPage, error) {
+	// 开始OpenTelemetry span
+	ctx, span := telemetry.StartSpan(ctx, "message.service.SyncSince")
+	defer span.End()
+
+	// 设置span属性
+	span.SetAttributes(
+		attribute.Int64("user.id", userID),
+		attribute.Bool("sync.initial", cursor == ""),
+		attribute.Int("sync.limit", int(limit)),
+	)
+
+	// 将业务信息添加到context
+	ctx = tracecontext.WithUserID(ctx, userID)
+
+	if userID <= 0 {
+		span.SetStatus(codes.Error, "invalid user id")
+		return nil, httpx.InvalidArgument(fmt.Errorf("无效的用户ID"))
+	}
+	if limit <= 0 {
+		limit = model.DefaultSyncPageSize
+	}
+	if limit > model.MaxSyncPageSize {
+		limit = model.MaxSyncPageSize
+	}
+
+	now := time.Now()
+	until := model.SyncPosition{Time: now.Add(-model.SyncSettleDelay).UnixMilli()}
+	current := &model.SyncCursor{Messages: until, Deletions: until, Watermarks: until}
+
+	if cursor == "" {
+		span.SetStatus(codes.Ok, "initial sync cursor issued")
+		return &model.SyncPage{NextCursor: model.EncodeSyncCursor(current)}, nil
+	}
+
+	from, err := model.DecodeSyncCursor(cursor)
+	if err != nil {
+		span.SetStatus(codes.Error, "invalid sync cursor")
+		return nil, httpx.InvalidArgument(err)
+	}
+
+	// 游标早于墓碑保留时长时，期间的删除记录可能已被清理，增量结果不完整
+	oldest := min(from.Messages.Time, from.Deletions.Time, from.Watermarks.Time)
+	if time.UnixMilli(oldest).Before(now.Add(-model.SyncRetention)) {
+		span.SetAttributes(attribute.Bool("sync.reset_required", true))
+		span.SetStatus(codes.Ok, "sync cursor expired")
+		return &model.SyncPage{NextCursor: model.EncodeSyncCursor(current), ResetRequired: true}, nil
+	}
+
+	windows, err := membership.Fetch(ctx, s.social, userID, 0)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to get membership periods")
+		return nil, err
+	}
+
+	untilTime := time.UnixMilli(until.Time)
+	page := &model.SyncPage{}
+	next := &model.SyncCursor{}
+
+	// 多取一条判断是否还有剩余
+	messages, err := s.dao.FindMessagesChangedSince(ctx, userID, windows, from.Messages, untilTime, int64(limit)+1)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to query changed messages")
+		return nil, fmt.Errorf("查询变更消息失败: %v", err)
+	}
+	var more bool
+	messages, more = trimSyncPage(messages, int(limit))
+	for i, msg := range messages {
+		if msg.Status == model.MessageStatusRevoked {
+			// 撤回的消息只同步状态，不下发内容
+			msg.Content = ""
+			msg.KeyID = ""
+		} else if err := s.decryptMessage(msg); err != nil {
+			// 本页截止到解密失败的消息之前，游标不越过该消息
+			log.Printf("解密消息失败: MessageID=%d, Error=%v", msg.MessageID, err)
+			if i == 0 {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, "failed to decrypt message")
+				return nil, httpx.Unavailable(fmt.Errorf("解密消息失败，请稍后重试"))
+			}
+			messages, more = messages[:i], true
+			break
+		}
+	}
+	page.Messages = messages
+	page.HasMore = page.HasMore || more
+	next.Messages = nextSyncPosition(messages, more, from.Messages, until, func(msg *model.Message) (time.Time, primitive.ObjectID) {
+		return msg.UpdatedAt, msg.ID
+	})
+
+	deletions, err := s.dao.FindTombstonesSince(ctx, userID, windows, from.Deletions, untilTime, int64(limit)+1)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to query deleted messages")
+		return nil, fmt.Errorf("查询已删除消息失败: %v", err)
+	}
+	deletions, more = trimSyncPage(deletions, int(limit))
+	page.HasMore = page.HasMore || more
+	page.Deletions = deletions
+	next.Deletions = nextSyncPosition(deletions, more, from.Deletions, until, func(tombstone *model.MessageTombstone) (time.Time, primitive.ObjectID) {
+		return tombstone.DeletedAt, tombstone.ID
+	})
+
+	watermarks, err := s.dao.FindGroupReadWatermarksSince(ctx, memberGroupIDs(windows), from.Watermarks, untilTime, int64(limit)+1)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to query read watermarks")
+		return nil, fmt.Errorf("查询已读水位失败: %v", err)
+	}
+	watermarks, more = trimSyncPage(watermarks, int(limit))
+	page.HasMore = page.HasMore || more
+	page.Watermarks = watermarks
+	next.Watermarks = nextSyncPosition(watermarks, more, from.Watermarks, until, func(watermark *model.GroupReadWatermark) (time.Time, primitive.ObjectID) {
+		return watermark.UpdatedAt, watermark.ID
+	})
+
+	page.NextCursor = model.EncodeSyncCursor(next)
+
+	span.SetAttributes(
+		attribute.Int("result.message_count", len(page.Messages)),
+		attribute.Int("result.deletion_count", len(page.Deletions)),
+		attribute.Int("result.watermark_count", len(page.Watermarks)),
+		attribute.Bool("result.has_more", page.HasMore),
+	)
+	span.SetStatus(codes.Ok, "sync page retrieved successfully")
+	return page, nil
+}
+
+// memberGroupIDs 用户仍在群内的群组，即存在未结束窗口的群组
+func memberGroupIDs(windows []model.VisibleWindow) []int64 {
+	seen := make(map[int64]bool, len(windows))
+	var groupIDs []int64
+	for _, window := range windows {
+		if window.End.IsZero() && !seen[window.GroupID] {
+			seen[window.GroupID] = true
+			groupIDs = append(groupIDs, window.GroupID)
+		}
+	}
+	return groupIDs
+}
+
+// trimSyncPage 去掉多取的一条，返回本页数据以及是否还有剩余
+func trimSyncPage[T any](items []T, limit int) ([]T, bool) {
+	if len(items) > limit {
+		return items[:limit], true
+	}
+	return items, false
+}
+
+// nextSyncPosition 计算下一次同步的位置：还有剩余时从本页最后一条继续，否则推进到本次同步的截止位置；
+// 位置不会后退，游标来自时钟较快的实例时保持原位置
+func nextSyncPosition[T any](items []T, more bool, from, until model.SyncPosition, key func(T) (time.Time, primitive.ObjectID)) model.SyncPosition {
+	if more && len(items) > 0 {
+		changedAt, id := key(items[len(items)-1])
+		return model.SyncPosition{Time: changedAt.UnixMilli(), ID: id.Hex()}
+	}
+	if from.Time >= until.Time {
+		return from
+	}
+	return until
+}
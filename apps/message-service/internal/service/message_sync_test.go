@@ -0,0 +1,180 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"google.golang.org/grpc"
+
+	"goim-social/api/rest"
+	"goim-social/apps/message-service/internal/model"
+	"goim-social/pkg/httpx"
+)
+
+// membershipSocial 返回固定成员身份时段的社交服务客户端
+type membershipSocial struct {
+	rest.SocialServiceClient
+	periods []*rest.GroupMembershipPeriod
+}
+
+func (m *membershipSocial) GetMembershipPeriods(ctx context.Context, req *rest.GetMembershipPeriodsRequest, opts ...grpc.CallOption) (*rest.GetMembershipPeriodsResponse, error) {
+	return &rest.GetMembershipPeriodsResponse{Success: true, Periods: m.periods}, nil
+}
+
+// syncCursorAt 所有类别都从at开始的同步游标
+func syncCursorAt(at time.Time) string {
+	position := model.SyncPosition{Time: at.UnixMilli()}
+	return model.EncodeSyncCursor(&model.SyncCursor{Messages: position, Deletions: position, Watermarks: position})
+}
+
+// TestSyncSinceDecryptFailure 解密失败的消息不被跳过：本页截止到它之前，之后的同步在它处失败而不越过
+func TestSyncSinceDecryptFailure(t *testing.T) {
+	s, store := newMemoryService(t)
+	s.social = &membershipSocial{}
+	ctx := context.Background()
+	base := time.Now().Add(-time.Hour)
+	saveTestMessage(t, store, &model.Message{MessageID: 1, From: 1, To: 2, Content: "a", CreatedAt: base.Add(time.Second)})
+	saveTestMessage(t, store, &model.Message{MessageID: 2, From: 1, To: 2, Content: "b", KeyID: "k1", CreatedAt: base.Add(2 * time.Second)})
+	saveTestMessage(t, store, &model.Message{MessageID: 3, From: 1, To: 2, Content: "c", CreatedAt: base.Add(3 * time.Second)})
+
+	page, err := s.SyncSince(ctx, 2, syncCursorAt(base), 10)
+	if err != nil {
+		t.Fatalf("SyncSince returned error: %v", err)
+	}
+	if len(page.Messages) != 1 || page.Messages[0].MessageID != 1 || !page.HasMore {
+		t.Fatalf("SyncSince() = %d messages, HasMore %v, want message 1 with more", len(page.Messages), page.HasMore)
+	}
+
+	_, err = s.SyncSince(ctx, 2, page.NextCursor, 10)
+	if httpx.StatusOf(err) != http.StatusServiceUnavailable {
+		t.Errorf("SyncSince at undecryptable message = %v, want unavailable", err)
+	}
+}
+
+// TestSyncSinceMembershipWindows 群消息只同步用户在群期间的，允许查看历史的群从群组创建开始
+func TestSyncSinceMembershipWindows(t *testing.T) {
+	s, store := newMemoryService(t)
+	base := time.Now().Add(-time.Hour)
+	at := func(seconds int) time.Time { return base.Add(time.Duration(seconds) * time.Second) }
+	s.social = &membershipSocial{periods: []*rest.GroupMembershipPeriod{
+		{GroupId: 9, JoinedAt: at(10).UnixMilli(), LeftAt: at(20).UnixMilli()},
+		{GroupId: 10, JoinedAt: at(30).UnixMilli(), HistoryVisible: true},
+	}}
+	saveTestMessage(t, store, &model.Message{MessageID: 1, From: 1, To: 2, CreatedAt: at(1)})
+	saveTestMessage(t, store, &model.Message{MessageID: 2, From: 1, GroupID: 9, CreatedAt: at(5)})
+	saveTestMessage(t, store, &model.Message{MessageID: 3, From: 1, GroupID: 9, CreatedAt: at(15)})
+	saveTestMessage(t, store, &model.Message{MessageID: 4, From: 1, GroupID: 9, CreatedAt: at(25)})
+	saveTestMessage(t, store, &model.Message{MessageID: 5, From: 1, GroupID: 10, CreatedAt: at(6)})
+	saveTestMessage(t, store, &model.Message{MessageID: 6, From: 1, GroupID: 11, CreatedAt: at(7)})
+
+	page, err := s.SyncSince(context.Background(), 2, syncCursorAt(base), 10)
+	if err != nil {
+		t.Fatalf("SyncSince returned error: %v", err)
+	}
+	var got []int64
+	for _, msg := range page.Messages {
+		got = append(got, msg.MessageID)
+	}
+	sort.Slice(got, func(i, j int) bool { return got[i] < got[j] })
+	if want := []int64{1, 3, 5}; !reflect.DeepEqual(got, want) {
+		t.Errorf("synced messages = %v, want %v", got, want)
+	}
+}
+
+// TestDecodeSyncCursor 编码后的游标可原样解析，时间缺失、ID无效或格式错误的游标被拒绝
+func TestDecodeSyncCursor(t *testing.T) {
+	id := primitive.NewObjectID().Hex()
+	cursor := &model.SyncCursor{
+		Messages:   model.SyncPosition{Time: 1000, ID: id},
+		Deletions:  model.SyncPosition{Time: 2000},
+		Watermarks: model.SyncPosition{Time: 3000},
+	}
+	got, err := model.DecodeSyncCursor(model.EncodeSyncCursor(cursor))
+	if err != nil {
+		t.Fatalf("DecodeSyncCursor returned error: %v", err)
+	}
+	if !reflect.DeepEqual(got, cursor) {
+		t.Errorf("DecodeSyncCursor() = %+v, want %+v", got, cursor)
+	}
+
+	invalid := map[string]string{
+		"not base64": "!!!",
+		"not json":   "bm90IGpzb24",
+		"zero time": model.EncodeSyncCursor(&model.SyncCursor{
+			Messages: model.SyncPosition{Time: 1000}, Deletions: model.SyncPosition{Time: 1000},
+		}),
+		"invalid id": model.EncodeSyncCursor(&model.SyncCursor{
+			Messages:   model.SyncPosition{Time: 1000, ID: "abc"},
+			Deletions:  model.SyncPosition{Time: 1000},
+			Watermarks: model.SyncPosition{Time: 1000},
+		}),
+	}
+	for name, s := range invalid {
+		if _, err := model.DecodeSyncCursor(s); err == nil {
+			t.Errorf("DecodeSyncCursor(%s) succeeded, want error", name)
+		}
+	}
+}
+
+// TestTrimSyncPage 多取的一条被去掉并标记还有剩余
+func TestTrimSyncPage(t *testing.T) {
+	items, more := trimSyncPage([]int{1, 2, 3}, 2)
+	if !reflect.DeepEqual(items, []int{1, 2}) || !more {
+		t.Errorf("trimSyncPage(3 items, 2) = %v, %v, want [1 2], true", items, more)
+	}
+	items, more = trimSyncPage([]int{1, 2}, 2)
+	if !reflect.DeepEqual(items, []int{1, 2}) || more {
+		t.Errorf("trimSyncPage(2 items, 2) = %v, %v, want [1 2], false", items, more)
+	}
+	items, more = trimSyncPage([]int(nil), 2)
+	if len(items) != 0 || more {
+		t.Errorf("trimSyncPage(nil, 2) = %v, %v, want empty, false", items, more)
+	}
+}
+
+// TestNextSyncPosition 还有剩余时停在本页最后一条，否则推进到截止位置，且位置不后退
+func TestNextSyncPosition(t *testing.T) {
+	type change struct {
+		at time.Time
+		id primitive.ObjectID
+	}
+	key := func(c change) (time.Time, primitive.ObjectID) { return c.at, c.id }
+	last := change{at: time.UnixMilli(1500), id: primitive.NewObjectID()}
+	items := []change{{at: time.UnixMilli(1200), id: primitive.NewObjectID()}, last}
+	from := model.SyncPosition{Time: 1000}
+	until := model.SyncPosition{Time: 2000}
+
+	if got, want := nextSyncPosition(items, true, from, until, key), (model.SyncPosition{Time: 1500, ID: last.id.Hex()}); got != want {
+		t.Errorf("nextSyncPosition(more) = %+v, want %+v", got, want)
+	}
+	if got := nextSyncPosition(items, false, from, until, key); got != until {
+		t.Errorf("nextSyncPosition(done) = %+v, want %+v", got, until)
+	}
+	if got := nextSyncPosition(nil, false, from, until, key); got != until {
+		t.Errorf("nextSyncPosition(empty) = %+v, want %+v", got, until)
+	}
+	ahead := model.SyncPosition{Time: 3000, ID: primitive.NewObjectID().Hex()}
+	if got := nextSyncPosition(nil, false, ahead, until, key); got != ahead {
+		t.Errorf("nextSyncPosition(cursor ahead of until) = %+v, want %+v", got, ahead)
+	}
+}
+
+// TestMemberGroupIDs 只返回存在未结束窗口的群组，重复的群组只返回一次
+func TestMemberGroupIDs(t *testing.T) {
+	left := time.UnixMilli(1000)
+	windows := []model.VisibleWindow{
+		{GroupID: 1, End: left},
+		{GroupID: 1},
+		{GroupID: 2, End: left},
+		{GroupID: 3, Start: left},
+		{GroupID: 3},
+	}
+	if got, want := memberGroupIDs(windows), []int64{1, 3}; !reflect.DeepEqual(got, want) {
+		t.Errorf("memberGroupIDs() = %v, want %v", got, want)
+	}
+}
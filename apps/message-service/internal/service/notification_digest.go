@@ -0,0 +1,108 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	goredis "github.com/go-redis/redis/v8"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+
+	"goim-social/apps/message-service/internal/model"
+	tracecontext "goim-social/pkg/context"
+	"goim-social/pkg/httpx"
+	"goim-social/pkg/logger"
+	"goim-social/pkg/telemetry"
+)
+
+// GetDigestMode 获取用户的通知摘要模式，未设置时返回默认模式；摘要关闭时总是立即推送
+func (s *Service) GetDigestMode(ctx context.Context, userID int64) (string, error) {
+	// 开始OpenTelemetry span
+	ctx, span := telemetry.StartSpan(ctx, "message.service.GetDigestMode")
+	defer span.End()
+
+	// 设置span属性
+	span.SetAttributes(attribute.Int64("user.id", userID))
+
+	// 将业务信息添加到context
+	ctx = tracecontext.WithUserID(ctx, userID)
+
+	if userID <= 0 {
+		span.SetStatus(codes.Error, "invalid user id")
+		return "", httpx.InvalidArgument(fmt.Errorf("无效的用户ID"))
+	}
+	if !s.digestCfg.Enabled {
+		span.SetStatus(codes.Ok, "digest disabled")
+		return model.DigestModeImmediate, nil
+	}
+
+	mode, err := s.redis.GetClient().HGet(ctx, model.CacheKeyDigestMode, strconv.FormatInt(userID, 10)).Result()
+	if err == goredis.Nil || (err == nil && !model.IsDigestMode(mode)) {
+		mode = s.digestCfg.DefaultMode
+		if !model.IsDigestMode(mode) {
+			mode = model.DigestModeImmediate
+		}
+	} else if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to get digest mode")
+		return "", httpx.Unavailable(fmt.Errorf("获取通知摘要设置失败: %v", err))
+	}
+
+	span.SetAttributes(attribute.String("digest.mode", mode))
+	span.SetStatus(codes.Ok, "digest mode retrieved")
+	return mode, nil
+}
+
+// SetDigestMode 设置用户的通知摘要模式；切换为立即推送时，已积累的摘要在下一次扫描时推送
+func (s *Service) SetDigestMode(ctx context.Context, userID int64, mode string) error {
+	// 开始OpenTelemetry span
+	ctx, span := telemetry.StartSpan(ctx, "message.service.SetDigestMode")
+	defer span.End()
+
+	// 设置span属性
+	span.SetAttributes(
+		attribute.Int64("user.id", userID),
+		attribute.String("digest.mode", mode),
+	)
+
+	// 将业务信息添加到context
+	ctx = tracecontext.WithUserID(ctx, userID)
+
+	if userID <= 0 {
+		span.SetStatus(codes.Error, "invalid user id")
+		return httpx.InvalidArgument(fmt.Errorf("无效的用户ID"))
+	}
+	if !model.IsDigestMode(mode) {
+		span.SetStatus(codes.Error, "invalid digest mode")
+		return httpx.InvalidArgument(fmt.Errorf("无效的摘要模式: %s，可选值为 %s/%s/%s",
+			mode, model.DigestModeImmediate, model.DigestModeHourly, model.DigestModeDaily))
+	}
+
+	member := strconv.FormatInt(userID, 10)
+	if err := s.redis.HSet(ctx, model.CacheKeyDigestMode, member, mode); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to set digest mode")
+		return httpx.Unavailable(fmt.Errorf("保存通知摘要设置失败: %v", err))
+	}
+
+	if mode == model.DigestModeImmediate {
+		// 只更新已登记的推送时间，没有积累的摘要时不登记
+		if err := s.redis.GetClient().ZAddXX(ctx, model.CacheKeyDigestDue, &goredis.Z{
+			Score:  float64(time.Now().UnixMilli()),
+			Member: member,
+		}).Err(); err != nil {
+			s.logger.Warn(ctx, "Failed to reschedule pending digest",
+				logger.F("userID", userID),
+				logger.F("error", err.Error()))
+		}
+	}
+
+	s.logger.Info(ctx, "Digest mode updated",
+		logger.F("userID", userID),
+		logger.F("mode", mode))
+
+	span.SetStatus(codes.Ok, "digest mode updated")
+	return nil
+}
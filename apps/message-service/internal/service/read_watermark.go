@@ -0,0 +1,216 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+
+	"goim-social/api/rest"
+	"goim-social/apps/message-service/internal/dao"
+	"goim-social/apps/message-service/internal/model"
+	tracecontext "goim-social/pkg/context"
+	"goim-social/pkg/logger"
+	"goim-social/pkg/telemetry"
+)
+
+// SetGroupReadWatermark 推进群成员的已读水位
+// 水位为成员在群内已读到的消息ID，只前进不后退，传入比当前水位更早的消息时保持原水位并返回当前水位
+func (s *Service) SetGroupReadWatermark(ctx context.Context, userID, groupID, messageID int64) (*model.GroupReadWatermark, error) {
+	// 开始OpenTelemetry span
+	ctx, span := telemetry.StartSpan(ctx, "message.service.SetGroupReadWatermark")
+	defer span.End()
+
+	// 设置span属性
+	span.SetAttributes(
+		attribute.Int64("group.id", groupID),
+		attribute.Int64("group.user_id", userID),
+		attribute.Int64("message.id", messageID),
+	)
+
+	// 将业务信息添加到context
+	ctx = tracecontext.WithUserID(ctx, userID)
+	ctx = tracecontext.WithGroupID(ctx, groupID)
+	ctx = tracecontext.WithMessageID(ctx, messageID)
+
+	// 参数验证
+	if userID <= 0 || groupID <= 0 || messageID <= 0 {
+		span.SetStatus(codes.Error, "invalid parameters")
+		return nil, fmt.Errorf("无效的用户ID、群组ID或消息ID")
+	}
+
+	// 权限验证
+	if err := s.checkGroupMember(ctx, userID, groupID); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "permission denied")
+		return nil, err
+	}
+
+	// 水位必须指向本群的消息，保证与群内消息ID的顺序一致
+	if _, err := s.getGroupMessage(ctx, groupID, messageID); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "invalid message")
+		return nil, err
+	}
+
+	watermark, err := s.dao.SetGroupReadWatermark(ctx, groupID, userID, messageID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to set read watermark")
+		s.logger.Error(ctx, "Failed to set group read watermark",
+			logger.F("groupID", groupID),
+			logger.F("userID", userID),
+			logger.F("messageID", messageID),
+			logger.F("error", err.Error()))
+		return nil, fmt.Errorf("设置群已读水位失败: %v", err)
+	}
+
+	span.SetAttributes(attribute.Int64("group.read_watermark", watermark.MessageID))
+
+	// 已读人数变化，由汇总任务向发送者推送新的汇总
+	s.markDeliveryDirty(ctx, groupID)
+
+	s.logger.Info(ctx, "Group read watermark updated",
+		logger.F("groupID", groupID),
+		logger.F("userID", userID),
+		logger.F("watermark", watermark.MessageID))
+
+	span.SetStatus(codes.Ok, "group read watermark updated")
+	return watermark, nil
+}
+
+// GetGroupReadStatus 获取群成员的已读水位，messageID大于0时统计该消息的已读人数
+// 已读人数为水位不低于该消息的当前成员数，不含消息发送者；已退群成员的水位不返回
+func (s *Service) GetGroupReadStatus(ctx context.Context, userID, groupID, messageID int64) (*model.GroupReadStatus, error) {
+	// 开始OpenTelemetry span
+	ctx, span := telemetry.StartSpan(ctx, "message.service.GetGroupReadStatus")
+	defer span.End()
+
+	// 设置span属性
+	span.SetAttributes(
+		attribute.Int64("group.id", groupID),
+		attribute.Int64("group.user_id", userID),
+		attribute.Int64("message.id", messageID),
+	)
+
+	// 将业务信息添加到context
+	ctx = tracecontext.WithUserID(ctx, userID)
+	ctx = tracecontext.WithGroupID(ctx, groupID)
+
+	// 参数验证
+	if userID <= 0 || groupID <= 0 || messageID < 0 {
+		span.SetStatus(codes.Error, "invalid parameters")
+		return nil, fmt.Errorf("无效的用户ID、群组ID或消息ID")
+	}
+
+	// 权限验证
+	if err := s.checkGroupMember(ctx, userID, groupID); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "permission denied")
+		return nil, err
+	}
+
+	var senderID int64
+	if messageID > 0 {
+		message, err := s.getGroupMessage(ctx, groupID, messageID)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, "invalid message")
+			return nil, err
+		}
+		senderID = message.From
+	}
+
+	memberResp, err := s.social.GetGroupMemberIDs(ctx, &rest.GetGroupMemberIDsRequest{GroupId: groupID})
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to get group members")
+		return nil, fmt.Errorf("获取群成员列表失败: %v", err)
+	}
+	if !memberResp.Success {
+		span.SetStatus(codes.Error, "failed to get group members")
+		return nil, fmt.Errorf("获取群成员列表失败: %s", memberResp.Message)
+	}
+	members := make(map[int64]struct{}, len(memberResp.MemberIds))
+	for _, memberID := range memberResp.MemberIds {
+		members[memberID] = struct{}{}
+	}
+
+	watermarks, err := s.dao.GetGroupReadWatermarks(ctx, groupID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to get read watermarks")
+		s.logger.Error(ctx, "Failed to get group read watermarks",
+			logger.F("groupID", groupID),
+			logger.F("error", err.Error()))
+		return nil, fmt.Errorf("获取群已读水位失败: %v", err)
+	}
+
+	status := &model.GroupReadStatus{
+		GroupID:     groupID,
+		MessageID:   messageID,
+		MemberCount: int64(len(members)),
+		Watermarks:  make([]*model.GroupReadWatermark, 0, len(watermarks)),
+	}
+	for _, watermark := range watermarks {
+		if _, ok := members[watermark.UserID]; !ok {
+			continue
+		}
+		status.Watermarks = append(status.Watermarks, watermark)
+		if messageID > 0 && watermark.UserID != senderID && watermark.MessageID >= messageID {
+			status.ReadCount++
+		}
+	}
+
+	span.SetAttributes(
+		attribute.Int64("group.read_count", status.ReadCount),
+		attribute.Int64("group.member_count", status.MemberCount),
+	)
+
+	s.logger.Info(ctx, "Group read status retrieved successfully",
+		logger.F("groupID", groupID),
+		logger.F("messageID", messageID),
+		logger.F("readCount", status.ReadCount))
+
+	span.SetStatus(codes.Ok, "group read status retrieved successfully")
+	return status, nil
+}
+
+// checkGroupMember 验证用户是否为群成员
+func (s *Service) checkGroupMember(ctx context.Context, userID, groupID int64) error {
+	if s.social == nil {
+		return fmt.Errorf("社交服务不可用")
+	}
+
+	resp, err := s.social.ValidateGroupMember(ctx, &rest.ValidateGroupMemberRequest{
+		GroupId: groupID,
+		UserId:  userID,
+	})
+	if err != nil {
+		return fmt.Errorf("验证群成员身份失败: %v", err)
+	}
+	if !resp.Success {
+		return fmt.Errorf("验证群成员身份失败: %s", resp.Message)
+	}
+	if !resp.IsMember {
+		return fmt.Errorf("用户不是群成员")
+	}
+	return nil
+}
+
+// getGroupMessage 获取群消息，消息不存在或不属于该群时返回错误
+func (s *Service) getGroupMessage(ctx context.Context, groupID, messageID int64) (*model.Message, error) {
+	message, err := s.dao.GetMessage(ctx, messageID)
+	if err != nil {
+		if errors.Is(err, dao.ErrNotFound) {
+			return nil, fmt.Errorf("消息不存在: %d", messageID)
+		}
+		return nil, fmt.Errorf("获取消息失败: %v", err)
+	}
+	if message.GroupID != groupID {
+		return nil, fmt.Errorf("消息不属于该群组")
+	}
+	return message, nil
+}
@@ -2,22 +2,24 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
+	"strconv"
 	"strings"
 	"time"
 
-	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
-	"go.mongodb.org/mongo-driver/mongo/options"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
 
 	"goim-social/api/rest"
 	"goim-social/apps/message-service/internal/dao"
 	"goim-social/apps/message-service/internal/model"
+	"goim-social/pkg/config"
 	tracecontext "goim-social/pkg/context"
-	"goim-social/pkg/database"
+	"goim-social/pkg/conversation"
+	"goim-social/pkg/encryption"
 	"goim-social/pkg/kafka"
 	"goim-social/pkg/logger"
 	"goim-social/pkg/redis"
@@ -26,25 +28,55 @@ import (
 
 // Service Message服务（合并了历史记录功能）
 type Service struct {
-	db     *database.MongoDB
-	redis  *redis.RedisClient
-	kafka  *kafka.Producer
-	dao    dao.MessageDAO
-	logger logger.Logger
+	redis        *redis.RedisClient
+	kafka        *kafka.Producer
+	dao          dao.MessageDAO
+	encryptor    encryption.Encryptor
+	social       rest.SocialServiceClient // 社交服务客户端，用于群组权限校验
+	pushStats    PushStatsSource          // 推送统计来源，用于投递链路状态检查
+	acks         PushAckTracker           // 推送确认跟踪，客户端确认后停止超时重发
+	fanout       FanoutStatsSource        // 扇出投递结果统计来源，用于投递链路状态检查
+	exportCfg    config.MessageExportConfig
+	exportQueue  chan *model.GroupExport // 等待后台生成的异步导出，由RunExportWorker依次生成
+	archiveCfg   config.MessageArchiveConfig
+	highlightCfg config.HighlightConfig // 会话内搜索摘要的高亮标签和长度，与搜索服务共用配置
+	digestCfg    config.NotificationDigestConfig
+	retentionCfg config.MessageRetentionConfig
+	pollCfg      config.MessagePollConfig
+	pinCfg       config.MessagePinConfig
+	reportCfg    config.DeliveryReportConfig // 群消息投递和已读汇总配置
+	logger       logger.Logger
 }
 
 // NewService 创建Message服务实例
-func NewService(db *database.MongoDB, redis *redis.RedisClient, kafka *kafka.Producer, logger logger.Logger) *Service {
-	messageDAO := dao.NewMongoDAO(db.GetDatabase())
+func NewService(store dao.MessageDAO, redis *redis.RedisClient, kafka *kafka.Producer, encryptor encryption.Encryptor, social rest.SocialServiceClient, pushStats PushStatsSource, acks PushAckTracker, fanout FanoutStatsSource, exportCfg config.MessageExportConfig, archiveCfg config.MessageArchiveConfig, highlightCfg config.HighlightConfig, digestCfg config.NotificationDigestConfig, retentionCfg config.MessageRetentionConfig, pollCfg config.MessagePollConfig, pinCfg config.MessagePinConfig, reportCfg config.DeliveryReportConfig, logger logger.Logger) *Service {
 	return &Service{
-		db:     db,
-		redis:  redis,
-		kafka:  kafka,
-		dao:    messageDAO,
-		logger: logger,
+		redis:        redis,
+		kafka:        kafka,
+		dao:          store,
+		encryptor:    encryptor,
+		social:       social,
+		pushStats:    pushStats,
+		acks:         acks,
+		fanout:       fanout,
+		exportCfg:    exportCfg,
+		exportQueue:  make(chan *model.GroupExport, model.ExportQueueSize),
+		archiveCfg:   archiveCfg,
+		highlightCfg: highlightCfg,
+		digestCfg:    digestCfg,
+		retentionCfg: retentionCfg,
+		pollCfg:      pollCfg,
+		pinCfg:       pinCfg,
+		reportCfg:    reportCfg,
+		logger:       logger,
 	}
 }
 
+// EnsureIndexes 创建服务依赖的索引
+func (s *Service) EnsureIndexes(ctx context.Context) error {
+	return s.dao.EnsureIndexes(ctx)
+}
+
 // SaveMessage 保存消息到数据库
 func (s *Service) SaveMessage(ctx context.Context, msg *model.Message) error {
 	// 开始OpenTelemetry span
@@ -66,20 +98,34 @@ func (s *Service) SaveMessage(ctx context.Context, msg *model.Message) error {
 		ctx = tracecontext.WithGroupID(ctx, msg.GroupID)
 	}
 
-	collection := s.db.GetCollection("messages")
-
 	if msg.CreatedAt.IsZero() {
 		msg.CreatedAt = time.Now()
 	}
 	msg.UpdatedAt = time.Now()
 
-	_, err := collection.InsertOne(ctx, msg)
-	if err != nil {
+	// 加密消息内容后再落库，保存副本避免修改调用方持有的明文
+	stored := *msg
+	if stored.KeyID == "" {
+		content, keyID, err := s.encryptor.Encrypt(msg.Content)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, "failed to encrypt message")
+			return fmt.Errorf("加密消息内容失败: %v", err)
+		}
+		stored.Content = content
+		stored.KeyID = keyID
+	}
+
+	if err := s.dao.SaveMessage(ctx, &stored); err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, "failed to save message")
 		return fmt.Errorf("保存消息失败: %v", err)
 	}
 
+	if s.archiveCfg.AutoUnarchive {
+		s.unarchiveOnNewMessage(ctx, msg)
+	}
+
 	span.SetStatus(codes.Ok, "message saved successfully")
 	return nil
 }
@@ -104,65 +150,27 @@ func (s *Service) GetMessageHistory(ctx context.Context, userID, groupID int64,
 		ctx = tracecontext.WithGroupID(ctx, groupID)
 	}
 
-	collection := s.db.GetCollection("messages")
-
-	// 构建查询条件
-	var filter bson.M
 	if groupID > 0 {
-		// 群聊消息：查询该群组的所有消息
-		filter = bson.M{"group_id": groupID}
 		span.SetAttributes(attribute.String("query.type", "group"))
 	} else {
-		// 私聊消息：查询用户参与的对话
-		// 这里需要另一个参数来指定对话的另一方
-		// 暂时查询用户相关的所有私聊消息
-		filter = bson.M{
-			"$and": []bson.M{
-				{"group_id": bson.M{"$eq": 0}}, // 不是群聊
-				{
-					"$or": []bson.M{
-						{"from": userID},
-						{"to": userID},
-					},
-				},
-			},
-		}
+		// 私聊消息：暂时查询用户相关的所有私聊消息
 		span.SetAttributes(attribute.String("query.type", "private"))
 	}
 
-	// 获取总数
-	total, err := collection.CountDocuments(ctx, filter)
-	if err != nil {
-		span.RecordError(err)
-		span.SetStatus(codes.Error, "failed to count messages")
-		return nil, 0, fmt.Errorf("统计消息数量失败: %v", err)
-	}
-
-	// 分页查询
-	skip := int64((page - 1) * size)
-	limit := int64(size)
-
-	opts := options.Find().
-		SetSort(bson.D{{Key: "timestamp", Value: -1}}). // 按时间倒序
-		SetSkip(skip).
-		SetLimit(limit)
-
-	cursor, err := collection.Find(ctx, filter, opts)
+	stored, total, err := s.dao.ListMessageHistory(ctx, userID, groupID, int64((page-1)*size), int64(size))
 	if err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, "failed to query messages")
-		return nil, 0, fmt.Errorf("查询消息失败: %v", err)
+		return nil, 0, err
 	}
-	defer cursor.Close(ctx)
 
 	var messages []*model.Message
-	for cursor.Next(ctx) {
-		var msg model.Message
-		if err := cursor.Decode(&msg); err != nil {
-			log.Printf("解码消息失败: %v", err)
+	for _, msg := range stored {
+		if err := s.decryptMessage(msg); err != nil {
+			log.Printf("解密消息失败: MessageID=%d, Error=%v", msg.MessageID, err)
 			continue
 		}
-		messages = append(messages, &msg)
+		messages = append(messages, msg)
 	}
 
 	span.SetAttributes(
@@ -175,70 +183,44 @@ func (s *Service) GetMessageHistory(ctx context.Context, userID, groupID int64,
 
 // UpdateMessageStatus 更新消息状态
 func (s *Service) UpdateMessageStatus(ctx context.Context, messageID int64, status string) error {
-	collection := s.db.GetCollection("messages")
-
-	filter := bson.M{"message_id": messageID}
-	update := bson.M{
-		"$set": bson.M{
-			"status":     status,
-			"updated_at": time.Now(),
-		},
+	err := s.dao.UpdateMessageStatus(ctx, messageID, status)
+	if errors.Is(err, dao.ErrNotFound) {
+		return fmt.Errorf("消息不存在: MessageID=%d", messageID)
 	}
-
-	result, err := collection.UpdateOne(ctx, filter, update)
 	if err != nil {
 		return fmt.Errorf("更新消息状态失败: %v", err)
 	}
-
-	if result.MatchedCount == 0 {
-		return fmt.Errorf("消息不存在: MessageID=%d", messageID)
-	}
-
 	return nil
 }
 
-// MarkMessageAsRead 标记消息为已读
-func (s *Service) MarkMessageAsRead(ctx context.Context, userID, messageID int64) error {
+// MarkMessageAsRead 标记消息为已读，返回被标记的消息
+func (s *Service) MarkMessageAsRead(ctx context.Context, userID, messageID int64) (*model.Message, error) {
 	// 验证用户是否有权限标记该消息为已读
-	collection := s.db.GetCollection("messages")
-
-	filter := bson.M{
-		"message_id": messageID,
-		"$or": []bson.M{
-			{"to": userID},                 // 私聊中的接收者
-			{"group_id": bson.M{"$gt": 0}}, // 群聊消息（需要进一步验证群成员身份）
-		},
-	}
-
-	var message model.Message
-	err := collection.FindOne(ctx, filter).Decode(&message)
+	message, err := s.dao.GetRecipientMessage(ctx, userID, messageID)
 	if err != nil {
-		return fmt.Errorf("消息不存在或无权限: %v", err)
+		return nil, fmt.Errorf("消息不存在或无权限: %v", err)
 	}
 
 	// 更新消息状态为已读
-	return s.UpdateMessageStatus(ctx, messageID, model.MessageStatusRead)
+	if err := s.UpdateMessageStatus(ctx, messageID, model.MessageStatusRead); err != nil {
+		return nil, err
+	}
+	return message, nil
 }
 
 // DeleteMessage 删除消息
 func (s *Service) DeleteMessage(ctx context.Context, messageID int64, userID int64) error {
-	collection := s.db.GetCollection("messages")
-
 	// 只允许发送者删除自己的消息
-	filter := bson.M{
-		"message_id": messageID,
-		"from":       userID,
+	message, err := s.dao.DeleteMessage(ctx, messageID, userID)
+	if errors.Is(err, dao.ErrNotFound) {
+		return fmt.Errorf("消息不存在或无权限删除")
 	}
-
-	result, err := collection.DeleteOne(ctx, filter)
 	if err != nil {
 		return fmt.Errorf("删除消息失败: %v", err)
 	}
 
-	if result.DeletedCount == 0 {
-		return fmt.Errorf("消息不存在或无权限删除")
-	}
-
+	// 通知搜索索引等下游移除已删除的消息
+	s.publishMessagesPurged(ctx, []*model.Message{message}, model.DeletionReasonDeleted)
 	return nil
 }
 
@@ -246,18 +228,25 @@ func (s *Service) DeleteMessage(ctx context.Context, messageID int64, userID int
 func (s *Service) SaveWSMessage(ctx context.Context, msg *rest.WSMessage) error {
 	// 构造消息对象
 	message := &model.Message{
-		ID:          primitive.NewObjectID(),
-		MessageID:   msg.MessageId,
-		From:        msg.From,
-		To:          msg.To,
-		GroupID:     msg.GroupId,
-		Content:     msg.Content,
-		MessageType: int(msg.MessageType),
-		Timestamp:   msg.Timestamp,
-		AckID:       msg.AckId,
-		Status:      model.MessageStatusSent,
-		CreatedAt:   time.Now(),
-		UpdatedAt:   time.Now(),
+		ID:             primitive.NewObjectID(),
+		MessageID:      msg.MessageId,
+		From:           msg.From,
+		To:             msg.To,
+		GroupID:        msg.GroupId,
+		ConversationID: conversation.ID(msg.From, msg.To, msg.GroupId),
+		Content:        msg.Content,
+		MessageType:    int(msg.MessageType),
+		Timestamp:      msg.Timestamp,
+		SenderSeq:      msg.SenderSeq,
+		AckID:          msg.AckId,
+		Status:         model.MessageStatusSent,
+		CreatedAt:      time.Now(),
+		UpdatedAt:      time.Now(),
+	}
+	if err := dao.ApplyMessageTTL(ctx, s.dao, message, msg.Ttl); err != nil {
+		s.logger.Warn(ctx, "Failed to get conversation ttl",
+			logger.F("conversationID", message.ConversationID),
+			logger.F("error", err.Error()))
 	}
 
 	return s.SaveMessage(ctx, message)
@@ -271,18 +260,24 @@ func (s *Service) SendMessage(ctx context.Context, req *rest.SendMessageRequest)
 
 	// 构造消息对象
 	message := &model.Message{
-		ID:          primitive.NewObjectID(),
-		MessageID:   messageID,
-		From:        0, // TODO: 从上下文获取用户ID
-		To:          req.To,
-		GroupID:     req.GroupId,
-		Content:     req.Content,
-		MessageType: int(req.MessageType),
-		Timestamp:   time.Now().Unix(),
-		AckID:       ackID,
-		Status:      model.MessageStatusSent,
-		CreatedAt:   time.Now(),
-		UpdatedAt:   time.Now(),
+		ID:             primitive.NewObjectID(),
+		MessageID:      messageID,
+		From:           0, // TODO: 从上下文获取用户ID
+		To:             req.To,
+		GroupID:        req.GroupId,
+		ConversationID: conversation.ID(0, req.To, req.GroupId),
+		Content:        req.Content,
+		MessageType:    int(req.MessageType),
+		Timestamp:      time.Now().Unix(),
+		AckID:          ackID,
+		Status:         model.MessageStatusSent,
+		CreatedAt:      time.Now(),
+		UpdatedAt:      time.Now(),
+	}
+	if err := dao.ApplyMessageTTL(ctx, s.dao, message, req.Ttl); err != nil {
+		s.logger.Warn(ctx, "Failed to get conversation ttl",
+			logger.F("conversationID", message.ConversationID),
+			logger.F("error", err.Error()))
 	}
 
 	err := s.SaveMessage(ctx, message)
@@ -293,62 +288,118 @@ func (s *Service) SendMessage(ctx context.Context, req *rest.SendMessageRequest)
 	return messageID, ackID, nil
 }
 
-// GetUnreadMessages 获取未读消息
-func (s *Service) GetUnreadMessages(ctx context.Context, userID int64) ([]*model.Message, error) {
+// GetUnreadMessages 分页获取未读消息，按消息ID倒序，游标为上一页最后一条消息的ID
+// 每页最多MaxUnreadPageSize条，总数单独计数，避免长期离线用户一次拉取全部未读消息
+func (s *Service) GetUnreadMessages(ctx context.Context, userID int64, cursor string, limit int32) (*model.UnreadPage, error) {
 	// 开始OpenTelemetry span
 	ctx, span := telemetry.StartSpan(ctx, "message.service.GetUnreadMessages")
 	defer span.End()
 
 	// 设置span属性
-	span.SetAttributes(attribute.Int64("user.id", userID))
+	span.SetAttributes(
+		attribute.Int64("user.id", userID),
+		attribute.String("unread.cursor", cursor),
+		attribute.Int("unread.limit", int(limit)),
+	)
 
 	// 将业务信息添加到context
 	ctx = tracecontext.WithUserID(ctx, userID)
 
-	collection := s.db.GetCollection("messages")
+	if limit <= 0 || limit > model.MaxUnreadPageSize {
+		limit = model.MaxUnreadPageSize
+	}
+
+	// 总数只计数，不加载消息
+	total, err := s.dao.CountUnreadMessages(ctx, userID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to count unread messages")
+		return nil, fmt.Errorf("统计未读消息失败: %v", err)
+	}
 
-	// 查询未读消息
-	filter := bson.M{
-		"$or": []bson.M{
-			{"to": userID, "status": bson.M{"$ne": model.MessageStatusRead}},
-			{"group_id": bson.M{"$gt": 0}, "status": bson.M{"$ne": model.MessageStatusRead}},
-		},
+	var beforeID int64
+	if cursor != "" {
+		beforeID, err = strconv.ParseInt(cursor, 10, 64)
+		if err != nil || beforeID <= 0 {
+			span.SetStatus(codes.Error, "invalid unread cursor")
+			return nil, fmt.Errorf("无效的游标: %s", cursor)
+		}
 	}
 
-	cursor, err := collection.Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "timestamp", Value: -1}}))
+	// 多取一条判断是否还有下一页
+	messages, err := s.dao.ListUnreadMessages(ctx, userID, beforeID, int64(limit)+1)
 	if err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, "failed to query unread messages")
 		return nil, fmt.Errorf("查询未读消息失败: %v", err)
 	}
-	defer cursor.Close(ctx)
 
-	var messages []*model.Message
-	for cursor.Next(ctx) {
-		var msg model.Message
-		if err := cursor.Decode(&msg); err != nil {
-			log.Printf("解码消息失败: %v", err)
+	page := &model.UnreadPage{Total: total}
+	var lastID int64
+	for i, msg := range messages {
+		if i >= int(limit) {
+			// 游标取本页最后一条已读取的消息，解密失败被跳过的消息也不会重复出现
+			if lastID > 0 {
+				page.NextCursor = strconv.FormatInt(lastID, 10)
+			}
+			break
+		}
+
+		lastID = msg.MessageID
+		if err := s.decryptMessage(msg); err != nil {
+			log.Printf("解密消息失败: MessageID=%d, Error=%v", msg.MessageID, err)
 			continue
 		}
-		messages = append(messages, &msg)
+		page.Messages = append(page.Messages, msg)
 	}
 
-	span.SetAttributes(attribute.Int("result.count", len(messages)))
+	span.SetAttributes(
+		attribute.Int("result.count", len(page.Messages)),
+		attribute.Int64("result.total", total),
+		attribute.Bool("result.has_more", page.NextCursor != ""),
+	)
 	span.SetStatus(codes.Ok, "unread messages retrieved successfully")
-	return messages, nil
+	return page, nil
+}
+
+// decryptMessage 解密消息内容，仅在返回给有权限的参与者时调用
+func (s *Service) decryptMessage(msg *model.Message) error {
+	if msg.KeyID == "" {
+		return nil
+	}
+
+	content, err := s.encryptor.Decrypt(msg.Content, msg.KeyID)
+	if err != nil {
+		return err
+	}
+	msg.Content = content
+	msg.KeyID = ""
+	return nil
 }
 
-// MarkMessagesAsRead 批量标记消息已读
-func (s *Service) MarkMessagesAsRead(ctx context.Context, userID int64, messageIDs []int64) ([]int64, error) {
-	var failedIDs []int64
+// MarkMessagesAsRead 批量标记消息已读，同时视为客户端已确认收到推送
+// 返回标记成功的消息（调用方据此生成已读回执）和标记失败的消息ID
+func (s *Service) MarkMessagesAsRead(ctx context.Context, userID int64, messageIDs []int64) ([]*model.Message, []int64, error) {
+	var (
+		readMessages []*model.Message
+		failedIDs    []int64
+	)
+
+	// 客户端已收到消息，停止超时重发
+	if s.acks != nil {
+		s.acks.Acknowledge(ctx, userID, messageIDs)
+	}
 
 	for _, messageID := range messageIDs {
-		if err := s.MarkMessageAsRead(ctx, userID, messageID); err != nil {
+		message, err := s.MarkMessageAsRead(ctx, userID, messageID)
+		if err != nil {
 			failedIDs = append(failedIDs, messageID)
+			continue
 		}
+		readMessages = append(readMessages, message)
 	}
 
-	return failedIDs, nil
+	return readMessages, failedIDs, nil
 }
 
 // ==================== 历史记录相关服务方法 ====================
@@ -535,12 +586,7 @@ func (s *Service) GetUserHistory(ctx context.Context, userID int64, actionType,
 		span.SetStatus(codes.Error, "invalid user ID")
 		return nil, 0, fmt.Errorf("invalid user ID")
 	}
-	if page <= 0 {
-		page = 1
-	}
-	if pageSize <= 0 || pageSize > model.MaxPageSize {
-		pageSize = model.DefaultPageSize
-	}
+	page, pageSize = UserHistoryPage(page, pageSize)
 
 	// 获取历史记录
 	records, total, err := s.dao.GetUserHistory(ctx, userID, actionType, objectType, startTime, endTime, page, pageSize)
@@ -567,6 +613,17 @@ func (s *Service) GetUserHistory(ctx context.Context, userID int64, actionType,
 	return records, total, nil
 }
 
+// UserHistoryPage 规范用户历史记录的页码和每页数量，未传或超过上限时使用默认每页数量
+func UserHistoryPage(page, pageSize int32) (int32, int32) {
+	if page <= 0 {
+		page = 1
+	}
+	if pageSize <= 0 || pageSize > model.MaxPageSize {
+		pageSize = model.DefaultPageSize
+	}
+	return page, pageSize
+}
+
 // DeleteUserHistory 删除用户历史记录
 func (s *Service) DeleteUserHistory(ctx context.Context, userID int64, recordIDs []string) (int64, error) {
 	// 开始OpenTelemetry span
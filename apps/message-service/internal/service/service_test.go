@@ -0,0 +1,111 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"goim-social/apps/message-service/internal/dao"
+	"goim-social/apps/message-service/internal/model"
+	"goim-social/pkg/conversation"
+	"goim-social/pkg/encryption"
+	"goim-social/pkg/logger"
+)
+
+// memoryDAO 消息存储使用内存实现，消息存储之外的辅助数据方法未实现，调用时panic
+type memoryDAO struct {
+	*dao.MemoryStore
+	unimplementedDAO
+}
+
+type unimplementedDAO struct {
+	dao.MessageDAO
+}
+
+// FindGroupReadWatermarksSince 群已读水位不在消息存储中，测试中始终为空
+func (d *memoryDAO) FindGroupReadWatermarksSince(ctx context.Context, groupIDs []int64, after model.SyncPosition, until time.Time, limit int64) ([]*model.GroupReadWatermark, error) {
+	return nil, nil
+}
+
+// newMemoryService 创建使用内存消息存储、不加密的服务
+func newMemoryService(t *testing.T) (*Service, *dao.MemoryStore) {
+	t.Helper()
+	log, err := logger.NewLogger("error")
+	if err != nil {
+		t.Fatalf("创建日志失败: %v", err)
+	}
+	store := dao.NewMemoryStore()
+	s := &Service{
+		dao:       &memoryDAO{MemoryStore: store},
+		encryptor: encryption.NewNoopEncryptor(),
+		logger:    log,
+	}
+	return s, store
+}
+
+// saveTestMessage 直接写入存储，不经过服务层的加密
+func saveTestMessage(t *testing.T, store *dao.MemoryStore, msg *model.Message) {
+	t.Helper()
+	if msg.ConversationID == "" {
+		msg.ConversationID = conversation.ID(msg.From, msg.To, msg.GroupID)
+	}
+	if msg.Status == "" {
+		msg.Status = model.MessageStatusSent
+	}
+	if msg.UpdatedAt.IsZero() {
+		msg.UpdatedAt = msg.CreatedAt
+	}
+	if err := store.SaveMessage(context.Background(), msg); err != nil {
+		t.Fatalf("SaveMessage(%d) returned error: %v", msg.MessageID, err)
+	}
+}
+
+// TestDeleteMessageOnlySender 只有发送者能删除消息，删除后消息不再出现在历史中并留下墓碑
+func TestDeleteMessageOnlySender(t *testing.T) {
+	s, store := newMemoryService(t)
+	ctx := context.Background()
+	now := time.Now()
+	saveTestMessage(t, store, &model.Message{MessageID: 1, From: 1, To: 2, Content: "hi", Timestamp: now.Unix(), CreatedAt: now})
+
+	if err := s.DeleteMessage(ctx, 1, 2); err == nil {
+		t.Fatal("DeleteMessage by recipient succeeded, want error")
+	}
+	if err := s.DeleteMessage(ctx, 1, 1); err != nil {
+		t.Fatalf("DeleteMessage by sender returned error: %v", err)
+	}
+
+	messages, total, err := s.GetMessageHistory(ctx, 2, 0, 1, 20)
+	if err != nil {
+		t.Fatalf("GetMessageHistory returned error: %v", err)
+	}
+	if total != 0 || len(messages) != 0 {
+		t.Errorf("GetMessageHistory after delete = %d messages (total %d), want none", len(messages), total)
+	}
+
+	tombstones, err := store.FindTombstonesSince(ctx, 2, nil, model.SyncPosition{Time: 1}, now.Add(time.Minute), 10)
+	if err != nil {
+		t.Fatalf("FindTombstonesSince returned error: %v", err)
+	}
+	if len(tombstones) != 1 || tombstones[0].MessageID != 1 || tombstones[0].Reason != model.DeletionReasonDeleted {
+		t.Errorf("tombstones = %+v, want one deleted tombstone for message 1", tombstones)
+	}
+}
+
+// TestGetMessageHistorySkipsExpired 已到期但尚未清理的消息不出现在历史中，按时间倒序分页
+func TestGetMessageHistorySkipsExpired(t *testing.T) {
+	s, store := newMemoryService(t)
+	now := time.Now()
+	expired := now.Add(-time.Second)
+	saveTestMessage(t, store, &model.Message{MessageID: 1, From: 1, To: 2, Timestamp: 1, CreatedAt: now})
+	saveTestMessage(t, store, &model.Message{MessageID: 2, From: 2, To: 1, Timestamp: 2, CreatedAt: now})
+	saveTestMessage(t, store, &model.Message{MessageID: 3, From: 1, To: 2, Timestamp: 3, CreatedAt: now, ExpireAt: &expired})
+	saveTestMessage(t, store, &model.Message{MessageID: 4, From: 3, To: 4, Timestamp: 4, CreatedAt: now})
+
+	messages, total, err := s.GetMessageHistory(context.Background(), 1, 0, 1, 1)
+	if err != nil {
+		t.Fatalf("GetMessageHistory returned error: %v", err)
+	}
+	if total != 2 || len(messages) != 1 || messages[0].MessageID != 2 {
+		t.Errorf("GetMessageHistory(page 1, size 1) = %d messages (total %d), want message 2 of 2", len(messages), total)
+	}
+}
@@ -2,17 +2,21 @@ package main
 
 import (
 	"context"
+	"fmt"
 	"log"
 	"os"
-	"time"
 
 	"github.com/gin-gonic/gin"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
 
 	"goim-social/api/rest"
+	"goim-social/apps/search-service/internal/consumer"
 	"goim-social/apps/search-service/internal/handler"
 	"goim-social/apps/search-service/internal/service"
 	"goim-social/pkg/middleware"
+	"goim-social/pkg/outbox"
+	"goim-social/pkg/pagination"
 	"goim-social/pkg/server"
 	"goim-social/pkg/telemetry"
 )
@@ -36,7 +40,7 @@ func main() {
 
 	// 确保在程序退出时关闭OpenTelemetry
 	defer func() {
-		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		ctx, cancel := context.WithTimeout(context.Background(), otelConfig.ShutdownTimeout)
 		defer cancel()
 		if err := telemetry.ShutdownGlobal(ctx); err != nil {
 			log.Printf("Failed to shutdown OpenTelemetry: %v", err)
@@ -55,9 +59,79 @@ func main() {
 	// 创建OpenTelemetry中间件
 	otelMW := middleware.NewOTelMiddleware(serviceName, app.GetLogger())
 
+	cfg := app.GetConfig()
+
+	// 初始化社交服务客户端（按群成员身份时段限制群消息搜索范围）
+	socialAddr := fmt.Sprintf("%s:%d", cfg.Services.SocialService.Host, cfg.Services.SocialService.Port)
+	socialConn, err := grpc.NewClient(socialAddr, grpc.WithTransportCredentials(insecure.NewCredentials()), middleware.DeadlineDialOption(cfg.Deadline))
+	if err != nil {
+		log.Fatalf("Failed to connect to social service: %v", err)
+	}
+	defer socialConn.Close()
+
 	// 初始化Service层
-	searchService := service.NewService(app.GetElasticSearch(), app.GetPostgreSQL(), app.GetLogger())
-	indexService := service.NewIndexService(app.GetElasticSearch(), app.GetPostgreSQL(), app.GetLogger())
+	searchService := service.NewService(app.GetElasticSearch(), app.GetPostgreSQL(), cfg.Search, pagination.New(cfg.Pagination), rest.NewSocialServiceClient(socialConn), app.GetLogger())
+	indexService := service.NewIndexService(app.GetElasticSearch(), app.GetPostgreSQL(), cfg.Search, app.GetLogger())
+
+	// 启动热度计数消费者，按内容服务的互动事件增量更新索引中的计数，重复投递的事件按事件ID去重
+	popularityConsumer := consumer.NewPopularityConsumer(indexService, outbox.NewDeduper(app.GetRedisClient(), "search-popularity", cfg.Outbox))
+	go func() {
+		log.Println("启动热度计数消费者...")
+		if err := popularityConsumer.Start(context.Background(), cfg.Kafka.Brokers); err != nil {
+			log.Printf("Failed to start popularity consumer: %v", err)
+		}
+	}()
+	app.RegisterShutdownHook("popularity-consumer", func(ctx context.Context) error {
+		return popularityConsumer.Stop()
+	})
+
+	// 启动群组索引消费者，按社交服务的群资料变更事件重建群组文档
+	groupIndexConsumer := consumer.NewGroupIndexConsumer(indexService)
+	go func() {
+		log.Println("启动群组索引消费者...")
+		if err := groupIndexConsumer.Start(context.Background(), cfg.Kafka.Brokers); err != nil {
+			log.Printf("Failed to start group index consumer: %v", err)
+		}
+	}()
+	app.RegisterShutdownHook("group-index-consumer", func(ctx context.Context) error {
+		return groupIndexConsumer.Stop()
+	})
+
+	// 启动消息索引消费者，按消息服务保存消息时发布的明文文档写入消息索引
+	messageIndexConsumer := consumer.NewMessageIndexConsumer(indexService)
+	go func() {
+		log.Println("启动消息索引消费者...")
+		if err := messageIndexConsumer.Start(context.Background(), cfg.Kafka.Brokers); err != nil {
+			log.Printf("Failed to start message index consumer: %v", err)
+		}
+	}()
+	app.RegisterShutdownHook("message-index-consumer", func(ctx context.Context) error {
+		return messageIndexConsumer.Stop()
+	})
+
+	// 启动内容删除消费者，内容被删除后从内容索引移除
+	contentDeletionConsumer := consumer.NewContentDeletionConsumer(indexService)
+	go func() {
+		log.Println("启动内容删除消费者...")
+		if err := contentDeletionConsumer.Start(context.Background(), cfg.Kafka.Brokers); err != nil {
+			log.Printf("Failed to start content deletion consumer: %v", err)
+		}
+	}()
+	app.RegisterShutdownHook("content-deletion-consumer", func(ctx context.Context) error {
+		return contentDeletionConsumer.Stop()
+	})
+
+	// 启动消息清理消费者，超过保留期的历史消息被删除后从消息索引移除
+	messagePurgeConsumer := consumer.NewMessagePurgeConsumer(indexService)
+	go func() {
+		log.Println("启动消息清理消费者...")
+		if err := messagePurgeConsumer.Start(context.Background(), cfg.Kafka.Brokers); err != nil {
+			log.Printf("Failed to start message purge consumer: %v", err)
+		}
+	}()
+	app.RegisterShutdownHook("message-purge-consumer", func(ctx context.Context) error {
+		return messagePurgeConsumer.Stop()
+	})
 
 	// 初始化Handler
 	httpHandler := handler.NewHTTPHandler(searchService, indexService, app.GetLogger())
@@ -0,0 +1,83 @@
+package consumer
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"strconv"
+
+	"github.com/IBM/sarama"
+
+	"goim-social/apps/search-service/internal/model"
+	"goim-social/pkg/kafka"
+)
+
+// ContentDocumentDeleter 从搜索索引删除内容文档
+type ContentDocumentDeleter interface {
+	DeleteDocument(ctx context.Context, indexType string, docID string) error
+}
+
+// ContentDeletionConsumer 内容删除消费者
+// 职责：消费内容服务发布的内容删除事件，从内容索引删除对应文档。
+// 文档不存在时删除视为成功，事件重放是安全的
+type ContentDeletionConsumer struct {
+	consumer *kafka.Consumer
+	deleter  ContentDocumentDeleter
+}
+
+// NewContentDeletionConsumer 创建内容删除消费者
+func NewContentDeletionConsumer(deleter ContentDocumentDeleter) *ContentDeletionConsumer {
+	return &ContentDeletionConsumer{deleter: deleter}
+}
+
+// Start 启动内容删除消费者
+func (c *ContentDeletionConsumer) Start(ctx context.Context, brokers []string) error {
+	cfg := kafka.KafkaConfig{
+		Brokers: brokers,
+		GroupID: "search-content-deletion-consumer-group",
+		Topics:  []string{model.TopicContentDeleted},
+	}
+
+	consumer, err := kafka.InitConsumer(cfg, c)
+	if err != nil {
+		return err
+	}
+
+	c.consumer = consumer
+	log.Printf("内容删除消费者启动成功，监听topic: %s", model.TopicContentDeleted)
+
+	return c.consumer.StartConsuming(ctx)
+}
+
+// Stop 停止消费
+func (c *ContentDeletionConsumer) Stop() error {
+	if c.consumer != nil {
+		return c.consumer.Close()
+	}
+	return nil
+}
+
+// HandleMessage 实现 kafka.ConsumerHandler 接口
+func (c *ContentDeletionConsumer) HandleMessage(msg *sarama.ConsumerMessage) error {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("内容删除消费者处理消息时发生panic: %v", r)
+		}
+	}()
+
+	var event model.ContentDeletedEvent
+	if err := json.Unmarshal(msg.Value, &event); err != nil {
+		log.Printf("解析内容删除事件失败: %v", err)
+		return nil // 返回nil避免重试
+	}
+	if event.ContentID == 0 {
+		return nil
+	}
+
+	docID := strconv.FormatInt(event.ContentID, 10)
+	if err := c.deleter.DeleteDocument(context.Background(), model.SearchTypeContent, docID); err != nil {
+		log.Printf("删除内容索引失败: ContentID=%d, Error=%v", event.ContentID, err)
+		return err
+	}
+	return nil
+}
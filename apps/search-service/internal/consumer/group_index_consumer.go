@@ -0,0 +1,83 @@
+package consumer
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"strconv"
+
+	"github.com/IBM/sarama"
+
+	"goim-social/apps/search-service/internal/model"
+	"goim-social/pkg/kafka"
+)
+
+// GroupIndexer 将群组文档写入搜索索引
+type GroupIndexer interface {
+	IndexDocument(ctx context.Context, indexType string, docID string, document interface{}) error
+}
+
+// GroupIndexConsumer 群组索引消费者
+// 职责：消费社交服务发布的群组索引事件，用事件携带的完整文档覆盖写入群组索引。
+// 事件按群组ID分区，同一群组的事件按版本顺序到达
+type GroupIndexConsumer struct {
+	consumer *kafka.Consumer
+	indexer  GroupIndexer
+}
+
+// NewGroupIndexConsumer 创建群组索引消费者
+func NewGroupIndexConsumer(indexer GroupIndexer) *GroupIndexConsumer {
+	return &GroupIndexConsumer{indexer: indexer}
+}
+
+// Start 启动群组索引消费者
+func (g *GroupIndexConsumer) Start(ctx context.Context, brokers []string) error {
+	cfg := kafka.KafkaConfig{
+		Brokers: brokers,
+		GroupID: "search-group-index-consumer-group",
+		Topics:  []string{model.TopicGroupIndex},
+	}
+
+	consumer, err := kafka.InitConsumer(cfg, g)
+	if err != nil {
+		return err
+	}
+
+	g.consumer = consumer
+	log.Printf("群组索引消费者启动成功，监听topic: %s", model.TopicGroupIndex)
+
+	return g.consumer.StartConsuming(ctx)
+}
+
+// Stop 停止消费
+func (g *GroupIndexConsumer) Stop() error {
+	if g.consumer != nil {
+		return g.consumer.Close()
+	}
+	return nil
+}
+
+// HandleMessage 实现 kafka.ConsumerHandler 接口
+func (g *GroupIndexConsumer) HandleMessage(msg *sarama.ConsumerMessage) error {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("群组索引消费者处理消息时发生panic: %v", r)
+		}
+	}()
+
+	var event model.GroupIndexEvent
+	if err := json.Unmarshal(msg.Value, &event); err != nil {
+		log.Printf("解析群组索引事件失败: %v", err)
+		return nil // 返回nil避免重试
+	}
+	if event.EventType != model.GroupIndexEventUpdate || event.ID == 0 {
+		return nil
+	}
+
+	docID := strconv.FormatInt(event.ID, 10)
+	if err := g.indexer.IndexDocument(context.Background(), model.SearchTypeGroup, docID, &event.GroupDocument); err != nil {
+		// 下一次资料变更会携带完整文档覆盖写入，不阻塞后续事件
+		log.Printf("写入群组索引失败: GroupID=%d, Version=%d, Error=%v", event.ID, event.Version, err)
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package consumer
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"strconv"
+
+	"github.com/IBM/sarama"
+
+	"goim-social/apps/search-service/internal/model"
+	"goim-social/pkg/kafka"
+)
+
+// MessageIndexer 将消息文档写入搜索索引
+type MessageIndexer interface {
+	IndexDocument(ctx context.Context, indexType string, docID string, document interface{}) error
+}
+
+// MessageIndexConsumer 消息索引消费者
+// 职责：消费消息服务保存消息后发布的索引事件，事件即明文的消息文档，按消息ID覆盖写入消息索引。
+// 启用静态加密后库中正文为密文，消息索引只能由这些事件写入，不从数据库同步
+type MessageIndexConsumer struct {
+	consumer *kafka.Consumer
+	indexer  MessageIndexer
+}
+
+// NewMessageIndexConsumer 创建消息索引消费者
+func NewMessageIndexConsumer(indexer MessageIndexer) *MessageIndexConsumer {
+	return &MessageIndexConsumer{indexer: indexer}
+}
+
+// Start 启动消息索引消费者
+func (m *MessageIndexConsumer) Start(ctx context.Context, brokers []string) error {
+	cfg := kafka.KafkaConfig{
+		Brokers: brokers,
+		GroupID: "search-message-index-consumer-group",
+		Topics:  []string{model.TopicMessageIndex},
+	}
+
+	consumer, err := kafka.InitConsumer(cfg, m)
+	if err != nil {
+		return err
+	}
+
+	m.consumer = consumer
+	log.Printf("消息索引消费者启动成功，监听topic: %s", model.TopicMessageIndex)
+
+	return m.consumer.StartConsuming(ctx)
+}
+
+// Stop 停止消费
+func (m *MessageIndexConsumer) Stop() error {
+	if m.consumer != nil {
+		return m.consumer.Close()
+	}
+	return nil
+}
+
+// HandleMessage 实现 kafka.ConsumerHandler 接口
+func (m *MessageIndexConsumer) HandleMessage(msg *sarama.ConsumerMessage) error {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("消息索引消费者处理消息时发生panic: %v", r)
+		}
+	}()
+
+	var document model.MessageSearchDocument
+	if err := json.Unmarshal(msg.Value, &document); err != nil {
+		log.Printf("解析消息索引事件失败: %v", err)
+		return nil // 返回nil避免重试
+	}
+	if document.ID == 0 {
+		return nil
+	}
+
+	docID := strconv.FormatInt(document.ID, 10)
+	if err := m.indexer.IndexDocument(context.Background(), model.SearchTypeMessage, docID, &document); err != nil {
+		// 消息事件只发布一次，失败时记录消息ID，可通过文档索引接口补写，不阻塞后续事件
+		log.Printf("写入消息索引失败: MessageID=%d, Error=%v", document.ID, err)
+	}
+	return nil
+}
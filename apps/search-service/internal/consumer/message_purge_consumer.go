@@ -0,0 +1,80 @@
+package consumer
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+
+	"github.com/IBM/sarama"
+
+	"goim-social/apps/search-service/internal/model"
+	"goim-social/pkg/kafka"
+)
+
+// MessageDocumentDeleter 消息索引文档删除接口
+type MessageDocumentDeleter interface {
+	DeleteMessageDocuments(ctx context.Context, messageIDs []int64) error
+}
+
+// MessagePurgeConsumer 消息清理消费者
+// 职责：消费消息服务发布的消息清理事件（超过保留期、到期和发送者删除），从消息索引删除对应文档。
+// 文档不存在时删除视为成功，事件重复或重放是安全的
+type MessagePurgeConsumer struct {
+	consumer *kafka.Consumer
+	deleter  MessageDocumentDeleter
+}
+
+// NewMessagePurgeConsumer 创建消息清理消费者
+func NewMessagePurgeConsumer(deleter MessageDocumentDeleter) *MessagePurgeConsumer {
+	return &MessagePurgeConsumer{deleter: deleter}
+}
+
+// Start 启动消息清理消费者
+func (c *MessagePurgeConsumer) Start(ctx context.Context, brokers []string) error {
+	cfg := kafka.KafkaConfig{
+		Brokers: brokers,
+		GroupID: "search-message-purge-consumer-group",
+		Topics:  []string{model.TopicMessagePurged},
+	}
+
+	consumer, err := kafka.InitConsumer(cfg, c)
+	if err != nil {
+		return err
+	}
+
+	c.consumer = consumer
+	log.Printf("消息清理消费者启动成功，监听topic: %s", model.TopicMessagePurged)
+
+	return c.consumer.StartConsuming(ctx)
+}
+
+// Stop 停止消费
+func (c *MessagePurgeConsumer) Stop() error {
+	if c.consumer != nil {
+		return c.consumer.Close()
+	}
+	return nil
+}
+
+// HandleMessage 实现 kafka.ConsumerHandler 接口
+func (c *MessagePurgeConsumer) HandleMessage(msg *sarama.ConsumerMessage) error {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("消息清理消费者处理消息时发生panic: %v", r)
+		}
+	}()
+
+	var event model.MessagePurgedEvent
+	if err := json.Unmarshal(msg.Value, &event); err != nil {
+		log.Printf("解析消息清理事件失败: %v", err)
+		return nil // 返回nil避免重试
+	}
+
+	if len(event.MessageIDs) == 0 {
+		return nil
+	}
+	if err := c.deleter.DeleteMessageDocuments(context.Background(), event.MessageIDs); err != nil {
+		log.Printf("删除消息索引失败: ConversationID=%s, Count=%d, Error=%v", event.ConversationID, len(event.MessageIDs), err)
+	}
+	return nil
+}
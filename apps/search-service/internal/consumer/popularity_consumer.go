@@ -0,0 +1,236 @@
+package consumer
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/IBM/sarama"
+
+	"goim-social/apps/search-service/internal/model"
+	"goim-social/pkg/kafka"
+	"goim-social/pkg/outbox"
+)
+
+// PopularityUpdater 将内容的计数增量写入搜索索引
+type PopularityUpdater interface {
+	ApplyPopularityDeltas(ctx context.Context, deltas map[int64]*model.PopularityDelta) []int64
+}
+
+// PopularityConsumer 热度计数消费者
+// 职责：消费内容服务的互动、评论和浏览事件，按内容合并计数增量后定期写入索引，避免全量重建索引
+// 互动和评论事件经发件箱至少一次发布，按事件ID去重，避免重复投递的事件重复累加计数；
+// 事件ID在增量写入索引成功后才记录为已处理，写入前重启或写入失败时重新投递的事件仍会被计入
+type PopularityConsumer struct {
+	consumer *kafka.Consumer
+	updater  PopularityUpdater
+	dedup    *outbox.Deduper
+
+	mu       sync.Mutex
+	pending  map[int64]*model.PopularityDelta
+	eventIDs map[int64][]string  // 按内容记录待写入增量包含的事件ID，写入成功后记录为已处理
+	inflight map[string]struct{} // 已合并、尚未记录为已处理的事件ID，去重同一间隔内的重复投递
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// NewPopularityConsumer 创建热度计数消费者，dedup为nil时不去重
+func NewPopularityConsumer(updater PopularityUpdater, dedup *outbox.Deduper) *PopularityConsumer {
+	return &PopularityConsumer{
+		updater:  updater,
+		dedup:    dedup,
+		pending:  make(map[int64]*model.PopularityDelta),
+		eventIDs: make(map[int64][]string),
+		inflight: make(map[string]struct{}),
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+}
+
+// Start 启动热度计数消费者和增量写入任务
+func (p *PopularityConsumer) Start(ctx context.Context, brokers []string) error {
+	topics := []string{model.TopicInteractionEvents, model.TopicCommentEvents, model.TopicContentViewEvents}
+	cfg := kafka.KafkaConfig{
+		Brokers: brokers,
+		GroupID: "search-popularity-consumer-group",
+		Topics:  topics,
+	}
+
+	consumer, err := kafka.InitConsumer(cfg, p)
+	if err != nil {
+		return err
+	}
+
+	p.consumer = consumer
+	go p.runFlusher(ctx)
+	log.Printf("热度计数消费者启动成功，监听topic: %v", topics)
+
+	return p.consumer.StartConsuming(ctx)
+}
+
+// Stop 停止消费并写入剩余的增量
+func (p *PopularityConsumer) Stop() error {
+	var err error
+	if p.consumer != nil {
+		err = p.consumer.Close()
+		close(p.stop)
+		<-p.done
+	}
+	return err
+}
+
+// HandleMessage 实现 kafka.ConsumerHandler 接口
+func (p *PopularityConsumer) HandleMessage(msg *sarama.ConsumerMessage) error {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("热度计数消费者处理消息时发生panic: %v", r)
+		}
+	}()
+
+	var event model.ContentEngagementEvent
+	if err := json.Unmarshal(msg.Value, &event); err != nil {
+		log.Printf("解析互动事件失败: %v, topic: %s", err, msg.Topic)
+		return nil // 返回nil避免重试
+	}
+
+	delta := eventDelta(msg.Topic, &event)
+	if delta == nil {
+		return nil
+	}
+	eventID := outbox.EventIDOf(msg.Value)
+	if eventID != "" && (p.isInflight(eventID) || p.dedup.Seen(context.Background(), msg.Value)) {
+		return nil
+	}
+	p.add(event.TargetID, delta, eventID)
+	return nil
+}
+
+// isInflight 事件是否已合并到待写入的增量中
+func (p *PopularityConsumer) isInflight(eventID string) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	_, ok := p.inflight[eventID]
+	return ok
+}
+
+// eventDelta 计算事件对内容计数的影响，与内容计数无关的事件返回nil
+func eventDelta(topic string, event *model.ContentEngagementEvent) *model.PopularityDelta {
+	if event.TargetType != model.EngagementTargetContent || event.TargetID == 0 {
+		return nil
+	}
+
+	var sign int64
+	switch event.EventType {
+	case model.EngagementEventCreate:
+		sign = 1
+	case model.EngagementEventDelete:
+		sign = -1
+	}
+
+	switch topic {
+	case model.TopicContentViewEvents:
+		if event.Count <= 0 {
+			return nil
+		}
+		return &model.PopularityDelta{ViewCount: event.Count}
+	case model.TopicCommentEvents:
+		if sign == 0 {
+			return nil
+		}
+		return &model.PopularityDelta{CommentCount: sign}
+	case model.TopicInteractionEvents:
+		if sign == 0 {
+			return nil
+		}
+		switch event.InteractionType {
+		case model.EngagementTypeLike:
+			return &model.PopularityDelta{LikeCount: sign}
+		case model.EngagementTypeShare:
+			return &model.PopularityDelta{ShareCount: sign}
+		}
+	}
+	return nil
+}
+
+// add 合并一篇内容的计数增量，eventIDs为增量包含的事件ID，不是发件箱发布的事件时为空
+func (p *PopularityConsumer) add(contentID int64, delta *model.PopularityDelta, eventIDs ...string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, eventID := range eventIDs {
+		if eventID == "" {
+			continue
+		}
+		p.inflight[eventID] = struct{}{}
+		p.eventIDs[contentID] = append(p.eventIDs[contentID], eventID)
+	}
+
+	pending, ok := p.pending[contentID]
+	if !ok {
+		pending = &model.PopularityDelta{}
+		p.pending[contentID] = pending
+	}
+	pending.ViewCount += delta.ViewCount
+	pending.LikeCount += delta.LikeCount
+	pending.CommentCount += delta.CommentCount
+	pending.ShareCount += delta.ShareCount
+}
+
+// runFlusher 定期写入合并后的增量，停止时再写入一次
+func (p *PopularityConsumer) runFlusher(ctx context.Context) {
+	defer close(p.done)
+
+	ticker := time.NewTicker(time.Duration(model.PopularityFlushInterval) * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			p.flush(context.Background())
+			return
+		case <-p.stop:
+			p.flush(context.Background())
+			return
+		case <-ticker.C:
+			p.flush(ctx)
+		}
+	}
+}
+
+// flush 写入当前合并的增量，写入成功的事件记录为已处理；写入失败的增量连同事件ID放回待写入集合，下一轮重试
+func (p *PopularityConsumer) flush(ctx context.Context) {
+	p.mu.Lock()
+	batch := p.pending
+	batchEvents := p.eventIDs
+	p.pending = make(map[int64]*model.PopularityDelta)
+	p.eventIDs = make(map[int64][]string)
+	p.mu.Unlock()
+
+	if len(batch) == 0 {
+		return
+	}
+
+	failed := make(map[int64]bool)
+	for _, contentID := range p.updater.ApplyPopularityDeltas(ctx, batch) {
+		failed[contentID] = true
+		p.add(contentID, batch[contentID], batchEvents[contentID]...)
+	}
+
+	var applied []string
+	for contentID, eventIDs := range batchEvents {
+		if !failed[contentID] {
+			applied = append(applied, eventIDs...)
+		}
+	}
+	p.dedup.Mark(ctx, applied...)
+
+	p.mu.Lock()
+	for _, eventID := range applied {
+		delete(p.inflight, eventID)
+	}
+	p.mu.Unlock()
+}
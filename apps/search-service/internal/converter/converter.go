@@ -89,6 +89,7 @@ func (c *Converter) SearchResultToProto(result *model.SearchResult) *rest.Search
 		Score:      float32(result.Score),
 		Highlights: highlights,
 		ExtraData:  extraData,
+		Snippet:    result.Snippet,
 	}
 }
 
@@ -6,26 +6,36 @@ import (
 	"crypto/md5"
 	"encoding/json"
 	"fmt"
+	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/elastic/go-elasticsearch/v8"
 	"github.com/elastic/go-elasticsearch/v8/esapi"
 
 	"goim-social/apps/search-service/internal/model"
+	"goim-social/pkg/config"
+	"goim-social/pkg/httpx"
 	"goim-social/pkg/logger"
 )
 
 // elasticsearchDAO ElasticSearch数据访问对象
 type elasticsearchDAO struct {
-	client *elasticsearch.Client
-	logger logger.Logger
+	client     *elasticsearch.Client
+	highlight  config.HighlightConfig
+	popularity config.PopularityConfig
+	blend      config.BlendConfig
+	logger     logger.Logger
 }
 
 // NewElasticsearchDAO 创建ElasticSearch DAO实例
-func NewElasticsearchDAO(client *elasticsearch.Client, log logger.Logger) SearchDAO {
+func NewElasticsearchDAO(client *elasticsearch.Client, searchConfig config.SearchConfig, log logger.Logger) SearchDAO {
 	return &elasticsearchDAO{
-		client: client,
-		logger: log,
+		client:     client,
+		highlight:  searchConfig.Highlight,
+		popularity: searchConfig.Popularity,
+		blend:      searchConfig.Blend,
+		logger:     log,
 	}
 }
 
@@ -289,6 +299,57 @@ func (d *elasticsearchDAO) UpdateDocument(ctx context.Context, indexName, docID
 	return nil
 }
 
+// incrementCountersScript 累加计数字段，结果不小于0，避免删除事件先于创建事件到达时出现负数
+const incrementCountersScript = `for (entry in params.deltas.entrySet()) {
+	def current = ctx._source[entry.getKey()];
+	long value = current == null ? 0 : ((Number) current).longValue();
+	ctx._source[entry.getKey()] = Math.max(0, value + entry.getValue());
+}`
+
+// IncrementCounters 按增量更新文档中的计数字段，文档不存在时忽略
+func (d *elasticsearchDAO) IncrementCounters(ctx context.Context, indexName, docID string, deltas map[string]int64) error {
+	if len(deltas) == 0 {
+		return nil
+	}
+
+	updateDoc := map[string]interface{}{
+		"script": map[string]interface{}{
+			"source": incrementCountersScript,
+			"lang":   "painless",
+			"params": map[string]interface{}{"deltas": deltas},
+		},
+	}
+
+	docJSON, err := json.Marshal(updateDoc)
+	if err != nil {
+		return fmt.Errorf("failed to marshal counter update: %v", err)
+	}
+
+	retryOnConflict := 3
+	req := esapi.UpdateRequest{
+		Index:           indexName,
+		DocumentID:      docID,
+		Body:            bytes.NewReader(docJSON),
+		RetryOnConflict: &retryOnConflict,
+	}
+
+	res, err := req.Do(ctx, d.client)
+	if err != nil {
+		return fmt.Errorf("failed to increment counters: %v", err)
+	}
+	defer res.Body.Close()
+
+	// 内容尚未建立索引时没有可更新的计数
+	if res.StatusCode == 404 {
+		return nil
+	}
+	if res.IsError() {
+		return fmt.Errorf("failed to increment counters: %s", res.String())
+	}
+
+	return nil
+}
+
 // DeleteDocument 删除文档
 func (d *elasticsearchDAO) DeleteDocument(ctx context.Context, indexName, docID string) error {
 	req := esapi.DeleteRequest{
@@ -344,6 +405,15 @@ func (d *elasticsearchDAO) GetDocument(ctx context.Context, indexName, docID str
 
 // ============ 搜索操作 ============
 
+// searchResponseError 将ElasticSearch的错误响应分类：400为查询语法错误，其余视为搜索引擎故障
+func searchResponseError(op string, res *esapi.Response) error {
+	err := fmt.Errorf("%s failed: %s", op, res.String())
+	if res.StatusCode == http.StatusBadRequest {
+		return httpx.InvalidArgument(err)
+	}
+	return httpx.Unavailable(err)
+}
+
 // Search 通用搜索
 func (d *elasticsearchDAO) Search(ctx context.Context, req *SearchRequest) (*SearchResponse, error) {
 	queryJSON, err := json.Marshal(req)
@@ -361,17 +431,17 @@ func (d *elasticsearchDAO) Search(ctx context.Context, req *SearchRequest) (*Sea
 		d.logger.Error(ctx, "Failed to execute search",
 			logger.F("index", req.Index),
 			logger.F("error", err.Error()))
-		return nil, fmt.Errorf("failed to execute search: %v", err)
+		return nil, httpx.Unavailable(fmt.Errorf("failed to execute search: %v", err))
 	}
 	defer res.Body.Close()
 
 	if res.IsError() {
-		return nil, fmt.Errorf("search failed: %s", res.String())
+		return nil, searchResponseError("search", res)
 	}
 
 	var response SearchResponse
 	if err := json.NewDecoder(res.Body).Decode(&response); err != nil {
-		return nil, fmt.Errorf("failed to decode search response: %v", err)
+		return nil, httpx.Unavailable(fmt.Errorf("failed to decode search response: %v", err))
 	}
 
 	return &response, nil
@@ -379,8 +449,11 @@ func (d *elasticsearchDAO) Search(ctx context.Context, req *SearchRequest) (*Sea
 
 // SearchContent 内容搜索
 func (d *elasticsearchDAO) SearchContent(ctx context.Context, req *model.SearchRequest) ([]*model.ContentSearchResult, int64, error) {
-	// 构建搜索查询
+	// 构建搜索查询，按相关性排序时叠加热度分
 	query := d.buildContentSearchQuery(req)
+	if req.SortBy == "" || req.SortBy == model.SortByRelevance {
+		query = d.withPopularityBoost(query)
+	}
 
 	searchReq := &SearchRequest{
 		Index: model.IndexContent,
@@ -421,6 +494,58 @@ func (d *elasticsearchDAO) SearchContent(ctx context.Context, req *model.SearchR
 	return results, response.Hits.Total.Value, nil
 }
 
+// GetContentCategoryFacets 获取内容搜索结果的分类分面计数，按分类ID聚合，键为分类ID
+// 分类名称可以修改，按ID聚合保证同一分类改名前后的内容计入同一分面；分面计数忽略请求自身的分类过滤，便于在已选分类下展示其他分类的命中数
+func (d *elasticsearchDAO) GetContentCategoryFacets(ctx context.Context, req *model.SearchRequest) (map[string]int64, error) {
+	facetReq := *req
+	facetReq.Filters = make(map[string]string, len(req.Filters))
+	for key, value := range req.Filters {
+		if key == "category" || key == "category_id" {
+			continue
+		}
+		facetReq.Filters[key] = value
+	}
+
+	searchReq := &SearchRequest{
+		Index: model.IndexContent,
+		Query: d.buildContentSearchQuery(&facetReq),
+		Size:  0,
+		Aggregations: map[string]interface{}{
+			model.FacetCategory: map[string]interface{}{
+				"terms": map[string]interface{}{
+					"field": "category_id",
+					"size":  model.CategoryFacetSize,
+				},
+			},
+		},
+	}
+
+	response, err := d.Search(ctx, searchReq)
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]int64)
+	agg, ok := response.Aggregations[model.FacetCategory].(map[string]interface{})
+	if !ok {
+		return counts, nil
+	}
+	buckets, _ := agg["buckets"].([]interface{})
+	for _, bucket := range buckets {
+		bucketMap, ok := bucket.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		// 数值字段的桶键解码为float64
+		categoryID, _ := bucketMap["key"].(float64)
+		docCount, _ := bucketMap["doc_count"].(float64)
+		if categoryID > 0 {
+			counts[strconv.FormatInt(int64(categoryID), 10)] = int64(docCount)
+		}
+	}
+	return counts, nil
+}
+
 // SearchUsers 用户搜索
 func (d *elasticsearchDAO) SearchUsers(ctx context.Context, req *model.SearchRequest) ([]*model.UserSearchResult, int64, error) {
 	// 构建搜索查询
@@ -559,7 +684,11 @@ func (d *elasticsearchDAO) MultiSearch(ctx context.Context, req *model.SearchReq
 
 	// 构建多搜索请求
 	var buf bytes.Buffer
-	indices := []string{model.IndexContent, model.IndexUser, model.IndexMessage, model.IndexGroup}
+	searchTypes := []string{model.SearchTypeContent, model.SearchTypeUser, model.SearchTypeMessage, model.SearchTypeGroup}
+	indices := make([]string, len(searchTypes))
+	for i, searchType := range searchTypes {
+		indices[i] = model.GetIndexBySearchType(searchType)
+	}
 
 	for _, index := range indices {
 		// 搜索头部
@@ -594,18 +723,18 @@ func (d *elasticsearchDAO) MultiSearch(ctx context.Context, req *model.SearchReq
 
 	res, err := msearchReq.Do(ctx, d.client)
 	if err != nil {
-		return nil, fmt.Errorf("failed to execute multi-search: %v", err)
+		return nil, httpx.Unavailable(fmt.Errorf("failed to execute multi-search: %v", err))
 	}
 	defer res.Body.Close()
 
 	if res.IsError() {
-		return nil, fmt.Errorf("multi-search failed: %s", res.String())
+		return nil, searchResponseError("multi-search", res)
 	}
 
 	// 解析响应
 	var msearchResponse map[string]interface{}
 	if err := json.NewDecoder(res.Body).Decode(&msearchResponse); err != nil {
-		return nil, fmt.Errorf("failed to decode multi-search response: %v", err)
+		return nil, httpx.Unavailable(fmt.Errorf("failed to decode multi-search response: %v", err))
 	}
 
 	// 构建响应
@@ -618,35 +747,67 @@ func (d *elasticsearchDAO) MultiSearch(ctx context.Context, req *model.SearchReq
 		Duration: time.Since(startTime).Milliseconds(),
 	}
 
-	// 处理每个索引的结果
+	// 处理每个索引的结果，各索引的相关性分归一化并加权后才能合并排序
+	groups := make([]*model.TypeResults, len(indices))
+	blended := make([][]blendHit, len(indices))
 	if responses, ok := msearchResponse["responses"].([]interface{}); ok {
 		var totalHits int64
 		for i, resp := range responses {
-			if respMap, ok := resp.(map[string]interface{}); ok {
-				if hits, ok := respMap["hits"].(map[string]interface{}); ok {
-					if total, ok := hits["total"].(map[string]interface{}); ok {
-						if value, ok := total["value"].(float64); ok {
-							totalHits += int64(value)
-						}
-					}
+			if i >= len(indices) {
+				break
+			}
+			respMap, ok := resp.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			// 单个索引查询失败时整体失败，避免把错误当作无结果返回
+			if searchErr, failed := respMap["error"]; failed {
+				return nil, httpx.Unavailable(fmt.Errorf("multi-search on %s failed: %v", indices[i], searchErr))
+			}
+			hits, ok := respMap["hits"].(map[string]interface{})
+			if !ok {
+				continue
+			}
 
-					if hitsList, ok := hits["hits"].([]interface{}); ok {
-						indexName := indices[i]
-						for _, hit := range hitsList {
-							if hitMap, ok := hit.(map[string]interface{}); ok {
-								result := d.convertMultiSearchResult(hitMap, indexName)
-								if result != nil {
-									response.Results = append(response.Results, result)
-								}
-							}
-						}
+			group := &model.TypeResults{Type: searchTypes[i], Results: make([]interface{}, 0)}
+			if total, ok := hits["total"].(map[string]interface{}); ok {
+				if value, ok := total["value"].(float64); ok {
+					group.Total = int64(value)
+					totalHits += group.Total
+				}
+			}
+
+			var hitMaps []map[string]interface{}
+			if hitsList, ok := hits["hits"].([]interface{}); ok {
+				for _, hit := range hitsList {
+					if hitMap, ok := hit.(map[string]interface{}); ok {
+						hitMaps = append(hitMaps, hitMap)
 					}
 				}
 			}
+
+			weight := d.typeWeight(searchTypes[i])
+			for j, score := range normalizeHitScores(hitMaps) {
+				hitMaps[j]["_score"] = score * weight
+				if result := d.convertMultiSearchResult(hitMaps[j], indices[i]); result != nil {
+					group.Results = append(group.Results, result)
+					blended[i] = append(blended[i], blendHit{score: score * weight, result: result})
+				}
+			}
+			groups[i] = group
 		}
 		response.Total = totalHits
 	}
 
+	if req.GroupByType {
+		for _, group := range groups {
+			if group != nil {
+				response.Groups = append(response.Groups, group)
+			}
+		}
+		return response, nil
+	}
+	response.Results = blendResults(blended)
 	return response, nil
 }
 
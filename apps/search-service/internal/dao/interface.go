@@ -41,6 +41,9 @@ type SearchDAO interface {
 	
 	// GetDocument 获取文档
 	GetDocument(ctx context.Context, indexName, docID string) (map[string]interface{}, error)
+	
+	// IncrementCounters 按增量更新文档中的计数字段，文档不存在时忽略
+	IncrementCounters(ctx context.Context, indexName, docID string, deltas map[string]int64) error
 
 	// ============ 搜索操作 ============
 	
@@ -75,6 +78,9 @@ type SearchDAO interface {
 	// GetAggregations 获取聚合数据
 	GetAggregations(ctx context.Context, indexName string, aggs map[string]interface{}) (map[string]interface{}, error)
 	
+	// GetContentCategoryFacets 获取内容搜索结果的分类分面计数
+	GetContentCategoryFacets(ctx context.Context, req *model.SearchRequest) (map[string]int64, error)
+	
 	// GetSearchStats 获取搜索统计
 	GetSearchStats(ctx context.Context, timeRange string) (*model.SearchStats, error)
 
@@ -134,27 +134,25 @@ func (d *elasticsearchDAO) buildMessageSearchQuery(req *model.SearchRequest) map
 	// 添加过滤器
 	d.addFilters(boolQuery, req.Filters)
 
-	// 如果有用户ID，添加权限过滤（只能搜索自己的消息或群消息）
+	// 如果有用户ID，添加权限过滤（只能搜索自己的私聊消息，以及在群期间的群消息）
 	if req.UserID > 0 {
+		should := []interface{}{
+			map[string]interface{}{
+				"term": map[string]interface{}{
+					"from_user_id": req.UserID,
+				},
+			},
+			map[string]interface{}{
+				"term": map[string]interface{}{
+					"to_user_id": req.UserID,
+				},
+			},
+		}
+		should = append(should, d.buildGroupWindowClauses(req.GroupWindows)...)
+
 		userFilter := map[string]interface{}{
 			"bool": map[string]interface{}{
-				"should": []interface{}{
-					map[string]interface{}{
-						"term": map[string]interface{}{
-							"from_user_id": req.UserID,
-						},
-					},
-					map[string]interface{}{
-						"term": map[string]interface{}{
-							"to_user_id": req.UserID,
-						},
-					},
-					map[string]interface{}{
-						"exists": map[string]interface{}{
-							"field": "group_id",
-						},
-					},
-				},
+				"should":               should,
 				"minimum_should_match": 1,
 			},
 		}
@@ -164,6 +162,42 @@ func (d *elasticsearchDAO) buildMessageSearchQuery(req *model.SearchRequest) map
 	return query
 }
 
+// buildGroupWindowClauses 按群消息时间窗口构建过滤子句，每个窗口匹配指定群组在时间范围内的消息
+func (d *elasticsearchDAO) buildGroupWindowClauses(windows []model.GroupTimeWindow) []interface{} {
+	clauses := make([]interface{}, 0, len(windows))
+	for _, window := range windows {
+		filter := []interface{}{
+			map[string]interface{}{
+				"term": map[string]interface{}{
+					"group_id": window.GroupID,
+				},
+			},
+		}
+
+		createdAt := map[string]interface{}{}
+		if !window.Start.IsZero() {
+			createdAt["gte"] = window.Start.Format(time.RFC3339Nano)
+		}
+		if !window.End.IsZero() {
+			createdAt["lte"] = window.End.Format(time.RFC3339Nano)
+		}
+		if len(createdAt) > 0 {
+			filter = append(filter, map[string]interface{}{
+				"range": map[string]interface{}{
+					"created_at": createdAt,
+				},
+			})
+		}
+
+		clauses = append(clauses, map[string]interface{}{
+			"bool": map[string]interface{}{
+				"filter": filter,
+			},
+		})
+	}
+	return clauses
+}
+
 // buildGroupSearchQuery 构建群组搜索查询
 func (d *elasticsearchDAO) buildGroupSearchQuery(req *model.SearchRequest) map[string]interface{} {
 	query := map[string]interface{}{
@@ -213,7 +247,7 @@ func (d *elasticsearchDAO) buildGroupSearchQuery(req *model.SearchRequest) map[s
 func (d *elasticsearchDAO) buildMultiSearchQuery(req *model.SearchRequest, index string) map[string]interface{} {
 	switch index {
 	case model.IndexContent:
-		return d.buildContentSearchQuery(req)
+		return d.withPopularityBoost(d.buildContentSearchQuery(req))
 	case model.IndexUser:
 		return d.buildUserSearchQuery(req)
 	case model.IndexMessage:
@@ -227,6 +261,45 @@ func (d *elasticsearchDAO) buildMultiSearchQuery(req *model.SearchRequest, index
 	}
 }
 
+// withPopularityBoost 在内容查询上叠加热度分：最终得分 = 相关性分 + weight * Σ 系数 * log1p(计数)
+// 文本相关性相同时热度高的内容排在前面，weight为0时原样返回
+func (d *elasticsearchDAO) withPopularityBoost(query map[string]interface{}) map[string]interface{} {
+	if d.popularity.Weight <= 0 {
+		return query
+	}
+
+	factors := []struct {
+		field  string
+		factor float64
+	}{
+		{"like_count", model.PopularityLikeFactor},
+		{"comment_count", model.PopularityCommentFactor},
+		{"share_count", model.PopularityShareFactor},
+		{"view_count", model.PopularityViewFactor},
+	}
+
+	functions := make([]interface{}, 0, len(factors))
+	for _, f := range factors {
+		functions = append(functions, map[string]interface{}{
+			"field_value_factor": map[string]interface{}{
+				"field":    f.field,
+				"modifier": "log1p",
+				"missing":  0,
+			},
+			"weight": d.popularity.Weight * f.factor,
+		})
+	}
+
+	return map[string]interface{}{
+		"function_score": map[string]interface{}{
+			"query":      query,
+			"functions":  functions,
+			"score_mode": "sum",
+			"boost_mode": "sum",
+		},
+	}
+}
+
 // buildSortQuery 构建排序查询
 func (d *elasticsearchDAO) buildSortQuery(sortBy, sortOrder string) []map[string]interface{} {
 	if sortOrder == "" {
@@ -291,24 +364,45 @@ func (d *elasticsearchDAO) buildSortQuery(sortBy, sortOrder string) []map[string
 }
 
 // buildHighlightQuery 构建高亮查询
+// 使用html编码器，片段中原文的HTML会被转义，只有配置的高亮标签保持原样
 func (d *elasticsearchDAO) buildHighlightQuery(fields []string) map[string]interface{} {
+	preTag, postTag := d.highlightTags()
 	highlight := map[string]interface{}{
-		"pre_tags":  []string{model.DefaultHighlightPreTag},
-		"post_tags": []string{model.DefaultHighlightPostTag},
+		"pre_tags":  []string{preTag},
+		"post_tags": []string{postTag},
+		"encoder":   "html",
 		"fields":    map[string]interface{}{},
 	}
 
+	fragmentSize := d.highlight.FragmentSize
+	if fragmentSize <= 0 {
+		fragmentSize = model.DefaultHighlightFragmentSize
+	}
+	numberOfFragments := d.highlight.NumberOfFragments
+	if numberOfFragments < 0 {
+		numberOfFragments = model.DefaultHighlightFragments
+	}
+
 	highlightFields := highlight["fields"].(map[string]interface{})
 	for _, field := range fields {
 		highlightFields[field] = map[string]interface{}{
-			"fragment_size":       150,
-			"number_of_fragments": 3,
+			"fragment_size":       fragmentSize,
+			"number_of_fragments": numberOfFragments,
 		}
 	}
 
 	return highlight
 }
 
+// highlightTags 获取高亮标签，未配置时使用默认标签
+func (d *elasticsearchDAO) highlightTags() (string, string) {
+	preTag, postTag := d.highlight.PreTag, d.highlight.PostTag
+	if preTag == "" || postTag == "" {
+		return model.DefaultHighlightPreTag, model.DefaultHighlightPostTag
+	}
+	return preTag, postTag
+}
+
 // addFilters 添加过滤器
 func (d *elasticsearchDAO) addFilters(boolQuery map[string]interface{}, filters map[string]string) {
 	if filters == nil {
@@ -321,7 +415,7 @@ func (d *elasticsearchDAO) addFilters(boolQuery map[string]interface{}, filters
 		}
 
 		switch key {
-		case "author_id", "user_id", "owner_id", "from_user_id", "to_user_id", "group_id":
+		case "author_id", "user_id", "owner_id", "from_user_id", "to_user_id", "group_id", "category_id":
 			if id, err := strconv.ParseInt(value, 10, 64); err == nil {
 				boolQuery["filter"] = append(boolQuery["filter"].([]interface{}), map[string]interface{}{
 					"term": map[string]interface{}{
@@ -0,0 +1,47 @@
+package dao
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"goim-social/apps/search-service/internal/model"
+)
+
+// TestBuildGroupWindowClauses 每个窗口限定群组，只有设置了的窗口边界才生成时间范围
+func TestBuildGroupWindowClauses(t *testing.T) {
+	d := &elasticsearchDAO{}
+	start := time.UnixMilli(1000).UTC()
+	end := time.UnixMilli(2000).UTC()
+
+	got := d.buildGroupWindowClauses([]model.GroupTimeWindow{
+		{GroupID: 1, Start: start, End: end},
+		{GroupID: 2},
+	})
+	want := []interface{}{
+		map[string]interface{}{
+			"bool": map[string]interface{}{
+				"filter": []interface{}{
+					map[string]interface{}{"term": map[string]interface{}{"group_id": int64(1)}},
+					map[string]interface{}{"range": map[string]interface{}{"created_at": map[string]interface{}{
+						"gte": start.Format(time.RFC3339Nano),
+						"lte": end.Format(time.RFC3339Nano),
+					}}},
+				},
+			},
+		},
+		map[string]interface{}{
+			"bool": map[string]interface{}{
+				"filter": []interface{}{
+					map[string]interface{}{"term": map[string]interface{}{"group_id": int64(2)}},
+				},
+			},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("buildGroupWindowClauses() = %v, want %v", got, want)
+	}
+	if got := d.buildGroupWindowClauses(nil); len(got) != 0 {
+		t.Errorf("buildGroupWindowClauses(nil) = %v, want empty", got)
+	}
+}
@@ -0,0 +1,78 @@
+package dao
+
+import (
+	"sort"
+)
+
+// ============ 多类型搜索结果合并 ============
+// ES的相关性分受索引的文档数、字段长度和查询结构影响，不同索引的原始分数不可比较，直接拼接后的顺序没有意义。
+// 合并前先将每个索引内的分数按最高分和最低分归一化到0~1，再乘以类型权重，最后按加权分数统一排序
+
+// tiedHitScore 索引内所有命中同分时的归一化分数。同分时无法判断这些命中在0~1中的位置，
+// 取中间值：只有一条弱相关命中的索引不会因此排到其他索引的最佳结果之前，也不会整体沉底
+const tiedHitScore = 0.5
+
+// blendHit 待合并的一条结果及其加权后的分数
+type blendHit struct {
+	score  float64
+	result interface{}
+}
+
+// typeWeight 结果类型的合并权重，未配置时为1，负数按0处理
+func (d *elasticsearchDAO) typeWeight(searchType string) float64 {
+	weight, ok := d.blend.TypeWeights[searchType]
+	if !ok {
+		return 1
+	}
+	if weight < 0 {
+		return 0
+	}
+	return weight
+}
+
+// normalizeHitScores 将同一索引内命中的相关性分归一化到0~1，返回与hits顺序一致的分数
+// 所有命中同分（包括只有一条命中）时都为tiedHitScore，缺少分数的命中按0分计算
+func normalizeHitScores(hits []map[string]interface{}) []float64 {
+	scores := make([]float64, len(hits))
+	if len(hits) == 0 {
+		return scores
+	}
+
+	minScore, maxScore := 0.0, 0.0
+	for i, hit := range hits {
+		score, _ := hit["_score"].(float64)
+		scores[i] = score
+		if i == 0 || score < minScore {
+			minScore = score
+		}
+		if i == 0 || score > maxScore {
+			maxScore = score
+		}
+	}
+
+	for i, score := range scores {
+		if maxScore == minScore {
+			scores[i] = tiedHitScore
+		} else {
+			scores[i] = (score - minScore) / (maxScore - minScore)
+		}
+	}
+	return scores
+}
+
+// blendResults 按加权分数降序合并各类型的结果，同分时保持类型顺序和类型内原有顺序
+func blendResults(groups [][]blendHit) []interface{} {
+	var hits []blendHit
+	for _, group := range groups {
+		hits = append(hits, group...)
+	}
+	sort.SliceStable(hits, func(i, j int) bool {
+		return hits[i].score > hits[j].score
+	})
+
+	results := make([]interface{}, len(hits))
+	for i, hit := range hits {
+		results[i] = hit.result
+	}
+	return results
+}
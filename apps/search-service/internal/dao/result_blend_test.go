@@ -0,0 +1,44 @@
+package dao
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestNormalizeHitScores 按索引内最高分和最低分归一化，同分或只有一条命中时取中间值
+func TestNormalizeHitScores(t *testing.T) {
+	cases := []struct {
+		name string
+		hits []map[string]interface{}
+		want []float64
+	}{
+		{name: "empty", hits: nil, want: []float64{}},
+		{name: "min max", hits: []map[string]interface{}{{"_score": 9.0}, {"_score": 5.0}, {"_score": 1.0}}, want: []float64{1, 0.5, 0}},
+		{name: "single hit", hits: []map[string]interface{}{{"_score": 0.3}}, want: []float64{tiedHitScore}},
+		{name: "all tied", hits: []map[string]interface{}{{"_score": 2.0}, {"_score": 2.0}}, want: []float64{tiedHitScore, tiedHitScore}},
+		{name: "missing score", hits: []map[string]interface{}{{"_score": 4.0}, {}}, want: []float64{1, 0}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := normalizeHitScores(tc.hits); !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("normalizeHitScores() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+// TestBlendResults 按加权分数降序合并，同分时保持类型顺序和类型内顺序
+func TestBlendResults(t *testing.T) {
+	groups := [][]blendHit{
+		{{score: 1, result: "content-1"}, {score: 0.5, result: "content-2"}},
+		{{score: tiedHitScore, result: "user-1"}},
+		{{score: 0.8, result: "group-1"}, {score: 0, result: "group-2"}},
+	}
+	want := []interface{}{"content-1", "group-1", "content-2", "user-1", "group-2"}
+	if got := blendResults(groups); !reflect.DeepEqual(got, want) {
+		t.Errorf("blendResults() = %v, want %v", got, want)
+	}
+	if got := blendResults(nil); len(got) != 0 {
+		t.Errorf("blendResults(nil) = %v, want empty", got)
+	}
+}
@@ -3,10 +3,13 @@ package dao
 import (
 	"encoding/json"
 	"fmt"
+	"html"
 	"strconv"
+	"strings"
 	"time"
 
 	"goim-social/apps/search-service/internal/model"
+	"goim-social/pkg/utils"
 )
 
 // ============ 结果转换器 ============
@@ -91,6 +94,13 @@ func (d *elasticsearchDAO) convertToContentResult(hit SearchHit) (*model.Content
 		}
 	}
 
+	// 没有作者摘要时由正文生成纯文本摘要，与内容服务的摘要规则一致；搜索结果只返回摘要，不返回正文
+	if result.Summary == "" {
+		result.Summary = utils.Excerpt(result.Content, d.snippetLength())
+	}
+	result.Snippet = d.buildSnippet(result.Highlight, []string{"content", "summary", "title"}, result.Summary)
+	result.Content = ""
+
 	return result, nil
 }
 
@@ -156,6 +166,8 @@ func (d *elasticsearchDAO) convertToUserResult(hit SearchHit) (*model.UserSearch
 		}
 	}
 
+	result.Snippet = d.buildSnippet(result.Highlight, []string{"bio", "nickname", "username"}, result.Bio)
+
 	return result, nil
 }
 
@@ -218,6 +230,8 @@ func (d *elasticsearchDAO) convertToMessageResult(hit SearchHit) (*model.Message
 		}
 	}
 
+	result.Snippet = d.buildSnippet(result.Highlight, []string{"content"}, result.Content)
+
 	return result, nil
 }
 
@@ -281,9 +295,40 @@ func (d *elasticsearchDAO) convertToGroupResult(hit SearchHit) (*model.GroupSear
 		result.Category = category
 	}
 
+	result.Snippet = d.buildSnippet(result.Highlight, []string{"description", "name"}, result.Description)
+
 	return result, nil
 }
 
+// buildSnippet 生成结果摘要，按字段优先级取第一个有高亮片段的字段，多个片段以省略号连接
+// 高亮片段已由ES按html编码器转义；没有高亮时截断原文并转义
+func (d *elasticsearchDAO) buildSnippet(highlight map[string][]string, fields []string, fallback string) string {
+	for _, field := range fields {
+		if fragments := highlight[field]; len(fragments) > 0 {
+			return strings.Join(fragments, " "+model.SnippetEllipsis+" ")
+		}
+	}
+	return html.EscapeString(truncateSnippet(fallback, d.snippetLength()))
+}
+
+// snippetLength 获取摘要截断长度
+func (d *elasticsearchDAO) snippetLength() int {
+	if d.highlight.SnippetLength > 0 {
+		return d.highlight.SnippetLength
+	}
+	return model.DefaultSnippetLength
+}
+
+// truncateSnippet 按字符截断文本，截断时追加省略号
+func truncateSnippet(text string, length int) string {
+	text = strings.TrimSpace(text)
+	runes := []rune(text)
+	if len(runes) <= length {
+		return text
+	}
+	return string(runes[:length]) + model.SnippetEllipsis
+}
+
 // convertMultiSearchResult 转换多搜索结果
 func (d *elasticsearchDAO) convertMultiSearchResult(hit map[string]interface{}, index string) interface{} {
 	// 构建SearchHit结构
@@ -349,7 +394,18 @@ func (d *elasticsearchDAO) convertToSearchResult(hit SearchHit) *model.SearchRes
 		Score:     hit.Score,
 		Source:    hit.Source,
 		Highlight: hit.Highlight,
+		Snippet:   d.buildSnippet(hit.Highlight, d.getHighlightFields(hit.Index), d.snippetSource(hit)),
+	}
+}
+
+// snippetSource 获取通用结果中用于生成摘要的原文字段
+func (d *elasticsearchDAO) snippetSource(hit SearchHit) string {
+	for _, field := range d.getHighlightFields(hit.Index) {
+		if value, ok := hit.Source[field].(string); ok && value != "" {
+			return value
+		}
 	}
+	return ""
 }
 
 // parseTimeField 解析时间字段
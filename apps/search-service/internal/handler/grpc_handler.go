@@ -64,7 +64,7 @@ func (h *GRPCHandler) Search(ctx context.Context, req *rest.SearchRequest) (*res
 		Results:  make([]*rest.SearchResult, len(searchResp.Results)),
 		Total:    searchResp.Total,
 		Page:     req.Page,
-		PageSize: req.PageSize,
+		PageSize: int32(searchReq.PageSize),
 		Query:    req.Query,
 		Type:     req.Type,
 		Took:     0, // TODO: 添加执行时间统计
@@ -89,27 +89,7 @@ func (h *GRPCHandler) SearchContent(ctx context.Context, req *rest.SearchContent
 		SortOrder: req.SortOrder,
 		Highlight: req.Highlight,
 		UserID:    req.UserId,
-		Filters:   make(map[string]string),
-	}
-
-	// 添加过滤器
-	if req.Category != "" {
-		searchReq.Filters["category"] = req.Category
-	}
-	if req.AuthorId > 0 {
-		searchReq.Filters["author_id"] = strconv.FormatInt(req.AuthorId, 10)
-	}
-	if req.Status != "" {
-		searchReq.Filters["status"] = req.Status
-	}
-	if req.IsPublic {
-		searchReq.Filters["is_public"] = "true"
-	}
-	if req.DateFrom != "" {
-		searchReq.Filters["date_from"] = req.DateFrom
-	}
-	if req.DateTo != "" {
-		searchReq.Filters["date_to"] = req.DateTo
+		Filters:   contentSearchFilters(req),
 	}
 
 	// 执行搜索
@@ -126,7 +106,7 @@ func (h *GRPCHandler) SearchContent(ctx context.Context, req *rest.SearchContent
 		Results:  make([]*rest.ContentSearchResult, len(results)),
 		Total:    total,
 		Page:     req.Page,
-		PageSize: req.PageSize,
+		PageSize: int32(searchReq.PageSize),
 		Took:     0, // TODO: 添加执行时间统计
 	}
 
@@ -134,9 +114,46 @@ func (h *GRPCHandler) SearchContent(ctx context.Context, req *rest.SearchContent
 		response.Results[i] = h.convertToContentSearchResult(result)
 	}
 
+	// 分类分面计数失败不影响搜索结果
+	categoryCounts, err := h.searchService.GetContentCategoryFacets(ctx, searchReq)
+	if err != nil {
+		h.logger.Warn(ctx, "SearchContent category facets failed",
+			logger.F("query", req.Query),
+			logger.F("error", err.Error()))
+	} else {
+		response.CategoryCounts = categoryCounts
+	}
+
 	return response, nil
 }
 
+// contentSearchFilters 构建内容搜索过滤器
+func contentSearchFilters(req *rest.SearchContentRequest) map[string]string {
+	filters := make(map[string]string)
+	if req.Category != "" {
+		filters["category"] = req.Category
+	}
+	if req.CategoryId > 0 {
+		filters["category_id"] = strconv.FormatInt(req.CategoryId, 10)
+	}
+	if req.AuthorId > 0 {
+		filters["author_id"] = strconv.FormatInt(req.AuthorId, 10)
+	}
+	if req.Status != "" {
+		filters["status"] = req.Status
+	}
+	if req.IsPublic {
+		filters["is_public"] = "true"
+	}
+	if req.DateFrom != "" {
+		filters["date_from"] = req.DateFrom
+	}
+	if req.DateTo != "" {
+		filters["date_to"] = req.DateTo
+	}
+	return filters
+}
+
 // SearchUsers 用户搜索
 func (h *GRPCHandler) SearchUsers(ctx context.Context, req *rest.SearchUsersRequest) (*rest.SearchUsersResponse, error) {
 	// 转换请求
@@ -183,7 +200,7 @@ func (h *GRPCHandler) SearchUsers(ctx context.Context, req *rest.SearchUsersRequ
 		Results:  make([]*rest.UserSearchResult, len(results)),
 		Total:    total,
 		Page:     req.Page,
-		PageSize: req.PageSize,
+		PageSize: int32(searchReq.PageSize),
 		Took:     0, // TODO: 添加执行时间统计
 	}
 
@@ -240,7 +257,7 @@ func (h *GRPCHandler) SearchMessages(ctx context.Context, req *rest.SearchMessag
 		Results:  make([]*rest.MessageSearchResult, len(results)),
 		Total:    total,
 		Page:     req.Page,
-		PageSize: req.PageSize,
+		PageSize: int32(searchReq.PageSize),
 		Took:     0, // TODO: 添加执行时间统计
 	}
 
@@ -297,7 +314,7 @@ func (h *GRPCHandler) SearchGroups(ctx context.Context, req *rest.SearchGroupsRe
 		Results:  make([]*rest.GroupSearchResult, len(results)),
 		Total:    total,
 		Page:     req.Page,
-		PageSize: req.PageSize,
+		PageSize: int32(searchReq.PageSize),
 		Took:     0, // TODO: 添加执行时间统计
 	}
 
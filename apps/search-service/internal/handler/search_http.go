@@ -68,6 +68,7 @@ func (h *HTTPHandler) SearchContent(c *gin.Context) {
 		SortOrder: req.SortOrder,
 		Highlight: req.Highlight,
 		UserID:    req.UserId,
+		Filters:   contentSearchFilters(req),
 	}
 
 	results, total, err := h.searchService.SearchContent(ctx, modelReq)
@@ -77,14 +78,22 @@ func (h *HTTPHandler) SearchContent(c *gin.Context) {
 			logger.F("error", err.Error()))
 		resp = h.converter.BuildHTTPErrorResponse("search content failed: " + err.Error())
 	} else {
+		// 分类分面计数失败不影响搜索结果
+		categoryCounts, facetErr := h.searchService.GetContentCategoryFacets(ctx, modelReq)
+		if facetErr != nil {
+			h.logger.Warn(ctx, "SearchContent category facets failed",
+				logger.F("query", req.Query),
+				logger.F("error", facetErr.Error()))
+		}
 		resp = map[string]interface{}{
 			"code":    0,
 			"message": "success",
 			"data": map[string]interface{}{
-				"results":   results,
-				"total":     total,
-				"page":      req.Page,
-				"page_size": req.PageSize,
+				"results":         results,
+				"total":           total,
+				"page":            req.Page,
+				"page_size":       modelReq.PageSize,
+				"category_counts": categoryCounts,
 			},
 		}
 	}
@@ -133,7 +142,7 @@ func (h *HTTPHandler) SearchUsers(c *gin.Context) {
 				"results":   results,
 				"total":     total,
 				"page":      req.Page,
-				"page_size": req.PageSize,
+				"page_size": modelReq.PageSize,
 			},
 		}
 	}
@@ -182,7 +191,7 @@ func (h *HTTPHandler) SearchMessages(c *gin.Context) {
 				"results":   results,
 				"total":     total,
 				"page":      req.Page,
-				"page_size": req.PageSize,
+				"page_size": modelReq.PageSize,
 			},
 		}
 	}
@@ -231,7 +240,7 @@ func (h *HTTPHandler) SearchGroups(c *gin.Context) {
 				"results":   results,
 				"total":     total,
 				"page":      req.Page,
-				"page_size": req.PageSize,
+				"page_size": modelReq.PageSize,
 			},
 		}
 	}
@@ -256,14 +265,15 @@ func (h *HTTPHandler) MultiSearch(c *gin.Context) {
 
 	// 转换为model请求
 	modelReq := &model.SearchRequest{
-		Query:     req.Query,
-		Type:      model.SearchTypeAll, // 多类型搜索
-		Page:      int(req.Page),
-		PageSize:  int(req.PageSize),
-		SortBy:    req.SortBy,
-		SortOrder: req.SortOrder,
-		Highlight: req.Highlight,
-		UserID:    req.UserId,
+		Query:       req.Query,
+		Type:        model.SearchTypeAll, // 多类型搜索
+		Page:        int(req.Page),
+		PageSize:    int(req.PageSize),
+		SortBy:      req.SortBy,
+		SortOrder:   req.SortOrder,
+		Highlight:   req.Highlight,
+		UserID:      req.UserId,
+		GroupByType: req.GroupByType,
 	}
 
 	result, err := h.searchService.MultiSearch(ctx, modelReq)
@@ -319,12 +329,6 @@ func (h *HTTPHandler) bindSearchRequest(c *gin.Context, req *rest.SearchRequest)
 	if req.Page <= 0 {
 		req.Page = 1
 	}
-	if req.PageSize <= 0 {
-		req.PageSize = int32(model.DefaultPageSize)
-	}
-	if req.PageSize > int32(model.MaxPageSize) {
-		req.PageSize = int32(model.MaxPageSize)
-	}
 
 	return nil
 }
@@ -366,6 +370,12 @@ func (h *HTTPHandler) bindSearchContentRequest(c *gin.Context, req *rest.SearchC
 		}
 	}
 
+	if categoryID := c.Query("category_id"); categoryID != "" {
+		if cid, err := strconv.ParseInt(categoryID, 10, 64); err == nil {
+			req.CategoryId = cid
+		}
+	}
+
 	if isPublic := c.Query("is_public"); isPublic == "true" {
 		req.IsPublic = true
 	}
@@ -377,9 +387,6 @@ func (h *HTTPHandler) bindSearchContentRequest(c *gin.Context, req *rest.SearchC
 	if req.Page <= 0 {
 		req.Page = 1
 	}
-	if req.PageSize <= 0 {
-		req.PageSize = int32(model.DefaultPageSize)
-	}
 
 	return nil
 }
@@ -426,9 +433,6 @@ func (h *HTTPHandler) bindSearchUsersRequest(c *gin.Context, req *rest.SearchUse
 	if req.Page <= 0 {
 		req.Page = 1
 	}
-	if req.PageSize <= 0 {
-		req.PageSize = int32(model.DefaultPageSize)
-	}
 
 	return nil
 }
@@ -482,9 +486,6 @@ func (h *HTTPHandler) bindSearchMessagesRequest(c *gin.Context, req *rest.Search
 	if req.Page <= 0 {
 		req.Page = 1
 	}
-	if req.PageSize <= 0 {
-		req.PageSize = int32(model.DefaultPageSize)
-	}
 
 	return nil
 }
@@ -531,9 +532,6 @@ func (h *HTTPHandler) bindSearchGroupsRequest(c *gin.Context, req *rest.SearchGr
 	if req.Page <= 0 {
 		req.Page = 1
 	}
-	if req.PageSize <= 0 {
-		req.PageSize = int32(model.DefaultPageSize)
-	}
 
 	return nil
 }
@@ -567,6 +565,10 @@ func (h *HTTPHandler) bindMultiSearchRequest(c *gin.Context, req *rest.MultiSear
 		req.Highlight = true
 	}
 
+	if groupByType := c.Query("group_by_type"); groupByType == "true" {
+		req.GroupByType = true
+	}
+
 	if userID := c.Query("user_id"); userID != "" {
 		if uid, err := strconv.ParseInt(userID, 10, 64); err == nil {
 			req.UserId = uid
@@ -577,9 +579,6 @@ func (h *HTTPHandler) bindMultiSearchRequest(c *gin.Context, req *rest.MultiSear
 	if req.Page <= 0 {
 		req.Page = 1
 	}
-	if req.PageSize <= 0 {
-		req.PageSize = int32(model.DefaultPageSize)
-	}
 
 	return nil
 }
@@ -130,6 +130,16 @@ const (
 	DefaultHighlightPreTag  = "<mark>"
 	DefaultHighlightPostTag = "</mark>"
 
+	// 默认高亮片段配置
+	DefaultHighlightFragmentSize = 150
+	DefaultHighlightFragments    = 3
+	DefaultSnippetLength         = 150
+	SnippetEllipsis              = "..."
+
+	// 分面聚合
+	FacetCategory     = "category"
+	CategoryFacetSize = 50
+
 	// 默认搜索权重
 	DefaultTitleWeight   = 3.0
 	DefaultContentWeight = 1.0
@@ -181,11 +191,13 @@ const (
 
 const (
 	// Kafka主题名称
-	TopicContentIndex = "content-index-events"
-	TopicUserIndex    = "user-index-events"
-	TopicMessageIndex = "message-index-events"
-	TopicGroupIndex   = "group-index-events"
-	TopicSearchEvents = "search-events"
+	TopicContentIndex   = "content-index-events"
+	TopicUserIndex      = "user-index-events"
+	TopicMessageIndex   = "message-index-events"
+	TopicGroupIndex     = "group-index-events"
+	TopicContentDeleted = "content-deleted-events" // 内容服务发布的内容删除事件
+	TopicMessagePurged  = "message-purged-events"  // 消息服务发布的消息清理事件
+	TopicSearchEvents   = "search-events"
 )
 
 // ============ 错误消息常量 ============
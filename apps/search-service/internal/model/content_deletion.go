@@ -0,0 +1,9 @@
+package model
+
+// ============ 内容删除同步 ============
+
+// ContentDeletedEvent 内容服务发布的内容删除事件，搜索服务只关心内容ID
+type ContentDeletedEvent struct {
+	ContentID int64 `json:"content_id"`
+	Hard      bool  `json:"hard"`
+}
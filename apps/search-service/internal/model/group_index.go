@@ -0,0 +1,29 @@
+package model
+
+import "time"
+
+// ============ 群组索引同步 ============
+
+// GroupIndexEventUpdate 群组资料更新事件，与社交服务保持一致
+const GroupIndexEventUpdate = "update"
+
+// GroupIndexEvent 社交服务发布的群组索引事件，除event_type外的字段即完整的群组文档
+type GroupIndexEvent struct {
+	EventType string `json:"event_type"`
+	GroupDocument
+}
+
+// GroupDocument 群组索引文档
+type GroupDocument struct {
+	ID          int64     `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	Avatar      string    `json:"avatar"`
+	OwnerID     int64     `json:"owner_id"`
+	MemberCount int32     `json:"member_count"`
+	MaxMembers  int32     `json:"max_members"`
+	IsPublic    bool      `json:"is_public"`
+	Version     int64     `json:"version"` // 群资料版本号
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
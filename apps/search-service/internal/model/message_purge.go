@@ -0,0 +1,12 @@
+package model
+
+// ============ 消息清理同步 ============
+
+// MessagePurgedEvent 消息服务发布的消息清理事件，同一事件中的消息属于同一会话
+type MessagePurgedEvent struct {
+	ConversationID string  `json:"conversation_id"`
+	GroupID        int64   `json:"group_id,omitempty"`
+	MessageIDs     []int64 `json:"message_ids"`
+	Reason         string  `json:"reason"`
+	PurgedAt       int64   `json:"purged_at"`
+}
@@ -0,0 +1,63 @@
+package model
+
+// ============ 热度加权 ============
+
+// 内容服务发布的互动类事件Topic
+const (
+	TopicInteractionEvents = "interaction-events"  // 点赞、分享等互动事件
+	TopicCommentEvents     = "comment-events"      // 评论事件
+	TopicContentViewEvents = "content-view-events" // 浏览增量事件，由浏览计数落库时发布
+)
+
+// 互动事件取值，与内容服务保持一致
+const (
+	EngagementTargetContent = "content"
+	EngagementEventCreate   = "create"
+	EngagementEventDelete   = "delete"
+	EngagementTypeLike      = "like"
+	EngagementTypeShare     = "share"
+)
+
+// 热度分中各计数的系数，热度分 = Σ 系数 * log1p(计数)
+const (
+	PopularityLikeFactor    = 1.0
+	PopularityCommentFactor = 1.5
+	PopularityShareFactor   = 2.0
+	PopularityViewFactor    = 0.2
+)
+
+// PopularityFlushInterval 计数增量批量写入索引的间隔（秒）
+const PopularityFlushInterval = 5
+
+// ContentEngagementEvent 内容服务发布的互动、评论和浏览事件，三类事件共用字段
+type ContentEngagementEvent struct {
+	EventType       string `json:"event_type"`
+	TargetID        int64  `json:"target_id"`
+	TargetType      string `json:"target_type"`
+	InteractionType string `json:"interaction_type,omitempty"` // 互动事件的互动类型
+	Count           int64  `json:"count,omitempty"`            // 浏览事件的浏览增量
+}
+
+// PopularityDelta 一篇内容待写入索引的计数增量
+type PopularityDelta struct {
+	ViewCount    int64
+	LikeCount    int64
+	CommentCount int64
+	ShareCount   int64
+}
+
+// Fields 返回非零的增量，键为索引字段名
+func (d *PopularityDelta) Fields() map[string]int64 {
+	fields := make(map[string]int64, 4)
+	for field, value := range map[string]int64{
+		"view_count":    d.ViewCount,
+		"like_count":    d.LikeCount,
+		"comment_count": d.CommentCount,
+		"share_count":   d.ShareCount,
+	} {
+		if value != 0 {
+			fields[field] = value
+		}
+	}
+	return fields
+}
@@ -2,23 +2,30 @@ package model
 
 import (
 	"time"
+
+	"goim-social/pkg/membership"
 )
 
 // ============ 搜索请求和响应模型 ============
 
 // SearchRequest 通用搜索请求
 type SearchRequest struct {
-	Query      string            `json:"query" binding:"required"`
-	Type       string            `json:"type" binding:"required"` // content/user/message/group
-	Filters    map[string]string `json:"filters,omitempty"`
-	SortBy     string            `json:"sort_by,omitempty"`
-	SortOrder  string            `json:"sort_order,omitempty"` // asc/desc
-	Page       int               `json:"page,omitempty"`
-	PageSize   int               `json:"page_size,omitempty"`
-	Highlight  bool              `json:"highlight,omitempty"`
-	UserID     int64             `json:"user_id,omitempty"`
+	Query        string            `json:"query" binding:"required"`
+	Type         string            `json:"type" binding:"required"` // content/user/message/group
+	Filters      map[string]string `json:"filters,omitempty"`
+	SortBy       string            `json:"sort_by,omitempty"`
+	SortOrder    string            `json:"sort_order,omitempty"` // asc/desc
+	Page         int               `json:"page,omitempty"`
+	PageSize     int               `json:"page_size,omitempty"`
+	Highlight    bool              `json:"highlight,omitempty"`
+	UserID       int64             `json:"user_id,omitempty"`
+	GroupByType  bool              `json:"group_by_type,omitempty"` // 多类型搜索按类型分组返回，不合并为一个列表
+	GroupWindows []GroupTimeWindow `json:"-"` // 消息搜索时用户可见的群消息时间窗口，由服务层按成员身份时段填充
 }
 
+// GroupTimeWindow 用户在群内可见消息的时间窗口，对应一段成员身份时段，时间为零值表示不限
+type GroupTimeWindow = membership.Window
+
 // SearchResponse 通用搜索响应
 type SearchResponse struct {
 	Query       string        `json:"query"`
@@ -27,12 +34,20 @@ type SearchResponse struct {
 	Page        int           `json:"page"`
 	PageSize    int           `json:"page_size"`
 	Results     []interface{} `json:"results"`
+	Groups      []*TypeResults `json:"groups,omitempty"` // 多类型搜索按类型分组返回时的各类型结果，此时Results为空
 	Aggregations interface{}   `json:"aggregations,omitempty"`
 	Suggestions []string      `json:"suggestions,omitempty"`
 	Duration    int64         `json:"duration_ms"`
 	FromCache   bool          `json:"from_cache"`
 }
 
+// TypeResults 多类型搜索中一种类型的结果，按该类型内的相关性排序
+type TypeResults struct {
+	Type    string        `json:"type"`
+	Total   int64         `json:"total"`
+	Results []interface{} `json:"results"`
+}
+
 // SearchResult 搜索结果项
 type SearchResult struct {
 	ID          string                 `json:"id"`
@@ -40,6 +55,7 @@ type SearchResult struct {
 	Score       float64                `json:"score"`
 	Source      map[string]interface{} `json:"source"`
 	Highlight   map[string][]string    `json:"highlight,omitempty"`
+	Snippet     string                 `json:"snippet,omitempty"` // 可直接渲染的摘要，已转义，仅保留高亮标签
 }
 
 // ============ 内容搜索模型 ============
@@ -80,6 +96,7 @@ type ContentSearchResult struct {
 	CreatedAt    time.Time           `json:"created_at"`
 	Score        float64             `json:"score"`
 	Highlight    map[string][]string `json:"highlight,omitempty"`
+	Snippet      string              `json:"snippet,omitempty"`
 }
 
 // ============ 用户搜索模型 ============
@@ -118,6 +135,7 @@ type UserSearchResult struct {
 	IsFriend      bool                `json:"is_friend,omitempty"`
 	Score         float64             `json:"score"`
 	Highlight     map[string][]string `json:"highlight,omitempty"`
+	Snippet       string              `json:"snippet,omitempty"`
 }
 
 // ============ 消息搜索模型 ============
@@ -150,6 +168,7 @@ type MessageSearchResult struct {
 	CreatedAt    time.Time           `json:"created_at"`
 	Score        float64             `json:"score"`
 	Highlight    map[string][]string `json:"highlight,omitempty"`
+	Snippet      string              `json:"snippet,omitempty"`
 }
 
 // ============ 群组搜索模型 ============
@@ -187,6 +206,7 @@ type GroupSearchResult struct {
 	IsMember    bool                `json:"is_member,omitempty"`
 	Score       float64             `json:"score"`
 	Highlight   map[string][]string `json:"highlight,omitempty"`
+	Snippet     string              `json:"snippet,omitempty"`
 }
 
 // ============ 搜索建议模型 ============
@@ -3,10 +3,12 @@ package service
 import (
 	"context"
 	"fmt"
+	"strconv"
 	"time"
 
 	"goim-social/apps/search-service/internal/dao"
 	"goim-social/apps/search-service/internal/model"
+	"goim-social/pkg/config"
 	"goim-social/pkg/database"
 	"goim-social/pkg/logger"
 )
@@ -21,13 +23,13 @@ type indexService struct {
 }
 
 // NewIndexService 创建索引服务实例（简化版本）
-func NewIndexService(elasticSearch *database.ElasticSearch, postgreSQL *database.PostgreSQL, log logger.Logger) IndexService {
+func NewIndexService(elasticSearch *database.ElasticSearch, postgreSQL *database.PostgreSQL, searchConfig config.SearchConfig, log logger.Logger) IndexService {
 	if elasticSearch == nil {
 		panic("ElasticSearch is required for search service. Please set ELASTICSEARCH_ENABLED=true and ensure ElasticSearch is running.")
 	}
 
 	// 初始化DAO层
-	searchDAO := dao.NewElasticsearchDAO(elasticSearch.GetClient(), log)
+	searchDAO := dao.NewElasticsearchDAO(elasticSearch.GetClient(), searchConfig, log)
 	historyDAO := dao.NewHistoryDAO(postgreSQL, log)
 
 	// 初始化服务层依赖
@@ -35,11 +37,9 @@ func NewIndexService(elasticSearch *database.ElasticSearch, postgreSQL *database
 
 	// 创建默认配置
 	config := &ServiceConfig{
-		DefaultPageSize:  20,
-		MaxPageSize:      100,
 		SearchTimeout:    5000,
-		HighlightPreTag:  "<em>",
-		HighlightPostTag: "</em>",
+		HighlightPreTag:  searchConfig.Highlight.PreTag,
+		HighlightPostTag: searchConfig.Highlight.PostTag,
 		CacheEnabled:     true,
 		CacheTTL: map[string]int{
 			"search_results": 300,
@@ -358,10 +358,15 @@ func (s *indexService) BulkIndexDocuments(ctx context.Context, indexType string,
 // ============ 数据同步 ============
 
 // SyncFromDatabase 从数据库同步数据
+// 消息索引不支持从数据库同步：启用静态加密（message.encryption）后消息正文在库中为密文，密钥只由消息服务持有，
+// 消息文档由消息服务保存消息时用明文发布到message-index-events，消息索引消费者写入
 func (s *indexService) SyncFromDatabase(ctx context.Context, sourceService string, sourceTable string, targetIndex string) error {
 	if sourceService == "" || sourceTable == "" || targetIndex == "" {
 		return fmt.Errorf("source service, source table and target index are required")
 	}
+	if targetIndex == model.IndexMessage || targetIndex == model.SearchTypeMessage {
+		return fmt.Errorf("message index cannot be synced from database: message bodies may be encrypted at rest, index decrypted documents from message service instead")
+	}
 
 	s.logger.Info(ctx, "Starting database sync",
 		logger.F("source_service", sourceService),
@@ -623,6 +628,7 @@ func (s *indexService) getGroupMapping() map[string]interface{} {
 			"member_count": map[string]interface{}{"type": "long"},
 			"max_members":  map[string]interface{}{"type": "long"},
 			"is_public":    map[string]interface{}{"type": "boolean"},
+			"version":      map[string]interface{}{"type": "long"},
 			"tags":         map[string]interface{}{"type": "keyword"},
 			"category":     map[string]interface{}{"type": "keyword"},
 			"status":       map[string]interface{}{"type": "keyword"},
@@ -730,3 +736,36 @@ func (s *indexService) DeleteDocument(ctx context.Context, indexType string, doc
 
 	return nil
 }
+
+// DeleteMessageDocuments 批量从消息索引删除文档，文档不存在时视为成功
+func (s *indexService) DeleteMessageDocuments(ctx context.Context, messageIDs []int64) error {
+	docs := make([]dao.BulkDocument, 0, len(messageIDs))
+	for _, messageID := range messageIDs {
+		if messageID > 0 {
+			docs = append(docs, dao.BulkDocument{ID: strconv.FormatInt(messageID, 10), Action: "delete"})
+		}
+	}
+
+	if err := s.searchDAO.BulkIndexDocuments(ctx, model.IndexMessage, docs); err != nil {
+		s.logger.Error(ctx, "Failed to delete message documents",
+			logger.F("count", len(docs)),
+			logger.F("error", err.Error()))
+		return fmt.Errorf("failed to delete message documents: %v", err)
+	}
+	return nil
+}
+
+// ApplyPopularityDeltas 将内容的互动计数增量写入索引，返回写入失败的内容ID
+func (s *indexService) ApplyPopularityDeltas(ctx context.Context, deltas map[int64]*model.PopularityDelta) []int64 {
+	var failed []int64
+	for contentID, delta := range deltas {
+		docID := strconv.FormatInt(contentID, 10)
+		if err := s.searchDAO.IncrementCounters(ctx, model.IndexContent, docID, delta.Fields()); err != nil {
+			s.logger.Warn(ctx, "Failed to apply popularity delta",
+				logger.F("content_id", contentID),
+				logger.F("error", err.Error()))
+			failed = append(failed, contentID)
+		}
+	}
+	return failed
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"goim-social/apps/search-service/internal/model"
+	"goim-social/pkg/pagination"
 )
 
 // SearchService 搜索服务接口
@@ -16,6 +17,9 @@ type SearchService interface {
 	// SearchContent 内容搜索
 	SearchContent(ctx context.Context, req *model.SearchRequest) ([]*model.ContentSearchResult, int64, error)
 	
+	// GetContentCategoryFacets 获取内容搜索的分类分面计数
+	GetContentCategoryFacets(ctx context.Context, req *model.SearchRequest) (map[string]int64, error)
+	
 	// SearchUsers 用户搜索
 	SearchUsers(ctx context.Context, req *model.SearchRequest) ([]*model.UserSearchResult, int64, error)
 	
@@ -97,6 +101,12 @@ type IndexService interface {
 	// DeleteDocument 删除文档
 	DeleteDocument(ctx context.Context, indexType string, docID string) error
 
+	// DeleteMessageDocuments 批量从消息索引删除文档
+	DeleteMessageDocuments(ctx context.Context, messageIDs []int64) error
+	
+	// ApplyPopularityDeltas 将内容的互动计数增量写入索引，返回写入失败的内容ID
+	ApplyPopularityDeltas(ctx context.Context, deltas map[int64]*model.PopularityDelta) []int64
+
 	// ============ 数据同步 ============
 	
 	// SyncFromDatabase 从数据库同步数据
@@ -262,8 +272,7 @@ type IndexDocument struct {
 // ServiceConfig 服务配置
 type ServiceConfig struct {
 	// 搜索配置
-	DefaultPageSize   int                    `json:"default_page_size"`
-	MaxPageSize       int                    `json:"max_page_size"`
+	Paging            pagination.Limits      `json:"-"` // 每页数量限制，超过上限时截断
 	SearchTimeout     int                    `json:"search_timeout_ms"`
 	HighlightPreTag   string                 `json:"highlight_pre_tag"`
 	HighlightPostTag  string                 `json:"highlight_post_tag"`
@@ -0,0 +1,25 @@
+package service
+
+import (
+	"context"
+
+	"goim-social/apps/search-service/internal/model"
+	"goim-social/pkg/logger"
+	"goim-social/pkg/membership"
+)
+
+// ============ 群消息可见范围 ============
+// 用户只能搜到在群期间的群消息，时间窗口按成员身份时段计算，见pkg/membership。
+// 社交服务不可用时不返回任何群消息，只搜索用户自己的私聊消息
+
+// resolveGroupWindows 查询用户的成员身份时段，填充消息搜索的群消息时间窗口
+func (s *searchService) resolveGroupWindows(ctx context.Context, req *model.SearchRequest) {
+	windows, err := membership.Fetch(ctx, s.social, req.UserID, 0)
+	if err != nil {
+		s.logger.Warn(ctx, "Failed to get membership periods, group messages excluded from search",
+			logger.F("user_id", req.UserID),
+			logger.F("error", err.Error()))
+		windows = []model.GroupTimeWindow{}
+	}
+	req.GroupWindows = windows
+}
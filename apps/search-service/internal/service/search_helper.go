@@ -8,39 +8,36 @@ import (
 	"time"
 
 	"goim-social/apps/search-service/internal/model"
+	"goim-social/pkg/httpx"
 	"goim-social/pkg/logger"
 )
 
 // ============ 验证和默认值设置 ============
 
-// validateSearchRequest 验证搜索请求
+// validateSearchRequest 验证搜索请求，不合法的请求返回InvalidArgument错误
 func (s *searchService) validateSearchRequest(req *model.SearchRequest) error {
 	if req == nil {
-		return fmt.Errorf("search request is nil")
+		return httpx.InvalidArgument(fmt.Errorf("search request is nil"))
 	}
 
 	if req.Query == "" && req.Type != model.SearchTypeAll {
-		return fmt.Errorf("search query is required")
+		return httpx.InvalidArgument(fmt.Errorf("search query is required"))
 	}
 
 	if len(req.Query) > 500 {
-		return fmt.Errorf("search query too long (max 500 characters)")
+		return httpx.InvalidArgument(fmt.Errorf("search query too long (max 500 characters)"))
 	}
 
 	if !model.IsValidSearchType(req.Type) {
-		return fmt.Errorf("invalid search type: %s", req.Type)
-	}
-
-	if req.PageSize > s.config.MaxPageSize {
-		return fmt.Errorf("page size too large (max %d)", s.config.MaxPageSize)
+		return httpx.InvalidArgument(fmt.Errorf("invalid search type: %s", req.Type))
 	}
 
 	if req.SortBy != "" && !model.IsValidSortField(req.SortBy) {
-		return fmt.Errorf("invalid sort field: %s", req.SortBy)
+		return httpx.InvalidArgument(fmt.Errorf("invalid sort field: %s", req.SortBy))
 	}
 
 	if req.SortOrder != "" && !model.IsValidSortOrder(req.SortOrder) {
-		return fmt.Errorf("invalid sort order: %s", req.SortOrder)
+		return httpx.InvalidArgument(fmt.Errorf("invalid sort order: %s", req.SortOrder))
 	}
 
 	return nil
@@ -52,13 +49,8 @@ func (s *searchService) setDefaultValues(req *model.SearchRequest) {
 		req.Page = 1
 	}
 
-	if req.PageSize <= 0 {
-		req.PageSize = s.config.DefaultPageSize
-	}
-
-	if req.PageSize > s.config.MaxPageSize {
-		req.PageSize = s.config.MaxPageSize
-	}
+	// 每页数量超过上限时截断为上限
+	req.PageSize = s.config.Paging.PageSize(req.PageSize)
 
 	if req.SortBy == "" {
 		req.SortBy = model.SortByRelevance
@@ -73,9 +65,9 @@ func (s *searchService) setDefaultValues(req *model.SearchRequest) {
 
 // generateCacheKey 生成缓存键
 func (s *searchService) generateCacheKey(req *model.SearchRequest) string {
-	data := fmt.Sprintf("%s:%s:%d:%d:%s:%s:%v:%d",
+	data := fmt.Sprintf("%s:%s:%d:%d:%s:%s:%v:%d:%t",
 		req.Query, req.Type, req.Page, req.PageSize,
-		req.SortBy, req.SortOrder, req.Filters, req.UserID)
+		req.SortBy, req.SortOrder, req.Filters, req.UserID, req.GroupByType)
 
 	hash := md5.Sum([]byte(data))
 	return fmt.Sprintf("%s%x", model.CacheKeySearchResult, hash)
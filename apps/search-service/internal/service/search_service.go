@@ -5,10 +5,14 @@ import (
 	"fmt"
 	"time"
 
+	"goim-social/api/rest"
 	"goim-social/apps/search-service/internal/dao"
 	"goim-social/apps/search-service/internal/model"
+	"goim-social/pkg/config"
 	"goim-social/pkg/database"
+	"goim-social/pkg/httpx"
 	"goim-social/pkg/logger"
+	"goim-social/pkg/pagination"
 )
 
 // searchService 搜索服务实现
@@ -18,17 +22,18 @@ type searchService struct {
 	cacheService CacheService
 	eventService EventService
 	config       *ServiceConfig
+	social       rest.SocialServiceClient // 查询群成员身份时段，限制群消息搜索范围
 	logger       logger.Logger
 }
 
 // NewService 创建搜索服务实例（简化版本）
-func NewService(elasticSearch *database.ElasticSearch, postgreSQL *database.PostgreSQL, log logger.Logger) SearchService {
+func NewService(elasticSearch *database.ElasticSearch, postgreSQL *database.PostgreSQL, searchConfig config.SearchConfig, paging pagination.Limits, social rest.SocialServiceClient, log logger.Logger) SearchService {
 	if elasticSearch == nil {
 		panic("ElasticSearch is required for search service. Please set ELASTICSEARCH_ENABLED=true and ensure ElasticSearch is running.")
 	}
 
 	// 初始化DAO层
-	searchDAO := dao.NewElasticsearchDAO(elasticSearch.GetClient(), log)
+	searchDAO := dao.NewElasticsearchDAO(elasticSearch.GetClient(), searchConfig, log)
 	historyDAO := dao.NewHistoryDAO(postgreSQL, log)
 
 	// 初始化服务层依赖
@@ -37,11 +42,10 @@ func NewService(elasticSearch *database.ElasticSearch, postgreSQL *database.Post
 
 	// 创建默认配置
 	config := &ServiceConfig{
-		DefaultPageSize:  20,
-		MaxPageSize:      100,
+		Paging:           paging,
 		SearchTimeout:    5000,
-		HighlightPreTag:  "<em>",
-		HighlightPostTag: "</em>",
+		HighlightPreTag:  searchConfig.Highlight.PreTag,
+		HighlightPostTag: searchConfig.Highlight.PostTag,
 		CacheEnabled:     true,
 		CacheTTL: map[string]int{
 			"search_results": 300,
@@ -71,6 +75,7 @@ func NewService(elasticSearch *database.ElasticSearch, postgreSQL *database.Post
 		cacheService: cacheService,
 		eventService: eventService,
 		config:       config,
+		social:       social,
 		logger:       log,
 	}
 }
@@ -166,6 +171,9 @@ func (s *searchService) Search(ctx context.Context, req *model.SearchRequest) (*
 		}
 
 	case model.SearchTypeMessage:
+		if req.UserID > 0 {
+			s.resolveGroupWindows(ctx, req)
+		}
 		results, total, searchErr := s.searchDAO.SearchMessages(ctx, req)
 		if searchErr != nil {
 			err = searchErr
@@ -215,7 +223,7 @@ func (s *searchService) Search(ctx context.Context, req *model.SearchRequest) (*
 
 		// 记录搜索失败事件
 		go s.recordSearchEvent(ctx, req, nil, time.Since(startTime), false)
-		return nil, fmt.Errorf("search failed: %v", err)
+		return nil, fmt.Errorf("search failed: %w", err)
 	}
 
 	// 缓存结果
@@ -273,7 +281,7 @@ func (s *searchService) SearchContent(ctx context.Context, req *model.SearchRequ
 		s.logger.Error(ctx, "Content search failed",
 			logger.F("query", req.Query),
 			logger.F("error", err.Error()))
-		return nil, 0, fmt.Errorf("content search failed: %v", err)
+		return nil, 0, fmt.Errorf("content search failed: %w", err)
 	}
 
 	// 记录搜索历史和事件（异步）
@@ -285,6 +293,21 @@ func (s *searchService) SearchContent(ctx context.Context, req *model.SearchRequ
 	return results, total, nil
 }
 
+// GetContentCategoryFacets 获取内容搜索的分类分面计数
+func (s *searchService) GetContentCategoryFacets(ctx context.Context, req *model.SearchRequest) (map[string]int64, error) {
+	req.Type = model.SearchTypeContent
+	s.setDefaultValues(req)
+
+	counts, err := s.searchDAO.GetContentCategoryFacets(ctx, req)
+	if err != nil {
+		s.logger.Error(ctx, "Content category facets failed",
+			logger.F("query", req.Query),
+			logger.F("error", err.Error()))
+		return nil, fmt.Errorf("content category facets failed: %v", err)
+	}
+	return counts, nil
+}
+
 // SearchUsers 用户搜索
 func (s *searchService) SearchUsers(ctx context.Context, req *model.SearchRequest) ([]*model.UserSearchResult, int64, error) {
 	req.Type = model.SearchTypeUser
@@ -299,7 +322,7 @@ func (s *searchService) SearchUsers(ctx context.Context, req *model.SearchReques
 		s.logger.Error(ctx, "User search failed",
 			logger.F("query", req.Query),
 			logger.F("error", err.Error()))
-		return nil, 0, fmt.Errorf("user search failed: %v", err)
+		return nil, 0, fmt.Errorf("user search failed: %w", err)
 	}
 
 	// 记录搜索历史和事件（异步）
@@ -322,8 +345,9 @@ func (s *searchService) SearchMessages(ctx context.Context, req *model.SearchReq
 
 	// 消息搜索需要用户ID
 	if req.UserID <= 0 {
-		return nil, 0, fmt.Errorf("user ID is required for message search")
+		return nil, 0, httpx.InvalidArgument(fmt.Errorf("user ID is required for message search"))
 	}
+	s.resolveGroupWindows(ctx, req)
 
 	results, total, err := s.searchDAO.SearchMessages(ctx, req)
 	if err != nil {
@@ -331,7 +355,7 @@ func (s *searchService) SearchMessages(ctx context.Context, req *model.SearchReq
 			logger.F("query", req.Query),
 			logger.F("user_id", req.UserID),
 			logger.F("error", err.Error()))
-		return nil, 0, fmt.Errorf("message search failed: %v", err)
+		return nil, 0, fmt.Errorf("message search failed: %w", err)
 	}
 
 	// 记录搜索历史和事件（异步）
@@ -355,7 +379,7 @@ func (s *searchService) SearchGroups(ctx context.Context, req *model.SearchReque
 		s.logger.Error(ctx, "Group search failed",
 			logger.F("query", req.Query),
 			logger.F("error", err.Error()))
-		return nil, 0, fmt.Errorf("group search failed: %v", err)
+		return nil, 0, fmt.Errorf("group search failed: %w", err)
 	}
 
 	// 记录搜索历史和事件（异步）
@@ -376,12 +400,16 @@ func (s *searchService) MultiSearch(ctx context.Context, req *model.SearchReques
 
 	s.setDefaultValues(req)
 
+	if req.UserID > 0 {
+		s.resolveGroupWindows(ctx, req)
+	}
+
 	response, err := s.searchDAO.MultiSearch(ctx, req)
 	if err != nil {
 		s.logger.Error(ctx, "Multi search failed",
 			logger.F("query", req.Query),
 			logger.F("error", err.Error()))
-		return nil, fmt.Errorf("multi search failed: %v", err)
+		return nil, fmt.Errorf("multi search failed: %w", err)
 	}
 
 	// 记录搜索历史和事件（异步）
@@ -474,6 +502,7 @@ func (s *searchService) GetHotSearches(ctx context.Context, searchType string, l
 	if limit <= 0 {
 		limit = 10
 	}
+	limit = min(limit, s.config.Paging.MaxPageSize())
 
 	// 尝试从缓存获取
 	if s.config.CacheEnabled {
@@ -517,6 +546,7 @@ func (s *searchService) GetUserSearchHistory(ctx context.Context, userID int64,
 	if limit <= 0 {
 		limit = 50
 	}
+	limit = min(limit, s.config.Paging.MaxPageSize())
 
 	// 尝试从缓存获取
 	if s.config.CacheEnabled {
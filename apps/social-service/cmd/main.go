@@ -2,12 +2,13 @@ package main
 
 import (
 	"context"
+	"fmt"
 	"log"
 	"os"
-	"time"
 
 	"github.com/gin-gonic/gin"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
 
 	"goim-social/api/rest"
 	"goim-social/apps/social-service/internal/converter"
@@ -15,7 +16,10 @@ import (
 	"goim-social/apps/social-service/internal/handler"
 	"goim-social/apps/social-service/internal/model"
 	"goim-social/apps/social-service/internal/service"
+	"goim-social/pkg/audit"
+	"goim-social/pkg/kafka"
 	"goim-social/pkg/middleware"
+	"goim-social/pkg/outbox"
 	"goim-social/pkg/server"
 	"goim-social/pkg/telemetry"
 )
@@ -39,7 +43,7 @@ func main() {
 
 	// 确保在程序退出时关闭OpenTelemetry
 	defer func() {
-		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		ctx, cancel := context.WithTimeout(context.Background(), otelConfig.ShutdownTimeout)
 		defer cancel()
 		if err := telemetry.ShutdownGlobal(ctx); err != nil {
 			log.Printf("Failed to shutdown OpenTelemetry: %v", err)
@@ -58,14 +62,37 @@ func main() {
 	// 获取PostgreSQL连接
 	postgreSQL := app.GetPostgreSQL()
 
+	// 成员唯一索引创建前清理历史重复记录，否则已有重复数据时迁移失败
+	deduped, err := dao.DedupGroupMembers(postgreSQL)
+	if err != nil {
+		panic("Failed to dedup group members: " + err.Error())
+	}
+	if deduped > 0 {
+		log.Printf("Removed %d duplicate group members before creating unique index", deduped)
+	}
+
+	// 好友关系唯一索引只约束未删除的关系，迁移前删除旧的全量唯一索引并清理重复关系
+	if err := dao.PrepareFriendUniqueIndex(postgreSQL); err != nil {
+		panic("Failed to prepare friend unique index: " + err.Error())
+	}
+
+	// 入群方式列上线前以is_public区分是否需要审批，加列时按原有语义回填
+	if err := dao.AddGroupJoinMode(postgreSQL); err != nil {
+		panic("Failed to add group join mode: " + err.Error())
+	}
+
 	// 自动迁移数据库表结构
 	if err := postgreSQL.AutoMigrate(
 		&model.Friend{},
 		&model.FriendApply{},
+		&model.UserBlock{},
 		&model.Group{},
 		&model.GroupMember{},
+		&model.GroupMembershipPeriod{},
 		&model.GroupInvitation{},
 		&model.GroupJoinRequest{},
+		&model.GroupAnnouncement{},
+		&outbox.Event{}, // 发件箱表
 	); err != nil {
 		panic("Failed to migrate database: " + err.Error())
 	}
@@ -73,8 +100,34 @@ func main() {
 	// 初始化DAO层
 	socialDAO := dao.NewSocialDAO(postgreSQL)
 
+	// 初始化审计记录器，管理操作异步写入共享审计记录，退出前写完队列
+	auditor := audit.NewRecorder(audit.NewStore(app.GetRedisClient(), app.GetConfig().Audit.RetentionDays), serviceName)
+	app.RegisterShutdownHook("audit", auditor.Close)
+
+	// 初始化用户服务客户端（批量添加好友时按用户名解析用户）
+	cfg := app.GetConfig()
+	userAddr := fmt.Sprintf("%s:%d", cfg.Services.UserService.Host, cfg.Services.UserService.Port)
+	userConn, err := grpc.NewClient(userAddr, grpc.WithTransportCredentials(insecure.NewCredentials()), middleware.DeadlineDialOption(cfg.Deadline))
+	if err != nil {
+		log.Fatalf("Failed to connect to user service: %v", err)
+	}
+	defer userConn.Close()
+
+	// 启动发件箱转发器，好友关系和群组索引事件与业务数据在同一事务中写入发件箱，由转发器发布到Kafka
+	relay := outbox.NewRelay(postgreSQL.GetDB(), serviceName, func() (outbox.Publisher, error) {
+		return kafka.InitReliableProducer(cfg.Kafka.Brokers)
+	}, cfg.Outbox)
+	relay.Start(context.Background())
+	app.RegisterShutdownHook("outbox-relay", relay.Close)
+
 	// 初始化Service层
-	socialService := service.NewService(socialDAO, app.GetRedisClient(), app.GetKafkaProducer(), app.GetLogger())
+	socialService := service.NewService(socialDAO, app.GetRedisClient(), app.GetKafkaProducer(), relay, app.GetLogger(), cfg.Social, cfg.Presence, auditor, rest.NewUserServiceClient(userConn))
+
+	// 定期清除超过保留期的已删除好友关系
+	go socialService.StartFriendHistoryPurger(context.Background())
+
+	// 启动时和之后定期从数据库重建拉黑关系缓存
+	go socialService.StartBlockCacheSync(context.Background())
 
 	// 初始化Converter层
 	socialConverter := converter.NewConverter()
@@ -4,6 +4,7 @@ import (
 	"goim-social/api/rest"
 	"goim-social/apps/social-service/internal/dao"
 	"goim-social/apps/social-service/internal/model"
+	"goim-social/pkg/httpx"
 )
 
 // Converter 转换器
@@ -24,6 +25,27 @@ func (c *Converter) BuildSendFriendRequestResponse(success bool, message string)
 	}
 }
 
+// BuildBatchAddFriendsResponse 构建批量添加好友响应
+func (c *Converter) BuildBatchAddFriendsResponse(success bool, message string, results []*model.FriendBatchResult) *rest.BatchAddFriendsResponse {
+	res := &rest.BatchAddFriendsResponse{
+		Success: success,
+		Message: message,
+		Results: make([]*rest.BatchAddFriendResult, 0, len(results)),
+	}
+	for _, result := range results {
+		if result.Status == model.FriendBatchStatusSent {
+			res.SentCount++
+		}
+		res.Results = append(res.Results, &rest.BatchAddFriendResult{
+			FriendId: result.FriendID,
+			Username: result.Username,
+			Status:   result.Status,
+			Message:  result.Message,
+		})
+	}
+	return res
+}
+
 // BuildAcceptFriendRequestResponse 构建接受好友申请响应
 func (c *Converter) BuildAcceptFriendRequestResponse(success bool, message string) *rest.RespondFriendApplyResponse {
 	return &rest.RespondFriendApplyResponse{
@@ -64,9 +86,13 @@ func (c *Converter) BuildGetFriendListResponse(success bool, message string, fri
 	}
 
 	return &rest.ListFriendsResponse{
-		Success: success,
-		Message: message,
-		Friends: friendInfos,
+		Success:    success,
+		Message:    message,
+		Friends:    friendInfos,
+		Total:      int32(len(friends)),
+		Page:       1,
+		PageSize:   int32(len(friends)),
+		Pagination: httpx.OffsetPage(1, int32(len(friends)), int64(len(friends))).Proto(),
 	}
 }
 
@@ -86,7 +112,9 @@ func (c *Converter) BuildGetFriendApplyListResponse(success bool, message string
 	}
 
 	return &rest.ListFriendApplyResponse{
-		Applies: applyInfos,
+		Applies:    applyInfos,
+		Total:      int32(len(applies)),
+		Pagination: httpx.OffsetPage(1, int32(len(applies)), int64(len(applies))).Proto(),
 	}
 }
 
@@ -97,17 +125,20 @@ func (c *Converter) BuildCreateGroupResponse(success bool, message string, group
 	var groupInfo *rest.GroupInfo
 	if group != nil {
 		groupInfo = &rest.GroupInfo{
-			Id:           group.ID,
-			Name:         group.Name,
-			Description:  group.Description,
-			Avatar:       group.Avatar,
-			OwnerId:      group.OwnerID,
-			MemberCount:  group.MemberCount,
-			MaxMembers:   group.MaxMembers,
-			IsPublic:     group.IsPublic,
-			Announcement: group.Announcement,
-			CreatedAt:    group.CreatedAt.Unix(),
-			UpdatedAt:    group.UpdatedAt.Unix(),
+			Id:                   group.ID,
+			Name:                 group.Name,
+			Description:          group.Description,
+			Avatar:               group.Avatar,
+			OwnerId:              group.OwnerID,
+			MemberCount:          group.MemberCount,
+			MaxMembers:           group.MaxMembers,
+			Tier:                 group.Tier,
+			IsPublic:             group.IsPublic,
+			Announcement:         group.Announcement,
+			CreatedAt:            group.CreatedAt.Unix(),
+			UpdatedAt:            group.UpdatedAt.Unix(),
+			NewMembersSeeHistory: group.NewMembersSeeHistory,
+			JoinMode:             group.JoinMode,
 		}
 	}
 
@@ -123,17 +154,21 @@ func (c *Converter) BuildGetGroupResponse(success bool, message string, group *m
 	var groupInfo *rest.GroupInfo
 	if group != nil {
 		groupInfo = &rest.GroupInfo{
-			Id:           group.ID,
-			Name:         group.Name,
-			Description:  group.Description,
-			Avatar:       group.Avatar,
-			OwnerId:      group.OwnerID,
-			MemberCount:  group.MemberCount,
-			MaxMembers:   group.MaxMembers,
-			IsPublic:     group.IsPublic,
-			Announcement: group.Announcement,
-			CreatedAt:    group.CreatedAt.Unix(),
-			UpdatedAt:    group.UpdatedAt.Unix(),
+			Id:                   group.ID,
+			Name:                 group.Name,
+			Description:          group.Description,
+			Avatar:               group.Avatar,
+			OwnerId:              group.OwnerID,
+			MemberCount:          group.MemberCount,
+			MaxMembers:           group.MaxMembers,
+			Tier:                 group.Tier,
+			IsPublic:             group.IsPublic,
+			Announcement:         group.Announcement,
+			CreatedAt:            group.CreatedAt.Unix(),
+			UpdatedAt:            group.UpdatedAt.Unix(),
+			Version:              group.Version,
+			NewMembersSeeHistory: group.NewMembersSeeHistory,
+			JoinMode:             group.JoinMode,
 		}
 	}
 
@@ -152,12 +187,17 @@ func (c *Converter) BuildUpdateGroupResponse(success bool, message string) *rest
 	}
 }
 
-// BuildJoinGroupResponse 构建加入群组响应
-func (c *Converter) BuildJoinGroupResponse(success bool, message string) *rest.JoinGroupResponse {
-	return &rest.JoinGroupResponse{
+// BuildJoinGroupResponse 构建加入群组响应，request不为nil表示已提交加群申请等待审批
+func (c *Converter) BuildJoinGroupResponse(success bool, message string, request *model.GroupJoinRequest) *rest.JoinGroupResponse {
+	res := &rest.JoinGroupResponse{
 		Success: success,
 		Message: message,
 	}
+	if request != nil {
+		res.Pending = true
+		res.RequestId = request.ID
+	}
+	return res
 }
 
 // BuildLeaveGroupResponse 构建离开群组响应
@@ -168,6 +208,30 @@ func (c *Converter) BuildLeaveGroupResponse(success bool, message string) *rest.
 	}
 }
 
+// BuildTransferGroupOwnerResponse 构建转让群主响应
+func (c *Converter) BuildTransferGroupOwnerResponse(success bool, message string) *rest.TransferGroupOwnerResponse {
+	return &rest.TransferGroupOwnerResponse{
+		Success: success,
+		Message: message,
+	}
+}
+
+// BuildSetGroupHistoryVisibilityResponse 构建设置历史消息可见范围响应
+func (c *Converter) BuildSetGroupHistoryVisibilityResponse(success bool, message string) *rest.SetGroupHistoryVisibilityResponse {
+	return &rest.SetGroupHistoryVisibilityResponse{
+		Success: success,
+		Message: message,
+	}
+}
+
+// BuildSetGroupJoinModeResponse 构建设置入群方式响应
+func (c *Converter) BuildSetGroupJoinModeResponse(success bool, message string) *rest.SetGroupJoinModeResponse {
+	return &rest.SetGroupJoinModeResponse{
+		Success: success,
+		Message: message,
+	}
+}
+
 // BuildGetGroupMembersResponse 构建获取群成员列表响应
 func (c *Converter) BuildGetGroupMembersResponse(success bool, message string, members []*model.GroupMember) *rest.GetGroupInfoResponse {
 	var memberInfos []*rest.GroupMemberInfo
@@ -191,6 +255,118 @@ func (c *Converter) BuildGetGroupMembersResponse(success bool, message string, m
 	}
 }
 
+// BuildGetGroupPresenceResponse 构建获取群组在线成员响应
+func (c *Converter) BuildGetGroupPresenceResponse(success bool, message string, presence *model.GroupPresence) *rest.GetGroupPresenceResponse {
+	resp := &rest.GetGroupPresenceResponse{
+		Success: success,
+		Message: message,
+	}
+
+	if presence != nil {
+		resp.OnlineCount = int32(presence.OnlineCount)
+		resp.OnlineUserIds = presence.OnlineUserIDs
+		resp.Page = int32(presence.Page)
+		resp.PageSize = int32(presence.PageSize)
+		resp.Pagination = httpx.OffsetPage(resp.Page, resp.PageSize, int64(presence.OnlineCount)).Proto()
+	}
+
+	return resp
+}
+
+// AnnouncementModelToProto 群公告历史模型转换为protobuf
+func (c *Converter) AnnouncementModelToProto(announcement *model.GroupAnnouncement) *rest.GroupAnnouncementInfo {
+	if announcement == nil {
+		return nil
+	}
+	return &rest.GroupAnnouncementInfo{
+		Id:        announcement.ID,
+		GroupId:   announcement.GroupID,
+		AuthorId:  announcement.AuthorID,
+		Content:   announcement.Content,
+		Active:    announcement.Active,
+		CreatedAt: announcement.CreatedAt.Unix(),
+	}
+}
+
+// BuildListAnnouncementsResponse 构建查询群公告历史响应
+func (c *Converter) BuildListAnnouncementsResponse(success bool, message string, announcements []*model.GroupAnnouncement, total int64, page, pageSize int32) *rest.ListAnnouncementsResponse {
+	infos := make([]*rest.GroupAnnouncementInfo, 0, len(announcements))
+	for _, announcement := range announcements {
+		infos = append(infos, c.AnnouncementModelToProto(announcement))
+	}
+
+	return &rest.ListAnnouncementsResponse{
+		Success:       success,
+		Message:       message,
+		Announcements: infos,
+		Total:         int32(total),
+		Page:          page,
+		PageSize:      pageSize,
+		Pagination:    httpx.OffsetPage(page, pageSize, total).Proto(),
+	}
+}
+
+// JoinRequestModelToProto 加群申请模型转换为protobuf
+func (c *Converter) JoinRequestModelToProto(request *model.GroupJoinRequest) *rest.GroupJoinRequestInfo {
+	if request == nil {
+		return nil
+	}
+	return &rest.GroupJoinRequestInfo{
+		Id:           request.ID,
+		GroupId:      request.GroupID,
+		UserId:       request.UserID,
+		Status:       request.Status,
+		Reason:       request.Reason,
+		ReviewerId:   request.ReviewerID,
+		ReviewReason: request.ReviewReason,
+		CreatedAt:    request.CreatedAt.Unix(),
+		UpdatedAt:    request.UpdatedAt.Unix(),
+	}
+}
+
+// BuildListJoinRequestsResponse 构建查询加群申请响应，申请列表不分页，一次返回全部申请
+func (c *Converter) BuildListJoinRequestsResponse(success bool, message string, requests []*model.GroupJoinRequest, pendingCount int64) *rest.ListJoinRequestsResponse {
+	infos := make([]*rest.GroupJoinRequestInfo, 0, len(requests))
+	for _, request := range requests {
+		infos = append(infos, c.JoinRequestModelToProto(request))
+	}
+
+	total := int32(len(requests))
+	return &rest.ListJoinRequestsResponse{
+		Success:      success,
+		Message:      message,
+		Requests:     infos,
+		PendingCount: int32(pendingCount),
+		Pagination:   httpx.OffsetPage(1, total, int64(total)).Proto(),
+	}
+}
+
+// BuildSetPresenceVisibilityResponse 构建设置在线状态可见性响应
+func (c *Converter) BuildSetPresenceVisibilityResponse(success bool, message string) *rest.SetPresenceVisibilityResponse {
+	return &rest.SetPresenceVisibilityResponse{
+		Success: success,
+		Message: message,
+	}
+}
+
+// BuildGetUserPresenceResponse 构建批量查询用户在线状态响应
+func (c *Converter) BuildGetUserPresenceResponse(success bool, message string, presences []*model.UserPresence) *rest.GetUserPresenceResponse {
+	infos := make([]*rest.UserPresenceInfo, 0, len(presences))
+	for _, presence := range presences {
+		infos = append(infos, &rest.UserPresenceInfo{
+			UserId:   presence.UserID,
+			Online:   presence.Online,
+			LastSeen: presence.LastSeen,
+		})
+	}
+
+	return &rest.GetUserPresenceResponse{
+		Success:   success,
+		Message:   message,
+		Presences: infos,
+	}
+}
+
 // ============ 统一社交关系查询转换 ============
 
 // BuildValidateFriendshipResponse 构建验证好友关系响应
@@ -238,6 +414,11 @@ func (c *Converter) BuildErrorSendFriendRequestResponse(message string) *rest.Ap
 	return c.BuildSendFriendRequestResponse(false, message)
 }
 
+// BuildErrorBatchAddFriendsResponse 构建批量添加好友错误响应
+func (c *Converter) BuildErrorBatchAddFriendsResponse(message string) *rest.BatchAddFriendsResponse {
+	return c.BuildBatchAddFriendsResponse(false, message, nil)
+}
+
 // BuildErrorAcceptFriendRequestResponse 构建接受好友申请错误响应
 func (c *Converter) BuildErrorAcceptFriendRequestResponse(message string) *rest.RespondFriendApplyResponse {
 	return c.BuildAcceptFriendRequestResponse(false, message)
@@ -280,7 +461,12 @@ func (c *Converter) BuildErrorUpdateGroupResponse(message string) *rest.GetGroup
 
 // BuildErrorJoinGroupResponse 构建加入群组错误响应
 func (c *Converter) BuildErrorJoinGroupResponse(message string) *rest.JoinGroupResponse {
-	return c.BuildJoinGroupResponse(false, message)
+	return c.BuildJoinGroupResponse(false, message, nil)
+}
+
+// BuildErrorListJoinRequestsResponse 构建查询加群申请错误响应
+func (c *Converter) BuildErrorListJoinRequestsResponse(message string) *rest.ListJoinRequestsResponse {
+	return c.BuildListJoinRequestsResponse(false, message, nil, 0)
 }
 
 // BuildErrorLeaveGroupResponse 构建离开群组错误响应
@@ -288,6 +474,21 @@ func (c *Converter) BuildErrorLeaveGroupResponse(message string) *rest.LeaveGrou
 	return c.BuildLeaveGroupResponse(false, message)
 }
 
+// BuildErrorTransferGroupOwnerResponse 构建转让群主错误响应
+func (c *Converter) BuildErrorTransferGroupOwnerResponse(message string) *rest.TransferGroupOwnerResponse {
+	return c.BuildTransferGroupOwnerResponse(false, message)
+}
+
+// BuildErrorSetGroupHistoryVisibilityResponse 构建设置历史消息可见范围错误响应
+func (c *Converter) BuildErrorSetGroupHistoryVisibilityResponse(message string) *rest.SetGroupHistoryVisibilityResponse {
+	return c.BuildSetGroupHistoryVisibilityResponse(false, message)
+}
+
+// BuildErrorSetGroupJoinModeResponse 构建设置入群方式错误响应
+func (c *Converter) BuildErrorSetGroupJoinModeResponse(message string) *rest.SetGroupJoinModeResponse {
+	return c.BuildSetGroupJoinModeResponse(false, message)
+}
+
 // BuildErrorGetGroupMembersResponse 构建获取群成员列表错误响应
 func (c *Converter) BuildErrorGetGroupMembersResponse(message string) *rest.GetGroupInfoResponse {
 	return c.BuildGetGroupMembersResponse(false, message, nil)
@@ -307,3 +508,23 @@ func (c *Converter) BuildErrorValidateGroupMembershipResponse(message string) *r
 func (c *Converter) BuildErrorGetUserSocialInfoResponse(message string) *rest.GetUserSocialInfoResponse {
 	return c.BuildGetUserSocialInfoResponse(false, message, nil)
 }
+
+// BuildErrorGetGroupPresenceResponse 构建获取群组在线成员错误响应
+func (c *Converter) BuildErrorGetGroupPresenceResponse(message string) *rest.GetGroupPresenceResponse {
+	return c.BuildGetGroupPresenceResponse(false, message, nil)
+}
+
+// BuildErrorListAnnouncementsResponse 构建查询群公告历史错误响应
+func (c *Converter) BuildErrorListAnnouncementsResponse(message string) *rest.ListAnnouncementsResponse {
+	return c.BuildListAnnouncementsResponse(false, message, nil, 0, 0, 0)
+}
+
+// BuildErrorSetPresenceVisibilityResponse 构建设置在线状态可见性错误响应
+func (c *Converter) BuildErrorSetPresenceVisibilityResponse(message string) *rest.SetPresenceVisibilityResponse {
+	return c.BuildSetPresenceVisibilityResponse(false, message)
+}
+
+// BuildErrorGetUserPresenceResponse 构建批量查询用户在线状态错误响应
+func (c *Converter) BuildErrorGetUserPresenceResponse(message string) *rest.GetUserPresenceResponse {
+	return c.BuildGetUserPresenceResponse(false, message, nil)
+}
@@ -2,44 +2,75 @@ package dao
 
 import (
 	"context"
+	"errors"
+	"time"
 
 	"goim-social/apps/social-service/internal/model"
+	"goim-social/pkg/outbox"
 )
 
+// ErrMemberExists 用户已是群成员，加群重复提交时服务层据此按成功处理
+var ErrMemberExists = errors.New("member already exists")
+
 // SocialDAO 社交数据访问接口
 type SocialDAO interface {
 	// 好友关系管理
 	CreateFriend(ctx context.Context, friend *model.Friend) error
-	DeleteFriend(ctx context.Context, userID, friendID int64) error
+	DeleteFriend(ctx context.Context, userID, friendID int64, events ...outbox.Builder) error
 	GetFriend(ctx context.Context, userID, friendID int64) (*model.Friend, error)
 	ListFriends(ctx context.Context, userID int64) ([]*model.Friend, error)
 	IsFriend(ctx context.Context, userID, friendID int64) (bool, error)
 	UpdateFriendRemark(ctx context.Context, userID, friendID int64, remark string) error
+	GetLastDeletedFriend(ctx context.Context, userID, friendID int64) (*model.Friend, error)
+	ListFriendHistory(ctx context.Context, userID, friendID int64) ([]*model.Friend, error)
+	PurgeDeletedFriends(ctx context.Context, before time.Time) (int64, error)
+
+	// 拉黑管理
+	CreateBlock(ctx context.Context, block *model.UserBlock) error
+	DeleteBlock(ctx context.Context, userID, blockedID int64) error
+	ListBlocks(ctx context.Context, userID int64) ([]*model.UserBlock, error)
+	ListBlocksAfter(ctx context.Context, afterID int64, limit int) ([]*model.UserBlock, error)
+	HasBlockBetween(ctx context.Context, userID, otherID int64) (bool, error)
 
 	// 好友申请管理
 	CreateFriendApply(ctx context.Context, apply *model.FriendApply) error
 	GetFriendApply(ctx context.Context, userID, applicantID int64) (*model.FriendApply, error)
 	ListFriendApply(ctx context.Context, userID int64) ([]*model.FriendApply, error)
 	UpdateFriendApplyStatus(ctx context.Context, userID, applicantID int64, status string) error
+	AcceptFriendApply(ctx context.Context, userID, applicantID int64, friends []*model.Friend, events ...outbox.Builder) error
 
 	// 群组管理
 	CreateGroup(ctx context.Context, group *model.Group) error
 	GetGroup(ctx context.Context, groupID int64) (*model.Group, error)
 	UpdateGroup(ctx context.Context, group *model.Group) error
+	UpdateGroupInfo(ctx context.Context, group *model.Group, expectedVersion int64, events ...outbox.Builder) (bool, error)
+	TransferGroupOwnership(ctx context.Context, groupID, ownerID, newOwnerID int64, events ...outbox.Builder) (bool, error)
+	UpdateGroupHistoryVisibility(ctx context.Context, groupID int64, visible bool) error
+	UpdateGroupJoinMode(ctx context.Context, groupID int64, joinMode string) error
 	DeleteGroup(ctx context.Context, groupID int64) error
 	SearchGroups(ctx context.Context, keyword string, isPublic bool, limit, offset int) ([]*model.Group, int64, error)
 	UpdateMemberCount(ctx context.Context, groupID int64, count int32) error
 
+	// 群公告历史
+	PublishAnnouncement(ctx context.Context, announcement *model.GroupAnnouncement) error
+	GetAnnouncement(ctx context.Context, groupID, announcementID int64) (*model.GroupAnnouncement, error)
+	GetActiveAnnouncement(ctx context.Context, groupID int64) (*model.GroupAnnouncement, error)
+	ListAnnouncements(ctx context.Context, groupID int64, limit, offset int) ([]*model.GroupAnnouncement, int64, error)
+	ActivateAnnouncement(ctx context.Context, announcement *model.GroupAnnouncement) error
+
 	// 群成员管理
 	AddMember(ctx context.Context, member *model.GroupMember) error
+	AddMemberWithinLimit(ctx context.Context, member *model.GroupMember) (bool, error)
 	RemoveMember(ctx context.Context, groupID, userID int64) error
 	GetMember(ctx context.Context, groupID, userID int64) (*model.GroupMember, error)
 	GetGroupMembers(ctx context.Context, groupID int64) ([]*model.GroupMember, error)
 	GetMemberIDs(ctx context.Context, groupID int64) ([]int64, error)
+	GetMemberIDsByRoles(ctx context.Context, groupID int64, roles []string) ([]int64, error)
 	IsMember(ctx context.Context, groupID, userID int64) (bool, error)
 	UpdateMemberRole(ctx context.Context, groupID, userID int64, role string) error
 	UpdateMemberNickname(ctx context.Context, groupID, userID int64, nickname string) error
 	GetUserGroups(ctx context.Context, userID int64) ([]*model.Group, error)
+	GetMembershipPeriods(ctx context.Context, userID, groupID int64) ([]*model.GroupMembershipPeriod, error)
 
 	// 群邀请管理
 	CreateInvitation(ctx context.Context, invitation *model.GroupInvitation) error
@@ -52,6 +83,9 @@ type SocialDAO interface {
 	GetJoinRequest(ctx context.Context, groupID, userID int64) (*model.GroupJoinRequest, error)
 	ListJoinRequests(ctx context.Context, groupID int64, status string) ([]*model.GroupJoinRequest, error)
 	UpdateJoinRequestStatus(ctx context.Context, requestID int64, status string) error
+	GetJoinRequestByID(ctx context.Context, requestID int64) (*model.GroupJoinRequest, error)
+	CountJoinRequests(ctx context.Context, groupID int64, status string) (int64, error)
+	ReviewJoinRequest(ctx context.Context, requestID int64, status string, reviewerID int64, reason string) (bool, error)
 
 	// 统一社交关系查询接口
 	ValidateFriendship(ctx context.Context, userID, friendID int64) (bool, error)
@@ -0,0 +1,120 @@
+package dao
+
+import (
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+
+	"goim-social/apps/social-service/internal/model"
+	"goim-social/pkg/database"
+)
+
+// DedupGroupMembers 在创建成员唯一索引uk_user_group之前清理重复的成员记录，须在AutoMigrate之前调用
+// 唯一索引上线前并发加群可能为同一用户插入多条记录，已有重复数据时建索引会失败导致服务无法启动。
+// 每组重复记录保留角色最高、最早加入的一条，并按实际成员记录重算受影响群组的成员数；
+// 成员表不存在或索引已创建时不做任何改动，返回删除的记录数
+func DedupGroupMembers(db *database.PostgreSQL) (int64, error) {
+	migrator := db.GetDB().Migrator()
+	if !migrator.HasTable(&model.GroupMember{}) || migrator.HasIndex(&model.GroupMember{}, "uk_user_group") {
+		return 0, nil
+	}
+
+	var deleted int64
+	err := db.GetDB().Transaction(func(tx *gorm.DB) error {
+		var groupIDs []int64
+		if err := tx.Model(&model.GroupMember{}).
+			Group("group_id, user_id").Having("COUNT(*) > 1").
+			Distinct().Pluck("group_id", &groupIDs).Error; err != nil {
+			return fmt.Errorf("failed to find duplicate members: %v", err)
+		}
+		if len(groupIDs) == 0 {
+			return nil
+		}
+
+		result := tx.Exec(`DELETE FROM group_members WHERE id IN (
+			SELECT id FROM (
+				SELECT id, ROW_NUMBER() OVER (
+					PARTITION BY group_id, user_id
+					ORDER BY CASE role WHEN ? THEN 0 WHEN ? THEN 1 ELSE 2 END, joined_at, id
+				) AS rn
+				FROM group_members WHERE group_id IN ?
+			) ranked WHERE rn > 1
+		)`, model.RoleOwner, model.RoleAdmin, groupIDs)
+		if result.Error != nil {
+			return fmt.Errorf("failed to delete duplicate members: %v", result.Error)
+		}
+		deleted = result.RowsAffected
+
+		if err := tx.Exec(`UPDATE groups SET member_count = (
+			SELECT COUNT(*) FROM group_members WHERE group_members.group_id = groups.id
+		) WHERE id IN ?`, groupIDs).Error; err != nil {
+			return fmt.Errorf("failed to recount group members: %v", err)
+		}
+		return nil
+	})
+	return deleted, err
+}
+
+// AddGroupJoinMode 为群组表添加入群方式列，须在AutoMigrate之前调用
+// 此前以is_public=false表示需要审批，加列后将这些群组设为需审批，保持原有的入群方式；
+// 群组表不存在或已有该列时不做任何改动
+func AddGroupJoinMode(db *database.PostgreSQL) error {
+	migrator := db.GetDB().Migrator()
+	if !migrator.HasTable(&model.Group{}) || migrator.HasColumn(&model.Group{}, "JoinMode") {
+		return nil
+	}
+
+	return db.GetDB().Transaction(func(tx *gorm.DB) error {
+		if err := tx.Migrator().AddColumn(&model.Group{}, "JoinMode"); err != nil {
+			return fmt.Errorf("failed to add join_mode column: %v", err)
+		}
+		if err := tx.Model(&model.Group{}).Where("is_public = ?", false).
+			Update("join_mode", model.GroupJoinModeApproval).Error; err != nil {
+			return fmt.Errorf("failed to backfill join_mode: %v", err)
+		}
+		return nil
+	})
+}
+
+// PrepareFriendUniqueIndex 为好友关系唯一索引uk_user_friend只约束未删除的关系做准备，须在AutoMigrate之前调用
+// 好友关系改为软删除后，覆盖全部记录的旧索引会让删除后再次添加好友违反唯一约束，旧索引存在时将其删除，由AutoMigrate按模型重建为部分索引；
+// 此前未建索引的库可能有并发添加产生的重复关系，建索引前保留最早的一条，其余标记为删除并计入好友历史。
+// 好友表不存在或已是部分索引时不做任何改动
+func PrepareFriendUniqueIndex(db *database.PostgreSQL) error {
+	if !db.GetDB().Migrator().HasTable(&model.Friend{}) {
+		return nil
+	}
+
+	var indexDefs []string
+	if err := db.GetDB().Raw(`SELECT indexdef FROM pg_indexes WHERE tablename = ? AND indexname = ?`,
+		model.Friend{}.TableName(), "uk_user_friend").Scan(&indexDefs).Error; err != nil {
+		return fmt.Errorf("failed to inspect friend unique index: %v", err)
+	}
+	if len(indexDefs) > 0 && strings.Contains(strings.ToUpper(indexDefs[0]), "WHERE") {
+		return nil
+	}
+
+	return db.GetDB().Transaction(func(tx *gorm.DB) error {
+		if len(indexDefs) > 0 {
+			// 以唯一约束方式创建的索引须删除约束，直接删除索引会失败
+			if err := tx.Exec(`ALTER TABLE friends DROP CONSTRAINT IF EXISTS uk_user_friend`).Error; err != nil {
+				return fmt.Errorf("failed to drop friend unique constraint: %v", err)
+			}
+			if err := tx.Exec(`DROP INDEX IF EXISTS uk_user_friend`).Error; err != nil {
+				return fmt.Errorf("failed to drop friend unique index: %v", err)
+			}
+			return nil
+		}
+
+		if err := tx.Exec(`UPDATE friends SET deleted_at = NOW() WHERE id IN (
+			SELECT id FROM (
+				SELECT id, ROW_NUMBER() OVER (PARTITION BY user_id, friend_id ORDER BY id) AS rn
+				FROM friends WHERE deleted_at IS NULL
+			) ranked WHERE rn > 1
+		)`).Error; err != nil {
+			return fmt.Errorf("failed to delete duplicate friends: %v", err)
+		}
+		return nil
+	})
+}
@@ -3,9 +3,14 @@ package dao
 import (
 	"context"
 	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 
 	"goim-social/apps/social-service/internal/model"
 	"goim-social/pkg/database"
+	"goim-social/pkg/outbox"
 )
 
 // socialDAO 社交数据访问对象
@@ -29,15 +34,17 @@ func (d *socialDAO) CreateFriend(ctx context.Context, friend *model.Friend) erro
 	return nil
 }
 
-// DeleteFriend 删除好友关系
-func (d *socialDAO) DeleteFriend(ctx context.Context, userID, friendID int64) error {
+// DeleteFriend 删除好友关系，软删除以保留好友历史，events与删除在同一事务中写入发件箱
+func (d *socialDAO) DeleteFriend(ctx context.Context, userID, friendID int64, events ...outbox.Builder) error {
 	db := d.db.GetDB()
-	// 删除双向关系
-	if err := db.WithContext(ctx).Where("(user_id = ? AND friend_id = ?) OR (user_id = ? AND friend_id = ?)",
-		userID, friendID, friendID, userID).Delete(&model.Friend{}).Error; err != nil {
-		return fmt.Errorf("failed to delete friend: %v", err)
-	}
-	return nil
+	return db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// 删除双向关系
+		if err := tx.Where("(user_id = ? AND friend_id = ?) OR (user_id = ? AND friend_id = ?)",
+			userID, friendID, friendID, userID).Delete(&model.Friend{}).Error; err != nil {
+			return fmt.Errorf("failed to delete friend: %v", err)
+		}
+		return outbox.Write(tx, events...)
+	})
 }
 
 // GetFriend 获取好友信息
@@ -86,6 +93,99 @@ func (d *socialDAO) UpdateFriendRemark(ctx context.Context, userID, friendID int
 	return nil
 }
 
+// GetLastDeletedFriend 获取最近一次被删除的好友关系，不存在时返回nil
+func (d *socialDAO) GetLastDeletedFriend(ctx context.Context, userID, friendID int64) (*model.Friend, error) {
+	var friend model.Friend
+	db := d.db.GetDB()
+	if err := db.WithContext(ctx).Unscoped().
+		Where("user_id = ? AND friend_id = ? AND deleted_at IS NOT NULL", userID, friendID).
+		Order("deleted_at DESC").First(&friend).Error; err != nil {
+		if err.Error() == "record not found" {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get deleted friend: %v", err)
+	}
+	return &friend, nil
+}
+
+// ListFriendHistory 获取与某个用户的全部好友关系记录，包含已删除的，按添加时间倒序
+func (d *socialDAO) ListFriendHistory(ctx context.Context, userID, friendID int64) ([]*model.Friend, error) {
+	var friends []*model.Friend
+	db := d.db.GetDB()
+	if err := db.WithContext(ctx).Unscoped().
+		Where("user_id = ? AND friend_id = ?", userID, friendID).
+		Order("created_at DESC").Find(&friends).Error; err != nil {
+		return nil, fmt.Errorf("failed to list friend history: %v", err)
+	}
+	return friends, nil
+}
+
+// PurgeDeletedFriends 彻底清除删除时间早于before的好友关系，返回清除的记录数
+func (d *socialDAO) PurgeDeletedFriends(ctx context.Context, before time.Time) (int64, error) {
+	db := d.db.GetDB()
+	result := db.WithContext(ctx).Unscoped().
+		Where("deleted_at IS NOT NULL AND deleted_at < ?", before).Delete(&model.Friend{})
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to purge deleted friends: %v", result.Error)
+	}
+	return result.RowsAffected, nil
+}
+
+// ============ 拉黑管理 ============
+
+// CreateBlock 创建拉黑关系，已拉黑时不重复创建
+func (d *socialDAO) CreateBlock(ctx context.Context, block *model.UserBlock) error {
+	db := d.db.GetDB()
+	if err := db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(block).Error; err != nil {
+		return fmt.Errorf("failed to create block: %v", err)
+	}
+	return nil
+}
+
+// DeleteBlock 删除拉黑关系，关系不存在时不报错
+func (d *socialDAO) DeleteBlock(ctx context.Context, userID, blockedID int64) error {
+	db := d.db.GetDB()
+	if err := db.WithContext(ctx).Where("user_id = ? AND blocked_id = ?", userID, blockedID).
+		Delete(&model.UserBlock{}).Error; err != nil {
+		return fmt.Errorf("failed to delete block: %v", err)
+	}
+	return nil
+}
+
+// ListBlocks 获取用户拉黑的用户列表，按拉黑时间倒序
+func (d *socialDAO) ListBlocks(ctx context.Context, userID int64) ([]*model.UserBlock, error) {
+	var blocks []*model.UserBlock
+	db := d.db.GetDB()
+	if err := db.WithContext(ctx).Where("user_id = ?", userID).
+		Order("created_at DESC").Find(&blocks).Error; err != nil {
+		return nil, fmt.Errorf("failed to list blocks: %v", err)
+	}
+	return blocks, nil
+}
+
+// ListBlocksAfter 按ID升序分页获取所有拉黑关系，afterID为上一页最后一条记录的ID
+func (d *socialDAO) ListBlocksAfter(ctx context.Context, afterID int64, limit int) ([]*model.UserBlock, error) {
+	var blocks []*model.UserBlock
+	db := d.db.GetDB()
+	if err := db.WithContext(ctx).Where("id > ?", afterID).
+		Order("id ASC").Limit(limit).Find(&blocks).Error; err != nil {
+		return nil, fmt.Errorf("failed to list blocks: %v", err)
+	}
+	return blocks, nil
+}
+
+// HasBlockBetween 判断两个用户之间是否存在任一方向的拉黑关系
+func (d *socialDAO) HasBlockBetween(ctx context.Context, userID, otherID int64) (bool, error) {
+	var count int64
+	db := d.db.GetDB()
+	if err := db.WithContext(ctx).Model(&model.UserBlock{}).
+		Where("(user_id = ? AND blocked_id = ?) OR (user_id = ? AND blocked_id = ?)", userID, otherID, otherID, userID).
+		Count(&count).Error; err != nil {
+		return false, fmt.Errorf("failed to check block: %v", err)
+	}
+	return count > 0, nil
+}
+
 // ============ 好友申请管理 ============
 
 // CreateFriendApply 创建好友申请
@@ -102,7 +202,7 @@ func (d *socialDAO) GetFriendApply(ctx context.Context, userID, applicantID int6
 	var apply model.FriendApply
 	db := d.db.GetDB()
 	if err := db.WithContext(ctx).Where("user_id = ? AND applicant_id = ?", userID, applicantID).
-		First(&apply).Error; err != nil {
+		Order("created_at DESC").First(&apply).Error; err != nil {
 		if err.Error() == "record not found" {
 			return nil, nil
 		}
@@ -133,15 +233,48 @@ func (d *socialDAO) UpdateFriendApplyStatus(ctx context.Context, userID, applica
 	return nil
 }
 
+// AcceptFriendApply 在同一事务中创建双向好友关系并将申请标记为已接受，events一并写入发件箱
+func (d *socialDAO) AcceptFriendApply(ctx context.Context, userID, applicantID int64, friends []*model.Friend, events ...outbox.Builder) error {
+	db := d.db.GetDB()
+	return db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, friend := range friends {
+			if err := tx.Create(friend).Error; err != nil {
+				return fmt.Errorf("failed to create friend: %v", err)
+			}
+		}
+		if err := tx.Model(&model.FriendApply{}).
+			Where("user_id = ? AND applicant_id = ?", userID, applicantID).
+			Update("status", model.FriendApplyStatusAccepted).Error; err != nil {
+			return fmt.Errorf("failed to update friend apply status: %v", err)
+		}
+		return outbox.Write(tx, events...)
+	})
+}
+
 // ============ 群组管理 ============
 
 // CreateGroup 创建群组
+// 带默认值true的布尔字段为false时，GORM创建时会改写成默认值，因此创建后在同一事务中按原值补写
 func (d *socialDAO) CreateGroup(ctx context.Context, group *model.Group) error {
+	isPublic, seeHistory := group.IsPublic, group.NewMembersSeeHistory
 	db := d.db.GetDB()
-	if err := db.WithContext(ctx).Create(group).Error; err != nil {
-		return fmt.Errorf("failed to create group: %v", err)
-	}
-	return nil
+	return db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(group).Error; err != nil {
+			return fmt.Errorf("failed to create group: %v", err)
+		}
+		if isPublic && seeHistory {
+			return nil
+		}
+
+		group.IsPublic, group.NewMembersSeeHistory = isPublic, seeHistory
+		if err := tx.Model(&model.Group{}).Where("id = ?", group.ID).Updates(map[string]interface{}{
+			"is_public":               isPublic,
+			"new_members_see_history": seeHistory,
+		}).Error; err != nil {
+			return fmt.Errorf("failed to create group: %v", err)
+		}
+		return nil
+	})
 }
 
 // GetGroup 获取群组信息
@@ -166,6 +299,97 @@ func (d *socialDAO) UpdateGroup(ctx context.Context, group *model.Group) error {
 	return nil
 }
 
+// UpdateGroupInfo 按版本号条件更新群名称、简介和头像并递增版本号，返回false表示版本已变化未更新
+// 只写资料字段，不会覆盖并发修改的成员数、公告等其他字段；更新成功时events在同一事务中写入发件箱
+func (d *socialDAO) UpdateGroupInfo(ctx context.Context, group *model.Group, expectedVersion int64, events ...outbox.Builder) (bool, error) {
+	updated := false
+	db := d.db.GetDB()
+	err := db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&model.Group{}).
+			Where("id = ? AND version = ?", group.ID, expectedVersion).
+			Updates(map[string]interface{}{
+				"name":        group.Name,
+				"description": group.Description,
+				"avatar":      group.Avatar,
+				"version":     expectedVersion + 1,
+				"updated_at":  group.UpdatedAt,
+			})
+		if result.Error != nil {
+			return fmt.Errorf("failed to update group info: %v", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+		group.Version = expectedVersion + 1
+		updated = true
+		return outbox.Write(tx, events...)
+	})
+	if err != nil {
+		group.Version = expectedVersion
+		return false, err
+	}
+	return updated, nil
+}
+
+// TransferGroupOwnership 将群主转让给群成员，原群主降为管理员，返回false表示群主已变化未转让
+// 转让成功时events在同一事务中写入发件箱
+func (d *socialDAO) TransferGroupOwnership(ctx context.Context, groupID, ownerID, newOwnerID int64, events ...outbox.Builder) (bool, error) {
+	transferred := false
+	db := d.db.GetDB()
+	err := db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&model.Group{}).
+			Where("id = ? AND owner_id = ?", groupID, ownerID).
+			Update("owner_id", newOwnerID)
+		if result.Error != nil {
+			return fmt.Errorf("failed to update group owner: %v", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+
+		result = tx.Model(&model.GroupMember{}).
+			Where("group_id = ? AND user_id = ?", groupID, newOwnerID).
+			Update("role", model.RoleOwner)
+		if result.Error != nil {
+			return fmt.Errorf("failed to promote new owner: %v", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return fmt.Errorf("new owner is not a group member")
+		}
+
+		if err := tx.Model(&model.GroupMember{}).
+			Where("group_id = ? AND user_id = ?", groupID, ownerID).
+			Update("role", model.RoleAdmin).Error; err != nil {
+			return fmt.Errorf("failed to demote previous owner: %v", err)
+		}
+		transferred = true
+		return outbox.Write(tx, events...)
+	})
+	return transferred && err == nil, err
+}
+
+// UpdateGroupHistoryVisibility 更新新成员能否查看入群前历史消息的设置
+func (d *socialDAO) UpdateGroupHistoryVisibility(ctx context.Context, groupID int64, visible bool) error {
+	db := d.db.GetDB()
+	if err := db.WithContext(ctx).Model(&model.Group{}).
+		Where("id = ?", groupID).
+		Update("new_members_see_history", visible).Error; err != nil {
+		return fmt.Errorf("failed to update group history visibility: %v", err)
+	}
+	return nil
+}
+
+// UpdateGroupJoinMode 更新入群方式
+func (d *socialDAO) UpdateGroupJoinMode(ctx context.Context, groupID int64, joinMode string) error {
+	db := d.db.GetDB()
+	if err := db.WithContext(ctx).Model(&model.Group{}).
+		Where("id = ?", groupID).
+		Update("join_mode", joinMode).Error; err != nil {
+		return fmt.Errorf("failed to update group join mode: %v", err)
+	}
+	return nil
+}
+
 // DeleteGroup 删除群组
 func (d *socialDAO) DeleteGroup(ctx context.Context, groupID int64) error {
 	db := d.db.GetDB()
@@ -211,27 +435,248 @@ func (d *socialDAO) UpdateMemberCount(ctx context.Context, groupID int64, count
 	return nil
 }
 
-// ============ 群成员管理 ============
+// ============ 群公告历史 ============
 
-// AddMember 添加群成员
-func (d *socialDAO) AddMember(ctx context.Context, member *model.GroupMember) error {
+// PublishAnnouncement 发布新公告，旧公告保留为历史，新公告成为唯一的生效公告
+func (d *socialDAO) PublishAnnouncement(ctx context.Context, announcement *model.GroupAnnouncement) error {
+	announcement.Active = true
+	db := d.db.GetDB()
+	return db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := deactivateAnnouncements(tx, announcement.GroupID); err != nil {
+			return err
+		}
+		if err := tx.Create(announcement).Error; err != nil {
+			return fmt.Errorf("failed to create announcement: %v", err)
+		}
+		return syncGroupAnnouncement(tx, announcement)
+	})
+}
+
+// GetAnnouncement 获取群内的一条历史公告
+func (d *socialDAO) GetAnnouncement(ctx context.Context, groupID, announcementID int64) (*model.GroupAnnouncement, error) {
+	var announcement model.GroupAnnouncement
+	db := d.db.GetDB()
+	if err := db.WithContext(ctx).
+		Where("id = ? AND group_id = ?", announcementID, groupID).First(&announcement).Error; err != nil {
+		if err.Error() == "record not found" {
+			return nil, fmt.Errorf("announcement not found")
+		}
+		return nil, fmt.Errorf("failed to get announcement: %v", err)
+	}
+	return &announcement, nil
+}
+
+// GetActiveAnnouncement 获取群当前生效的公告，没有生效公告时返回nil
+func (d *socialDAO) GetActiveAnnouncement(ctx context.Context, groupID int64) (*model.GroupAnnouncement, error) {
+	var announcements []*model.GroupAnnouncement
+	db := d.db.GetDB()
+	if err := db.WithContext(ctx).
+		Where("group_id = ? AND active = ?", groupID, true).
+		Order("created_at DESC, id DESC").Limit(1).Find(&announcements).Error; err != nil {
+		return nil, fmt.Errorf("failed to get active announcement: %v", err)
+	}
+	if len(announcements) == 0 {
+		return nil, nil
+	}
+	return announcements[0], nil
+}
+
+// ListAnnouncements 分页获取群公告历史，生效公告在前，其余按发布时间倒序
+func (d *socialDAO) ListAnnouncements(ctx context.Context, groupID int64, limit, offset int) ([]*model.GroupAnnouncement, int64, error) {
+	var announcements []*model.GroupAnnouncement
+	var total int64
+
+	db := d.db.GetDB()
+	query := db.WithContext(ctx).Model(&model.GroupAnnouncement{}).Where("group_id = ?", groupID)
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count announcements: %v", err)
+	}
+
+	if err := query.Order("active DESC, created_at DESC, id DESC").
+		Limit(limit).Offset(offset).Find(&announcements).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to list announcements: %v", err)
+	}
+
+	return announcements, total, nil
+}
+
+// ActivateAnnouncement 将一条历史公告恢复为生效公告
+func (d *socialDAO) ActivateAnnouncement(ctx context.Context, announcement *model.GroupAnnouncement) error {
 	db := d.db.GetDB()
-	if err := db.WithContext(ctx).Create(member).Error; err != nil {
-		return fmt.Errorf("failed to add member: %v", err)
+	return db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := deactivateAnnouncements(tx, announcement.GroupID); err != nil {
+			return err
+		}
+		if err := tx.Model(&model.GroupAnnouncement{}).
+			Where("id = ?", announcement.ID).Update("active", true).Error; err != nil {
+			return fmt.Errorf("failed to activate announcement: %v", err)
+		}
+		announcement.Active = true
+		return syncGroupAnnouncement(tx, announcement)
+	})
+}
+
+// deactivateAnnouncements 取消群内当前生效的公告
+func deactivateAnnouncements(tx *gorm.DB, groupID int64) error {
+	if err := tx.Model(&model.GroupAnnouncement{}).
+		Where("group_id = ? AND active = ?", groupID, true).Update("active", false).Error; err != nil {
+		return fmt.Errorf("failed to deactivate announcements: %v", err)
+	}
+	return nil
+}
+
+// syncGroupAnnouncement 将生效公告同步到群组信息，群组信息中始终展示当前公告
+func syncGroupAnnouncement(tx *gorm.DB, announcement *model.GroupAnnouncement) error {
+	if err := tx.Model(&model.Group{}).Where("id = ?", announcement.GroupID).
+		Update("announcement", announcement.Content).Error; err != nil {
+		return fmt.Errorf("failed to update group announcement: %v", err)
 	}
 	return nil
 }
 
-// RemoveMember 移除群成员
+// ============ 群成员管理 ============
+
+// AddMember 添加群成员并记录成员身份时段
+func (d *socialDAO) AddMember(ctx context.Context, member *model.GroupMember) error {
+	db := d.db.GetDB()
+	return db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(member).Error; err != nil {
+			return fmt.Errorf("failed to add member: %v", err)
+		}
+		return openMembershipPeriod(tx, member)
+	})
+}
+
+// AddMemberWithinLimit 在成员上限内添加群成员并递增成员数，返回false表示群组已满，已是成员时返回ErrMemberExists
+// 带上限条件的递增与插入成员在同一事务中，递增持有群组行锁，并发加群不会超出上限；
+// 成员记录有唯一约束，重复加入时回滚递增，成员数不会因重试或重复请求而偏大
+func (d *socialDAO) AddMemberWithinLimit(ctx context.Context, member *model.GroupMember) (bool, error) {
+	added := false
+	db := d.db.GetDB()
+	err := db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&model.Group{}).
+			Where("id = ? AND member_count < max_members", member.GroupID).
+			Update("member_count", gorm.Expr("member_count + 1"))
+		if result.Error != nil {
+			return fmt.Errorf("failed to increase member count: %v", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+		result = tx.Clauses(clause.OnConflict{DoNothing: true}).Create(member)
+		if result.Error != nil {
+			return fmt.Errorf("failed to add member: %v", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return ErrMemberExists
+		}
+		if err := openMembershipPeriod(tx, member); err != nil {
+			return err
+		}
+		added = true
+		return nil
+	})
+	return added, err
+}
+
+// RemoveMember 移除群成员、递减成员数并结束当前的成员身份时段
 func (d *socialDAO) RemoveMember(ctx context.Context, groupID, userID int64) error {
 	db := d.db.GetDB()
-	if err := db.WithContext(ctx).Where("group_id = ? AND user_id = ?", groupID, userID).
-		Delete(&model.GroupMember{}).Error; err != nil {
-		return fmt.Errorf("failed to remove member: %v", err)
+	return db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var member model.GroupMember
+		if err := tx.Where("group_id = ? AND user_id = ?", groupID, userID).
+			First(&member).Error; err != nil {
+			if err.Error() == "record not found" {
+				return nil
+			}
+			return fmt.Errorf("failed to get member: %v", err)
+		}
+
+		result := tx.Where("group_id = ? AND user_id = ?", groupID, userID).Delete(&model.GroupMember{})
+		if result.Error != nil {
+			return fmt.Errorf("failed to remove member: %v", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+		if err := tx.Model(&model.Group{}).
+			Where("id = ? AND member_count > 0", groupID).
+			Update("member_count", gorm.Expr("member_count - 1")).Error; err != nil {
+			return fmt.Errorf("failed to decrease member count: %v", err)
+		}
+
+		// 功能上线前入群的成员没有时段记录，以成员表的入群时间补一条已结束的时段
+		now := time.Now()
+		result = tx.Model(&model.GroupMembershipPeriod{}).
+			Where("group_id = ? AND user_id = ? AND left_at IS NULL", groupID, userID).
+			Update("left_at", now)
+		if result.Error != nil {
+			return fmt.Errorf("failed to close membership period: %v", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			if err := tx.Create(&model.GroupMembershipPeriod{
+				GroupID:  groupID,
+				UserID:   userID,
+				JoinedAt: member.JoinedAt,
+				LeftAt:   &now,
+			}).Error; err != nil {
+				return fmt.Errorf("failed to record membership period: %v", err)
+			}
+		}
+		return nil
+	})
+}
+
+// openMembershipPeriod 在添加成员的事务中记录新的成员身份时段
+func openMembershipPeriod(tx *gorm.DB, member *model.GroupMember) error {
+	joinedAt := member.JoinedAt
+	if joinedAt.IsZero() {
+		joinedAt = time.Now()
+	}
+	if err := tx.Create(&model.GroupMembershipPeriod{
+		GroupID:  member.GroupID,
+		UserID:   member.UserID,
+		JoinedAt: joinedAt,
+	}).Error; err != nil {
+		return fmt.Errorf("failed to record membership period: %v", err)
 	}
 	return nil
 }
 
+// GetMembershipPeriods 获取用户的成员身份时段并填充群组的历史消息可见设置，groupID为0时返回所有群组的时段
+// 功能上线前入群且仍在群内的成员没有时段记录，以成员表的入群时间补齐当前时段
+func (d *socialDAO) GetMembershipPeriods(ctx context.Context, userID, groupID int64) ([]*model.GroupMembershipPeriod, error) {
+	db := d.db.GetDB().WithContext(ctx)
+
+	var periods []*model.GroupMembershipPeriod
+	query := db.Table("group_membership_periods").
+		Select("group_membership_periods.*, COALESCE(groups.new_members_see_history, false) AS history_visible").
+		Joins("LEFT JOIN groups ON groups.id = group_membership_periods.group_id").
+		Where("group_membership_periods.user_id = ?", userID)
+	if groupID > 0 {
+		query = query.Where("group_membership_periods.group_id = ?", groupID)
+	}
+	if err := query.Order("group_membership_periods.group_id ASC, group_membership_periods.joined_at ASC").
+		Find(&periods).Error; err != nil {
+		return nil, fmt.Errorf("failed to get membership periods: %v", err)
+	}
+
+	var legacy []*model.GroupMembershipPeriod
+	legacyQuery := db.Table("group_members").
+		Select("group_members.group_id, group_members.user_id, group_members.joined_at, COALESCE(groups.new_members_see_history, false) AS history_visible").
+		Joins("LEFT JOIN groups ON groups.id = group_members.group_id").
+		Where("group_members.user_id = ?", userID).
+		Where("NOT EXISTS (SELECT 1 FROM group_membership_periods p WHERE p.group_id = group_members.group_id AND p.user_id = group_members.user_id AND p.left_at IS NULL)")
+	if groupID > 0 {
+		legacyQuery = legacyQuery.Where("group_members.group_id = ?", groupID)
+	}
+	if err := legacyQuery.Find(&legacy).Error; err != nil {
+		return nil, fmt.Errorf("failed to get legacy memberships: %v", err)
+	}
+	return append(periods, legacy...), nil
+}
+
 // GetMember 获取群成员信息
 func (d *socialDAO) GetMember(ctx context.Context, groupID, userID int64) (*model.GroupMember, error) {
 	var member model.GroupMember
@@ -267,6 +712,17 @@ func (d *socialDAO) GetMemberIDs(ctx context.Context, groupID int64) ([]int64, e
 	return memberIDs, nil
 }
 
+// GetMemberIDsByRoles 获取群内指定角色的成员ID列表
+func (d *socialDAO) GetMemberIDsByRoles(ctx context.Context, groupID int64, roles []string) ([]int64, error) {
+	var memberIDs []int64
+	db := d.db.GetDB()
+	if err := db.WithContext(ctx).Model(&model.GroupMember{}).
+		Where("group_id = ? AND role IN ?", groupID, roles).Pluck("user_id", &memberIDs).Error; err != nil {
+		return nil, fmt.Errorf("failed to get member IDs by roles: %v", err)
+	}
+	return memberIDs, nil
+}
+
 // IsMember 检查是否为群成员
 func (d *socialDAO) IsMember(ctx context.Context, groupID, userID int64) (bool, error) {
 	var count int64
@@ -371,12 +827,12 @@ func (d *socialDAO) CreateJoinRequest(ctx context.Context, request *model.GroupJ
 	return nil
 }
 
-// GetJoinRequest 获取加群申请
+// GetJoinRequest 获取用户对群的最近一次加群申请
 func (d *socialDAO) GetJoinRequest(ctx context.Context, groupID, userID int64) (*model.GroupJoinRequest, error) {
 	var request model.GroupJoinRequest
 	db := d.db.GetDB()
 	if err := db.WithContext(ctx).Where("group_id = ? AND user_id = ?", groupID, userID).
-		First(&request).Error; err != nil {
+		Order("created_at DESC").First(&request).Error; err != nil {
 		if err.Error() == "record not found" {
 			return nil, nil
 		}
@@ -409,6 +865,46 @@ func (d *socialDAO) UpdateJoinRequestStatus(ctx context.Context, requestID int64
 	return nil
 }
 
+// GetJoinRequestByID 根据ID获取加群申请
+func (d *socialDAO) GetJoinRequestByID(ctx context.Context, requestID int64) (*model.GroupJoinRequest, error) {
+	var request model.GroupJoinRequest
+	db := d.db.GetDB()
+	if err := db.WithContext(ctx).Where("id = ?", requestID).First(&request).Error; err != nil {
+		if err.Error() == "record not found" {
+			return nil, fmt.Errorf("join request not found")
+		}
+		return nil, fmt.Errorf("failed to get join request: %v", err)
+	}
+	return &request, nil
+}
+
+// CountJoinRequests 统计群内指定状态的加群申请数
+func (d *socialDAO) CountJoinRequests(ctx context.Context, groupID int64, status string) (int64, error) {
+	var count int64
+	db := d.db.GetDB()
+	if err := db.WithContext(ctx).Model(&model.GroupJoinRequest{}).
+		Where("group_id = ? AND status = ?", groupID, status).Count(&count).Error; err != nil {
+		return 0, fmt.Errorf("failed to count join requests: %v", err)
+	}
+	return count, nil
+}
+
+// ReviewJoinRequest 处理待处理的加群申请，申请已被处理时返回false
+func (d *socialDAO) ReviewJoinRequest(ctx context.Context, requestID int64, status string, reviewerID int64, reason string) (bool, error) {
+	db := d.db.GetDB()
+	result := db.WithContext(ctx).Model(&model.GroupJoinRequest{}).
+		Where("id = ? AND status = ?", requestID, model.JoinRequestStatusPending).
+		Updates(map[string]interface{}{
+			"status":        status,
+			"reviewer_id":   reviewerID,
+			"review_reason": reason,
+		})
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to review join request: %v", result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
+
 // ============ 统一社交关系查询接口 ============
 
 // ValidateFriendship 验证好友关系
@@ -4,6 +4,7 @@ import (
 	"github.com/gin-gonic/gin"
 
 	"goim-social/api/rest"
+	"goim-social/apps/social-service/internal/service"
 	tracecontext "goim-social/pkg/context"
 	"goim-social/pkg/httpx"
 	"goim-social/pkg/logger"
@@ -28,13 +29,13 @@ func (h *HTTPHandler) SendFriendRequest(c *gin.Context) {
 	// 将业务信息添加到context
 	ctx = tracecontext.WithUserID(ctx, req.UserId)
 
-	err = h.svc.SendFriendRequest(ctx, req.UserId, req.FriendId, req.Remark)
+	err = h.svc.SendFriendRequest(ctx, req.UserId, req.FriendId, req.Remark, req.RestoreRemark)
 	if err != nil {
 		h.logger.Error(ctx, "Send friend request failed",
 			logger.F("error", err.Error()),
 			logger.F("userID", req.UserId),
 			logger.F("friendID", req.FriendId))
-		resp = &rest.ApplyFriendResponse{Success: false, Message: err.Error()}
+		resp = &rest.ApplyFriendResponse{Success: false, Message: err.Error(), Reason: service.FriendRequestReason(err)}
 	} else {
 		h.logger.Info(ctx, "Send friend request successful",
 			logger.F("userID", req.UserId),
@@ -45,6 +46,39 @@ func (h *HTTPHandler) SendFriendRequest(c *gin.Context) {
 	httpx.WriteObject(c, resp, err)
 }
 
+// BatchAddFriends 批量添加好友
+func (h *HTTPHandler) BatchAddFriends(c *gin.Context) {
+	var (
+		ctx  = c.Request.Context()
+		req  rest.BatchAddFriendsRequest
+		resp *rest.BatchAddFriendsResponse
+		err  error
+	)
+
+	if err = c.Bind(&req); err != nil {
+		h.logger.Error(ctx, "Invalid batch add friends request", logger.F("error", err.Error()))
+		resp = h.converter.BuildErrorBatchAddFriendsResponse("Invalid request format")
+		httpx.WriteObject(c, resp, err)
+		return
+	}
+
+	// 将业务信息添加到context
+	ctx = tracecontext.WithUserID(ctx, req.UserId)
+
+	results, err := h.svc.BatchAddFriends(ctx, req.UserId, req.FriendIds, req.Usernames, req.Remark, req.ClientRequestId)
+	if err != nil {
+		h.logger.Error(ctx, "Batch add friends failed",
+			logger.F("error", err.Error()),
+			logger.F("userID", req.UserId),
+			logger.F("clientRequestID", req.ClientRequestId))
+		resp = h.converter.BuildErrorBatchAddFriendsResponse(err.Error())
+	} else {
+		resp = h.converter.BuildBatchAddFriendsResponse(true, "批量添加处理完成", results)
+	}
+
+	httpx.WriteObject(c, resp, err)
+}
+
 // AcceptFriendRequest 接受好友申请
 func (h *HTTPHandler) AcceptFriendRequest(c *gin.Context) {
 	var (
@@ -64,7 +98,7 @@ func (h *HTTPHandler) AcceptFriendRequest(c *gin.Context) {
 	// 将业务信息添加到context
 	ctx = tracecontext.WithUserID(ctx, req.UserId)
 
-	err = h.svc.AcceptFriendRequest(ctx, req.UserId, req.ApplicantId, "")
+	err = h.svc.AcceptFriendRequest(ctx, req.UserId, req.ApplicantId, "", req.RestoreRemark)
 	if err != nil {
 		h.logger.Error(ctx, "Accept friend request failed",
 			logger.F("error", err.Error()),
@@ -194,11 +228,16 @@ func (h *HTTPHandler) GetFriendList(c *gin.Context) {
 			}
 		}
 
+		// 好友列表不分页，一次返回全部好友
+		total := int32(len(friends))
 		resp = &rest.ListFriendsResponse{
-			Success: true,
-			Message: "获取好友列表成功",
-			Friends: friendInfos,
-			Total:   int32(len(friends)),
+			Success:    true,
+			Message:    "获取好友列表成功",
+			Friends:    friendInfos,
+			Total:      total,
+			Page:       1,
+			PageSize:   total,
+			Pagination: httpx.OffsetPage(1, total, int64(total)).Proto(),
 		}
 	}
 
@@ -246,7 +285,64 @@ func (h *HTTPHandler) GetFriendApplyList(c *gin.Context) {
 			}
 		}
 
-		resp = &rest.ListFriendApplyResponse{Applies: applyInfos}
+		// 申请列表不分页，一次返回全部申请
+		total := int32(len(applies))
+		resp = &rest.ListFriendApplyResponse{
+			Applies:    applyInfos,
+			Total:      total,
+			Pagination: httpx.OffsetPage(1, total, int64(total)).Proto(),
+		}
+	}
+
+	httpx.WriteObject(c, resp, err)
+}
+
+// GetFriendHistory 获取好友关系历史
+func (h *HTTPHandler) GetFriendHistory(c *gin.Context) {
+	var (
+		ctx  = c.Request.Context()
+		req  rest.GetFriendHistoryRequest
+		resp *rest.GetFriendHistoryResponse
+		err  error
+	)
+
+	if err = c.Bind(&req); err != nil {
+		h.logger.Error(ctx, "Invalid get friend history request", logger.F("error", err.Error()))
+		resp = &rest.GetFriendHistoryResponse{Success: false, Message: "Invalid request format"}
+		httpx.WriteObject(c, resp, err)
+		return
+	}
+
+	// 将业务信息添加到context
+	ctx = tracecontext.WithUserID(ctx, req.UserId)
+
+	history, err := h.svc.GetFriendHistory(ctx, req.UserId, req.FriendId)
+	if err != nil {
+		h.logger.Error(ctx, "Get friend history failed",
+			logger.F("error", err.Error()),
+			logger.F("userID", req.UserId),
+			logger.F("friendID", req.FriendId))
+		resp = &rest.GetFriendHistoryResponse{Success: false, Message: err.Error()}
+	} else {
+		periods := make([]*rest.FriendshipPeriod, len(history))
+		for i, friend := range history {
+			periods[i] = &rest.FriendshipPeriod{
+				Remark:    friend.Remark,
+				CreatedAt: friend.CreatedAt.Unix(),
+			}
+			if friend.DeletedAt.Valid {
+				periods[i].DeletedAt = friend.DeletedAt.Time.Unix()
+			}
+		}
+
+		// 关系历史不分页，一次返回全部时段
+		total := int32(len(periods))
+		resp = &rest.GetFriendHistoryResponse{
+			Success:    true,
+			Message:    "获取好友关系历史成功",
+			Periods:    periods,
+			Pagination: httpx.OffsetPage(1, total, int64(total)).Proto(),
+		}
 	}
 
 	httpx.WriteObject(c, resp, err)
@@ -82,6 +82,24 @@ func (h *GRPCHandler) validateGroupMemberImpl(ctx context.Context, req *rest.Val
 		}, nil
 	}
 
+	// 成员角色和入群时间供调用方做管理权限判断和可见范围限制，查询失败不影响成员身份结果
+	var (
+		role     string
+		joinedAt int64
+	)
+	if isMember {
+		member, err := h.svc.GetGroupMember(ctx, req.GroupId, req.UserId)
+		if err != nil {
+			h.logger.Warn(ctx, "Failed to get group member",
+				logger.F("error", err.Error()),
+				logger.F("userID", req.UserId),
+				logger.F("groupID", req.GroupId))
+		} else if member != nil {
+			role = member.Role
+			joinedAt = member.JoinedAt.Unix()
+		}
+	}
+
 	span.SetAttributes(attribute.Bool("group.is_member", isMember))
 	span.SetStatus(codes.Ok, "group membership validated successfully")
 
@@ -94,5 +112,105 @@ func (h *GRPCHandler) validateGroupMemberImpl(ctx context.Context, req *rest.Val
 		Success:  true,
 		Message:  "验证群成员身份成功",
 		IsMember: isMember,
+		Role:     role,
+		JoinedAt: joinedAt,
 	}, nil
 }
+
+// getMembershipPeriodsImpl 获取群成员身份时段实现
+func (h *GRPCHandler) getMembershipPeriodsImpl(ctx context.Context, req *rest.GetMembershipPeriodsRequest) (*rest.GetMembershipPeriodsResponse, error) {
+	// 开始OpenTelemetry span
+	ctx, span := telemetry.StartSpan(ctx, "social.grpc.GetMembershipPeriods")
+	defer span.End()
+
+	// 设置span属性
+	span.SetAttributes(
+		attribute.Int64("group.user_id", req.UserId),
+		attribute.Int64("group.id", req.GroupId),
+	)
+
+	// 将业务信息添加到context
+	ctx = tracecontext.WithUserID(ctx, req.UserId)
+	if req.GroupId > 0 {
+		ctx = tracecontext.WithGroupID(ctx, req.GroupId)
+	}
+
+	periods, err := h.svc.GetMembershipPeriods(ctx, req.UserId, req.GroupId)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to get membership periods")
+		h.logger.Error(ctx, "Failed to get membership periods",
+			logger.F("error", err.Error()),
+			logger.F("userID", req.UserId),
+			logger.F("groupID", req.GroupId))
+		return &rest.GetMembershipPeriodsResponse{
+			Success: false,
+			Message: err.Error(),
+		}, nil
+	}
+
+	result := make([]*rest.GroupMembershipPeriod, 0, len(periods))
+	for _, period := range periods {
+		item := &rest.GroupMembershipPeriod{
+			GroupId:        period.GroupID,
+			JoinedAt:       period.JoinedAt.UnixMilli(),
+			HistoryVisible: period.HistoryVisible,
+		}
+		if period.LeftAt != nil {
+			item.LeftAt = period.LeftAt.UnixMilli()
+		}
+		result = append(result, item)
+	}
+
+	span.SetAttributes(attribute.Int("group.period_count", len(result)))
+	span.SetStatus(codes.Ok, "membership periods retrieved successfully")
+
+	return &rest.GetMembershipPeriodsResponse{
+		Success: true,
+		Message: "获取成员身份时段成功",
+		Periods: result,
+	}, nil
+}
+
+// getActiveAnnouncementImpl 获取群当前生效的公告实现
+func (h *GRPCHandler) getActiveAnnouncementImpl(ctx context.Context, req *rest.GetActiveAnnouncementRequest) (*rest.GetActiveAnnouncementResponse, error) {
+	// 开始OpenTelemetry span
+	ctx, span := telemetry.StartSpan(ctx, "social.grpc.GetActiveAnnouncement")
+	defer span.End()
+
+	// 设置span属性
+	span.SetAttributes(attribute.Int64("group.id", req.GroupId))
+
+	// 将业务信息添加到context
+	ctx = tracecontext.WithGroupID(ctx, req.GroupId)
+
+	announcement, err := h.svc.GetActiveAnnouncement(ctx, req.GroupId)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to get active announcement")
+		h.logger.Error(ctx, "Failed to get active announcement",
+			logger.F("error", err.Error()),
+			logger.F("groupID", req.GroupId))
+		return &rest.GetActiveAnnouncementResponse{
+			Success: false,
+			Message: err.Error(),
+		}, nil
+	}
+
+	resp := &rest.GetActiveAnnouncementResponse{
+		Success: true,
+		Message: "获取群公告成功",
+	}
+	if announcement != nil {
+		resp.Announcement = &rest.ActiveAnnouncement{
+			Id:        announcement.ID,
+			GroupId:   announcement.GroupID,
+			AuthorId:  announcement.AuthorID,
+			Content:   announcement.Content,
+			CreatedAt: announcement.CreatedAt.Unix(),
+		}
+	}
+
+	span.SetStatus(codes.Ok, "active announcement retrieved successfully")
+	return resp, nil
+}
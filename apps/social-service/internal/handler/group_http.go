@@ -4,6 +4,7 @@ import (
 	"github.com/gin-gonic/gin"
 
 	"goim-social/api/rest"
+	"goim-social/apps/social-service/internal/service"
 	tracecontext "goim-social/pkg/context"
 	"goim-social/pkg/httpx"
 	"goim-social/pkg/logger"
@@ -28,7 +29,7 @@ func (h *HTTPHandler) CreateGroup(c *gin.Context) {
 	// 将业务信息添加到context
 	ctx = tracecontext.WithUserID(ctx, req.OwnerId)
 
-	group, err := h.svc.CreateGroup(ctx, req.OwnerId, req.Name, req.Description, req.Avatar, req.IsPublic, req.MaxMembers, req.MemberIds)
+	group, err := h.svc.CreateGroup(ctx, req.OwnerId, req.Name, req.Description, req.Avatar, req.IsPublic, req.JoinMode, req.MaxMembers, req.Tier, req.MemberIds)
 	if err != nil {
 		h.logger.Error(ctx, "Create group failed",
 			logger.F("error", err.Error()),
@@ -82,7 +83,9 @@ func (h *HTTPHandler) GetGroup(c *gin.Context) {
 			OwnerId:      group.OwnerID,
 			MemberCount:  group.MemberCount,
 			MaxMembers:   group.MaxMembers,
+			Tier:         group.Tier,
 			IsPublic:     group.IsPublic,
+			JoinMode:     group.JoinMode,
 			Announcement: group.Announcement,
 			CreatedAt:    group.CreatedAt.Unix(),
 			UpdatedAt:    group.UpdatedAt.Unix(),
@@ -134,6 +137,140 @@ func (h *HTTPHandler) UpdateGroup(c *gin.Context) {
 	httpx.WriteObject(c, res, err)
 }
 
+// UpdateGroupInfo 修改群名称、简介和头像
+func (h *HTTPHandler) UpdateGroupInfo(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var req rest.UpdateGroupInfoRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Error(ctx, "Invalid update group info request", logger.F("error", err.Error()))
+		res := h.converter.BuildErrorUpdateGroupResponse("Invalid request format")
+		httpx.WriteObject(c, res, err)
+		return
+	}
+
+	// 将业务信息添加到context
+	ctx = tracecontext.WithGroupID(ctx, req.GroupId)
+	ctx = tracecontext.WithUserID(ctx, req.UserId)
+
+	group, err := h.svc.UpdateGroupInfo(ctx, req.GroupId, req.UserId, service.GroupInfoUpdate{
+		Name:        req.Name,
+		Description: req.Description,
+		Avatar:      req.Avatar,
+		Version:     req.Version,
+	})
+
+	var res *rest.GetGroupInfoResponse
+	if err != nil {
+		h.logger.Error(ctx, "Update group info failed",
+			logger.F("error", err.Error()),
+			logger.F("groupID", req.GroupId),
+			logger.F("userID", req.UserId))
+		res = h.converter.BuildErrorUpdateGroupResponse(err.Error())
+	} else {
+		res = h.converter.BuildGetGroupResponse(true, "修改群资料成功", group)
+	}
+
+	httpx.WriteObject(c, res, err)
+}
+
+// TransferGroupOwner 转让群主
+func (h *HTTPHandler) TransferGroupOwner(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var req rest.TransferGroupOwnerRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Error(ctx, "Invalid transfer group owner request", logger.F("error", err.Error()))
+		res := h.converter.BuildErrorTransferGroupOwnerResponse("Invalid request format")
+		httpx.WriteObject(c, res, err)
+		return
+	}
+
+	// 将业务信息添加到context
+	ctx = tracecontext.WithGroupID(ctx, req.GroupId)
+	ctx = tracecontext.WithUserID(ctx, req.UserId)
+
+	err := h.svc.TransferGroupOwnership(ctx, req.GroupId, req.UserId, req.NewOwnerId)
+
+	var res *rest.TransferGroupOwnerResponse
+	if err != nil {
+		h.logger.Error(ctx, "Transfer group owner failed",
+			logger.F("error", err.Error()),
+			logger.F("groupID", req.GroupId),
+			logger.F("userID", req.UserId),
+			logger.F("newOwnerID", req.NewOwnerId))
+		res = h.converter.BuildErrorTransferGroupOwnerResponse(err.Error())
+	} else {
+		res = h.converter.BuildTransferGroupOwnerResponse(true, "转让群主成功")
+	}
+
+	httpx.WriteObject(c, res, err)
+}
+
+// SetGroupHistoryVisibility 设置新成员能否查看入群前的历史消息
+func (h *HTTPHandler) SetGroupHistoryVisibility(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var req rest.SetGroupHistoryVisibilityRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Error(ctx, "Invalid set group history visibility request", logger.F("error", err.Error()))
+		res := h.converter.BuildErrorSetGroupHistoryVisibilityResponse("Invalid request format")
+		httpx.WriteObject(c, res, err)
+		return
+	}
+
+	// 将业务信息添加到context
+	ctx = tracecontext.WithGroupID(ctx, req.GroupId)
+	ctx = tracecontext.WithUserID(ctx, req.UserId)
+
+	err := h.svc.SetGroupHistoryVisibility(ctx, req.GroupId, req.UserId, req.NewMembersSeeHistory)
+
+	var res *rest.SetGroupHistoryVisibilityResponse
+	if err != nil {
+		h.logger.Error(ctx, "Set group history visibility failed",
+			logger.F("error", err.Error()),
+			logger.F("groupID", req.GroupId),
+			logger.F("userID", req.UserId))
+		res = h.converter.BuildErrorSetGroupHistoryVisibilityResponse(err.Error())
+	} else {
+		res = h.converter.BuildSetGroupHistoryVisibilityResponse(true, "设置成功")
+	}
+
+	httpx.WriteObject(c, res, err)
+}
+
+// SetGroupJoinMode 设置入群方式
+func (h *HTTPHandler) SetGroupJoinMode(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var req rest.SetGroupJoinModeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Error(ctx, "Invalid set group join mode request", logger.F("error", err.Error()))
+		res := h.converter.BuildErrorSetGroupJoinModeResponse("Invalid request format")
+		httpx.WriteObject(c, res, err)
+		return
+	}
+
+	// 将业务信息添加到context
+	ctx = tracecontext.WithGroupID(ctx, req.GroupId)
+	ctx = tracecontext.WithUserID(ctx, req.UserId)
+
+	err := h.svc.SetGroupJoinMode(ctx, req.GroupId, req.UserId, req.JoinMode)
+
+	var res *rest.SetGroupJoinModeResponse
+	if err != nil {
+		h.logger.Error(ctx, "Set group join mode failed",
+			logger.F("error", err.Error()),
+			logger.F("groupID", req.GroupId),
+			logger.F("userID", req.UserId))
+		res = h.converter.BuildErrorSetGroupJoinModeResponse(err.Error())
+	} else {
+		res = h.converter.BuildSetGroupJoinModeResponse(true, "设置成功")
+	}
+
+	httpx.WriteObject(c, res, err)
+}
+
 // JoinGroup 加入群组
 func (h *HTTPHandler) JoinGroup(c *gin.Context) {
 	ctx := c.Request.Context()
@@ -150,7 +287,7 @@ func (h *HTTPHandler) JoinGroup(c *gin.Context) {
 	ctx = tracecontext.WithGroupID(ctx, req.GroupId)
 	ctx = tracecontext.WithUserID(ctx, req.UserId)
 
-	err := h.svc.JoinGroup(ctx, req.GroupId, req.UserId)
+	request, err := h.svc.JoinGroup(ctx, req.GroupId, req.UserId, req.Reason)
 
 	var res *rest.JoinGroupResponse
 	if err != nil {
@@ -159,11 +296,17 @@ func (h *HTTPHandler) JoinGroup(c *gin.Context) {
 			logger.F("groupID", req.GroupId),
 			logger.F("userID", req.UserId))
 		res = h.converter.BuildErrorJoinGroupResponse(err.Error())
+	} else if request != nil {
+		h.logger.Info(ctx, "Join request submitted",
+			logger.F("groupID", req.GroupId),
+			logger.F("userID", req.UserId),
+			logger.F("requestID", request.ID))
+		res = h.converter.BuildJoinGroupResponse(true, "已提交加群申请，等待审批", request)
 	} else {
 		h.logger.Info(ctx, "Join group successful",
 			logger.F("groupID", req.GroupId),
 			logger.F("userID", req.UserId))
-		res = h.converter.BuildJoinGroupResponse(true, "加入群组成功")
+		res = h.converter.BuildJoinGroupResponse(true, "加入群组成功", nil)
 	}
 
 	httpx.WriteObject(c, res, err)
@@ -254,3 +397,217 @@ func (h *HTTPHandler) GetGroupMembers(c *gin.Context) {
 
 	httpx.WriteObject(c, res, err)
 }
+
+// GetGroupPresence 获取群组在线成员汇总
+func (h *HTTPHandler) GetGroupPresence(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var req rest.GetGroupPresenceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Error(ctx, "Invalid get group presence request", logger.F("error", err.Error()))
+		res := h.converter.BuildErrorGetGroupPresenceResponse("Invalid request format")
+		httpx.WriteObject(c, res, err)
+		return
+	}
+
+	// 将业务信息添加到context
+	ctx = tracecontext.WithGroupID(ctx, req.GroupId)
+	ctx = tracecontext.WithUserID(ctx, req.UserId)
+
+	presence, err := h.svc.GetGroupPresence(ctx, req.GroupId, req.UserId, int(req.Page), int(req.PageSize))
+
+	var res *rest.GetGroupPresenceResponse
+	if err != nil {
+		h.logger.Error(ctx, "Get group presence failed",
+			logger.F("error", err.Error()),
+			logger.F("groupID", req.GroupId),
+			logger.F("userID", req.UserId))
+		res = h.converter.BuildErrorGetGroupPresenceResponse(err.Error())
+	} else {
+		h.logger.Info(ctx, "Get group presence successful",
+			logger.F("groupID", req.GroupId),
+			logger.F("onlineCount", presence.OnlineCount))
+		res = h.converter.BuildGetGroupPresenceResponse(true, "获取群在线成员成功", presence)
+	}
+
+	httpx.WriteObject(c, res, err)
+}
+
+// ListAnnouncements 查询群公告历史
+func (h *HTTPHandler) ListAnnouncements(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var req rest.ListAnnouncementsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Error(ctx, "Invalid list announcements request", logger.F("error", err.Error()))
+		res := h.converter.BuildErrorListAnnouncementsResponse("Invalid request format")
+		httpx.WriteObject(c, res, err)
+		return
+	}
+
+	// 将业务信息添加到context
+	ctx = tracecontext.WithGroupID(ctx, req.GroupId)
+	ctx = tracecontext.WithUserID(ctx, req.UserId)
+
+	page, pageSize := service.AnnouncementPage(int(req.Page), int(req.PageSize))
+	announcements, total, err := h.svc.ListAnnouncements(ctx, req.GroupId, req.UserId, page, pageSize)
+
+	var res *rest.ListAnnouncementsResponse
+	if err != nil {
+		h.logger.Error(ctx, "List announcements failed",
+			logger.F("error", err.Error()),
+			logger.F("groupID", req.GroupId),
+			logger.F("userID", req.UserId))
+		res = h.converter.BuildErrorListAnnouncementsResponse(err.Error())
+	} else {
+		h.logger.Info(ctx, "List announcements successful",
+			logger.F("groupID", req.GroupId),
+			logger.F("total", total))
+		res = h.converter.BuildListAnnouncementsResponse(true, "获取群公告历史成功", announcements, total, int32(page), int32(pageSize))
+	}
+
+	httpx.WriteObject(c, res, err)
+}
+
+// RevertAnnouncement 恢复历史公告为生效公告
+func (h *HTTPHandler) RevertAnnouncement(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var req rest.RevertAnnouncementRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Error(ctx, "Invalid revert announcement request", logger.F("error", err.Error()))
+		res := &rest.RevertAnnouncementResponse{Success: false, Message: "Invalid request format"}
+		httpx.WriteObject(c, res, err)
+		return
+	}
+
+	// 将业务信息添加到context
+	ctx = tracecontext.WithGroupID(ctx, req.GroupId)
+	ctx = tracecontext.WithUserID(ctx, req.UserId)
+
+	err := h.svc.RevertAnnouncement(ctx, req.GroupId, req.UserId, req.AnnouncementId)
+
+	var res *rest.RevertAnnouncementResponse
+	if err != nil {
+		h.logger.Error(ctx, "Revert announcement failed",
+			logger.F("error", err.Error()),
+			logger.F("groupID", req.GroupId),
+			logger.F("announcementID", req.AnnouncementId))
+		res = &rest.RevertAnnouncementResponse{Success: false, Message: err.Error()}
+	} else {
+		h.logger.Info(ctx, "Revert announcement successful",
+			logger.F("groupID", req.GroupId),
+			logger.F("announcementID", req.AnnouncementId))
+		res = &rest.RevertAnnouncementResponse{Success: true, Message: "恢复群公告成功"}
+	}
+
+	httpx.WriteObject(c, res, err)
+}
+
+// ListJoinRequests 查询加群申请
+func (h *HTTPHandler) ListJoinRequests(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var req rest.ListJoinRequestsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Error(ctx, "Invalid list join requests request", logger.F("error", err.Error()))
+		res := h.converter.BuildErrorListJoinRequestsResponse("Invalid request format")
+		httpx.WriteObject(c, res, err)
+		return
+	}
+
+	// 将业务信息添加到context
+	ctx = tracecontext.WithGroupID(ctx, req.GroupId)
+	ctx = tracecontext.WithUserID(ctx, req.UserId)
+
+	requests, pendingCount, err := h.svc.ListJoinRequests(ctx, req.GroupId, req.UserId, req.Status)
+
+	var res *rest.ListJoinRequestsResponse
+	if err != nil {
+		h.logger.Error(ctx, "List join requests failed",
+			logger.F("error", err.Error()),
+			logger.F("groupID", req.GroupId),
+			logger.F("userID", req.UserId))
+		res = h.converter.BuildErrorListJoinRequestsResponse(err.Error())
+	} else {
+		h.logger.Info(ctx, "List join requests successful",
+			logger.F("groupID", req.GroupId),
+			logger.F("count", len(requests)),
+			logger.F("pendingCount", pendingCount))
+		res = h.converter.BuildListJoinRequestsResponse(true, "获取加群申请成功", requests, pendingCount)
+	}
+
+	httpx.WriteObject(c, res, err)
+}
+
+// ReviewJoinRequest 同意或拒绝加群申请
+func (h *HTTPHandler) ReviewJoinRequest(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var req rest.ReviewJoinRequestRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Error(ctx, "Invalid review join request request", logger.F("error", err.Error()))
+		res := &rest.ReviewJoinRequestResponse{Success: false, Message: "Invalid request format"}
+		httpx.WriteObject(c, res, err)
+		return
+	}
+
+	// 将业务信息添加到context
+	ctx = tracecontext.WithGroupID(ctx, req.GroupId)
+	ctx = tracecontext.WithUserID(ctx, req.UserId)
+
+	err := h.svc.ReviewJoinRequest(ctx, req.GroupId, req.UserId, req.RequestId, req.Approve, req.Reason)
+
+	var res *rest.ReviewJoinRequestResponse
+	if err != nil {
+		h.logger.Error(ctx, "Review join request failed",
+			logger.F("error", err.Error()),
+			logger.F("groupID", req.GroupId),
+			logger.F("requestID", req.RequestId))
+		res = &rest.ReviewJoinRequestResponse{Success: false, Message: err.Error()}
+	} else {
+		h.logger.Info(ctx, "Review join request successful",
+			logger.F("groupID", req.GroupId),
+			logger.F("requestID", req.RequestId),
+			logger.F("approve", req.Approve))
+		res = &rest.ReviewJoinRequestResponse{Success: true, Message: "处理加群申请成功"}
+	}
+
+	httpx.WriteObject(c, res, err)
+}
+
+// MuteJoinRequestNotify 屏蔽或取消屏蔽加群申请通知
+func (h *HTTPHandler) MuteJoinRequestNotify(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var req rest.MuteJoinRequestNotifyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Error(ctx, "Invalid mute join request notify request", logger.F("error", err.Error()))
+		res := &rest.MuteJoinRequestNotifyResponse{Success: false, Message: "Invalid request format"}
+		httpx.WriteObject(c, res, err)
+		return
+	}
+
+	// 将业务信息添加到context
+	ctx = tracecontext.WithGroupID(ctx, req.GroupId)
+	ctx = tracecontext.WithUserID(ctx, req.UserId)
+
+	err := h.svc.MuteJoinRequestNotify(ctx, req.GroupId, req.UserId, req.Muted)
+
+	var res *rest.MuteJoinRequestNotifyResponse
+	if err != nil {
+		h.logger.Error(ctx, "Mute join request notify failed",
+			logger.F("error", err.Error()),
+			logger.F("groupID", req.GroupId),
+			logger.F("userID", req.UserId))
+		res = &rest.MuteJoinRequestNotifyResponse{Success: false, Message: err.Error()}
+	} else {
+		h.logger.Info(ctx, "Mute join request notify successful",
+			logger.F("groupID", req.GroupId),
+			logger.F("userID", req.UserId),
+			logger.F("muted", req.Muted))
+		res = &rest.MuteJoinRequestNotifyResponse{Success: true, Message: "更新加群申请通知设置成功"}
+	}
+
+	httpx.WriteObject(c, res, err)
+}
@@ -38,6 +38,11 @@ func (h *GRPCHandler) ValidateGroupMember(ctx context.Context, req *rest.Validat
 	return h.validateGroupMemberImpl(ctx, req)
 }
 
+// GetMembershipPeriods 获取用户的群成员身份时段
+func (h *GRPCHandler) GetMembershipPeriods(ctx context.Context, req *rest.GetMembershipPeriodsRequest) (*rest.GetMembershipPeriodsResponse, error) {
+	return h.getMembershipPeriodsImpl(ctx, req)
+}
+
 // ValidateFriendship 验证好友关系
 func (h *GRPCHandler) ValidateFriendship(ctx context.Context, req *rest.ValidateFriendshipRequest) (*rest.ValidateFriendshipResponse, error) {
 	return h.validateFriendshipImpl(ctx, req)
@@ -47,3 +52,8 @@ func (h *GRPCHandler) ValidateFriendship(ctx context.Context, req *rest.Validate
 func (h *GRPCHandler) GetUserSocialInfo(ctx context.Context, req *rest.GetUserSocialInfoRequest) (*rest.GetUserSocialInfoResponse, error) {
 	return h.getUserSocialInfoImpl(ctx, req)
 }
+
+// GetActiveAnnouncement 获取群当前生效的公告
+func (h *GRPCHandler) GetActiveAnnouncement(ctx context.Context, req *rest.GetActiveAnnouncementRequest) (*rest.GetActiveAnnouncementResponse, error) {
+	return h.getActiveAnnouncementImpl(ctx, req)
+}
@@ -30,11 +30,13 @@ func (h *HTTPHandler) RegisterRoutes(engine *gin.Engine) {
 	friendGroup := engine.Group("/api/v1/friend")
 	{
 		friendGroup.POST("/send_request", h.SendFriendRequest)
+		friendGroup.POST("/batch_add", h.BatchAddFriends)
 		friendGroup.POST("/accept_request", h.AcceptFriendRequest)
 		friendGroup.POST("/reject_request", h.RejectFriendRequest)
 		friendGroup.POST("/delete", h.DeleteFriend)
 		friendGroup.POST("/list", h.GetFriendList)
 		friendGroup.POST("/apply_list", h.GetFriendApplyList)
+		friendGroup.POST("/history", h.GetFriendHistory)
 	}
 
 	// 群组相关路由
@@ -43,9 +45,19 @@ func (h *HTTPHandler) RegisterRoutes(engine *gin.Engine) {
 		groupGroup.POST("/create", h.CreateGroup)
 		groupGroup.POST("/info", h.GetGroup)
 		groupGroup.POST("/update", h.UpdateGroup)
+		groupGroup.POST("/update_info", h.UpdateGroupInfo)
+		groupGroup.POST("/transfer_owner", h.TransferGroupOwner)
+		groupGroup.POST("/history_visibility", h.SetGroupHistoryVisibility)
+		groupGroup.POST("/join_mode", h.SetGroupJoinMode)
 		groupGroup.POST("/join", h.JoinGroup)
 		groupGroup.POST("/leave", h.LeaveGroup)
 		groupGroup.POST("/members", h.GetGroupMembers)
+		groupGroup.POST("/presence", h.GetGroupPresence)
+		groupGroup.POST("/announcements", h.ListAnnouncements)
+		groupGroup.POST("/announcement/revert", h.RevertAnnouncement)
+		groupGroup.POST("/join_requests", h.ListJoinRequests)
+		groupGroup.POST("/join_request/review", h.ReviewJoinRequest)
+		groupGroup.POST("/join_request/mute", h.MuteJoinRequestNotify)
 	}
 
 	// 社交关系验证路由
@@ -54,5 +66,10 @@ func (h *HTTPHandler) RegisterRoutes(engine *gin.Engine) {
 		socialGroup.POST("/validate_friendship", h.ValidateFriendship)
 		socialGroup.POST("/validate_membership", h.ValidateGroupMembership)
 		socialGroup.POST("/user_info", h.GetUserSocialInfo)
+		socialGroup.POST("/presence_visibility", h.SetPresenceVisibility)
+		socialGroup.POST("/presence", h.GetUserPresence)
+		socialGroup.POST("/block", h.BlockUser)
+		socialGroup.POST("/unblock", h.UnblockUser)
+		socialGroup.POST("/blocked_list", h.ListBlockedUsers)
 	}
 }
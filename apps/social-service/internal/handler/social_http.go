@@ -121,10 +121,179 @@ func (h *HTTPHandler) GetUserSocialInfo(c *gin.Context) {
 			logger.F("userID", req.UserId),
 			logger.F("friendCount", socialInfo.FriendCount),
 			logger.F("groupCount", socialInfo.GroupCount))
+		// 只返回群组数，不返回群组列表，每页数量为0
 		res = &rest.GetUserGroupsResponse{
-			Success: true,
-			Message: fmt.Sprintf("用户有%d个好友，%d个群组", socialInfo.FriendCount, socialInfo.GroupCount),
-			Total:   int32(socialInfo.GroupCount),
+			Success:    true,
+			Message:    fmt.Sprintf("用户有%d个好友，%d个群组", socialInfo.FriendCount, socialInfo.GroupCount),
+			Total:      int32(socialInfo.GroupCount),
+			Page:       1,
+			Pagination: httpx.OffsetPage(1, 0, int64(socialInfo.GroupCount)).Proto(),
+		}
+	}
+
+	httpx.WriteObject(c, res, err)
+}
+
+// SetPresenceVisibility 设置在线状态可见性
+func (h *HTTPHandler) SetPresenceVisibility(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var req rest.SetPresenceVisibilityRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Error(ctx, "Invalid set presence visibility request", logger.F("error", err.Error()))
+		res := h.converter.BuildErrorSetPresenceVisibilityResponse("Invalid request format")
+		httpx.WriteObject(c, res, err)
+		return
+	}
+
+	// 将业务信息添加到context
+	ctx = tracecontext.WithUserID(ctx, req.UserId)
+
+	err := h.svc.SetPresenceVisibility(ctx, req.UserId, req.Hidden)
+
+	var res *rest.SetPresenceVisibilityResponse
+	if err != nil {
+		h.logger.Error(ctx, "Set presence visibility failed",
+			logger.F("error", err.Error()),
+			logger.F("userID", req.UserId))
+		res = h.converter.BuildErrorSetPresenceVisibilityResponse(err.Error())
+	} else {
+		res = h.converter.BuildSetPresenceVisibilityResponse(true, "设置在线状态可见性成功")
+	}
+
+	httpx.WriteObject(c, res, err)
+}
+
+// GetUserPresence 批量查询用户在线状态和最近在线时间
+func (h *HTTPHandler) GetUserPresence(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var req rest.GetUserPresenceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Error(ctx, "Invalid get user presence request", logger.F("error", err.Error()))
+		res := h.converter.BuildErrorGetUserPresenceResponse("Invalid request format")
+		httpx.WriteObject(c, res, err)
+		return
+	}
+
+	// 将业务信息添加到context
+	ctx = tracecontext.WithUserID(ctx, req.UserId)
+
+	presences, err := h.svc.GetUserPresence(ctx, req.UserId, req.TargetIds)
+
+	var res *rest.GetUserPresenceResponse
+	if err != nil {
+		h.logger.Error(ctx, "Get user presence failed",
+			logger.F("error", err.Error()),
+			logger.F("userID", req.UserId))
+		res = h.converter.BuildErrorGetUserPresenceResponse(err.Error())
+	} else {
+		res = h.converter.BuildGetUserPresenceResponse(true, "获取在线状态成功", presences)
+	}
+
+	httpx.WriteObject(c, res, err)
+}
+
+// BlockUser 拉黑用户
+func (h *HTTPHandler) BlockUser(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var req rest.BlockUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Error(ctx, "Invalid block user request", logger.F("error", err.Error()))
+		res := &rest.BlockUserResponse{Success: false, Message: "Invalid request format"}
+		httpx.WriteObject(c, res, err)
+		return
+	}
+
+	// 将业务信息添加到context
+	ctx = tracecontext.WithUserID(ctx, req.UserId)
+
+	err := h.svc.BlockUser(ctx, req.UserId, req.TargetId)
+
+	var res *rest.BlockUserResponse
+	if err != nil {
+		h.logger.Error(ctx, "Block user failed",
+			logger.F("error", err.Error()),
+			logger.F("userID", req.UserId),
+			logger.F("targetID", req.TargetId))
+		res = &rest.BlockUserResponse{Success: false, Message: err.Error()}
+	} else {
+		res = &rest.BlockUserResponse{Success: true, Message: "拉黑成功"}
+	}
+
+	httpx.WriteObject(c, res, err)
+}
+
+// UnblockUser 取消拉黑
+func (h *HTTPHandler) UnblockUser(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var req rest.UnblockUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Error(ctx, "Invalid unblock user request", logger.F("error", err.Error()))
+		res := &rest.UnblockUserResponse{Success: false, Message: "Invalid request format"}
+		httpx.WriteObject(c, res, err)
+		return
+	}
+
+	// 将业务信息添加到context
+	ctx = tracecontext.WithUserID(ctx, req.UserId)
+
+	err := h.svc.UnblockUser(ctx, req.UserId, req.TargetId)
+
+	var res *rest.UnblockUserResponse
+	if err != nil {
+		h.logger.Error(ctx, "Unblock user failed",
+			logger.F("error", err.Error()),
+			logger.F("userID", req.UserId),
+			logger.F("targetID", req.TargetId))
+		res = &rest.UnblockUserResponse{Success: false, Message: err.Error()}
+	} else {
+		res = &rest.UnblockUserResponse{Success: true, Message: "取消拉黑成功"}
+	}
+
+	httpx.WriteObject(c, res, err)
+}
+
+// ListBlockedUsers 查询黑名单
+func (h *HTTPHandler) ListBlockedUsers(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var req rest.ListBlockedUsersRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Error(ctx, "Invalid list blocked users request", logger.F("error", err.Error()))
+		res := &rest.ListBlockedUsersResponse{Success: false, Message: "Invalid request format"}
+		httpx.WriteObject(c, res, err)
+		return
+	}
+
+	// 将业务信息添加到context
+	ctx = tracecontext.WithUserID(ctx, req.UserId)
+
+	blocks, err := h.svc.ListBlockedUsers(ctx, req.UserId)
+
+	var res *rest.ListBlockedUsersResponse
+	if err != nil {
+		h.logger.Error(ctx, "List blocked users failed",
+			logger.F("error", err.Error()),
+			logger.F("userID", req.UserId))
+		res = &rest.ListBlockedUsersResponse{Success: false, Message: err.Error()}
+	} else {
+		users := make([]*rest.BlockedUserInfo, len(blocks))
+		for i, block := range blocks {
+			users[i] = &rest.BlockedUserInfo{
+				UserId:    block.BlockedID,
+				CreatedAt: block.CreatedAt.Unix(),
+			}
+		}
+		// 黑名单不分页，一次返回全部
+		total := int32(len(users))
+		res = &rest.ListBlockedUsersResponse{
+			Success:    true,
+			Message:    "获取黑名单成功",
+			Users:      users,
+			Pagination: httpx.OffsetPage(1, total, int64(total)).Proto(),
 		}
 	}
 
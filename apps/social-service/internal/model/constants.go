@@ -6,6 +6,17 @@ const (
 	DefaultPageSize   = 20
 )
 
+// 群组等级
+const (
+	GroupTierStandard = "standard" // 默认等级，成员上限为配置的默认值
+)
+
+// 入群方式
+const (
+	GroupJoinModeOpen     = "open"     // 直接加入
+	GroupJoinModeApproval = "approval" // 提交申请，群主或管理员审批后加入
+)
+
 // 群成员角色
 const (
 	RoleOwner  = "owner"  // 群主
@@ -28,9 +39,108 @@ const (
 	JoinRequestStatusRejected = "rejected"
 )
 
+// 加群申请通知，经下行推送Topic由message-service推送给在线用户
+const (
+	TopicDownlinkMessage           = "downlink_messages"        // 下行推送Topic
+	EventTypeGroupJoinRequest      = "group_join_request"       // 加群申请通知事件
+	MessageTypeJoinRequestPending  = 102                        // 待处理加群申请数变化，推送给群主和管理员
+	MessageTypeJoinRequestReviewed = 103                        // 加群申请已处理，推送给申请人
+	RedisKeyJoinRequestMutedPrefix = "group_join_request:muted" // 屏蔽加群申请通知的管理员集合前缀 group_join_request:muted:{groupID}
+)
+
+// 群资料修改
+const (
+	MaxGroupNameLength        = 100 // 群名称最大字符数
+	MaxGroupDescriptionLength = 500 // 群简介最大字符数
+	MaxGroupAvatarLength      = 500 // 头像地址最大长度
+
+	EventTypeGroupInfoUpdated   = "group_info_updated" // 群资料变更通知事件
+	MessageTypeGroupInfoUpdated = 107                  // 群资料变更，推送给在线群成员
+
+	TopicGroupIndex       = "group-index-events" // 群组索引事件Topic，与search-service一致，按群组ID分区
+	GroupIndexEventUpdate = "update"
+)
+
+// 群成员变更分布式锁，同一群组的加群、退群和群主转让在多个实例间串行执行
+const (
+	RedisKeyGroupMemberLockPrefix = "group:lock:members" // 群成员变更锁前缀 group:lock:members:{groupID}
+	GroupMemberLockTTLSeconds     = 5                    // 锁过期时间，远大于加群、转让等数据库操作的耗时
+	GroupMemberLockWaitMillis     = 2000                 // 锁被占用时的最长等待时间，超时按繁忙返回
+)
+
 // 好友申请状态
 const (
 	FriendApplyStatusPending  = "pending"
 	FriendApplyStatusAccepted = "accepted"
 	FriendApplyStatusRejected = "rejected"
 )
+
+// 好友关系事件，经发件箱发布，按操作人ID分区
+const (
+	TopicFriendEvents  = "friend-events"
+	FriendEventAdded   = "friend_added"   // 接受好友申请后成为好友
+	FriendEventDeleted = "friend_deleted" // 删除好友
+)
+
+// 好友申请被拒绝的原因，随申请响应返回，客户端据此区分提示
+const (
+	FriendRequestReasonPending     = "already_pending" // 已有待处理的申请，不重复创建
+	FriendRequestReasonFriends     = "already_friends" // 已经是好友
+	FriendRequestReasonBlocked     = "blocked"         // 任一方拉黑了对方
+	FriendRequestReasonRateLimited = "rate_limited"    // 超出每小时申请数上限
+)
+
+// 好友申请频率限制
+const (
+	CacheKeyFriendRequestRate = "social:friend_request:rate" // 每小时申请计数 social:friend_request:rate:{userID}
+	FriendRequestRateWindow   = 3600                         // 计数窗口（秒）
+)
+
+// 批量添加好友中单个目标的处理结果，申请被拒绝时沿用好友申请的拒绝原因
+const (
+	FriendBatchStatusSent     = "sent"      // 已发送好友申请
+	FriendBatchStatusNotFound = "not_found" // 用户不存在、已注销或已禁用
+	FriendBatchStatusInvalid  = "invalid"   // 目标是自己
+	FriendBatchStatusFailed   = "failed"    // 其他错误，可换新的请求ID重试
+)
+
+// UserStatusNormal 用户服务中正常状态的用户，禁用和注销的用户不能被添加为好友
+const UserStatusNormal int32 = 0
+
+// UserDiscoverabilityNone 用户服务中关闭了搜索可见性的用户，不能按用户名找到
+const UserDiscoverabilityNone = "none"
+
+// 批量添加好友幂等
+const (
+	CacheKeyFriendBatchResult = "social:friend_batch:result" // 处理结果 social:friend_batch:result:{userID}:{clientRequestID}
+	CacheKeyFriendBatchLock   = "social:friend_batch:lock"   // 处理中的请求锁 social:friend_batch:lock:{userID}:{clientRequestID}
+	FriendBatchResultTTL      = 86400                        // 处理结果保留时间（秒），期间重试返回相同结果
+	FriendBatchLockTTLSeconds = 60                           // 请求锁过期时间，远大于处理一批目标的耗时
+	MaxClientRequestIDLength  = 64                           // 客户端请求ID最大长度
+)
+
+// 在线状态相关
+const (
+	RedisKeyOnlineUsers         = "online_users"          // 在线用户集合，由im-gateway维护
+	RedisKeyPresenceHiddenUsers = "presence_hidden_users" // 隐身用户集合
+	CacheKeyGroupPresence       = "group:presence"        // 群在线成员缓存
+	CacheExpireGroupPresence    = 10                      // 群在线成员缓存10秒
+	MaxPresencePageSize         = 200                     // 在线成员分页最大值
+	MaxPresenceQueryUsers       = 200                     // 批量查询在线状态的最大用户数
+)
+
+// 好友关系历史
+const (
+	FriendHistoryPurgeInterval = 3600 // 清除过期已删除好友关系的间隔（秒）
+)
+
+// 拉黑关系缓存
+const (
+	BlockCacheSyncInterval  = 600  // 从数据库重建拉黑关系缓存的间隔（秒）
+	BlockCacheSyncBatchSize = 1000 // 重建时每批读取的拉黑关系数
+)
+
+// 群公告历史
+const (
+	MaxAnnouncementPageSize = 100 // 公告历史分页最大值
+)
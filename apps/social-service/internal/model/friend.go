@@ -2,15 +2,18 @@ package model
 
 import (
 	"time"
+
+	"gorm.io/gorm"
 )
 
-// Friend 好友关系
+// Friend 好友关系，删除好友为软删除，每次成为好友对应一条记录
 type Friend struct {
-	ID        int64     `json:"id" gorm:"primaryKey;autoIncrement"`
-	UserID    int64     `json:"user_id" gorm:"not null;index"`    // 用户ID
-	FriendID  int64     `json:"friend_id" gorm:"not null;index"`  // 好友ID
-	Remark    string    `json:"remark" gorm:"type:varchar(100)"`  // 备注
-	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"` // 添加时间
+	ID        int64          `json:"id" gorm:"primaryKey;autoIncrement"`
+	UserID    int64          `json:"user_id" gorm:"not null;index;uniqueIndex:uk_user_friend,where:deleted_at IS NULL"`   // 用户ID，未删除的好友关系每对用户只有一条
+	FriendID  int64          `json:"friend_id" gorm:"not null;index;uniqueIndex:uk_user_friend,where:deleted_at IS NULL"` // 好友ID
+	Remark    string         `json:"remark" gorm:"type:varchar(100)"`                                                     // 备注
+	CreatedAt time.Time      `json:"created_at" gorm:"autoCreateTime"`                                                    // 添加时间
+	DeletedAt gorm.DeletedAt `json:"deleted_at,omitempty" gorm:"index"`                                                   // 删除时间，已删除的关系不计入好友
 }
 
 // TableName .
@@ -20,20 +23,50 @@ func (Friend) TableName() string {
 
 // FriendApply 好友申请
 type FriendApply struct {
-	ID           int64      `json:"id" gorm:"primaryKey;autoIncrement"`
-	UserID       int64      `json:"user_id" gorm:"not null;index"`                    // 被申请人
-	ApplicantID  int64      `json:"applicant_id" gorm:"not null;index"`               // 申请人
-	Remark       string     `json:"remark" gorm:"type:text"`                          // 申请备注
-	Status       string     `json:"status" gorm:"type:varchar(20);default:'pending'"` // 状态(pending/accepted/rejected)
-	CreatedAt    time.Time  `json:"created_at" gorm:"autoCreateTime"`                 // 申请时间
-	UpdatedAt    time.Time  `json:"updated_at" gorm:"autoUpdateTime"`                 // 更新时间
-	AgreeTime    *time.Time `json:"agree_time,omitempty" gorm:"index"`                // 同意时间
-	RejectTime   *time.Time `json:"reject_time,omitempty" gorm:"index"`               // 拒绝时间
-	AgreeRemark  string     `json:"agree_remark,omitempty" gorm:"type:varchar(100)"`  // 同意时备注
-	RejectReason string     `json:"reject_reason,omitempty" gorm:"type:varchar(200)"` // 拒绝原因
+	ID            int64      `json:"id" gorm:"primaryKey;autoIncrement"`
+	UserID        int64      `json:"user_id" gorm:"not null;index"`                    // 被申请人
+	ApplicantID   int64      `json:"applicant_id" gorm:"not null;index"`               // 申请人
+	Remark        string     `json:"remark" gorm:"type:text"`                          // 申请备注
+	Status        string     `json:"status" gorm:"type:varchar(20);default:'pending'"` // 状态(pending/accepted/rejected)
+	CreatedAt     time.Time  `json:"created_at" gorm:"autoCreateTime"`                 // 申请时间
+	UpdatedAt     time.Time  `json:"updated_at" gorm:"autoUpdateTime"`                 // 更新时间
+	AgreeTime     *time.Time `json:"agree_time,omitempty" gorm:"index"`                // 同意时间
+	RejectTime    *time.Time `json:"reject_time,omitempty" gorm:"index"`               // 拒绝时间
+	AgreeRemark   string     `json:"agree_remark,omitempty" gorm:"type:varchar(100)"`  // 同意时备注
+	RejectReason  string     `json:"reject_reason,omitempty" gorm:"type:varchar(200)"` // 拒绝原因
+	RestoreRemark bool       `json:"restore_remark" gorm:"default:false"`              // 重新添加时恢复申请人上次的备注
 }
 
 // TableName .
 func (FriendApply) TableName() string {
 	return "friend_applies"
 }
+
+// FriendBatchResult 批量添加好友中单个目标的处理结果
+type FriendBatchResult struct {
+	FriendID int64  `json:"friend_id"`          // 用户名未找到时为0
+	Username string `json:"username,omitempty"` // 按用户名指定时为请求中的用户名
+	Status   string `json:"status"`             // 见FriendBatchStatus*和FriendRequestReason*
+	Message  string `json:"message,omitempty"`
+}
+
+// UserBlock 拉黑关系，拉黑后双方互相看不到对方的内容和评论
+type UserBlock struct {
+	ID        int64     `json:"id" gorm:"primaryKey;autoIncrement"`
+	UserID    int64     `json:"user_id" gorm:"not null;uniqueIndex:idx_user_block"`    // 拉黑者
+	BlockedID int64     `json:"blocked_id" gorm:"not null;uniqueIndex:idx_user_block"` // 被拉黑者
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`                      // 拉黑时间
+}
+
+// TableName .
+func (UserBlock) TableName() string {
+	return "user_blocks"
+}
+
+// FriendEvent 好友关系事件，好友关系是双向的，一个事件对应一对用户
+type FriendEvent struct {
+	EventType string `json:"event_type"`
+	UserID    int64  `json:"user_id"`   // 操作人，接受申请或删除好友的用户
+	FriendID  int64  `json:"friend_id"` // 对方
+	Timestamp int64  `json:"timestamp"`
+}
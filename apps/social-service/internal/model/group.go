@@ -6,17 +6,21 @@ import (
 
 // Group 群组
 type Group struct {
-	ID           int64     `json:"id" gorm:"primaryKey;autoIncrement"`
-	Name         string    `json:"name" gorm:"type:varchar(100);not null;index"`
-	Description  string    `json:"description" gorm:"type:text"`
-	Avatar       string    `json:"avatar" gorm:"type:varchar(500)"`
-	OwnerID      int64     `json:"owner_id" gorm:"not null;index"`
-	MemberCount  int32     `json:"member_count" gorm:"default:1"`
-	MaxMembers   int32     `json:"max_members" gorm:"default:500"`
-	IsPublic     bool      `json:"is_public" gorm:"default:true"`
-	Announcement string    `json:"announcement" gorm:"type:text"`
-	CreatedAt    time.Time `json:"created_at" gorm:"autoCreateTime"`
-	UpdatedAt    time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+	ID                   int64     `json:"id" gorm:"primaryKey;autoIncrement"`
+	Name                 string    `json:"name" gorm:"type:varchar(100);not null;index"`
+	Description          string    `json:"description" gorm:"type:text"`
+	Avatar               string    `json:"avatar" gorm:"type:varchar(500)"`
+	OwnerID              int64     `json:"owner_id" gorm:"not null;index"`
+	MemberCount          int32     `json:"member_count" gorm:"default:1"`
+	MaxMembers           int32     `json:"max_members" gorm:"default:500"`
+	Tier                 string    `json:"tier" gorm:"type:varchar(20);default:'standard'"`           // 群组等级，决定成员上限
+	IsPublic             bool      `json:"is_public" gorm:"default:true"`                             // 是否可被搜索到，不影响入群方式
+	JoinMode             string    `json:"join_mode" gorm:"type:varchar(20);not null;default:'open'"` // 入群方式：open直接加入，approval需群主或管理员审批
+	Announcement         string    `json:"announcement" gorm:"type:text"`
+	Version              int64     `json:"version" gorm:"not null;default:0"`                    // 资料版本号，修改名称、简介或头像时递增，用于乐观并发控制
+	NewMembersSeeHistory bool      `json:"new_members_see_history" gorm:"not null;default:true"` // 新成员能否查看和搜索入群前的历史消息
+	CreatedAt            time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt            time.Time `json:"updated_at" gorm:"autoUpdateTime"`
 }
 
 // TableName .
@@ -27,8 +31,8 @@ func (Group) TableName() string {
 // GroupMember 群成员
 type GroupMember struct {
 	ID       int64     `json:"id" gorm:"primaryKey;autoIncrement"`
-	UserID   int64     `json:"user_id" gorm:"not null;index"`
-	GroupID  int64     `json:"group_id" gorm:"not null;index"`
+	UserID   int64     `json:"user_id" gorm:"not null;index;uniqueIndex:uk_user_group"` // 同一用户在同一群组只有一条成员记录
+	GroupID  int64     `json:"group_id" gorm:"not null;index;uniqueIndex:uk_user_group"`
 	Role     string    `json:"role" gorm:"type:varchar(20);default:'member'"` // owner, admin, member
 	Nickname string    `json:"nickname" gorm:"type:varchar(100)"`
 	JoinedAt time.Time `json:"joined_at" gorm:"autoCreateTime"`
@@ -39,6 +43,22 @@ func (GroupMember) TableName() string {
 	return "group_members"
 }
 
+// GroupMembershipPeriod 群成员身份时段，每次入群新增一条，退群时记录退群时间
+// 用于限制成员只能搜索在群期间的消息；功能上线前入群的成员没有时段记录，以成员表的入群时间补齐
+type GroupMembershipPeriod struct {
+	ID             int64      `json:"id" gorm:"primaryKey;autoIncrement"`
+	GroupID        int64      `json:"group_id" gorm:"not null;index:idx_membership_period_user_group,priority:2"`
+	UserID         int64      `json:"user_id" gorm:"not null;index:idx_membership_period_user_group,priority:1"`
+	JoinedAt       time.Time  `json:"joined_at" gorm:"not null"`
+	LeftAt         *time.Time `json:"left_at"`                               // 退群时间，仍在群内为nil
+	HistoryVisible bool       `json:"history_visible" gorm:"->;-:migration"` // 群组是否允许新成员查看入群前的历史消息，查询时按群组设置填充
+}
+
+// TableName .
+func (GroupMembershipPeriod) TableName() string {
+	return "group_membership_periods"
+}
+
 // GroupInvitation 群邀请
 type GroupInvitation struct {
 	ID        int64     `json:"id" gorm:"primaryKey;autoIncrement"`
@@ -59,16 +79,85 @@ func (GroupInvitation) TableName() string {
 
 // GroupJoinRequest 加群申请
 type GroupJoinRequest struct {
+	ID           int64     `json:"id" gorm:"primaryKey;autoIncrement"`
+	GroupID      int64     `json:"group_id" gorm:"not null;index"`
+	UserID       int64     `json:"user_id" gorm:"not null;index"`
+	Status       string    `json:"status" gorm:"type:varchar(20);default:'pending'"` // pending, approved, rejected
+	Reason       string    `json:"reason" gorm:"type:text"`
+	ReviewerID   int64     `json:"reviewer_id"`                    // 处理人，未处理时为0
+	ReviewReason string    `json:"review_reason" gorm:"type:text"` // 处理理由，通知给申请人
+	CreatedAt    time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt    time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// TableName .
+func (GroupJoinRequest) TableName() string {
+	return "group_join_requests"
+}
+
+// GroupPresence 群组在线成员汇总
+type GroupPresence struct {
+	GroupID       int64   `json:"group_id"`
+	OnlineCount   int     `json:"online_count"`
+	OnlineUserIDs []int64 `json:"online_user_ids"`
+	Page          int     `json:"page"`
+	PageSize      int     `json:"page_size"`
+}
+
+// UserPresence 用户在线状态，隐身用户对他人显示为离线且不返回最近在线时间
+type UserPresence struct {
+	UserID   int64 `json:"user_id"`
+	Online   bool  `json:"online"`
+	LastSeen int64 `json:"last_seen"` // 最近在线时间（Unix秒），未知或隐身时为0
+}
+
+// GroupAnnouncement 群公告历史，每个群同一时间只有一条生效公告
+type GroupAnnouncement struct {
 	ID        int64     `json:"id" gorm:"primaryKey;autoIncrement"`
 	GroupID   int64     `json:"group_id" gorm:"not null;index"`
-	UserID    int64     `json:"user_id" gorm:"not null;index"`
-	Status    string    `json:"status" gorm:"type:varchar(20);default:'pending'"` // pending, approved, rejected
-	Reason    string    `json:"reason" gorm:"type:text"`
+	AuthorID  int64     `json:"author_id" gorm:"not null"`
+	Content   string    `json:"content" gorm:"type:text"`
+	Active    bool      `json:"active" gorm:"default:false"`
 	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
-	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime"`
 }
 
 // TableName .
-func (GroupJoinRequest) TableName() string {
-	return "group_join_requests"
+func (GroupAnnouncement) TableName() string {
+	return "group_announcements"
+}
+
+// JoinRequestNotice 加群申请通知，序列化后作为推送消息的内容
+type JoinRequestNotice struct {
+	GroupID      int64  `json:"group_id"`
+	RequestID    int64  `json:"request_id"`
+	UserID       int64  `json:"user_id"`                 // 申请人
+	PendingCount int64  `json:"pending_count,omitempty"` // 群内待处理申请数，客户端按群折叠为一个角标
+	Status       string `json:"status,omitempty"`        // 处理结果，仅通知申请人时有值
+	Reason       string `json:"reason,omitempty"`        // 处理理由
+}
+
+// GroupInfoNotice 群资料变更通知，序列化后作为推送消息的内容，客户端据此直接刷新群资料
+type GroupInfoNotice struct {
+	GroupID     int64  `json:"group_id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Avatar      string `json:"avatar"`
+	Version     int64  `json:"version"` // 客户端只应用比本地更新的版本
+	OperatorID  int64  `json:"operator_id"`
+}
+
+// GroupIndexEvent 群组索引事件，携带完整的群组文档，search-service据此覆盖写入群组索引
+type GroupIndexEvent struct {
+	EventType   string    `json:"event_type"`
+	ID          int64     `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	Avatar      string    `json:"avatar"`
+	OwnerID     int64     `json:"owner_id"`
+	MemberCount int32     `json:"member_count"`
+	MaxMembers  int32     `json:"max_members"`
+	IsPublic    bool      `json:"is_public"`
+	Version     int64     `json:"version"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
 }
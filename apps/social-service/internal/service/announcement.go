@@ -0,0 +1,160 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+
+	"goim-social/apps/social-service/internal/model"
+	"goim-social/pkg/audit"
+	tracecontext "goim-social/pkg/context"
+	"goim-social/pkg/logger"
+	"goim-social/pkg/telemetry"
+)
+
+// ============ 群公告历史 ============
+
+// ListAnnouncements 分页获取群公告历史，生效公告在前，仅群成员可查看
+func (s *Service) ListAnnouncements(ctx context.Context, groupID, viewerID int64, page, pageSize int) ([]*model.GroupAnnouncement, int64, error) {
+	// 开始OpenTelemetry span
+	ctx, span := telemetry.StartSpan(ctx, "social.service.ListAnnouncements")
+	defer span.End()
+
+	// 设置span属性
+	span.SetAttributes(
+		attribute.Int64("group.id", groupID),
+		attribute.Int64("group.viewer_id", viewerID),
+	)
+
+	// 将业务信息添加到context
+	ctx = tracecontext.WithGroupID(ctx, groupID)
+	ctx = tracecontext.WithUserID(ctx, viewerID)
+
+	isMember, err := s.dao.IsMember(ctx, groupID, viewerID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to check membership")
+		return nil, 0, fmt.Errorf("检查群成员身份失败: %v", err)
+	}
+	if !isMember {
+		span.SetStatus(codes.Error, "viewer is not a member")
+		return nil, 0, fmt.Errorf("用户不是群成员")
+	}
+
+	page, pageSize = AnnouncementPage(page, pageSize)
+	announcements, total, err := s.dao.ListAnnouncements(ctx, groupID, pageSize, (page-1)*pageSize)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to list announcements")
+		return nil, 0, fmt.Errorf("获取群公告历史失败: %v", err)
+	}
+
+	span.SetAttributes(attribute.Int64("group.announcement_total", total))
+	span.SetStatus(codes.Ok, "announcements retrieved successfully")
+	return announcements, total, nil
+}
+
+// GetActiveAnnouncement 获取群当前生效的公告，没有生效公告时返回nil
+// 供消息服务的群置顶统一展示调用，成员身份由调用方校验
+func (s *Service) GetActiveAnnouncement(ctx context.Context, groupID int64) (*model.GroupAnnouncement, error) {
+	// 开始OpenTelemetry span
+	ctx, span := telemetry.StartSpan(ctx, "social.service.GetActiveAnnouncement")
+	defer span.End()
+
+	// 设置span属性
+	span.SetAttributes(attribute.Int64("group.id", groupID))
+
+	// 将业务信息添加到context
+	ctx = tracecontext.WithGroupID(ctx, groupID)
+
+	announcement, err := s.dao.GetActiveAnnouncement(ctx, groupID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to get active announcement")
+		return nil, fmt.Errorf("获取群公告失败: %v", err)
+	}
+
+	span.SetAttributes(attribute.Bool("group.has_announcement", announcement != nil))
+	span.SetStatus(codes.Ok, "active announcement retrieved successfully")
+	return announcement, nil
+}
+
+// RevertAnnouncement 将历史公告恢复为生效公告，仅群主和管理员可操作
+func (s *Service) RevertAnnouncement(ctx context.Context, groupID, operatorID, announcementID int64) error {
+	// 开始OpenTelemetry span
+	ctx, span := telemetry.StartSpan(ctx, "social.service.RevertAnnouncement")
+	defer span.End()
+
+	// 设置span属性
+	span.SetAttributes(
+		attribute.Int64("group.id", groupID),
+		attribute.Int64("group.operator_id", operatorID),
+		attribute.Int64("group.announcement_id", announcementID),
+	)
+
+	// 将业务信息添加到context
+	ctx = tracecontext.WithGroupID(ctx, groupID)
+	ctx = tracecontext.WithUserID(ctx, operatorID)
+
+	// 检查权限
+	member, err := s.dao.GetMember(ctx, groupID, operatorID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to get member")
+		return fmt.Errorf("获取成员信息失败: %v", err)
+	}
+	if member.Role != model.RoleOwner && member.Role != model.RoleAdmin {
+		span.SetStatus(codes.Error, "insufficient permissions")
+		return fmt.Errorf("权限不足")
+	}
+
+	announcement, err := s.dao.GetAnnouncement(ctx, groupID, announcementID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to get announcement")
+		return fmt.Errorf("获取群公告失败: %v", err)
+	}
+	if announcement.Active {
+		span.SetStatus(codes.Ok, "announcement already active")
+		return nil
+	}
+
+	if err := s.dao.ActivateAnnouncement(ctx, announcement); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to activate announcement")
+		return fmt.Errorf("恢复群公告失败: %v", err)
+	}
+
+	s.auditor.Record(audit.Entry{
+		ActorID:    operatorID,
+		Action:     audit.ActionAnnouncementRevert,
+		TargetType: audit.TargetGroup,
+		TargetID:   groupID,
+		Detail:     map[string]string{"announcement_id": strconv.FormatInt(announcementID, 10)},
+	})
+
+	s.logger.Info(ctx, "Announcement reverted successfully",
+		logger.F("groupID", groupID),
+		logger.F("operatorID", operatorID),
+		logger.F("announcementID", announcementID))
+
+	span.SetStatus(codes.Ok, "announcement reverted successfully")
+	return nil
+}
+
+// AnnouncementPage 规范公告历史的页码和每页数量，未传时使用默认值，超过上限时截断为上限
+func AnnouncementPage(page, pageSize int) (int, int) {
+	if page <= 0 {
+		page = 1
+	}
+	if pageSize <= 0 {
+		pageSize = model.DefaultPageSize
+	}
+	if pageSize > model.MaxAnnouncementPageSize {
+		pageSize = model.MaxAnnouncementPageSize
+	}
+	return page, pageSize
+}
@@ -0,0 +1,192 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+
+	"goim-social/apps/social-service/internal/model"
+	tracecontext "goim-social/pkg/context"
+	"goim-social/pkg/logger"
+	"goim-social/pkg/telemetry"
+)
+
+// ============ 拉黑管理 ============
+
+// BlockUser 拉黑用户，重复拉黑不报错；关系写库后同步到共享缓存，内容服务据此双向屏蔽
+// 数据库是拉黑关系的唯一来源，写缓存失败不影响拉黑结果，由定期重建修正
+func (s *Service) BlockUser(ctx context.Context, userID, targetID int64) error {
+	// 开始OpenTelemetry span
+	ctx, span := telemetry.StartSpan(ctx, "social.service.BlockUser")
+	defer span.End()
+
+	// 设置span属性
+	span.SetAttributes(
+		attribute.Int64("block.user_id", userID),
+		attribute.Int64("block.target_id", targetID),
+	)
+
+	// 将业务信息添加到context
+	ctx = tracecontext.WithUserID(ctx, userID)
+
+	if userID <= 0 || targetID <= 0 {
+		span.SetStatus(codes.Error, "invalid user ID")
+		return fmt.Errorf("用户ID无效")
+	}
+	if userID == targetID {
+		span.SetStatus(codes.Error, "cannot block self")
+		return fmt.Errorf("不能拉黑自己")
+	}
+
+	if err := s.dao.CreateBlock(ctx, &model.UserBlock{UserID: userID, BlockedID: targetID}); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to create block")
+		return fmt.Errorf("拉黑用户失败: %v", err)
+	}
+	if err := s.blocks.Block(ctx, userID, targetID); err != nil {
+		span.RecordError(err)
+		s.logger.Error(ctx, "写入拉黑关系缓存失败，等待定期重建",
+			logger.F("userID", userID),
+			logger.F("targetID", targetID),
+			logger.F("error", err.Error()))
+	}
+
+	s.logger.Info(ctx, "User blocked",
+		logger.F("userID", userID),
+		logger.F("targetID", targetID))
+
+	span.SetStatus(codes.Ok, "user blocked successfully")
+	return nil
+}
+
+// UnblockUser 取消拉黑，未拉黑时不报错；移除缓存失败不影响结果，由定期重建修正
+func (s *Service) UnblockUser(ctx context.Context, userID, targetID int64) error {
+	// 开始OpenTelemetry span
+	ctx, span := telemetry.StartSpan(ctx, "social.service.UnblockUser")
+	defer span.End()
+
+	// 设置span属性
+	span.SetAttributes(
+		attribute.Int64("block.user_id", userID),
+		attribute.Int64("block.target_id", targetID),
+	)
+
+	// 将业务信息添加到context
+	ctx = tracecontext.WithUserID(ctx, userID)
+
+	if userID <= 0 || targetID <= 0 {
+		span.SetStatus(codes.Error, "invalid user ID")
+		return fmt.Errorf("用户ID无效")
+	}
+
+	if err := s.dao.DeleteBlock(ctx, userID, targetID); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to delete block")
+		return fmt.Errorf("取消拉黑失败: %v", err)
+	}
+	if err := s.blocks.Unblock(ctx, userID, targetID); err != nil {
+		span.RecordError(err)
+		s.logger.Error(ctx, "移除拉黑关系缓存失败，等待定期重建",
+			logger.F("userID", userID),
+			logger.F("targetID", targetID),
+			logger.F("error", err.Error()))
+	}
+
+	s.logger.Info(ctx, "User unblocked",
+		logger.F("userID", userID),
+		logger.F("targetID", targetID))
+
+	span.SetStatus(codes.Ok, "user unblocked successfully")
+	return nil
+}
+
+// ListBlockedUsers 获取用户的黑名单
+func (s *Service) ListBlockedUsers(ctx context.Context, userID int64) ([]*model.UserBlock, error) {
+	// 开始OpenTelemetry span
+	ctx, span := telemetry.StartSpan(ctx, "social.service.ListBlockedUsers")
+	defer span.End()
+
+	// 设置span属性
+	span.SetAttributes(attribute.Int64("block.user_id", userID))
+
+	// 将业务信息添加到context
+	ctx = tracecontext.WithUserID(ctx, userID)
+
+	blocks, err := s.dao.ListBlocks(ctx, userID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to list blocks")
+		return nil, fmt.Errorf("获取黑名单失败: %v", err)
+	}
+
+	span.SetAttributes(attribute.Int("block.count", len(blocks)))
+	span.SetStatus(codes.Ok, "blocked users retrieved successfully")
+	return blocks, nil
+}
+
+// StartBlockCacheSync 启动时和之后定期从数据库重建拉黑关系缓存，修正写缓存失败或缓存丢失造成的偏差
+func (s *Service) StartBlockCacheSync(ctx context.Context) {
+	s.syncBlockCache(ctx)
+
+	ticker := time.NewTicker(time.Duration(model.BlockCacheSyncInterval) * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.syncBlockCache(ctx)
+		}
+	}
+}
+
+// syncBlockCache 用数据库中的全量拉黑关系重建缓存，缓存中数据库已不存在的关系一并移除
+// 重建期间并发的拉黑和取消拉黑可能被快照覆盖，下一轮重建修正
+func (s *Service) syncBlockCache(ctx context.Context) {
+	blocks := make(map[int64][]int64)
+	blockedBy := make(map[int64][]int64)
+	var afterID int64
+	for {
+		batch, err := s.dao.ListBlocksAfter(ctx, afterID, model.BlockCacheSyncBatchSize)
+		if err != nil {
+			s.logger.Error(ctx, "读取拉黑关系失败，跳过本轮缓存重建", logger.F("error", err.Error()))
+			return
+		}
+		for _, block := range batch {
+			blocks[block.UserID] = append(blocks[block.UserID], block.BlockedID)
+			blockedBy[block.BlockedID] = append(blockedBy[block.BlockedID], block.UserID)
+		}
+		if len(batch) < model.BlockCacheSyncBatchSize {
+			break
+		}
+		afterID = batch[len(batch)-1].ID
+	}
+
+	users, err := s.blocks.CachedUsers(ctx)
+	if err != nil {
+		s.logger.Error(ctx, "扫描拉黑关系缓存失败，跳过本轮缓存重建", logger.F("error", err.Error()))
+		return
+	}
+	for userID := range blocks {
+		users[userID] = true
+	}
+	for userID := range blockedBy {
+		users[userID] = true
+	}
+
+	failed := 0
+	for userID := range users {
+		if err := s.blocks.Replace(ctx, userID, blocks[userID], blockedBy[userID]); err != nil {
+			failed++
+		}
+	}
+	if failed > 0 {
+		s.logger.Error(ctx, "重建拉黑关系缓存部分失败",
+			logger.F("users", len(users)),
+			logger.F("failed", failed))
+	}
+}
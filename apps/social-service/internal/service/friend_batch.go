@@ -0,0 +1,293 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	goredis "github.com/go-redis/redis/v8"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+
+	"goim-social/api/rest"
+	"goim-social/apps/social-service/internal/model"
+	tracecontext "goim-social/pkg/context"
+	"goim-social/pkg/httpx"
+	"goim-social/pkg/logger"
+	"goim-social/pkg/redis"
+	"goim-social/pkg/telemetry"
+)
+
+// ============ 批量添加好友 ============
+// 按用户ID或用户名（经用户服务解析）逐个发送好友申请，每个目标单独返回处理结果，单个目标失败不影响其他目标。
+// 申请数与单个申请共用每小时计数；注册满BulkMinAccountDays天且状态正常的账号视为已认证，适用更高的BulkHourlyLimit。
+// 请求以客户端请求ID幂等：处理期间持有请求锁，处理结果在Redis中保留一天，重试返回首次的结果，不会重复计数。
+// 按用户名添加与精确用户名搜索一致：关闭了搜索可见性的用户与不存在的用户返回相同的结果，不发送申请，也不返回用户ID
+
+// BatchAddFriends 批量发送好友申请，返回每个目标的处理结果，顺序与请求中的用户ID、用户名一致
+func (s *Service) BatchAddFriends(ctx context.Context, applicantID int64, friendIDs []int64, usernames []string, remark, clientRequestID string) ([]*model.FriendBatchResult, error) {
+	// 开始OpenTelemetry span
+	ctx, span := telemetry.StartSpan(ctx, "social.service.BatchAddFriends")
+	defer span.End()
+
+	// 设置span属性
+	span.SetAttributes(
+		attribute.Int64("friend.applicant_id", applicantID),
+		attribute.Int("friend.target_ids", len(friendIDs)),
+		attribute.Int("friend.target_usernames", len(usernames)),
+		attribute.String("friend.client_request_id", clientRequestID),
+	)
+
+	// 将业务信息添加到context
+	ctx = tracecontext.WithUserID(ctx, applicantID)
+
+	clientRequestID = strings.TrimSpace(clientRequestID)
+	friendIDs, usernames = dedupFriendTargets(friendIDs, usernames)
+	if err := s.validateFriendBatch(applicantID, friendIDs, usernames, clientRequestID); err != nil {
+		span.SetStatus(codes.Error, "invalid batch request")
+		return nil, httpx.InvalidArgument(err)
+	}
+
+	// Redis不可用时无法保证幂等，直接处理
+	if s.redis == nil {
+		results, err := s.addFriendBatch(ctx, applicantID, friendIDs, usernames, remark)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, "failed to add friends")
+			return nil, err
+		}
+		span.SetStatus(codes.Ok, "friend batch processed")
+		return results, nil
+	}
+
+	var results []*model.FriendBatchResult
+	lockKey := fmt.Sprintf("%s:%d:%s", model.CacheKeyFriendBatchLock, applicantID, clientRequestID)
+	lockOpts := redis.LockOptions{TTL: model.FriendBatchLockTTLSeconds * time.Second}
+	err := s.redis.WithLock(ctx, lockKey, lockOpts, func(ctx context.Context) error {
+		resultKey := fmt.Sprintf("%s:%d:%s", model.CacheKeyFriendBatchResult, applicantID, clientRequestID)
+		cached, err := s.loadFriendBatchResult(ctx, resultKey)
+		if err != nil {
+			return err
+		}
+		if cached != nil {
+			span.SetAttributes(attribute.Bool("friend.replayed", true))
+			results = cached
+			return nil
+		}
+
+		results, err = s.addFriendBatch(ctx, applicantID, friendIDs, usernames, remark)
+		if err != nil {
+			return err
+		}
+		s.saveFriendBatchResult(ctx, resultKey, results)
+		return nil
+	})
+	if errors.Is(err, redis.ErrLockNotObtained) {
+		err = httpx.Conflict(fmt.Errorf("相同请求ID的批量添加正在处理，请稍后重试"))
+	}
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to add friends")
+		return nil, err
+	}
+
+	span.SetAttributes(attribute.Int("result.count", len(results)))
+	span.SetStatus(codes.Ok, "friend batch processed")
+	return results, nil
+}
+
+// validateFriendBatch 校验批量添加的申请人、目标数和客户端请求ID
+func (s *Service) validateFriendBatch(applicantID int64, friendIDs []int64, usernames []string, clientRequestID string) error {
+	if applicantID <= 0 {
+		return fmt.Errorf("用户ID无效")
+	}
+	if clientRequestID == "" || len(clientRequestID) > model.MaxClientRequestIDLength {
+		return fmt.Errorf("客户端请求ID不能为空且不超过%d个字符", model.MaxClientRequestIDLength)
+	}
+	total := len(friendIDs) + len(usernames)
+	if total == 0 {
+		return fmt.Errorf("添加目标不能为空")
+	}
+	if max := s.friendCfg.BatchMaxTargets; max > 0 && total > max {
+		return fmt.Errorf("单次最多添加%d个目标", max)
+	}
+	return nil
+}
+
+// addFriendBatch 解析目标用户并逐个发送好友申请
+func (s *Service) addFriendBatch(ctx context.Context, applicantID int64, friendIDs []int64, usernames []string, remark string) ([]*model.FriendBatchResult, error) {
+	usersByID, usersByName, err := s.resolveFriendTargets(ctx, applicantID, friendIDs, usernames)
+	if err != nil {
+		return nil, err
+	}
+
+	limit := s.friendCfg.RequestHourlyLimit
+	if s.isVerifiedForBulk(usersByID[applicantID]) && s.friendCfg.BulkHourlyLimit > limit {
+		limit = s.friendCfg.BulkHourlyLimit
+	}
+
+	results := make([]*model.FriendBatchResult, 0, len(friendIDs)+len(usernames))
+	processed := make(map[int64]*model.FriendBatchResult)
+	add := func(result *model.FriendBatchResult, user *rest.UserInfo) {
+		results = append(results, result)
+		if user == nil {
+			result.Status = model.FriendBatchStatusNotFound
+			result.Message = "用户不存在"
+			return
+		}
+		result.FriendID = user.Id
+		if prev, ok := processed[user.Id]; ok {
+			// 用户ID和用户名指向同一用户时只发送一次，沿用前一次的结果
+			result.Status, result.Message = prev.Status, prev.Message
+			return
+		}
+		processed[user.Id] = result
+		s.sendBatchFriendRequest(ctx, applicantID, user.Id, remark, limit, result)
+	}
+
+	for _, friendID := range friendIDs {
+		add(&model.FriendBatchResult{FriendID: friendID}, friendTarget(usersByID[friendID], false))
+	}
+	for _, username := range usernames {
+		add(&model.FriendBatchResult{Username: username}, friendTarget(usersByName[username], true))
+	}
+
+	sent := 0
+	for _, result := range results {
+		if result.Status == model.FriendBatchStatusSent {
+			sent++
+		}
+	}
+	s.logger.Info(ctx, "Friend batch processed",
+		logger.F("applicantID", applicantID),
+		logger.F("targets", len(results)),
+		logger.F("sent", sent),
+		logger.F("hourlyLimit", limit))
+	return results, nil
+}
+
+// sendBatchFriendRequest 向单个目标发送好友申请并记录结果
+func (s *Service) sendBatchFriendRequest(ctx context.Context, applicantID, userID int64, remark string, hourlyLimit int, result *model.FriendBatchResult) {
+	if userID == applicantID {
+		result.Status = model.FriendBatchStatusInvalid
+		result.Message = "不能添加自己为好友"
+		return
+	}
+
+	if _, err := s.createFriendRequest(ctx, applicantID, userID, remark, false, hourlyLimit); err != nil {
+		result.Message = err.Error()
+		result.Status = FriendRequestReason(err)
+		if result.Status == "" {
+			result.Status = model.FriendBatchStatusFailed
+			s.logger.Warn(ctx, "Failed to send friend request in batch",
+				logger.F("applicantID", applicantID),
+				logger.F("userID", userID),
+				logger.F("error", err.Error()))
+		}
+		return
+	}
+	result.Status = model.FriendBatchStatusSent
+}
+
+// resolveFriendTargets 通过用户服务查询目标用户和申请人，已注销的用户不在结果中
+func (s *Service) resolveFriendTargets(ctx context.Context, applicantID int64, friendIDs []int64, usernames []string) (map[int64]*rest.UserInfo, map[string]*rest.UserInfo, error) {
+	if s.users == nil {
+		return nil, nil, httpx.Unavailable(fmt.Errorf("用户服务不可用"))
+	}
+
+	resp, err := s.users.BatchGetUsers(ctx, &rest.BatchGetUsersRequest{
+		UserIds:   append([]int64{applicantID}, friendIDs...),
+		Usernames: usernames,
+	})
+	if err != nil {
+		return nil, nil, httpx.Unavailable(fmt.Errorf("查询用户失败: %v", err))
+	}
+	if !resp.Success {
+		return nil, nil, httpx.Unavailable(fmt.Errorf("查询用户失败: %s", resp.Message))
+	}
+
+	usersByID := make(map[int64]*rest.UserInfo, len(resp.Users))
+	usersByName := make(map[string]*rest.UserInfo, len(resp.Users))
+	for _, user := range resp.Users {
+		usersByID[user.Id] = user
+		usersByName[user.Username] = user
+	}
+	return usersByID, usersByName, nil
+}
+
+// friendTarget 返回可以添加为好友的目标用户，不可添加时返回nil
+// 禁用和注销的用户不能添加；按用户名添加时关闭了搜索可见性的用户视为不存在，避免通过批量添加探测用户名
+func friendTarget(user *rest.UserInfo, byUsername bool) *rest.UserInfo {
+	if user == nil || user.Status != model.UserStatusNormal {
+		return nil
+	}
+	if byUsername && user.Discoverability == model.UserDiscoverabilityNone {
+		return nil
+	}
+	return user
+}
+
+// isVerifiedForBulk 注册满BulkMinAccountDays天且状态正常的账号可使用批量申请上限
+func (s *Service) isVerifiedForBulk(applicant *rest.UserInfo) bool {
+	if applicant == nil || applicant.Status != model.UserStatusNormal || s.friendCfg.BulkMinAccountDays <= 0 {
+		return false
+	}
+	registeredAt := time.Unix(applicant.CreatedAt, 0)
+	return time.Since(registeredAt) >= time.Duration(s.friendCfg.BulkMinAccountDays)*24*time.Hour
+}
+
+// loadFriendBatchResult 读取已处理请求的结果，未处理过时返回nil
+func (s *Service) loadFriendBatchResult(ctx context.Context, key string) ([]*model.FriendBatchResult, error) {
+	data, err := s.redis.Get(ctx, key)
+	if err != nil {
+		if err == goredis.Nil {
+			return nil, nil
+		}
+		return nil, httpx.Unavailable(fmt.Errorf("读取批量添加结果失败: %v", err))
+	}
+
+	var results []*model.FriendBatchResult
+	if err := json.Unmarshal([]byte(data), &results); err != nil {
+		return nil, fmt.Errorf("解析批量添加结果失败: %v", err)
+	}
+	return results, nil
+}
+
+// saveFriendBatchResult 保存处理结果供重试时返回，保存失败只记录日志
+func (s *Service) saveFriendBatchResult(ctx context.Context, key string, results []*model.FriendBatchResult) {
+	data, err := json.Marshal(results)
+	if err == nil {
+		err = s.redis.Set(ctx, key, string(data), model.FriendBatchResultTTL*time.Second)
+	}
+	if err != nil {
+		s.logger.Warn(ctx, "Failed to save friend batch result",
+			logger.F("key", key),
+			logger.F("error", err.Error()))
+	}
+}
+
+// dedupFriendTargets 去除重复和无效的目标，保持请求中的顺序
+func dedupFriendTargets(friendIDs []int64, usernames []string) ([]int64, []string) {
+	ids := make([]int64, 0, len(friendIDs))
+	seenIDs := make(map[int64]bool, len(friendIDs))
+	for _, id := range friendIDs {
+		if id > 0 && !seenIDs[id] {
+			seenIDs[id] = true
+			ids = append(ids, id)
+		}
+	}
+
+	names := make([]string, 0, len(usernames))
+	seenNames := make(map[string]bool, len(usernames))
+	for _, name := range usernames {
+		name = strings.TrimSpace(name)
+		if name != "" && !seenNames[name] {
+			seenNames[name] = true
+			names = append(names, name)
+		}
+	}
+	return ids, names
+}
@@ -0,0 +1,108 @@
+package service
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"google.golang.org/grpc"
+
+	"goim-social/api/rest"
+	"goim-social/apps/social-service/internal/model"
+	"goim-social/pkg/logger"
+)
+
+// batchUsers 按用户ID和用户名返回固定用户的用户服务客户端
+type batchUsers struct {
+	rest.UserServiceClient
+	users []*rest.UserInfo
+}
+
+func (u *batchUsers) BatchGetUsers(ctx context.Context, req *rest.BatchGetUsersRequest, opts ...grpc.CallOption) (*rest.BatchGetUsersResponse, error) {
+	ids := make(map[int64]bool, len(req.UserIds))
+	for _, id := range req.UserIds {
+		ids[id] = true
+	}
+	names := make(map[string]bool, len(req.Usernames))
+	for _, name := range req.Usernames {
+		names[name] = true
+	}
+
+	resp := &rest.BatchGetUsersResponse{Success: true}
+	for _, user := range u.users {
+		if ids[user.Id] || names[user.Username] {
+			resp.Users = append(resp.Users, user)
+		}
+	}
+	return resp, nil
+}
+
+// TestFriendTarget 禁用的用户不可添加，关闭搜索可见性的用户只能按用户ID添加
+func TestFriendTarget(t *testing.T) {
+	normal := &rest.UserInfo{Id: 1, Username: "alice", Status: model.UserStatusNormal, Discoverability: "everyone"}
+	exact := &rest.UserInfo{Id: 2, Username: "bob", Status: model.UserStatusNormal, Discoverability: "exact_username"}
+	hidden := &rest.UserInfo{Id: 3, Username: "carol", Status: model.UserStatusNormal, Discoverability: model.UserDiscoverabilityNone}
+	disabled := &rest.UserInfo{Id: 4, Username: "dave", Status: 1, Discoverability: "everyone"}
+
+	cases := []struct {
+		name       string
+		user       *rest.UserInfo
+		byUsername bool
+		want       *rest.UserInfo
+	}{
+		{name: "missing", user: nil, byUsername: true, want: nil},
+		{name: "normal by username", user: normal, byUsername: true, want: normal},
+		{name: "exact username", user: exact, byUsername: true, want: exact},
+		{name: "hidden by username", user: hidden, byUsername: true, want: nil},
+		{name: "hidden by id", user: hidden, byUsername: false, want: hidden},
+		{name: "disabled by id", user: disabled, byUsername: false, want: nil},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := friendTarget(tc.user, tc.byUsername); got != tc.want {
+				t.Errorf("friendTarget() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+// TestAddFriendBatchHiddenUsername 按用户名添加关闭了搜索可见性的用户与不存在的用户结果相同
+func TestAddFriendBatchHiddenUsername(t *testing.T) {
+	log, err := logger.NewLogger("error")
+	if err != nil {
+		t.Fatalf("创建日志失败: %v", err)
+	}
+	users := &batchUsers{users: []*rest.UserInfo{
+		{Id: 100, Username: "applicant", Status: model.UserStatusNormal},
+		{Id: 3, Username: "carol", Status: model.UserStatusNormal, Discoverability: model.UserDiscoverabilityNone},
+	}}
+	s := &Service{users: users, logger: log}
+
+	results, err := s.addFriendBatch(context.Background(), 100, nil, []string{"carol", "nobody"}, "")
+	if err != nil {
+		t.Fatalf("addFriendBatch returned error: %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("got %d results, want 2", len(results))
+	}
+
+	hidden, missing := *results[0], *results[1]
+	if hidden.Status != model.FriendBatchStatusNotFound || hidden.FriendID != 0 {
+		t.Errorf("hidden user result = %+v, want not_found without friend ID", hidden)
+	}
+	hidden.Username, missing.Username = "", ""
+	if !reflect.DeepEqual(hidden, missing) {
+		t.Errorf("hidden user result %+v differs from missing user result %+v", hidden, missing)
+	}
+}
+
+// TestDedupFriendTargets 去除重复、非正数ID和空用户名，保持请求顺序
+func TestDedupFriendTargets(t *testing.T) {
+	ids, names := dedupFriendTargets([]int64{3, 0, 1, 3, -2}, []string{" bob ", "alice", "bob", ""})
+	if want := []int64{3, 1}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("ids = %v, want %v", ids, want)
+	}
+	if want := []string{"bob", "alice"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("names = %v, want %v", names, want)
+	}
+}
@@ -0,0 +1,99 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+
+	"goim-social/apps/social-service/internal/model"
+	tracecontext "goim-social/pkg/context"
+	"goim-social/pkg/logger"
+	"goim-social/pkg/telemetry"
+)
+
+// ============ 好友关系历史 ============
+
+// GetFriendHistory 获取与某个用户的好友关系历史，包含已删除的好友期
+func (s *Service) GetFriendHistory(ctx context.Context, userID, friendID int64) ([]*model.Friend, error) {
+	// 开始OpenTelemetry span
+	ctx, span := telemetry.StartSpan(ctx, "social.service.GetFriendHistory")
+	defer span.End()
+
+	// 设置span属性
+	span.SetAttributes(
+		attribute.Int64("friend.user_id", userID),
+		attribute.Int64("friend.friend_id", friendID),
+	)
+
+	// 将业务信息添加到context
+	ctx = tracecontext.WithUserID(ctx, userID)
+
+	history, err := s.dao.ListFriendHistory(ctx, userID, friendID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to get friend history")
+		return nil, fmt.Errorf("获取好友关系历史失败: %v", err)
+	}
+
+	span.SetAttributes(attribute.Int("friend.history_count", len(history)))
+	span.SetStatus(codes.Ok, "friend history retrieved successfully")
+	return history, nil
+}
+
+// restoreFriendRemark 重新添加好友时确定备注，已指定备注或未要求恢复时原样返回
+func (s *Service) restoreFriendRemark(ctx context.Context, userID, friendID int64, remark string, restore bool) string {
+	if remark != "" || !restore {
+		return remark
+	}
+
+	previous, err := s.dao.GetLastDeletedFriend(ctx, userID, friendID)
+	if err != nil {
+		// 恢复备注失败不影响添加好友
+		s.logger.Warn(ctx, "获取上次好友关系失败，不恢复备注",
+			logger.F("userID", userID),
+			logger.F("friendID", friendID),
+			logger.F("error", err.Error()))
+		return remark
+	}
+	if previous == nil {
+		return remark
+	}
+	return previous.Remark
+}
+
+// StartFriendHistoryPurger 定期彻底清除超过保留期的已删除好友关系，保留天数为0时不清除
+func (s *Service) StartFriendHistoryPurger(ctx context.Context) {
+	if s.friendCfg.HistoryRetentionDays <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(time.Duration(model.FriendHistoryPurgeInterval) * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.purgeFriendHistory(ctx)
+		}
+	}
+}
+
+// purgeFriendHistory 清除一次超过保留期的已删除好友关系
+func (s *Service) purgeFriendHistory(ctx context.Context) {
+	before := time.Now().AddDate(0, 0, -s.friendCfg.HistoryRetentionDays)
+	purged, err := s.dao.PurgeDeletedFriends(ctx, before)
+	if err != nil {
+		s.logger.Error(ctx, "清除已删除好友关系失败", logger.F("error", err.Error()))
+		return
+	}
+	if purged > 0 {
+		s.logger.Info(ctx, "已清除过期的已删除好友关系",
+			logger.F("count", purged),
+			logger.F("before", before.Format(time.RFC3339)))
+	}
+}
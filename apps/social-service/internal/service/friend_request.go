@@ -0,0 +1,106 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"goim-social/apps/social-service/internal/model"
+	"goim-social/pkg/httpx"
+	"goim-social/pkg/logger"
+)
+
+// 好友申请被拒绝的错误，由FriendRequestReason转换为响应中的原因
+var (
+	ErrFriendRequestPending     = errors.New("已有待处理的好友申请")
+	ErrAlreadyFriends           = errors.New("已经是好友关系")
+	ErrFriendRequestBlocked     = errors.New("你与对方存在拉黑关系，无法发送好友申请")
+	ErrFriendRequestRateLimited = errors.New("好友申请过于频繁，请稍后再试")
+)
+
+// FriendRequestReason 返回好友申请被拒绝的原因，其他错误返回空字符串
+func FriendRequestReason(err error) string {
+	switch {
+	case errors.Is(err, ErrFriendRequestPending):
+		return model.FriendRequestReasonPending
+	case errors.Is(err, ErrAlreadyFriends):
+		return model.FriendRequestReasonFriends
+	case errors.Is(err, ErrFriendRequestBlocked):
+		return model.FriendRequestReasonBlocked
+	case errors.Is(err, ErrFriendRequestRateLimited):
+		return model.FriendRequestReasonRateLimited
+	default:
+		return ""
+	}
+}
+
+// createFriendRequest 校验拉黑、好友关系、待处理申请和申请频率后创建好友申请，hourlyLimit为本次适用的每小时上限
+// 已有待处理的申请时沿用该申请，不重复创建，也不计入申请次数
+func (s *Service) createFriendRequest(ctx context.Context, applicantID, userID int64, remark string, restoreRemark bool, hourlyLimit int) (*model.FriendApply, error) {
+	// 任一方拉黑对方时不能申请
+	blocked, err := s.dao.HasBlockBetween(ctx, applicantID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("检查拉黑关系失败: %v", err)
+	}
+	if blocked {
+		return nil, httpx.PermissionDenied(ErrFriendRequestBlocked)
+	}
+
+	// 检查是否已经是好友
+	isFriend, err := s.dao.IsFriend(ctx, applicantID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("检查好友关系失败: %v", err)
+	}
+	if isFriend {
+		return nil, httpx.Conflict(ErrAlreadyFriends)
+	}
+
+	existingApply, err := s.dao.GetFriendApply(ctx, userID, applicantID)
+	if err != nil {
+		return nil, fmt.Errorf("检查申请记录失败: %v", err)
+	}
+	if existingApply != nil && existingApply.Status == model.FriendApplyStatusPending {
+		return nil, httpx.Conflict(ErrFriendRequestPending)
+	}
+
+	if err := s.checkFriendRequestRate(ctx, applicantID, hourlyLimit); err != nil {
+		return nil, err
+	}
+
+	// 创建好友申请
+	apply := &model.FriendApply{
+		UserID:        userID,
+		ApplicantID:   applicantID,
+		Remark:        remark,
+		Status:        model.FriendApplyStatusPending,
+		RestoreRemark: restoreRemark,
+	}
+	if err := s.dao.CreateFriendApply(ctx, apply); err != nil {
+		return nil, fmt.Errorf("创建好友申请失败: %v", err)
+	}
+	return apply, nil
+}
+
+// checkFriendRequestRate 按小时限制用户发送的好友申请数，计数失败时放行
+func (s *Service) checkFriendRequestRate(ctx context.Context, applicantID int64, limit int) error {
+	if s.redis == nil || limit <= 0 {
+		return nil
+	}
+
+	key := fmt.Sprintf("%s:%d", model.CacheKeyFriendRequestRate, applicantID)
+	count, err := s.redis.GetClient().Incr(ctx, key).Result()
+	if err != nil {
+		s.logger.Warn(ctx, "Failed to check friend request rate",
+			logger.F("applicantID", applicantID),
+			logger.F("error", err.Error()))
+		return nil
+	}
+	if count == 1 {
+		s.redis.Expire(ctx, key, model.FriendRequestRateWindow*time.Second)
+	}
+	if count > int64(limit) {
+		return httpx.TooManyRequests(fmt.Errorf("%w，每小时最多发送%d个", ErrFriendRequestRateLimited, limit))
+	}
+	return nil
+}
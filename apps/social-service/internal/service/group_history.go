@@ -0,0 +1,105 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+
+	"goim-social/apps/social-service/internal/model"
+	"goim-social/pkg/audit"
+	tracecontext "goim-social/pkg/context"
+	"goim-social/pkg/httpx"
+	"goim-social/pkg/logger"
+	"goim-social/pkg/telemetry"
+)
+
+// ============ 群消息历史可见范围 ============
+// 每次入群记录一条成员身份时段，退群时写入退群时间，重新入群开启新的时段。消息服务和搜索服务按时段
+// 限制群消息搜索：成员只能搜到在群期间的消息；群组允许新成员查看历史时，第一个时段从群组创建开始。
+// 群主和管理员可修改该设置，修改对已有成员同样生效
+
+// SetGroupHistoryVisibility 设置新成员能否查看和搜索入群前的历史消息，仅群主和管理员可设置
+func (s *Service) SetGroupHistoryVisibility(ctx context.Context, groupID, operatorID int64, visible bool) error {
+	// 开始OpenTelemetry span
+	ctx, span := telemetry.StartSpan(ctx, "social.service.SetGroupHistoryVisibility")
+	defer span.End()
+
+	// 设置span属性
+	span.SetAttributes(
+		attribute.Int64("group.id", groupID),
+		attribute.Int64("group.operator_id", operatorID),
+		attribute.Bool("group.new_members_see_history", visible),
+	)
+
+	// 将业务信息添加到context
+	ctx = tracecontext.WithGroupID(ctx, groupID)
+	ctx = tracecontext.WithUserID(ctx, operatorID)
+
+	// 检查权限
+	member, err := s.dao.GetMember(ctx, groupID, operatorID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to get member")
+		return fmt.Errorf("获取成员信息失败: %v", err)
+	}
+	if member.Role != model.RoleOwner && member.Role != model.RoleAdmin {
+		span.SetStatus(codes.Error, "insufficient permissions")
+		return httpx.PermissionDenied(fmt.Errorf("权限不足"))
+	}
+
+	if err := s.dao.UpdateGroupHistoryVisibility(ctx, groupID, visible); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to update history visibility")
+		return fmt.Errorf("设置历史消息可见范围失败: %v", err)
+	}
+
+	s.auditor.Record(audit.Entry{
+		ActorID:    operatorID,
+		Action:     audit.ActionGroupHistoryVisibility,
+		TargetType: audit.TargetGroup,
+		TargetID:   groupID,
+		Detail: map[string]string{
+			"new_members_see_history": strconv.FormatBool(visible),
+		},
+	})
+
+	s.logger.Info(ctx, "Group history visibility updated",
+		logger.F("groupID", groupID),
+		logger.F("operatorID", operatorID),
+		logger.F("visible", visible))
+
+	span.SetStatus(codes.Ok, "group history visibility updated successfully")
+	return nil
+}
+
+// GetMembershipPeriods 获取用户的群成员身份时段，groupID为0时返回用户在所有群组的时段
+func (s *Service) GetMembershipPeriods(ctx context.Context, userID, groupID int64) ([]*model.GroupMembershipPeriod, error) {
+	// 开始OpenTelemetry span
+	ctx, span := telemetry.StartSpan(ctx, "social.service.GetMembershipPeriods")
+	defer span.End()
+
+	// 设置span属性
+	span.SetAttributes(
+		attribute.Int64("group.user_id", userID),
+		attribute.Int64("group.id", groupID),
+	)
+
+	if userID <= 0 {
+		span.SetStatus(codes.Error, "invalid user ID")
+		return nil, httpx.InvalidArgument(fmt.Errorf("用户ID无效"))
+	}
+
+	periods, err := s.dao.GetMembershipPeriods(ctx, userID, groupID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to get membership periods")
+		return nil, fmt.Errorf("获取成员身份时段失败: %v", err)
+	}
+
+	span.SetAttributes(attribute.Int("result.count", len(periods)))
+	span.SetStatus(codes.Ok, "membership periods retrieved successfully")
+	return periods, nil
+}
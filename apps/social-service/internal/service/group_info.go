@@ -0,0 +1,266 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+
+	"goim-social/api/rest"
+	"goim-social/apps/social-service/internal/model"
+	"goim-social/pkg/audit"
+	tracecontext "goim-social/pkg/context"
+	"goim-social/pkg/httpx"
+	"goim-social/pkg/logger"
+	"goim-social/pkg/outbox"
+	"goim-social/pkg/telemetry"
+)
+
+// ============ 群资料修改 ============
+// 群主和管理员可修改群名称、简介和头像。修改按资料版本号做乐观并发控制：客户端带上看到的版本号，
+// 与当前版本不一致时返回冲突，需重新获取群资料后再修改；未带版本号时以服务端读取到的版本为准，
+// 读取与写入之间的并发修改同样不会被覆盖。修改成功后记录审计，发布群组索引事件供搜索服务重建索引，
+// 并向在线成员推送新资料
+
+// GroupInfoUpdate 群资料修改参数，字段为空时保持不变
+type GroupInfoUpdate struct {
+	Name        string
+	Description string
+	Avatar      string
+	Version     int64 // 客户端看到的资料版本号，0表示不校验
+}
+
+// UpdateGroupInfo 修改群名称、简介和头像，返回修改后的群组
+func (s *Service) UpdateGroupInfo(ctx context.Context, groupID, operatorID int64, update GroupInfoUpdate) (*model.Group, error) {
+	// 开始OpenTelemetry span
+	ctx, span := telemetry.StartSpan(ctx, "social.service.UpdateGroupInfo")
+	defer span.End()
+
+	// 设置span属性
+	span.SetAttributes(
+		attribute.Int64("group.id", groupID),
+		attribute.Int64("group.operator_id", operatorID),
+		attribute.Int64("group.expected_version", update.Version),
+	)
+
+	// 将业务信息添加到context
+	ctx = tracecontext.WithGroupID(ctx, groupID)
+	ctx = tracecontext.WithUserID(ctx, operatorID)
+
+	update.Name = strings.TrimSpace(update.Name)
+	update.Description = strings.TrimSpace(update.Description)
+	update.Avatar = strings.TrimSpace(update.Avatar)
+	if err := validateGroupInfoUpdate(update); err != nil {
+		span.SetStatus(codes.Error, "invalid group info")
+		return nil, httpx.InvalidArgument(err)
+	}
+
+	// 检查权限
+	member, err := s.dao.GetMember(ctx, groupID, operatorID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to get member")
+		return nil, fmt.Errorf("获取成员信息失败: %v", err)
+	}
+	if member.Role != model.RoleOwner && member.Role != model.RoleAdmin {
+		span.SetStatus(codes.Error, "insufficient permissions")
+		return nil, httpx.PermissionDenied(fmt.Errorf("权限不足"))
+	}
+
+	group, err := s.dao.GetGroup(ctx, groupID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to get group")
+		return nil, fmt.Errorf("获取群组信息失败: %v", err)
+	}
+	if update.Version > 0 && update.Version != group.Version {
+		span.SetStatus(codes.Error, "group version conflict")
+		return nil, httpx.Conflict(fmt.Errorf("群资料已被修改，请刷新后重试"))
+	}
+
+	before := *group
+	if update.Name != "" {
+		group.Name = update.Name
+	}
+	if update.Description != "" {
+		group.Description = update.Description
+	}
+	if update.Avatar != "" {
+		group.Avatar = update.Avatar
+	}
+	if group.Name == before.Name && group.Description == before.Description && group.Avatar == before.Avatar {
+		span.SetStatus(codes.Ok, "group info unchanged")
+		return group, nil
+	}
+	group.UpdatedAt = time.Now()
+
+	updated, err := s.dao.UpdateGroupInfo(ctx, group, before.Version, groupIndexEvent(group))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to update group info")
+		return nil, fmt.Errorf("修改群资料失败: %v", err)
+	}
+	if !updated {
+		span.SetStatus(codes.Error, "group version conflict")
+		return nil, httpx.Conflict(fmt.Errorf("群资料已被修改，请刷新后重试"))
+	}
+
+	s.recordGroupInfoChange(operatorID, &before, group)
+	s.relay.Notify()
+	s.notifyGroupInfoUpdated(ctx, group, operatorID)
+
+	s.logger.Info(ctx, "Group info updated successfully",
+		logger.F("groupID", groupID),
+		logger.F("operatorID", operatorID),
+		logger.F("version", group.Version))
+
+	span.SetAttributes(attribute.Int64("group.version", group.Version))
+	span.SetStatus(codes.Ok, "group info updated successfully")
+	return group, nil
+}
+
+// validateGroupInfoUpdate 校验群资料长度和头像地址格式
+func validateGroupInfoUpdate(update GroupInfoUpdate) error {
+	if update.Name == "" && update.Description == "" && update.Avatar == "" {
+		return fmt.Errorf("没有需要修改的群资料")
+	}
+	if len([]rune(update.Name)) > model.MaxGroupNameLength {
+		return fmt.Errorf("群名称过长，最多%d个字符", model.MaxGroupNameLength)
+	}
+	if len([]rune(update.Description)) > model.MaxGroupDescriptionLength {
+		return fmt.Errorf("群简介过长，最多%d个字符", model.MaxGroupDescriptionLength)
+	}
+	if update.Avatar != "" {
+		if len(update.Avatar) > model.MaxGroupAvatarLength {
+			return fmt.Errorf("头像地址过长")
+		}
+		u, err := url.Parse(update.Avatar)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("头像地址格式无效，须为http或https地址")
+		}
+	}
+	return nil
+}
+
+// recordGroupInfoChange 将修改前后的群资料写入群组的审计记录，作为群资料修改历史
+func (s *Service) recordGroupInfoChange(operatorID int64, before, after *model.Group) {
+	detail := map[string]string{"version": strconv.FormatInt(after.Version, 10)}
+	if before.Name != after.Name {
+		detail["old_name"] = before.Name
+		detail["new_name"] = after.Name
+	}
+	if before.Description != after.Description {
+		detail["old_description"] = before.Description
+		detail["new_description"] = after.Description
+	}
+	if before.Avatar != after.Avatar {
+		detail["old_avatar"] = before.Avatar
+		detail["new_avatar"] = after.Avatar
+	}
+
+	s.auditor.Record(audit.Entry{
+		ActorID:    operatorID,
+		Action:     audit.ActionGroupInfoUpdate,
+		TargetType: audit.TargetGroup,
+		TargetID:   after.ID,
+		Detail:     detail,
+	})
+}
+
+// groupIndexEvent 群组索引事件，与群组变更在同一事务中写入发件箱，按群组ID分区保证同一群组的事件按版本顺序消费
+// 事件在更新后构建，携带更新后的版本号
+func groupIndexEvent(group *model.Group) outbox.Builder {
+	return func() (*outbox.Event, error) {
+		return outbox.NewEvent(model.TopicGroupIndex, strconv.FormatInt(group.ID, 10), &model.GroupIndexEvent{
+			EventType:   model.GroupIndexEventUpdate,
+			ID:          group.ID,
+			Name:        group.Name,
+			Description: group.Description,
+			Avatar:      group.Avatar,
+			OwnerID:     group.OwnerID,
+			MemberCount: group.MemberCount,
+			MaxMembers:  group.MaxMembers,
+			IsPublic:    group.IsPublic,
+			Version:     group.Version,
+			CreatedAt:   group.CreatedAt,
+			UpdatedAt:   group.UpdatedAt,
+		})
+	}
+}
+
+// notifyGroupInfoUpdated 向在线群成员推送新的群资料，离线成员上线后重新获取群资料即可，失败只记录日志
+func (s *Service) notifyGroupInfoUpdated(ctx context.Context, group *model.Group, operatorID int64) {
+	if s.kafka == nil {
+		return
+	}
+
+	onlineIDs, err := s.getOnlineMemberIDs(ctx, group.ID)
+	if err != nil {
+		s.logger.Warn(ctx, "Failed to get online members for group info notice",
+			logger.F("groupID", group.ID),
+			logger.F("error", err.Error()))
+		return
+	}
+
+	content, err := json.Marshal(&model.GroupInfoNotice{
+		GroupID:     group.ID,
+		Name:        group.Name,
+		Description: group.Description,
+		Avatar:      group.Avatar,
+		Version:     group.Version,
+		OperatorID:  operatorID,
+	})
+	if err != nil {
+		return
+	}
+
+	now := time.Now().Unix()
+	for _, userID := range onlineIDs {
+		event := &rest.MessageEvent{
+			Type: model.EventTypeGroupInfoUpdated,
+			Message: &rest.WSMessage{
+				MessageId:   group.Version,
+				From:        operatorID,
+				To:          userID,
+				GroupId:     group.ID,
+				Content:     string(content),
+				MessageType: model.MessageTypeGroupInfoUpdated,
+				Timestamp:   now,
+			},
+			Timestamp: now,
+		}
+		if err := s.kafka.PublishMessage(model.TopicDownlinkMessage, event); err != nil {
+			s.logger.Warn(ctx, "Failed to publish group info notice",
+				logger.F("groupID", group.ID),
+				logger.F("userID", userID),
+				logger.F("error", err.Error()))
+		}
+	}
+}
+
+// getOnlineMemberIDs 获取当前在线的群成员ID，隐身成员同样在线，需要收到资料变更推送
+func (s *Service) getOnlineMemberIDs(ctx context.Context, groupID int64) ([]int64, error) {
+	memberIDs, err := s.dao.GetMemberIDs(ctx, groupID)
+	if err != nil || len(memberIDs) == 0 {
+		return nil, err
+	}
+
+	online, err := s.presence.Online(ctx, memberIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	onlineIDs := make([]int64, 0, len(memberIDs))
+	for _, memberID := range memberIDs {
+		if online[memberID] {
+			onlineIDs = append(onlineIDs, memberID)
+		}
+	}
+	return onlineIDs, nil
+}
@@ -0,0 +1,51 @@
+package service
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"goim-social/apps/social-service/internal/model"
+	"goim-social/pkg/config"
+)
+
+// parseGroupTiers 解析群组等级的成员上限，格式 tier:max，逗号分隔，非法项忽略
+// standard等级使用默认上限，配置中的standard项会覆盖默认上限
+func parseGroupTiers(cfg config.GroupConfig) map[string]int32 {
+	defaultMax := int32(cfg.DefaultMaxMembers)
+	if defaultMax <= 0 {
+		defaultMax = model.DefaultMaxMembers
+	}
+	tiers := map[string]int32{model.GroupTierStandard: defaultMax}
+
+	for _, item := range strings.Split(cfg.Tiers, ",") {
+		name, value, ok := strings.Cut(strings.TrimSpace(item), ":")
+		if !ok {
+			continue
+		}
+		max, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil || max <= 0 {
+			continue
+		}
+		tiers[strings.TrimSpace(name)] = int32(max)
+	}
+	return tiers
+}
+
+// resolveGroupMaxMembers 计算群组成员上限，requested为0时使用等级上限，否则不能超过等级上限
+func (s *Service) resolveGroupMaxMembers(tier string, requested int32) (int32, error) {
+	limit, ok := s.groupTiers[tier]
+	if !ok {
+		return 0, fmt.Errorf("未知的群组等级: %s", tier)
+	}
+	if requested < 0 {
+		return 0, fmt.Errorf("成员上限无效: %d", requested)
+	}
+	if requested == 0 {
+		return limit, nil
+	}
+	if requested > limit {
+		return 0, fmt.Errorf("成员上限超过群组等级上限: %d > %d", requested, limit)
+	}
+	return requested, nil
+}
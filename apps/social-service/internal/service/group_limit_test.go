@@ -0,0 +1,49 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"goim-social/apps/social-service/internal/model"
+	"goim-social/pkg/httpx"
+)
+
+// TestCreateGroupTierRequiresAdmin 普通用户不能创建standard以外等级的群组
+func TestCreateGroupTierRequiresAdmin(t *testing.T) {
+	s := &Service{
+		groupTiers: map[string]int32{model.GroupTierStandard: 500, "large": 2000},
+		admins:     map[int64]bool{1: true},
+	}
+
+	_, err := s.CreateGroup(context.Background(), 2, "group", "", "", true, "", 0, "large", nil)
+	if httpx.StatusOf(err) != http.StatusForbidden {
+		t.Errorf("CreateGroup(tier=large) by non-admin = %v, want permission denied", err)
+	}
+}
+
+// TestResolveGroupMaxMembers 未指定时使用等级上限，指定时不能超过等级上限
+func TestResolveGroupMaxMembers(t *testing.T) {
+	s := &Service{groupTiers: map[string]int32{model.GroupTierStandard: 500, "large": 2000}}
+	cases := []struct {
+		name      string
+		tier      string
+		requested int32
+		want      int32
+		wantErr   bool
+	}{
+		{name: "tier limit", tier: model.GroupTierStandard, want: 500},
+		{name: "requested", tier: "large", requested: 1000, want: 1000},
+		{name: "above limit", tier: model.GroupTierStandard, requested: 501, wantErr: true},
+		{name: "negative", tier: model.GroupTierStandard, requested: -1, wantErr: true},
+		{name: "unknown tier", tier: "super", wantErr: true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := s.resolveGroupMaxMembers(tc.tier, tc.requested)
+			if (err != nil) != tc.wantErr || got != tc.want {
+				t.Errorf("resolveGroupMaxMembers(%s, %d) = %d, %v, want %d, wantErr %v", tc.tier, tc.requested, got, err, tc.want, tc.wantErr)
+			}
+		})
+	}
+}
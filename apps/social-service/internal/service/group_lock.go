@@ -0,0 +1,39 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"goim-social/apps/social-service/internal/model"
+	"goim-social/pkg/httpx"
+	"goim-social/pkg/logger"
+	"goim-social/pkg/redis"
+)
+
+// withGroupMemberLock 持有群成员变更锁执行fn，同一群组的加群、退群和群主转让在多个实例间串行执行
+// 锁被占用超过等待时间时返回繁忙，Redis不可用时拒绝操作而不是在没有互斥的情况下继续
+func (s *Service) withGroupMemberLock(ctx context.Context, groupID int64, fn func(ctx context.Context) error) error {
+	key := fmt.Sprintf("%s:%d", model.RedisKeyGroupMemberLockPrefix, groupID)
+	lock, err := s.redis.ObtainLock(ctx, key, redis.LockOptions{
+		TTL:         model.GroupMemberLockTTLSeconds * time.Second,
+		WaitTimeout: model.GroupMemberLockWaitMillis * time.Millisecond,
+	})
+	if errors.Is(err, redis.ErrLockNotObtained) {
+		return httpx.Unavailable(fmt.Errorf("群组操作繁忙，请稍后重试"))
+	}
+	if err != nil {
+		return fmt.Errorf("获取群组锁失败: %v", err)
+	}
+
+	defer func() {
+		if err := lock.Release(context.Background()); err != nil {
+			// 锁在操作完成前已过期，说明锁TTL相对操作耗时过短
+			s.logger.Warn(ctx, "Group member lock expired before release",
+				logger.F("groupID", groupID),
+				logger.F("error", err.Error()))
+		}
+	}()
+	return fn(ctx)
+}
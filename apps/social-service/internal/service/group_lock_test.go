@@ -0,0 +1,251 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"goim-social/apps/social-service/internal/dao"
+	"goim-social/apps/social-service/internal/model"
+	"goim-social/pkg/httpx"
+	"goim-social/pkg/logger"
+	"goim-social/pkg/redis"
+)
+
+// memberDAO 群成员的内存DAO，检查和写入是分开的调用且不做唯一约束，互斥只依赖群成员变更锁
+type memberDAO struct {
+	dao.SocialDAO
+
+	mu      sync.Mutex
+	group   model.Group
+	members []model.GroupMember
+}
+
+func (d *memberDAO) GetGroup(ctx context.Context, groupID int64) (*model.Group, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	group := d.group
+	return &group, nil
+}
+
+func (d *memberDAO) IsMember(ctx context.Context, groupID, userID int64) (bool, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for _, member := range d.members {
+		if member.GroupID == groupID && member.UserID == userID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (d *memberDAO) GetMember(ctx context.Context, groupID, userID int64) (*model.GroupMember, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for _, member := range d.members {
+		if member.GroupID == groupID && member.UserID == userID {
+			m := member
+			return &m, nil
+		}
+	}
+	return nil, errors.New("record not found")
+}
+
+func (d *memberDAO) AddMemberWithinLimit(ctx context.Context, member *model.GroupMember) (bool, error) {
+	// 放大检查与写入之间的窗口，没有锁时并发加群会重复插入
+	time.Sleep(time.Millisecond)
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.group.MemberCount >= d.group.MaxMembers {
+		return false, nil
+	}
+	d.members = append(d.members, *member)
+	d.group.MemberCount++
+	return true, nil
+}
+
+func (d *memberDAO) RemoveMember(ctx context.Context, groupID, userID int64) error {
+	time.Sleep(time.Millisecond)
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for i, member := range d.members {
+		if member.GroupID == groupID && member.UserID == userID {
+			d.members = append(d.members[:i], d.members[i+1:]...)
+			d.group.MemberCount--
+			return nil
+		}
+	}
+	return nil
+}
+
+// fakeRedis 只实现分布式锁用到的SET NX和释放脚本的最小Redis服务
+type fakeRedis struct {
+	mu   sync.Mutex
+	keys map[string]string
+}
+
+func startFakeRedis(t *testing.T) string {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("启动Redis失败: %v", err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	r := &fakeRedis{keys: make(map[string]string)}
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go r.serve(conn)
+		}
+	}()
+	return ln.Addr().String()
+}
+
+func (r *fakeRedis) serve(conn net.Conn) {
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	for {
+		args, err := readCommand(reader)
+		if err != nil {
+			return
+		}
+		if _, err := conn.Write([]byte(r.exec(args))); err != nil {
+			return
+		}
+	}
+}
+
+func (r *fakeRedis) exec(args []string) string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	switch strings.ToUpper(args[0]) {
+	case "SET": // SET key value EX|PX ttl NX
+		if _, ok := r.keys[args[1]]; ok {
+			return "$-1\r\n"
+		}
+		r.keys[args[1]] = args[2]
+		return "+OK\r\n"
+	case "EVALSHA": // 释放锁：EVALSHA sha 1 key token
+		if r.keys[args[3]] != args[4] {
+			return ":0\r\n"
+		}
+		delete(r.keys, args[3])
+		return ":1\r\n"
+	}
+	return "-ERR unknown command\r\n"
+}
+
+// readCommand 读取RESP数组形式的命令
+func readCommand(reader *bufio.Reader) ([]string, error) {
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	count, err := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(line, "*")))
+	if err != nil || count <= 0 {
+		return nil, fmt.Errorf("invalid command: %q", line)
+	}
+
+	args := make([]string, count)
+	for i := range args {
+		if line, err = reader.ReadString('\n'); err != nil {
+			return nil, err
+		}
+		size, err := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(line, "$")))
+		if err != nil {
+			return nil, err
+		}
+		buf := make([]byte, size+2)
+		if _, err := io.ReadFull(reader, buf); err != nil {
+			return nil, err
+		}
+		args[i] = string(buf[:size])
+	}
+	return args, nil
+}
+
+// TestConcurrentJoinLeave 并发加群、重复加群和退群后，成员记录不重复且与成员数一致
+func TestConcurrentJoinLeave(t *testing.T) {
+	client := redis.NewRedisClient(startFakeRedis(t))
+	defer client.Close()
+	log, err := logger.NewLogger("error")
+	if err != nil {
+		t.Fatalf("创建日志失败: %v", err)
+	}
+
+	const groupID, users = 1, 6
+	memberDAO := &memberDAO{
+		group:   model.Group{ID: groupID, OwnerID: 100, IsPublic: true, MemberCount: 1, MaxMembers: 500},
+		members: []model.GroupMember{{UserID: 100, GroupID: groupID, Role: model.RoleOwner}},
+	}
+	s := &Service{dao: memberDAO, redis: client, logger: log}
+
+	var wg sync.WaitGroup
+	var joined, left int64
+	errs := make(chan error, users*4)
+	run := func(succeeded *int64, op func() error) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			// 锁等待超时返回繁忙，操作未执行，不影响一致性
+			err := op()
+			if err == nil {
+				atomic.AddInt64(succeeded, 1)
+			} else if httpx.StatusOf(err) != http.StatusServiceUnavailable {
+				errs <- err
+			}
+		}()
+	}
+	for userID := int64(1); userID <= users; userID++ {
+		userID := userID
+		for i := 0; i < 3; i++ {
+			run(&joined, func() error {
+				_, err := s.JoinGroup(context.Background(), groupID, userID, "")
+				return err
+			})
+		}
+		if userID%2 == 0 {
+			run(&left, func() error { return s.LeaveGroup(context.Background(), groupID, userID) })
+		}
+	}
+	// 群主退群被拒绝，不影响成员记录
+	if err := s.LeaveGroup(context.Background(), groupID, 100); err == nil {
+		t.Fatalf("群主不应能退群")
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Fatalf("并发操作失败: %v", err)
+	}
+	// 全部因锁繁忙而未执行时，下面的一致性检查没有意义
+	if joined == 0 || left == 0 {
+		t.Fatalf("成功加群%d次、退群%d次，加群和退群都应至少成功一次", joined, left)
+	}
+
+	seen := make(map[int64]bool)
+	for _, member := range memberDAO.members {
+		if seen[member.UserID] {
+			t.Fatalf("用户%d有重复的成员记录: %+v", member.UserID, memberDAO.members)
+		}
+		seen[member.UserID] = true
+	}
+	if int(memberDAO.group.MemberCount) != len(memberDAO.members) {
+		t.Fatalf("成员数%d与成员记录数%d不一致", memberDAO.group.MemberCount, len(memberDAO.members))
+	}
+	if !seen[100] {
+		t.Fatalf("群主的成员记录丢失")
+	}
+}
@@ -0,0 +1,96 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+
+	"goim-social/pkg/audit"
+	tracecontext "goim-social/pkg/context"
+	"goim-social/pkg/httpx"
+	"goim-social/pkg/logger"
+	"goim-social/pkg/telemetry"
+)
+
+// TransferGroupOwnership 将群主转让给群成员，原群主降为管理员
+// 持有群成员变更锁执行，新群主不会在校验之后、转让之前退出群组；数据库按原群主条件更新兜底
+func (s *Service) TransferGroupOwnership(ctx context.Context, groupID, ownerID, newOwnerID int64) error {
+	// 开始OpenTelemetry span
+	ctx, span := telemetry.StartSpan(ctx, "social.service.TransferGroupOwnership")
+	defer span.End()
+
+	// 设置span属性
+	span.SetAttributes(
+		attribute.Int64("group.id", groupID),
+		attribute.Int64("group.owner_id", ownerID),
+		attribute.Int64("group.new_owner_id", newOwnerID),
+	)
+
+	// 将业务信息添加到context
+	ctx = tracecontext.WithGroupID(ctx, groupID)
+	ctx = tracecontext.WithUserID(ctx, ownerID)
+
+	if newOwnerID <= 0 || newOwnerID == ownerID {
+		span.SetStatus(codes.Error, "invalid new owner")
+		return httpx.InvalidArgument(fmt.Errorf("新群主无效"))
+	}
+
+	err := s.withGroupMemberLock(ctx, groupID, func(ctx context.Context) error {
+		group, err := s.dao.GetGroup(ctx, groupID)
+		if err != nil {
+			return fmt.Errorf("获取群组信息失败: %v", err)
+		}
+		if group.OwnerID != ownerID {
+			return httpx.PermissionDenied(fmt.Errorf("只有群主可以转让群组"))
+		}
+
+		isMember, err := s.dao.IsMember(ctx, groupID, newOwnerID)
+		if err != nil {
+			return fmt.Errorf("检查成员关系失败: %v", err)
+		}
+		if !isMember {
+			return httpx.InvalidArgument(fmt.Errorf("新群主不是群成员"))
+		}
+
+		// 索引事件在转让的事务中构建，携带新群主
+		group.OwnerID = newOwnerID
+		group.UpdatedAt = time.Now()
+		transferred, err := s.dao.TransferGroupOwnership(ctx, groupID, ownerID, newOwnerID, groupIndexEvent(group))
+		if err != nil {
+			return fmt.Errorf("转让群主失败: %v", err)
+		}
+		if !transferred {
+			return httpx.Conflict(fmt.Errorf("群主已变更，请刷新后重试"))
+		}
+		s.relay.Notify()
+		return nil
+	})
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to transfer group ownership")
+		return err
+	}
+
+	s.auditor.Record(audit.Entry{
+		ActorID:    ownerID,
+		Action:     audit.ActionGroupOwnerTransfer,
+		TargetType: audit.TargetGroup,
+		TargetID:   groupID,
+		Detail: map[string]string{
+			"old_owner_id": strconv.FormatInt(ownerID, 10),
+			"new_owner_id": strconv.FormatInt(newOwnerID, 10),
+		},
+	})
+
+	s.logger.Info(ctx, "Group ownership transferred successfully",
+		logger.F("groupID", groupID),
+		logger.F("ownerID", ownerID),
+		logger.F("newOwnerID", newOwnerID))
+
+	span.SetStatus(codes.Ok, "group ownership transferred successfully")
+	return nil
+}
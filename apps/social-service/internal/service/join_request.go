@@ -0,0 +1,448 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+
+	"goim-social/api/rest"
+	"goim-social/apps/social-service/internal/dao"
+	"goim-social/apps/social-service/internal/model"
+	"goim-social/pkg/audit"
+	tracecontext "goim-social/pkg/context"
+	"goim-social/pkg/httpx"
+	"goim-social/pkg/logger"
+	"goim-social/pkg/telemetry"
+)
+
+// SetGroupJoinMode 设置入群方式，仅群主和管理员可设置；改为直接加入时已提交的待处理申请仍需审批
+func (s *Service) SetGroupJoinMode(ctx context.Context, groupID, operatorID int64, joinMode string) error {
+	// 开始OpenTelemetry span
+	ctx, span := telemetry.StartSpan(ctx, "social.service.SetGroupJoinMode")
+	defer span.End()
+
+	// 设置span属性
+	span.SetAttributes(
+		attribute.Int64("group.id", groupID),
+		attribute.Int64("group.operator_id", operatorID),
+		attribute.String("group.join_mode", joinMode),
+	)
+
+	// 将业务信息添加到context
+	ctx = tracecontext.WithGroupID(ctx, groupID)
+	ctx = tracecontext.WithUserID(ctx, operatorID)
+
+	if !validGroupJoinMode(joinMode) {
+		span.SetStatus(codes.Error, "invalid join mode")
+		return httpx.InvalidArgument(fmt.Errorf("入群方式无效: %s", joinMode))
+	}
+
+	// 检查权限
+	member, err := s.dao.GetMember(ctx, groupID, operatorID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to get member")
+		return fmt.Errorf("获取成员信息失败: %v", err)
+	}
+	if member.Role != model.RoleOwner && member.Role != model.RoleAdmin {
+		span.SetStatus(codes.Error, "insufficient permissions")
+		return httpx.PermissionDenied(fmt.Errorf("权限不足"))
+	}
+
+	if err := s.dao.UpdateGroupJoinMode(ctx, groupID, joinMode); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to update join mode")
+		return fmt.Errorf("设置入群方式失败: %v", err)
+	}
+
+	s.auditor.Record(audit.Entry{
+		ActorID:    operatorID,
+		Action:     audit.ActionGroupJoinMode,
+		TargetType: audit.TargetGroup,
+		TargetID:   groupID,
+		Detail: map[string]string{
+			"join_mode": joinMode,
+		},
+	})
+
+	s.logger.Info(ctx, "Group join mode updated",
+		logger.F("groupID", groupID),
+		logger.F("operatorID", operatorID),
+		logger.F("joinMode", joinMode))
+
+	span.SetStatus(codes.Ok, "group join mode updated successfully")
+	return nil
+}
+
+// defaultGroupJoinMode 创建群组未指定入群方式时的默认值，非公开群需要审批
+func defaultGroupJoinMode(isPublic bool) string {
+	if isPublic {
+		return model.GroupJoinModeOpen
+	}
+	return model.GroupJoinModeApproval
+}
+
+// validGroupJoinMode 检查入群方式是否有效
+func validGroupJoinMode(joinMode string) bool {
+	return joinMode == model.GroupJoinModeOpen || joinMode == model.GroupJoinModeApproval
+}
+
+// ListJoinRequests 查询群的加群申请，status为空时只返回待处理的申请，仅群主和管理员可查询
+// 同时返回待处理申请数，供客户端展示角标
+func (s *Service) ListJoinRequests(ctx context.Context, groupID, operatorID int64, status string) ([]*model.GroupJoinRequest, int64, error) {
+	// 开始OpenTelemetry span
+	ctx, span := telemetry.StartSpan(ctx, "social.service.ListJoinRequests")
+	defer span.End()
+
+	// 设置span属性
+	span.SetAttributes(
+		attribute.Int64("group.id", groupID),
+		attribute.Int64("group.operator_id", operatorID),
+		attribute.String("group.join_request_status", status),
+	)
+
+	// 将业务信息添加到context
+	ctx = tracecontext.WithGroupID(ctx, groupID)
+	ctx = tracecontext.WithUserID(ctx, operatorID)
+
+	// 检查权限
+	member, err := s.dao.GetMember(ctx, groupID, operatorID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to get member")
+		return nil, 0, fmt.Errorf("获取成员信息失败: %v", err)
+	}
+	if member.Role != model.RoleOwner && member.Role != model.RoleAdmin {
+		span.SetStatus(codes.Error, "insufficient permissions")
+		return nil, 0, fmt.Errorf("权限不足")
+	}
+
+	if status == "" {
+		status = model.JoinRequestStatusPending
+	}
+
+	requests, err := s.dao.ListJoinRequests(ctx, groupID, status)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to list join requests")
+		return nil, 0, fmt.Errorf("获取加群申请失败: %v", err)
+	}
+
+	pendingCount, err := s.dao.CountJoinRequests(ctx, groupID, model.JoinRequestStatusPending)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to count pending join requests")
+		return nil, 0, fmt.Errorf("统计待处理加群申请失败: %v", err)
+	}
+
+	span.SetAttributes(attribute.Int64("group.join_request_pending", pendingCount))
+	span.SetStatus(codes.Ok, "join requests retrieved successfully")
+	return requests, pendingCount, nil
+}
+
+// ReviewJoinRequest 同意或拒绝加群申请，仅群主和管理员可操作
+// 处理结果和理由推送给申请人，其他管理员的待处理角标同步更新
+func (s *Service) ReviewJoinRequest(ctx context.Context, groupID, operatorID, requestID int64, approve bool, reason string) error {
+	// 开始OpenTelemetry span
+	ctx, span := telemetry.StartSpan(ctx, "social.service.ReviewJoinRequest")
+	defer span.End()
+
+	// 设置span属性
+	span.SetAttributes(
+		attribute.Int64("group.id", groupID),
+		attribute.Int64("group.operator_id", operatorID),
+		attribute.Int64("group.join_request_id", requestID),
+		attribute.Bool("group.join_request_approve", approve),
+	)
+
+	// 将业务信息添加到context
+	ctx = tracecontext.WithGroupID(ctx, groupID)
+	ctx = tracecontext.WithUserID(ctx, operatorID)
+
+	// 检查权限
+	member, err := s.dao.GetMember(ctx, groupID, operatorID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to get member")
+		return fmt.Errorf("获取成员信息失败: %v", err)
+	}
+	if member.Role != model.RoleOwner && member.Role != model.RoleAdmin {
+		span.SetStatus(codes.Error, "insufficient permissions")
+		return fmt.Errorf("权限不足")
+	}
+
+	request, err := s.dao.GetJoinRequestByID(ctx, requestID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to get join request")
+		return fmt.Errorf("获取加群申请失败: %v", err)
+	}
+	if request.GroupID != groupID {
+		span.SetStatus(codes.Error, "join request not in group")
+		return fmt.Errorf("加群申请不属于该群组")
+	}
+
+	status := model.JoinRequestStatusRejected
+	if approve {
+		status = model.JoinRequestStatusApproved
+	}
+
+	// 先将申请标记为已处理，避免多个管理员同时处理
+	reviewed, err := s.dao.ReviewJoinRequest(ctx, requestID, status, operatorID, reason)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to review join request")
+		return fmt.Errorf("处理加群申请失败: %v", err)
+	}
+	if !reviewed {
+		span.SetStatus(codes.Error, "join request already reviewed")
+		return fmt.Errorf("加群申请已被处理")
+	}
+
+	if approve {
+		if err := s.addApprovedMember(ctx, request); err != nil {
+			// 加入失败时恢复为待处理，便于稍后重新处理
+			if rollbackErr := s.dao.UpdateJoinRequestStatus(ctx, requestID, model.JoinRequestStatusPending); rollbackErr != nil {
+				s.logger.Error(ctx, "Failed to restore join request to pending",
+					logger.F("requestID", requestID),
+					logger.F("error", rollbackErr.Error()))
+			}
+			span.RecordError(err)
+			span.SetStatus(codes.Error, "failed to add member")
+			return err
+		}
+	}
+
+	request.Status = status
+	request.ReviewerID = operatorID
+	request.ReviewReason = reason
+
+	s.auditor.Record(audit.Entry{
+		ActorID:    operatorID,
+		Action:     audit.ActionJoinRequestReview,
+		TargetType: audit.TargetGroup,
+		TargetID:   groupID,
+		Detail: map[string]string{
+			"request_id": strconv.FormatInt(requestID, 10),
+			"user_id":    strconv.FormatInt(request.UserID, 10),
+			"status":     status,
+			"reason":     reason,
+		},
+	})
+
+	s.notifyJoinRequestReviewed(ctx, request)
+	s.notifyJoinRequestPending(ctx, groupID, request)
+
+	s.logger.Info(ctx, "Join request reviewed successfully",
+		logger.F("groupID", groupID),
+		logger.F("operatorID", operatorID),
+		logger.F("requestID", requestID),
+		logger.F("status", status))
+
+	span.SetStatus(codes.Ok, "join request reviewed successfully")
+	return nil
+}
+
+// MuteJoinRequestNotify 群主或管理员屏蔽或取消屏蔽本群的加群申请通知，屏蔽后仍可主动查询申请列表
+func (s *Service) MuteJoinRequestNotify(ctx context.Context, groupID, operatorID int64, muted bool) error {
+	// 开始OpenTelemetry span
+	ctx, span := telemetry.StartSpan(ctx, "social.service.MuteJoinRequestNotify")
+	defer span.End()
+
+	// 设置span属性
+	span.SetAttributes(
+		attribute.Int64("group.id", groupID),
+		attribute.Int64("group.operator_id", operatorID),
+		attribute.Bool("group.join_request_muted", muted),
+	)
+
+	// 将业务信息添加到context
+	ctx = tracecontext.WithGroupID(ctx, groupID)
+	ctx = tracecontext.WithUserID(ctx, operatorID)
+
+	// 检查权限
+	member, err := s.dao.GetMember(ctx, groupID, operatorID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to get member")
+		return fmt.Errorf("获取成员信息失败: %v", err)
+	}
+	if member.Role != model.RoleOwner && member.Role != model.RoleAdmin {
+		span.SetStatus(codes.Error, "insufficient permissions")
+		return fmt.Errorf("权限不足")
+	}
+
+	mutedKey := fmt.Sprintf("%s:%d", model.RedisKeyJoinRequestMutedPrefix, groupID)
+	if muted {
+		err = s.redis.SAdd(ctx, mutedKey, operatorID)
+	} else {
+		err = s.redis.SRem(ctx, mutedKey, operatorID)
+	}
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to update mute setting")
+		return fmt.Errorf("更新加群申请通知屏蔽设置失败: %v", err)
+	}
+
+	s.logger.Info(ctx, "Join request notify mute updated",
+		logger.F("groupID", groupID),
+		logger.F("operatorID", operatorID),
+		logger.F("muted", muted))
+
+	span.SetStatus(codes.Ok, "join request notify mute updated")
+	return nil
+}
+
+// submitJoinRequest 提交加群申请，已有待处理申请时不重复提交
+func (s *Service) submitJoinRequest(ctx context.Context, group *model.Group, userID int64, reason string) (*model.GroupJoinRequest, error) {
+	existing, err := s.dao.GetJoinRequest(ctx, group.ID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("检查加群申请失败: %v", err)
+	}
+	if existing != nil && existing.Status == model.JoinRequestStatusPending {
+		return nil, fmt.Errorf("已有待处理的加群申请")
+	}
+
+	request := &model.GroupJoinRequest{
+		GroupID: group.ID,
+		UserID:  userID,
+		Status:  model.JoinRequestStatusPending,
+		Reason:  reason,
+	}
+	if err := s.dao.CreateJoinRequest(ctx, request); err != nil {
+		return nil, fmt.Errorf("创建加群申请失败: %v", err)
+	}
+
+	s.notifyJoinRequestPending(ctx, group.ID, request)
+
+	s.logger.Info(ctx, "Join request submitted",
+		logger.F("groupID", group.ID),
+		logger.F("userID", userID),
+		logger.F("requestID", request.ID))
+	return request, nil
+}
+
+// addApprovedMember 持有群成员变更锁将审批通过的申请人加入群组
+func (s *Service) addApprovedMember(ctx context.Context, request *model.GroupJoinRequest) error {
+	return s.withGroupMemberLock(ctx, request.GroupID, func(ctx context.Context) error {
+		isMember, err := s.dao.IsMember(ctx, request.GroupID, request.UserID)
+		if err != nil {
+			return fmt.Errorf("检查成员关系失败: %v", err)
+		}
+		if isMember {
+			return nil
+		}
+
+		added, err := s.dao.AddMemberWithinLimit(ctx, &model.GroupMember{
+			UserID:  request.UserID,
+			GroupID: request.GroupID,
+			Role:    model.RoleMember,
+		})
+		if errors.Is(err, dao.ErrMemberExists) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("添加成员失败: %v", err)
+		}
+		if !added {
+			return fmt.Errorf("群组已满")
+		}
+		return nil
+	})
+}
+
+// notifyJoinRequestPending 向未屏蔽通知的群主和管理员推送本群当前的待处理申请数
+// 多个待处理申请折叠为同一个角标，客户端按群覆盖计数；失败只记录日志
+func (s *Service) notifyJoinRequestPending(ctx context.Context, groupID int64, request *model.GroupJoinRequest) {
+	if s.kafka == nil {
+		return
+	}
+
+	pendingCount, err := s.dao.CountJoinRequests(ctx, groupID, model.JoinRequestStatusPending)
+	if err != nil {
+		s.logger.Warn(ctx, "Failed to count pending join requests",
+			logger.F("groupID", groupID),
+			logger.F("error", err.Error()))
+		return
+	}
+
+	adminIDs, err := s.dao.GetMemberIDsByRoles(ctx, groupID, []string{model.RoleOwner, model.RoleAdmin})
+	if err != nil {
+		s.logger.Warn(ctx, "Failed to get group admins for join request notice",
+			logger.F("groupID", groupID),
+			logger.F("error", err.Error()))
+		return
+	}
+
+	muted := make(map[string]bool)
+	mutedKey := fmt.Sprintf("%s:%d", model.RedisKeyJoinRequestMutedPrefix, groupID)
+	if members, err := s.redis.SMembers(ctx, mutedKey); err == nil {
+		for _, member := range members {
+			muted[member] = true
+		}
+	}
+
+	notice := &model.JoinRequestNotice{
+		GroupID:      groupID,
+		RequestID:    request.ID,
+		UserID:       request.UserID,
+		PendingCount: pendingCount,
+	}
+	for _, adminID := range adminIDs {
+		if muted[strconv.FormatInt(adminID, 10)] {
+			continue
+		}
+		s.publishJoinRequestNotice(ctx, adminID, model.MessageTypeJoinRequestPending, notice)
+	}
+}
+
+// notifyJoinRequestReviewed 向申请人推送处理结果和理由，失败只记录日志
+func (s *Service) notifyJoinRequestReviewed(ctx context.Context, request *model.GroupJoinRequest) {
+	if s.kafka == nil {
+		return
+	}
+
+	s.publishJoinRequestNotice(ctx, request.UserID, model.MessageTypeJoinRequestReviewed, &model.JoinRequestNotice{
+		GroupID:   request.GroupID,
+		RequestID: request.ID,
+		UserID:    request.UserID,
+		Status:    request.Status,
+		Reason:    request.ReviewReason,
+	})
+}
+
+// publishJoinRequestNotice 发布加群申请通知到下行推送Topic，由message-service推送给在线用户
+func (s *Service) publishJoinRequestNotice(ctx context.Context, userID int64, messageType int32, notice *model.JoinRequestNotice) {
+	content, err := json.Marshal(notice)
+	if err != nil {
+		s.logger.Warn(ctx, "Failed to marshal join request notice",
+			logger.F("requestID", notice.RequestID),
+			logger.F("error", err.Error()))
+		return
+	}
+
+	event := &rest.MessageEvent{
+		Type: model.EventTypeGroupJoinRequest,
+		Message: &rest.WSMessage{
+			MessageId:   notice.RequestID,
+			To:          userID,
+			GroupId:     notice.GroupID,
+			Content:     string(content),
+			MessageType: messageType,
+			Timestamp:   time.Now().Unix(),
+		},
+		Timestamp: time.Now().Unix(),
+	}
+	if err := s.kafka.PublishMessage(model.TopicDownlinkMessage, event); err != nil {
+		s.logger.Warn(ctx, "Failed to publish join request notice",
+			logger.F("requestID", notice.RequestID),
+			logger.F("userID", userID),
+			logger.F("error", err.Error()))
+	}
+}
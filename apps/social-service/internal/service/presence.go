@@ -0,0 +1,262 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	goredis "github.com/go-redis/redis/v8"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+
+	"goim-social/apps/social-service/internal/model"
+	tracecontext "goim-social/pkg/context"
+	"goim-social/pkg/httpx"
+	"goim-social/pkg/logger"
+	"goim-social/pkg/presence"
+	"goim-social/pkg/telemetry"
+)
+
+// ============ 在线状态管理 ============
+
+// GetGroupPresence 获取群组在线成员汇总（分页）
+func (s *Service) GetGroupPresence(ctx context.Context, groupID, viewerID int64, page, pageSize int) (*model.GroupPresence, error) {
+	// 开始OpenTelemetry span
+	ctx, span := telemetry.StartSpan(ctx, "social.service.GetGroupPresence")
+	defer span.End()
+
+	// 设置span属性
+	span.SetAttributes(
+		attribute.Int64("group.id", groupID),
+		attribute.Int64("group.viewer_id", viewerID),
+	)
+
+	// 将业务信息添加到context
+	ctx = tracecontext.WithGroupID(ctx, groupID)
+	ctx = tracecontext.WithUserID(ctx, viewerID)
+
+	// 只有群成员才能查看在线状态
+	isMember, err := s.dao.IsMember(ctx, groupID, viewerID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to check membership")
+		return nil, fmt.Errorf("检查群成员身份失败: %v", err)
+	}
+	if !isMember {
+		span.SetStatus(codes.Error, "viewer is not a member")
+		return nil, fmt.Errorf("用户不是群成员")
+	}
+
+	if page <= 0 {
+		page = 1
+	}
+	if pageSize <= 0 {
+		pageSize = model.DefaultPageSize
+	}
+	if pageSize > model.MaxPresencePageSize {
+		pageSize = model.MaxPresencePageSize
+	}
+
+	onlineIDs, err := s.getVisibleOnlineMemberIDs(ctx, groupID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to get online members")
+		return nil, fmt.Errorf("获取群在线成员失败: %v", err)
+	}
+
+	start := (page - 1) * pageSize
+	if start > len(onlineIDs) {
+		start = len(onlineIDs)
+	}
+	end := start + pageSize
+	if end > len(onlineIDs) {
+		end = len(onlineIDs)
+	}
+
+	presence := &model.GroupPresence{
+		GroupID:       groupID,
+		OnlineCount:   len(onlineIDs),
+		OnlineUserIDs: onlineIDs[start:end],
+		Page:          page,
+		PageSize:      pageSize,
+	}
+
+	span.SetAttributes(attribute.Int("group.online_count", presence.OnlineCount))
+	span.SetStatus(codes.Ok, "group presence retrieved successfully")
+	return presence, nil
+}
+
+// getVisibleOnlineMemberIDs 获取群内对他人可见的在线成员ID，结果短暂缓存
+func (s *Service) getVisibleOnlineMemberIDs(ctx context.Context, groupID int64) ([]int64, error) {
+	cacheKey := fmt.Sprintf("%s:%d", model.CacheKeyGroupPresence, groupID)
+	if cached, err := s.redis.Get(ctx, cacheKey); err == nil && cached != "" {
+		var ids []int64
+		if err := json.Unmarshal([]byte(cached), &ids); err == nil {
+			return ids, nil
+		}
+	}
+
+	memberIDs, err := s.dao.GetMemberIDs(ctx, groupID)
+	if err != nil {
+		return nil, err
+	}
+
+	onlineIDs := make([]int64, 0)
+	if len(memberIDs) > 0 {
+		online, err := s.presence.Online(ctx, memberIDs)
+		if err != nil {
+			return nil, err
+		}
+
+		// 使用pipeline批量检查在线成员的隐身状态，避免逐个查询
+		pipe := s.redis.GetClient().Pipeline()
+		hiddenCmds := make(map[int64]*goredis.BoolCmd, len(memberIDs))
+		for _, memberID := range memberIDs {
+			if online[memberID] {
+				hiddenCmds[memberID] = pipe.SIsMember(ctx, model.RedisKeyPresenceHiddenUsers, memberID)
+			}
+		}
+		if len(hiddenCmds) > 0 {
+			if _, err := pipe.Exec(ctx); err != nil {
+				return nil, err
+			}
+		}
+
+		for _, memberID := range memberIDs {
+			if cmd, ok := hiddenCmds[memberID]; ok && !cmd.Val() {
+				onlineIDs = append(onlineIDs, memberID)
+			}
+		}
+	}
+
+	if data, err := json.Marshal(onlineIDs); err == nil {
+		if err := s.redis.Set(ctx, cacheKey, data, time.Duration(model.CacheExpireGroupPresence)*time.Second); err != nil {
+			s.logger.Warn(ctx, "Failed to cache group presence",
+				logger.F("groupID", groupID),
+				logger.F("error", err.Error()))
+		}
+	}
+
+	return onlineIDs, nil
+}
+
+// GetUserPresence 批量查询用户在线状态和最近在线时间，结果按去重后的查询顺序返回
+// 隐身用户对他人显示为离线且不返回最近在线时间，查询自己时不受隐身影响
+func (s *Service) GetUserPresence(ctx context.Context, viewerID int64, userIDs []int64) ([]*model.UserPresence, error) {
+	// 开始OpenTelemetry span
+	ctx, span := telemetry.StartSpan(ctx, "social.service.GetUserPresence")
+	defer span.End()
+
+	// 设置span属性
+	span.SetAttributes(
+		attribute.Int64("presence.viewer_id", viewerID),
+		attribute.Int("presence.query_count", len(userIDs)),
+	)
+
+	// 将业务信息添加到context
+	ctx = tracecontext.WithUserID(ctx, viewerID)
+
+	userIDs = uniqueUserIDs(userIDs)
+	if len(userIDs) > model.MaxPresenceQueryUsers {
+		span.SetStatus(codes.Error, "too many users")
+		return nil, httpx.InvalidArgument(fmt.Errorf("一次最多查询%d个用户的在线状态", model.MaxPresenceQueryUsers))
+	}
+	if len(userIDs) == 0 {
+		span.SetStatus(codes.Ok, "no users")
+		return []*model.UserPresence{}, nil
+	}
+
+	statuses, err := s.presence.Statuses(ctx, userIDs)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to get presence")
+		return nil, httpx.Unavailable(fmt.Errorf("获取在线状态失败: %v", err))
+	}
+
+	// 使用pipeline批量检查隐身状态，查询自己时不检查
+	pipe := s.redis.GetClient().Pipeline()
+	hiddenCmds := make(map[int64]*goredis.BoolCmd, len(userIDs))
+	for _, userID := range userIDs {
+		if userID != viewerID {
+			hiddenCmds[userID] = pipe.SIsMember(ctx, model.RedisKeyPresenceHiddenUsers, userID)
+		}
+	}
+	if len(hiddenCmds) > 0 {
+		if _, err := pipe.Exec(ctx); err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, "failed to check presence visibility")
+			return nil, httpx.Unavailable(fmt.Errorf("查询在线状态可见性失败: %v", err))
+		}
+	}
+	hidden := make(map[int64]bool, len(hiddenCmds))
+	for userID, cmd := range hiddenCmds {
+		hidden[userID] = cmd.Val()
+	}
+
+	span.SetStatus(codes.Ok, "user presence retrieved successfully")
+	return visiblePresences(userIDs, statuses, hidden), nil
+}
+
+// visiblePresences 按查询顺序组装在线状态，隐身用户显示为离线且不返回最近在线时间
+func visiblePresences(userIDs []int64, statuses map[int64]presence.Status, hidden map[int64]bool) []*model.UserPresence {
+	presences := make([]*model.UserPresence, 0, len(userIDs))
+	for _, userID := range userIDs {
+		p := &model.UserPresence{UserID: userID}
+		if status, ok := statuses[userID]; ok && !hidden[userID] {
+			p.Online = status.Online
+			p.LastSeen = status.LastSeen
+		}
+		presences = append(presences, p)
+	}
+	return presences
+}
+
+// uniqueUserIDs 去掉重复和无效的用户ID，保持原有顺序
+func uniqueUserIDs(userIDs []int64) []int64 {
+	seen := make(map[int64]bool, len(userIDs))
+	unique := make([]int64, 0, len(userIDs))
+	for _, userID := range userIDs {
+		if userID <= 0 || seen[userID] {
+			continue
+		}
+		seen[userID] = true
+		unique = append(unique, userID)
+	}
+	return unique
+}
+
+// SetPresenceVisibility 设置用户在线状态对他人是否可见
+func (s *Service) SetPresenceVisibility(ctx context.Context, userID int64, hidden bool) error {
+	// 开始OpenTelemetry span
+	ctx, span := telemetry.StartSpan(ctx, "social.service.SetPresenceVisibility")
+	defer span.End()
+
+	// 设置span属性
+	span.SetAttributes(
+		attribute.Int64("presence.user_id", userID),
+		attribute.Bool("presence.hidden", hidden),
+	)
+
+	// 将业务信息添加到context
+	ctx = tracecontext.WithUserID(ctx, userID)
+
+	var err error
+	if hidden {
+		err = s.redis.SAdd(ctx, model.RedisKeyPresenceHiddenUsers, userID)
+	} else {
+		err = s.redis.SRem(ctx, model.RedisKeyPresenceHiddenUsers, userID)
+	}
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to set presence visibility")
+		return fmt.Errorf("设置在线状态可见性失败: %v", err)
+	}
+
+	s.logger.Info(ctx, "Presence visibility updated",
+		logger.F("userID", userID),
+		logger.F("hidden", hidden))
+
+	span.SetStatus(codes.Ok, "presence visibility updated successfully")
+	return nil
+}
@@ -0,0 +1,50 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"goim-social/apps/social-service/internal/model"
+	"goim-social/pkg/httpx"
+	"goim-social/pkg/presence"
+)
+
+// TestVisiblePresences 按查询顺序返回，隐身用户显示为离线且不返回最近在线时间，未查到的用户为离线
+func TestVisiblePresences(t *testing.T) {
+	statuses := map[int64]presence.Status{
+		1: {Online: true, LastSeen: 1700000100},
+		2: {Online: true, LastSeen: 1700000200},
+		3: {LastSeen: 1690000000},
+	}
+	got := visiblePresences([]int64{3, 2, 1, 4}, statuses, map[int64]bool{2: true})
+	want := []*model.UserPresence{
+		{UserID: 3, LastSeen: 1690000000},
+		{UserID: 2},
+		{UserID: 1, Online: true, LastSeen: 1700000100},
+		{UserID: 4},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("visiblePresences() = %+v, want %+v", got, want)
+	}
+}
+
+// TestUniqueUserIDs 去掉重复和无效的ID，保持原有顺序
+func TestUniqueUserIDs(t *testing.T) {
+	if got, want := uniqueUserIDs([]int64{3, 1, 3, 0, -1, 2, 1}), []int64{3, 1, 2}; !reflect.DeepEqual(got, want) {
+		t.Errorf("uniqueUserIDs() = %v, want %v", got, want)
+	}
+}
+
+// TestGetUserPresenceTooManyUsers 超过单次查询上限时拒绝
+func TestGetUserPresenceTooManyUsers(t *testing.T) {
+	userIDs := make([]int64, model.MaxPresenceQueryUsers+1)
+	for i := range userIDs {
+		userIDs[i] = int64(i + 1)
+	}
+	_, err := (&Service{}).GetUserPresence(context.Background(), 1, userIDs)
+	if httpx.StatusOf(err) != http.StatusBadRequest {
+		t.Errorf("GetUserPresence(%d users) = %v, want invalid argument", len(userIDs), err)
+	}
+}
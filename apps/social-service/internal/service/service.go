@@ -2,43 +2,69 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strconv"
 	"time"
 
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
 
+	"goim-social/api/rest"
 	"goim-social/apps/social-service/internal/dao"
 	"goim-social/apps/social-service/internal/model"
+	"goim-social/pkg/audit"
+	"goim-social/pkg/blocklist"
+	"goim-social/pkg/config"
 	tracecontext "goim-social/pkg/context"
+	"goim-social/pkg/httpx"
 	"goim-social/pkg/kafka"
 	"goim-social/pkg/logger"
+	"goim-social/pkg/outbox"
+	"goim-social/pkg/presence"
 	"goim-social/pkg/redis"
 	"goim-social/pkg/telemetry"
 )
 
 // Service 社交服务
 type Service struct {
-	dao    dao.SocialDAO
-	redis  *redis.RedisClient
-	kafka  *kafka.Producer
-	logger logger.Logger
+	dao        dao.SocialDAO
+	redis      *redis.RedisClient
+	kafka      *kafka.Producer
+	relay      *outbox.Relay // 发件箱转发器，业务事务提交后唤醒，为nil时按扫描间隔转发
+	logger     logger.Logger
+	groupTiers map[string]int32 // 群组等级对应的成员上限
+	admins     map[int64]bool   // 允许创建standard以外等级群组的管理员
+	friendCfg  config.FriendConfig
+	blocks     *blocklist.Store       // 拉黑关系缓存，供其他服务查询屏蔽集合
+	auditor    *audit.Recorder        // 管理操作的审计记录器
+	users      rest.UserServiceClient // 批量添加好友时按用户名解析用户并校验申请人账号
+	presence   *presence.Cache        // 在线状态缓存，由im-gateway维护
 }
 
 // NewService 创建社交服务实例
-func NewService(socialDAO dao.SocialDAO, redis *redis.RedisClient, kafka *kafka.Producer, log logger.Logger) *Service {
+func NewService(socialDAO dao.SocialDAO, redis *redis.RedisClient, kafka *kafka.Producer, relay *outbox.Relay, log logger.Logger, socialCfg config.SocialConfig, presenceCfg config.PresenceConfig, auditor *audit.Recorder, users rest.UserServiceClient) *Service {
 	return &Service{
-		dao:    socialDAO,
-		redis:  redis,
-		kafka:  kafka,
-		logger: log,
+		dao:        socialDAO,
+		redis:      redis,
+		kafka:      kafka,
+		relay:      relay,
+		logger:     log,
+		groupTiers: parseGroupTiers(socialCfg.Group),
+		admins:     config.ParseUserIDs(socialCfg.Group.AdminIDs),
+		friendCfg:  socialCfg.Friend,
+		blocks:     blocklist.NewStore(redis),
+		auditor:    auditor,
+		users:      users,
+		presence:   presence.NewCache(redis, presenceCfg),
 	}
 }
 
 // ============ 好友关系管理 ============
 
-// SendFriendRequest 发送好友申请
-func (s *Service) SendFriendRequest(ctx context.Context, applicantID, userID int64, remark string) error {
+// SendFriendRequest 发送好友申请，restoreRemark为true时对方同意后恢复申请人上次的好友备注
+// 拉黑、已是好友、已有待处理申请和超出频率限制时返回对应的错误，见FriendRequestReason
+func (s *Service) SendFriendRequest(ctx context.Context, applicantID, userID int64, remark string, restoreRemark bool) error {
 	// 开始OpenTelemetry span
 	ctx, span := telemetry.StartSpan(ctx, "social.service.SendFriendRequest")
 	defer span.End()
@@ -52,42 +78,17 @@ func (s *Service) SendFriendRequest(ctx context.Context, applicantID, userID int
 	// 将业务信息添加到context
 	ctx = tracecontext.WithUserID(ctx, applicantID)
 
-	// 检查是否已经是好友
-	isFriend, err := s.dao.IsFriend(ctx, applicantID, userID)
-	if err != nil {
-		span.RecordError(err)
-		span.SetStatus(codes.Error, "failed to check friendship")
-		return fmt.Errorf("检查好友关系失败: %v", err)
-	}
-	if isFriend {
-		span.SetStatus(codes.Error, "already friends")
-		return fmt.Errorf("已经是好友关系")
+	if applicantID <= 0 || userID <= 0 || applicantID == userID {
+		span.SetStatus(codes.Error, "invalid user ID")
+		return httpx.InvalidArgument(fmt.Errorf("用户ID无效"))
 	}
 
-	// 检查是否已有申请
-	existingApply, err := s.dao.GetFriendApply(ctx, userID, applicantID)
+	apply, err := s.createFriendRequest(ctx, applicantID, userID, remark, restoreRemark, s.friendCfg.RequestHourlyLimit)
 	if err != nil {
 		span.RecordError(err)
-		span.SetStatus(codes.Error, "failed to check existing apply")
-		return fmt.Errorf("检查申请记录失败: %v", err)
-	}
-	if existingApply != nil && existingApply.Status == model.FriendApplyStatusPending {
-		span.SetStatus(codes.Error, "apply already exists")
-		return fmt.Errorf("已有待处理的好友申请")
-	}
-
-	// 创建好友申请
-	apply := &model.FriendApply{
-		UserID:      userID,
-		ApplicantID: applicantID,
-		Remark:      remark,
-		Status:      model.FriendApplyStatusPending,
-	}
-
-	if err := s.dao.CreateFriendApply(ctx, apply); err != nil {
-		span.RecordError(err)
-		span.SetStatus(codes.Error, "failed to create friend apply")
-		return fmt.Errorf("创建好友申请失败: %v", err)
+		span.SetAttributes(attribute.String("friend.reject_reason", FriendRequestReason(err)))
+		span.SetStatus(codes.Error, "failed to send friend request")
+		return err
 	}
 
 	s.logger.Info(ctx, "Friend request sent successfully",
@@ -99,8 +100,8 @@ func (s *Service) SendFriendRequest(ctx context.Context, applicantID, userID int
 	return nil
 }
 
-// AcceptFriendRequest 接受好友申请
-func (s *Service) AcceptFriendRequest(ctx context.Context, userID, applicantID int64, remark string) error {
+// AcceptFriendRequest 接受好友申请，restoreRemark为true且未指定备注时恢复被申请人上次的好友备注
+func (s *Service) AcceptFriendRequest(ctx context.Context, userID, applicantID int64, remark string, restoreRemark bool) error {
 	// 开始OpenTelemetry span
 	ctx, span := telemetry.StartSpan(ctx, "social.service.AcceptFriendRequest")
 	defer span.End()
@@ -130,36 +131,26 @@ func (s *Service) AcceptFriendRequest(ctx context.Context, userID, applicantID i
 		return fmt.Errorf("申请状态不正确")
 	}
 
-	// 创建双向好友关系
+	// 创建双向好友关系，曾经是好友时按双方各自的选择恢复上次的备注
 	friend1 := &model.Friend{
 		UserID:   userID,
 		FriendID: applicantID,
-		Remark:   remark,
+		Remark:   s.restoreFriendRemark(ctx, userID, applicantID, remark, restoreRemark),
 	}
 	friend2 := &model.Friend{
 		UserID:   applicantID,
 		FriendID: userID,
-		Remark:   "",
+		Remark:   s.restoreFriendRemark(ctx, applicantID, userID, "", apply.RestoreRemark),
 	}
 
-	if err := s.dao.CreateFriend(ctx, friend1); err != nil {
+	// 好友关系、申请状态和好友事件在同一事务中写入
+	if err := s.dao.AcceptFriendApply(ctx, userID, applicantID, []*model.Friend{friend1, friend2},
+		friendEvent(model.FriendEventAdded, userID, applicantID)); err != nil {
 		span.RecordError(err)
-		span.SetStatus(codes.Error, "failed to create friend1")
-		return fmt.Errorf("创建好友关系失败: %v", err)
-	}
-
-	if err := s.dao.CreateFriend(ctx, friend2); err != nil {
-		span.RecordError(err)
-		span.SetStatus(codes.Error, "failed to create friend2")
-		return fmt.Errorf("创建好友关系失败: %v", err)
-	}
-
-	// 更新申请状态
-	if err := s.dao.UpdateFriendApplyStatus(ctx, userID, applicantID, model.FriendApplyStatusAccepted); err != nil {
-		span.RecordError(err)
-		span.SetStatus(codes.Error, "failed to update apply status")
-		return fmt.Errorf("更新申请状态失败: %v", err)
+		span.SetStatus(codes.Error, "failed to accept friend apply")
+		return fmt.Errorf("接受好友申请失败: %v", err)
 	}
+	s.relay.Notify()
 
 	s.logger.Info(ctx, "Friend request accepted successfully",
 		logger.F("userID", userID),
@@ -215,12 +206,13 @@ func (s *Service) DeleteFriend(ctx context.Context, userID, friendID int64) erro
 	// 将业务信息添加到context
 	ctx = tracecontext.WithUserID(ctx, userID)
 
-	// 删除双向好友关系
-	if err := s.dao.DeleteFriend(ctx, userID, friendID); err != nil {
+	// 软删除双向好友关系，保留好友历史
+	if err := s.dao.DeleteFriend(ctx, userID, friendID, friendEvent(model.FriendEventDeleted, userID, friendID)); err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, "failed to delete friend")
 		return fmt.Errorf("删除好友失败: %v", err)
 	}
+	s.relay.Notify()
 
 	s.logger.Info(ctx, "Friend deleted successfully",
 		logger.F("userID", userID),
@@ -230,6 +222,18 @@ func (s *Service) DeleteFriend(ctx context.Context, userID, friendID int64) erro
 	return nil
 }
 
+// friendEvent 好友关系事件，与好友关系变更在同一事务中写入发件箱，按操作人ID分区
+func friendEvent(eventType string, userID, friendID int64) outbox.Builder {
+	return func() (*outbox.Event, error) {
+		return outbox.NewEvent(model.TopicFriendEvents, strconv.FormatInt(userID, 10), &model.FriendEvent{
+			EventType: eventType,
+			UserID:    userID,
+			FriendID:  friendID,
+			Timestamp: time.Now().Unix(),
+		})
+	}
+}
+
 // GetFriendList 获取好友列表
 func (s *Service) GetFriendList(ctx context.Context, userID int64) ([]*model.Friend, error) {
 	// 开始OpenTelemetry span
@@ -281,7 +285,7 @@ func (s *Service) GetFriendApplyList(ctx context.Context, userID int64) ([]*mode
 // ============ 群组管理 ============
 
 // CreateGroup 创建群组
-func (s *Service) CreateGroup(ctx context.Context, ownerID int64, name, description, avatar string, isPublic bool, maxMembers int32, memberIDs []int64) (*model.Group, error) {
+func (s *Service) CreateGroup(ctx context.Context, ownerID int64, name, description, avatar string, isPublic bool, joinMode string, maxMembers int32, tier string, memberIDs []int64) (*model.Group, error) {
 	// 开始OpenTelemetry span
 	ctx, span := telemetry.StartSpan(ctx, "social.service.CreateGroup")
 	defer span.End()
@@ -297,11 +301,50 @@ func (s *Service) CreateGroup(ctx context.Context, ownerID int64, name, descript
 	// 将业务信息添加到context
 	ctx = tracecontext.WithUserID(ctx, ownerID)
 
-	// 设置默认值
-	if maxMembers <= 0 {
-		maxMembers = model.DefaultMaxMembers
+	// 未指定入群方式时，非公开群需要审批，公开群可直接加入
+	if joinMode == "" {
+		joinMode = defaultGroupJoinMode(isPublic)
+	}
+	if !validGroupJoinMode(joinMode) {
+		span.SetStatus(codes.Error, "invalid join mode")
+		return nil, httpx.InvalidArgument(fmt.Errorf("入群方式无效: %s", joinMode))
+	}
+
+	// 按群组等级确定成员上限，standard以外的等级只能由管理员创建
+	if tier == "" {
+		tier = model.GroupTierStandard
+	}
+	if tier != model.GroupTierStandard && !s.admins[ownerID] {
+		span.SetStatus(codes.Error, "permission denied")
+		return nil, httpx.PermissionDenied(fmt.Errorf("无权限创建%s等级的群组", tier))
+	}
+	maxMembers, err := s.resolveGroupMaxMembers(tier, maxMembers)
+	if err != nil {
+		span.SetStatus(codes.Error, "invalid max members")
+		return nil, err
+	}
+
+	// 初始成员去重，连同群主不能超过上限
+	initialMembers := make([]int64, 0, len(memberIDs))
+	seen := map[int64]bool{ownerID: true}
+	for _, memberID := range memberIDs {
+		if memberID <= 0 || seen[memberID] {
+			continue
+		}
+		seen[memberID] = true
+		initialMembers = append(initialMembers, memberID)
+	}
+	if int32(len(initialMembers))+1 > maxMembers {
+		span.SetStatus(codes.Error, "too many initial members")
+		return nil, fmt.Errorf("初始成员数超过群组上限: %d", maxMembers)
 	}
 
+	span.SetAttributes(
+		attribute.String("group.tier", tier),
+		attribute.String("group.join_mode", joinMode),
+		attribute.Int("group.max_members", int(maxMembers)),
+	)
+
 	// 创建群组
 	group := &model.Group{
 		Name:         name,
@@ -310,7 +353,9 @@ func (s *Service) CreateGroup(ctx context.Context, ownerID int64, name, descript
 		OwnerID:      ownerID,
 		MemberCount:  1, // 群主
 		MaxMembers:   maxMembers,
+		Tier:         tier,
 		IsPublic:     isPublic,
+		JoinMode:     joinMode,
 		Announcement: "",
 	}
 
@@ -338,13 +383,9 @@ func (s *Service) CreateGroup(ctx context.Context, ownerID int64, name, descript
 		return nil, fmt.Errorf("添加群主失败: %v", err)
 	}
 
-	// 添加初始成员
+	// 添加初始成员，成员数随添加原子递增
 	memberCount := int32(1) // 群主
-	for _, memberID := range memberIDs {
-		if memberID == ownerID {
-			continue // 跳过群主
-		}
-
+	for _, memberID := range initialMembers {
 		member := &model.GroupMember{
 			UserID:   memberID,
 			GroupID:  group.ID,
@@ -352,23 +393,23 @@ func (s *Service) CreateGroup(ctx context.Context, ownerID int64, name, descript
 			Nickname: "",
 		}
 
-		if err := s.dao.AddMember(ctx, member); err != nil {
+		added, err := s.dao.AddMemberWithinLimit(ctx, member)
+		if errors.Is(err, dao.ErrMemberExists) {
+			// 初始成员重复或包含群主
+			continue
+		}
+		if err != nil {
 			s.logger.Error(ctx, "Failed to add initial member",
 				logger.F("groupID", group.ID),
 				logger.F("memberID", memberID),
 				logger.F("error", err.Error()))
 			continue
 		}
+		if !added {
+			break
+		}
 		memberCount++
 	}
-
-	// 更新成员数量
-	if err := s.dao.UpdateMemberCount(ctx, group.ID, memberCount); err != nil {
-		s.logger.Error(ctx, "Failed to update member count",
-			logger.F("groupID", group.ID),
-			logger.F("count", memberCount),
-			logger.F("error", err.Error()))
-	}
 	group.MemberCount = memberCount
 
 	s.logger.Info(ctx, "Group created successfully",
@@ -438,33 +479,30 @@ func (s *Service) UpdateGroup(ctx context.Context, groupID, operatorID int64, na
 		return fmt.Errorf("权限不足")
 	}
 
-	// 获取群组信息
-	group, err := s.dao.GetGroup(ctx, groupID)
-	if err != nil {
-		span.RecordError(err)
-		span.SetStatus(codes.Error, "failed to get group")
-		return fmt.Errorf("获取群组信息失败: %v", err)
+	// 群资料按版本号修改，不整行覆盖
+	if name != "" || description != "" || avatar != "" {
+		if _, err := s.UpdateGroupInfo(ctx, groupID, operatorID, GroupInfoUpdate{
+			Name:        name,
+			Description: description,
+			Avatar:      avatar,
+		}); err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, "failed to update group")
+			return err
+		}
 	}
 
-	// 更新字段
-	if name != "" {
-		group.Name = name
-	}
-	if description != "" {
-		group.Description = description
-	}
-	if avatar != "" {
-		group.Avatar = avatar
-	}
+	// 新公告写入公告历史并成为生效公告，旧公告保留可查
 	if announcement != "" {
-		group.Announcement = announcement
-	}
-	group.UpdatedAt = time.Now()
-
-	if err := s.dao.UpdateGroup(ctx, group); err != nil {
-		span.RecordError(err)
-		span.SetStatus(codes.Error, "failed to update group")
-		return fmt.Errorf("更新群组信息失败: %v", err)
+		if err := s.dao.PublishAnnouncement(ctx, &model.GroupAnnouncement{
+			GroupID:  groupID,
+			AuthorID: operatorID,
+			Content:  announcement,
+		}); err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, "failed to publish announcement")
+			return fmt.Errorf("发布群公告失败: %v", err)
+		}
 	}
 
 	s.logger.Info(ctx, "Group updated successfully",
@@ -475,8 +513,9 @@ func (s *Service) UpdateGroup(ctx context.Context, groupID, operatorID int64, na
 	return nil
 }
 
-// JoinGroup 加入群组
-func (s *Service) JoinGroup(ctx context.Context, groupID, userID int64) error {
+// JoinGroup 加入群组，公开群直接加入；非公开群需要审批，提交加群申请并通知群主和管理员
+// 返回的加群申请为nil表示已直接加入；已是成员时不做任何改动并返回成功，重复点击或重试不会重复加入
+func (s *Service) JoinGroup(ctx context.Context, groupID, userID int64, reason string) (*model.GroupJoinRequest, error) {
 	// 开始OpenTelemetry span
 	ctx, span := telemetry.StartSpan(ctx, "social.service.JoinGroup")
 	defer span.End()
@@ -496,11 +535,12 @@ func (s *Service) JoinGroup(ctx context.Context, groupID, userID int64) error {
 	if err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, "failed to check membership")
-		return fmt.Errorf("检查成员关系失败: %v", err)
+		return nil, fmt.Errorf("检查成员关系失败: %v", err)
 	}
 	if isMember {
-		span.SetStatus(codes.Error, "already member")
-		return fmt.Errorf("已经是群成员")
+		span.SetAttributes(attribute.Bool("group.already_member", true))
+		span.SetStatus(codes.Ok, "already member")
+		return nil, nil
 	}
 
 	// 检查群组是否存在
@@ -508,16 +548,24 @@ func (s *Service) JoinGroup(ctx context.Context, groupID, userID int64) error {
 	if err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, "failed to get group")
-		return fmt.Errorf("获取群组信息失败: %v", err)
+		return nil, fmt.Errorf("获取群组信息失败: %v", err)
 	}
 
-	// 检查群组是否已满
-	if group.MemberCount >= group.MaxMembers {
-		span.SetStatus(codes.Error, "group is full")
-		return fmt.Errorf("群组已满")
+	// 需审批的群组提交加群申请，由群主或管理员审批
+	if group.JoinMode == model.GroupJoinModeApproval {
+		request, err := s.submitJoinRequest(ctx, group, userID, reason)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, "failed to submit join request")
+			return nil, err
+		}
+		span.SetAttributes(attribute.Int64("group.join_request_id", request.ID))
+		span.SetStatus(codes.Ok, "join request submitted")
+		return request, nil
 	}
 
-	// 添加成员
+	// 持有群成员变更锁添加成员，多个实例并发加入同一群组时不会重复插入成员，
+	// 上限校验与成员数递增在同一事务中完成；锁内再次确认成员关系，并发的重复请求只有一个真正加入
 	member := &model.GroupMember{
 		UserID:   userID,
 		GroupID:  groupID,
@@ -525,19 +573,38 @@ func (s *Service) JoinGroup(ctx context.Context, groupID, userID int64) error {
 		Nickname: "",
 	}
 
-	if err := s.dao.AddMember(ctx, member); err != nil {
+	joined := false
+	err = s.withGroupMemberLock(ctx, groupID, func(ctx context.Context) error {
+		isMember, err := s.dao.IsMember(ctx, groupID, userID)
+		if err != nil {
+			return fmt.Errorf("检查成员关系失败: %v", err)
+		}
+		if isMember {
+			return nil
+		}
+
+		added, err := s.dao.AddMemberWithinLimit(ctx, member)
+		if errors.Is(err, dao.ErrMemberExists) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("添加成员失败: %v", err)
+		}
+		if !added {
+			return fmt.Errorf("群组已满: %d/%d", group.MemberCount, group.MaxMembers)
+		}
+		joined = true
+		return nil
+	})
+	if err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, "failed to add member")
-		return fmt.Errorf("添加成员失败: %v", err)
+		return nil, err
 	}
-
-	// 更新成员数量
-	newCount := group.MemberCount + 1
-	if err := s.dao.UpdateMemberCount(ctx, groupID, newCount); err != nil {
-		s.logger.Error(ctx, "Failed to update member count",
-			logger.F("groupID", groupID),
-			logger.F("count", newCount),
-			logger.F("error", err.Error()))
+	if !joined {
+		span.SetAttributes(attribute.Bool("group.already_member", true))
+		span.SetStatus(codes.Ok, "already member")
+		return nil, nil
 	}
 
 	s.logger.Info(ctx, "User joined group successfully",
@@ -545,10 +612,10 @@ func (s *Service) JoinGroup(ctx context.Context, groupID, userID int64) error {
 		logger.F("userID", userID))
 
 	span.SetStatus(codes.Ok, "user joined group successfully")
-	return nil
+	return nil, nil
 }
 
-// LeaveGroup 离开群组
+// LeaveGroup 离开群组，不是成员时不做任何改动并返回成功，重复点击或重试不会重复递减成员数
 func (s *Service) LeaveGroup(ctx context.Context, groupID, userID int64) error {
 	// 开始OpenTelemetry span
 	ctx, span := telemetry.StartSpan(ctx, "social.service.LeaveGroup")
@@ -564,35 +631,40 @@ func (s *Service) LeaveGroup(ctx context.Context, groupID, userID int64) error {
 	ctx = tracecontext.WithGroupID(ctx, groupID)
 	ctx = tracecontext.WithUserID(ctx, userID)
 
-	// 检查是否为群主
-	member, err := s.dao.GetMember(ctx, groupID, userID)
-	if err != nil {
-		span.RecordError(err)
-		span.SetStatus(codes.Error, "failed to get member")
-		return fmt.Errorf("获取成员信息失败: %v", err)
-	}
-	if member.Role == model.RoleOwner {
-		span.SetStatus(codes.Error, "owner cannot leave")
-		return fmt.Errorf("群主不能离开群组")
-	}
+	// 持有群成员变更锁检查角色并移除成员，不会与转让群主给该成员并发执行；成员数在同一事务中递减
+	left := false
+	err := s.withGroupMemberLock(ctx, groupID, func(ctx context.Context) error {
+		isMember, err := s.dao.IsMember(ctx, groupID, userID)
+		if err != nil {
+			return fmt.Errorf("检查成员关系失败: %v", err)
+		}
+		if !isMember {
+			return nil
+		}
 
-	// 移除成员
-	if err := s.dao.RemoveMember(ctx, groupID, userID); err != nil {
+		// 检查是否为群主
+		member, err := s.dao.GetMember(ctx, groupID, userID)
+		if err != nil {
+			return fmt.Errorf("获取成员信息失败: %v", err)
+		}
+		if member.Role == model.RoleOwner {
+			return fmt.Errorf("群主不能离开群组")
+		}
+		if err := s.dao.RemoveMember(ctx, groupID, userID); err != nil {
+			return fmt.Errorf("移除成员失败: %v", err)
+		}
+		left = true
+		return nil
+	})
+	if err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, "failed to remove member")
-		return fmt.Errorf("移除成员失败: %v", err)
+		return err
 	}
-
-	// 更新成员数量
-	group, err := s.dao.GetGroup(ctx, groupID)
-	if err == nil {
-		newCount := group.MemberCount - 1
-		if err := s.dao.UpdateMemberCount(ctx, groupID, newCount); err != nil {
-			s.logger.Error(ctx, "Failed to update member count",
-				logger.F("groupID", groupID),
-				logger.F("count", newCount),
-				logger.F("error", err.Error()))
-		}
+	if !left {
+		span.SetAttributes(attribute.Bool("group.not_member", true))
+		span.SetStatus(codes.Ok, "not a member")
+		return nil
 	}
 
 	s.logger.Info(ctx, "User left group successfully",
@@ -701,6 +773,41 @@ func (s *Service) ValidateGroupMembership(ctx context.Context, userID, groupID i
 	return isMember, nil
 }
 
+// GetGroupMember 获取群成员信息（角色、入群时间等），非群成员返回nil
+func (s *Service) GetGroupMember(ctx context.Context, groupID, userID int64) (*model.GroupMember, error) {
+	// 开始OpenTelemetry span
+	ctx, span := telemetry.StartSpan(ctx, "social.service.GetGroupMember")
+	defer span.End()
+
+	// 设置span属性
+	span.SetAttributes(
+		attribute.Int64("group.id", groupID),
+		attribute.Int64("group.user_id", userID),
+	)
+
+	isMember, err := s.dao.IsMember(ctx, groupID, userID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to check member")
+		return nil, fmt.Errorf("检查群成员失败: %v", err)
+	}
+	if !isMember {
+		span.SetStatus(codes.Ok, "not a member")
+		return nil, nil
+	}
+
+	member, err := s.dao.GetMember(ctx, groupID, userID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to get member")
+		return nil, fmt.Errorf("获取成员信息失败: %v", err)
+	}
+
+	span.SetAttributes(attribute.String("group.member_role", member.Role))
+	span.SetStatus(codes.Ok, "member retrieved successfully")
+	return member, nil
+}
+
 // GetUserSocialInfo 获取用户社交信息汇总
 func (s *Service) GetUserSocialInfo(ctx context.Context, userID int64) (*dao.SocialInfo, error) {
 	// 开始OpenTelemetry span
@@ -14,15 +14,17 @@ CREATE TABLE IF NOT EXISTS friends (
     friend_id BIGINT NOT NULL,
     remark VARCHAR(100) DEFAULT '',
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP NULL COMMENT '删除时间，已删除的关系保留为好友历史',
     
     -- 索引
     INDEX idx_user_id (user_id),
     INDEX idx_friend_id (friend_id),
-    
-    -- 唯一约束
-    UNIQUE KEY uk_user_friend (user_id, friend_id)
+    INDEX idx_friends_deleted_at (deleted_at)
 );
 
+-- 唯一约束只覆盖未删除的关系，删除好友后可以再次添加
+CREATE UNIQUE INDEX IF NOT EXISTS uk_user_friend ON friends (user_id, friend_id) WHERE deleted_at IS NULL;
+
 -- 好友申请表
 CREATE TABLE IF NOT EXISTS friend_applies (
     id BIGSERIAL PRIMARY KEY,
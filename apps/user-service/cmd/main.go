@@ -4,7 +4,6 @@ import (
 	"context"
 	"log"
 	"os"
-	"time"
 
 	"github.com/gin-gonic/gin"
 	"google.golang.org/grpc"
@@ -14,6 +13,8 @@ import (
 	"goim-social/apps/user-service/internal/handler"
 	"goim-social/apps/user-service/internal/model"
 	"goim-social/apps/user-service/internal/service"
+	"goim-social/pkg/audit"
+	"goim-social/pkg/pagination"
 	"goim-social/pkg/server"
 	"goim-social/pkg/telemetry"
 )
@@ -37,7 +38,7 @@ func main() {
 
 	// 确保在程序退出时关闭OpenTelemetry
 	defer func() {
-		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		ctx, cancel := context.WithTimeout(context.Background(), otelConfig.ShutdownTimeout)
 		defer cancel()
 		if err := telemetry.ShutdownGlobal(ctx); err != nil {
 			log.Printf("Failed to shutdown OpenTelemetry: %v", err)
@@ -62,6 +63,7 @@ func main() {
 	// 自动迁移数据库表结构
 	if err := postgreSQL.AutoMigrate(
 		&model.User{},
+		&model.ContentBan{},
 	); err != nil {
 		panic("Failed to migrate database: " + err.Error())
 	}
@@ -69,8 +71,13 @@ func main() {
 	// 初始化DAO层
 	userDAO := dao.NewUserDAO(postgreSQL)
 
+	// 初始化审计记录器，发布限制操作异步写入共享审计记录，退出前写完队列
+	cfg := app.GetConfig()
+	auditor := audit.NewRecorder(audit.NewStore(app.GetRedisClient(), cfg.Audit.RetentionDays), serviceName)
+	app.RegisterShutdownHook("audit", auditor.Close)
+
 	// 初始化Service层
-	svc := service.NewService(userDAO, app.GetRedisClient(), app.GetKafkaProducer(), app.GetLogger())
+	svc := service.NewService(userDAO, app.GetRedisClient(), app.GetKafkaProducer(), app.GetLogger(), cfg.User, auditor, pagination.New(cfg.Pagination))
 
 	// 初始化Handler
 	httpHandler := handler.NewHTTPHandler(svc, app.GetLogger())
@@ -19,14 +19,29 @@ func (c *Converter) UserModelToProto(user *model.User) *rest.UserInfo {
 		return nil
 	}
 	return &rest.UserInfo{
-		Id:        user.ID,
-		Username:  user.Username,
-		Email:     user.Email,
-		Nickname:  user.Nickname,
-		Avatar:    user.Avatar,
-		Status:    int32(user.Status),
-		CreatedAt: user.CreatedAt.Unix(),
-		UpdatedAt: user.UpdatedAt.Unix(),
+		Id:              user.ID,
+		Username:        user.Username,
+		Email:           user.Email,
+		Nickname:        user.Nickname,
+		Avatar:          user.Avatar,
+		Status:          int32(user.Status),
+		CreatedAt:       user.CreatedAt.Unix(),
+		UpdatedAt:       user.UpdatedAt.Unix(),
+		Discoverability: user.Discoverability,
+		Verified:        user.VerifiedAt != nil,
+	}
+}
+
+// PublicProfileToProto 将用户Model转换为公开资料，不包含邮箱和状态等字段
+func (c *Converter) PublicProfileToProto(user *model.User) *rest.PublicUserProfile {
+	if user == nil {
+		return nil
+	}
+	return &rest.PublicUserProfile{
+		Id:       user.ID,
+		Username: user.Username,
+		Nickname: user.Nickname,
+		Avatar:   user.Avatar,
 	}
 }
 
@@ -76,6 +91,15 @@ func (c *Converter) BuildGetUserResponse(success bool, message string, user *mod
 	}
 }
 
+// BuildBatchGetUsersResponse 构建批量获取用户响应
+func (c *Converter) BuildBatchGetUsersResponse(success bool, message string, users []*model.User) *rest.BatchGetUsersResponse {
+	return &rest.BatchGetUsersResponse{
+		Success: success,
+		Message: message,
+		Users:   c.UserModelsToProto(users),
+	}
+}
+
 // BuildUpdateUserResponse 构建更新用户信息响应
 func (c *Converter) BuildUpdateUserResponse(success bool, message string, user *model.User) *rest.UpdateUserResponse {
 	return &rest.UpdateUserResponse{
@@ -85,6 +109,24 @@ func (c *Converter) BuildUpdateUserResponse(success bool, message string, user *
 	}
 }
 
+// BuildFindUsersResponse 构建搜索用户响应，只返回公开资料
+func (c *Converter) BuildFindUsersResponse(success bool, message string, users []*model.User, total int64, page, pageSize int32) *rest.FindUsersResponse {
+	profiles := make([]*rest.PublicUserProfile, 0, len(users))
+	for _, user := range users {
+		if profile := c.PublicProfileToProto(user); profile != nil {
+			profiles = append(profiles, profile)
+		}
+	}
+	return &rest.FindUsersResponse{
+		Success:  success,
+		Message:  message,
+		Users:    profiles,
+		Total:    total,
+		Page:     page,
+		PageSize: pageSize,
+	}
+}
+
 // BuildDeleteUserResponse 构建删除用户响应
 func (c *Converter) BuildDeleteUserResponse(success bool, message string) *rest.DeleteUserResponse {
 	return &rest.DeleteUserResponse{
@@ -122,6 +164,64 @@ func (c *Converter) BuildUploadAvatarResponse(success bool, message, avatarUrl s
 	}
 }
 
+// ContentBanModelToProto 将发布限制Model转换为Protobuf
+func (c *Converter) ContentBanModelToProto(ban *model.ContentBan) *rest.ContentBan {
+	if ban == nil {
+		return nil
+	}
+
+	var expiresAt int64
+	if ban.ExpiresAt != nil {
+		expiresAt = ban.ExpiresAt.Unix()
+	}
+	return &rest.ContentBan{
+		Id:         ban.ID,
+		UserId:     ban.UserID,
+		Scope:      ban.Scope,
+		Reason:     ban.Reason,
+		OperatorId: ban.OperatorID,
+		ExpiresAt:  expiresAt,
+		CreatedAt:  ban.CreatedAt.Unix(),
+	}
+}
+
+// BuildBanUserContentResponse 构建限制用户发布响应
+func (c *Converter) BuildBanUserContentResponse(success bool, message string, ban *model.ContentBan) *rest.BanUserContentResponse {
+	return &rest.BanUserContentResponse{
+		Success: success,
+		Message: message,
+		Ban:     c.ContentBanModelToProto(ban),
+	}
+}
+
+// BuildUnbanUserContentResponse 构建解除发布限制响应
+func (c *Converter) BuildUnbanUserContentResponse(success bool, message string, revokedCount int64) *rest.UnbanUserContentResponse {
+	return &rest.UnbanUserContentResponse{
+		Success:      success,
+		Message:      message,
+		RevokedCount: int32(revokedCount),
+	}
+}
+
+// BuildGetContentBanResponse 构建查询发布限制响应
+func (c *Converter) BuildGetContentBanResponse(success bool, message string, ban *model.ContentBan) *rest.GetContentBanResponse {
+	return &rest.GetContentBanResponse{
+		Success: success,
+		Message: message,
+		Banned:  ban != nil,
+		Ban:     c.ContentBanModelToProto(ban),
+	}
+}
+
+// BuildSetUserVerifiedResponse 构建设置账号认证状态响应
+func (c *Converter) BuildSetUserVerifiedResponse(success bool, message string, user *model.User) *rest.SetUserVerifiedResponse {
+	return &rest.SetUserVerifiedResponse{
+		Success: success,
+		Message: message,
+		User:    c.UserModelToProto(user),
+	}
+}
+
 // 便捷方法：构建错误响应
 
 // BuildErrorRegisterResponse 构建注册错误响应
@@ -139,6 +239,41 @@ func (c *Converter) BuildErrorGetUserResponse(message string) *rest.GetUserRespo
 	return c.BuildGetUserResponse(false, message, nil)
 }
 
+// BuildErrorBatchGetUsersResponse 构建批量获取用户错误响应
+func (c *Converter) BuildErrorBatchGetUsersResponse(message string) *rest.BatchGetUsersResponse {
+	return c.BuildBatchGetUsersResponse(false, message, nil)
+}
+
+// BuildErrorBanUserContentResponse 构建限制用户发布错误响应
+func (c *Converter) BuildErrorBanUserContentResponse(message string) *rest.BanUserContentResponse {
+	return c.BuildBanUserContentResponse(false, message, nil)
+}
+
+// BuildErrorUnbanUserContentResponse 构建解除发布限制错误响应
+func (c *Converter) BuildErrorUnbanUserContentResponse(message string) *rest.UnbanUserContentResponse {
+	return c.BuildUnbanUserContentResponse(false, message, 0)
+}
+
+// BuildErrorGetContentBanResponse 构建查询发布限制错误响应
+func (c *Converter) BuildErrorGetContentBanResponse(message string) *rest.GetContentBanResponse {
+	return c.BuildGetContentBanResponse(false, message, nil)
+}
+
+// BuildErrorSetUserVerifiedResponse 构建设置账号认证状态错误响应
+func (c *Converter) BuildErrorSetUserVerifiedResponse(message string) *rest.SetUserVerifiedResponse {
+	return c.BuildSetUserVerifiedResponse(false, message, nil)
+}
+
+// BuildErrorUpdateUserResponse 构建更新用户信息错误响应
+func (c *Converter) BuildErrorUpdateUserResponse(message string) *rest.UpdateUserResponse {
+	return c.BuildUpdateUserResponse(false, message, nil)
+}
+
+// BuildErrorFindUsersResponse 构建搜索用户错误响应
+func (c *Converter) BuildErrorFindUsersResponse(message string) *rest.FindUsersResponse {
+	return c.BuildFindUsersResponse(false, message, nil, 0, 0, 0)
+}
+
 // BuildSuccessResponse 构建通用成功响应
 func (c *Converter) BuildSuccessResponse(message string) map[string]interface{} {
 	return map[string]interface{}{
@@ -0,0 +1,56 @@
+package dao
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+
+	"goim-social/apps/user-service/internal/model"
+)
+
+// CreateContentBan 创建发布限制
+func (d *userDAO) CreateContentBan(ctx context.Context, ban *model.ContentBan) error {
+	db := d.db.GetDB()
+	if err := db.WithContext(ctx).Create(ban).Error; err != nil {
+		return fmt.Errorf("failed to create content ban: %v", err)
+	}
+	return nil
+}
+
+// GetActiveContentBan 获取指定范围内当前生效的发布限制，永久限制优先，其次到期最晚的，没有时返回nil
+func (d *userDAO) GetActiveContentBan(ctx context.Context, userID int64, scopes []string, now time.Time) (*model.ContentBan, error) {
+	var ban model.ContentBan
+	db := d.db.GetDB()
+	err := db.WithContext(ctx).
+		Where("user_id = ? AND scope IN ? AND revoked_at IS NULL AND (expires_at IS NULL OR expires_at > ?)", userID, scopes, now).
+		Order("expires_at DESC NULLS FIRST").
+		First(&ban).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get active content ban: %v", err)
+	}
+	return &ban, nil
+}
+
+// RevokeContentBans 解除指定范围内当前生效的发布限制，scopes为空时解除全部，返回解除的条数
+func (d *userDAO) RevokeContentBans(ctx context.Context, userID int64, scopes []string, operatorID int64, now time.Time) (int64, error) {
+	db := d.db.GetDB()
+	query := db.WithContext(ctx).Model(&model.ContentBan{}).
+		Where("user_id = ? AND revoked_at IS NULL AND (expires_at IS NULL OR expires_at > ?)", userID, now)
+	if len(scopes) > 0 {
+		query = query.Where("scope IN ?", scopes)
+	}
+	result := query.Updates(map[string]interface{}{
+		"revoked_at": now,
+		"revoked_by": operatorID,
+	})
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to revoke content bans: %v", result.Error)
+	}
+	return result.RowsAffected, nil
+}
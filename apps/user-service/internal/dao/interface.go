@@ -2,6 +2,7 @@ package dao
 
 import (
 	"context"
+	"time"
 
 	"goim-social/apps/user-service/internal/model"
 )
@@ -20,9 +21,11 @@ type UserDAO interface {
 	SearchUsers(ctx context.Context, keyword string, page, pageSize int32) ([]*model.User, int64, error)
 	ListUsers(ctx context.Context, page, pageSize int32) ([]*model.User, int64, error)
 	GetUsersByIDs(ctx context.Context, userIDs []int64) ([]*model.User, error)
+	GetUsersByUsernames(ctx context.Context, usernames []string) ([]*model.User, error)
 
 	// 用户状态管理
 	UpdateUserStatus(ctx context.Context, userID int64, status int) error
+	UpdateUserVerifiedAt(ctx context.Context, userID int64, verifiedAt *time.Time) error
 	GetActiveUsers(ctx context.Context, page, pageSize int32) ([]*model.User, int64, error)
 
 	// 用户统计
@@ -32,4 +35,9 @@ type UserDAO interface {
 	// 用户验证
 	CheckUsernameExists(ctx context.Context, username string) (bool, error)
 	CheckEmailExists(ctx context.Context, email string) (bool, error)
+
+	// 发布限制
+	CreateContentBan(ctx context.Context, ban *model.ContentBan) error
+	GetActiveContentBan(ctx context.Context, userID int64, scopes []string, now time.Time) (*model.ContentBan, error)
+	RevokeContentBans(ctx context.Context, userID int64, scopes []string, operatorID int64, now time.Time) (int64, error)
 }
@@ -3,6 +3,10 @@ package dao
 import (
 	"context"
 	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm/clause"
 
 	"goim-social/apps/user-service/internal/model"
 	"goim-social/pkg/database"
@@ -84,15 +88,20 @@ func (d *userDAO) DeleteUser(ctx context.Context, userID int64) error {
 	return nil
 }
 
-// SearchUsers 搜索用户
+// SearchUsers 按用户名和昵称搜索正常状态的用户，查询时按搜索可见性过滤：
+// everyone可被模糊匹配，exact_username仅在关键词与用户名完全一致（不区分大小写）时返回，none不返回。
+// 用户名完全匹配的用户排在最前
 func (d *userDAO) SearchUsers(ctx context.Context, keyword string, page, pageSize int32) ([]*model.User, int64, error) {
 	var users []*model.User
 	var total int64
 
+	pattern := "%" + escapeLike(keyword) + "%"
 	db := d.db.GetDB()
 	query := db.WithContext(ctx).Model(&model.User{}).
-		Where("username ILIKE ? OR nickname ILIKE ? OR email ILIKE ?",
-			"%"+keyword+"%", "%"+keyword+"%", "%"+keyword+"%")
+		Where("status = ?", model.UserStatusNormal).
+		Where("(discoverability = ? AND (username ILIKE ? OR nickname ILIKE ?)) OR (discoverability = ? AND LOWER(username) = LOWER(?))",
+			model.DiscoverabilityEveryone, pattern, pattern,
+			model.DiscoverabilityExactUsername, keyword)
 
 	// 获取总数
 	if err := query.Count(&total).Error; err != nil {
@@ -101,13 +110,21 @@ func (d *userDAO) SearchUsers(ctx context.Context, keyword string, page, pageSiz
 
 	// 分页查询
 	offset := (page - 1) * pageSize
-	if err := query.Offset(int(offset)).Limit(int(pageSize)).Find(&users).Error; err != nil {
+	err := query.
+		Order(clause.Expr{SQL: "CASE WHEN LOWER(username) = LOWER(?) THEN 0 ELSE 1 END, id", Vars: []interface{}{keyword}}).
+		Offset(int(offset)).Limit(int(pageSize)).Find(&users).Error
+	if err != nil {
 		return nil, 0, fmt.Errorf("failed to search users: %v", err)
 	}
 
 	return users, total, nil
 }
 
+// escapeLike 转义LIKE模式中的通配符，关键词按字面匹配
+func escapeLike(keyword string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(keyword)
+}
+
 // ListUsers 获取用户列表
 func (d *userDAO) ListUsers(ctx context.Context, page, pageSize int32) ([]*model.User, int64, error) {
 	var users []*model.User
@@ -140,6 +157,16 @@ func (d *userDAO) GetUsersByIDs(ctx context.Context, userIDs []int64) ([]*model.
 	return users, nil
 }
 
+// GetUsersByUsernames 根据用户名列表获取用户
+func (d *userDAO) GetUsersByUsernames(ctx context.Context, usernames []string) ([]*model.User, error) {
+	var users []*model.User
+	db := d.db.GetDB()
+	if err := db.WithContext(ctx).Where("username IN ?", usernames).Find(&users).Error; err != nil {
+		return nil, fmt.Errorf("failed to get users by usernames: %v", err)
+	}
+	return users, nil
+}
+
 // UpdateUserStatus 更新用户状态
 func (d *userDAO) UpdateUserStatus(ctx context.Context, userID int64, status int) error {
 	db := d.db.GetDB()
@@ -150,6 +177,16 @@ func (d *userDAO) UpdateUserStatus(ctx context.Context, userID int64, status int
 	return nil
 }
 
+// UpdateUserVerifiedAt 更新用户认证时间，为nil时取消认证
+func (d *userDAO) UpdateUserVerifiedAt(ctx context.Context, userID int64, verifiedAt *time.Time) error {
+	db := d.db.GetDB()
+	if err := db.WithContext(ctx).Model(&model.User{}).
+		Where("id = ?", userID).Update("verified_at", verifiedAt).Error; err != nil {
+		return fmt.Errorf("failed to update user verification: %v", err)
+	}
+	return nil
+}
+
 // GetActiveUsers 获取活跃用户列表
 func (d *userDAO) GetActiveUsers(ctx context.Context, page, pageSize int32) ([]*model.User, int64, error) {
 	var users []*model.User
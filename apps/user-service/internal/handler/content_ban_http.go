@@ -0,0 +1,107 @@
+package handler
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"goim-social/api/rest"
+	tracecontext "goim-social/pkg/context"
+	"goim-social/pkg/httpx"
+	"goim-social/pkg/logger"
+)
+
+// BanUserContent 限制用户发布内容或评论
+func (h *HTTPHandler) BanUserContent(c *gin.Context) {
+	var (
+		ctx  = c.Request.Context()
+		req  rest.BanUserContentRequest
+		resp *rest.BanUserContentResponse
+		err  error
+	)
+
+	if err = c.Bind(&req); err != nil {
+		h.logger.Error(ctx, "Invalid ban user content request", logger.F("error", err.Error()))
+		resp = h.converter.BuildErrorBanUserContentResponse("Invalid request format")
+		httpx.WriteObject(c, resp, err)
+		return
+	}
+
+	// 将业务信息添加到context
+	ctx = tracecontext.WithUserID(ctx, req.OperatorId)
+
+	ban, err := h.service.BanUserContent(ctx, req.OperatorId, req.UserId, req.Scope, req.Reason, time.Duration(req.DurationSeconds)*time.Second, req.Notify)
+	if err != nil {
+		h.logger.Error(ctx, "Ban user content failed",
+			logger.F("operatorID", req.OperatorId),
+			logger.F("userID", req.UserId),
+			logger.F("error", err.Error()))
+		resp = h.converter.BuildErrorBanUserContentResponse(err.Error())
+	} else {
+		resp = h.converter.BuildBanUserContentResponse(true, "限制用户发布成功", ban)
+	}
+
+	httpx.WriteObject(c, resp, err)
+}
+
+// UnbanUserContent 解除用户的发布限制
+func (h *HTTPHandler) UnbanUserContent(c *gin.Context) {
+	var (
+		ctx  = c.Request.Context()
+		req  rest.UnbanUserContentRequest
+		resp *rest.UnbanUserContentResponse
+		err  error
+	)
+
+	if err = c.Bind(&req); err != nil {
+		h.logger.Error(ctx, "Invalid unban user content request", logger.F("error", err.Error()))
+		resp = h.converter.BuildErrorUnbanUserContentResponse("Invalid request format")
+		httpx.WriteObject(c, resp, err)
+		return
+	}
+
+	// 将业务信息添加到context
+	ctx = tracecontext.WithUserID(ctx, req.OperatorId)
+
+	revoked, err := h.service.UnbanUserContent(ctx, req.OperatorId, req.UserId, req.Scope, req.Reason)
+	if err != nil {
+		h.logger.Error(ctx, "Unban user content failed",
+			logger.F("operatorID", req.OperatorId),
+			logger.F("userID", req.UserId),
+			logger.F("error", err.Error()))
+		resp = h.converter.BuildErrorUnbanUserContentResponse(err.Error())
+	} else {
+		resp = h.converter.BuildUnbanUserContentResponse(true, "解除发布限制成功", revoked)
+	}
+
+	httpx.WriteObject(c, resp, err)
+}
+
+// GetContentBan 查询用户当前的发布限制
+func (h *HTTPHandler) GetContentBan(c *gin.Context) {
+	var (
+		ctx  = c.Request.Context()
+		req  rest.GetContentBanRequest
+		resp *rest.GetContentBanResponse
+		err  error
+	)
+
+	if err = c.Bind(&req); err != nil {
+		h.logger.Error(ctx, "Invalid get content ban request", logger.F("error", err.Error()))
+		resp = h.converter.BuildErrorGetContentBanResponse("Invalid request format")
+		httpx.WriteObject(c, resp, err)
+		return
+	}
+
+	ban, err := h.service.GetContentBan(ctx, req.UserId, req.Scope)
+	if err != nil {
+		h.logger.Error(ctx, "Get content ban failed",
+			logger.F("userID", req.UserId),
+			logger.F("error", err.Error()))
+		resp = h.converter.BuildErrorGetContentBanResponse(err.Error())
+	} else {
+		resp = h.converter.BuildGetContentBanResponse(true, "查询发布限制成功", ban)
+	}
+
+	httpx.WriteObject(c, resp, err)
+}
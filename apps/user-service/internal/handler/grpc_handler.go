@@ -40,3 +40,13 @@ func (g *GRPCHandler) Register(ctx context.Context, req *rest.RegisterRequest) (
 func (g *GRPCHandler) GetUser(ctx context.Context, req *rest.GetUserRequest) (*rest.GetUserResponse, error) {
 	return g.getUserImpl(ctx, req)
 }
+
+// BatchGetUsers 批量获取用户
+func (g *GRPCHandler) BatchGetUsers(ctx context.Context, req *rest.BatchGetUsersRequest) (*rest.BatchGetUsersResponse, error) {
+	return g.batchGetUsersImpl(ctx, req)
+}
+
+// GetContentBan 查询发布限制
+func (g *GRPCHandler) GetContentBan(ctx context.Context, req *rest.GetContentBanRequest) (*rest.GetContentBanResponse, error) {
+	return g.getContentBanImpl(ctx, req)
+}
@@ -31,5 +31,18 @@ func (h *HTTPHandler) RegisterRoutes(r *gin.Engine) {
 		api.POST("/register", h.Register)
 		api.POST("/login", h.Login)
 		api.POST("/get", h.GetUserByID)
+		api.POST("/update", h.UpdateProfile)
+		api.POST("/search", h.FindUsers)
+
+		// 发布限制（管理员）
+		ban := api.Group("/content_ban")
+		{
+			ban.POST("/ban", h.BanUserContent)
+			ban.POST("/unban", h.UnbanUserContent)
+			ban.POST("/get", h.GetContentBan)
+		}
+
+		// 账号认证（管理员），认证账号不受新账号发布冷却限制
+		api.POST("/verify", h.SetUserVerified)
 	}
 }
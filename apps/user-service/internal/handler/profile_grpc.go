@@ -29,3 +29,31 @@ func (g *GRPCHandler) getUserImpl(ctx context.Context, req *rest.GetUserRequest)
 
 	return g.converter.BuildGetUserResponse(true, "获取用户信息成功", user), nil
 }
+
+// batchGetUsersImpl 批量获取用户实现
+func (g *GRPCHandler) batchGetUsersImpl(ctx context.Context, req *rest.BatchGetUsersRequest) (*rest.BatchGetUsersResponse, error) {
+	users, err := g.svc.BatchGetUsers(ctx, req.UserIds, req.Usernames)
+	if err != nil {
+		g.logger.Error(ctx, "gRPC BatchGetUsers failed",
+			logger.F("userIDs", len(req.UserIds)),
+			logger.F("usernames", len(req.Usernames)),
+			logger.F("error", err.Error()))
+		return g.converter.BuildErrorBatchGetUsersResponse(err.Error()), nil
+	}
+
+	return g.converter.BuildBatchGetUsersResponse(true, "批量获取用户成功", users), nil
+}
+
+// getContentBanImpl 查询发布限制实现
+func (g *GRPCHandler) getContentBanImpl(ctx context.Context, req *rest.GetContentBanRequest) (*rest.GetContentBanResponse, error) {
+	ban, err := g.svc.GetContentBan(ctx, req.UserId, req.Scope)
+	if err != nil {
+		g.logger.Error(ctx, "gRPC GetContentBan failed",
+			logger.F("userID", req.UserId),
+			logger.F("scope", req.Scope),
+			logger.F("error", err.Error()))
+		return g.converter.BuildErrorGetContentBanResponse(err.Error()), nil
+	}
+
+	return g.converter.BuildGetContentBanResponse(true, "查询发布限制成功", ban), nil
+}
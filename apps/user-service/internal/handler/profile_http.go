@@ -4,6 +4,7 @@ import (
 	"github.com/gin-gonic/gin"
 
 	rest "goim-social/api/rest"
+	"goim-social/apps/user-service/internal/service"
 	tracecontext "goim-social/pkg/context"
 	"goim-social/pkg/httpx"
 	"goim-social/pkg/logger"
@@ -47,3 +48,70 @@ func (h *HTTPHandler) GetUserByID(c *gin.Context) {
 	resp = h.converter.BuildGetUserResponse(true, "获取用户成功", user)
 	httpx.WriteObject(c, resp, nil)
 }
+
+// UpdateProfile 更新昵称、头像和搜索可见性
+func (h *HTTPHandler) UpdateProfile(c *gin.Context) {
+	var (
+		ctx  = c.Request.Context()
+		req  rest.UpdateUserRequest
+		resp *rest.UpdateUserResponse
+		err  error
+	)
+
+	if err = c.Bind(&req); err != nil {
+		h.logger.Error(ctx, "Invalid update profile request", logger.F("error", err.Error()))
+		resp = h.converter.BuildErrorUpdateUserResponse("Invalid request format")
+		httpx.WriteObject(c, resp, err)
+		return
+	}
+
+	// 将用户ID添加到context
+	ctx = tracecontext.WithUserID(ctx, req.UserId)
+
+	user, err := h.service.UpdateProfile(ctx, req.UserId, service.ProfileUpdate{
+		Nickname:        req.Nickname,
+		Avatar:          req.Avatar,
+		Discoverability: req.Discoverability,
+	})
+	if err != nil {
+		h.logger.Error(ctx, "Update profile failed",
+			logger.F("user_id", req.UserId),
+			logger.F("error", err.Error()))
+		resp = h.converter.BuildErrorUpdateUserResponse(err.Error())
+		httpx.WriteObject(c, resp, err)
+		return
+	}
+
+	resp = h.converter.BuildUpdateUserResponse(true, "更新资料成功", user)
+	httpx.WriteObject(c, resp, nil)
+}
+
+// FindUsers 按用户名和昵称搜索用户，只返回公开资料
+func (h *HTTPHandler) FindUsers(c *gin.Context) {
+	var (
+		ctx  = c.Request.Context()
+		req  rest.FindUsersRequest
+		resp *rest.FindUsersResponse
+		err  error
+	)
+
+	if err = c.Bind(&req); err != nil {
+		h.logger.Error(ctx, "Invalid find users request", logger.F("error", err.Error()))
+		resp = h.converter.BuildErrorFindUsersResponse("Invalid request format")
+		httpx.WriteObject(c, resp, err)
+		return
+	}
+
+	users, total, err := h.service.SearchUsers(ctx, req.UserId, req.Keyword, req.Page, req.PageSize)
+	if err != nil {
+		h.logger.Error(ctx, "Find users failed",
+			logger.F("user_id", req.UserId),
+			logger.F("error", err.Error()))
+		resp = h.converter.BuildErrorFindUsersResponse(err.Error())
+		httpx.WriteObject(c, resp, err)
+		return
+	}
+
+	resp = h.converter.BuildFindUsersResponse(true, "搜索用户成功", users, total, req.Page, req.PageSize)
+	httpx.WriteObject(c, resp, nil)
+}
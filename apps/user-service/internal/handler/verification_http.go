@@ -0,0 +1,43 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"goim-social/api/rest"
+	tracecontext "goim-social/pkg/context"
+	"goim-social/pkg/httpx"
+	"goim-social/pkg/logger"
+)
+
+// SetUserVerified 认证或取消认证用户账号
+func (h *HTTPHandler) SetUserVerified(c *gin.Context) {
+	var (
+		ctx  = c.Request.Context()
+		req  rest.SetUserVerifiedRequest
+		resp *rest.SetUserVerifiedResponse
+		err  error
+	)
+
+	if err = c.Bind(&req); err != nil {
+		h.logger.Error(ctx, "Invalid set user verified request", logger.F("error", err.Error()))
+		resp = h.converter.BuildErrorSetUserVerifiedResponse("Invalid request format")
+		httpx.WriteObject(c, resp, err)
+		return
+	}
+
+	// 将业务信息添加到context
+	ctx = tracecontext.WithUserID(ctx, req.OperatorId)
+
+	user, err := h.service.SetUserVerified(ctx, req.OperatorId, req.UserId, req.Verified, req.Reason)
+	if err != nil {
+		h.logger.Error(ctx, "Set user verified failed",
+			logger.F("operatorID", req.OperatorId),
+			logger.F("userID", req.UserId),
+			logger.F("error", err.Error()))
+		resp = h.converter.BuildErrorSetUserVerifiedResponse(err.Error())
+	} else {
+		resp = h.converter.BuildSetUserVerifiedResponse(true, "设置账号认证状态成功", user)
+	}
+
+	httpx.WriteObject(c, resp, err)
+}
@@ -4,20 +4,98 @@ import (
 	"time"
 )
 
+// 用户状态
+const (
+	UserStatusNormal   = 0
+	UserStatusDisabled = 1
+	UserStatusDeleted  = 2
+)
+
 // User 用户模型
 type User struct {
-	ID        int64     `json:"id" gorm:"primaryKey;autoIncrement"`
-	Username  string    `json:"username" gorm:"type:varchar(50);uniqueIndex;not null"`
-	Password  string    `json:"-" gorm:"type:varchar(255);not null"`
-	Email     string    `json:"email" gorm:"type:varchar(100);uniqueIndex;not null"`
-	Nickname  string    `json:"nickname" gorm:"type:varchar(100);not null"`
-	Avatar    string    `json:"avatar" gorm:"type:varchar(500)"`
-	Status    int       `json:"status" gorm:"default:0;index"` // 0:正常 1:禁用 2:删除
-	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
-	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+	ID              int64      `json:"id" gorm:"primaryKey;autoIncrement"`
+	Username        string     `json:"username" gorm:"type:varchar(50);uniqueIndex;not null"`
+	Password        string     `json:"-" gorm:"type:varchar(255);not null"`
+	Email           string     `json:"email" gorm:"type:varchar(100);uniqueIndex;not null"`
+	Nickname        string     `json:"nickname" gorm:"type:varchar(100);not null"`
+	Avatar          string     `json:"avatar" gorm:"type:varchar(500)"`
+	Status          int        `json:"status" gorm:"default:0;index"`                                       // 0:正常 1:禁用 2:删除
+	Discoverability string     `json:"discoverability" gorm:"type:varchar(20);not null;default:'everyone'"` // 搜索可见性，默认可被模糊搜索
+	VerifiedAt      *time.Time `json:"verified_at"`                                                         // 认证时间，为空表示未认证；认证账号不受新账号发布冷却限制
+	CreatedAt       time.Time  `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt       time.Time  `json:"updated_at" gorm:"autoUpdateTime"`
 }
 
 // TableName .
 func (User) TableName() string {
 	return "users"
 }
+
+// 搜索可见性
+const (
+	DiscoverabilityEveryone      = "everyone"       // 可按用户名和昵称模糊搜索
+	DiscoverabilityExactUsername = "exact_username" // 仅输入完整用户名时可被搜到
+	DiscoverabilityNone          = "none"           // 不出现在任何搜索结果中
+)
+
+// 用户搜索和资料更新限制
+const (
+	MinSearchKeywordLength = 2   // 模糊搜索关键词最少字符数，避免单字符扫描全表
+	MaxSearchKeywordLength = 50  // 搜索关键词最多字符数
+	MaxNicknameLength      = 100 // 昵称最多字符数
+	MaxAvatarLength        = 500 // 头像地址最大长度
+)
+
+// ValidateDiscoverability 验证搜索可见性取值
+func ValidateDiscoverability(discoverability string) bool {
+	return discoverability == DiscoverabilityEveryone ||
+		discoverability == DiscoverabilityExactUsername ||
+		discoverability == DiscoverabilityNone
+}
+
+// 发布限制范围
+const (
+	BanScopeContent = "content" // 禁止发布内容
+	BanScopeComment = "comment" // 禁止发表评论
+	BanScopeAll     = "all"     // 禁止发布内容和评论
+)
+
+// 发布限制相关常量
+const (
+	MaxBanReasonLength    = 500
+	EventTypeContentBan   = "content_ban"       // 发布限制通知事件类型
+	MessageTypeContentBan = 105                 // 发布限制通知消息类型
+	TopicDownlinkMessage  = "downlink_messages" // 下行消息Topic，由message-service推送
+)
+
+// ContentBan 发布限制，只限制发布新的内容或评论，不影响登录和已发布内容的可见性
+// 到期后自动失效，提前解除时记录解除时间
+type ContentBan struct {
+	ID         int64      `json:"id" gorm:"primaryKey;autoIncrement"`
+	UserID     int64      `json:"user_id" gorm:"not null;index"`
+	Scope      string     `json:"scope" gorm:"type:varchar(20);not null"`
+	Reason     string     `json:"reason" gorm:"type:varchar(500)"`
+	OperatorID int64      `json:"operator_id" gorm:"not null"`
+	ExpiresAt  *time.Time `json:"expires_at"` // 为空表示永久
+	RevokedAt  *time.Time `json:"revoked_at"`
+	RevokedBy  int64      `json:"revoked_by"`
+	CreatedAt  time.Time  `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// TableName .
+func (ContentBan) TableName() string {
+	return "content_bans"
+}
+
+// ContentBanNotice 发布限制通知内容
+type ContentBanNotice struct {
+	BanID     int64  `json:"ban_id"`
+	Scope     string `json:"scope"`
+	Reason    string `json:"reason"`
+	ExpiresAt int64  `json:"expires_at"` // 0表示永久
+}
+
+// ValidateBanScope 验证发布限制范围
+func ValidateBanScope(scope string) bool {
+	return scope == BanScopeContent || scope == BanScopeComment || scope == BanScopeAll
+}
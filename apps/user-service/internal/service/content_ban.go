@@ -0,0 +1,258 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+
+	"goim-social/api/rest"
+	"goim-social/apps/user-service/internal/model"
+	"goim-social/pkg/audit"
+	tracecontext "goim-social/pkg/context"
+	"goim-social/pkg/logger"
+	"goim-social/pkg/telemetry"
+)
+
+// ==================== 发布限制 ====================
+// 管理员可以限制用户在一段时间内发布内容或评论，不封禁账号，已发布的内容保持可见。
+// 限制到期后按到期时间自动失效，无需清理任务；content-service在创建内容和评论时查询
+
+// BanUserContent 限制用户发布，duration为0表示永久，仅管理员可操作
+func (s *Service) BanUserContent(ctx context.Context, operatorID, userID int64, scope, reason string, duration time.Duration, notify bool) (*model.ContentBan, error) {
+	// 开始OpenTelemetry span
+	ctx, span := telemetry.StartSpan(ctx, "user.service.BanUserContent")
+	defer span.End()
+
+	// 设置span属性
+	span.SetAttributes(
+		attribute.Int64("ban.operator_id", operatorID),
+		attribute.Int64("ban.user_id", userID),
+		attribute.String("ban.scope", scope),
+		attribute.Int64("ban.duration_seconds", int64(duration/time.Second)),
+	)
+
+	// 将业务信息添加到context
+	ctx = tracecontext.WithUserID(ctx, operatorID)
+
+	if !s.admins[operatorID] {
+		span.SetStatus(codes.Error, "permission denied")
+		return nil, fmt.Errorf("无权限限制用户发布")
+	}
+	if userID <= 0 {
+		span.SetStatus(codes.Error, "invalid user ID")
+		return nil, fmt.Errorf("用户ID无效")
+	}
+	if !model.ValidateBanScope(scope) {
+		span.SetStatus(codes.Error, "invalid scope")
+		return nil, fmt.Errorf("限制范围无效")
+	}
+	if duration < 0 {
+		span.SetStatus(codes.Error, "invalid duration")
+		return nil, fmt.Errorf("限制时长无效")
+	}
+	reason = strings.TrimSpace(reason)
+	if len([]rune(reason)) > model.MaxBanReasonLength {
+		span.SetStatus(codes.Error, "reason too long")
+		return nil, fmt.Errorf("限制原因过长，最多%d个字符", model.MaxBanReasonLength)
+	}
+
+	user, err := s.dao.GetUser(ctx, userID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "user not found")
+		return nil, err
+	}
+	if user.Status == model.UserStatusDeleted {
+		span.SetStatus(codes.Error, "user deleted")
+		return nil, fmt.Errorf("用户不存在")
+	}
+
+	ban := &model.ContentBan{
+		UserID:     userID,
+		Scope:      scope,
+		Reason:     reason,
+		OperatorID: operatorID,
+	}
+	if duration > 0 {
+		expiresAt := time.Now().Add(duration)
+		ban.ExpiresAt = &expiresAt
+	}
+	if err := s.dao.CreateContentBan(ctx, ban); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to create content ban")
+		return nil, err
+	}
+
+	s.auditor.Record(audit.Entry{
+		ActorID:    operatorID,
+		Action:     audit.ActionUserContentBan,
+		TargetType: audit.TargetUser,
+		TargetID:   userID,
+		Reason:     reason,
+		Detail:     map[string]string{"scope": scope, "expires_at": formatBanExpiry(ban)},
+	})
+
+	if notify {
+		s.publishContentBanNotice(ctx, ban)
+	}
+
+	s.logger.Info(ctx, "User content banned",
+		logger.F("operatorID", operatorID),
+		logger.F("userID", userID),
+		logger.F("scope", scope),
+		logger.F("expiresAt", formatBanExpiry(ban)))
+
+	span.SetAttributes(attribute.Int64("ban.id", ban.ID))
+	span.SetStatus(codes.Ok, "user content banned")
+	return ban, nil
+}
+
+// UnbanUserContent 提前解除用户指定范围的发布限制，scope为空时解除全部，仅管理员可操作
+func (s *Service) UnbanUserContent(ctx context.Context, operatorID, userID int64, scope, reason string) (int64, error) {
+	// 开始OpenTelemetry span
+	ctx, span := telemetry.StartSpan(ctx, "user.service.UnbanUserContent")
+	defer span.End()
+
+	// 设置span属性
+	span.SetAttributes(
+		attribute.Int64("ban.operator_id", operatorID),
+		attribute.Int64("ban.user_id", userID),
+		attribute.String("ban.scope", scope),
+	)
+
+	// 将业务信息添加到context
+	ctx = tracecontext.WithUserID(ctx, operatorID)
+
+	if !s.admins[operatorID] {
+		span.SetStatus(codes.Error, "permission denied")
+		return 0, fmt.Errorf("无权限解除用户发布限制")
+	}
+	if userID <= 0 {
+		span.SetStatus(codes.Error, "invalid user ID")
+		return 0, fmt.Errorf("用户ID无效")
+	}
+	var scopes []string
+	if scope != "" {
+		if !model.ValidateBanScope(scope) {
+			span.SetStatus(codes.Error, "invalid scope")
+			return 0, fmt.Errorf("限制范围无效")
+		}
+		scopes = []string{scope}
+	}
+
+	revoked, err := s.dao.RevokeContentBans(ctx, userID, scopes, operatorID, time.Now())
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to revoke content bans")
+		return 0, err
+	}
+
+	if revoked > 0 {
+		s.auditor.Record(audit.Entry{
+			ActorID:    operatorID,
+			Action:     audit.ActionUserContentUnban,
+			TargetType: audit.TargetUser,
+			TargetID:   userID,
+			Reason:     strings.TrimSpace(reason),
+			Detail:     map[string]string{"scope": scope, "revoked": strconv.FormatInt(revoked, 10)},
+		})
+	}
+
+	span.SetAttributes(attribute.Int64("ban.revoked_count", revoked))
+	span.SetStatus(codes.Ok, "user content unbanned")
+	return revoked, nil
+}
+
+// GetContentBan 获取用户在指定范围内当前生效的发布限制，没有限制时返回nil
+// scope为content或comment时，all范围的限制同样生效
+func (s *Service) GetContentBan(ctx context.Context, userID int64, scope string) (*model.ContentBan, error) {
+	// 开始OpenTelemetry span
+	ctx, span := telemetry.StartSpan(ctx, "user.service.GetContentBan")
+	defer span.End()
+
+	// 设置span属性
+	span.SetAttributes(
+		attribute.Int64("ban.user_id", userID),
+		attribute.String("ban.scope", scope),
+	)
+
+	if userID <= 0 {
+		span.SetStatus(codes.Error, "invalid user ID")
+		return nil, fmt.Errorf("用户ID无效")
+	}
+
+	var scopes []string
+	switch scope {
+	case model.BanScopeContent, model.BanScopeComment:
+		scopes = []string{scope, model.BanScopeAll}
+	case model.BanScopeAll, "":
+		scopes = []string{model.BanScopeContent, model.BanScopeComment, model.BanScopeAll}
+	default:
+		span.SetStatus(codes.Error, "invalid scope")
+		return nil, fmt.Errorf("限制范围无效")
+	}
+
+	ban, err := s.dao.GetActiveContentBan(ctx, userID, scopes, time.Now())
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to get content ban")
+		return nil, err
+	}
+
+	span.SetAttributes(attribute.Bool("ban.active", ban != nil))
+	span.SetStatus(codes.Ok, "content ban retrieved")
+	return ban, nil
+}
+
+// publishContentBanNotice 通知被限制的用户，由message-service推送，发送失败只记录日志
+func (s *Service) publishContentBanNotice(ctx context.Context, ban *model.ContentBan) {
+	if s.kafka == nil {
+		return
+	}
+
+	notice := &model.ContentBanNotice{
+		BanID:  ban.ID,
+		Scope:  ban.Scope,
+		Reason: ban.Reason,
+	}
+	if ban.ExpiresAt != nil {
+		notice.ExpiresAt = ban.ExpiresAt.Unix()
+	}
+	data, err := json.Marshal(notice)
+	if err != nil {
+		return
+	}
+
+	now := time.Now().Unix()
+	event := &rest.MessageEvent{
+		Type: model.EventTypeContentBan,
+		Message: &rest.WSMessage{
+			MessageId:   ban.ID,
+			To:          ban.UserID,
+			Content:     string(data),
+			MessageType: model.MessageTypeContentBan,
+			Timestamp:   now,
+		},
+		Timestamp: now,
+	}
+	if err := s.kafka.PublishMessage(model.TopicDownlinkMessage, event); err != nil {
+		s.logger.Warn(ctx, "Failed to publish content ban notice",
+			logger.F("banID", ban.ID),
+			logger.F("userID", ban.UserID),
+			logger.F("error", err.Error()))
+	}
+}
+
+// formatBanExpiry 返回限制到期时间的文本表示，永久限制为permanent
+func formatBanExpiry(ban *model.ContentBan) string {
+	if ban.ExpiresAt == nil {
+		return "permanent"
+	}
+	return ban.ExpiresAt.Format(time.RFC3339)
+}
@@ -0,0 +1,172 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+
+	"goim-social/apps/user-service/internal/model"
+	tracecontext "goim-social/pkg/context"
+	"goim-social/pkg/httpx"
+	"goim-social/pkg/logger"
+	"goim-social/pkg/telemetry"
+)
+
+// ==================== 资料更新与用户搜索 ====================
+// 用户可以通过搜索可见性控制自己能否被其他用户搜到：everyone可被用户名和昵称模糊搜索，
+// exact_username只在输入完整用户名时出现，none不出现在搜索结果中。可见性在查询条件中过滤，
+// 被过滤的用户不会计入总数；搜索结果只返回公开资料
+
+// ProfileUpdate 资料更新参数，字段为空时保持不变
+type ProfileUpdate struct {
+	Nickname        string
+	Avatar          string
+	Discoverability string
+}
+
+// UpdateProfile 更新用户昵称、头像和搜索可见性
+func (s *Service) UpdateProfile(ctx context.Context, userID int64, update ProfileUpdate) (*model.User, error) {
+	// 开始OpenTelemetry span
+	ctx, span := telemetry.StartSpan(ctx, "user.service.UpdateProfile")
+	defer span.End()
+
+	// 设置span属性
+	span.SetAttributes(
+		attribute.Int64("user.id", userID),
+		attribute.String("user.discoverability", update.Discoverability),
+	)
+
+	// 将用户ID添加到context
+	ctx = tracecontext.WithUserID(ctx, userID)
+
+	if userID <= 0 {
+		span.SetStatus(codes.Error, "invalid user ID")
+		return nil, httpx.InvalidArgument(fmt.Errorf("用户ID无效"))
+	}
+	nickname := strings.TrimSpace(update.Nickname)
+	if len([]rune(nickname)) > model.MaxNicknameLength {
+		span.SetStatus(codes.Error, "nickname too long")
+		return nil, httpx.InvalidArgument(fmt.Errorf("昵称过长，最多%d个字符", model.MaxNicknameLength))
+	}
+	avatar := strings.TrimSpace(update.Avatar)
+	if len(avatar) > model.MaxAvatarLength {
+		span.SetStatus(codes.Error, "avatar too long")
+		return nil, httpx.InvalidArgument(fmt.Errorf("头像地址过长"))
+	}
+	if update.Discoverability != "" && !model.ValidateDiscoverability(update.Discoverability) {
+		span.SetStatus(codes.Error, "invalid discoverability")
+		return nil, httpx.InvalidArgument(fmt.Errorf("搜索可见性无效: %s", update.Discoverability))
+	}
+
+	user, err := s.dao.GetUser(ctx, userID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "user not found")
+		return nil, err
+	}
+	if user.Status == model.UserStatusDeleted {
+		span.SetStatus(codes.Error, "user deleted")
+		return nil, fmt.Errorf("用户不存在")
+	}
+
+	if nickname != "" {
+		user.Nickname = nickname
+	}
+	if avatar != "" {
+		user.Avatar = avatar
+	}
+	if update.Discoverability != "" {
+		user.Discoverability = update.Discoverability
+	}
+	if err := s.dao.UpdateUser(ctx, user); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to update user")
+		return nil, err
+	}
+
+	s.logger.Info(ctx, "User profile updated",
+		logger.F("userID", userID),
+		logger.F("discoverability", user.Discoverability))
+
+	span.SetStatus(codes.Ok, "user profile updated")
+	return user, nil
+}
+
+// SearchUsers 按用户名和昵称搜索用户，只返回允许被搜索的正常用户
+// 关键词少于MinSearchKeywordLength个字符时只能精确匹配用户名，避免过短的关键词遍历用户
+func (s *Service) SearchUsers(ctx context.Context, viewerID int64, keyword string, page, pageSize int32) ([]*model.User, int64, error) {
+	// 开始OpenTelemetry span
+	ctx, span := telemetry.StartSpan(ctx, "user.service.SearchUsers")
+	defer span.End()
+
+	// 设置span属性
+	span.SetAttributes(
+		attribute.Int64("search.viewer_id", viewerID),
+		attribute.Int("search.keyword_length", len([]rune(keyword))),
+		attribute.Int("search.page", int(page)),
+		attribute.Int("search.page_size", int(pageSize)),
+	)
+
+	if viewerID > 0 {
+		ctx = tracecontext.WithUserID(ctx, viewerID)
+	}
+
+	keyword = strings.TrimSpace(keyword)
+	if keyword == "" {
+		span.SetStatus(codes.Error, "empty keyword")
+		return nil, 0, httpx.InvalidArgument(fmt.Errorf("搜索关键词不能为空"))
+	}
+	if len([]rune(keyword)) > model.MaxSearchKeywordLength {
+		span.SetStatus(codes.Error, "keyword too long")
+		return nil, 0, httpx.InvalidArgument(fmt.Errorf("搜索关键词过长，最多%d个字符", model.MaxSearchKeywordLength))
+	}
+	if page <= 0 {
+		page = 1
+	}
+	pageSize = s.paging.PageSize32(pageSize)
+
+	var (
+		users []*model.User
+		total int64
+		err   error
+	)
+	if len([]rune(keyword)) < model.MinSearchKeywordLength {
+		users, total, err = s.searchExactUsername(ctx, keyword, page)
+	} else {
+		users, total, err = s.dao.SearchUsers(ctx, keyword, page, pageSize)
+	}
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to search users")
+		return nil, 0, httpx.Unavailable(fmt.Errorf("搜索用户失败: %v", err))
+	}
+
+	span.SetAttributes(
+		attribute.Int("result.count", len(users)),
+		attribute.Int64("result.total", total),
+	)
+	span.SetStatus(codes.Ok, "users searched successfully")
+	return users, total, nil
+}
+
+// searchExactUsername 精确匹配用户名，关闭了搜索可见性的用户不返回
+func (s *Service) searchExactUsername(ctx context.Context, username string, page int32) ([]*model.User, int64, error) {
+	users, err := s.dao.GetUsersByUsernames(ctx, []string{username})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var matched []*model.User
+	for _, user := range users {
+		if user.Status == model.UserStatusNormal && user.Discoverability != model.DiscoverabilityNone {
+			matched = append(matched, user)
+		}
+	}
+	if page > 1 {
+		return []*model.User{}, int64(len(matched)), nil
+	}
+	return matched, int64(len(matched)), nil
+}
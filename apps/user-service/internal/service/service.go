@@ -3,6 +3,8 @@ package service
 import (
 	"context"
 	"errors"
+	"fmt"
+	"strings"
 	"time"
 
 	"go.opentelemetry.io/otel/attribute"
@@ -11,29 +13,38 @@ import (
 	"goim-social/api/rest"
 	"goim-social/apps/user-service/internal/dao"
 	"goim-social/apps/user-service/internal/model"
+	"goim-social/pkg/audit"
 	"goim-social/pkg/auth"
+	"goim-social/pkg/config"
 	tracecontext "goim-social/pkg/context"
 	"goim-social/pkg/kafka"
 	"goim-social/pkg/logger"
+	"goim-social/pkg/pagination"
 	"goim-social/pkg/redis"
 	"goim-social/pkg/telemetry"
 )
 
 // Service 用户服务
 type Service struct {
-	dao    dao.UserDAO
-	redis  *redis.RedisClient
-	kafka  *kafka.Producer
-	logger logger.Logger
+	dao     dao.UserDAO
+	redis   *redis.RedisClient
+	kafka   *kafka.Producer
+	logger  logger.Logger
+	auditor *audit.Recorder
+	admins  map[int64]bool    // 允许限制用户发布权限、设置账号认证状态的管理员
+	paging  pagination.Limits // 批量查询数量限制
 }
 
 // NewService 创建用户服务
-func NewService(userDAO dao.UserDAO, redis *redis.RedisClient, kafka *kafka.Producer, log logger.Logger) *Service {
+func NewService(userDAO dao.UserDAO, redis *redis.RedisClient, kafka *kafka.Producer, log logger.Logger, cfg config.UserConfig, auditor *audit.Recorder, paging pagination.Limits) *Service {
 	return &Service{
-		dao:    userDAO,
-		redis:  redis,
-		kafka:  kafka,
-		logger: log,
+		dao:     userDAO,
+		redis:   redis,
+		kafka:   kafka,
+		logger:  log,
+		auditor: auditor,
+		admins:  config.ParseUserIDs(cfg.AdminIDs),
+		paging:  paging,
 	}
 }
 
@@ -76,11 +87,12 @@ func (s *Service) Register(ctx context.Context, req *rest.RegisterRequest) (*res
 
 	// 创建用户
 	user := &model.User{
-		Username: req.Username,
-		Password: req.Password, // TODO: 加密
-		Email:    req.Email,
-		Nickname: req.Nickname,
-		Status:   0, // 正常状态
+		Username:        req.Username,
+		Password:        req.Password, // TODO: 加密
+		Email:           req.Email,
+		Nickname:        req.Nickname,
+		Status:          0, // 正常状态
+		Discoverability: model.DiscoverabilityEveryone,
 	}
 
 	err = s.dao.CreateUser(ctx, user)
@@ -102,11 +114,12 @@ func (s *Service) Register(ctx context.Context, req *rest.RegisterRequest) (*res
 	span.SetStatus(codes.Ok, "user registered successfully")
 	return &rest.RegisterResponse{
 		User: &rest.UserInfo{
-			Id:       user.ID,
-			Username: user.Username,
-			Email:    user.Email,
-			Nickname: user.Nickname,
-			Avatar:   user.Avatar,
+			Id:              user.ID,
+			Username:        user.Username,
+			Email:           user.Email,
+			Nickname:        user.Nickname,
+			Avatar:          user.Avatar,
+			Discoverability: user.Discoverability,
 		},
 	}, nil
 }
@@ -205,3 +218,74 @@ func (s *Service) GetUserByID(ctx context.Context, userID int64) (*model.User, e
 	span.SetStatus(codes.Ok, "user retrieved successfully")
 	return user, nil
 }
+
+// BatchGetUsers 按用户ID和用户名批量获取用户，结果去重，不存在和已删除的用户不返回
+func (s *Service) BatchGetUsers(ctx context.Context, userIDs []int64, usernames []string) ([]*model.User, error) {
+	// 开始OpenTelemetry span
+	ctx, span := telemetry.StartSpan(ctx, "user.service.BatchGetUsers")
+	defer span.End()
+
+	// 设置span属性
+	span.SetAttributes(
+		attribute.Int("user.id_count", len(userIDs)),
+		attribute.Int("user.username_count", len(usernames)),
+	)
+
+	maxBatch := s.paging.MaxBatchSize()
+	if len(userIDs) > maxBatch || len(usernames) > maxBatch {
+		span.SetStatus(codes.Error, "too many users")
+		return nil, fmt.Errorf("批量获取用户一次最多%d个", maxBatch)
+	}
+
+	ids := make([]int64, 0, len(userIDs))
+	seenIDs := make(map[int64]bool, len(userIDs))
+	for _, id := range userIDs {
+		if id > 0 && !seenIDs[id] {
+			seenIDs[id] = true
+			ids = append(ids, id)
+		}
+	}
+	names := make([]string, 0, len(usernames))
+	seenNames := make(map[string]bool, len(usernames))
+	for _, name := range usernames {
+		name = strings.TrimSpace(name)
+		if name != "" && !seenNames[name] {
+			seenNames[name] = true
+			names = append(names, name)
+		}
+	}
+
+	var found []*model.User
+	if len(ids) > 0 {
+		users, err := s.dao.GetUsersByIDs(ctx, ids)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, "failed to get users by IDs")
+			return nil, err
+		}
+		found = append(found, users...)
+	}
+	if len(names) > 0 {
+		users, err := s.dao.GetUsersByUsernames(ctx, names)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, "failed to get users by usernames")
+			return nil, err
+		}
+		found = append(found, users...)
+	}
+
+	result := make([]*model.User, 0, len(found))
+	returned := make(map[int64]bool, len(found))
+	for _, user := range found {
+		if user.Status == model.UserStatusDeleted || returned[user.ID] {
+			continue
+		}
+		returned[user.ID] = true
+		result = append(result, user)
+	}
+
+	span.SetAttributes(attribute.Int("result.count", len(result)))
+	span.SetStatus(codes.Ok, "users retrieved successfully")
+	return result, nil
+}
@@ -0,0 +1,316 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math/rand"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	goredis "github.com/go-redis/redis/v8"
+
+	"goim-social/pkg/redis"
+)
+
+// Redis键，审计记录以JSON作为有序集合成员、以毫秒时间戳作为分数，
+// 除全量索引外按操作者和操作对象各建一份索引，便于按条件查询
+const (
+	KeyAll          = "audit:log"    // 全部审计记录
+	KeyActorPrefix  = "audit:actor"  // 按操作者索引 audit:actor:{actorID}
+	KeyTargetPrefix = "audit:target" // 按操作对象索引 audit:target:{targetType}:{targetID}
+)
+
+const (
+	DefaultRetentionDays = 180  // 默认保留天数
+	DefaultQueryLimit    = 50   // 默认查询条数
+	MaxQueryLimit        = 200  // 单次查询最大条数
+	queueSize            = 1024 // 待写入队列长度，写满后丢弃新记录
+	queryScanRounds      = 5    // 附加条件过滤时最多扫描的批次
+)
+
+// 审计动作
+const (
+	ActionContentStatusChange    = "content.status_change"     // 内容状态变更（审核通过、拒绝、下架、恢复等）
+	ActionCommentModerate        = "comment.moderate"          // 评论审核（隐藏、恢复、删除）
+	ActionReportResolve          = "report.resolve"            // 处理举报
+	ActionBroadcast              = "message.broadcast"         // 系统消息广播
+	ActionAnnouncementRevert     = "group.announcement_revert" // 回滚群公告
+	ActionJoinRequestReview      = "group.join_request_review" // 处理加群申请（同意、拒绝）
+	ActionGroupInfoUpdate        = "group.info_update"         // 修改群名称、简介或头像
+	ActionGroupOwnerTransfer     = "group.owner_transfer"      // 转让群主
+	ActionGroupHistoryVisibility = "group.history_visibility"  // 设置新成员能否查看入群前的历史消息
+	ActionGroupJoinMode          = "group.join_mode"           // 设置入群方式（直接加入、需审批）
+	ActionUserContentBan         = "user.content_ban"          // 限制用户发布内容或评论
+	ActionUserContentUnban       = "user.content_unban"        // 解除用户的发布限制
+	ActionUserVerify             = "user.verify"               // 认证或取消认证用户账号
+)
+
+// 操作对象类型，内容和评论沿用content-service的目标类型
+const (
+	TargetGroup = "group" // 群组
+	TargetUser  = "user"  // 用户
+	TargetAll   = "all"   // 全体用户，如全员广播
+)
+
+// Entry 审计记录
+type Entry struct {
+	ID         string            `json:"id"`
+	Service    string            `json:"service"`     // 记录来源服务
+	ActorID    int64             `json:"actor_id"`    // 操作者，系统自动操作为0
+	Action     string            `json:"action"`      // 动作
+	TargetType string            `json:"target_type"` // 操作对象类型
+	TargetID   int64             `json:"target_id"`   // 操作对象ID
+	Reason     string            `json:"reason,omitempty"`
+	Detail     map[string]string `json:"detail,omitempty"` // 附加信息，如变更前后的状态
+	CreatedAt  int64             `json:"created_at"`       // 毫秒时间戳
+}
+
+// Filter 查询条件，各条件之间为与关系，零值表示不限
+type Filter struct {
+	ActorID    int64
+	TargetType string
+	TargetID   int64
+	Action     string
+	StartTime  int64 // 毫秒时间戳，包含
+	EndTime    int64 // 毫秒时间戳，包含
+	Limit      int
+}
+
+func actorKey(actorID int64) string {
+	return fmt.Sprintf("%s:%d", KeyActorPrefix, actorID)
+}
+
+func targetKey(targetType string, targetID int64) string {
+	return fmt.Sprintf("%s:%s:%d", KeyTargetPrefix, targetType, targetID)
+}
+
+// Store 审计记录存储，各服务共享同一个Redis
+type Store struct {
+	redis     *redis.RedisClient
+	retention time.Duration
+}
+
+// NewStore 创建审计记录存储，retentionDays<=0时使用默认保留天数
+func NewStore(redis *redis.RedisClient, retentionDays int) *Store {
+	if retentionDays <= 0 {
+		retentionDays = DefaultRetentionDays
+	}
+	return &Store{
+		redis:     redis,
+		retention: time.Duration(retentionDays) * 24 * time.Hour,
+	}
+}
+
+// Write 写入一条审计记录，同时清理各索引中超过保留期的记录
+func (s *Store) Write(ctx context.Context, entry *Entry) error {
+	if s.redis == nil {
+		return fmt.Errorf("审计存储未配置")
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("序列化审计记录失败: %v", err)
+	}
+
+	expired := strconv.FormatInt(entry.CreatedAt-s.retention.Milliseconds(), 10)
+	keys := []string{KeyAll, actorKey(entry.ActorID)}
+	if entry.TargetType != "" {
+		keys = append(keys, targetKey(entry.TargetType, entry.TargetID))
+	}
+
+	pipe := s.redis.GetClient().TxPipeline()
+	for _, key := range keys {
+		pipe.ZAdd(ctx, key, &goredis.Z{Score: float64(entry.CreatedAt), Member: data})
+		pipe.ZRemRangeByScore(ctx, key, "-inf", "("+expired)
+		if key != KeyAll {
+			pipe.Expire(ctx, key, s.retention)
+		}
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("写入审计记录失败: %v", err)
+	}
+	return nil
+}
+
+// Query 按条件查询审计记录，按时间倒序
+// 优先使用操作对象索引，其次操作者索引，其余条件在读取后过滤
+func (s *Store) Query(ctx context.Context, filter Filter) ([]*Entry, error) {
+	if s.redis == nil {
+		return nil, fmt.Errorf("审计存储未配置")
+	}
+	if filter.Limit <= 0 {
+		filter.Limit = DefaultQueryLimit
+	}
+	if filter.Limit > MaxQueryLimit {
+		filter.Limit = MaxQueryLimit
+	}
+
+	key := KeyAll
+	switch {
+	case filter.TargetType != "" && filter.TargetID > 0:
+		key = targetKey(filter.TargetType, filter.TargetID)
+	case filter.ActorID > 0:
+		key = actorKey(filter.ActorID)
+	}
+
+	max := "+inf"
+	if filter.EndTime > 0 {
+		max = strconv.FormatInt(filter.EndTime, 10)
+	}
+	min := "-inf"
+	if filter.StartTime > 0 {
+		min = strconv.FormatInt(filter.StartTime, 10)
+	}
+
+	entries := make([]*Entry, 0, filter.Limit)
+	batch := int64(filter.Limit)
+	for round, offset := 0, int64(0); round < queryScanRounds && len(entries) < filter.Limit; round++ {
+		members, err := s.redis.GetClient().ZRevRangeByScore(ctx, key, &goredis.ZRangeBy{
+			Min:    min,
+			Max:    max,
+			Offset: offset,
+			Count:  batch,
+		}).Result()
+		if err != nil {
+			return nil, fmt.Errorf("查询审计记录失败: %v", err)
+		}
+
+		for _, member := range members {
+			var entry Entry
+			if err := json.Unmarshal([]byte(member), &entry); err != nil {
+				continue
+			}
+			if filter.match(&entry) {
+				entries = append(entries, &entry)
+				if len(entries) == filter.Limit {
+					break
+				}
+			}
+		}
+		if int64(len(members)) < batch {
+			break
+		}
+		offset += batch
+	}
+	return entries, nil
+}
+
+// match 检查索引之外的条件
+func (f Filter) match(entry *Entry) bool {
+	if f.ActorID > 0 && entry.ActorID != f.ActorID {
+		return false
+	}
+	if f.TargetType != "" && entry.TargetType != f.TargetType {
+		return false
+	}
+	if f.TargetID > 0 && entry.TargetID != f.TargetID {
+		return false
+	}
+	if f.Action != "" && entry.Action != f.Action {
+		return false
+	}
+	return true
+}
+
+// Recorder 异步审计记录器，记录先进入内存队列再由后台协程写入存储，
+// 写入失败或队列已满时只记录日志，不影响业务操作
+type Recorder struct {
+	store   *Store
+	service string
+	queue   chan *Entry
+	dropped int64
+	wg      sync.WaitGroup
+	mu      sync.RWMutex
+	closed  bool
+}
+
+// NewRecorder 创建审计记录器并启动后台写入协程
+func NewRecorder(store *Store, service string) *Recorder {
+	r := &Recorder{
+		store:   store,
+		service: service,
+		queue:   make(chan *Entry, queueSize),
+	}
+	r.wg.Add(1)
+	go r.run()
+	return r
+}
+
+// Record 提交一条审计记录，不阻塞调用方，nil记录器忽略
+func (r *Recorder) Record(entry Entry) {
+	if r == nil {
+		return
+	}
+	if entry.Service == "" {
+		entry.Service = r.service
+	}
+	if entry.CreatedAt == 0 {
+		entry.CreatedAt = time.Now().UnixMilli()
+	}
+	if entry.ID == "" {
+		entry.ID = fmt.Sprintf("%d-%04x", time.Now().UnixNano(), rand.Intn(0x10000))
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if r.closed {
+		return
+	}
+
+	select {
+	case r.queue <- &entry:
+	default:
+		atomic.AddInt64(&r.dropped, 1)
+		log.Printf("审计队列已满，丢弃记录: service=%s, action=%s, actor=%d, target=%s:%d",
+			entry.Service, entry.Action, entry.ActorID, entry.TargetType, entry.TargetID)
+	}
+}
+
+// Dropped 返回因队列已满而丢弃的记录数
+func (r *Recorder) Dropped() int64 {
+	if r == nil {
+		return 0
+	}
+	return atomic.LoadInt64(&r.dropped)
+}
+
+// Close 停止接收新记录并等待队列中的记录写完，ctx到期时放弃剩余记录
+func (r *Recorder) Close(ctx context.Context) error {
+	if r == nil {
+		return nil
+	}
+	r.mu.Lock()
+	if !r.closed {
+		r.closed = true
+		close(r.queue)
+	}
+	r.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		r.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("等待审计记录写入超时: %v", ctx.Err())
+	}
+}
+
+func (r *Recorder) run() {
+	defer r.wg.Done()
+	for entry := range r.queue {
+		ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+		if err := r.store.Write(ctx, entry); err != nil {
+			log.Printf("审计记录写入失败: action=%s, actor=%d, target=%s:%d, error=%v",
+				entry.Action, entry.ActorID, entry.TargetType, entry.TargetID, err)
+		}
+		cancel()
+	}
+}
@@ -0,0 +1,50 @@
+package audit
+
+import (
+	"context"
+	"testing"
+)
+
+// TestFilterMatch 索引之外的条件在读取后逐条过滤
+func TestFilterMatch(t *testing.T) {
+	entry := &Entry{ActorID: 7, Action: ActionCommentModerate, TargetType: "comment", TargetID: 42}
+
+	cases := []struct {
+		filter Filter
+		want   bool
+	}{
+		{Filter{}, true},
+		{Filter{ActorID: 7}, true},
+		{Filter{ActorID: 8}, false},
+		{Filter{TargetType: "comment", TargetID: 42}, true},
+		{Filter{TargetType: "content", TargetID: 42}, false},
+		{Filter{ActorID: 7, Action: ActionReportResolve}, false},
+		{Filter{ActorID: 7, Action: ActionCommentModerate}, true},
+	}
+	for _, c := range cases {
+		if got := c.filter.match(entry); got != c.want {
+			t.Fatalf("条件 %+v 匹配结果为 %v，期望 %v", c.filter, got, c.want)
+		}
+	}
+}
+
+// TestNilRecorder 未配置审计时记录和关闭均为空操作
+func TestNilRecorder(t *testing.T) {
+	var recorder *Recorder
+	recorder.Record(Entry{ActorID: 1, Action: ActionBroadcast})
+	if recorder.Dropped() != 0 {
+		t.Fatalf("nil记录器不应统计丢弃数")
+	}
+	if err := recorder.Close(context.Background()); err != nil {
+		t.Fatalf("关闭nil记录器不应报错: %v", err)
+	}
+}
+
+// TestRecordAfterClose 关闭后提交的记录被忽略，不会向已关闭的队列写入
+func TestRecordAfterClose(t *testing.T) {
+	recorder := NewRecorder(NewStore(nil, 0), "test")
+	if err := recorder.Close(context.Background()); err != nil {
+		t.Fatalf("关闭记录器失败: %v", err)
+	}
+	recorder.Record(Entry{ActorID: 1, Action: ActionBroadcast})
+}
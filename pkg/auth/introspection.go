@@ -0,0 +1,82 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"goim-social/pkg/config"
+)
+
+// IntrospectionProvider 通过OAuth令牌内省端点（RFC 7662）校验token
+type IntrospectionProvider struct {
+	cfg    config.IntrospectionConfig
+	client *http.Client
+}
+
+// introspectionResponse 内省端点响应，sub为用户ID
+type introspectionResponse struct {
+	Active   bool   `json:"active"`
+	Sub      string `json:"sub"`
+	Username string `json:"username"`
+}
+
+// NewIntrospectionProvider 创建令牌内省认证提供者
+func NewIntrospectionProvider(cfg config.IntrospectionConfig) (*IntrospectionProvider, error) {
+	if cfg.URL == "" {
+		return nil, fmt.Errorf("未配置令牌内省端点")
+	}
+
+	timeout := time.Duration(cfg.Timeout) * time.Millisecond
+	if timeout <= 0 {
+		timeout = 3 * time.Second
+	}
+	return &IntrospectionProvider{
+		cfg:    cfg,
+		client: &http.Client{Timeout: timeout},
+	}, nil
+}
+
+// Authenticate 调用内省端点校验token，端点返回active为false时视为无效
+func (p *IntrospectionProvider) Authenticate(ctx context.Context, token string) (*Identity, error) {
+	if token == "" {
+		return nil, ErrInvalidToken
+	}
+
+	form := url.Values{"token": {token}, "token_type_hint": {"access_token"}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.cfg.URL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("创建内省请求失败: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if p.cfg.ClientID != "" {
+		req.SetBasicAuth(p.cfg.ClientID, p.cfg.ClientSecret)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("调用内省端点失败: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("内省端点返回异常状态码: %d", resp.StatusCode)
+	}
+
+	var result introspectionResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("解析内省响应失败: %v", err)
+	}
+	if !result.Active {
+		return nil, ErrInvalidToken
+	}
+
+	// sub不是数字时保留用户名，用户ID为0
+	userID, _ := strconv.ParseInt(result.Sub, 10, 64)
+	return &Identity{UserID: userID, Username: result.Username}, nil
+}
@@ -26,33 +26,6 @@ var DefaultJWTConfig = &JWTConfig{
 	ExpireTime: time.Hour,
 }
 
-// ValidateToken 校验 JWT token
-func ValidateToken(token string) bool {
-	return ValidateTokenWithConfig(token, DefaultJWTConfig)
-}
-
-// ValidateTokenWithConfig 使用指定配置校验 JWT token
-func ValidateTokenWithConfig(token string, config *JWTConfig) bool {
-	if token == "" {
-		return false
-	}
-
-	// 支持调试模式
-	if token == "auth-debug" {
-		return true
-	}
-
-	parsedToken, err := jwt.Parse(token, func(token *jwt.Token) (interface{}, error) {
-		// 校验签名算法
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-		}
-		return []byte(config.Secret), nil
-	})
-
-	return err == nil && parsedToken != nil && parsedToken.Valid
-}
-
 // GenerateJWT 生成 JWT token
 func GenerateJWT(claims map[string]interface{}) (string, error) {
 	return GenerateJWTWithConfig(claims, DefaultJWTConfig)
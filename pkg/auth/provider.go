@@ -0,0 +1,116 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"goim-social/pkg/config"
+)
+
+// DebugToken 调试token，仅在显式开启调试旁路时被接受
+const DebugToken = "auth-debug"
+
+// ErrInvalidToken token无效
+var ErrInvalidToken = errors.New("invalid token")
+
+// Identity 认证通过后的用户身份
+type Identity struct {
+	UserID   int64
+	Username string
+	Debug    bool // 通过调试token认证，不对应真实用户
+}
+
+// Provider 认证提供者，服务只依赖该接口，不直接依赖具体的token格式
+type Provider interface {
+	// Authenticate 校验token并返回用户身份，token无效时返回错误
+	Authenticate(ctx context.Context, token string) (*Identity, error)
+}
+
+// ProviderFactory 根据配置创建认证提供者
+type ProviderFactory func(cfg config.AuthConfig, jwtSecret string) (Provider, error)
+
+var (
+	factoriesMu sync.RWMutex
+	factories   = map[string]ProviderFactory{
+		"jwt": func(cfg config.AuthConfig, jwtSecret string) (Provider, error) {
+			return NewJWTProvider(jwtSecret), nil
+		},
+		"introspection": func(cfg config.AuthConfig, jwtSecret string) (Provider, error) {
+			return NewIntrospectionProvider(cfg.Introspection)
+		},
+	}
+)
+
+// RegisterProvider 注册认证提供者，部署方可在启动前注册自定义实现并通过配置选用，同名注册会覆盖
+func RegisterProvider(name string, factory ProviderFactory) {
+	factoriesMu.Lock()
+	defer factoriesMu.Unlock()
+	factories[name] = factory
+}
+
+// NewFromConfig 根据配置创建认证提供者，未指定时使用JWT，开启调试旁路时额外接受调试token
+func NewFromConfig(cfg config.AuthConfig, jwtSecret string) (Provider, error) {
+	name := cfg.Provider
+	if name == "" {
+		name = "jwt"
+	}
+
+	factoriesMu.RLock()
+	factory, ok := factories[name]
+	factoriesMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("未知的认证提供者: %s", name)
+	}
+
+	provider, err := factory(cfg, jwtSecret)
+	if err != nil {
+		return nil, fmt.Errorf("创建认证提供者%s失败: %v", name, err)
+	}
+	if cfg.DebugBypass {
+		provider = WithDebugBypass(provider)
+	}
+	return provider, nil
+}
+
+// JWTProvider 默认认证提供者，校验HMAC签名的JWT
+type JWTProvider struct {
+	secret string
+}
+
+// NewJWTProvider 创建JWT认证提供者
+func NewJWTProvider(secret string) *JWTProvider {
+	return &JWTProvider{secret: secret}
+}
+
+// Authenticate 校验JWT签名和有效期
+func (p *JWTProvider) Authenticate(ctx context.Context, token string) (*Identity, error) {
+	if token == "" {
+		return nil, ErrInvalidToken
+	}
+
+	claims, err := ValidateJWT(token, p.secret)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+	return &Identity{UserID: claims.UserID, Username: claims.Username}, nil
+}
+
+// debugBypassProvider 在底层提供者之前接受调试token
+type debugBypassProvider struct {
+	next Provider
+}
+
+// WithDebugBypass 包装认证提供者使其接受调试token，仅用于本地调试
+func WithDebugBypass(next Provider) Provider {
+	return &debugBypassProvider{next: next}
+}
+
+// Authenticate 调试token直接通过，其他token交给底层提供者
+func (p *debugBypassProvider) Authenticate(ctx context.Context, token string) (*Identity, error) {
+	if token == DebugToken {
+		return &Identity{Username: DebugToken, Debug: true}, nil
+	}
+	return p.next.Authenticate(ctx, token)
+}
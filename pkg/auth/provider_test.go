@@ -0,0 +1,82 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"goim-social/pkg/config"
+)
+
+// TestJWTProviderAuthenticate 测试JWT提供者校验签名并返回用户身份
+func TestJWTProviderAuthenticate(t *testing.T) {
+	token, err := GenerateJWTWithConfig(map[string]interface{}{
+		"user_id":  int64(1001),
+		"username": "alice",
+	}, &JWTConfig{Secret: "secret", ExpireTime: time.Hour})
+	if err != nil {
+		t.Fatalf("生成token失败: %v", err)
+	}
+
+	identity, err := NewJWTProvider("secret").Authenticate(context.Background(), token)
+	if err != nil {
+		t.Fatalf("校验token失败: %v", err)
+	}
+	if identity.UserID != 1001 || identity.Username != "alice" || identity.Debug {
+		t.Fatalf("用户身份异常: %+v", identity)
+	}
+
+	if _, err := NewJWTProvider("other").Authenticate(context.Background(), token); !errors.Is(err, ErrInvalidToken) {
+		t.Fatalf("密钥不一致时应校验失败, got %v", err)
+	}
+}
+
+// TestDebugBypass 测试调试token只在显式开启时被接受
+func TestDebugBypass(t *testing.T) {
+	provider, err := NewFromConfig(config.AuthConfig{}, "secret")
+	if err != nil {
+		t.Fatalf("创建认证提供者失败: %v", err)
+	}
+	if _, err := provider.Authenticate(context.Background(), DebugToken); err == nil {
+		t.Fatalf("未开启调试旁路时不应接受调试token")
+	}
+
+	provider, err = NewFromConfig(config.AuthConfig{DebugBypass: true}, "secret")
+	if err != nil {
+		t.Fatalf("创建认证提供者失败: %v", err)
+	}
+	identity, err := provider.Authenticate(context.Background(), DebugToken)
+	if err != nil || !identity.Debug {
+		t.Fatalf("开启调试旁路时应接受调试token: %v", err)
+	}
+	if _, err := provider.Authenticate(context.Background(), "bad-token"); err == nil {
+		t.Fatalf("开启调试旁路时其他token仍应正常校验")
+	}
+}
+
+// TestRegisterProvider 测试注册自定义认证提供者
+func TestRegisterProvider(t *testing.T) {
+	RegisterProvider("static", func(cfg config.AuthConfig, jwtSecret string) (Provider, error) {
+		return staticProvider{}, nil
+	})
+
+	provider, err := NewFromConfig(config.AuthConfig{Provider: "static"}, "")
+	if err != nil {
+		t.Fatalf("创建自定义认证提供者失败: %v", err)
+	}
+	if identity, err := provider.Authenticate(context.Background(), "any"); err != nil || identity.UserID != 42 {
+		t.Fatalf("自定义认证提供者结果异常: %+v, %v", identity, err)
+	}
+
+	if _, err := NewFromConfig(config.AuthConfig{Provider: "unknown"}, ""); err == nil {
+		t.Fatalf("未知的认证提供者应返回错误")
+	}
+}
+
+// staticProvider 固定返回同一用户的测试提供者
+type staticProvider struct{}
+
+func (staticProvider) Authenticate(ctx context.Context, token string) (*Identity, error) {
+	return &Identity{UserID: 42}, nil
+}
@@ -0,0 +1,171 @@
+package blocklist
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"goim-social/pkg/redis"
+)
+
+// scanCount 扫描缓存键时每批的提示数量
+const scanCount = 500
+
+// Redis键，关系本身由social-service持久化，此处为各服务共享的查询缓存，可随时从数据库重建
+const (
+	KeyBlocksPrefix    = "blocklist:blocks"     // 用户拉黑的用户集合 blocklist:blocks:{userID}
+	KeyBlockedByPrefix = "blocklist:blocked_by" // 拉黑该用户的用户集合 blocklist:blocked_by:{userID}
+)
+
+// Set 对某个用户不可见的用户集合，包括其拉黑的用户和拉黑了它的用户
+type Set map[int64]bool
+
+// Has 判断用户是否在集合中，nil集合表示没有屏蔽关系
+func (s Set) Has(userID int64) bool {
+	return s[userID]
+}
+
+// IDs 返回集合中的用户ID，用于在查询条件中排除
+func (s Set) IDs() []int64 {
+	ids := make([]int64, 0, len(s))
+	for id := range s {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// Store 拉黑关系缓存，social-service写入，内容等服务按查看者读取屏蔽集合
+type Store struct {
+	redis *redis.RedisClient
+}
+
+// NewStore 创建拉黑关系缓存
+func NewStore(redis *redis.RedisClient) *Store {
+	return &Store{redis: redis}
+}
+
+// Block 记录userID拉黑targetID，双向维护以便双方都能一次查出屏蔽集合
+func (s *Store) Block(ctx context.Context, userID, targetID int64) error {
+	pipe := s.redis.GetClient().TxPipeline()
+	pipe.SAdd(ctx, blocksKey(userID), targetID)
+	pipe.SAdd(ctx, blockedByKey(targetID), userID)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("写入拉黑关系缓存失败: %v", err)
+	}
+	return nil
+}
+
+// Unblock 移除userID对targetID的拉黑，关系不存在时不报错
+func (s *Store) Unblock(ctx context.Context, userID, targetID int64) error {
+	pipe := s.redis.GetClient().TxPipeline()
+	pipe.SRem(ctx, blocksKey(userID), targetID)
+	pipe.SRem(ctx, blockedByKey(targetID), userID)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("移除拉黑关系缓存失败: %v", err)
+	}
+	return nil
+}
+
+// Replace 用数据库中的全量关系覆盖userID的缓存，blocks为其拉黑的用户，blockedBy为拉黑了它的用户
+// 两个集合在同一事务中替换，查询方不会读到替换一半的集合
+func (s *Store) Replace(ctx context.Context, userID int64, blocks, blockedBy []int64) error {
+	pipe := s.redis.GetClient().TxPipeline()
+	pipe.Del(ctx, blocksKey(userID), blockedByKey(userID))
+	if len(blocks) > 0 {
+		pipe.SAdd(ctx, blocksKey(userID), members(blocks)...)
+	}
+	if len(blockedBy) > 0 {
+		pipe.SAdd(ctx, blockedByKey(userID), members(blockedBy)...)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("重建拉黑关系缓存失败: %v", err)
+	}
+	return nil
+}
+
+// CachedUsers 返回缓存中存在拉黑关系的用户，重建时据此移除数据库中已不存在的关系
+func (s *Store) CachedUsers(ctx context.Context) (map[int64]bool, error) {
+	users := make(map[int64]bool)
+	for _, prefix := range []string{KeyBlocksPrefix, KeyBlockedByPrefix} {
+		var cursor uint64
+		for {
+			keys, next, err := s.redis.Scan(ctx, cursor, prefix+":*", scanCount)
+			if err != nil {
+				return nil, fmt.Errorf("扫描拉黑关系缓存失败: %v", err)
+			}
+			for _, key := range keys {
+				if id, err := strconv.ParseInt(strings.TrimPrefix(key, prefix+":"), 10, 64); err == nil {
+					users[id] = true
+				}
+			}
+			if next == 0 {
+				break
+			}
+			cursor = next
+		}
+	}
+	return users, nil
+}
+
+// Hidden 返回对viewerID不可见的用户集合，未登录或没有屏蔽关系时返回空集合
+func (s *Store) Hidden(ctx context.Context, viewerID int64) (Set, error) {
+	if s == nil || viewerID <= 0 {
+		return Set{}, nil
+	}
+
+	members, err := s.redis.GetClient().SUnion(ctx, blocksKey(viewerID), blockedByKey(viewerID)).Result()
+	if err != nil {
+		return nil, fmt.Errorf("查询拉黑关系失败: %v", err)
+	}
+
+	hidden := make(Set, len(members))
+	for _, member := range members {
+		if id, err := strconv.ParseInt(member, 10, 64); err == nil {
+			hidden[id] = true
+		}
+	}
+	return hidden, nil
+}
+
+// IsHidden 判断authorID对viewerID是否不可见
+func (s *Store) IsHidden(ctx context.Context, viewerID, authorID int64) (bool, error) {
+	if authorID == viewerID {
+		return false, nil
+	}
+	hidden, err := s.Hidden(ctx, viewerID)
+	if err != nil {
+		return false, err
+	}
+	return hidden.Has(authorID), nil
+}
+
+// Filter 过滤掉作者在屏蔽集合中的条目
+func Filter[T any](items []T, hidden Set, authorOf func(T) int64) []T {
+	if len(hidden) == 0 {
+		return items
+	}
+	kept := items[:0:0]
+	for _, item := range items {
+		if !hidden.Has(authorOf(item)) {
+			kept = append(kept, item)
+		}
+	}
+	return kept
+}
+
+func members(userIDs []int64) []interface{} {
+	values := make([]interface{}, len(userIDs))
+	for i, id := range userIDs {
+		values[i] = id
+	}
+	return values
+}
+
+func blocksKey(userID int64) string {
+	return fmt.Sprintf("%s:%d", KeyBlocksPrefix, userID)
+}
+
+func blockedByKey(userID int64) string {
+	return fmt.Sprintf("%s:%d", KeyBlockedByPrefix, userID)
+}
@@ -0,0 +1,41 @@
+package blocklist
+
+import (
+	"context"
+	"testing"
+)
+
+// TestFilter 过滤屏蔽用户的条目，保持原有顺序
+func TestFilter(t *testing.T) {
+	type item struct{ id, author int64 }
+	items := []item{{1, 10}, {2, 20}, {3, 10}, {4, 30}}
+
+	kept := Filter(items, Set{10: true}, func(i item) int64 { return i.author })
+	if len(kept) != 2 || kept[0].id != 2 || kept[1].id != 4 {
+		t.Fatalf("过滤结果错误: %+v", kept)
+	}
+	if len(items) != 4 || items[0].id != 1 {
+		t.Fatalf("过滤不应修改原切片: %+v", items)
+	}
+
+	if got := Filter(items, nil, func(i item) int64 { return i.author }); len(got) != len(items) {
+		t.Fatalf("没有屏蔽关系时应原样返回: %+v", got)
+	}
+}
+
+// TestHiddenWithoutViewer 未登录查看者和未配置缓存时没有屏蔽关系，也不报错
+func TestHiddenWithoutViewer(t *testing.T) {
+	var store *Store
+	hidden, err := store.Hidden(context.Background(), 1001)
+	if err != nil || len(hidden) != 0 {
+		t.Fatalf("未配置缓存时应返回空集合: %v %v", hidden, err)
+	}
+
+	hidden, err = NewStore(nil).Hidden(context.Background(), 0)
+	if err != nil || len(hidden) != 0 {
+		t.Fatalf("未登录查看者应返回空集合: %v %v", hidden, err)
+	}
+	if hidden.Has(1001) {
+		t.Fatalf("空集合不应包含任何用户")
+	}
+}
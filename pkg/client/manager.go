@@ -10,6 +10,7 @@ import (
 	"google.golang.org/grpc/credentials/insecure"
 
 	"goim-social/pkg/config"
+	"goim-social/pkg/middleware"
 )
 
 // ClientManager 客户端管理器
@@ -81,6 +82,7 @@ func (cm *ClientManager) GetGRPCClientWithRetry(serviceName string, addr string,
 func (cm *ClientManager) createGRPCConnection(addr string) (*grpc.ClientConn, error) {
 	conn, err := grpc.NewClient(addr,
 		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		middleware.DeadlineDialOption(cm.config.Deadline),
 	)
 	if err != nil {
 		return nil, err
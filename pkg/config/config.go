@@ -4,18 +4,33 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
 )
 
 // Config 应用配置
 type Config struct {
-	App      AppConfig      `yaml:"app"`
-	Server   ServerConfig   `yaml:"server"`
-	Database DatabaseConfig `yaml:"database"`
-	Redis    RedisConfig    `yaml:"redis"`
-	Kafka    KafkaConfig    `yaml:"kafka"`
-	Connect  ConnectConfig  `yaml:"connect"`
-	Logic    LogicConfig    `yaml:"logic"`
-	Services ServicesConfig `yaml:"services"`
+	App        AppConfig        `yaml:"app"`
+	Auth       AuthConfig       `yaml:"auth"`
+	Server     ServerConfig     `yaml:"server"`
+	Database   DatabaseConfig   `yaml:"database"`
+	Redis      RedisConfig      `yaml:"redis"`
+	Kafka      KafkaConfig      `yaml:"kafka"`
+	Connect    ConnectConfig    `yaml:"connect"`
+	Logic      LogicConfig      `yaml:"logic"`
+	Services   ServicesConfig   `yaml:"services"`
+	Message    MessageConfig    `yaml:"message"`
+	Content    ContentConfig    `yaml:"content"`
+	Search     SearchConfig     `yaml:"search"`
+	Social     SocialConfig     `yaml:"social"`
+	Audit      AuditConfig      `yaml:"audit"`
+	User       UserConfig       `yaml:"user"`
+	Startup    StartupConfig    `yaml:"startup"`
+	Deadline   DeadlineConfig   `yaml:"deadline"`
+	Pagination PaginationConfig `yaml:"pagination"`
+	EditWindow EditWindowConfig `yaml:"edit_window"`
+	Presence   PresenceConfig   `yaml:"presence"`
+	Profanity  ProfanityConfig  `yaml:"profanity"`
+	Outbox     OutboxConfig     `yaml:"outbox"`
 }
 
 // AppConfig 应用配置
@@ -25,10 +40,85 @@ type AppConfig struct {
 	JWTSecret string `yaml:"jwt_secret"`
 }
 
+// AuthConfig 认证配置
+type AuthConfig struct {
+	Provider      string              `yaml:"provider"`      // 认证提供者：jwt（默认）、introspection
+	DebugBypass   bool                `yaml:"debug_bypass"`  // 是否接受调试token（auth-debug），生产环境必须关闭
+	Introspection IntrospectionConfig `yaml:"introspection"` // OAuth令牌内省配置，provider为introspection时使用
+}
+
+// IntrospectionConfig OAuth令牌内省（RFC 7662）配置
+type IntrospectionConfig struct {
+	URL          string `yaml:"url"`           // 内省端点
+	ClientID     string `yaml:"client_id"`     // 调用内省端点的客户端ID
+	ClientSecret string `yaml:"client_secret"` // 调用内省端点的客户端密钥
+	Timeout      int    `yaml:"timeout"`       // 请求超时（毫秒）
+}
+
+// StartupConfig 启动时依赖初始化配置
+type StartupConfig struct {
+	InitAttempts   int  `yaml:"init_attempts"`    // 依赖初始化最大尝试次数，配置错误不重试
+	InitBackoff    int  `yaml:"init_backoff"`     // 首次重试间隔（毫秒），之后每次翻倍
+	InitMaxBackoff int  `yaml:"init_max_backoff"` // 重试间隔上限（毫秒）
+	KafkaOptional  bool `yaml:"kafka_optional"`   // Kafka不可用时是否降级启动（不发布事件），否则启动失败
+}
+
+// DeadlineConfig 请求截止时间配置，截止时间随context向下游传递，上游请求超时后整条调用链一起取消
+type DeadlineConfig struct {
+	HTTPRequest int            `yaml:"http_request"` // HTTP请求处理超时（毫秒），0表示不限制
+	HTTPRoutes  map[string]int `yaml:"http_routes"`  // 按路由覆盖HTTP请求超时（毫秒），键为路由路径，如重建索引等已知的慢操作
+	GRPCCall    int            `yaml:"grpc_call"`    // 服务间gRPC调用默认超时（毫秒），不会晚于上游请求的截止时间，0表示不限制
+	GRPCMethods map[string]int `yaml:"grpc_methods"` // 按方法覆盖gRPC调用超时（毫秒），键为完整方法名，如/rest.MessageService/SendMessage
+}
+
+// PaginationConfig 分页配置，列表和搜索接口未传每页数量时使用默认值，超过上限时截断为上限
+type PaginationConfig struct {
+	DefaultPageSize int `yaml:"default_page_size"` // 默认每页数量
+	MaxPageSize     int `yaml:"max_page_size"`     // 每页数量上限
+	MaxBatchSize    int `yaml:"max_batch_size"`    // 批量查询一次最多的ID或用户名数量
+}
+
+// PresenceConfig 在线状态缓存配置，im-gateway在连接建立、心跳和断开时写入，各服务直接批量读取在线状态和最近在线时间
+type PresenceConfig struct {
+	Enabled bool `yaml:"enabled"` // 关闭时读取方回退到在线用户集合，不提供最近在线时间
+	TTL     int  `yaml:"ttl"`     // 在线标记有效期（秒），网关按有效期的1/3刷新本实例的连接，实例异常退出后在有效期内失效
+}
+
+// ProfanityConfig 违禁词过滤配置，内容和评论等接受文本的服务共用同一份词表
+type ProfanityConfig struct {
+	Enabled         bool   `yaml:"enabled"`          // 是否启用违禁词过滤
+	DryRun          bool   `yaml:"dry_run"`          // 试运行：只记录本应命中的词，不拦截，用于调整词表
+	WordLists       string `yaml:"word_lists"`       // 词表文件，格式为 语言:路径，逗号分隔，如 en:/etc/goim/profanity/en.txt
+	DefaultSeverity string `yaml:"default_severity"` // 词表中未注明处理级别的词使用的级别：warn、hold、reject
+	ReloadInterval  int    `yaml:"reload_interval"`  // 检查词表文件变更的间隔（秒），文件变更后自动重新加载，0表示不重新加载
+}
+
+// OutboxConfig 事务发件箱配置，内容和社交服务的事件与业务数据在同一事务中写入发件箱，由转发器发布到Kafka
+type OutboxConfig struct {
+	PollInterval int `yaml:"poll_interval"` // 转发器扫描发件箱的间隔（毫秒），业务事务提交后也会立即唤醒
+	BatchSize    int `yaml:"batch_size"`    // 每次转发的最大事件数
+	MaxAttempts  int `yaml:"max_attempts"`  // 单个事件发布失败的最大次数，超过后标记为失败并跳过；0表示一直重试，一个持续失败的事件会阻塞后续所有事件
+	MaxBackoff   int `yaml:"max_backoff"`   // 发布失败后重试间隔的上限（毫秒），间隔从扫描间隔开始翻倍
+	Retention    int `yaml:"retention"`     // 已发布事件在发件箱中的保留时间（小时）
+	DedupTTL     int `yaml:"dedup_ttl"`     // 消费者记录已处理事件ID的时间（秒），应覆盖事件可能被重复投递的时间窗口
+}
+
+// EditWindowConfig 编辑和撤回时限配置，以服务端时间计算，发布或发送后超过时限不能再编辑或撤回；
+// 各服务共用同一份配置，客户端通过接口读取后展示倒计时
+type EditWindowConfig struct {
+	Content      int            `yaml:"content"`       // 内容发布后可编辑的时间（秒），0使用默认值，负数表示不限制
+	Comment      int            `yaml:"comment"`       // 评论发表后可编辑的时间（秒），0使用默认值，负数表示不限制
+	Message      int            `yaml:"message"`       // 消息发送后可撤回或编辑的时间（秒），0使用默认值，负数表示不限制
+	ContentTypes map[string]int `yaml:"content_types"` // 按内容类型覆盖内容的编辑时限（秒），键为内容类型，如video
+}
+
 // ServerConfig 服务器配置
 type ServerConfig struct {
-	HTTP HTTPConfig `yaml:"http"`
-	GRPC GRPCConfig `yaml:"grpc"`
+	HTTP                HTTPConfig `yaml:"http"`
+	GRPC                GRPCConfig `yaml:"grpc"`
+	ShutdownTimeout     string     `yaml:"shutdown_timeout"`       // 优雅退出最长等待时间，超时后强制退出
+	ServiceInfo         bool       `yaml:"service_info"`           // 是否提供服务信息调试接口，默认关闭，只允许管理员访问，不返回凭据
+	ServiceInfoAdminIDs string     `yaml:"service_info_admin_ids"` // 允许访问服务信息接口的管理员用户ID，逗号分隔，为空时任何用户都不能访问
 }
 
 // HTTPConfig HTTP服务配置
@@ -40,9 +130,10 @@ type HTTPConfig struct {
 
 // GRPCConfig gRPC服务配置
 type GRPCConfig struct {
-	Network string `yaml:"network"`
-	Addr    string `yaml:"addr"`
-	Timeout string `yaml:"timeout"`
+	Network    string `yaml:"network"`
+	Addr       string `yaml:"addr"`
+	Timeout    string `yaml:"timeout"`
+	Reflection bool   `yaml:"reflection"` // 是否开启gRPC服务反射，供grpcurl等工具调试，生产环境应关闭
 }
 
 // DatabaseConfig 数据库配置
@@ -83,15 +174,77 @@ type ConnectConfig struct {
 	Instance       InstanceConfig       `yaml:"instance"`
 	Heartbeat      HeartbeatConfig      `yaml:"heartbeat"`
 	Connection     ConnectionConfig     `yaml:"connection"`
+	FriendOnline   FriendOnlineConfig   `yaml:"friend_online"`
+	PresenceHook   PresenceHookConfig   `yaml:"presence_webhook"`
+	WebSocket      WebSocketConfig      `yaml:"websocket"`
+	AntiReplay     AntiReplayConfig     `yaml:"anti_replay"`
+	Echo           EchoConfig           `yaml:"echo"`
+	PushLanes      PushLanesConfig      `yaml:"push_lanes"`
+	AdminIDs       string               `yaml:"admin_ids"` // 允许查询连接列表和连接容量的管理员用户ID，逗号分隔，为空时禁止查询
 }
 
 // LogicConfig Logic服务配置
 type LogicConfig struct {
-	UserService    ServiceEndpoint `yaml:"user_service"`
-	SocialService  ServiceEndpoint `yaml:"social_service"`
-	ContentService ServiceEndpoint `yaml:"content_service"`
-	MessageService ServiceEndpoint `yaml:"message_service"`
-	SearchService  ServiceEndpoint `yaml:"search_service"`
+	UserService    ServiceEndpoint      `yaml:"user_service"`
+	SocialService  ServiceEndpoint      `yaml:"social_service"`
+	ContentService ServiceEndpoint      `yaml:"content_service"`
+	MessageService ServiceEndpoint      `yaml:"message_service"`
+	SearchService  ServiceEndpoint      `yaml:"search_service"`
+	PersistRetry   RetryConfig          `yaml:"persist_retry"`   // 消息持久化重试配置
+	SendQuota      SendQuotaConfig      `yaml:"send_quota"`      // 发送配额与反垃圾配置
+	DeliveryBatch  DeliveryBatchConfig  `yaml:"delivery_batch"`  // 投递结果事件批量发送配置
+	Fanout         FanoutConfig         `yaml:"fanout"`          // 群消息扇出并发配置
+	AttachmentScan AttachmentScanConfig `yaml:"attachment_scan"` // 媒体消息附件扫描配置
+	AdminIDs       string               `yaml:"admin_ids"`       // 允许广播系统消息的管理员用户ID，逗号分隔，为空时禁止广播
+}
+
+// AttachmentScanConfig 媒体消息附件扫描配置，未启用时不扫描
+// 扫描在消息持久化和投递之前进行，超过同步等待时间仍未完成时消息转为待扫描，扫描通过后再投递
+type AttachmentScanConfig struct {
+	Enabled      bool     `yaml:"enabled"`       // 是否启用
+	MaxSize      int64    `yaml:"max_size"`      // 附件最大字节数，0为不限制，以附件地址的响应为准，无法确定大小时按扫描失败处理
+	AllowedTypes []string `yaml:"allowed_types"` // 允许的MIME类型，支持image/*通配，为空不限制，以附件地址的响应为准
+	ScanURL      string   `yaml:"scan_url"`      // 外部杀毒扫描服务地址，为空时只检查大小和类型
+	SyncWait     int      `yaml:"sync_wait"`     // 同步等待扫描结果的时长（毫秒）
+	Timeout      int      `yaml:"timeout"`       // 单次扫描最长时间（毫秒）
+	FailOpen     bool     `yaml:"fail_open"`     // 扫描服务出错或超时时是否放行，默认拒绝
+}
+
+// FanoutConfig 群消息扇出的并发推送配置
+// 全局并发数为本实例所有消息共享的推送槽位，槽位用满时扇出等待而不是排队堆积
+type FanoutConfig struct {
+	Workers    int `yaml:"workers"`     // 本实例同时进行的成员推送上限
+	PerMessage int `yaml:"per_message"` // 单条消息同时进行的成员推送上限，避免大群占满全局槽位
+}
+
+// DeliveryBatchConfig 成员级投递结果事件的批量发送配置
+// 启用后事件先在本地合并，达到批量大小或窗口到期时作为一条批量事件发布
+type DeliveryBatchConfig struct {
+	Enabled bool `yaml:"enabled"`  // 是否启用，未启用时逐条发布
+	Window  int  `yaml:"window"`   // 合并窗口（毫秒），超过上限时按上限处理
+	MaxSize int  `yaml:"max_size"` // 单批最多事件数，达到后立即发布
+}
+
+// SendQuotaConfig 用户发送配额与反垃圾评分配置
+type SendQuotaConfig struct {
+	Enabled            bool    `yaml:"enabled"`             // 是否启用
+	HourlyLimit        int     `yaml:"hourly_limit"`        // 每小时最多发送条数，0为不限制
+	DailyLimit         int     `yaml:"daily_limit"`         // 每天最多发送条数，0为不限制
+	SpamWindow         int     `yaml:"spam_window"`         // 垃圾评分统计窗口（秒）
+	RateThreshold      int     `yaml:"rate_threshold"`      // 窗口内发送条数达到该值时频率分量计满1分
+	DuplicateThreshold int     `yaml:"duplicate_threshold"` // 窗口内同一内容重复次数达到该值时重复分量计满1分
+	RecipientThreshold int     `yaml:"recipient_threshold"` // 窗口内不同接收方数达到该值时接收方分量计满1分
+	ThrottleScore      float64 `yaml:"throttle_score"`      // 评分达到该值时拒绝本条消息
+	BlockScore         float64 `yaml:"block_score"`         // 评分达到该值时临时禁止发送并发出标记事件
+	BlockDuration      int     `yaml:"block_duration"`      // 临时禁止发送时长（秒）
+	ExemptUsers        string  `yaml:"exempt_users"`        // 豁免用户ID（认证/管理员账号），逗号分隔
+}
+
+// RetryConfig 重试退避配置
+type RetryConfig struct {
+	MaxAttempts    int `yaml:"max_attempts"`    // 最大尝试次数
+	InitialBackoff int `yaml:"initial_backoff"` // 初始退避时间（毫秒）
+	MaxBackoff     int `yaml:"max_backoff"`     // 最大退避时间（毫秒）
 }
 
 // ServiceEndpoint 服务端点配置
@@ -132,8 +285,287 @@ type HeartbeatConfig struct {
 
 // ConnectionConfig 连接配置
 type ConnectionConfig struct {
-	ExpireTime int    `yaml:"expire_time"` // 连接过期时间（小时）
-	ClientType string `yaml:"client_type"` // 默认客户端类型
+	ExpireTime   int    `yaml:"expire_time"`   // 连接过期时间（小时）
+	ClientType   string `yaml:"client_type"`   // 默认客户端类型
+	DrainTimeout int    `yaml:"drain_timeout"` // 退出时等待客户端断开的时间（秒），不超过优雅退出剩余时间
+
+	MaxConnections   int `yaml:"max_connections"`   // 本实例最多的WebSocket连接数，达到后新的握手返回503，0表示不限制
+	ReconnectReserve int `yaml:"reconnect_reserve"` // 为重连用户预留的容量（最大连接数的百分比），连接数进入预留区后只接受重连
+	RetryAfter       int `yaml:"retry_after"`       // 拒绝握手时建议客户端重试的等待时间（秒），通过Retry-After响应头返回
+}
+
+// FriendOnlineConfig 好友上线提醒配置
+type FriendOnlineConfig struct {
+	Enabled  bool `yaml:"enabled"`  // 是否启用好友上线提醒
+	Debounce int  `yaml:"debounce"` // 防抖时间（秒），下线后在此时间内重连不再提醒
+}
+
+// PresenceHookConfig 在线状态回调配置，机器人等外部集成订阅好友的上下线事件，由网关签名后回调
+type PresenceHookConfig struct {
+	Enabled          bool   `yaml:"enabled"`           // 是否启用在线状态回调订阅
+	Timeout          int    `yaml:"timeout"`           // 单次回调超时（秒）
+	MaxRetries       int    `yaml:"max_retries"`       // 回调失败后的最大重试次数
+	RetryBackoff     int    `yaml:"retry_backoff"`     // 首次重试等待时间（毫秒），之后每次翻倍
+	MaxSubscriptions int    `yaml:"max_subscriptions"` // 每个用户最多的订阅数
+	MaxUsers         int    `yaml:"max_users"`         // 每个订阅最多关注的用户数
+	AllowHTTP        bool   `yaml:"allow_http"`        // 是否允许非HTTPS回调地址，仅用于开发环境
+	IntegrationIDs   string `yaml:"integration_ids"`   // 允许订阅的集成账号用户ID，逗号分隔，为空时禁止订阅
+}
+
+// WebSocketConfig WebSocket握手配置
+// 浏览器会携带Origin请求头，原生客户端通常不携带，是否放行由AllowNoOrigin决定
+type WebSocketConfig struct {
+	AllowedOrigins []string `yaml:"allowed_origins"` // 允许的Origin，如https://im.example.com；为空时只允许同源，包含"*"时允许任意来源（仅用于开发环境）
+	AllowNoOrigin  bool     `yaml:"allow_no_origin"` // 是否允许不携带Origin的握手请求（原生客户端）
+}
+
+// AntiReplayConfig 上行帧防重放配置，拒绝时间戳超出窗口的帧，窗口内重复的client_msg_id按幂等处理
+type AntiReplayConfig struct {
+	Enabled            bool `yaml:"enabled"`               // 是否启用
+	Window             int  `yaml:"window"`                // 时间窗口（秒），帧时间戳与服务端时间相差超过窗口时拒绝
+	RequireClientMsgID bool `yaml:"require_client_msg_id"` // 聊天帧必须携带client_msg_id和时间戳，关闭时兼容未携带的旧版客户端
+}
+
+// EchoConfig 发送方消息回显配置，受理后的消息副本回显到发送方的设备用于多设备同步
+type EchoConfig struct {
+	Mode string `yaml:"mode"` // other_devices（默认）只回显到发送方的其他设备；all_devices同时回显到发起发送的连接
+}
+
+// PushLanesConfig 推送优先级通道配置，每个连接的发送队列按优先级分为high、normal、bulk三个通道，高优先级先发送
+type PushLanesConfig struct {
+	QueueSize int               `yaml:"queue_size"` // 每个连接每个通道最多排队的消息数，通道满时丢弃新消息
+	Overrides map[string]string `yaml:"overrides"`  // 按消息类型覆盖默认通道，键为message_type，值为high、normal或bulk
+}
+
+// MessageConfig 消息存储配置
+type MessageConfig struct {
+	Encryption     EncryptionConfig         `yaml:"encryption"`
+	Export         MessageExportConfig      `yaml:"export"`          // 群聊记录导出配置
+	AckResend      AckResendConfig          `yaml:"ack_resend"`      // 推送确认超时重发配置
+	Archive        MessageArchiveConfig     `yaml:"archive"`         // 会话归档配置
+	Storage        MessageStorageConfig     `yaml:"storage"`         // 按消息类型的存储策略
+	Digest         NotificationDigestConfig `yaml:"digest"`          // 低优先级通知摘要配置
+	Retention      MessageRetentionConfig   `yaml:"retention"`       // 历史消息保留期配置
+	Poll           MessagePollConfig        `yaml:"poll"`            // 群投票配置
+	Pin            MessagePinConfig         `yaml:"pin"`             // 群置顶消息配置
+	DeliveryReport DeliveryReportConfig     `yaml:"delivery_report"` // 群消息投递和已读汇总配置
+}
+
+// DeliveryReportConfig 群消息投递和已读汇总配置
+// 大群中发送者不逐条接收成员的投递和已读情况，而是按间隔收到汇总（如“已送达940，已读210”），需要时再分页查询明细
+type DeliveryReportConfig struct {
+	Enabled         bool `yaml:"enabled"`          // 是否跟踪群消息的成员投递结果并向发送者推送汇总
+	SummaryInterval int  `yaml:"summary_interval"` // 汇总推送间隔（秒），同一条消息在一个间隔内最多推送一次
+	MinGroupSize    int  `yaml:"min_group_size"`   // 成员数达到该值的群才推送汇总，0表示所有群；明细查询不受限制
+	Retention       int  `yaml:"retention"`        // 投递结果保留时长（小时），超过后只能查询已读情况
+}
+
+// MessagePinConfig 群置顶消息配置
+type MessagePinConfig struct {
+	MaxPerGroup int    `yaml:"max_per_group"` // 每个群最多置顶的消息数
+	OnLimit     string `yaml:"on_limit"`      // 达到上限时的处理：reject拒绝新的置顶，evict_oldest取消排在最后的置顶
+}
+
+// MessagePollConfig 群投票配置
+type MessagePollConfig struct {
+	AllowMultiSelect bool `yaml:"allow_multi_select"` // 是否允许多选投票，关闭时所有投票每人只能选一项
+	CountLeftMembers bool `yaml:"count_left_members"` // 已退群成员的投票是否继续计入结果
+	UpdateInterval   int  `yaml:"update_interval"`    // 投票结果更新推送间隔（秒），同一投票在一个间隔内最多推送一次
+}
+
+// MessageRetentionConfig 历史消息保留期配置，超过保留期的消息由后台任务删除
+// 会话（群聊由群主和管理员）可在平台上下限内设置自己的保留期，未设置时使用默认值
+type MessageRetentionConfig struct {
+	DefaultDays   int `yaml:"default_days"`   // 默认保留天数，0表示永久保留
+	MinDays       int `yaml:"min_days"`       // 会话可设置的最短保留天数
+	MaxDays       int `yaml:"max_days"`       // 会话可设置的最长保留天数，0表示不限制
+	PurgeInterval int `yaml:"purge_interval"` // 清理间隔（秒）
+	BatchSize     int `yaml:"batch_size"`     // 每批删除的消息数
+}
+
+// NotificationDigestConfig 低优先级通知（点赞等）摘要配置，用户可选择立即推送、每小时或每天汇总推送
+type NotificationDigestConfig struct {
+	Enabled     bool   `yaml:"enabled"`      // 是否启用摘要，关闭时所有通知立即推送
+	DefaultMode string `yaml:"default_mode"` // 用户未设置时的摘要模式：immediate/hourly/daily
+	MaxItems    int    `yaml:"max_items"`    // 每个摘要保留的最近通知条数，计数不受影响
+}
+
+// MessageStorageConfig 按消息类型的存储策略，未列出的类型存储并参与会话搜索，同时列在两处的类型按只转发处理
+type MessageStorageConfig struct {
+	RelayTypes     []int `yaml:"relay_types"`     // 只转发不存储的消息类型，如在线状态、已读回执等控制消息
+	UnindexedTypes []int `yaml:"unindexed_types"` // 存储但不参与会话搜索的消息类型，如内容为地址或卡片JSON的媒体消息
+}
+
+// MessageArchiveConfig 会话归档配置
+type MessageArchiveConfig struct {
+	AutoUnarchive bool `yaml:"auto_unarchive"` // 收到新消息时是否自动取消归档
+}
+
+// AckResendConfig 推送确认超时重发配置
+type AckResendConfig struct {
+	Enabled     bool `yaml:"enabled"`      // 是否启用超时重发
+	Timeout     int  `yaml:"timeout"`      // 推送后等待客户端确认的时间（秒）
+	MaxAttempts int  `yaml:"max_attempts"` // 最多推送次数（含首次），超过后留给客户端上线拉取
+}
+
+// MessageExportConfig 群聊记录导出配置
+type MessageExportConfig struct {
+	Dir             string `yaml:"dir"`               // 导出文件目录
+	RespectJoinTime bool   `yaml:"respect_join_time"` // 是否只导出成员入群之后的消息
+	RateLimit       int    `yaml:"rate_limit"`        // 每个用户在限流窗口内最多发起的导出次数
+	RateWindow      int    `yaml:"rate_window"`       // 限流窗口（秒）
+	AsyncThreshold  int    `yaml:"async_threshold"`   // 消息数超过该值时异步生成导出文件
+	Timeout         int    `yaml:"timeout"`           // 单次导出生成的最长时间（秒），超时记为失败
+	FileTTL         int    `yaml:"file_ttl"`          // 导出文件从发起导出起的保留时间（秒），到期删除文件，0为永久保留
+}
+
+// EncryptionConfig 静态加密配置
+type EncryptionConfig struct {
+	Enabled     bool   `yaml:"enabled"`       // 是否启用加密
+	ActiveKeyID string `yaml:"active_key_id"` // 当前用于加密的密钥ID
+	Keys        string `yaml:"keys"`          // 密钥列表，格式 keyID:base64Key,keyID:base64Key
+}
+
+// ContentConfig 内容服务配置
+type ContentConfig struct {
+	FeedRanking      FeedRankingConfig `yaml:"feed_ranking"`
+	Moderation       ModerationConfig  `yaml:"moderation"`
+	AllowedReactions string            `yaml:"allowed_reactions"` // 允许的表情回应标识，逗号分隔，为空时不限制
+	Media            MediaConfig       `yaml:"media"`
+	Trending         TrendingConfig    `yaml:"trending"`
+	MaxMentions      int               `yaml:"max_mentions"`   // 单条内容或评论最多解析的@提及数，超出部分保留为普通文本
+	DetailTimeout    int               `yaml:"detail_timeout"` // 内容详情评论、互动统计等部分的整体加载时限（毫秒），超时的部分不返回
+	Excerpt          ExcerptConfig     `yaml:"excerpt"`
+	FeedViewed       FeedViewedConfig  `yaml:"feed_viewed"`
+	NewAccount       NewAccountConfig  `yaml:"new_account"` // 新账号发布冷却配置
+	AdminIDs         string            `yaml:"admin_ids"`   // 允许处理举报和管理分类的管理员用户ID，逗号分隔，为空时禁止处理
+}
+
+// NewAccountConfig 新账号发布冷却配置，注册时间不足阈值的账号按更严格的频率限制创建内容和评论
+// 频率上限为0表示该类发布需要账号认证或注册满阈值后才能进行
+type NewAccountConfig struct {
+	Enabled        bool `yaml:"enabled"`         // 是否启用新账号发布冷却
+	MinAgeHours    int  `yaml:"min_age_hours"`   // 注册时间不足该小时数的账号视为新账号
+	ContentLimit   int  `yaml:"content_limit"`   // 新账号在一个窗口内最多创建的内容数
+	CommentLimit   int  `yaml:"comment_limit"`   // 新账号在一个窗口内最多发表的评论数
+	Window         int  `yaml:"window"`          // 频率限制窗口（秒）
+	ExemptVerified bool `yaml:"exempt_verified"` // 认证账号是否不受限制
+}
+
+// FeedViewedConfig 内容流已浏览内容配置，用户打开过的内容记录在有上限的集合中，刷新内容流时排除或降权
+type FeedViewedConfig struct {
+	Mode     string  `yaml:"mode"`      // 默认处理方式：off不处理、exclude排除、downrank降权，请求可单独指定
+	MaxItems int     `yaml:"max_items"` // 每个用户最多记录的已浏览内容数，超出时淘汰最早的记录
+	TTLDays  int     `yaml:"ttl_days"`  // 浏览记录的有效天数，过期的记录不再影响内容流
+	Penalty  float64 `yaml:"penalty"`   // 降权时已浏览内容的分数系数，取值(0,1)
+}
+
+// ExcerptConfig 内容摘要配置，列表响应只返回摘要，不返回正文
+type ExcerptConfig struct {
+	Length           int `yaml:"length"`             // 自动生成摘要的最大字符数（按字符计，中文一字为一个字符）
+	SummaryMaxLength int `yaml:"summary_max_length"` // 作者填写的摘要最大字符数
+}
+
+// TrendingConfig 标签和话题热度配置
+type TrendingConfig struct {
+	HalfLifeHours int `yaml:"half_life_hours"` // 热度衰减半衰期（小时）
+}
+
+// MediaConfig 媒体上传配置，通过预签名地址直传对象存储
+type MediaConfig struct {
+	Enabled             bool   `yaml:"enabled"`               // 是否启用媒体上传
+	Provider            string `yaml:"provider"`              // 存储实现：s3（兼容S3协议的对象存储）
+	Endpoint            string `yaml:"endpoint"`              // 对象存储地址，如 https://s3.us-east-1.amazonaws.com
+	Region              string `yaml:"region"`                // 区域
+	Bucket              string `yaml:"bucket"`                // 存储桶
+	AccessKey           string `yaml:"access_key"`            // 访问密钥ID
+	SecretKey           string `yaml:"secret_key"`            // 访问密钥
+	PathStyle           bool   `yaml:"path_style"`            // 是否使用路径风格地址（MinIO等自建存储通常需要开启）
+	PublicURL           string `yaml:"public_url"`            // 对象访问地址前缀，如CDN域名，为空时使用存储地址
+	PresignExpire       int    `yaml:"presign_expire"`        // 上传地址有效期（秒）
+	MaxSize             int64  `yaml:"max_size"`              // 单个文件最大字节数
+	AllowedContentTypes string `yaml:"allowed_content_types"` // 允许上传的MIME类型，逗号分隔
+}
+
+// FeedRankingConfig 内容流加权排序配置
+type FeedRankingConfig struct {
+	RecencyWeight         float64 `yaml:"recency_weight"`          // 时效权重
+	EngagementWeight      float64 `yaml:"engagement_weight"`       // 互动权重
+	PersonalizationWeight float64 `yaml:"personalization_weight"`  // 个性化权重，为0时不查询用户偏好
+	RecencyHalfLifeHours  int     `yaml:"recency_half_life_hours"` // 时效分数衰减半衰期（小时）
+	Debug                 bool    `yaml:"debug"`                   // 是否记录每条内容的分数构成
+}
+
+// ModerationConfig 内容发布审核配置
+type ModerationConfig struct {
+	Enabled        bool   `yaml:"enabled"`         // 是否启用发布审核
+	Provider       string `yaml:"provider"`        // 审核实现：keyword（关键词/正则）或 http（外部审核服务）
+	Async          bool   `yaml:"async"`           // 是否异步审核，异步时发布接口立即返回待审核状态
+	RejectKeywords string `yaml:"reject_keywords"` // 命中即拒绝的关键词，逗号分隔
+	FlagKeywords   string `yaml:"flag_keywords"`   // 命中后标记待人工复核的关键词，逗号分隔
+	RejectPattern  string `yaml:"reject_pattern"`  // 命中即拒绝的正则
+	FlagPattern    string `yaml:"flag_pattern"`    // 命中后标记待人工复核的正则
+	Endpoint       string `yaml:"endpoint"`        // 外部审核服务地址
+	Timeout        int    `yaml:"timeout"`         // 单次审核超时（毫秒）
+}
+
+// SearchConfig 搜索服务配置
+type SearchConfig struct {
+	Highlight  HighlightConfig  `yaml:"highlight"`
+	Popularity PopularityConfig `yaml:"popularity"`
+	Blend      BlendConfig      `yaml:"blend"`
+}
+
+// HighlightConfig 搜索结果高亮配置
+type HighlightConfig struct {
+	PreTag            string `yaml:"pre_tag"`             // 高亮前置标签
+	PostTag           string `yaml:"post_tag"`            // 高亮后置标签
+	FragmentSize      int    `yaml:"fragment_size"`       // 高亮片段长度（字符）
+	NumberOfFragments int    `yaml:"number_of_fragments"` // 每个字段返回的高亮片段数
+	SnippetLength     int    `yaml:"snippet_length"`      // 无高亮时截取的摘要长度（字符）
+}
+
+// PopularityConfig 搜索热度加权配置
+type PopularityConfig struct {
+	Weight float64 `yaml:"weight"` // 热度分相对文本相关性的权重，0表示只按相关性排序
+}
+
+// BlendConfig 多类型搜索结果合并配置，各索引的相关性分先按索引内最高分和最低分归一化到0~1，再乘以类型权重后合并排序
+type BlendConfig struct {
+	TypeWeights map[string]float64 `yaml:"type_weights"` // 按结果类型（content/user/message/group）的权重，未配置的类型为1
+}
+
+// SocialConfig 社交服务配置
+type SocialConfig struct {
+	Group  GroupConfig  `yaml:"group"`
+	Friend FriendConfig `yaml:"friend"`
+}
+
+// AuditConfig 审计日志配置，各服务的审核和管理操作写入共享的审计记录
+type AuditConfig struct {
+	RetentionDays int    `yaml:"retention_days"` // 审计记录保留天数
+	AdminIDs      string `yaml:"admin_ids"`      // 允许查询审计记录的管理员用户ID，逗号分隔
+}
+
+// UserConfig 用户服务配置
+type UserConfig struct {
+	AdminIDs string `yaml:"admin_ids"` // 允许限制和解除用户发布权限、设置账号认证状态的管理员用户ID，逗号分隔
+}
+
+// GroupConfig 群组配置
+type GroupConfig struct {
+	DefaultMaxMembers int    `yaml:"default_max_members"` // standard等级群组的成员上限
+	Tiers             string `yaml:"tiers"`               // 其他群组等级的成员上限，格式 tier:max，逗号分隔
+	AdminIDs          string `yaml:"admin_ids"`           // 允许创建standard以外等级群组的管理员用户ID，逗号分隔，为空时只能创建standard等级
+}
+
+// FriendConfig 好友关系配置
+type FriendConfig struct {
+	HistoryRetentionDays int `yaml:"history_retention_days"` // 已删除好友关系的保留天数，超过后彻底清除，0表示永久保留
+	RequestHourlyLimit   int `yaml:"request_hourly_limit"`   // 每个用户每小时最多发送的好友申请数，0表示不限制
+	BatchMaxTargets      int `yaml:"batch_max_targets"`      // 批量添加好友单次最多的目标数
+	BulkHourlyLimit      int `yaml:"bulk_hourly_limit"`      // 已认证账号批量添加时每小时的申请数上限，与单个申请共用计数
+	BulkMinAccountDays   int `yaml:"bulk_min_account_days"`  // 注册满该天数且状态正常的账号视为已认证，适用批量上限
 }
 
 // LoadConfig 从环境变量加载配置
@@ -180,6 +612,16 @@ func LoadConfig(serviceName string) *Config {
 			Version:   getEnvOrDefault("APP_VERSION", "1.0.0"),
 			JWTSecret: getEnvOrDefault("JWT_SECRET", "focusandinsist"),
 		},
+		Auth: AuthConfig{
+			Provider:    getEnvOrDefault("AUTH_PROVIDER", "jwt"),
+			DebugBypass: getEnvBoolOrDefault("AUTH_DEBUG_BYPASS", false),
+			Introspection: IntrospectionConfig{
+				URL:          getEnvOrDefault("AUTH_INTROSPECTION_URL", ""),
+				ClientID:     getEnvOrDefault("AUTH_INTROSPECTION_CLIENT_ID", ""),
+				ClientSecret: getEnvOrDefault("AUTH_INTROSPECTION_CLIENT_SECRET", ""),
+				Timeout:      getEnvIntOrDefault("AUTH_INTROSPECTION_TIMEOUT_MS", 3000),
+			},
+		},
 		Server: ServerConfig{
 			HTTP: HTTPConfig{
 				Network: "tcp",
@@ -187,10 +629,14 @@ func LoadConfig(serviceName string) *Config {
 				Timeout: "30s",
 			},
 			GRPC: GRPCConfig{
-				Network: "tcp",
-				Addr:    ":" + grpcPort,
-				Timeout: "30s",
+				Network:    "tcp",
+				Addr:       ":" + grpcPort,
+				Timeout:    "30s",
+				Reflection: getEnvBoolOrDefault("GRPC_REFLECTION_ENABLED", false),
 			},
+			ShutdownTimeout:     getEnvOrDefault("SHUTDOWN_TIMEOUT", "30s"),
+			ServiceInfo:         getEnvBoolOrDefault("SERVICE_INFO_ENABLED", false),
+			ServiceInfoAdminIDs: getEnvOrDefault("SERVICE_INFO_ADMIN_IDS", ""),
 		},
 		Database: DatabaseConfig{
 			MongoDB: MongoDBConfig{
@@ -229,9 +675,45 @@ func LoadConfig(serviceName string) *Config {
 				Timeout:  getEnvIntOrDefault("HEARTBEAT_TIMEOUT", 30),
 			},
 			Connection: ConnectionConfig{
-				ExpireTime: getEnvIntOrDefault("CONNECTION_EXPIRE_TIME", 2),
-				ClientType: getEnvOrDefault("DEFAULT_CLIENT_TYPE", "web"),
+				ExpireTime:   getEnvIntOrDefault("CONNECTION_EXPIRE_TIME", 2),
+				ClientType:   getEnvOrDefault("DEFAULT_CLIENT_TYPE", "web"),
+				DrainTimeout: getEnvIntOrDefault("CONNECTION_DRAIN_TIMEOUT", 10),
+
+				MaxConnections:   getEnvIntOrDefault("CONNECTION_MAX_CONNECTIONS", 0),
+				ReconnectReserve: getEnvIntOrDefault("CONNECTION_RECONNECT_RESERVE", 5),
+				RetryAfter:       getEnvIntOrDefault("CONNECTION_RETRY_AFTER", 5),
+			},
+			FriendOnline: FriendOnlineConfig{
+				Enabled:  getEnvBoolOrDefault("FRIEND_ONLINE_NOTIFY_ENABLED", false),
+				Debounce: getEnvIntOrDefault("FRIEND_ONLINE_NOTIFY_DEBOUNCE", 60),
+			},
+			PresenceHook: PresenceHookConfig{
+				Enabled:          getEnvBoolOrDefault("PRESENCE_WEBHOOK_ENABLED", false),
+				Timeout:          getEnvIntOrDefault("PRESENCE_WEBHOOK_TIMEOUT", 5),
+				MaxRetries:       getEnvIntOrDefault("PRESENCE_WEBHOOK_MAX_RETRIES", 3),
+				RetryBackoff:     getEnvIntOrDefault("PRESENCE_WEBHOOK_RETRY_BACKOFF", 1000),
+				MaxSubscriptions: getEnvIntOrDefault("PRESENCE_WEBHOOK_MAX_SUBSCRIPTIONS", 5),
+				MaxUsers:         getEnvIntOrDefault("PRESENCE_WEBHOOK_MAX_USERS", 500),
+				AllowHTTP:        getEnvBoolOrDefault("PRESENCE_WEBHOOK_ALLOW_HTTP", false),
+				IntegrationIDs:   getEnvOrDefault("PRESENCE_WEBHOOK_INTEGRATION_IDS", ""),
+			},
+			WebSocket: WebSocketConfig{
+				AllowedOrigins: getEnvListOrDefault("WS_ALLOWED_ORIGINS", nil),
+				AllowNoOrigin:  getEnvBoolOrDefault("WS_ALLOW_NO_ORIGIN", true),
+			},
+			AntiReplay: AntiReplayConfig{
+				Enabled:            getEnvBoolOrDefault("WS_ANTI_REPLAY_ENABLED", true),
+				Window:             getEnvIntOrDefault("WS_ANTI_REPLAY_WINDOW", 300),
+				RequireClientMsgID: getEnvBoolOrDefault("WS_ANTI_REPLAY_REQUIRE_CLIENT_MSG_ID", false),
+			},
+			Echo: EchoConfig{
+				Mode: getEnvOrDefault("MESSAGE_ECHO_MODE", "other_devices"),
 			},
+			PushLanes: PushLanesConfig{
+				QueueSize: getEnvIntOrDefault("PUSH_LANE_QUEUE_SIZE", 256),
+				Overrides: getEnvStringMapOrDefault("PUSH_LANE_OVERRIDES", nil),
+			},
+			AdminIDs: getEnvOrDefault("CONNECT_ADMIN_IDS", ""),
 		},
 		Logic: LogicConfig{
 			UserService: ServiceEndpoint{
@@ -254,6 +736,43 @@ func LoadConfig(serviceName string) *Config {
 				Host: getEnvOrDefault("SEARCH_SERVICE_HOST", "localhost"),
 				Port: getEnvIntOrDefault("SEARCH_SERVICE_PORT", 22005),
 			},
+			PersistRetry: RetryConfig{
+				MaxAttempts:    getEnvIntOrDefault("LOGIC_PERSIST_RETRY_MAX_ATTEMPTS", 3),
+				InitialBackoff: getEnvIntOrDefault("LOGIC_PERSIST_RETRY_INITIAL_BACKOFF_MS", 100),
+				MaxBackoff:     getEnvIntOrDefault("LOGIC_PERSIST_RETRY_MAX_BACKOFF_MS", 2000),
+			},
+			SendQuota: SendQuotaConfig{
+				Enabled:            getEnvBoolOrDefault("LOGIC_SEND_QUOTA_ENABLED", false),
+				HourlyLimit:        getEnvIntOrDefault("LOGIC_SEND_QUOTA_HOURLY_LIMIT", 600),
+				DailyLimit:         getEnvIntOrDefault("LOGIC_SEND_QUOTA_DAILY_LIMIT", 5000),
+				SpamWindow:         getEnvIntOrDefault("LOGIC_SPAM_WINDOW_SECONDS", 60),
+				RateThreshold:      getEnvIntOrDefault("LOGIC_SPAM_RATE_THRESHOLD", 30),
+				DuplicateThreshold: getEnvIntOrDefault("LOGIC_SPAM_DUPLICATE_THRESHOLD", 5),
+				RecipientThreshold: getEnvIntOrDefault("LOGIC_SPAM_RECIPIENT_THRESHOLD", 20),
+				ThrottleScore:      getEnvFloatOrDefault("LOGIC_SPAM_THROTTLE_SCORE", 1.0),
+				BlockScore:         getEnvFloatOrDefault("LOGIC_SPAM_BLOCK_SCORE", 2.0),
+				BlockDuration:      getEnvIntOrDefault("LOGIC_SPAM_BLOCK_DURATION_SECONDS", 600),
+				ExemptUsers:        getEnvOrDefault("LOGIC_SEND_QUOTA_EXEMPT_USERS", ""),
+			},
+			DeliveryBatch: DeliveryBatchConfig{
+				Enabled: getEnvBoolOrDefault("LOGIC_DELIVERY_BATCH_ENABLED", false),
+				Window:  getEnvIntOrDefault("LOGIC_DELIVERY_BATCH_WINDOW_MS", 200),
+				MaxSize: getEnvIntOrDefault("LOGIC_DELIVERY_BATCH_MAX_SIZE", 500),
+			},
+			Fanout: FanoutConfig{
+				Workers:    getEnvIntOrDefault("LOGIC_FANOUT_WORKERS", 64),
+				PerMessage: getEnvIntOrDefault("LOGIC_FANOUT_PER_MESSAGE", 16),
+			},
+			AttachmentScan: AttachmentScanConfig{
+				Enabled:      getEnvBoolOrDefault("LOGIC_ATTACHMENT_SCAN_ENABLED", false),
+				MaxSize:      int64(getEnvIntOrDefault("LOGIC_ATTACHMENT_MAX_SIZE", 100*1024*1024)),
+				AllowedTypes: getEnvListOrDefault("LOGIC_ATTACHMENT_ALLOWED_TYPES", nil),
+				ScanURL:      getEnvOrDefault("LOGIC_ATTACHMENT_SCAN_URL", ""),
+				SyncWait:     getEnvIntOrDefault("LOGIC_ATTACHMENT_SCAN_SYNC_WAIT_MS", 500),
+				Timeout:      getEnvIntOrDefault("LOGIC_ATTACHMENT_SCAN_TIMEOUT_MS", 30000),
+				FailOpen:     getEnvBoolOrDefault("LOGIC_ATTACHMENT_SCAN_FAIL_OPEN", false),
+			},
+			AdminIDs: getEnvOrDefault("LOGIC_ADMIN_IDS", ""),
 		},
 		Services: ServicesConfig{
 			UserService: ServiceEndpoint{
@@ -289,7 +808,212 @@ func LoadConfig(serviceName string) *Config {
 				Port: getEnvIntOrDefault("API_GATEWAY_PORT", 22008),
 			},
 		},
+		Message: MessageConfig{
+			Encryption: EncryptionConfig{
+				Enabled:     getEnvBoolOrDefault("MESSAGE_ENCRYPTION_ENABLED", false),
+				ActiveKeyID: getEnvOrDefault("MESSAGE_ENCRYPTION_ACTIVE_KEY_ID", ""),
+				Keys:        getEnvOrDefault("MESSAGE_ENCRYPTION_KEYS", ""),
+			},
+			Export: MessageExportConfig{
+				Dir:             getEnvOrDefault("MESSAGE_EXPORT_DIR", "./exports"),
+				RespectJoinTime: getEnvBoolOrDefault("MESSAGE_EXPORT_RESPECT_JOIN_TIME", true),
+				RateLimit:       getEnvIntOrDefault("MESSAGE_EXPORT_RATE_LIMIT", 3),
+				RateWindow:      getEnvIntOrDefault("MESSAGE_EXPORT_RATE_WINDOW", 3600),
+				AsyncThreshold:  getEnvIntOrDefault("MESSAGE_EXPORT_ASYNC_THRESHOLD", 5000),
+				Timeout:         getEnvIntOrDefault("MESSAGE_EXPORT_TIMEOUT", 600),
+				FileTTL:         getEnvIntOrDefault("MESSAGE_EXPORT_FILE_TTL", 86400),
+			},
+			AckResend: AckResendConfig{
+				Enabled:     getEnvBoolOrDefault("MESSAGE_ACK_RESEND_ENABLED", true),
+				Timeout:     getEnvIntOrDefault("MESSAGE_ACK_TIMEOUT", 10),
+				MaxAttempts: getEnvIntOrDefault("MESSAGE_ACK_MAX_ATTEMPTS", 3),
+			},
+			Archive: MessageArchiveConfig{
+				AutoUnarchive: getEnvBoolOrDefault("MESSAGE_ARCHIVE_AUTO_UNARCHIVE", true),
+			},
+			Storage: MessageStorageConfig{
+				RelayTypes:     getEnvIntListOrDefault("MESSAGE_STORAGE_RELAY_TYPES", []int{10, 11, 101, 106, 107, 111, 112}),
+				UnindexedTypes: getEnvIntListOrDefault("MESSAGE_STORAGE_UNINDEXED_TYPES", []int{2, 3, 4, 5, 6, 7}),
+			},
+			Digest: NotificationDigestConfig{
+				Enabled:     getEnvBoolOrDefault("MESSAGE_DIGEST_ENABLED", true),
+				DefaultMode: getEnvOrDefault("MESSAGE_DIGEST_DEFAULT_MODE", "hourly"),
+				MaxItems:    getEnvIntOrDefault("MESSAGE_DIGEST_MAX_ITEMS", 20),
+			},
+			Retention: MessageRetentionConfig{
+				DefaultDays:   getEnvIntOrDefault("MESSAGE_RETENTION_DEFAULT_DAYS", 0),
+				MinDays:       getEnvIntOrDefault("MESSAGE_RETENTION_MIN_DAYS", 1),
+				MaxDays:       getEnvIntOrDefault("MESSAGE_RETENTION_MAX_DAYS", 3650),
+				PurgeInterval: getEnvIntOrDefault("MESSAGE_RETENTION_PURGE_INTERVAL", 3600),
+				BatchSize:     getEnvIntOrDefault("MESSAGE_RETENTION_BATCH_SIZE", 500),
+			},
+			Pin: MessagePinConfig{
+				MaxPerGroup: getEnvIntOrDefault("MESSAGE_PIN_MAX_PER_GROUP", 10),
+				OnLimit:     getEnvOrDefault("MESSAGE_PIN_ON_LIMIT", "reject"),
+			},
+			Poll: MessagePollConfig{
+				AllowMultiSelect: getEnvBoolOrDefault("MESSAGE_POLL_ALLOW_MULTI_SELECT", true),
+				CountLeftMembers: getEnvBoolOrDefault("MESSAGE_POLL_COUNT_LEFT_MEMBERS", true),
+				UpdateInterval:   getEnvIntOrDefault("MESSAGE_POLL_UPDATE_INTERVAL", 1),
+			},
+			DeliveryReport: DeliveryReportConfig{
+				Enabled:         getEnvBoolOrDefault("MESSAGE_DELIVERY_REPORT_ENABLED", true),
+				SummaryInterval: getEnvIntOrDefault("MESSAGE_DELIVERY_REPORT_SUMMARY_INTERVAL", 10),
+				MinGroupSize:    getEnvIntOrDefault("MESSAGE_DELIVERY_REPORT_MIN_GROUP_SIZE", 100),
+				Retention:       getEnvIntOrDefault("MESSAGE_DELIVERY_REPORT_RETENTION", 72),
+			},
+		},
+		Content: ContentConfig{
+			FeedRanking: FeedRankingConfig{
+				RecencyWeight:         getEnvFloatOrDefault("CONTENT_FEED_RECENCY_WEIGHT", 0.6),
+				EngagementWeight:      getEnvFloatOrDefault("CONTENT_FEED_ENGAGEMENT_WEIGHT", 0.4),
+				PersonalizationWeight: getEnvFloatOrDefault("CONTENT_FEED_PERSONALIZATION_WEIGHT", 0),
+				RecencyHalfLifeHours:  getEnvIntOrDefault("CONTENT_FEED_RECENCY_HALF_LIFE_HOURS", 24),
+				Debug:                 getEnvBoolOrDefault("CONTENT_FEED_RANKING_DEBUG", false),
+			},
+			Moderation: ModerationConfig{
+				Enabled:        getEnvBoolOrDefault("CONTENT_MODERATION_ENABLED", false),
+				Provider:       getEnvOrDefault("CONTENT_MODERATION_PROVIDER", "keyword"),
+				Async:          getEnvBoolOrDefault("CONTENT_MODERATION_ASYNC", false),
+				RejectKeywords: getEnvOrDefault("CONTENT_MODERATION_REJECT_KEYWORDS", ""),
+				FlagKeywords:   getEnvOrDefault("CONTENT_MODERATION_FLAG_KEYWORDS", ""),
+				RejectPattern:  getEnvOrDefault("CONTENT_MODERATION_REJECT_PATTERN", ""),
+				FlagPattern:    getEnvOrDefault("CONTENT_MODERATION_FLAG_PATTERN", ""),
+				Endpoint:       getEnvOrDefault("CONTENT_MODERATION_ENDPOINT", ""),
+				Timeout:        getEnvIntOrDefault("CONTENT_MODERATION_TIMEOUT_MS", 3000),
+			},
+			AllowedReactions: getEnvOrDefault("CONTENT_ALLOWED_REACTIONS", ""),
+			Media: MediaConfig{
+				Enabled:             getEnvBoolOrDefault("CONTENT_MEDIA_ENABLED", false),
+				Provider:            getEnvOrDefault("CONTENT_MEDIA_PROVIDER", "s3"),
+				Endpoint:            getEnvOrDefault("CONTENT_MEDIA_ENDPOINT", ""),
+				Region:              getEnvOrDefault("CONTENT_MEDIA_REGION", "us-east-1"),
+				Bucket:              getEnvOrDefault("CONTENT_MEDIA_BUCKET", ""),
+				AccessKey:           getEnvOrDefault("CONTENT_MEDIA_ACCESS_KEY", ""),
+				SecretKey:           getEnvOrDefault("CONTENT_MEDIA_SECRET_KEY", ""),
+				PathStyle:           getEnvBoolOrDefault("CONTENT_MEDIA_PATH_STYLE", false),
+				PublicURL:           getEnvOrDefault("CONTENT_MEDIA_PUBLIC_URL", ""),
+				PresignExpire:       getEnvIntOrDefault("CONTENT_MEDIA_PRESIGN_EXPIRE", 300),
+				MaxSize:             int64(getEnvIntOrDefault("CONTENT_MEDIA_MAX_SIZE", 20*1024*1024)),
+				AllowedContentTypes: getEnvOrDefault("CONTENT_MEDIA_ALLOWED_CONTENT_TYPES", "image/jpeg,image/png,image/gif,image/webp,video/mp4,audio/mpeg,audio/mp4,application/pdf"),
+			},
+			Trending: TrendingConfig{
+				HalfLifeHours: getEnvIntOrDefault("CONTENT_TRENDING_HALF_LIFE_HOURS", 6),
+			},
+			MaxMentions:   getEnvIntOrDefault("CONTENT_MAX_MENTIONS", 10),
+			DetailTimeout: getEnvIntOrDefault("CONTENT_DETAIL_TIMEOUT_MS", 800),
+			Excerpt: ExcerptConfig{
+				Length:           getEnvIntOrDefault("CONTENT_EXCERPT_LENGTH", 120),
+				SummaryMaxLength: getEnvIntOrDefault("CONTENT_SUMMARY_MAX_LENGTH", 300),
+			},
+			FeedViewed: FeedViewedConfig{
+				Mode:     getEnvOrDefault("CONTENT_FEED_VIEWED_MODE", "off"),
+				MaxItems: getEnvIntOrDefault("CONTENT_FEED_VIEWED_MAX_ITEMS", 1000),
+				TTLDays:  getEnvIntOrDefault("CONTENT_FEED_VIEWED_TTL_DAYS", 30),
+				Penalty:  getEnvFloatOrDefault("CONTENT_FEED_VIEWED_PENALTY", 0.3),
+			},
+			NewAccount: NewAccountConfig{
+				Enabled:        getEnvBoolOrDefault("CONTENT_NEW_ACCOUNT_ENABLED", true),
+				MinAgeHours:    getEnvIntOrDefault("CONTENT_NEW_ACCOUNT_MIN_AGE_HOURS", 72),
+				ContentLimit:   getEnvIntOrDefault("CONTENT_NEW_ACCOUNT_CONTENT_LIMIT", 2),
+				CommentLimit:   getEnvIntOrDefault("CONTENT_NEW_ACCOUNT_COMMENT_LIMIT", 10),
+				Window:         getEnvIntOrDefault("CONTENT_NEW_ACCOUNT_WINDOW", 3600),
+				ExemptVerified: getEnvBoolOrDefault("CONTENT_NEW_ACCOUNT_EXEMPT_VERIFIED", true),
+			},
+			AdminIDs: getEnvOrDefault("CONTENT_ADMIN_IDS", ""),
+		},
+		Search: SearchConfig{
+			Highlight: HighlightConfig{
+				PreTag:            getEnvOrDefault("SEARCH_HIGHLIGHT_PRE_TAG", "<mark>"),
+				PostTag:           getEnvOrDefault("SEARCH_HIGHLIGHT_POST_TAG", "</mark>"),
+				FragmentSize:      getEnvIntOrDefault("SEARCH_HIGHLIGHT_FRAGMENT_SIZE", 150),
+				NumberOfFragments: getEnvIntOrDefault("SEARCH_HIGHLIGHT_NUMBER_OF_FRAGMENTS", 3),
+				SnippetLength:     getEnvIntOrDefault("SEARCH_SNIPPET_LENGTH", 150),
+			},
+			Popularity: PopularityConfig{
+				Weight: getEnvFloatOrDefault("SEARCH_POPULARITY_WEIGHT", 1.0),
+			},
+			Blend: BlendConfig{
+				TypeWeights: getEnvFloatMapOrDefault("SEARCH_BLEND_TYPE_WEIGHTS", nil),
+			},
+		},
+		Social: SocialConfig{
+			Group: GroupConfig{
+				DefaultMaxMembers: getEnvIntOrDefault("SOCIAL_GROUP_DEFAULT_MAX_MEMBERS", 500),
+				Tiers:             getEnvOrDefault("SOCIAL_GROUP_TIERS", "large:2000,super:5000"),
+				AdminIDs:          getEnvOrDefault("SOCIAL_GROUP_ADMIN_IDS", ""),
+			},
+			Friend: FriendConfig{
+				HistoryRetentionDays: getEnvIntOrDefault("SOCIAL_FRIEND_HISTORY_RETENTION_DAYS", 180),
+				RequestHourlyLimit:   getEnvIntOrDefault("SOCIAL_FRIEND_REQUEST_HOURLY_LIMIT", 20),
+				BatchMaxTargets:      getEnvIntOrDefault("SOCIAL_FRIEND_BATCH_MAX_TARGETS", 100),
+				BulkHourlyLimit:      getEnvIntOrDefault("SOCIAL_FRIEND_BULK_HOURLY_LIMIT", 200),
+				BulkMinAccountDays:   getEnvIntOrDefault("SOCIAL_FRIEND_BULK_MIN_ACCOUNT_DAYS", 7),
+			},
+		},
+		Audit: AuditConfig{
+			RetentionDays: getEnvIntOrDefault("AUDIT_RETENTION_DAYS", 180),
+			AdminIDs:      getEnvOrDefault("AUDIT_ADMIN_IDS", ""),
+		},
+		User: UserConfig{
+			AdminIDs: getEnvOrDefault("USER_ADMIN_IDS", ""),
+		},
+		Deadline: DeadlineConfig{
+			HTTPRequest: getEnvIntOrDefault("DEADLINE_HTTP_REQUEST_MS", 15000),
+			HTTPRoutes:  getEnvIntMapOrDefault("DEADLINE_HTTP_ROUTES_MS", map[string]int{"/api/v1/admin/index/reindex": 600000, "/api/v1/admin/index/reindex_by_type": 600000}),
+			GRPCCall:    getEnvIntOrDefault("DEADLINE_GRPC_CALL_MS", 5000),
+			GRPCMethods: getEnvIntMapOrDefault("DEADLINE_GRPC_METHODS_MS", nil),
+		},
+		Pagination: PaginationConfig{
+			DefaultPageSize: getEnvIntOrDefault("PAGINATION_DEFAULT_PAGE_SIZE", 20),
+			MaxPageSize:     getEnvIntOrDefault("PAGINATION_MAX_PAGE_SIZE", 100),
+			MaxBatchSize:    getEnvIntOrDefault("PAGINATION_MAX_BATCH_SIZE", 100),
+		},
+		EditWindow: EditWindowConfig{
+			Content:      getEnvIntOrDefault("EDIT_WINDOW_CONTENT_SECONDS", 1800),
+			Comment:      getEnvIntOrDefault("EDIT_WINDOW_COMMENT_SECONDS", 300),
+			Message:      getEnvIntOrDefault("EDIT_WINDOW_MESSAGE_SECONDS", 120),
+			ContentTypes: getEnvIntMapOrDefault("EDIT_WINDOW_CONTENT_TYPES_SECONDS", nil),
+		},
+		Presence: PresenceConfig{
+			Enabled: getEnvBoolOrDefault("PRESENCE_CACHE_ENABLED", true),
+			TTL:     getEnvIntOrDefault("PRESENCE_CACHE_TTL", 60),
+		},
+		Profanity: ProfanityConfig{
+			Enabled:         getEnvBoolOrDefault("PROFANITY_ENABLED", false),
+			DryRun:          getEnvBoolOrDefault("PROFANITY_DRY_RUN", false),
+			WordLists:       getEnvOrDefault("PROFANITY_WORD_LISTS", ""),
+			DefaultSeverity: getEnvOrDefault("PROFANITY_DEFAULT_SEVERITY", "hold"),
+			ReloadInterval:  getEnvIntOrDefault("PROFANITY_RELOAD_INTERVAL", 30),
+		},
+		Outbox: OutboxConfig{
+			PollInterval: getEnvIntOrDefault("OUTBOX_POLL_INTERVAL_MS", 500),
+			BatchSize:    getEnvIntOrDefault("OUTBOX_BATCH_SIZE", 100),
+			MaxAttempts:  getEnvIntOrDefault("OUTBOX_MAX_ATTEMPTS", 20),
+			MaxBackoff:   getEnvIntOrDefault("OUTBOX_MAX_BACKOFF_MS", 30000),
+			Retention:    getEnvIntOrDefault("OUTBOX_RETENTION_HOURS", 72),
+			DedupTTL:     getEnvIntOrDefault("OUTBOX_DEDUP_TTL", 86400),
+		},
+		Startup: StartupConfig{
+			InitAttempts:   getEnvIntOrDefault("STARTUP_INIT_ATTEMPTS", 6),
+			InitBackoff:    getEnvIntOrDefault("STARTUP_INIT_BACKOFF_MS", 1000),
+			InitMaxBackoff: getEnvIntOrDefault("STARTUP_INIT_MAX_BACKOFF_MS", 15000),
+			KafkaOptional:  getEnvBoolOrDefault("STARTUP_KAFKA_OPTIONAL", false),
+		},
+	}
+}
+
+// ParseUserIDs 解析逗号分隔的用户ID列表，如各服务的admin_ids，忽略无效项和非正数
+// 各服务的管理员分别配置而不共用一个集合：每项只授予一种管理权限，按最小权限分开授予，
+// 例如运维人员可以查询连接而不能封禁用户；需要多项权限的管理员在各项中重复配置
+func ParseUserIDs(value string) map[int64]bool {
+	userIDs := make(map[int64]bool)
+	for _, item := range strings.Split(value, ",") {
+		if userID, err := strconv.ParseInt(strings.TrimSpace(item), 10, 64); err == nil && userID > 0 {
+			userIDs[userID] = true
+		}
 	}
+	return userIDs
 }
 
 // getEnvOrDefault 获取环境变量或默认值
@@ -309,3 +1033,113 @@ func getEnvIntOrDefault(key string, defaultValue int) int {
 	}
 	return defaultValue
 }
+
+// getEnvFloatOrDefault 获取环境变量浮点值或默认值
+func getEnvFloatOrDefault(key string, defaultValue float64) float64 {
+	if value := os.Getenv(key); value != "" {
+		if floatValue, err := strconv.ParseFloat(value, 64); err == nil {
+			return floatValue
+		}
+	}
+	return defaultValue
+}
+
+// getEnvBoolOrDefault 获取环境变量布尔值或默认值
+func getEnvBoolOrDefault(key string, defaultValue bool) bool {
+	if value := os.Getenv(key); value != "" {
+		if boolValue, err := strconv.ParseBool(value); err == nil {
+			return boolValue
+		}
+	}
+	return defaultValue
+}
+
+// getEnvIntMapOrDefault 获取逗号分隔的key=value环境变量映射或默认值，忽略格式错误的项
+func getEnvIntMapOrDefault(key string, defaultValue map[string]int) map[string]int {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	result := make(map[string]int)
+	for _, item := range strings.Split(value, ",") {
+		k, v, ok := strings.Cut(strings.TrimSpace(item), "=")
+		if !ok {
+			continue
+		}
+		if intValue, err := strconv.Atoi(strings.TrimSpace(v)); err == nil {
+			result[strings.TrimSpace(k)] = intValue
+		}
+	}
+	return result
+}
+
+// getEnvFloatMapOrDefault 获取逗号分隔的key=value环境变量浮点映射或默认值，忽略格式错误的项
+func getEnvFloatMapOrDefault(key string, defaultValue map[string]float64) map[string]float64 {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	result := make(map[string]float64)
+	for _, item := range strings.Split(value, ",") {
+		k, v, ok := strings.Cut(strings.TrimSpace(item), "=")
+		if !ok {
+			continue
+		}
+		if floatValue, err := strconv.ParseFloat(strings.TrimSpace(v), 64); err == nil {
+			result[strings.TrimSpace(k)] = floatValue
+		}
+	}
+	return result
+}
+
+// getEnvStringMapOrDefault 获取逗号分隔的key=value环境变量映射或默认值，忽略格式错误的项
+func getEnvStringMapOrDefault(key string, defaultValue map[string]string) map[string]string {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	result := make(map[string]string)
+	for _, item := range strings.Split(value, ",") {
+		k, v, ok := strings.Cut(strings.TrimSpace(item), "=")
+		if !ok {
+			continue
+		}
+		result[strings.TrimSpace(k)] = strings.TrimSpace(v)
+	}
+	return result
+}
+
+// getEnvListOrDefault 获取逗号分隔的环境变量列表或默认值
+func getEnvListOrDefault(key string, defaultValue []string) []string {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	var list []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+// getEnvIntListOrDefault 获取逗号分隔的整数环境变量列表或默认值，忽略格式错误的项
+func getEnvIntListOrDefault(key string, defaultValue []int) []int {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	var list []int
+	for _, item := range strings.Split(value, ",") {
+		if intValue, err := strconv.Atoi(strings.TrimSpace(item)); err == nil {
+			list = append(list, intValue)
+		}
+	}
+	return list
+}
@@ -0,0 +1,18 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestParseUserIDs 忽略空白、格式错误和非正数的项
+func TestParseUserIDs(t *testing.T) {
+	got := ParseUserIDs(" 1, 2,bad,,0,-3,2 ")
+	want := map[int64]bool{1: true, 2: true}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseUserIDs() = %v, want %v", got, want)
+	}
+	if got := ParseUserIDs(""); len(got) != 0 {
+		t.Errorf("ParseUserIDs(\"\") = %v, want empty", got)
+	}
+}
@@ -0,0 +1,89 @@
+package conversation
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// 会话类型
+const (
+	TypePrivate = "private" // 单聊
+	TypeGroup   = "group"   // 群聊
+)
+
+// Conversation 会话，单聊由双方用户ID确定，群聊由群组ID确定
+type Conversation struct {
+	Type    string
+	UserA   int64 // 单聊中较小的用户ID
+	UserB   int64 // 单聊中较大的用户ID
+	GroupID int64
+}
+
+// Private 单聊会话ID，与双方顺序无关，格式为 private:{较小用户ID}:{较大用户ID}
+func Private(userA, userB int64) string {
+	if userA > userB {
+		userA, userB = userB, userA
+	}
+	return fmt.Sprintf("%s:%d:%d", TypePrivate, userA, userB)
+}
+
+// Group 群聊会话ID，格式为 group:{群组ID}
+func Group(groupID int64) string {
+	return fmt.Sprintf("%s:%d", TypeGroup, groupID)
+}
+
+// ID 根据消息的收发方计算会话ID，groupID大于0时为群聊
+func ID(from, to, groupID int64) string {
+	if groupID > 0 {
+		return Group(groupID)
+	}
+	return Private(from, to)
+}
+
+// Parse 解析会话ID
+func Parse(id string) (*Conversation, error) {
+	parts := strings.Split(id, ":")
+	switch {
+	case len(parts) == 3 && parts[0] == TypePrivate:
+		a, errA := strconv.ParseInt(parts[1], 10, 64)
+		b, errB := strconv.ParseInt(parts[2], 10, 64)
+		if errA != nil || errB != nil || a > b {
+			return nil, fmt.Errorf("无效的会话ID: %s", id)
+		}
+		return &Conversation{Type: TypePrivate, UserA: a, UserB: b}, nil
+	case len(parts) == 2 && parts[0] == TypeGroup:
+		groupID, err := strconv.ParseInt(parts[1], 10, 64)
+		if err != nil || groupID <= 0 {
+			return nil, fmt.Errorf("无效的会话ID: %s", id)
+		}
+		return &Conversation{Type: TypeGroup, GroupID: groupID}, nil
+	default:
+		return nil, fmt.Errorf("无效的会话ID: %s", id)
+	}
+}
+
+// String 会话ID
+func (c *Conversation) String() string {
+	if c.Type == TypeGroup {
+		return Group(c.GroupID)
+	}
+	return Private(c.UserA, c.UserB)
+}
+
+// IsGroup 是否为群聊
+func (c *Conversation) IsGroup() bool {
+	return c.Type == TypeGroup
+}
+
+// Peer 单聊中对方的用户ID，userID不属于该会话时返回0
+func (c *Conversation) Peer(userID int64) int64 {
+	switch userID {
+	case c.UserA:
+		return c.UserB
+	case c.UserB:
+		return c.UserA
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,40 @@
+package conversation
+
+import "testing"
+
+// TestPrivateOrderIndependent 单聊会话ID与双方顺序无关
+func TestPrivateOrderIndependent(t *testing.T) {
+	if Private(1002, 1001) != Private(1001, 1002) {
+		t.Fatalf("单聊会话ID应与顺序无关: %s != %s", Private(1002, 1001), Private(1001, 1002))
+	}
+	if got := ID(1002, 1001, 0); got != "private:1001:1002" {
+		t.Fatalf("单聊会话ID错误: %s", got)
+	}
+	if got := ID(1002, 1001, 3001); got != "group:3001" {
+		t.Fatalf("群聊会话ID错误: %s", got)
+	}
+}
+
+// TestParse 测试会话ID解析
+func TestParse(t *testing.T) {
+	for _, id := range []string{Private(7, 3), Group(42)} {
+		c, err := Parse(id)
+		if err != nil {
+			t.Fatalf("解析会话ID失败: %v", err)
+		}
+		if c.String() != id {
+			t.Fatalf("解析后会话ID不一致: %s != %s", c.String(), id)
+		}
+	}
+
+	c, _ := Parse(Private(7, 3))
+	if c.IsGroup() || c.Peer(3) != 7 || c.Peer(7) != 3 || c.Peer(5) != 0 {
+		t.Fatalf("单聊会话解析错误: %+v", c)
+	}
+
+	for _, id := range []string{"", "private:1", "private:2:1", "group:0", "group:x", "channel:1"} {
+		if _, err := Parse(id); err == nil {
+			t.Fatalf("无效会话ID应解析失败: %q", id)
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package editwindow
+
+import (
+	"errors"
+	"sort"
+	"time"
+
+	"goim-social/pkg/config"
+)
+
+// 编辑对象的类型
+const (
+	KindContent = "content" // 内容
+	KindComment = "comment" // 评论
+	KindMessage = "message" // 消息（撤回或编辑）
+)
+
+// 配置缺失时使用的默认时限
+const (
+	DefaultContentWindow = 30 * time.Minute
+	DefaultCommentWindow = 5 * time.Minute
+	DefaultMessageWindow = 2 * time.Minute
+)
+
+// ErrExpired 已超过编辑时限
+var ErrExpired = errors.New("已超过可编辑时限")
+
+// Windows 各类对象的编辑时限，内容、评论和消息服务统一通过它判断是否还能编辑或撤回，
+// 时限从发布或发送时间起算，以服务端当前时间判断，不信任客户端传入的时间。
+// 时限为0表示不限制。零值可用，使用默认值
+type Windows struct {
+	content      int
+	comment      int
+	message      int
+	contentTypes map[string]int
+}
+
+// Window 单项编辑时限，用于向客户端下发
+type Window struct {
+	Kind        string        // 编辑对象的类型
+	ContentType string        // 内容类型，仅内容按类型覆盖时非空
+	Duration    time.Duration // 编辑时限，0表示不限制
+}
+
+// New 根据配置创建编辑时限
+func New(cfg config.EditWindowConfig) Windows {
+	return Windows{
+		content:      cfg.Content,
+		comment:      cfg.Comment,
+		message:      cfg.Message,
+		contentTypes: cfg.ContentTypes,
+	}
+}
+
+// seconds 将配置的秒数转换为时限：0使用默认值，负数表示不限制
+func seconds(value int, fallback time.Duration) time.Duration {
+	switch {
+	case value == 0:
+		return fallback
+	case value < 0:
+		return 0
+	default:
+		return time.Duration(value) * time.Second
+	}
+}
+
+// Content 内容的编辑时限，按内容类型覆盖的优先
+func (w Windows) Content(contentType string) time.Duration {
+	if value, ok := w.contentTypes[contentType]; ok {
+		return seconds(value, DefaultContentWindow)
+	}
+	return seconds(w.content, DefaultContentWindow)
+}
+
+// Comment 评论的编辑时限
+func (w Windows) Comment() time.Duration {
+	return seconds(w.comment, DefaultCommentWindow)
+}
+
+// Message 消息的撤回和编辑时限
+func (w Windows) Message() time.Duration {
+	return seconds(w.message, DefaultMessageWindow)
+}
+
+// All 全部编辑时限，内容类型的覆盖项按类型排序排在最后
+func (w Windows) All() []Window {
+	windows := []Window{
+		{Kind: KindContent, Duration: w.Content("")},
+		{Kind: KindComment, Duration: w.Comment()},
+		{Kind: KindMessage, Duration: w.Message()},
+	}
+
+	contentTypes := make([]string, 0, len(w.contentTypes))
+	for contentType := range w.contentTypes {
+		if contentType != "" {
+			contentTypes = append(contentTypes, contentType)
+		}
+	}
+	sort.Strings(contentTypes)
+	for _, contentType := range contentTypes {
+		windows = append(windows, Window{Kind: KindContent, ContentType: contentType, Duration: w.Content(contentType)})
+	}
+	return windows
+}
+
+// Deadline 编辑截止时间，不限制时返回零值
+func Deadline(window time.Duration, since time.Time) time.Time {
+	if window <= 0 {
+		return time.Time{}
+	}
+	return since.Add(window)
+}
+
+// Check 判断从since起算的编辑是否仍在时限内，now应为服务端当前时间；超过时限返回ErrExpired
+func Check(window time.Duration, since, now time.Time) error {
+	if window <= 0 {
+		return nil
+	}
+	if now.Sub(since) > window {
+		return ErrExpired
+	}
+	return nil
+}
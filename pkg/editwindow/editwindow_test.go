@@ -0,0 +1,85 @@
+package editwindow
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"goim-social/pkg/config"
+)
+
+// TestDefaults 未配置时使用默认时限
+func TestDefaults(t *testing.T) {
+	var w Windows
+	if w.Content("text") != DefaultContentWindow || w.Comment() != DefaultCommentWindow || w.Message() != DefaultMessageWindow {
+		t.Fatalf("默认时限不正确: content=%v comment=%v message=%v", w.Content("text"), w.Comment(), w.Message())
+	}
+}
+
+// TestConfigured 配置的秒数生效，负数表示不限制，内容类型覆盖优先
+func TestConfigured(t *testing.T) {
+	w := New(config.EditWindowConfig{
+		Content:      600,
+		Comment:      -1,
+		Message:      60,
+		ContentTypes: map[string]int{"video": 3600, "template": -1},
+	})
+	if got := w.Content("text"); got != 10*time.Minute {
+		t.Fatalf("内容时限 = %v", got)
+	}
+	if got := w.Content("video"); got != time.Hour {
+		t.Fatalf("视频内容时限 = %v", got)
+	}
+	if got := w.Content("template"); got != 0 {
+		t.Fatalf("模板内容应不限制: %v", got)
+	}
+	if got := w.Comment(); got != 0 {
+		t.Fatalf("评论应不限制: %v", got)
+	}
+	if got := w.Message(); got != time.Minute {
+		t.Fatalf("消息时限 = %v", got)
+	}
+}
+
+// TestAllOrder 基础时限在前，内容类型覆盖项按类型排序
+func TestAllOrder(t *testing.T) {
+	w := New(config.EditWindowConfig{ContentTypes: map[string]int{"video": 3600, "audio": 900}})
+	all := w.All()
+	want := []Window{
+		{Kind: KindContent, Duration: DefaultContentWindow},
+		{Kind: KindComment, Duration: DefaultCommentWindow},
+		{Kind: KindMessage, Duration: DefaultMessageWindow},
+		{Kind: KindContent, ContentType: "audio", Duration: 15 * time.Minute},
+		{Kind: KindContent, ContentType: "video", Duration: time.Hour},
+	}
+	if len(all) != len(want) {
+		t.Fatalf("时限数量 = %d, want %d", len(all), len(want))
+	}
+	for i := range want {
+		if all[i] != want[i] {
+			t.Fatalf("第%d项 = %+v, want %+v", i, all[i], want[i])
+		}
+	}
+}
+
+// TestCheck 恰好到截止时间仍可编辑，超过后返回ErrExpired，不限制时总是可编辑
+func TestCheck(t *testing.T) {
+	since := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	window := 5 * time.Minute
+
+	if err := Check(window, since, since.Add(window)); err != nil {
+		t.Fatalf("截止时间当刻应可编辑: %v", err)
+	}
+	if err := Check(window, since, since.Add(window+time.Second)); !errors.Is(err, ErrExpired) {
+		t.Fatalf("超过时限应返回ErrExpired: %v", err)
+	}
+	if err := Check(0, since, since.Add(24*time.Hour)); err != nil {
+		t.Fatalf("不限制时应可编辑: %v", err)
+	}
+	if got := Deadline(window, since); !got.Equal(since.Add(window)) {
+		t.Fatalf("截止时间 = %v", got)
+	}
+	if got := Deadline(0, since); !got.IsZero() {
+		t.Fatalf("不限制时截止时间应为零值: %v", got)
+	}
+}
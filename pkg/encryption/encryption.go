@@ -0,0 +1,150 @@
+package encryption
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"strings"
+
+	"goim-social/pkg/config"
+)
+
+// Encryptor 内容加解密接口，用于敏感数据静态加密
+type Encryptor interface {
+	// Encrypt 使用当前密钥加密，返回密文和密钥ID；未启用加密时原样返回且密钥ID为空
+	Encrypt(plaintext string) (ciphertext string, keyID string, err error)
+	// Decrypt 使用指定密钥ID解密；密钥ID为空表示明文存储
+	Decrypt(ciphertext string, keyID string) (string, error)
+}
+
+// NewFromConfig 根据配置创建加密器，未启用时返回不加密的实现
+func NewFromConfig(cfg config.EncryptionConfig) (Encryptor, error) {
+	if !cfg.Enabled {
+		return NewNoopEncryptor(), nil
+	}
+
+	keys, err := ParseKeys(cfg.Keys)
+	if err != nil {
+		return nil, err
+	}
+	return NewAESGCMEncryptor(cfg.ActiveKeyID, keys)
+}
+
+// ParseKeys 解析密钥配置，格式为 "keyID:base64Key,keyID:base64Key"
+func ParseKeys(spec string) (map[string][]byte, error) {
+	keys := make(map[string][]byte)
+	for _, item := range strings.Split(spec, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		parts := strings.SplitN(item, ":", 2)
+		if len(parts) != 2 || parts[0] == "" {
+			return nil, fmt.Errorf("密钥配置格式错误: %s", item)
+		}
+
+		key, err := base64.StdEncoding.DecodeString(parts[1])
+		if err != nil {
+			return nil, fmt.Errorf("密钥 %s 解码失败: %v", parts[0], err)
+		}
+		keys[parts[0]] = key
+	}
+	return keys, nil
+}
+
+// noopEncryptor 不加密的实现
+type noopEncryptor struct{}
+
+// NewNoopEncryptor 创建不加密的加密器
+func NewNoopEncryptor() Encryptor {
+	return noopEncryptor{}
+}
+
+// Encrypt 原样返回
+func (noopEncryptor) Encrypt(plaintext string) (string, string, error) {
+	return plaintext, "", nil
+}
+
+// Decrypt 仅支持明文数据
+func (noopEncryptor) Decrypt(ciphertext string, keyID string) (string, error) {
+	if keyID != "" {
+		return "", fmt.Errorf("未启用加密，无法解密密钥 %s 加密的数据", keyID)
+	}
+	return ciphertext, nil
+}
+
+// AESGCMEncryptor AES-GCM加密器，支持多密钥以便密钥轮换
+// 新数据使用当前密钥加密，旧数据按其密钥ID解密
+type AESGCMEncryptor struct {
+	activeKeyID string
+	aeads       map[string]cipher.AEAD
+}
+
+// NewAESGCMEncryptor 创建AES-GCM加密器，密钥长度须为16/24/32字节
+func NewAESGCMEncryptor(activeKeyID string, keys map[string][]byte) (*AESGCMEncryptor, error) {
+	if _, ok := keys[activeKeyID]; !ok {
+		return nil, fmt.Errorf("当前密钥 %s 未配置", activeKeyID)
+	}
+
+	aeads := make(map[string]cipher.AEAD, len(keys))
+	for keyID, key := range keys {
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, fmt.Errorf("密钥 %s 无效: %v", keyID, err)
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, fmt.Errorf("密钥 %s 初始化GCM失败: %v", keyID, err)
+		}
+		aeads[keyID] = aead
+	}
+
+	return &AESGCMEncryptor{
+		activeKeyID: activeKeyID,
+		aeads:       aeads,
+	}, nil
+}
+
+// Encrypt 使用当前密钥加密，密文格式为 base64(nonce + sealed)
+func (e *AESGCMEncryptor) Encrypt(plaintext string) (string, string, error) {
+	aead := e.aeads[e.activeKeyID]
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", "", fmt.Errorf("生成nonce失败: %v", err)
+	}
+
+	sealed := aead.Seal(nonce, nonce, []byte(plaintext), nil)
+	return base64.StdEncoding.EncodeToString(sealed), e.activeKeyID, nil
+}
+
+// Decrypt 使用指定密钥ID解密
+func (e *AESGCMEncryptor) Decrypt(ciphertext string, keyID string) (string, error) {
+	if keyID == "" {
+		return ciphertext, nil
+	}
+
+	aead, ok := e.aeads[keyID]
+	if !ok {
+		return "", fmt.Errorf("密钥 %s 未配置", keyID)
+	}
+
+	data, err := base64.StdEncoding.DecodeString(ciphertext)
+	if err != nil {
+		return "", fmt.Errorf("密文解码失败: %v", err)
+	}
+	if len(data) < aead.NonceSize() {
+		return "", fmt.Errorf("密文长度无效")
+	}
+
+	nonce, sealed := data[:aead.NonceSize()], data[aead.NonceSize():]
+	plaintext, err := aead.Open(nil, nonce, sealed, nil)
+	if err != nil {
+		return "", fmt.Errorf("解密失败: %v", err)
+	}
+	return string(plaintext), nil
+}
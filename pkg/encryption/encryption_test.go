@@ -0,0 +1,64 @@
+package encryption
+
+import (
+	"bytes"
+	"encoding/base64"
+	"testing"
+)
+
+// TestAESGCMRoundTrip 测试加密解密往返
+func TestAESGCMRoundTrip(t *testing.T) {
+	enc, err := NewAESGCMEncryptor("k1", map[string][]byte{"k1": bytes.Repeat([]byte{1}, 32)})
+	if err != nil {
+		t.Fatalf("创建加密器失败: %v", err)
+	}
+
+	ciphertext, keyID, err := enc.Encrypt("hello 世界")
+	if err != nil {
+		t.Fatalf("加密失败: %v", err)
+	}
+	if keyID != "k1" || ciphertext == "hello 世界" {
+		t.Fatalf("加密结果异常: keyID=%s, ciphertext=%s", keyID, ciphertext)
+	}
+
+	plaintext, err := enc.Decrypt(ciphertext, keyID)
+	if err != nil {
+		t.Fatalf("解密失败: %v", err)
+	}
+	if plaintext != "hello 世界" {
+		t.Fatalf("解密结果不一致: %s", plaintext)
+	}
+}
+
+// TestAESGCMKeyRotation 测试密钥轮换后旧数据仍可解密
+func TestAESGCMKeyRotation(t *testing.T) {
+	oldKey := bytes.Repeat([]byte{1}, 32)
+	newKey := bytes.Repeat([]byte{2}, 32)
+
+	oldEnc, _ := NewAESGCMEncryptor("k1", map[string][]byte{"k1": oldKey})
+	ciphertext, keyID, _ := oldEnc.Encrypt("rotated")
+
+	spec := "k1:" + base64.StdEncoding.EncodeToString(oldKey) + ",k2:" + base64.StdEncoding.EncodeToString(newKey)
+	keys, err := ParseKeys(spec)
+	if err != nil {
+		t.Fatalf("解析密钥失败: %v", err)
+	}
+	newEnc, err := NewAESGCMEncryptor("k2", keys)
+	if err != nil {
+		t.Fatalf("创建加密器失败: %v", err)
+	}
+
+	plaintext, err := newEnc.Decrypt(ciphertext, keyID)
+	if err != nil || plaintext != "rotated" {
+		t.Fatalf("轮换后解密旧数据失败: %v, %s", err, plaintext)
+	}
+
+	if _, keyID, _ := newEnc.Encrypt("new"); keyID != "k2" {
+		t.Fatalf("新数据应使用当前密钥加密, got %s", keyID)
+	}
+
+	// 明文数据（无密钥ID）原样返回
+	if plaintext, _ := newEnc.Decrypt("plain", ""); plaintext != "plain" {
+		t.Fatalf("明文数据解密异常: %s", plaintext)
+	}
+}
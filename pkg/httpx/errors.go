@@ -0,0 +1,67 @@
+package httpx
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// statusError 携带HTTP状态码的错误，错误信息保持不变
+type statusError struct {
+	status int
+	err    error
+}
+
+func (e *statusError) Error() string { return e.err.Error() }
+
+func (e *statusError) Unwrap() error { return e.err }
+
+// InvalidArgument 标记请求本身有误（参数缺失、查询语法错误、游标无效等），响应400
+func InvalidArgument(err error) error {
+	return withStatus(http.StatusBadRequest, err)
+}
+
+// Unavailable 标记后端依赖故障（数据库、搜索引擎不可用等），响应503，客户端可稍后重试
+func Unavailable(err error) error {
+	return withStatus(http.StatusServiceUnavailable, err)
+}
+
+// PermissionDenied 标记调用方无权执行该操作（如被对方拉黑），响应403
+func PermissionDenied(err error) error {
+	return withStatus(http.StatusForbidden, err)
+}
+
+// Conflict 标记操作与当前状态冲突（如重复申请、关系已存在），响应409
+func Conflict(err error) error {
+	return withStatus(http.StatusConflict, err)
+}
+
+// TooManyRequests 标记调用方超出频率限制，响应429，客户端应稍后重试
+func TooManyRequests(err error) error {
+	return withStatus(http.StatusTooManyRequests, err)
+}
+
+func withStatus(status int, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &statusError{status: status, err: err}
+}
+
+// StatusOf 返回错误对应的HTTP状态码，nil为200，超过截止时间为504，未标记的错误按请求错误处理
+func StatusOf(err error) int {
+	if err == nil {
+		return http.StatusOK
+	}
+	if errors.Is(err, context.DeadlineExceeded) || status.Code(err) == codes.DeadlineExceeded {
+		return http.StatusGatewayTimeout
+	}
+	var se *statusError
+	if errors.As(err, &se) {
+		return se.status
+	}
+	return http.StatusBadRequest
+}
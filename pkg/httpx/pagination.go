@@ -0,0 +1,52 @@
+package httpx
+
+import "goim-social/api/rest"
+
+// PageMeta 列表响应的分页元数据，各服务的列表接口统一用它生成响应中的pagination字段，
+// 客户端只需根据HasMore和NextCursor判断是否继续加载，不必关心接口是页码分页还是游标分页
+type PageMeta struct {
+	Page       int32  // 当前页，从1开始
+	PageSize   int32  // 每页数量
+	Total      int64  // 总数，无法统计时为0
+	HasMore    bool   // 是否还有下一页
+	NextCursor string // 下一页游标，页码分页或已到末尾时为空
+}
+
+// OffsetPage 页码分页的元数据，前page页的数量少于总数时还有下一页；页码小于1按第1页处理
+func OffsetPage(page, pageSize int32, total int64) PageMeta {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 0 {
+		pageSize = 0
+	}
+	if total < 0 {
+		total = 0
+	}
+	return PageMeta{
+		Page:     page,
+		PageSize: pageSize,
+		Total:    total,
+		HasMore:  pageSize > 0 && int64(page)*int64(pageSize) < total,
+	}
+}
+
+// CursorPage 游标分页的元数据，是否有下一页以游标为准：去重、过滤后本页数量可能少于每页数量，
+// 总数也可能随数据变化，都不能用来判断是否到末尾
+func CursorPage(page, pageSize int32, total int64, nextCursor string) PageMeta {
+	meta := OffsetPage(page, pageSize, total)
+	meta.HasMore = nextCursor != ""
+	meta.NextCursor = nextCursor
+	return meta
+}
+
+// Proto 转换为响应中的protobuf消息
+func (m PageMeta) Proto() *rest.PageMeta {
+	return &rest.PageMeta{
+		Page:       m.Page,
+		PageSize:   m.PageSize,
+		Total:      m.Total,
+		HasMore:    m.HasMore,
+		NextCursor: m.NextCursor,
+	}
+}
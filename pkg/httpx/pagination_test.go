@@ -0,0 +1,76 @@
+package httpx
+
+import "testing"
+
+// TestOffsetPageBoundaries 最后一页、恰好整页、超出末尾和空列表时HasMore正确
+func TestOffsetPageBoundaries(t *testing.T) {
+	cases := []struct {
+		name     string
+		page     int32
+		pageSize int32
+		total    int64
+		hasMore  bool
+	}{
+		{"空列表", 1, 20, 0, false},
+		{"第一页未满", 1, 20, 5, false},
+		{"恰好一整页", 1, 20, 20, false},
+		{"多一条", 1, 20, 21, true},
+		{"最后一页未满", 2, 20, 21, false},
+		{"中间页", 2, 10, 35, true},
+		{"恰好到末尾", 3, 10, 30, false},
+		{"超出末尾", 5, 10, 30, false},
+		{"每页数量为0", 1, 0, 30, false},
+	}
+	for _, c := range cases {
+		meta := OffsetPage(c.page, c.pageSize, c.total)
+		if meta.HasMore != c.hasMore {
+			t.Fatalf("%s: OffsetPage(%d, %d, %d).HasMore = %v, want %v", c.name, c.page, c.pageSize, c.total, meta.HasMore, c.hasMore)
+		}
+		if meta.Page != c.page || meta.PageSize != c.pageSize || meta.Total != c.total || meta.NextCursor != "" {
+			t.Fatalf("%s: 元数据与输入不一致: %+v", c.name, meta)
+		}
+	}
+}
+
+// TestOffsetPageNormalizes 页码小于1按第1页处理，负的每页数量和总数按0处理
+func TestOffsetPageNormalizes(t *testing.T) {
+	meta := OffsetPage(0, 10, 15)
+	if meta.Page != 1 || !meta.HasMore {
+		t.Fatalf("页码0应按第1页处理: %+v", meta)
+	}
+
+	meta = OffsetPage(-3, -1, -5)
+	if meta.Page != 1 || meta.PageSize != 0 || meta.Total != 0 || meta.HasMore {
+		t.Fatalf("非法参数未规范: %+v", meta)
+	}
+}
+
+// TestOffsetPageLargeValues 页码和每页数量相乘不溢出
+func TestOffsetPageLargeValues(t *testing.T) {
+	meta := OffsetPage(1<<30, 1<<30, 1<<62)
+	if !meta.HasMore {
+		t.Fatalf("页码和每页数量相乘溢出: %+v", meta)
+	}
+}
+
+// TestCursorPageFollowsCursor 游标分页以下一页游标判断是否还有更多，与本页数量和总数无关
+func TestCursorPageFollowsCursor(t *testing.T) {
+	meta := CursorPage(1, 20, 100, "")
+	if meta.HasMore || meta.NextCursor != "" {
+		t.Fatalf("游标为空时不应有下一页: %+v", meta)
+	}
+
+	meta = CursorPage(3, 20, 40, "eyJvIjo2MH0")
+	if !meta.HasMore || meta.NextCursor != "eyJvIjo2MH0" || meta.Total != 40 || meta.Page != 3 {
+		t.Fatalf("游标非空时应有下一页: %+v", meta)
+	}
+}
+
+// TestPageMetaProto 转换后的protobuf消息与元数据一致
+func TestPageMetaProto(t *testing.T) {
+	meta := CursorPage(2, 10, 25, "next")
+	msg := meta.Proto()
+	if msg.Page != 2 || msg.PageSize != 10 || msg.Total != 25 || !msg.HasMore || msg.NextCursor != "next" {
+		t.Fatalf("转换结果不一致: %+v", msg)
+	}
+}
@@ -8,12 +8,9 @@ import (
 	"google.golang.org/protobuf/proto"
 )
 
-// WriteObject 兼容protobuf和json
+// WriteObject 兼容protobuf和json，状态码由err决定，见StatusOf
 func WriteObject(c *gin.Context, obj interface{}, err error) {
-	status := http.StatusOK
-	if err != nil {
-		status = http.StatusBadRequest
-	}
+	status := StatusOf(err)
 
 	switch c.ContentType() {
 	case binding.MIMEPROTOBUF:
@@ -0,0 +1,90 @@
+package httpx
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"goim-social/api/rest"
+)
+
+// write 调用WriteObject并返回状态码和JSON响应体
+func write(t *testing.T, obj interface{}, err error) (int, map[string]interface{}) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPost, "/", nil)
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	WriteObject(c, obj, err)
+
+	var body map[string]interface{}
+	if decodeErr := json.Unmarshal(w.Body.Bytes(), &body); decodeErr != nil {
+		t.Fatalf("响应体不是JSON: %v, body=%s", decodeErr, w.Body.String())
+	}
+	return w.Code, body
+}
+
+// TestEmptyResultIsSuccess 没有结果时返回200和success=true，结果列表为空
+func TestEmptyResultIsSuccess(t *testing.T) {
+	status, body := write(t, &rest.GetCommentsResponse{Success: true, Message: "获取评论列表成功", Comments: []*rest.Comment{}}, nil)
+	if status != http.StatusOK || body["success"] != true {
+		t.Fatalf("空结果应为成功响应: status=%d, body=%v", status, body)
+	}
+
+	status, body = write(t, map[string]interface{}{
+		"code":    0,
+		"message": "success",
+		"data":    map[string]interface{}{"results": []interface{}{}, "total": 0},
+	}, nil)
+	results, ok := body["data"].(map[string]interface{})["results"].([]interface{})
+	if status != http.StatusOK || !ok || len(results) != 0 {
+		t.Fatalf("空结果应返回空列表而不是null: status=%d, body=%v", status, body)
+	}
+}
+
+// TestErrorStatus 请求错误和后端故障返回不同的状态码，错误信息保持不变
+func TestErrorStatus(t *testing.T) {
+	backendErr := errors.New("connection refused")
+	cases := []struct {
+		name   string
+		err    error
+		status int
+	}{
+		{"invalid argument", InvalidArgument(errors.New("目标ID无效")), http.StatusBadRequest},
+		{"unavailable", Unavailable(backendErr), http.StatusServiceUnavailable},
+		{"wrapped unavailable", fmt.Errorf("获取评论列表失败: %w", Unavailable(backendErr)), http.StatusServiceUnavailable},
+		{"permission denied", PermissionDenied(errors.New("对方已拉黑")), http.StatusForbidden},
+		{"conflict", Conflict(errors.New("已有待处理的好友申请")), http.StatusConflict},
+		{"too many requests", TooManyRequests(errors.New("好友申请过于频繁")), http.StatusTooManyRequests},
+		{"unclassified", errors.New("bind failed"), http.StatusBadRequest},
+		{"deadline exceeded", fmt.Errorf("获取评论列表失败: %w", context.DeadlineExceeded), http.StatusGatewayTimeout},
+		{"grpc deadline exceeded", status.Error(codes.DeadlineExceeded, "调用/rest.UserService/BatchGetUsers超时"), http.StatusGatewayTimeout},
+	}
+
+	for _, tc := range cases {
+		status, body := write(t, &rest.GetCommentsResponse{Success: false, Message: tc.err.Error()}, tc.err)
+		if status != tc.status {
+			t.Fatalf("%s: 状态码应为%d，实际%d", tc.name, tc.status, status)
+		}
+		if body["success"] == true {
+			t.Fatalf("%s: 错误响应不应为success", tc.name)
+		}
+	}
+
+	if !errors.Is(Unavailable(backendErr), backendErr) {
+		t.Fatalf("Unavailable应保留原始错误")
+	}
+	if Unavailable(nil) != nil || StatusOf(nil) != http.StatusOK {
+		t.Fatalf("nil错误应视为成功")
+	}
+}
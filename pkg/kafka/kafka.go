@@ -2,7 +2,10 @@ package kafka
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/IBM/sarama"
@@ -29,6 +32,12 @@ type Producer struct {
 	retryQueue    chan *RetryMessage
 	maxRetries    int
 	retryDelay    time.Duration
+
+	mu       sync.RWMutex
+	closing  bool           // 开始关闭，不再接受新消息
+	closed   bool           // 异步生产者已关闭，重试消息不再重新发送
+	pending  atomic.Int64   // 已提交但尚未确认送达或最终丢弃的消息数
+	handlers sync.WaitGroup // 成功和错误处理goroutine
 }
 
 // ReliableProducer 高可靠性同步生产者
@@ -62,6 +71,11 @@ func InitProducer(brokers []string) (*Producer, error) {
 		return nil, err
 	}
 
+	return newProducer(producer), nil
+}
+
+// newProducer 包装异步生产者并启动结果处理goroutine，异步生产者须开启Return.Successes和Return.Errors
+func newProducer(producer sarama.AsyncProducer) *Producer {
 	p := &Producer{
 		asyncProducer: producer,
 		retryQueue:    make(chan *RetryMessage, 1000), // 重试队列
@@ -69,6 +83,8 @@ func InitProducer(brokers []string) (*Producer, error) {
 		retryDelay:    2 * time.Second,                // 重试延迟
 	}
 
+	p.handlers.Add(2)
+
 	// 启动错误处理和重试goroutine
 	go p.handleErrors()
 
@@ -78,7 +94,7 @@ func InitProducer(brokers []string) (*Producer, error) {
 	// 启动重试处理goroutine
 	go p.handleRetries()
 
-	return p, nil
+	return p
 }
 
 // InitReliableProducer 初始化高可靠性同步生产者（用于持久化保障）
@@ -111,10 +127,17 @@ func InitReliableProducer(brokers []string) (*ReliableProducer, error) {
 
 // handleErrors 处理发送错误，将失败消息加入重试队列
 func (p *Producer) handleErrors() {
+	defer p.handlers.Done()
+
 	for err := range p.asyncProducer.Errors() {
 		fmt.Printf("Kafka Producer错误: %v, topic=%s, partition=%d\n",
 			err.Err, err.Msg.Topic, err.Msg.Partition)
 
+		if p.isClosed() {
+			p.drop(err.Msg, "生产者已关闭")
+			continue
+		}
+
 		// 创建重试消息
 		retryMsg := &RetryMessage{
 			Message:     err.Msg,
@@ -127,14 +150,17 @@ func (p *Producer) handleErrors() {
 		case p.retryQueue <- retryMsg:
 			fmt.Printf("消息已加入重试队列: topic=%s\n", err.Msg.Topic)
 		default:
-			fmt.Printf("重试队列已满，消息丢弃: topic=%s\n", err.Msg.Topic)
+			p.drop(err.Msg, "重试队列已满")
 		}
 	}
 }
 
 // handleSuccesses 处理发送成功
 func (p *Producer) handleSuccesses() {
+	defer p.handlers.Done()
+
 	for success := range p.asyncProducer.Successes() {
+		p.pending.Add(-1)
 		fmt.Printf("Kafka消息发送成功: topic=%s, partition=%d, offset=%d\n",
 			success.Topic, success.Partition, success.Offset)
 	}
@@ -145,8 +171,11 @@ func (p *Producer) handleRetries() {
 	for retryMsg := range p.retryQueue {
 		// 检查是否超过最大重试次数
 		if retryMsg.RetryCount >= p.maxRetries {
-			fmt.Printf("消息重试次数超限，最终丢弃: topic=%s, retries=%d\n",
-				retryMsg.Message.Topic, retryMsg.RetryCount)
+			p.drop(retryMsg.Message, fmt.Sprintf("重试%d次仍失败", retryMsg.RetryCount))
+			continue
+		}
+		if p.isClosed() {
+			p.drop(retryMsg.Message, "生产者已关闭")
 			continue
 		}
 
@@ -160,13 +189,37 @@ func (p *Producer) handleRetries() {
 		fmt.Printf("重试发送消息: topic=%s, attempt=%d/%d\n",
 			retryMsg.Message.Topic, retryMsg.RetryCount, p.maxRetries)
 
-		// 重新发送消息
+		// 重新发送消息，异步生产者已关闭时丢弃
+		p.mu.RLock()
+		if p.closed {
+			p.mu.RUnlock()
+			p.drop(retryMsg.Message, "生产者已关闭")
+			continue
+		}
 		p.asyncProducer.Input() <- retryMsg.Message
+		p.mu.RUnlock()
 	}
 }
 
-// SendMessage 发送消息
+// drop 最终丢弃一条未送达的消息并记录日志
+func (p *Producer) drop(msg *sarama.ProducerMessage, reason string) {
+	p.pending.Add(-1)
+	fmt.Printf("Kafka消息丢弃(%s): topic=%s\n", reason, msg.Topic)
+}
+
+// isClosed 异步生产者是否已关闭
+func (p *Producer) isClosed() bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.closed
+}
+
+// SendMessage 发送消息，Kafka降级不可用（Producer为nil）时返回错误
 func (p *Producer) SendMessage(topic string, key, value []byte) error {
+	if p == nil {
+		return fmt.Errorf("Kafka不可用，消息未发送: topic=%s", topic)
+	}
+
 	msg := &sarama.ProducerMessage{
 		Topic: topic,
 		Key:   sarama.ByteEncoder(key),
@@ -175,7 +228,14 @@ func (p *Producer) SendMessage(topic string, key, value []byte) error {
 
 	fmt.Printf("准备发送消息到topic: %s, 消息大小: %d bytes\n", topic, len(value))
 
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if p.closing {
+		return fmt.Errorf("Kafka生产者已关闭，消息未发送: topic=%s", topic)
+	}
+
 	// 发送消息到异步队列
+	p.pending.Add(1)
 	p.asyncProducer.Input() <- msg
 	fmt.Printf("消息已提交到异步队列\n")
 
@@ -192,17 +252,113 @@ func (p *Producer) PublishMessage(topic string, msg proto.Message) error {
 	return p.SendMessage(topic, nil, protoData)
 }
 
-// Close 关闭生产者
+// PublishMessageWithKey 按key分区发送protobuf消息，相同key的消息进入同一分区，消费顺序与发送顺序一致
+func (p *Producer) PublishMessageWithKey(topic string, key []byte, msg proto.Message) error {
+	protoData, err := proto.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("protobuf序列化失败: %v", err)
+	}
+
+	return p.SendMessage(topic, key, protoData)
+}
+
+// Flush 等待已提交的消息全部送达或最终丢弃，ctx结束时返回仍未送达的消息数
+// 失败消息的重试同样计入等待，Flush期间仍可提交新消息
+func (p *Producer) Flush(ctx context.Context) error {
+	if p == nil {
+		return nil
+	}
+
+	ticker := time.NewTicker(10 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		if p.pending.Load() <= 0 {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("Kafka生产者刷新未完成，%d条消息未送达: %w", p.pending.Load(), ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
+
+// Shutdown 优雅关闭生产者：拒绝新消息，在ctx截止前等待已提交的消息送达后关闭
+// 超时仍未送达的消息被丢弃并记录日志，ctx通常由退出时限约束
+func (p *Producer) Shutdown(ctx context.Context) error {
+	if p == nil {
+		return nil
+	}
+	if !p.markClosing() {
+		return nil
+	}
+
+	flushErr := p.Flush(ctx)
+	if flushErr != nil {
+		fmt.Printf("Kafka生产者退出时丢弃未送达的消息: %v\n", flushErr)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- p.close()
+	}()
+
+	select {
+	case err := <-done:
+		if flushErr != nil {
+			return flushErr
+		}
+		return err
+	case <-ctx.Done():
+		if flushErr != nil {
+			return flushErr
+		}
+		return fmt.Errorf("Kafka生产者关闭超时: %w", ctx.Err())
+	}
+}
+
+// Close 立即关闭生产者，不等待重试中的消息
 func (p *Producer) Close() error {
-	// 关闭重试队列
-	close(p.retryQueue)
+	if p == nil {
+		return nil
+	}
+	if !p.markClosing() {
+		return nil
+	}
+	return p.close()
+}
+
+// markClosing 标记开始关闭，已在关闭时返回false
+func (p *Producer) markClosing() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closing {
+		return false
+	}
+	p.closing = true
+	return true
+}
+
+// close 关闭异步生产者，等待结果处理goroutine退出后关闭重试队列
+func (p *Producer) close() error {
+	p.mu.Lock()
+	p.closed = true
+	p.mu.Unlock()
 
-	// 关闭异步生产者
-	return p.asyncProducer.Close()
+	// 关闭异步生产者，缓冲中的消息发送完成后关闭Successes和Errors通道
+	p.asyncProducer.AsyncClose()
+	p.handlers.Wait()
+
+	// 关闭重试队列，剩余的重试消息被丢弃
+	close(p.retryQueue)
+	return nil
 }
 
 // GetRetryQueueSize 获取重试队列大小（用于监控）
 func (p *Producer) GetRetryQueueSize() int {
+	if p == nil {
+		return 0
+	}
 	return len(p.retryQueue)
 }
 
@@ -229,6 +385,10 @@ func (c *Consumer) StartConsuming(ctx context.Context) error {
 		for {
 			fmt.Printf("消费者开始新的消费循环...\n")
 			if err := c.group.Consume(ctx, c.topics, c); err != nil {
+				if errors.Is(err, sarama.ErrClosedConsumerGroup) {
+					fmt.Printf("消费者已关闭，退出\n")
+					return
+				}
 				fmt.Printf("消费者错误: %v\n", err)
 			}
 			if ctx.Err() != nil {
@@ -243,6 +403,11 @@ func (c *Consumer) StartConsuming(ctx context.Context) error {
 	return nil
 }
 
+// Close 关闭消费者，等待进行中的消息处理完成并提交已标记的位移
+func (c *Consumer) Close() error {
+	return c.group.Close()
+}
+
 // Setup sarama.ConsumerGroupHandler
 func (c *Consumer) Setup(_ sarama.ConsumerGroupSession) error {
 	close(c.ready)
@@ -0,0 +1,66 @@
+package kafka
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/IBM/sarama"
+	"github.com/IBM/sarama/mocks"
+)
+
+// newTestProducer 创建使用模拟异步生产者的Producer，每条消息延迟delay后确认送达
+func newTestProducer(t *testing.T, delay time.Duration, messages int) *Producer {
+	config := sarama.NewConfig()
+	config.Producer.Return.Successes = true
+	config.Producer.Return.Errors = true
+
+	mock := mocks.NewAsyncProducer(t, config)
+	for i := 0; i < messages; i++ {
+		mock.ExpectInputWithMessageCheckerFunctionAndSucceed(func(*sarama.ProducerMessage) error {
+			time.Sleep(delay)
+			return nil
+		})
+	}
+	return newProducer(mock)
+}
+
+// TestShutdownFlushesPendingMessages 已提交但尚未确认的消息在Shutdown返回前送达
+func TestShutdownFlushesPendingMessages(t *testing.T) {
+	p := newTestProducer(t, 100*time.Millisecond, 3)
+	for i := 0; i < 3; i++ {
+		if err := p.SendMessage("test", nil, []byte("event")); err != nil {
+			t.Fatalf("发送消息失败: %v", err)
+		}
+	}
+	if n := p.pending.Load(); n == 0 {
+		t.Fatalf("提交后应有未确认的消息")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := p.Shutdown(ctx); err != nil {
+		t.Fatalf("Shutdown失败: %v", err)
+	}
+	if n := p.pending.Load(); n != 0 {
+		t.Fatalf("Shutdown返回时仍有%d条消息未送达", n)
+	}
+
+	if err := p.SendMessage("test", nil, []byte("event")); err == nil {
+		t.Fatalf("关闭后发送消息应返回错误")
+	}
+}
+
+// TestShutdownReportsUndelivered 超过退出时限仍未送达的消息数通过错误返回
+func TestShutdownReportsUndelivered(t *testing.T) {
+	p := newTestProducer(t, 500*time.Millisecond, 1)
+	if err := p.SendMessage("test", nil, []byte("event")); err != nil {
+		t.Fatalf("发送消息失败: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := p.Shutdown(ctx); err == nil {
+		t.Fatalf("超时未送达时Shutdown应返回错误")
+	}
+}
@@ -2,6 +2,7 @@ package lifecycle
 
 import (
 	"context"
+	"fmt"
 	"os"
 	"os/signal"
 	"sync"
@@ -11,15 +12,19 @@ import (
 	kratoslog "github.com/go-kratos/kratos/v2/log"
 )
 
+// DefaultShutdownTimeout 默认优雅退出超时时间
+const DefaultShutdownTimeout = 30 * time.Second
+
 // LifecycleManager 生命周期管理器
 type LifecycleManager struct {
-	logger   kratoslog.Logger
-	hooks    []Hook
-	mu       sync.RWMutex
-	ctx      context.Context
-	cancel   context.CancelFunc
-	done     chan struct{}
-	stopOnce sync.Once
+	logger          kratoslog.Logger
+	hooks           []Hook
+	mu              sync.RWMutex
+	ctx             context.Context
+	cancel          context.CancelFunc
+	done            chan struct{}
+	stopOnce        sync.Once
+	shutdownTimeout time.Duration // 所有钩子停止的总时限
 }
 
 // Hook 生命周期钩子
@@ -35,16 +40,20 @@ type Hook struct {
 	// 300+:    业务逻辑层
 }
 
-// NewLifecycleManager 创建生命周期管理器
-func NewLifecycleManager(logger kratoslog.Logger) *LifecycleManager {
+// NewLifecycleManager 创建生命周期管理器，shutdownTimeout不大于0时使用默认值
+func NewLifecycleManager(logger kratoslog.Logger, shutdownTimeout time.Duration) *LifecycleManager {
 	ctx, cancel := context.WithCancel(context.Background())
+	if shutdownTimeout <= 0 {
+		shutdownTimeout = DefaultShutdownTimeout
+	}
 
 	return &LifecycleManager{
-		logger: logger,
-		hooks:  make([]Hook, 0),
-		ctx:    ctx,
-		cancel: cancel,
-		done:   make(chan struct{}),
+		logger:          logger,
+		hooks:           make([]Hook, 0),
+		ctx:             ctx,
+		cancel:          cancel,
+		done:            make(chan struct{}),
+		shutdownTimeout: shutdownTimeout,
 	}
 }
 
@@ -94,6 +103,7 @@ func (lm *LifecycleManager) Start() error {
 }
 
 // Stop 停止所有钩子
+// 所有钩子共享同一个截止时间，超时后不再等待仍在执行的钩子，记录未完成的钩子后直接返回
 func (lm *LifecycleManager) Stop() error {
 	var stopErr error
 
@@ -101,19 +111,30 @@ func (lm *LifecycleManager) Stop() error {
 		lm.mu.RLock()
 		defer lm.mu.RUnlock()
 
-		lm.logger.Log(kratoslog.LevelInfo, "msg", "Stopping lifecycle hooks")
+		lm.logger.Log(kratoslog.LevelInfo, "msg", "Stopping lifecycle hooks", "timeout", lm.shutdownTimeout.String())
 
 		// 创建带超时的上下文
-		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		ctx, cancel := context.WithTimeout(context.Background(), lm.shutdownTimeout)
 		defer cancel()
 
 		// 反向停止钩子（后启动的先停止）
+	stopLoop:
 		for i := len(lm.hooks) - 1; i >= 0; i-- {
 			hook := lm.hooks[i]
-			if hook.OnStop != nil {
-				lm.logger.Log(kratoslog.LevelInfo, "msg", "Stopping hook", "name", hook.Name)
+			if hook.OnStop == nil {
+				continue
+			}
+
+			lm.logger.Log(kratoslog.LevelInfo, "msg", "Stopping hook", "name", hook.Name)
+
+			errCh := make(chan error, 1)
+			go func() {
+				errCh <- hook.OnStop(ctx)
+			}()
 
-				if err := hook.OnStop(ctx); err != nil {
+			select {
+			case err := <-errCh:
+				if err != nil {
 					lm.logger.Log(kratoslog.LevelError, "msg", "Hook stop failed", "name", hook.Name, "error", err)
 					if stopErr == nil {
 						stopErr = err
@@ -121,6 +142,14 @@ func (lm *LifecycleManager) Stop() error {
 				} else {
 					lm.logger.Log(kratoslog.LevelInfo, "msg", "Hook stopped successfully", "name", hook.Name)
 				}
+			case <-ctx.Done():
+				pending := lm.pendingHooks(i)
+				lm.logger.Log(kratoslog.LevelError, "msg", "Shutdown deadline exceeded, forcing exit",
+					"timeout", lm.shutdownTimeout.String(), "pending", pending)
+				if stopErr == nil {
+					stopErr = fmt.Errorf("shutdown deadline exceeded, pending hooks: %v", pending)
+				}
+				break stopLoop
 			}
 		}
 
@@ -134,6 +163,17 @@ func (lm *LifecycleManager) Stop() error {
 	return stopErr
 }
 
+// pendingHooks 返回截止时间到达时尚未完成停止的钩子名称，从正在停止的钩子开始按停止顺序排列
+func (lm *LifecycleManager) pendingHooks(current int) []string {
+	var pending []string
+	for i := current; i >= 0; i-- {
+		if lm.hooks[i].OnStop != nil {
+			pending = append(pending, lm.hooks[i].Name)
+		}
+	}
+	return pending
+}
+
 // Wait 等待停止信号
 func (lm *LifecycleManager) Wait() {
 	// 监听系统信号
@@ -0,0 +1,56 @@
+package membership
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"goim-social/api/rest"
+)
+
+// ============ 群消息可见范围 ============
+// 用户只能看到在群期间的群消息：按社交服务记录的成员身份时段生成每个群的时间窗口，退群后重新入群的
+// 用户有多个窗口，退群期间的消息不可见。消息服务（同步、会话内搜索）和搜索服务共用同一套窗口计算
+
+// Window 用户在群内可见消息的时间窗口，对应一段成员身份时段，时间为零值表示不限
+type Window struct {
+	GroupID int64 // 窗口所属的群组，跨群查询时按群组区分
+	Start   time.Time
+	End     time.Time
+}
+
+// Windows 将成员身份时段转换为可见消息的时间窗口
+// 群组允许新成员查看历史时窗口从群组创建开始，否则从入群时间开始；已退群的时段到退群时间为止
+func Windows(periods []*rest.GroupMembershipPeriod) []Window {
+	windows := make([]Window, 0, len(periods))
+	for _, period := range periods {
+		window := Window{GroupID: period.GroupId}
+		if !period.HistoryVisible && period.JoinedAt > 0 {
+			window.Start = time.UnixMilli(period.JoinedAt)
+		}
+		if period.LeftAt > 0 {
+			window.End = time.UnixMilli(period.LeftAt)
+		}
+		windows = append(windows, window)
+	}
+	return windows
+}
+
+// Fetch 查询用户的成员身份时段并计算时间窗口，groupID为0时返回用户在所有群组的窗口
+func Fetch(ctx context.Context, social rest.SocialServiceClient, userID, groupID int64) ([]Window, error) {
+	if social == nil {
+		return nil, fmt.Errorf("社交服务不可用")
+	}
+
+	resp, err := social.GetMembershipPeriods(ctx, &rest.GetMembershipPeriodsRequest{
+		UserId:  userID,
+		GroupId: groupID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("获取成员身份时段失败: %v", err)
+	}
+	if !resp.Success {
+		return nil, fmt.Errorf("获取成员身份时段失败: %s", resp.Message)
+	}
+	return Windows(resp.Periods), nil
+}
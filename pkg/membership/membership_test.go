@@ -0,0 +1,79 @@
+package membership
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+
+	"goim-social/api/rest"
+)
+
+// periodSocial 返回固定成员身份时段的社交服务客户端，记录收到的请求
+type periodSocial struct {
+	rest.SocialServiceClient
+	resp *rest.GetMembershipPeriodsResponse
+	err  error
+	req  *rest.GetMembershipPeriodsRequest
+}
+
+func (p *periodSocial) GetMembershipPeriods(ctx context.Context, req *rest.GetMembershipPeriodsRequest, opts ...grpc.CallOption) (*rest.GetMembershipPeriodsResponse, error) {
+	p.req = req
+	return p.resp, p.err
+}
+
+// TestWindows 不可查看历史的时段从入群开始，可查看历史的从群组创建开始，未退群的时段不限结束时间
+func TestWindows(t *testing.T) {
+	joined, left := int64(1000), int64(2000)
+	got := Windows([]*rest.GroupMembershipPeriod{
+		{GroupId: 1, JoinedAt: joined, LeftAt: left},
+		{GroupId: 1, JoinedAt: 3000},
+		{GroupId: 2, JoinedAt: joined, LeftAt: left, HistoryVisible: true},
+		{GroupId: 3},
+	})
+	want := []Window{
+		{GroupID: 1, Start: time.UnixMilli(joined), End: time.UnixMilli(left)},
+		{GroupID: 1, Start: time.UnixMilli(3000)},
+		{GroupID: 2, End: time.UnixMilli(left)},
+		{GroupID: 3},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Windows() = %+v, want %+v", got, want)
+	}
+	if got := Windows(nil); got == nil || len(got) != 0 {
+		t.Errorf("Windows(nil) = %#v, want empty non-nil", got)
+	}
+}
+
+// TestFetch 按用户和群组查询时段，社交服务不可用、调用失败或返回失败时报错
+func TestFetch(t *testing.T) {
+	ctx := context.Background()
+	social := &periodSocial{resp: &rest.GetMembershipPeriodsResponse{
+		Success: true,
+		Periods: []*rest.GroupMembershipPeriod{{GroupId: 9, JoinedAt: 1000}},
+	}}
+	windows, err := Fetch(ctx, social, 7, 9)
+	if err != nil {
+		t.Fatalf("Fetch returned error: %v", err)
+	}
+	if social.req.UserId != 7 || social.req.GroupId != 9 {
+		t.Errorf("request = %+v, want user 7 group 9", social.req)
+	}
+	if want := []Window{{GroupID: 9, Start: time.UnixMilli(1000)}}; !reflect.DeepEqual(windows, want) {
+		t.Errorf("Fetch() = %+v, want %+v", windows, want)
+	}
+
+	if _, err := Fetch(ctx, nil, 7, 0); err == nil {
+		t.Error("Fetch without social service succeeded, want error")
+	}
+	failed := &periodSocial{resp: &rest.GetMembershipPeriodsResponse{Message: "boom"}}
+	if _, err := Fetch(ctx, failed, 7, 0); err == nil {
+		t.Error("Fetch with unsuccessful response succeeded, want error")
+	}
+	broken := &periodSocial{err: context.DeadlineExceeded}
+	if _, err := Fetch(ctx, broken, 7, 0); err == nil {
+		t.Error("Fetch with call error succeeded, want error")
+	}
+}
@@ -17,15 +17,15 @@ import (
 
 // AuthMiddleware 认证中间件配置
 type AuthMiddleware struct {
-	logger kratoslog.Logger
-	jwtKey string
+	logger   kratoslog.Logger
+	provider auth.Provider
 }
 
 // NewAuthMiddleware 创建认证中间件
-func NewAuthMiddleware(logger kratoslog.Logger, jwtKey string) *AuthMiddleware {
+func NewAuthMiddleware(logger kratoslog.Logger, provider auth.Provider) *AuthMiddleware {
 	return &AuthMiddleware{
-		logger: logger,
-		jwtKey: jwtKey,
+		logger:   logger,
+		provider: provider,
 	}
 }
 
@@ -44,13 +44,10 @@ func (am *AuthMiddleware) GinAuth() gin.HandlerFunc {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "Missing authorization token"})
 			c.Abort()
 			return
-		} else if /* TODO: isPrivateIP && */ token == "auth-debug" {
-			c.Next()
-			return
 		}
 
-		// 验证JWT token
-		claims, err := auth.ValidateJWT(token, am.jwtKey)
+		// 验证token
+		identity, err := am.provider.Authenticate(c.Request.Context(), token)
 		if err != nil {
 			am.logger.Log(kratoslog.LevelWarn, "msg", "Invalid token", "error", err, "path", c.Request.URL.Path)
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
@@ -59,10 +56,10 @@ func (am *AuthMiddleware) GinAuth() gin.HandlerFunc {
 		}
 
 		// 将用户信息存储到上下文
-		c.Set("userID", claims.UserID)
-		c.Set("username", claims.Username)
+		c.Set("userID", identity.UserID)
+		c.Set("username", identity.Username)
 
-		am.logger.Log(kratoslog.LevelDebug, "msg", "User authenticated", "userID", claims.UserID, "path", c.Request.URL.Path)
+		am.logger.Log(kratoslog.LevelDebug, "msg", "User authenticated", "userID", identity.UserID, "path", c.Request.URL.Path)
 		c.Next()
 	}
 }
@@ -93,18 +90,18 @@ func (am *AuthMiddleware) GRPCAuth() grpc.UnaryServerInterceptor {
 			return nil, status.Errorf(codes.Unauthenticated, "Invalid authorization header")
 		}
 
-		// 验证JWT token
-		claims, err := auth.ValidateJWT(token, am.jwtKey)
+		// 验证token
+		identity, err := am.provider.Authenticate(ctx, token)
 		if err != nil {
 			am.logger.Log(kratoslog.LevelWarn, "msg", "Invalid token", "error", err, "method", info.FullMethod)
 			return nil, status.Errorf(codes.Unauthenticated, "Invalid token")
 		}
 
 		// 将用户信息添加到上下文
-		ctx = context.WithValue(ctx, "userID", claims.UserID)
-		ctx = context.WithValue(ctx, "username", claims.Username)
+		ctx = context.WithValue(ctx, "userID", identity.UserID)
+		ctx = context.WithValue(ctx, "username", identity.Username)
 
-		am.logger.Log(kratoslog.LevelDebug, "msg", "User authenticated", "userID", claims.UserID, "method", info.FullMethod)
+		am.logger.Log(kratoslog.LevelDebug, "msg", "User authenticated", "userID", identity.UserID, "method", info.FullMethod)
 		return handler(ctx, req)
 	}
 }
@@ -136,8 +133,8 @@ func (am *AuthMiddleware) GRPCStreamAuth() grpc.StreamServerInterceptor {
 			return status.Errorf(codes.Unauthenticated, "Invalid authorization header")
 		}
 
-		// 验证JWT token
-		claims, err := auth.ValidateJWT(token, am.jwtKey)
+		// 验证token
+		identity, err := am.provider.Authenticate(ctx, token)
 		if err != nil {
 			am.logger.Log(kratoslog.LevelWarn, "msg", "Invalid token", "error", err, "method", info.FullMethod)
 			return status.Errorf(codes.Unauthenticated, "Invalid token")
@@ -146,10 +143,10 @@ func (am *AuthMiddleware) GRPCStreamAuth() grpc.StreamServerInterceptor {
 		// 创建包装的流，包含用户信息
 		wrappedStream := &wrappedServerStream{
 			ServerStream: ss,
-			ctx:          context.WithValue(ctx, "userID", claims.UserID),
+			ctx:          context.WithValue(ctx, "userID", identity.UserID),
 		}
 
-		am.logger.Log(kratoslog.LevelDebug, "msg", "User authenticated", "userID", claims.UserID, "method", info.FullMethod)
+		am.logger.Log(kratoslog.LevelDebug, "msg", "User authenticated", "userID", identity.UserID, "method", info.FullMethod)
 		return handler(srv, wrappedStream)
 	}
 }
@@ -0,0 +1,101 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"goim-social/pkg/config"
+)
+
+// deadlineExceeded 截止时间超时次数，通过HTTP服务的/metrics暴露
+// kind为http时target是路由，为grpc时target是被调用的方法
+var deadlineExceeded = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "deadline_exceeded_total",
+	Help: "请求或服务间调用超过截止时间的次数",
+}, []string{"kind", "target"})
+
+// callTimeoutKey 单次调用超时覆盖在context中的键
+type callTimeoutKey struct{}
+
+// WithCallTimeout 为ctx上发起的gRPC调用指定超时，覆盖配置中的默认值，用于已知的慢操作
+// 覆盖值同样不会晚于ctx已有的截止时间
+func WithCallTimeout(ctx context.Context, timeout time.Duration) context.Context {
+	return context.WithValue(ctx, callTimeoutKey{}, timeout)
+}
+
+// GinDeadline HTTP请求截止时间中间件，请求context超时后下游的数据库查询和gRPC调用随之取消
+// 处理函数因超时未写出响应时返回504；WebSocket等协议升级请求是长连接，不设截止时间，由处理函数按帧计时
+func GinDeadline(cfg config.DeadlineConfig) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetHeader("Upgrade") != "" {
+			c.Next()
+			return
+		}
+
+		timeout := cfg.HTTPRequest
+		if routeTimeout, ok := cfg.HTTPRoutes[c.FullPath()]; ok {
+			timeout = routeTimeout
+		}
+		if timeout <= 0 {
+			c.Next()
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), time.Duration(timeout)*time.Millisecond)
+		defer cancel()
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			deadlineExceeded.WithLabelValues("http", c.FullPath()).Inc()
+			if !c.Writer.Written() {
+				c.AbortWithStatusJSON(http.StatusGatewayTimeout, gin.H{
+					"code":    http.StatusGatewayTimeout,
+					"message": "request deadline exceeded",
+				})
+			}
+		}
+	}
+}
+
+// DeadlineUnaryClientInterceptor gRPC客户端截止时间拦截器
+// 调用超时依次取WithCallTimeout、按方法配置和默认配置，与上游传入的截止时间取较早者，
+// 截止时间通过grpc-timeout传给被调方，超时返回DeadlineExceeded并注明超时的方法
+func DeadlineUnaryClientInterceptor(cfg config.DeadlineConfig) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		timeout := time.Duration(cfg.GRPCCall) * time.Millisecond
+		if methodTimeout, ok := cfg.GRPCMethods[method]; ok {
+			timeout = time.Duration(methodTimeout) * time.Millisecond
+		}
+		if callTimeout, ok := ctx.Value(callTimeoutKey{}).(time.Duration); ok {
+			timeout = callTimeout
+		}
+		if timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, timeout)
+			defer cancel()
+		}
+
+		err := invoker(ctx, method, req, reply, cc, opts...)
+		if err != nil && (status.Code(err) == codes.DeadlineExceeded || errors.Is(ctx.Err(), context.DeadlineExceeded)) {
+			deadlineExceeded.WithLabelValues("grpc", method).Inc()
+			return status.Errorf(codes.DeadlineExceeded, "调用%s超时: %s", method, status.Convert(err).Message())
+		}
+		return err
+	}
+}
+
+// DeadlineDialOption 返回挂载截止时间拦截器的gRPC连接选项
+func DeadlineDialOption(cfg config.DeadlineConfig) grpc.DialOption {
+	return grpc.WithChainUnaryInterceptor(DeadlineUnaryClientInterceptor(cfg))
+}
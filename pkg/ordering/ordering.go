@@ -0,0 +1,174 @@
+package ordering
+
+import (
+	"sync"
+	"time"
+
+	"goim-social/api/rest"
+)
+
+// MaxPendingPerStream 单个发送流最多缓存的乱序消息数，超出时不再等待缺失的序号
+const MaxPendingPerStream = 1000
+
+// DeliverFunc 投递消息，同一发送流的消息按序号顺序串行调用
+type DeliverFunc func(recipient int64, msg *rest.WSMessage)
+
+// streamKey 发送流：同一接收者收到的同一发送者在同一会话中的消息
+type streamKey struct {
+	recipient int64
+	sender    int64
+	groupID   int64
+}
+
+// stream 单个发送流的排序状态
+type stream struct {
+	mu       sync.Mutex
+	next     int64                     // 下一条应投递的序号，序号从1开始分配
+	pending  map[int64]*rest.WSMessage // 等待前序消息的乱序消息
+	timer    *time.Timer               // 等待缺失序号的计时器
+	timerGen int64                     // 计时器代次，丢弃已被取代的计时器回调
+	lastSeen time.Time                 // 最近收到消息的时间，由Buffer在持有锁时更新
+}
+
+// Buffer 按发送者序号重排下行消息，保证接收者看到的同一发送者在同一会话中的消息与发送顺序一致
+// 序号由logic-service按会话和发送者分配，推送链路的并发和降级可能打乱到达顺序：
+// 提前到达的消息先缓存，等待缺失的序号最多wait时长，超时后跳过缺失的序号继续投递，
+// 缺失的消息之后到达时直接投递，客户端仍可按sender_seq排序。
+// 新建的发送流从序号1开始等待，先到达的后续消息同样缓存；网关重启或发送流空闲过期后，
+// 第一条消息最多延迟wait时长投递。未携带序号的消息直接投递。
+type Buffer struct {
+	wait       time.Duration
+	idleExpire time.Duration
+	deliver    DeliverFunc
+
+	mu        sync.Mutex
+	streams   map[streamKey]*stream
+	lastEvict time.Time
+}
+
+// NewBuffer 创建重排缓冲区，wait为等待缺失序号的最长时间，idleExpire为发送流空闲过期时间
+func NewBuffer(wait, idleExpire time.Duration, deliver DeliverFunc) *Buffer {
+	return &Buffer{
+		wait:       wait,
+		idleExpire: idleExpire,
+		deliver:    deliver,
+		streams:    make(map[streamKey]*stream),
+		lastEvict:  time.Now(),
+	}
+}
+
+// Push 提交一条发往recipient的消息，按序号顺序投递
+func (b *Buffer) Push(recipient int64, msg *rest.WSMessage) {
+	seq := msg.SenderSeq
+	if seq <= 0 || msg.From <= 0 {
+		b.deliver(recipient, msg)
+		return
+	}
+
+	st := b.stream(streamKey{recipient: recipient, sender: msg.From, groupID: msg.GroupId})
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	switch {
+	case seq == st.next:
+		b.deliver(recipient, msg)
+		st.next = seq + 1
+		b.drain(recipient, st)
+	case seq < st.next:
+		// 已越过的序号：重发的消息或等待超时后才到达的消息，直接投递，由客户端按消息ID去重
+		b.deliver(recipient, msg)
+	default:
+		if _, dup := st.pending[seq]; !dup {
+			st.pending[seq] = msg
+		}
+		if len(st.pending) > MaxPendingPerStream {
+			b.skipGap(recipient, st)
+			return
+		}
+		if st.timer == nil {
+			b.startTimer(recipient, st)
+		}
+	}
+}
+
+// stream 获取或创建发送流，并顺带清理空闲过期的发送流
+func (b *Buffer) stream(key streamKey) *stream {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(b.lastEvict) > b.idleExpire {
+		for k, st := range b.streams {
+			if now.Sub(st.lastSeen) > b.idleExpire {
+				delete(b.streams, k)
+			}
+		}
+		b.lastEvict = now
+	}
+
+	st, ok := b.streams[key]
+	if !ok {
+		st = &stream{next: 1, pending: make(map[int64]*rest.WSMessage)}
+		b.streams[key] = st
+	}
+	st.lastSeen = now
+	return st
+}
+
+// drain 投递从next开始连续的缓存消息，仍有缓存时重新等待，调用方需持有发送流的锁
+func (b *Buffer) drain(recipient int64, st *stream) {
+	for {
+		msg, ok := st.pending[st.next]
+		if !ok {
+			break
+		}
+		delete(st.pending, st.next)
+		b.deliver(recipient, msg)
+		st.next++
+	}
+
+	b.stopTimer(st)
+	if len(st.pending) > 0 {
+		b.startTimer(recipient, st)
+	}
+}
+
+// skipGap 跳过缺失的序号，从最小的缓存序号继续投递，调用方需持有发送流的锁
+func (b *Buffer) skipGap(recipient int64, st *stream) {
+	var lowest int64
+	for seq := range st.pending {
+		if lowest == 0 || seq < lowest {
+			lowest = seq
+		}
+	}
+	if lowest == 0 {
+		b.stopTimer(st)
+		return
+	}
+	st.next = lowest
+	b.drain(recipient, st)
+}
+
+// startTimer 开始等待缺失的序号，超时后跳过，调用方需持有发送流的锁
+func (b *Buffer) startTimer(recipient int64, st *stream) {
+	st.timerGen++
+	gen := st.timerGen
+	st.timer = time.AfterFunc(b.wait, func() {
+		st.mu.Lock()
+		defer st.mu.Unlock()
+		if st.timerGen != gen {
+			return
+		}
+		st.timer = nil
+		b.skipGap(recipient, st)
+	})
+}
+
+// stopTimer 取消等待，调用方需持有发送流的锁
+func (b *Buffer) stopTimer(st *stream) {
+	if st.timer != nil {
+		st.timer.Stop()
+		st.timer = nil
+	}
+	st.timerGen++
+}
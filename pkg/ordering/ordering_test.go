@@ -0,0 +1,156 @@
+package ordering
+
+import (
+	"math/rand"
+	"sync"
+	"testing"
+	"time"
+
+	"goim-social/api/rest"
+)
+
+// recorder 记录投递顺序
+type recorder struct {
+	mu   sync.Mutex
+	seqs []int64
+}
+
+func (r *recorder) deliver(recipient int64, msg *rest.WSMessage) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.seqs = append(r.seqs, msg.SenderSeq)
+}
+
+func (r *recorder) snapshot() []int64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]int64(nil), r.seqs...)
+}
+
+func newMessage(seq int64) *rest.WSMessage {
+	return &rest.WSMessage{MessageId: 1000 + seq, From: 1, To: 2, SenderSeq: seq}
+}
+
+// TestRapidSendsDeliveredInOrder 快速发送100条消息，推送链路并发乱序到达，接收者仍按发送顺序收到
+func TestRapidSendsDeliveredInOrder(t *testing.T) {
+	r := &recorder{}
+	b := NewBuffer(time.Second, time.Minute, r.deliver)
+
+	// 第一条消息建立基线，其余消息由多个推送协程并发乱序提交
+	b.Push(2, newMessage(1))
+	order := rand.Perm(99)
+	var wg sync.WaitGroup
+	for _, i := range order {
+		wg.Add(1)
+		go func(seq int64) {
+			defer wg.Done()
+			time.Sleep(time.Duration(rand.Intn(2000)) * time.Microsecond)
+			b.Push(2, newMessage(seq))
+		}(int64(i) + 2)
+	}
+	wg.Wait()
+
+	got := r.snapshot()
+	if len(got) != 100 {
+		t.Fatalf("应投递100条消息，实际%d条", len(got))
+	}
+	for i, seq := range got {
+		if seq != int64(i)+1 {
+			t.Fatalf("第%d条投递的序号为%d，消息乱序: %v", i+1, seq, got)
+		}
+	}
+}
+
+// TestFirstMessageOutOfOrder 发送流的第一条消息乱序到达时同样等待前序消息
+func TestFirstMessageOutOfOrder(t *testing.T) {
+	r := &recorder{}
+	b := NewBuffer(time.Second, time.Minute, r.deliver)
+
+	b.Push(2, newMessage(2))
+	if got := r.snapshot(); len(got) != 0 {
+		t.Fatalf("序号1到达前不应投递序号2: %v", got)
+	}
+	b.Push(2, newMessage(1))
+
+	want := []int64{1, 2}
+	got := r.snapshot()
+	if len(got) != len(want) {
+		t.Fatalf("投递结果不符: got %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("投递结果不符: got %v, want %v", got, want)
+		}
+	}
+}
+
+// TestNewStreamMidSequence 网关重启后发送流从中途的序号开始，等待超时后继续投递
+func TestNewStreamMidSequence(t *testing.T) {
+	r := &recorder{}
+	b := NewBuffer(20*time.Millisecond, time.Minute, r.deliver)
+
+	b.Push(2, newMessage(58))
+	b.Push(2, newMessage(57))
+	time.Sleep(100 * time.Millisecond)
+	b.Push(2, newMessage(59))
+
+	want := []int64{57, 58, 59}
+	got := r.snapshot()
+	if len(got) != len(want) {
+		t.Fatalf("投递结果不符: got %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("投递结果不符: got %v, want %v", got, want)
+		}
+	}
+}
+
+// TestGapSkippedAfterWait 缺失的序号超时未到达时跳过，之后到达的缺失消息直接投递
+func TestGapSkippedAfterWait(t *testing.T) {
+	r := &recorder{}
+	b := NewBuffer(20*time.Millisecond, time.Minute, r.deliver)
+
+	b.Push(2, newMessage(1))
+	b.Push(2, newMessage(3))
+	b.Push(2, newMessage(4))
+	if got := r.snapshot(); len(got) != 1 {
+		t.Fatalf("等待缺失序号期间不应投递后续消息: %v", got)
+	}
+
+	time.Sleep(100 * time.Millisecond)
+	b.Push(2, newMessage(2))
+
+	want := []int64{1, 3, 4, 2}
+	got := r.snapshot()
+	if len(got) != len(want) {
+		t.Fatalf("投递结果不符: got %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("投递结果不符: got %v, want %v", got, want)
+		}
+	}
+}
+
+// TestStreamsIndependent 不同发送者和未携带序号的消息互不阻塞
+func TestStreamsIndependent(t *testing.T) {
+	r := &recorder{}
+	b := NewBuffer(time.Second, time.Minute, r.deliver)
+
+	b.Push(2, newMessage(1))
+	b.Push(2, newMessage(3)) // 等待序号2
+	b.Push(2, &rest.WSMessage{MessageId: 1, From: 5, To: 2, SenderSeq: 1})
+	b.Push(2, &rest.WSMessage{MessageId: 2, From: 1, To: 2})
+
+	want := []int64{1, 1, 0}
+	got := r.snapshot()
+	if len(got) != len(want) {
+		t.Fatalf("投递结果不符: got %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("投递结果不符: got %v, want %v", got, want)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package outbox
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"goim-social/pkg/config"
+	"goim-social/pkg/redis"
+)
+
+// KeySeenPrefix 已处理事件的Redis键前缀 outbox:seen:{consumer}:{eventID}
+const KeySeenPrefix = "outbox:seen"
+
+// DefaultDedupTTL 未配置时记录已处理事件的时间
+const DefaultDedupTTL = 24 * time.Hour
+
+// Deduper 消费者按事件ID去重，发件箱至少一次发布，同一事件可能被重复投递
+// 只有对重复处理敏感的消费者（如累加计数）需要去重；按版本覆盖或可重复执行的消费者无需去重
+type Deduper struct {
+	redis    *redis.RedisClient
+	consumer string
+	ttl      time.Duration
+}
+
+// NewDeduper 创建去重器，consumer为消费者名称，不同消费者各自记录已处理的事件
+func NewDeduper(redis *redis.RedisClient, consumer string, cfg config.OutboxConfig) *Deduper {
+	ttl := time.Duration(cfg.DedupTTL) * time.Second
+	if ttl <= 0 {
+		ttl = DefaultDedupTTL
+	}
+	return &Deduper{redis: redis, consumer: consumer, ttl: ttl}
+}
+
+// Seen 事件是否已处理过，只读取记录，事件处理完成后由调用方通过 Mark 记录
+// 不带事件ID的消息（非发件箱发布）或Redis不可用时返回false，按未处理过处理，宁可重复也不丢失
+func (d *Deduper) Seen(ctx context.Context, value []byte) bool {
+	if d == nil || d.redis == nil {
+		return false
+	}
+	eventID := EventIDOf(value)
+	if eventID == "" {
+		return false
+	}
+
+	n, err := d.redis.Exists(ctx, d.key(eventID))
+	if err != nil {
+		log.Printf("事件去重失败，按未处理过处理: consumer=%s, event_id=%s, error=%v", d.consumer, eventID, err)
+		return false
+	}
+	return n > 0
+}
+
+// Mark 记录事件已处理，应在处理结果写入成功后调用，写入失败的事件不记录，重新投递时可以再次处理
+func (d *Deduper) Mark(ctx context.Context, eventIDs ...string) {
+	if d == nil || d.redis == nil || len(eventIDs) == 0 {
+		return
+	}
+
+	pipe := d.redis.GetClient().Pipeline()
+	for _, eventID := range eventIDs {
+		pipe.Set(ctx, d.key(eventID), 1, d.ttl)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		log.Printf("记录已处理事件失败: consumer=%s, events=%d, error=%v", d.consumer, len(eventIDs), err)
+	}
+}
+
+func (d *Deduper) key(eventID string) string {
+	return KeySeenPrefix + ":" + d.consumer + ":" + eventID
+}
@@ -0,0 +1,113 @@
+package outbox
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// 事务发件箱：事件与业务数据在同一个数据库事务中写入发件箱表，由转发器（Relay）发布到Kafka后标记为已发布。
+// 业务写入成功则事件一定会被发布，写入回滚则事件不会发布；转发器在发布后、标记前退出时事件会再次发布，
+// 因此投递语义为至少一次。每个事件带有唯一的event_id（写入消息体），消费者据此去重（见Deduper）
+
+// 事件状态
+const (
+	StatusPending = "pending" // 待发布
+	StatusSent    = "sent"    // 已发布
+	StatusFailed  = "failed"  // 超过最大发布次数，不再重试，需人工处理
+)
+
+// FieldEventID 消息体中事件ID的字段名，消费者据此去重
+const FieldEventID = "event_id"
+
+// Event 发件箱中的事件，Payload为JSON对象，写入时已包含event_id
+type Event struct {
+	ID        int64      `gorm:"primaryKey;autoIncrement"`
+	EventID   string     `gorm:"type:varchar(64);not null;uniqueIndex"`             // 去重键，同一事件重复发布时不变
+	Topic     string     `gorm:"type:varchar(128);not null"`                        // Kafka Topic
+	Key       string     `gorm:"type:varchar(128)"`                                 // 分区键，为空时随机分区
+	Payload   []byte     `gorm:"type:bytea;not null"`                               // 消息体
+	Status    string     `gorm:"type:varchar(16);not null;default:'pending';index"` // 事件状态
+	Attempts  int        `gorm:"not null;default:0"`                                // 发布失败次数
+	LastError string     `gorm:"type:varchar(512)"`                                 // 最近一次发布失败的原因
+	CreatedAt time.Time  `gorm:"autoCreateTime;index"`                              // 写入时间
+	SentAt    *time.Time `gorm:"index"`                                             // 发布时间
+}
+
+// TableName .
+func (Event) TableName() string {
+	return "outbox_events"
+}
+
+// Builder 在事务中构建事件，业务数据写入后调用，可以使用同一事务中刚生成的主键
+type Builder func() (*Event, error)
+
+// NewEvent 创建事件，payload序列化为JSON对象后写入event_id；payload为[]byte或json.RawMessage时视为已序列化的JSON
+func NewEvent(topic, key string, payload interface{}) (*Event, error) {
+	var data []byte
+	switch v := payload.(type) {
+	case []byte:
+		data = v
+	case json.RawMessage:
+		data = v
+	default:
+		var err error
+		if data, err = json.Marshal(payload); err != nil {
+			return nil, fmt.Errorf("序列化事件失败: %v", err)
+		}
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil || fields == nil {
+		return nil, fmt.Errorf("事件消息体必须是JSON对象: topic=%s", topic)
+	}
+
+	eventID := uuid.NewString()
+	fields[FieldEventID], _ = json.Marshal(eventID)
+	data, err := json.Marshal(fields)
+	if err != nil {
+		return nil, fmt.Errorf("序列化事件失败: %v", err)
+	}
+
+	return &Event{
+		EventID: eventID,
+		Topic:   topic,
+		Key:     key,
+		Payload: data,
+		Status:  StatusPending,
+	}, nil
+}
+
+// Write 在业务事务中构建并写入事件，任一事件构建或写入失败时返回错误，调用方应回滚事务
+func Write(tx *gorm.DB, builders ...Builder) error {
+	for _, build := range builders {
+		if build == nil {
+			continue
+		}
+		event, err := build()
+		if err != nil {
+			return err
+		}
+		if event == nil {
+			continue
+		}
+		if err := tx.Create(event).Error; err != nil {
+			return fmt.Errorf("写入发件箱失败: %v", err)
+		}
+	}
+	return nil
+}
+
+// EventIDOf 从消息体中读取事件ID，不是发件箱发布的事件时返回空字符串
+func EventIDOf(value []byte) string {
+	var envelope struct {
+		EventID string `json:"event_id"`
+	}
+	if err := json.Unmarshal(value, &envelope); err != nil {
+		return ""
+	}
+	return envelope.EventID
+}
@@ -0,0 +1,156 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"goim-social/pkg/config"
+	"goim-social/pkg/redis"
+)
+
+// fakePublisher 记录发布顺序，failures中的Topic发布失败
+type fakePublisher struct {
+	published []string
+	failures  map[string]bool
+}
+
+func (p *fakePublisher) SendMessageSync(topic string, key, value []byte) error {
+	if p.failures[topic] {
+		return errors.New("broker unavailable")
+	}
+	p.published = append(p.published, topic+":"+string(key))
+	return nil
+}
+
+func (p *fakePublisher) Close() error { return nil }
+
+// TestNewEvent 消息体写入唯一的event_id，原有字段保留
+func TestNewEvent(t *testing.T) {
+	event, err := NewEvent("comment-events", "42", map[string]interface{}{"event_type": "create", "comment_id": 42})
+	if err != nil {
+		t.Fatalf("创建事件失败: %v", err)
+	}
+	if event.EventID == "" || EventIDOf(event.Payload) != event.EventID || event.Status != StatusPending {
+		t.Fatalf("事件ID错误: %+v", event)
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(event.Payload, &fields); err != nil || fields["event_type"] != "create" || fields["comment_id"] != float64(42) {
+		t.Fatalf("原有字段丢失: %s", event.Payload)
+	}
+
+	raw, err := NewEvent("comment-events", "", []byte(`{"event_type":"delete"}`))
+	if err != nil {
+		t.Fatalf("已序列化的消息体创建事件失败: %v", err)
+	}
+	if raw.EventID == event.EventID || EventIDOf(raw.Payload) != raw.EventID {
+		t.Fatalf("事件ID应唯一: %s %s", raw.EventID, event.EventID)
+	}
+
+	if _, err := NewEvent("comment-events", "", []int{1, 2}); err == nil {
+		t.Fatalf("消息体不是JSON对象时应报错")
+	}
+	if EventIDOf([]byte("not json")) != "" || EventIDOf([]byte(`{"event_type":"create"}`)) != "" {
+		t.Fatalf("非发件箱事件不应有事件ID")
+	}
+}
+
+// TestDeliverStopsOnFailure 发布失败时停止本轮，保证后续事件不会先于失败的事件发布
+func TestDeliverStopsOnFailure(t *testing.T) {
+	publisher := &fakePublisher{failures: map[string]bool{"b": true}}
+	events := []*Event{
+		{ID: 1, Topic: "a", Key: "1", Status: StatusPending},
+		{ID: 2, Topic: "b", Key: "2", Status: StatusPending},
+		{ID: 3, Topic: "a", Key: "3", Status: StatusPending},
+	}
+
+	result := deliver(publisher, events, 0)
+	if len(result.sent) != 1 || result.sent[0] != 1 || result.err == nil {
+		t.Fatalf("发布结果错误: %+v", result)
+	}
+	if len(result.failed) != 1 || result.failed[0].Attempts != 1 || result.failed[0].Status != StatusPending || result.failed[0].LastError == "" {
+		t.Fatalf("失败记录错误: %+v", result.failed)
+	}
+	if len(publisher.published) != 1 || publisher.published[0] != "a:1" {
+		t.Fatalf("发布顺序错误: %v", publisher.published)
+	}
+}
+
+// TestDeliverSkipsExhausted 超过最大发布次数的事件标记为失败并跳过，继续发布后续事件
+func TestDeliverSkipsExhausted(t *testing.T) {
+	publisher := &fakePublisher{failures: map[string]bool{"b": true}}
+	events := []*Event{
+		{ID: 1, Topic: "b", Status: StatusPending, Attempts: 2},
+		{ID: 2, Topic: "a", Status: StatusPending},
+	}
+
+	result := deliver(publisher, events, 3)
+	if result.err != nil || len(result.sent) != 1 || result.sent[0] != 2 {
+		t.Fatalf("发布结果错误: %+v", result)
+	}
+	if len(result.failed) != 1 || result.failed[0].Status != StatusFailed || result.failed[0].Attempts != 3 {
+		t.Fatalf("应标记为失败: %+v", result.failed)
+	}
+	if len(publisher.published) != 1 || publisher.published[0] != "a:" {
+		t.Fatalf("空分区键应发布为nil: %v", publisher.published)
+	}
+}
+
+// TestBackoff 重试间隔从扫描间隔开始翻倍，不超过上限
+func TestBackoff(t *testing.T) {
+	base, limit := 500*time.Millisecond, 3*time.Second
+	want := []time.Duration{500 * time.Millisecond, time.Second, 2 * time.Second, 3 * time.Second, 3 * time.Second}
+	for i, expected := range want {
+		if got := backoff(base, limit, i+1); got != expected {
+			t.Fatalf("第%d次失败的间隔为%s，应为%s", i+1, got, expected)
+		}
+	}
+}
+
+// TestNewRelayDefaults 未配置的参数使用默认值，同名服务的转发锁相同
+func TestNewRelayDefaults(t *testing.T) {
+	r := NewRelay(nil, "content-service", nil, config.OutboxConfig{})
+	if r.pollInterval != DefaultPollInterval || r.batchSize != DefaultBatchSize ||
+		r.maxBackoff != DefaultMaxBackoff || r.retention != DefaultRetention {
+		t.Fatalf("默认参数错误: %+v", r)
+	}
+	if r.lockID != lockID("content-service") || r.lockID == lockID("social-service") {
+		t.Fatalf("转发锁ID错误")
+	}
+	// 未启动时关闭和唤醒不应阻塞
+	r.Notify()
+	r.Notify()
+	if err := r.Close(context.Background()); err != nil {
+		t.Fatalf("未启动时关闭失败: %v", err)
+	}
+}
+
+// TestDeduperUnavailable 未配置或Redis不可用时按未处理过处理，宁可重复也不丢失
+func TestDeduperUnavailable(t *testing.T) {
+	event, err := NewEvent("interaction-events", "", map[string]string{"event_type": "create"})
+	if err != nil {
+		t.Fatalf("创建事件失败: %v", err)
+	}
+
+	var nilDeduper *Deduper
+	if nilDeduper.Seen(context.Background(), event.Payload) {
+		t.Fatalf("未配置去重时不应视为已处理")
+	}
+
+	client := redis.NewRedisClient("127.0.0.1:1")
+	defer client.Close()
+	d := NewDeduper(client, "test", config.OutboxConfig{})
+	if d.ttl != DefaultDedupTTL {
+		t.Fatalf("默认去重时间错误: %s", d.ttl)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if d.Seen(ctx, event.Payload) || d.Seen(ctx, []byte(`{"event_type":"create"}`)) {
+		t.Fatalf("Redis不可用时不应视为已处理")
+	}
+	// Redis不可用时记录失败只记日志，不影响调用方
+	d.Mark(ctx, event.EventID)
+	nilDeduper.Mark(ctx, event.EventID)
+}
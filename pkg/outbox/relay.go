@@ -0,0 +1,292 @@
+package outbox
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+
+	"goim-social/pkg/config"
+)
+
+// 转发器默认参数
+const (
+	DefaultPollInterval = 500 * time.Millisecond // 扫描发件箱的间隔
+	DefaultBatchSize    = 100                    // 每次转发的最大事件数
+	DefaultMaxBackoff   = 30 * time.Second       // 发布失败后重试间隔上限
+	DefaultRetention    = 72 * time.Hour         // 已发布事件的保留时间
+	purgeInterval       = time.Hour              // 清理已发布事件的间隔
+	maxErrorLength      = 512                    // 记录的失败原因最大长度
+)
+
+// Publisher 发件箱事件的发布者，返回nil表示Kafka已确认写入，kafka.ReliableProducer满足该接口
+type Publisher interface {
+	SendMessageSync(topic string, key, value []byte) error
+	Close() error
+}
+
+// Dialer 创建发布者，Kafka不可用时返回错误，转发器在下一轮扫描时重新连接
+type Dialer func() (Publisher, error)
+
+// Relay 发件箱转发器，按写入顺序将待发布的事件发布到Kafka并标记为已发布
+// 多个服务实例共用一个数据库时通过PostgreSQL事务级咨询锁保证同一时刻只有一个实例在转发，事件按写入顺序发布；
+// 发布失败时停止本轮转发并退避重试，超过最大发布次数的事件标记为失败后跳过，避免一个事件阻塞整个发件箱
+type Relay struct {
+	db     *gorm.DB
+	name   string
+	lockID int64
+	dial   Dialer
+
+	pollInterval time.Duration
+	maxBackoff   time.Duration
+	retention    time.Duration
+	batchSize    int
+	maxAttempts  int
+
+	publisher Publisher // 仅在转发循环中使用
+	wake      chan struct{}
+	cancel    context.CancelFunc
+	done      chan struct{}
+}
+
+// NewRelay 创建发件箱转发器，name为服务名，同一数据库的转发器使用相同的name
+func NewRelay(db *gorm.DB, name string, dial Dialer, cfg config.OutboxConfig) *Relay {
+	r := &Relay{
+		db:           db,
+		name:         name,
+		lockID:       lockID(name),
+		dial:         dial,
+		pollInterval: time.Duration(cfg.PollInterval) * time.Millisecond,
+		maxBackoff:   time.Duration(cfg.MaxBackoff) * time.Millisecond,
+		retention:    time.Duration(cfg.Retention) * time.Hour,
+		batchSize:    cfg.BatchSize,
+		maxAttempts:  cfg.MaxAttempts,
+		wake:         make(chan struct{}, 1),
+		done:         make(chan struct{}),
+	}
+	if r.pollInterval <= 0 {
+		r.pollInterval = DefaultPollInterval
+	}
+	if r.maxBackoff < r.pollInterval {
+		r.maxBackoff = max(DefaultMaxBackoff, r.pollInterval)
+	}
+	if r.retention <= 0 {
+		r.retention = DefaultRetention
+	}
+	if r.batchSize <= 0 {
+		r.batchSize = DefaultBatchSize
+	}
+	return r
+}
+
+// Start 启动转发循环
+func (r *Relay) Start(ctx context.Context) {
+	ctx, r.cancel = context.WithCancel(ctx)
+	go r.run(ctx)
+}
+
+// Notify 业务事务提交后唤醒转发器立即扫描，降低事件发布延迟；发布失败退避期间忽略
+func (r *Relay) Notify() {
+	if r == nil {
+		return
+	}
+	select {
+	case r.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Close 停止转发并关闭发布者，未发布的事件留在发件箱中，重启后继续发布
+func (r *Relay) Close(ctx context.Context) error {
+	if r.cancel == nil {
+		return nil
+	}
+	r.cancel()
+	select {
+	case <-r.done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	if r.publisher != nil {
+		return r.publisher.Close()
+	}
+	return nil
+}
+
+// run 转发循环，一轮转满一批时立即继续，发布失败时按指数退避
+func (r *Relay) run(ctx context.Context) {
+	defer close(r.done)
+
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+	lastPurge := time.Now()
+	failures := 0
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-r.wake:
+			if failures > 0 {
+				continue
+			}
+			timer.Stop()
+		case <-timer.C:
+		}
+
+		more, err := r.relayOnce(ctx)
+		delay := r.pollInterval
+		if err != nil {
+			failures++
+			delay = backoff(r.pollInterval, r.maxBackoff, failures)
+			log.Printf("发件箱转发失败: service=%s, failures=%d, retry_in=%s, error=%v", r.name, failures, delay, err)
+		} else {
+			failures = 0
+			if more {
+				delay = 0
+			}
+		}
+
+		if time.Since(lastPurge) >= purgeInterval {
+			r.purge(ctx)
+			lastPurge = time.Now()
+		}
+		timer.Reset(delay)
+	}
+}
+
+// relayOnce 转发一批待发布的事件，返回是否可能还有待发布的事件
+// 发布和标记在同一个事务中，发布后标记前退出时事件会再次发布，由消费者按事件ID去重
+func (r *Relay) relayOnce(ctx context.Context) (bool, error) {
+	if r.publisher == nil {
+		publisher, err := r.dial()
+		if err != nil {
+			return false, fmt.Errorf("连接Kafka失败: %v", err)
+		}
+		r.publisher = publisher
+	}
+
+	var (
+		events  []*Event
+		result  deliveryResult
+		claimed bool
+	)
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// 事务结束时自动释放，其他实例本轮跳过
+		if err := tx.Raw("SELECT pg_try_advisory_xact_lock(?)", r.lockID).Scan(&claimed).Error; err != nil {
+			return fmt.Errorf("获取转发锁失败: %v", err)
+		}
+		if !claimed {
+			return nil
+		}
+
+		if err := tx.Where("status = ?", StatusPending).
+			Order("id").Limit(r.batchSize).Find(&events).Error; err != nil {
+			return fmt.Errorf("读取发件箱失败: %v", err)
+		}
+		if len(events) == 0 {
+			return nil
+		}
+
+		result = deliver(r.publisher, events, r.maxAttempts)
+		if len(result.sent) > 0 {
+			if err := tx.Model(&Event{}).Where("id IN ?", result.sent).Updates(map[string]interface{}{
+				"status":  StatusSent,
+				"sent_at": time.Now(),
+			}).Error; err != nil {
+				return fmt.Errorf("标记已发布事件失败: %v", err)
+			}
+		}
+		for _, event := range result.failed {
+			if err := tx.Model(&Event{}).Where("id = ?", event.ID).Updates(map[string]interface{}{
+				"status":     event.Status,
+				"attempts":   event.Attempts,
+				"last_error": event.LastError,
+			}).Error; err != nil {
+				return fmt.Errorf("记录发布失败失败: %v", err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+	if result.err != nil {
+		return false, result.err
+	}
+	return claimed && len(events) == r.batchSize, nil
+}
+
+// purge 清理超过保留时间的已发布事件，失败的事件保留以便人工处理
+func (r *Relay) purge(ctx context.Context) {
+	result := r.db.WithContext(ctx).
+		Where("status = ? AND sent_at < ?", StatusSent, time.Now().Add(-r.retention)).
+		Delete(&Event{})
+	if result.Error != nil {
+		log.Printf("清理已发布事件失败: service=%s, error=%v", r.name, result.Error)
+		return
+	}
+	if result.RowsAffected > 0 {
+		log.Printf("清理已发布事件: service=%s, count=%d", r.name, result.RowsAffected)
+	}
+}
+
+// deliveryResult 一批事件的发布结果
+type deliveryResult struct {
+	sent   []int64  // 已发布的事件ID
+	failed []*Event // 发布失败的事件，Attempts、LastError和Status已更新
+	err    error    // 导致本轮停止的发布错误
+}
+
+// deliver 按顺序发布一批事件，遇到发布失败时停止以保证顺序；
+// 超过最大发布次数的事件标记为失败并跳过，继续发布后续事件
+func deliver(publisher Publisher, events []*Event, maxAttempts int) deliveryResult {
+	var result deliveryResult
+	for _, event := range events {
+		var key []byte
+		if event.Key != "" {
+			key = []byte(event.Key)
+		}
+
+		err := publisher.SendMessageSync(event.Topic, key, event.Payload)
+		if err == nil {
+			result.sent = append(result.sent, event.ID)
+			continue
+		}
+
+		event.Attempts++
+		event.LastError = err.Error()
+		if runes := []rune(event.LastError); len(runes) > maxErrorLength {
+			event.LastError = string(runes[:maxErrorLength])
+		}
+		result.failed = append(result.failed, event)
+		if maxAttempts > 0 && event.Attempts >= maxAttempts {
+			event.Status = StatusFailed
+			log.Printf("事件超过最大发布次数，已跳过: event_id=%s, topic=%s, attempts=%d, error=%v",
+				event.EventID, event.Topic, event.Attempts, err)
+			continue
+		}
+		result.err = fmt.Errorf("发布事件失败: event_id=%s, topic=%s: %v", event.EventID, event.Topic, err)
+		break
+	}
+	return result
+}
+
+// backoff 第failures次连续失败后的重试间隔，从扫描间隔开始翻倍，不超过上限
+func backoff(base, limit time.Duration, failures int) time.Duration {
+	delay := base
+	for i := 1; i < failures && delay < limit; i++ {
+		delay *= 2
+	}
+	return min(delay, limit)
+}
+
+// lockID 转发锁的咨询锁ID，由服务名计算
+func lockID(name string) int64 {
+	h := fnv.New64a()
+	h.Write([]byte("outbox:" + name))
+	return int64(h.Sum64())
+}
@@ -0,0 +1,66 @@
+package pagination
+
+import "goim-social/pkg/config"
+
+// 配置缺失或非法时使用的默认值
+const (
+	DefaultPageSize = 20
+	MaxPageSize     = 100
+	MaxBatchSize    = 100
+)
+
+// Limits 列表和批量查询的数量限制，各服务统一通过它规范客户端传入的每页数量，
+// 超过上限时截断为上限而不是报错，避免超大分页给数据库和ES带来过重的查询。
+// 零值可用，使用默认值
+type Limits struct {
+	defaultPageSize int
+	maxPageSize     int
+	maxBatchSize    int
+}
+
+// New 根据配置创建数量限制，非正数的配置项使用默认值
+func New(cfg config.PaginationConfig) Limits {
+	return Limits{
+		defaultPageSize: cfg.DefaultPageSize,
+		maxPageSize:     cfg.MaxPageSize,
+		maxBatchSize:    cfg.MaxBatchSize,
+	}
+}
+
+// MaxPageSize 每页数量上限
+func (l Limits) MaxPageSize() int {
+	if l.maxPageSize <= 0 {
+		return MaxPageSize
+	}
+	return l.maxPageSize
+}
+
+// DefaultPageSize 默认每页数量，不超过每页数量上限
+func (l Limits) DefaultPageSize() int {
+	size := l.defaultPageSize
+	if size <= 0 {
+		size = DefaultPageSize
+	}
+	return min(size, l.MaxPageSize())
+}
+
+// MaxBatchSize 批量查询一次最多的数量
+func (l Limits) MaxBatchSize() int {
+	if l.maxBatchSize <= 0 {
+		return MaxBatchSize
+	}
+	return l.maxBatchSize
+}
+
+// PageSize 规范每页数量：未传或非法时返回默认值，超过上限时返回上限
+func (l Limits) PageSize(size int) int {
+	if size <= 0 {
+		return l.DefaultPageSize()
+	}
+	return min(size, l.MaxPageSize())
+}
+
+// PageSize32 同PageSize，用于protobuf请求中的int32字段
+func (l Limits) PageSize32(size int32) int32 {
+	return int32(l.PageSize(int(size)))
+}
@@ -0,0 +1,54 @@
+package pagination
+
+import (
+	"testing"
+
+	"goim-social/pkg/config"
+)
+
+// TestPageSizeClamped 未传时使用默认值，超过上限时截断为上限
+func TestPageSizeClamped(t *testing.T) {
+	l := New(config.PaginationConfig{DefaultPageSize: 10, MaxPageSize: 50, MaxBatchSize: 30})
+
+	cases := []struct {
+		size int
+		want int
+	}{
+		{0, 10},
+		{-5, 10},
+		{1, 1},
+		{50, 50},
+		{51, 50},
+		{1 << 30, 50},
+	}
+	for _, c := range cases {
+		if got := l.PageSize(c.size); got != c.want {
+			t.Fatalf("PageSize(%d) = %d, want %d", c.size, got, c.want)
+		}
+	}
+	if got := l.PageSize32(1000); got != 50 {
+		t.Fatalf("PageSize32(1000) = %d, want 50", got)
+	}
+	if got := l.MaxBatchSize(); got != 30 {
+		t.Fatalf("MaxBatchSize() = %d, want 30", got)
+	}
+}
+
+// TestInvalidConfigUsesDefaults 非法配置和零值使用默认值，默认每页数量不超过上限
+func TestInvalidConfigUsesDefaults(t *testing.T) {
+	var zero Limits
+	if got := zero.PageSize(0); got != DefaultPageSize {
+		t.Fatalf("零值默认每页数量 = %d, want %d", got, DefaultPageSize)
+	}
+	if got := zero.PageSize(1000); got != MaxPageSize {
+		t.Fatalf("零值每页数量上限 = %d, want %d", got, MaxPageSize)
+	}
+	if got := zero.MaxBatchSize(); got != MaxBatchSize {
+		t.Fatalf("零值批量查询上限 = %d, want %d", got, MaxBatchSize)
+	}
+
+	l := New(config.PaginationConfig{DefaultPageSize: 200, MaxPageSize: 50})
+	if got := l.PageSize(0); got != 50 {
+		t.Fatalf("默认每页数量超过上限时应截断: got %d", got)
+	}
+}
@@ -0,0 +1,86 @@
+package presence
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	goredis "github.com/go-redis/redis/v8"
+
+	"goim-social/pkg/redis"
+)
+
+// 连接信息由im-gateway在连接建立、心跳和断开时维护：每个连接一个信息哈希，另按用户维护连接ID集合。
+// 查找用户的连接时读取集合，不再以conn:{userID}:*执行KEYS，KEYS会遍历整个键空间并阻塞Redis
+const (
+	KeyConnectionPrefix = "conn"       // 连接信息哈希 conn:{userID}:{connID}
+	KeyUserConnsPrefix  = "user_conns" // 用户的连接ID集合 user_conns:{userID}，不以conn:开头，不会被按conn:*扫描到
+)
+
+// ConnectionKey 连接信息哈希键
+func ConnectionKey(userID int64, connID string) string {
+	return fmt.Sprintf("%s:%d:%s", KeyConnectionPrefix, userID, connID)
+}
+
+// UserConnectionsKey 用户的连接ID集合键
+func UserConnectionsKey(userID int64) string {
+	return fmt.Sprintf("%s:%d", KeyUserConnsPrefix, userID)
+}
+
+// TrackConnection 将连接加入用户的连接集合并刷新集合的过期时间，连接建立和心跳时调用
+// 集合与连接信息哈希使用相同的过期时间，用户全部连接过期后集合随之过期
+func TrackConnection(ctx context.Context, rc *redis.RedisClient, userID int64, connID string, ttl time.Duration) error {
+	key := UserConnectionsKey(userID)
+	pipe := rc.GetClient().Pipeline()
+	pipe.SAdd(ctx, key, connID)
+	pipe.Expire(ctx, key, ttl)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("记录用户连接失败: %v", err)
+	}
+	return nil
+}
+
+// UntrackConnection 将连接移出用户的连接集合，连接断开时调用
+func UntrackConnection(ctx context.Context, rc *redis.RedisClient, userID int64, connID string) error {
+	if err := rc.SRem(ctx, UserConnectionsKey(userID), connID); err != nil {
+		return fmt.Errorf("移除用户连接失败: %v", err)
+	}
+	return nil
+}
+
+// ConnectionKeys 返回用户当前连接的信息哈希键，用户不在线时返回空
+// 实例异常退出时连接未从集合移除，信息哈希已过期的连接ID在读取时移出集合
+func ConnectionKeys(ctx context.Context, rc *redis.RedisClient, userID int64) ([]string, error) {
+	setKey := UserConnectionsKey(userID)
+	connIDs, err := rc.SMembers(ctx, setKey)
+	if err != nil {
+		return nil, fmt.Errorf("查询用户连接失败: %v", err)
+	}
+	if len(connIDs) == 0 {
+		return nil, nil
+	}
+
+	pipe := rc.GetClient().Pipeline()
+	cmds := make([]*goredis.IntCmd, len(connIDs))
+	for i, connID := range connIDs {
+		cmds[i] = pipe.Exists(ctx, ConnectionKey(userID, connID))
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, fmt.Errorf("查询用户连接失败: %v", err)
+	}
+
+	keys := make([]string, 0, len(connIDs))
+	var stale []interface{}
+	for i, connID := range connIDs {
+		if cmds[i].Val() > 0 {
+			keys = append(keys, ConnectionKey(userID, connID))
+		} else {
+			stale = append(stale, connID)
+		}
+	}
+	// 移除失败不影响本次结果，下次读取时再移除
+	if len(stale) > 0 {
+		_ = rc.SRem(ctx, setKey, stale...)
+	}
+	return keys, nil
+}
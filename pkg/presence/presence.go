@@ -0,0 +1,175 @@
+package presence
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	goredis "github.com/go-redis/redis/v8"
+
+	"goim-social/pkg/config"
+	"goim-social/pkg/redis"
+)
+
+// Redis键，在线状态由im-gateway在连接建立、心跳和断开时写入，各服务直接批量读取，无需调用网关
+const (
+	KeyOnlinePrefix = "presence:online"    // 在线标记 presence:online:{userID}，值为最近确认在线的时间（Unix秒），短TTL
+	KeyLastSeen     = "presence:last_seen" // 最近在线时间哈希，field为用户ID，值为Unix秒
+	KeyOnlineUsers  = "online_users"       // 网关维护的在线用户集合，缓存未启用时回退读取
+)
+
+// DefaultTTL 未配置时在线标记的有效期
+const DefaultTTL = 60 * time.Second
+
+// Status 用户在线状态
+type Status struct {
+	Online   bool
+	LastSeen int64 // 最近在线时间（Unix秒），在线时为最近一次刷新的时间，未知时为0
+}
+
+// Cache 在线状态缓存
+// 在线标记带短TTL，网关按TTL的1/3刷新本实例连接的用户，实例异常退出后其用户的在线标记在TTL内自然失效；
+// 最近在线时间不过期，随在线标记一起更新，用户下线后保留
+type Cache struct {
+	redis   *redis.RedisClient
+	enabled bool
+	ttl     time.Duration
+}
+
+// NewCache 根据配置创建在线状态缓存
+func NewCache(redis *redis.RedisClient, cfg config.PresenceConfig) *Cache {
+	ttl := time.Duration(cfg.TTL) * time.Second
+	if ttl <= 0 {
+		ttl = DefaultTTL
+	}
+	return &Cache{redis: redis, enabled: cfg.Enabled, ttl: ttl}
+}
+
+// Enabled 是否启用缓存，未启用时不写入，读取回退到在线用户集合
+func (c *Cache) Enabled() bool {
+	return c != nil && c.enabled && c.redis != nil
+}
+
+// RefreshInterval 网关刷新在线标记的间隔
+func (c *Cache) RefreshInterval() time.Duration {
+	return c.ttl / 3
+}
+
+// MarkOnline 标记用户在线并刷新最近在线时间，连接建立、心跳和定期刷新时调用
+func (c *Cache) MarkOnline(ctx context.Context, userIDs ...int64) error {
+	if !c.Enabled() || len(userIDs) == 0 {
+		return nil
+	}
+
+	now := time.Now().Unix()
+	lastSeen := make(map[string]interface{}, len(userIDs))
+	pipe := c.redis.GetClient().Pipeline()
+	for _, userID := range userIDs {
+		pipe.Set(ctx, onlineKey(userID), now, c.ttl)
+		lastSeen[strconv.FormatInt(userID, 10)] = now
+	}
+	pipe.HSet(ctx, KeyLastSeen, lastSeen)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("写入在线状态缓存失败: %v", err)
+	}
+	return nil
+}
+
+// MarkOffline 用户全部设备下线时清除在线标记，最近在线时间记为下线时间
+func (c *Cache) MarkOffline(ctx context.Context, userID int64) error {
+	if !c.Enabled() {
+		return nil
+	}
+
+	pipe := c.redis.GetClient().TxPipeline()
+	pipe.Del(ctx, onlineKey(userID))
+	pipe.HSet(ctx, KeyLastSeen, strconv.FormatInt(userID, 10), time.Now().Unix())
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("清除在线状态缓存失败: %v", err)
+	}
+	return nil
+}
+
+// Statuses 批量查询在线状态和最近在线时间，一次往返完成，结果包含每个查询的用户
+// 缓存未启用时按在线用户集合判断，不返回最近在线时间
+func (c *Cache) Statuses(ctx context.Context, userIDs []int64) (map[int64]Status, error) {
+	statuses := make(map[int64]Status, len(userIDs))
+	if len(userIDs) == 0 || c == nil || c.redis == nil {
+		return statuses, nil
+	}
+
+	pipe := c.redis.GetClient().Pipeline()
+	if !c.enabled {
+		cmds := make([]*goredis.BoolCmd, len(userIDs))
+		for i, userID := range userIDs {
+			cmds[i] = pipe.SIsMember(ctx, KeyOnlineUsers, strconv.FormatInt(userID, 10))
+		}
+		if _, err := pipe.Exec(ctx); err != nil {
+			return nil, fmt.Errorf("查询在线用户集合失败: %v", err)
+		}
+		for i, userID := range userIDs {
+			statuses[userID] = Status{Online: cmds[i].Val()}
+		}
+		return statuses, nil
+	}
+
+	keys := make([]string, len(userIDs))
+	fields := make([]string, len(userIDs))
+	for i, userID := range userIDs {
+		keys[i] = onlineKey(userID)
+		fields[i] = strconv.FormatInt(userID, 10)
+	}
+	onlineCmd := pipe.MGet(ctx, keys...)
+	lastSeenCmd := pipe.HMGet(ctx, KeyLastSeen, fields...)
+	if _, err := pipe.Exec(ctx); err != nil && err != goredis.Nil {
+		return nil, fmt.Errorf("查询在线状态缓存失败: %v", err)
+	}
+	return decodeStatuses(userIDs, onlineCmd.Val(), lastSeenCmd.Val()), nil
+}
+
+// Online 批量查询用户是否在线
+func (c *Cache) Online(ctx context.Context, userIDs []int64) (map[int64]bool, error) {
+	statuses, err := c.Statuses(ctx, userIDs)
+	if err != nil {
+		return nil, err
+	}
+	online := make(map[int64]bool, len(statuses))
+	for userID, status := range statuses {
+		online[userID] = status.Online
+	}
+	return online, nil
+}
+
+// decodeStatuses 合并MGET和HMGET的结果，两者与userIDs按下标一一对应，缺失的值为nil
+func decodeStatuses(userIDs []int64, onlineVals, lastSeenVals []interface{}) map[int64]Status {
+	statuses := make(map[int64]Status, len(userIDs))
+	for i, userID := range userIDs {
+		var status Status
+		if i < len(lastSeenVals) {
+			status.LastSeen = parseUnix(lastSeenVals[i])
+		}
+		if i < len(onlineVals) && onlineVals[i] != nil {
+			status.Online = true
+			// 在线标记刷新后最近在线时间可能尚未写入，取两者中较新的
+			status.LastSeen = max(status.LastSeen, parseUnix(onlineVals[i]))
+		}
+		statuses[userID] = status
+	}
+	return statuses
+}
+
+// parseUnix 解析Redis返回的时间戳，无法解析时返回0
+func parseUnix(value interface{}) int64 {
+	str, ok := value.(string)
+	if !ok {
+		return 0
+	}
+	ts, _ := strconv.ParseInt(str, 10, 64)
+	return ts
+}
+
+// onlineKey 用户在线标记键
+func onlineKey(userID int64) string {
+	return fmt.Sprintf("%s:%d", KeyOnlinePrefix, userID)
+}
@@ -0,0 +1,82 @@
+package presence
+
+import (
+	"context"
+	"path"
+	"testing"
+	"time"
+
+	"goim-social/pkg/config"
+)
+
+// TestDecodeStatuses 在线标记存在即在线，最近在线时间取两者中较新的，缺失的值按未知处理
+func TestDecodeStatuses(t *testing.T) {
+	userIDs := []int64{1, 2, 3}
+	onlineVals := []interface{}{"1700000100", nil, nil}
+	lastSeenVals := []interface{}{"1700000000", "1690000000", nil}
+
+	statuses := decodeStatuses(userIDs, onlineVals, lastSeenVals)
+	if len(statuses) != 3 {
+		t.Fatalf("应返回每个查询的用户: %+v", statuses)
+	}
+	if s := statuses[1]; !s.Online || s.LastSeen != 1700000100 {
+		t.Fatalf("在线用户状态错误: %+v", s)
+	}
+	if s := statuses[2]; s.Online || s.LastSeen != 1690000000 {
+		t.Fatalf("离线用户应保留最近在线时间: %+v", s)
+	}
+	if s := statuses[3]; s.Online || s.LastSeen != 0 {
+		t.Fatalf("从未上线的用户状态错误: %+v", s)
+	}
+}
+
+// TestDecodeStatusesShortResult 结果数量少于用户数时不越界
+func TestDecodeStatusesShortResult(t *testing.T) {
+	statuses := decodeStatuses([]int64{1, 2}, []interface{}{"1700000000"}, nil)
+	if !statuses[1].Online || statuses[2].Online {
+		t.Fatalf("状态错误: %+v", statuses)
+	}
+}
+
+// TestNewCache 未配置TTL时使用默认值，刷新间隔为TTL的1/3
+func TestNewCache(t *testing.T) {
+	cache := NewCache(nil, config.PresenceConfig{Enabled: true})
+	if cache.RefreshInterval() != DefaultTTL/3 {
+		t.Fatalf("刷新间隔错误: %v", cache.RefreshInterval())
+	}
+	if cache.Enabled() {
+		t.Fatalf("未配置Redis时不应启用")
+	}
+
+	cache = NewCache(nil, config.PresenceConfig{Enabled: true, TTL: 30})
+	if cache.RefreshInterval() != 10*time.Second {
+		t.Fatalf("刷新间隔错误: %v", cache.RefreshInterval())
+	}
+}
+
+// TestCacheWithoutRedis 未配置缓存时写入为空操作，查询返回空结果
+func TestCacheWithoutRedis(t *testing.T) {
+	ctx := context.Background()
+	var cache *Cache
+	if err := cache.MarkOnline(ctx, 1); err != nil {
+		t.Fatalf("未配置缓存时写入不应报错: %v", err)
+	}
+	if err := cache.MarkOffline(ctx, 1); err != nil {
+		t.Fatalf("未配置缓存时写入不应报错: %v", err)
+	}
+	statuses, err := cache.Statuses(ctx, []int64{1})
+	if err != nil || len(statuses) != 0 {
+		t.Fatalf("未配置缓存时应返回空结果: %v %v", statuses, err)
+	}
+}
+
+// TestConnectionKeys 连接信息哈希键保持conn:{userID}:{connID}格式，用户连接集合不会被按conn:*扫描到
+func TestConnectionKeys(t *testing.T) {
+	if got, want := ConnectionKey(7, "conn-7-100"), "conn:7:conn-7-100"; got != want {
+		t.Errorf("ConnectionKey() = %s, want %s", got, want)
+	}
+	key := UserConnectionsKey(7)
+	if matched, _ := path.Match("conn:*", key); matched {
+		t.Errorf("UserConnectionsKey() = %s, matches conn:*", key)
+	}
+}
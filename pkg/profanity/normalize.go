@@ -0,0 +1,280 @@
+package profanity
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ==================== 文本归一化与匹配 ====================
+// 词表和待检查文本使用同一套归一化：统一小写和全角字符，含字母的片段中把常见的数字/符号替代还原为字母，
+// 词内的 . - _ ~ 视为干扰符号直接去除，* 视为遮挡的字母保留，其余符号和空白作为分词边界；
+// 逐字母拆开书写的单词（f u c k）合并为一个词，中日韩文字与其他文字之间也作为分词边界
+
+// leetRunes 常见的数字/符号替代字母
+var leetRunes = map[rune]rune{
+	'0': 'o',
+	'1': 'i',
+	'3': 'e',
+	'4': 'a',
+	'5': 's',
+	'7': 't',
+	'8': 'b',
+	'@': 'a',
+	'$': 's',
+	'!': 'i',
+	'|': 'i',
+	'+': 't',
+}
+
+// maskRune 遮挡字母的符号，匹配时可对应任意字母
+const maskRune = '*'
+
+// minSpelledRun 逐字母拆开书写时至少连续的单字母数，避免把普通的单字母词合并
+const minSpelledRun = 3
+
+// tokenize 将文本归一化并切分为词
+func tokenize(text string) []string {
+	var tokens []string
+	for _, field := range strings.Fields(text) {
+		field = strings.TrimRightFunc(field, isTrailingPunct)
+		tokens = append(tokens, splitField(foldField(field))...)
+	}
+	return mergeSpelled(tokens)
+}
+
+// foldField 统一大小写和全角字符，含字母的片段中还原数字/符号替代
+func foldField(field string) string {
+	hasLetter := false
+	for _, r := range field {
+		if unicode.IsLetter(r) {
+			hasLetter = true
+			break
+		}
+	}
+
+	var b strings.Builder
+	b.Grow(len(field))
+	for _, r := range field {
+		// 全角ASCII字符转为半角
+		if r >= 0xFF01 && r <= 0xFF5E {
+			r -= 0xFEE0
+		}
+		r = unicode.ToLower(r)
+		if hasLetter {
+			if mapped, ok := leetRunes[r]; ok {
+				r = mapped
+			}
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// splitField 按符号和文字类别切分片段，干扰符号直接去除
+func splitField(field string) []string {
+	var tokens []string
+	var current strings.Builder
+	currentCJK := false
+	flush := func() {
+		if current.Len() > 0 {
+			tokens = append(tokens, current.String())
+			current.Reset()
+		}
+	}
+
+	for _, r := range field {
+		switch {
+		case r == '.' || r == '-' || r == '_' || r == '~':
+			continue
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || r == maskRune:
+			cjk := isCJK(r)
+			if current.Len() > 0 && cjk != currentCJK {
+				flush()
+			}
+			currentCJK = cjk
+			current.WriteRune(r)
+		default:
+			flush()
+		}
+	}
+	flush()
+	return tokens
+}
+
+// mergeSpelled 合并逐字母拆开书写的单词
+func mergeSpelled(tokens []string) []string {
+	merged := make([]string, 0, len(tokens))
+	for i := 0; i < len(tokens); {
+		j := i
+		for j < len(tokens) && isSingleLetter(tokens[j]) {
+			j++
+		}
+		if j-i >= minSpelledRun {
+			merged = append(merged, strings.Join(tokens[i:j], ""))
+			i = j
+			continue
+		}
+		merged = append(merged, tokens[i])
+		i++
+	}
+	return merged
+}
+
+// squeeze 合并连续重复的字符，用于匹配 fuuuck 之类的拉长写法
+func squeeze(s string) string {
+	var b strings.Builder
+	b.Grow(len(s))
+	var last rune = -1
+	for _, r := range s {
+		if r != last {
+			b.WriteRune(r)
+		}
+		last = r
+	}
+	return b.String()
+}
+
+// matchMasked 带遮挡符号的词与词表中的词逐字比较，遮挡符号对应任意字母；至少要露出首字母和两个字母
+func matchMasked(token, word string) bool {
+	if utf8.RuneCountInString(token) != utf8.RuneCountInString(word) {
+		return false
+	}
+	tokenRunes, wordRunes := []rune(token), []rune(word)
+	if tokenRunes[0] == maskRune {
+		return false
+	}
+	visible := 0
+	for i, r := range tokenRunes {
+		if r == maskRune {
+			continue
+		}
+		if r != wordRunes[i] {
+			return false
+		}
+		visible++
+	}
+	return visible >= 2
+}
+
+// isSingleLetter 是否为单个字母
+func isSingleLetter(token string) bool {
+	r, size := utf8.DecodeRuneInString(token)
+	return size == len(token) && unicode.IsLetter(r) && !isCJK(r)
+}
+
+// isCJK 是否为中日韩文字，这些语言不以空格分词，按子串匹配
+func isCJK(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul)
+}
+
+// isTrailingPunct 片段末尾的标点，去除后再还原符号替代，避免 wow! 被当作 wowi
+func isTrailingPunct(r rune) bool {
+	return strings.ContainsRune(".,!?;:)]}\"'。，！？；：）】", r)
+}
+
+// pattern 归一化后的词表项
+type pattern struct {
+	term Term
+	form string
+}
+
+// matcher 由词表编译的匹配器，加载后只读，热加载时整体替换
+type matcher struct {
+	words    map[string]Term      // 单词，按归一化后的形式精确匹配
+	squeezed map[string][]pattern // 单词合并重复字符后的形式
+	prefixes []pattern            // 以*结尾的前缀词
+	phrases  []pattern            // 多个单词组成的短语，形式为前后带空格的词序列
+	compact  []pattern            // 中日韩词语，在去除符号和空格后的文本中按子串匹配
+}
+
+// newMatcher 编译词表
+func newMatcher(terms []Term) *matcher {
+	m := &matcher{
+		words:    make(map[string]Term),
+		squeezed: make(map[string][]pattern),
+	}
+	for _, term := range terms {
+		word := strings.TrimSpace(term.Word)
+		prefix := strings.HasSuffix(word, "*")
+		tokens := tokenize(strings.TrimSuffix(word, "*"))
+		if len(tokens) == 0 {
+			continue
+		}
+
+		switch {
+		case strings.IndexFunc(word, isCJK) >= 0:
+			m.compact = append(m.compact, pattern{term: term, form: strings.Join(tokens, "")})
+		case prefix:
+			m.prefixes = append(m.prefixes, pattern{term: term, form: strings.Join(tokens, "")})
+		case len(tokens) > 1:
+			m.phrases = append(m.phrases, pattern{term: term, form: " " + strings.Join(tokens, " ") + " "})
+		default:
+			m.words[tokens[0]] = term
+			key := squeeze(tokens[0])
+			m.squeezed[key] = append(m.squeezed[key], pattern{term: term, form: tokens[0]})
+		}
+	}
+	return m
+}
+
+// match 返回文本命中的词表项，同一个词只返回一次
+func (m *matcher) match(text string) []Term {
+	tokens := tokenize(text)
+	if len(tokens) == 0 {
+		return nil
+	}
+
+	var matched []Term
+	seen := make(map[Term]bool)
+	add := func(term Term) {
+		if !seen[term] {
+			seen[term] = true
+			matched = append(matched, term)
+		}
+	}
+
+	for _, token := range tokens {
+		if term, ok := m.words[token]; ok {
+			add(term)
+			continue
+		}
+		squeezed := squeeze(token)
+		// 拉长写法至少与原词一样长，避免 as 命中 ass
+		for _, p := range m.squeezed[squeezed] {
+			if len(token) >= len(p.form) {
+				add(p.term)
+			}
+		}
+		for _, p := range m.prefixes {
+			if strings.HasPrefix(token, p.form) || strings.HasPrefix(squeezed, squeeze(p.form)) && len(token) >= len(p.form) {
+				add(p.term)
+			}
+		}
+		if strings.ContainsRune(token, maskRune) {
+			for word, term := range m.words {
+				if matchMasked(token, word) {
+					add(term)
+				}
+			}
+		}
+	}
+
+	if len(m.phrases) > 0 {
+		joined := " " + strings.Join(tokens, " ") + " "
+		for _, p := range m.phrases {
+			if strings.Contains(joined, p.form) {
+				add(p.term)
+			}
+		}
+	}
+	if len(m.compact) > 0 {
+		compact := strings.ReplaceAll(strings.Join(tokens, ""), string(maskRune), "")
+		for _, p := range m.compact {
+			if strings.Contains(compact, p.form) {
+				add(p.term)
+			}
+		}
+	}
+	return matched
+}
@@ -0,0 +1,285 @@
+package profanity
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"goim-social/pkg/config"
+)
+
+// Severity 命中后的处理级别，数值越大越严重
+type Severity int
+
+// 处理级别
+const (
+	SeverityNone   Severity = iota // 未命中
+	SeverityWarn                   // 只记录，不影响发布
+	SeverityHold                   // 拦截等待人工复核：内容保持待审核，评论隐藏
+	SeverityReject                 // 直接拒绝
+)
+
+// ParseSeverity 解析处理级别名称
+func ParseSeverity(name string) (Severity, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "warn":
+		return SeverityWarn, nil
+	case "hold":
+		return SeverityHold, nil
+	case "reject":
+		return SeverityReject, nil
+	default:
+		return SeverityNone, fmt.Errorf("未知的处理级别: %s", name)
+	}
+}
+
+// String 处理级别名称
+func (s Severity) String() string {
+	switch s {
+	case SeverityWarn:
+		return "warn"
+	case SeverityHold:
+		return "hold"
+	case SeverityReject:
+		return "reject"
+	default:
+		return "none"
+	}
+}
+
+// Term 词表中的一个词或短语
+type Term struct {
+	Word     string
+	Language string
+	Severity Severity
+}
+
+// Result 检查结果，Severity为所有命中词中最严重的级别
+type Result struct {
+	Severity Severity
+	Matches  []Term
+}
+
+// Words 命中的词，用于记录原因
+func (r Result) Words() string {
+	words := make([]string, len(r.Matches))
+	for i, term := range r.Matches {
+		words[i] = fmt.Sprintf("%s(%s)", term.Word, term.Language)
+	}
+	return strings.Join(words, ", ")
+}
+
+// ParseList 解析词表，每行一个词或短语，可用 词|级别 单独指定处理级别，空行和#开头的行忽略
+func ParseList(language string, r io.Reader, defaultSeverity Severity) ([]Term, error) {
+	var terms []Term
+	scanner := bufio.NewScanner(r)
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		term := Term{Word: line, Language: language, Severity: defaultSeverity}
+		if idx := strings.LastIndex(line, "|"); idx > 0 {
+			severity, err := ParseSeverity(line[idx+1:])
+			if err != nil {
+				return nil, fmt.Errorf("第%d行: %v", lineNo, err)
+			}
+			term.Word = strings.TrimSpace(line[:idx])
+			term.Severity = severity
+		}
+		terms = append(terms, term)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return terms, nil
+}
+
+// wordList 词表文件
+type wordList struct {
+	language string
+	path     string
+}
+
+// parseWordLists 解析 语言:路径 形式的词表配置，未注明语言时取文件名
+func parseWordLists(spec string) []wordList {
+	var lists []wordList
+	for _, item := range strings.Split(spec, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		language, path, ok := strings.Cut(item, ":")
+		if !ok {
+			path = item
+			language = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+		}
+		lists = append(lists, wordList{language: strings.TrimSpace(language), path: strings.TrimSpace(path)})
+	}
+	return lists
+}
+
+// Filter 违禁词过滤器，内容和评论等接受文本的服务共用
+// 词表文件变更后按配置的间隔自动重新加载，加载失败时继续使用原词表
+type Filter struct {
+	lists           []wordList
+	defaultSeverity Severity
+	dryRun          bool
+	interval        time.Duration
+
+	matcher  atomic.Pointer[matcher]
+	modTimes map[string]time.Time // 只由加载和检查变更的goroutine访问
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+// New 根据配置创建过滤器并加载词表，未启用时返回nil，nil过滤器不拦截任何文本
+func New(cfg config.ProfanityConfig) (*Filter, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+
+	defaultSeverity := SeverityHold
+	if cfg.DefaultSeverity != "" {
+		severity, err := ParseSeverity(cfg.DefaultSeverity)
+		if err != nil {
+			return nil, err
+		}
+		defaultSeverity = severity
+	}
+
+	f := &Filter{
+		lists:           parseWordLists(cfg.WordLists),
+		defaultSeverity: defaultSeverity,
+		dryRun:          cfg.DryRun,
+		interval:        time.Duration(cfg.ReloadInterval) * time.Second,
+		stop:            make(chan struct{}),
+	}
+	if len(f.lists) == 0 {
+		return nil, fmt.Errorf("启用违禁词过滤时必须配置词表")
+	}
+	if err := f.reload(); err != nil {
+		return nil, err
+	}
+	if f.interval > 0 {
+		go f.watch()
+	}
+	return f, nil
+}
+
+// NewFromTerms 使用给定的词创建过滤器，不关联词表文件
+func NewFromTerms(terms []Term, dryRun bool) *Filter {
+	f := &Filter{dryRun: dryRun, stop: make(chan struct{})}
+	f.matcher.Store(newMatcher(terms))
+	return f
+}
+
+// Inspect 检查文本，返回全部命中的词，不受试运行影响
+func (f *Filter) Inspect(texts ...string) Result {
+	var result Result
+	if f == nil {
+		return result
+	}
+	m := f.matcher.Load()
+	if m == nil {
+		return result
+	}
+
+	for _, text := range texts {
+		for _, term := range m.match(text) {
+			result.Matches = append(result.Matches, term)
+			result.Severity = max(result.Severity, term.Severity)
+		}
+	}
+	return result
+}
+
+// Check 检查文本，source标识文本来源，如 comment:123
+// 试运行时只记录本应命中的词，返回未命中的结果
+func (f *Filter) Check(source string, texts ...string) Result {
+	result := f.Inspect(texts...)
+	if result.Severity == SeverityNone || !f.dryRun {
+		return result
+	}
+
+	log.Printf("违禁词过滤试运行命中: source=%s, severity=%s, words=%s", source, result.Severity, result.Words())
+	return Result{}
+}
+
+// Close 停止检查词表变更
+func (f *Filter) Close() {
+	if f == nil {
+		return
+	}
+	f.stopOnce.Do(func() { close(f.stop) })
+}
+
+// reload 重新加载全部词表，任一文件加载失败时不替换原词表
+func (f *Filter) reload() error {
+	var terms []Term
+	modTimes := make(map[string]time.Time, len(f.lists))
+	for _, list := range f.lists {
+		file, err := os.Open(list.path)
+		if err != nil {
+			return fmt.Errorf("打开词表失败: %v", err)
+		}
+		info, err := file.Stat()
+		if err == nil {
+			modTimes[list.path] = info.ModTime()
+		}
+		listTerms, err := ParseList(list.language, file, f.defaultSeverity)
+		file.Close()
+		if err != nil {
+			return fmt.Errorf("解析词表%s失败: %v", list.path, err)
+		}
+		terms = append(terms, listTerms...)
+	}
+
+	f.matcher.Store(newMatcher(terms))
+	f.modTimes = modTimes
+	return nil
+}
+
+// changed 词表文件是否有变更
+func (f *Filter) changed() bool {
+	for _, list := range f.lists {
+		info, err := os.Stat(list.path)
+		if err != nil {
+			continue
+		}
+		if !info.ModTime().Equal(f.modTimes[list.path]) {
+			return true
+		}
+	}
+	return false
+}
+
+// watch 定期检查词表文件，变更后重新加载
+func (f *Filter) watch() {
+	ticker := time.NewTicker(f.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-f.stop:
+			return
+		case <-ticker.C:
+			if !f.changed() {
+				continue
+			}
+			if err := f.reload(); err != nil {
+				log.Printf("重新加载违禁词表失败，继续使用原词表: %v", err)
+				continue
+			}
+			log.Printf("违禁词表已重新加载")
+		}
+	}
+}
@@ -0,0 +1,195 @@
+package profanity
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"goim-social/pkg/config"
+)
+
+// testTerms 测试词表
+func testTerms() []Term {
+	return []Term{
+		{Word: "fuck", Language: "en", Severity: SeverityReject},
+		{Word: "shit", Language: "en", Severity: SeverityHold},
+		{Word: "ass", Language: "en", Severity: SeverityWarn},
+		{Word: "idiot*", Language: "en", Severity: SeverityWarn},
+		{Word: "kill yourself", Language: "en", Severity: SeverityReject},
+		{Word: "傻逼", Language: "zh", Severity: SeverityReject},
+		{Word: "puta", Language: "es", Severity: SeverityHold},
+	}
+}
+
+// TestInspectObfuscated 大小写、全角、符号替代、插入符号、拆开书写、拉长和遮挡的写法都能命中
+func TestInspectObfuscated(t *testing.T) {
+	f := NewFromTerms(testTerms(), false)
+	cases := map[string]string{
+		"FUCK this":             "fuck",
+		"ｆｕｃｋ":                  "fuck",
+		"what the f.u.c.k":      "fuck",
+		"f u c k you":           "fuck",
+		"fuuuuuck":              "fuck",
+		"f*ck off":              "fuck",
+		"holy sh!t!":            "shit",
+		"$h1t happens":          "shit",
+		"s-h-i-t":               "shit",
+		"you a$$":               "ass",
+		"such an 1d10ts":        "idiot*",
+		"just k1ll   yourself.": "kill yourself",
+		"你就是个傻*逼":               "傻逼",
+		"你是傻 逼吧":                "傻逼",
+		"eres una PUTA":         "puta",
+	}
+	for text, want := range cases {
+		result := f.Inspect(text)
+		if len(result.Matches) != 1 || result.Matches[0].Word != want {
+			t.Errorf("%q 应命中 %q，实际: %+v", text, want, result.Matches)
+		}
+	}
+}
+
+// TestInspectClean 正常文本不应误判
+func TestInspectClean(t *testing.T) {
+	f := NewFromTerms(testTerms(), false)
+	for _, text := range []string{
+		"as soon as possible",
+		"the class passed the assessment",
+		"I scored 100 points in 2024!",
+		"wow! great post",
+		"a b c",
+		"shitake mushrooms",
+		"***",
+		"我们一起去吃饭",
+	} {
+		if result := f.Inspect(text); result.Severity != SeverityNone {
+			t.Errorf("%q 不应命中，实际: %+v", text, result.Matches)
+		}
+	}
+}
+
+// TestInspectSeverity 结果级别取命中词中最严重的
+func TestInspectSeverity(t *testing.T) {
+	f := NewFromTerms(testTerms(), false)
+	result := f.Inspect("what an ass", "sh1t, f*ck")
+	if result.Severity != SeverityReject || len(result.Matches) != 3 {
+		t.Fatalf("结果错误: %+v", result)
+	}
+	if words := result.Words(); !strings.Contains(words, "fuck(en)") {
+		t.Fatalf("命中词错误: %s", words)
+	}
+}
+
+// TestCheckDryRun 试运行时不返回命中结果
+func TestCheckDryRun(t *testing.T) {
+	f := NewFromTerms(testTerms(), true)
+	if result := f.Check("comment:1", "fuck"); result.Severity != SeverityNone {
+		t.Fatalf("试运行不应拦截: %+v", result)
+	}
+	if result := f.Inspect("fuck"); result.Severity != SeverityReject {
+		t.Fatalf("Inspect不受试运行影响: %+v", result)
+	}
+
+	var nilFilter *Filter
+	if result := nilFilter.Check("comment:1", "fuck"); result.Severity != SeverityNone {
+		t.Fatalf("未启用时不应拦截: %+v", result)
+	}
+}
+
+// TestParseList 解析词表，未注明级别时使用默认级别
+func TestParseList(t *testing.T) {
+	terms, err := ParseList("en", strings.NewReader("# comment\n\nshit\nfuck | reject\nkill yourself|warn\n"), SeverityHold)
+	if err != nil {
+		t.Fatalf("解析失败: %v", err)
+	}
+	want := []Term{
+		{Word: "shit", Language: "en", Severity: SeverityHold},
+		{Word: "fuck", Language: "en", Severity: SeverityReject},
+		{Word: "kill yourself", Language: "en", Severity: SeverityWarn},
+	}
+	if len(terms) != len(want) {
+		t.Fatalf("词数错误: %+v", terms)
+	}
+	for i := range want {
+		if terms[i] != want[i] {
+			t.Fatalf("第%d个词错误: %+v", i, terms[i])
+		}
+	}
+
+	if _, err := ParseList("en", strings.NewReader("shit|block\n"), SeverityHold); err == nil {
+		t.Fatalf("未知级别应报错")
+	}
+}
+
+// TestReload 词表文件变更后重新加载，加载失败时保留原词表
+func TestReload(t *testing.T) {
+	dir := t.TempDir()
+	enPath := filepath.Join(dir, "en.txt")
+	zhPath := filepath.Join(dir, "zh.txt")
+	writeFile(t, enPath, "shit\n")
+	writeFile(t, zhPath, "傻逼|reject\n")
+
+	f, err := New(config.ProfanityConfig{
+		Enabled:         true,
+		WordLists:       "english:" + enPath + "," + zhPath,
+		DefaultSeverity: "hold",
+	})
+	if err != nil {
+		t.Fatalf("创建过滤器失败: %v", err)
+	}
+	defer f.Close()
+
+	result := f.Inspect("sh1t", "傻逼")
+	if result.Severity != SeverityReject || len(result.Matches) != 2 || result.Matches[0].Language != "english" || result.Matches[1].Language != "zh" {
+		t.Fatalf("初始词表错误: %+v", result)
+	}
+
+	writeFile(t, enPath, "crap|warn\n")
+	touch(t, enPath)
+	if !f.changed() {
+		t.Fatalf("应检测到词表变更")
+	}
+	if err := f.reload(); err != nil {
+		t.Fatalf("重新加载失败: %v", err)
+	}
+	if f.Inspect("shit").Severity != SeverityNone || f.Inspect("cr4p").Severity != SeverityWarn {
+		t.Fatalf("重新加载后的词表错误")
+	}
+
+	writeFile(t, enPath, "crap|block\n")
+	if err := f.reload(); err == nil {
+		t.Fatalf("无效词表应加载失败")
+	}
+	if f.Inspect("crap").Severity != SeverityWarn {
+		t.Fatalf("加载失败时应保留原词表")
+	}
+}
+
+// TestNewDisabled 未启用时返回nil
+func TestNewDisabled(t *testing.T) {
+	f, err := New(config.ProfanityConfig{Enabled: false, WordLists: "missing.txt"})
+	if err != nil || f != nil {
+		t.Fatalf("未启用时应返回nil: %v %v", f, err)
+	}
+	if _, err := New(config.ProfanityConfig{Enabled: true}); err == nil {
+		t.Fatalf("未配置词表应报错")
+	}
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("写入词表失败: %v", err)
+	}
+}
+
+// touch 修改文件时间，避免文件系统时间精度导致检测不到变更
+func touch(t *testing.T, path string) {
+	t.Helper()
+	future := time.Now().Add(time.Minute)
+	if err := os.Chtimes(path, future, future); err != nil {
+		t.Fatalf("修改文件时间失败: %v", err)
+	}
+}
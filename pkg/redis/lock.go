@@ -0,0 +1,232 @@
+package redis
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// ============ 分布式锁 ============
+// 单实例Redlock：以SET key token NX PX获取锁，token为每次获取时生成的随机值。释放和续期通过Lua脚本
+// 先比较token再操作，锁过期后被其他实例获取时，旧持有者的释放或续期不会影响新的持有者。
+//
+// TTL调优：TTL应为临界区正常耗时的3倍以上，过短会在GC停顿、慢查询时提前过期导致互斥失效；
+// 过长则持有者崩溃后其他实例要等到过期才能获取。耗时不确定的操作开启自动续期，每TTL/3续期一次，
+// 连续续期失败超过TTL或锁已被他人持有时视为锁丢失，WithLock会取消传给临界区的context。
+//
+// 避免死锁：锁都带TTL，持有者崩溃不会永久占用；一个流程尽量只持有一把锁，确需多把时所有流程按相同顺序获取；
+// 锁不可重入，持锁期间不要调用会再次获取同一把锁的方法。锁只在TTL内保证互斥，写入仍应有数据库条件更新兜底
+
+var (
+	// ErrLockNotObtained 锁被其他持有者占用，在等待时间内未能获取
+	ErrLockNotObtained = errors.New("redis: lock not obtained")
+	// ErrLockNotHeld 锁已过期或被其他持有者获取
+	ErrLockNotHeld = errors.New("redis: lock not held")
+)
+
+// 分布式锁默认参数
+const (
+	DefaultLockTTL           = 10 * time.Second
+	DefaultLockRetryInterval = 50 * time.Millisecond
+)
+
+// releaseLockScript token一致时删除锁
+var releaseLockScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+// extendLockScript token一致时重置锁的过期时间
+var extendLockScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return 0
+`)
+
+// LockOptions 分布式锁参数
+type LockOptions struct {
+	TTL           time.Duration // 锁过期时间，默认10秒
+	WaitTimeout   time.Duration // 锁被占用时的最长等待时间，0表示只尝试一次
+	RetryInterval time.Duration // 等待期间的重试间隔，默认50毫秒
+	AutoExtend    bool          // 持有期间自动续期，直到释放
+}
+
+// withDefaults 补全未设置的参数
+func (o LockOptions) withDefaults() LockOptions {
+	if o.TTL <= 0 {
+		o.TTL = DefaultLockTTL
+	}
+	if o.RetryInterval <= 0 {
+		o.RetryInterval = DefaultLockRetryInterval
+	}
+	return o
+}
+
+// Lock 已获取的分布式锁
+type Lock struct {
+	client *redis.Client
+	key    string
+	token  string
+	ttl    time.Duration
+
+	lost     chan struct{} // 自动续期发现锁丢失时关闭
+	stop     chan struct{} // 释放时关闭，停止自动续期
+	done     chan struct{} // 自动续期协程退出时关闭
+	stopOnce sync.Once
+}
+
+// ObtainLock 获取分布式锁，锁被占用时在WaitTimeout内按RetryInterval重试，超时返回ErrLockNotObtained
+func (r *RedisClient) ObtainLock(ctx context.Context, key string, opts LockOptions) (*Lock, error) {
+	opts = opts.withDefaults()
+	token, err := newLockToken()
+	if err != nil {
+		return nil, err
+	}
+
+	deadline := time.Now().Add(opts.WaitTimeout)
+	for {
+		ok, err := r.client.SetNX(ctx, key, token, opts.TTL).Result()
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			break
+		}
+		if time.Now().Add(opts.RetryInterval).After(deadline) {
+			return nil, ErrLockNotObtained
+		}
+
+		timer := time.NewTimer(opts.RetryInterval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+
+	lock := &Lock{
+		client: r.client,
+		key:    key,
+		token:  token,
+		ttl:    opts.TTL,
+		lost:   make(chan struct{}),
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+	if opts.AutoExtend {
+		go lock.keepAlive()
+	} else {
+		close(lock.done)
+	}
+	return lock, nil
+}
+
+// WithLock 持有分布式锁执行fn，执行完毕后释放锁
+// 开启自动续期时锁丢失会取消传给fn的context；释放失败说明锁已过期，不影响fn的结果
+func (r *RedisClient) WithLock(ctx context.Context, key string, opts LockOptions, fn func(ctx context.Context) error) error {
+	lock, err := r.ObtainLock(ctx, key, opts)
+	if err != nil {
+		return err
+	}
+
+	lockCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		select {
+		case <-lock.Lost():
+			cancel()
+		case <-lockCtx.Done():
+		}
+	}()
+	defer func() {
+		releaseCtx, releaseCancel := context.WithTimeout(context.Background(), time.Second)
+		defer releaseCancel()
+		_ = lock.Release(releaseCtx)
+	}()
+
+	return fn(lockCtx)
+}
+
+// Key 锁的键
+func (l *Lock) Key() string {
+	return l.key
+}
+
+// Lost 自动续期发现锁丢失时关闭的通道，未开启自动续期时不会关闭
+func (l *Lock) Lost() <-chan struct{} {
+	return l.lost
+}
+
+// Extend 将锁的过期时间重置为ttl，锁已不属于自己时返回ErrLockNotHeld
+func (l *Lock) Extend(ctx context.Context, ttl time.Duration) error {
+	res, err := extendLockScript.Run(ctx, l.client, []string{l.key}, l.token, ttl.Milliseconds()).Int64()
+	if err != nil {
+		return err
+	}
+	if res == 0 {
+		return ErrLockNotHeld
+	}
+	return nil
+}
+
+// Release 停止自动续期并释放锁，锁已过期或被他人获取时返回ErrLockNotHeld，可重复调用
+func (l *Lock) Release(ctx context.Context) error {
+	l.stopOnce.Do(func() { close(l.stop) })
+	<-l.done
+
+	res, err := releaseLockScript.Run(ctx, l.client, []string{l.key}, l.token).Int64()
+	if err != nil {
+		return err
+	}
+	if res == 0 {
+		return ErrLockNotHeld
+	}
+	return nil
+}
+
+// keepAlive 每TTL/3续期一次，锁已被他人持有或距上次成功续期超过TTL时视为锁丢失
+func (l *Lock) keepAlive() {
+	defer close(l.done)
+
+	interval := l.ttl / 3
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	extendedAt := time.Now()
+	for {
+		select {
+		case <-l.stop:
+			return
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), interval)
+			err := l.Extend(ctx, l.ttl)
+			cancel()
+			if err == nil {
+				extendedAt = time.Now()
+				continue
+			}
+			if errors.Is(err, ErrLockNotHeld) || time.Since(extendedAt) >= l.ttl {
+				close(l.lost)
+				return
+			}
+		}
+	}
+}
+
+// newLockToken 生成锁的随机token
+func newLockToken() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
@@ -0,0 +1,309 @@
+package redis
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fakeRedis 只支持分布式锁所需命令（SET NX EX/PX、GET和锁的两个脚本）的内存Redis服务
+type fakeRedis struct {
+	mu   sync.Mutex
+	data map[string]fakeEntry
+}
+
+type fakeEntry struct {
+	value    string
+	expireAt time.Time
+}
+
+// newFakeRedisClient 启动fakeRedis并返回连接到它的客户端，测试结束时关闭
+func newFakeRedisClient(t *testing.T) *RedisClient {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("监听失败: %v", err)
+	}
+	f := &fakeRedis{data: make(map[string]fakeEntry)}
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go f.serve(conn)
+		}
+	}()
+
+	client := NewRedisClient(ln.Addr().String())
+	t.Cleanup(func() {
+		client.Close()
+		ln.Close()
+	})
+	return client
+}
+
+func (f *fakeRedis) serve(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	for {
+		args, err := readCommand(r)
+		if err != nil {
+			return
+		}
+		if _, err := io.WriteString(conn, f.exec(args)); err != nil {
+			return
+		}
+	}
+}
+
+// readCommand 读取一条RESP数组格式的命令
+func readCommand(r *bufio.Reader) ([]string, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasPrefix(line, "*") {
+		return nil, fmt.Errorf("unexpected line %q", line)
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(line[1:]))
+	if err != nil {
+		return nil, err
+	}
+	args := make([]string, n)
+	for i := range args {
+		if line, err = r.ReadString('\n'); err != nil {
+			return nil, err
+		}
+		size, err := strconv.Atoi(strings.TrimSpace(line[1:]))
+		if err != nil {
+			return nil, err
+		}
+		buf := make([]byte, size+2)
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return nil, err
+		}
+		args[i] = string(buf[:size])
+	}
+	return args, nil
+}
+
+func (f *fakeRedis) exec(args []string) string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	switch strings.ToLower(args[0]) {
+	case "set":
+		nx := false
+		var expireAt time.Time
+		for i := 3; i < len(args); i++ {
+			switch strings.ToLower(args[i]) {
+			case "nx":
+				nx = true
+			case "ex", "px":
+				n, _ := strconv.ParseInt(args[i+1], 10, 64)
+				unit := time.Second
+				if strings.ToLower(args[i]) == "px" {
+					unit = time.Millisecond
+				}
+				expireAt = time.Now().Add(time.Duration(n) * unit)
+				i++
+			}
+		}
+		if _, ok := f.get(args[1]); ok && nx {
+			return "$-1\r\n"
+		}
+		f.data[args[1]] = fakeEntry{value: args[2], expireAt: expireAt}
+		return "+OK\r\n"
+	case "get":
+		value, ok := f.get(args[1])
+		if !ok {
+			return "$-1\r\n"
+		}
+		return fmt.Sprintf("$%d\r\n%s\r\n", len(value), value)
+	case "evalsha":
+		// 锁的脚本：KEYS[1]为锁，ARGV[1]为token，续期时ARGV[2]为毫秒数
+		key, token := args[3], args[4]
+		if value, ok := f.get(key); !ok || value != token {
+			return ":0\r\n"
+		}
+		switch args[1] {
+		case releaseLockScript.Hash():
+			delete(f.data, key)
+		case extendLockScript.Hash():
+			ms, _ := strconv.ParseInt(args[5], 10, 64)
+			f.data[key] = fakeEntry{value: token, expireAt: time.Now().Add(time.Duration(ms) * time.Millisecond)}
+		default:
+			return "-NOSCRIPT No matching script\r\n"
+		}
+		return ":1\r\n"
+	}
+	return "-ERR unknown command\r\n"
+}
+
+// get 读取未过期的值，已过期的键随之删除
+func (f *fakeRedis) get(key string) (string, bool) {
+	entry, ok := f.data[key]
+	if !ok {
+		return "", false
+	}
+	if !entry.expireAt.IsZero() && !time.Now().Before(entry.expireAt) {
+		delete(f.data, key)
+		return "", false
+	}
+	return entry.value, true
+}
+
+// TestLockOptionsDefaults 未设置的TTL和重试间隔使用默认值，已设置的保持不变
+func TestLockOptionsDefaults(t *testing.T) {
+	opts := LockOptions{}.withDefaults()
+	if opts.TTL != DefaultLockTTL || opts.RetryInterval != DefaultLockRetryInterval {
+		t.Fatalf("默认参数为 %+v", opts)
+	}
+
+	opts = LockOptions{TTL: 3 * time.Second, RetryInterval: 10 * time.Millisecond, WaitTimeout: time.Second}.withDefaults()
+	if opts.TTL != 3*time.Second || opts.RetryInterval != 10*time.Millisecond || opts.WaitTimeout != time.Second {
+		t.Fatalf("已设置的参数被覆盖: %+v", opts)
+	}
+}
+
+// TestLockTokenUnique 每次获取锁的token都不相同
+func TestLockTokenUnique(t *testing.T) {
+	seen := make(map[string]bool)
+	for i := 0; i < 100; i++ {
+		token, err := newLockToken()
+		if err != nil {
+			t.Fatalf("生成token失败: %v", err)
+		}
+		if len(token) != 32 || seen[token] {
+			t.Fatalf("token无效或重复: %s", token)
+		}
+		seen[token] = true
+	}
+}
+
+// TestWithLockUnavailable Redis不可用时返回错误且不执行临界区
+func TestWithLockUnavailable(t *testing.T) {
+	client := NewRedisClient("127.0.0.1:1")
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	called := false
+	err := client.WithLock(ctx, "lock:test", LockOptions{}, func(ctx context.Context) error {
+		called = true
+		return nil
+	})
+	if err == nil || err == ErrLockNotObtained {
+		t.Fatalf("Redis不可用时应返回连接错误，实际为 %v", err)
+	}
+	if called {
+		t.Fatalf("未获取到锁时不应执行临界区")
+	}
+}
+
+// TestLockForeignTokenCannotRelease token不一致时既不能释放也不能续期，锁仍由原持有者持有
+func TestLockForeignTokenCannotRelease(t *testing.T) {
+	client := newFakeRedisClient(t)
+	ctx := context.Background()
+
+	lock, err := client.ObtainLock(ctx, "lock:test", LockOptions{})
+	if err != nil {
+		t.Fatalf("获取锁失败: %v", err)
+	}
+	foreign := &Lock{client: client.client, key: lock.key, token: "foreign", stop: make(chan struct{}), done: make(chan struct{})}
+	close(foreign.done)
+
+	if err := foreign.Extend(ctx, time.Minute); !errors.Is(err, ErrLockNotHeld) {
+		t.Errorf("其他token续期返回 %v，应为ErrLockNotHeld", err)
+	}
+	if err := foreign.Release(ctx); !errors.Is(err, ErrLockNotHeld) {
+		t.Errorf("其他token释放返回 %v，应为ErrLockNotHeld", err)
+	}
+	if _, err := client.ObtainLock(ctx, "lock:test", LockOptions{}); !errors.Is(err, ErrLockNotObtained) {
+		t.Fatalf("其他token释放后锁被再次获取: %v", err)
+	}
+
+	if err := lock.Release(ctx); err != nil {
+		t.Fatalf("持有者释放锁失败: %v", err)
+	}
+	if _, err := client.ObtainLock(ctx, "lock:test", LockOptions{}); err != nil {
+		t.Errorf("释放后获取锁失败: %v", err)
+	}
+}
+
+// TestLockExpiredReleaseKeepsNewHolder 锁过期被他人获取后，旧持有者的释放返回ErrLockNotHeld且不影响新持有者
+func TestLockExpiredReleaseKeepsNewHolder(t *testing.T) {
+	client := newFakeRedisClient(t)
+	ctx := context.Background()
+
+	old, err := client.ObtainLock(ctx, "lock:test", LockOptions{TTL: 20 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("获取锁失败: %v", err)
+	}
+	current, err := client.ObtainLock(ctx, "lock:test", LockOptions{WaitTimeout: time.Second, RetryInterval: 5 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("锁过期后获取失败: %v", err)
+	}
+
+	if err := old.Release(ctx); !errors.Is(err, ErrLockNotHeld) {
+		t.Errorf("旧持有者释放返回 %v，应为ErrLockNotHeld", err)
+	}
+	if err := current.Extend(ctx, time.Minute); err != nil {
+		t.Errorf("旧持有者释放后新持有者续期失败: %v", err)
+	}
+}
+
+// TestWithLockContention 并发竞争同一把锁时临界区互斥执行，等待中的调用者最终都能获取到锁
+func TestWithLockContention(t *testing.T) {
+	client := newFakeRedisClient(t)
+	const workers = 8
+
+	var inside, maxInside, completed int32
+	var wg sync.WaitGroup
+	errs := make(chan error, workers)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			opts := LockOptions{WaitTimeout: 5 * time.Second, RetryInterval: time.Millisecond}
+			errs <- client.WithLock(context.Background(), "lock:test", opts, func(ctx context.Context) error {
+				n := atomic.AddInt32(&inside, 1)
+				for {
+					peak := atomic.LoadInt32(&maxInside)
+					if n <= peak || atomic.CompareAndSwapInt32(&maxInside, peak, n) {
+						break
+					}
+				}
+				time.Sleep(2 * time.Millisecond)
+				atomic.AddInt32(&inside, -1)
+				atomic.AddInt32(&completed, 1)
+				return nil
+			})
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Errorf("WithLock返回错误: %v", err)
+		}
+	}
+	if maxInside != 1 {
+		t.Errorf("临界区最多同时有%d个调用者，应为1", maxInside)
+	}
+	if completed != workers {
+		t.Errorf("完成%d次临界区，应为%d", completed, workers)
+	}
+}
@@ -75,6 +75,11 @@ func (r *RedisClient) Keys(ctx context.Context, pattern string) ([]string, error
 	return r.client.Keys(ctx, pattern).Result()
 }
 
+// Scan 按 pattern 增量遍历 key，返回本批 key 和下一次遍历的游标，游标为 0 表示遍历结束
+func (r *RedisClient) Scan(ctx context.Context, cursor uint64, pattern string, count int64) ([]string, uint64, error) {
+	return r.client.Scan(ctx, cursor, pattern, count).Result()
+}
+
 // SAdd 将成员添加到 set
 func (r *RedisClient) SAdd(ctx context.Context, key string, members ...interface{}) error {
 	return r.client.SAdd(ctx, key, members...).Err()
@@ -105,6 +110,26 @@ func (r *RedisClient) Publish(ctx context.Context, channel string, message inter
 	return r.client.Publish(ctx, channel, message).Err()
 }
 
+// PublishCount 发布消息到频道，返回接收到消息的订阅者数
+func (r *RedisClient) PublishCount(ctx context.Context, channel string, message interface{}) (int64, error) {
+	return r.client.Publish(ctx, channel, message).Result()
+}
+
+// PubSubChannels 列出匹配模式的活跃频道（至少有一个订阅者）
+func (r *RedisClient) PubSubChannels(ctx context.Context, pattern string) ([]string, error) {
+	return r.client.PubSubChannels(ctx, pattern).Result()
+}
+
+// PubSubNumSub 获取频道的订阅者数
+func (r *RedisClient) PubSubNumSub(ctx context.Context, channels ...string) (map[string]int64, error) {
+	return r.client.PubSubNumSub(ctx, channels...).Result()
+}
+
+// Ping 检查Redis连接
+func (r *RedisClient) Ping(ctx context.Context) error {
+	return r.client.Ping(ctx).Err()
+}
+
 // Subscribe 订阅频道
 func (r *RedisClient) Subscribe(ctx context.Context, channels ...string) *redis.PubSub {
 	return r.client.Subscribe(ctx, channels...)
@@ -4,11 +4,13 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	kratoslog "github.com/go-kratos/kratos/v2/log"
 	"google.golang.org/grpc"
 
+	"goim-social/pkg/auth"
 	"goim-social/pkg/client"
 	"goim-social/pkg/config"
 	"goim-social/pkg/database"
@@ -29,6 +31,7 @@ type Application struct {
 	serverManager  *ServerManager
 	clientManager  *client.ClientManager
 	lifecycle      *lifecycle.LifecycleManager
+	startedAt      time.Time
 
 	// 基础设施组件
 	mongoDB       *database.MongoDB
@@ -37,6 +40,9 @@ type Application struct {
 	redisClient   *redis.RedisClient
 	kafkaProducer *kafka.Producer
 
+	// 认证
+	authProvider auth.Provider
+
 	// 中间件
 	authMiddleware    *middleware.AuthMiddleware
 	loggingMiddleware *middleware.LoggingMiddleware
@@ -65,7 +71,8 @@ func NewApplication(serviceName string) *Application {
 	kratosLogger := logger.NewKratosStdLogger(cfg.App.Name, cfg.App.Version)
 
 	// 创建生命周期管理器
-	lifecycleManager := lifecycle.NewLifecycleManager(kratosLogger)
+	shutdownTimeout := parseDuration(cfg.Server.ShutdownTimeout, lifecycle.DefaultShutdownTimeout)
+	lifecycleManager := lifecycle.NewLifecycleManager(kratosLogger, shutdownTimeout)
 
 	// 创建服务器管理器
 	serverManager := NewServerManager(cfg, kratosLogger)
@@ -73,8 +80,17 @@ func NewApplication(serviceName string) *Application {
 	// 创建客户端管理器
 	clientManager := client.NewClientManager(cfg, kratosLogger)
 
+	// 创建认证提供者，配置错误时拒绝启动，避免以错误的认证方式运行
+	authProvider, err := auth.NewFromConfig(cfg.Auth, cfg.App.JWTSecret)
+	if err != nil {
+		panic(fmt.Sprintf("Failed to initialize auth provider: %v", err))
+	}
+	if cfg.Auth.DebugBypass {
+		kratosLogger.Log(kratoslog.LevelWarn, "msg", "Auth debug bypass enabled, debug token will be accepted")
+	}
+
 	// 创建中间件
-	authMiddleware := middleware.NewAuthMiddleware(kratosLogger, cfg.App.JWTSecret)
+	authMiddleware := middleware.NewAuthMiddleware(kratosLogger, authProvider)
 	loggingMiddleware := middleware.NewLoggingMiddleware(kratosLogger)
 
 	// 创建Loki日志器
@@ -91,6 +107,8 @@ func NewApplication(serviceName string) *Application {
 		serverManager:           serverManager,
 		clientManager:           clientManager,
 		lifecycle:               lifecycleManager,
+		startedAt:               time.Now(),
+		authProvider:            authProvider,
 		authMiddleware:          authMiddleware,
 		loggingMiddleware:       loggingMiddleware,
 		lokiLogger:              lokiLogger,
@@ -103,23 +121,41 @@ func NewApplication(serviceName string) *Application {
 	return app
 }
 
-// initInfrastructure 初始化基础设施组件
+// initInfrastructure 初始化基础设施组件，依赖暂不可用时按配置重试，配置错误或重试用尽时退出
 func (app *Application) initInfrastructure() {
 	// 初始化MongoDB
-	mongoDB, err := database.NewMongoDB(app.config.Database.MongoDB.URI, app.config.Database.MongoDB.DBName)
-	if err != nil {
+	mongoCfg := app.config.Database.MongoDB
+	if err := app.WaitForDependency("MongoDB", func() error {
+		if mongoCfg.URI == "" {
+			return Permanent(fmt.Errorf("MongoDB地址未配置"))
+		}
+		mongoDB, err := database.NewMongoDB(mongoCfg.URI, mongoCfg.DBName)
+		if err != nil {
+			return err
+		}
+		app.mongoDB = mongoDB
+		return nil
+	}); err != nil {
 		app.logger.Log(kratoslog.LevelFatal, "msg", "Failed to connect to MongoDB", "error", err)
 		panic(err)
 	}
-	app.mongoDB = mongoDB
 
 	// 初始化PostgreSQL
-	postgreSQL, err := database.NewPostgreSQL(app.config.Database.PostgreSQL.DSN, app.config.Database.PostgreSQL.DBName)
-	if err != nil {
+	postgresCfg := app.config.Database.PostgreSQL
+	if err := app.WaitForDependency("PostgreSQL", func() error {
+		if postgresCfg.DSN == "" {
+			return Permanent(fmt.Errorf("PostgreSQL DSN未配置"))
+		}
+		postgreSQL, err := database.NewPostgreSQL(postgresCfg.DSN, postgresCfg.DBName)
+		if err != nil {
+			return err
+		}
+		app.postgreSQL = postgreSQL
+		return nil
+	}); err != nil {
 		app.logger.Log(kratoslog.LevelFatal, "msg", "Failed to connect to PostgreSQL", "error", err)
 		panic(err)
 	}
-	app.postgreSQL = postgreSQL
 
 	// 初始化ElasticSearch（可选，只有需要的服务才初始化）
 	if os.Getenv("ELASTICSEARCH_ENABLED") == "true" {
@@ -131,16 +167,39 @@ func (app *Application) initInfrastructure() {
 		}
 	}
 
-	// 初始化Redis
+	// 初始化Redis，客户端按需建连，启动时主动检查一次可用性
+	if app.config.Redis.Addr == "" {
+		app.logger.Log(kratoslog.LevelFatal, "msg", "Redis address is not configured")
+		panic("Redis地址未配置")
+	}
 	app.redisClient = redis.NewRedisClient(app.config.Redis.Addr)
-
-	// 初始化Kafka
-	kafkaProducer, err := kafka.InitProducer(app.config.Kafka.Brokers)
-	if err != nil {
-		app.logger.Log(kratoslog.LevelFatal, "msg", "Failed to connect to Kafka", "error", err)
+	if err := app.WaitForDependency("Redis", func() error {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		return app.redisClient.Ping(ctx)
+	}); err != nil {
+		app.logger.Log(kratoslog.LevelFatal, "msg", "Failed to connect to Redis", "error", err)
 		panic(err)
 	}
-	app.kafkaProducer = kafkaProducer
+
+	// 初始化Kafka，配置为可选时不可用则降级启动，Producer为nil，发布事件返回错误
+	if err := app.WaitForDependency("Kafka", func() error {
+		if len(app.config.Kafka.Brokers) == 0 {
+			return Permanent(fmt.Errorf("Kafka地址未配置"))
+		}
+		kafkaProducer, err := kafka.InitProducer(app.config.Kafka.Brokers)
+		if err != nil {
+			return err
+		}
+		app.kafkaProducer = kafkaProducer
+		return nil
+	}); err != nil {
+		if !app.config.Startup.KafkaOptional {
+			app.logger.Log(kratoslog.LevelFatal, "msg", "Failed to connect to Kafka", "error", err)
+			panic(err)
+		}
+		app.logger.Log(kratoslog.LevelWarn, "msg", "Kafka unavailable, starting degraded without event publishing", "error", err)
+	}
 }
 
 // EnableHTTP 启用HTTP服务器
@@ -151,7 +210,11 @@ func (app *Application) EnableHTTP() HTTPServer {
 	httpServer.RegisterRoutes(func(engine *gin.Engine) {
 		engine.Use(app.loggingMiddleware.GinLogging())
 		engine.Use(app.loggingMiddleware.GinRecovery())
+		engine.Use(middleware.GinDeadline(app.config.Deadline))
 		engine.Use(app.authMiddleware.GinAuth())
+		if app.config.Server.ServiceInfo {
+			app.registerServiceInfo(engine)
+		}
 	})
 
 	return httpServer
@@ -173,6 +236,16 @@ func (app *Application) RegisterGRPCService(registerFunc func(*grpc.Server)) {
 	app.grpcServiceRegister = registerFunc
 }
 
+// RegisterShutdownHook 注册业务层退出钩子，在服务器和基础设施关闭之前执行，
+// 用于停止Kafka消费者、断开长连接等需要在退出时限内完成的清理
+func (app *Application) RegisterShutdownHook(name string, onStop func(context.Context) error) {
+	app.lifecycle.AddHook(lifecycle.Hook{
+		Name:     name,
+		Priority: 300,
+		OnStop:   onStop,
+	})
+}
+
 // GetMongoDB 获取MongoDB连接
 func (app *Application) GetMongoDB() *database.MongoDB {
 	return app.mongoDB
@@ -213,6 +286,11 @@ func (app *Application) GetKratosLogger() kratoslog.Logger {
 	return app.logger
 }
 
+// GetAuthProvider 获取认证提供者
+func (app *Application) GetAuthProvider() auth.Provider {
+	return app.authProvider
+}
+
 // GetConfig 获取配置
 func (app *Application) GetConfig() *config.Config {
 	return app.config
@@ -282,10 +360,19 @@ func (app *Application) registerLifecycleHooks() {
 		},
 	})
 
-	// 数据库清理钩子
+	// Kafka生产者清理钩子，在服务器停止后等待已提交的事件送达，超过退出时限的事件被丢弃并记录日志
+	app.lifecycle.AddHook(lifecycle.Hook{
+		Name:     "kafka",
+		Priority: 50,
+		OnStop: func(ctx context.Context) error {
+			return app.kafkaProducer.Shutdown(ctx)
+		},
+	})
+
+	// 数据库清理钩子，属于基础设施层，最后关闭以保证进行中的请求仍可访问数据库
 	app.lifecycle.AddHook(lifecycle.Hook{
 		Name:     "databases",
-		Priority: 300,
+		Priority: 0,
 		OnStop: func(ctx context.Context) error {
 			if app.mongoDB != nil {
 				if err := app.mongoDB.Close(); err != nil {
@@ -0,0 +1,78 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/IBM/sarama"
+	kratoslog "github.com/go-kratos/kratos/v2/log"
+)
+
+// permanentError 配置错误等重试也无法恢复的失败，不再等待直接退出
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent 将错误标记为不可重试，用于在依赖初始化函数中区分配置错误和依赖尚未就绪
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+// isPermanent 判断错误是否不可重试，Kafka客户端的配置错误同样视为不可重试
+func isPermanent(err error) bool {
+	var permanent *permanentError
+	if errors.As(err, &permanent) {
+		return true
+	}
+	var configErr sarama.ConfigurationError
+	return errors.As(err, &configErr)
+}
+
+// WaitForDependency 初始化依赖，失败时按指数退避重试，直到成功、遇到不可重试的错误或用完重试次数
+// 部署时依赖短暂不可用（如Redis、Kafka尚在启动）不会导致服务立即崩溃重启
+func (app *Application) WaitForDependency(name string, init func() error) error {
+	cfg := app.config.Startup
+	attempts := cfg.InitAttempts
+	if attempts <= 0 {
+		attempts = 1
+	}
+	backoff := time.Duration(cfg.InitBackoff) * time.Millisecond
+	maxBackoff := time.Duration(cfg.InitMaxBackoff) * time.Millisecond
+
+	var err error
+	for attempt := 1; attempt <= attempts; attempt++ {
+		if err = init(); err == nil {
+			if attempt > 1 {
+				app.logger.Log(kratoslog.LevelInfo, "msg", "Dependency ready", "dependency", name, "attempt", attempt)
+			}
+			return nil
+		}
+		if isPermanent(err) {
+			return fmt.Errorf("%s 配置错误: %v", name, err)
+		}
+		if attempt == attempts {
+			break
+		}
+
+		app.logger.Log(kratoslog.LevelWarn,
+			"msg", "Waiting for dependency",
+			"dependency", name,
+			"attempt", attempt,
+			"max_attempts", attempts,
+			"retry_in", backoff.String(),
+			"error", err)
+		time.Sleep(backoff)
+		backoff *= 2
+		if maxBackoff > 0 && backoff > maxBackoff {
+			backoff = maxBackoff
+		}
+	}
+	return fmt.Errorf("%s 在%d次尝试后仍不可用: %v", name, attempts, err)
+}
@@ -0,0 +1,63 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/IBM/sarama"
+	kratoslog "github.com/go-kratos/kratos/v2/log"
+
+	"goim-social/pkg/config"
+)
+
+func newTestApp(attempts int) *Application {
+	return &Application{
+		config: &config.Config{Startup: config.StartupConfig{InitAttempts: attempts}},
+		logger: kratoslog.DefaultLogger,
+	}
+}
+
+// TestWaitForDependencyRetries 依赖暂不可用时重试直到成功
+func TestWaitForDependencyRetries(t *testing.T) {
+	calls := 0
+	err := newTestApp(3).WaitForDependency("test", func() error {
+		calls++
+		if calls < 3 {
+			return errors.New("connection refused")
+		}
+		return nil
+	})
+	if err != nil || calls != 3 {
+		t.Fatalf("期望第3次成功，实际调用%d次，错误: %v", calls, err)
+	}
+}
+
+// TestWaitForDependencyGivesUp 用完重试次数后返回最后一次错误
+func TestWaitForDependencyGivesUp(t *testing.T) {
+	calls := 0
+	err := newTestApp(2).WaitForDependency("test", func() error {
+		calls++
+		return errors.New("connection refused")
+	})
+	if err == nil || calls != 2 {
+		t.Fatalf("期望尝试2次后失败，实际调用%d次，错误: %v", calls, err)
+	}
+}
+
+// TestWaitForDependencyPermanent 配置错误不重试
+func TestWaitForDependencyPermanent(t *testing.T) {
+	for _, initErr := range []error{
+		Permanent(errors.New("地址未配置")),
+		fmt.Errorf("初始化失败: %w", sarama.ConfigurationError("invalid config")),
+	} {
+		calls := 0
+		err := newTestApp(5).WaitForDependency("test", func() error {
+			calls++
+			return initErr
+		})
+		if err == nil || calls != 1 {
+			t.Fatalf("配置错误不应重试，实际调用%d次，错误: %v", calls, err)
+		}
+	}
+}
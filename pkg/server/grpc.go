@@ -6,6 +6,7 @@ import (
 
 	kratoslog "github.com/go-kratos/kratos/v2/log"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/reflection"
 
 	"goim-social/pkg/config"
 )
@@ -38,6 +39,12 @@ func NewGRPCServerWrapper(c *config.Config, logger kratoslog.Logger) *GRPCServer
 		),
 	)
 
+	// 服务反射默认关闭，只在调试环境按配置开启，反射在请求时列出已注册的服务，与服务注册顺序无关
+	if c.Server.GRPC.Reflection {
+		reflection.Register(server)
+		logger.Log(kratoslog.LevelWarn, "msg", "gRPC server reflection enabled")
+	}
+
 	return &GRPCServerWrapper{
 		server: server,
 		addr:   c.Server.GRPC.Addr,
@@ -68,10 +75,23 @@ func (w *GRPCServerWrapper) Start(ctx context.Context) error {
 	return w.server.Serve(lis)
 }
 
-// Stop 停止服务器
+// Stop 停止服务器，等待进行中的请求完成，超过ctx截止时间后强制关闭
 func (w *GRPCServerWrapper) Stop(ctx context.Context) error {
 	w.logger.Log(kratoslog.LevelInfo, "msg", "gRPC server stopping")
-	w.server.GracefulStop()
+
+	stopped := make(chan struct{})
+	go func() {
+		w.server.GracefulStop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+	case <-ctx.Done():
+		w.logger.Log(kratoslog.LevelWarn, "msg", "gRPC graceful stop timed out, forcing stop")
+		w.server.Stop()
+	}
+
 	if w.listener != nil {
 		w.listener.Close()
 	}
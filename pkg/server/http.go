@@ -7,6 +7,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 	kratoslog "github.com/go-kratos/kratos/v2/log"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 
 	"goim-social/pkg/config"
 )
@@ -28,6 +29,9 @@ func NewGinEngine() *gin.Engine {
 		})
 	})
 
+	// Prometheus指标
+	r.GET("/metrics", gin.WrapH(promhttp.Handler()))
+
 	return r
 }
 
@@ -0,0 +1,173 @@
+package server
+
+import (
+	"net/http"
+	"net/url"
+	"os"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"goim-social/pkg/config"
+)
+
+// 构建信息，由构建命令通过 -ldflags "-X goim-social/pkg/server.BuildVersion=... -X goim-social/pkg/server.BuildCommit=..." 注入
+var (
+	BuildVersion = "" // 构建版本，未注入时使用配置中的应用版本
+	BuildCommit  = "" // 构建提交，未注入时读取Go工具链记录的VCS信息
+)
+
+// ServiceInfoPath 服务信息调试接口路径
+const ServiceInfoPath = "/debug/service-info"
+
+// ServiceInfo 服务信息，用于本地调试和运维排查，不包含任何凭据
+type ServiceInfo struct {
+	Service       string            `json:"service"`
+	Version       string            `json:"version"`
+	Commit        string            `json:"commit"`
+	GoVersion     string            `json:"go_version"`
+	Hostname      string            `json:"hostname"`
+	StartedAt     int64             `json:"started_at"`
+	UptimeSeconds int64             `json:"uptime_seconds"`
+	Features      map[string]bool   `json:"features"`
+	Dependencies  map[string]string `json:"dependencies"`
+}
+
+// ServiceInfo 汇总当前服务的版本、运行时长、启用的功能和依赖地址
+func (app *Application) ServiceInfo() *ServiceInfo {
+	version := BuildVersion
+	if version == "" {
+		version = app.config.App.Version
+	}
+	hostname, _ := os.Hostname()
+	now := time.Now()
+
+	return &ServiceInfo{
+		Service:       app.serviceName,
+		Version:       version,
+		Commit:        buildCommit(),
+		GoVersion:     runtime.Version(),
+		Hostname:      hostname,
+		StartedAt:     app.startedAt.Unix(),
+		UptimeSeconds: int64(now.Sub(app.startedAt).Seconds()),
+		Features: map[string]bool{
+			"http":              app.serverManager.GetHTTPServer() != nil,
+			"grpc":              app.serverManager.GetGRPCServer() != nil,
+			"grpc_reflection":   app.serverManager.GetGRPCServer() != nil && app.config.Server.GRPC.Reflection,
+			"kafka_producer":    app.kafkaProducer != nil,
+			"elasticsearch":     app.elasticSearch != nil,
+			"auth_debug_bypass": app.config.Auth.DebugBypass,
+		},
+		Dependencies: app.dependencyAddrs(),
+	}
+}
+
+// dependencyAddrs 基础设施依赖的地址，去除用户名、密码等凭据，未配置的依赖不返回
+func (app *Application) dependencyAddrs() map[string]string {
+	deps := make(map[string]string)
+	if addr := redactURI(app.config.Database.MongoDB.URI); addr != "" {
+		deps["mongodb"] = addr
+	}
+	if addr := postgresAddr(app.config.Database.PostgreSQL.DSN); addr != "" {
+		deps["postgresql"] = addr
+	}
+	if app.config.Redis.Addr != "" {
+		deps["redis"] = app.config.Redis.Addr
+	}
+	if len(app.config.Kafka.Brokers) > 0 {
+		deps["kafka"] = strings.Join(app.config.Kafka.Brokers, ",")
+	}
+	if app.elasticSearch != nil {
+		if addr := redactURI(os.Getenv("ELASTICSEARCH_URL")); addr != "" {
+			deps["elasticsearch"] = addr
+		}
+	}
+	return deps
+}
+
+// registerServiceInfo 注册服务信息接口，挂在认证中间件之后，只有配置的管理员可以访问
+// 接口暴露构建版本和内部依赖地址，普通登录用户不应看到
+func (app *Application) registerServiceInfo(engine *gin.Engine) {
+	admins := config.ParseUserIDs(app.config.Server.ServiceInfoAdminIDs)
+	engine.GET(ServiceInfoPath, serviceInfoHandler(admins, app.ServiceInfo))
+}
+
+// serviceInfoHandler 服务信息接口处理函数，认证中间件写入的userID不在管理员列表中时返回403
+func serviceInfoHandler(admins map[int64]bool, info func() *ServiceInfo) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID := c.GetInt64("userID")
+		if !admins[userID] {
+			c.JSON(http.StatusForbidden, gin.H{"error": "permission denied"})
+			return
+		}
+		c.JSON(http.StatusOK, info())
+	}
+}
+
+// buildCommit 构建提交，未通过ldflags注入时读取Go工具链记录的VCS信息，工作区有未提交修改时追加-dirty
+func buildCommit() string {
+	if BuildCommit != "" {
+		return BuildCommit
+	}
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return ""
+	}
+	var revision string
+	var modified bool
+	for _, setting := range info.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			revision = setting.Value
+		case "vcs.modified":
+			modified = setting.Value == "true"
+		}
+	}
+	if revision != "" && modified {
+		revision += "-dirty"
+	}
+	return revision
+}
+
+// redactURI 去除连接串中的用户信息和查询参数，只保留协议、主机和路径；无法解析时不返回，避免泄露凭据
+func redactURI(uri string) string {
+	if uri == "" {
+		return ""
+	}
+	u, err := url.Parse(uri)
+	if err != nil || u.Host == "" {
+		return ""
+	}
+	u.User = nil
+	u.RawQuery = ""
+	u.Fragment = ""
+	return u.String()
+}
+
+// postgresAddr 从PostgreSQL DSN中取出主机、端口和库名，支持URL和key=value两种格式，不返回用户名和密码
+func postgresAddr(dsn string) string {
+	if strings.HasPrefix(dsn, "postgres://") || strings.HasPrefix(dsn, "postgresql://") {
+		return redactURI(dsn)
+	}
+
+	params := make(map[string]string)
+	for _, field := range strings.Fields(dsn) {
+		if key, value, ok := strings.Cut(field, "="); ok {
+			params[key] = value
+		}
+	}
+	host := params["host"]
+	if host == "" {
+		return ""
+	}
+	if port := params["port"]; port != "" {
+		host += ":" + port
+	}
+	if dbname := params["dbname"]; dbname != "" {
+		host += "/" + dbname
+	}
+	return host
+}
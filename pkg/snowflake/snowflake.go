@@ -1,6 +1,7 @@
 package snowflake
 
 import (
+	"errors"
 	"fmt"
 	"sync"
 	"time"
@@ -8,86 +9,178 @@ import (
 
 // Snowflake ID生成器
 // 64位ID结构：1位符号位(0) + 41位时间戳 + 10位机器ID + 12位序列号
+// 机器ID和序列号的位数、起始时间和时钟可通过Options调整，便于测试和多机房部署
 type Snowflake struct {
 	mutex     sync.Mutex
 	epoch     int64 // 起始时间戳 (毫秒)
-	machineID int64 // 机器ID (0-1023)
-	sequence  int64 // 序列号 (0-4095)
+	machineID int64 // 机器ID
+	sequence  int64 // 序列号
 	lastTime  int64 // 上次生成ID的时间戳
+
+	maxMachineID   int64
+	maxSequence    int64
+	machineShift   uint
+	timestampShift uint
+	maxTimestamp   int64               // 时间戳部分能表示的最大毫秒数
+	maxBackward    time.Duration       // 可等待的最大时钟回拨
+	clock          func() time.Time    // 时钟
+	sleep          func(time.Duration) // 等待时钟追上时使用
 }
 
 const (
-	// 各部分位数
+	// 默认各部分位数，可通过Options调整
 	machineBits  = 10 // 机器ID位数
 	sequenceBits = 12 // 序列号位数
 
-	// 最大值
-	maxMachineID = (1 << machineBits) - 1 // 1023
-	maxSequence  = (1 << sequenceBits) - 1 // 4095
-
-	// 位移
-	machineShift  = sequenceBits                    // 12
-	timestampShift = sequenceBits + machineBits     // 22
-
 	// 自定义起始时间 (2024-01-01 00:00:00 UTC)
 	defaultEpoch = 1704067200000
+
+	// 时间戳部分总位数，与机器ID和序列号共用63位
+	timestampBits = 41
+
+	// 默认可等待的最大时钟回拨，超过时返回错误而不是生成可能重复的ID
+	defaultMaxBackward = 10 * time.Millisecond
 )
 
-// NewSnowflake 创建Snowflake实例
+// ErrClockBackwards 时钟回拨超过可等待的范围
+var ErrClockBackwards = errors.New("时钟回拨，拒绝生成ID")
+
+// ErrTimestampOverflow 时间戳超出ID可表示的范围
+var ErrTimestampOverflow = errors.New("时间戳超出ID可表示的范围")
+
+// Options 生成器选项，零值使用默认配置
+type Options struct {
+	Epoch        int64               // 起始时间戳（毫秒），默认2024-01-01 00:00:00 UTC
+	MachineBits  int                 // 机器ID位数，默认10
+	SequenceBits int                 // 序列号位数，默认12，与机器ID位数之和不超过22
+	MaxBackward  time.Duration       // 可等待的最大时钟回拨，默认10ms，为负数时不等待直接返回错误
+	Clock        func() time.Time    // 时钟，默认time.Now
+	Sleep        func(time.Duration) // 等待函数，默认time.Sleep
+}
+
+// NewSnowflake 使用默认配置创建Snowflake实例
 func NewSnowflake(machineID int64) (*Snowflake, error) {
-	if machineID < 0 || machineID > maxMachineID {
-		return nil, fmt.Errorf("机器ID必须在0-%d之间", maxMachineID)
+	return New(machineID, Options{})
+}
+
+// New 按选项创建Snowflake实例
+func New(machineID int64, opts Options) (*Snowflake, error) {
+	if opts.MachineBits == 0 {
+		opts.MachineBits = machineBits
+	}
+	if opts.SequenceBits == 0 {
+		opts.SequenceBits = sequenceBits
+	}
+	if opts.MachineBits < 0 || opts.SequenceBits < 0 || opts.MachineBits+opts.SequenceBits > 63-timestampBits {
+		return nil, fmt.Errorf("机器ID位数与序列号位数之和不能超过%d", 63-timestampBits)
+	}
+	if opts.Epoch == 0 {
+		opts.Epoch = defaultEpoch
+	}
+	if opts.MaxBackward == 0 {
+		opts.MaxBackward = defaultMaxBackward
+	}
+	if opts.Clock == nil {
+		opts.Clock = time.Now
+	}
+	if opts.Sleep == nil {
+		opts.Sleep = time.Sleep
+	}
+
+	maxMachine := int64(1)<<opts.MachineBits - 1
+	if machineID < 0 || machineID > maxMachine {
+		return nil, fmt.Errorf("机器ID必须在0-%d之间", maxMachine)
+	}
+	if opts.Epoch > opts.Clock().UnixMilli() {
+		return nil, fmt.Errorf("起始时间不能晚于当前时间")
 	}
 
+	timestampShift := uint(opts.MachineBits + opts.SequenceBits)
 	return &Snowflake{
-		epoch:     defaultEpoch,
-		machineID: machineID,
-		sequence:  0,
-		lastTime:  0,
+		epoch:          opts.Epoch,
+		machineID:      machineID,
+		maxMachineID:   maxMachine,
+		maxSequence:    int64(1)<<opts.SequenceBits - 1,
+		machineShift:   uint(opts.SequenceBits),
+		timestampShift: timestampShift,
+		maxTimestamp:   int64(1)<<(63-timestampShift) - 1,
+		maxBackward:    opts.MaxBackward,
+		clock:          opts.Clock,
+		sleep:          opts.Sleep,
 	}, nil
 }
 
-// Generate 生成下一个ID
+// Generate 生成下一个ID，时钟回拨超过可等待范围时panic，需要处理错误时使用NextID
 func (s *Snowflake) Generate() int64 {
+	id, err := s.NextID()
+	if err != nil {
+		panic(err.Error())
+	}
+	return id
+}
+
+// NextID 生成下一个ID
+// 时钟回拨在可等待范围内时等待时钟追上，超过范围时返回ErrClockBackwards，保证不生成重复ID
+func (s *Snowflake) NextID() (int64, error) {
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
 
-	now := time.Now().UnixMilli()
+	now := s.now()
 
 	// 时钟回拨检查
 	if now < s.lastTime {
-		panic(fmt.Sprintf("时钟回拨，拒绝生成ID。当前时间: %d, 上次时间: %d", now, s.lastTime))
+		backward := time.Duration(s.lastTime-now) * time.Millisecond
+		if s.maxBackward < 0 || backward > s.maxBackward {
+			return 0, fmt.Errorf("%w: 当前时间: %d, 上次时间: %d", ErrClockBackwards, now, s.lastTime)
+		}
+		now = s.waitUntil(s.lastTime)
 	}
 
 	if now == s.lastTime {
 		// 同一毫秒内，序列号递增
-		s.sequence = (s.sequence + 1) & maxSequence
+		s.sequence = (s.sequence + 1) & s.maxSequence
 		if s.sequence == 0 {
 			// 序列号溢出，等待下一毫秒
-			for now <= s.lastTime {
-				now = time.Now().UnixMilli()
-			}
+			now = s.waitUntil(s.lastTime + 1)
 		}
 	} else {
 		// 新的毫秒，序列号重置
 		s.sequence = 0
 	}
 
+	if now-s.epoch > s.maxTimestamp {
+		return 0, ErrTimestampOverflow
+	}
 	s.lastTime = now
 
 	// 组装ID: 时间戳部分 | 机器ID部分 | 序列号部分
-	id := ((now - s.epoch) << timestampShift) |
-		(s.machineID << machineShift) |
+	id := ((now - s.epoch) << s.timestampShift) |
+		(s.machineID << s.machineShift) |
 		s.sequence
 
-	return id
+	return id, nil
+}
+
+// now 当前时间戳（毫秒）
+func (s *Snowflake) now() int64 {
+	return s.clock().UnixMilli()
+}
+
+// waitUntil 等待时钟走到target（毫秒），返回等待后的时间戳
+func (s *Snowflake) waitUntil(target int64) int64 {
+	now := s.now()
+	for now < target {
+		s.sleep(time.Duration(target-now) * time.Millisecond)
+		now = s.now()
+	}
+	return now
 }
 
 // ParseID 解析Snowflake ID
 func (s *Snowflake) ParseID(id int64) (timestamp int64, machineID int64, sequence int64) {
-	timestamp = (id >> timestampShift) + s.epoch
-	machineID = (id >> machineShift) & maxMachineID
-	sequence = id & maxSequence
+	timestamp = (id >> s.timestampShift) + s.epoch
+	machineID = (id >> s.machineShift) & s.maxMachineID
+	sequence = id & s.maxSequence
 	return
 }
 
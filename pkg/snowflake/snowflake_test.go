@@ -0,0 +1,155 @@
+package snowflake
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeClock 可控时钟，sleep时按等待时长前进
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Sleep(d time.Duration) {
+	c.Advance(d)
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+func newTestSnowflake(t *testing.T, clock *fakeClock, opts Options) *Snowflake {
+	t.Helper()
+	opts.Clock = clock.Now
+	opts.Sleep = clock.Sleep
+	s, err := New(1, opts)
+	if err != nil {
+		t.Fatalf("创建生成器失败: %v", err)
+	}
+	return s
+}
+
+// TestMonotonic 同一生成器生成的ID严格递增，序列号溢出时等待下一毫秒
+func TestMonotonic(t *testing.T) {
+	clock := &fakeClock{now: time.UnixMilli(defaultEpoch + 1000)}
+	s := newTestSnowflake(t, clock, Options{SequenceBits: 4})
+
+	var last int64
+	for i := 0; i < 100; i++ {
+		id, err := s.NextID()
+		if err != nil {
+			t.Fatalf("生成ID失败: %v", err)
+		}
+		if id <= last {
+			t.Fatalf("ID未递增: %d <= %d", id, last)
+		}
+		last = id
+	}
+
+	// 16个序列号用完后时钟应已前进
+	if clock.Now().UnixMilli() <= defaultEpoch+1000 {
+		t.Fatalf("序列号溢出后应等待下一毫秒")
+	}
+}
+
+// TestParseID 解析出的时间、机器ID和序列号与生成时一致
+func TestParseID(t *testing.T) {
+	clock := &fakeClock{now: time.UnixMilli(defaultEpoch + 12345)}
+	s := newTestSnowflake(t, clock, Options{MachineBits: 5, SequenceBits: 8})
+
+	s.Generate()
+	id := s.Generate()
+	timestamp, machineID, sequence := s.ParseID(id)
+	if timestamp != defaultEpoch+12345 || machineID != 1 || sequence != 1 {
+		t.Fatalf("解析结果错误: timestamp=%d machineID=%d sequence=%d", timestamp, machineID, sequence)
+	}
+}
+
+// TestConcurrentUnique 并发生成的ID不重复
+func TestConcurrentUnique(t *testing.T) {
+	s, err := NewSnowflake(7)
+	if err != nil {
+		t.Fatalf("创建生成器失败: %v", err)
+	}
+
+	const workers, perWorker = 8, 2000
+	ids := make(chan int64, workers*perWorker)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < perWorker; j++ {
+				ids <- s.Generate()
+			}
+		}()
+	}
+	wg.Wait()
+	close(ids)
+
+	seen := make(map[int64]bool, workers*perWorker)
+	for id := range ids {
+		if seen[id] {
+			t.Fatalf("生成了重复ID: %d", id)
+		}
+		seen[id] = true
+	}
+}
+
+// TestClockBackwardsWait 小幅时钟回拨时等待时钟追上，不生成重复ID
+func TestClockBackwardsWait(t *testing.T) {
+	clock := &fakeClock{now: time.UnixMilli(defaultEpoch + 1000)}
+	s := newTestSnowflake(t, clock, Options{MaxBackward: 5 * time.Millisecond})
+
+	first := s.Generate()
+	clock.Advance(-3 * time.Millisecond)
+	second, err := s.NextID()
+	if err != nil {
+		t.Fatalf("小幅回拨应等待而不是报错: %v", err)
+	}
+	if second <= first {
+		t.Fatalf("回拨后ID未递增: %d <= %d", second, first)
+	}
+}
+
+// TestClockBackwardsError 时钟回拨超过可等待范围时返回错误
+func TestClockBackwardsError(t *testing.T) {
+	clock := &fakeClock{now: time.UnixMilli(defaultEpoch + 1000)}
+	s := newTestSnowflake(t, clock, Options{MaxBackward: 5 * time.Millisecond})
+
+	s.Generate()
+	clock.Advance(-time.Second)
+	if _, err := s.NextID(); !errors.Is(err, ErrClockBackwards) {
+		t.Fatalf("大幅回拨应返回ErrClockBackwards: %v", err)
+	}
+
+	// 时钟恢复后继续生成
+	clock.Advance(time.Second)
+	if _, err := s.NextID(); err != nil {
+		t.Fatalf("时钟恢复后应能生成ID: %v", err)
+	}
+}
+
+// TestInvalidOptions 机器ID或位数超出范围时创建失败
+func TestInvalidOptions(t *testing.T) {
+	if _, err := New(1024, Options{}); err == nil {
+		t.Fatalf("机器ID超出范围应创建失败")
+	}
+	if _, err := New(1, Options{MachineBits: 12, SequenceBits: 12}); err == nil {
+		t.Fatalf("位数之和超过22应创建失败")
+	}
+	if _, err := New(1, Options{Epoch: time.Now().Add(time.Hour).UnixMilli()}); err == nil {
+		t.Fatalf("起始时间晚于当前时间应创建失败")
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"os"
+	"time"
 
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
@@ -16,46 +18,61 @@ import (
 
 // Config OpenTelemetry配置
 type Config struct {
-	ServiceName    string
-	ServiceVersion string
-	Environment    string
-	ExporterType   string // "stdout", "jaeger", "otlp"
-	JaegerEndpoint string
-	OTLPEndpoint   string
-	SampleRate     float64 // 采样率 0.0-1.0
+	ServiceName     string
+	ServiceVersion  string
+	Environment     string
+	ExporterType    string // "stdout", "jaeger", "otlp"
+	JaegerEndpoint  string
+	OTLPEndpoint    string
+	SampleRate      float64       // 采样率 0.0-1.0
+	ShutdownTimeout time.Duration // 关闭时等待剩余span导出的最长时间
+}
+
+// DefaultShutdownTimeout 默认关闭等待时间
+const DefaultShutdownTimeout = 5 * time.Second
+
+// shutdownTimeoutFromEnv 从环境变量OTEL_SHUTDOWN_TIMEOUT读取关闭等待时间
+func shutdownTimeoutFromEnv() time.Duration {
+	if d, err := time.ParseDuration(os.Getenv("OTEL_SHUTDOWN_TIMEOUT")); err == nil && d > 0 {
+		return d
+	}
+	return DefaultShutdownTimeout
 }
 
 // DefaultConfig 返回默认配置
 func DefaultConfig(serviceName string) *Config {
 	return &Config{
-		ServiceName:    serviceName,
-		ServiceVersion: "1.0.0",
-		Environment:    "development",
-		ExporterType:   "noop", // 默认不输出，避免控制台污染
-		SampleRate:     1.0,
+		ServiceName:     serviceName,
+		ServiceVersion:  "1.0.0",
+		Environment:     "development",
+		ExporterType:    "noop", // 默认不输出，避免控制台污染
+		SampleRate:      1.0,
+		ShutdownTimeout: shutdownTimeoutFromEnv(),
 	}
 }
 
 // DevelopmentConfig 返回开发环境配置（会输出到控制台）
 func DevelopmentConfig(serviceName string) *Config {
 	return &Config{
-		ServiceName:    serviceName,
-		ServiceVersion: "1.0.0",
-		Environment:    "development",
-		ExporterType:   "stdout",
-		SampleRate:     1.0, // 开发环境全采样
+		ServiceName:     serviceName,
+		ServiceVersion:  "1.0.0",
+		Environment:     "development",
+		ExporterType:    "stdout",
+		SampleRate:      1.0, // 开发环境全采样
+		ShutdownTimeout: shutdownTimeoutFromEnv(),
 	}
 }
 
 // ProductionConfig 返回生产环境配置
 func ProductionConfig(serviceName string) *Config {
 	return &Config{
-		ServiceName:    serviceName,
-		ServiceVersion: "1.0.0",
-		Environment:    "production",
-		ExporterType:   "jaeger", // 生产环境使用Jaeger
-		JaegerEndpoint: "http://localhost:14268/api/traces",
-		SampleRate:     0.1, // 生产环境10%采样
+		ServiceName:     serviceName,
+		ServiceVersion:  "1.0.0",
+		Environment:     "production",
+		ExporterType:    "jaeger", // 生产环境使用Jaeger
+		JaegerEndpoint:  "http://localhost:14268/api/traces",
+		SampleRate:      0.1, // 生产环境10%采样
+		ShutdownTimeout: shutdownTimeoutFromEnv(),
 	}
 }
 
@@ -0,0 +1,72 @@
+package utils
+
+import (
+	"html"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// ExcerptEllipsis 摘要被截断时追加的省略号
+const ExcerptEllipsis = "…"
+
+var (
+	htmlTagPattern       = regexp.MustCompile(`<[^>]*>`)
+	htmlScriptPattern    = regexp.MustCompile(`(?is)<(?:script|style)\b[^>]*>.*?(?:</(?:script|style)\s*>|$)`)
+	markdownImagePattern = regexp.MustCompile(`!\[([^\]]*)\]\([^)]*\)`)
+	markdownLinkPattern  = regexp.MustCompile(`\[([^\]]*)\]\([^)]*\)`)
+	markdownLinePattern  = regexp.MustCompile(`(?m)^[ \t]*(?:#{1,6}[ \t]+|>[ \t]?|[-*+][ \t]+|\d+\.[ \t]+)`)
+	markdownFencePattern = regexp.MustCompile("(?m)^[ \t]*```.*$")
+	markdownEmphasis     = strings.NewReplacer("**", "", "__", "", "~~", "", "`", "", "*", "")
+)
+
+// PlainText 将HTML或Markdown正文转换为纯文本：还原实体，去除脚本和样式元素、标签和常见标记，合并连续空白
+// 先还原实体再去除标签，避免转义的标签在还原后重新出现
+func PlainText(text string) string {
+	text = html.UnescapeString(text)
+	text = htmlScriptPattern.ReplaceAllString(text, " ")
+	text = htmlTagPattern.ReplaceAllString(text, " ")
+	text = markdownFencePattern.ReplaceAllString(text, " ")
+	text = markdownImagePattern.ReplaceAllString(text, "$1")
+	text = markdownLinkPattern.ReplaceAllString(text, "$1")
+	text = markdownLinePattern.ReplaceAllString(text, "")
+	text = markdownEmphasis.Replace(text)
+	return strings.Join(strings.Fields(stripControlChars(text)), " ")
+}
+
+// Excerpt 生成纯文本摘要，超过maxRunes个字符时截断并追加省略号（不计入长度）
+// 按字符而不是字节截断，不会截断中文等多字节字符；以空格分词的文字尽量在单词边界截断
+func Excerpt(text string, maxRunes int) string {
+	text = PlainText(text)
+	if maxRunes <= 0 {
+		return ""
+	}
+
+	runes := []rune(text)
+	if len(runes) <= maxRunes {
+		return text
+	}
+
+	cut := runes[:maxRunes]
+	// 截断位置在单词中间时回退到前一个空格，空格太靠前时按字符截断
+	if isWordRune(runes[maxRunes]) && isWordRune(cut[len(cut)-1]) {
+		for i := len(cut) - 1; i > maxRunes/2; i-- {
+			if cut[i] == ' ' {
+				cut = cut[:i]
+				break
+			}
+		}
+	}
+
+	return strings.TrimRightFunc(string(cut), func(r rune) bool {
+		return unicode.IsSpace(r) || unicode.IsPunct(r)
+	}) + ExcerptEllipsis
+}
+
+// isWordRune 是否为以空格分词的文字中的字符，中日韩文字不以空格分词，任意位置都可截断
+func isWordRune(r rune) bool {
+	if unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul) {
+		return false
+	}
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
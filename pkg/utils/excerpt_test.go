@@ -0,0 +1,63 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+// TestExcerptShortText 不超过长度的文本不截断，也不追加省略号
+func TestExcerptShortText(t *testing.T) {
+	if got := Excerpt("  你好，\n世界  ", 10); got != "你好， 世界" {
+		t.Fatalf("got %q", got)
+	}
+}
+
+// TestExcerptCJK 中文按字符截断，不产生半个字符
+func TestExcerptCJK(t *testing.T) {
+	text := strings.Repeat("内容摘要测试", 10)
+	got := Excerpt(text, 7)
+	if !utf8.ValidString(got) {
+		t.Fatalf("摘要不是有效的UTF-8: %q", got)
+	}
+	if got != "内容摘要测试内"+ExcerptEllipsis {
+		t.Fatalf("got %q", got)
+	}
+}
+
+// TestExcerptWordBoundary 英文在单词边界截断，并去掉截断处的标点
+func TestExcerptWordBoundary(t *testing.T) {
+	got := Excerpt("The quick brown fox, jumps over the lazy dog", 22)
+	if got != "The quick brown fox"+ExcerptEllipsis {
+		t.Fatalf("got %q", got)
+	}
+}
+
+// TestExcerptLongWord 没有合适的空格时按字符截断
+func TestExcerptLongWord(t *testing.T) {
+	got := Excerpt("a supercalifragilisticexpialidocious word", 10)
+	if got != "a supercal"+ExcerptEllipsis {
+		t.Fatalf("got %q", got)
+	}
+}
+
+// TestPlainTextStripsMarkup 去除HTML标签、脚本和样式元素以及Markdown标记，保留链接文本并还原实体
+func TestPlainTextStripsMarkup(t *testing.T) {
+	cases := map[string]string{
+		"<p>Hello <b>world</b></p><script>x</script>":     "Hello world",
+		"<STYLE type=\"text/css\">p{color:red}</STYLE>正文": "正文",
+		"a<script>alert(1)":                      "a",
+		"&lt;script&gt;alert(1)&lt;/script&gt;b": "b",
+		"&lt;img src=x onerror=alert(1)&gt;c":    "c",
+		"1 &lt; 2":                               "1 < 2",
+		"# 标题\n\n**加粗**和*斜体*，[链接](https://example.com)": "标题 加粗和斜体，链接",
+		"> 引用\n- 列表一\n1. 列表二":                           "引用 列表一 列表二",
+		"![图片](a.png) a &amp; b":                        "图片 a & b",
+		"```go\nfmt.Println(1)\n```":                    "fmt.Println(1)",
+	}
+	for input, want := range cases {
+		if got := PlainText(input); got != want {
+			t.Errorf("PlainText(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
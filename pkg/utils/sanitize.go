@@ -0,0 +1,31 @@
+package utils
+
+import (
+	"html"
+	"strings"
+)
+
+// EscapeHTML 转义HTML特殊字符，用于将昵称、群名等用户输入插入HTML文本或属性
+func EscapeHTML(s string) string {
+	return html.EscapeString(stripControlChars(s))
+}
+
+// RenderMessageHTML 将消息内容渲染为可安全嵌入HTML的片段
+// 消息按原文存储，只在渲染为HTML时调用；所有标签和实体都被转义为文本，换行转为<br>
+func RenderMessageHTML(content string) string {
+	content = strings.ReplaceAll(content, "\r\n", "\n")
+	return strings.ReplaceAll(EscapeHTML(content), "\n", "<br>")
+}
+
+// stripControlChars 去除除换行和制表符外的控制字符，避免NUL等字符干扰浏览器解析
+func stripControlChars(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '\n' || r == '\t' {
+			return r
+		}
+		if r < 0x20 || r == 0x7f {
+			return -1
+		}
+		return r
+	}, s)
+}
@@ -0,0 +1,42 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestRenderMessageHTML 测试恶意消息内容渲染后不包含可执行的标签
+func TestRenderMessageHTML(t *testing.T) {
+	payloads := []string{
+		`<script>alert(1)</script>`,
+		`<img src=x onerror=alert(1)>`,
+		`<a href="javascript:alert(1)">click</a>`,
+		`"><svg/onload=alert(1)>`,
+		`<scr<script>ipt>alert(1)</script>`,
+		"<scr\x00ipt>alert(1)</script>",
+		`</div><iframe src="https://evil.example"></iframe>`,
+	}
+	for _, payload := range payloads {
+		rendered := RenderMessageHTML(payload)
+		if strings.ContainsAny(rendered, `<>"'`) {
+			t.Errorf("渲染结果包含未转义的字符: %q -> %q", payload, rendered)
+		}
+	}
+}
+
+// TestRenderMessageHTMLKeepsText 测试普通文本和换行保持可读
+func TestRenderMessageHTMLKeepsText(t *testing.T) {
+	got := RenderMessageHTML("你好 a&b\r\n第二行")
+	want := "你好 a&amp;b<br>第二行"
+	if got != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+}
+
+// TestEscapeHTML 测试昵称等属性值中的引号被转义
+func TestEscapeHTML(t *testing.T) {
+	got := EscapeHTML(`Bob" onmouseover="alert(1)`)
+	if strings.Contains(got, `"`) {
+		t.Fatalf("引号未转义: %q", got)
+	}
+}
@@ -0,0 +1,61 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+)
+
+// ErrForbiddenDestination 回调地址解析到回环、内网、链路本地等非公网地址
+var ErrForbiddenDestination = errors.New("webhook回调地址不能指向内网地址")
+
+// PublicIP 是否为可以回调的公网地址，回环、内网、链路本地、组播和未指定地址都不允许，防止回调被用来访问内部服务
+func PublicIP(ip net.IP) bool {
+	return !(ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() || ip.IsUnspecified())
+}
+
+// ResolvePublic 解析主机名，任一地址不是公网地址时返回ErrForbiddenDestination
+func ResolvePublic(ctx context.Context, host string) ([]net.IP, error) {
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return nil, fmt.Errorf("解析回调地址失败: %v", err)
+	}
+
+	ips := make([]net.IP, 0, len(addrs))
+	for _, addr := range addrs {
+		if !PublicIP(addr.IP) {
+			return nil, ErrForbiddenDestination
+		}
+		ips = append(ips, addr.IP)
+	}
+	if len(ips) == 0 {
+		return nil, fmt.Errorf("解析回调地址失败: %s没有可用地址", host)
+	}
+	return ips, nil
+}
+
+// DialContext 只连接公网地址的拨号函数，供回调HTTP客户端的Transport使用
+// 连接时重新解析并直接拨号校验过的IP，订阅时校验通过的域名之后改为解析到内网（DNS重绑定）也无法回调
+func DialContext(ctx context.Context, network, address string) (net.Conn, error) {
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, err
+	}
+	ips, err := ResolvePublic(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+
+	var dialer net.Dialer
+	var lastErr error
+	for _, ip := range ips {
+		conn, err := dialer.DialContext(ctx, network, net.JoinHostPort(ip.String(), port))
+		if err == nil {
+			return conn, nil
+		}
+		lastErr = err
+	}
+	return nil, lastErr
+}
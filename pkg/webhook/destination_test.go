@@ -0,0 +1,46 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+)
+
+// TestPublicIP 回环、内网、链路本地和未指定地址不允许回调
+func TestPublicIP(t *testing.T) {
+	cases := map[string]bool{
+		"8.8.8.8":         true,
+		"2001:4860::8888": true,
+		"127.0.0.1":       false,
+		"::1":             false,
+		"10.1.2.3":        false,
+		"172.16.0.1":      false,
+		"192.168.1.1":     false,
+		"169.254.169.254": false,
+		"fe80::1":         false,
+		"fd00::1":         false,
+		"0.0.0.0":         false,
+		"224.0.0.1":       false,
+	}
+	for addr, want := range cases {
+		if got := PublicIP(net.ParseIP(addr)); got != want {
+			t.Fatalf("%s: 期望%v，实际%v", addr, want, got)
+		}
+	}
+}
+
+// TestDialContextRejectsPrivate 解析到非公网地址时拒绝连接
+func TestDialContextRejectsPrivate(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("监听失败: %v", err)
+	}
+	defer ln.Close()
+
+	for _, addr := range []string{ln.Addr().String(), "localhost:80", "[::1]:443"} {
+		if _, err := DialContext(context.Background(), "tcp", addr); !errors.Is(err, ErrForbiddenDestination) {
+			t.Fatalf("%s应被拒绝: %v", addr, err)
+		}
+	}
+}
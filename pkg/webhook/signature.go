@@ -0,0 +1,66 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 回调请求头，接收方用签名头和时间戳头校验请求确实来自本平台且未被篡改
+const (
+	HeaderSignature = "X-Webhook-Signature" // 签名，格式为 v1={hex(HMAC-SHA256(secret, timestamp + "." + body))}
+	HeaderTimestamp = "X-Webhook-Timestamp" // 签名时间，Unix秒
+	HeaderEventID   = "X-Webhook-Event-ID"  // 事件ID，重试时不变，接收方据此去重
+)
+
+// 签名参数
+const (
+	SignatureVersion = "v1"            // 签名算法版本
+	DefaultTolerance = 5 * time.Minute // 默认允许的签名时间偏差，超出视为重放
+	secretBytes      = 32              // 生成的签名密钥字节数
+)
+
+var (
+	ErrInvalidSignature = errors.New("webhook签名无效")
+	ErrTimestampExpired = errors.New("webhook签名时间超出允许范围")
+)
+
+// NewSecret 生成随机签名密钥，订阅时下发给接收方，之后不再返回
+func NewSecret() (string, error) {
+	buf := make([]byte, secretBytes)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// Sign 计算回调请求签名，时间戳参与签名，防止旧请求被重放
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return SignatureVersion + "=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify 校验回调请求签名，tolerance为0时使用默认时间偏差
+func Verify(secret, signature string, timestamp int64, body []byte, tolerance time.Duration, now time.Time) error {
+	if tolerance <= 0 {
+		tolerance = DefaultTolerance
+	}
+	if diff := now.Sub(time.Unix(timestamp, 0)); diff > tolerance || diff < -tolerance {
+		return ErrTimestampExpired
+	}
+	if !strings.HasPrefix(signature, SignatureVersion+"=") {
+		return ErrInvalidSignature
+	}
+	if !hmac.Equal([]byte(signature), []byte(Sign(secret, timestamp, body))) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package webhook
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// TestSignAndVerify 同一密钥、时间戳和请求体签名可通过校验
+func TestSignAndVerify(t *testing.T) {
+	secret, err := NewSecret()
+	if err != nil {
+		t.Fatalf("生成密钥失败: %v", err)
+	}
+	now := time.Unix(1700000000, 0)
+	body := []byte(`{"user_id":1001,"status":"online"}`)
+
+	signature := Sign(secret, now.Unix(), body)
+	if err := Verify(secret, signature, now.Unix(), body, 0, now.Add(time.Minute)); err != nil {
+		t.Fatalf("签名校验失败: %v", err)
+	}
+}
+
+// TestVerifyRejectsTampering 请求体、时间戳或密钥不一致时校验失败
+func TestVerifyRejectsTampering(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	body := []byte(`{"user_id":1001,"status":"online"}`)
+	signature := Sign("secret", now.Unix(), body)
+
+	cases := map[string]error{
+		"body":      Verify("secret", signature, now.Unix(), []byte(`{"user_id":1002,"status":"online"}`), 0, now),
+		"timestamp": Verify("secret", signature, now.Unix()+1, body, 0, now),
+		"secret":    Verify("other", signature, now.Unix(), body, 0, now),
+		"version":   Verify("secret", "v0"+signature[2:], now.Unix(), body, 0, now),
+	}
+	for name, err := range cases {
+		if !errors.Is(err, ErrInvalidSignature) {
+			t.Fatalf("%s被篡改时应校验失败: %v", name, err)
+		}
+	}
+}
+
+// TestVerifyRejectsReplay 签名时间超出允许偏差时视为重放
+func TestVerifyRejectsReplay(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	body := []byte(`{}`)
+	signature := Sign("secret", now.Unix(), body)
+
+	if err := Verify("secret", signature, now.Unix(), body, time.Minute, now.Add(2*time.Minute)); !errors.Is(err, ErrTimestampExpired) {
+		t.Fatalf("过期签名应被拒绝: %v", err)
+	}
+	if err := Verify("secret", signature, now.Unix(), body, time.Minute, now.Add(-2*time.Minute)); !errors.Is(err, ErrTimestampExpired) {
+		t.Fatalf("未来时间的签名应被拒绝: %v", err)
+	}
+}
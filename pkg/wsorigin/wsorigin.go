@@ -0,0 +1,59 @@
+package wsorigin
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+
+	"goim-social/pkg/config"
+)
+
+// Wildcard 允许任意来源，仅用于开发环境
+const Wildcard = "*"
+
+// Checker WebSocket握手的Origin校验，作为websocket.Upgrader的CheckOrigin使用
+type Checker struct {
+	origins       map[string]bool
+	allowAll      bool
+	allowNoOrigin bool
+}
+
+// NewChecker 根据配置创建Origin校验器
+func NewChecker(cfg config.WebSocketConfig) *Checker {
+	c := &Checker{
+		origins:       make(map[string]bool, len(cfg.AllowedOrigins)),
+		allowNoOrigin: cfg.AllowNoOrigin,
+	}
+	for _, origin := range cfg.AllowedOrigins {
+		if origin == Wildcard {
+			c.allowAll = true
+			continue
+		}
+		if origin = normalize(origin); origin != "" {
+			c.origins[origin] = true
+		}
+	}
+	return c
+}
+
+// Check 判断握手请求的Origin是否允许
+// 不携带Origin的请求来自原生客户端，按配置放行；允许列表为空时只允许与请求Host同源
+func (c *Checker) Check(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return c.allowNoOrigin
+	}
+	if c.allowAll {
+		return true
+	}
+	if len(c.origins) == 0 {
+		u, err := url.Parse(origin)
+		return err == nil && strings.EqualFold(u.Host, r.Host)
+	}
+	return c.origins[normalize(origin)]
+}
+
+// normalize 统一Origin格式，忽略大小写和末尾的斜杠
+func normalize(origin string) string {
+	return strings.TrimSuffix(strings.ToLower(strings.TrimSpace(origin)), "/")
+}
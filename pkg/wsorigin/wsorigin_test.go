@@ -0,0 +1,73 @@
+package wsorigin
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"goim-social/pkg/config"
+)
+
+// newRequest 构造握手请求，origin为空时不携带Origin请求头
+func newRequest(host, origin string) *http.Request {
+	r := httptest.NewRequest(http.MethodGet, "http://"+host+"/api/v1/connect/ws", nil)
+	if origin != "" {
+		r.Header.Set("Origin", origin)
+	}
+	return r
+}
+
+// TestAllowList 只允许列表中的来源，忽略大小写和末尾斜杠
+func TestAllowList(t *testing.T) {
+	c := NewChecker(config.WebSocketConfig{
+		AllowedOrigins: []string{"https://im.example.com", "HTTPS://App.Example.com/"},
+	})
+
+	for _, origin := range []string{"https://im.example.com", "https://IM.example.com/", "https://app.example.com"} {
+		if !c.Check(newRequest("gateway.example.com", origin)) {
+			t.Fatalf("允许列表中的来源应通过: %s", origin)
+		}
+	}
+	for _, origin := range []string{"https://evil.example.com", "http://im.example.com", "https://im.example.com.evil.com", "null"} {
+		if c.Check(newRequest("gateway.example.com", origin)) {
+			t.Fatalf("不在允许列表中的来源应拒绝: %s", origin)
+		}
+	}
+}
+
+// TestSameOriginByDefault 允许列表为空时只允许同源
+func TestSameOriginByDefault(t *testing.T) {
+	c := NewChecker(config.WebSocketConfig{})
+
+	if !c.Check(newRequest("im.example.com", "https://im.example.com")) {
+		t.Fatalf("同源请求应通过")
+	}
+	if c.Check(newRequest("im.example.com", "https://evil.example.com")) {
+		t.Fatalf("跨源请求应拒绝")
+	}
+}
+
+// TestWildcard 通配符允许任意来源
+func TestWildcard(t *testing.T) {
+	c := NewChecker(config.WebSocketConfig{AllowedOrigins: []string{Wildcard}})
+
+	if !c.Check(newRequest("localhost:21003", "http://localhost:3000")) {
+		t.Fatalf("通配符模式应允许任意来源")
+	}
+}
+
+// TestNoOrigin 不携带Origin的原生客户端按配置放行
+func TestNoOrigin(t *testing.T) {
+	allowed := NewChecker(config.WebSocketConfig{
+		AllowedOrigins: []string{"https://im.example.com"},
+		AllowNoOrigin:  true,
+	})
+	if !allowed.Check(newRequest("gateway.example.com", "")) {
+		t.Fatalf("允许原生客户端时不携带Origin的请求应通过")
+	}
+
+	denied := NewChecker(config.WebSocketConfig{AllowedOrigins: []string{Wildcard}})
+	if denied.Check(newRequest("gateway.example.com", "")) {
+		t.Fatalf("不允许原生客户端时不携带Origin的请求应拒绝")
+	}
+}
@@ -206,13 +206,16 @@ func loginUser(apiURL string, scanner *bufio.Scanner) *UserInfo {
 
 // 未读消息请求结构
 type UnreadRequest struct {
-	UserID int64 `json:"user_id"`
+	UserID int64  `json:"user_id"`
+	Cursor string `json:"cursor,omitempty"`
+	Limit  int32  `json:"limit,omitempty"`
 }
 
 // 未读消息响应结构
 type UnreadResponse struct {
-	Messages []UnreadMessage `json:"messages"`
-	Total    int             `json:"total"`
+	Messages   []UnreadMessage `json:"messages"`
+	Total      int             `json:"total"`
+	NextCursor string          `json:"next_cursor"`
 }
 
 type UnreadMessage struct {
@@ -227,27 +230,71 @@ type UnreadMessage struct {
 	CreatedAt string `json:"created_at"`
 }
 
-// fetchUnreadMessages 通过HTTP POST接口获取未读消息
+// unreadPageSize 每页拉取的未读消息数
+const unreadPageSize = 100
+
+// fetchUnreadMessages 通过HTTP POST接口分页获取未读消息，逐页显示并标记已读
 func fetchUnreadMessages(userID int64) {
 	fmt.Printf("\n 正在获取未读消息...\n")
 
+	cursor := ""
+	shown := 0
+	for {
+		unreadResp, err := fetchUnreadPage(userID, cursor)
+		if err != nil {
+			fmt.Printf("❌ %v\n", err)
+			return
+		}
+
+		if shown == 0 {
+			if len(unreadResp.Messages) == 0 {
+				fmt.Printf("📭 没有未读消息\n")
+				return
+			}
+			fmt.Printf(" 共有 %d 条未读消息:\n", unreadResp.Total)
+		}
+
+		for _, msg := range unreadResp.Messages {
+			// 解析时间
+			createdAt, _ := time.Parse(time.RFC3339, msg.CreatedAt)
+			timestamp := createdAt.Format("2006-01-02 15:04:05")
+
+			// 显示消息
+			fmt.Printf("[%s] [未读消息] 来自用户%d: %s\n", timestamp, msg.From, msg.Content)
+		}
+		shown += len(unreadResp.Messages)
+
+		// 标记本页消息为已读
+		go markMessagesAsRead(userID, unreadResp.Messages)
+
+		if unreadResp.NextCursor == "" {
+			break
+		}
+		cursor = unreadResp.NextCursor
+	}
+
+	fmt.Printf("[用户%d] 💬 ", userID)
+}
+
+// fetchUnreadPage 获取一页未读消息
+func fetchUnreadPage(userID int64, cursor string) (*UnreadResponse, error) {
 	// 构造POST请求体
 	reqBody := UnreadRequest{
 		UserID: userID,
+		Cursor: cursor,
+		Limit:  unreadPageSize,
 	}
 
 	jsonData, err := json.Marshal(reqBody)
 	if err != nil {
-		fmt.Printf("❌ 构造请求失败: %v\n", err)
-		return
+		return nil, fmt.Errorf("构造请求失败: %v", err)
 	}
 
 	// 发送POST请求
 	url := "http://localhost:21004/api/v1/messages/unread"
 	req, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonData))
 	if err != nil {
-		fmt.Printf("❌ 创建请求失败: %v\n", err)
-		return
+		return nil, fmt.Errorf("创建请求失败: %v", err)
 	}
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Authorization", "auth-debug")
@@ -255,41 +302,19 @@ func fetchUnreadMessages(userID int64) {
 	client := &http.Client{}
 	resp, err := client.Do(req)
 	if err != nil {
-		fmt.Printf("❌ 获取未读消息失败: %v\n", err)
-		return
+		return nil, fmt.Errorf("获取未读消息失败: %v", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		fmt.Printf("❌ 未读消息请求失败，状态码: %d\n", resp.StatusCode)
-		return
+		return nil, fmt.Errorf("未读消息请求失败，状态码: %d", resp.StatusCode)
 	}
 
 	var unreadResp UnreadResponse
 	if err := json.NewDecoder(resp.Body).Decode(&unreadResp); err != nil {
-		fmt.Printf("❌ 解析未读消息失败: %v\n", err)
-		return
+		return nil, fmt.Errorf("解析未读消息失败: %v", err)
 	}
-
-	if len(unreadResp.Messages) == 0 {
-		fmt.Printf("📭 没有未读消息\n")
-		return
-	}
-
-	fmt.Printf(" 收到 %d 条未读消息:\n", len(unreadResp.Messages))
-	for _, msg := range unreadResp.Messages {
-		// 解析时间
-		createdAt, _ := time.Parse(time.RFC3339, msg.CreatedAt)
-		timestamp := createdAt.Format("2006-01-02 15:04:05")
-
-		// 显示消息
-		fmt.Printf("[%s] [未读消息] 来自用户%d: %s\n", timestamp, msg.From, msg.Content)
-	}
-
-	// 标记消息为已读
-	go markMessagesAsRead(userID, unreadResp.Messages)
-
-	fmt.Printf("[用户%d] 💬 ", userID)
+	return &unreadResp, nil
 }
 
 // markMessagesAsRead 标记消息为已读
@@ -10,10 +10,12 @@ import (
 	"os"
 	"os/exec"
 	"os/signal"
+	"path/filepath"
 	"runtime"
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"syscall"
 	"time"
 
@@ -21,6 +23,7 @@ import (
 	"google.golang.org/protobuf/proto"
 
 	"goim-social/api/rest"
+	"goim-social/pkg/utils"
 )
 
 // GroupMember 群成员信息
@@ -42,11 +45,113 @@ type GroupInfo struct {
 	Members     []GroupMember `json:"members"`
 }
 
-// ChatWindow 聊天窗口
+// ChatWindow 聊天窗口，HTML文件只由窗口自己的写入协程生成，避免并发重写与读取交错
 type ChatWindow struct {
 	Member   GroupMember
 	Messages []ChatMessage
 	mu       sync.Mutex
+
+	filename string
+	refresh  chan struct{} // 容量为1，写入协程忙时多次刷新请求合并为一次
+	written  chan struct{} // 首次写入完成后关闭
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+// newChatWindow 创建聊天窗口
+func newChatWindow(member GroupMember, filename string) *ChatWindow {
+	return &ChatWindow{
+		Member:   member,
+		Messages: make([]ChatMessage, 0),
+		filename: filename,
+		refresh:  make(chan struct{}, 1),
+		written:  make(chan struct{}),
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+}
+
+// start 启动窗口写入协程，render根据消息快照生成HTML
+func (w *ChatWindow) start(render func(GroupMember, []ChatMessage) string) {
+	go func() {
+		defer close(w.done)
+		var once sync.Once
+		for {
+			select {
+			case <-w.stop:
+				return
+			case <-w.refresh:
+				if err := writeFileAtomic(w.filename, []byte(render(w.Member, w.snapshot()))); err != nil {
+					log.Printf("Failed to update chat window file for %s: %v", w.Member.Nickname, err)
+					continue
+				}
+				once.Do(func() { close(w.written) })
+			}
+		}
+	}()
+}
+
+// appendMessage 追加消息，返回当前消息数
+func (w *ChatWindow) appendMessage(message ChatMessage) int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.Messages = append(w.Messages, message)
+	return len(w.Messages)
+}
+
+// snapshot 复制当前消息列表
+func (w *ChatWindow) snapshot() []ChatMessage {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	messages := make([]ChatMessage, len(w.Messages))
+	copy(messages, w.Messages)
+	return messages
+}
+
+// requestRefresh 请求重写HTML文件，不阻塞调用方
+func (w *ChatWindow) requestRefresh() {
+	select {
+	case w.refresh <- struct{}{}:
+	default:
+	}
+}
+
+// waitWritten 等待HTML文件首次写入完成
+func (w *ChatWindow) waitWritten(timeout time.Duration) bool {
+	select {
+	case <-w.written:
+		return true
+	case <-time.After(timeout):
+		return false
+	}
+}
+
+// close 停止写入协程并等待退出
+func (w *ChatWindow) close() {
+	close(w.stop)
+	<-w.done
+}
+
+// writeFileAtomic 先写临时文件再重命名，读取方不会读到写了一半的文件
+func writeFileAtomic(filename string, data []byte) error {
+	tmp := filename + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, filename)
+}
+
+// memberConn 成员WebSocket连接，gorilla连接不支持并发写，写入需加锁
+type memberConn struct {
+	conn    *websocket.Conn
+	writeMu sync.Mutex
+}
+
+// write 串行写入一帧
+func (m *memberConn) write(data []byte) error {
+	m.writeMu.Lock()
+	defer m.writeMu.Unlock()
+	return m.conn.WriteMessage(websocket.BinaryMessage, data)
 }
 
 // ChatMessage 聊天消息
@@ -55,6 +160,7 @@ type ChatMessage struct {
 	Content   string    `json:"content"`
 	Timestamp time.Time `json:"timestamp"`
 	Nickname  string    `json:"nickname"`
+	System    bool      `json:"system"` // 系统广播消息
 }
 
 // SendMessageRequest 发送消息请求
@@ -69,14 +175,43 @@ type GroupChatClient struct {
 	groupID         int64
 	groupInfo       GroupInfo
 	chatWindows     map[int64]*ChatWindow
-	connections     map[int64]*websocket.Conn
+	connections     map[int64]*memberConn
+	windowDir       string         // 聊天窗口HTML文件所在目录，为空时使用当前目录
+	wsURL           string         // 连接服务WebSocket地址
 	defaultSender   int64          // 默认发送者ID
 	processedMsgIDs map[int64]bool // 已处理的消息ID，用于去重
 	mu              sync.RWMutex
 }
 
 // 全局客户端实例，用于HTTP处理器访问
-var globalClient *GroupChatClient
+var globalClient atomic.Pointer[GroupChatClient]
+
+// newGroupChatClient 创建群聊客户端
+func newGroupChatClient() *GroupChatClient {
+	return &GroupChatClient{
+		chatWindows:     make(map[int64]*ChatWindow),
+		connections:     make(map[int64]*memberConn),
+		processedMsgIDs: make(map[int64]bool),
+		wsURL:           "ws://localhost:21006/api/v1/connect/ws",
+	}
+}
+
+// lastMessageID 最近分配的消息ID
+var lastMessageID atomic.Int64
+
+// nextMessageID 以纳秒时间戳分配消息ID，并发发送时保证单调递增不重复，避免被接收端去重误丢
+func nextMessageID() int64 {
+	for {
+		last := lastMessageID.Load()
+		id := time.Now().UnixNano()
+		if id <= last {
+			id = last + 1
+		}
+		if lastMessageID.CompareAndSwap(last, id) {
+			return id
+		}
+	}
+}
 
 // handleSendMessage 处理发送消息的HTTP请求
 func handleSendMessage(w http.ResponseWriter, r *http.Request) {
@@ -100,13 +235,14 @@ func handleSendMessage(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if globalClient == nil {
+	client := globalClient.Load()
+	if client == nil {
 		http.Error(w, "Client not initialized", http.StatusInternalServerError)
 		return
 	}
 
 	// 发送消息
-	if err := globalClient.sendMessage(req.UserID, req.Content); err != nil {
+	if err := client.sendMessage(req.UserID, req.Content); err != nil {
 		fmt.Printf("Failed to send message via HTTP: %v\n", err)
 		http.Error(w, "Failed to send message", http.StatusInternalServerError)
 		return
@@ -136,14 +272,10 @@ func main() {
 	fmt.Println("Using auth-debug token for debugging")
 	fmt.Println()
 
-	client := &GroupChatClient{
-		chatWindows:     make(map[int64]*ChatWindow),
-		connections:     make(map[int64]*websocket.Conn),
-		processedMsgIDs: make(map[int64]bool),
-	}
+	client := newGroupChatClient()
 
 	// 设置全局客户端实例
-	globalClient = client
+	globalClient.Store(client)
 
 	// 启动HTTP服务器
 	startHTTPServer()
@@ -208,12 +340,7 @@ func main() {
 	}
 
 	// Create chat windows for each member
-	for _, member := range client.groupInfo.Members {
-		client.chatWindows[member.UserID] = &ChatWindow{
-			Member:   member,
-			Messages: make([]ChatMessage, 0),
-		}
-	}
+	client.createChatWindows()
 
 	// Connect WebSocket for each member
 	fmt.Println("Connecting WebSocket for each member...")
@@ -255,7 +382,7 @@ func main() {
 	// Additional cleanup - force delete HTML files
 	fmt.Println("Cleaning up chat windows...")
 	for _, member := range client.groupInfo.Members {
-		filename := fmt.Sprintf("chat_%d.html", member.UserID)
+		filename := client.windowFilename(member.UserID)
 		if err := os.Remove(filename); err == nil {
 			fmt.Printf("Deleted chat window file: %s\n", filename)
 		}
@@ -370,15 +497,13 @@ func (c *GroupChatClient) checkOnlineStatus() error {
 		return nil
 	}
 
-	userIDs := make([]int64, len(c.groupInfo.Members))
-	for i, member := range c.groupInfo.Members {
-		userIDs[i] = member.UserID
-	}
-
-	// 直接调用IM网关服务检查在线状态
-	url := "http://localhost:21006/api/v1/connect/online_status"
+	// 调用群组服务的在线成员汇总接口，一次请求获取全部在线成员
+	url := "http://localhost:21002/api/v1/group/presence"
 	reqData := map[string]interface{}{
-		"user_ids": userIDs,
+		"group_id":  c.groupID,
+		"user_id":   c.groupInfo.Members[0].UserID,
+		"page":      1,
+		"page_size": len(c.groupInfo.Members),
 	}
 
 	jsonData, _ := json.Marshal(reqData)
@@ -410,17 +535,17 @@ func (c *GroupChatClient) checkOnlineStatus() error {
 		}
 	}
 
-	fmt.Printf("Online Status API Response: %s\n", string(bodyBytes)) // Debug output
+	fmt.Printf("Group Presence API Response: %s\n", string(bodyBytes)) // Debug output
 
 	var result struct {
-		Success bool            `json:"success"`
-		Message string          `json:"message"`
-		Status  map[string]bool `json:"status"`
-		Data    map[string]bool `json:"data"` // Alternative field name
+		Success       bool    `json:"success"`
+		Message       string  `json:"message"`
+		OnlineCount   int32   `json:"online_count"`
+		OnlineUserIDs []int64 `json:"online_user_ids"`
 	}
 
-	if err := json.Unmarshal(bodyBytes, &result); err != nil {
-		fmt.Printf("Failed to parse online status response: %v\n", err)
+	if err := json.Unmarshal(bodyBytes, &result); err != nil || !result.Success {
+		fmt.Printf("Failed to get group presence: %v %s\n", err, result.Message)
 		// Set all members as offline if API fails
 		for i := range c.groupInfo.Members {
 			c.groupInfo.Members[i].Online = false
@@ -428,24 +553,15 @@ func (c *GroupChatClient) checkOnlineStatus() error {
 		return nil // Don't fail the whole process
 	}
 
-	// Update online status - try both possible field names
-	statusMap := result.Status
-	if statusMap == nil {
-		statusMap = result.Data
+	online := make(map[int64]bool, len(result.OnlineUserIDs))
+	for _, userID := range result.OnlineUserIDs {
+		online[userID] = true
 	}
-
-	if statusMap != nil {
-		for i := range c.groupInfo.Members {
-			userIDStr := fmt.Sprintf("%d", c.groupInfo.Members[i].UserID)
-			c.groupInfo.Members[i].Online = statusMap[userIDStr]
-		}
-	} else {
-		fmt.Println("No status data found in response, setting all as offline")
-		for i := range c.groupInfo.Members {
-			c.groupInfo.Members[i].Online = false
-		}
+	for i := range c.groupInfo.Members {
+		c.groupInfo.Members[i].Online = online[c.groupInfo.Members[i].UserID]
 	}
 
+	fmt.Printf("Online members: %d/%d\n", result.OnlineCount, len(c.groupInfo.Members))
 	return nil
 }
 
@@ -464,8 +580,6 @@ func (c *GroupChatClient) displayMembers() {
 
 // connectMember connects WebSocket for a specific member
 func (c *GroupChatClient) connectMember(member GroupMember) error {
-	wsURL := "ws://localhost:21006/api/v1/connect/ws"
-
 	headers := http.Header{}
 	headers.Set("Authorization", "auth-debug")
 	headers.Set("User-ID", strconv.FormatInt(member.UserID, 10))
@@ -474,13 +588,13 @@ func (c *GroupChatClient) connectMember(member GroupMember) error {
 		HandshakeTimeout: 10 * time.Second,
 	}
 
-	conn, _, err := dialer.Dial(wsURL, headers)
+	conn, _, err := dialer.Dial(c.wsURL, headers)
 	if err != nil {
 		return fmt.Errorf("WebSocket connection failed: %v", err)
 	}
 
 	c.mu.Lock()
-	c.connections[member.UserID] = conn
+	c.connections[member.UserID] = &memberConn{conn: conn}
 	c.mu.Unlock()
 
 	// Start message receiver for this connection
@@ -541,25 +655,30 @@ func (c *GroupChatClient) addMessageToAllWindows(wsMsg *rest.WSMessage) {
 		}
 	}
 
+	// 系统广播消息（message_type=100）单独标识
+	isSystem := wsMsg.MessageType == 100
+	if isSystem {
+		senderNickname = "System"
+	}
+
 	message := ChatMessage{
 		From:      wsMsg.From,
 		Content:   wsMsg.Content,
 		Timestamp: time.Unix(wsMsg.Timestamp, 0),
 		Nickname:  senderNickname,
+		System:    isSystem,
 	}
 
 	fmt.Printf("Adding NEW message to windows: From=%s(%d), Content=%s, MsgID=%d\n", senderNickname, wsMsg.From, wsMsg.Content, wsMsg.MessageId)
 
-	c.mu.Lock()
+	c.mu.RLock()
 	windowCount := 0
 	for _, window := range c.chatWindows {
-		window.mu.Lock()
-		window.Messages = append(window.Messages, message)
+		total := window.appendMessage(message)
 		windowCount++
-		fmt.Printf("Added message to window %d, total messages: %d\n", windowCount, len(window.Messages))
-		window.mu.Unlock()
+		fmt.Printf("Added message to window %d, total messages: %d\n", windowCount, total)
 	}
-	c.mu.Unlock()
+	c.mu.RUnlock()
 
 	fmt.Printf("Message added to %d windows, updating HTML files...\n", windowCount)
 
@@ -578,7 +697,7 @@ func (c *GroupChatClient) sendMessage(userID int64, content string) error {
 	}
 
 	wsMsg := &rest.WSMessage{
-		MessageId:   time.Now().UnixNano(),
+		MessageId:   nextMessageID(),
 		From:        userID,
 		To:          0, // Group message
 		GroupId:     c.groupID,
@@ -593,7 +712,7 @@ func (c *GroupChatClient) sendMessage(userID int64, content string) error {
 		return fmt.Errorf("message marshal failed: %v", err)
 	}
 
-	if err := conn.WriteMessage(websocket.BinaryMessage, data); err != nil {
+	if err := conn.write(data); err != nil {
 		return fmt.Errorf("WebSocket send failed: %v", err)
 	}
 
@@ -615,14 +734,20 @@ func (c *GroupChatClient) openChatWindow(member GroupMember) {
 	var cmd *exec.Cmd
 	switch runtime.GOOS {
 	case "windows":
-		// Create a simple HTML file for the chat window
-		htmlContent := c.generateChatHTML(member)
-		filename := fmt.Sprintf("chat_%d.html", member.UserID)
+		// HTML文件由窗口写入协程生成，等待首次写入完成后再打开
+		c.mu.RLock()
+		window, exists := c.chatWindows[member.UserID]
+		c.mu.RUnlock()
+		if !exists {
+			return
+		}
 
-		if err := os.WriteFile(filename, []byte(htmlContent), 0644); err != nil {
-			log.Printf("Failed to create chat window file for %s: %v", member.Nickname, err)
+		window.requestRefresh()
+		if !window.waitWritten(5 * time.Second) {
+			log.Printf("Failed to create chat window file for %s: timeout", member.Nickname)
 			return
 		}
+		filename := window.filename
 
 		cmd = exec.Command("cmd", "/c", "start", windowTitle, filename)
 	case "darwin":
@@ -641,6 +766,10 @@ func (c *GroupChatClient) openChatWindow(member GroupMember) {
 
 // generateChatHTML generates HTML content for a chat window
 func (c *GroupChatClient) generateChatHTML(member GroupMember) string {
+	// 昵称和群名来自用户输入，插入HTML前转义
+	nickname := utils.EscapeHTML(member.Nickname)
+	groupName := utils.EscapeHTML(c.groupInfo.Name)
+
 	return fmt.Sprintf(`
 <!DOCTYPE html>
 <html>
@@ -692,6 +821,13 @@ func (c *GroupChatClient) generateChatHTML(member GroupMember) string {
             border-left: 3px solid #608b4e;
             color: #d4d4d4;
         }
+        .message.system {
+            background-color: #3a3d41;
+            margin: 0 auto 12px auto;
+            border-left: 3px solid #d7ba7d;
+            color: #d7ba7d;
+            text-align: center;
+        }
         .message-header {
             font-weight: bold;
             font-size: 0.9em;
@@ -747,11 +883,52 @@ func (c *GroupChatClient) generateChatHTML(member GroupMember) string {
         let userID = %d;
         let groupID = %d;
 
+        // 直连模式：浏览器通过json子协议直接与IM网关收发WSMessage，不经过本地Go代理
+        // 注意：同一用户的新连接会替换Go客户端已建立的连接
+        const useDirectWebSocket = false;
+        let socket = null;
+
+        function connectWebSocket() {
+            // 浏览器WebSocket无法设置header，认证信息通过query参数传递
+            socket = new WebSocket('ws://localhost:21006/api/v1/connect/ws?token=auth-debug&user_id=' + userID, 'json');
+            socket.onmessage = function(event) {
+                const wsMsg = JSON.parse(event.data);
+                const div = document.createElement('div');
+                if (wsMsg.message_type == 100) {
+                    // 系统广播消息
+                    div.className = 'message system';
+                    div.textContent = 'System: ' + wsMsg.content;
+                } else {
+                    div.className = wsMsg.from == userID ? 'message own' : 'message other';
+                    div.textContent = 'User' + wsMsg.from + ': ' + wsMsg.content;
+                }
+                document.getElementById('messages').appendChild(div);
+            };
+            socket.onclose = function() {
+                console.log('WebSocket连接已关闭');
+            };
+        }
+
         function sendMessage() {
             const input = document.getElementById('messageInput');
             const message = input.value.trim();
             if (message === '') return;
 
+            if (useDirectWebSocket && socket && socket.readyState === WebSocket.OPEN) {
+                const now = Date.now();
+                socket.send(JSON.stringify({
+                    message_id: now,
+                    from: userID,
+                    group_id: groupID,
+                    content: message,
+                    timestamp: Math.floor(now / 1000),
+                    message_type: 1,
+                    ack_id: 'ack_' + userID + '_' + now
+                }));
+                input.value = '';
+                return;
+            }
+
             // 发送消息到Go后端
             fetch('http://localhost:8080/send-message', {
                 method: 'POST',
@@ -784,6 +961,10 @@ func (c *GroupChatClient) generateChatHTML(member GroupMember) string {
             const input = document.getElementById('messageInput');
             input.addEventListener('keypress', handleKeyPress);
             input.focus();
+
+            if (useDirectWebSocket) {
+                connectWebSocket();
+            }
         };
     </script>
 </head>
@@ -808,16 +989,19 @@ func (c *GroupChatClient) generateChatHTML(member GroupMember) string {
 </body>
 </html>
 `, // --- CORRECTED ARGUMENTS START HERE ---
-		member.Nickname, member.UserID, // For <title>
+		nickname, member.UserID, // For <title>
 		member.UserID, c.groupID, // For JavaScript variables
-		member.Nickname, member.UserID, // For <h2>
-		c.groupInfo.Name, c.groupInfo.ID, // For <p>
-		member.Nickname,               // For system message
+		nickname, member.UserID, // For <h2>
+		groupName, c.groupInfo.ID, // For <p>
+		nickname,                      // For system message
 		time.Now().Format("15:04:05")) // For timestamp
 }
 
 // generateChatHTMLWithMessages generates HTML content with actual messages
 func (c *GroupChatClient) generateChatHTMLWithMessages(member GroupMember, messages []ChatMessage) string {
+	// 昵称、群名和消息内容来自用户输入，插入HTML前转义，防止存储型XSS
+	nickname := utils.EscapeHTML(member.Nickname)
+	groupName := utils.EscapeHTML(c.groupInfo.Name)
 	messagesHTML := ""
 
 	// Add system message
@@ -827,12 +1011,14 @@ func (c *GroupChatClient) generateChatHTMLWithMessages(member GroupMember, messa
             Chat window opened for %s
             <div class="timestamp">%s</div>
         </div>
-    `, member.Nickname, time.Now().Format("15:04:05"))
+    `, nickname, time.Now().Format("15:04:05"))
 
 	// Add all messages
 	for _, msg := range messages {
 		messageClass := "other"
-		if msg.From == member.UserID {
+		if msg.System {
+			messageClass = "system"
+		} else if msg.From == member.UserID {
 			messageClass = "own"
 		}
 
@@ -842,7 +1028,7 @@ func (c *GroupChatClient) generateChatHTMLWithMessages(member GroupMember, messa
             %s
             <div class="timestamp">%s</div>
         </div>
-        `, messageClass, msg.Nickname, msg.From, msg.Content, msg.Timestamp.Format("15:04:05"))
+        `, messageClass, utils.EscapeHTML(msg.Nickname), msg.From, utils.RenderMessageHTML(msg.Content), msg.Timestamp.Format("15:04:05"))
 	}
 
 	return fmt.Sprintf(`
@@ -896,6 +1082,13 @@ func (c *GroupChatClient) generateChatHTMLWithMessages(member GroupMember, messa
             border-left: 3px solid #608b4e;
             color: #d4d4d4;
         }
+        .message.system {
+            background-color: #3a3d41;
+            margin: 0 auto 12px auto;
+            border-left: 3px solid #d7ba7d;
+            color: #d7ba7d;
+            text-align: center;
+        }
         .message-header {
             font-weight: bold;
             font-size: 0.9em;
@@ -951,11 +1144,52 @@ func (c *GroupChatClient) generateChatHTMLWithMessages(member GroupMember, messa
         let userID = %d;
         let groupID = %d;
 
+        // 直连模式：浏览器通过json子协议直接与IM网关收发WSMessage，不经过本地Go代理
+        // 注意：同一用户的新连接会替换Go客户端已建立的连接
+        const useDirectWebSocket = false;
+        let socket = null;
+
+        function connectWebSocket() {
+            // 浏览器WebSocket无法设置header，认证信息通过query参数传递
+            socket = new WebSocket('ws://localhost:21006/api/v1/connect/ws?token=auth-debug&user_id=' + userID, 'json');
+            socket.onmessage = function(event) {
+                const wsMsg = JSON.parse(event.data);
+                const div = document.createElement('div');
+                if (wsMsg.message_type == 100) {
+                    // 系统广播消息
+                    div.className = 'message system';
+                    div.textContent = 'System: ' + wsMsg.content;
+                } else {
+                    div.className = wsMsg.from == userID ? 'message own' : 'message other';
+                    div.textContent = 'User' + wsMsg.from + ': ' + wsMsg.content;
+                }
+                document.getElementById('messages').appendChild(div);
+            };
+            socket.onclose = function() {
+                console.log('WebSocket连接已关闭');
+            };
+        }
+
         function sendMessage() {
             const input = document.getElementById('messageInput');
             const message = input.value.trim();
             if (message === '') return;
 
+            if (useDirectWebSocket && socket && socket.readyState === WebSocket.OPEN) {
+                const now = Date.now();
+                socket.send(JSON.stringify({
+                    message_id: now,
+                    from: userID,
+                    group_id: groupID,
+                    content: message,
+                    timestamp: Math.floor(now / 1000),
+                    message_type: 1,
+                    ack_id: 'ack_' + userID + '_' + now
+                }));
+                input.value = '';
+                return;
+            }
+
             // 发送消息到Go后端
             fetch('http://localhost:8080/send-message', {
                 method: 'POST',
@@ -992,6 +1226,10 @@ func (c *GroupChatClient) generateChatHTMLWithMessages(member GroupMember, messa
             const input = document.getElementById('messageInput');
             input.addEventListener('keypress', handleKeyPress);
             input.focus();
+
+            if (useDirectWebSocket) {
+                connectWebSocket();
+            }
         };
     </script>
 </head>
@@ -1013,47 +1251,47 @@ func (c *GroupChatClient) generateChatHTMLWithMessages(member GroupMember, messa
 </body>
 </html>
 `, // --- CORRECTED ARGUMENTS START HERE ---
-		member.Nickname, member.UserID, // For <title>
+		nickname, member.UserID, // For <title>
 		member.UserID, c.groupID, // For JavaScript variables
-		member.Nickname, member.UserID, // For <h2>
-		c.groupInfo.Name, c.groupInfo.ID, // For <p>
+		nickname, member.UserID, // For <h2>
+		groupName, c.groupInfo.ID, // For <p>
 		len(messages), // For message count in <p>
 		messagesHTML)  // For the messages div
 }
 
-// updateAllChatWindows updates all chat windows by regenerating HTML files
-func (c *GroupChatClient) updateAllChatWindows() {
-	fmt.Printf("[%s] New message received in group %s\n", time.Now().Format("15:04:05"), c.groupInfo.Name)
+// createChatWindows 为每个成员创建聊天窗口并启动写入协程
+func (c *GroupChatClient) createChatWindows() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 
-	// Update HTML files for each member
 	for _, member := range c.groupInfo.Members {
-		c.updateChatWindowHTML(member)
+		window := newChatWindow(member, c.windowFilename(member.UserID))
+		window.start(c.renderChatWindow)
+		c.chatWindows[member.UserID] = window
 	}
 }
 
-// updateChatWindowHTML updates the HTML file for a specific member
-func (c *GroupChatClient) updateChatWindowHTML(member GroupMember) {
-	filename := fmt.Sprintf("chat_%d.html", member.UserID)
-
-	c.mu.RLock()
-	window, exists := c.chatWindows[member.UserID]
-	c.mu.RUnlock()
+// windowFilename 聊天窗口HTML文件路径
+func (c *GroupChatClient) windowFilename(userID int64) string {
+	return filepath.Join(c.windowDir, fmt.Sprintf("chat_%d.html", userID))
+}
 
-	if !exists {
-		return
+// renderChatWindow 根据消息快照生成窗口HTML，尚无消息时使用初始页面
+func (c *GroupChatClient) renderChatWindow(member GroupMember, messages []ChatMessage) string {
+	if len(messages) == 0 {
+		return c.generateChatHTML(member)
 	}
+	return c.generateChatHTMLWithMessages(member, messages)
+}
 
-	window.mu.Lock()
-	messages := make([]ChatMessage, len(window.Messages))
-	copy(messages, window.Messages)
-	window.mu.Unlock()
-
-	// Generate updated HTML content
-	htmlContent := c.generateChatHTMLWithMessages(member, messages)
+// updateAllChatWindows 通知所有窗口的写入协程重写HTML文件
+func (c *GroupChatClient) updateAllChatWindows() {
+	fmt.Printf("[%s] New message received in group %s\n", time.Now().Format("15:04:05"), c.groupInfo.Name)
 
-	// Write to file
-	if err := os.WriteFile(filename, []byte(htmlContent), 0644); err != nil {
-		log.Printf("Failed to update chat window file for %s: %v", member.Nickname, err)
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	for _, window := range c.chatWindows {
+		window.requestRefresh()
 	}
 }
 
@@ -1063,14 +1301,19 @@ func (c *GroupChatClient) closeAllConnections() {
 	defer c.mu.Unlock()
 
 	for userID, conn := range c.connections {
-		if err := conn.Close(); err != nil {
+		if err := conn.conn.Close(); err != nil {
 			log.Printf("Failed to close connection for user %d: %v", userID, err)
 		}
 	}
 
+	// 先停止写入协程，避免清理后文件被重新生成
+	for _, window := range c.chatWindows {
+		window.close()
+	}
+
 	// Clean up HTML files
 	for _, member := range c.groupInfo.Members {
-		filename := fmt.Sprintf("chat_%d.html", member.UserID)
+		filename := c.windowFilename(member.UserID)
 		if err := os.Remove(filename); err != nil {
 			// Ignore errors for file cleanup
 		}
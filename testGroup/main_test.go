@@ -0,0 +1,146 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// groupServer 模拟连接服务，将收到的每一帧广播给所有连接
+type groupServer struct {
+	mu    sync.Mutex
+	conns []*memberConn
+}
+
+func (g *groupServer) handle(w http.ResponseWriter, r *http.Request) {
+	upgrader := websocket.Upgrader{}
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	mc := &memberConn{conn: conn}
+	g.mu.Lock()
+	g.conns = append(g.conns, mc)
+	g.mu.Unlock()
+
+	for {
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		g.mu.Lock()
+		conns := append([]*memberConn(nil), g.conns...)
+		g.mu.Unlock()
+		for _, c := range conns {
+			_ = c.write(data)
+		}
+	}
+}
+
+func TestConcurrentSends(t *testing.T) {
+	server := &groupServer{}
+	ts := httptest.NewServer(http.HandlerFunc(server.handle))
+	defer ts.Close()
+
+	client := newGroupChatClient()
+	client.wsURL = "ws" + strings.TrimPrefix(ts.URL, "http")
+	client.windowDir = t.TempDir()
+	client.groupID = 1
+	client.groupInfo = GroupInfo{ID: 1, Name: "race"}
+	for i := int64(1); i <= 3; i++ {
+		client.groupInfo.Members = append(client.groupInfo.Members, GroupMember{UserID: i, GroupID: 1, Nickname: fmt.Sprintf("User%d", i)})
+	}
+	client.createChatWindows()
+
+	for _, member := range client.groupInfo.Members {
+		if err := client.connectMember(member); err != nil {
+			t.Fatalf("connect member %d: %v", member.UserID, err)
+		}
+	}
+	globalClient.Store(client)
+
+	const sendersPerUser, messagesPerSender = 4, 20
+	total := len(client.groupInfo.Members) * sendersPerUser * messagesPerSender
+
+	// 发送期间持续读取窗口文件，文件必须始终是完整的HTML
+	stopReading := make(chan struct{})
+	var readers sync.WaitGroup
+	for _, member := range client.groupInfo.Members {
+		readers.Add(1)
+		go func(filename string) {
+			defer readers.Done()
+			for {
+				select {
+				case <-stopReading:
+					return
+				default:
+				}
+				data, err := os.ReadFile(filename)
+				if err == nil && !strings.Contains(string(data), "</html>") {
+					t.Errorf("read partial window file %s", filename)
+					return
+				}
+			}
+		}(client.windowFilename(member.UserID))
+	}
+
+	var senders sync.WaitGroup
+	for _, member := range client.groupInfo.Members {
+		for i := 0; i < sendersPerUser; i++ {
+			senders.Add(1)
+			go func(userID int64, sender int) {
+				defer senders.Done()
+				for j := 0; j < messagesPerSender; j++ {
+					if err := globalClient.Load().sendMessage(userID, fmt.Sprintf("msg-%d-%d-%d", userID, sender, j)); err != nil {
+						t.Errorf("send message: %v", err)
+						return
+					}
+				}
+			}(member.UserID, i)
+		}
+	}
+	senders.Wait()
+
+	deadline := time.Now().Add(10 * time.Second)
+	for _, member := range client.groupInfo.Members {
+		window := client.chatWindows[member.UserID]
+		for len(window.snapshot()) < total {
+			if time.Now().After(deadline) {
+				t.Fatalf("window %d received %d of %d messages", member.UserID, len(window.snapshot()), total)
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+
+	close(stopReading)
+	readers.Wait()
+	client.closeAllConnections()
+}
+
+// TestChatHTMLEscapesMessages 测试恶意消息内容和昵称不会以标签形式写入聊天窗口
+func TestChatHTMLEscapesMessages(t *testing.T) {
+	client := newGroupChatClient()
+	client.groupInfo = GroupInfo{ID: 1, Name: `<b>group</b>`}
+	member := GroupMember{UserID: 1, GroupID: 1, Nickname: `<img src=x onerror=alert(1)>`}
+	messages := []ChatMessage{
+		{From: 2, Nickname: `"><script>alert(1)</script>`, Content: `<script>alert(document.cookie)</script>`, Timestamp: time.Now()},
+		{From: 2, Nickname: "User2", Content: `<a href="javascript:alert(1)">click</a>`, Timestamp: time.Now()},
+	}
+
+	page := client.generateChatHTMLWithMessages(member, messages)
+	for _, injected := range []string{"<script>alert", "<img src=x", "<a href=", "<b>group"} {
+		if strings.Contains(page, injected) {
+			t.Errorf("聊天窗口包含未转义的内容: %s", injected)
+		}
+	}
+	if !strings.Contains(page, "&lt;script&gt;alert(document.cookie)&lt;/script&gt;") {
+		t.Errorf("消息内容应以转义后的文本显示")
+	}
+}